@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/robertguss/recon/internal/cli"
 )
@@ -21,14 +23,26 @@ func main() {
 }
 
 func run() int {
-	ctx := context.Background()
+	// Ctrl-C cancels the command context, so a sync in flight rolls its
+	// transaction back; a second Ctrl-C kills the process outright.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	root, err := newRootCommand(ctx)
 	if err != nil {
 		fmt.Fprintln(stderr, err)
 		return 1
 	}
 
-	if err := root.Execute(); err != nil {
+	if err := root.ExecuteContext(ctx); err != nil {
+		if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+			fmt.Fprintln(stderr, "interrupted")
+			return 130
+		}
 		var exitErr cli.ExitError
 		if errors.As(err, &exitErr) {
 			if exitErr.Message != "" {
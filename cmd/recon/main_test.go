@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"syscall"
 	"testing"
 
 	"github.com/robertguss/recon/internal/cli"
@@ -161,6 +164,38 @@ func TestRunExecuteExitErrorZeroCodeFallback(t *testing.T) {
 	}
 }
 
+func TestRunInterrupted(t *testing.T) {
+	origNewRoot := newRootCommand
+	origStderr := stderr
+	defer func() {
+		newRootCommand = origNewRoot
+		stderr = origStderr
+	}()
+
+	newRootCommand = func(context.Context) (*cobra.Command, error) {
+		cmd := &cobra.Command{
+			Use: "recon",
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+					return err
+				}
+				<-cmd.Context().Done()
+				return fmt.Errorf("sync: %w", cmd.Context().Err())
+			},
+		}
+		return cmd, nil
+	}
+	var buf bytes.Buffer
+	stderr = &buf
+
+	if code := run(); code != 130 {
+		t.Fatalf("run() code = %d, want 130", code)
+	}
+	if got := buf.String(); got != "interrupted\n" {
+		t.Fatalf("stderr = %q, want interrupted", got)
+	}
+}
+
 func TestMainCallsExit(t *testing.T) {
 	origExit := exitFn
 	origNewRoot := newRootCommand
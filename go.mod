@@ -7,6 +7,7 @@ require (
 	github.com/golang-migrate/migrate/v4 v4.19.0
 	github.com/spf13/cobra v1.10.1
 	golang.org/x/term v0.37.0
+	golang.org/x/text v0.23.0
 	modernc.org/sqlite v1.40.0
 )
 
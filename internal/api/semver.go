@@ -0,0 +1,118 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Semver levels, from the largest bump down. LevelNone means nothing was
+// committed since the tag.
+const (
+	LevelMajor = "major"
+	LevelMinor = "minor"
+	LevelPatch = "patch"
+	LevelNone  = "none"
+)
+
+// Advice is the version bump the API changes since a tag call for. Next is
+// empty when Tag is not a semantic version. Breaking and Added are the
+// changes that drove the level.
+type Advice struct {
+	Tag      string   `json:"tag"`
+	Commits  int      `json:"commits"`
+	Level    string   `json:"level"`
+	Next     string   `json:"next,omitempty"`
+	Reason   string   `json:"reason"`
+	Breaking []Change `json:"breaking"`
+	Added    []Change `json:"added"`
+}
+
+// Advise classifies diff, the API changes since tag over commits commits.
+// Breaking changes call for a major release, additions for a minor one, and
+// anything else for a patch. Before v1.0.0 breaking changes bump the minor
+// version instead, as Go modules do. With no commits since tag there is
+// nothing to release, whatever diff holds: uncommitted edits are not part of
+// the range.
+func Advise(tag string, commits int, diff Diff) Advice {
+	a := Advice{Tag: tag, Commits: commits, Breaking: []Change{}, Added: []Change{}}
+	if commits == 0 {
+		a.Level = LevelNone
+		a.Reason = "no commits since " + tag
+		return a
+	}
+	for _, c := range diff.Changes {
+		if c.Breaking {
+			a.Breaking = append(a.Breaking, c)
+		} else {
+			a.Added = append(a.Added, c)
+		}
+	}
+
+	switch {
+	case len(a.Breaking) > 0:
+		a.Level = LevelMajor
+		a.Reason = fmt.Sprintf("%d exported %s removed or changed", len(a.Breaking), plural(len(a.Breaking), "declaration", "declarations"))
+	case len(a.Added) > 0:
+		a.Level = LevelMinor
+		a.Reason = fmt.Sprintf("%d exported %s added, none removed or changed", len(a.Added), plural(len(a.Added), "declaration", "declarations"))
+	default:
+		a.Level = LevelPatch
+		a.Reason = "no exported API changes"
+	}
+
+	prefix, major, minor, patch, ok := parseVersion(tag)
+	if !ok {
+		return a
+	}
+	switch {
+	case a.Level == LevelMajor && major == 0:
+		minor, patch = minor+1, 0
+		a.Reason += "; before v1 breaking changes bump the minor version"
+	case a.Level == LevelMajor:
+		major, minor, patch = major+1, 0, 0
+	case a.Level == LevelMinor:
+		minor, patch = minor+1, 0
+	default:
+		patch++
+	}
+	a.Next = fmt.Sprintf("%sv%d.%d.%d", prefix, major, minor, patch)
+	return a
+}
+
+// parseVersion splits a tag such as "v1.4.2" or "sub/v0.3.0" into the
+// path prefix of a nested module's tags and its version numbers. A
+// pre-release or build suffix is dropped.
+func parseVersion(tag string) (prefix string, major, minor, patch int, ok bool) {
+	version := tag
+	if i := strings.LastIndex(tag, "/"); i >= 0 {
+		prefix, version = tag[:i+1], tag[i+1:]
+	}
+	version, found := strings.CutPrefix(version, "v")
+	if !found {
+		return "", 0, 0, 0, false
+	}
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return "", 0, 0, 0, false
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return "", 0, 0, 0, false
+		}
+		nums[i] = n
+	}
+	return prefix, nums[0], nums[1], nums[2], true
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
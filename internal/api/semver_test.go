@@ -0,0 +1,41 @@
+package api
+
+import "testing"
+
+func TestAdvise(t *testing.T) {
+	removed := Change{Package: "a", Name: "Close", Change: "removed", Before: "func Close() error", Breaking: true}
+	added := Change{Package: "a", Name: "Open", Change: "added", After: "func Open()"}
+
+	tests := []struct {
+		name      string
+		tag       string
+		commits   int
+		changes   []Change
+		wantLevel string
+		wantNext  string
+		wantLen   [2]int
+	}{
+		{"breaking", "v1.4.2", 3, []Change{removed, added}, LevelMajor, "v2.0.0", [2]int{1, 1}},
+		{"breaking before v1", "v0.3.1", 3, []Change{removed}, LevelMajor, "v0.4.0", [2]int{1, 0}},
+		{"additions", "v1.4.2", 3, []Change{added}, LevelMinor, "v1.5.0", [2]int{0, 1}},
+		{"no API change", "v1.4.2", 3, nil, LevelPatch, "v1.4.3", [2]int{0, 0}},
+		{"nothing committed", "v1.4.2", 0, nil, LevelNone, "", [2]int{0, 0}},
+		{"nothing committed, uncommitted edits", "v1.4.2", 0, []Change{removed, added}, LevelNone, "", [2]int{0, 0}},
+		{"nested module tag", "tools/v2.0.0-rc.1", 1, []Change{added}, LevelMinor, "tools/v2.1.0", [2]int{0, 1}},
+		{"not a version", "release-7", 1, []Change{added}, LevelMinor, "", [2]int{0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Advise(tt.tag, tt.commits, Diff{Ref: tt.tag, Changes: tt.changes})
+			if got.Level != tt.wantLevel || got.Next != tt.wantNext {
+				t.Fatalf("Advise() = %s %q, want %s %q", got.Level, got.Next, tt.wantLevel, tt.wantNext)
+			}
+			if len(got.Breaking) != tt.wantLen[0] || len(got.Added) != tt.wantLen[1] {
+				t.Fatalf("Advise() drivers = %d breaking, %d added; want %v", len(got.Breaking), len(got.Added), tt.wantLen)
+			}
+			if got.Reason == "" {
+				t.Fatal("Advise() gave no reason")
+			}
+		})
+	}
+}
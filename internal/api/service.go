@@ -0,0 +1,300 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/robertguss/recon/internal/index"
+)
+
+// Decl is one element of a package's exported API: a func, method, type,
+// var, const, or struct field. Methods and fields are named "Type.Name".
+// Declaration is the Go declaration with whitespace collapsed; struct types
+// are shown as "struct", their exported fields being declarations of their
+// own.
+type Decl struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Declaration string `json:"declaration"`
+	FilePath    string `json:"file_path"`
+	Line        int    `json:"line"`
+}
+
+// Package is the exported API of one package, in source order.
+type Package struct {
+	Path  string `json:"path"`
+	Decls []Decl `json:"decls"`
+}
+
+// Options filters the surface. Package is a module-relative package path, or
+// a pattern ending in "/..." for a subtree. main packages are always left
+// out, and packages under an internal directory unless Internal is set,
+// since neither can be imported by other modules.
+type Options struct {
+	Package  string
+	Internal bool
+}
+
+// Change is a difference between two API surfaces. Removing a declaration
+// or changing it is breaking; adding one is not.
+type Change struct {
+	Package  string `json:"package"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Change   string `json:"change"`
+	Before   string `json:"before,omitempty"`
+	After    string `json:"after,omitempty"`
+	Breaking bool   `json:"breaking"`
+	FilePath string `json:"file_path,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// Diff is the comparison of the API at Ref with the indexed API.
+type Diff struct {
+	Ref      string   `json:"ref"`
+	Breaking int      `json:"breaking"`
+	Added    int      `json:"added"`
+	Changes  []Change `json:"changes"`
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// Indexed returns the exported declarations recorded by the last sync.
+func (s *Service) Indexed(ctx context.Context) ([]index.ExportedDecl, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT sy.id, p.path, p.name, f.path, sy.line_start, sy.kind, sy.name,
+       COALESCE(sy.receiver, ''), COALESCE(sy.signature, '')
+FROM symbols sy
+JOIN files f ON f.id = sy.file_id
+JOIN packages p ON p.id = f.package_id
+WHERE sy.exported = 1 AND sy.kind IN ('func', 'method', 'type', 'var', 'const')
+ORDER BY f.path, sy.line_start, sy.id;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query exported symbols: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		decls []index.ExportedDecl
+		ids   = map[int64]int{}
+	)
+	for rows.Next() {
+		var (
+			id int64
+			d  index.ExportedDecl
+		)
+		if err := rows.Scan(&id, &d.Package, &d.PackageName, &d.FilePath, &d.Line, &d.Kind, &d.Name, &d.Receiver, &d.Signature); err != nil {
+			return nil, fmt.Errorf("scan exported symbol: %w", err)
+		}
+		ids[id] = len(decls)
+		decls = append(decls, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate exported symbols: %w", err)
+	}
+
+	fieldRows, err := s.db.QueryContext(ctx, `
+SELECT symbol_id, name, type FROM struct_fields WHERE exported = 1 ORDER BY symbol_id, position;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query exported fields: %w", err)
+	}
+	defer fieldRows.Close()
+	for fieldRows.Next() {
+		var (
+			id int64
+			f  index.ExportedField
+		)
+		if err := fieldRows.Scan(&id, &f.Name, &f.Type); err != nil {
+			return nil, fmt.Errorf("scan exported field: %w", err)
+		}
+		if i, ok := ids[id]; ok {
+			decls[i].Fields = append(decls[i].Fields, f)
+		}
+	}
+	if err := fieldRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate exported fields: %w", err)
+	}
+	return decls, nil
+}
+
+// Surface groups decls into the API of each package matching opts, sorted
+// by package path. A declaration repeated per build constraint is listed
+// once, from the first file declaring it.
+func Surface(decls []index.ExportedDecl, opts Options) []Package {
+	byPath := map[string]*Package{}
+	seen := map[string]bool{}
+	add := func(pkg string, d Decl) {
+		key := pkg + "\x00" + d.Name
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		p := byPath[pkg]
+		if p == nil {
+			p = &Package{Path: pkg}
+			byPath[pkg] = p
+		}
+		p.Decls = append(p.Decls, d)
+	}
+	for _, d := range decls {
+		if d.PackageName == "main" || !matchPackage(d.Package, opts) {
+			continue
+		}
+		decl, ok := render(d)
+		if !ok {
+			continue
+		}
+		add(d.Package, decl)
+		for _, f := range d.Fields {
+			add(d.Package, Decl{
+				Kind:        "field",
+				Name:        d.Name + "." + f.Name,
+				Declaration: d.Name + "." + f.Name + " " + collapse(f.Type),
+				FilePath:    d.FilePath,
+				Line:        d.Line,
+			})
+		}
+	}
+
+	pkgs := make([]Package, 0, len(byPath))
+	for _, p := range byPath {
+		pkgs = append(pkgs, *p)
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Path < pkgs[j].Path })
+	return pkgs
+}
+
+// Compare lists the changes from the base surface to head, by package and
+// then in the order the declarations appear.
+func Compare(ref string, base, head []Package) Diff {
+	type entry struct {
+		pkg  string
+		decl Decl
+	}
+	byKey := func(pkgs []Package) (map[string]entry, []string) {
+		m := map[string]entry{}
+		var keys []string
+		for _, p := range pkgs {
+			for _, d := range p.Decls {
+				key := p.Path + "\x00" + d.Name
+				m[key] = entry{pkg: p.Path, decl: d}
+				keys = append(keys, key)
+			}
+		}
+		return m, keys
+	}
+	before, beforeKeys := byKey(base)
+	after, afterKeys := byKey(head)
+
+	diff := Diff{Ref: ref, Changes: []Change{}}
+	for _, key := range beforeKeys {
+		old := before[key]
+		cur, ok := after[key]
+		switch {
+		case !ok:
+			diff.Changes = append(diff.Changes, Change{
+				Package: old.pkg, Kind: old.decl.Kind, Name: old.decl.Name, Change: "removed",
+				Before: old.decl.Declaration, Breaking: true,
+			})
+		case cur.decl.Declaration != old.decl.Declaration:
+			diff.Changes = append(diff.Changes, Change{
+				Package: cur.pkg, Kind: cur.decl.Kind, Name: cur.decl.Name, Change: "changed",
+				Before: old.decl.Declaration, After: cur.decl.Declaration, Breaking: true,
+				FilePath: cur.decl.FilePath, Line: cur.decl.Line,
+			})
+		}
+	}
+	for _, key := range afterKeys {
+		if _, ok := before[key]; ok {
+			continue
+		}
+		cur := after[key]
+		diff.Changes = append(diff.Changes, Change{
+			Package: cur.pkg, Kind: cur.decl.Kind, Name: cur.decl.Name, Change: "added",
+			After: cur.decl.Declaration, FilePath: cur.decl.FilePath, Line: cur.decl.Line,
+		})
+	}
+	sort.SliceStable(diff.Changes, func(i, j int) bool { return diff.Changes[i].Package < diff.Changes[j].Package })
+	for _, c := range diff.Changes {
+		if c.Breaking {
+			diff.Breaking++
+		} else {
+			diff.Added++
+		}
+	}
+	return diff
+}
+
+// render returns the Decl of an exported declaration, or false for a method
+// of an unexported type.
+func render(d index.ExportedDecl) (Decl, bool) {
+	decl := Decl{Kind: d.Kind, Name: d.Name, FilePath: d.FilePath, Line: d.Line}
+	sig := collapse(d.Signature)
+	switch d.Kind {
+	case "func":
+		decl.Declaration = "func " + d.Name + strings.TrimPrefix(sig, "func")
+	case "method":
+		recv := strings.TrimPrefix(d.Receiver, "*")
+		recv, _, _ = strings.Cut(recv, "[")
+		if !token.IsExported(recv) {
+			return Decl{}, false
+		}
+		decl.Name = recv + "." + d.Name
+		decl.Declaration = "func (" + d.Receiver + ") " + d.Name + strings.TrimPrefix(sig, "func")
+	case "type":
+		decl.Declaration = "type " + d.Name + " " + typeShape(sig)
+	default:
+		decl.Declaration = d.Kind + " " + d.Name
+		if sig != "" {
+			decl.Declaration += " " + sig
+		}
+	}
+	return decl, true
+}
+
+// typeShape reduces a struct type's signature to "struct", keeping any type
+// parameters, so only its exported fields count toward the API.
+func typeShape(sig string) string {
+	params, rest := "", sig
+	if strings.HasPrefix(sig, "[") {
+		if i := strings.Index(sig, "] "); i >= 0 {
+			params, rest = sig[:i+2], sig[i+2:]
+		}
+	}
+	if strings.HasPrefix(rest, "struct{") || strings.HasPrefix(rest, "struct {") {
+		return params + "struct"
+	}
+	return sig
+}
+
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func matchPackage(pkg string, opts Options) bool {
+	if !opts.Internal && (pkg == "internal" || strings.HasPrefix(pkg, "internal/") ||
+		strings.Contains(pkg, "/internal/") || strings.HasSuffix(pkg, "/internal")) {
+		return false
+	}
+	switch pattern := strings.TrimSuffix(opts.Package, "/"); {
+	case pattern == "" || pattern == "..." || pattern == "./...":
+		return true
+	case strings.HasSuffix(pattern, "/..."):
+		base := strings.TrimSuffix(pattern, "/...")
+		return pkg == base || strings.HasPrefix(pkg, base+"/")
+	default:
+		return pkg == pattern
+	}
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func TestIndexedAndSurface(t *testing.T) {
+	root := t.TempDir()
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	for _, stmt := range []string{
+		`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES
+			(1,'store','store','example.com/m/store',2,30,'x','x'),
+			(2,'internal/db','db','example.com/m/internal/db',1,10,'x','x'),
+			(3,'.','main','example.com/m',1,10,'x','x')`,
+		`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES
+			(1,1,'store/store.go','go',20,'h','x','x'),
+			(2,1,'store/store_linux.go','go',10,'h','x','x'),
+			(3,2,'internal/db/db.go','go',10,'h','x','x'),
+			(4,3,'main.go','go',10,'h','x','x')`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES
+			(1,1,'type','Store','[T any] struct {
+	Path	string
+	mu	T
+}',3,6,1,''),
+			(2,1,'method','Get','func(key string) (T, error)',8,8,1,'*Store[T]'),
+			(3,1,'method','Close','func()',9,9,1,'*cache'),
+			(4,1,'const','Version','',10,10,1,''),
+			(5,1,'func','helper','func()',11,11,0,''),
+			(6,2,'func','Open','func(path string) *Store[int]',3,3,1,''),
+			(7,3,'func','Open','func() error',3,3,1,''),
+			(8,4,'func','Run','func()',3,3,1,'')`,
+		`INSERT INTO struct_fields(symbol_id,position,name,type,tag,embedded,exported) VALUES
+			(1,0,'Path','string','',0,1),
+			(1,1,'mu','T','',0,0)`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	decls, err := NewService(conn).Indexed(context.Background())
+	if err != nil {
+		t.Fatalf("Indexed() error = %v", err)
+	}
+	got := Surface(decls, Options{})
+	want := []Package{{Path: "store", Decls: []Decl{
+		{Kind: "type", Name: "Store", Declaration: "type Store [T any] struct", FilePath: "store/store.go", Line: 3},
+		{Kind: "field", Name: "Store.Path", Declaration: "Store.Path string", FilePath: "store/store.go", Line: 3},
+		{Kind: "method", Name: "Store.Get", Declaration: "func (*Store[T]) Get(key string) (T, error)", FilePath: "store/store.go", Line: 8},
+		{Kind: "const", Name: "Version", Declaration: "const Version", FilePath: "store/store.go", Line: 10},
+		{Kind: "func", Name: "Open", Declaration: "func Open(path string) *Store[int]", FilePath: "store/store_linux.go", Line: 3},
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Surface() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	internal := Surface(decls, Options{Package: "internal/...", Internal: true})
+	if len(internal) != 1 || internal[0].Path != "internal/db" || len(internal[0].Decls) != 1 {
+		t.Fatalf("Surface(internal/..., Internal) = %+v, want internal/db.Open", internal)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	decl := func(kind, name, declaration string) Decl {
+		return Decl{Kind: kind, Name: name, Declaration: declaration, FilePath: "a/a.go", Line: 1}
+	}
+	base := []Package{
+		{Path: "a", Decls: []Decl{
+			decl("func", "Open", "func Open(path string) *Store"),
+			decl("func", "Close", "func Close() error"),
+			decl("type", "Store", "type Store struct"),
+		}},
+		{Path: "b", Decls: []Decl{decl("var", "Default", "var Default int")}},
+	}
+	head := []Package{
+		{Path: "a", Decls: []Decl{
+			decl("func", "Open", "func Open(path string) (*Store, error)"),
+			decl("type", "Store", "type Store struct"),
+			decl("field", "Store.Size", "Store.Size int"),
+		}},
+	}
+
+	got := Compare("v1.0.0", base, head)
+	want := Diff{Ref: "v1.0.0", Breaking: 3, Added: 1, Changes: []Change{
+		{Package: "a", Kind: "func", Name: "Open", Change: "changed", Before: "func Open(path string) *Store",
+			After: "func Open(path string) (*Store, error)", Breaking: true, FilePath: "a/a.go", Line: 1},
+		{Package: "a", Kind: "func", Name: "Close", Change: "removed", Before: "func Close() error", Breaking: true},
+		{Package: "a", Kind: "field", Name: "Store.Size", Change: "added", After: "Store.Size int", FilePath: "a/a.go", Line: 1},
+		{Package: "b", Kind: "var", Name: "Default", Change: "removed", Before: "var Default int", Breaking: true},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Compare() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if same := Compare("HEAD", head, head); same.Breaking != 0 || len(same.Changes) != 0 {
+		t.Fatalf("Compare(head, head) = %+v, want no changes", same)
+	}
+}
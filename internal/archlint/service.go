@@ -0,0 +1,405 @@
+package archlint
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Layer is one tier of a declared architecture. Packages are package
+// patterns such as "internal/cli" or "internal/...".
+type Layer struct {
+	Name     string   `json:"name"`
+	Packages []string `json:"packages"`
+}
+
+// Rule forbids packages matching From from importing packages matching To.
+// An empty From applies the rule to every package. Source is "config",
+// "layer", or "constraint".
+type Rule struct {
+	From         string `json:"from,omitempty"`
+	To           string `json:"to"`
+	Reason       string `json:"reason,omitempty"`
+	Source       string `json:"source"`
+	ConstraintID int64  `json:"constraint_id,omitempty"`
+}
+
+// Edge is an import between two module packages with the files that make it.
+type Edge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Files []string `json:"files"`
+}
+
+type Violation struct {
+	Rule Rule `json:"rule"`
+	Edge
+}
+
+// Cycle lists the packages of an import cycle in order, starting from the
+// lexically smallest; Edges closes the loop back to the first package.
+type Cycle struct {
+	Packages []string `json:"packages"`
+	Edges    []Edge   `json:"edges"`
+}
+
+type Result struct {
+	Packages   int         `json:"packages"`
+	Rules      []Rule      `json:"rules"`
+	Cycles     []Cycle     `json:"cycles"`
+	Violations []Violation `json:"violations"`
+	OK         bool        `json:"ok"`
+}
+
+type Options struct {
+	Layers []Layer
+	Rules  []Rule
+	// IncludeTests counts imports from _test.go files, which are left out by
+	// default because external test packages may import in either direction.
+	IncludeTests bool
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// Check analyzes the indexed import graph for cycles, for imports that reach
+// up from a lower layer into a higher one, and for imports forbidden by
+// opts.Rules or by active constraints with an import_absent check.
+func (s *Service) Check(ctx context.Context, opts Options) (Result, error) {
+	g, err := s.loadGraph(ctx, opts.IncludeTests)
+	if err != nil {
+		return Result{}, err
+	}
+	constraintRules, err := s.ConstraintRules(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+
+	rules := append(append([]Rule{}, opts.Rules...), constraintRules...)
+	result := Result{
+		Packages:   len(g.packages),
+		Rules:      append([]Rule{}, rules...),
+		Cycles:     g.cycles(),
+		Violations: append(g.layerViolations(opts.Layers), g.ruleViolations(rules)...),
+	}
+	for i, layer := range opts.Layers {
+		for _, higher := range opts.Layers[:i] {
+			result.Rules = append(result.Rules, Rule{
+				From:   strings.Join(layer.Packages, ","),
+				To:     strings.Join(higher.Packages, ","),
+				Reason: fmt.Sprintf("layer %s must not import layer %s", layer.Name, higher.Name),
+				Source: "layer",
+			})
+		}
+	}
+	sort.SliceStable(result.Violations, func(i, j int) bool {
+		a, b := result.Violations[i], result.Violations[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	result.OK = len(result.Cycles) == 0 && len(result.Violations) == 0
+	return result, nil
+}
+
+// ForbiddenImports returns the imports that break rules, ignoring test files.
+// It backs the import_absent evidence check.
+func (s *Service) ForbiddenImports(ctx context.Context, rules []Rule) ([]Violation, error) {
+	g, err := s.loadGraph(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	return g.ruleViolations(rules), nil
+}
+
+// ConstraintRules returns a rule for each active constraint verified by an
+// import_absent check, whose spec is {"import": <pattern>, "scope": <pattern>}.
+func (s *Service) ConstraintRules(ctx context.Context) ([]Rule, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT c.id, c.title, COALESCE(e.check_spec, '')
+FROM constraints c
+JOIN evidence e ON e.entity_type = 'constraint' AND e.entity_id = c.id
+WHERE c.status = 'active' AND e.check_type = 'import_absent'
+ORDER BY c.id, e.id;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query constraint rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []Rule{}
+	for rows.Next() {
+		var (
+			id          int64
+			title, spec string
+		)
+		if err := rows.Scan(&id, &title, &spec); err != nil {
+			return nil, fmt.Errorf("scan constraint rule: %w", err)
+		}
+		var parsed struct {
+			Import string `json:"import"`
+			Scope  string `json:"scope"`
+		}
+		if err := json.Unmarshal([]byte(spec), &parsed); err != nil || parsed.Import == "" {
+			continue
+		}
+		rules = append(rules, Rule{From: parsed.Scope, To: parsed.Import, Reason: title, Source: "constraint", ConstraintID: id})
+	}
+	return rules, rows.Err()
+}
+
+// MatchPackage reports whether the module-relative package path pkg matches
+// pattern, using go tool syntax: "internal/db" matches only that package,
+// "internal/..." matches internal and everything below it, and "..." or
+// "./..." match every package.
+func MatchPackage(pattern, pkg string) bool {
+	pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+	switch pattern {
+	case "", ".":
+		return pkg == "."
+	case "...":
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return pkg == prefix || strings.HasPrefix(pkg, prefix+"/")
+	}
+	return pkg == strings.TrimSuffix(pattern, "/")
+}
+
+type graph struct {
+	packages []string
+	edges    map[string]map[string][]string
+}
+
+func (s *Service) loadGraph(ctx context.Context, includeTests bool) (graph, error) {
+	g := graph{edges: map[string]map[string][]string{}}
+
+	pkgRows, err := s.db.QueryContext(ctx, `SELECT path FROM packages ORDER BY path;`)
+	if err != nil {
+		return graph{}, fmt.Errorf("query packages: %w", err)
+	}
+	for pkgRows.Next() {
+		var path string
+		if err := pkgRows.Scan(&path); err != nil {
+			pkgRows.Close()
+			return graph{}, fmt.Errorf("scan package: %w", err)
+		}
+		g.packages = append(g.packages, path)
+	}
+	if err := pkgRows.Err(); err != nil {
+		pkgRows.Close()
+		return graph{}, fmt.Errorf("iterate packages: %w", err)
+	}
+	pkgRows.Close()
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT p1.path, p2.path, f.path
+FROM imports i
+JOIN files f ON f.id = i.from_file_id
+JOIN packages p1 ON p1.id = f.package_id
+JOIN packages p2 ON p2.id = i.to_package_id
+WHERE p1.id != p2.id
+  AND (? OR f.path NOT LIKE '%\_test.go' ESCAPE '\')
+ORDER BY p1.path, p2.path, f.path;
+`, includeTests)
+	if err != nil {
+		return graph{}, fmt.Errorf("query imports: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var from, to, file string
+		if err := rows.Scan(&from, &to, &file); err != nil {
+			return graph{}, fmt.Errorf("scan import: %w", err)
+		}
+		if g.edges[from] == nil {
+			g.edges[from] = map[string][]string{}
+		}
+		g.edges[from][to] = append(g.edges[from][to], file)
+	}
+	if err := rows.Err(); err != nil {
+		return graph{}, fmt.Errorf("iterate imports: %w", err)
+	}
+	return g, nil
+}
+
+func (g graph) sortedEdges() []Edge {
+	var edges []Edge
+	for from, tos := range g.edges {
+		for to, files := range tos {
+			edges = append(edges, Edge{From: from, To: to, Files: files})
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
+
+func (g graph) ruleViolations(rules []Rule) []Violation {
+	violations := []Violation{}
+	for _, e := range g.sortedEdges() {
+		for _, rule := range rules {
+			if (rule.From == "" || MatchPackage(rule.From, e.From)) && MatchPackage(rule.To, e.To) {
+				violations = append(violations, Violation{Rule: rule, Edge: e})
+			}
+		}
+	}
+	return violations
+}
+
+// layerViolations reports imports from a package into a package of an
+// earlier (higher) layer. A package belongs to the first layer with a
+// matching pattern, so "internal/cli" can precede a catch-all "internal/...".
+// Packages in no layer are unconstrained.
+func (g graph) layerViolations(layers []Layer) []Violation {
+	violations := []Violation{}
+	if len(layers) == 0 {
+		return violations
+	}
+	layerOf := func(pkg string) int {
+		for i, layer := range layers {
+			for _, pattern := range layer.Packages {
+				if MatchPackage(pattern, pkg) {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+	for _, e := range g.sortedEdges() {
+		from, to := layerOf(e.From), layerOf(e.To)
+		if from < 0 || to < 0 || to >= from {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule: Rule{
+				From:   strings.Join(layers[from].Packages, ","),
+				To:     strings.Join(layers[to].Packages, ","),
+				Reason: fmt.Sprintf("layer %s must not import layer %s", layers[from].Name, layers[to].Name),
+				Source: "layer",
+			},
+			Edge: e,
+		})
+	}
+	return violations
+}
+
+// cycles finds the strongly connected components of the package graph and
+// reports the shortest cycle through the smallest package of each.
+func (g graph) cycles() []Cycle {
+	var (
+		index   = map[string]int{}
+		low     = map[string]int{}
+		onStack = map[string]bool{}
+		stack   []string
+		next    int
+		comps   [][]string
+	)
+	var visit func(string)
+	visit = func(v string) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range sortedKeys(g.edges[v]) {
+			if _, seen := index[w]; !seen {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var comp []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			comp = append(comp, w)
+			if w == v {
+				break
+			}
+		}
+		if len(comp) > 1 {
+			comps = append(comps, comp)
+		}
+	}
+	for _, v := range sortedKeys(g.edges) {
+		if _, seen := index[v]; !seen {
+			visit(v)
+		}
+	}
+
+	cycles := []Cycle{}
+	for _, comp := range comps {
+		sort.Strings(comp)
+		in := map[string]bool{}
+		for _, p := range comp {
+			in[p] = true
+		}
+		path := g.shortestCycle(comp[0], in)
+		c := Cycle{Packages: path}
+		for i, from := range path {
+			to := path[(i+1)%len(path)]
+			c.Edges = append(c.Edges, Edge{From: from, To: to, Files: g.edges[from][to]})
+		}
+		cycles = append(cycles, c)
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i].Packages[0] < cycles[j].Packages[0] })
+	return cycles
+}
+
+// shortestCycle walks breadth-first from start within a component back to
+// start and returns the packages on the way.
+func (g graph) shortestCycle(start string, in map[string]bool) []string {
+	prev := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range sortedKeys(g.edges[v]) {
+			if !in[w] {
+				continue
+			}
+			if w == start {
+				path := []string{v}
+				for v != start {
+					v = prev[v]
+					path = append(path, v)
+				}
+				for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			if _, seen := prev[w]; !seen {
+				prev[w] = v
+				queue = append(queue, w)
+			}
+		}
+	}
+	return []string{start}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
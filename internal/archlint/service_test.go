@@ -0,0 +1,202 @@
+package archlint
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+// archTestDB indexes a small import graph:
+//
+//	cmd/app -> internal/cli -> internal/db
+//	internal/a -> internal/b -> internal/c -> internal/a (cycle)
+//	internal/db -> internal/cli (only from a test file)
+func archTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	conn, err := db.Open(filepath.Join(t.TempDir(), "recon.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+
+	ids := map[string]int64{}
+	for _, pkg := range []string{"cmd/app", "internal/a", "internal/b", "internal/c", "internal/cli", "internal/db"} {
+		res, err := conn.Exec(`INSERT INTO packages (path, name, import_path, created_at, updated_at) VALUES (?, ?, ?, 'now', 'now')`,
+			pkg, filepath.Base(pkg), "example.com/m/"+pkg)
+		if err != nil {
+			t.Fatalf("insert package %s: %v", pkg, err)
+		}
+		ids[pkg], _ = res.LastInsertId()
+	}
+	for _, imp := range []struct{ file, from, to string }{
+		{"cmd/app/main.go", "cmd/app", "internal/cli"},
+		{"internal/cli/root.go", "internal/cli", "internal/db"},
+		{"internal/cli/store.go", "internal/cli", "internal/db"},
+		{"internal/a/a.go", "internal/a", "internal/b"},
+		{"internal/b/b.go", "internal/b", "internal/c"},
+		{"internal/c/c.go", "internal/c", "internal/a"},
+		{"internal/db/db_test.go", "internal/db", "internal/cli"},
+	} {
+		var fileID int64
+		if err := conn.QueryRow(`SELECT id FROM files WHERE path = ?`, imp.file).Scan(&fileID); err != nil {
+			res, err := conn.Exec(`INSERT INTO files (package_id, path, lines, hash, created_at, updated_at) VALUES (?, ?, 1, 'h', 'now', 'now')`, ids[imp.from], imp.file)
+			if err != nil {
+				t.Fatalf("insert file %s: %v", imp.file, err)
+			}
+			fileID, _ = res.LastInsertId()
+		}
+		if _, err := conn.Exec(`INSERT INTO imports (from_file_id, to_path, to_package_id, import_type) VALUES (?, ?, ?, 'local')`,
+			fileID, "example.com/m/"+imp.to, ids[imp.to]); err != nil {
+			t.Fatalf("insert import %s: %v", imp.file, err)
+		}
+	}
+	return conn
+}
+
+func TestMatchPackage(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, pkg string
+		want         bool
+	}{
+		{"internal/db", "internal/db", true},
+		{"internal/db", "internal/dbx", false},
+		{"internal/...", "internal", true},
+		{"internal/...", "internal/db/sub", true},
+		{"internal/...", "internalx", false},
+		{"./cmd/...", "cmd/app", true},
+		{"...", "anything", true},
+		{".", ".", true},
+		{"", "internal/db", false},
+	} {
+		if got := MatchPackage(tc.pattern, tc.pkg); got != tc.want {
+			t.Errorf("MatchPackage(%q, %q) = %v, want %v", tc.pattern, tc.pkg, got, tc.want)
+		}
+	}
+}
+
+func TestCheckFindsCycles(t *testing.T) {
+	conn := archTestDB(t)
+	result, err := NewService(conn).Check(context.Background(), Options{})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if result.OK || result.Packages != 6 || len(result.Cycles) != 1 || len(result.Violations) != 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	c := result.Cycles[0]
+	if strings.Join(c.Packages, ",") != "internal/a,internal/b,internal/c" || len(c.Edges) != 3 {
+		t.Fatalf("cycle = %+v", c)
+	}
+	if last := c.Edges[2]; last.From != "internal/c" || last.To != "internal/a" || last.Files[0] != "internal/c/c.go" {
+		t.Fatalf("closing edge = %+v", last)
+	}
+
+	// The test-only import closes a second cycle through cli and db.
+	result, err = NewService(conn).Check(context.Background(), Options{IncludeTests: true})
+	if err != nil {
+		t.Fatalf("Check with tests: %v", err)
+	}
+	if len(result.Cycles) != 2 || result.Cycles[1].Packages[0] != "internal/cli" {
+		t.Fatalf("cycles with tests = %+v", result.Cycles)
+	}
+}
+
+func TestCheckLayersAndRules(t *testing.T) {
+	conn := archTestDB(t)
+	opts := Options{
+		Layers: []Layer{
+			{Name: "cli", Packages: []string{"cmd/...", "internal/cli"}},
+			{Name: "storage", Packages: []string{"internal/db"}},
+		},
+		Rules: []Rule{{From: "cmd/...", To: "internal/cli", Reason: "cmd calls cli.Run only", Source: "config"}},
+	}
+	result, err := NewService(conn).Check(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(result.Violations) != 1 || len(result.Rules) != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+	if v := result.Violations[0]; v.From != "cmd/app" || v.To != "internal/cli" || v.Rule.Source != "config" || v.Files[0] != "cmd/app/main.go" {
+		t.Fatalf("config violation = %+v", v)
+	}
+
+	// Only the test file reaches up from storage into cli.
+	opts.IncludeTests = true
+	result, err = NewService(conn).Check(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Check with tests: %v", err)
+	}
+	if len(result.Violations) != 2 {
+		t.Fatalf("violations = %+v", result.Violations)
+	}
+	v := result.Violations[1]
+	if v.From != "internal/db" || v.To != "internal/cli" || v.Rule.Source != "layer" ||
+		v.Rule.Reason != "layer storage must not import layer cli" || strings.Join(v.Files, ",") != "internal/db/db_test.go" {
+		t.Fatalf("layer violation = %+v", v)
+	}
+}
+
+func TestConstraintRules(t *testing.T) {
+	conn := archTestDB(t)
+	for _, stmt := range []string{
+		`INSERT INTO constraints (id, title, status, created_at, updated_at) VALUES (1, 'cmd stays above db', 'active', 'now', 'now'), (2, 'old', 'archived', 'now', 'now'), (3, 'grep rule', 'active', 'now', 'now')`,
+		`INSERT INTO evidence (entity_type, entity_id, summary, check_type, check_spec) VALUES
+			('constraint', 1, 's', 'import_absent', '{"import":"internal/db","scope":"cmd/..."}'),
+			('constraint', 2, 's', 'import_absent', '{"import":"internal/cli"}'),
+			('constraint', 3, 's', 'grep_absent', '{"pattern":"x"}')`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	svc := NewService(conn)
+	rules, err := svc.ConstraintRules(context.Background())
+	if err != nil {
+		t.Fatalf("ConstraintRules: %v", err)
+	}
+	if len(rules) != 1 || rules[0].ConstraintID != 1 || rules[0].From != "cmd/..." || rules[0].To != "internal/db" {
+		t.Fatalf("rules = %+v", rules)
+	}
+
+	// cmd reaches internal/db only through cli, so the constraint holds.
+	result, err := svc.Check(context.Background(), Options{})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(result.Violations) != 0 || len(result.Rules) != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+
+	violations, err := svc.ForbiddenImports(context.Background(), []Rule{{To: "internal/db"}})
+	if err != nil {
+		t.Fatalf("ForbiddenImports: %v", err)
+	}
+	if len(violations) != 1 || violations[0].From != "internal/cli" {
+		t.Fatalf("violations = %+v", violations)
+	}
+}
+
+func TestCheckQueryErrors(t *testing.T) {
+	conn := archTestDB(t)
+	if _, err := conn.Exec(`DROP TABLE constraints`); err != nil {
+		t.Fatalf("drop constraints: %v", err)
+	}
+	if _, err := NewService(conn).Check(context.Background(), Options{}); err == nil || !strings.Contains(err.Error(), "query constraint rules") {
+		t.Fatalf("expected constraint query error, got %v", err)
+	}
+	if _, err := conn.Exec(`DROP TABLE imports`); err != nil {
+		t.Fatalf("drop imports: %v", err)
+	}
+	if _, err := NewService(conn).ForbiddenImports(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "query imports") {
+		t.Fatalf("expected imports query error, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+)
+
+var sectionTitles = map[string]string{
+	KindConflictingChecks: "Conflicting evidence checks",
+	KindOverlappingTitles: "Overlapping titles, different reasoning",
+}
+
+// RenderText renders r for the terminal.
+func RenderText(r Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Audited %d decisions, patterns, and constraints: %s\n", r.Audited, findingCount(len(r.Findings)))
+	kind := ""
+	for _, f := range r.Findings {
+		if f.Kind != kind {
+			kind = f.Kind
+			fmt.Fprintf(&b, "\n%s:\n", sectionTitles[kind])
+		}
+		fmt.Fprintf(&b, "- %s %s\n  %s %s\n  %s\n", f.A.Label(), f.A.Title, f.B.Label(), f.B.Title, f.Reason)
+	}
+	return b.String()
+}
+
+// RenderMarkdown renders r as a review document with a checklist per
+// section.
+func RenderMarkdown(r Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Knowledge audit\n\nAudited %d decisions, patterns, and constraints: %s.\n", r.Audited, findingCount(len(r.Findings)))
+	kind := ""
+	for _, f := range r.Findings {
+		if f.Kind != kind {
+			kind = f.Kind
+			fmt.Fprintf(&b, "\n## %s\n\n", sectionTitles[kind])
+		}
+		fmt.Fprintf(&b, "- [ ] **%s** %s vs **%s** %s: %s\n", f.A.Label(), f.A.Title, f.B.Label(), f.B.Title, f.Reason)
+	}
+	return b.String()
+}
+
+func findingCount(n int) string {
+	switch n {
+	case 0:
+		return "no findings"
+	case 1:
+		return "1 finding"
+	default:
+		return fmt.Sprintf("%d findings", n)
+	}
+}
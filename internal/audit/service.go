@@ -0,0 +1,335 @@
+// Package audit reviews the active knowledge base for entries that cannot
+// all hold: evidence checks that exclude each other, and decisions or
+// patterns with overlapping titles but different reasoning.
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/robertguss/recon/internal/recall"
+)
+
+// Kinds of finding.
+const (
+	KindConflictingChecks = "conflicting_checks"
+	KindOverlappingTitles = "overlapping_titles"
+)
+
+// Thresholds of an overlapping_titles finding: titles at least this
+// similar, with reasoning less similar than ReasoningThreshold.
+const (
+	TitleThreshold     = 0.5
+	ReasoningThreshold = 0.4
+)
+
+// Entry is an active decision, pattern, or constraint with one of its
+// evidence checks.
+type Entry struct {
+	EntityType string `json:"entity_type"`
+	ID         int64  `json:"id"`
+	Title      string `json:"title"`
+	Reasoning  string `json:"reasoning"`
+	Branch     string `json:"branch,omitempty"`
+	CheckType  string `json:"check_type,omitempty"`
+	CheckSpec  string `json:"check_spec,omitempty"`
+
+	// optional is set on the checks of an entity that holds when any of
+	// several checks passes, so that no one of them must.
+	optional bool
+	// first is set on the first entry of each entity.
+	first bool
+}
+
+// Label names the entry as "decision #3".
+func (e Entry) Label() string {
+	return fmt.Sprintf("%s #%d", e.EntityType, e.ID)
+}
+
+// Finding is a pair of entries to review together.
+type Finding struct {
+	Kind   string  `json:"kind"`
+	A      Entry   `json:"a"`
+	B      Entry   `json:"b"`
+	Reason string  `json:"reason"`
+	Score  float64 `json:"score,omitempty"`
+}
+
+// Report is the result of an audit.
+type Report struct {
+	Audited  int       `json:"audited"`
+	Findings []Finding `json:"findings"`
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// Audit compares every pair of active decisions, patterns, and constraints,
+// check by check for entities backed by several. Decisions scoped to
+// different branches never meet, so they are not compared with each other.
+func (s *Service) Audit(ctx context.Context) (Report, error) {
+	entries, err := s.entries(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	report := Report{Findings: []Finding{}}
+	for i := range entries {
+		if entries[i].first {
+			report.Audited++
+		}
+		for j := i + 1; j < len(entries); j++ {
+			a, b := entries[i], entries[j]
+			if a.EntityType == b.EntityType && a.ID == b.ID {
+				continue
+			}
+			if a.Branch != "" && b.Branch != "" && a.Branch != b.Branch {
+				continue
+			}
+			if !a.optional && !b.optional {
+				if reason, ok := conflict(a, b); ok {
+					report.Findings = append(report.Findings, Finding{Kind: KindConflictingChecks, A: a, B: b, Reason: reason})
+				}
+			}
+			if !a.first || !b.first {
+				continue
+			}
+			if f, ok := overlap(a, b); ok {
+				report.Findings = append(report.Findings, f)
+			}
+		}
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return report.Findings[i].Kind < report.Findings[j].Kind
+	})
+	return report, nil
+}
+
+func (s *Service) entries(ctx context.Context) ([]Entry, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT 'decision', d.id, d.title, d.reasoning, COALESCE(d.branch, ''), COALESCE(e.check_type, ''), COALESCE(e.check_spec, ''), COALESCE(e.combine, 'all'), COALESCE(e.id, 0)
+FROM decisions d
+LEFT JOIN evidence e ON e.entity_type = 'decision' AND e.entity_id = d.id
+WHERE d.status = 'active'
+UNION ALL
+SELECT 'pattern', p.id, p.title, p.description, '', COALESCE(e.check_type, ''), COALESCE(e.check_spec, ''), COALESCE(e.combine, 'all'), COALESCE(e.id, 0)
+FROM patterns p
+LEFT JOIN evidence e ON e.entity_type = 'pattern' AND e.entity_id = p.id
+WHERE p.status = 'active'
+UNION ALL
+SELECT 'constraint', c.id, c.title, c.reasoning, '', COALESCE(e.check_type, ''), COALESCE(e.check_spec, ''), COALESCE(e.combine, 'all'), COALESCE(e.id, 0)
+FROM constraints c
+LEFT JOIN evidence e ON e.entity_type = 'constraint' AND e.entity_id = c.id
+WHERE c.status = 'active'
+ORDER BY 1, 2, 9;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query knowledge: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		entries []Entry
+		anyOf   []bool
+	)
+	for rows.Next() {
+		var (
+			e          Entry
+			combine    string
+			evidenceID int64
+		)
+		if err := rows.Scan(&e.EntityType, &e.ID, &e.Title, &e.Reasoning, &e.Branch, &e.CheckType, &e.CheckSpec, &combine, &evidenceID); err != nil {
+			return nil, fmt.Errorf("scan knowledge: %w", err)
+		}
+		n := len(entries)
+		e.first = n == 0 || entries[n-1].EntityType != e.EntityType || entries[n-1].ID != e.ID
+		entries = append(entries, e)
+		anyOf = append(anyOf, combine == "any")
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate knowledge: %w", err)
+	}
+	// A check is optional when its entity holds on any of several checks.
+	for i := 0; i < len(entries); {
+		j := i + 1
+		for j < len(entries) && !entries[j].first {
+			j++
+		}
+		for k := i; k < j; k++ {
+			entries[k].optional = anyOf[k] && j-i > 1
+		}
+		i = j
+	}
+	return entries, nil
+}
+
+// conflict reports whether the evidence checks of a and b cannot pass at
+// the same time, and why: one requires text, or a symbol name, that the
+// other's grep_absent check forbids in every file the first could find it
+// in, or a file the other's file_absent check forbids.
+func conflict(a, b Entry) (string, bool) {
+	if b.CheckType == "file_absent" || isGrepAbsent(b.CheckType) {
+		a, b = b, a
+	}
+	if a.CheckType == "file_absent" {
+		return fileConflict(a, b)
+	}
+	if !isGrepAbsent(a.CheckType) {
+		return "", false
+	}
+	var absent struct {
+		Pattern string `json:"pattern"`
+		Scope   string `json:"scope"`
+	}
+	if json.Unmarshal([]byte(a.CheckSpec), &absent) != nil || absent.Pattern == "" {
+		return "", false
+	}
+	forbidden, err := regexp.Compile(absent.Pattern)
+	if err != nil {
+		return "", false
+	}
+
+	switch b.CheckType {
+	case "grep_pattern":
+		var required struct {
+			Pattern string `json:"pattern"`
+			Scope   string `json:"scope"`
+		}
+		if json.Unmarshal([]byte(b.CheckSpec), &required) != nil || !scopeWithin(required.Scope, absent.Scope) {
+			return "", false
+		}
+		if required.Pattern != absent.Pattern && !requiresMatch(required.Pattern, forbidden) {
+			return "", false
+		}
+		return fmt.Sprintf("%s requires a file in %s matching `%s`, which %s forbids in %s with `%s`",
+			b.Label(), displayScope(required.Scope), required.Pattern, a.Label(), displayScope(absent.Scope), absent.Pattern), true
+	case "symbol_exists":
+		var required struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal([]byte(b.CheckSpec), &required) != nil || required.Name == "" || !coversGoFiles(absent.Scope) {
+			return "", false
+		}
+		if !forbidden.MatchString(required.Name) {
+			return "", false
+		}
+		return fmt.Sprintf("%s requires symbol %s, whose name %s forbids in %s with `%s`",
+			b.Label(), required.Name, a.Label(), displayScope(absent.Scope), absent.Pattern), true
+	}
+	return "", false
+}
+
+// isGrepAbsent reports whether checkType passes only while a pattern
+// matches nowhere in scope.
+func isGrepAbsent(checkType string) bool {
+	return checkType == "grep_absent" || checkType == "not_grep_pattern"
+}
+
+// fileConflict reports a file_exists check of b on the path a's file_absent
+// check forbids.
+func fileConflict(a, b Entry) (string, bool) {
+	if b.CheckType != "file_exists" {
+		return "", false
+	}
+	var absent, required struct {
+		Path string `json:"path"`
+	}
+	if json.Unmarshal([]byte(a.CheckSpec), &absent) != nil || json.Unmarshal([]byte(b.CheckSpec), &required) != nil {
+		return "", false
+	}
+	if absent.Path == "" || filepath.Clean(absent.Path) != filepath.Clean(required.Path) {
+		return "", false
+	}
+	return fmt.Sprintf("%s requires file %s, which %s requires to be absent", b.Label(), required.Path, a.Label()), true
+}
+
+// requiresMatch reports whether the text a required pattern spells out,
+// read with its escapes removed (`log\.Fatal` as "log.Fatal"), matches both
+// it and forbidden: a file satisfying the one the obvious way breaks the
+// other. Patterns that spell out no such text never match.
+func requiresMatch(required string, forbidden *regexp.Regexp) bool {
+	re, err := regexp.Compile(required)
+	if err != nil {
+		return false
+	}
+	var text strings.Builder
+	escaped := false
+	for _, r := range required {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		text.WriteRune(r)
+	}
+	sample := text.String()
+	return sample != "" && re.MatchString(sample) && forbidden.MatchString(sample)
+}
+
+// scopeWithin reports whether every file the inner grep scope selects is
+// also selected by outer. An empty scope means every Go file.
+func scopeWithin(inner, outer string) bool {
+	switch {
+	case inner == outer:
+		return true
+	case outer == "":
+		return strings.HasSuffix(inner, ".go")
+	case inner == "":
+		return coversGoFiles(outer)
+	}
+	// Matching the inner glob as a name treats its wildcards as literal
+	// characters, which only an outer wildcard matches: *.go covers
+	// *_test.go, but not the other way round.
+	match, _ := filepath.Match(outer, inner)
+	return match
+}
+
+// coversGoFiles reports whether a grep scope selects every Go file.
+func coversGoFiles(scope string) bool {
+	return scope == "" || scope == "*.go"
+}
+
+func displayScope(scope string) string {
+	if scope == "" {
+		return "all Go files"
+	}
+	return scope
+}
+
+// overlap reports decisions and patterns whose titles say nearly the same
+// thing while their reasoning does not: the same choice made twice for
+// different reasons, or two opposite choices.
+func overlap(a, b Entry) (Finding, bool) {
+	if a.EntityType == "constraint" || b.EntityType == "constraint" {
+		return Finding{}, false
+	}
+	title := recall.Overlap(a.Title, b.Title)
+	if title < TitleThreshold {
+		return Finding{}, false
+	}
+	reasoning := recall.Overlap(a.Reasoning, b.Reasoning)
+	if reasoning >= ReasoningThreshold {
+		return Finding{}, false
+	}
+	return Finding{
+		Kind:   KindOverlappingTitles,
+		A:      a,
+		B:      b,
+		Reason: fmt.Sprintf("titles overlap %.2f but reasoning only %.2f", title, reasoning),
+		Score:  round(title),
+	}, true
+}
+
+func round(f float64) float64 {
+	return float64(int(f*100+0.5)) / 100
+}
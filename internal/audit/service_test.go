@@ -0,0 +1,199 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func auditTestDB(t *testing.T, stmts ...string) *sql.DB {
+	t.Helper()
+	root := t.TempDir()
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	for _, stmt := range stmts {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	return conn
+}
+
+func TestAudit(t *testing.T) {
+	conn := auditTestDB(t,
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at,branch) VALUES
+			(1,'Return ExitError from commands','Controlled exit codes','high','active','x','x',NULL),
+			(2,'Keep the legacy client','Callers still depend on it','medium','active','x','x',NULL),
+			(3,'Log with slog','Structured output for the log pipeline','medium','active','x','x',NULL),
+			(4,'Log with zap','Allocation-free logging on hot paths','medium','active','x','x','feature'),
+			(5,'Log with logrus','Familiar API','low','active','x','x','other'),
+			(6,'Return ExitError everywhere','Old','low','archived','x','x',NULL)`,
+		`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES
+			(1,'Logging with slog','Structured output for the log pipeline','medium','active','x','x')`,
+		`INSERT INTO constraints(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+			(1,'No ExitError in cmd','main owns exit codes','high','active','x','x'),
+			(2,'No legacy client','Deprecated','high','active','x','x'),
+			(3,'No panics in tests','Use t.Fatal','high','active','x','x')`,
+		`INSERT INTO evidence(entity_type,entity_id,summary,check_type,check_spec) VALUES
+			('decision',1,'s','grep_pattern','{"pattern":"ExitError{","scope":"*.go"}'),
+			('decision',2,'s','symbol_exists','{"name":"LegacyClient"}'),
+			('decision',3,'s','file_exists','{"path":"go.mod"}'),
+			('decision',6,'s','grep_pattern','{"pattern":"ExitError"}'),
+			('constraint',1,'s','grep_absent','{"pattern":"ExitError"}'),
+			('constraint',2,'s','grep_absent','{"pattern":"Legacy[A-Z]","scope":"*.go"}'),
+			('constraint',3,'s','grep_absent','{"pattern":"ExitError","scope":"*_test.go"}')`,
+	)
+
+	report, err := NewService(conn).Audit(context.Background())
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+	if report.Audited != 9 {
+		t.Fatalf("audited = %d, want 9", report.Audited)
+	}
+	type pair struct{ kind, a, b string }
+	var got []pair
+	for _, f := range report.Findings {
+		got = append(got, pair{f.Kind, f.A.Label(), f.B.Label()})
+	}
+	want := []pair{
+		{KindConflictingChecks, "constraint #1", "decision #1"},
+		{KindConflictingChecks, "constraint #2", "decision #2"},
+		{KindOverlappingTitles, "decision #3", "decision #4"},
+		{KindOverlappingTitles, "decision #3", "decision #5"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("findings = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("finding %d = %+v, want %+v (all %+v)", i, got[i], want[i], got)
+		}
+	}
+	if r := report.Findings[0].Reason; r != "decision #1 requires a file in *.go matching `ExitError{`, which constraint #1 forbids in all Go files with `ExitError`" {
+		t.Fatalf("conflict reason = %q", r)
+	}
+
+	text := RenderText(report)
+	for _, want := range []string{"Audited 9 decisions, patterns, and constraints: 4 findings", "Conflicting evidence checks:", "Overlapping titles, different reasoning:", "- decision #3 Log with slog"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("text report missing %q:\n%s", want, text)
+		}
+	}
+	if md := RenderMarkdown(report); !strings.Contains(md, "## Conflicting evidence checks") || !strings.Contains(md, "- [ ] **constraint #2** No legacy client vs **decision #2**") {
+		t.Fatalf("markdown report:\n%s", md)
+	}
+}
+
+func TestScopeWithin(t *testing.T) {
+	for _, tc := range []struct {
+		inner, outer string
+		want         bool
+	}{
+		{"", "", true},
+		{"", "*.go", true},
+		{"*.go", "", true},
+		{"go.mod", "", false},
+		{"main.go", "*.go", true},
+		{"*_test.go", "*.go", true},
+		{"*.go", "*_test.go", false},
+		{"", "*_test.go", false},
+		{"*.md", "*.go", false},
+	} {
+		if got := scopeWithin(tc.inner, tc.outer); got != tc.want {
+			t.Errorf("scopeWithin(%q, %q) = %v, want %v", tc.inner, tc.outer, got, tc.want)
+		}
+	}
+}
+
+func TestRequiresMatch(t *testing.T) {
+	for _, tc := range []struct {
+		required, forbidden string
+		want                bool
+	}{
+		{`ExitError{`, `ExitError`, true},
+		{`log\.Fatalf`, `log\.Fatal`, true},
+		{`log\.Fatal`, `log\.Fatalf`, false},
+		{`Must[A-Z]\w+`, `Must`, false},
+		{`(`, `x`, false},
+	} {
+		if got := requiresMatch(tc.required, regexp.MustCompile(tc.forbidden)); got != tc.want {
+			t.Errorf("requiresMatch(%q, %q) = %v, want %v", tc.required, tc.forbidden, got, tc.want)
+		}
+	}
+}
+
+func TestAuditMultipleChecks(t *testing.T) {
+	conn := auditTestDB(t,
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+			(1,'Return ExitError from commands','Controlled exit codes','high','active','x','x'),
+			(2,'Keep a legacy client','Callers still depend on it','medium','active','x','x')`,
+		`INSERT INTO constraints(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+			(1,'No ExitError in cmd','main owns exit codes','high','active','x','x'),
+			(2,'No legacy client','Deprecated','high','active','x','x')`,
+		`INSERT INTO evidence(entity_type,entity_id,summary,check_type,check_spec,combine) VALUES
+			('decision',1,'s','file_exists','{"path":"go.mod"}','all'),
+			('decision',1,'s','grep_pattern','{"pattern":"ExitError{"}','all'),
+			('decision',2,'s','symbol_exists','{"name":"LegacyClient"}','any'),
+			('decision',2,'s','file_exists','{"path":"legacy/client.go"}','any'),
+			('constraint',1,'s','grep_absent','{"pattern":"ExitError"}','all'),
+			('constraint',2,'s','grep_absent','{"pattern":"Legacy"}','all')`,
+	)
+
+	report, err := NewService(conn).Audit(context.Background())
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+	if report.Audited != 4 {
+		t.Fatalf("audited = %d, want 4", report.Audited)
+	}
+	// Decision #2 still holds through its file check when any may pass.
+	if len(report.Findings) != 1 {
+		t.Fatalf("findings = %+v, want one", report.Findings)
+	}
+	if f := report.Findings[0]; f.A.Label() != "constraint #1" || f.B.Label() != "decision #1" || f.B.CheckType != "grep_pattern" {
+		t.Fatalf("finding = %+v", f)
+	}
+}
+
+func TestAuditAbsenceChecks(t *testing.T) {
+	conn := auditTestDB(t,
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+			(1,'Vendor dependencies','Offline builds','medium','active','x','x'),
+			(2,'Download modules on demand','Modules only','medium','active','x','x'),
+			(3,'Wrap errors with fmt','Context on every error','medium','active','x','x'),
+			(4,'No fmt in the core','Keep it lean','medium','active','x','x')`,
+		`INSERT INTO evidence(entity_type,entity_id,summary,check_type,check_spec) VALUES
+			('decision',1,'s','file_exists','{"path":"vendor/modules.txt"}'),
+			('decision',2,'s','file_absent','{"path":"./vendor/modules.txt"}'),
+			('decision',3,'s','grep_pattern','{"pattern":"fmt\\.Errorf"}'),
+			('decision',4,'s','not_grep_pattern','{"pattern":"fmt\\."}')`,
+	)
+
+	report, err := NewService(conn).Audit(context.Background())
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+	if len(report.Findings) != 2 {
+		t.Fatalf("findings = %+v, want two", report.Findings)
+	}
+	if r := report.Findings[0].Reason; r != "decision #1 requires file vendor/modules.txt, which decision #2 requires to be absent" {
+		t.Fatalf("file conflict reason = %q", r)
+	}
+	if f := report.Findings[1]; f.A.Label() != "decision #3" || f.B.Label() != "decision #4" || !strings.Contains(f.Reason, "decision #4 forbids") {
+		t.Fatalf("grep conflict = %+v", f)
+	}
+}
@@ -0,0 +1,436 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/config"
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/index"
+)
+
+// maxQueryRows bounds the result rows kept for a failing query.
+const maxQueryRows = 20
+
+// recentSyncRuns is how many of the latest sync_history rows are included.
+const recentSyncRuns = 10
+
+type Options struct {
+	ModuleRoot string
+	Version    string
+	Commit     string
+	// Query is SQL that fails or misbehaves. It is run, along with its query
+	// plan, inside a transaction that is always rolled back.
+	Query string
+	// Anonymize replaces the module path, commit hashes, string literals in
+	// Query, and text values in its results with stable hashes, so the same
+	// value maps to the same placeholder throughout the bundle.
+	Anonymize bool
+}
+
+// Manifest describes a bundle and is stored in it as manifest.json. Errors
+// collects the sections that could not be gathered; a bundle is still
+// written, since a broken database is often what is being reported.
+type Manifest struct {
+	CreatedAt     string       `json:"created_at"`
+	Recon         VersionInfo  `json:"recon"`
+	Platform      string       `json:"platform"`
+	SQLiteVersion string       `json:"sqlite_version,omitempty"`
+	ModulePath    string       `json:"module_path,omitempty"`
+	Database      DatabaseInfo `json:"database"`
+	Anonymized    bool         `json:"anonymized"`
+	Files         []string     `json:"files"`
+	Errors        []string     `json:"errors,omitempty"`
+}
+
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+}
+
+// DatabaseInfo compares the project database with the schema this build
+// expects. SchemaVersion is -1 when the database was never migrated.
+type DatabaseInfo struct {
+	Initialized           bool   `json:"initialized"`
+	SizeBytes             int64  `json:"size_bytes,omitempty"`
+	SchemaVersion         int    `json:"schema_version"`
+	SchemaDirty           bool   `json:"schema_dirty"`
+	ExpectedSchemaVersion int    `json:"expected_schema_version"`
+	IntegrityCheck        string `json:"integrity_check,omitempty"`
+}
+
+// SyncInfo is stored as sync_state.json.
+type SyncInfo struct {
+	State      *SyncState   `json:"state"`
+	RecentRuns []db.SyncRun `json:"recent_runs"`
+}
+
+type SyncState struct {
+	LastSyncAt       string `json:"last_sync_at"`
+	LastSyncCommit   string `json:"last_sync_commit"`
+	LastSyncDirty    bool   `json:"last_sync_dirty"`
+	IndexedFileCount int    `json:"indexed_file_count"`
+	IndexFingerprint string `json:"index_fingerprint"`
+}
+
+// QueryContext is stored as query.json when Options.Query is set.
+type QueryContext struct {
+	SQL       string   `json:"sql"`
+	Plan      []string `json:"plan,omitempty"`
+	PlanError string   `json:"plan_error,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	Columns   []string `json:"columns,omitempty"`
+	Rows      [][]any  `json:"rows,omitempty"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+// NewService returns a Service over conn, which is nil when the project has
+// no database yet.
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+var now = time.Now
+
+// Write gathers the bundle and writes it to w as a gzipped tar archive. Only
+// a failure to write the archive is returned as an error.
+func (s *Service) Write(ctx context.Context, w io.Writer, opts Options) (Manifest, error) {
+	b := &builder{opts: opts, files: map[string][]byte{}}
+	m := Manifest{
+		CreatedAt:  now().UTC().Format(time.RFC3339),
+		Recon:      VersionInfo{Version: opts.Version, Commit: opts.Commit, GoVersion: runtime.Version()},
+		Platform:   runtime.GOOS + "/" + runtime.GOARCH,
+		Anonymized: opts.Anonymize,
+		Database:   DatabaseInfo{SchemaVersion: -1},
+	}
+
+	if modulePath, err := index.ModulePath(opts.ModuleRoot); err != nil {
+		b.fail("module path", err)
+	} else {
+		m.ModulePath = b.anon(modulePath)
+	}
+	if expected, err := db.LatestMigrationVersion(); err != nil {
+		b.fail("expected schema version", err)
+	} else {
+		m.Database.ExpectedSchemaVersion = expected
+	}
+	s.addConfig(b)
+
+	if s.db == nil {
+		b.errors = append(b.errors, "database: not initialized")
+	} else {
+		m.Database.Initialized = true
+		if info, err := os.Stat(db.DBPath(opts.ModuleRoot)); err == nil {
+			m.Database.SizeBytes = info.Size()
+		}
+		if err := s.db.QueryRowContext(ctx, `SELECT sqlite_version();`).Scan(&m.SQLiteVersion); err != nil {
+			b.fail("sqlite version", err)
+		}
+		s.addSchema(ctx, b, &m.Database)
+		s.addRowCounts(ctx, b)
+		s.addSync(ctx, b)
+		if strings.TrimSpace(opts.Query) != "" {
+			s.addQuery(ctx, b)
+		}
+	}
+
+	m.Errors = b.errors
+	m.Files = make([]string, 0, len(b.files)+1)
+	m.Files = append(m.Files, "manifest.json")
+	for name := range b.files {
+		m.Files = append(m.Files, name)
+	}
+	sort.Strings(m.Files[1:])
+
+	manifestJSON, err := marshal(m)
+	if err != nil {
+		return Manifest{}, err
+	}
+	b.files["manifest.json"] = manifestJSON
+	if err := writeArchive(w, m.Files, b.files, now()); err != nil {
+		return Manifest{}, err
+	}
+	return m, nil
+}
+
+type builder struct {
+	opts   Options
+	files  map[string][]byte
+	errors []string
+}
+
+func (b *builder) fail(section string, err error) {
+	b.errors = append(b.errors, section+": "+err.Error())
+}
+
+func (b *builder) addJSON(name string, v any) {
+	raw, err := marshal(v)
+	if err != nil {
+		b.fail(name, err)
+		return
+	}
+	b.files[name] = raw
+}
+
+// anon returns value unchanged unless anonymizing, in which case it returns a
+// short stable hash. Empty values stay empty.
+func (b *builder) anon(value string) string {
+	if !b.opts.Anonymize || value == "" {
+		return value
+	}
+	sum := sha256.Sum256([]byte(value))
+	return "anon-" + hex.EncodeToString(sum[:4])
+}
+
+func (s *Service) addConfig(b *builder) {
+	raw, err := os.ReadFile(config.Path(b.opts.ModuleRoot))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			b.fail("config", err)
+		}
+		return
+	}
+	// The config holds policy settings only, so it is copied as is.
+	b.files["config.json"] = raw
+}
+
+func (s *Service) addSchema(ctx context.Context, b *builder, info *DatabaseInfo) {
+	var dirty bool
+	err := s.db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations;`).Scan(&info.SchemaVersion, &dirty)
+	switch {
+	case err == nil:
+		info.SchemaDirty = dirty
+	case errors.Is(err, sql.ErrNoRows):
+	default:
+		b.fail("schema version", err)
+	}
+
+	if err := s.db.QueryRowContext(ctx, `PRAGMA integrity_check;`).Scan(&info.IntegrityCheck); err != nil {
+		b.fail("integrity check", err)
+	}
+
+	ddl, err := db.DumpSchema(ctx, s.db)
+	if err != nil {
+		b.fail("schema.sql", err)
+		return
+	}
+	b.files["schema.sql"] = []byte(fmt.Sprintf("-- schema version %d (dirty=%v)\n\n%s", info.SchemaVersion, info.SchemaDirty, ddl))
+}
+
+// addRowCounts counts every table DumpSchema would list. A table that cannot
+// be counted is reported as -1.
+func (s *Service) addRowCounts(ctx context.Context, b *builder) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT m.name
+FROM sqlite_master m
+WHERE m.type = 'table'
+  AND m.name NOT LIKE 'sqlite\_%' ESCAPE '\'
+  AND NOT EXISTS (
+      SELECT 1 FROM sqlite_master v
+      WHERE v.type = 'table' AND v.sql LIKE 'CREATE VIRTUAL TABLE%'
+        AND m.name LIKE v.name || '\_%' ESCAPE '\'
+  )
+ORDER BY m.name;
+`)
+	if err != nil {
+		b.fail("row_counts.json", err)
+		return
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			b.fail("row_counts.json", err)
+			return
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+
+	counts := map[string]int{}
+	for _, table := range tables {
+		var n int
+		if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM "`+table+`";`).Scan(&n); err != nil {
+			b.fail("count "+table, err)
+			n = -1
+		}
+		counts[table] = n
+	}
+	b.addJSON("row_counts.json", counts)
+}
+
+func (s *Service) addSync(ctx context.Context, b *builder) {
+	info := SyncInfo{RecentRuns: []db.SyncRun{}}
+	state, exists, err := db.LoadSyncState(ctx, s.db)
+	if err != nil {
+		b.fail("sync state", err)
+	} else if exists {
+		info.State = &SyncState{
+			LastSyncAt:       state.LastSyncAt.UTC().Format(time.RFC3339),
+			LastSyncCommit:   b.anon(state.LastSyncCommit),
+			LastSyncDirty:    state.LastSyncDirty,
+			IndexedFileCount: state.IndexedFileCount,
+			IndexFingerprint: state.IndexFingerprint,
+		}
+	}
+
+	runs, err := db.ListSyncRuns(ctx, s.db, time.Time{})
+	if err != nil {
+		b.fail("sync history", err)
+	}
+	if len(runs) > recentSyncRuns {
+		runs = runs[len(runs)-recentSyncRuns:]
+	}
+	for _, run := range runs {
+		run.Commit = b.anon(run.Commit)
+		info.RecentRuns = append(info.RecentRuns, run)
+	}
+	b.addJSON("sync_state.json", info)
+}
+
+var stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+// addQuery runs the reported query and its plan in a transaction that is
+// rolled back, so a query that writes leaves the database untouched.
+func (s *Service) addQuery(ctx context.Context, b *builder) {
+	query := strings.TrimSpace(b.opts.Query)
+	qc := QueryContext{SQL: query}
+	if b.opts.Anonymize {
+		qc.SQL = stringLiteral.ReplaceAllStringFunc(query, func(lit string) string {
+			return "'" + b.anon(strings.ReplaceAll(lit[1:len(lit)-1], "''", "'")) + "'"
+		})
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		b.fail("query.json", err)
+		return
+	}
+	defer tx.Rollback()
+
+	if plan, err := queryPlan(ctx, tx, query); err != nil {
+		qc.PlanError = err.Error()
+	} else {
+		qc.Plan = plan
+	}
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		qc.Error = err.Error()
+		b.addJSON("query.json", qc)
+		return
+	}
+	defer rows.Close()
+	if qc.Columns, err = rows.Columns(); err != nil {
+		qc.Error = err.Error()
+	}
+	for err == nil && rows.Next() {
+		if len(qc.Rows) == maxQueryRows {
+			qc.Truncated = true
+			break
+		}
+		values := make([]any, len(qc.Columns))
+		ptrs := make([]any, len(values))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err = rows.Scan(ptrs...); err != nil {
+			qc.Error = err.Error()
+			break
+		}
+		for i, v := range values {
+			switch t := v.(type) {
+			case []byte:
+				values[i] = b.anon(string(t))
+			case string:
+				values[i] = b.anon(t)
+			}
+		}
+		qc.Rows = append(qc.Rows, values)
+	}
+	if err == nil {
+		if err := rows.Err(); err != nil {
+			qc.Error = err.Error()
+		}
+	}
+	b.addJSON("query.json", qc)
+}
+
+func queryPlan(ctx context.Context, tx *sql.Tx, query string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, "EXPLAIN QUERY PLAN "+query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var plan []string
+	for rows.Next() {
+		var (
+			id, parent, notUsed int
+			detail              string
+		)
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			return nil, err
+		}
+		plan = append(plan, detail)
+	}
+	return plan, rows.Err()
+}
+
+func marshal(v any) ([]byte, error) {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal bundle file: %w", err)
+	}
+	return append(raw, '\n'), nil
+}
+
+func writeArchive(w io.Writer, names []string, files map[string][]byte, modTime time.Time) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		body := files[name]
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    "recon-debug/" + name,
+			Mode:    0o644,
+			Size:    int64(len(body)),
+			ModTime: modTime,
+		}); err != nil {
+			return fmt.Errorf("write bundle header %s: %w", name, err)
+		}
+		if _, err := tw.Write(body); err != nil {
+			return fmt.Errorf("write bundle file %s: %w", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("close bundle archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("compress bundle archive: %w", err)
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("write bundle: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func bundleTestDB(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/secret\n"), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	return conn, root
+}
+
+func readBundle(t *testing.T, raw []byte) map[string][]byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	files := map[string][]byte{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatalf("tar next: %v", err)
+		}
+		body, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("read %s: %v", hdr.Name, err)
+		}
+		files[strings.TrimPrefix(hdr.Name, "recon-debug/")] = body
+	}
+}
+
+func TestWriteBundle(t *testing.T) {
+	conn, root := bundleTestDB(t)
+	ctx := context.Background()
+	if err := db.AppendSyncRun(ctx, conn, db.SyncRun{SyncedAt: time.Now(), Mode: "full", Commit: "abc123"}); err != nil {
+		t.Fatalf("AppendSyncRun: %v", err)
+	}
+	if _, err := conn.Exec(`INSERT INTO decisions (title, reasoning, confidence, status, created_at, updated_at) VALUES ('Use cobra', 'r', 'high', 'active', 'now', 'now')`); err != nil {
+		t.Fatalf("insert decision: %v", err)
+	}
+
+	var buf bytes.Buffer
+	m, err := NewService(conn).Write(ctx, &buf, Options{
+		ModuleRoot: root,
+		Version:    "v1.2.3",
+		Commit:     "deadbeef",
+		Query:      `SELECT title FROM decisions WHERE title = 'Use cobra'`,
+	})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if len(m.Errors) != 0 {
+		t.Fatalf("manifest errors = %v", m.Errors)
+	}
+	expected, _ := db.LatestMigrationVersion()
+	if !m.Database.Initialized || m.Database.SchemaVersion != expected || m.Database.ExpectedSchemaVersion != expected || m.Database.IntegrityCheck != "ok" {
+		t.Fatalf("database info = %+v", m.Database)
+	}
+	if m.ModulePath != "example.com/secret" || m.Recon.Version != "v1.2.3" || m.SQLiteVersion == "" {
+		t.Fatalf("manifest = %+v", m)
+	}
+
+	files := readBundle(t, buf.Bytes())
+	for _, name := range []string{"manifest.json", "schema.sql", "row_counts.json", "sync_state.json", "query.json"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("bundle missing %s, has %v", name, m.Files)
+		}
+	}
+	if !strings.Contains(string(files["schema.sql"]), "CREATE TABLE decisions") {
+		t.Fatalf("schema.sql = %s", files["schema.sql"])
+	}
+
+	var counts map[string]int
+	if err := json.Unmarshal(files["row_counts.json"], &counts); err != nil {
+		t.Fatalf("row counts: %v", err)
+	}
+	if counts["decisions"] != 1 || counts["sync_history"] != 1 {
+		t.Fatalf("counts = %v", counts)
+	}
+	if _, ok := counts["search_index_data"]; ok {
+		t.Fatalf("FTS shadow table counted: %v", counts)
+	}
+
+	var qc QueryContext
+	if err := json.Unmarshal(files["query.json"], &qc); err != nil {
+		t.Fatalf("query.json: %v", err)
+	}
+	if qc.Error != "" || len(qc.Plan) == 0 || len(qc.Rows) != 1 || qc.Rows[0][0] != "Use cobra" {
+		t.Fatalf("query context = %+v", qc)
+	}
+}
+
+func TestWriteBundleAnonymizes(t *testing.T) {
+	conn, root := bundleTestDB(t)
+	ctx := context.Background()
+	if err := db.AppendSyncRun(ctx, conn, db.SyncRun{SyncedAt: time.Now(), Mode: "full", Commit: "abc123"}); err != nil {
+		t.Fatalf("AppendSyncRun: %v", err)
+	}
+	if _, err := conn.Exec(`INSERT INTO decisions (title, reasoning, confidence, status, created_at, updated_at) VALUES ('Use cobra', 'r', 'high', 'active', 'now', 'now')`); err != nil {
+		t.Fatalf("insert decision: %v", err)
+	}
+
+	var buf bytes.Buffer
+	m, err := NewService(conn).Write(ctx, &buf, Options{
+		ModuleRoot: root,
+		Query:      `SELECT title FROM decisions WHERE title = 'Use cobra'`,
+		Anonymize:  true,
+	})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if !m.Anonymized || !strings.HasPrefix(m.ModulePath, "anon-") {
+		t.Fatalf("manifest = %+v", m)
+	}
+	for name, body := range readBundle(t, buf.Bytes()) {
+		for _, secret := range []string{"example.com/secret", "abc123", "Use cobra"} {
+			if strings.Contains(string(body), secret) {
+				t.Fatalf("%s leaks %q:\n%s", name, secret, body)
+			}
+		}
+	}
+}
+
+func TestWriteBundleRecordsFailures(t *testing.T) {
+	conn, root := bundleTestDB(t)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	m, err := NewService(conn).Write(ctx, &buf, Options{ModuleRoot: root, Query: `SELECT nope FROM missing`})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	var qc QueryContext
+	if err := json.Unmarshal(readBundle(t, buf.Bytes())["query.json"], &qc); err != nil {
+		t.Fatalf("query.json: %v", err)
+	}
+	if !strings.Contains(qc.Error, "no such table") || qc.PlanError == "" {
+		t.Fatalf("query context = %+v", qc)
+	}
+
+	// Without a database the bundle still carries version and config details.
+	buf.Reset()
+	m, err = NewService(nil).Write(ctx, &buf, Options{ModuleRoot: root})
+	if err != nil {
+		t.Fatalf("Write without db: %v", err)
+	}
+	if m.Database.Initialized || m.Database.SchemaVersion != -1 || len(m.Errors) != 1 || !strings.Contains(m.Errors[0], "not initialized") {
+		t.Fatalf("manifest = %+v", m)
+	}
+	if files := readBundle(t, buf.Bytes()); len(files) != 1 {
+		t.Fatalf("files = %v", m.Files)
+	}
+}
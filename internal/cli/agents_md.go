@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/robertguss/recon/internal/install"
+	"github.com/robertguss/recon/internal/orient"
+	"github.com/spf13/cobra"
+)
+
+var (
+	buildAgentsPayload = func(ctx context.Context, conn *sql.DB, moduleRoot string) (orient.Payload, error) {
+		return orient.NewService(conn).Build(ctx, orient.BuildOptions{ModuleRoot: moduleRoot, MaxModules: 50, MaxDecisions: 20, Branch: currentBranch(ctx, moduleRoot)})
+	}
+	installAgentsSection = install.InstallAgentsSection
+)
+
+func newAgentsMDCommand(app *App) *cobra.Command {
+	var (
+		jsonOut  bool
+		stdout   bool
+		autoSync bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "agents-md",
+		Short: "Write a generated project map section into AGENTS.md",
+		Long: "Render entry points, the module map, active decisions and patterns, and common commands\n" +
+			"from the recon index into a \"## Recon Project Map\" section of AGENTS.md. Re-running replaces\n" +
+			"the section in place and leaves the rest of the file untouched, so it is safe to run from a hook.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			payload, err := buildAgentsPayload(cmd.Context(), conn, app.ModuleRoot)
+			if err == nil && autoSync && payload.Freshness.IsStale {
+				if err = runOrientSync(cmd.Context(), conn, app.ModuleRoot); err == nil {
+					payload, err = buildAgentsPayload(cmd.Context(), conn, app.ModuleRoot)
+				}
+			}
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+
+			section := orient.RenderAgentsMD(payload)
+			if stdout {
+				fmt.Print(section)
+				return nil
+			}
+
+			changed, err := installAgentsSection(app.ModuleRoot, []byte(section))
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+
+			if jsonOut {
+				return writeJSON(map[string]any{
+					"path":    "AGENTS.md",
+					"changed": changed,
+					"stale":   payload.Freshness.IsStale,
+				})
+			}
+			if changed {
+				fmt.Println("Updated AGENTS.md")
+			} else {
+				fmt.Println("AGENTS.md is up to date")
+			}
+			if payload.Freshness.IsStale {
+				fmt.Printf("Warning: index is stale (%s); run `recon sync` or pass --auto-sync\n", payload.Freshness.Reason)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().BoolVar(&stdout, "stdout", false, "Print the generated section instead of writing AGENTS.md")
+	cmd.Flags().BoolVar(&autoSync, "auto-sync", false, "Sync the index first if it is stale")
+	return cmd
+}
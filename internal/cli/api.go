@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robertguss/recon/internal/api"
+	"github.com/robertguss/recon/internal/index"
+	"github.com/spf13/cobra"
+)
+
+// apiExitCode is returned when api --diff finds a breaking change, so CI can
+// fail the build without parsing output.
+const apiExitCode = 5
+
+func newAPICommand(app *App) *cobra.Command {
+	var (
+		jsonOut     bool
+		packagePath string
+		internal    bool
+		diffRef     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "api",
+		Short: "List the exported API of each package, or diff it against a git ref",
+		Long: "List the exported funcs, methods, types, vars, consts, and struct fields of each\n" +
+			"package with their signatures, as indexed by `recon sync`. main packages are\n" +
+			"left out, and packages under an internal directory unless --internal is set.\n\n" +
+			"--diff compares the indexed API with the API at a git ref, parsed from the\n" +
+			"ref's files without touching the working tree. Removed and changed\n" +
+			"declarations are breaking; added ones are not. " +
+			fmt.Sprintf("Exits %d when a breaking\n", apiExitCode) +
+			"change is found, so CI can gate on it.",
+		Example: "  recon api\n  recon api --package pkg/client/...\n  recon api --diff v1.4.0\n" +
+			"  recon api --diff origin/main --json",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			opts := api.Options{Package: normalizeFindPath(packagePath), Internal: internal}
+			decls, err := api.NewService(conn).Indexed(cmd.Context())
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+			surface := api.Surface(decls, opts)
+
+			if diffRef == "" {
+				app.applyPathMode(&surface)
+				if jsonOut {
+					return writeJSON(surface)
+				}
+				fmt.Print(apiReport(surface))
+				return nil
+			}
+
+			baseDecls, err := refExportedDecls(cmd, app.ModuleRoot, diffRef)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"ref": diffRef})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+			diff := api.Compare(diffRef, api.Surface(baseDecls, opts), surface)
+			app.applyPathMode(&diff)
+			if jsonOut {
+				if err := writeJSON(diff); err != nil {
+					return err
+				}
+			} else {
+				fmt.Print(apiDiffReport(diff))
+			}
+			if diff.Breaking > 0 {
+				return ExitError{Code: apiExitCode}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().StringVar(&packagePath, "package", "", "Only include this package, or those below it with a trailing /...")
+	cmd.Flags().BoolVar(&internal, "internal", false, "Include packages under internal directories")
+	cmd.Flags().StringVar(&diffRef, "diff", "", "Compare with the API at this git ref and flag breaking changes")
+	return cmd
+}
+
+// refExportedDecls parses the Go files of the module at the git ref.
+func refExportedDecls(cmd *cobra.Command, moduleRoot, ref string) ([]index.ExportedDecl, error) {
+	modulePath, err := index.ModulePath(moduleRoot)
+	if err != nil {
+		return nil, err
+	}
+	files, err := index.GoFilesAtRef(cmd.Context(), moduleRoot, ref)
+	if err != nil {
+		return nil, err
+	}
+	return index.ExportedDecls(modulePath, files)
+}
+
+func apiReport(pkgs []api.Package) string {
+	if len(pkgs) == 0 {
+		return "No exported API found\n"
+	}
+	var b strings.Builder
+	count := 0
+	for _, p := range pkgs {
+		count += len(p.Decls)
+	}
+	fmt.Fprintf(&b, "Exported API (%d packages, %d declarations):\n", len(pkgs), count)
+	for _, p := range pkgs {
+		fmt.Fprintf(&b, "\n%s\n", p.Path)
+		for _, d := range p.Decls {
+			indent := "  "
+			if d.Kind == "field" {
+				indent = "    "
+			}
+			fmt.Fprintf(&b, "%s%s\n", indent, d.Declaration)
+		}
+	}
+	return b.String()
+}
+
+func apiDiffReport(diff api.Diff) string {
+	if len(diff.Changes) == 0 {
+		return fmt.Sprintf("No API changes since %s\n", diff.Ref)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "API changes since %s: %d breaking, %d added\n", diff.Ref, diff.Breaking, diff.Added)
+	pkg := ""
+	for _, c := range diff.Changes {
+		if c.Package != pkg {
+			pkg = c.Package
+			fmt.Fprintf(&b, "\n%s\n", pkg)
+		}
+		switch c.Change {
+		case "removed":
+			fmt.Fprintf(&b, "  - %s\n", c.Before)
+		case "changed":
+			fmt.Fprintf(&b, "  ~ %s\n      was: %s\n", c.After, c.Before)
+		default:
+			fmt.Fprintf(&b, "  + %s\n", c.After)
+		}
+	}
+	return b.String()
+}
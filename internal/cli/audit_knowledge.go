@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robertguss/recon/internal/audit"
+	"github.com/spf13/cobra"
+)
+
+func newAuditKnowledgeCommand(app *App) *cobra.Command {
+	var (
+		format  string
+		jsonOut bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "audit-knowledge",
+		Short: "Report decisions, patterns, and constraints that contradict each other",
+		Long: "Compare every pair of active decisions, patterns, and constraints and report those to\n" +
+			"review together: evidence checks that cannot both pass, such as a grep_pattern check\n" +
+			"requiring text that a grep_absent check forbids in the same files, or a file_exists\n" +
+			"check on a path a file_absent check forbids, and decisions or patterns whose titles\n" +
+			"overlap while their reasoning differs. Decisions scoped to different branches are\n" +
+			"not compared.",
+		Example: "  recon audit-knowledge\n  recon audit-knowledge --format markdown > audit.md\n  recon audit-knowledge --json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFormat, err := parseFormat(format, "text", "markdown")
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"format": strings.TrimSpace(format)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			report, err := audit.NewService(conn).Audit(cmd.Context())
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+
+			if jsonOut {
+				return writeJSON(report)
+			}
+			if outFormat == "markdown" {
+				fmt.Print(audit.RenderMarkdown(report))
+				return nil
+			}
+			fmt.Print(audit.RenderText(report))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text or markdown")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
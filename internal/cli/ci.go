@@ -0,0 +1,348 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/robertguss/recon/internal/archlint"
+	"github.com/robertguss/recon/internal/config"
+	"github.com/robertguss/recon/internal/index"
+	"github.com/robertguss/recon/internal/knowledge"
+	"github.com/spf13/cobra"
+)
+
+// ciExitCode is returned when a `recon ci` check whose policy is fail has
+// findings.
+const ciExitCode = 6
+
+// stepSummaryEnv names the file GitHub Actions renders as the job summary.
+const stepSummaryEnv = "GITHUB_STEP_SUMMARY"
+
+// Outcomes of a `recon ci` check and of the whole run.
+const (
+	ciPass    = "pass"
+	ciWarn    = "warn"
+	ciFail    = "fail"
+	ciSkipped = "skipped"
+)
+
+// policyCheck is one `recon ci` check: the policy it ran under, how many
+// findings it has, one line per finding, and the outcome.
+type policyCheck struct {
+	Name     string        `json:"name"`
+	Policy   config.Policy `json:"policy"`
+	Status   string        `json:"status"`
+	Findings int           `json:"findings"`
+	Summary  string        `json:"summary"`
+	Details  []string      `json:"details"`
+}
+
+type syncCounts struct {
+	IndexedFiles    int    `json:"indexed_files"`
+	IndexedSymbols  int    `json:"indexed_symbols"`
+	IndexedPackages int    `json:"indexed_packages"`
+	Commit          string `json:"commit"`
+	Dirty           bool   `json:"dirty"`
+}
+
+// ciPayload is the summary of a `recon ci` run. Evidence, Drift, and
+// Architecture are omitted when their check is off.
+type ciPayload struct {
+	Status        string                      `json:"status"`
+	Sync          syncCounts                  `json:"sync"`
+	Checks        []policyCheck               `json:"checks"`
+	Evidence      *knowledge.RecheckResult    `json:"evidence,omitempty"`
+	Drift         []knowledge.DriftedEvidence `json:"drift,omitempty"`
+	Architecture  *archlint.Result            `json:"architecture,omitempty"`
+	DanglingEdges []index.DanglingEdge        `json:"dangling_edges,omitempty"`
+}
+
+func newCICommand(app *App) *cobra.Command {
+	var (
+		jsonOut       bool
+		drift         string
+		arch          string
+		dangling      string
+		warnOnly      bool
+		includeTests  bool
+		stepSummaryTo string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ci",
+		Short: "Sync, verify evidence, and check the architecture in one pass for CI",
+		Long: "Sync the index, re-check the evidence of all active knowledge as recon verify does,\n" +
+			"lint the import graph as recon lint-arch does, and look for edges from knowledge to\n" +
+			"symbols that no longer exist. Each check runs under a policy: fail the run, warn and\n" +
+			"pass, or off. Policies come from the ci section of .recon/config.json, default to\n" +
+			"failing on drift and architecture findings and warning on dangling edges, and can be\n" +
+			"overridden with flags; --warn-only turns every failure into a warning.\n\n" +
+			fmt.Sprintf("Exits %d when a check with the fail policy has findings. A markdown summary is\n", ciExitCode) +
+			"appended to the file named by --step-summary, or by $" + stepSummaryEnv + " when the\n" +
+			"flag is unset, so GitHub Actions shows it on the run page. Run recon init --profile ci\n" +
+			"first in a fresh checkout.",
+		Example: "  recon init --profile ci && recon ci\n  recon ci --json --dangling-edges fail\n  recon ci --warn-only",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fail := func(code string, err error, details map[string]any) error {
+				if jsonOut {
+					_ = writeJSONError(code, err.Error(), details)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			cfg, err := loadConfig(app.ModuleRoot)
+			if err != nil {
+				return fail("invalid_input", err, map[string]any{"path": config.Path(app.ModuleRoot)})
+			}
+			policies := map[string]config.Policy{}
+			for _, p := range []struct {
+				name, flag string
+				value      string
+				configured config.Policy
+				fallback   config.Policy
+			}{
+				{"drift", "drift", drift, cfg.CI.Drift, config.PolicyFail},
+				{"architecture", "arch", arch, cfg.CI.Architecture, config.PolicyFail},
+				{"dangling_edges", "dangling-edges", dangling, cfg.CI.DanglingEdges, config.PolicyWarn},
+			} {
+				policy := config.Policy(strings.ToLower(strings.TrimSpace(p.value)))
+				if policy == "" {
+					policy = p.configured
+				}
+				if policy == "" {
+					policy = p.fallback
+				}
+				if !policy.Valid() {
+					return fail("invalid_input", fmt.Errorf("--%s must be one of: fail, warn, off", p.flag), map[string]any{"flag": p.flag, "value": p.value})
+				}
+				if warnOnly && policy == config.PolicyFail {
+					policy = config.PolicyWarn
+				}
+				policies[p.name] = policy
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			lock, err := index.LockSync(cmd.Context(), app.ModuleRoot, true)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer lock.Unlock()
+
+			synced, err := runSync(cmd.Context(), conn, app.ModuleRoot, index.SyncOptions{})
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			if cfg.Knowledge.Files {
+				if _, err := runReconcile(cmd.Context(), conn, app.ModuleRoot); err != nil {
+					return fail("internal_error", err, nil)
+				}
+			}
+			payload := ciPayload{Sync: syncCounts{
+				IndexedFiles:    synced.IndexedFiles,
+				IndexedSymbols:  synced.IndexedSymbols,
+				IndexedPackages: synced.IndexedPackages,
+				Commit:          synced.Commit,
+				Dirty:           synced.Dirty,
+			}}
+
+			driftCheck := policyCheck{Name: "drift", Policy: policies["drift"]}
+			if driftCheck.Policy != config.PolicyOff {
+				svc := knowledge.NewService(conn)
+				verified, err := svc.VerifyEvidence(cmd.Context(), app.ModuleRoot)
+				if err != nil {
+					return fail("internal_error", err, nil)
+				}
+				drifted, err := svc.DriftedEvidence(cmd.Context())
+				if err != nil {
+					return fail("internal_error", err, nil)
+				}
+				payload.Evidence, payload.Drift = &verified, drifted
+				for _, d := range drifted {
+					line := fmt.Sprintf("%s #%d %q: %s [%s] %s", d.EntityType, d.EntityID, d.Title, d.Drift, d.CheckType, d.Summary)
+					if d.Details != "" {
+						line += " (" + d.Details + ")"
+					}
+					driftCheck.Details = append(driftCheck.Details, line)
+				}
+				driftCheck.Summary = fmt.Sprintf("%d of %d evidence checks drifting or broken", len(drifted), verified.Checked)
+			}
+
+			archCheck := policyCheck{Name: "architecture", Policy: policies["architecture"]}
+			if archCheck.Policy != config.PolicyOff {
+				result, err := archlint.NewService(conn).Check(cmd.Context(), archOptions(cfg, includeTests))
+				if err != nil {
+					return fail("internal_error", err, nil)
+				}
+				app.applyPathMode(&result)
+				payload.Architecture = &result
+				for _, c := range result.Cycles {
+					archCheck.Details = append(archCheck.Details, "import cycle "+strings.Join(c.Packages, " -> ")+" -> "+c.Packages[0])
+				}
+				for _, v := range result.Violations {
+					line := fmt.Sprintf("%s -> %s [%s]", v.From, v.To, v.Rule.Source)
+					if v.Rule.Reason != "" {
+						line += " " + v.Rule.Reason
+					}
+					archCheck.Details = append(archCheck.Details, line+": "+strings.Join(v.Files, ", "))
+				}
+				archCheck.Summary = fmt.Sprintf("%d import cycles, %d layering violations across %d packages",
+					len(result.Cycles), len(result.Violations), result.Packages)
+			}
+
+			danglingCheck := policyCheck{Name: "dangling_edges", Policy: policies["dangling_edges"]}
+			if danglingCheck.Policy != config.PolicyOff {
+				edges, err := loadDanglingEdges(cmd.Context(), conn)
+				if err != nil {
+					return fail("internal_error", err, nil)
+				}
+				payload.DanglingEdges = edges
+				for _, e := range edges {
+					danglingCheck.Details = append(danglingCheck.Details,
+						fmt.Sprintf("edge %d: %s #%d %s %s", e.ID, e.FromType, e.FromID, e.Relation, e.ToRef))
+				}
+				danglingCheck.Summary = fmt.Sprintf("%d edges from knowledge to symbols no longer in the index", len(edges))
+			}
+
+			payload.Status = ciPass
+			for _, c := range []policyCheck{driftCheck, archCheck, danglingCheck} {
+				c.Findings = len(c.Details)
+				if c.Details == nil {
+					c.Details = []string{}
+				}
+				switch {
+				case c.Policy == config.PolicyOff:
+					c.Status, c.Summary = ciSkipped, "off"
+				case c.Findings == 0:
+					c.Status = ciPass
+				case c.Policy == config.PolicyFail:
+					c.Status, payload.Status = ciFail, ciFail
+				default:
+					c.Status = ciWarn
+					if payload.Status == ciPass {
+						payload.Status = ciWarn
+					}
+				}
+				payload.Checks = append(payload.Checks, c)
+			}
+
+			if path := stepSummaryTo; path != "" || os.Getenv(stepSummaryEnv) != "" {
+				if path == "" {
+					path = os.Getenv(stepSummaryEnv)
+				}
+				if err := appendStepSummary(path, ciMarkdown(payload)); err != nil {
+					fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+				}
+			}
+			if jsonOut {
+				if err := writeJSON(payload); err != nil {
+					return err
+				}
+			} else {
+				fmt.Print(ciReport(payload))
+			}
+			if payload.Status == ciFail {
+				return ExitError{Code: ciExitCode}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().StringVar(&drift, "drift", "", "Policy for drifting or broken evidence: fail, warn, or off (default fail)")
+	cmd.Flags().StringVar(&arch, "arch", "", "Policy for import cycles and layering violations: fail, warn, or off (default fail)")
+	cmd.Flags().StringVar(&dangling, "dangling-edges", "", "Policy for edges to symbols no longer indexed: fail, warn, or off (default warn)")
+	cmd.Flags().BoolVar(&warnOnly, "warn-only", false, "Report failures as warnings and exit 0")
+	cmd.Flags().BoolVar(&includeTests, "include-tests", false, "Include imports from _test.go files in the architecture check")
+	cmd.Flags().StringVar(&stepSummaryTo, "step-summary", "", "Append a markdown summary to this file (default $"+stepSummaryEnv+")")
+	return cmd
+}
+
+// loadDanglingEdges lists the edges from active knowledge to symbols the
+// index no longer has.
+func loadDanglingEdges(ctx context.Context, conn *sql.DB) ([]index.DanglingEdge, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT e.id, e.from_type, e.from_id, e.relation, e.to_ref "+danglingEdgesFrom+" ORDER BY e.id")
+	if err != nil {
+		return nil, fmt.Errorf("query dangling edges: %w", err)
+	}
+	defer rows.Close()
+	var edges []index.DanglingEdge
+	for rows.Next() {
+		var e index.DanglingEdge
+		if err := rows.Scan(&e.ID, &e.FromType, &e.FromID, &e.Relation, &e.ToRef); err != nil {
+			return nil, fmt.Errorf("scan dangling edge: %w", err)
+		}
+		edges = append(edges, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate dangling edges: %w", err)
+	}
+	return edges, nil
+}
+
+func ciReport(p ciPayload) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Synced %d files, %d symbols across %d packages\n", p.Sync.IndexedFiles, p.Sync.IndexedSymbols, p.Sync.IndexedPackages)
+	for _, c := range p.Checks {
+		fmt.Fprintf(&b, "%-4s %s (%s): %s\n", strings.ToUpper(c.Status), c.Name, c.Policy, c.Summary)
+		for _, d := range c.Details {
+			fmt.Fprintf(&b, "  - %s\n", d)
+		}
+	}
+	fmt.Fprintf(&b, "Result: %s\n", p.Status)
+	return b.String()
+}
+
+// ciMarkdown renders the run as GitHub-flavored markdown for the job summary.
+func ciMarkdown(p ciPayload) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## recon ci: %s\n\n", p.Status)
+	fmt.Fprintf(&b, "Indexed %d files, %d symbols across %d packages", p.Sync.IndexedFiles, p.Sync.IndexedSymbols, p.Sync.IndexedPackages)
+	if p.Sync.Commit != "" {
+		fmt.Fprintf(&b, " at `%s`", p.Sync.Commit)
+	}
+	b.WriteString(".\n\n| Check | Policy | Findings | Result |\n| ----- | ------ | -------- | ------ |\n")
+	for _, c := range p.Checks {
+		fmt.Fprintf(&b, "| %s | %s | %d | %s |\n", c.Name, c.Policy, c.Findings, c.Status)
+	}
+	for _, c := range p.Checks {
+		if len(c.Details) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", c.Name)
+		for _, d := range c.Details {
+			fmt.Fprintf(&b, "- %s\n", d)
+		}
+	}
+	return b.String()
+}
+
+func appendStepSummary(path, markdown string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open step summary: %w", err)
+	}
+	if _, err := f.WriteString(markdown + "\n"); err != nil {
+		f.Close()
+		return fmt.Errorf("write step summary: %w", err)
+	}
+	return f.Close()
+}
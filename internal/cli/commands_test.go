@@ -7,15 +7,30 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/robertguss/recon/internal/archlint"
+	"github.com/robertguss/recon/internal/config"
+	"github.com/robertguss/recon/internal/constraint"
 	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/diagnostics"
+	"github.com/robertguss/recon/internal/digest"
+	"github.com/robertguss/recon/internal/doctor"
+	"github.com/robertguss/recon/internal/guard"
+	"github.com/robertguss/recon/internal/history"
 	"github.com/robertguss/recon/internal/index"
+	"github.com/robertguss/recon/internal/knowledge"
+	"github.com/robertguss/recon/internal/logging"
 	"github.com/robertguss/recon/internal/orient"
+	"github.com/robertguss/recon/internal/sarif"
+	"github.com/robertguss/recon/internal/snapshot"
 	"github.com/spf13/cobra"
 )
 
@@ -239,17 +254,24 @@ func saveAndMockInstallFuncs(t *testing.T) {
 	origSkill := installSkill
 	origSettings := installSettings
 	origClaude := installClaudeSection
+	origAgentRules := installAgentRules
+	origCheckBinary := checkBinary
 	t.Cleanup(func() {
 		installHook = origHook
 		installSkill = origSkill
 		installSettings = origSettings
 		installClaudeSection = origClaude
+		installAgentRules = origAgentRules
+		checkBinary = origCheckBinary
 	})
 	noop := func(string) error { return nil }
 	installHook = noop
 	installSkill = noop
 	installSettings = noop
 	installClaudeSection = noop
+	checkBinary = func(context.Context, doctor.Options) doctor.Check {
+		return doctor.Check{Name: "binary", Status: doctor.StatusOK}
+	}
 }
 
 func TestInitCommandErrorBranches(t *testing.T) {
@@ -361,6 +383,113 @@ func TestInitInstallErrorPaths(t *testing.T) {
 	}
 }
 
+func TestInitAgentIntegrations(t *testing.T) {
+	saveAndMockInstallFuncs(t)
+	var claudeCalls int
+	installHook = func(string) error { claudeCalls++; return nil }
+
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	out, _, err := runCommandWithCapture(t, newInitCommand(app), []string{"--agent", "Cursor,copilot", "--agent", "cursor", "--json"})
+	if err != nil {
+		t.Fatalf("init --agent: %v", err)
+	}
+	var payload struct {
+		ClaudeCode bool              `json:"claude_code"`
+		Agents     []string          `json:"agents"`
+		AgentFiles map[string]string `json:"agent_files"`
+	}
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out)
+	}
+	if payload.ClaudeCode || claudeCalls != 0 {
+		t.Fatalf("claude integration should not be installed: %+v calls=%d", payload, claudeCalls)
+	}
+	if strings.Join(payload.Agents, ",") != "cursor,copilot" {
+		t.Fatalf("expected de-duplicated agents, got %v", payload.Agents)
+	}
+	for _, rel := range []string{".cursor/rules/recon.mdc", ".github/copilot-instructions.md"} {
+		if _, err := os.Stat(filepath.Join(root, rel)); err != nil {
+			t.Fatalf("expected %s: %v", rel, err)
+		}
+	}
+
+	root2 := setupModuleRoot(t)
+	out, _, err = runCommandWithCapture(t, newInitCommand(&App{Context: context.Background(), ModuleRoot: root2}), []string{"--agent", "claude,windsurf"})
+	if err != nil {
+		t.Fatalf("init claude+windsurf: %v", err)
+	}
+	if claudeCalls != 1 || !strings.Contains(out, "Claude Code integration installed") || !strings.Contains(out, "Windsurf integration installed (.windsurf/rules/recon.md)") {
+		t.Fatalf("unexpected output (claude calls=%d):\n%s", claudeCalls, out)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newInitCommand(&App{Context: context.Background(), ModuleRoot: setupModuleRoot(t)}), []string{"--agent", "vim"}); err == nil || !strings.Contains(err.Error(), `unsupported agent "vim"`) {
+		t.Fatalf("expected unsupported agent error, got %v", err)
+	}
+
+	installAgentRules = func(string, string) (string, error) { return "", errors.New("disk full") }
+	if _, _, err := runCommandWithCapture(t, newInitCommand(&App{Context: context.Background(), ModuleRoot: setupModuleRoot(t)}), []string{"--agent", "gemini"}); err == nil || !strings.Contains(err.Error(), "install gemini rules") {
+		t.Fatalf("expected agent rules error, got %v", err)
+	}
+}
+
+func TestInitProfile(t *testing.T) {
+	saveAndMockInstallFuncs(t)
+	var claudeCalls int
+	installHook = func(string) error { claudeCalls++; return nil }
+
+	// ci: no agent files, WAL on, and a re-run needs no --force.
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	for i := 0; i < 2; i++ {
+		out, _, err := runCommandWithCapture(t, newInitCommand(app), []string{"--profile", "ci", "--json"})
+		if err != nil {
+			t.Fatalf("init --profile ci (run %d): %v", i+1, err)
+		}
+		var payload struct {
+			Profile string   `json:"profile"`
+			Agents  []string `json:"agents"`
+			WAL     bool     `json:"wal"`
+		}
+		if err := json.Unmarshal([]byte(out), &payload); err != nil {
+			t.Fatalf("unmarshal: %v\n%s", err, out)
+		}
+		if payload.Profile != "ci" || len(payload.Agents) != 0 || !payload.WAL || claudeCalls != 0 {
+			t.Fatalf("unexpected ci payload: %+v (claude calls=%d)", payload, claudeCalls)
+		}
+	}
+	ignore, err := os.ReadFile(filepath.Join(root, ".gitignore"))
+	if err != nil || !strings.Contains(string(ignore), ".recon/recon.db-wal\n") {
+		t.Fatalf("expected WAL files ignored, got %q (%v)", ignore, err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	var mode string
+	if err := conn.QueryRow("PRAGMA journal_mode;").Scan(&mode); err != nil || mode != "wal" {
+		t.Fatalf("expected wal journal mode, got %q (%v)", mode, err)
+	}
+	_ = conn.Close()
+
+	// Explicit flags override the preset.
+	out, _, err := runCommandWithCapture(t, newInitCommand(&App{Context: context.Background(), ModuleRoot: setupModuleRoot(t)}), []string{"--profile", "agent", "--wal=false"})
+	if err != nil || claudeCalls != 1 || strings.Contains(out, "Write-ahead logging") || !strings.Contains(out, "(profile agent)") {
+		t.Fatalf("init --profile agent --wal=false: out=%q err=%v calls=%d", out, err, claudeCalls)
+	}
+	out, _, err = runCommandWithCapture(t, newInitCommand(&App{Context: context.Background(), ModuleRoot: setupModuleRoot(t)}), []string{"--profile", "full"})
+	if err != nil || claudeCalls != 2 || !strings.Contains(out, "Windsurf integration installed") || !strings.Contains(out, "Write-ahead logging enabled") {
+		t.Fatalf("init --profile full: out=%q err=%v calls=%d", out, err, claudeCalls)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newInitCommand(&App{Context: context.Background(), ModuleRoot: setupModuleRoot(t)}), []string{"--profile", "laptop"}); err == nil || !strings.Contains(err.Error(), `unsupported profile "laptop"`) {
+		t.Fatalf("expected unsupported profile error, got %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(&App{Context: context.Background(), ModuleRoot: setupModuleRoot(t)}), []string{"--agent", "none,cursor"}); err == nil || !strings.Contains(err.Error(), "--agent none cannot be combined") {
+		t.Fatalf("expected none+cursor error, got %v", err)
+	}
+}
+
 func TestCommandErrorBranches(t *testing.T) {
 	root := setupModuleRoot(t)
 	app := &App{Context: context.Background(), ModuleRoot: root}
@@ -407,13 +536,13 @@ func TestCommandErrorBranches(t *testing.T) {
 	}
 
 	// Sync command service error and commit print branch.
-	runSync = func(context.Context, *sql.DB, string) (index.SyncResult, error) {
+	runSync = func(context.Context, *sql.DB, string, index.SyncOptions) (index.SyncResult, error) {
 		return index.SyncResult{}, errors.New("sync fail")
 	}
 	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err == nil {
 		t.Fatal("expected sync service error branch")
 	}
-	runSync = func(context.Context, *sql.DB, string) (index.SyncResult, error) {
+	runSync = func(context.Context, *sql.DB, string, index.SyncOptions) (index.SyncResult, error) {
 		return index.SyncResult{IndexedFiles: 1, IndexedSymbols: 2, IndexedPackages: 1, Fingerprint: "f", Commit: "abc", Dirty: true, SyncedAt: time.Now()}, nil
 	}
 	out, _, err := runCommandWithCapture(t, newSyncCommand(app), nil)
@@ -475,9 +604,19 @@ func TestCommandErrorBranches(t *testing.T) {
 		t.Fatalf("decide root4: %v", err)
 	}
 	out, _, err = runCommandWithCapture(t, newRecallCommand(app4), []string{"Use"})
-	if err != nil || !strings.Contains(out, "[decision] #") {
+	if err != nil || !strings.Contains(out, "[decision] #") || !strings.Contains(out, " rank=") || !strings.Contains(out, "  > **Use** X") {
 		t.Fatalf("expected recall item text output, out=%q err=%v", out, err)
 	}
+	out, _, err = runCommandWithCapture(t, newRecallCommand(app4), []string{"Use", "--min-rank", "1"})
+	if err != nil || !strings.Contains(out, " rank=1.00") {
+		t.Fatalf("expected the best match to pass --min-rank 1, out=%q err=%v", out, err)
+	}
+	for _, bad := range []string{"-1", "1.5"} {
+		out, _, err = runCommandWithCapture(t, newRecallCommand(app4), []string{"Use", "--min-rank", bad, "--json"})
+		if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+			t.Fatalf("expected invalid_input for --min-rank %s, out=%q err=%v", bad, out, err)
+		}
+	}
 
 	// decide promoted text branch.
 	out, _, err = runCommandWithCapture(t, newDecideCommand(app4), []string{"Use Y", "--reasoning", "r", "--evidence-summary", "go.mod exists", "--check-type", "file_exists", "--check-spec", `{"path":"go.mod"}`})
@@ -487,7 +626,7 @@ func TestCommandErrorBranches(t *testing.T) {
 
 	// Orient command explicit build/sync error branches.
 	call := 0
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		call++
 		if call == 1 {
 			return orient.Payload{Freshness: orient.Freshness{IsStale: true, Reason: "stale"}}, nil
@@ -501,7 +640,7 @@ func TestCommandErrorBranches(t *testing.T) {
 		t.Fatal("expected second build error branch")
 	}
 
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		return orient.Payload{Freshness: orient.Freshness{IsStale: true, Reason: "stale"}}, nil
 	}
 	runOrientSync = func(context.Context, *sql.DB, string) error { return errors.New("sync fail") }
@@ -509,7 +648,7 @@ func TestCommandErrorBranches(t *testing.T) {
 		t.Fatal("expected orient sync error branch")
 	}
 
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		return orient.Payload{}, errors.New("build fail first")
 	}
 	if _, _, err := runCommandWithCapture(t, newOrientCommand(app4), nil); err == nil {
@@ -618,7 +757,7 @@ func TestOrientCommandMachineFlags(t *testing.T) {
 
 	buildCalls := 0
 	syncCalls := 0
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		buildCalls++
 		return orient.Payload{}, nil
 	}
@@ -635,7 +774,7 @@ func TestOrientCommandMachineFlags(t *testing.T) {
 
 	buildCalls = 0
 	syncCalls = 0
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		buildCalls++
 		if buildCalls == 1 {
 			return orient.Payload{Freshness: orient.Freshness{IsStale: true, Reason: "stale"}}, nil
@@ -653,7 +792,7 @@ func TestOrientCommandMachineFlags(t *testing.T) {
 		t.Fatalf("expected one auto-sync and rebuild, syncCalls=%d buildCalls=%d", syncCalls, buildCalls)
 	}
 
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		return orient.Payload{}, nil
 	}
 	runOrientSync = func(context.Context, *sql.DB, string) error { return errors.New("sync now fail") }
@@ -661,7 +800,7 @@ func TestOrientCommandMachineFlags(t *testing.T) {
 		t.Fatal("expected orient --sync error")
 	}
 
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		return orient.Payload{Freshness: orient.Freshness{IsStale: true, Reason: "stale"}}, nil
 	}
 	runOrientSync = func(context.Context, *sql.DB, string) error { return errors.New("auto sync fail") }
@@ -670,7 +809,7 @@ func TestOrientCommandMachineFlags(t *testing.T) {
 	}
 
 	buildCalls = 0
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		buildCalls++
 		if buildCalls == 1 {
 			return orient.Payload{Freshness: orient.Freshness{IsStale: true, Reason: "stale"}}, nil
@@ -682,7 +821,7 @@ func TestOrientCommandMachineFlags(t *testing.T) {
 		t.Fatal("expected orient --auto-sync rebuild error")
 	}
 
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		return orient.Payload{Freshness: orient.Freshness{IsStale: true, Reason: "stale"}}, nil
 	}
 	runOrientSync = func(context.Context, *sql.DB, string) error { return nil }
@@ -695,6 +834,134 @@ func TestOrientCommandMachineFlags(t *testing.T) {
 	}
 }
 
+func TestOrientAutoSyncPolicy(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	origBuildOrient := buildOrient
+	origRunOrientSync := runOrientSync
+	origInteractive := isInteractive
+	defer func() {
+		buildOrient = origBuildOrient
+		runOrientSync = origRunOrientSync
+		isInteractive = origInteractive
+	}()
+	isInteractive = func() bool { return false }
+
+	syncCalls := 0
+	runOrientSync = func(context.Context, *sql.DB, string) error {
+		syncCalls++
+		return nil
+	}
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
+		return orient.Payload{Freshness: orient.Freshness{IsStale: syncCalls == 0, Reason: "stale"}}, nil
+	}
+	writeConfig := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, ".recon", "config.json"), []byte(body), 0o644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+	}
+
+	// No config: --session-start defaults to always.
+	if _, _, err := runCommandWithCapture(t, newOrientCommand(app), []string{"--session-start"}); err != nil {
+		t.Fatalf("orient --session-start: %v", err)
+	}
+	if syncCalls != 1 {
+		t.Fatalf("expected session-start to auto-sync, syncCalls=%d", syncCalls)
+	}
+
+	// Policy never: warn only, even for the hook.
+	syncCalls = 0
+	writeConfig(`{"freshness":{"auto_sync":"never"}}`)
+	out, stderr, err := runCommandWithCapture(t, newOrientCommand(app), []string{"--session-start"})
+	if err != nil {
+		t.Fatalf("orient --session-start with never: %v", err)
+	}
+	if syncCalls != 0 || !strings.Contains(stderr, "stale context") || !strings.Contains(out, `"is_stale": true`) {
+		t.Fatalf("expected warning without sync, syncCalls=%d out=%q stderr=%q", syncCalls, out, stderr)
+	}
+
+	// --auto-sync still forces a sync.
+	if _, _, err := runCommandWithCapture(t, newOrientCommand(app), []string{"--auto-sync", "--json"}); err != nil {
+		t.Fatalf("orient --auto-sync: %v", err)
+	}
+	if syncCalls != 1 {
+		t.Fatalf("expected --auto-sync to override policy, syncCalls=%d", syncCalls)
+	}
+
+	// Policy always applies to plain orient too.
+	syncCalls = 0
+	writeConfig(`{"freshness":{"auto_sync":"always"}}`)
+	if _, _, err := runCommandWithCapture(t, newOrientCommand(app), []string{"--json"}); err != nil {
+		t.Fatalf("orient --json with always: %v", err)
+	}
+	if syncCalls != 1 {
+		t.Fatalf("expected configured always to sync, syncCalls=%d", syncCalls)
+	}
+
+	writeConfig(`{"freshness":{"auto_sync":"sometimes"}}`)
+	out, _, err = runCommandWithCapture(t, newOrientCommand(app), []string{"--json"})
+	if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("expected invalid_input for bad config, out=%q err=%v", out, err)
+	}
+	if _, _, err = runCommandWithCapture(t, newOrientCommand(app), nil); err == nil || !strings.Contains(err.Error(), "freshness.auto_sync") {
+		t.Fatalf("expected text config error, got %v", err)
+	}
+}
+
+func TestResolveAutoSync(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	recent := orient.Freshness{IsStale: true, LastSyncAt: now.Add(-time.Hour).Format(time.RFC3339)}
+	old := orient.Freshness{IsStale: true, LastSyncAt: now.Add(-48 * time.Hour).Format(time.RFC3339)}
+	never := orient.Freshness{IsStale: true, Reason: "never_synced"}
+	withLimit := func(policy config.AutoSyncPolicy) config.Freshness {
+		t.Helper()
+		root := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(root, ".recon"), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		body := fmt.Sprintf(`{"freshness":{"auto_sync":%q,"max_staleness":"24h"}}`, policy)
+		if err := os.WriteFile(config.Path(root), []byte(body), 0o644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		cfg, err := config.Load(root)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		return cfg.Freshness
+	}
+
+	for _, tc := range []struct {
+		name         string
+		cfg          config.Freshness
+		fresh        orient.Freshness
+		autoSync     bool
+		sessionStart bool
+		wantPolicy   config.AutoSyncPolicy
+		wantAct      bool
+	}{
+		{"default orient prompts", config.Freshness{}, recent, false, false, config.AutoSyncPrompt, true},
+		{"default session start syncs", config.Freshness{}, recent, false, true, config.AutoSyncAlways, true},
+		{"config wins over session default", config.Freshness{AutoSync: config.AutoSyncNever}, old, false, true, config.AutoSyncNever, false},
+		{"flag wins over config", config.Freshness{AutoSync: config.AutoSyncNever}, recent, true, false, config.AutoSyncAlways, true},
+		{"below threshold only warns", withLimit(config.AutoSyncAlways), recent, false, false, config.AutoSyncAlways, false},
+		{"beyond threshold acts", withLimit(config.AutoSyncAlways), old, false, false, config.AutoSyncAlways, true},
+		{"never synced ignores threshold", withLimit(config.AutoSyncPrompt), never, false, false, config.AutoSyncPrompt, true},
+		{"flag ignores threshold", withLimit(config.AutoSyncNever), recent, true, false, config.AutoSyncAlways, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, act := resolveAutoSync(tc.cfg, tc.fresh, tc.autoSync, tc.sessionStart, now)
+			if policy != tc.wantPolicy || act != tc.wantAct {
+				t.Fatalf("resolveAutoSync = (%q, %v), want (%q, %v)", policy, act, tc.wantPolicy, tc.wantAct)
+			}
+		})
+	}
+}
+
 func TestOrientJSONEmptyLists(t *testing.T) {
 	root := setupModuleRoot(t)
 	app := &App{Context: context.Background(), ModuleRoot: root}
@@ -847,6 +1114,145 @@ func lineThree() {}
 	}
 }
 
+func TestFindFuzzyFlag(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"alpa", "--json"})
+	if err == nil || !strings.Contains(out, `"suggestions": [`) || !strings.Contains(out, `"Alpha"`) {
+		t.Fatalf("expected not_found with a fuzzy suggestion, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"alpa", "--fuzzy", "--json"})
+	if err != nil || !strings.Contains(out, `"fuzzy_query": "alpa"`) || !strings.Contains(out, `"name": "Alpha"`) {
+		t.Fatalf("find --fuzzy --json failed, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"ALPHA", "--fuzzy", "--no-body"})
+	if err != nil || !strings.Contains(out, `No symbol "ALPHA"; showing the closest match.`) || !strings.Contains(out, "func Alpha (main.go)") {
+		t.Fatalf("find --fuzzy text failed, out=%q err=%v", out, err)
+	}
+}
+
+func TestFindUsagesFlag(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--package", "pkg1", "--usages", "--no-body"})
+	if err != nil || !strings.Contains(out, "Usages (1):\n- main.go:3 in Alpha: func Alpha() { pkg1.Ambig() }") {
+		t.Fatalf("find --usages failed, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Alpha", "--usages", "--json"})
+	if err != nil || strings.Contains(out, `"usages"`) {
+		t.Fatalf("find --usages --json without callers, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--package", "pkg1", "--usages", "--json"})
+	if err != nil || !strings.Contains(out, `"line": 3`) || !strings.Contains(out, `"caller": "Alpha"`) {
+		t.Fatalf("find --usages --json failed, out=%q err=%v", out, err)
+	}
+}
+
+func TestFindTagsFlag(t *testing.T) {
+	root := setupModuleRoot(t)
+	for name, src := range map[string]string{
+		"open_linux.go":   "package main\n\nfunc Open() {}\n",
+		"open_windows.go": "package main\n\nfunc Open() {}\n",
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"Open", "--tags", "windows,amd64", "--no-body"})
+	if err != nil || !strings.Contains(out, "func Open (open_windows.go)") || !strings.Contains(out, "Platform: windows") {
+		t.Fatalf("find --tags windows, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"--tags", "plan9", "--json"})
+	if err != nil || !strings.Contains(out, `"total": 4`) || strings.Contains(out, `"Open"`) {
+		t.Fatalf("find list --tags plan9 should drop both Open variants, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Open", "--tags", "darwin", "--json"})
+	if err == nil || !strings.Contains(out, `"tags"`) {
+		t.Fatalf("find --tags darwin should report the filter, out=%q err=%v", out, err)
+	}
+}
+
+func TestFindUnsafeFlag(t *testing.T) {
+	root := setupModuleRoot(t)
+	if err := os.WriteFile(filepath.Join(root, "pkg2", "ptr.go"), []byte("package pkg2\n\nimport \"unsafe\"\n\nfunc Size() uintptr { return unsafe.Sizeof(0) }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"--kind", "func", "--unsafe"})
+	if err != nil || !strings.Contains(out, "Symbols (1 of 1)") || !strings.Contains(out, "func Size (pkg2/ptr.go:5-5)") ||
+		!strings.Contains(out, `handle with care: imports "unsafe"`) {
+		t.Fatalf("find --unsafe list, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Size", "--no-body"})
+	if err != nil || !strings.Contains(out, `Handle with care: imports "unsafe"`) {
+		t.Fatalf("find Size should flag unsafe, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Alpha", "--unsafe", "--json"})
+	if err == nil || !strings.Contains(out, `"unsafe": true`) {
+		t.Fatalf("find Alpha --unsafe should report the filter, out=%q err=%v", out, err)
+	}
+}
+
+func TestFindEmbeddedAssets(t *testing.T) {
+	root := setupModuleRoot(t)
+	if err := os.WriteFile(filepath.Join(root, "pkg1", "assets.go"), []byte("package pkg1\n\nimport \"embed\"\n\n//go:embed schema.sql seeds/*\nvar files embed.FS\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "pkg1", "schema.sql"), []byte("CREATE TABLE t (id INTEGER);\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	out, _, err := runCommandWithCapture(t, newSyncCommand(app), nil)
+	if err != nil || !strings.Contains(out, "Missing embedded files: 1") || !strings.Contains(out, "- pkg1/assets.go:5 files embeds seeds/*") {
+		t.Fatalf("sync should warn about the missing pattern, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--package", "pkg1", "--no-body"})
+	if err != nil || !strings.Contains(out, "Embedded assets:\n- files embeds schema.sql (pkg1/assets.go:5): 1 files\n- files embeds seeds/* (pkg1/assets.go:5): missing") {
+		t.Fatalf("find should list the package assets, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--package", "pkg1", "--json"})
+	if err != nil || !strings.Contains(out, `"assets"`) || !strings.Contains(out, `"pkg1/schema.sql"`) {
+		t.Fatalf("find --json should carry assets, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Alpha", "--json"})
+	if err != nil || strings.Contains(out, `"assets"`) {
+		t.Fatalf("find --json without assets, out=%q err=%v", out, err)
+	}
+}
+
 func TestFindListMode(t *testing.T) {
 	root := setupModuleRoot(t)
 	app := &App{Context: context.Background(), ModuleRoot: root}
@@ -891,6 +1297,100 @@ func TestFindListMode(t *testing.T) {
 	}
 }
 
+func TestNDJSONFormat(t *testing.T) {
+	defer func() { outputVersion, outputVersionPinned = legacyOutputVersion, false }()
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	createTestDecision(t, app, "Retries are bounded")
+
+	ndjsonLines := func(out string) []map[string]any {
+		t.Helper()
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+			var record map[string]any
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("expected one JSON object per line, got %q: %v", line, err)
+			}
+			records = append(records, record)
+		}
+		return records
+	}
+
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"--package", ".", "--format", "ndjson", "--limit", "0"})
+	if err != nil {
+		t.Fatalf("find --format ndjson error: %v", err)
+	}
+	records := ndjsonLines(out)
+	if len(records) < 2 || !slices.ContainsFunc(records, func(r map[string]any) bool { return r["name"] == "Alpha" }) {
+		t.Fatalf("expected a line per symbol including Alpha, out=%q", out)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"--package", ".", "--format", "ndjson", "--limit", "1"})
+	if err != nil || len(ndjsonLines(out)) != 1 {
+		t.Fatalf("expected --limit 1 to stream one symbol, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Alpha", "--format", "ndjson"})
+	if err == nil || !strings.Contains(out, `"code": "invalid_input"`) || !strings.Contains(out, "requires list mode") {
+		t.Fatalf("expected ndjson outside list mode to fail as JSON, out=%q err=%v", out, err)
+	}
+
+	if err := setOutputVersion(latestOutputVersion, true); err != nil {
+		t.Fatalf("setOutputVersion: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newRecallCommand(app), []string{"Retries", "--format", "ndjson"})
+	if err != nil {
+		t.Fatalf("recall --format ndjson error: %v", err)
+	}
+	records = ndjsonLines(out)
+	if len(records) != 1 || records[0]["title"] != "Retries are bounded" || records[0]["drift_status"] != "ok" {
+		t.Fatalf("expected one renamed recall item, out=%q", out)
+	}
+	if _, _, err := runCommandWithCapture(t, newRecallCommand(app), []string{"Retries", "--format", "xml"}); err == nil || !strings.Contains(err.Error(), "unsupported --format") {
+		t.Fatalf("expected unsupported format error, got %v", err)
+	}
+}
+
+func TestFindPathScoping(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	// --path alone enables list mode.
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"--path", "./pkg1/...", "--json"})
+	if err != nil {
+		t.Fatalf("find --path list error: %v", err)
+	}
+	if !strings.Contains(out, `"package": "pkg1"`) || strings.Contains(out, `"package": "pkg2"`) {
+		t.Fatalf("expected only pkg1 symbols, out=%q", out)
+	}
+
+	// --exclude-path disambiguates an exact lookup.
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--exclude-path", "pkg1", "--json"})
+	if err != nil {
+		t.Fatalf("find --exclude-path error: %v out=%q", err, out)
+	}
+	if !strings.Contains(out, `"package": "pkg2"`) {
+		t.Fatalf("expected pkg2 Ambig, out=%q", out)
+	}
+
+	// Excluded symbols report the active scope.
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Alpha", "--exclude-path", "."})
+	if err == nil || !strings.Contains(out, "Exclude paths: .") {
+		t.Fatalf("expected scoped not-found output, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"--path", "pkg1/.../x", "--json"})
+	if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("expected invalid_input for bad pattern, out=%q err=%v", out, err)
+	}
+}
+
 func TestNoPromptDisablesOrientPrompt(t *testing.T) {
 	root := setupModuleRoot(t)
 
@@ -987,35 +1487,214 @@ func TestStatusCommand(t *testing.T) {
 	}
 }
 
-func TestDecideLifecycleFlags(t *testing.T) {
-	root := setupModuleRoot(t)
-	app := &App{Context: context.Background(), ModuleRoot: root}
-	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
-		t.Fatalf("init: %v", err)
-	}
+func TestStatusVerbose(t *testing.T) {
+	app := setupInitializedApp(t)
 	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
 		t.Fatalf("sync: %v", err)
 	}
-
-	// Create a decision
-	out, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
-		"Lifecycle Test", "--reasoning", "r", "--evidence-summary", "e",
-		"--check-type", "file_exists", "--check-path", "go.mod", "--json",
-	})
+	id := createTestDecision(t, app, "Status decision")
+	conn, err := openExistingDB(app)
 	if err != nil {
-		t.Fatalf("decide create: %v", err)
+		t.Fatalf("open db: %v", err)
 	}
-	if !strings.Contains(out, `"promoted": true`) {
-		t.Fatalf("expected promoted, out=%q", out)
+	if _, err := conn.Exec(`
+INSERT INTO edges (from_type, from_id, to_type, to_ref, relation, created_at) VALUES
+  ('decision', ?, 'symbol', 'pkg1.Ambig', 'affects', 'x'),
+  ('decision', ?, 'symbol', 'pkg1.Gone', 'affects', 'x');`, id, id); err != nil {
+		t.Fatalf("seed edges: %v", err)
 	}
+	conn.Close()
 
-	// --list JSON
-	out, _, err = runCommandWithCapture(t, newDecideCommand(app), []string{"--list", "--json"})
-	if err != nil {
-		t.Fatalf("decide --list --json: %v", err)
+	out, _, err := runCommandWithCapture(t, newStatusCommand(app), []string{"--verbose", "--json"})
+	var payload struct {
+		Health struct {
+			DBSizeBytes           int64          `json:"db_size_bytes"`
+			SchemaVersion         int            `json:"schema_version"`
+			ExpectedSchemaVersion int            `json:"expected_schema_version"`
+			SearchIndex           map[string]int `json:"search_index"`
+			DanglingEdges         int            `json:"dangling_edges"`
+			LastVerifiedAt        string         `json:"last_verified_at"`
+		} `json:"health"`
 	}
-	if !strings.Contains(out, "Lifecycle Test") {
-		t.Fatalf("expected decision in list, out=%q", out)
+	if err != nil || json.Unmarshal([]byte(out), &payload) != nil {
+		t.Fatalf("status --verbose --json: out=%q err=%v", out, err)
+	}
+	h := payload.Health
+	if h.DBSizeBytes == 0 || h.SchemaVersion != h.ExpectedSchemaVersion || h.SearchIndex["decision"] != 1 || h.DanglingEdges != 1 || h.LastVerifiedAt == "" {
+		t.Fatalf("health = %+v", h)
+	}
+
+	out, _, err = runCommandWithCapture(t, newStatusCommand(app), []string{"--verbose"})
+	if err != nil || !strings.Contains(out, "Search index: 1 decisions, 0 patterns, 0 constraints") ||
+		!strings.Contains(out, "Pending proposals: 0 | Drifting evidence: 0 | Dangling edges: 1") {
+		t.Fatalf("status --verbose: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newStatusCommand(app), []string{"--verbose", "--format", "csv"})
+	if err != nil || !strings.Contains(out, "dangling_edges,1\n") {
+		t.Fatalf("status --verbose --format csv: out=%q err=%v", out, err)
+	}
+	// Without --verbose the report is left out.
+	out, _, err = runCommandWithCapture(t, newStatusCommand(app), []string{"--json"})
+	if err != nil || strings.Contains(out, `"health"`) {
+		t.Fatalf("status --json: out=%q err=%v", out, err)
+	}
+}
+
+func TestSyncRechecksEvidence(t *testing.T) {
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	out, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+		"Ambig in pkg2", "--reasoning", "r", "--evidence-summary", "pkg2/a.go exists",
+		"--check-type", "file_exists", "--check-spec", `{"path":"pkg2/a.go"}`, "--confidence", "high", "--json",
+	})
+	if err != nil {
+		t.Fatalf("decide: %v (out=%q)", err, out)
+	}
+
+	if err := os.Remove(filepath.Join(app.ModuleRoot, "pkg2", "a.go")); err != nil {
+		t.Fatalf("remove pkg2/a.go: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newSyncCommand(app), nil)
+	if err != nil {
+		t.Fatalf("sync after removal: %v", err)
+	}
+	if !strings.Contains(out, "Evidence re-checked: 1 (1 changed)") ||
+		!strings.Contains(out, "- decision #1 Ambig in pkg2: ok -> broken (file pkg2/a.go exists=false); confidence now medium") {
+		t.Fatalf("expected drift report, out=%q", out)
+	}
+
+	// A targeted sync of the restored file recovers the evidence.
+	if err := os.WriteFile(filepath.Join(app.ModuleRoot, "pkg2", "a.go"), []byte("package pkg2\nfunc Ambig() {}\n"), 0o644); err != nil {
+		t.Fatalf("restore pkg2/a.go: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newSyncCommand(app), []string{"--files", "pkg2/a.go", "--json"})
+	if err != nil {
+		t.Fatalf("sync --files: %v", err)
+	}
+	var payload syncPayload
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		t.Fatalf("decode sync payload: %v\n%s", err, out)
+	}
+	if payload.Evidence == nil || len(payload.Evidence.Changed) != 1 || payload.Evidence.Changed[0].After != "ok" || payload.Evidence.Changed[0].Confidence != "medium" {
+		t.Fatalf("unexpected evidence recheck: %+v", payload.Evidence)
+	}
+
+	// Recheck failures only warn.
+	orig := runRecheck
+	defer func() { runRecheck = orig }()
+	runRecheck = func(context.Context, *sql.DB, string, []string) (knowledge.RecheckResult, error) {
+		return knowledge.RecheckResult{}, errors.New("boom")
+	}
+	out, errOut, err := runCommandWithCapture(t, newSyncCommand(app), []string{"--json"})
+	if err != nil || strings.Contains(out, `"evidence"`) || !strings.Contains(errOut, "warning: evidence recheck failed: boom") {
+		t.Fatalf("recheck failure: out=%q stderr=%q err=%v", out, errOut, err)
+	}
+}
+
+func TestSyncReportsRenamesAndDanglingEdges(t *testing.T) {
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if out, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+		"Ambig stays tiny", "--reasoning", "r", "--evidence-summary", "pkg1/a.go exists",
+		"--check-type", "file_exists", "--check-spec", `{"path":"pkg1/a.go"}`, "--affects", "pkg1.Ambig", "--json",
+	}); err != nil {
+		t.Fatalf("decide: %v (out=%q)", err, out)
+	}
+
+	pkg1 := filepath.Join(app.ModuleRoot, "pkg1", "a.go")
+	if err := os.WriteFile(pkg1, []byte("package pkg1\nfunc Solo() {}\n"), 0o644); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	out, _, err := runCommandWithCapture(t, newSyncCommand(app), nil)
+	if err != nil || !strings.Contains(out, "Renamed symbols: 1\n- pkg1.Ambig -> pkg1.Solo (func, 1 edges rewritten)") {
+		t.Fatalf("rename sync: out=%q err=%v", out, err)
+	}
+
+	if err := os.WriteFile(pkg1, []byte("package pkg1\n"), 0o644); err != nil {
+		t.Fatalf("delete Solo: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newSyncCommand(app), []string{"--files", "pkg1/a.go"})
+	if err != nil || !strings.Contains(out, "Dangling edges: 1 point at symbols no longer in the index") || !strings.Contains(out, "- edge 1: decision #1 affects pkg1.Solo\n") {
+		t.Fatalf("dangling sync: out=%q err=%v", out, err)
+	}
+}
+
+func TestCSVFormat(t *testing.T) {
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newStatusCommand(app), []string{"--format", "csv"})
+	if err != nil || !strings.HasPrefix(out, "metric,value\ninitialized,true\n") || !strings.Contains(out, "\npackages,3\n") {
+		t.Fatalf("status csv out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"--list-packages", "--format", "CSV"})
+	if err != nil || !strings.HasPrefix(out, "path,name,file_count,line_count,heat,recent_commits\n.,main,1,") {
+		t.Fatalf("find csv out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newDigestCommand(app), []string{"--format", "csv"})
+	if err != nil || !strings.HasPrefix(out, "package,commits,files,lines_added,lines_deleted\n") {
+		t.Fatalf("digest csv out=%q err=%v", out, err)
+	}
+
+	for _, tc := range []struct {
+		cmd  *cobra.Command
+		args []string
+	}{
+		{newStatusCommand(app), []string{"--format", "xml"}},
+		{newFindCommand(app), []string{"main", "--format", "csv"}},
+		{newFindCommand(app), []string{"--list-packages", "--format", "tsv"}},
+		{newDigestCommand(app), []string{"--format", "xlsx"}},
+	} {
+		out, _, err := runCommandWithCapture(t, tc.cmd, append(tc.args, "--json"))
+		if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+			t.Fatalf("%v: expected invalid_input, out=%q err=%v", tc.args, out, err)
+		}
+	}
+	if _, _, err := runCommandWithCapture(t, newStatusCommand(app), []string{"--format", "xml"}); err == nil || !strings.Contains(err.Error(), "unsupported --format") {
+		t.Fatalf("expected unsupported format error, got %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"main", "--format", "csv"}); err == nil || !strings.Contains(err.Error(), "requires --list-packages") {
+		t.Fatalf("expected --list-packages error, got %v", err)
+	}
+}
+
+func TestDecideLifecycleFlags(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	// Create a decision
+	out, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+		"Lifecycle Test", "--reasoning", "r", "--evidence-summary", "e",
+		"--check-type", "file_exists", "--check-path", "go.mod", "--json",
+	})
+	if err != nil {
+		t.Fatalf("decide create: %v", err)
+	}
+	if !strings.Contains(out, `"promoted": true`) {
+		t.Fatalf("expected promoted, out=%q", out)
+	}
+
+	// --list JSON
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), []string{"--list", "--json"})
+	if err != nil {
+		t.Fatalf("decide --list --json: %v", err)
+	}
+	if !strings.Contains(out, "Lifecycle Test") {
+		t.Fatalf("expected decision in list, out=%q", out)
 	}
 
 	// --list text
@@ -1356,6 +2035,38 @@ func TestDecideInvalidCheckTypeError(t *testing.T) {
 	}
 }
 
+func TestDecideEvidencePolicy(t *testing.T) {
+	app := setupInitializedApp(t)
+	writeConfig := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(app.ModuleRoot, ".recon", "config.json"), []byte(body), 0o644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+	}
+	writeConfig(`{"knowledge":{"default_confidence":"high","required_checks":{"high":["symbol_exists","go_test_passes"]}}}`)
+
+	args := []string{"Keep go.mod", "--reasoning", "r", "--evidence-summary", "e", "--check-type", "file_exists", "--check-path", "go.mod"}
+	out, _, err := runCommandWithCapture(t, newDecideCommand(app), append(args, "--json"))
+	if err == nil || !strings.Contains(out, `"code": "invalid_input"`) || !strings.Contains(out, "high confidence requires a symbol_exists or go_test_passes check") {
+		t.Fatalf("expected policy rejection, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newPatternCommand(app), append(args, "--json"))
+	if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("expected pattern policy rejection, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), append(args, "--confidence", "medium", "--json"))
+	if err != nil || !strings.Contains(out, `"promoted": true`) {
+		t.Fatalf("expected medium decision to promote, out=%q err=%v", out, err)
+	}
+
+	writeConfig(`{"knowledge":{"default_confidence":"sure"}}`)
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), append(args, "--json"))
+	if err == nil || !strings.Contains(out, "knowledge.default_confidence must be one of") {
+		t.Fatalf("expected invalid config error, out=%q err=%v", out, err)
+	}
+}
+
 func TestInitInstallsClaudeCodeFiles(t *testing.T) {
 	root := setupModuleRoot(t)
 	app := &App{Context: context.Background(), ModuleRoot: root}
@@ -1511,6 +2222,112 @@ func TestDecideUpdate_NoFieldsError(t *testing.T) {
 	}
 }
 
+func TestDecideSimilarDecisions(t *testing.T) {
+	app := setupInitializedApp(t)
+	id := createTestDecision(t, app, "Retry network calls with backoff")
+	args := []string{
+		"Retries for network calls",
+		"--reasoning", "test reasoning",
+		"--evidence-summary", "go.mod exists",
+		"--check-type", "file_exists",
+		"--check-path", "go.mod",
+	}
+
+	out, _, err := runCommandWithCapture(t, newDecideCommand(app), append(args, "--strict", "--json"))
+	var exitErr ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 2 {
+		t.Fatalf("strict decide error = %v, want exit 2", err)
+	}
+	var envelope struct {
+		Error struct {
+			Code    string `json:"code"`
+			Details struct {
+				Similar []struct {
+					DecisionID int64   `json:"decision_id"`
+					Score      float64 `json:"score"`
+				} `json:"similar"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(out), &envelope); err != nil {
+		t.Fatalf("parse strict output: %v (out=%q)", err, out)
+	}
+	if envelope.Error.Code != "similar_exists" || len(envelope.Error.Details.Similar) != 1 || envelope.Error.Details.Similar[0].DecisionID != id {
+		t.Fatalf("strict envelope = %+v", envelope)
+	}
+	if list, _, _ := runCommandWithCapture(t, newDecideCommand(app), []string{"--list"}); strings.Contains(list, "Retries for network calls") {
+		t.Fatalf("strict decide recorded the decision:\n%s", list)
+	}
+
+	out, stderr, err := runCommandWithCapture(t, newDecideCommand(app), args)
+	if err != nil {
+		t.Fatalf("decide with warning: %v", err)
+	}
+	if !strings.Contains(stderr, "Warning: similar active decisions exist") || !strings.Contains(stderr, fmt.Sprintf("#%d Retry network calls with backoff", id)) {
+		t.Fatalf("stderr = %q, want similarity warning", stderr)
+	}
+	if !strings.Contains(out, "Decision promoted") {
+		t.Fatalf("stdout = %q, want promotion", out)
+	}
+
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), append([]string{"Retry network calls, backoff"}, append(args[1:], "--json")...))
+	if err != nil {
+		t.Fatalf("decide --json: %v", err)
+	}
+	var result struct {
+		Promoted bool `json:"promoted"`
+		Similar  []struct {
+			DecisionID int64 `json:"decision_id"`
+		} `json:"similar"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil || !result.Promoted || len(result.Similar) != 2 {
+		t.Fatalf("decide --json = %s (%v)", out, err)
+	}
+}
+
+func TestDecideMultipleChecks(t *testing.T) {
+	app := setupInitializedApp(t)
+	base := []string{"--reasoning", "test reasoning", "--evidence-summary", "module files"}
+
+	out, _, err := runCommandWithCapture(t, newDecideCommand(app), append([]string{"Keep the module files",
+		"--check", `file_exists:{"path":"go.mod"}`, "--check", `file_exists:{"path":"missing.go"}`}, base...))
+	var exitErr ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 2 || !strings.Contains(out, "1 of 2 checks passed (all required)") {
+		t.Fatalf("all checks: err=%v out=%q", err, out)
+	}
+
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), append([]string{"Keep the module manifest",
+		"--check", `file_exists:{"path":"go.mod"}`, "--check", `file_exists:{"path":"missing.go"}`, "--combine", "any", "--json"}, base...))
+	if err != nil {
+		t.Fatalf("any check: %v (out=%q)", err, out)
+	}
+	var result struct {
+		DecisionID int64 `json:"decision_id"`
+		Promoted   bool  `json:"promoted"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil || !result.Promoted {
+		t.Fatalf("any check output = %q, %v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), []string{"--dry-run",
+		"--check-spec", `[{"type":"file_exists","spec":{"path":"go.mod"}},{"type":"grep_pattern","spec":{"pattern":"func Ambig"}}]`})
+	if err != nil || !strings.Contains(out, "Dry run: passed — 2 of 2 checks passed (all required)") {
+		t.Fatalf("dry run: err=%v out=%q", err, out)
+	}
+
+	for _, args := range [][]string{
+		{"--check", `file_exists:{"path":"go.mod"}`, "--check-type", "file_exists"},
+		{"--check", `no_such_check:{}`},
+		{"--check", "file_exists"},
+		{"--check", `file_exists:{"path":"go.mod"}`, "--combine", "most"},
+	} {
+		out, _, err := runCommandWithCapture(t, newDecideCommand(app), append(append([]string{"Bad checks"}, args...), append(base, "--json")...))
+		if !errors.As(err, &exitErr) || !strings.Contains(out, `"code": "invalid_input"`) {
+			t.Fatalf("decide %v: err=%v out=%q", args, err, out)
+		}
+	}
+}
+
 func TestPatternUpdateReasoning(t *testing.T) {
 	app := setupInitializedApp(t)
 	id := createTestPattern(t, app, "Service struct pattern")
@@ -1552,6 +2369,96 @@ func TestPatternUpdate_NoFieldsError(t *testing.T) {
 	}
 }
 
+func TestPatternUpdateConfidenceAndExample(t *testing.T) {
+	app := setupInitializedApp(t)
+	id := createTestPattern(t, app, "Service struct pattern")
+	out, _, err := runCommandWithCapture(t, newPatternCommand(app), []string{
+		"--update", fmt.Sprintf("%d", id),
+		"--confidence", "high",
+		"--example", "type Service struct{ db *sql.DB }",
+		"--json",
+	})
+	if err != nil {
+		t.Fatalf("update pattern confidence/example: %v", err)
+	}
+	if !strings.Contains(out, `"confidence": "high"`) || !strings.Contains(out, `"example": "type Service struct{ db *sql.DB }"`) {
+		t.Fatalf("unexpected update output: %s", out)
+	}
+
+	out, _, err = runCommandWithCapture(t, newPatternCommand(app), []string{"--list", "--json"})
+	if err != nil || !strings.Contains(out, `"confidence": "high"`) {
+		t.Fatalf("expected list to show new confidence, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newPatternCommand(app), []string{
+		"--update", fmt.Sprintf("%d", id),
+		"--confidence", "certain",
+		"--json",
+	})
+	if err == nil || !strings.Contains(out, `"invalid_input"`) {
+		t.Fatalf("expected invalid_input for bad confidence, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newPatternCommand(app), []string{
+		"--update", "9999",
+		"--example", "x",
+		"--json",
+	})
+	if err == nil || !strings.Contains(out, `"not_found"`) {
+		t.Fatalf("expected not_found for missing pattern, out=%q err=%v", out, err)
+	}
+}
+
+func TestPatternDryRun(t *testing.T) {
+	app := setupInitializedApp(t)
+
+	out, _, err := runCommandWithCapture(t, newPatternCommand(app), []string{
+		"dry pattern", "--reasoning", "r", "--evidence-summary", "e",
+		"--check-type", "file_exists", "--check-path", "go.mod",
+		"--dry-run", "--json",
+	})
+	if err != nil {
+		t.Fatalf("dry-run pass: %v", err)
+	}
+	if !strings.Contains(out, `"passed": true`) || strings.Contains(out, `"proposal_id"`) {
+		t.Fatalf("expected dry-run passed without proposal, out=%q", out)
+	}
+
+	out, _, err = runCommandWithCapture(t, newPatternCommand(app), []string{
+		"dry pattern", "--reasoning", "r", "--evidence-summary", "e",
+		"--check-type", "file_exists", "--check-path", "missing.txt",
+		"--dry-run", "--json",
+	})
+	if err == nil || !strings.Contains(out, `"passed": false`) {
+		t.Fatalf("expected dry-run failure, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newPatternCommand(app), []string{
+		"dry pattern", "--evidence-summary", "e",
+		"--check-type", "file_exists", "--check-path", "go.mod",
+		"--dry-run",
+	})
+	if err != nil || !strings.Contains(out, "Dry run: passed") {
+		t.Fatalf("dry-run text pass out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newPatternCommand(app), []string{
+		"dry pattern", "--evidence-summary", "e",
+		"--check-type", "file_exists", "--check-path", "missing.txt",
+		"--dry-run",
+	}); err == nil {
+		t.Fatal("expected dry-run text failure exit")
+	}
+	if _, _, err := runCommandWithCapture(t, newPatternCommand(app), []string{
+		"dry pattern", "--check-type", "grep_pattern", "--dry-run",
+	}); err == nil {
+		t.Fatal("expected check spec error")
+	}
+
+	out, _, err = runCommandWithCapture(t, newPatternCommand(app), []string{"--list"})
+	if err != nil || !strings.Contains(out, "No active patterns.") {
+		t.Fatalf("dry runs must not create patterns, out=%q err=%v", out, err)
+	}
+}
+
 // seedImportGraph inserts two packages and an import relationship into an initialized DB.
 func seedImportGraph(t *testing.T, app *App) {
 	t.Helper()
@@ -1637,103 +2544,2787 @@ func TestDecideDeleteFlagStillWorks(t *testing.T) {
 	}
 }
 
-func TestPatternArchiveFlag(t *testing.T) {
+func TestDecideArchiveImpact(t *testing.T) {
 	app := setupInitializedApp(t)
-	id := createTestPattern(t, app, "Archive me")
-	out, _, err := runCommandWithCapture(t, newPatternCommand(app), []string{
-		"--archive", fmt.Sprintf("%d", id),
-	})
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	id := createTestDecision(t, app, "Keep CLI thin")
+	if _, _, err := runCommandWithCapture(t, newEdgesCommand(app), []string{
+		"--create", "--from", fmt.Sprintf("decision:%d", id), "--to", "package:internal/cli",
+	}); err != nil {
+		t.Fatalf("create edge: %v", err)
 	}
-	if !strings.Contains(out, "archived") {
-		t.Errorf("expected 'archived' in output, got: %s", out)
+	agents := fmt.Sprintf("## Recon Project Map\n\n- **Keep CLI thin** (#%d, medium confidence)\n", id)
+	if err := os.WriteFile(filepath.Join(app.ModuleRoot, "AGENTS.md"), []byte(agents), 0o644); err != nil {
+		t.Fatalf("write AGENTS.md: %v", err)
 	}
-}
 
-func TestPatternDeleteFlagStillWorks(t *testing.T) {
-	app := setupInitializedApp(t)
-	id := createTestPattern(t, app, "Delete me via old flag")
-	out, _, err := runCommandWithCapture(t, newPatternCommand(app), []string{
-		"--delete", fmt.Sprintf("%d", id),
-	})
-	if err != nil {
-		t.Fatalf("--delete backward compat: %v", err)
+	origInteractive := isInteractive
+	origAsk := askYesNo
+	defer func() {
+		isInteractive = origInteractive
+		askYesNo = origAsk
+	}()
+	isInteractive = func() bool { return false }
+
+	archive := []string{"--archive", fmt.Sprintf("%d", id)}
+	out, _, err := runCommandWithCapture(t, newDecideCommand(app), append(archive, "--json"))
+	if err == nil || !strings.Contains(out, `"code": "confirmation_required"`) || !strings.Contains(out, `"to_ref": "internal/cli"`) ||
+		!strings.Contains(out, `"AGENTS.md"`) {
+		t.Fatalf("expected confirmation_required, out=%q err=%v", out, err)
 	}
-	if !strings.Contains(out, "archived") {
-		t.Errorf("expected 'archived' in output, got: %s", out)
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), archive)
+	if err == nil || !strings.Contains(err.Error(), "--yes") || !strings.Contains(out, "-[affects]-> package:internal/cli") ||
+		!strings.Contains(out, "- rendered in AGENTS.md") {
+		t.Fatalf("expected impact and --yes hint, out=%q err=%v", out, err)
+	}
+
+	isInteractive = func() bool { return true }
+	askYesNo = func(string, bool) (bool, error) { return false, nil }
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), archive)
+	if err != nil || !strings.Contains(out, "Aborted.") {
+		t.Fatalf("expected declined prompt to abort, out=%q err=%v", out, err)
+	}
+	askYesNo = func(string, bool) (bool, error) { return false, errors.New("eof") }
+	if _, _, err = runCommandWithCapture(t, newDecideCommand(app), archive); err == nil || !strings.Contains(err.Error(), "read archive prompt") {
+		t.Fatalf("expected prompt error, got %v", err)
+	}
+
+	isInteractive = func() bool { return false }
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), append(archive, "--yes"))
+	if err != nil || !strings.Contains(out, "Decision "+fmt.Sprintf("%d", id)+" archived.") || !strings.Contains(out, "recon agents-md") {
+		t.Fatalf("expected --yes to archive, out=%q err=%v", out, err)
 	}
 }
 
-func TestResetCommand(t *testing.T) {
+func TestDecideBranchScope(t *testing.T) {
 	app := setupInitializedApp(t)
-	// Verify DB exists before reset
-	dbPath := db.DBPath(app.ModuleRoot)
-	if _, err := os.Stat(dbPath); err != nil {
-		t.Fatalf("expected db to exist before reset: %v", err)
+	orig := currentBranch
+	defer func() { currentBranch = orig }()
+	branch := ""
+	currentBranch = func(context.Context, string) string { return branch }
+
+	decide := []string{"Retry flaky uploads", "--reasoning", "r", "--evidence-summary", "e",
+		"--check-type", "file_exists", "--check-path", "go.mod", "--branch"}
+	out, _, err := runCommandWithCapture(t, newDecideCommand(app), append(decide, "--json"))
+	if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("expected --branch without a branch to fail, out=%q err=%v", out, err)
 	}
-	out, _, err := runCommandWithCapture(t, newResetCommand(app), []string{"--force"})
-	if err != nil {
-		t.Fatalf("reset --force: %v", err)
+
+	branch = "feature-x"
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), decide)
+	if err != nil || !strings.Contains(out, "Scoped to branch feature-x") || !strings.Contains(out, "--promote-to-main 1") {
+		t.Fatalf("decide --branch: out=%q err=%v", out, err)
 	}
-	if !strings.Contains(out, "reset") {
-		t.Errorf("expected 'reset' in output, got: %s", out)
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), []string{"--list"})
+	if err != nil || !strings.Contains(out, "branch=feature-x") {
+		t.Fatalf("expected branch in list, out=%q err=%v", out, err)
 	}
-	if _, statErr := os.Stat(dbPath); !errors.Is(statErr, os.ErrNotExist) {
-		t.Error("expected db file to be deleted after reset")
+	out, _, err = runCommandWithCapture(t, newOrientCommand(app), []string{"--json"})
+	if err != nil || !strings.Contains(out, `"branch": "feature-x"`) {
+		t.Fatalf("expected scoped decision on its branch, out=%q err=%v", out, err)
+	}
+
+	branch = "main"
+	out, _, err = runCommandWithCapture(t, newOrientCommand(app), []string{"--json"})
+	if err != nil || strings.Contains(out, "Retry flaky uploads") {
+		t.Fatalf("expected scoped decision hidden on main, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newRecallCommand(app), []string{"flaky", "--json"})
+	if err != nil || strings.Contains(out, "Retry flaky uploads") {
+		t.Fatalf("expected recall to hide scoped decision, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), []string{"--promote-to-main", "1"})
+	if err != nil || !strings.Contains(out, "promoted to main (was scoped to feature-x)") {
+		t.Fatalf("promote: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newRecallCommand(app), []string{"flaky", "--json"})
+	if err != nil || !strings.Contains(out, "Retry flaky uploads") {
+		t.Fatalf("expected promoted decision in recall, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), []string{"--promote-to-main", "1", "--json"})
+	if err != nil || !strings.Contains(out, `"promoted": false`) {
+		t.Fatalf("expected second promote to be a no-op, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newDecideCommand(app), []string{"--promote-to-main", "99", "--json"})
+	if err == nil || !strings.Contains(out, `"code": "not_found"`) {
+		t.Fatalf("expected not_found, out=%q err=%v", out, err)
 	}
 }
 
-func TestResetCommand_NotInitialized(t *testing.T) {
+func TestOrientMinConfidence(t *testing.T) {
+	app := setupInitializedApp(t)
+	createTestDecision(t, app, "Medium confidence decision")
+
+	out, _, err := runCommandWithCapture(t, newOrientCommand(app), []string{"--json", "--min-confidence", "high"})
+	if err != nil || strings.Contains(out, "Medium confidence decision") || !strings.Contains(out, `"min_confidence": "high"`) {
+		t.Fatalf("expected decision filtered out, out=%q err=%v", out, err)
+	}
+
+	if err := os.WriteFile(config.Path(app.ModuleRoot), []byte(`{"orient":{"min_confidence":"high"}}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newOrientCommand(app), []string{"--json"})
+	if err != nil || strings.Contains(out, "Medium confidence decision") {
+		t.Fatalf("expected config default to filter, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newOrientCommand(app), []string{"--json", "--min-confidence", "low"})
+	if err != nil || !strings.Contains(out, "Medium confidence decision") {
+		t.Fatalf("expected flag to override config, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newOrientCommand(app), []string{"--json", "--min-confidence", "certain"})
+	if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("expected invalid_input, out=%q err=%v", out, err)
+	}
+}
+
+func TestOrientRecent(t *testing.T) {
+	app := setupInitializedApp(t)
+	orig := buildOrient
+	t.Cleanup(func() { buildOrient = orig })
+	var gotRecent int
+	buildOrient = func(ctx context.Context, conn *sql.DB, opts orient.BuildOptions) (orient.Payload, error) {
+		gotRecent = opts.MaxRecent
+		return orig(ctx, conn, opts)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newOrientCommand(app), []string{"--json"}); err != nil || gotRecent != 5 {
+		t.Fatalf("expected 5 recent files by default, got %d (err=%v)", gotRecent, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newOrientCommand(app), []string{"--json", "--recent", "12"}); err != nil || gotRecent != 12 {
+		t.Fatalf("expected --recent 12 passed through, got %d (err=%v)", gotRecent, err)
+	}
+	out, _, err := runCommandWithCapture(t, newOrientCommand(app), []string{"--json", "--recent", "0"})
+	if err == nil || !strings.Contains(out, `"code": "invalid_input"`) || !strings.Contains(out, "--recent must be at least 1") {
+		t.Fatalf("expected invalid_input for --recent 0, out=%q err=%v", out, err)
+	}
+}
+
+func TestOrientHeatPolicy(t *testing.T) {
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newOrientCommand(app), []string{"--json"})
+	if err != nil || !strings.Contains(out, `"heat": {
+    "window_days": 30,
+    "hot": 4,
+    "warm": 1
+  }`) {
+		t.Fatalf("expected default heat policy, out=%q err=%v", out, err)
+	}
+
+	if err := os.WriteFile(config.Path(app.ModuleRoot), []byte(`{"heat":{"window_days":7,"hot":10}}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newOrientCommand(app), []string{"--json", "--heat-warm", "3"})
+	if err != nil || !strings.Contains(out, `"window_days": 7`) || !strings.Contains(out, `"hot": 10`) || !strings.Contains(out, `"warm": 3`) {
+		t.Fatalf("expected config and flag heat policy, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newOrientCommand(app), []string{"--heat-window", "14"})
+	if err != nil || !strings.Contains(out, "Heat counts commits in the last 14 days: HOT at 10 or more, WARM at 1 or more.") {
+		t.Fatalf("expected heat line in text output, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newOrientCommand(app), []string{"--json", "--heat-warm", "11"})
+	if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("expected invalid_input for warm above hot, out=%q err=%v", out, err)
+	}
+	_, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"--list-packages", "--heat-window", "0"})
+	if err == nil || !strings.Contains(err.Error(), "--heat-window must be positive") {
+		t.Fatalf("expected --heat-window error, err=%v", err)
+	}
+}
+
+func TestOwnersCommand(t *testing.T) {
+	app := setupInitializedApp(t)
+
+	out, _, err := runCommandWithCapture(t, newOwnersCommand(app), []string{"main.go", "--json"})
+	if err == nil || !strings.Contains(out, `"code": "not_found"`) {
+		t.Fatalf("expected not_found without CODEOWNERS, out=%q err=%v", out, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(app.ModuleRoot, "CODEOWNERS"), []byte("* @everyone\n/pkg1/ @alice @bob\n/pkg2/a.go\n"), 0o644); err != nil {
+		t.Fatalf("write CODEOWNERS: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newOwnersCommand(app), []string{"pkg1/a.go"})
+	if err != nil || out != "pkg1/a.go: @alice @bob (CODEOWNERS:2 /pkg1/)\n" {
+		t.Fatalf("owners pkg1/a.go, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newOwnersCommand(app), []string{"pkg2/a.go"})
+	if err != nil || out != "pkg2/a.go: unowned (CODEOWNERS:3 /pkg2/a.go)\n" {
+		t.Fatalf("owners pkg2/a.go, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newOwnersCommand(app), []string{".", "--json"})
+	if err != nil || !strings.Contains(out, `"owners": [
+    "@everyone"
+  ]`) || !strings.Contains(out, `"line": 1`) {
+		t.Fatalf("owners . --json, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newOwnersCommand(app), []string{"../elsewhere.go"})
+	if err == nil || !strings.Contains(err.Error(), "outside the module") {
+		t.Fatalf("expected outside-module error, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--package", "pkg1", "--no-body"})
+	if err != nil || !strings.Contains(out, "Owners: @alice @bob\n") {
+		t.Fatalf("find should show the symbol's owners, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newOrientCommand(app), nil)
+	if err != nil || !strings.Contains(out, "- pkg1 (pkg1)") || !strings.Contains(out, "owners=@alice,@bob") {
+		t.Fatalf("orient should show module owners, out=%q err=%v", out, err)
+	}
+}
+
+func TestTodosCommand(t *testing.T) {
+	app := setupInitializedApp(t)
+
+	out, _, err := runCommandWithCapture(t, newTodosCommand(app), nil)
+	if err != nil || out != "No annotations found\n" {
+		t.Fatalf("todos before any markers, out=%q err=%v", out, err)
+	}
+
+	src := `package pkg1
+
+// TODO(alice): rename this
+// Ambig is in two packages.
+//
+// Deprecated: use pkg2.Ambig.
+func Ambig() {
+	// FIXME: do something
+}
+`
+	if err := os.WriteFile(filepath.Join(app.ModuleRoot, "pkg1", "a.go"), []byte(src), 0o644); err != nil {
+		t.Fatalf("write pkg1/a.go: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newTodosCommand(app), nil)
+	want := "Annotations (1 todo, 1 fixme):\n- pkg1/a.go:3 TODO(alice): rename this\n- pkg1/a.go:8 FIXME: do something\n"
+	if err != nil || out != want {
+		t.Fatalf("todos, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newTodosCommand(app), []string{"--kind", "deprecated"})
+	if err != nil || !strings.Contains(out, "- pkg1/a.go:7 Deprecated Ambig: use pkg2.Ambig.\n") {
+		t.Fatalf("todos --kind deprecated, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newTodosCommand(app), []string{"--package", "pkg2", "--json"})
+	if err != nil || strings.TrimSpace(out) != "[]" {
+		t.Fatalf("todos --package pkg2 --json, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newTodosCommand(app), []string{"--kind", "note", "--json"})
+	if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("expected invalid_input for --kind note, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--package", "pkg1", "--no-body"})
+	if err != nil || !strings.Contains(out, "Deprecated: use pkg2.Ambig.\n") {
+		t.Fatalf("find should warn about the deprecated symbol, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--package", "pkg2", "--json"})
+	if err != nil || strings.Contains(out, `"deprecated"`) {
+		t.Fatalf("find should not mark pkg2.Ambig deprecated, out=%q err=%v", out, err)
+	}
+}
+
+func TestFindSQLAndShellSymbols(t *testing.T) {
+	app := setupInitializedApp(t)
+	for path, body := range map[string]string{
+		"migrations/0001_widgets.up.sql": "CREATE TABLE widgets (id INTEGER);\nCREATE INDEX idx_widgets ON widgets(id);\n",
+		"scripts/build.sh":               "build() {\n  go build ./...\n}\n",
+	} {
+		full := filepath.Join(app.ModuleRoot, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"widgets", "--no-body"})
+	if err != nil || !strings.Contains(out, "table widgets (migrations/0001_widgets.up.sql)\nLines: 1-2\nLanguage: sql\n") {
+		t.Fatalf("find widgets, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"--kind", "table"})
+	if err != nil || !strings.Contains(out, "- table widgets (migrations/0001_widgets.up.sql:1-2) lang=sql\n") {
+		t.Fatalf("find --kind table, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"build", "--package", "scripts", "--json"})
+	if err != nil || !strings.Contains(out, `"language": "sh"`) || !strings.Contains(out, `"package": "scripts"`) {
+		t.Fatalf("find build --package scripts --json, out=%q err=%v", out, err)
+	}
+}
+
+func TestFindProtoLinks(t *testing.T) {
+	app := setupInitializedApp(t)
+	for path, body := range map[string]string{
+		"api/greet.proto":  "syntax = \"proto3\";\nservice Greeter {\n  rpc SayHello(Req) returns (Req);\n}\nmessage Req {}\n",
+		"api/greet.go":     "package api\n\ntype Req struct{}\n\ntype UnimplementedGreeterServer struct{}\n",
+		"server/server.go": "package server\n\nimport \"example.com/recon/api\"\n\ntype Server struct {\n\tapi.UnimplementedGreeterServer\n}\n",
+	} {
+		full := filepath.Join(app.ModuleRoot, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"Greeter", "--kind", "proto_service", "--no-body"})
+	if err != nil || !strings.Contains(out, "proto_service Greeter (api/greet.proto)\n") ||
+		!strings.Contains(out, "Generated Go: api.GreeterClient, api.GreeterServer\n") ||
+		!strings.Contains(out, "Implemented by: server.Server (server/server.go:5)\n") {
+		t.Fatalf("find Greeter, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Greeter.SayHello", "--no-body"})
+	if err != nil || !strings.Contains(out, "proto_rpc SayHello (api/greet.proto)\n") || !strings.Contains(out, "Receiver: Greeter\n") {
+		t.Fatalf("find Greeter.SayHello, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Req", "--kind", "type", "--json"})
+	if err != nil || !strings.Contains(out, `"relation": "generated_from"`) || !strings.Contains(out, `"symbol": "api.Req"`) {
+		t.Fatalf("find Req --kind type --json, out=%q err=%v", out, err)
+	}
+	if strings.Contains(out, `"knowledge"`) {
+		t.Fatalf("code links reported as knowledge, out=%q", out)
+	}
+}
+
+func TestAPICommand(t *testing.T) {
+	app := setupInitializedApp(t)
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", app.ModuleRoot}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v (%s)", args, err, string(out))
+		}
+	}
+	run("init")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Tester")
+	run("add", "-A")
+	run("commit", "-m", "base")
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newAPICommand(app), nil)
+	if err != nil || !strings.Contains(out, "Exported API (2 packages, 2 declarations):\n\npkg1\n  func Ambig()\n") || strings.Contains(out, "Alpha") {
+		t.Fatalf("api, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newAPICommand(app), []string{"--diff", "HEAD"})
+	if err != nil || out != "No API changes since HEAD\n" {
+		t.Fatalf("api --diff HEAD, out=%q err=%v", out, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(app.ModuleRoot, "pkg2", "a.go"), []byte("package pkg2\nfunc Ambig(n int) {}\n"), 0o644); err != nil {
+		t.Fatalf("write pkg2/a.go: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newAPICommand(app), []string{"--diff", "HEAD"})
+	var exitErr ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != apiExitCode {
+		t.Fatalf("expected api exit code, got %v", err)
+	}
+	if !strings.Contains(out, "API changes since HEAD: 1 breaking, 0 added\n\npkg2\n  ~ func Ambig(n int)\n      was: func Ambig()\n") {
+		t.Fatalf("api --diff HEAD after change, out=%q", out)
+	}
+
+	out, _, err = runCommandWithCapture(t, newAPICommand(app), []string{"--diff", "no-such-ref", "--json"})
+	if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("api --diff no-such-ref --json, out=%q err=%v", out, err)
+	}
+}
+
+func TestAPIDiffOfUnchangedTreeWithComments(t *testing.T) {
+	app := setupInitializedApp(t)
+	src := `package lang
+
+// FileIndexer indexes one language.
+type FileIndexer interface {
+	// Language names the language.
+	Language() string
+	// Symbols lists the symbols in src.
+	Symbols(src []byte) []string // never nil
+}
+
+// Options configures an indexer.
+type Options struct {
+	// Root is the module root.
+	Root string // absolute
+	Exts []string
+}
+`
+	if err := os.MkdirAll(filepath.Join(app.ModuleRoot, "internal", "lang"), 0o755); err != nil {
+		t.Fatalf("mkdir internal/lang: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(app.ModuleRoot, "internal", "lang", "lang.go"), []byte(src), 0o644); err != nil {
+		t.Fatalf("write internal/lang/lang.go: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init"}, {"config", "user.email", "test@example.com"}, {"config", "user.name", "Tester"},
+		{"add", "-A"}, {"commit", "-m", "base"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", app.ModuleRoot}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v (%s)", args, err, string(out))
+		}
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newAPICommand(app), []string{"--internal", "--package", "internal/lang"})
+	if err != nil || !strings.Contains(out, "type FileIndexer interface { Language() string Symbols(src []byte) []string }\n") || strings.Contains(out, "//") {
+		t.Fatalf("api --internal, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newAPICommand(app), []string{"--internal", "--diff", "HEAD"})
+	if err != nil || out != "No API changes since HEAD\n" {
+		t.Fatalf("api --internal --diff HEAD, out=%q err=%v", out, err)
+	}
+}
+
+func TestSemverCommand(t *testing.T) {
+	app := setupInitializedApp(t)
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", app.ModuleRoot}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v (%s)", args, err, string(out))
+		}
+	}
+	run("init")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Tester")
+	run("add", "-A")
+	run("commit", "-m", "base")
+
+	out, _, err := runCommandWithCapture(t, newSemverCommand(app), []string{"--json"})
+	if err == nil || !strings.Contains(out, `"code": "not_found"`) {
+		t.Fatalf("semver without tags, out=%q err=%v", out, err)
+	}
+
+	run("tag", "v1.2.0")
+	if err := os.WriteFile(filepath.Join(app.ModuleRoot, "pkg1", "b.go"), []byte("package pkg1\nfunc Extra() {}\n"), 0o644); err != nil {
+		t.Fatalf("write pkg1/b.go: %v", err)
+	}
+	run("add", "-A")
+	run("commit", "-m", "add Extra")
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newSemverCommand(app), nil)
+	if err != nil || !strings.Contains(out, "Since v1.2.0 (1 commits): minor → v1.3.0\n") || !strings.Contains(out, "Added (1):\n- pkg1: func Extra()\n") {
+		t.Fatalf("semver, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newSemverCommand(app), []string{"--since", "HEAD", "--json"})
+	if err != nil || !strings.Contains(out, `"level": "none"`) {
+		t.Fatalf("semver --since HEAD --json, out=%q err=%v", out, err)
+	}
+
+	// An empty range is no release, even with uncommitted API edits synced.
+	if err := os.WriteFile(filepath.Join(app.ModuleRoot, "pkg1", "b.go"), []byte("package pkg1\nfunc Extra(n int) {}\n"), 0o644); err != nil {
+		t.Fatalf("write pkg1/b.go: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newSemverCommand(app), []string{"--since", "HEAD", "--internal"})
+	if err != nil || out != "Since HEAD (0 commits): none\nReason: no commits since HEAD\n" {
+		t.Fatalf("semver --since HEAD --internal, out=%q err=%v", out, err)
+	}
+}
+
+func TestCICommand(t *testing.T) {
 	root := setupModuleRoot(t)
 	app := &App{Context: context.Background(), ModuleRoot: root}
-	// No init — DB does not exist
-	out, _, err := runCommandWithCapture(t, newResetCommand(app), []string{"--force"})
+	if out, _, err := runCommandWithCapture(t, newCICommand(app), []string{"--json"}); err == nil || !strings.Contains(out, `"code": "not_initialized"`) {
+		t.Fatalf("expected not_initialized, out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), []string{"--profile", "ci"}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	summary := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv(stepSummaryEnv, summary)
+	out, _, err := runCommandWithCapture(t, newCICommand(app), nil)
 	if err != nil {
-		t.Fatalf("reset on uninitialized: %v", err)
+		t.Fatalf("clean ci: %v (out=%q)", err, out)
 	}
-	if !strings.Contains(out, "Nothing to reset") {
-		t.Errorf("expected 'Nothing to reset' in output, got: %s", out)
+	for _, want := range []string{"Synced 3 files", "PASS drift (fail): 0 of 0 evidence checks drifting or broken", "PASS dangling_edges (warn)", "Result: pass"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("clean ci output missing %q:\n%s", want, out)
+		}
+	}
+	md, err := os.ReadFile(summary)
+	if err != nil || !strings.Contains(string(md), "## recon ci: pass") || !strings.Contains(string(md), "| architecture | fail | 0 | pass |") {
+		t.Fatalf("step summary = %q, %v", md, err)
+	}
+
+	if out, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+		"Ambig stays small", "--reasoning", "r", "--evidence-summary", "a.go exists", "--check-type", "file_exists",
+		"--check-path", "pkg2/a.go", "--affects", "pkg2.Ambig", "--json",
+	}); err != nil {
+		t.Fatalf("decide: %v (out=%q)", err, out)
+	}
+	if err := os.Remove(filepath.Join(root, "pkg2", "a.go")); err != nil {
+		t.Fatalf("remove pkg2/a.go: %v", err)
+	}
+
+	run := func(args ...string) (ciPayload, error) {
+		t.Helper()
+		out, _, err := runCommandWithCapture(t, newCICommand(app), append([]string{"--json"}, args...))
+		var payload ciPayload
+		if jerr := json.Unmarshal([]byte(out), &payload); jerr != nil {
+			t.Fatalf("decode %v: %v\n%s", args, jerr, out)
+		}
+		return payload, err
+	}
+	statuses := func(p ciPayload) string {
+		var parts []string
+		for _, c := range p.Checks {
+			parts = append(parts, c.Name+"="+c.Status)
+		}
+		return strings.Join(parts, " ")
+	}
+
+	payload, err := run("--step-summary", summary)
+	var exitErr ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != ciExitCode {
+		t.Fatalf("expected exit %d, got %v", ciExitCode, err)
+	}
+	if payload.Status != ciFail || statuses(payload) != "drift=fail architecture=pass dangling_edges=warn" ||
+		len(payload.Drift) != 1 || len(payload.DanglingEdges) != 1 || payload.DanglingEdges[0].ToRef != "pkg2.Ambig" {
+		t.Fatalf("unexpected failing payload %+v", payload)
+	}
+	if md, _ := os.ReadFile(summary); !strings.Contains(string(md), "## recon ci: fail") ||
+		!strings.Contains(string(md), "### drift\n\n- decision #1 \"Ambig stays small\": broken [file_exists] a.go exists") {
+		t.Fatalf("step summary not appended:\n%s", md)
+	}
+
+	if payload, err = run("--warn-only"); err != nil || payload.Status != ciWarn || statuses(payload) != "drift=warn architecture=pass dangling_edges=warn" {
+		t.Fatalf("--warn-only: %+v, %v", payload, err)
+	}
+	if payload, err = run("--drift", "off", "--dangling-edges", "FAIL"); !errors.As(err, &exitErr) || exitErr.Code != ciExitCode ||
+		statuses(payload) != "drift=skipped architecture=pass dangling_edges=fail" || payload.Evidence != nil {
+		t.Fatalf("--drift off --dangling-edges fail: %+v, %v", payload, err)
+	}
+
+	if err := os.WriteFile(config.Path(root), []byte(`{"ci":{"drift":"warn","dangling_edges":"off"}}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if payload, err = run(); err != nil || payload.Status != ciWarn || statuses(payload) != "drift=warn architecture=pass dangling_edges=skipped" {
+		t.Fatalf("configured policies: %+v, %v", payload, err)
+	}
+
+	if out, _, err := runCommandWithCapture(t, newCICommand(app), []string{"--arch", "maybe", "--json"}); err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("expected invalid_input, out=%q err=%v", out, err)
 	}
 }
 
-func TestFindAmbiguousShowsHint(t *testing.T) {
+func TestInstallGitHooksAndStagedSync(t *testing.T) {
 	root := setupModuleRoot(t)
 	app := &App{Context: context.Background(), ModuleRoot: root}
-	// Init + sync to populate the DB with pkg1.Ambig and pkg2.Ambig
-	if _, _, err := runCommandWithCapture(t, newInitCommand(app), []string{"--force"}); err != nil {
+	git := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	if out, _, err := runCommandWithCapture(t, newInstallGitHooksCommand(app), []string{"--json"}); err == nil || !strings.Contains(out, `"invalid_input"`) {
+		t.Fatalf("expected invalid_input outside a repository, out=%q err=%v", out, err)
+	}
+	git("init", "-q")
+
+	out, _, err := runCommandWithCapture(t, newInstallGitHooksCommand(app), nil)
+	if err != nil || !strings.Contains(out, "installed pre-commit (") || !strings.Contains(out, "Note: recon is not initialized here") {
+		t.Fatalf("install git-hooks: out=%q err=%v", out, err)
+	}
+	hook, err := os.ReadFile(filepath.Join(root, ".git", "hooks", "pre-commit"))
+	if err != nil || !strings.Contains(string(hook), "recon sync --staged --quiet") {
+		t.Fatalf("pre-commit hook = %q, %v", hook, err)
+	}
+	if out, _, err := runCommandWithCapture(t, newInstallGitHooksCommand(app), []string{"--hooks", "pre-push", "--json"}); err == nil || !strings.Contains(out, "unsupported hook") {
+		t.Fatalf("expected unsupported hook, out=%q err=%v", out, err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".git", "hooks", "post-merge"), []byte("#!/bin/sh\nmake\n"), 0o755); err != nil {
+		t.Fatalf("write foreign hook: %v", err)
+	}
+	if out, _, err := runCommandWithCapture(t, newInstallGitHooksCommand(app), []string{"--json"}); err == nil || !strings.Contains(out, "use --force to replace it") {
+		t.Fatalf("expected foreign hook error, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newInstallGitHooksCommand(app), []string{"--remove"})
+	if err != nil || !strings.Contains(out, "removed pre-commit") || !strings.Contains(out, "absent post-merge") {
+		t.Fatalf("install git-hooks --remove: out=%q err=%v", out, err)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), []string{"--profile", "ci"}); err != nil {
 		t.Fatalf("init: %v", err)
 	}
 	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
 		t.Fatalf("sync: %v", err)
 	}
-	// Find Ambig in text mode — should fail with ambiguous and include hint
-	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"Ambig"})
-	if err == nil {
-		t.Fatal("expected ambiguous error")
+	if out, _, err := runCommandWithCapture(t, newSyncCommand(app), []string{"--staged"}); err != nil || out != "No staged files to re-index\n" {
+		t.Fatalf("sync --staged with nothing staged: out=%q err=%v", out, err)
 	}
-	if !strings.Contains(out, "Try:") {
-		t.Errorf("expected 'Try:' hint in output, got: %s", out)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), []string{"--staged", "--files", "main.go"}); err == nil {
+		t.Fatal("expected --staged with --files to fail")
 	}
-}
 
-func TestEdgesListShowsTitles(t *testing.T) {
-	app := setupInitializedApp(t)
-	// Create a decision, then create an edge from it
-	decisionID := createTestDecision(t, app, "ExitError is the standard error")
-	if _, _, err := runCommandWithCapture(t, newEdgesCommand(app), []string{
-		"--create",
-		"--from", fmt.Sprintf("decision:%d", decisionID),
-		"--to", "package:internal/cli",
-		"--relation", "affects",
+	if out, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+		"Ambig stays small", "--reasoning", "r", "--evidence-summary", "a.go exists", "--check-type", "file_exists",
+		"--check-path", "pkg2/a.go", "--json",
 	}); err != nil {
-		t.Fatalf("create edge: %v", err)
+		t.Fatalf("decide: %v (out=%q)", err, out)
 	}
-	out, _, err := runCommandWithCapture(t, newEdgesCommand(app), []string{"--list"})
-	if err != nil {
-		t.Fatalf("list edges: %v", err)
+	if err := os.WriteFile(filepath.Join(root, "pkg1", "a.go"), []byte("package pkg1\nfunc Ambig() {}\nfunc Beta() {}\n"), 0o644); err != nil {
+		t.Fatalf("write pkg1/a.go: %v", err)
+	}
+	git("add", "pkg1/a.go", "go.mod")
+	out, _, err = runCommandWithCapture(t, newSyncCommand(app), []string{"--staged", "--json"})
+	if err != nil || !strings.Contains(out, `"files": [`) || !strings.Contains(out, `"pkg1/a.go"`) || strings.Contains(out, `"go.mod"`) {
+		t.Fatalf("sync --staged --json: out=%q err=%v", out, err)
+	}
+	if out, _, err := runCommandWithCapture(t, newSyncCommand(app), []string{"--staged", "--quiet"}); err != nil || out != "" {
+		t.Fatalf("quiet sync without drift: out=%q err=%v", out, err)
+	}
+
+	if err := os.Remove(filepath.Join(root, "pkg2", "a.go")); err != nil {
+		t.Fatalf("remove pkg2/a.go: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newSyncCommand(app), []string{"--files", "pkg2/a.go", "--quiet"})
+	if err != nil || !strings.Contains(out, `- decision #1 Ambig stays small: ok -> broken`) {
+		t.Fatalf("quiet sync with drift: out=%q err=%v", out, err)
+	}
+}
+
+func TestPatternArchiveFlag(t *testing.T) {
+	app := setupInitializedApp(t)
+	id := createTestPattern(t, app, "Archive me")
+	out, _, err := runCommandWithCapture(t, newPatternCommand(app), []string{
+		"--archive", fmt.Sprintf("%d", id),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "archived") {
+		t.Errorf("expected 'archived' in output, got: %s", out)
+	}
+}
+
+func TestPatternDeleteFlagStillWorks(t *testing.T) {
+	app := setupInitializedApp(t)
+	id := createTestPattern(t, app, "Delete me via old flag")
+	out, _, err := runCommandWithCapture(t, newPatternCommand(app), []string{
+		"--delete", fmt.Sprintf("%d", id),
+	})
+	if err != nil {
+		t.Fatalf("--delete backward compat: %v", err)
+	}
+	if !strings.Contains(out, "archived") {
+		t.Errorf("expected 'archived' in output, got: %s", out)
+	}
+}
+
+func TestResetCommand(t *testing.T) {
+	app := setupInitializedApp(t)
+	// Verify DB exists before reset
+	dbPath := db.DBPath(app.ModuleRoot)
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Fatalf("expected db to exist before reset: %v", err)
+	}
+	out, _, err := runCommandWithCapture(t, newResetCommand(app), []string{"--force"})
+	if err != nil {
+		t.Fatalf("reset --force: %v", err)
+	}
+	if !strings.Contains(out, "reset") {
+		t.Errorf("expected 'reset' in output, got: %s", out)
+	}
+	if _, statErr := os.Stat(dbPath); !errors.Is(statErr, os.ErrNotExist) {
+		t.Error("expected db file to be deleted after reset")
+	}
+}
+
+func TestResetCommand_NotInitialized(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	// No init — DB does not exist
+	out, _, err := runCommandWithCapture(t, newResetCommand(app), []string{"--force"})
+	if err != nil {
+		t.Fatalf("reset on uninitialized: %v", err)
+	}
+	if !strings.Contains(out, "Nothing to reset") {
+		t.Errorf("expected 'Nothing to reset' in output, got: %s", out)
+	}
+}
+
+func TestFindAmbiguousShowsHint(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	// Init + sync to populate the DB with pkg1.Ambig and pkg2.Ambig
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), []string{"--force"}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	// Find Ambig in text mode — should fail with ambiguous and include hint
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"Ambig"})
+	if err == nil {
+		t.Fatal("expected ambiguous error")
+	}
+	if !strings.Contains(out, "Try:") {
+		t.Errorf("expected 'Try:' hint in output, got: %s", out)
+	}
+}
+
+func TestEdgesListShowsTitles(t *testing.T) {
+	app := setupInitializedApp(t)
+	// Create a decision, then create an edge from it
+	decisionID := createTestDecision(t, app, "ExitError is the standard error")
+	if _, _, err := runCommandWithCapture(t, newEdgesCommand(app), []string{
+		"--create",
+		"--from", fmt.Sprintf("decision:%d", decisionID),
+		"--to", "package:internal/cli",
+		"--relation", "affects",
+	}); err != nil {
+		t.Fatalf("create edge: %v", err)
+	}
+	out, _, err := runCommandWithCapture(t, newEdgesCommand(app), []string{"--list"})
+	if err != nil {
+		t.Fatalf("list edges: %v", err)
 	}
 	if !strings.Contains(out, "ExitError is the standard error") {
 		t.Errorf("expected decision title in output, got: %s", out)
 	}
 }
+
+func TestFindWildcardQueries(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/recon\n"), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	mainGo := `package main
+type A struct{}
+type B struct{}
+func (A) Close() error { return nil }
+func (*B) Close() error { return nil }
+func (*B) Open() {}
+func main() {}
+`
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte(mainGo), 0o644); err != nil {
+		t.Fatalf("write main.go: %v", err)
+	}
+
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"*.Close"})
+	if err != nil || !strings.Contains(out, "A.Close") || !strings.Contains(out, "*B.Close") || !strings.Contains(out, "Symbols (2 of 2)") {
+		t.Fatalf("expected both Close methods, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"B.*", "--list", "--json"})
+	if err != nil || !strings.Contains(out, `"total": 2`) || !strings.Contains(out, `"Open"`) {
+		t.Fatalf("expected B method set JSON, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Close", "--list"})
+	if err != nil || !strings.Contains(out, "Symbols (2 of 2)") {
+		t.Fatalf("expected --list to list ambiguous matches, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Missing.*"})
+	if err != nil || !strings.Contains(out, "No symbols match") {
+		t.Fatalf("expected empty wildcard output, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"*", "--list", "--json"})
+	if err == nil || !strings.Contains(out, `"invalid_input"`) {
+		t.Fatalf("expected invalid_input for bare wildcard, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"?lose"})
+	if err != nil || !strings.Contains(out, "Symbols (2 of 2)") {
+		t.Fatalf("expected name glob to list both Close methods, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"^(Open|main)$", "--regex", "--json"})
+	if err != nil || !strings.Contains(out, `"total": 2`) || !strings.Contains(out, `"Open"`) || !strings.Contains(out, `"main"`) {
+		t.Fatalf("expected --regex to list Open and main, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"(", "--regex", "--json"})
+	if err == nil || !strings.Contains(out, `"invalid_input"`) {
+		t.Fatalf("expected invalid_input for a bad regex, out=%q err=%v", out, err)
+	}
+}
+
+func TestSnippetsCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newSnippetsCommand(app), []string{"Ambig", "--package", "pkg1"})
+	if err != nil || !strings.Contains(out, "main.go:3 in Alpha [shortest, most_recent, most_central]") || !strings.Contains(out, "pkg1.Ambig()") {
+		t.Fatalf("unexpected snippets output, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newSnippetsCommand(app), []string{"Ambig", "--package", "pkg2", "--json"})
+	if err != nil || !strings.Contains(out, `"snippets": []`) {
+		t.Fatalf("expected empty snippets JSON, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newSnippetsCommand(app), []string{"Ambig"})
+	if err == nil || !strings.Contains(out, "Try: recon snippets Ambig --package") {
+		t.Fatalf("expected ambiguity hint, out=%q err=%v", out, err)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newSnippetsCommand(app), []string{"Ambig", "--limit", "0"}); err == nil || !strings.Contains(err.Error(), "--limit must be > 0") {
+		t.Fatalf("expected limit validation error, got %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSnippetsCommand(app), nil); err == nil || !strings.Contains(err.Error(), "requires a <symbol>") {
+		t.Fatalf("expected missing argument error, got %v", err)
+	}
+}
+
+func TestAgentsMDCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "AGENTS.md"), []byte("# Notes\n\nKeep me.\n"), 0o644); err != nil {
+		t.Fatalf("write AGENTS.md: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newAgentsMDCommand(app), []string{"--stdout"})
+	if err != nil || !strings.HasPrefix(out, "## Recon Project Map") || !strings.Contains(out, "| `pkg1` | pkg1 | 1 |") {
+		t.Fatalf("unexpected --stdout output, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newAgentsMDCommand(app), []string{"--json"})
+	if err != nil || !strings.Contains(out, `"changed": true`) {
+		t.Fatalf("expected changed=true, out=%q err=%v", out, err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "AGENTS.md"))
+	if err != nil || !strings.HasPrefix(string(data), "# Notes\n\nKeep me.\n\n## Recon Project Map") {
+		t.Fatalf("unexpected AGENTS.md, data=%q err=%v", data, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newAgentsMDCommand(app), nil)
+	if err != nil || !strings.Contains(out, "AGENTS.md is up to date") {
+		t.Fatalf("expected idempotent re-run, out=%q err=%v", out, err)
+	}
+
+	orig := installAgentsSection
+	defer func() { installAgentsSection = orig }()
+	installAgentsSection = func(string, []byte) (bool, error) { return false, errors.New("disk full") }
+	if _, _, err := runCommandWithCapture(t, newAgentsMDCommand(app), nil); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("expected write error, got %v", err)
+	}
+}
+
+func TestOnboardingCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newOnboardingCommand(app), []string{"--auto-sync"})
+	if err != nil || !strings.HasPrefix(out, "# Onboarding: ") || !strings.Contains(out, "| `pkg1` | — | 1 |") || strings.Contains(out, "stale") {
+		t.Fatalf("unexpected onboarding output, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newOnboardingCommand(app), []string{"--json"})
+	if err != nil || !strings.Contains(out, `"testing": {`) || !strings.Contains(out, `"go test ./..."`) {
+		t.Fatalf("unexpected onboarding JSON, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newOnboardingCommand(app), []string{"--output", "docs/ONBOARDING.md"})
+	if err == nil {
+		t.Fatalf("expected error writing into a missing directory, out=%q", out)
+	}
+	out, _, err = runCommandWithCapture(t, newOnboardingCommand(app), []string{"--output", "ONBOARDING.md", "--json"})
+	if err != nil || !strings.Contains(out, `"path": "ONBOARDING.md"`) {
+		t.Fatalf("onboarding --output --json failed, out=%q err=%v", out, err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "ONBOARDING.md"))
+	if err != nil || !strings.Contains(string(data), "## Running the tests") {
+		t.Fatalf("unexpected ONBOARDING.md, data=%q err=%v", data, err)
+	}
+}
+
+func TestSyncKnowledgeFiles(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".recon", "config.json"), []byte(`{"knowledge":{"files":true}}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	dir := filepath.Join(root, ".recon", "knowledge")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "no-cgo.yaml"), []byte("kind: decision\ntitle: No cgo\nreasoning: Static builds.\n"), 0o644); err != nil {
+		t.Fatalf("write knowledge file: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newSyncCommand(app), []string{"--json"})
+	if err != nil || !strings.Contains(out, `"knowledge_files": {`) || !strings.Contains(out, `"action": "created"`) {
+		t.Fatalf("sync --json with knowledge files, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newSyncCommand(app), nil)
+	if err != nil || strings.Contains(out, "Knowledge files:") {
+		t.Fatalf("second sync should report no knowledge changes, out=%q err=%v", out, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "no-cgo.yaml"), []byte("kind: decision\n"), 0o644); err != nil {
+		t.Fatalf("write knowledge file: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newSyncCommand(app), nil)
+	if err != nil || !strings.Contains(out, "- invalid .recon/knowledge/no-cgo.yaml: title is required") {
+		t.Fatalf("sync with a broken knowledge file, out=%q err=%v", out, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, ".recon", "config.json"), []byte(`{"knowledge":{"files":"yes"}}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newSyncCommand(app), []string{"--json"})
+	if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("sync with an invalid config should fail, out=%q err=%v", out, err)
+	}
+}
+
+func TestSnapshotCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root, NoPrompt: true}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newSnapshotCommand(app), []string{"list"})
+	if err != nil || !strings.Contains(out, "No snapshots") {
+		t.Fatalf("empty snapshot list, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newSnapshotCommand(app), []string{"create", "--label", "first", "--json"})
+	if err != nil || !strings.Contains(out, `-first"`) || !strings.Contains(out, `"pruned": null`) {
+		t.Fatalf("snapshot create --json failed, out=%q err=%v", out, err)
+	}
+	gitignore, err := os.ReadFile(filepath.Join(root, ".gitignore"))
+	if err != nil || !strings.Contains(string(gitignore), ".recon/snapshots/") {
+		t.Fatalf("snapshots not gitignored, data=%q err=%v", gitignore, err)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newSnapshotCommand(app), []string{"restore", "latest", "--json"})
+	if err != nil || !strings.Contains(out, `"restored": {`) || !strings.Contains(out, `-pre-restore"`) {
+		t.Fatalf("snapshot restore --json failed, out=%q err=%v", out, err)
+	}
+	conn, err := openExistingDB(app)
+	if err != nil {
+		t.Fatalf("open restored db: %v", err)
+	}
+	var files int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM files;").Scan(&files); err != nil || files != 0 {
+		t.Fatalf("files after restore = %d, err=%v; want the pre-sync database", files, err)
+	}
+	conn.Close()
+
+	out, _, err = runCommandWithCapture(t, newSnapshotCommand(app), []string{"create", "--keep", "1"})
+	if err != nil || strings.Count(out, "Pruned snapshot") != 2 {
+		t.Fatalf("snapshot create --keep failed, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newSnapshotCommand(app), []string{"list", "--json"})
+	if err != nil || strings.Count(out, `"name"`) != 1 {
+		t.Fatalf("snapshot list --json failed, out=%q err=%v", out, err)
+	}
+
+	for _, args := range [][]string{
+		{"restore", "--json"},
+		{"restore", "missing", "--json"},
+		{"create", "--label", "a/b", "--json"},
+		{"create", "--keep", "-1", "--json"},
+	} {
+		out, _, err = runCommandWithCapture(t, newSnapshotCommand(app), args)
+		if err == nil || !strings.Contains(out, `"code": "`) {
+			t.Fatalf("snapshot %v: expected JSON error, out=%q err=%v", args, out, err)
+		}
+	}
+}
+
+func TestMergeCommand(t *testing.T) {
+	theirRoot := setupModuleRoot(t)
+	theirApp := &App{Context: context.Background(), ModuleRoot: theirRoot, NoPrompt: true}
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root, NoPrompt: true}
+	for _, a := range []*App{theirApp, app} {
+		if _, _, err := runCommandWithCapture(t, newInitCommand(a), nil); err != nil {
+			t.Fatalf("init: %v", err)
+		}
+	}
+	seed := func(a *App, reasoning string) {
+		conn, err := openExistingDB(a)
+		if err != nil {
+			t.Fatalf("open db: %v", err)
+		}
+		defer conn.Close()
+		if _, err := conn.Exec(`INSERT INTO decisions (title, reasoning, confidence, status, created_at, updated_at) VALUES ('Use SQLite', ?, 'high', 'active', '2026-01-01T00:00:00Z', '2026-01-01T00:00:00Z');`, reasoning); err != nil {
+			t.Fatalf("seed decision: %v", err)
+		}
+	}
+	seed(theirApp, "Single file.")
+
+	bundle := filepath.Join(t.TempDir(), "team.json")
+	out, _, err := runCommandWithCapture(t, newMergeCommand(theirApp), []string{"--export", bundle})
+	if err != nil || !strings.Contains(out, "Exported 1 decisions, 0 patterns, and 0 edges") {
+		t.Fatalf("merge --export failed, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newMergeCommand(app), []string{bundle, "--dry-run"})
+	if err != nil || !strings.Contains(out, "1 added") || !strings.Contains(out, "+ decision #1 Use SQLite") || !strings.Contains(out, "Dry run") {
+		t.Fatalf("merge --dry-run failed, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newMergeCommand(app), []string{db.DBPath(theirRoot), "--json"})
+	if err != nil || !strings.Contains(out, `"title": "Use SQLite"`) || !strings.Contains(out, `"dry_run": false`) {
+		t.Fatalf("merge db --json failed, out=%q err=%v", out, err)
+	}
+
+	seed(theirApp, "Single file, no server.")
+	conn, err := openExistingDB(theirApp)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if _, err := conn.Exec(`UPDATE decisions SET status = 'archived' WHERE reasoning = 'Single file.';`); err != nil {
+		t.Fatalf("archive decision: %v", err)
+	}
+	conn.Close()
+	out, _, err = runCommandWithCapture(t, newMergeCommand(app), []string{db.DBPath(theirRoot)})
+	if err != nil || !strings.Contains(out, "1 conflicts") || !strings.Contains(out, ": unresolved") || !strings.Contains(out, "rerun with --ours or --theirs") {
+		t.Fatalf("merge with conflict failed, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newMergeCommand(app), []string{db.DBPath(theirRoot), "--theirs"})
+	if err != nil || !strings.Contains(out, ": theirs") {
+		t.Fatalf("merge --theirs failed, out=%q err=%v", out, err)
+	}
+
+	for _, args := range [][]string{
+		{"--json"},
+		{bundle, "--export", bundle, "--json"},
+		{bundle, "--ours", "--theirs", "--json"},
+		{db.DBPath(root), "--json"},
+		{filepath.Join(root, "missing.json"), "--json"},
+	} {
+		out, _, err = runCommandWithCapture(t, newMergeCommand(app), args)
+		if err == nil || !strings.Contains(out, `"code": "`) {
+			t.Fatalf("merge %v: expected JSON error, out=%q err=%v", args, out, err)
+		}
+	}
+}
+
+func TestSyncFilesCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\nfunc Alpha(){ }\nfunc Beta(){ }\n"), 0o644); err != nil {
+		t.Fatalf("write main.go: %v", err)
+	}
+	out, _, err := runCommandWithCapture(t, newSyncCommand(app), []string{"--files", "main.go", "--json"})
+	if err != nil || !strings.Contains(out, `"files": [`) || !strings.Contains(out, `"files_modified": 1`) {
+		t.Fatalf("sync --files --json failed out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newSyncCommand(app), []string{"--files", "main.go"})
+	if err != nil || !strings.Contains(out, "Re-indexed 1 of") {
+		t.Fatalf("sync --files text failed out=%q err=%v", out, err)
+	}
+
+	for _, args := range [][]string{
+		{"--files", "--json"},
+		{"main.go", "--json"},
+		{"--files", "README.md", "--json"},
+	} {
+		out, _, err = runCommandWithCapture(t, newSyncCommand(app), args)
+		if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+			t.Fatalf("sync %v: expected invalid_input, out=%q err=%v", args, out, err)
+		}
+	}
+	if _, _, err = runCommandWithCapture(t, newSyncCommand(app), []string{"--files"}); err == nil {
+		t.Fatal("expected text-mode error for --files without paths")
+	}
+}
+
+func TestSyncProgress(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	origTerminal, origInterval := stderrIsTerminal, spinnerInterval
+	t.Cleanup(func() { stderrIsTerminal, spinnerInterval = origTerminal, origInterval })
+	terminal := false
+	stderrIsTerminal = func() bool { return terminal }
+
+	out, errOut, err := runCommandWithCapture(t, newSyncCommand(app), []string{"--verbose"})
+	if err != nil || !strings.Contains(out, "Timings: collect ") {
+		t.Fatalf("sync --verbose out=%q err=%v", out, err)
+	}
+	if want := "Collecting files...\nParsing 3 files...\nLinking imports, methods, and renames...\nCommitting...\n"; errOut != want {
+		t.Fatalf("sync --verbose stderr = %q, want %q", errOut, want)
+	}
+	if _, errOut, err = runCommandWithCapture(t, newSyncCommand(app), nil); err != nil || errOut != "" {
+		t.Fatalf("sync without a terminal should be silent on stderr, got %q, %v", errOut, err)
+	}
+
+	terminal = true
+	spinnerInterval = time.Millisecond
+	out, errOut, err = runCommandWithCapture(t, newSyncCommand(app), nil)
+	if err != nil || strings.Contains(out, "Timings:") || !strings.Contains(errOut, "Collecting files...") || !strings.HasSuffix(errOut, "\r\033[K") {
+		t.Fatalf("sync on a terminal out=%q stderr=%q err=%v", out, errOut, err)
+	}
+	if _, errOut, err = runCommandWithCapture(t, newSyncCommand(app), []string{"--quiet"}); err != nil || errOut != "" {
+		t.Fatalf("sync --quiet stderr = %q, err=%v", errOut, err)
+	}
+	if _, _, err = runCommandWithCapture(t, newSyncCommand(app), []string{"--quiet", "--verbose"}); err == nil {
+		t.Fatal("expected --quiet and --verbose to be mutually exclusive")
+	}
+}
+
+func TestSyncLock(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if ignore, err := os.ReadFile(filepath.Join(root, ".gitignore")); err != nil || !strings.Contains(string(ignore), ".recon/sync.lock\n") {
+		t.Fatalf("expected the sync lock ignored, got %q (%v)", ignore, err)
+	}
+	lock, err := index.LockSync(context.Background(), root, false)
+	if err != nil {
+		t.Fatalf("LockSync: %v", err)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err == nil || !strings.Contains(err.Error(), "another sync is running") || !strings.Contains(err.Error(), "--wait") {
+		t.Fatalf("expected another sync is running, got %v", err)
+	}
+	out, _, err := runCommandWithCapture(t, newSyncCommand(app), []string{"--json"})
+	if err == nil || !strings.Contains(out, `"code": "sync_running"`) || !strings.Contains(out, fmt.Sprintf(`"pid": %d`, os.Getpid())) {
+		t.Fatalf("sync --json with the lock held: out=%q err=%v", out, err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = lock.Unlock()
+	}()
+	out, errOut, err := runCommandWithCapture(t, newSyncCommand(app), []string{"--wait"})
+	if err != nil || !strings.Contains(out, "Synced 3 files") || !strings.Contains(errOut, "Waiting for another sync") {
+		t.Fatalf("sync --wait out=%q stderr=%q err=%v", out, errOut, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".recon", index.SyncLockFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the lock released after sync, got %v", err)
+	}
+}
+
+func TestMigrateCommands(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newMigrateCommand(app), []string{"status"}); err == nil || !strings.Contains(err.Error(), "not initialized") {
+		t.Fatalf("expected migrate status to need init, got %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	latest, err := db.LatestMigrationVersion()
+	if err != nil {
+		t.Fatalf("LatestMigrationVersion: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newMigrateCommand(app), []string{"status"})
+	if err != nil || !strings.Contains(out, fmt.Sprintf("Schema version: %d (this recon: %d)\nUp to date", latest, latest)) {
+		t.Fatalf("migrate status out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newMigrateCommand(app), []string{"up"})
+	if err != nil || !strings.Contains(out, "is up to date") {
+		t.Fatalf("migrate up with nothing pending out=%q err=%v", out, err)
+	}
+	if snaps, _ := snapshot.List(root); len(snaps) != 0 {
+		t.Fatalf("expected no backup without a schema change, got %v", snaps)
+	}
+
+	out, _, err = runCommandWithCapture(t, newMigrateCommand(app), []string{"down", "--json"})
+	if err == nil || !strings.Contains(out, `"code": "confirmation_required"`) {
+		t.Fatalf("migrate down without --force out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newMigrateCommand(app), []string{"down", "--steps", "0"}); err == nil || !strings.Contains(err.Error(), "--steps must be at least 1") {
+		t.Fatalf("expected --steps 0 refused, got %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newMigrateCommand(app), []string{"down", "--steps", "1000", "--force"}); err == nil || !strings.Contains(err.Error(), "exceeds the") {
+		t.Fatalf("expected too many steps refused, got %v", err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newMigrateCommand(app), []string{"down", "--force", "--json"})
+	var down migratePayload
+	if err != nil || json.Unmarshal([]byte(out), &down) != nil || down.From != latest || down.To != latest-1 || len(down.Migrations) != 1 || down.Backup == nil {
+		t.Fatalf("migrate down --force --json out=%q err=%v", out, err)
+	}
+	if !strings.HasSuffix(down.Backup.Name, "-pre-migrate-down") {
+		t.Fatalf("unexpected backup %q", down.Backup.Name)
+	}
+	out, _, err = runCommandWithCapture(t, newMigrateCommand(app), []string{"status"})
+	if err != nil || !strings.Contains(out, "Pending migrations: 1; apply them with `recon migrate up`") {
+		t.Fatalf("migrate status after down out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newMigrateCommand(app), []string{"up"})
+	if err != nil || !strings.Contains(out, fmt.Sprintf("Migrated schema %d -> %d", latest-1, latest)) || !strings.Contains(out, "-pre-migrate\n") {
+		t.Fatalf("migrate up out=%q err=%v", out, err)
+	}
+
+	// init migrates an older database too, and backs it up first.
+	if _, _, err := runCommandWithCapture(t, newMigrateCommand(app), []string{"down", "--force"}); err != nil {
+		t.Fatalf("migrate down: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newInitCommand(app), []string{"--force"})
+	if err != nil || !strings.Contains(out, "The database before migrating was saved as snapshot") {
+		t.Fatalf("init on an older database out=%q err=%v", out, err)
+	}
+	if snaps, _ := snapshot.List(root); len(snaps) != 4 {
+		t.Fatalf("expected four backups, got %v", snaps)
+	}
+}
+
+func TestOpenExistingDBOutOfDateSchema(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	latest, _ := db.LatestMigrationVersion()
+	rollback := func() {
+		t.Helper()
+		if _, _, err := runCommandWithCapture(t, newMigrateCommand(app), []string{"down", "--force"}); err != nil {
+			t.Fatalf("migrate down: %v", err)
+		}
+	}
+	origInteractive, origAsk := isInteractive, askYesNo
+	t.Cleanup(func() { isInteractive, askYesNo = origInteractive, origAsk })
+	isInteractive = func() bool { return false }
+	rollback()
+
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"--list-packages", "--json"})
+	if err == nil || !strings.Contains(out, `"code": "schema_out_of_date"`) || !strings.Contains(out, fmt.Sprintf(`"expected": %d`, latest)) || !strings.Contains(out, "recon init --force") {
+		t.Fatalf("find on an older schema out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newStatusCommand(app), nil); err != nil {
+		t.Fatalf("status should read any schema version: %v", err)
+	}
+
+	// A declined prompt leaves the database alone.
+	isInteractive = func() bool { return true }
+	askYesNo = func(string, bool) (bool, error) { return false, nil }
+	if _, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"--list-packages"}); err == nil || !strings.Contains(err.Error(), "rerun with --yes") {
+		t.Fatalf("expected a declined migration to refuse, got %v", err)
+	}
+	askYesNo = func(string, bool) (bool, error) { return true, nil }
+	if _, errOut, err := runCommandWithCapture(t, newFindCommand(app), []string{"--list-packages"}); err != nil || !strings.Contains(errOut, fmt.Sprintf("from schema version %d to %d", latest-1, latest)) {
+		t.Fatalf("find after agreeing to migrate stderr=%q err=%v", errOut, err)
+	}
+
+	isInteractive = func() bool { return false }
+	rollback()
+	yes := &App{Context: context.Background(), ModuleRoot: root, Yes: true}
+	if _, errOut, err := runCommandWithCapture(t, newFindCommand(yes), []string{"--list-packages", "--json"}); err != nil || !strings.Contains(errOut, "-pre-migrate") {
+		t.Fatalf("find --yes stderr=%q err=%v", errOut, err)
+	}
+}
+
+func TestFindCallers(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--package", "pkg1", "--callers", "--json"})
+	if err != nil || !strings.Contains(out, `"callers": [`) || !strings.Contains(out, `"calls": "Ambig"`) {
+		t.Fatalf("find --callers --json failed out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--package", "pkg1", "--callers-depth", "2", "--no-body"})
+	if err != nil || !strings.Contains(out, "Callers (depth 2):") || !strings.Contains(out, "- [1] func Alpha (main.go:") {
+		t.Fatalf("find --callers-depth text failed out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--package", "pkg1"})
+	if err != nil || strings.Contains(out, "Callers") {
+		t.Fatalf("expected no callers section without the flag, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--callers-depth", "0", "--json"})
+	if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("expected invalid_input for --callers-depth 0, out=%q err=%v", out, err)
+	}
+}
+
+func TestSchemaCommand(t *testing.T) {
+	out, _, err := runCommandWithCapture(t, newSchemaCommand(), []string{"--sql"})
+	if err != nil || !strings.HasPrefix(out, "-- recon schema version") || !strings.Contains(out, "CREATE TABLE symbols") {
+		t.Fatalf("schema --sql failed out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newSchemaCommand(), []string{"--go", "--package", "client"})
+	if err != nil || !strings.Contains(out, "package client") || !strings.Contains(out, "type FindResult struct") ||
+		!strings.Contains(out, "type OrientPayload struct") || !strings.Contains(out, "type ErrorEnvelope struct") {
+		t.Fatalf("schema --go failed out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newSchemaCommand(), []string{"orient"})
+	if err != nil || !strings.Contains(out, `"$schema": "https://json-schema.org/draft/2020-12/schema"`) ||
+		!strings.Contains(out, `"$ref": "#/$defs/OrientPayload"`) {
+		t.Fatalf("schema orient failed out=%q err=%v", out, err)
+	}
+
+	for _, args := range [][]string{nil, {"--sql", "--go"}, {"--go", "--package", "bad-name"}, {"bogus"}, {"orient", "--sql"}, {"orient", "find"}} {
+		if _, _, err := runCommandWithCapture(t, newSchemaCommand(), args); err == nil {
+			t.Fatalf("schema %v: expected error", args)
+		}
+	}
+
+	orig := currentSchema
+	defer func() { currentSchema = orig }()
+	currentSchema = func(context.Context) (string, error) { return "", errors.New("boom") }
+	if _, _, err := runCommandWithCapture(t, newSchemaCommand(), []string{"--sql"}); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected schema error, got %v", err)
+	}
+}
+
+func TestFindFields(t *testing.T) {
+	root := setupModuleRoot(t)
+	if err := os.WriteFile(filepath.Join(root, "store.go"), []byte("package main\n"+
+		"type Store struct {\n\tName string `json:\"name\"`\n}\n"+
+		"func (s *Store) Save() error { return nil }\n"+
+		"type Saver interface { Save() error }\n"), 0o644); err != nil {
+		t.Fatalf("write store.go: %v", err)
+	}
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"Store", "--fields", "--json"})
+	if err != nil || !strings.Contains(out, `"fields": [`) || !strings.Contains(out, `"tag": "json:\"name\""`) ||
+		!strings.Contains(out, `"pointer_receiver": true`) {
+		t.Fatalf("find --fields --json failed out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Store", "--json"})
+	if err != nil || strings.Contains(out, `"fields"`) {
+		t.Fatalf("expected no fields without the flag, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Store", "--fields", "--no-body"})
+	if err != nil || !strings.Contains(out, "- Name string `json:\"name\"`") || !strings.Contains(out, "- (*Store) Save() error (store.go:5)") {
+		t.Fatalf("find --fields text failed out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Saver", "--fields", "--no-body"})
+	if err != nil || !strings.Contains(out, "Methods:\n- Save() error\n") {
+		t.Fatalf("find interface --fields failed out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Alpha", "--fields", "--no-body"})
+	if err != nil || !strings.Contains(out, "is not a type") {
+		t.Fatalf("find func --fields failed out=%q err=%v", out, err)
+	}
+}
+
+func TestFindEnum(t *testing.T) {
+	root := setupModuleRoot(t)
+	if err := os.WriteFile(filepath.Join(root, "color.go"), []byte("package main\n"+
+		"type Color int\n"+
+		"const (\n\tRed Color = iota\n\tGreen\n)\n"), 0o644); err != nil {
+		t.Fatalf("write color.go: %v", err)
+	}
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"Color", "--no-body"})
+	if err != nil || !strings.HasPrefix(out, "type Color") || !strings.Contains(out, "Members:\n- Red = 0\n- Green = 1\n") {
+		t.Fatalf("find enum type failed out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"--kind", "enum"})
+	if err != nil || !strings.Contains(out, "- enum Color (color.go:3-6)") || !strings.Contains(out, "  Red = 0, Green = 1\n") {
+		t.Fatalf("find --kind enum failed out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Color", "--kind", "enum", "--json"})
+	if err != nil || !strings.Contains(out, `"kind": "enum"`) || !strings.Contains(out, `"members": [`) {
+		t.Fatalf("find enum --json failed out=%q err=%v", out, err)
+	}
+}
+
+func TestDigestCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+
+	out, _, err := runCommandWithCapture(t, newDigestCommand(app), []string{"--json"})
+	if err == nil || !strings.Contains(out, `"code": "not_initialized"`) {
+		t.Fatalf("expected not_initialized before init, out=%q err=%v", out, err)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	createTestDecision(t, app, "Digest decision")
+
+	out, _, err = runCommandWithCapture(t, newDigestCommand(app), nil)
+	if err != nil {
+		t.Fatalf("digest: %v", err)
+	}
+	for _, want := range []string{"# Recon digest:", "## Sync activity", "full", "| created | decision #1 | Digest decision |", "## Hotspots"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("markdown digest missing %q:\n%s", want, out)
+		}
+	}
+
+	out, _, err = runCommandWithCapture(t, newDigestCommand(app), []string{"--since", "2w", "--format", "TEXT"})
+	if err != nil || !strings.Contains(out, "Sync activity:") || !strings.Contains(out, "created decision #1 Digest decision") {
+		t.Fatalf("text digest out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newDigestCommand(app), []string{"--json"})
+	if err != nil {
+		t.Fatalf("digest --json: %v", err)
+	}
+	var payload digest.Digest
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		t.Fatalf("decode digest: %v\n%s", err, out)
+	}
+	if payload.Syncs.Runs == 0 || len(payload.Knowledge) != 1 {
+		t.Fatalf("unexpected digest payload: %+v", payload)
+	}
+
+	for _, args := range [][]string{{"--since", "soon"}, {"--format", "html"}} {
+		out, _, err := runCommandWithCapture(t, newDigestCommand(app), append(args, "--json"))
+		if err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+			t.Fatalf("%v: expected invalid_input, out=%q err=%v", args, out, err)
+		}
+		if _, _, err := runCommandWithCapture(t, newDigestCommand(app), args); err == nil {
+			t.Fatalf("%v: expected error", args)
+		}
+	}
+}
+
+func TestDiagnosticsCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if out, _, err := runCommandWithCapture(t, newDiagnosticsCommand(app), []string{"--json"}); err == nil || !strings.Contains(out, `"code": "not_initialized"`) {
+		t.Fatalf("expected not_initialized, out=%q err=%v", out, err)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	out, _, err := runCommandWithCapture(t, newDiagnosticsCommand(app), nil)
+	if err != nil || out != "No diagnostics.\n" {
+		t.Fatalf("clean diagnostics: out=%q err=%v", out, err)
+	}
+
+	if out, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+		"Ambig stays small", "--reasoning", "r", "--evidence-summary", "a.go exists", "--check-type", "file_exists",
+		"--check-path", "pkg2/a.go", "--affects", "pkg2/a.go", "--affects", "pkg2.Ambig", "--json",
+	}); err != nil {
+		t.Fatalf("decide: %v (out=%q)", err, out)
+	}
+	conn, err := openExistingDB(app)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if _, err := conn.Exec(`UPDATE evidence SET drift_status = 'broken' WHERE entity_type = 'decision'`); err != nil {
+		t.Fatalf("break evidence: %v", err)
+	}
+	conn.Close()
+
+	out, _, err = runCommandWithCapture(t, newDiagnosticsCommand(app), nil)
+	if err != nil || !strings.Contains(out, `pkg2/a.go:1:1: error: decision #1 "Ambig stays small": evidence is broken (a.go exists) [drift_broken]`) {
+		t.Fatalf("text diagnostics: out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newDiagnosticsCommand(app), []string{filepath.Join(root, "pkg2", "a.go"), "main.go", "--json"})
+	if err != nil {
+		t.Fatalf("json diagnostics: %v", err)
+	}
+	var files []diagnostics.File
+	if err := json.Unmarshal([]byte(out), &files); err != nil {
+		t.Fatalf("decode: %v\n%s", err, out)
+	}
+	if len(files) != 2 || files[0].Path != "main.go" || len(files[0].Diagnostics) != 0 ||
+		files[1].Path != "pkg2/a.go" || len(files[1].Diagnostics) != 2 || files[1].URI != "file://"+filepath.ToSlash(filepath.Join(root, "pkg2", "a.go")) {
+		t.Fatalf("unexpected json diagnostics: %s", out)
+	}
+
+	out, _, err = runCommandWithCapture(t, newDiagnosticsCommand(app), []string{"../outside.go", "--json"})
+	if err == nil || !strings.Contains(out, `"invalid_input"`) {
+		t.Fatalf("expected invalid_input, out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newDiagnosticsCommand(app), []string{"../outside.go"}); err == nil {
+		t.Fatal("expected text-mode error")
+	}
+}
+
+func TestGuardCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+
+	// Hook mode lets edits through before init; plain mode reports it.
+	hookPayload := fmt.Sprintf(`{"tool_name":"Edit","tool_input":{"file_path":%q}}`, filepath.Join(root, "pkg1", "a.go"))
+	hookCmd := newGuardCommand(app)
+	hookCmd.SetIn(strings.NewReader(hookPayload))
+	if out, _, err := runCommandWithCapture(t, hookCmd, []string{"--hook"}); err != nil || out != "" {
+		t.Fatalf("hook before init: out=%q err=%v", out, err)
+	}
+	if out, _, err := runCommandWithCapture(t, newGuardCommand(app), []string{"pkg1/a.go", "--json"}); err == nil || !strings.Contains(out, `"code": "not_initialized"`) {
+		t.Fatalf("expected not_initialized, out=%q err=%v", out, err)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if out, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+		"Keep pkg1 tiny", "--reasoning", "r", "--evidence-summary", "e", "--check-type", "file_exists",
+		"--check-spec", `{"path":"pkg1/a.go"}`, "--confidence", "high", "--json",
+	}); err != nil {
+		t.Fatalf("decide: %v (out=%q)", err, out)
+	}
+	// The title names pkg1, so decide links the decision to the package.
+
+	out, _, err := runCommandWithCapture(t, newGuardCommand(app), []string{"pkg1/a.go"})
+	var exitErr ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != guardExitCode {
+		t.Fatalf("expected guard exit code, got %v", err)
+	}
+	if !strings.Contains(out, "- decision #1 Keep pkg1 tiny [high] via package:pkg1 (affects)") {
+		t.Fatalf("unexpected guard report: %q", out)
+	}
+
+	out, _, err = runCommandWithCapture(t, newGuardCommand(app), []string{filepath.Join(root, "pkg2", "a.go")})
+	if err != nil || !strings.Contains(out, "No guarded knowledge for pkg2/a.go") {
+		t.Fatalf("unguarded file: out=%q err=%v", out, err)
+	}
+
+	out, _, _ = runCommandWithCapture(t, newGuardCommand(app), []string{"pkg1/a.go", "--json"})
+	var result guard.Result
+	if err := json.Unmarshal([]byte(out), &result); err != nil || len(result.Findings) != 1 || result.Findings[0].Via != "package:pkg1" {
+		t.Fatalf("guard --json = %q (%v)", out, err)
+	}
+
+	hookCmd = newGuardCommand(app)
+	hookCmd.SetIn(strings.NewReader(hookPayload))
+	out, _, err = runCommandWithCapture(t, hookCmd, []string{"--hook"})
+	if err != nil {
+		t.Fatalf("hook: %v", err)
+	}
+	var decision hookOutput
+	if err := json.Unmarshal([]byte(out), &decision); err != nil {
+		t.Fatalf("decode hook output: %v\n%s", err, out)
+	}
+	if d := decision.HookSpecificOutput; d.HookEventName != "PreToolUse" || d.PermissionDecision != "ask" || !strings.Contains(d.PermissionDecisionReason, "Keep pkg1 tiny") {
+		t.Fatalf("unexpected hook decision: %+v", d)
+	}
+
+	for _, payload := range []string{`{"tool_name":"Bash","tool_input":{"command":"ls"}}`, `{"tool_input":{"file_path":"/elsewhere/x.go"}}`} {
+		hookCmd = newGuardCommand(app)
+		hookCmd.SetIn(strings.NewReader(payload))
+		if out, _, err := runCommandWithCapture(t, hookCmd, []string{"--hook"}); err != nil || out != "" {
+			t.Fatalf("hook %s: out=%q err=%v", payload, out, err)
+		}
+	}
+	hookCmd = newGuardCommand(app)
+	hookCmd.SetIn(strings.NewReader("not json"))
+	if _, _, err := runCommandWithCapture(t, hookCmd, []string{"--hook"}); err == nil || !strings.Contains(err.Error(), "parse hook payload") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+
+	for _, args := range [][]string{{"--json"}, {"../outside.go", "--json"}, {"pkg1/a.go", "--min-confidence", "extreme", "--json"}} {
+		out, _, err := runCommandWithCapture(t, newGuardCommand(app), args)
+		if err == nil || !strings.Contains(out, `"code"`) {
+			t.Fatalf("%v: expected error envelope, out=%q err=%v", args, out, err)
+		}
+		if _, _, err := runCommandWithCapture(t, newGuardCommand(app), args[:len(args)-1]); err == nil {
+			t.Fatalf("%v: expected text-mode error", args)
+		}
+	}
+}
+
+func TestCoverageCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	profile := filepath.Join(t.TempDir(), "cover.out")
+	body := "mode: set\nexample.com/recon/main.go:3.14,3.29 1 1\nexample.com/recon/main.go:4.13,4.14 1 0\nexample.com/recon/pkg1/a.go:2.15,2.16 1 0\n"
+	if err := os.WriteFile(profile, []byte(body), 0o644); err != nil {
+		t.Fatalf("write profile: %v", err)
+	}
+
+	if out, _, err := runCommandWithCapture(t, newCoverageCommand(app), []string{"import", profile, "--json"}); err == nil || !strings.Contains(out, `"code": "not_initialized"`) {
+		t.Fatalf("expected not_initialized, out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newCoverageCommand(app), []string{"import", profile})
+	if err != nil || !strings.Contains(out, "Imported coverage for 3 functions in 2 files: 33.3% of 3 statements (mode set)") {
+		t.Fatalf("coverage import: out=%q err=%v", out, err)
+	}
+
+	importCmd := newCoverageCommand(app)
+	importCmd.SetIn(strings.NewReader(body))
+	out, _, err = runCommandWithCapture(t, importCmd, []string{"import", "-", "--json"})
+	if err != nil || !strings.Contains(out, `"symbols": 3`) {
+		t.Fatalf("coverage import from stdin: out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Alpha", "--no-body"})
+	if err != nil || !strings.Contains(out, "Coverage: 1/1 statements (100.0%), package . 50.0%") {
+		t.Fatalf("find with coverage: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--package", "pkg1", "--json"})
+	if err != nil || !strings.Contains(out, `"low_coverage_hot_package": false`) || !strings.Contains(out, `"percent": 0`) {
+		t.Fatalf("find --json with coverage: out=%q err=%v", out, err)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"import", "--json"}, `"code": "missing_argument"`},
+		{[]string{"import", filepath.Join(root, "missing.out"), "--json"}, `"code": "invalid_input"`},
+		{[]string{"import", filepath.Join(root, "go.mod"), "--json"}, "invalid cover profile"},
+	} {
+		out, _, err := runCommandWithCapture(t, newCoverageCommand(app), tc.args)
+		if err == nil || !strings.Contains(out, tc.want) {
+			t.Fatalf("coverage %v: expected %q, out=%q err=%v", tc.args, tc.want, out, err)
+		}
+	}
+	other := filepath.Join(t.TempDir(), "other.out")
+	if err := os.WriteFile(other, []byte("mode: set\nexample.com/other/a.go:1.1,2.2 1 1\n"), 0o644); err != nil {
+		t.Fatalf("write other profile: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newCoverageCommand(app), []string{"import", other}); err == nil || !strings.Contains(err.Error(), "run `recon sync`") {
+		t.Fatalf("expected sync hint, got %v", err)
+	}
+}
+
+func TestOrientFocus(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newOrientCommand(app), []string{"--focus", "pkg1", "--json-strict"})
+	if err != nil || !strings.Contains(out, `"path": "pkg1"`) || !strings.Contains(out, `"files": [
+      "pkg1/a.go"
+    ]`) {
+		t.Fatalf("orient --focus --json: out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newOrientCommand(app), []string{"--focus", "./pkg2/", "--json-strict"})
+	if err != nil || strings.Contains(out, "pkg1/a.go") || !strings.Contains(out, `"package_count": 1`) {
+		t.Fatalf("orient --focus ./pkg2/: out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newOrientCommand(app), []string{"--focus", "nope", "--json"})
+	if err == nil || !strings.Contains(out, `"code": "not_found"`) {
+		t.Fatalf("expected not_found for unknown focus, out=%q err=%v", out, err)
+	}
+	_, _, err = runCommandWithCapture(t, newOrientCommand(app), []string{"--focus", "nope"})
+	var exitErr ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 2 || !strings.Contains(exitErr.Message, `"nope"`) {
+		t.Fatalf("expected text-mode focus error, got %v", err)
+	}
+}
+
+func TestExplainSymbolCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if err := os.WriteFile(filepath.Join(root, "pkg1", "a_test.go"), []byte("package pkg1\nimport \"testing\"\nfunc TestAmbig(t *testing.T) { Ambig() }\n"), 0o644); err != nil {
+		t.Fatalf("write test file: %v", err)
+	}
+
+	if out, _, err := runCommandWithCapture(t, newExplainSymbolCommand(app), []string{"Alpha", "--json"}); err == nil || !strings.Contains(out, `"code": "not_initialized"`) {
+		t.Fatalf("expected not_initialized, out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newExplainSymbolCommand(app), []string{"Ambig", "--package", "pkg1", "--no-body"})
+	if err != nil || !strings.Contains(out, "Used by:\n- func Alpha (main.go:3)") || !strings.Contains(out, "- TestAmbig (pkg1/a_test.go:3)") || strings.Contains(out, "Body:") {
+		t.Fatalf("explain-symbol text: out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newExplainSymbolCommand(app), []string{"Alpha", "--json"})
+	if err != nil || !strings.Contains(out, `"gaps": [
+    "no_tests",
+    "no_callers",
+    "no_knowledge"
+  ]`) || !strings.Contains(out, `"body": "func Alpha() { pkg1.Ambig() }"`) {
+		t.Fatalf("explain-symbol --json: out=%q err=%v", out, err)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		code string
+	}{
+		{[]string{"--json"}, "missing_argument"},
+		{[]string{"Ambig", "--json"}, "ambiguous"},
+		{[]string{"Nope", "--json"}, "not_found"},
+		{[]string{"Alpha", "--kind", "bogus", "--json"}, "invalid_input"},
+	} {
+		out, _, err := runCommandWithCapture(t, newExplainSymbolCommand(app), tc.args)
+		if err == nil || !strings.Contains(out, `"code": "`+tc.code+`"`) {
+			t.Fatalf("explain-symbol %v: expected %s, out=%q err=%v", tc.args, tc.code, out, err)
+		}
+	}
+}
+
+func TestEmbedCommandAndSemanticRecall(t *testing.T) {
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(app.ModuleRoot, "pkg1", "a.go"), []byte("package pkg1\n\n// Ambig resolves the retry budget.\nfunc Ambig() {}\n"), 0o644); err != nil {
+		t.Fatalf("write pkg1/a.go: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("resync: %v", err)
+	}
+	createTestDecision(t, app, "Retries are bounded")
+
+	if out, _, err := runCommandWithCapture(t, newEmbedCommand(app), []string{"--json"}); err == nil || !strings.Contains(out, "embeddings are not configured") {
+		t.Fatalf("expected not configured, out=%q err=%v", out, err)
+	}
+	out, errOut, err := runCommandWithCapture(t, newRecallCommand(app), []string{"Retries", "--semantic", "--json"})
+	if err != nil || !strings.Contains(out, `"mode": "fts"`) || !strings.Contains(out, `"fallback": "embeddings are not configured`) || errOut != "" {
+		t.Fatalf("unconfigured semantic recall: out=%q stderr=%q err=%v", out, errOut, err)
+	}
+
+	if err := os.WriteFile(config.Path(app.ModuleRoot), []byte(`{"embeddings":{"provider":"local"}}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	_, errOut, err = runCommandWithCapture(t, newRecallCommand(app), []string{"Retries", "--semantic"})
+	if err != nil || !strings.Contains(errOut, "semantic recall unavailable (no embeddings stored for this provider") {
+		t.Fatalf("semantic recall before embed: stderr=%q err=%v", errOut, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newEmbedCommand(app), nil)
+	if err != nil || out != "Embedded 2 texts with local (1 decision, 1 symbol)\nUnchanged: 0\nRemoved: 0\n" {
+		t.Fatalf("embed: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newEmbedCommand(app), []string{"--json"})
+	if err != nil || !strings.Contains(out, `"embedded": 0`) || !strings.Contains(out, `"unchanged": 2`) {
+		t.Fatalf("embed again: out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newRecallCommand(app), []string{"retry budget", "--semantic"})
+	if err != nil || !strings.HasPrefix(out, "- [symbol] func pkg1.Ambig (pkg1/a.go:4) score=") || !strings.Contains(out, "- [decision] #1 Retries are bounded [medium] drift=ok score=") {
+		t.Fatalf("semantic recall: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newRecallCommand(app), []string{"retry budget", "--semantic", "--kind", "decision", "--json"})
+	if err != nil || !strings.Contains(out, `"mode": "semantic"`) || strings.Contains(out, `"symbol"`) {
+		t.Fatalf("semantic recall --kind decision: out=%q err=%v", out, err)
+	}
+	if out, _, err := runCommandWithCapture(t, newRecallCommand(app), []string{"--semantic", "--since", "7d", "--json"}); err == nil || !strings.Contains(out, "missing_argument") {
+		t.Fatalf("expected semantic recall to need a query, out=%q err=%v", out, err)
+	}
+}
+
+func TestAuditKnowledgeCommand(t *testing.T) {
+	app := setupInitializedApp(t)
+	out, _, err := runCommandWithCapture(t, newAuditKnowledgeCommand(app), nil)
+	if err != nil || out != "Audited 0 decisions, patterns, and constraints: no findings\n" {
+		t.Fatalf("empty audit = %q, %v", out, err)
+	}
+
+	for _, d := range []struct{ title, reasoning string }{
+		{"Log with slog", "Structured output for the log pipeline"},
+		{"Log with zap", "Allocation-free logging on hot paths"},
+	} {
+		if _, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+			d.title, "--reasoning", d.reasoning, "--evidence-summary", "go.mod exists", "--check-type", "file_exists", "--check-path", "go.mod",
+		}); err != nil {
+			t.Fatalf("decide %s: %v", d.title, err)
+		}
+	}
+	conn, err := openExistingDB(app)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	for _, stmt := range []string{
+		`INSERT INTO constraints(id,title,reasoning,confidence,status,created_at,updated_at) VALUES (1,'No Ambig','Ambiguous names','high','active','x','x')`,
+		`INSERT INTO evidence(entity_type,entity_id,summary,check_type,check_spec) VALUES ('constraint',1,'s','grep_absent','{"pattern":"Ambig"}')`,
+		`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES (1,'Ambig helpers','Shared helper per package','medium','active','x','x')`,
+		`INSERT INTO evidence(entity_type,entity_id,summary,check_type,check_spec) VALUES ('pattern',1,'s','grep_pattern','{"pattern":"func Ambig"}')`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	out, _, err = runCommandWithCapture(t, newAuditKnowledgeCommand(app), nil)
+	if err != nil {
+		t.Fatalf("audit-knowledge: %v", err)
+	}
+	for _, want := range []string{
+		"Audited 4 decisions, patterns, and constraints: 2 findings",
+		"Conflicting evidence checks:\n- constraint #1 No Ambig\n  pattern #1 Ambig helpers\n  pattern #1 requires a file in all Go files matching `func Ambig`",
+		"Overlapping titles, different reasoning:\n- decision #1 Log with slog\n  decision #2 Log with zap",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("audit output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, _, err = runCommandWithCapture(t, newAuditKnowledgeCommand(app), []string{"--json"})
+	if err != nil {
+		t.Fatalf("audit-knowledge --json: %v", err)
+	}
+	var report struct {
+		Audited  int `json:"audited"`
+		Findings []struct {
+			Kind string `json:"kind"`
+		} `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil || report.Audited != 4 || len(report.Findings) != 2 || report.Findings[0].Kind != "conflicting_checks" {
+		t.Fatalf("audit json = %s (%v)", out, err)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newAuditKnowledgeCommand(app), []string{"--format", "csv"}); err == nil {
+		t.Fatal("expected an error for --format csv")
+	}
+}
+
+func TestPromptCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+
+	if out, _, err := runCommandWithCapture(t, newPromptCommand(app), []string{"--json"}); err == nil || !strings.Contains(out, `"missing_argument"`) {
+		t.Fatalf("expected missing_argument, out=%q err=%v", out, err)
+	}
+	if out, _, err := runCommandWithCapture(t, newPromptCommand(app), []string{"ambig", "--budget", "0", "--json"}); err == nil || !strings.Contains(out, "--budget must be positive") {
+		t.Fatalf("expected invalid budget, out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	createTestDecision(t, app, "Ambig stays unexported in spirit")
+
+	out, _, err := runCommandWithCapture(t, newPromptCommand(app), []string{"rename the ambig helper"})
+	for _, want := range []string{
+		"# Context: rename the ambig helper",
+		"Keywords: rename, ambig, helper",
+		"- decision #1 Ambig stays unexported in spirit [medium, drift=ok]",
+		"- func Ambig (pkg1/a.go:2-2, package pkg1)\n\n```go\nfunc Ambig() {}\n```",
+		"- pkg2/a.go: Ambig",
+	} {
+		if err != nil || !strings.Contains(out, want) {
+			t.Fatalf("prompt text missing %q: out=%q err=%v", want, out, err)
+		}
+	}
+
+	out, _, err = runCommandWithCapture(t, newPromptCommand(app), []string{"rename the ambig helper", "--json", "--budget", "60"})
+	if err != nil || !strings.Contains(out, `"budget": 60`) || !strings.Contains(out, `"omitted": {`) {
+		t.Fatalf("prompt --json: out=%q err=%v", out, err)
+	}
+	if out, _, err := runCommandWithCapture(t, newPromptCommand(app), []string{"fix it", "--json"}); err == nil || !strings.Contains(out, "task has no keywords") {
+		t.Fatalf("expected no keywords error, out=%q err=%v", out, err)
+	}
+}
+
+func TestFindPlatformVariants(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	// open_windows.go's //go:build line repeats what its name implies.
+	for name, body := range map[string]string{
+		"pkg1/open_linux.go":   "package pkg1\nfunc Open() error { return nil }\n",
+		"pkg1/open_windows.go": "//go:build windows\n\npackage pkg1\n\nfunc Open() error { return nil }\n",
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"Open", "--no-body"})
+	if err != nil || !strings.Contains(out, "Platform: linux\nPlatform variants: linux (pkg1/open_linux.go:2), windows (pkg1/open_windows.go:5)\n") {
+		t.Fatalf("find Open: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"--package", "pkg1"})
+	if err != nil || !strings.Contains(out, "Symbols (2 of 2):") || !strings.Contains(out, "  variants: linux (pkg1/open_linux.go:2), windows (pkg1/open_windows.go:5)\n") {
+		t.Fatalf("find --package pkg1: out=%q err=%v", out, err)
+	}
+}
+
+func TestConstrainCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if out, _, err := runCommandWithCapture(t, newConstrainCommand(app), []string{"--list", "--json"}); err == nil || !strings.Contains(out, `"code": "not_initialized"`) {
+		t.Fatalf("expected not_initialized, out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	if out, _, err := runCommandWithCapture(t, newConstrainCommand(app), []string{"--json"}); err == nil || !strings.Contains(out, `"code": "missing_argument"`) {
+		t.Fatalf("expected missing_argument, out=%q err=%v", out, err)
+	}
+	if out, _, err := runCommandWithCapture(t, newConstrainCommand(app), []string{"No pkg2 in pkg1", "--evidence-summary", "e", "--json"}); err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("expected invalid_input without a pattern, out=%q err=%v", out, err)
+	}
+
+	// The code already mentions Ambig, so a rule forbidding it stays pending.
+	out, _, err := runCommandWithCapture(t, newConstrainCommand(app), []string{
+		"No Ambig anywhere", "--evidence-summary", "no file declares Ambig", "--check-pattern", "Ambig", "--json",
+	})
+	if err == nil || !strings.Contains(out, `"code": "verification_failed"`) {
+		t.Fatalf("expected verification_failed, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newConstrainCommand(app), []string{
+		"Never import pkg2 from pkg1", "--reasoning", "pkg1 stays a leaf", "--evidence-summary", "pkg1 has no pkg2 import",
+		"--check-pattern", `recon/pkg2"`, "--check-scope", "pkg1/*.go", "--affects", "pkg1", "--json",
+	})
+	if err != nil {
+		t.Fatalf("constrain: %v (out=%q)", err, out)
+	}
+	var result constraint.ProposeConstraintResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil || !result.Promoted || result.ConstraintID != 1 {
+		t.Fatalf("unexpected result %+v (%v): %s", result, err, out)
+	}
+
+	out, _, err = runCommandWithCapture(t, newConstrainCommand(app), []string{"--list"})
+	if err != nil || out != "#1 Never import pkg2 from pkg1 (confidence=medium, drift=ok)\n" {
+		t.Fatalf("list: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newEdgesCommand(app), []string{"--from", "constraint:1"})
+	if err != nil || !strings.Contains(out, "pkg1") {
+		t.Fatalf("edges: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newOrientCommand(app), nil)
+	if err != nil || !strings.Contains(out, "Constraints (must hold):\n- #1 Never import pkg2 from pkg1 [medium]\n  Why: pkg1 stays a leaf\n") {
+		t.Fatalf("orient: out=%q err=%v", out, err)
+	}
+
+	if out, _, err := runCommandWithCapture(t, newConstrainCommand(app), []string{"--archive", "1"}); err != nil || out != "Constraint 1 archived.\n" {
+		t.Fatalf("archive: out=%q err=%v", out, err)
+	}
+	if out, _, err := runCommandWithCapture(t, newConstrainCommand(app), []string{"--archive", "1", "--json"}); err == nil || !strings.Contains(out, `"code": "not_found"`) {
+		t.Fatalf("expected not_found, out=%q err=%v", out, err)
+	}
+}
+
+func TestDebugBundleCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+
+	// An uninitialized project still gets a bundle with version details.
+	out, _, err := runCommandWithCapture(t, newDebugBundleCommand(app), []string{"--output", filepath.Join(root, "pre.tar.gz")})
+	if err != nil || !strings.Contains(out, "Debug bundle written") || !strings.Contains(out, "not collected: database: not initialized") {
+		t.Fatalf("uninitialized bundle: out=%q err=%v", out, err)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newDebugBundleCommand(app), []string{"--anonymize", "--query", "SELECT name FROM symbols WHERE name = 'Ambig'", "--json"})
+	if err != nil {
+		t.Fatalf("debug-bundle: %v (out=%q)", err, out)
+	}
+	var payload debugBundlePayload
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		t.Fatalf("unmarshal: %v: %s", err, out)
+	}
+	if filepath.Dir(payload.Path) != db.ReconDir(root) || !payload.Manifest.Anonymized || len(payload.Manifest.Errors) != 0 {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+	if !strings.Contains(strings.Join(payload.Manifest.Files, ","), "query.json") {
+		t.Fatalf("files = %v", payload.Manifest.Files)
+	}
+	if info, err := os.Stat(payload.Path); err != nil || info.Size() == 0 {
+		t.Fatalf("bundle file: %v", err)
+	}
+
+	if out, _, err := runCommandWithCapture(t, newDebugBundleCommand(app), []string{"--output", filepath.Join(root, "main.go", "x.tar.gz"), "--json"}); err == nil || !strings.Contains(out, `"code": "internal_error"`) {
+		t.Fatalf("expected internal_error, out=%q err=%v", out, err)
+	}
+}
+
+func TestLintArchCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if out, _, err := runCommandWithCapture(t, newLintArchCommand(app), []string{"--json"}); err == nil || !strings.Contains(out, `"code": "not_initialized"`) {
+		t.Fatalf("expected not_initialized, out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newLintArchCommand(app), nil)
+	if err != nil || !strings.Contains(out, "No import cycles or layering violations (3 packages, 0 rules).") {
+		t.Fatalf("clean lint-arch: out=%q err=%v", out, err)
+	}
+
+	// main.go imports pkg1 and never pkg2, so only the pkg2 constraint verifies.
+	out, _, err = runCommandWithCapture(t, newConstrainCommand(app), []string{
+		"Root never imports pkg2", "--check-type", "import_absent", "--evidence-summary", "main.go has no pkg2 import",
+		"--check-pattern", "pkg2", "--check-scope", ".", "--json",
+	})
+	if err != nil || !strings.Contains(out, `"promoted": true`) {
+		t.Fatalf("constrain import_absent: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newConstrainCommand(app), []string{
+		"Nothing imports pkg1", "--check-type", "import_absent", "--evidence-summary", "e", "--check-pattern", "pkg1", "--json",
+	})
+	if err == nil || !strings.Contains(out, `"code": "verification_failed"`) || !strings.Contains(out, "forbidden import found in 1 package edge(s)") {
+		t.Fatalf("expected verification_failed, out=%q err=%v", out, err)
+	}
+
+	cfg := `{"architecture":{"layers":[{"name":"lib","packages":["pkg1"]},{"name":"app","packages":["."]}],"forbidden_imports":[{"to":"pkg1","reason":"pkg1 is deprecated"}]}}`
+	if err := os.WriteFile(config.Path(root), []byte(cfg), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newLintArchCommand(app), []string{"--json"})
+	var exitErr ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != lintArchExitCode {
+		t.Fatalf("expected exit %d, got %v (out=%q)", lintArchExitCode, err, out)
+	}
+	var result archlint.Result
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("unmarshal: %v: %s", err, out)
+	}
+	if result.OK || len(result.Violations) != 2 || len(result.Rules) != 3 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	out, _, _ = runCommandWithCapture(t, newLintArchCommand(app), nil)
+	for _, want := range []string{"Layering violations (2):", "- . -> pkg1 [layer] layer app must not import layer lib", "- . -> pkg1 [config] pkg1 is deprecated", "    main.go"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("text output missing %q:\n%s", want, out)
+		}
+	}
+
+	app.AbsPaths = true
+	out, _, err = runCommandWithCapture(t, newLintArchCommand(app), []string{"--format", "sarif"})
+	app.AbsPaths = false
+	if !errors.As(err, &exitErr) || exitErr.Code != lintArchExitCode {
+		t.Fatalf("sarif: expected exit %d, got %v (out=%q)", lintArchExitCode, err, out)
+	}
+	var log sarif.Log
+	if err := json.Unmarshal([]byte(out), &log); err != nil {
+		t.Fatalf("unmarshal sarif: %v: %s", err, out)
+	}
+	if log.Version != sarif.Version || len(log.Runs) != 1 || len(log.Runs[0].Results) != 2 {
+		t.Fatalf("unexpected sarif log: %s", out)
+	}
+	got := log.Runs[0].Results
+	if got[0].RuleID != "layer_violation" || got[0].Message.Text != ". imports pkg1, breaking the architecture layers: layer app must not import layer lib" ||
+		got[1].RuleID != "forbidden_import" || got[0].Locations[0].PhysicalLocation.ArtifactLocation.URI != "main.go" {
+		t.Fatalf("unexpected sarif results: %+v", got)
+	}
+	if out, _, err := runCommandWithCapture(t, newLintArchCommand(app), []string{"--format", "xml", "--json"}); err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("expected invalid_input for --format xml, out=%q err=%v", out, err)
+	}
+
+	if err := os.WriteFile(config.Path(root), []byte(`{"architecture":{"layers":[{"name":"x"}]}}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if out, _, err := runCommandWithCapture(t, newLintArchCommand(app), []string{"--json"}); err == nil || !strings.Contains(out, `"code": "invalid_input"`) {
+		t.Fatalf("expected invalid_input, out=%q err=%v", out, err)
+	}
+}
+
+func TestInitWarnsAboutStaleBinary(t *testing.T) {
+	saveAndMockInstallFuncs(t)
+	checkBinary = func(_ context.Context, opts doctor.Options) doctor.Check {
+		return doctor.Check{Name: "binary", Status: doctor.StatusWarn, Message: "stale recon on PATH, not " + opts.Version, Hint: "run `brew upgrade recon`"}
+	}
+
+	out, _, err := runCommandWithCapture(t, newInitCommand(&App{Context: context.Background(), ModuleRoot: setupModuleRoot(t)}), nil)
+	if err != nil || !strings.Contains(out, "Warning: stale recon on PATH, not dev\n  run `brew upgrade recon`") {
+		t.Fatalf("text init: out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newInitCommand(&App{Context: context.Background(), ModuleRoot: setupModuleRoot(t)}), []string{"--json"})
+	if err != nil || !strings.Contains(out, `"warnings"`) || !strings.Contains(out, `"status": "warn"`) {
+		t.Fatalf("json init: out=%q err=%v", out, err)
+	}
+
+	// Without the Claude Code hook there is nothing to run recon from PATH.
+	out, _, err = runCommandWithCapture(t, newInitCommand(&App{Context: context.Background(), ModuleRoot: setupModuleRoot(t)}), []string{"--agent", "cursor"})
+	if err != nil || strings.Contains(out, "Warning") {
+		t.Fatalf("cursor init: out=%q err=%v", out, err)
+	}
+}
+
+func TestDoctorCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+
+	out, _, err := runCommandWithCapture(t, newDoctorCommand(app), nil)
+	var exitErr ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 2 || !strings.Contains(out, "[warn] database: database not initialized\n    run `recon init`") {
+		t.Fatalf("uninitialized doctor: out=%q err=%v", out, err)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	out, _, _ = runCommandWithCapture(t, newDoctorCommand(app), []string{"--json"})
+	var report doctor.Report
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("unmarshal: %v: %s", err, out)
+	}
+	if len(report.Checks) != 3 || report.Checks[0].Status != doctor.StatusOK || report.Checks[1].Message != "SessionStart hook installed and registered" {
+		t.Fatalf("report = %+v", report)
+	}
+}
+
+func TestPathModeFlags(t *testing.T) {
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	absMain := filepath.Join(app.ModuleRoot, "main.go")
+
+	app.AbsPaths = true
+	out, _, err := runCommandWithCapture(t, newFindCommand(app), []string{"Alpha", "--json"})
+	if err != nil {
+		t.Fatalf("find --abs-paths: %v", err)
+	}
+	var found struct {
+		Symbol struct {
+			FilePath string `json:"file_path"`
+			Package  string `json:"package"`
+		} `json:"symbol"`
+	}
+	if err := json.Unmarshal([]byte(out), &found); err != nil {
+		t.Fatalf("parse find: %v (out=%q)", err, out)
+	}
+	if found.Symbol.FilePath != absMain || found.Symbol.Package != "." {
+		t.Fatalf("abs find symbol = %+v", found.Symbol)
+	}
+	out, _, _ = runCommandWithCapture(t, newFindCommand(app), []string{"Ambig"})
+	if !strings.Contains(out, filepath.Join(app.ModuleRoot, "pkg1", "a.go")) {
+		t.Fatalf("expected absolute candidate paths, out=%q", out)
+	}
+
+	// An absolute --file filter matches the module-relative index path.
+	app.AbsPaths, app.RelPaths = false, true
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Ambig", "--file", filepath.Join(app.ModuleRoot, "pkg2", "a.go")})
+	if err != nil || !strings.Contains(out, "(pkg2/a.go)") {
+		t.Fatalf("find --rel-paths --file abs: out=%q err=%v", out, err)
+	}
+
+	app.RelPaths = false
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Alpha"})
+	if err != nil || !strings.Contains(out, "(main.go)") {
+		t.Fatalf("default find: out=%q err=%v", out, err)
+	}
+}
+
+func TestApplyPathMode(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mod")
+	type nested struct {
+		File string
+	}
+	type payload struct {
+		FilePath string
+		Path     string
+		Files    []string
+		Items    []*nested
+		Any      any
+	}
+	newPayload := func() payload {
+		return payload{
+			FilePath: "a/b.go",
+			Path:     "a",
+			Files:    []string{"x.go", filepath.Join(root, "y.go")},
+			Items:    []*nested{{File: "c.go"}, nil},
+			Any:      &nested{File: "d.go"},
+		}
+	}
+
+	app := &App{ModuleRoot: root, AbsPaths: true}
+	p := newPayload()
+	app.applyPathMode(&p)
+	if p.FilePath != filepath.Join(root, "a", "b.go") || p.Path != "a" ||
+		p.Files[0] != filepath.Join(root, "x.go") || p.Files[1] != filepath.Join(root, "y.go") ||
+		p.Items[0].File != filepath.Join(root, "c.go") || p.Any.(*nested).File != filepath.Join(root, "d.go") {
+		t.Fatalf("abs payload = %+v", p)
+	}
+
+	app = &App{ModuleRoot: root, RelPaths: true}
+	app.applyPathMode(&p)
+	if p.FilePath != "a/b.go" || p.Files[1] != "y.go" || p.Items[0].File != "c.go" {
+		t.Fatalf("rel payload = %+v", p)
+	}
+	outside := filepath.Join(filepath.Dir(root), "other.go")
+	if got := app.displayPath(outside); got != outside {
+		t.Fatalf("path outside module = %q", got)
+	}
+
+	app = &App{ModuleRoot: root}
+	p = newPayload()
+	app.applyPathMode(&p)
+	if p.FilePath != "a/b.go" || p.Files[1] != filepath.Join(root, "y.go") {
+		t.Fatalf("default payload changed: %+v", p)
+	}
+}
+
+func TestWhyCommand(t *testing.T) {
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	id := createTestDecision(t, app, "Ambig stays in pkg1")
+	if _, _, err := runCommandWithCapture(t, newEdgesCommand(app), []string{"--create", "--from", fmt.Sprintf("decision:%d", id), "--to", "symbol:pkg1.Ambig"}); err != nil {
+		t.Fatalf("create edge: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newWhyCommand(app), []string{"Ambig", "--package", "pkg1"})
+	if err != nil || !strings.Contains(out, "Ambig stays in pkg1 [") || !strings.Contains(out, "via symbol:pkg1.Ambig") {
+		t.Fatalf("why symbol: out=%q err=%v", out, err)
+	}
+
+	app.AbsPaths = true
+	out, _, err = runCommandWithCapture(t, newWhyCommand(app), []string{filepath.Join(app.ModuleRoot, "pkg1", "a.go"), "--json"})
+	app.AbsPaths = false
+	var result struct {
+		Kind      string `json:"kind"`
+		File      string `json:"file"`
+		Knowledge []struct {
+			ID  int64  `json:"id"`
+			Via string `json:"via"`
+		} `json:"knowledge"`
+	}
+	if err != nil || json.Unmarshal([]byte(out), &result) != nil {
+		t.Fatalf("why file --json: out=%q err=%v", out, err)
+	}
+	if result.Kind != "file" || result.File != filepath.Join(app.ModuleRoot, "pkg1", "a.go") || len(result.Knowledge) != 1 || result.Knowledge[0].ID != id {
+		t.Fatalf("why file result = %+v", result)
+	}
+
+	out, _, err = runCommandWithCapture(t, newWhyCommand(app), []string{"pkg2"})
+	if err != nil || !strings.Contains(out, "Why package pkg2") || !strings.Contains(out, "(none recorded)") {
+		t.Fatalf("why package: out=%q err=%v", out, err)
+	}
+
+	_, _, err = runCommandWithCapture(t, newWhyCommand(app), []string{"Ambig", "--json"})
+	if exitErr, ok := err.(ExitError); !ok || exitErr.Code != 2 {
+		t.Fatalf("expected ambiguous exit 2, got %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newWhyCommand(app), []string{"pkg1/missing.go", "--json"})
+	if err == nil || !strings.Contains(out, `"not_found"`) {
+		t.Fatalf("why missing file: out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newWhyCommand(app), nil); err == nil || !strings.Contains(err.Error(), "requires a <path|symbol>") {
+		t.Fatalf("expected missing argument error, got %v", err)
+	}
+}
+
+func TestDepsCommand(t *testing.T) {
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newDepsCommand(app), []string{"pkg1"})
+	if err != nil || !strings.Contains(out, "Package pkg1 (example.com/recon/pkg1)") || !strings.Contains(out, "Imported by (1):\n- .") {
+		t.Fatalf("deps pkg1: out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newDepsCommand(app), []string{filepath.Join(app.ModuleRoot, "pkg1"), "--json"})
+	var result struct {
+		Package   string   `json:"package"`
+		Importers []string `json:"importers"`
+		FanIn     int      `json:"fan_in"`
+	}
+	if err != nil || json.Unmarshal([]byte(out), &result) != nil {
+		t.Fatalf("deps --json: out=%q err=%v", out, err)
+	}
+	if result.Package != "pkg1" || result.FanIn != 1 || len(result.Importers) != 1 {
+		t.Fatalf("deps result = %+v", result)
+	}
+
+	out, _, err = runCommandWithCapture(t, newDepsCommand(app), []string{"nope", "--json"})
+	if err == nil || !strings.Contains(out, `"not_found"`) {
+		t.Fatalf("deps missing package: out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newDepsCommand(app), nil); err == nil || !strings.Contains(err.Error(), "requires a <package>") {
+		t.Fatalf("expected missing argument error, got %v", err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newDepsCommand(app), []string{"--external"})
+	if err != nil || !strings.Contains(out, "Module example.com/recon (go unknown)") || !strings.Contains(out, "Dependencies (0):") {
+		t.Fatalf("deps --external: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newDepsCommand(app), []string{"--external", "--json"})
+	if err != nil || !strings.Contains(out, `"dependencies": []`) {
+		t.Fatalf("deps --external --json: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newDepsCommand(app), []string{"pkg1", "--external", "--json"})
+	if err == nil || !strings.Contains(out, `"invalid_input"`) {
+		t.Fatalf("deps --external with package: out=%q err=%v", out, err)
+	}
+}
+
+func TestVerifyCommand(t *testing.T) {
+	app := setupInitializedApp(t)
+	id := createTestDecision(t, app, "Verified decision")
+	out, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+		"Hourly decision", "--reasoning", "r", "--evidence-summary", "go.mod exists",
+		"--check-type", "file_exists", "--check-spec", `{"path":"go.mod"}`, "--verify-interval", "1h", "--json",
+	})
+	if err != nil {
+		t.Fatalf("decide --verify-interval: %v (out=%q)", err, out)
+	}
+
+	out, _, err = runCommandWithCapture(t, newVerifyCommand(app), nil)
+	if err != nil || !strings.Contains(out, "Evidence re-checked: 2 (0 changed)") || !strings.Contains(out, "Evidence due: 0 | Next due: ") {
+		t.Fatalf("verify: out=%q err=%v", out, err)
+	}
+	// Both were just checked, so nothing is due yet.
+	out, _, err = runCommandWithCapture(t, newVerifyCommand(app), []string{"--due", "--skip-toolchain", "--json"})
+	var payload struct {
+		Checked  int `json:"checked"`
+		Schedule struct {
+			Due       int    `json:"due"`
+			NextDueAt string `json:"next_due_at"`
+		} `json:"schedule"`
+	}
+	if err != nil || json.Unmarshal([]byte(out), &payload) != nil {
+		t.Fatalf("verify --due --json: out=%q err=%v", out, err)
+	}
+	if payload.Checked != 0 || payload.Schedule.Due != 0 || payload.Schedule.NextDueAt == "" {
+		t.Fatalf("verify --due payload = %+v", payload)
+	}
+
+	conn, err := openExistingDB(app)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	var interval int
+	if err := conn.QueryRow(`SELECT verify_interval FROM evidence WHERE entity_id != ?`, id).Scan(&interval); err != nil || interval != 3600 {
+		t.Fatalf("stored interval = %d, %v", interval, err)
+	}
+	conn.Close()
+
+	ref := fmt.Sprintf("decision:%d", id)
+	out, _, err = runCommandWithCapture(t, newVerifyCommand(app), []string{"--set-interval", "48h", "--entity", ref})
+	if err != nil || !strings.Contains(out, "Set the verify interval to 48h0m0s for 1 evidence rows of decision #") {
+		t.Fatalf("verify --set-interval: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newVerifyCommand(app), []string{"--set-interval", "1h", "--entity", "decision:999", "--json"})
+	if err == nil || !strings.Contains(out, `"not_found"`) {
+		t.Fatalf("verify --set-interval missing entity: out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newVerifyCommand(app), []string{"--entity", ref}); err == nil || !strings.Contains(err.Error(), "must be used together") {
+		t.Fatalf("expected --entity without --set-interval error, got %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+		"Bad interval", "--reasoning", "r", "--evidence-summary", "e",
+		"--check-type", "file_exists", "--check-spec", `{"path":"go.mod"}`, "--verify-interval", "-1h",
+	}); err == nil || !strings.Contains(err.Error(), "--verify-interval must not be negative") {
+		t.Fatalf("expected negative --verify-interval error, got %v", err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newStatusCommand(app), nil)
+	if err != nil || !strings.Contains(out, "Evidence due: 0 | Next due: ") {
+		t.Fatalf("status: out=%q err=%v", out, err)
+	}
+}
+
+func TestVerifySARIF(t *testing.T) {
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	out, _, err := runCommandWithCapture(t, newVerifyCommand(app), []string{"--format", "sarif"})
+	if err != nil || !strings.Contains(out, `"results": []`) {
+		t.Fatalf("clean verify sarif: out=%q err=%v", out, err)
+	}
+
+	if out, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+		"Ambig stays small", "--reasoning", "r", "--evidence-summary", "a.go exists", "--check-type", "file_exists",
+		"--check-path", "pkg2/a.go", "--affects", "pkg2.Ambig", "--json",
+	}); err != nil {
+		t.Fatalf("decide: %v (out=%q)", err, out)
+	}
+	if err := os.Remove(filepath.Join(app.ModuleRoot, "pkg2", "a.go")); err != nil {
+		t.Fatalf("remove pkg2/a.go: %v", err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newVerifyCommand(app), []string{"--format", "sarif"})
+	if err != nil {
+		t.Fatalf("verify sarif: %v", err)
+	}
+	var log sarif.Log
+	if err := json.Unmarshal([]byte(out), &log); err != nil {
+		t.Fatalf("unmarshal sarif: %v: %s", err, out)
+	}
+	if len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 || len(log.Runs[0].Tool.Driver.Rules) != 3 {
+		t.Fatalf("unexpected sarif log: %s", out)
+	}
+	res := log.Runs[0].Results[0]
+	loc := res.Locations[0].PhysicalLocation
+	if res.RuleID != "drift_broken" || res.Level != sarif.LevelError || loc.ArtifactLocation.URI != "pkg2/a.go" ||
+		loc.ArtifactLocation.URIBaseID != sarif.SrcRoot || loc.Region == nil || loc.Region.StartLine != 2 {
+		t.Fatalf("unexpected sarif result: %+v", res)
+	}
+}
+
+func TestProposalsCommand(t *testing.T) {
+	app := setupInitializedApp(t)
+	propose := func(title, path string) {
+		t.Helper()
+		if _, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+			title, "--reasoning", "r", "--evidence-summary", path + " exists",
+			"--check-type", "file_exists", "--check-spec", `{"path":"` + path + `"}`,
+		}); err == nil {
+			t.Fatalf("expected %s to stay pending", title)
+		}
+	}
+	propose("Keep a README", "README.md")
+	propose("Keep a CHANGELOG", "CHANGELOG.md")
+
+	out, _, err := runCommandWithCapture(t, newProposalsCommand(app), nil)
+	if err != nil || !strings.Contains(out, `#1 decision "Keep a README" [file_exists] checked `) || !strings.Contains(out, "README.md exists=false") {
+		t.Fatalf("proposals: out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newProposalsCommand(app), []string{"--retry", "1"})
+	if err == nil || !strings.Contains(out, "Proposal 1 still pending") {
+		t.Fatalf("proposals --retry before fix: out=%q err=%v", out, err)
+	}
+	if err := os.WriteFile(filepath.Join(app.ModuleRoot, "README.md"), []byte("# docs\n"), 0o644); err != nil {
+		t.Fatalf("write README.md: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newProposalsCommand(app), []string{"--retry", "1", "--json"})
+	var retried struct {
+		EntityType string `json:"entity_type"`
+		EntityID   int64  `json:"entity_id"`
+		Promoted   bool   `json:"promoted"`
+	}
+	if err != nil || json.Unmarshal([]byte(out), &retried) != nil || !retried.Promoted || retried.EntityType != "decision" || retried.EntityID == 0 {
+		t.Fatalf("proposals --retry after fix: out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newProposalsCommand(app), []string{"--discard", "2"})
+	if err != nil || !strings.Contains(out, "Discarded proposal 2") {
+		t.Fatalf("proposals --discard: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newProposalsCommand(app), []string{"--list", "--json"})
+	if err != nil || strings.TrimSpace(out) != "[]" {
+		t.Fatalf("proposals --list --json: out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newProposalsCommand(app), []string{"--retry", "2", "--json"})
+	if err == nil || !strings.Contains(out, `"not_found"`) {
+		t.Fatalf("proposals --retry discarded: out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newProposalsCommand(app), []string{"--retry", "1", "--discard", "2"}); err == nil || !strings.Contains(err.Error(), "only one of") {
+		t.Fatalf("expected mode conflict error, got %v", err)
+	}
+}
+
+func TestLogFlags(t *testing.T) {
+	root := setupModuleRoot(t)
+
+	origGetwd := osGetwd
+	origFind := findModuleRoot
+	origLogger := slog.Default()
+	defer func() {
+		osGetwd = origGetwd
+		findModuleRoot = origFind
+		slog.SetDefault(origLogger)
+		if logFile != nil {
+			logFile.Close()
+			logFile = nil
+		}
+	}()
+	osGetwd = func() (string, error) { return root, nil }
+	findModuleRoot = func(string) (string, error) { return root, nil }
+	t.Setenv(logging.LevelEnv, "")
+	t.Setenv(logging.FileEnv, "")
+
+	newRoot := func(t *testing.T) *cobra.Command {
+		t.Helper()
+		cmd, err := NewRootCommand(context.Background())
+		if err != nil {
+			t.Fatalf("new root: %v", err)
+		}
+		return cmd
+	}
+
+	if _, _, err := runCommandWithCapture(t, newRoot(t), []string{"init"}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if ignore, err := os.ReadFile(filepath.Join(root, ".gitignore")); err != nil || !strings.Contains(string(ignore), ".recon/recon.log\n") {
+		t.Fatalf("expected the log file ignored, got %q (%v)", ignore, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".recon", logging.FileName)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no log file without --log-file, got %v", err)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newRoot(t), []string{"--log-level", "debug", "--log-file", "sync"}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, ".recon", logging.FileName))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	for _, want := range []string{`msg=command`, `command="recon sync"`, `msg=sql`, `msg=exec`, `cmd=git`, `msg=sync`} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("expected %s in the log, got:\n%s", want, data)
+		}
+	}
+
+	// --log-file alone logs at info, and the environment sets both flags.
+	t.Setenv(logging.FileEnv, "true")
+	if _, _, err := runCommandWithCapture(t, newRoot(t), []string{"sync"}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	data, _ = os.ReadFile(filepath.Join(root, ".recon", logging.FileName))
+	if last := string(data[strings.LastIndex(string(data), `msg=command`):]); strings.Contains(last, "level=DEBUG") || !strings.Contains(last, "msg=sync") {
+		t.Fatalf("expected only info records for the second sync, got:\n%s", last)
+	}
+
+	t.Setenv(logging.LevelEnv, "loud")
+	if _, _, err := runCommandWithCapture(t, newRoot(t), []string{"status"}); err == nil || !strings.Contains(err.Error(), `invalid log level "loud"`) {
+		t.Fatalf("expected an invalid log level error, got %v", err)
+	}
+}
+
+func TestTraceFlag(t *testing.T) {
+	root := setupModuleRoot(t)
+
+	origGetwd := osGetwd
+	origFind := findModuleRoot
+	origLogger := slog.Default()
+	defer func() {
+		osGetwd = origGetwd
+		findModuleRoot = origFind
+		slog.SetDefault(origLogger)
+	}()
+	osGetwd = func() (string, error) { return root, nil }
+	findModuleRoot = func(string) (string, error) { return root, nil }
+	t.Setenv(logging.LevelEnv, "")
+	t.Setenv(logging.FileEnv, "")
+	t.Setenv(logging.TraceEnv, "")
+
+	newRoot := func(t *testing.T) *cobra.Command {
+		t.Helper()
+		cmd, err := NewRootCommand(context.Background())
+		if err != nil {
+			t.Fatalf("new root: %v", err)
+		}
+		return cmd
+	}
+	if _, _, err := runCommandWithCapture(t, newRoot(t), []string{"init"}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newRoot(t), []string{"sync"}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "trace.json")
+	out, errOut, err := runCommandWithCapture(t, newRoot(t), []string{"orient", "--json", "--trace=" + path})
+	if err != nil || strings.Contains(errOut, "level=") {
+		t.Fatalf("orient --trace out=%q stderr=%q err=%v", out, errOut, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read trace: %v", err)
+	}
+	var report logging.ProfileReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("decode trace: %v\n%s", err, data)
+	}
+	phases := map[string]int{}
+	for _, p := range report.Phases {
+		phases[p.Phase] = p.Count
+	}
+	if report.Command != "recon orient" || phases[logging.PhaseDBOpen] != 1 || phases[logging.PhaseQuery] == 0 || phases[logging.PhaseRender] != 1 {
+		t.Fatalf("unexpected trace: %s", data)
+	}
+
+	// A failing command is still profiled, and the environment turns it on.
+	t.Setenv(logging.TraceEnv, "1")
+	_, errOut, err = runCommandWithCapture(t, newRoot(t), []string{"find", "Missing"})
+	if err == nil || !strings.Contains(errOut, "trace: recon find took") || !strings.Contains(errOut, "db_open") {
+		t.Fatalf("find with RECON_TRACE=1 stderr=%q err=%v", errOut, err)
+	}
+
+	help, _, _ := runCommandWithCapture(t, newRoot(t), []string{"--help"})
+	if strings.Contains(help, "--trace") {
+		t.Fatalf("expected --trace hidden from help, got %q", help)
+	}
+}
+
+func TestHistoryCommand(t *testing.T) {
+	root := setupModuleRoot(t)
+	app := &App{Context: context.Background(), ModuleRoot: root}
+	if _, _, err := runCommandWithCapture(t, newInitCommand(app), nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	origHistory, origTouch := symbolHistory, lastTouch
+	defer func() { symbolHistory, lastTouch = origHistory, origTouch }()
+	alice := history.Commit{Hash: "1111111aaaaaaa", Author: "alice", Email: "alice@example.com", Date: "2026-01-02T10:00:00Z", Subject: "add Alpha", LinesAdded: 1}
+	bob := history.Commit{Hash: "2222222bbbbbbb", Author: "bob", Email: "bob@example.com", Date: "2026-02-03T10:00:00Z", Subject: "gofmt", LinesAdded: 1, LinesDeleted: 1, Cosmetic: true}
+	var gotFile string
+	var gotRange [3]int
+	symbolHistory = func(_ context.Context, _ string, file string, start, end, limit int) (history.History, error) {
+		gotFile, gotRange = file, [3]int{start, end, limit}
+		return history.History{Introduced: &alice, LastChanged: &alice, Commits: []history.Commit{bob}, Total: 2}, nil
+	}
+	lastTouch = func(context.Context, string, string, int, int) (*history.Commit, error) { return &bob, nil }
+
+	out, _, err := runCommandWithCapture(t, newHistoryCommand(app), []string{"Alpha", "--limit", "1"})
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if gotFile != "main.go" || gotRange != [3]int{3, 3, 1} {
+		t.Fatalf("history of %s %v, want main.go [3 3 1]", gotFile, gotRange)
+	}
+	for _, want := range []string{
+		"func Alpha (main.go:3-3)\n",
+		"Introduced:   1111111 2026-01-02 alice: add Alpha\n",
+		"Last changed: 1111111 2026-01-02 alice: add Alpha\n",
+		"- 2222222 2026-02-03 bob: gofmt (+1 -1, whitespace only)\n",
+		"... 1 older commits; raise --limit to see them\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in history output, got:\n%s", want, out)
+		}
+	}
+
+	out, _, err = runCommandWithCapture(t, newHistoryCommand(app), []string{"Alpha", "--json"})
+	if err != nil || !strings.Contains(out, `"symbol": "Alpha"`) || !strings.Contains(out, `"last_changed": {`) || !strings.Contains(out, `"total": 2`) {
+		t.Fatalf("history --json out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newHistoryCommand(app), []string{"Ambig", "--json"})
+	if err == nil || !strings.Contains(out, `"code": "ambiguous"`) {
+		t.Fatalf("expected ambiguous, out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newHistoryCommand(app), nil); err == nil || !strings.Contains(err.Error(), "requires a <symbol>") {
+		t.Fatalf("expected a missing argument error, got %v", err)
+	}
+
+	symbolHistory = func(context.Context, string, string, int, int, int) (history.History, error) {
+		return history.History{Commits: []history.Commit{}, Uncommitted: true}, nil
+	}
+	if out, _, err := runCommandWithCapture(t, newHistoryCommand(app), []string{"Alpha"}); err != nil || !strings.Contains(out, "not committed yet") {
+		t.Fatalf("history of an uncommitted file out=%q err=%v", out, err)
+	}
+	symbolHistory = func(context.Context, string, string, int, int, int) (history.History, error) {
+		return history.History{}, errors.New("git log: not a git repository")
+	}
+	if out, _, err := runCommandWithCapture(t, newHistoryCommand(app), []string{"Alpha", "--json"}); err == nil || !strings.Contains(out, `"code": "internal_error"`) {
+		t.Fatalf("expected internal_error, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Alpha", "--history", "--no-body"})
+	if err != nil || !strings.Contains(out, "Last touched: 2222222 2026-02-03 bob: gofmt <bob@example.com>") {
+		t.Fatalf("find --history out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Alpha", "--history", "--json"})
+	if err != nil || !strings.Contains(out, `"last_touch": {`) || !strings.Contains(out, `"author": "bob"`) {
+		t.Fatalf("find --history --json out=%q err=%v", out, err)
+	}
+	out, _, _ = runCommandWithCapture(t, newFindCommand(app), []string{"Alpha", "--json"})
+	if strings.Contains(out, "last_touch") {
+		t.Fatalf("expected no last_touch without --history, got %q", out)
+	}
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/robertguss/recon/internal/find"
+	"github.com/spf13/cobra"
+)
+
+// completionLimit caps the candidates a dynamic completion offers.
+const completionLimit = 200
+
+func newCompletionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion (bash | zsh | fish | powershell)",
+		Short: "Generate a shell completion script",
+		Long: "Print a completion script for the given shell. Besides commands and flags, it\n" +
+			"completes the <symbol> of find, explain-symbol, and open, and their --package\n" +
+			"flag, from the names in the local index, so run `recon sync` first.\n\n" +
+			"Load it in the current shell, or add the line to your shell's startup file:\n\n" +
+			"  bash:       source <(recon completion bash)\n" +
+			"  zsh:        source <(recon completion zsh)\n" +
+			"  fish:       recon completion fish | source\n" +
+			"  powershell: recon completion powershell | Out-String | Invoke-Expression",
+		Example:   "  recon completion zsh > \"${fpath[1]}/_recon\"\n  recon completion bash > /etc/bash_completion.d/recon",
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout, true)
+			default:
+				return root.GenPowerShellCompletionWithDesc(os.Stdout)
+			}
+		},
+	}
+}
+
+// completeSymbolArg completes a command's <symbol> argument with the names of
+// indexed symbols, and "Receiver." with the receiver's methods.
+func completeSymbolArg(app *App) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeFromIndex(cmd, app, func(svc *find.Service) ([]string, error) {
+			return svc.CompleteSymbols(cmd.Context(), toComplete, completionLimit)
+		})
+	}
+}
+
+// completePackageFlag completes a --package flag with indexed package paths.
+func completePackageFlag(app *App) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeFromIndex(cmd, app, func(svc *find.Service) ([]string, error) {
+			return svc.CompletePackages(cmd.Context(), toComplete, completionLimit)
+		})
+	}
+}
+
+// completeRepoFlag completes a --repo flag with the names of registered
+// repos.
+func completeRepoFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	_, reg, err := loadWorkspace()
+	if err != nil {
+		cobra.CompDebugln("recon completion: "+err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(reg.Repos))
+	for _, root := range reg.Repos {
+		names = append(names, reg.Name(root))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFromIndex runs a completion query against the index. Completion must
+// never print errors into the user's shell, so a missing or unreadable index
+// offers no candidates.
+func completeFromIndex(cmd *cobra.Command, app *App, query func(*find.Service) ([]string, error)) ([]string, cobra.ShellCompDirective) {
+	conn, err := openExistingDB(&App{Context: app.Context, ModuleRoot: app.ModuleRoot, NoPrompt: true})
+	if err != nil {
+		cobra.CompDebugln("recon completion: "+err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer conn.Close()
+
+	candidates, err := query(find.NewService(conn))
+	if err != nil {
+		cobra.CompDebugln("recon completion: "+err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
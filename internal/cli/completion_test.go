@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCompletionCommand(t *testing.T) {
+	for shell, want := range map[string]string{
+		"bash":       "__start_recon",
+		"zsh":        "#compdef recon",
+		"fish":       "complete -c recon",
+		"powershell": "Register-ArgumentCompleter",
+	} {
+		root := &cobra.Command{Use: "recon"}
+		root.AddCommand(newCompletionCommand())
+		out, _, err := runCommandWithCapture(t, root, []string{"completion", shell})
+		if err != nil || !strings.Contains(out, want) {
+			t.Fatalf("completion %s: expected %q in script, err=%v", shell, want, err)
+		}
+	}
+	root := &cobra.Command{Use: "recon"}
+	root.AddCommand(newCompletionCommand())
+	if _, _, err := runCommandWithCapture(t, root, []string{"completion", "tcsh"}); err == nil || !strings.Contains(err.Error(), "invalid argument") {
+		t.Fatalf("expected invalid shell error, got %v", err)
+	}
+}
+
+func TestDynamicCompletion(t *testing.T) {
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+
+	got, directive := completeSymbolArg(app)(cmd, nil, "Al")
+	if !slices.Equal(got, []string{"Alpha"}) || directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Fatalf("symbol completion = %v, %v", got, directive)
+	}
+	if got, _ := completeSymbolArg(app)(cmd, []string{"Alpha"}, ""); got != nil {
+		t.Fatalf("expected no completion after the symbol, got %v", got)
+	}
+	got, _ = completePackageFlag(app)(cmd, nil, "pkg")
+	if !slices.Equal(got, []string{"pkg1", "pkg2"}) {
+		t.Fatalf("package completion = %v", got)
+	}
+
+	// Without an index, completion offers nothing rather than failing.
+	empty := &App{Context: context.Background(), ModuleRoot: t.TempDir()}
+	if got, directive := completePackageFlag(empty)(cmd, nil, ""); got != nil || directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Fatalf("expected no candidates without an index, got %v, %v", got, directive)
+	}
+}
@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robertguss/recon/internal/config"
+	"github.com/robertguss/recon/internal/constraint"
+	"github.com/robertguss/recon/internal/edge"
+	"github.com/robertguss/recon/internal/knowledge"
+	"github.com/spf13/cobra"
+)
+
+func newConstrainCommand(app *App) *cobra.Command {
+	var (
+		reasoning       string
+		confidence      string
+		evidenceSummary string
+		checkType       string
+		checkSpec       string
+		checkPath       string
+		checkSymbol     string
+		checkPattern    string
+		checkScope      string
+		checkTimeout    time.Duration
+		verifyInterval  time.Duration
+		jsonOut         bool
+		listFlag        bool
+		archiveID       int64
+		affectsRefs     []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "constrain [<title>]",
+		Short: "Record a hard rule the code must never break, verified by a check that must not match",
+		Long: "Record a constraint: a hard rule such as \"never import internal/db from cmd\".\n" +
+			"Unlike a pattern, which describes what the code does, a constraint names what it\n" +
+			"must never do. Its check defaults to grep_absent, which passes only while\n" +
+			"--check-pattern matches no file in --check-scope, so a constraint is promoted only\n" +
+			"when the code already obeys it and turns broken on the first sync that violates it.\n" +
+			"Layering rules use import_absent instead: --check-pattern names the forbidden\n" +
+			"package pattern and --check-scope the importing packages, and recon lint-arch\n" +
+			"enforces them alongside config rules. Active constraints head recon orient output.",
+		Example: "  recon constrain \"Never import internal/db from cmd\" \\\n" +
+			"    --reasoning \"cmd only wires the CLI\" \\\n" +
+			"    --evidence-summary \"no cmd file imports internal/db\" \\\n" +
+			"    --check-pattern 'internal/db\"' --check-scope 'cmd/*.go'\n" +
+			"  recon constrain \"cmd stays above the database\" --check-type import_absent \\\n" +
+			"    --evidence-summary \"no cmd package imports internal/db\" \\\n" +
+			"    --check-pattern internal/db --check-scope 'cmd/...'\n" +
+			"  recon constrain --list",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// List mode
+			if listFlag {
+				conn, err := openExistingDB(app)
+				if err != nil {
+					if jsonOut {
+						return exitJSONCommandError(err)
+					}
+					return err
+				}
+				defer conn.Close()
+
+				items, err := constraint.NewService(conn).ListConstraints(cmd.Context())
+				if err != nil {
+					if jsonOut {
+						_ = writeJSONError("internal_error", err.Error(), nil)
+						return ExitError{Code: 2}
+					}
+					return err
+				}
+
+				if jsonOut {
+					return writeJSON(items)
+				}
+				if len(items) == 0 {
+					fmt.Println("No active constraints.")
+					return nil
+				}
+				for _, item := range items {
+					fmt.Printf("#%d %s (confidence=%s, drift=%s)\n", item.ID, item.Title, item.Confidence, item.Drift)
+				}
+				return nil
+			}
+
+			// Archive mode
+			if archiveID > 0 {
+				conn, err := openExistingDB(app)
+				if err != nil {
+					if jsonOut {
+						return exitJSONCommandError(err)
+					}
+					return err
+				}
+				defer conn.Close()
+
+				if err := constraint.NewService(conn).ArchiveConstraint(cmd.Context(), archiveID); err != nil {
+					if jsonOut {
+						code := "internal_error"
+						if errors.Is(err, constraint.ErrNotFound) {
+							code = "not_found"
+						}
+						_ = writeJSONError(code, err.Error(), map[string]any{"id": archiveID})
+						return ExitError{Code: 2}
+					}
+					return err
+				}
+				if jsonOut {
+					return writeJSON(map[string]any{"archived": true, "id": archiveID})
+				}
+				fmt.Printf("Constraint %d archived.\n", archiveID)
+				return nil
+			}
+
+			// Propose mode
+			if len(args) == 0 {
+				msg := "constrain requires a <title> argument"
+				if jsonOut {
+					_ = writeJSONError("missing_argument", msg, map[string]any{"command": "constrain"})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			title := args[0]
+
+			resolvedSpec, err := buildCheckSpec(checkType, checkSpec, checkPath, checkSymbol, checkPattern, checkScope, checkTimeout)
+			if err == nil && verifyInterval < 0 {
+				err = fmt.Errorf("--verify-interval must not be negative")
+			}
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"check_type": checkType})
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			cfg, err := loadConfig(app.ModuleRoot)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"path": config.Path(app.ModuleRoot)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			result, err := constraint.NewService(conn).ProposeAndVerifyConstraint(cmd.Context(), constraint.ProposeConstraintInput{
+				Title:           title,
+				Reasoning:       reasoning,
+				Confidence:      confidence,
+				EvidenceSummary: evidenceSummary,
+				CheckType:       checkType,
+				CheckSpec:       resolvedSpec,
+				ModuleRoot:      app.ModuleRoot,
+				Policy:          evidencePolicy(cfg.Knowledge),
+				VerifyInterval:  verifyInterval,
+			})
+			if err != nil {
+				if jsonOut {
+					code := "internal_error"
+					if errors.Is(err, knowledge.ErrEvidenceTooWeak) || classifyDecideMessage(err.Error()) == "invalid_input" {
+						code = "invalid_input"
+					}
+					_ = writeJSONError(code, err.Error(), map[string]any{"check_type": checkType})
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+
+			if result.Promoted {
+				edgeSvc := edge.NewService(conn)
+				var edgeErrors []string
+				for _, ref := range affectsRefs {
+					if _, err := edgeSvc.Create(cmd.Context(), edge.CreateInput{
+						FromType:   "constraint",
+						FromID:     result.ConstraintID,
+						ToType:     inferRefType(ref),
+						ToRef:      ref,
+						Relation:   "affects",
+						Source:     "manual",
+						Confidence: "high",
+					}); err != nil {
+						if jsonOut {
+							edgeErrors = append(edgeErrors, fmt.Sprintf("ref=%s: %v", ref, err))
+						} else {
+							fmt.Printf("  edge warning: %v\n", err)
+						}
+					}
+				}
+				if jsonOut && len(edgeErrors) > 0 {
+					details := map[string]any{
+						"constraint_id": result.ConstraintID,
+						"errors":        edgeErrors,
+					}
+					_ = writeJSONError("edge_creation_failed", "one or more edges could not be created", details)
+					return ExitError{Code: 2}
+				}
+			}
+
+			if jsonOut {
+				if !result.VerificationPassed {
+					details := map[string]any{
+						"proposal_id": result.ProposalID,
+						"check_type":  checkType,
+					}
+					_ = writeJSONError("verification_failed", result.VerificationDetails, details)
+					return ExitError{Code: 2}
+				}
+				return writeJSON(result)
+			}
+
+			if result.Promoted {
+				fmt.Printf("Constraint promoted: proposal=%d constraint=%d\n", result.ProposalID, result.ConstraintID)
+			} else {
+				fmt.Printf("Constraint pending: proposal=%d\n", result.ProposalID)
+			}
+			fmt.Printf("Verification: passed=%v details=%s\n", result.VerificationPassed, result.VerificationDetails)
+			if !result.VerificationPassed {
+				return ExitError{Code: 2}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&reasoning, "reasoning", "", "Why the rule exists")
+	cmd.Flags().StringVar(&confidence, "confidence", "", "Confidence: low, medium, high (default knowledge.default_confidence, else medium)")
+	cmd.Flags().StringVar(&evidenceSummary, "evidence-summary", "", "Evidence summary")
+	cmd.Flags().StringVar(&checkType, "check-type", constraint.DefaultCheckType, "Verification check type: grep_absent, not_grep_pattern, file_absent, import_absent, grep_pattern, symbol_exists, symbol_count, file_exists, go_build_passes, go_test_passes, command_output")
+	cmd.Flags().StringVar(&checkSpec, "check-spec", "", "Verification check spec JSON")
+	cmd.Flags().StringVar(&checkPath, "check-path", "", "Typed check field for file_exists/file_absent: path")
+	cmd.Flags().StringVar(&checkSymbol, "check-symbol", "", "Typed check field for symbol_exists: symbol name")
+	cmd.Flags().StringVar(&checkPattern, "check-pattern", "", "Typed check field for grep_absent/grep_pattern (regex) or import_absent (package pattern) the rule forbids")
+	cmd.Flags().StringVar(&checkScope, "check-scope", "", "Typed check field for grep_absent/grep_pattern (file glob), import_absent (importing packages), or go_build_passes/go_test_passes (package patterns)")
+	cmd.Flags().DurationVar(&checkTimeout, "check-timeout", 0, "Typed check field for go_build_passes/go_test_passes: time limit (default 2m build, 5m test)")
+	cmd.Flags().DurationVar(&verifyInterval, "verify-interval", 0, "How often recon verify --due re-checks the evidence (default 24h, 168h for build/test checks)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().BoolVar(&listFlag, "list", false, "List active constraints")
+	cmd.Flags().Int64Var(&archiveID, "archive", 0, "Archive a constraint by ID")
+	cmd.Flags().StringSliceVar(&affectsRefs, "affects", nil, "Package/file/symbol this constraint governs (creates edges)")
+
+	return cmd
+}
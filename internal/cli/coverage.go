@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/robertguss/recon/internal/coverage"
+	"github.com/spf13/cobra"
+)
+
+func newCoverageCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Import Go test coverage and map it to indexed symbols",
+		Long: "Store per-function statement coverage from a Go cover profile. Imported coverage\n" +
+			"is shown by `recon find <symbol>`, and `recon orient` lists hot modules whose\n" +
+			fmt.Sprintf("coverage is below %.0f%% as coverage gaps.", coverage.LowThreshold),
+		Args: cobra.NoArgs,
+	}
+	cmd.AddCommand(newCoverageImportCommand(app))
+	return cmd
+}
+
+func newCoverageImportCommand(app *App) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "import <coverprofile>",
+		Short: "Replace stored coverage with a profile from go test -coverprofile",
+		Long: "Read a cover profile (\"-\" for stdin) and credit each block to the function or\n" +
+			"method whose lines enclose it. The import replaces earlier coverage, so import a\n" +
+			"profile of the whole module, and import again after `recon sync` picks up edits.",
+		Example: "  go test -coverprofile=cover.out ./... && recon coverage import cover.out\n" +
+			"  go test -coverprofile=/dev/stdout ./... | recon coverage import - --json",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				msg := "coverage import requires a <coverprofile> argument"
+				if jsonOut {
+					_ = writeJSONError("missing_argument", msg, map[string]any{"command": "coverage import"})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			var profile io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					if jsonOut {
+						_ = writeJSONError("invalid_input", err.Error(), map[string]any{"path": args[0]})
+						return ExitError{Code: 2}
+					}
+					return ExitError{Code: 2, Message: err.Error()}
+				}
+				defer f.Close()
+				profile = f
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			result, err := coverage.NewService(conn).Import(cmd.Context(), profile)
+			if err != nil {
+				code, msg := "internal_error", err.Error()
+				switch {
+				case errors.Is(err, coverage.ErrInvalidProfile):
+					code = "invalid_input"
+				case errors.Is(err, coverage.ErrNoIndexedFiles):
+					code = "invalid_input"
+					msg += "; run `recon sync` or check the profile is from this module"
+				}
+				if jsonOut {
+					_ = writeJSONError(code, msg, map[string]any{"path": args[0]})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			if jsonOut {
+				return writeJSON(result)
+			}
+			fmt.Printf("Imported coverage for %d functions in %d files: %.1f%% of %d statements (mode %s)\n",
+				result.Symbols, result.Files, result.Total.Percent, result.Total.Statements, result.Mode)
+			if n := len(result.UnmatchedFiles); n > 0 {
+				fmt.Printf("Skipped %d profile file(s) not in the index; run `recon sync` if they are new.\n", n)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/robertguss/recon/internal/bundle"
+	"github.com/robertguss/recon/internal/db"
+	"github.com/spf13/cobra"
+)
+
+type debugBundlePayload struct {
+	Path     string          `json:"path"`
+	Manifest bundle.Manifest `json:"manifest"`
+}
+
+func newDebugBundleCommand(app *App) *cobra.Command {
+	var (
+		output    string
+		query     string
+		anonymize bool
+		jsonOut   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "debug-bundle",
+		Short: "Package schema, row counts, sync state, and config into an archive for bug reports",
+		Long: "Write a .tar.gz with what a maintainer needs to reproduce a recon bug: the\n" +
+			"database schema and migration version, row counts per table, sync state and\n" +
+			"recent sync runs, .recon/config.json, and recon, Go, and SQLite versions.\n" +
+			"Source code and knowledge text are never included. Pass --query with SQL that\n" +
+			"fails or misbehaves to add its error, query plan, and first rows; it runs in a\n" +
+			"transaction that is rolled back. --anonymize replaces the module path, commit\n" +
+			"hashes, and string values from --query with stable hashes.\n" +
+			"Sections that cannot be read are listed under errors in manifest.json, so a\n" +
+			"bundle is written even for a damaged or uninitialized database.",
+		Example: "  recon debug-bundle\n" +
+			"  recon debug-bundle --anonymize --query \"SELECT * FROM symbols WHERE name = 'Run'\"",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conn, err := openDBAnyVersion(app)
+			if err != nil {
+				var notInit dbNotInitializedError
+				if !errors.As(err, &notInit) {
+					if jsonOut {
+						return exitJSONCommandError(err)
+					}
+					return err
+				}
+				conn = nil
+			} else {
+				defer conn.Close()
+			}
+
+			path := output
+			if path == "" {
+				name := "debug-bundle-" + time.Now().UTC().Format("20060102T150405Z") + ".tar.gz"
+				path = filepath.Join(db.ReconDir(app.ModuleRoot), name)
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return debugBundleError(jsonOut, fmt.Errorf("create bundle dir: %w", err))
+			}
+			f, err := os.Create(path)
+			if err != nil {
+				return debugBundleError(jsonOut, fmt.Errorf("create bundle: %w", err))
+			}
+
+			manifest, err := bundle.NewService(conn).Write(cmd.Context(), f, bundle.Options{
+				ModuleRoot: app.ModuleRoot,
+				Version:    Version,
+				Commit:     Commit,
+				Query:      query,
+				Anonymize:  anonymize,
+			})
+			if closeErr := f.Close(); err == nil && closeErr != nil {
+				err = fmt.Errorf("close bundle: %w", closeErr)
+			}
+			if err != nil {
+				_ = os.Remove(path)
+				return debugBundleError(jsonOut, err)
+			}
+
+			if jsonOut {
+				return writeJSON(debugBundlePayload{Path: path, Manifest: manifest})
+			}
+			fmt.Printf("Debug bundle written: %s\n", path)
+			fmt.Printf("Files: %d, schema version %d (expected %d)\n", len(manifest.Files), manifest.Database.SchemaVersion, manifest.Database.ExpectedSchemaVersion)
+			for _, msg := range manifest.Errors {
+				fmt.Printf("  not collected: %s\n", msg)
+			}
+			if !anonymize {
+				fmt.Println("Review the bundle before sharing, or rerun with --anonymize.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&output, "output", "", "Archive path (default .recon/debug-bundle-<timestamp>.tar.gz)")
+	cmd.Flags().StringVar(&query, "query", "", "SQL that fails or misbehaves; its error, plan, and first rows are included")
+	cmd.Flags().BoolVar(&anonymize, "anonymize", false, "Hash the module path, commit hashes, and --query string values")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+func debugBundleError(jsonOut bool, err error) error {
+	if jsonOut {
+		_ = writeJSONError("internal_error", err.Error(), nil)
+		return ExitError{Code: 2}
+	}
+	return err
+}
@@ -4,10 +4,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/robertguss/recon/internal/config"
 	"github.com/robertguss/recon/internal/edge"
 	"github.com/robertguss/recon/internal/knowledge"
+	"github.com/robertguss/recon/internal/orient"
+	"github.com/robertguss/recon/internal/recall"
 	"github.com/spf13/cobra"
 )
 
@@ -23,12 +30,20 @@ func newDecideCommand(app *App) *cobra.Command {
 		checkSymbol     string
 		checkPattern    string
 		checkScope      string
+		checkTimeout    time.Duration
+		verifyInterval  time.Duration
 		jsonOut         bool
 		listFlag        bool
 		deleteID        int64
 		updateID        int64
 		dryRun          bool
 		affectsRefs     []string
+		yes             bool
+		branchScoped    bool
+		promoteID       int64
+		strict          bool
+		checkFlags      []string
+		combine         string
 	)
 
 	cmd := &cobra.Command{
@@ -36,6 +51,10 @@ func newDecideCommand(app *App) *cobra.Command {
 		Short: "Propose a decision, verify evidence, and auto-promote when checks pass",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// The local --yes shadows the global one, so it answers the
+			// global confirmations too.
+			app.Yes = app.Yes || yes
+
 			// List mode
 			if listFlag {
 				conn, err := openExistingDB(app)
@@ -64,7 +83,11 @@ func newDecideCommand(app *App) *cobra.Command {
 					return nil
 				}
 				for _, item := range items {
-					fmt.Printf("#%d %s (confidence=%s, drift=%s)\n", item.ID, item.Title, item.Confidence, item.Drift)
+					branch := ""
+					if item.Branch != "" {
+						branch = ", branch=" + item.Branch
+					}
+					fmt.Printf("#%d %s (confidence=%s, drift=%s%s)\n", item.ID, item.Title, item.Confidence, item.Drift, branch)
 				}
 				return nil
 			}
@@ -80,7 +103,32 @@ func newDecideCommand(app *App) *cobra.Command {
 				}
 				defer conn.Close()
 
-				err = knowledge.NewService(conn).ArchiveDecision(cmd.Context(), deleteID)
+				svc := knowledge.NewService(conn)
+				impact, err := svc.DecisionArchiveImpact(cmd.Context(), deleteID)
+				if err == nil {
+					impact.RenderedIn = renderedDecisionFiles(app.ModuleRoot, impact.DecisionID, impact.Title)
+					if !impact.Empty() && !yes {
+						if jsonOut || app.NoPrompt || !isInteractive() {
+							msg := fmt.Sprintf("archiving decision %d removes the backing of other knowledge; re-run with --yes to confirm", deleteID)
+							if jsonOut {
+								_ = writeJSONError("confirmation_required", msg, map[string]any{"id": deleteID, "impact": impact})
+								return ExitError{Code: 2}
+							}
+							printArchiveImpact(impact)
+							return ExitError{Code: 2, Message: msg}
+						}
+						printArchiveImpact(impact)
+						confirmed, askErr := askYesNo(fmt.Sprintf("Archive decision %d? [y/N]: ", deleteID), false)
+						if askErr != nil {
+							return fmt.Errorf("read archive prompt: %w", askErr)
+						}
+						if !confirmed {
+							fmt.Println("Aborted.")
+							return nil
+						}
+					}
+					err = svc.ArchiveDecision(cmd.Context(), deleteID)
+				}
 				if err != nil {
 					if jsonOut {
 						code := "internal_error"
@@ -93,9 +141,46 @@ func newDecideCommand(app *App) *cobra.Command {
 					return err
 				}
 				if jsonOut {
-					return writeJSON(map[string]any{"archived": true, "id": deleteID})
+					return writeJSON(map[string]any{"archived": true, "id": deleteID, "impact": impact})
 				}
 				fmt.Printf("Decision %d archived.\n", deleteID)
+				if len(impact.RenderedIn) > 0 {
+					fmt.Printf("Run `recon agents-md` to drop it from %s.\n", strings.Join(impact.RenderedIn, ", "))
+				}
+				return nil
+			}
+
+			// Promote mode
+			if promoteID > 0 {
+				conn, err := openExistingDB(app)
+				if err != nil {
+					if jsonOut {
+						return exitJSONCommandError(err)
+					}
+					return err
+				}
+				defer conn.Close()
+
+				branch, err := knowledge.NewService(conn).PromoteDecisionToMain(cmd.Context(), promoteID)
+				if err != nil {
+					if jsonOut {
+						code := "internal_error"
+						if errors.Is(err, knowledge.ErrNotFound) {
+							code = "not_found"
+						}
+						_ = writeJSONError(code, err.Error(), map[string]any{"id": promoteID})
+						return ExitError{Code: 2}
+					}
+					return err
+				}
+				if jsonOut {
+					return writeJSON(map[string]any{"promoted": branch != "", "id": promoteID, "branch": branch})
+				}
+				if branch == "" {
+					fmt.Printf("Decision %d is not branch-scoped.\n", promoteID)
+					return nil
+				}
+				fmt.Printf("Decision %d promoted to main (was scoped to %s).\n", promoteID, branch)
 				return nil
 			}
 
@@ -178,7 +263,7 @@ func newDecideCommand(app *App) *cobra.Command {
 
 			// Dry-run mode
 			if dryRun {
-				resolvedSpec, err := buildCheckSpec(checkType, checkSpec, checkPath, checkSymbol, checkPattern, checkScope)
+				checks, err := decideChecks(checkFlags, combine, checkType, checkSpec, checkPath, checkSymbol, checkPattern, checkScope, checkTimeout)
 				if err != nil {
 					if jsonOut {
 						details := map[string]any{"check_type": checkType}
@@ -197,7 +282,14 @@ func newDecideCommand(app *App) *cobra.Command {
 				}
 				defer conn.Close()
 
-				outcome := knowledge.NewService(conn).RunCheckPublic(cmd.Context(), checkType, resolvedSpec, app.ModuleRoot)
+				outcome, err := knowledge.NewService(conn).RunChecks(cmd.Context(), checks, combine, app.ModuleRoot)
+				if err != nil {
+					if jsonOut {
+						_ = writeJSONError("internal_error", err.Error(), nil)
+						return ExitError{Code: 2}
+					}
+					return err
+				}
 
 				type dryRunResult struct {
 					Passed  bool   `json:"passed"`
@@ -232,7 +324,23 @@ func newDecideCommand(app *App) *cobra.Command {
 			}
 			title := args[0]
 
-			resolvedSpec, err := buildCheckSpec(checkType, checkSpec, checkPath, checkSymbol, checkPattern, checkScope)
+			branch := ""
+			if branchScoped {
+				branch = currentBranch(cmd.Context(), app.ModuleRoot)
+				if branch == "" {
+					msg := "--branch requires a checked-out git branch"
+					if jsonOut {
+						_ = writeJSONError("invalid_input", msg, map[string]any{"command": "decide"})
+						return ExitError{Code: 2}
+					}
+					return ExitError{Code: 2, Message: msg}
+				}
+			}
+
+			checks, err := decideChecks(checkFlags, combine, checkType, checkSpec, checkPath, checkSymbol, checkPattern, checkScope, checkTimeout)
+			if err == nil && verifyInterval < 0 {
+				err = fmt.Errorf("--verify-interval must not be negative")
+			}
 			if err != nil {
 				if jsonOut {
 					details := map[string]any{"check_type": checkType}
@@ -251,18 +359,55 @@ func newDecideCommand(app *App) *cobra.Command {
 			}
 			defer conn.Close()
 
+			cfg, err := loadConfig(app.ModuleRoot)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"path": config.Path(app.ModuleRoot)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			similar, err := recall.NewService(conn).SimilarDecisions(cmd.Context(), title, reasoning, recall.RecallOptions{Branch: currentBranch(cmd.Context(), app.ModuleRoot)})
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+			if len(similar) > 0 {
+				if strict {
+					msg := "similar active decisions already exist; update one with --update <id> or re-run without --strict"
+					if jsonOut {
+						_ = writeJSONError("similar_exists", msg, map[string]any{"similar": similar})
+						return ExitError{Code: 2}
+					}
+					printSimilarDecisions(os.Stderr, similar)
+					return ExitError{Code: 2, Message: msg}
+				}
+				if !jsonOut {
+					printSimilarDecisions(os.Stderr, similar)
+				}
+			}
+
 			result, err := knowledge.NewService(conn).ProposeAndVerifyDecision(cmd.Context(), knowledge.ProposeDecisionInput{
 				Title:           title,
 				Reasoning:       reasoning,
 				Confidence:      confidence,
 				EvidenceSummary: evidenceSummary,
-				CheckType:       checkType,
-				CheckSpec:       resolvedSpec,
+				CheckType:       checks[0].Type,
+				CheckSpec:       checks[0].Spec,
+				Checks:          checks,
+				Combine:         combine,
 				ModuleRoot:      app.ModuleRoot,
+				Policy:          evidencePolicy(cfg.Knowledge),
+				VerifyInterval:  verifyInterval,
+				Branch:          branch,
 			})
 			if err != nil {
 				if jsonOut {
-					code, details := classifyDecideError(checkType, err)
+					code, details := classifyDecideError(checks[0].Type, err)
 					_ = writeJSONError(code, err.Error(), details)
 					return ExitError{Code: 2}
 				}
@@ -300,12 +445,13 @@ func newDecideCommand(app *App) *cobra.Command {
 				}
 			}
 
+			result.Similar = similar
 			if jsonOut {
 				if !result.VerificationPassed {
 					errorCode := classifyDecideMessage(result.VerificationDetails)
 					details := map[string]any{
 						"proposal_id": result.ProposalID,
-						"check_type":  checkType,
+						"check_type":  checks[0].Type,
 					}
 					_ = writeJSONError(errorCode, result.VerificationDetails, details)
 					return ExitError{Code: 2}
@@ -315,6 +461,9 @@ func newDecideCommand(app *App) *cobra.Command {
 
 			if result.Promoted {
 				fmt.Printf("Decision promoted: proposal=%d decision=%d\n", result.ProposalID, result.DecisionID)
+				if result.Branch != "" {
+					fmt.Printf("Scoped to branch %s; run `recon decide --promote-to-main %d` after merging.\n", result.Branch, result.DecisionID)
+				}
 			} else {
 				fmt.Printf("Decision pending: proposal=%d\n", result.ProposalID)
 			}
@@ -327,17 +476,22 @@ func newDecideCommand(app *App) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&reasoning, "reasoning", "", "Decision reasoning")
-	cmd.Flags().StringVar(&confidence, "confidence", "medium", "Confidence: low, medium, high")
+	cmd.Flags().StringVar(&confidence, "confidence", "", "Confidence: low, medium, high (default knowledge.default_confidence, else medium)")
 	cmd.Flags().StringVar(&evidenceSummary, "evidence-summary", "", "Evidence summary")
-	cmd.Flags().StringVar(&checkType, "check-type", "", "Verification check type: grep_pattern, symbol_exists, file_exists")
-	cmd.Flags().StringVar(&checkSpec, "check-spec", "", "Verification check spec JSON")
-	cmd.Flags().StringVar(&checkPath, "check-path", "", "Typed check field for file_exists: path")
+	cmd.Flags().StringVar(&checkType, "check-type", "", "Verification check type: grep_pattern, grep_absent, not_grep_pattern, import_absent, symbol_exists, symbol_count, file_exists, file_absent, go_build_passes, go_test_passes, command_output")
+	cmd.Flags().StringVar(&checkSpec, "check-spec", "", "Verification check spec JSON, or a JSON array of {\"type\",\"spec\"} checks")
+	cmd.Flags().StringArrayVar(&checkFlags, "check", nil, "Verification check as type:{json spec}; repeat to back the decision with several checks")
+	cmd.Flags().StringVar(&combine, "combine", "all", "With several checks: all must pass, or any")
+	cmd.Flags().StringVar(&checkPath, "check-path", "", "Typed check field for file_exists/file_absent: path")
 	cmd.Flags().StringVar(&checkSymbol, "check-symbol", "", "Typed check field for symbol_exists: symbol name")
-	cmd.Flags().StringVar(&checkPattern, "check-pattern", "", "Typed check field for grep_pattern: regex pattern")
-	cmd.Flags().StringVar(&checkScope, "check-scope", "", "Typed check field for grep_pattern: optional file glob scope")
+	cmd.Flags().StringVar(&checkPattern, "check-pattern", "", "Typed check field for grep_pattern/grep_absent/not_grep_pattern (regex) or import_absent (forbidden package pattern)")
+	cmd.Flags().StringVar(&checkScope, "check-scope", "", "Typed check field for grep_pattern/grep_absent/not_grep_pattern (file glob), import_absent (importing packages), or go_build_passes/go_test_passes (package patterns)")
+	cmd.Flags().DurationVar(&checkTimeout, "check-timeout", 0, "Typed check field for go_build_passes/go_test_passes: time limit (default 2m build, 5m test)")
+	cmd.Flags().DurationVar(&verifyInterval, "verify-interval", 0, "How often recon verify --due re-checks the evidence (default 24h, 168h for build/test checks)")
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
 	cmd.Flags().BoolVar(&listFlag, "list", false, "List active decisions")
 	cmd.Flags().Int64Var(&deleteID, "archive", 0, "Archive (soft-delete) a decision by ID")
+	cmd.Flags().BoolVar(&yes, "yes", false, "Archive without confirming when edges, patterns, or rendered files depend on the decision, and answer yes to other confirmations")
 	// --delete kept as a hidden alias for backward compatibility
 	cmd.Flags().Int64Var(&deleteID, "delete", 0, "")
 	_ = cmd.Flags().MarkHidden("delete")
@@ -345,11 +499,60 @@ func newDecideCommand(app *App) *cobra.Command {
 	cmd.Flags().StringVar(&updateTitle, "title", "", "New title (for --update mode)")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Run verification check only, without creating any state")
 	cmd.Flags().StringSliceVar(&affectsRefs, "affects", nil, "Package/file/symbol this decision affects (creates edges)")
+	cmd.Flags().BoolVar(&branchScoped, "branch", false, "Scope the decision to the current git branch")
+	cmd.Flags().Int64Var(&promoteID, "promote-to-main", 0, "Clear a decision's branch scope by ID, e.g. after merging")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Refuse to record the decision when a similar active decision exists")
 
 	return cmd
 }
 
-func buildCheckSpec(checkType string, checkSpec string, checkPath string, checkSymbol string, checkPattern string, checkScope string) (string, error) {
+// decideChecks returns the evidence checks a decide invocation names: each
+// --check type:{spec}, a --check-spec JSON array of checks, or the single
+// check of --check-type with --check-spec or the typed check flags.
+func decideChecks(checkFlags []string, combine, checkType, checkSpec, checkPath, checkSymbol, checkPattern, checkScope string, checkTimeout time.Duration) ([]knowledge.Check, error) {
+	if combine != knowledge.CombineAll && combine != knowledge.CombineAny {
+		return nil, fmt.Errorf("--combine must be all or any, got %q", combine)
+	}
+	typedProvided := checkPath != "" || checkSymbol != "" || checkPattern != "" || checkScope != "" || checkTimeout != 0
+	var checks []knowledge.Check
+	switch spec := strings.TrimSpace(checkSpec); {
+	case len(checkFlags) > 0:
+		if checkType != "" || spec != "" || typedProvided {
+			return nil, fmt.Errorf("cannot combine --check with --check-type, --check-spec, or typed check flags")
+		}
+		for _, raw := range checkFlags {
+			typ, spec, ok := strings.Cut(raw, ":")
+			typ, spec = strings.TrimSpace(typ), strings.TrimSpace(spec)
+			if !ok || typ == "" || spec == "" {
+				return nil, fmt.Errorf("--check %q must be type:{json check spec}", raw)
+			}
+			checks = append(checks, knowledge.Check{Type: typ, Spec: spec})
+		}
+	case strings.HasPrefix(spec, "["):
+		if checkType != "" || typedProvided {
+			return nil, fmt.Errorf("a --check-spec array names its own check types; drop --check-type and typed check flags")
+		}
+		parsed, err := knowledge.ParseChecks(spec)
+		if err != nil {
+			return nil, err
+		}
+		checks = parsed
+	default:
+		resolved, err := buildCheckSpec(checkType, checkSpec, checkPath, checkSymbol, checkPattern, checkScope, checkTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return []knowledge.Check{{Type: checkType, Spec: resolved}}, nil
+	}
+	for _, c := range checks {
+		if !supportedCheckType(c.Type) {
+			return nil, fmt.Errorf("unsupported check type %q; must be one of: file_exists, file_absent, symbol_exists, symbol_count, grep_pattern, grep_absent, not_grep_pattern, import_absent, go_build_passes, go_test_passes, command_output", c.Type)
+		}
+	}
+	return checks, nil
+}
+
+func buildCheckSpec(checkType string, checkSpec string, checkPath string, checkSymbol string, checkPattern string, checkScope string, checkTimeout time.Duration) (string, error) {
 	checkType = strings.TrimSpace(checkType)
 	checkSpec = strings.TrimSpace(checkSpec)
 	checkPath = strings.TrimSpace(checkPath)
@@ -357,27 +560,27 @@ func buildCheckSpec(checkType string, checkSpec string, checkPath string, checkS
 	checkPattern = strings.TrimSpace(checkPattern)
 	checkScope = strings.TrimSpace(checkScope)
 
-	typedProvided := checkPath != "" || checkSymbol != "" || checkPattern != "" || checkScope != ""
+	typedProvided := checkPath != "" || checkSymbol != "" || checkPattern != "" || checkScope != "" || checkTimeout != 0
 	if checkSpec != "" && typedProvided {
 		return "", fmt.Errorf("cannot combine --check-spec with typed check flags")
 	}
 	if checkType != "" && !supportedCheckType(checkType) {
-		return "", fmt.Errorf("unsupported check type %q; must be one of: file_exists, symbol_exists, grep_pattern", checkType)
+		return "", fmt.Errorf("unsupported check type %q; must be one of: file_exists, file_absent, symbol_exists, symbol_count, grep_pattern, grep_absent, not_grep_pattern, import_absent, go_build_passes, go_test_passes, command_output", checkType)
 	}
 	if checkSpec != "" {
 		return checkSpec, nil
 	}
-	if !typedProvided {
+	if !typedProvided && checkType != "go_build_passes" {
 		return "", fmt.Errorf("either --check-spec or typed check flags are required")
 	}
 
 	switch checkType {
-	case "file_exists":
+	case "file_exists", "file_absent":
 		if checkPath == "" {
-			return "", fmt.Errorf("--check-path is required for check-type file_exists")
+			return "", fmt.Errorf("--check-path is required for check-type %s", checkType)
 		}
-		if checkSymbol != "" || checkPattern != "" || checkScope != "" {
-			return "", fmt.Errorf("file_exists only supports --check-path")
+		if checkSymbol != "" || checkPattern != "" || checkScope != "" || checkTimeout != 0 {
+			return "", fmt.Errorf("%s only supports --check-path", checkType)
 		}
 		return marshalCheckSpec(struct {
 			Path string `json:"path"`
@@ -386,31 +589,63 @@ func buildCheckSpec(checkType string, checkSpec string, checkPath string, checkS
 		if checkSymbol == "" {
 			return "", fmt.Errorf("--check-symbol is required for check-type symbol_exists")
 		}
-		if checkPath != "" || checkPattern != "" || checkScope != "" {
+		if checkPath != "" || checkPattern != "" || checkScope != "" || checkTimeout != 0 {
 			return "", fmt.Errorf("symbol_exists only supports --check-symbol")
 		}
 		return marshalCheckSpec(struct {
 			Name string `json:"name"`
 		}{Name: checkSymbol})
-	case "grep_pattern":
+	case "symbol_count":
+		// A query and its bounds have no typed flags.
+		return "", fmt.Errorf(`symbol_count requires --check-spec, e.g. '{"query":"kind=func AND package=internal/cli","max":50}'`)
+	case "command_output":
+		return "", fmt.Errorf(`command_output requires --check-spec, e.g. '{"command":"go vet ./...","timeout_seconds":60}'`)
+	case "grep_pattern", "grep_absent", "not_grep_pattern":
 		if checkPattern == "" {
-			return "", fmt.Errorf("--check-pattern is required for check-type grep_pattern")
+			return "", fmt.Errorf("--check-pattern is required for check-type %s", checkType)
 		}
-		if checkPath != "" || checkSymbol != "" {
-			return "", fmt.Errorf("grep_pattern supports --check-pattern and optional --check-scope only")
+		if checkPath != "" || checkSymbol != "" || checkTimeout != 0 {
+			return "", fmt.Errorf("%s supports --check-pattern and optional --check-scope only", checkType)
 		}
 		return marshalCheckSpec(struct {
 			Pattern string `json:"pattern"`
 			Scope   string `json:"scope,omitempty"`
 		}{Pattern: checkPattern, Scope: checkScope})
+	case "import_absent":
+		// The forbidden import and the importing packages are package
+		// patterns, carried by --check-pattern and --check-scope.
+		if checkPattern == "" {
+			return "", fmt.Errorf("--check-pattern is required for check-type import_absent")
+		}
+		if checkPath != "" || checkSymbol != "" || checkTimeout != 0 {
+			return "", fmt.Errorf("import_absent supports --check-pattern and optional --check-scope only")
+		}
+		return marshalCheckSpec(struct {
+			Import string `json:"import"`
+			Scope  string `json:"scope,omitempty"`
+		}{Import: checkPattern, Scope: checkScope})
+	case "go_build_passes", "go_test_passes":
+		if checkType == "go_test_passes" && checkScope == "" {
+			return "", fmt.Errorf("--check-scope is required for check-type go_test_passes")
+		}
+		if checkPath != "" || checkSymbol != "" || checkPattern != "" {
+			return "", fmt.Errorf("%s supports optional --check-scope and --check-timeout only", checkType)
+		}
+		if checkTimeout < 0 {
+			return "", fmt.Errorf("--check-timeout must be >= 0")
+		}
+		return marshalCheckSpec(struct {
+			Scope          string `json:"scope,omitempty"`
+			TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+		}{Scope: checkScope, TimeoutSeconds: int((checkTimeout + time.Second - 1) / time.Second)})
 	default:
-		return "", fmt.Errorf("unsupported check type %q; must be one of: file_exists, symbol_exists, grep_pattern", checkType)
+		return "", fmt.Errorf("unsupported check type %q; must be one of: file_exists, file_absent, symbol_exists, symbol_count, grep_pattern, grep_absent, not_grep_pattern, import_absent, go_build_passes, go_test_passes, command_output", checkType)
 	}
 }
 
 func supportedCheckType(checkType string) bool {
 	switch checkType {
-	case "file_exists", "symbol_exists", "grep_pattern":
+	case "file_exists", "file_absent", "symbol_exists", "symbol_count", "grep_pattern", "grep_absent", "not_grep_pattern", "import_absent", "go_build_passes", "go_test_passes", "command_output":
 		return true
 	default:
 		return false
@@ -425,10 +660,18 @@ func marshalCheckSpec(v any) (string, error) {
 	return string(spec), nil
 }
 
+// evidencePolicy converts the knowledge section of .recon/config.json into
+// the policy decide and pattern proposals are checked against.
+func evidencePolicy(cfg config.Knowledge) knowledge.EvidencePolicy {
+	return knowledge.EvidencePolicy{
+		DefaultConfidence: cfg.DefaultConfidence,
+		RequiredChecks:    cfg.RequiredChecks,
+	}
+}
+
 func classifyDecideError(checkType string, err error) (string, any) {
-	code := classifyDecideMessage(err.Error())
-	if code == "invalid_input" {
-		return code, map[string]any{"check_type": checkType}
+	if errors.Is(err, knowledge.ErrEvidenceTooWeak) || classifyDecideMessage(err.Error()) == "invalid_input" {
+		return "invalid_input", map[string]any{"check_type": checkType}
 	}
 	return "internal_error", nil
 }
@@ -459,3 +702,39 @@ func classifyDecideMessage(msg string) string {
 		return "verification_failed"
 	}
 }
+
+// renderedDecisionFiles lists the instruction files, relative to root, whose
+// generated recon section still lists the decision.
+func renderedDecisionFiles(root string, id int64, title string) []string {
+	marker := orient.DecisionMarker(id, title)
+	files := []string{}
+	for _, name := range []string{"AGENTS.md", "CLAUDE.md"} {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err == nil && strings.Contains(string(data), marker) {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+// printSimilarDecisions warns that the decision being recorded resembles
+// active ones.
+func printSimilarDecisions(w io.Writer, similar []recall.SimilarDecision) {
+	fmt.Fprintln(w, "Warning: similar active decisions exist:")
+	for _, d := range similar {
+		fmt.Fprintf(w, "- #%d %s (confidence=%s, score=%.2f)\n", d.DecisionID, d.Title, d.Confidence, d.Score)
+	}
+}
+
+func printArchiveImpact(impact knowledge.ArchiveImpact) {
+	fmt.Printf("Archiving decision %d (%s) affects:\n", impact.DecisionID, impact.Title)
+	for _, e := range impact.Edges {
+		fmt.Printf("- edge #%d %s:%d -[%s]-> %s:%s\n", e.ID, e.FromType, e.FromID, e.Relation, e.ToType, e.ToRef)
+	}
+	for _, p := range impact.Patterns {
+		fmt.Printf("- pattern #%d %s (%s)\n", p.ID, p.Title, p.Relation)
+	}
+	for _, f := range impact.RenderedIn {
+		fmt.Printf("- rendered in %s\n", f)
+	}
+}
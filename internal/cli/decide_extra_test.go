@@ -6,29 +6,72 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuildCheckSpec(t *testing.T) {
-	spec, err := buildCheckSpec("file_exists", `{"path":"go.mod"}`, "", "", "", "")
+	spec, err := buildCheckSpec("file_exists", `{"path":"go.mod"}`, "", "", "", "", 0)
 	if err != nil || spec != `{"path":"go.mod"}` {
 		t.Fatalf("expected raw spec passthrough, spec=%q err=%v", spec, err)
 	}
 
-	spec, err = buildCheckSpec("file_exists", "", "go.mod", "", "", "")
+	spec, err = buildCheckSpec("file_exists", "", "go.mod", "", "", "", 0)
 	if err != nil || spec != `{"path":"go.mod"}` {
 		t.Fatalf("expected file_exists typed spec, spec=%q err=%v", spec, err)
 	}
 
-	spec, err = buildCheckSpec("symbol_exists", "", "", "Alpha", "", "")
+	spec, err = buildCheckSpec("symbol_exists", "", "", "Alpha", "", "", 0)
 	if err != nil || spec != `{"name":"Alpha"}` {
 		t.Fatalf("expected symbol_exists typed spec, spec=%q err=%v", spec, err)
 	}
 
-	spec, err = buildCheckSpec("grep_pattern", "", "", "", "package", "*.go")
+	spec, err = buildCheckSpec("grep_pattern", "", "", "", "package", "*.go", 0)
 	if err != nil || spec != `{"pattern":"package","scope":"*.go"}` {
 		t.Fatalf("expected grep_pattern typed spec, spec=%q err=%v", spec, err)
 	}
 
+	spec, err = buildCheckSpec("file_absent", "", "vendor", "", "", "", 0)
+	if err != nil || spec != `{"path":"vendor"}` {
+		t.Fatalf("expected file_absent typed spec, spec=%q err=%v", spec, err)
+	}
+
+	spec, err = buildCheckSpec("not_grep_pattern", "", "", "", "reflect", "internal/index/*.go", 0)
+	if err != nil || spec != `{"pattern":"reflect","scope":"internal/index/*.go"}` {
+		t.Fatalf("expected not_grep_pattern typed spec, spec=%q err=%v", spec, err)
+	}
+
+	spec, err = buildCheckSpec("symbol_count", `{"query":"kind=func","max":50}`, "", "", "", "", 0)
+	if err != nil || spec != `{"query":"kind=func","max":50}` {
+		t.Fatalf("expected symbol_count raw spec, spec=%q err=%v", spec, err)
+	}
+	if _, err = buildCheckSpec("symbol_count", "", "", "", "kind=func", "", 0); err == nil || !strings.Contains(err.Error(), "requires --check-spec") {
+		t.Fatalf("expected symbol_count typed flags error, got %v", err)
+	}
+
+	if _, err = buildCheckSpec("command_output", "", "", "", "", "", time.Minute); err == nil || !strings.Contains(err.Error(), "requires --check-spec") {
+		t.Fatalf("expected command_output typed flags error, got %v", err)
+	}
+
+	spec, err = buildCheckSpec("go_build_passes", "", "", "", "", "", 0)
+	if err != nil || spec != `{}` {
+		t.Fatalf("expected default go_build_passes spec, spec=%q err=%v", spec, err)
+	}
+
+	spec, err = buildCheckSpec("go_test_passes", "", "", "", "", "./internal/x", 1500*time.Millisecond)
+	if err != nil || spec != `{"scope":"./internal/x","timeout_seconds":2}` {
+		t.Fatalf("expected go_test_passes typed spec, spec=%q err=%v", spec, err)
+	}
+
+	if _, err := buildCheckSpec("go_test_passes", "", "", "", "", "", time.Minute); err == nil || !strings.Contains(err.Error(), "--check-scope is required") {
+		t.Fatalf("expected go_test_passes scope error, got %v", err)
+	}
+	if _, err := buildCheckSpec("go_build_passes", "", "go.mod", "", "", "", 0); err == nil || !strings.Contains(err.Error(), "supports optional --check-scope") {
+		t.Fatalf("expected go_build_passes field error, got %v", err)
+	}
+	if _, err := buildCheckSpec("file_exists", "", "go.mod", "", "", "", time.Minute); err == nil || !strings.Contains(err.Error(), "only supports --check-path") {
+		t.Fatalf("expected file_exists timeout rejection, got %v", err)
+	}
+
 	for _, tc := range []struct {
 		name      string
 		checkType string
@@ -104,7 +147,7 @@ func TestBuildCheckSpec(t *testing.T) {
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := buildCheckSpec(tc.checkType, tc.checkSpec, tc.checkPath, tc.checkSym, tc.checkPat, tc.checkScp)
+			_, err := buildCheckSpec(tc.checkType, tc.checkSpec, tc.checkPath, tc.checkSym, tc.checkPat, tc.checkScp, 0)
 			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
 				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
 			}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/robertguss/recon/internal/deps"
+	"github.com/spf13/cobra"
+)
+
+func newDepsCommand(app *App) *cobra.Command {
+	var (
+		jsonOut  bool
+		external bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "deps [<package>]",
+		Short: "Show a package's imports, importers, and external dependencies",
+		Long: "Show what a package depends on and what depends on it: module packages it imports,\n" +
+			"module packages importing it, standard library imports, and external imports with the\n" +
+			"go.mod requirement that provides each. Fan-out and fan-in count module packages only:\n" +
+			"those imported and those importing it. The package is a module-relative path or a full\n" +
+			"import path. With --external, list every go.mod requirement instead, with its version,\n" +
+			"replacement, and the packages importing it.",
+		Example: "  recon deps internal/index\n  recon deps github.com/robertguss/recon/internal/db --json\n  recon deps --external",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if external && len(args) > 0 {
+				msg := "deps --external takes no <package> argument"
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, map[string]any{"package": args[0]})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			if !external && len(args) == 0 {
+				msg := "deps requires a <package> argument"
+				if jsonOut {
+					_ = writeJSONError("missing_argument", msg, map[string]any{"command": "deps"})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+			svc := deps.NewService(conn)
+
+			if external {
+				inv, err := svc.External(cmd.Context())
+				if err != nil {
+					if jsonOut {
+						_ = writeJSONError("internal_error", err.Error(), nil)
+						return ExitError{Code: 2}
+					}
+					return err
+				}
+				if jsonOut {
+					return writeJSON(inv)
+				}
+				fmt.Print(deps.RenderInventoryText(inv))
+				return nil
+			}
+
+			ref := normalizeFindPath(app.modulePath(args[0]))
+			result, err := svc.Deps(cmd.Context(), ref)
+			switch {
+			case errors.Is(err, deps.ErrPackageNotFound):
+				msg := err.Error() + "; see `recon find --list-packages`"
+				if jsonOut {
+					_ = writeJSONError("not_found", msg, map[string]any{"package": ref})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			case err != nil:
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+
+			if jsonOut {
+				return writeJSON(result)
+			}
+			fmt.Print(deps.RenderText(result))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().BoolVar(&external, "external", false, "List go.mod requirements and the packages importing each")
+	return cmd
+}
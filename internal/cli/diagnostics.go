@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/robertguss/recon/internal/diagnostics"
+	"github.com/spf13/cobra"
+)
+
+func newDiagnosticsCommand(app *App) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "diagnostics [path...]",
+		Short: "Map drifting knowledge and anti-patterns to file ranges for editors",
+		Long: "Report code whose recorded knowledge no longer holds: the files and symbols\n" +
+			"linked to decisions or patterns with drifting or broken evidence, and those linked\n" +
+			"to patterns as anti-patterns (\"contradicts\" edges). Package links are skipped.\n\n" +
+			"--json prints one entry per file shaped like LSP publishDiagnostics parameters\n" +
+			"(zero-based ranges, severity 1 error or 2 warning, source \"recon\"), so an editor\n" +
+			"integration can forward them after each sync. Text output uses one-based\n" +
+			"path:line:column lines. Given paths, only those files are reported, each with an\n" +
+			"empty list when clean so stale marks can be cleared. `recon serve` pushes the same\n" +
+			"entries to editors as they change, on /diagnostics/stream.",
+		Example: "  recon diagnostics\n  recon diagnostics internal/db/db.go --json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := make([]string, 0, len(args))
+			for _, arg := range args {
+				rel, ok := moduleRelPath(app.ModuleRoot, arg)
+				if !ok {
+					msg := fmt.Sprintf("%s is outside the module", arg)
+					if jsonOut {
+						_ = writeJSONError("invalid_input", msg, map[string]any{"path": arg})
+						return ExitError{Code: 2}
+					}
+					return ExitError{Code: 2, Message: msg}
+				}
+				paths = append(paths, rel)
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			files, err := diagnostics.NewService(conn).Collect(cmd.Context(), app.ModuleRoot, paths)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+
+			for i := range files {
+				files[i].Path = app.displayPath(files[i].Path)
+			}
+			if jsonOut {
+				return writeJSON(files)
+			}
+			count := 0
+			for _, f := range files {
+				for _, d := range f.Diagnostics {
+					severity := "warning"
+					if d.Severity == diagnostics.SeverityError {
+						severity = "error"
+					}
+					fmt.Printf("%s:%d:%d: %s: %s [%s]\n", f.Path, d.Range.Start.Line+1, d.Range.Start.Character+1, severity, d.Message, d.Code)
+					count++
+				}
+			}
+			if count == 0 {
+				fmt.Println("No diagnostics.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+// diagnosticsPoll is how often /diagnostics/stream re-reads the index for
+// changed diagnostics; a package-level var for testability.
+var diagnosticsPoll = 2 * time.Second
+
+// serveDiagnostics answers /diagnostics with every flagged file, as
+// `recon diagnostics --json` prints them.
+func serveDiagnostics(app *App, conn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		files, err := diagnostics.NewService(conn).Collect(r.Context(), app.ModuleRoot, nil)
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+			return
+		}
+		writeHTTPJSON(w, http.StatusOK, displayDiagnostics(app, files))
+	}
+}
+
+// serveDiagnosticsStream pushes diagnostics to a client as server-sent
+// events: every flagged file when it connects, then, whenever a sync or a
+// knowledge change alters them, the files whose diagnostics changed, with
+// an empty list for each file that is now clean. Each event is named
+// "diagnostics" and carries a JSON array of files.
+func serveDiagnosticsStream(app *App, conn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeHTTPError(w, http.StatusInternalServerError, "internal_error", "streaming is not supported by this connection", nil)
+			return
+		}
+		svc := diagnostics.NewService(conn)
+		current, err := svc.Collect(r.Context(), app.ModuleRoot, nil)
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+
+		send := func(event string, v any) bool {
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return false
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, raw); err != nil {
+				return false
+			}
+			flusher.Flush()
+			return true
+		}
+		if !send("diagnostics", displayDiagnostics(app, current)) {
+			return
+		}
+
+		ticker := time.NewTicker(diagnosticsPoll)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+			}
+			next, err := svc.Collect(r.Context(), app.ModuleRoot, nil)
+			if err != nil {
+				if r.Context().Err() != nil {
+					return
+				}
+				if !send("error", jsonErrorBody{Code: "internal_error", Message: err.Error()}) {
+					return
+				}
+				continue
+			}
+			if changes := diagnostics.Changes(current, next); len(changes) > 0 {
+				if !send("diagnostics", displayDiagnostics(app, changes)) {
+					return
+				}
+			}
+			current = next
+		}
+	}
+}
+
+// displayDiagnostics returns files with their paths in the display mode,
+// leaving files itself untouched.
+func displayDiagnostics(app *App, files []diagnostics.File) []diagnostics.File {
+	out := make([]diagnostics.File, len(files))
+	for i, f := range files {
+		f.Path = app.displayPath(f.Path)
+		out[i] = f
+	}
+	return out
+}
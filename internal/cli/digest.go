@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/digest"
+	"github.com/spf13/cobra"
+)
+
+func newDigestCommand(app *App) *cobra.Command {
+	var (
+		since   string
+		format  string
+		jsonOut bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Summarize recent syncs, knowledge changes, drift, and hotspots",
+		Long: "Print a shareable report of what changed in a window: sync activity, decisions and\n" +
+			"patterns created, updated, or archived, drift found by verification, and the Go\n" +
+			"packages with the most churn in git history.",
+		Example: "  recon digest\n  recon digest --since 2w --format text\n  recon digest --since 2026-10-01 > digest.md",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := time.Now()
+			start, err := digest.ParseSince(since, now)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"since": since})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+			outFormat, err := parseFormat(format, "markdown", "text", "csv")
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"format": strings.TrimSpace(format)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			d, err := digest.NewService(conn).Build(cmd.Context(), digest.BuildOptions{
+				ModuleRoot: app.ModuleRoot,
+				Since:      start,
+				Now:        now,
+			})
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+
+			if jsonOut {
+				return writeJSON(d)
+			}
+			switch outFormat {
+			case "text":
+				fmt.Print(digest.RenderText(d))
+				return nil
+			case "csv":
+				return writeCSV(hotspotCSV(d.Hotspots))
+			}
+			fmt.Print(digest.RenderMarkdown(d))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "7d", "Start of the window: 7d, 2w, 36h, YYYY-MM-DD, or RFC 3339")
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown, text, or csv (hotspots only)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+func hotspotCSV(hotspots []digest.Hotspot) ([]string, [][]string) {
+	rows := make([][]string, 0, len(hotspots))
+	for _, h := range hotspots {
+		rows = append(rows, []string{
+			h.Package, strconv.Itoa(h.Commits), strconv.Itoa(h.Files), strconv.Itoa(h.LinesAdded), strconv.Itoa(h.LinesDeleted),
+		})
+	}
+	return []string{"package", "commits", "files", "lines_added", "lines_deleted"}, rows
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/robertguss/recon/internal/doctor"
+	"github.com/spf13/cobra"
+)
+
+func newDoctorCommand(app *App) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the database, the Claude Code hook, and the recon binary on PATH",
+		Long: "Check that this project's recon setup works end to end: the database schema\n" +
+			"matches this recon, the SessionStart hook script exists and settings.json\n" +
+			"registers it, and the `recon` the hook invokes resolves on PATH and is this\n" +
+			"build or the same version. A stale binary earlier on PATH, such as an old\n" +
+			"go install copy shadowing a Homebrew or Scoop install, is reported with the\n" +
+			"upgrade command for how it was installed.\n\n" +
+			"Exits 2 when any check warns.",
+		Example: "  recon doctor\n  recon doctor --json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conn, err := openDBAnyVersion(app)
+			if err != nil {
+				var notInit dbNotInitializedError
+				if !errors.As(err, &notInit) {
+					if jsonOut {
+						return exitJSONCommandError(err)
+					}
+					return err
+				}
+				conn = nil
+			} else {
+				defer conn.Close()
+			}
+
+			report := doctor.NewService(conn).Run(cmd.Context(), doctor.Options{
+				ModuleRoot: app.ModuleRoot,
+				Version:    Version,
+				Commit:     Commit,
+			})
+			if jsonOut {
+				if err := writeJSON(report); err != nil {
+					return err
+				}
+			} else {
+				fmt.Print(doctorReport(report.Checks))
+			}
+			if !report.OK {
+				return ExitError{Code: 2}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+func doctorReport(checks []doctor.Check) string {
+	var b strings.Builder
+	for _, c := range checks {
+		fmt.Fprintf(&b, "[%s] %s: %s\n", c.Status, c.Name, c.Message)
+		if c.Hint != "" {
+			fmt.Fprintf(&b, "    %s\n", c.Hint)
+		}
+	}
+	return b.String()
+}
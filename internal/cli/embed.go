@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robertguss/recon/internal/config"
+	"github.com/robertguss/recon/internal/embed"
+	"github.com/spf13/cobra"
+)
+
+func newEmbedCommand(app *App) *cobra.Command {
+	var (
+		jsonOut bool
+		rebuild bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "embed",
+		Short: "Compute vectors for knowledge and symbol docs for semantic recall",
+		Long: "Compute embedding vectors for active decisions, patterns, and constraints and for Go\n" +
+			"symbols with doc comments, with the provider set in embeddings.provider of\n" +
+			".recon/config.json: local (offline word hashing) or openai (the OpenAI API or a\n" +
+			"server compatible with it). Only texts that changed since the last run are sent to\n" +
+			"the provider. `recon recall --semantic` then ranks by similarity to the query.",
+		Example: "  recon embed\n  recon embed --rebuild --json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fail := func(code, msg string, details map[string]any) error {
+				if jsonOut {
+					_ = writeJSONError(code, msg, details)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			cfg, err := loadConfig(app.ModuleRoot)
+			if err != nil {
+				return fail("invalid_input", err.Error(), map[string]any{"path": config.Path(app.ModuleRoot)})
+			}
+			provider, err := embed.NewProvider(cfg.Embeddings)
+			if err != nil {
+				return fail("invalid_input", err.Error(), map[string]any{"path": config.Path(app.ModuleRoot)})
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			result, err := embed.NewService(conn).Embed(cmd.Context(), app.ModuleRoot, provider, rebuild)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			if jsonOut {
+				return writeJSON(result)
+			}
+
+			var parts []string
+			for _, kind := range []string{"decision", "pattern", "constraint", embed.TypeSymbol} {
+				switch n := result.ByType[kind]; n {
+				case 0:
+				case 1:
+					parts = append(parts, "1 "+kind)
+				default:
+					parts = append(parts, fmt.Sprintf("%d %ss", n, kind))
+				}
+			}
+			line := fmt.Sprintf("Embedded %d texts with %s", result.Embedded, result.Provider)
+			if len(parts) > 0 {
+				line += " (" + strings.Join(parts, ", ") + ")"
+			}
+			fmt.Println(line)
+			fmt.Printf("Unchanged: %d\nRemoved: %d\n", result.Unchanged, result.Removed)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().BoolVar(&rebuild, "rebuild", false, "Recompute every vector, not only those whose text changed")
+	return cmd
+}
+
+// semanticProvider returns the configured embeddings provider for
+// `recall --semantic`. A missing or unusable provider is not an error:
+// recall falls back to full-text search and reports the reason.
+func semanticProvider(app *App) (embed.Provider, string, error) {
+	cfg, err := loadConfig(app.ModuleRoot)
+	if err != nil {
+		return nil, "", err
+	}
+	provider, err := embed.NewProvider(cfg.Embeddings)
+	if err != nil {
+		return nil, err.Error(), nil
+	}
+	return provider, "", nil
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robertguss/recon/internal/explain"
+	"github.com/robertguss/recon/internal/find"
+	"github.com/spf13/cobra"
+)
+
+func newExplainSymbolCommand(app *App) *cobra.Command {
+	var (
+		jsonOut       bool
+		noBody        bool
+		packageFilter string
+		fileFilter    string
+		kindFilter    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "explain-symbol <symbol>",
+		Short: "Gather a symbol's body, dependencies, callers, tests, and knowledge",
+		Long: "Collect what recon knows about one symbol into sections an agent can turn into an\n" +
+			"explanation: its body, direct dependencies, direct callers, the tests in its\n" +
+			"package that mention it, imported coverage, and the active decisions and patterns\n" +
+			"linked to the symbol, its file, or its package. Gaps lists what is missing, such\n" +
+			"as no_tests or drifted_knowledge. Nothing is generated; every field is retrieved.",
+		Example: "  recon explain-symbol Sync --package internal/index\n  recon explain-symbol Service.Build --json",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				msg := "explain-symbol requires a <symbol> argument"
+				if jsonOut {
+					_ = writeJSONError("missing_argument", msg, map[string]any{"command": "explain-symbol"})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			kind, err := normalizeFindKind(kindFilter)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"kind": strings.TrimSpace(kindFilter)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			opts := find.QueryOptions{
+				PackagePath: strings.TrimSpace(packageFilter),
+				FilePath:    normalizeFindPath(app.modulePath(fileFilter)),
+				Kind:        kind,
+				NoBody:      noBody,
+			}
+			explanation, err := explain.NewService(conn).Explain(cmd.Context(), app.ModuleRoot, args[0], opts)
+			if err != nil {
+				return writeFindLookupError(app, "explain-symbol", args[0], err, opts, jsonOut)
+			}
+
+			app.applyPathMode(&explanation)
+			if jsonOut {
+				return writeJSON(explanation)
+			}
+			fmt.Print(explain.RenderText(explanation))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().BoolVar(&noBody, "no-body", false, "Omit symbol and dependency bodies without reading them")
+	cmd.Flags().StringVar(&packageFilter, "package", "", "Filter by package path when symbols are ambiguous")
+	cmd.Flags().StringVar(&fileFilter, "file", "", "Filter by file path when symbols are ambiguous")
+	cmd.Flags().StringVar(&kindFilter, "kind", "", "Filter by symbol kind (func, method, type, var, const, enum, table, view, proto_service, proto_rpc, proto_message, proto_enum)")
+	cmd.ValidArgsFunction = completeSymbolArg(app)
+	_ = cmd.RegisterFlagCompletionFunc("package", completePackageFlag(app))
+	_ = cmd.RegisterFlagCompletionFunc("kind", cobra.FixedCompletions(findKinds, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
@@ -6,10 +6,19 @@ import (
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/robertguss/recon/internal/config"
+	"github.com/robertguss/recon/internal/coverage"
 	"github.com/robertguss/recon/internal/edge"
 	"github.com/robertguss/recon/internal/find"
+	"github.com/robertguss/recon/internal/index"
+	"github.com/robertguss/recon/internal/logging"
+	"github.com/robertguss/recon/internal/owners"
 	"github.com/spf13/cobra"
 )
 
@@ -17,16 +26,32 @@ var execCommandContext = exec.CommandContext
 
 func newFindCommand(app *App) *cobra.Command {
 	var (
-		jsonOut       bool
-		noBody        bool
-		maxBodyLines  int
-		packageFilter string
-		fileFilter    string
-		kindFilter    string
-		limit         int
-		listPackages  bool
-		importsOf     string
-		importedBy    string
+		jsonOut          bool
+		noBody           bool
+		maxBodyLines     int
+		packageFilter    string
+		fileFilter       string
+		kindFilter       string
+		limit            int
+		listPackages     bool
+		format           string
+		repo             string
+		importsOf        string
+		importedBy       string
+		listMatches      bool
+		paths            []string
+		excludePaths     []string
+		tags             []string
+		unsafeOnly       bool
+		includeGenerated bool
+		callers          bool
+		callersDepth     int
+		withFields       bool
+		fuzzy            bool
+		regex            bool
+		usages           bool
+		withHistory      bool
+		heatOpts         heatFlags
 	)
 
 	cmd := &cobra.Command{
@@ -34,6 +59,45 @@ func newFindCommand(app *App) *cobra.Command {
 		Short: "Find exact symbol or list symbols by filter",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			outFormat, err := parseFormat(format, "text", "csv", "ndjson")
+			if err == nil && outFormat == "csv" && !listPackages {
+				err = fmt.Errorf("--format csv requires --list-packages")
+			}
+			if err == nil && outFormat == "ndjson" && (len(args) > 0 || listPackages || importsOf != "" || importedBy != "") {
+				err = fmt.Errorf("--format ndjson requires list mode (filter flags without a <symbol>)")
+			}
+			// NDJSON reports errors as --json does.
+			jsonOut = jsonOut || outFormat == "ndjson"
+			app := app
+			if repo != "" {
+				if app, err = repoApp(app, repo); err != nil {
+					return workspaceError(jsonOut, err)
+				}
+			}
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"format": strings.TrimSpace(format)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+			cfg, err := loadConfig(app.ModuleRoot)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"path": config.Path(app.ModuleRoot)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+			heat, err := heatOpts.policy(cmd, cfg.Heat)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
 			if importsOf != "" {
 				conn, connErr := openExistingDB(app)
 				if connErr != nil {
@@ -115,11 +179,14 @@ func newFindCommand(app *App) *cobra.Command {
 					return err
 				}
 
-				enrichPackageHeat(cmd.Context(), app.ModuleRoot, pkgs)
+				enrichPackageHeat(cmd.Context(), app.ModuleRoot, pkgs, heat)
 
 				if jsonOut {
 					return writeJSON(pkgs)
 				}
+				if outFormat == "csv" {
+					return writeCSV(packageCSV(pkgs))
+				}
 
 				fmt.Printf("Packages (%d):\n", len(pkgs))
 				for _, p := range pkgs {
@@ -138,27 +205,66 @@ func newFindCommand(app *App) *cobra.Command {
 				return ExitError{Code: 2, Message: err.Error()}
 			}
 
+			includes, err := find.NormalizePathPatterns(paths)
+			if err == nil {
+				excludePaths, err = find.NormalizePathPatterns(excludePaths)
+			}
+			if err != nil {
+				if jsonOut {
+					details := map[string]any{"path": paths, "exclude_path": excludePaths}
+					_ = writeJSONError("invalid_input", err.Error(), details)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
 			queryOptions := find.QueryOptions{
-				PackagePath: strings.TrimSpace(packageFilter),
-				FilePath:    normalizeFindPath(fileFilter),
-				Kind:        normalizedKind,
+				PackagePath:      strings.TrimSpace(packageFilter),
+				FilePath:         normalizeFindPath(app.modulePath(fileFilter)),
+				Kind:             normalizedKind,
+				Paths:            includes,
+				ExcludePaths:     excludePaths,
+				Tags:             find.NormalizeTags(tags),
+				Unsafe:           unsafeOnly,
+				NoBody:           noBody,
+				Fuzzy:            fuzzy,
+				IncludeGenerated: includeGenerated,
 			}
 
 			// No symbol arg: check for list mode vs missing arg error
 			if len(args) == 0 {
-				hasFilters := queryOptions.PackagePath != "" || queryOptions.FilePath != "" || queryOptions.Kind != ""
+				hasFilters := queryOptions.PackagePath != "" || queryOptions.FilePath != "" || queryOptions.Kind != "" ||
+					len(queryOptions.Paths) > 0 || len(queryOptions.ExcludePaths) > 0 || len(queryOptions.Tags) > 0 || queryOptions.Unsafe
 				if !hasFilters {
-					msg := "find requires a <symbol> argument or filter flags (--package, --file, --kind)"
+					msg := "find requires a <symbol> argument or filter flags (--package, --file, --kind, --path, --tags, --unsafe)"
 					if jsonOut {
 						_ = writeJSONError("missing_argument", msg, map[string]any{"command": "find"})
 						return ExitError{Code: 2}
 					}
 					return ExitError{Code: 2, Message: msg}
 				}
+				if outFormat == "ndjson" {
+					return runFindStreamMode(cmd, app, queryOptions, limit)
+				}
 				return runFindListMode(cmd, app, queryOptions, limit, jsonOut)
 			}
 
 			symbol := args[0]
+			if regex {
+				re, err := regexp.Compile(symbol)
+				if err != nil {
+					msg := fmt.Sprintf("invalid --regex pattern: %v", err)
+					if jsonOut {
+						_ = writeJSONError("invalid_input", msg, map[string]any{"symbol": symbol})
+						return ExitError{Code: 2}
+					}
+					return ExitError{Code: 2, Message: msg}
+				}
+				return runFindMatchesMode(cmd, app, symbol, re, queryOptions, limit, jsonOut)
+			}
+			if listMatches || find.ParseSymbolQuery(symbol).HasWildcard() {
+				return runFindMatchesMode(cmd, app, symbol, nil, queryOptions, limit, jsonOut)
+			}
 			if maxBodyLines < 0 {
 				msg := "--max-body-lines must be >= 0"
 				if jsonOut {
@@ -168,6 +274,18 @@ func newFindCommand(app *App) *cobra.Command {
 				}
 				return ExitError{Code: 2, Message: msg}
 			}
+			if cmd.Flags().Changed("callers-depth") {
+				callers = true
+			}
+			if callersDepth < 1 || callersDepth > find.MaxCallerDepth {
+				msg := fmt.Sprintf("--callers-depth must be between 1 and %d", find.MaxCallerDepth)
+				if jsonOut {
+					details := map[string]any{"flag": "callers_depth", "value": callersDepth}
+					_ = writeJSONError("invalid_input", msg, details)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
 
 			conn, connErr := openExistingDB(app)
 			if connErr != nil {
@@ -178,67 +296,70 @@ func newFindCommand(app *App) *cobra.Command {
 			}
 			defer conn.Close()
 
-			result, findErr := find.NewService(conn).Find(cmd.Context(), symbol, queryOptions)
-			err = findErr
+			findSvc := find.NewService(conn)
+			result, err := findSvc.Find(cmd.Context(), symbol, queryOptions)
 			if err != nil {
-				switch e := err.(type) {
-				case find.NotFoundError:
+				return writeFindLookupError(app, "find", symbol, err, queryOptions, jsonOut)
+			}
+			if callers {
+				if result.Callers, err = findSvc.Callers(cmd.Context(), result.Symbol, callersDepth); err != nil {
 					if jsonOut {
-						details := map[string]any{
-							"symbol":      symbol,
-							"suggestions": e.Suggestions,
-						}
-						if len(e.Suggestions) == 0 {
-							details["tip"] = "try --kind func|type|var|method|const to browse, or --list-packages to see indexed packages"
-						}
-						addFindFilterDetails(details, queryOptions)
-						_ = writeJSONError("not_found", e.Error(), details)
-					} else {
-						if e.Filtered {
-							fmt.Printf("symbol %q not found with provided filters\n", symbol)
-						} else {
-							fmt.Printf("symbol %q not found\n", symbol)
-						}
-						printFindFilters(queryOptions)
-						if len(e.Suggestions) > 0 {
-							fmt.Println("Suggestions:")
-							for _, suggestion := range e.Suggestions {
-								fmt.Printf("- %s\n", suggestion)
-							}
-						} else {
-							fmt.Println("Tip: try --kind func|type|var|method|const to browse, or --list-packages to see indexed packages")
-						}
+						_ = writeJSONError("internal_error", err.Error(), nil)
+						return ExitError{Code: 2}
 					}
-					return ExitError{Code: 2}
-				case find.AmbiguousError:
+					return err
+				}
+			}
+			if usages {
+				if result.Usages, err = findSvc.Usages(cmd.Context(), result.Symbol); err != nil {
 					if jsonOut {
-						details := map[string]any{
-							"symbol":     symbol,
-							"candidates": e.Candidates,
-						}
-						addFindFilterDetails(details, queryOptions)
-						_ = writeJSONError("ambiguous", e.Error(), details)
-					} else {
-						fmt.Printf("symbol %q is ambiguous (%d candidates)\n", symbol, len(e.Candidates))
-						printFindFilters(queryOptions)
-						for _, candidate := range e.Candidates {
-							label := symbol
-							if candidate.Receiver != "" {
-								label = candidate.Receiver + "." + symbol
-							}
-							fmt.Printf("- %s %s (%s, pkg %s)\n", candidate.Kind, label, candidate.FilePath, candidate.Package)
-						}
-						if len(e.Candidates) > 0 {
-							c := e.Candidates[0]
-							label := symbol
-							if c.Receiver != "" {
-								label = c.Receiver + "." + symbol
-							}
-							fmt.Printf("\nTry: recon find %s --package %s\n", label, c.Package)
-						}
+						_ = writeJSONError("internal_error", err.Error(), nil)
+						return ExitError{Code: 2}
 					}
+					return err
+				}
+			}
+			if result.Assets, err = findSvc.Assets(cmd.Context(), result.Symbol.Package); err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+			if result.Owners, err = symbolOwners(cmd.Context(), conn, result.Symbol); err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
 					return ExitError{Code: 2}
-				default:
+				}
+				return err
+			}
+			if result.Deprecated, err = findSvc.Deprecation(cmd.Context(), result.Symbol.ID); err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+			if result.CodeLinks, err = findSvc.CodeLinks(cmd.Context(), result.Symbol); err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+			if withFields && result.Symbol.Kind == "type" {
+				if result.Fields, result.Methods, err = findSvc.TypeMembers(cmd.Context(), result.Symbol.ID); err != nil {
+					if jsonOut {
+						_ = writeJSONError("internal_error", err.Error(), nil)
+						return ExitError{Code: 2}
+					}
+					return err
+				}
+			}
+
+			if withHistory {
+				sym := result.Symbol
+				if result.LastTouch, err = lastTouch(cmd.Context(), app.ModuleRoot, sym.FilePath, sym.LineStart, sym.LineEnd); err != nil {
 					if jsonOut {
 						_ = writeJSONError("internal_error", err.Error(), nil)
 						return ExitError{Code: 2}
@@ -247,16 +368,62 @@ func newFindCommand(app *App) *cobra.Command {
 				}
 			}
 
+			result.Coverage = enrichFindCoverage(cmd.Context(), conn, app.ModuleRoot, result.Symbol, heat)
+			app.applyPathMode(&result)
 			if jsonOut {
-				result.Knowledge = enrichFindKnowledge(cmd, conn, result.Symbol)
+				result.Knowledge = enrichFindKnowledge(cmd.Context(), conn, result.Symbol)
 				return writeJSON(result)
 			}
 
+			if result.FuzzyQuery != "" {
+				fmt.Printf("No symbol %q; showing the closest match.\n", result.FuzzyQuery)
+			}
 			fmt.Printf("%s %s (%s)\n", result.Symbol.Kind, result.Symbol.Name, result.Symbol.FilePath)
 			fmt.Printf("Lines: %d-%d\n", result.Symbol.LineStart, result.Symbol.LineEnd)
+			if result.Symbol.Language != "" {
+				fmt.Printf("Language: %s\n", result.Symbol.Language)
+			}
 			if result.Symbol.Receiver != "" {
 				fmt.Printf("Receiver: %s\n", result.Symbol.Receiver)
 			}
+			if len(result.Symbol.TypeParams) > 0 {
+				params := make([]string, len(result.Symbol.TypeParams))
+				for i, tp := range result.Symbol.TypeParams {
+					params[i] = tp.Name + " " + tp.Constraint
+				}
+				fmt.Printf("Type parameters: %s\n", strings.Join(params, ", "))
+			}
+			if result.Symbol.Platform != "" {
+				fmt.Printf("Platform: %s\n", result.Symbol.Platform)
+			}
+			if len(result.Symbol.Variants) > 0 {
+				fmt.Printf("Platform variants: %s\n", variantsLine(result.Symbol.Variants))
+			}
+			if line := lowLevelLine(result.Symbol); line != "" {
+				fmt.Printf("Handle with care: %s\n", line)
+			}
+			if line := generatedLine(result.Symbol); line != "" {
+				fmt.Printf("Generated: %s\n", line)
+			}
+			if result.Deprecated != "" {
+				fmt.Printf("Deprecated: %s\n", result.Deprecated)
+			}
+			if len(result.Owners) > 0 {
+				fmt.Printf("Owners: %s\n", strings.Join(result.Owners, " "))
+			}
+			if withHistory {
+				if result.LastTouch != nil {
+					fmt.Printf("Last touched: %s <%s>\n", commitLine(*result.LastTouch), result.LastTouch.Email)
+				} else {
+					fmt.Println("Last touched: not committed yet")
+				}
+			}
+			for _, line := range codeLinkLines(result.CodeLinks) {
+				fmt.Println(line)
+			}
+			if c := result.Coverage; c != nil {
+				fmt.Println(findCoverageLine(result.Symbol.Package, c))
+			}
 			if !noBody {
 				fmt.Println("\nBody:")
 				fmt.Println(truncateBody(result.Symbol.Body, maxBodyLines))
@@ -269,20 +436,87 @@ func newFindCommand(app *App) *cobra.Command {
 					fmt.Printf("- %s %s (%s)\n", dep.Kind, dep.Name, dep.FilePath)
 				}
 			}
+			if len(result.Symbol.Members) > 0 {
+				fmt.Println("\nMembers:")
+				for _, m := range result.Symbol.Members {
+					fmt.Printf("- %s = %s\n", m.Name, m.Value)
+				}
+			}
+			if len(result.Embeds) > 0 {
+				fmt.Println("\nEmbeds:")
+				for _, e := range result.Embeds {
+					name := e.Name
+					if e.Pointer {
+						name = "*" + name
+					}
+					fmt.Printf("- %s (%s)\n", name, e.Package)
+				}
+			}
+			if len(result.PromotedMethods) > 0 {
+				fmt.Println("\nPromoted methods:")
+				for _, m := range result.PromotedMethods {
+					fmt.Printf("- %s via %s (%s)\n", m.Name, m.Via, m.FilePath)
+				}
+			}
+			if len(result.Assets) > 0 {
+				fmt.Println("\nEmbedded assets:")
+				for _, a := range result.Assets {
+					fmt.Println(assetLine(a))
+				}
+			}
+			if withFields {
+				printTypeMembers(result)
+			}
+			if callers {
+				fmt.Printf("\nCallers (depth %d):\n", callersDepth)
+				if len(result.Callers) == 0 {
+					fmt.Println("- (none)")
+				}
+				for _, c := range result.Callers {
+					label := c.Name
+					if c.Receiver != "" {
+						label = c.Receiver + "." + c.Name
+					}
+					fmt.Printf("- [%d] %s %s (%s:%d) calls %s\n", c.Depth, c.Kind, label, c.FilePath, c.LineStart, c.Calls)
+				}
+			}
+			if usages {
+				printUsages(result.Usages)
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
-	cmd.Flags().BoolVar(&noBody, "no-body", false, "Omit symbol body in text output")
+	cmd.Flags().BoolVar(&noBody, "no-body", false, "Omit symbol and dependency bodies without reading them from the index")
 	cmd.Flags().IntVar(&maxBodyLines, "max-body-lines", 0, "Maximum symbol body lines in text output (0 = no limit)")
 	cmd.Flags().StringVar(&packageFilter, "package", "", "Filter by package path when symbols are ambiguous")
 	cmd.Flags().StringVar(&fileFilter, "file", "", "Filter by file path when symbols are ambiguous")
-	cmd.Flags().StringVar(&kindFilter, "kind", "", "Filter by symbol kind (func, method, type, var, const)")
-	cmd.Flags().IntVar(&limit, "limit", 50, "Maximum symbols in list mode")
+	cmd.Flags().StringVar(&kindFilter, "kind", "", "Filter by symbol kind (func, method, type, var, const, enum, table, view, proto_service, proto_rpc, proto_message, proto_enum)")
+	cmd.Flags().StringSliceVar(&paths, "path", nil, "Only include packages matching this pattern, e.g. internal/... (repeatable)")
+	cmd.Flags().StringSliceVar(&excludePaths, "exclude-path", nil, "Exclude packages matching this pattern, e.g. internal/testdata/... (repeatable)")
+	cmd.Flags().StringSliceVar(&tags, "tags", nil, "Only include symbols from files built with these build tags, e.g. linux,amd64 (unconstrained files always match)")
+	cmd.Flags().BoolVar(&unsafeOnly, "unsafe", false, "Only include symbols from files that import \"C\" (cgo) or \"unsafe\"")
+	cmd.Flags().BoolVar(&includeGenerated, "include-generated", false, "Also list symbols of generated and vendored files, and let them make a lookup ambiguous")
+	cmd.Flags().BoolVar(&callers, "callers", false, "Also list symbols that depend on the symbol")
+	cmd.Flags().IntVar(&callersDepth, "callers-depth", 1, fmt.Sprintf("Levels of transitive callers to walk (1-%d, implies --callers)", find.MaxCallerDepth))
+	cmd.Flags().BoolVar(&fuzzy, "fuzzy", false, "Resolve a symbol with no exact match to its case-insensitive or single closest fuzzy match")
+	cmd.Flags().BoolVar(&usages, "usages", false, "Also list every call site with its line of source")
+	cmd.Flags().BoolVar(&withHistory, "history", false, "Also show the last commit that touched the symbol's lines, and its author (see recon history)")
+	cmd.Flags().BoolVar(&withFields, "fields", false, "For types, also list struct fields and the declared method set")
+	cmd.Flags().IntVar(&limit, "limit", 50, "Maximum symbols in list mode (0 = no limit with --format ndjson)")
+	cmd.Flags().BoolVar(&regex, "regex", false, "Treat <symbol> as a Go regular expression and list every symbol whose name matches")
+	cmd.Flags().BoolVar(&listMatches, "list", false, "List every symbol matching <symbol> instead of resolving one (implied by '*.Name' and 'Receiver.*')")
 	cmd.Flags().BoolVar(&listPackages, "list-packages", false, "List all indexed packages")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: csv for --list-packages, ndjson to stream list mode one symbol per line")
 	cmd.Flags().StringVar(&importsOf, "imports-of", "", "List packages imported by this package")
 	cmd.Flags().StringVar(&importedBy, "imported-by", "", "List packages that import this package")
+	cmd.Flags().StringVar(&repo, "repo", "", "Query the registered repo with this name (see recon repos) instead of the current one")
+	addHeatFlags(cmd, &heatOpts)
+	cmd.ValidArgsFunction = completeSymbolArg(app)
+	_ = cmd.RegisterFlagCompletionFunc("package", completePackageFlag(app))
+	_ = cmd.RegisterFlagCompletionFunc("kind", cobra.FixedCompletions(findKinds, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("repo", completeRepoFlag)
 	return cmd
 }
 
@@ -305,22 +539,229 @@ func runFindListMode(cmd *cobra.Command, app *App, opts find.QueryOptions, limit
 		return err
 	}
 
+	app.applyPathMode(&result)
 	if jsonOut {
 		return writeJSON(result)
 	}
 
+	printFindList(result)
+	return nil
+}
+
+// runFindStreamMode prints list mode as NDJSON, writing each symbol as it is
+// read rather than building the whole list.
+func runFindStreamMode(cmd *cobra.Command, app *App, opts find.QueryOptions, limit int) error {
+	conn, err := openExistingDB(app)
+	if err != nil {
+		return exitJSONCommandError(err)
+	}
+	defer conn.Close()
+
+	out := newNDJSONWriter(find.ListResult{}, "symbols[]")
+	err = find.NewService(conn).Stream(cmd.Context(), opts, limit, func(sym find.Symbol) error {
+		app.applyPathMode(&sym)
+		return out.Write(sym)
+	})
+	if err != nil {
+		_ = writeJSONError("internal_error", err.Error(), nil)
+		return ExitError{Code: 2}
+	}
+	return nil
+}
+
+func runFindMatchesMode(cmd *cobra.Command, app *App, symbol string, re *regexp.Regexp, opts find.QueryOptions, limit int, jsonOut bool) error {
+	query := find.ParseSymbolQuery(symbol)
+	if re == nil && (query.Name == "" || (query.Receiver == "" && query.Name == "*")) {
+		msg := "wildcard queries need a name or receiver, e.g. New*, *.Close, or Service.*"
+		if jsonOut {
+			_ = writeJSONError("invalid_input", msg, map[string]any{"symbol": symbol})
+			return ExitError{Code: 2}
+		}
+		return ExitError{Code: 2, Message: msg}
+	}
+
+	conn, err := openExistingDB(app)
+	if err != nil {
+		if jsonOut {
+			return exitJSONCommandError(err)
+		}
+		return err
+	}
+	defer conn.Close()
+
+	svc := find.NewService(conn)
+	var result find.ListResult
+	if re != nil {
+		result, err = svc.ListRegex(cmd.Context(), re, opts, limit)
+	} else {
+		result, err = svc.ListMatches(cmd.Context(), symbol, opts, limit)
+	}
+	if err != nil {
+		if jsonOut {
+			_ = writeJSONError("internal_error", err.Error(), nil)
+			return ExitError{Code: 2}
+		}
+		return err
+	}
+
+	app.applyPathMode(&result)
+	if jsonOut {
+		return writeJSON(result)
+	}
+
+	if result.Total == 0 {
+		fmt.Printf("No symbols match %q\n", symbol)
+		printFindFilters(opts)
+		return nil
+	}
+	printFindList(result)
+	return nil
+}
+
+func printFindList(result find.ListResult) {
 	fmt.Printf("Symbols (%d of %d):\n", len(result.Symbols), result.Total)
 	for _, s := range result.Symbols {
 		label := s.Name
 		if s.Receiver != "" {
 			label = s.Receiver + "." + s.Name
 		}
-		fmt.Printf("- %s %s (%s:%d-%d) pkg=%s\n", s.Kind, label, s.FilePath, s.LineStart, s.LineEnd, s.Package)
+		scope := "pkg=" + s.Package
+		if s.Language != "" {
+			scope = "lang=" + s.Language
+		}
+		fmt.Printf("- %s %s (%s:%d-%d) %s\n", s.Kind, label, s.FilePath, s.LineStart, s.LineEnd, scope)
+		switch {
+		case len(s.Variants) > 0:
+			fmt.Printf("  variants: %s\n", variantsLine(s.Variants))
+		case s.Platform != "":
+			fmt.Printf("  platform: %s\n", s.Platform)
+		}
+		if line := lowLevelLine(s); line != "" {
+			fmt.Printf("  handle with care: %s\n", line)
+		}
+		if line := generatedLine(s); line != "" {
+			fmt.Printf("  generated: %s\n", line)
+		}
+		if len(s.Members) > 0 {
+			values := make([]string, 0, len(s.Members))
+			for _, m := range s.Members {
+				values = append(values, m.Name+" = "+m.Value)
+			}
+			fmt.Printf("  %s\n", strings.Join(values, ", "))
+		}
 	}
 	if result.Total > len(result.Symbols) {
 		fmt.Printf("\nShowing %d of %d. Use --limit %d to see all.\n", len(result.Symbols), result.Total, result.Total)
 	}
-	return nil
+}
+
+// lowLevelLine names what makes sym's file low-level: cgo, unsafe, or both.
+// It is empty for ordinary files.
+func lowLevelLine(sym find.Symbol) string {
+	var parts []string
+	if sym.Cgo {
+		parts = append(parts, `imports "C" (cgo)`)
+	}
+	if sym.Unsafe {
+		parts = append(parts, `imports "unsafe"`)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// generatedLine says where to change sym when its file is generated or
+// vendored rather than hand-written. It is empty for ordinary files.
+func generatedLine(sym find.Symbol) string {
+	switch {
+	case !sym.Generated:
+		return ""
+	case strings.HasPrefix(sym.FilePath, "vendor/"):
+		return "vendored; change it upstream"
+	default:
+		return "do not edit; change the generator's input and regenerate"
+	}
+}
+
+// variantsLine lists platform variants as "constraint (file:line)".
+func variantsLine(variants []find.Variant) string {
+	parts := make([]string, 0, len(variants))
+	for _, v := range variants {
+		parts = append(parts, fmt.Sprintf("%s (%s:%d)", v.Platform, v.FilePath, v.LineStart))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printTypeMembers(result find.Result) {
+	if result.Symbol.Kind != "type" {
+		fmt.Printf("\nFields: (%s %s is not a type)\n", result.Symbol.Kind, result.Symbol.Name)
+		return
+	}
+	fmt.Println("\nFields:")
+	if len(result.Fields) == 0 {
+		fmt.Println("- (none)")
+	}
+	for _, f := range result.Fields {
+		switch {
+		case f.Embedded:
+			fmt.Printf("- %s (embedded)", f.Type)
+		default:
+			fmt.Printf("- %s %s", f.Name, f.Type)
+		}
+		if f.Tag != "" {
+			fmt.Printf(" `%s`", f.Tag)
+		}
+		fmt.Println()
+	}
+	fmt.Println("\nMethods:")
+	if len(result.Methods) == 0 {
+		fmt.Println("- (none)")
+	}
+	for _, m := range result.Methods {
+		sig := strings.TrimPrefix(m.Signature, "func")
+		if m.FilePath == "" {
+			fmt.Printf("- %s%s\n", m.Name, sig)
+			continue
+		}
+		recv := result.Symbol.Name
+		if m.PointerReceiver {
+			recv = "*" + recv
+		}
+		fmt.Printf("- (%s) %s%s (%s:%d)\n", recv, m.Name, sig, m.FilePath, m.LineStart)
+	}
+}
+
+// symbolOwners returns the CODEOWNERS owners of the file declaring sym.
+func symbolOwners(ctx context.Context, conn *sql.DB, sym find.Symbol) ([]string, error) {
+	result, err := owners.NewService(conn).Lookup(ctx, sym.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	return result.Owners, nil
+}
+
+// assetLine describes a //go:embed pattern of the symbol's package and how
+// many files it matched.
+func assetLine(a find.Asset) string {
+	status := fmt.Sprintf("%d files", len(a.Files))
+	if len(a.Files) == 0 {
+		status = "missing, the package will not build"
+	}
+	return fmt.Sprintf("- %s embeds %s (%s:%d): %s", a.Var, a.Pattern, a.FilePath, a.Line, status)
+}
+
+// printUsages prints call sites as file:line, the calling symbol, and the
+// line of source, like an editor's find references.
+func printUsages(usages []find.Usage) {
+	fmt.Printf("\nUsages (%d):\n", len(usages))
+	if len(usages) == 0 {
+		fmt.Println("- (none)")
+	}
+	for _, u := range usages {
+		if u.Line == 0 {
+			fmt.Printf("- %s in %s: call lines not indexed yet, run `recon sync`\n", u.FilePath, u.Caller)
+			continue
+		}
+		fmt.Printf("- %s:%d in %s: %s\n", u.FilePath, u.Line, u.Caller, u.Code)
+	}
 }
 
 func normalizeFindPath(path string) string {
@@ -331,17 +772,87 @@ func normalizeFindPath(path string) string {
 	return filepath.ToSlash(filepath.Clean(trimmed))
 }
 
+// writeFindLookupError reports a failed symbol lookup in the shared find
+// format (suggestions for not-found, candidates for ambiguous) for command.
+// Candidate file paths follow app's path mode.
+func writeFindLookupError(app *App, command, symbol string, err error, queryOptions find.QueryOptions, jsonOut bool) error {
+	switch e := err.(type) {
+	case find.NotFoundError:
+		if jsonOut {
+			details := map[string]any{
+				"symbol":      symbol,
+				"suggestions": e.Suggestions,
+			}
+			if len(e.Suggestions) == 0 {
+				details["tip"] = "try --kind func|type|var|method|const to browse, or --list-packages to see indexed packages"
+			}
+			addFindFilterDetails(details, queryOptions)
+			_ = writeJSONError("not_found", e.Error(), details)
+		} else {
+			if e.Filtered {
+				fmt.Printf("symbol %q not found with provided filters\n", symbol)
+			} else {
+				fmt.Printf("symbol %q not found\n", symbol)
+			}
+			printFindFilters(queryOptions)
+			if len(e.Suggestions) > 0 {
+				fmt.Println("Suggestions:")
+				for _, suggestion := range e.Suggestions {
+					fmt.Printf("- %s\n", suggestion)
+				}
+			} else {
+				fmt.Println("Tip: try --kind func|type|var|method|const to browse, or --list-packages to see indexed packages")
+			}
+		}
+		return ExitError{Code: 2}
+	case find.AmbiguousError:
+		app.applyPathMode(&e.Candidates)
+		if jsonOut {
+			details := map[string]any{
+				"symbol":     symbol,
+				"candidates": e.Candidates,
+			}
+			addFindFilterDetails(details, queryOptions)
+			_ = writeJSONError("ambiguous", e.Error(), details)
+		} else {
+			fmt.Printf("symbol %q is ambiguous (%d candidates)\n", symbol, len(e.Candidates))
+			printFindFilters(queryOptions)
+			for _, candidate := range e.Candidates {
+				label := symbol
+				if candidate.Receiver != "" {
+					label = candidate.Receiver + "." + symbol
+				}
+				fmt.Printf("- %s %s (%s, pkg %s)\n", candidate.Kind, label, candidate.FilePath, candidate.Package)
+			}
+			if len(e.Candidates) > 0 {
+				c := e.Candidates[0]
+				label := symbol
+				if c.Receiver != "" {
+					label = c.Receiver + "." + symbol
+				}
+				fmt.Printf("\nTry: recon %s %s --package %s\n", command, label, c.Package)
+			}
+		}
+		return ExitError{Code: 2}
+	default:
+		if jsonOut {
+			_ = writeJSONError("internal_error", err.Error(), nil)
+			return ExitError{Code: 2}
+		}
+		return err
+	}
+}
+
+// findKinds are the symbol kinds --kind accepts.
+var findKinds = []string{"func", "method", "type", "var", "const", "enum", "table", "view",
+	"proto_service", "proto_rpc", "proto_message", "proto_enum"}
+
 func normalizeFindKind(kind string) (string, error) {
 	normalized := strings.ToLower(strings.TrimSpace(kind))
-	if normalized == "" {
-		return "", nil
-	}
-	switch normalized {
-	case "func", "method", "type", "var", "const":
+	if normalized == "" || slices.Contains(findKinds, normalized) {
 		return normalized, nil
-	default:
-		return "", fmt.Errorf("--kind must be one of: func, method, type, var, const")
 	}
+	return "", fmt.Errorf("--kind must be one of: %s", strings.Join(findKinds, ", "))
 }
 
 func addFindFilterDetails(details map[string]any, opts find.QueryOptions) {
@@ -354,6 +865,18 @@ func addFindFilterDetails(details map[string]any, opts find.QueryOptions) {
 	if opts.Kind != "" {
 		details["kind"] = opts.Kind
 	}
+	if len(opts.Paths) > 0 {
+		details["paths"] = opts.Paths
+	}
+	if len(opts.ExcludePaths) > 0 {
+		details["exclude_paths"] = opts.ExcludePaths
+	}
+	if len(opts.Tags) > 0 {
+		details["tags"] = opts.Tags
+	}
+	if opts.Unsafe {
+		details["unsafe"] = true
+	}
 }
 
 func printFindFilters(opts find.QueryOptions) {
@@ -366,6 +889,18 @@ func printFindFilters(opts find.QueryOptions) {
 	if opts.Kind != "" {
 		fmt.Printf("Filter kind: %s\n", opts.Kind)
 	}
+	if len(opts.Paths) > 0 {
+		fmt.Printf("Filter paths: %s\n", strings.Join(opts.Paths, ", "))
+	}
+	if len(opts.ExcludePaths) > 0 {
+		fmt.Printf("Exclude paths: %s\n", strings.Join(opts.ExcludePaths, ", "))
+	}
+	if len(opts.Tags) > 0 {
+		fmt.Printf("Filter tags: %s\n", strings.Join(opts.Tags, ", "))
+	}
+	if opts.Unsafe {
+		fmt.Println("Filter: cgo or unsafe files only")
+	}
 }
 
 func truncateBody(body string, maxLines int) string {
@@ -379,13 +914,13 @@ func truncateBody(body string, maxLines int) string {
 	return strings.Join(append(lines[:maxLines], "... (truncated)"), "\n")
 }
 
-func enrichFindKnowledge(cmd *cobra.Command, conn *sql.DB, sym find.Symbol) []find.KnowledgeLink {
+func enrichFindKnowledge(ctx context.Context, conn *sql.DB, sym find.Symbol) []find.KnowledgeLink {
 	edgeSvc := edge.NewService(conn)
 	var links []find.KnowledgeLink
 
 	// Edges pointing at this symbol's package
 	if sym.Package != "" {
-		pkgEdges, _ := edgeSvc.ListTo(cmd.Context(), "package", sym.Package)
+		pkgEdges, _ := edgeSvc.ListTo(ctx, "package", sym.Package)
 		for _, e := range pkgEdges {
 			links = append(links, edgeToKnowledgeLink(conn, e))
 		}
@@ -393,70 +928,152 @@ func enrichFindKnowledge(cmd *cobra.Command, conn *sql.DB, sym find.Symbol) []fi
 
 	// Edges pointing at this symbol directly
 	symRef := sym.Package + "." + sym.Name
-	symEdges, _ := edgeSvc.ListTo(cmd.Context(), "symbol", symRef)
+	symEdges, _ := edgeSvc.ListTo(ctx, "symbol", symRef)
 	for _, e := range symEdges {
+		if e.FromType == "symbol" {
+			continue // code links, reported by find.Service.CodeLinks
+		}
 		links = append(links, edgeToKnowledgeLink(conn, e))
 	}
 
 	return links
 }
 
-func enrichPackageHeat(ctx context.Context, moduleRoot string, pkgs []find.PackageSummary) {
-	cmd := execCommandContext(ctx, "git", "-C", moduleRoot, "log", "--since=30 days ago", "--name-only", "--pretty=format:")
-	out, err := cmd.Output()
-	if err != nil {
-		return // Non-fatal: heat is optional
-	}
+// enrichFindCoverage returns the imported coverage of sym and its package, or
+// nil when no profile covering the package has been imported. Package heat is
+// only computed then, so lookups without coverage data skip the git call.
+// codeLinkLabels title the code links of each relation in find's text output.
+var codeLinkLabels = []struct{ relation, label string }{
+	{"generated_as", "Generated Go"},
+	{"implemented_by", "Implemented by"},
+	{"generated_from", "Generated from"},
+	{"implements", "Implements proto"},
+}
 
-	counts := map[string]int{}
-	for _, line := range strings.Split(string(out), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		dir := filepath.ToSlash(filepath.Dir(line))
-		if dir == "." {
-			counts["."]++
-			continue
-		}
-		// Find the most specific (longest) matching package path
-		bestPath := ""
-		for _, p := range pkgs {
-			if p.Path == "." {
+// codeLinkLines renders links as one "Label: ref (file:line), ..." line per
+// relation.
+func codeLinkLines(links []find.CodeLink) []string {
+	var lines []string
+	for _, l := range codeLinkLabels {
+		var refs []string
+		for _, link := range links {
+			if link.Relation != l.relation {
 				continue
 			}
-			if dir == p.Path || strings.HasPrefix(dir, p.Path+"/") {
-				if len(p.Path) > len(bestPath) {
-					bestPath = p.Path
-				}
+			ref := link.Symbol
+			if link.FilePath != "" {
+				ref += fmt.Sprintf(" (%s:%d)", link.FilePath, link.Line)
 			}
+			refs = append(refs, ref)
 		}
-		if bestPath == "" {
-			// Fallback to root package only if no other match
-			for _, p := range pkgs {
-				if p.Path == "." {
-					bestPath = "."
-					break
-				}
-			}
+		if len(refs) > 0 {
+			lines = append(lines, l.label+": "+strings.Join(refs, ", "))
 		}
-		if bestPath != "" {
-			counts[bestPath]++
+	}
+	return lines
+}
+
+func enrichFindCoverage(ctx context.Context, conn *sql.DB, moduleRoot string, sym find.Symbol, heat index.HeatPolicy) *find.CoverageInfo {
+	svc := coverage.NewService(conn)
+	byPackage, err := svc.Packages(ctx)
+	if err != nil {
+		return nil
+	}
+	pkgCoverage, ok := byPackage[sym.Package]
+	if !ok {
+		return nil
+	}
+	info := &find.CoverageInfo{Package: pkgCoverage}
+	if c, ok, err := svc.Symbol(ctx, sym.FilePath, sym.Name, sym.Receiver); err == nil && ok {
+		info.Symbol = &c
+	}
+	if pkgs, err := find.NewService(conn).ListPackages(ctx); err == nil {
+		enrichPackageHeat(ctx, moduleRoot, pkgs, heat)
+		for _, p := range pkgs {
+			if p.Path == sym.Package {
+				info.PackageHeat = p.Heat
+			}
 		}
 	}
+	info.LowHot = info.PackageHeat == "hot" && pkgCoverage.Low()
+	return info
+}
+
+func findCoverageLine(pkg string, c *find.CoverageInfo) string {
+	line := "Coverage: "
+	if c.Symbol != nil {
+		line += fmt.Sprintf("%d/%d statements (%.1f%%), ", c.Symbol.Covered, c.Symbol.Statements, c.Symbol.Percent)
+	}
+	line += fmt.Sprintf("package %s %.1f%%", pkg, c.Package.Percent)
+	if c.LowHot {
+		line += " — hot package with low coverage, add tests before changing it"
+	}
+	return line
+}
+
+// enrichPackageHeat sets the recent commit count and heat of each package.
+// pkgs lists every indexed package, so nested packages resolve correctly.
+func enrichPackageHeat(ctx context.Context, moduleRoot string, pkgs []find.PackageSummary, policy index.HeatPolicy) {
+	ctx, cancel := index.ExecContext(ctx)
+	defer cancel()
+	args := append([]string{"-C", moduleRoot}, policy.LogArgs()...)
+	start := time.Now()
+	out, err := execCommandContext(ctx, "git", args...).Output()
+	logging.Exec(ctx, "git", args, start, err)
+	if err != nil {
+		return // Non-fatal: heat is optional
+	}
 
+	paths := make([]string, len(pkgs))
+	for i, p := range pkgs {
+		paths[i] = p.Path
+	}
+	counts := index.CountPackageCommits(out, paths)
 	for i := range pkgs {
 		c := counts[pkgs[i].Path]
 		pkgs[i].RecentCommits = c
-		switch {
-		case c >= 4:
-			pkgs[i].Heat = "hot"
-		case c >= 1:
-			pkgs[i].Heat = "warm"
-		default:
-			pkgs[i].Heat = "cold"
+		pkgs[i].Heat = policy.Level(c)
+	}
+}
+
+// heatFlags holds the --heat-window, --heat-hot, and --heat-warm flags of
+// orient and find.
+type heatFlags struct {
+	window, hot, warm int
+}
+
+func addHeatFlags(cmd *cobra.Command, f *heatFlags) {
+	cmd.Flags().IntVar(&f.window, "heat-window", 0, "Days of git history that count toward package heat (default heat.window_days, or 30)")
+	cmd.Flags().IntVar(&f.hot, "heat-hot", 0, "Commits in the heat window that make a package hot (default heat.hot, or 4)")
+	cmd.Flags().IntVar(&f.warm, "heat-warm", 0, "Commits in the heat window that make a package warm (default heat.warm, or 1)")
+}
+
+// heatPolicy converts the heat section of .recon/config.json into the policy
+// package heat is classified with.
+func heatPolicy(cfg config.Heat) index.HeatPolicy {
+	return index.HeatPolicy{WindowDays: cfg.WindowDays, Hot: cfg.Hot, Warm: cfg.Warm}.OrDefault()
+}
+
+// policy returns heatPolicy(cfg) with the flags set on cmd applied over it.
+func (f heatFlags) policy(cmd *cobra.Command, cfg config.Heat) (index.HeatPolicy, error) {
+	p := heatPolicy(cfg)
+	for _, flag := range []struct {
+		name  string
+		value int
+		dst   *int
+	}{{"heat-window", f.window, &p.WindowDays}, {"heat-hot", f.hot, &p.Hot}, {"heat-warm", f.warm, &p.Warm}} {
+		if !cmd.Flags().Changed(flag.name) {
+			continue
+		}
+		if flag.value <= 0 {
+			return index.HeatPolicy{}, fmt.Errorf("--%s must be positive", flag.name)
 		}
+		*flag.dst = flag.value
 	}
+	if err := p.Validate(); err != nil {
+		return index.HeatPolicy{}, err
+	}
+	return p, nil
 }
 
 func edgeToKnowledgeLink(conn *sql.DB, e edge.Edge) find.KnowledgeLink {
@@ -475,3 +1092,13 @@ func edgeToKnowledgeLink(conn *sql.DB, e edge.Edge) find.KnowledgeLink {
 	}
 	return link
 }
+
+func packageCSV(pkgs []find.PackageSummary) ([]string, [][]string) {
+	rows := make([][]string, 0, len(pkgs))
+	for _, p := range pkgs {
+		rows = append(rows, []string{
+			p.Path, p.Name, strconv.Itoa(p.FileCount), strconv.Itoa(p.LineCount), p.Heat, strconv.Itoa(p.RecentCommits),
+		})
+	}
+	return []string{"path", "name", "file_count", "line_count", "heat", "recent_commits"}, rows
+}
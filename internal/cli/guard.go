@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/robertguss/recon/internal/guard"
+	"github.com/spf13/cobra"
+)
+
+// guardExitCode is returned when recorded knowledge covers the guarded path.
+// It is distinct from the error codes so scripts can tell "warn" from "failed".
+const guardExitCode = 3
+
+// hookInput is the part of a Claude Code PreToolUse payload guard reads.
+type hookInput struct {
+	ToolInput struct {
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+	} `json:"tool_input"`
+}
+
+type hookOutput struct {
+	HookSpecificOutput hookDecision `json:"hookSpecificOutput"`
+}
+
+type hookDecision struct {
+	HookEventName            string `json:"hookEventName"`
+	PermissionDecision       string `json:"permissionDecision"`
+	PermissionDecisionReason string `json:"permissionDecisionReason"`
+}
+
+func newGuardCommand(app *App) *cobra.Command {
+	var (
+		jsonOut       bool
+		hook          bool
+		minConfidence string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "guard (<path> | --hook)",
+		Short: "Warn about decisions and anti-patterns covering a file before it is edited",
+		Long: "Report the active decisions and patterns linked to a file, its package, or its\n" +
+			"symbols, and the patterns linked to it as anti-patterns (\"contradicts\" edges).\n\n" +
+			fmt.Sprintf("Exits %d when anything is found and 0 otherwise. With --hook, reads a Claude Code\n", guardExitCode) +
+			"PreToolUse payload from stdin and, when the edited file is covered, asks for\n" +
+			"confirmation with the findings as the reason. Hook mode never fails the edit when\n" +
+			"recon is not initialized or the file is outside the module.",
+		Example: "  recon guard internal/db/db.go\n  recon guard internal/db/db.go --min-confidence medium --json\n" +
+			"  recon guard --hook < payload.json",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			minConfidence = strings.ToLower(strings.TrimSpace(minConfidence))
+			if !guard.ValidConfidence(minConfidence) {
+				msg := fmt.Sprintf("invalid --min-confidence %q (expected low, medium, or high)", minConfidence)
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, map[string]any{"min_confidence": minConfidence})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			if hook {
+				return runGuardHook(cmd, app, minConfidence)
+			}
+
+			if len(args) == 0 {
+				msg := "guard requires a file path or --hook"
+				if jsonOut {
+					_ = writeJSONError("missing_argument", msg, map[string]any{"command": "guard"})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			rel, ok := moduleRelPath(app.ModuleRoot, args[0])
+			if !ok {
+				msg := fmt.Sprintf("%s is outside the module", args[0])
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, map[string]any{"path": args[0]})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			result, err := guard.NewService(conn).Check(cmd.Context(), rel, minConfidence)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+
+			result.Path = app.displayPath(result.Path)
+			if jsonOut {
+				if err := writeJSON(result); err != nil {
+					return err
+				}
+			} else if len(result.Findings) == 0 {
+				fmt.Printf("No guarded knowledge for %s\n", result.Path)
+			} else {
+				fmt.Print(guardReport(result))
+			}
+			if len(result.Findings) > 0 {
+				return ExitError{Code: guardExitCode}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().BoolVar(&hook, "hook", false, "Read a Claude Code PreToolUse payload from stdin and answer with a hook decision")
+	cmd.Flags().StringVar(&minConfidence, "min-confidence", "high", "Lowest confidence of decisions and patterns to report (low, medium, high)")
+	return cmd
+}
+
+// runGuardHook answers a PreToolUse hook. Anything that prevents a check
+// (no file in the payload, a file outside the module, no database) lets the
+// edit proceed silently.
+func runGuardHook(cmd *cobra.Command, app *App, minConfidence string) error {
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return fmt.Errorf("read hook payload: %w", err)
+	}
+	var in hookInput
+	if err := json.Unmarshal(data, &in); err != nil {
+		return fmt.Errorf("parse hook payload: %w", err)
+	}
+	target := in.ToolInput.FilePath
+	if target == "" {
+		target = in.ToolInput.NotebookPath
+	}
+	if target == "" {
+		return nil
+	}
+	rel, ok := moduleRelPath(app.ModuleRoot, target)
+	if !ok {
+		return nil
+	}
+
+	conn, err := openExistingDB(app)
+	if err != nil {
+		var notInit dbNotInitializedError
+		if errors.As(err, &notInit) {
+			return nil
+		}
+		return err
+	}
+	defer conn.Close()
+
+	result, err := guard.NewService(conn).Check(cmd.Context(), rel, minConfidence)
+	if err != nil {
+		return err
+	}
+	if len(result.Findings) == 0 {
+		return nil
+	}
+	return writeJSON(hookOutput{HookSpecificOutput: hookDecision{
+		HookEventName:            "PreToolUse",
+		PermissionDecision:       "ask",
+		PermissionDecisionReason: "Recon: " + guardReport(result) + "Confirm the edit respects them.",
+	}})
+}
+
+// moduleRelPath resolves p, absolute or module-relative, to a module-relative
+// slash path. ok is false when p is outside the module.
+func moduleRelPath(moduleRoot, p string) (string, bool) {
+	abs := p
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(moduleRoot, abs)
+	}
+	rel, err := filepath.Rel(moduleRoot, abs)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
+
+func guardReport(r guard.Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s is covered by %d recorded decision(s) or pattern(s):\n", r.Path, len(r.Findings))
+	for _, f := range r.Findings {
+		kind := f.EntityType
+		if f.AntiPattern {
+			kind = "anti-pattern"
+		}
+		fmt.Fprintf(&b, "- %s #%d %s [%s] via %s (%s)", kind, f.ID, f.Title, f.Confidence, f.Via, f.Relation)
+		if f.Drift != "ok" {
+			fmt.Fprintf(&b, " drift=%s", f.Drift)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robertguss/recon/internal/find"
+	"github.com/robertguss/recon/internal/history"
+	"github.com/spf13/cobra"
+)
+
+// historyPayload is the payload of `recon history --json`: the symbol as
+// find resolves it and the git history of its lines.
+type historyPayload struct {
+	Symbol    string `json:"symbol"`
+	Kind      string `json:"kind"`
+	Package   string `json:"package"`
+	FilePath  string `json:"file_path"`
+	LineStart int    `json:"line_start"`
+	LineEnd   int    `json:"line_end"`
+	history.History
+}
+
+var (
+	symbolHistory = history.Log
+	lastTouch     = history.LastTouch
+)
+
+func newHistoryCommand(app *App) *cobra.Command {
+	var (
+		jsonOut       bool
+		packageFilter string
+		fileFilter    string
+		kindFilter    string
+		fuzzy         bool
+		limit         int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history <symbol>",
+		Short: "Show when a symbol was introduced and last changed in git",
+		Long: "Resolve a symbol as find does and follow its lines back through git history with\n" +
+			"`git log -L`: the commit that introduced them, the last commit that changed more than\n" +
+			"whitespace, and every commit between, newest first. Commits that only reformatted the\n" +
+			"symbol are marked cosmetic. Line numbers come from the last sync and are read against\n" +
+			"HEAD, so sync after committing for exact ranges.",
+		Example: "  recon history Service.Build\n  recon history Open --package internal/db --limit 5",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				msg := "history requires a <symbol> argument"
+				if jsonOut {
+					_ = writeJSONError("missing_argument", msg, map[string]any{"command": "history"})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			if limit < 0 {
+				return historyError(jsonOut, "invalid_input", fmt.Errorf("--limit must not be negative"))
+			}
+			kind, err := normalizeFindKind(kindFilter)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"kind": strings.TrimSpace(kindFilter)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			opts := find.QueryOptions{
+				PackagePath: strings.TrimSpace(packageFilter),
+				FilePath:    normalizeFindPath(app.modulePath(fileFilter)),
+				Kind:        kind,
+				NoBody:      true,
+				Fuzzy:       fuzzy,
+			}
+			result, err := find.NewService(conn).Find(cmd.Context(), args[0], opts)
+			if err != nil {
+				return writeFindLookupError(app, "history", args[0], err, opts, jsonOut)
+			}
+
+			sym := result.Symbol
+			name := sym.Name
+			if sym.Receiver != "" {
+				name = sym.Receiver + "." + sym.Name
+			}
+			h, err := symbolHistory(cmd.Context(), app.ModuleRoot, sym.FilePath, sym.LineStart, sym.LineEnd, limit)
+			if err != nil {
+				return historyError(jsonOut, "internal_error", err)
+			}
+			out := historyPayload{
+				Symbol:    name,
+				Kind:      sym.Kind,
+				Package:   sym.Package,
+				FilePath:  sym.FilePath,
+				LineStart: sym.LineStart,
+				LineEnd:   sym.LineEnd,
+				History:   h,
+			}
+			app.applyPathMode(&out)
+			if jsonOut {
+				return writeJSON(out)
+			}
+
+			fmt.Printf("%s %s (%s:%d-%d)\n", out.Kind, out.Symbol, out.FilePath, out.LineStart, out.LineEnd)
+			if out.Uncommitted {
+				fmt.Println("No history: the file is not committed yet")
+				return nil
+			}
+			if out.Total == 0 {
+				fmt.Println("No commits touch these lines")
+				return nil
+			}
+			fmt.Printf("Introduced:   %s\n", commitLine(*out.Introduced))
+			if out.LastChanged != nil {
+				fmt.Printf("Last changed: %s\n", commitLine(*out.LastChanged))
+			}
+			fmt.Printf("\nCommits (%d, newest first):\n", out.Total)
+			for _, c := range out.Commits {
+				note := fmt.Sprintf("+%d -%d", c.LinesAdded, c.LinesDeleted)
+				if c.Cosmetic {
+					note += ", whitespace only"
+				}
+				fmt.Printf("- %s (%s)\n", commitLine(c), note)
+			}
+			if hidden := out.Total - len(out.Commits); hidden > 0 {
+				fmt.Printf("... %d older commits; raise --limit to see them\n", hidden)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().StringVar(&packageFilter, "package", "", "Filter by package path when symbols are ambiguous")
+	cmd.Flags().StringVar(&fileFilter, "file", "", "Filter by file path when symbols are ambiguous")
+	cmd.Flags().StringVar(&kindFilter, "kind", "", "Filter by symbol kind (func, method, type, var, const, enum, table, view, proto_service, proto_rpc, proto_message, proto_enum)")
+	cmd.Flags().BoolVar(&fuzzy, "fuzzy", false, "Resolve a symbol with no exact match to its case-insensitive or single closest fuzzy match")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum commits to list (0 = no limit)")
+	cmd.ValidArgsFunction = completeSymbolArg(app)
+	_ = cmd.RegisterFlagCompletionFunc("package", completePackageFlag(app))
+	_ = cmd.RegisterFlagCompletionFunc("kind", cobra.FixedCompletions(findKinds, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// commitLine formats a commit as short hash, day, author, and subject.
+func commitLine(c history.Commit) string {
+	hash, day := c.Hash, c.Date
+	if len(hash) > 7 {
+		hash = hash[:7]
+	}
+	if len(day) > 10 {
+		day = day[:10]
+	}
+	return fmt.Sprintf("%s %s %s: %s", hash, day, c.Author, c.Subject)
+}
+
+func historyError(jsonOut bool, code string, err error) error {
+	if jsonOut {
+		_ = writeJSONError(code, err.Error(), nil)
+		return ExitError{Code: 2}
+	}
+	return ExitError{Code: 2, Message: err.Error()}
+}
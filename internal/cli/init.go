@@ -5,9 +5,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/doctor"
+	"github.com/robertguss/recon/internal/index"
 	"github.com/robertguss/recon/internal/install"
+	"github.com/robertguss/recon/internal/logging"
+	"github.com/robertguss/recon/internal/snapshot"
 	"github.com/spf13/cobra"
 )
 
@@ -17,18 +22,43 @@ var (
 	installSkill         = install.InstallSkill
 	installSettings      = install.InstallSettings
 	installClaudeSection = install.InstallClaudeSection
+	installAgentRules    = install.InstallAgentRules
+	checkBinary          = doctor.CheckBinary
 )
 
 func newInitCommand(app *App) *cobra.Command {
 	var (
 		jsonOut bool
 		force   bool
+		agents  []string
+		wal     bool
+		profile string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize recon storage in this repository",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if profile != "" {
+				preset, ok := initProfiles[profile]
+				if !ok {
+					return ExitError{Code: 2, Message: fmt.Sprintf("unsupported profile %q; must be one of: ci, agent, full", profile)}
+				}
+				// Profiles provision fresh clones unattended: never prompt,
+				// and let explicit flags override the preset.
+				force = true
+				if !cmd.Flags().Changed("agent") {
+					agents = preset.agents
+				}
+				if !cmd.Flags().Changed("wal") {
+					wal = preset.wal
+				}
+			}
+			agents, err := normalizeAgents(agents)
+			if err != nil {
+				return err
+			}
+
 			goModPath := filepath.Join(app.ModuleRoot, "go.mod")
 			if _, err := os.Stat(goModPath); err != nil {
 				if errors.Is(err, os.ErrNotExist) {
@@ -66,42 +96,182 @@ func newInitCommand(app *App) *cobra.Command {
 			}
 			defer conn.Close()
 
-			if err := runMigrations(conn); err != nil {
+			// Re-running init on an older database migrates it; keep a
+			// copy from before, as `recon migrate up` does.
+			status, err := readMigrationStatus(cmd.Context(), conn)
+			if err != nil {
 				return err
 			}
-			if err := db.EnsureGitIgnore(app.ModuleRoot); err != nil {
+			var backup *snapshot.Snapshot
+			if status.Version >= 0 && !status.Dirty && len(status.Pending) > 0 {
+				if backup, err = backupBeforeMigrate(cmd.Context(), conn, app.ModuleRoot, "pre-migrate"); err != nil {
+					return err
+				}
+			}
+			if err := runMigrations(conn); err != nil {
 				return err
 			}
-
-			// Install Claude Code integration files.
-			if err := installHook(app.ModuleRoot); err != nil {
-				return fmt.Errorf("install hook: %w", err)
+			ignore := []string{index.SyncLockGitIgnore, logging.GitIgnore}
+			if wal {
+				if err := db.EnableWAL(conn); err != nil {
+					return err
+				}
+				ignore = append(ignore, db.WALFiles...)
+			} else if err := db.DisableWAL(conn); err != nil {
+				return err
 			}
-			if err := installSkill(app.ModuleRoot); err != nil {
-				return fmt.Errorf("install skill: %w", err)
+			if err := db.EnsureGitIgnore(app.ModuleRoot, ignore...); err != nil {
+				return err
 			}
-			if err := installSettings(app.ModuleRoot); err != nil {
-				return fmt.Errorf("install settings: %w", err)
+
+			claudeCode := false
+			agentFiles := map[string]string{}
+			for _, agent := range agents {
+				if agent != "claude" {
+					rel, err := installAgentRules(app.ModuleRoot, agent)
+					if err != nil {
+						return fmt.Errorf("install %s rules: %w", agent, err)
+					}
+					agentFiles[agent] = app.displayPath(rel)
+					continue
+				}
+
+				// Install Claude Code integration files.
+				if err := installHook(app.ModuleRoot); err != nil {
+					return fmt.Errorf("install hook: %w", err)
+				}
+				if err := installSkill(app.ModuleRoot); err != nil {
+					return fmt.Errorf("install skill: %w", err)
+				}
+				if err := installSettings(app.ModuleRoot); err != nil {
+					return fmt.Errorf("install settings: %w", err)
+				}
+				if err := installClaudeSection(app.ModuleRoot); err != nil {
+					return fmt.Errorf("install claude section: %w", err)
+				}
+				claudeCode = true
 			}
-			if err := installClaudeSection(app.ModuleRoot); err != nil {
-				return fmt.Errorf("install claude section: %w", err)
+
+			// The hook runs whichever recon is first on PATH, which may not be
+			// the binary running init.
+			var warnings []doctor.Check
+			if claudeCode {
+				check := checkBinary(cmd.Context(), doctor.Options{ModuleRoot: app.ModuleRoot, Version: Version, Commit: Commit})
+				if check.Status != doctor.StatusOK {
+					warnings = append(warnings, check)
+				}
 			}
 
 			if jsonOut {
-				return writeJSON(map[string]any{
+				payload := map[string]any{
 					"ok":          true,
 					"module_root": app.ModuleRoot,
-					"db_path":     path,
-					"claude_code": true,
-				})
+					"db_path":     app.displayPath(path),
+					"claude_code": claudeCode,
+					"agents":      agents,
+					"agent_files": agentFiles,
+					"wal":         wal,
+				}
+				if profile != "" {
+					payload["profile"] = profile
+				}
+				if backup != nil {
+					payload["backup"] = backup
+				}
+				if len(warnings) > 0 {
+					payload["warnings"] = warnings
+				}
+				return writeJSON(payload)
 			}
 
-			fmt.Printf("Initialized recon at %s\nClaude Code integration installed (.claude/hooks, skills, settings)\n", path)
+			if profile != "" {
+				fmt.Printf("Initialized recon at %s (profile %s)\n", app.displayPath(path), profile)
+			} else {
+				fmt.Printf("Initialized recon at %s\n", app.displayPath(path))
+			}
+			printMigrateBackup(backup)
+			if wal {
+				fmt.Println("Write-ahead logging enabled")
+			}
+			if len(agents) == 0 {
+				fmt.Println("No agent integrations installed")
+			}
+			for _, agent := range agents {
+				if agent == "claude" {
+					fmt.Println("Claude Code integration installed (.claude/hooks, skills, settings)")
+					continue
+				}
+				fmt.Printf("%s integration installed (%s)\n", agentDisplayNames[agent], agentFiles[agent])
+			}
+			for _, w := range warnings {
+				fmt.Printf("Warning: %s\n", w.Message)
+				if w.Hint != "" {
+					fmt.Printf("  %s\n", w.Hint)
+				}
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
 	cmd.Flags().BoolVar(&force, "force", false, "Force reinstall without prompting")
+	cmd.Flags().StringSliceVar(&agents, "agent", []string{"claude"}, "Agent integrations to install: "+strings.Join(install.Agents, ", ")+", or none (repeatable or comma-separated)")
+	cmd.Flags().BoolVar(&wal, "wal", true, "Put the database in write-ahead logging mode so reads don't wait on a sync; --wal=false uses a rollback journal")
+	cmd.Flags().StringVar(&profile, "profile", "", "Non-interactive preset: ci (no agent files, WAL), agent (Claude Code, WAL), full (every agent, WAL); implies --force")
 	return cmd
 }
+
+type initProfile struct {
+	agents []string
+	wal    bool
+}
+
+// initProfiles are the presets of `recon init --profile`, so automation can
+// provision a fresh clone with one deterministic command.
+var initProfiles = map[string]initProfile{
+	"ci":    {agents: []string{"none"}, wal: true},
+	"agent": {agents: []string{"claude"}, wal: true},
+	"full":  {agents: install.Agents, wal: true},
+}
+
+var agentDisplayNames = map[string]string{
+	"claude":   "Claude Code",
+	"copilot":  "GitHub Copilot",
+	"cursor":   "Cursor",
+	"gemini":   "Gemini CLI",
+	"windsurf": "Windsurf",
+}
+
+// normalizeAgents lower-cases and de-duplicates --agent values, preserving
+// order, and rejects unknown agents before init touches the filesystem. A
+// lone "none" installs no agent integrations.
+func normalizeAgents(raw []string) ([]string, error) {
+	seen := make(map[string]bool, len(raw))
+	agents := make([]string, 0, len(raw))
+	none := false
+	for _, a := range raw {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" || seen[a] {
+			continue
+		}
+		if a == "none" {
+			none = true
+			continue
+		}
+		if !install.IsSupportedAgent(a) {
+			return nil, fmt.Errorf("unsupported agent %q; must be one of: %s", a, strings.Join(install.Agents, ", "))
+		}
+		seen[a] = true
+		agents = append(agents, a)
+	}
+	if none {
+		if len(agents) > 0 {
+			return nil, fmt.Errorf("--agent none cannot be combined with other agents")
+		}
+		return agents, nil
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("--agent requires at least one of: %s, or none", strings.Join(install.Agents, ", "))
+	}
+	return agents, nil
+}
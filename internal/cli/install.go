@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/index"
+	"github.com/robertguss/recon/internal/install"
+	"github.com/spf13/cobra"
+)
+
+func newInstallCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install integrations that keep the recon index current",
+		Long: "Install integrations outside the agent setup of `recon init`. `recon install git-hooks`\n" +
+			"keeps the index current from git itself, for contributors who work without an agent.",
+		Args: cobra.NoArgs,
+	}
+	cmd.AddCommand(newInstallGitHooksCommand(app))
+	return cmd
+}
+
+func newInstallGitHooksCommand(app *App) *cobra.Command {
+	var (
+		jsonOut bool
+		hooks   []string
+		force   bool
+		remove  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "git-hooks",
+		Short: "Install git hooks that sync the index and report drift",
+		Long: "Install a pre-commit hook that re-indexes the staged files (recon sync --staged) and a\n" +
+			"post-merge hook that syncs after a merge or pull, both with --quiet so they print only\n" +
+			"evidence whose drift status changed. The hooks never fail the git command and do\n" +
+			"nothing where recon is not on PATH or the module is not initialized.\n\n" +
+			"Hooks go where git runs them from, honouring core.hooksPath. A hook installed by\n" +
+			"another tool is left alone unless --force is set, which moves it to <hook>.pre-recon.\n" +
+			"Re-running updates recon's hooks in place; --remove deletes them.",
+		Example: "  recon install git-hooks\n  recon install git-hooks --hooks pre-commit\n  recon install git-hooks --remove",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fail := func(code, msg string, details map[string]any) error {
+				if jsonOut {
+					_ = writeJSONError(code, msg, details)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			var selected []string
+			for _, hook := range hooks {
+				hook = strings.TrimSpace(hook)
+				if !install.IsGitHook(hook) {
+					return fail("invalid_input", fmt.Sprintf("unsupported hook %q; must be one of: %s", hook, strings.Join(install.GitHooks, ", ")),
+						map[string]any{"hook": hook})
+				}
+				if !slices.Contains(selected, hook) {
+					selected = append(selected, hook)
+				}
+			}
+
+			dir, prefix, err := index.GitHooksDir(cmd.Context(), app.ModuleRoot)
+			if err != nil {
+				return fail("invalid_input", err.Error(), map[string]any{"path": app.ModuleRoot})
+			}
+
+			var results []install.GitHookResult
+			if remove {
+				results, err = install.RemoveGitHooks(dir, selected)
+			} else {
+				results, err = install.InstallGitHooks(dir, prefix, selected, force)
+			}
+			if err != nil {
+				if errors.Is(err, install.ErrForeignGitHook) {
+					return fail("invalid_input", err.Error()+"; use --force to replace it", map[string]any{"hooks_dir": dir})
+				}
+				return fail("internal_error", err.Error(), nil)
+			}
+
+			if jsonOut {
+				return writeJSON(results)
+			}
+			for _, r := range results {
+				line := fmt.Sprintf("%s %s (%s)", r.Action, r.Hook, r.Path)
+				if r.Backup != "" {
+					line += "; previous hook moved to " + r.Backup
+				}
+				fmt.Println(line)
+			}
+			if !remove {
+				if _, err := os.Stat(db.DBPath(app.ModuleRoot)); err != nil {
+					fmt.Println("Note: recon is not initialized here; the hooks do nothing until `recon init` and `recon sync` run.")
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().StringSliceVar(&hooks, "hooks", install.GitHooks, "Hooks to install or remove (repeatable or comma-separated)")
+	cmd.Flags().BoolVar(&force, "force", false, "Replace hooks installed by other tools, keeping them as <hook>.pre-recon")
+	cmd.Flags().BoolVar(&remove, "remove", false, "Remove recon's hooks instead of installing them")
+	return cmd
+}
@@ -1,6 +1,10 @@
 package cli
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/robertguss/recon/internal/index"
+)
 
 func exitJSONCommandError(err error) error {
 	code, details := classifyJSONCommandError(err)
@@ -13,5 +17,13 @@ func classifyJSONCommandError(err error) (string, any) {
 	if errors.As(err, &notInitialized) {
 		return "not_initialized", map[string]any{"path": notInitialized.Path}
 	}
+	var outOfDate schemaOutOfDateError
+	if errors.As(err, &outOfDate) {
+		return "schema_out_of_date", map[string]any{"path": outOfDate.Path, "version": outOfDate.Version, "expected": outOfDate.Expected}
+	}
+	var running index.SyncRunningError
+	if errors.As(err, &running) {
+		return "sync_running", running.Holder
+	}
 	return "internal_error", nil
 }
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robertguss/recon/internal/archlint"
+	"github.com/robertguss/recon/internal/config"
+	"github.com/robertguss/recon/internal/sarif"
+	"github.com/spf13/cobra"
+)
+
+// lintArchExitCode is returned when lint-arch finds an import cycle or a
+// rule violation, so CI can fail the build without parsing output.
+const lintArchExitCode = 4
+
+func newLintArchCommand(app *App) *cobra.Command {
+	var (
+		jsonOut      bool
+		format       string
+		includeTests bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "lint-arch",
+		Short: "Report import cycles and layering violations between packages",
+		Long: "Analyze the indexed imports between module packages for cycles and for\n" +
+			"imports that break the declared architecture. Rules come from the\n" +
+			"architecture section of .recon/config.json (ordered layers and forbidden\n" +
+			"imports) and from active constraints recorded with --check-type import_absent.\n" +
+			"Each offending import is reported with the files that make it.\n\n" +
+			fmt.Sprintf("Exits %d when anything is found and 0 otherwise. Imports from _test.go files\n", lintArchExitCode) +
+			"are skipped unless --include-tests is set. Run recon sync first so the index\n" +
+			"is current.\n\n" +
+			"--format sarif prints a SARIF 2.1.0 log, one result per offending file, for\n" +
+			"upload to GitHub code scanning.",
+		Example: "  recon lint-arch\n  recon lint-arch --json\n  recon lint-arch --format sarif > lint-arch.sarif",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFormat, err := parseFormat(format, "text", "sarif")
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"format": strings.TrimSpace(format)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			cfg, err := loadConfig(app.ModuleRoot)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"path": config.Path(app.ModuleRoot)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			result, err := archlint.NewService(conn).Check(cmd.Context(), archOptions(cfg, includeTests))
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+
+			switch {
+			case jsonOut:
+				app.applyPathMode(&result)
+				if err := writeJSON(result); err != nil {
+					return err
+				}
+			case outFormat == "sarif":
+				// SARIF locations stay module-relative whatever the path mode.
+				if err := writeJSON(lintArchSARIF(result)); err != nil {
+					return err
+				}
+			default:
+				app.applyPathMode(&result)
+				fmt.Print(lintArchReport(result))
+			}
+			if !result.OK {
+				return ExitError{Code: lintArchExitCode}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text or sarif")
+	cmd.Flags().BoolVar(&includeTests, "include-tests", false, "Include imports from _test.go files")
+	return cmd
+}
+
+// archOptions turns the architecture section of the config into lint rules.
+func archOptions(cfg config.Config, includeTests bool) archlint.Options {
+	opts := archlint.Options{IncludeTests: includeTests}
+	for _, layer := range cfg.Architecture.Layers {
+		opts.Layers = append(opts.Layers, archlint.Layer{Name: layer.Name, Packages: layer.Packages})
+	}
+	for _, rule := range cfg.Architecture.ForbiddenImports {
+		opts.Rules = append(opts.Rules, archlint.Rule{From: rule.From, To: rule.To, Reason: rule.Reason, Source: "config"})
+	}
+	return opts
+}
+
+func lintArchReport(result archlint.Result) string {
+	var b strings.Builder
+	if result.OK {
+		fmt.Fprintf(&b, "No import cycles or layering violations (%d packages, %d rules).\n", result.Packages, len(result.Rules))
+		return b.String()
+	}
+	if len(result.Cycles) > 0 {
+		fmt.Fprintf(&b, "Import cycles (%d):\n", len(result.Cycles))
+		for _, c := range result.Cycles {
+			fmt.Fprintf(&b, "- %s -> %s\n", strings.Join(c.Packages, " -> "), c.Packages[0])
+			for _, e := range c.Edges {
+				fmt.Fprintf(&b, "    %s -> %s: %s\n", e.From, e.To, strings.Join(e.Files, ", "))
+			}
+		}
+	}
+	if len(result.Violations) > 0 {
+		fmt.Fprintf(&b, "Layering violations (%d):\n", len(result.Violations))
+		for _, v := range result.Violations {
+			source := v.Rule.Source
+			if v.Rule.ConstraintID > 0 {
+				source = fmt.Sprintf("constraint #%d", v.Rule.ConstraintID)
+			}
+			fmt.Fprintf(&b, "- %s -> %s [%s]", v.From, v.To, source)
+			if v.Rule.Reason != "" {
+				fmt.Fprintf(&b, " %s", v.Rule.Reason)
+			}
+			fmt.Fprintf(&b, "\n    %s\n", strings.Join(v.Files, ", "))
+		}
+	}
+	return b.String()
+}
+
+// lintArchSARIF reports each file that makes an offending import as a SARIF
+// result. The import line is not indexed, so results point at whole files.
+func lintArchSARIF(result archlint.Result) sarif.Log {
+	rules := []sarif.Rule{
+		sarif.NewRule("import_cycle", "Packages import each other in a cycle", sarif.LevelError),
+		sarif.NewRule("layer_violation", "A package imports a higher architecture layer", sarif.LevelError),
+		sarif.NewRule("forbidden_import", "A package makes an import forbidden by .recon/config.json", sarif.LevelError),
+		sarif.NewRule("constraint_violation", "A package makes an import an active constraint forbids", sarif.LevelError),
+	}
+	results := []sarif.Result{}
+	add := func(ruleID, msg string, files []string) {
+		for _, f := range files {
+			results = append(results, sarif.Result{
+				RuleID:    ruleID,
+				Level:     sarif.LevelError,
+				Message:   sarif.Message{Text: msg},
+				Locations: []sarif.Location{sarif.FileLocation(f, 0, 0)},
+			})
+		}
+	}
+	for _, c := range result.Cycles {
+		loop := strings.Join(c.Packages, " -> ") + " -> " + c.Packages[0]
+		for _, e := range c.Edges {
+			add("import_cycle", fmt.Sprintf("%s imports %s, closing the import cycle %s", e.From, e.To, loop), e.Files)
+		}
+	}
+	for _, v := range result.Violations {
+		ruleID, source := "forbidden_import", "a forbidden import rule"
+		switch v.Rule.Source {
+		case "layer":
+			ruleID, source = "layer_violation", "the architecture layers"
+		case "constraint":
+			ruleID, source = "constraint_violation", fmt.Sprintf("constraint #%d", v.Rule.ConstraintID)
+		}
+		msg := fmt.Sprintf("%s imports %s, breaking %s", v.From, v.To, source)
+		if v.Rule.Reason != "" {
+			msg += ": " + v.Rule.Reason
+		}
+		add(ruleID, msg, v.Files)
+	}
+	return sarif.NewLog(Version, rules, results)
+}
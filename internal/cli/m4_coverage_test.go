@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/robertguss/recon/internal/find"
+	"github.com/robertguss/recon/internal/index"
 )
 
 // ---------------------------------------------------------------------------
@@ -848,6 +849,40 @@ func TestM4RecallMissingArgText(t *testing.T) {
 	}
 }
 
+func TestM4RecallTimeWindow(t *testing.T) {
+	_, app := m4Setup(t)
+	if _, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+		"Window decision",
+		"--reasoning", "recorded today",
+		"--evidence-summary", "go.mod exists",
+		"--check-type", "file_exists",
+		"--check-path", "go.mod",
+	}); err != nil {
+		t.Fatalf("create decision: %v", err)
+	}
+
+	out, _, err := runCommandWithCapture(t, newRecallCommand(app), []string{"--since", "1d"})
+	if err != nil || !strings.Contains(out, "Window decision") {
+		t.Fatalf("recall --since without query out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newRecallCommand(app), []string{"Window", "--before", "1d"})
+	if err != nil || !strings.Contains(out, "No promoted knowledge found.") {
+		t.Fatalf("recall --before out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newRecallCommand(app), []string{"Window", "--updated-since", "2000-01-01", "--updated-before", "2w", "--json"})
+	if err != nil || !strings.Contains(out, `"items": []`) {
+		t.Fatalf("recall updated window out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newRecallCommand(app), []string{"Window", "--before", "soon", "--json"})
+	if err == nil || !strings.Contains(out, `"invalid_input"`) || !strings.Contains(out, "invalid --before") {
+		t.Fatalf("expected invalid_input for --before, out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newRecallCommand(app), []string{"Window", "--since", "0d"}); err == nil || !strings.Contains(err.Error(), "must be positive") {
+		t.Fatalf("expected text error for --since 0d, got %v", err)
+	}
+}
+
 func TestM4RecallNoDBText(t *testing.T) {
 	_, app := m4SetupNoInit(t)
 	_, _, err := runCommandWithCapture(t, newRecallCommand(app), []string{"test"})
@@ -944,7 +979,7 @@ func TestM4StatusTextLoadSyncStateError(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 func TestM4BuildCheckSpecEmptyTypeWithTypedFlags(t *testing.T) {
-	_, err := buildCheckSpec("", "", "go.mod", "", "", "")
+	_, err := buildCheckSpec("", "", "go.mod", "", "", "", 0)
 	if err == nil || !strings.Contains(err.Error(), "unsupported check type") {
 		t.Fatalf("expected unsupported check type error for empty type, got %v", err)
 	}
@@ -1358,19 +1393,20 @@ func TestM4EnrichPackageHeat(t *testing.T) {
 	origExec := execCommandContext
 	defer func() { execCommandContext = origExec }()
 
-	// Mock git output: 5 changes in internal/cli, 1 in root
+	// Mock git output: 4 commits in internal/cli, one changing two of its
+	// files and one also changing root.
 	execCommandContext = func(ctx context.Context, name string, args ...string) *exec.Cmd {
-		return exec.CommandContext(ctx, "echo", "internal/cli/find.go\ninternal/cli/root.go\ninternal/cli/init.go\ninternal/cli/sync.go\ninternal/cli/decide.go\nmain.go")
+		return exec.CommandContext(ctx, "printf", `\000\ninternal/cli/find.go\n\000\ninternal/cli/root.go\n\000\ninternal/cli/init.go\ninternal/cli/sync.go\n\000\ninternal/cli/decide.go\nmain.go\n`)
 	}
 
 	pkgs := []find.PackageSummary{
 		{Path: "internal/cli", Name: "cli"},
 		{Path: ".", Name: "main"},
 	}
-	enrichPackageHeat(context.Background(), "/tmp/fake", pkgs)
+	enrichPackageHeat(context.Background(), "/tmp/fake", pkgs, index.DefaultHeatPolicy)
 
-	if pkgs[0].Heat != "hot" {
-		t.Fatalf("expected internal/cli heat=hot (5 commits), got %s", pkgs[0].Heat)
+	if pkgs[0].Heat != "hot" || pkgs[0].RecentCommits != 4 {
+		t.Fatalf("expected internal/cli heat=hot (4 commits), got %s (%d)", pkgs[0].Heat, pkgs[0].RecentCommits)
 	}
 	if pkgs[1].Heat != "warm" {
 		t.Fatalf("expected root heat=warm (1 commit), got %s", pkgs[1].Heat)
@@ -1388,7 +1424,7 @@ func TestM4EnrichPackageHeatGitError(t *testing.T) {
 
 	pkgs := []find.PackageSummary{{Path: ".", Name: "main"}}
 	// Should not panic, just return without setting heat
-	enrichPackageHeat(context.Background(), "/tmp/fake", pkgs)
+	enrichPackageHeat(context.Background(), "/tmp/fake", pkgs, index.DefaultHeatPolicy)
 	if pkgs[0].Heat != "" {
 		t.Fatalf("expected empty heat on git error, got %s", pkgs[0].Heat)
 	}
@@ -1400,14 +1436,14 @@ func TestM4EnrichPackageHeatFallbackToRoot(t *testing.T) {
 
 	// File in unknown dir — should fall back to root package
 	execCommandContext = func(ctx context.Context, name string, args ...string) *exec.Cmd {
-		return exec.CommandContext(ctx, "echo", "unknown/dir/file.go")
+		return exec.CommandContext(ctx, "printf", `\000alice\nunknown/dir/file.go\n`)
 	}
 
 	pkgs := []find.PackageSummary{
 		{Path: "internal/cli", Name: "cli"},
 		{Path: ".", Name: "main"},
 	}
-	enrichPackageHeat(context.Background(), "/tmp/fake", pkgs)
+	enrichPackageHeat(context.Background(), "/tmp/fake", pkgs, index.DefaultHeatPolicy)
 
 	if pkgs[1].RecentCommits != 1 {
 		t.Fatalf("expected root package to get fallback commit, got %d", pkgs[1].RecentCommits)
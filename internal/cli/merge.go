@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/merge"
+	"github.com/spf13/cobra"
+)
+
+// mergeExportPayload is the payload of `recon merge --export --json`.
+type mergeExportPayload struct {
+	Path      string `json:"path"`
+	Decisions int    `json:"decisions"`
+	Patterns  int    `json:"patterns"`
+	Edges     int    `json:"edges"`
+}
+
+func newMergeCommand(app *App) *cobra.Command {
+	var (
+		jsonOut bool
+		ours    bool
+		theirs  bool
+		dryRun  bool
+		export  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "merge (<other.db|bundle.json> | --export <bundle.json>)",
+		Short: "Merge decisions, patterns, and edges from another recon database or bundle",
+		Long: "Combine knowledge recorded in another clone into this one. The source is a teammate's\n" +
+			".recon/recon.db or a bundle they wrote with --export. Decisions and patterns are matched\n" +
+			"by title: new ones are added with their evidence, and edges follow once both ends exist.\n" +
+			"A match whose reasoning or description diverged is a conflict; choose a side for each\n" +
+			"one at the prompt, or settle all of them with --ours or --theirs.",
+		Example: "  recon merge --export team.json\n" +
+			"  recon merge team.json --dry-run\n" +
+			"  recon merge ../other-clone/.recon/recon.db --theirs",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var msg string
+			switch {
+			case export != "" && len(args) > 0:
+				msg = "use either a source to merge or --export, not both"
+			case export == "" && len(args) == 0:
+				msg = "merge requires a <other.db|bundle.json> argument, or --export <path>"
+			case ours && theirs:
+				msg = "use only one of --ours and --theirs"
+			case export != "" && (ours || theirs || dryRun):
+				msg = "--ours, --theirs, and --dry-run apply to merging, not --export"
+			}
+			if msg != "" {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, nil)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+			svc := merge.NewService(conn)
+
+			if export != "" {
+				bundle, err := svc.Export(cmd.Context())
+				if err == nil {
+					err = merge.WriteBundle(export, bundle)
+				}
+				if err != nil {
+					return mergeError(jsonOut, "internal_error", err)
+				}
+				if jsonOut {
+					return writeJSON(mergeExportPayload{Path: export, Decisions: len(bundle.Decisions), Patterns: len(bundle.Patterns), Edges: len(bundle.Edges)})
+				}
+				fmt.Printf("Exported %d decisions, %d patterns, and %d edges to %s\n", len(bundle.Decisions), len(bundle.Patterns), len(bundle.Edges), export)
+				return nil
+			}
+
+			source := args[0]
+			if sameFile(source, db.DBPath(app.ModuleRoot)) {
+				return mergeError(jsonOut, "invalid_input", errors.New("cannot merge the database into itself"))
+			}
+			bundle, err := merge.Load(cmd.Context(), source)
+			if err != nil {
+				code := "internal_error"
+				if errors.Is(err, os.ErrNotExist) {
+					code = "not_found"
+				}
+				return mergeError(jsonOut, code, err)
+			}
+
+			opts := merge.Options{DryRun: dryRun}
+			switch {
+			case ours:
+				opts.Resolve = func(merge.Conflict) (merge.Resolution, error) { return merge.Ours, nil }
+			case theirs:
+				opts.Resolve = func(merge.Conflict) (merge.Resolution, error) { return merge.Theirs, nil }
+			case !app.NoPrompt && !jsonOut:
+				opts.Resolve = promptConflict
+			}
+			result, err := svc.Merge(cmd.Context(), bundle, opts)
+			if err != nil {
+				return mergeError(jsonOut, "internal_error", err)
+			}
+
+			if jsonOut {
+				return writeJSON(result)
+			}
+			fmt.Printf("Merged %s: %d added, %d unchanged, %d conflicts, %d edges added\n",
+				source, len(result.Added), result.Unchanged, len(result.Conflicts), result.EdgesAdded)
+			for _, a := range result.Added {
+				fmt.Printf("+ %s #%d %s\n", a.EntityType, a.ID, a.Title)
+			}
+			unresolved := 0
+			for _, c := range result.Conflicts {
+				fmt.Printf("! %s #%d %s: %s\n", c.EntityType, c.ID, c.Title, c.Resolution)
+				if c.Resolution == merge.Unresolved {
+					unresolved++
+				}
+			}
+			if result.DryRun {
+				fmt.Println("Dry run: nothing was written.")
+			}
+			if unresolved > 0 {
+				fmt.Printf("%d conflicts kept the local text; rerun with --ours or --theirs to settle them.\n", unresolved)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().BoolVar(&ours, "ours", false, "Keep the local text of every conflict")
+	cmd.Flags().BoolVar(&theirs, "theirs", false, "Take the merged text and confidence of every conflict")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report what would be merged without writing")
+	cmd.Flags().StringVar(&export, "export", "", "Write this database's active knowledge to a bundle file")
+	return cmd
+}
+
+// promptConflict shows both sides of a conflict and asks which to keep.
+func promptConflict(c merge.Conflict) (merge.Resolution, error) {
+	fmt.Printf("\nConflict: %s #%d %s\n", c.EntityType, c.ID, c.Title)
+	fmt.Printf("  ours:   %s\n", indentLines(c.Ours))
+	fmt.Printf("  theirs: %s\n", indentLines(c.Theirs))
+	fmt.Print("Keep [o]urs, take [t]heirs, or [s]kip? ")
+	var answer string
+	fmt.Scan(&answer)
+	switch strings.ToLower(answer) {
+	case "t", "theirs":
+		return merge.Theirs, nil
+	case "o", "ours":
+		return merge.Ours, nil
+	}
+	return merge.Unresolved, nil
+}
+
+// indentLines aligns the continuation lines of s under the first one in
+// promptConflict's output.
+func indentLines(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n          ")
+}
+
+// sameFile reports whether a and b name the same existing file.
+func sameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi) || filepath.Clean(a) == filepath.Clean(b)
+}
+
+func mergeError(jsonOut bool, code string, err error) error {
+	if jsonOut {
+		_ = writeJSONError(code, err.Error(), nil)
+		return ExitError{Code: 2}
+	}
+	return ExitError{Code: 2, Message: err.Error()}
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metric is one series in the Prometheus text exposition format.
+type metric struct {
+	name, help, kind string
+	samples          []metricSample
+}
+
+type metricSample struct {
+	labels string
+	value  float64
+}
+
+var (
+	metricConfidences   = []string{"low", "medium", "high"}
+	metricEntityTypes   = []string{"decision", "pattern", "constraint"}
+	metricDriftStatuses = []string{"ok", "drifting", "broken"}
+)
+
+// loadMetrics gathers the index health gauges served at /metrics. now is the
+// scrape time, against which staleness is measured.
+func loadMetrics(ctx context.Context, conn *sql.DB, dbPath string, now time.Time) ([]metric, error) {
+	status, err := loadStatus(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	gauge := func(name, help string, v float64) metric {
+		return metric{name: name, help: help, kind: "gauge", samples: []metricSample{{value: v}}}
+	}
+	metrics := []metric{
+		gauge("recon_index_files", "Files in the index, in every indexed language.", float64(status.Counts.Files)),
+		gauge("recon_index_symbols", "Symbols in the index.", float64(status.Counts.Symbols)),
+		gauge("recon_index_packages", "Packages in the index.", float64(status.Counts.Packages)),
+		gauge("recon_evidence_due", "Evidence rows due for a recon verify --due re-check.", float64(status.Evidence.Due)),
+	}
+
+	size := 0.0
+	if info, err := os.Stat(dbPath); err == nil {
+		size = float64(info.Size())
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("stat db file: %w", err)
+	}
+	metrics = append(metrics, gauge("recon_index_size_bytes", "Size of .recon/recon.db on disk.", size))
+
+	var syncs int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM sync_history").Scan(&syncs); err != nil {
+		return nil, fmt.Errorf("count sync runs: %w", err)
+	}
+	var durationMS int64
+	err = conn.QueryRowContext(ctx, "SELECT duration_ms FROM sync_history ORDER BY synced_at DESC, id DESC LIMIT 1").Scan(&durationMS)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("load last sync duration: %w", err)
+	}
+	metrics = append(metrics,
+		metric{name: "recon_syncs_total", help: "Successful syncs recorded in the index.", kind: "counter",
+			samples: []metricSample{{value: float64(syncs)}}},
+		gauge("recon_last_sync_duration_seconds", "Wall time of the most recent sync.", float64(durationMS)/1000),
+	)
+
+	// Without a sync there is nothing to be stale against, so both gauges
+	// are left out rather than reported as zero.
+	if status.LastSyncAt != "" {
+		lastSync, err := time.Parse(time.RFC3339, status.LastSyncAt)
+		if err != nil {
+			return nil, fmt.Errorf("parse last sync: %w", err)
+		}
+		metrics = append(metrics,
+			gauge("recon_last_sync_timestamp_seconds", "Unix time of the most recent sync.", float64(lastSync.Unix())),
+			gauge("recon_index_staleness_seconds", "Seconds since the most recent sync.", max(now.Sub(lastSync).Seconds(), 0)),
+		)
+	}
+
+	for _, table := range []struct{ name, noun string }{
+		{"decisions", "Active decisions"},
+		{"patterns", "Active patterns"},
+		{"constraints", "Active constraints"},
+	} {
+		counts, err := countBy(ctx, conn, "SELECT confidence, COUNT(*) FROM "+table.name+" WHERE status = 'active' GROUP BY confidence")
+		if err != nil {
+			return nil, fmt.Errorf("count %s: %w", table.name, err)
+		}
+		m := metric{name: "recon_" + table.name, help: table.noun + " by confidence.", kind: "gauge"}
+		for _, c := range metricConfidences {
+			m.samples = append(m.samples, metricSample{labels: fmt.Sprintf(`confidence=%q`, c), value: float64(counts[c])})
+		}
+		metrics = append(metrics, m)
+	}
+
+	drift, err := countBy(ctx, conn, "SELECT entity_type || '/' || COALESCE(drift_status, 'ok'), COUNT(*) FROM evidence GROUP BY 1")
+	if err != nil {
+		return nil, fmt.Errorf("count evidence drift: %w", err)
+	}
+	m := metric{name: "recon_evidence", help: "Evidence rows by entity type and drift status.", kind: "gauge"}
+	for _, entity := range metricEntityTypes {
+		for _, status := range metricDriftStatuses {
+			m.samples = append(m.samples, metricSample{
+				labels: fmt.Sprintf(`entity_type=%q,drift_status=%q`, entity, status),
+				value:  float64(drift[entity+"/"+status]),
+			})
+		}
+	}
+	return append(metrics, m), nil
+}
+
+func countBy(ctx context.Context, conn *sql.DB, query string) (map[string]int, error) {
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := map[string]int{}
+	for rows.Next() {
+		var (
+			key string
+			n   int
+		)
+		if err := rows.Scan(&key, &n); err != nil {
+			return nil, err
+		}
+		counts[key] = n
+	}
+	return counts, rows.Err()
+}
+
+func writeMetrics(w io.Writer, metrics []metric) error {
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for _, s := range m.samples {
+			if s.labels != "" {
+				fmt.Fprintf(&b, "%s{%s} %s\n", m.name, s.labels, strconv.FormatFloat(s.value, 'f', -1, 64))
+			} else {
+				fmt.Fprintf(&b, "%s %s\n", m.name, strconv.FormatFloat(s.value, 'f', -1, 64))
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,272 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var (
+	readMigrationStatus = db.ReadMigrationStatus
+	rollbackMigrations  = db.RollbackMigrations
+)
+
+// migratePayload is the payload of `recon migrate up --json` and
+// `recon migrate down --json`. Migrations are the ones applied, or rolled
+// back newest first. Backup is the snapshot taken before the schema
+// changed; nothing is taken when there was nothing to do.
+type migratePayload struct {
+	From       int                `json:"from"`
+	To         int                `json:"to"`
+	Migrations []db.Migration     `json:"migrations"`
+	Backup     *snapshot.Snapshot `json:"backup,omitempty"`
+}
+
+func newMigrateCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Show and change the schema version of the recon database",
+		Long: "Show which schema migrations the database has and which this build of recon would apply,\n" +
+			"apply them, or roll the newest back while developing a migration. `recon init` applies\n" +
+			"pending migrations too. Before the schema changes, the database is snapshotted under\n" +
+			".recon/" + snapshot.DirName + " (see `recon snapshot`), so a bad migration can be undone.",
+		Args: cobra.NoArgs,
+	}
+	cmd.AddCommand(newMigrateStatusCommand(app))
+	cmd.AddCommand(newMigrateUpCommand(app))
+	cmd.AddCommand(newMigrateDownCommand(app))
+	return cmd
+}
+
+func newMigrateStatusCommand(app *App) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the schema version and pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conn, err := openDBAnyVersion(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			status, err := readMigrationStatus(cmd.Context(), conn)
+			if err != nil {
+				return migrateError(jsonOut, "internal_error", err)
+			}
+			if jsonOut {
+				return writeJSON(status)
+			}
+
+			fmt.Printf("Schema version: %d (this recon: %d)\n", status.Version, status.Latest)
+			switch {
+			case status.Dirty:
+				fmt.Printf("Migration %d failed partway; run `recon reset` and `recon init`\n", status.Version)
+			case status.Version > status.Latest:
+				fmt.Println("The database is newer than this recon; upgrade recon")
+			case len(status.Pending) == 0:
+				fmt.Println("Up to date")
+			default:
+				fmt.Printf("Pending migrations: %d; apply them with `recon migrate up`\n", len(status.Pending))
+				for _, m := range status.Pending {
+					fmt.Printf("- %06d %s\n", m.Version, m.Name)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+func newMigrateUpCommand(app *App) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Snapshot the database and apply pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conn, err := openDBAnyVersion(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			before, err := readMigrationStatus(cmd.Context(), conn)
+			if err != nil {
+				return migrateError(jsonOut, "internal_error", err)
+			}
+			if before.Dirty {
+				return migrateError(jsonOut, "invalid_input", fmt.Errorf("migration %d failed partway; run `recon reset` and `recon init`", before.Version))
+			}
+			payload := migratePayload{From: before.Version, To: before.Version, Migrations: before.Pending}
+			if len(before.Pending) > 0 {
+				if payload.Backup, err = backupBeforeMigrate(cmd.Context(), conn, app.ModuleRoot, "pre-migrate"); err != nil {
+					return migrateError(jsonOut, "internal_error", err)
+				}
+				if err := runMigrations(conn); err != nil {
+					return migrateError(jsonOut, "internal_error", err)
+				}
+				payload.To = before.Latest
+			}
+
+			if jsonOut {
+				return writeJSON(payload)
+			}
+			if len(payload.Migrations) == 0 {
+				fmt.Printf("Schema version %d is up to date\n", payload.To)
+				return nil
+			}
+			fmt.Printf("Migrated schema %d -> %d\n", payload.From, payload.To)
+			for _, m := range payload.Migrations {
+				fmt.Printf("- applied %06d %s\n", m.Version, m.Name)
+			}
+			printMigrateBackup(payload.Backup)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+func newMigrateDownCommand(app *App) *cobra.Command {
+	var (
+		jsonOut bool
+		force   bool
+		steps   int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the newest migrations, for developing a migration",
+		Long: "Run the down scripts of the newest applied migrations, one by default. The down scripts\n" +
+			"drop what their migrations added, data included, and commands built for the newest schema\n" +
+			"may fail until `recon migrate up` applies them again. The database is snapshotted first.\n" +
+			"Asks for confirmation; without a terminal, or with --json or --no-prompt, --force is required.",
+		Example: "  recon migrate down\n  recon migrate down --steps 2 --force",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if steps < 1 {
+				return migrateError(jsonOut, "invalid_input", errors.New("--steps must be at least 1"))
+			}
+			conn, err := openDBAnyVersion(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			before, err := readMigrationStatus(cmd.Context(), conn)
+			if err != nil {
+				return migrateError(jsonOut, "internal_error", err)
+			}
+			switch {
+			case before.Dirty:
+				return migrateError(jsonOut, "invalid_input", fmt.Errorf("migration %d failed partway; run `recon reset` and `recon init`", before.Version))
+			case before.Version > before.Latest:
+				return migrateError(jsonOut, "invalid_input", fmt.Errorf("schema version %d is newer than this recon (%d), which lacks its down scripts", before.Version, before.Latest))
+			case steps > len(before.Applied):
+				return migrateError(jsonOut, "invalid_input", fmt.Errorf("--steps %d exceeds the %d applied migrations", steps, len(before.Applied)))
+			}
+			reverted := make([]db.Migration, 0, steps)
+			for i := len(before.Applied) - 1; i >= len(before.Applied)-steps; i-- {
+				reverted = append(reverted, before.Applied[i])
+			}
+
+			if !force {
+				if jsonOut || app.NoPrompt || !isInteractive() {
+					return migrateError(jsonOut, "confirmation_required", errors.New("migrate down drops the tables and columns the migrations added; pass --force"))
+				}
+				fmt.Printf("This will roll back %d migration(s) of %s, dropping what they added. Continue? [y/N] ", steps, db.DBPath(app.ModuleRoot))
+				var confirm string
+				fmt.Scan(&confirm)
+				if confirm != "y" && confirm != "Y" {
+					fmt.Println("Aborted.")
+					return nil
+				}
+			}
+
+			payload := migratePayload{From: before.Version, Migrations: reverted}
+			if payload.Backup, err = backupBeforeMigrate(cmd.Context(), conn, app.ModuleRoot, "pre-migrate-down"); err != nil {
+				return migrateError(jsonOut, "internal_error", err)
+			}
+			if err := rollbackMigrations(conn, steps); err != nil {
+				return migrateError(jsonOut, "internal_error", err)
+			}
+			after, err := readMigrationStatus(cmd.Context(), conn)
+			if err != nil {
+				return migrateError(jsonOut, "internal_error", err)
+			}
+			payload.To = after.Version
+
+			if jsonOut {
+				return writeJSON(payload)
+			}
+			fmt.Printf("Rolled back schema %d -> %d\n", payload.From, payload.To)
+			for _, m := range payload.Migrations {
+				fmt.Printf("- reverted %06d %s\n", m.Version, m.Name)
+			}
+			printMigrateBackup(payload.Backup)
+			fmt.Println("Run `recon migrate up` to apply them again.")
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().BoolVar(&force, "force", false, "Skip confirmation prompt")
+	cmd.Flags().IntVar(&steps, "steps", 1, "Number of migrations to roll back")
+	return cmd
+}
+
+// backupBeforeMigrate snapshots the database behind conn before its schema
+// changes, and prunes snapshots to snapshots.keep.
+func backupBeforeMigrate(ctx context.Context, conn *sql.DB, root, label string) (*snapshot.Snapshot, error) {
+	cfg, err := loadConfig(root)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := snapshot.Create(ctx, conn, root, label, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("snapshot database before migrating: %w", err)
+	}
+	if _, err := snapshot.Prune(root, cfg.Snapshots.Keep); err != nil {
+		return nil, err
+	}
+	if err := db.EnsureGitIgnore(root, snapshot.GitIgnore); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+func printMigrateBackup(snap *snapshot.Snapshot) {
+	if snap != nil {
+		fmt.Printf("The database before migrating was saved as snapshot %s\n", snap.Name)
+	}
+}
+
+func migrateError(jsonOut bool, code string, err error) error {
+	if jsonOut {
+		_ = writeJSONError(code, err.Error(), nil)
+		return ExitError{Code: 2}
+	}
+	return ExitError{Code: 2, Message: err.Error()}
+}
@@ -32,7 +32,7 @@ func TestJSONModeInternalErrorsAreEnveloped(t *testing.T) {
 		runOrientSync = origRunOrientSync
 	}()
 
-	runSync = func(context.Context, *sql.DB, string) (index.SyncResult, error) {
+	runSync = func(context.Context, *sql.DB, string, index.SyncOptions) (index.SyncResult, error) {
 		return index.SyncResult{}, errors.New("sync exploded")
 	}
 	out, _, err := runCommandWithCapture(t, newSyncCommand(app), []string{"--json"})
@@ -40,7 +40,7 @@ func TestJSONModeInternalErrorsAreEnveloped(t *testing.T) {
 		t.Fatalf("expected sync internal_error envelope, out=%q err=%v", out, err)
 	}
 
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		return orient.Payload{}, errors.New("orient exploded")
 	}
 	out, _, err = runCommandWithCapture(t, newOrientCommand(app), []string{"--json"})
@@ -48,7 +48,7 @@ func TestJSONModeInternalErrorsAreEnveloped(t *testing.T) {
 		t.Fatalf("expected orient internal_error envelope, out=%q err=%v", out, err)
 	}
 
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		return orient.Payload{Freshness: orient.Freshness{IsStale: true, Reason: "stale"}}, nil
 	}
 	runOrientSync = func(context.Context, *sql.DB, string) error {
@@ -160,7 +160,7 @@ func TestOrientSyncAndAutoSyncAvoidDoubleSync(t *testing.T) {
 		syncCalls++
 		return nil
 	}
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		return orient.Payload{Freshness: orient.Freshness{IsStale: true, Reason: "stale"}}, nil
 	}
 
@@ -194,7 +194,7 @@ func TestOrientTextModeSyncAndAutoSyncErrors(t *testing.T) {
 		t.Fatal("expected text-mode --sync error")
 	}
 
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		return orient.Payload{Freshness: orient.Freshness{IsStale: true, Reason: "stale"}}, nil
 	}
 	runOrientSync = func(context.Context, *sql.DB, string) error {
@@ -205,7 +205,7 @@ func TestOrientTextModeSyncAndAutoSyncErrors(t *testing.T) {
 	}
 
 	buildCalls := 0
-	buildOrient = func(context.Context, *sql.DB, string) (orient.Payload, error) {
+	buildOrient = func(context.Context, *sql.DB, orient.BuildOptions) (orient.Payload, error) {
 		buildCalls++
 		if buildCalls == 1 {
 			return orient.Payload{Freshness: orient.Freshness{IsStale: true, Reason: "stale"}}, nil
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/robertguss/recon/internal/orient"
+	"github.com/spf13/cobra"
+)
+
+var buildOnboarding = func(ctx context.Context, conn *sql.DB, moduleRoot string) (orient.Onboarding, error) {
+	return orient.NewService(conn).BuildOnboarding(ctx, orient.BuildOptions{ModuleRoot: moduleRoot, MaxModules: 12, MaxDecisions: 10, Branch: currentBranch(ctx, moduleRoot)})
+}
+
+func newOnboardingCommand(app *App) *cobra.Command {
+	var (
+		jsonOut  bool
+		output   string
+		autoSync bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "onboarding",
+		Short: "Generate a markdown onboarding walkthrough for new contributors",
+		Long: "Render a guide for people new to the module from the recon index: entry points, the key\n" +
+			"packages with the first sentence of their package documentation, constraints, decisions,\n" +
+			"and patterns, and how to run the tests. The markdown goes to stdout unless --output names a file.",
+		Example: "  recon onboarding\n  recon onboarding --output docs/ONBOARDING.md --auto-sync",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			guide, err := buildOnboarding(cmd.Context(), conn, app.ModuleRoot)
+			if err == nil && autoSync && guide.Freshness.IsStale {
+				if err = runOrientSync(cmd.Context(), conn, app.ModuleRoot); err == nil {
+					guide, err = buildOnboarding(cmd.Context(), conn, app.ModuleRoot)
+				}
+			}
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+
+			if jsonOut && output == "" {
+				return writeJSON(guide)
+			}
+			doc := orient.RenderOnboarding(guide)
+			if output == "" {
+				fmt.Print(doc)
+				return nil
+			}
+
+			path := output
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(app.ModuleRoot, path)
+			}
+			if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), map[string]any{"output": output})
+					return ExitError{Code: 2}
+				}
+				return fmt.Errorf("write onboarding guide: %w", err)
+			}
+			if jsonOut {
+				return writeJSON(map[string]any{"path": output, "stale": guide.Freshness.IsStale})
+			}
+			fmt.Printf("Wrote %s\n", output)
+			if guide.Freshness.IsStale {
+				fmt.Printf("Warning: index is stale (%s); run `recon sync` or pass --auto-sync\n", guide.Freshness.Reason)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON (the guide's data, or the written path with --output)")
+	cmd.Flags().StringVar(&output, "output", "", "Write the guide to this file, relative to the module root")
+	cmd.Flags().BoolVar(&autoSync, "auto-sync", false, "Sync the index first if it is stale")
+	return cmd
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/robertguss/recon/internal/find"
+	"github.com/spf13/cobra"
+)
+
+// openResult is the payload of `recon open --json`.
+type openResult struct {
+	Symbol   string `json:"symbol"`
+	Kind     string `json:"kind"`
+	Package  string `json:"package"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	// Location is FilePath:Line with an absolute path, as editors take it.
+	Location string `json:"location"`
+}
+
+// gotoEditors take the location as --goto file:line, and lineSuffixEditors as
+// file:line. Every other editor gets vi's +line file.
+var (
+	gotoEditors       = map[string]bool{"code": true, "code-insiders": true, "codium": true, "cursor": true, "windsurf": true}
+	lineSuffixEditors = map[string]bool{"subl": true, "zed": true, "hx": true, "helix": true, "mate": true}
+)
+
+func newOpenCommand(app *App) *cobra.Command {
+	var (
+		jsonOut       bool
+		printOnly     bool
+		packageFilter string
+		fileFilter    string
+		kindFilter    string
+		fuzzy         bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "open <symbol>",
+		Short: "Open a symbol's definition in $EDITOR",
+		Long: "Resolve a symbol as find does and open its file at the symbol's first line in\n" +
+			"$VISUAL or $EDITOR. VS Code and its forks are passed --goto file:line, Sublime\n" +
+			"Text, Zed, and Helix file:line, and every other editor +line file.\n\n" +
+			"--print writes the location as file:line instead of opening it, for editors\n" +
+			"and scripts that take it directly. An ambiguous symbol lists its candidates\n" +
+			"with the --package that picks each one.",
+		Example: "  recon open Service.Build\n  recon open Sync --package internal/index\n  code --goto \"$(recon open Open --print)\"",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				msg := "open requires a <symbol> argument"
+				if jsonOut {
+					_ = writeJSONError("missing_argument", msg, map[string]any{"command": "open"})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			kind, err := normalizeFindKind(kindFilter)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"kind": strings.TrimSpace(kindFilter)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			opts := find.QueryOptions{
+				PackagePath: strings.TrimSpace(packageFilter),
+				FilePath:    normalizeFindPath(app.modulePath(fileFilter)),
+				Kind:        kind,
+				NoBody:      true,
+				Fuzzy:       fuzzy,
+			}
+			result, err := find.NewService(conn).Find(cmd.Context(), args[0], opts)
+			if err != nil {
+				return writeFindLookupError(app, "open", args[0], err, opts, jsonOut)
+			}
+
+			sym := result.Symbol
+			path := filepath.Join(app.ModuleRoot, filepath.FromSlash(sym.FilePath))
+			name := sym.Name
+			if sym.Receiver != "" {
+				name = sym.Receiver + "." + sym.Name
+			}
+			out := openResult{
+				Symbol:   name,
+				Kind:     sym.Kind,
+				Package:  sym.Package,
+				FilePath: sym.FilePath,
+				Line:     sym.LineStart,
+				Location: fmt.Sprintf("%s:%d", path, sym.LineStart),
+			}
+			app.applyPathMode(&out)
+			switch {
+			case jsonOut:
+				return writeJSON(out)
+			case printOnly:
+				fmt.Println(out.Location)
+				return nil
+			}
+
+			argv, err := editorArgs(path, sym.LineStart)
+			if err != nil {
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+			editor := execCommandContext(cmd.Context(), argv[0], argv[1:]...)
+			editor.Dir = app.ModuleRoot
+			editor.Stdin, editor.Stdout, editor.Stderr = os.Stdin, os.Stdout, os.Stderr
+			if err := editor.Run(); err != nil {
+				return fmt.Errorf("run editor %s: %w", argv[0], err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output the resolved location as JSON instead of opening it")
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print file:line instead of opening it")
+	cmd.Flags().StringVar(&packageFilter, "package", "", "Filter by package path when symbols are ambiguous")
+	cmd.Flags().StringVar(&fileFilter, "file", "", "Filter by file path when symbols are ambiguous")
+	cmd.Flags().StringVar(&kindFilter, "kind", "", "Filter by symbol kind (func, method, type, var, const, enum, table, view, proto_service, proto_rpc, proto_message, proto_enum)")
+	cmd.Flags().BoolVar(&fuzzy, "fuzzy", false, "Resolve a symbol with no exact match to its case-insensitive or single closest fuzzy match")
+	cmd.ValidArgsFunction = completeSymbolArg(app)
+	_ = cmd.RegisterFlagCompletionFunc("package", completePackageFlag(app))
+	_ = cmd.RegisterFlagCompletionFunc("kind", cobra.FixedCompletions(findKinds, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// editorArgs returns the command that opens path at line in $VISUAL, or
+// $EDITOR when VISUAL is unset. Either may carry arguments, as in
+// "code --wait".
+func editorArgs(path string, line int) ([]string, error) {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		return nil, errors.New("no editor configured: set $VISUAL or $EDITOR, or use --print")
+	}
+	argv := strings.Fields(editor)
+	name := strings.TrimSuffix(filepath.Base(argv[0]), ".exe")
+	switch {
+	case gotoEditors[name]:
+		return append(argv, "--goto", path+":"+strconv.Itoa(line)), nil
+	case lineSuffixEditors[name]:
+		return append(argv, path+":"+strconv.Itoa(line)), nil
+	default:
+		return append(argv, "+"+strconv.Itoa(line), path), nil
+	}
+}
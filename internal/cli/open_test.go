@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOpenCommand(t *testing.T) {
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	mainGo := filepath.Join(app.ModuleRoot, "main.go")
+
+	out, _, err := runCommandWithCapture(t, newOpenCommand(app), []string{"Alpha", "--print"})
+	if err != nil || !strings.HasPrefix(out, mainGo+":") {
+		t.Fatalf("expected absolute file:line, out=%q err=%v", out, err)
+	}
+	location := strings.TrimSpace(out)
+
+	out, _, err = runCommandWithCapture(t, newOpenCommand(app), []string{"Alpha", "--json"})
+	var result openResult
+	if err != nil || json.Unmarshal([]byte(out), &result) != nil || result.FilePath != "main.go" || result.Location != location || result.Kind != "func" {
+		t.Fatalf("unexpected --json result, out=%q err=%v", out, err)
+	}
+
+	var ran []string
+	origExec := execCommandContext
+	defer func() { execCommandContext = origExec }()
+	execCommandContext = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		ran = append([]string{name}, args...)
+		return exec.CommandContext(ctx, "true")
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+	if _, _, err := runCommandWithCapture(t, newOpenCommand(app), []string{"Alpha"}); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if !slices.Equal(ran, []string{"code", "--wait", "--goto", location}) {
+		t.Fatalf("unexpected editor command %q", ran)
+	}
+
+	out, _, err = runCommandWithCapture(t, newOpenCommand(app), []string{"Ambig"})
+	if err == nil || !strings.Contains(out, "is ambiguous") || !strings.Contains(out, "Try: recon open Ambig --package") {
+		t.Fatalf("expected find's disambiguation, out=%q err=%v", out, err)
+	}
+	out, _, err = runCommandWithCapture(t, newOpenCommand(app), []string{"Missing", "--json"})
+	if err == nil || !strings.Contains(out, `"code": "not_found"`) {
+		t.Fatalf("expected not_found, out=%q err=%v", out, err)
+	}
+	if _, _, err := runCommandWithCapture(t, newOpenCommand(app), nil); err == nil || !strings.Contains(err.Error(), "requires a <symbol>") {
+		t.Fatalf("expected missing argument error, got %v", err)
+	}
+}
+
+func TestEditorArgs(t *testing.T) {
+	for _, tc := range []struct {
+		visual, editor string
+		want           []string
+	}{
+		{"", "vim", []string{"vim", "+12", "/m/a.go"}},
+		{"nvim -p", "code", []string{"nvim", "-p", "+12", "/m/a.go"}},
+		{"", "/usr/local/bin/cursor", []string{"/usr/local/bin/cursor", "--goto", "/m/a.go:12"}},
+		{"", "hx", []string{"hx", "/m/a.go:12"}},
+	} {
+		t.Setenv("VISUAL", tc.visual)
+		t.Setenv("EDITOR", tc.editor)
+		got, err := editorArgs("/m/a.go", 12)
+		if err != nil || !slices.Equal(got, tc.want) {
+			t.Fatalf("VISUAL=%q EDITOR=%q: editorArgs = %q, %v; want %q", tc.visual, tc.editor, got, err, tc.want)
+		}
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", " ")
+	if _, err := editorArgs("/m/a.go", 1); err == nil || !strings.Contains(err.Error(), "use --print") {
+		t.Fatalf("expected no editor error, got %v", err)
+	}
+}
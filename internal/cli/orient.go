@@ -3,9 +3,13 @@ package cli
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"time"
 
+	"github.com/robertguss/recon/internal/config"
 	"github.com/robertguss/recon/internal/index"
 	"github.com/robertguss/recon/internal/orient"
 	"github.com/spf13/cobra"
@@ -14,27 +18,44 @@ import (
 var (
 	isInteractive = isInteractiveTTY
 	askYesNo      = promptYesNo
-	buildOrient   = func(ctx context.Context, conn *sql.DB, moduleRoot string) (orient.Payload, error) {
-		return orient.NewService(conn).Build(ctx, orient.BuildOptions{ModuleRoot: moduleRoot, MaxModules: 8, MaxDecisions: 5})
+	buildOrient   = func(ctx context.Context, conn *sql.DB, opts orient.BuildOptions) (orient.Payload, error) {
+		opts.MaxModules, opts.MaxDecisions = 8, 5
+		opts.Branch = currentBranch(ctx, opts.ModuleRoot)
+		return orient.NewService(conn).Build(ctx, opts)
 	}
 	runOrientSync = func(ctx context.Context, conn *sql.DB, moduleRoot string) error {
-		_, err := index.NewService(conn).Sync(ctx, moduleRoot)
+		lock, err := index.LockSync(ctx, moduleRoot, true)
+		if err != nil {
+			return err
+		}
+		defer lock.Unlock()
+		_, err = index.NewService(conn).Sync(ctx, moduleRoot)
 		return err
 	}
+	loadConfig = config.Load
 )
 
 func newOrientCommand(app *App) *cobra.Command {
 	var (
-		jsonOut    bool
-		jsonStrict bool
-		syncNow    bool
-		autoSync   bool
+		jsonOut      bool
+		jsonStrict   bool
+		syncNow      bool
+		autoSync     bool
+		sessionStart bool
+		focus        string
+		minConf      string
+		withGen      bool
+		recent       int
+		heatOpts     heatFlags
 	)
 
 	cmd := &cobra.Command{
 		Use:   "orient",
 		Short: "Serve startup context for this repository",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if sessionStart {
+				jsonOut = true
+			}
 			if jsonStrict {
 				jsonOut = true
 			}
@@ -48,6 +69,46 @@ func newOrientCommand(app *App) *cobra.Command {
 			}
 			defer conn.Close()
 
+			cfg, err := loadConfig(app.ModuleRoot)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"path": config.Path(app.ModuleRoot)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			if !cmd.Flags().Changed("min-confidence") {
+				minConf = cfg.Orient.MinConfidence
+			}
+			switch minConf {
+			case "", "low", "medium", "high":
+			default:
+				msg := "--min-confidence must be low, medium, or high"
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, map[string]any{"min_confidence": minConf})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			if recent < 1 {
+				msg := "--recent must be at least 1"
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, map[string]any{"recent": recent})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			heat, err := heatOpts.policy(cmd, cfg.Heat)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+			opts := orient.BuildOptions{ModuleRoot: app.ModuleRoot, Focus: focus, MinConfidence: minConf, IncludeGenerated: withGen, Heat: heat, MaxRecent: recent}
+
 			syncedInRun := false
 			if syncNow {
 				if err := runOrientSync(cmd.Context(), conn, app.ModuleRoot); err != nil {
@@ -59,30 +120,29 @@ func newOrientCommand(app *App) *cobra.Command {
 				syncedInRun = true
 			}
 
-			payload, err := buildOrient(cmd.Context(), conn, app.ModuleRoot)
+			payload, err := buildOrient(cmd.Context(), conn, opts)
 			if err != nil {
-				if jsonOut {
-					return exitJSONCommandError(err)
-				}
-				return err
+				return orientBuildError(err, focus, jsonOut)
 			}
 
 			if payload.Freshness.IsStale {
-				if autoSync && !syncedInRun {
+				policy, act := resolveAutoSync(cfg.Freshness, payload.Freshness, autoSync, sessionStart, time.Now())
+				switch {
+				case act && policy == config.AutoSyncAlways && !syncedInRun:
 					if err := runOrientSync(cmd.Context(), conn, app.ModuleRoot); err != nil {
 						if jsonOut {
 							return exitJSONCommandError(err)
 						}
 						return err
 					}
-					payload, err = buildOrient(cmd.Context(), conn, app.ModuleRoot)
+					payload, err = buildOrient(cmd.Context(), conn, opts)
 					if err != nil {
 						if jsonOut {
 							return exitJSONCommandError(err)
 						}
 						return err
 					}
-				} else if !jsonOut && !app.NoPrompt && isInteractive() {
+				case act && policy == config.AutoSyncPrompt && !jsonOut && !app.NoPrompt && isInteractive():
 					runSync, err := askYesNo("Index looks stale. Run recon sync now? [Y/n]: ", true)
 					if err != nil {
 						return fmt.Errorf("read stale prompt: %w", err)
@@ -91,21 +151,24 @@ func newOrientCommand(app *App) *cobra.Command {
 						if err := runOrientSync(cmd.Context(), conn, app.ModuleRoot); err != nil {
 							return err
 						}
-						payload, err = buildOrient(cmd.Context(), conn, app.ModuleRoot)
+						payload, err = buildOrient(cmd.Context(), conn, opts)
 						if err != nil {
 							return err
 						}
 					}
-				} else if !jsonStrict {
+				case !jsonStrict:
 					fmt.Fprintf(os.Stderr, "warning: stale context (%s)\n", payload.Freshness.Reason)
 				}
 			}
 
+			app.applyPathMode(&payload)
 			if jsonOut {
 				return writeJSON(payload)
 			}
 
+			start := time.Now()
 			fmt.Print(orient.RenderText(payload))
+			slog.Debug("render", "format", "text", "duration", time.Since(start))
 			return nil
 		},
 	}
@@ -114,5 +177,57 @@ func newOrientCommand(app *App) *cobra.Command {
 	cmd.Flags().BoolVar(&jsonStrict, "json-strict", false, "Output JSON only (suppresses warnings; implies --json)")
 	cmd.Flags().BoolVar(&syncNow, "sync", false, "Run sync before building orient context")
 	cmd.Flags().BoolVar(&autoSync, "auto-sync", false, "Automatically run sync when stale instead of prompting")
+	cmd.Flags().BoolVar(&sessionStart, "session-start", false, "Session-start mode for hooks and agents: JSON output, stale policy defaults to always")
+	cmd.Flags().StringVar(&focus, "focus", "", "Scope context to one package subtree (e.g. internal/index)")
+	cmd.Flags().BoolVar(&withGen, "include-generated", false, "Count generated and vendored files in the summary and module map")
+	cmd.Flags().StringVar(&minConf, "min-confidence", "", "Leave out decisions and patterns below this confidence: low, medium, high (default orient.min_confidence)")
+	cmd.Flags().IntVar(&recent, "recent", 5, "Number of recently changed files to list, each with its last commit")
+	addHeatFlags(cmd, &heatOpts)
 	return cmd
 }
+
+// orientBuildError reports an unknown --focus path as not_found and any
+// other build failure as-is.
+func orientBuildError(err error, focus string, jsonOut bool) error {
+	if errors.Is(err, orient.ErrFocusNotFound) {
+		msg := fmt.Sprintf("no indexed package at or under %q; run `recon sync` if it is new", focus)
+		if jsonOut {
+			_ = writeJSONError("not_found", msg, map[string]any{"focus": focus})
+			return ExitError{Code: 2}
+		}
+		return ExitError{Code: 2, Message: msg}
+	}
+	if jsonOut {
+		return exitJSONCommandError(err)
+	}
+	return err
+}
+
+// resolveAutoSync picks the stale-index policy and reports whether it should
+// be applied now. --auto-sync forces always regardless of age; otherwise
+// freshness.auto_sync from the config wins over the entry point's default,
+// and a stale index younger than freshness.max_staleness only gets a warning.
+func resolveAutoSync(cfg config.Freshness, fresh orient.Freshness, autoSync, sessionStart bool, now time.Time) (config.AutoSyncPolicy, bool) {
+	if autoSync {
+		return config.AutoSyncAlways, true
+	}
+	policy := cfg.AutoSync
+	if policy == "" {
+		policy = config.AutoSyncPrompt
+		if sessionStart {
+			policy = config.AutoSyncAlways
+		}
+	}
+	if policy == config.AutoSyncNever {
+		return policy, false
+	}
+	limit := cfg.MaxStalenessDuration()
+	if limit <= 0 || fresh.LastSyncAt == "" {
+		return policy, true
+	}
+	last, err := time.Parse(time.RFC3339, fresh.LastSyncAt)
+	if err != nil {
+		return policy, true
+	}
+	return policy, now.Sub(last) >= limit
+}
@@ -2,18 +2,28 @@ package cli
 
 import (
 	"bufio"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"golang.org/x/term"
 )
 
 func writeJSON(v any) error {
+	start := time.Now()
+	v, err := versionOutput(v, outputVersion, outputVersionPinned)
+	if err != nil {
+		return fmt.Errorf("encode output: %w", err)
+	}
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
-	return enc.Encode(v)
+	err = enc.Encode(v)
+	slog.Debug("render", "format", "json", "duration", time.Since(start))
+	return err
 }
 
 type jsonErrorEnvelope struct {
@@ -36,6 +46,75 @@ func writeJSONError(code string, message string, details any) error {
 	})
 }
 
+// writeCSV prints a header row followed by rows as RFC 4180 CSV.
+func writeCSV(header []string, rows [][]string) error {
+	w := csv.NewWriter(os.Stdout)
+	if err := w.WriteAll(append([][]string{header}, rows...)); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	return nil
+}
+
+// ndjsonWriter prints records as newline-delimited JSON, one compact object
+// per line, so that large results can be piped and filtered as they arrive.
+// The records are the elements of a jsonPayloads payload; they are never
+// wrapped in the output version envelope, but version 2 renames their fields.
+type ndjsonWriter struct {
+	enc     *json.Encoder
+	element string
+	renames map[string]string // relative to the element
+	warned  bool
+}
+
+// newNDJSONWriter returns a writer for the elements of payload found at
+// element, such as "items[]" for the items of a recall result.
+func newNDJSONWriter(payload any, element string) *ndjsonWriter {
+	w := &ndjsonWriter{enc: json.NewEncoder(os.Stdout), element: element, renames: map[string]string{}}
+	for path, to := range payloadRenames(payload) {
+		if rel, ok := strings.CutPrefix(path, element+"."); ok {
+			w.renames[rel] = to
+		}
+	}
+	return w
+}
+
+func (w *ndjsonWriter) Write(v any) error {
+	if len(w.renames) == 0 || (outputVersion < latestOutputVersion && (!outputVersionPinned || w.warned)) {
+		return w.enc.Encode(v)
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode output: %w", err)
+	}
+	renamed, found, err := renameFields(raw, w.renames)
+	if err != nil {
+		return fmt.Errorf("encode output: %w", err)
+	}
+	if outputVersion < latestOutputVersion {
+		for _, path := range found {
+			fmt.Fprintf(os.Stderr, "warning: JSON field %s.%s is deprecated; --output-version %d renames it %s\n", w.element, path, latestOutputVersion, w.renames[path])
+		}
+		w.warned = w.warned || len(found) > 0
+		return w.enc.Encode(json.RawMessage(raw))
+	}
+	return w.enc.Encode(json.RawMessage(renamed))
+}
+
+// parseFormat validates a --format value against the formats a command
+// supports. The first allowed format is the default.
+func parseFormat(value string, allowed ...string) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(value))
+	if format == "" {
+		return allowed[0], nil
+	}
+	for _, a := range allowed {
+		if format == a {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported --format %q (expected %s)", value, strings.Join(allowed, ", "))
+}
+
 func isInteractiveTTY() bool {
 	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
 }
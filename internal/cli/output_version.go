@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+
+	"github.com/robertguss/recon/internal/schema"
+)
+
+// Versions of the --json output. Version 1 prints each payload as is.
+// Version 2 wraps it as {"schema_version": 2, "data": payload}, and an error
+// as {"schema_version": 2, "error": {...}}, and uses the field names of
+// outputRenames.
+const (
+	legacyOutputVersion = 1
+	latestOutputVersion = 2
+)
+
+// outputVersion is the --output-version of the running command, and
+// outputVersionPinned whether the flag was given. A script that pins version
+// 1 is warned about the fields version 2 renames.
+var (
+	outputVersion       = legacyOutputVersion
+	outputVersionPinned bool
+)
+
+// fieldRename renames a field of a jsonPayloads payload in output version 2.
+// Path is the field's place in the payload, with [] for the elements of a
+// list, such as "items[].evidence_drift_status"; To is its new name.
+type fieldRename struct {
+	Payload string
+	Path    string
+	To      string
+}
+
+var outputRenames = []fieldRename{
+	{Payload: "RecallResult", Path: "items[].evidence_drift_status", To: "drift_status"},
+	{Payload: "RecallAllReposResult", Path: "repos[].items[].evidence_drift_status", To: "drift_status"},
+}
+
+func setOutputVersion(v int, pinned bool) error {
+	if v < legacyOutputVersion || v > latestOutputVersion {
+		return fmt.Errorf("--output-version must be %d or %d", legacyOutputVersion, latestOutputVersion)
+	}
+	outputVersion, outputVersionPinned = v, pinned
+	return nil
+}
+
+type versionedOutput struct {
+	SchemaVersion int             `json:"schema_version"`
+	Data          json.RawMessage `json:"data,omitempty"`
+	Error         *jsonErrorBody  `json:"error,omitempty"`
+}
+
+// versionOutput returns what writeJSON prints for v in output version
+// version. In version 1 a field that version 2 renames is kept, with a
+// deprecation warning on stderr when warn is set.
+func versionOutput(v any, version int, warn bool) (any, error) {
+	renames := payloadRenames(v)
+	if version < latestOutputVersion {
+		if warn && len(renames) > 0 {
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			_, found, err := renameFields(raw, renames)
+			if err != nil {
+				return nil, err
+			}
+			for _, path := range found {
+				fmt.Fprintf(os.Stderr, "warning: JSON field %s is deprecated; --output-version %d renames it %s\n", path, latestOutputVersion, renames[path])
+			}
+		}
+		return v, nil
+	}
+
+	if envelope, ok := v.(jsonErrorEnvelope); ok {
+		return versionedOutput{SchemaVersion: version, Error: &envelope.Error}, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(renames) > 0 {
+		if raw, _, err = renameFields(raw, renames); err != nil {
+			return nil, err
+		}
+	}
+	return versionedOutput{SchemaVersion: version, Data: raw}, nil
+}
+
+// payloadRenames returns the renames of v's payload type, by path.
+func payloadRenames(v any) map[string]string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	i := slices.IndexFunc(jsonPayloads, func(p schema.Type) bool { return reflect.TypeOf(p.Value) == t })
+	if i < 0 {
+		return nil
+	}
+	renames := map[string]string{}
+	for _, r := range outputRenames {
+		if r.Payload == jsonPayloads[i].Name {
+			renames[r.Path] = r.To
+		}
+	}
+	return renames
+}
+
+// renameFields rewrites the object keys of raw found at the paths of renames,
+// keeping their order, and reports the paths it found.
+func renameFields(raw []byte, renames map[string]string) ([]byte, []string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var (
+		out   bytes.Buffer
+		found []string
+	)
+	var walk func(path string) error
+	walk = func(path string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			out.WriteByte('{')
+			for i := 0; dec.More(); i++ {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key := keyTok.(string)
+				child := key
+				if path != "" {
+					child = path + "." + key
+				}
+				if to, ok := renames[child]; ok {
+					if !slices.Contains(found, child) {
+						found = append(found, child)
+					}
+					key = to
+				}
+				if i > 0 {
+					out.WriteByte(',')
+				}
+				name, _ := json.Marshal(key)
+				out.Write(name)
+				out.WriteByte(':')
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+			out.WriteByte('}')
+			return err
+		case json.Delim('['):
+			out.WriteByte('[')
+			for i := 0; dec.More(); i++ {
+				if i > 0 {
+					out.WriteByte(',')
+				}
+				if err := walk(path + "[]"); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+			out.WriteByte(']')
+			return err
+		default:
+			value, err := json.Marshal(tok)
+			if err != nil {
+				return err
+			}
+			out.Write(value)
+			return nil
+		}
+	}
+	if err := walk(""); err != nil {
+		return nil, nil, fmt.Errorf("rename output fields: %w", err)
+	}
+	return out.Bytes(), found, nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOutputVersions(t *testing.T) {
+	defer func() { outputVersion, outputVersionPinned = legacyOutputVersion, false }()
+	app := setupInitializedApp(t)
+	createTestDecision(t, app, "Retries are bounded")
+
+	out, errOut, err := runCommandWithCapture(t, newRecallCommand(app), []string{"Retries", "--json"})
+	if err != nil || !strings.Contains(out, `"evidence_drift_status": "ok"`) || errOut != "" {
+		t.Fatalf("expected bare v1 payload without warnings, out=%q stderr=%q err=%v", out, errOut, err)
+	}
+
+	if err := setOutputVersion(legacyOutputVersion, true); err != nil {
+		t.Fatalf("setOutputVersion: %v", err)
+	}
+	out, errOut, err = runCommandWithCapture(t, newRecallCommand(app), []string{"Retries", "--json"})
+	if err != nil || !strings.Contains(out, `"evidence_drift_status": "ok"`) ||
+		errOut != "warning: JSON field items[].evidence_drift_status is deprecated; --output-version 2 renames it drift_status\n" {
+		t.Fatalf("expected pinned v1 payload with a deprecation warning, out=%q stderr=%q err=%v", out, errOut, err)
+	}
+
+	if err := setOutputVersion(latestOutputVersion, true); err != nil {
+		t.Fatalf("setOutputVersion: %v", err)
+	}
+	out, errOut, err = runCommandWithCapture(t, newRecallCommand(app), []string{"Retries", "--json"})
+	var versioned struct {
+		SchemaVersion int `json:"schema_version"`
+		Data          struct {
+			Query string           `json:"query"`
+			Items []map[string]any `json:"items"`
+		} `json:"data"`
+	}
+	if err != nil || errOut != "" || json.Unmarshal([]byte(out), &versioned) != nil || versioned.SchemaVersion != 2 ||
+		versioned.Data.Query != "Retries" || len(versioned.Data.Items) != 1 || versioned.Data.Items[0]["drift_status"] != "ok" {
+		t.Fatalf("expected v2 envelope with renamed fields, out=%q stderr=%q err=%v", out, errOut, err)
+	}
+	if _, ok := versioned.Data.Items[0]["evidence_drift_status"]; ok {
+		t.Fatalf("expected evidence_drift_status to be renamed, out=%q", out)
+	}
+	if strings.Index(out, `"entity_type"`) > strings.Index(out, `"drift_status"`) {
+		t.Fatalf("expected renaming to keep field order, out=%q", out)
+	}
+
+	out, _, err = runCommandWithCapture(t, newFindCommand(app), []string{"Missing", "--json"})
+	if err == nil || !strings.HasPrefix(out, "{\n  \"schema_version\": 2,\n  \"error\": {\n    \"code\": \"not_found\"") {
+		t.Fatalf("expected v2 error envelope, out=%q err=%v", out, err)
+	}
+
+	if err := setOutputVersion(3, false); err == nil || !strings.Contains(err.Error(), "--output-version must be 1 or 2") {
+		t.Fatalf("expected invalid version error, got %v", err)
+	}
+}
+
+func TestOutputVersionFlag(t *testing.T) {
+	defer func() { outputVersion, outputVersionPinned = legacyOutputVersion, false }()
+	app := setupInitializedApp(t)
+	origGetwd := osGetwd
+	defer func() { osGetwd = origGetwd }()
+	osGetwd = func() (string, error) { return app.ModuleRoot, nil }
+
+	root, err := NewRootCommand(context.Background())
+	if err != nil {
+		t.Fatalf("NewRootCommand: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, root, []string{"--output-version", "0", "status", "--json"}); err == nil || !strings.Contains(err.Error(), "--output-version must be 1 or 2") {
+		t.Fatalf("expected invalid --output-version error, got %v", err)
+	}
+
+	root, _ = NewRootCommand(context.Background())
+	out, _, err := runCommandWithCapture(t, root, []string{"--output-version", "2", "status", "--json"})
+	if err != nil || !strings.HasPrefix(out, "{\n  \"schema_version\": 2,\n  \"data\": {\n    \"initialized\": true") || !outputVersionPinned {
+		t.Fatalf("expected versioned status, out=%q err=%v", out, err)
+	}
+}
+
+func TestRenameFields(t *testing.T) {
+	out, found, err := renameFields([]byte(`{"a":[{"x":1,"y":"<"}],"x":null,"b":{"x":true}}`), map[string]string{"a[].x": "z", "b.x": "w", "c": "d"})
+	if err != nil || string(out) != `{"a":[{"z":1,"y":"\u003c"}],"x":null,"b":{"w":true}}` || strings.Join(found, ",") != "a[].x,b.x" {
+		t.Fatalf("renameFields = %s, %v, %v", out, found, err)
+	}
+	if _, _, err := renameFields([]byte(`{"a":`), nil); err == nil || !strings.Contains(err.Error(), "rename output fields") {
+		t.Fatalf("expected malformed JSON error, got %v", err)
+	}
+}
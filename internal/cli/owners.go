@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/robertguss/recon/internal/owners"
+	"github.com/spf13/cobra"
+)
+
+func newOwnersCommand(app *App) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "owners <path>",
+		Short: "Show who owns a file or directory according to CODEOWNERS",
+		Long: "Resolve a path against the repository's CODEOWNERS file (.github/CODEOWNERS,\n" +
+			"CODEOWNERS, or docs/CODEOWNERS) as recorded by the last `recon sync`. The last\n" +
+			"matching rule wins, as on GitHub; a rule without owners leaves the path unowned.",
+		Example: "  recon owners internal/db/db.go\n  recon owners internal/index --json",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				msg := "owners requires a path"
+				if jsonOut {
+					_ = writeJSONError("missing_argument", msg, map[string]any{"command": "owners"})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			rel, ok := moduleRelPath(app.ModuleRoot, args[0])
+			if !ok && filepath.Clean(args[0]) == "." {
+				rel, ok = ".", true
+			}
+			if !ok {
+				msg := fmt.Sprintf("%s is outside the module", args[0])
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, map[string]any{"path": args[0]})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			svc := owners.NewService(conn)
+			rules, err := svc.Rules(cmd.Context())
+			if err == nil && len(rules) == 0 {
+				msg := "no CODEOWNERS rules indexed; add .github/CODEOWNERS, CODEOWNERS, or docs/CODEOWNERS and run `recon sync`"
+				if jsonOut {
+					_ = writeJSONError("not_found", msg, map[string]any{"path": rel})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			var result owners.Result
+			if err == nil {
+				result, err = svc.Lookup(cmd.Context(), rel)
+			}
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+
+			result.Path = app.displayPath(result.Path)
+			if jsonOut {
+				return writeJSON(result)
+			}
+			switch {
+			case result.Rule == nil:
+				fmt.Printf("%s: no CODEOWNERS rule matches\n", result.Path)
+			case len(result.Owners) == 0:
+				fmt.Printf("%s: unowned (%s:%d %s)\n", result.Path, result.Rule.Source, result.Rule.Line, result.Rule.Pattern)
+			default:
+				fmt.Printf("%s: %s (%s:%d %s)\n", result.Path, strings.Join(result.Owners, " "), result.Rule.Source, result.Rule.Line, result.Rule.Pattern)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
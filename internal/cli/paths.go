@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// filePathFields names the payload fields that hold module-relative file
+// paths. Package paths and cover profile names are import paths, not files,
+// and keep their form.
+var filePathFields = map[string]bool{
+	"FilePath":     true,
+	"File":         true,
+	"Files":        true,
+	"ChangedFiles": true,
+}
+
+// displayPath renders a file path as the global --abs-paths and --rel-paths
+// flags ask: absolute, or relative to the module root. Without either flag,
+// and for paths outside the module, p is returned as is.
+func (a *App) displayPath(p string) string {
+	switch {
+	case p == "":
+		return p
+	case a.AbsPaths && !filepath.IsAbs(p):
+		return filepath.Join(a.ModuleRoot, filepath.FromSlash(p))
+	case a.RelPaths:
+		return a.modulePath(p)
+	}
+	return p
+}
+
+// modulePath converts an absolute path inside the module to the
+// module-relative slash form recon indexes files by.
+func (a *App) modulePath(p string) string {
+	if !filepath.IsAbs(p) {
+		return p
+	}
+	rel, err := filepath.Rel(a.ModuleRoot, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return p
+	}
+	return filepath.ToSlash(rel)
+}
+
+// applyPathMode rewrites the file path fields of the payload v points to
+// with displayPath, so text and JSON output agree.
+func (a *App) applyPathMode(v any) {
+	if !a.AbsPaths && !a.RelPaths {
+		return
+	}
+	a.rewritePaths(reflect.ValueOf(v), false)
+}
+
+func (a *App) rewritePaths(v reflect.Value, isPath bool) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			a.rewritePaths(v.Elem(), isPath)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				a.rewritePaths(v.Field(i), filePathFields[f.Name])
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			a.rewritePaths(v.Index(i), isPath)
+		}
+	case reflect.String:
+		if isPath && v.CanSet() {
+			v.SetString(a.displayPath(v.String()))
+		}
+	}
+}
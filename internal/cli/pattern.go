@@ -3,8 +3,12 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/robertguss/recon/internal/config"
 	"github.com/robertguss/recon/internal/edge"
+	"github.com/robertguss/recon/internal/knowledge"
 	"github.com/robertguss/recon/internal/pattern"
 	"github.com/spf13/cobra"
 )
@@ -22,8 +26,11 @@ func newPatternCommand(app *App) *cobra.Command {
 		checkSymbol     string
 		checkPattern    string
 		checkScope      string
+		checkTimeout    time.Duration
+		verifyInterval  time.Duration
 		jsonOut         bool
 		listFlag        bool
+		dryRun          bool
 		deleteID        int64
 		updateID        int64
 		affectsRefs     []string
@@ -100,10 +107,12 @@ func newPatternCommand(app *App) *cobra.Command {
 			// Update mode
 			if updateID > 0 {
 				titleChanged := cmd.Flags().Changed("title")
-				reasoningChanged := cmd.Flags().Changed("reasoning")
+				reasoningChanged := cmd.Flags().Changed("reasoning") || cmd.Flags().Changed("description")
+				confidenceChanged := cmd.Flags().Changed("confidence")
+				exampleChanged := cmd.Flags().Changed("example")
 
-				if !titleChanged && !reasoningChanged {
-					msg := "--update requires at least one of --reasoning or --title"
+				if !titleChanged && !reasoningChanged && !confidenceChanged && !exampleChanged {
+					msg := "--update requires at least one of --confidence, --example, --reasoning, or --title"
 					if jsonOut {
 						_ = writeJSONError("missing_argument", msg, map[string]any{"id": updateID})
 						return ExitError{Code: 2}
@@ -120,27 +129,34 @@ func newPatternCommand(app *App) *cobra.Command {
 				}
 				defer conn.Close()
 
-				svc := pattern.NewService(conn)
-
-				if titleChanged || reasoningChanged {
-					if err := svc.UpdatePattern(cmd.Context(), updateID, pattern.UpdatePatternInput{
-						Title:       updateTitle,
-						Description: reasoning,
-					}); err != nil {
-						if jsonOut {
-							code := "internal_error"
-							if errors.Is(err, pattern.ErrNotFound) {
-								code = "not_found"
-							}
-							_ = writeJSONError(code, err.Error(), map[string]any{"id": updateID})
-							return ExitError{Code: 2}
+				if err := pattern.NewService(conn).UpdatePattern(cmd.Context(), updateID, pattern.UpdatePatternInput{
+					Title:       updateTitle,
+					Description: reasoning,
+					Confidence:  confidence,
+					Example:     example,
+				}); err != nil {
+					if jsonOut {
+						code := "internal_error"
+						switch {
+						case errors.Is(err, pattern.ErrNotFound):
+							code = "not_found"
+						case strings.Contains(err.Error(), "confidence must be"), strings.Contains(err.Error(), "at least one field"):
+							code = "invalid_input"
 						}
-						return err
+						_ = writeJSONError(code, err.Error(), map[string]any{"id": updateID})
+						return ExitError{Code: 2}
 					}
+					return err
 				}
 
 				if jsonOut {
 					fields := map[string]any{"updated": true, "id": updateID}
+					if confidenceChanged {
+						fields["confidence"] = confidence
+					}
+					if exampleChanged {
+						fields["example"] = example
+					}
 					if titleChanged {
 						fields["title"] = updateTitle
 					}
@@ -153,6 +169,51 @@ func newPatternCommand(app *App) *cobra.Command {
 				return nil
 			}
 
+			// Dry-run mode
+			if dryRun {
+				resolvedSpec, err := buildCheckSpec(checkType, checkSpec, checkPath, checkSymbol, checkPattern, checkScope, checkTimeout)
+				if err != nil {
+					if jsonOut {
+						details := map[string]any{"check_type": checkType}
+						_ = writeJSONError("invalid_input", err.Error(), details)
+						return ExitError{Code: 2}
+					}
+					return err
+				}
+
+				conn, err := openExistingDB(app)
+				if err != nil {
+					if jsonOut {
+						return exitJSONCommandError(err)
+					}
+					return err
+				}
+				defer conn.Close()
+
+				outcome := knowledge.NewService(conn).RunCheckPublic(cmd.Context(), checkType, resolvedSpec, app.ModuleRoot)
+
+				type dryRunResult struct {
+					Passed  bool   `json:"passed"`
+					Details string `json:"details"`
+				}
+				result := dryRunResult{Passed: outcome.Passed, Details: outcome.Details}
+
+				if jsonOut {
+					if !result.Passed {
+						_ = writeJSONError("verification_failed", result.Details, map[string]any{"passed": false})
+						return ExitError{Code: 2}
+					}
+					return writeJSON(result)
+				}
+
+				if result.Passed {
+					fmt.Printf("Dry run: passed — %s\n", result.Details)
+					return nil
+				}
+				fmt.Printf("Dry run: failed — %s\n", result.Details)
+				return ExitError{Code: 2}
+			}
+
 			// Propose mode
 			if len(args) == 0 {
 				msg := "pattern requires a <title> argument"
@@ -164,7 +225,10 @@ func newPatternCommand(app *App) *cobra.Command {
 			}
 			title := args[0]
 
-			resolvedSpec, err := buildCheckSpec(checkType, checkSpec, checkPath, checkSymbol, checkPattern, checkScope)
+			resolvedSpec, err := buildCheckSpec(checkType, checkSpec, checkPath, checkSymbol, checkPattern, checkScope, checkTimeout)
+			if err == nil && verifyInterval < 0 {
+				err = fmt.Errorf("--verify-interval must not be negative")
+			}
 			if err != nil {
 				if jsonOut {
 					details := map[string]any{"check_type": checkType}
@@ -183,6 +247,15 @@ func newPatternCommand(app *App) *cobra.Command {
 			}
 			defer conn.Close()
 
+			cfg, err := loadConfig(app.ModuleRoot)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"path": config.Path(app.ModuleRoot)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
 			result, err := pattern.NewService(conn).ProposeAndVerifyPattern(cmd.Context(), pattern.ProposePatternInput{
 				Title:           title,
 				Description:     reasoning,
@@ -192,9 +265,15 @@ func newPatternCommand(app *App) *cobra.Command {
 				CheckType:       checkType,
 				CheckSpec:       resolvedSpec,
 				ModuleRoot:      app.ModuleRoot,
+				Policy:          evidencePolicy(cfg.Knowledge),
+				VerifyInterval:  verifyInterval,
 			})
 			if err != nil {
 				if jsonOut {
+					if errors.Is(err, knowledge.ErrEvidenceTooWeak) {
+						_ = writeJSONError("invalid_input", err.Error(), map[string]any{"check_type": checkType})
+						return ExitError{Code: 2}
+					}
 					_ = writeJSONError("internal_error", err.Error(), nil)
 					return ExitError{Code: 2}
 				}
@@ -271,22 +350,26 @@ func newPatternCommand(app *App) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&reasoning, "reasoning", "", "Pattern reasoning")
+	cmd.Flags().StringVar(&reasoning, "description", "", "Pattern description (same as --reasoning)")
 	cmd.Flags().StringVar(&example, "example", "", "Code example demonstrating the pattern")
-	cmd.Flags().StringVar(&confidence, "confidence", "medium", "Confidence: low, medium, high")
+	cmd.Flags().StringVar(&confidence, "confidence", "", "Confidence: low, medium, high (default knowledge.default_confidence, else medium)")
 	cmd.Flags().StringVar(&evidenceSummary, "evidence-summary", "", "Evidence summary")
-	cmd.Flags().StringVar(&checkType, "check-type", "", "Verification check type: grep_pattern, symbol_exists, file_exists")
+	cmd.Flags().StringVar(&checkType, "check-type", "", "Verification check type: grep_pattern, grep_absent, not_grep_pattern, import_absent, symbol_exists, symbol_count, file_exists, file_absent, go_build_passes, go_test_passes, command_output")
 	cmd.Flags().StringVar(&checkSpec, "check-spec", "", "Verification check spec JSON")
-	cmd.Flags().StringVar(&checkPath, "check-path", "", "Typed check field for file_exists: path")
+	cmd.Flags().StringVar(&checkPath, "check-path", "", "Typed check field for file_exists/file_absent: path")
 	cmd.Flags().StringVar(&checkSymbol, "check-symbol", "", "Typed check field for symbol_exists: symbol name")
-	cmd.Flags().StringVar(&checkPattern, "check-pattern", "", "Typed check field for grep_pattern: regex pattern")
-	cmd.Flags().StringVar(&checkScope, "check-scope", "", "Typed check field for grep_pattern: optional file glob scope")
+	cmd.Flags().StringVar(&checkPattern, "check-pattern", "", "Typed check field for grep_pattern/grep_absent/not_grep_pattern (regex) or import_absent (forbidden package pattern)")
+	cmd.Flags().StringVar(&checkScope, "check-scope", "", "Typed check field for grep_pattern/grep_absent/not_grep_pattern (file glob), import_absent (importing packages), or go_build_passes/go_test_passes (package patterns)")
+	cmd.Flags().DurationVar(&checkTimeout, "check-timeout", 0, "Typed check field for go_build_passes/go_test_passes: time limit (default 2m build, 5m test)")
+	cmd.Flags().DurationVar(&verifyInterval, "verify-interval", 0, "How often recon verify --due re-checks the evidence (default 24h, 168h for build/test checks)")
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
 	cmd.Flags().BoolVar(&listFlag, "list", false, "List active patterns")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Run verification check only, without creating any state")
 	cmd.Flags().Int64Var(&deleteID, "archive", 0, "Archive (soft-delete) a pattern by ID")
 	// --delete kept as a hidden alias for backward compatibility
 	cmd.Flags().Int64Var(&deleteID, "delete", 0, "")
 	_ = cmd.Flags().MarkHidden("delete")
-	cmd.Flags().Int64Var(&updateID, "update", 0, "Update a pattern by ID (use with --reasoning or --title)")
+	cmd.Flags().Int64Var(&updateID, "update", 0, "Update a pattern by ID (use with --confidence, --example, --reasoning, or --title)")
 	cmd.Flags().StringVar(&updateTitle, "title", "", "New title (for --update mode)")
 	cmd.Flags().StringSliceVar(&affectsRefs, "affects", nil, "Package/file/symbol this pattern affects (creates edges)")
 
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/robertguss/recon/internal/index"
+	"golang.org/x/term"
+)
+
+// stderrIsTerminal reports whether progress can redraw a line on stderr.
+var stderrIsTerminal = func() bool { return term.IsTerminal(int(os.Stderr.Fd())) }
+
+// spinnerInterval is how often the sync spinner redraws.
+var spinnerInterval = 100 * time.Millisecond
+
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// syncSpinner redraws one status line on a terminal while a sync runs. It
+// ticks on its own goroutine, so it keeps moving through phases that report
+// nothing, such as walking a large module.
+type syncSpinner struct {
+	w     io.Writer
+	mu    sync.Mutex
+	state index.Progress
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+func startSyncSpinner(w io.Writer) *syncSpinner {
+	s := &syncSpinner{w: w, state: index.Progress{Phase: index.PhaseCollect}, stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			s.mu.Lock()
+			fmt.Fprintf(s.w, "\r\033[K%c %s", spinnerFrames[frame%len(spinnerFrames)], progressLine(s.state))
+			s.mu.Unlock()
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				fmt.Fprint(s.w, "\r\033[K")
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// Update is an index.SyncOptions.Progress callback.
+func (s *syncSpinner) Update(p index.Progress) {
+	s.mu.Lock()
+	s.state = p
+	s.mu.Unlock()
+}
+
+// Stop clears the status line.
+func (s *syncSpinner) Stop() {
+	close(s.stop)
+	<-s.done
+}
+
+// phaseLogger writes a line to w as a sync enters each phase, for --verbose
+// output that is not a terminal.
+func phaseLogger(w io.Writer) func(index.Progress) {
+	var last string
+	return func(p index.Progress) {
+		if p.Phase == last {
+			return
+		}
+		last = p.Phase
+		fmt.Fprintln(w, progressLine(p))
+	}
+}
+
+// progressLine describes where a sync is.
+func progressLine(p index.Progress) string {
+	switch p.Phase {
+	case index.PhaseCollect:
+		return "Collecting files..."
+	case index.PhaseParse:
+		if p.Package == "" {
+			return fmt.Sprintf("Parsing %d files...", p.Total)
+		}
+		return fmt.Sprintf("Parsing %d/%d files (%s)", p.Done, p.Total, p.Package)
+	case index.PhaseLink:
+		return "Linking imports, methods, and renames..."
+	default:
+		return "Committing..."
+	}
+}
+
+// printSyncTimings prints the phase breakdown of a full sync.
+func printSyncTimings(t index.SyncTimings) {
+	fmt.Printf("Timings: collect %dms, parse %dms, insert %dms, commit %dms, total %dms\n",
+		t.CollectMS, t.ParseMS, t.InsertMS, t.CommitMS, t.TotalMS)
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/robertguss/recon/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+func newPromptCommand(app *App) *cobra.Command {
+	var (
+		jsonOut bool
+		budget  int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prompt <task>",
+		Short: "Bundle the knowledge, symbols, and files relevant to a task",
+		Long: "Extract keywords from a task description, recall the decisions, patterns, and\n" +
+			"constraints they match, find the symbols whose names contain them, and rank the files\n" +
+			"those point to. The most relevant entries that fit --budget (estimated at four bytes\n" +
+			"per token) are rendered as one markdown document to paste into a prompt. Symbols\n" +
+			"named exactly by a keyword include their source.",
+		Example: "  recon prompt \"add retry to the sync command\"\n  recon prompt \"cache find results\" --budget 2000 --json",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fail := func(code, msg string, details map[string]any) error {
+				if jsonOut {
+					_ = writeJSONError(code, msg, details)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			if len(args) == 0 || strings.TrimSpace(args[0]) == "" {
+				return fail("missing_argument", "prompt requires a <task> argument", map[string]any{"command": "prompt"})
+			}
+			if budget <= 0 {
+				return fail("invalid_input", "--budget must be positive", map[string]any{"budget": budget})
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			bundle, err := prompt.NewService(conn).Build(cmd.Context(), app.ModuleRoot, args[0], prompt.Options{
+				Budget: budget,
+				Branch: currentBranch(cmd.Context(), app.ModuleRoot),
+			})
+			if errors.Is(err, prompt.ErrNoKeywords) {
+				return fail("invalid_input", err.Error(), map[string]any{"task": args[0]})
+			}
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+
+			app.applyPathMode(&bundle)
+			if jsonOut {
+				return writeJSON(bundle)
+			}
+			fmt.Print(prompt.Render(bundle))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().IntVar(&budget, "budget", prompt.DefaultBudget, "Approximate token budget for the bundle")
+	return cmd
+}
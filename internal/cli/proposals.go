@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/robertguss/recon/internal/constraint"
+	"github.com/robertguss/recon/internal/edge"
+	"github.com/robertguss/recon/internal/knowledge"
+	"github.com/robertguss/recon/internal/pattern"
+	"github.com/spf13/cobra"
+)
+
+// proposalRetryPayload is the outcome of `recon proposals --retry`. EntityID
+// is the promoted decision, pattern, or constraint.
+type proposalRetryPayload struct {
+	ProposalID          int64  `json:"proposal_id"`
+	EntityType          string `json:"entity_type"`
+	EntityID            int64  `json:"entity_id,omitempty"`
+	Promoted            bool   `json:"promoted"`
+	VerificationPassed  bool   `json:"verification_passed"`
+	VerificationDetails string `json:"verification_details"`
+}
+
+func newProposalsCommand(app *App) *cobra.Command {
+	var (
+		jsonOut   bool
+		list      bool
+		retryID   int64
+		discardID int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "proposals",
+		Short: "Review decisions, patterns, and constraints whose evidence check failed",
+		Long: "A decision, pattern, or constraint whose evidence check fails is kept as a pending\n" +
+			"proposal instead of being promoted. List them, re-run a proposal's check with --retry\n" +
+			"once the code has changed, promoting it if the check now passes, or drop it with --discard.",
+		Example: "  recon proposals\n  recon proposals --retry 4\n  recon proposals --discard 4 --json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modes := 0
+			for _, set := range []bool{list, retryID != 0, discardID != 0} {
+				if set {
+					modes++
+				}
+			}
+			if modes > 1 {
+				msg := "use only one of --list, --retry, and --discard"
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, nil)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+			svc := knowledge.NewService(conn)
+
+			switch {
+			case retryID != 0:
+				result, err := retryProposal(cmd.Context(), conn, app.ModuleRoot, retryID)
+				if err != nil {
+					return proposalError(jsonOut, retryID, err)
+				}
+				if jsonOut {
+					if !result.VerificationPassed {
+						_ = writeJSONError(classifyDecideMessage(result.VerificationDetails), result.VerificationDetails, map[string]any{
+							"proposal_id": result.ProposalID,
+							"entity_type": result.EntityType,
+						})
+						return ExitError{Code: 2}
+					}
+					return writeJSON(result)
+				}
+				if result.Promoted {
+					fmt.Printf("Proposal %d promoted: %s=%d\n", result.ProposalID, result.EntityType, result.EntityID)
+				} else {
+					fmt.Printf("Proposal %d still pending\n", result.ProposalID)
+				}
+				fmt.Printf("Verification: passed=%v details=%s\n", result.VerificationPassed, result.VerificationDetails)
+				if !result.VerificationPassed {
+					return ExitError{Code: 2}
+				}
+				return nil
+
+			case discardID != 0:
+				if err := svc.DiscardProposal(cmd.Context(), discardID); err != nil {
+					return proposalError(jsonOut, discardID, err)
+				}
+				if jsonOut {
+					return writeJSON(map[string]any{"discarded": true, "proposal_id": discardID})
+				}
+				fmt.Printf("Discarded proposal %d\n", discardID)
+				return nil
+			}
+
+			proposals, err := svc.ListProposals(cmd.Context())
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+			if jsonOut {
+				return writeJSON(proposals)
+			}
+			if len(proposals) == 0 {
+				fmt.Println("No pending proposals.")
+				return nil
+			}
+			for _, p := range proposals {
+				checked := p.VerifiedAt
+				if checked == "" {
+					checked = p.ProposedAt
+				}
+				fmt.Printf("#%d %s %q [%s] checked %s\n", p.ID, p.EntityType, p.Title, p.CheckType, checked)
+				if p.Details != "" {
+					fmt.Printf("  %s\n", p.Details)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().BoolVar(&list, "list", false, "List pending proposals (the default)")
+	cmd.Flags().Int64Var(&retryID, "retry", 0, "Re-run a proposal's evidence check and promote it if it passes")
+	cmd.Flags().Int64Var(&discardID, "discard", 0, "Discard a pending proposal by ID")
+	return cmd
+}
+
+// retryProposal re-verifies a pending proposal with the service that owns its
+// entity type and, on promotion, links the new entity to the code its text
+// names, as proposing it would have.
+func retryProposal(ctx context.Context, conn *sql.DB, moduleRoot string, id int64) (proposalRetryPayload, error) {
+	p, err := knowledge.NewService(conn).PendingProposal(ctx, id)
+	if err != nil {
+		return proposalRetryPayload{}, err
+	}
+	out := proposalRetryPayload{ProposalID: id, EntityType: p.EntityType}
+	switch p.EntityType {
+	case "decision":
+		r, err := knowledge.NewService(conn).RetryDecisionProposal(ctx, id, moduleRoot)
+		if err != nil {
+			return proposalRetryPayload{}, err
+		}
+		out.EntityID, out.Promoted, out.VerificationPassed, out.VerificationDetails = r.DecisionID, r.Promoted, r.VerificationPassed, r.VerificationDetails
+	case "pattern":
+		r, err := pattern.NewService(conn).RetryPatternProposal(ctx, id, moduleRoot)
+		if err != nil {
+			return proposalRetryPayload{}, err
+		}
+		out.EntityID, out.Promoted, out.VerificationPassed, out.VerificationDetails = r.PatternID, r.Promoted, r.VerificationPassed, r.VerificationDetails
+	case "constraint":
+		r, err := constraint.NewService(conn).RetryConstraintProposal(ctx, id, moduleRoot)
+		if err != nil {
+			return proposalRetryPayload{}, err
+		}
+		out.EntityID, out.Promoted, out.VerificationPassed, out.VerificationDetails = r.ConstraintID, r.Promoted, r.VerificationPassed, r.VerificationDetails
+	default:
+		return proposalRetryPayload{}, fmt.Errorf("proposal %d has unsupported entity type %q", id, p.EntityType)
+	}
+
+	if out.Promoted {
+		edgeSvc := edge.NewService(conn)
+		for _, d := range edge.NewAutoLinker(conn).Detect(ctx, out.EntityType, out.EntityID, p.Title, p.Reasoning+p.Description) {
+			edgeSvc.Create(ctx, edge.CreateInput{
+				FromType: out.EntityType, FromID: out.EntityID,
+				ToType: d.ToType, ToRef: d.ToRef, Relation: d.Relation,
+				Source: "auto", Confidence: "medium",
+			})
+		}
+	}
+	return out, nil
+}
+
+func proposalError(jsonOut bool, id int64, err error) error {
+	if errors.Is(err, knowledge.ErrNotFound) {
+		msg := fmt.Sprintf("pending proposal %d not found; see `recon proposals --list`", id)
+		if jsonOut {
+			_ = writeJSONError("not_found", msg, map[string]any{"proposal_id": id})
+			return ExitError{Code: 2}
+		}
+		return ExitError{Code: 2, Message: msg}
+	}
+	if jsonOut {
+		_ = writeJSONError("internal_error", err.Error(), map[string]any{"proposal_id": id})
+		return ExitError{Code: 2}
+	}
+	return err
+}
@@ -1,25 +1,102 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/robertguss/recon/internal/config"
+	"github.com/robertguss/recon/internal/digest"
+	"github.com/robertguss/recon/internal/embed"
 	"github.com/robertguss/recon/internal/recall"
+	"github.com/robertguss/recon/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
 func newRecallCommand(app *App) *cobra.Command {
 	var (
-		jsonOut    bool
-		limit      int
-		kindFilter string
+		jsonOut       bool
+		limit         int
+		minRank       float64
+		kindFilter    string
+		since         string
+		before        string
+		updatedSince  string
+		updatedBefore string
+		semantic      bool
+		format        string
+		allRepos      bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "recall <query>",
+		Use:   "recall [query]",
 		Short: "Search promoted knowledge",
-		Args:  cobra.MaximumNArgs(1),
+		Long: "Search active decisions and patterns by text, best match first: bm25 relevance\n" +
+			"with titles weighted above reasoning and evidence, shown with a snippet of the\n" +
+			"match. Ranks run from 1 for the best match down toward 0; --min-rank 0.5 drops\n" +
+			"matches less than half as relevant as the best.\n\n" +
+			"--since and --before bound when an item was recorded, --updated-since and\n" +
+			"--updated-before when it last changed; each takes 7d, 2w, 36h, YYYY-MM-DD, or\n" +
+			"RFC 3339. With a time bound the query may be omitted to list everything in the\n" +
+			"window.\n\n" +
+			"--semantic ranks knowledge and documented symbols by embedding similarity to the\n" +
+			"query instead, using the vectors `recon embed` stored. Without embeddings configured\n" +
+			"or stored, it falls back to text search and says why.",
+		Example: "  recon recall \"error handling\"\n  recon recall sqlite --min-rank 0.5\n  recon recall --since 14d\n  recon recall cobra --since 2026-09-01 --before 2026-10-01",
+		Args:    cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
+			outFormat, err := parseFormat(format, "text", "ndjson")
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"format": strings.TrimSpace(format)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+			// NDJSON reports errors as --json does.
+			jsonOut = jsonOut || outFormat == "ndjson"
+
+			if minRank < 0 || minRank > 1 {
+				msg := "--min-rank must be between 0 and 1"
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, map[string]any{"min_rank": minRank})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			now := time.Now()
+			opts := recall.RecallOptions{Limit: limit, Kind: kindFilter, MinRank: minRank}
+			bounded := false
+			for _, bound := range []struct {
+				flag  string
+				value string
+				dst   *time.Time
+			}{
+				{"--since", since, &opts.CreatedSince},
+				{"--before", before, &opts.CreatedBefore},
+				{"--updated-since", updatedSince, &opts.UpdatedSince},
+				{"--updated-before", updatedBefore, &opts.UpdatedBefore},
+			} {
+				value := strings.TrimSpace(bound.value)
+				if value == "" {
+					continue
+				}
+				t, err := digest.ParseTimeFlag(bound.flag, value, now)
+				if err != nil {
+					if jsonOut {
+						_ = writeJSONError("invalid_input", err.Error(), map[string]any{strings.TrimPrefix(bound.flag, "--"): value})
+						return ExitError{Code: 2}
+					}
+					return ExitError{Code: 2, Message: err.Error()}
+				}
+				*bound.dst = t
+				bounded = true
+			}
+
+			if len(args) == 0 && (!bounded || semantic) {
 				msg := "recall requires a <query> argument"
 				if jsonOut {
 					_ = writeJSONError("missing_argument", msg, map[string]any{"command": "recall"})
@@ -27,7 +104,21 @@ func newRecallCommand(app *App) *cobra.Command {
 				}
 				return ExitError{Code: 2, Message: msg}
 			}
-			query := args[0]
+			query := ""
+			if len(args) > 0 {
+				query = args[0]
+			}
+			if allRepos {
+				if semantic {
+					msg := "--all-repos does not support --semantic"
+					if jsonOut {
+						_ = writeJSONError("invalid_input", msg, map[string]any{"flag": "all_repos"})
+						return ExitError{Code: 2}
+					}
+					return ExitError{Code: 2, Message: msg}
+				}
+				return runRecallAllRepos(cmd, query, opts, outFormat, jsonOut)
+			}
 
 			conn, err := openExistingDB(app)
 			if err != nil {
@@ -38,7 +129,27 @@ func newRecallCommand(app *App) *cobra.Command {
 			}
 			defer conn.Close()
 
-			result, err := recall.NewService(conn).Recall(cmd.Context(), query, recall.RecallOptions{Limit: limit, Kind: kindFilter})
+			opts.Branch = currentBranch(cmd.Context(), app.ModuleRoot)
+			svc := recall.NewService(conn)
+			var result recall.Result
+			if semantic {
+				provider, fallback, cfgErr := semanticProvider(app)
+				switch {
+				case cfgErr != nil:
+					if jsonOut {
+						_ = writeJSONError("invalid_input", cfgErr.Error(), map[string]any{"path": config.Path(app.ModuleRoot)})
+						return ExitError{Code: 2}
+					}
+					return ExitError{Code: 2, Message: cfgErr.Error()}
+				case provider == nil:
+					result, err = svc.Recall(cmd.Context(), query, opts)
+					result.Mode, result.Fallback = recall.ModeFTS, fallback
+				default:
+					result, err = svc.RecallSemantic(cmd.Context(), query, provider, opts)
+				}
+			} else {
+				result, err = svc.Recall(cmd.Context(), query, opts)
+			}
 			if err != nil {
 				if jsonOut {
 					return exitJSONCommandError(err)
@@ -46,33 +157,166 @@ func newRecallCommand(app *App) *cobra.Command {
 				return err
 			}
 
-			if jsonOut {
+			if outFormat == "text" && jsonOut {
 				return writeJSON(result)
 			}
-
-			if len(result.Items) == 0 {
-				fmt.Println("No promoted knowledge found.")
-				return nil
+			// NDJSON records are items, so the fallback is reported here.
+			if result.Fallback != "" {
+				fmt.Fprintf(os.Stderr, "Warning: semantic recall unavailable (%s); using text search\n", result.Fallback)
 			}
-			for _, item := range result.Items {
-				id := item.DecisionID
-				label := "decision"
-				if item.EntityType == "pattern" {
-					id = item.PatternID
-					label = "pattern"
-				}
-				fmt.Printf("- [%s] #%d %s [%s] drift=%s\n", label, id, item.Title, item.Confidence, item.EvidenceDrift)
-				fmt.Printf("  %s\n", item.EvidenceSummary)
-				for _, ce := range item.ConnectedEdges {
-					fmt.Printf("    %s: %s (%s)\n", ce.Relation, ce.ToRef, ce.ToType)
+			if outFormat == "ndjson" {
+				out := newNDJSONWriter(result, "items[]")
+				for _, item := range result.Items {
+					if err := out.Write(item); err != nil {
+						return err
+					}
 				}
+				return nil
 			}
+			printRecallItems(result.Items)
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
 	cmd.Flags().IntVar(&limit, "limit", 10, "Maximum results")
-	cmd.Flags().StringVar(&kindFilter, "kind", "", "Filter by entity type: decision, pattern")
+	cmd.Flags().Float64Var(&minRank, "min-rank", 0, "Drop text matches ranked below this fraction of the best match, 0 to 1")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text, or ndjson for one item per line")
+	cmd.Flags().StringVar(&kindFilter, "kind", "", "Filter by entity type: decision, pattern, constraint (or symbol with --semantic)")
+	cmd.Flags().StringVar(&since, "since", "", "Only items recorded at or after: 7d, 2w, 36h, YYYY-MM-DD, or RFC 3339")
+	cmd.Flags().StringVar(&before, "before", "", "Only items recorded before this time")
+	cmd.Flags().StringVar(&updatedSince, "updated-since", "", "Only items last updated at or after this time")
+	cmd.Flags().StringVar(&updatedBefore, "updated-before", "", "Only items last updated before this time")
+	cmd.Flags().BoolVar(&semantic, "semantic", false, "Rank by embedding similarity (see recon embed), falling back to text search")
+	cmd.Flags().BoolVar(&allRepos, "all-repos", false, "Search every registered repo (see recon repos) instead of the current one")
 	return cmd
 }
+
+func printRecallItems(items []recall.Item) {
+	if len(items) == 0 {
+		fmt.Println("No promoted knowledge found.")
+		return
+	}
+	for _, item := range items {
+		score := ""
+		if item.Score != 0 {
+			score = fmt.Sprintf(" score=%.2f", item.Score)
+		}
+		if item.Rank != 0 {
+			score += fmt.Sprintf(" rank=%.2f", item.Rank)
+		}
+		if item.EntityType == embed.TypeSymbol {
+			fmt.Printf("- [symbol] %s (%s:%d)%s\n", item.Title, item.FilePath, item.Line, score)
+			continue
+		}
+		id := item.DecisionID
+		label := "decision"
+		switch item.EntityType {
+		case "pattern":
+			id = item.PatternID
+			label = "pattern"
+		case "constraint":
+			id = item.ConstraintID
+			label = "constraint"
+		}
+		fmt.Printf("- [%s] #%d %s [%s] drift=%s%s\n", label, id, item.Title, item.Confidence, item.EvidenceDrift, score)
+		if item.Snippet != "" {
+			fmt.Printf("  > %s\n", strings.Join(strings.Fields(item.Snippet), " "))
+		}
+		fmt.Printf("  %s\n", item.EvidenceSummary)
+		for _, ce := range item.ConnectedEdges {
+			fmt.Printf("    %s: %s (%s)\n", ce.Relation, ce.ToRef, ce.ToType)
+		}
+	}
+}
+
+// repoRecall is one registered repo's part of `recon recall --all-repos`.
+type repoRecall struct {
+	Repo  string        `json:"repo"`
+	Root  string        `json:"root"`
+	Items []recall.Item `json:"items"`
+	Error string        `json:"error,omitempty"`
+}
+
+// allReposRecallPayload is the payload of `recon recall --all-repos --json`.
+type allReposRecallPayload struct {
+	Query string       `json:"query"`
+	Repos []repoRecall `json:"repos"`
+}
+
+// repoRecallItem is a recall item tagged with its repo, as --all-repos
+// prints it in NDJSON.
+type repoRecallItem struct {
+	Repo string `json:"repo"`
+	recall.Item
+}
+
+// runRecallAllRepos recalls from every registered repo, each with its own
+// branch scope and opts.Limit. Like the workspace batch commands, a repo that
+// fails is reported without stopping the others, and makes the exit code 2.
+func runRecallAllRepos(cmd *cobra.Command, query string, opts recall.RecallOptions, outFormat string, jsonOut bool) error {
+	_, reg, err := loadWorkspace()
+	if err != nil {
+		return workspaceError(jsonOut, err)
+	}
+
+	reports := workspace.Run(cmd.Context(), reg.Repos, 4, func(ctx context.Context, root string) (any, error) {
+		conn, err := openExistingDB(&App{ModuleRoot: root})
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+
+		repoOpts := opts
+		repoOpts.Branch = currentBranch(ctx, root)
+		return recall.NewService(conn).Recall(ctx, query, repoOpts)
+	})
+	payload := allReposRecallPayload{Query: query, Repos: make([]repoRecall, len(reports))}
+	failed := false
+	for i, r := range reports {
+		payload.Repos[i] = repoRecall{Repo: reg.Name(r.Root), Root: r.Root, Items: []recall.Item{}, Error: r.Error}
+		if !r.OK {
+			failed = true
+			continue
+		}
+		payload.Repos[i].Items = r.Result.(recall.Result).Items
+	}
+
+	switch {
+	case outFormat == "ndjson":
+		out := newNDJSONWriter(payload, "repos[].items[]")
+		for _, repo := range payload.Repos {
+			if repo.Error != "" {
+				fmt.Fprintf(os.Stderr, "Warning: recall in %s failed: %s\n", repo.Repo, repo.Error)
+			}
+			for _, item := range repo.Items {
+				if err := out.Write(repoRecallItem{Repo: repo.Repo, Item: item}); err != nil {
+					return err
+				}
+			}
+		}
+	case jsonOut:
+		if err := writeJSON(payload); err != nil {
+			return err
+		}
+	default:
+		if len(payload.Repos) == 0 {
+			fmt.Println("No repositories registered; run `recon repos add` in one.")
+		}
+		for i, repo := range payload.Repos {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("== %s (%s)\n", repo.Repo, repo.Root)
+			if repo.Error != "" {
+				fmt.Printf("FAIL  %s\n", repo.Error)
+				continue
+			}
+			printRecallItems(repo.Items)
+		}
+	}
+	if failed {
+		return ExitError{Code: 2}
+	}
+	return nil
+}
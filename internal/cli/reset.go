@@ -46,6 +46,11 @@ func newResetCommand(app *App) *cobra.Command {
 				}
 				return fmt.Errorf("delete database: %w", err)
 			}
+			// Leftover write-ahead log files would otherwise sit beside the
+			// database init creates next.
+			for _, suffix := range []string{"-wal", "-shm"} {
+				_ = os.Remove(path + suffix)
+			}
 
 			if jsonOut {
 				return writeJSON(map[string]any{"reset": true, "path": path})
@@ -2,22 +2,50 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/robertguss/recon/internal/index"
+	"github.com/robertguss/recon/internal/logging"
 	"github.com/spf13/cobra"
 )
 
 var (
 	osGetwd        = os.Getwd
 	findModuleRoot = index.FindModuleRoot
+	currentBranch  = index.CurrentBranch
 )
 
 type App struct {
 	Context    context.Context
 	ModuleRoot string
 	NoPrompt   bool
+	// Yes answers yes to confirmations, such as migrating a database whose
+	// schema is older than this build's.
+	Yes bool
+	// AbsPaths and RelPaths print file paths absolute or relative to
+	// ModuleRoot; with neither, each command keeps its usual form.
+	AbsPaths bool
+	RelPaths bool
+	// ExecTimeout limits each git subprocess a command runs; zero disables
+	// the limit.
+	ExecTimeout time.Duration
+	// OutputVersion selects the shape of --json output; see outputVersion.
+	OutputVersion int
+	// LogLevel and LogFile configure the structured debug log; see
+	// configureLogging.
+	LogLevel string
+	LogFile  bool
+	// Trace, from the hidden --trace flag, profiles the command: "-" prints
+	// the phase timings to stderr, anything else is a JSON file to write.
+	Trace string
 }
 
 func NewRootCommand(ctx context.Context) (*cobra.Command, error) {
@@ -38,20 +66,164 @@ func NewRootCommand(ctx context.Context) (*cobra.Command, error) {
 		Short:         "Recon is a code intelligence and knowledge CLI for Go repositories",
 		SilenceUsage:  true,
 		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := setOutputVersion(app.OutputVersion, cmd.Flags().Changed("output-version")); err != nil {
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+			if err := configureLogging(app, cmd, args); err != nil {
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+			cmd.SetContext(index.WithExecTimeout(cmd.Context(), app.ExecTimeout))
+			return nil
+		},
+	}
+	logFileDefault, _ := strconv.ParseBool(os.Getenv(logging.FileEnv))
+	traceDefault := os.Getenv(logging.TraceEnv)
+	// RECON_TRACE=1 prints to stderr, like a bare --trace.
+	if on, err := strconv.ParseBool(traceDefault); err == nil {
+		traceDefault = ""
+		if on {
+			traceDefault = "-"
+		}
 	}
 	root.PersistentFlags().BoolVar(&app.NoPrompt, "no-prompt", false, "Disable interactive prompts globally")
+	root.PersistentFlags().BoolVar(&app.Yes, "yes", false, "Answer yes to confirmations, such as migrating an out-of-date database")
+	root.PersistentFlags().BoolVar(&app.AbsPaths, "abs-paths", false, "Print file paths as absolute paths")
+	root.PersistentFlags().BoolVar(&app.RelPaths, "rel-paths", false, "Print file paths relative to the module root")
+	root.PersistentFlags().DurationVar(&app.ExecTimeout, "exec-timeout", index.DefaultExecTimeout, "Time limit for each git call (0 disables)")
+	root.PersistentFlags().IntVar(&app.OutputVersion, "output-version", legacyOutputVersion, "Shape of --json output: 1 prints the payload, 2 wraps it in {schema_version, data}")
+	root.PersistentFlags().StringVar(&app.LogLevel, "log-level", os.Getenv(logging.LevelEnv), "Structured log level: off, error, warn, info, or debug (default $"+logging.LevelEnv+", else off)")
+	root.PersistentFlags().BoolVar(&app.LogFile, "log-file", logFileDefault, "Append the log to .recon/"+logging.FileName+" instead of stderr, at info level unless --log-level is set (default $"+logging.FileEnv+")")
+	root.PersistentFlags().StringVar(&app.Trace, "trace", traceDefault, "Profile the command: print phase timings to stderr, or with =FILE write them as JSON (default $"+logging.TraceEnv+")")
+	root.PersistentFlags().Lookup("trace").NoOptDefVal = "-"
+	_ = root.PersistentFlags().MarkHidden("trace")
+	root.MarkFlagsMutuallyExclusive("abs-paths", "rel-paths")
+	registerTraceFinalizer.Do(func() { cobra.OnFinalize(finishTrace) })
 
 	root.AddCommand(newInitCommand(app))
 	root.AddCommand(newSyncCommand(app))
 	root.AddCommand(newOrientCommand(app))
 	root.AddCommand(newFindCommand(app))
+	root.AddCommand(newExplainSymbolCommand(app))
+	root.AddCommand(newOpenCommand(app))
+	root.AddCommand(newHistoryCommand(app))
+	root.AddCommand(newSnippetsCommand(app))
+	root.AddCommand(newAgentsMDCommand(app))
+	root.AddCommand(newOnboardingCommand(app))
+	root.AddCommand(newPromptCommand(app))
 	root.AddCommand(newDecideCommand(app))
 	root.AddCommand(newPatternCommand(app))
+	root.AddCommand(newConstrainCommand(app))
 	root.AddCommand(newRecallCommand(app))
+	root.AddCommand(newEmbedCommand(app))
+	root.AddCommand(newWhyCommand(app))
 	root.AddCommand(newStatusCommand(app))
+	root.AddCommand(newVerifyCommand(app))
+	root.AddCommand(newProposalsCommand(app))
+	root.AddCommand(newAuditKnowledgeCommand(app))
 	root.AddCommand(newEdgesCommand(app))
+	root.AddCommand(newDigestCommand(app))
+	root.AddCommand(newGuardCommand(app))
+	root.AddCommand(newOwnersCommand(app))
+	root.AddCommand(newTodosCommand(app))
+	root.AddCommand(newAPICommand(app))
+	root.AddCommand(newSemverCommand(app))
+	root.AddCommand(newCICommand(app))
+	root.AddCommand(newInstallCommand(app))
+	root.AddCommand(newDiagnosticsCommand(app))
+	root.AddCommand(newCoverageCommand(app))
+	root.AddCommand(newLintArchCommand(app))
+	root.AddCommand(newDepsCommand(app))
+	root.AddCommand(newDebugBundleCommand(app))
+	root.AddCommand(newDoctorCommand(app))
+	root.AddCommand(newServeCommand(app))
+	root.AddCommand(newWorkspaceCommand(app))
+	root.AddCommand(newSchemaCommand())
+	root.AddCommand(newCompletionCommand())
 	root.AddCommand(newVersionCommand())
 	root.AddCommand(newResetCommand(app))
+	root.AddCommand(newSnapshotCommand(app))
+	root.AddCommand(newMigrateCommand(app))
+	root.AddCommand(newMergeCommand(app))
 
 	return root, nil
 }
+
+// logFile is the log file the last command opened. It stays open while the
+// command runs and is closed when the next command configures logging.
+var logFile *os.File
+
+// configureLogging installs the default slog logger that the index, orient,
+// and knowledge packages write to, tagging each record with the process and
+// command so a shared .recon/recon.log can be untangled.
+func configureLogging(app *App, cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	if logFile != nil {
+		logFile.Close()
+		logFile = nil
+	}
+	level := app.LogLevel
+	if level == "" && app.LogFile {
+		level = "info"
+	}
+	_, on, err := logging.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stderr)
+	if on && app.LogFile {
+		f, err := logging.OpenFile(filepath.Join(app.ModuleRoot, ".recon"))
+		if err != nil {
+			return err
+		}
+		logFile, w = f, f
+	}
+	logger, err := logging.New(level, w)
+	if err != nil {
+		return err
+	}
+	trace = nil
+	if app.Trace != "" {
+		trace = &traceRun{profile: logging.NewProfile(start), dest: app.Trace, command: cmd.CommandPath(), args: args}
+		logger = slog.New(trace.profile.Handler(logger.Handler()))
+	}
+	slog.SetDefault(logger.With("pid", os.Getpid(), "command", cmd.CommandPath()))
+	slog.InfoContext(cmd.Context(), "command", "args", args, "module_root", app.ModuleRoot, "version", Version)
+	return nil
+}
+
+// traceRun is the profile --trace is collecting for the running command.
+type traceRun struct {
+	profile *logging.Profile
+	dest    string
+	command string
+	args    []string
+}
+
+var (
+	trace                  *traceRun
+	registerTraceFinalizer sync.Once
+)
+
+// finishTrace reports the profile of a traced command once it has run,
+// whether or not it failed.
+func finishTrace() {
+	run := trace
+	if run == nil {
+		return
+	}
+	trace = nil
+	report := run.profile.Report(run.command, run.args, time.Now())
+	if run.dest == "-" {
+		report.WriteText(os.Stderr)
+		return
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		err = os.WriteFile(run.dest, append(data, '\n'), 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: write trace: %v\n", err)
+	}
+}
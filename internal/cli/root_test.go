@@ -28,8 +28,8 @@ func TestNewRootCommandAndErrors(t *testing.T) {
 	if cmd.Use != "recon" {
 		t.Fatalf("unexpected root use: %q", cmd.Use)
 	}
-	if len(cmd.Commands()) != 11 {
-		t.Fatalf("expected 11 subcommands, got %d", len(cmd.Commands()))
+	if len(cmd.Commands()) != 45 {
+		t.Fatalf("expected 45 subcommands, got %d", len(cmd.Commands()))
 	}
 
 	osGetwd = func() (string, error) { return "", errors.New("cwd fail") }
@@ -66,3 +66,14 @@ func TestNewRootCommandFallbackModuleRoot(t *testing.T) {
 		t.Fatalf("expected fallback cwd module_root in output, got %q", out)
 	}
 }
+
+func TestPathModeFlagsAreExclusive(t *testing.T) {
+	cmd, err := NewRootCommand(context.Background())
+	if err != nil {
+		t.Fatalf("NewRootCommand: %v", err)
+	}
+	_, _, err = runCommandWithCapture(t, cmd, []string{"--abs-paths", "--rel-paths", "version"})
+	if err == nil || !strings.Contains(err.Error(), "none of the others can be") {
+		t.Fatalf("expected exclusive flag error, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/robertguss/recon/internal/archlint"
+	"github.com/robertguss/recon/internal/audit"
+	"github.com/robertguss/recon/internal/constraint"
+	"github.com/robertguss/recon/internal/coverage"
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/deps"
+	"github.com/robertguss/recon/internal/diagnostics"
+	"github.com/robertguss/recon/internal/digest"
+	"github.com/robertguss/recon/internal/doctor"
+	"github.com/robertguss/recon/internal/edge"
+	"github.com/robertguss/recon/internal/embed"
+	"github.com/robertguss/recon/internal/explain"
+	"github.com/robertguss/recon/internal/find"
+	"github.com/robertguss/recon/internal/guard"
+	"github.com/robertguss/recon/internal/knowledge"
+	"github.com/robertguss/recon/internal/merge"
+	"github.com/robertguss/recon/internal/orient"
+	"github.com/robertguss/recon/internal/pattern"
+	"github.com/robertguss/recon/internal/prompt"
+	"github.com/robertguss/recon/internal/recall"
+	"github.com/robertguss/recon/internal/schema"
+	"github.com/robertguss/recon/internal/snapshot"
+	"github.com/robertguss/recon/internal/snippet"
+	"github.com/robertguss/recon/internal/why"
+	"github.com/spf13/cobra"
+)
+
+// jsonPayloads lists the typed --json payloads that make up recon's public
+// contract. Commands that answer with ad hoc maps (init, reset, version,
+// archive/delete confirmations) are not included.
+var jsonPayloads = []schema.Type{
+	{Name: "ErrorEnvelope", Doc: "ErrorEnvelope is printed by every --json command that fails.", Value: jsonErrorEnvelope{}},
+	{Name: "ErrorBody", Doc: "ErrorBody carries the error code, message, and optional details.", Value: jsonErrorBody{}},
+	{Name: "SyncPayload", Doc: "SyncPayload is the payload of `recon sync --json`.", Value: syncPayload{}},
+	{Name: "OrientPayload", Doc: "OrientPayload is the payload of `recon orient --json`.", Value: orient.Payload{}},
+	{Name: "OnboardingPayload", Doc: "OnboardingPayload is the payload of `recon onboarding --json`.", Value: orient.Onboarding{}},
+	{Name: "StatusPayload", Doc: "StatusPayload is the payload of `recon status --json`.", Value: statusPayload{}},
+	{Name: "VerifyPayload", Doc: "VerifyPayload is the payload of `recon verify --json`.", Value: verifyPayload{}},
+	{Name: "Proposal", Doc: "Proposal is an element of `recon proposals --json`.", Value: knowledge.Proposal{}},
+	{Name: "ProposalRetryPayload", Doc: "ProposalRetryPayload is the payload of `recon proposals --retry --json`.", Value: proposalRetryPayload{}},
+	{Name: "AuditReport", Doc: "AuditReport is the payload of `recon audit-knowledge --json`.", Value: audit.Report{}},
+	{Name: "VerifyIntervalPayload", Doc: "VerifyIntervalPayload is the payload of `recon verify --set-interval --json`.", Value: verifyIntervalPayload{}},
+	{Name: "FindResult", Doc: "FindResult is the payload of `recon find <symbol> --json`.", Value: find.Result{}},
+	{Name: "ExplainSymbolResult", Doc: "ExplainSymbolResult is the payload of `recon explain-symbol --json`.", Value: explain.Explanation{}},
+	{Name: "OpenResult", Doc: "OpenResult is the payload of `recon open --json`.", Value: openResult{}},
+	{Name: "HistoryPayload", Doc: "HistoryPayload is the payload of `recon history --json`.", Value: historyPayload{}},
+	{Name: "FindListResult", Doc: "FindListResult is the payload of `recon find --json` in list mode.", Value: find.ListResult{}},
+	{Name: "PackageSummary", Doc: "PackageSummary is an element of `recon find --list-packages --json`.", Value: find.PackageSummary{}},
+	{Name: "ImportResult", Doc: "ImportResult is an element of `recon find --imports-of/--imported-by --json`.", Value: find.ImportResult{}},
+	{Name: "SnippetsResult", Doc: "SnippetsResult is the payload of `recon snippets --json`.", Value: snippet.Result{}},
+	{Name: "RecallResult", Doc: "RecallResult is the payload of `recon recall --json`.", Value: recall.Result{}},
+	{Name: "RecallAllReposResult", Doc: "RecallAllReposResult is the payload of `recon recall --all-repos --json`.", Value: allReposRecallPayload{}},
+	{Name: "EmbedResult", Doc: "EmbedResult is the payload of `recon embed --json`.", Value: embed.Result{}},
+	{Name: "WhyResult", Doc: "WhyResult is the payload of `recon why --json`.", Value: why.Result{}},
+	{Name: "PromptBundle", Doc: "PromptBundle is the payload of `recon prompt --json`.", Value: prompt.Bundle{}},
+	{Name: "DecisionListItem", Doc: "DecisionListItem is an element of `recon decide --list --json`.", Value: knowledge.DecisionListItem{}},
+	{Name: "ProposeDecisionResult", Doc: "ProposeDecisionResult is the payload of `recon decide --json`.", Value: knowledge.ProposeDecisionResult{}},
+	{Name: "ArchiveImpact", Doc: "ArchiveImpact is the impact of `recon decide --archive --json`, also sent as details.impact with confirmation_required.", Value: knowledge.ArchiveImpact{}},
+	{Name: "PatternListItem", Doc: "PatternListItem is an element of `recon pattern --list --json`.", Value: pattern.PatternListItem{}},
+	{Name: "ProposePatternResult", Doc: "ProposePatternResult is the payload of `recon pattern --json`.", Value: pattern.ProposePatternResult{}},
+	{Name: "ConstraintListItem", Doc: "ConstraintListItem is an element of `recon constrain --list --json`.", Value: constraint.ConstraintListItem{}},
+	{Name: "ProposeConstraintResult", Doc: "ProposeConstraintResult is the payload of `recon constrain --json`.", Value: constraint.ProposeConstraintResult{}},
+	{Name: "Edge", Doc: "Edge is the payload of `recon edges --create --json`.", Value: edge.Edge{}},
+	{Name: "EdgeWithTitle", Doc: "EdgeWithTitle is an element of `recon edges --from/--to/--list --json`.", Value: edge.EdgeWithTitle{}},
+	{Name: "Digest", Doc: "Digest is the payload of `recon digest --json`.", Value: digest.Digest{}},
+	{Name: "GuardResult", Doc: "GuardResult is the payload of `recon guard --json`.", Value: guard.Result{}},
+	{Name: "DiagnosticsFile", Doc: "DiagnosticsFile is an element of `recon diagnostics --json`.", Value: diagnostics.File{}},
+	{Name: "CoverageImportResult", Doc: "CoverageImportResult is the payload of `recon coverage import --json`.", Value: coverage.ImportResult{}},
+	{Name: "DepsResult", Doc: "DepsResult is the payload of `recon deps --json`.", Value: deps.Result{}},
+	{Name: "DepsInventory", Doc: "DepsInventory is the payload of `recon deps --external --json`.", Value: deps.Inventory{}},
+	{Name: "LintArchResult", Doc: "LintArchResult is the payload of `recon lint-arch --json`.", Value: archlint.Result{}},
+	{Name: "CIPayload", Doc: "CIPayload is the payload of `recon ci --json`.", Value: ciPayload{}},
+	{Name: "DebugBundlePayload", Doc: "DebugBundlePayload is the payload of `recon debug-bundle --json`.", Value: debugBundlePayload{}},
+	{Name: "DoctorReport", Doc: "DoctorReport is the payload of `recon doctor --json`.", Value: doctor.Report{}},
+	{Name: "SnapshotCreatePayload", Doc: "SnapshotCreatePayload is the payload of `recon snapshot create --json`.", Value: snapshotCreatePayload{}},
+	{Name: "Snapshot", Doc: "Snapshot is an element of `recon snapshot list --json`.", Value: snapshot.Snapshot{}},
+	{Name: "SnapshotRestorePayload", Doc: "SnapshotRestorePayload is the payload of `recon snapshot restore --json`.", Value: snapshotRestorePayload{}},
+	{Name: "MigrationStatus", Doc: "MigrationStatus is the payload of `recon migrate status --json`.", Value: db.MigrationStatus{}},
+	{Name: "MigratePayload", Doc: "MigratePayload is the payload of `recon migrate up --json` and `recon migrate down --json`.", Value: migratePayload{}},
+	{Name: "MergeResult", Doc: "MergeResult is the payload of `recon merge --json`.", Value: merge.Result{}},
+	{Name: "MergeExportPayload", Doc: "MergeExportPayload is the payload of `recon merge --export --json`.", Value: mergeExportPayload{}},
+	{Name: "Bundle", Doc: "Bundle is the file written by `recon merge --export`.", Value: merge.Bundle{}},
+}
+
+// jsonSchemaCommands maps each command `recon schema <command>` covers to the
+// jsonPayloads its --json output may be; error is the envelope of any command
+// that fails.
+var jsonSchemaCommands = map[string][]string{
+	"decide": {"ProposeDecisionResult"},
+	"error":  {"ErrorEnvelope"},
+	"find":   {"FindResult", "FindListResult"},
+	"orient": {"OrientPayload"},
+	"recall": {"RecallResult", "RecallAllReposResult"},
+	"status": {"StatusPayload"},
+	"sync":   {"SyncPayload"},
+}
+
+func jsonSchemaCommandNames() []string {
+	names := make([]string, 0, len(jsonSchemaCommands))
+	for name := range jsonSchemaCommands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// commandJSONSchema renders the JSON Schema of a command's --json output.
+func commandJSONSchema(command string) ([]byte, error) {
+	names, ok := jsonSchemaCommands[command]
+	if !ok {
+		return nil, fmt.Errorf("no JSON schema for %q; use one of: %s", command, strings.Join(jsonSchemaCommandNames(), ", "))
+	}
+	var types []schema.Type
+	for _, name := range names {
+		i := slices.IndexFunc(jsonPayloads, func(t schema.Type) bool { return t.Name == name })
+		types = append(types, jsonPayloads[i])
+	}
+	return schema.JSONSchema("recon "+command+" --json", types)
+}
+
+var currentSchema = db.CurrentSchema
+
+func newSchemaCommand() *cobra.Command {
+	var (
+		sqlOut    bool
+		goOut     bool
+		goPackage string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "schema (<command> | --sql | --go)",
+		Short: "Print the JSON Schema of a command's output, the database DDL, or Go types",
+		Long: "Print recon's stable contract for third-party tools.\n\n" +
+			"With a command name, print the JSON Schema (draft 2020-12) of its --json output:\n" +
+			strings.Join(jsonSchemaCommandNames(), ", ") + ". error is the envelope any --json command\n" +
+			"prints when it fails. --sql prints the DDL this build migrates .recon/recon.db to. --go\n" +
+			"prints Go structs that decode the --json output of each command. None needs an\n" +
+			"initialized project.",
+		Example:   "  recon schema orient\n  recon schema --sql\n  recon schema --go --package client",
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: jsonSchemaCommandNames(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				if sqlOut || goOut {
+					return ExitError{Code: 2, Message: "schema takes a command name or one of --sql or --go, not both"}
+				}
+				out, err := commandJSONSchema(args[0])
+				if err != nil {
+					return ExitError{Code: 2, Message: err.Error()}
+				}
+				_, err = os.Stdout.Write(out)
+				return err
+			}
+			if sqlOut == goOut {
+				return ExitError{Code: 2, Message: "schema requires a command name or exactly one of --sql or --go"}
+			}
+
+			if sqlOut {
+				ddl, err := currentSchema(cmd.Context())
+				if err != nil {
+					return err
+				}
+				fmt.Print(ddl)
+				return nil
+			}
+
+			if !token.IsIdentifier(goPackage) {
+				return ExitError{Code: 2, Message: fmt.Sprintf("--package %q is not a valid Go package name", goPackage)}
+			}
+			src, err := schema.GoSource(goPackage, jsonPayloads)
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(src)
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&sqlOut, "sql", false, "Print the SQLite DDL of the recon database")
+	cmd.Flags().BoolVar(&goOut, "go", false, "Print Go structs for the --json payloads")
+	cmd.Flags().StringVar(&goPackage, "package", "reconapi", "Package name for --go output")
+	return cmd
+}
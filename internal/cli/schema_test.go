@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/robertguss/recon/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+func TestPublishedJSONSchemasAreCurrent(t *testing.T) {
+	for _, command := range jsonSchemaCommandNames() {
+		want, err := commandJSONSchema(command)
+		if err != nil {
+			t.Fatalf("%s: %v", command, err)
+		}
+		path := filepath.Join("..", "..", "docs", "schemas", command+".schema.json")
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%s is out of date; regenerate it with: go run ./cmd/recon schema %s > docs/schemas/%s.schema.json", path, command, command)
+		}
+	}
+}
+
+func TestJSONOutputsMatchSchemas(t *testing.T) {
+	app := setupInitializedApp(t)
+	createTestDecision(t, app, "Use Cobra for CLI")
+
+	for _, tc := range []struct {
+		schema string
+		cmd    *cobra.Command
+		args   []string
+	}{
+		{"sync", newSyncCommand(app), []string{"--json"}},
+		{"decide", newDecideCommand(app), []string{"Keep go.mod", "--reasoning", "r", "--evidence-summary", "e", "--check-type", "file_exists", "--check-path", "go.mod", "--json"}},
+		{"orient", newOrientCommand(app), []string{"--json"}},
+		{"status", newStatusCommand(app), []string{"--json"}},
+		{"recall", newRecallCommand(app), []string{"Cobra", "--json"}},
+		{"find", newFindCommand(app), []string{"Alpha", "--json"}},
+		{"find", newFindCommand(app), []string{"--kind", "func", "--json"}},
+		{"error", newFindCommand(app), []string{"Missing", "--json"}},
+	} {
+		out, _, _ := runCommandWithCapture(t, tc.cmd, tc.args)
+		s, err := commandJSONSchema(tc.schema)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.schema, err)
+		}
+		if err := schema.Validate(s, []byte(out)); err != nil {
+			t.Fatalf("%s %v: output does not match the schema: %v\n%s", tc.schema, tc.args, err, out)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/robertguss/recon/internal/api"
+	"github.com/robertguss/recon/internal/index"
+	"github.com/spf13/cobra"
+)
+
+func newSemverCommand(app *App) *cobra.Command {
+	var (
+		jsonOut     bool
+		since       string
+		packagePath string
+		internal    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "semver",
+		Short: "Suggest the next version from the API changes since the last tag",
+		Long: "Compare the indexed exported API with the API at the latest tag reachable\n" +
+			"from HEAD, as `recon api --diff` does, and classify the release: major when an\n" +
+			"exported declaration was removed or changed, minor when one was added, and\n" +
+			"patch otherwise, or none with no commits since the tag. Before v1.0.0 breaking\n" +
+			"changes call for a minor bump. The declarations that drove the classification\n" +
+			"are listed. Run recon sync first so the index matches the working tree.",
+		Example: "  recon semver\n  recon semver --since v1.2.0 --json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fail := func(code string, err error, details map[string]any) error {
+				if jsonOut {
+					_ = writeJSONError(code, err.Error(), details)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			ref := since
+			if ref == "" {
+				if ref, err = index.LatestTag(cmd.Context(), app.ModuleRoot); err != nil {
+					return fail("not_found", errors.New("no tag is reachable from HEAD; pass --since <ref> to compare with another revision"),
+						map[string]any{"git": err.Error()})
+				}
+			}
+			commits, err := index.CommitsSince(cmd.Context(), app.ModuleRoot, ref)
+			if err != nil {
+				return fail("invalid_input", err, map[string]any{"ref": ref})
+			}
+			baseDecls, err := refExportedDecls(cmd, app.ModuleRoot, ref)
+			if err != nil {
+				return fail("invalid_input", err, map[string]any{"ref": ref})
+			}
+			decls, err := api.NewService(conn).Indexed(cmd.Context())
+			if err != nil {
+				return fail("internal_error", err, nil)
+			}
+
+			opts := api.Options{Package: normalizeFindPath(packagePath), Internal: internal}
+			advice := api.Advise(ref, commits, api.Compare(ref, api.Surface(baseDecls, opts), api.Surface(decls, opts)))
+			app.applyPathMode(&advice)
+			if jsonOut {
+				return writeJSON(advice)
+			}
+			fmt.Print(semverReport(advice))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().StringVar(&since, "since", "", "Compare with this git ref instead of the latest tag")
+	cmd.Flags().StringVar(&packagePath, "package", "", "Only consider this package, or those below it with a trailing /...")
+	cmd.Flags().BoolVar(&internal, "internal", false, "Include packages under internal directories")
+	return cmd
+}
+
+func semverReport(a api.Advice) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Since %s (%d commits): %s", a.Tag, a.Commits, a.Level)
+	if a.Next != "" {
+		fmt.Fprintf(&b, " → %s", a.Next)
+	}
+	fmt.Fprintf(&b, "\nReason: %s\n", a.Reason)
+	if len(a.Breaking) > 0 {
+		fmt.Fprintf(&b, "\nBreaking (%d):\n", len(a.Breaking))
+		for _, c := range a.Breaking {
+			if c.Change == "removed" {
+				fmt.Fprintf(&b, "- %s: removed %s\n", c.Package, c.Before)
+			} else {
+				fmt.Fprintf(&b, "- %s: changed %s\n    was: %s\n", c.Package, c.After, c.Before)
+			}
+		}
+	}
+	if len(a.Added) > 0 {
+		fmt.Fprintf(&b, "\nAdded (%d):\n", len(a.Added))
+		for _, c := range a.Added {
+			fmt.Fprintf(&b, "- %s: %s\n", c.Package, c.After)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/robertguss/recon/internal/config"
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/find"
+	"github.com/robertguss/recon/internal/orient"
+	"github.com/robertguss/recon/internal/recall"
+	"github.com/spf13/cobra"
+)
+
+var netListen = net.Listen
+
+func newServeCommand(app *App) *cobra.Command {
+	var addr string
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve read-only JSON endpoints over HTTP",
+		Long: "Serve the index over HTTP so editor plugins and dashboards can query it without spawning\n" +
+			"the CLI per request. Endpoints (GET only): /orient, /find/{symbol}, /recall?q=, /status,\n" +
+			"and /packages. Responses match the --json output of the corresponding commands; errors\n" +
+			"use the same {\"error\": {...}} envelope. /diagnostics matches `recon diagnostics --json`,\n" +
+			"and /diagnostics/stream pushes them to editors as server-sent events whenever they\n" +
+			"change. /metrics serves index health in the Prometheus text format for scraping.\n" +
+			"Nothing is written to the index.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if addr == "" {
+				return ExitError{Code: 2, Message: "serve requires --http <addr>, e.g. --http :7777"}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			cfg, err := loadConfig(app.ModuleRoot)
+			if err != nil {
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			ln, err := netListen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("listen on %s: %w", addr, err)
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			srv := &http.Server{
+				Handler:           newServeMux(app, conn, cfg),
+				ReadHeaderTimeout: 10 * time.Second,
+				// Requests inherit the command context, and with it the
+				// --exec-timeout limit on the git calls they make.
+				BaseContext: func(net.Listener) context.Context { return ctx },
+			}
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = srv.Shutdown(shutdownCtx)
+			}()
+
+			fmt.Fprintf(os.Stderr, "recon serving %s on http://%s\n", app.ModuleRoot, ln.Addr())
+			if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("serve: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "http", "", "Address to listen on, e.g. :7777 or 127.0.0.1:7777")
+	return cmd
+}
+
+// newServeMux routes the read-only endpoints of `recon serve`. Every handler
+// shares conn, so requests are answered one query at a time.
+func newServeMux(app *App, conn *sql.DB, cfg config.Config) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /orient", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		minConf := cfg.Orient.MinConfidence
+		if q.Has("min_confidence") {
+			minConf = q.Get("min_confidence")
+		}
+		switch minConf {
+		case "", "low", "medium", "high":
+		default:
+			writeHTTPError(w, http.StatusBadRequest, "invalid_input", "min_confidence must be low, medium, or high", map[string]any{"min_confidence": minConf})
+			return
+		}
+		focus := q.Get("focus")
+		payload, err := buildOrient(r.Context(), conn, orient.BuildOptions{ModuleRoot: app.ModuleRoot, Focus: focus, MinConfidence: minConf, Heat: heatPolicy(cfg.Heat)})
+		if errors.Is(err, orient.ErrFocusNotFound) {
+			writeHTTPError(w, http.StatusNotFound, "not_found", err.Error(), map[string]any{"focus": focus})
+			return
+		}
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+			return
+		}
+		app.applyPathMode(&payload)
+		writeHTTPJSON(w, http.StatusOK, payload)
+	})
+
+	mux.HandleFunc("GET /find/{symbol}", func(w http.ResponseWriter, r *http.Request) {
+		symbol := r.PathValue("symbol")
+		q := r.URL.Query()
+		opts := find.QueryOptions{
+			PackagePath: strings.TrimSpace(q.Get("package")),
+			FilePath:    normalizeFindPath(app.modulePath(q.Get("file"))),
+			Kind:        q.Get("kind"),
+		}
+		svc := find.NewService(conn)
+		result, err := svc.Find(r.Context(), symbol, opts)
+		if err != nil {
+			var notFound find.NotFoundError
+			var ambiguous find.AmbiguousError
+			switch {
+			case errors.As(err, &notFound):
+				writeHTTPError(w, http.StatusNotFound, "not_found", err.Error(), map[string]any{"symbol": symbol, "suggestions": notFound.Suggestions})
+			case errors.As(err, &ambiguous):
+				app.applyPathMode(&ambiguous.Candidates)
+				writeHTTPError(w, http.StatusConflict, "ambiguous", err.Error(), map[string]any{"symbol": symbol, "candidates": ambiguous.Candidates})
+			default:
+				writeHTTPError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+			}
+			return
+		}
+		if result.Owners, err = symbolOwners(r.Context(), conn, result.Symbol); err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+			return
+		}
+		if result.Deprecated, err = svc.Deprecation(r.Context(), result.Symbol.ID); err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+			return
+		}
+		if result.CodeLinks, err = svc.CodeLinks(r.Context(), result.Symbol); err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+			return
+		}
+		result.Coverage = enrichFindCoverage(r.Context(), conn, app.ModuleRoot, result.Symbol, heatPolicy(cfg.Heat))
+		result.Knowledge = enrichFindKnowledge(r.Context(), conn, result.Symbol)
+		app.applyPathMode(&result)
+		writeHTTPJSON(w, http.StatusOK, result)
+	})
+
+	mux.HandleFunc("GET /recall", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		query := q.Get("q")
+		if query == "" {
+			writeHTTPError(w, http.StatusBadRequest, "missing_argument", "recall requires a q parameter", map[string]any{"endpoint": "/recall"})
+			return
+		}
+		opts := recall.RecallOptions{Limit: 10, Kind: q.Get("kind")}
+		if raw := q.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit <= 0 {
+				writeHTTPError(w, http.StatusBadRequest, "invalid_input", "limit must be a positive integer", map[string]any{"limit": raw})
+				return
+			}
+			opts.Limit = limit
+		}
+		if raw := q.Get("min_rank"); raw != "" {
+			minRank, err := strconv.ParseFloat(raw, 64)
+			if err != nil || minRank < 0 || minRank > 1 {
+				writeHTTPError(w, http.StatusBadRequest, "invalid_input", "min_rank must be a number between 0 and 1", map[string]any{"min_rank": raw})
+				return
+			}
+			opts.MinRank = minRank
+		}
+		opts.Branch = currentBranch(r.Context(), app.ModuleRoot)
+		result, err := recall.NewService(conn).Recall(r.Context(), query, opts)
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+			return
+		}
+		writeHTTPJSON(w, http.StatusOK, result)
+	})
+
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		payload, err := loadStatus(r.Context(), conn)
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+			return
+		}
+		writeHTTPJSON(w, http.StatusOK, payload)
+	})
+
+	mux.HandleFunc("GET /packages", func(w http.ResponseWriter, r *http.Request) {
+		packages, err := find.NewService(conn).ListPackages(r.Context())
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+			return
+		}
+		writeHTTPJSON(w, http.StatusOK, packages)
+	})
+
+	mux.HandleFunc("GET /diagnostics", serveDiagnostics(app, conn))
+	mux.HandleFunc("GET /diagnostics/stream", serveDiagnosticsStream(app, conn))
+
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		metrics, err := loadMetrics(r.Context(), conn, db.DBPath(app.ModuleRoot), time.Now())
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = writeMetrics(w, metrics)
+	})
+
+	// The catch-all pattern matches every method, so it also answers
+	// non-GET requests to the endpoints above.
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeHTTPError(w, http.StatusMethodNotAllowed, "method_not_allowed", "recon serve is read-only; use GET", map[string]any{"method": r.Method})
+			return
+		}
+		writeHTTPError(w, http.StatusNotFound, "not_found", fmt.Sprintf("no endpoint %s", r.URL.Path), map[string]any{
+			"endpoints": []string{"/orient", "/find/{symbol}", "/recall?q=", "/status", "/packages", "/diagnostics", "/diagnostics/stream", "/metrics"},
+		})
+	})
+
+	return mux
+}
+
+func writeHTTPJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeHTTPError(w http.ResponseWriter, status int, code, message string, details any) {
+	writeHTTPJSON(w, status, jsonErrorEnvelope{Error: jsonErrorBody{Code: code, Message: message, Details: details}})
+}
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robertguss/recon/internal/config"
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/diagnostics"
+)
+
+func TestServeEndpoints(t *testing.T) {
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	createTestDecision(t, app, "Serve decisions over HTTP")
+
+	conn, err := db.Open(db.DBPath(app.ModuleRoot))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	srv := httptest.NewServer(newServeMux(app, conn, config.Config{}))
+	defer srv.Close()
+
+	get := func(method, path string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		if err != nil {
+			t.Fatalf("new request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		var body json.RawMessage
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("%s %s: decode: %v", method, path, err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	cases := []struct {
+		method, path string
+		status       int
+		want         string
+	}{
+		{"GET", "/orient", 200, `"Serve decisions over HTTP"`},
+		{"GET", "/orient?min_confidence=high", 200, `"min_confidence": "high"`},
+		{"GET", "/orient?min_confidence=certain", 400, `"code": "invalid_input"`},
+		{"GET", "/orient?focus=nowhere", 404, `"code": "not_found"`},
+		{"GET", "/find/Alpha", 200, `"name": "Alpha"`},
+		{"GET", "/find/Ambig", 409, `"code": "ambiguous"`},
+		{"GET", "/find/Ambig?package=pkg1", 200, `"file_path": "pkg1/a.go"`},
+		{"GET", "/find/Nope", 404, `"code": "not_found"`},
+		{"GET", "/recall?q=HTTP", 200, `"Serve decisions over HTTP"`},
+		{"GET", "/recall", 400, `"code": "missing_argument"`},
+		{"GET", "/recall?q=HTTP&limit=x", 400, `"code": "invalid_input"`},
+		{"GET", "/recall?q=HTTP&min_rank=x", 400, `"code": "invalid_input"`},
+		{"GET", "/recall?q=HTTP&min_rank=2", 400, `"code": "invalid_input"`},
+		{"GET", "/recall?q=HTTP&min_rank=0.5", 200, `"rank": 1`},
+		{"GET", "/status", 200, `"initialized": true`},
+		{"GET", "/packages", 200, `"path": "pkg1"`},
+		{"GET", "/nowhere", 404, `"/find/{symbol}"`},
+		{"POST", "/orient", 405, `"code": "method_not_allowed"`},
+	}
+	for _, tc := range cases {
+		status, body := get(tc.method, tc.path)
+		if status != tc.status || !strings.Contains(body, tc.want) {
+			t.Errorf("%s %s: status=%d body=%s; want %d containing %s", tc.method, tc.path, status, body, tc.status, tc.want)
+		}
+	}
+}
+
+func TestServeCommand(t *testing.T) {
+	app := setupInitializedApp(t)
+
+	if _, _, err := runCommandWithCapture(t, newServeCommand(app), nil); err == nil || !strings.Contains(err.Error(), "--http") {
+		t.Fatalf("expected --http to be required, got %v", err)
+	}
+
+	origListen := netListen
+	defer func() { netListen = origListen }()
+	netListen = func(string, string) (net.Listener, error) { return nil, errors.New("address in use") }
+	if _, _, err := runCommandWithCapture(t, newServeCommand(app), []string{"--http", ":7777"}); err == nil || !strings.Contains(err.Error(), "listen on :7777") {
+		t.Fatalf("expected listen error, got %v", err)
+	}
+
+	// A cancelled context shuts the server down straight away.
+	netListen = origListen
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cmd := newServeCommand(app)
+	cmd.SetArgs([]string{"--http", "127.0.0.1:0"})
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	app := setupInitializedApp(t)
+	conn, err := db.Open(db.DBPath(app.ModuleRoot))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	srv := httptest.NewServer(newServeMux(app, conn, config.Config{}))
+	defer srv.Close()
+
+	scrape := func() string {
+		t.Helper()
+		resp, err := http.Get(srv.URL + "/metrics")
+		if err != nil {
+			t.Fatalf("GET /metrics: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != 200 || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+			t.Fatalf("GET /metrics: status=%d content-type=%q", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		return string(body)
+	}
+
+	// Before the first sync there is no staleness to report.
+	body := scrape()
+	if strings.Contains(body, "recon_index_staleness_seconds") || !strings.Contains(body, "recon_syncs_total 0\n") {
+		t.Fatalf("unexpected metrics before sync:\n%s", body)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	createTestDecision(t, app, "Expose metrics")
+
+	body = scrape()
+	for _, want := range []string{
+		"# TYPE recon_index_symbols gauge\nrecon_index_symbols 4\n",
+		"# TYPE recon_syncs_total counter\nrecon_syncs_total 1\n",
+		"recon_index_files 3\n",
+		"recon_index_staleness_seconds ",
+		"recon_last_sync_duration_seconds ",
+		`recon_decisions{confidence="medium"} 1`,
+		`recon_decisions{confidence="high"} 0`,
+		`recon_evidence{entity_type="decision",drift_status="ok"} 1`,
+		`recon_evidence{entity_type="pattern",drift_status="broken"} 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+	if !regexp.MustCompile(`recon_index_size_bytes [1-9]\d*\n`).MatchString(body) {
+		t.Errorf("expected a non-zero index size:\n%s", body)
+	}
+}
+
+func TestServeDiagnosticsStream(t *testing.T) {
+	app := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(app), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if out, _, err := runCommandWithCapture(t, newDecideCommand(app), []string{
+		"Ambig stays small", "--reasoning", "r", "--evidence-summary", "a.go exists", "--check-type", "file_exists",
+		"--check-path", "pkg2/a.go", "--affects", "pkg2/a.go", "--json",
+	}); err != nil {
+		t.Fatalf("decide: %v (out=%q)", err, out)
+	}
+
+	conn, err := db.Open(db.DBPath(app.ModuleRoot))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	origPoll := diagnosticsPoll
+	diagnosticsPoll = 10 * time.Millisecond
+	t.Cleanup(func() { diagnosticsPoll = origPoll })
+	srv := httptest.NewServer(newServeMux(app, conn, config.Config{}))
+	defer srv.Close()
+
+	if resp, err := http.Get(srv.URL + "/diagnostics"); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("GET /diagnostics: %v", err)
+	} else {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if strings.TrimSpace(string(body)) != "[]" {
+			t.Fatalf("expected no diagnostics, got %s", body)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/diagnostics/stream", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /diagnostics/stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("content-type = %q", resp.Header.Get("Content-Type"))
+	}
+	events := bufio.NewReader(resp.Body)
+	next := func() []diagnostics.File {
+		t.Helper()
+		var name, data string
+		for {
+			line, err := events.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read event: %v", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			case line == "" && name != "":
+				if name != "diagnostics" {
+					t.Fatalf("unexpected %s event: %s", name, data)
+				}
+				var files []diagnostics.File
+				if err := json.Unmarshal([]byte(data), &files); err != nil {
+					t.Fatalf("decode event: %v (%s)", err, data)
+				}
+				return files
+			}
+		}
+	}
+
+	if files := next(); len(files) != 0 {
+		t.Fatalf("expected an empty first event, got %+v", files)
+	}
+	if _, err := conn.Exec(`UPDATE evidence SET drift_status = 'broken' WHERE entity_type = 'decision'`); err != nil {
+		t.Fatalf("break evidence: %v", err)
+	}
+	if files := next(); len(files) != 1 || files[0].Path != "pkg2/a.go" || len(files[0].Diagnostics) != 1 || files[0].Diagnostics[0].Code != diagnostics.CodeDriftBroken {
+		t.Fatalf("expected pkg2/a.go pushed as broken, got %+v", files)
+	}
+	if _, err := conn.Exec(`UPDATE evidence SET drift_status = 'ok' WHERE entity_type = 'decision'`); err != nil {
+		t.Fatalf("restore evidence: %v", err)
+	}
+	if files := next(); len(files) != 1 || files[0].Path != "pkg2/a.go" || files[0].Diagnostics == nil || len(files[0].Diagnostics) != 0 {
+		t.Fatalf("expected pkg2/a.go pushed clean, got %+v", files)
+	}
+}
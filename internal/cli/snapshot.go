@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+// snapshotCreatePayload is the payload of `recon snapshot create --json`.
+type snapshotCreatePayload struct {
+	Snapshot snapshot.Snapshot   `json:"snapshot"`
+	Pruned   []snapshot.Snapshot `json:"pruned"`
+}
+
+// snapshotRestorePayload is the payload of `recon snapshot restore --json`.
+// Previous is the snapshot taken of the replaced database, if there was one.
+type snapshotRestorePayload struct {
+	Restored snapshot.Snapshot  `json:"restored"`
+	Previous *snapshot.Snapshot `json:"previous,omitempty"`
+}
+
+func newSnapshotCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Create, list, and restore compressed snapshots of the recon database",
+		Long: "Keep gzip-compressed copies of .recon/recon.db under .recon/" + snapshot.DirName + " so a bad sync or\n" +
+			"deleted knowledge can be rolled back. Only the newest snapshots are kept: snapshots.keep in\n" +
+			fmt.Sprintf(".recon/config.json, %d by default. Restoring first snapshots the database it replaces.", snapshot.DefaultKeep),
+		Args: cobra.NoArgs,
+	}
+	cmd.AddCommand(newSnapshotCreateCommand(app))
+	cmd.AddCommand(newSnapshotListCommand(app))
+	cmd.AddCommand(newSnapshotRestoreCommand(app))
+	return cmd
+}
+
+func newSnapshotCreateCommand(app *App) *cobra.Command {
+	var (
+		jsonOut bool
+		label   string
+		keep    int
+	)
+
+	cmd := &cobra.Command{
+		Use:     "create",
+		Short:   "Snapshot the database and prune old snapshots",
+		Example: "  recon snapshot create\n  recon snapshot create --label before-refactor",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if keep < 0 {
+				return snapshotError(jsonOut, "invalid_input", errors.New("--keep must not be negative"))
+			}
+			cfg, err := loadConfig(app.ModuleRoot)
+			if err != nil {
+				return snapshotError(jsonOut, "invalid_input", err)
+			}
+			if keep == 0 {
+				keep = cfg.Snapshots.Keep
+			}
+
+			conn, err := openDBAnyVersion(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			snap, err := snapshot.Create(cmd.Context(), conn, app.ModuleRoot, label, time.Now())
+			if err != nil {
+				code := "internal_error"
+				if errors.Is(err, snapshot.ErrInvalidLabel) {
+					code = "invalid_input"
+				}
+				return snapshotError(jsonOut, code, err)
+			}
+			pruned, err := snapshot.Prune(app.ModuleRoot, keep)
+			if err != nil {
+				return snapshotError(jsonOut, "internal_error", err)
+			}
+			if err := db.EnsureGitIgnore(app.ModuleRoot, snapshot.GitIgnore); err != nil {
+				return snapshotError(jsonOut, "internal_error", err)
+			}
+
+			if jsonOut {
+				return writeJSON(snapshotCreatePayload{Snapshot: snap, Pruned: pruned})
+			}
+			fmt.Printf("Created snapshot %s (%s)\n", snap.Name, formatBytes(snap.SizeBytes))
+			for _, p := range pruned {
+				fmt.Printf("Pruned snapshot %s\n", p.Name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().StringVar(&label, "label", "", "Suffix for the snapshot name, e.g. before-refactor")
+	cmd.Flags().IntVar(&keep, "keep", 0, "Snapshots to retain after this one is written (default snapshots.keep, or 10)")
+	return cmd
+}
+
+func newSnapshotListCommand(app *App) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List snapshots, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snaps, err := snapshot.List(app.ModuleRoot)
+			if err != nil {
+				return snapshotError(jsonOut, "internal_error", err)
+			}
+			if jsonOut {
+				return writeJSON(snaps)
+			}
+			if len(snaps) == 0 {
+				fmt.Println("No snapshots; create one with `recon snapshot create`.")
+				return nil
+			}
+			width := 0
+			for _, s := range snaps {
+				width = max(width, len(s.Name))
+			}
+			for _, s := range snaps {
+				fmt.Printf("%-*s  %s  %s\n", width, s.Name, s.CreatedAt, formatBytes(s.SizeBytes))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+func newSnapshotRestoreCommand(app *App) *cobra.Command {
+	var (
+		jsonOut bool
+		force   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "restore <name|latest>",
+		Short: "Replace the database with a snapshot",
+		Long: "Replace .recon/recon.db with a snapshot from `recon snapshot list`, or the newest one for\n" +
+			"\"latest\". The current database is snapshotted first, labelled pre-restore, so the restore\n" +
+			"can itself be undone. The snapshot is checked and migrated to this build's schema before it\n" +
+			"replaces anything. Stop `recon serve` and other recon processes first.",
+		Example: "  recon snapshot restore latest\n  recon snapshot restore 20260218T103000Z-before-refactor --force",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				msg := "snapshot restore requires a <name> argument; see `recon snapshot list`"
+				if jsonOut {
+					_ = writeJSONError("missing_argument", msg, map[string]any{"command": "snapshot restore"})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			snap, err := snapshot.Find(app.ModuleRoot, args[0])
+			if err != nil {
+				if errors.Is(err, snapshot.ErrNotFound) {
+					return snapshotError(jsonOut, "not_found", fmt.Errorf("snapshot %q not found; see `recon snapshot list`", args[0]))
+				}
+				return snapshotError(jsonOut, "internal_error", err)
+			}
+
+			if !force && !app.NoPrompt && !jsonOut {
+				fmt.Printf("This will replace %s with snapshot %s. Continue? [y/N] ", db.DBPath(app.ModuleRoot), snap.Name)
+				var confirm string
+				fmt.Scan(&confirm)
+				if confirm != "y" && confirm != "Y" {
+					fmt.Println("Aborted.")
+					return nil
+				}
+			}
+
+			cfg, err := loadConfig(app.ModuleRoot)
+			if err != nil {
+				return snapshotError(jsonOut, "invalid_input", err)
+			}
+			payload := snapshotRestorePayload{Restored: snap}
+			if _, err := os.Stat(db.DBPath(app.ModuleRoot)); err == nil {
+				conn, err := openDBAnyVersion(app)
+				if err != nil {
+					return snapshotError(jsonOut, "internal_error", err)
+				}
+				prev, err := snapshot.Create(cmd.Context(), conn, app.ModuleRoot, "pre-restore", time.Now())
+				conn.Close()
+				if err != nil {
+					return snapshotError(jsonOut, "internal_error", fmt.Errorf("snapshot current database: %w", err))
+				}
+				payload.Previous = &prev
+			}
+
+			if err := snapshot.Restore(cmd.Context(), app.ModuleRoot, snap); err != nil {
+				return snapshotError(jsonOut, "internal_error", err)
+			}
+			// Pruning waits for the restore, which may use the oldest snapshot.
+			if _, err := snapshot.Prune(app.ModuleRoot, cfg.Snapshots.Keep); err != nil {
+				return snapshotError(jsonOut, "internal_error", err)
+			}
+
+			if jsonOut {
+				return writeJSON(payload)
+			}
+			fmt.Printf("Restored snapshot %s\n", snap.Name)
+			if payload.Previous != nil {
+				fmt.Printf("The replaced database was saved as %s\n", payload.Previous.Name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().BoolVar(&force, "force", false, "Skip confirmation prompt")
+	return cmd
+}
+
+func snapshotError(jsonOut bool, code string, err error) error {
+	if jsonOut {
+		_ = writeJSONError(code, err.Error(), nil)
+		return ExitError{Code: 2}
+	}
+	return ExitError{Code: 2, Message: err.Error()}
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robertguss/recon/internal/find"
+	"github.com/robertguss/recon/internal/snippet"
+	"github.com/spf13/cobra"
+)
+
+func newSnippetsCommand(app *App) *cobra.Command {
+	var (
+		jsonOut       bool
+		limit         int
+		contextLines  int
+		packageFilter string
+		fileFilter    string
+		kindFilter    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "snippets <symbol>",
+		Short: "Show representative call sites of a symbol as usage examples",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				msg := "snippets requires a <symbol> argument"
+				if jsonOut {
+					_ = writeJSONError("missing_argument", msg, map[string]any{"command": "snippets"})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			if limit <= 0 || contextLines < 0 {
+				msg := "--limit must be > 0 and --context must be >= 0"
+				if jsonOut {
+					details := map[string]any{"limit": limit, "context": contextLines}
+					_ = writeJSONError("invalid_input", msg, details)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			normalizedKind, err := normalizeFindKind(kindFilter)
+			if err != nil {
+				if jsonOut {
+					details := map[string]any{"kind": strings.TrimSpace(kindFilter)}
+					_ = writeJSONError("invalid_input", err.Error(), details)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			symbol := args[0]
+			queryOptions := find.QueryOptions{
+				PackagePath: strings.TrimSpace(packageFilter),
+				FilePath:    normalizeFindPath(app.modulePath(fileFilter)),
+				Kind:        normalizedKind,
+			}
+			result, err := snippet.NewService(conn).Snippets(cmd.Context(), symbol, snippet.Options{
+				Query:      queryOptions,
+				Limit:      limit,
+				Context:    contextLines,
+				ModuleRoot: app.ModuleRoot,
+			})
+			if err != nil {
+				return writeFindLookupError(app, "snippets", symbol, err, queryOptions, jsonOut)
+			}
+
+			app.applyPathMode(&result)
+			if jsonOut {
+				return writeJSON(result)
+			}
+
+			sym := result.Symbol
+			label := sym.Name
+			if sym.Receiver != "" {
+				label = strings.TrimPrefix(sym.Receiver, "*") + "." + sym.Name
+			}
+			if len(result.Snippets) == 0 {
+				fmt.Printf("No indexed call sites for %s %s (%s)\n", sym.Kind, label, sym.FilePath)
+				return nil
+			}
+			fmt.Printf("Usage of %s %s (%d of %d callers):\n", sym.Kind, label, len(result.Snippets), result.TotalCallers)
+			for _, sn := range result.Snippets {
+				fmt.Printf("\n%s:%d in %s [%s]\n", sn.FilePath, sn.Line, sn.Caller, strings.Join(sn.Reasons, ", "))
+				fmt.Println(sn.Code)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().IntVar(&limit, "limit", 3, "Maximum snippets to show")
+	cmd.Flags().IntVar(&contextLines, "context", 2, "Lines of context around each call")
+	cmd.Flags().StringVar(&packageFilter, "package", "", "Filter by package path when symbols are ambiguous")
+	cmd.Flags().StringVar(&fileFilter, "file", "", "Filter by file path when symbols are ambiguous")
+	cmd.Flags().StringVar(&kindFilter, "kind", "", "Filter by symbol kind (func, method, type, var, const, enum, table, view, proto_service, proto_rpc, proto_message, proto_enum)")
+	return cmd
+}
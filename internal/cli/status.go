@@ -1,9 +1,18 @@
 package cli
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/knowledge"
+	"github.com/robertguss/recon/internal/orient"
 	"github.com/spf13/cobra"
 )
 
@@ -11,6 +20,11 @@ type statusPayload struct {
 	Initialized bool         `json:"initialized"`
 	LastSyncAt  string       `json:"last_sync_at,omitempty"`
 	Counts      statusCounts `json:"counts"`
+	// Evidence is when decision, pattern, and constraint evidence is due for
+	// a `recon verify --due` re-check.
+	Evidence knowledge.Schedule `json:"evidence"`
+	// Health is the detailed index report of `recon status --verbose`.
+	Health *statusHealth `json:"health,omitempty"`
 }
 
 type statusCounts struct {
@@ -22,14 +36,64 @@ type statusCounts struct {
 	Patterns          int `json:"patterns"`
 }
 
+// statusHealth gathers the signals needed to judge whether the index and the
+// knowledge on it can be trusted. SchemaVersion is -1 when the database was
+// never migrated.
+type statusHealth struct {
+	DBSizeBytes           int64          `json:"db_size_bytes"`
+	SchemaVersion         int            `json:"schema_version"`
+	ExpectedSchemaVersion int            `json:"expected_schema_version"`
+	SearchIndex           map[string]int `json:"search_index"`
+	PendingProposals      int            `json:"pending_proposals"`
+	EvidenceDrifting      int            `json:"evidence_drifting"`
+	DanglingEdges         int            `json:"dangling_edges"`
+	LastVerifiedAt        string         `json:"last_verified_at,omitempty"`
+	Stale                 bool           `json:"stale"`
+	StaleReason           string         `json:"stale_reason,omitempty"`
+	StaleSummary          string         `json:"stale_summary,omitempty"`
+	Warnings              []string       `json:"warnings,omitempty"`
+}
+
+// danglingEdgesFrom selects the edges from active knowledge to symbols the
+// index no longer has, matched on the package-qualified ref edges use.
+const danglingEdgesFrom = `
+FROM edges e
+LEFT JOIN decisions d ON e.from_type = 'decision' AND d.id = e.from_id
+LEFT JOIN patterns p ON e.from_type = 'pattern' AND p.id = e.from_id
+LEFT JOIN constraints c ON e.from_type = 'constraint' AND c.id = e.from_id
+WHERE e.to_type = 'symbol' AND COALESCE(d.status, p.status, c.status) = 'active'
+  AND NOT EXISTS (
+    SELECT 1 FROM symbols sy
+    JOIN files f ON f.id = sy.file_id
+    JOIN packages pk ON pk.id = f.package_id
+    WHERE pk.path || '.' || sy.name = e.to_ref
+  )`
+
 func newStatusCommand(app *App) *cobra.Command {
-	var jsonOut bool
+	var (
+		jsonOut bool
+		format  string
+		verbose bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Quick health check for recon state",
+		Long: "Show initialization state, last sync time, entity counts, and the evidence re-check\n" +
+			"schedule. With --verbose, add a health report: database size, schema version, search\n" +
+			"index rows, pending proposals, drifting evidence, dangling edges, the last evidence\n" +
+			"check, and why the index is stale, if it is.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			conn, err := openExistingDB(app)
+			outFormat, err := parseFormat(format, "text", "csv")
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"format": strings.TrimSpace(format)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			conn, err := openDBAnyVersion(app)
 			if err != nil {
 				if jsonOut {
 					return exitJSONCommandError(err)
@@ -38,31 +102,25 @@ func newStatusCommand(app *App) *cobra.Command {
 			}
 			defer conn.Close()
 
-			var payload statusPayload
-			payload.Initialized = true
-
-			state, exists, err := db.LoadSyncState(cmd.Context(), conn)
+			payload, err := loadStatus(cmd.Context(), conn)
+			if err == nil && verbose {
+				var health statusHealth
+				health, err = loadStatusHealth(cmd.Context(), conn, app.ModuleRoot)
+				payload.Health = &health
+			}
 			if err != nil {
 				if jsonOut {
 					return exitJSONCommandError(err)
 				}
 				return err
 			}
-			if exists {
-				payload.LastSyncAt = state.LastSyncAt.Format("2006-01-02T15:04:05Z07:00")
-			}
-
-			ctx := cmd.Context()
-			_ = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM files").Scan(&payload.Counts.Files)
-			_ = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM symbols").Scan(&payload.Counts.Symbols)
-			_ = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM packages").Scan(&payload.Counts.Packages)
-			_ = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM decisions WHERE status = 'active'").Scan(&payload.Counts.Decisions)
-			_ = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM evidence WHERE entity_type = 'decision' AND drift_status != 'ok'").Scan(&payload.Counts.DecisionsDrifting)
-			_ = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM patterns WHERE status = 'active'").Scan(&payload.Counts.Patterns)
 
 			if jsonOut {
 				return writeJSON(payload)
 			}
+			if outFormat == "csv" {
+				return writeCSV(statusCSV(payload))
+			}
 
 			fmt.Printf("Initialized: yes\n")
 			if payload.LastSyncAt != "" {
@@ -74,10 +132,181 @@ func newStatusCommand(app *App) *cobra.Command {
 				payload.Counts.Files, payload.Counts.Symbols, payload.Counts.Packages)
 			fmt.Printf("Decisions: %d (%d drifting) | Patterns: %d\n",
 				payload.Counts.Decisions, payload.Counts.DecisionsDrifting, payload.Counts.Patterns)
+			printSchedule(payload.Evidence)
+			if payload.Health != nil {
+				printStatusHealth(*payload.Health)
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text or csv")
+	cmd.Flags().BoolVar(&verbose, "verbose", false, "Add a detailed index health report")
 	return cmd
 }
+
+// loadStatus reads the sync state, entity counts, and evidence schedule
+// `recon status` and the /status endpoint of `recon serve` report.
+func loadStatus(ctx context.Context, conn *sql.DB) (statusPayload, error) {
+	payload := statusPayload{Initialized: true}
+	state, exists, err := db.LoadSyncState(ctx, conn)
+	if err != nil {
+		return statusPayload{}, err
+	}
+	if exists {
+		payload.LastSyncAt = state.LastSyncAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	_ = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM files").Scan(&payload.Counts.Files)
+	_ = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM symbols").Scan(&payload.Counts.Symbols)
+	_ = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM packages").Scan(&payload.Counts.Packages)
+	_ = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM decisions WHERE status = 'active'").Scan(&payload.Counts.Decisions)
+	_ = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM entity_evidence WHERE entity_type = 'decision' AND drift_status != 'ok'").Scan(&payload.Counts.DecisionsDrifting)
+	_ = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM patterns WHERE status = 'active'").Scan(&payload.Counts.Patterns)
+
+	sched, err := knowledge.NewService(conn).VerifySchedule(ctx, time.Now())
+	if err != nil {
+		return statusPayload{}, err
+	}
+	payload.Evidence = sched
+	return payload, nil
+}
+
+// loadStatusHealth reads the detailed health report of `recon status
+// --verbose` for the module at moduleRoot.
+func loadStatusHealth(ctx context.Context, conn *sql.DB, moduleRoot string) (statusHealth, error) {
+	h := statusHealth{SchemaVersion: -1, SearchIndex: map[string]int{}}
+	if info, err := os.Stat(db.DBPath(moduleRoot)); err == nil {
+		h.DBSizeBytes = info.Size()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return statusHealth{}, fmt.Errorf("stat db file: %w", err)
+	}
+
+	expected, err := db.LatestMigrationVersion()
+	if err != nil {
+		return statusHealth{}, err
+	}
+	h.ExpectedSchemaVersion = expected
+	err = conn.QueryRowContext(ctx, "SELECT version FROM schema_migrations").Scan(&h.SchemaVersion)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return statusHealth{}, fmt.Errorf("read schema version: %w", err)
+	}
+
+	search, err := countBy(ctx, conn, "SELECT entity_type, COUNT(*) FROM search_index GROUP BY entity_type")
+	if err != nil {
+		return statusHealth{}, fmt.Errorf("count search index: %w", err)
+	}
+	for _, entity := range metricEntityTypes {
+		h.SearchIndex[entity] = search[entity]
+	}
+
+	for _, q := range []struct {
+		dest  *int
+		what  string
+		query string
+	}{
+		{&h.PendingProposals, "pending proposals", "SELECT COUNT(*) FROM proposals WHERE status = 'pending'"},
+		{&h.EvidenceDrifting, "drifting evidence", "SELECT COUNT(*) FROM evidence WHERE entity_type != 'proposal' AND COALESCE(drift_status, 'ok') != 'ok'"},
+		{&h.DanglingEdges, "dangling edges", "SELECT COUNT(*) " + danglingEdgesFrom},
+	} {
+		if err := conn.QueryRowContext(ctx, q.query).Scan(q.dest); err != nil {
+			return statusHealth{}, fmt.Errorf("count %s: %w", q.what, err)
+		}
+	}
+	if err := conn.QueryRowContext(ctx, "SELECT COALESCE(MAX(last_verified_at), '') FROM evidence").Scan(&h.LastVerifiedAt); err != nil {
+		return statusHealth{}, fmt.Errorf("load last verify time: %w", err)
+	}
+
+	freshness, warnings, err := orient.NewService(conn).Freshness(ctx, moduleRoot)
+	if err != nil {
+		return statusHealth{}, err
+	}
+	h.Stale, h.StaleReason, h.StaleSummary, h.Warnings = freshness.IsStale, freshness.Reason, freshness.StaleSummary, warnings
+	return h, nil
+}
+
+// printStatusHealth renders the `recon status --verbose` report.
+func printStatusHealth(h statusHealth) {
+	schema := strconv.Itoa(h.SchemaVersion)
+	if h.SchemaVersion < 0 {
+		schema = "none"
+	}
+	if h.SchemaVersion != h.ExpectedSchemaVersion {
+		schema += fmt.Sprintf(" (this recon expects %d)", h.ExpectedSchemaVersion)
+	}
+	lastVerified := h.LastVerifiedAt
+	if lastVerified == "" {
+		lastVerified = "never"
+	}
+	fmt.Printf("\nHealth:\n")
+	fmt.Printf("Database: %s | Schema version: %s\n", formatBytes(h.DBSizeBytes), schema)
+	fmt.Printf("Search index: %d decisions, %d patterns, %d constraints\n",
+		h.SearchIndex["decision"], h.SearchIndex["pattern"], h.SearchIndex["constraint"])
+	fmt.Printf("Pending proposals: %d | Drifting evidence: %d | Dangling edges: %d\n",
+		h.PendingProposals, h.EvidenceDrifting, h.DanglingEdges)
+	fmt.Printf("Last verified: %s\n", lastVerified)
+	if h.Stale {
+		line := "Index: stale (" + h.StaleReason + ")"
+		if h.StaleSummary != "" {
+			line += "; " + h.StaleSummary
+		}
+		fmt.Println(line)
+	} else {
+		fmt.Println("Index: fresh")
+	}
+	for _, w := range h.Warnings {
+		fmt.Printf("Warning: %s\n", w)
+	}
+}
+
+// statusCSV flattens the status payload into metric,value rows.
+func statusCSV(p statusPayload) ([]string, [][]string) {
+	c := p.Counts
+	header := []string{"metric", "value"}
+	rows := [][]string{
+		{"initialized", strconv.FormatBool(p.Initialized)},
+		{"last_sync_at", p.LastSyncAt},
+		{"files", strconv.Itoa(c.Files)},
+		{"symbols", strconv.Itoa(c.Symbols)},
+		{"packages", strconv.Itoa(c.Packages)},
+		{"decisions", strconv.Itoa(c.Decisions)},
+		{"decisions_drifting", strconv.Itoa(c.DecisionsDrifting)},
+		{"patterns", strconv.Itoa(c.Patterns)},
+		{"evidence_due", strconv.Itoa(p.Evidence.Due)},
+		{"evidence_next_due_at", p.Evidence.NextDueAt},
+	}
+	if h := p.Health; h != nil {
+		rows = append(rows,
+			[]string{"db_size_bytes", strconv.FormatInt(h.DBSizeBytes, 10)},
+			[]string{"schema_version", strconv.Itoa(h.SchemaVersion)},
+			[]string{"expected_schema_version", strconv.Itoa(h.ExpectedSchemaVersion)},
+		)
+		for _, entity := range metricEntityTypes {
+			rows = append(rows, []string{"search_index_" + entity, strconv.Itoa(h.SearchIndex[entity])})
+		}
+		rows = append(rows,
+			[]string{"pending_proposals", strconv.Itoa(h.PendingProposals)},
+			[]string{"evidence_drifting", strconv.Itoa(h.EvidenceDrifting)},
+			[]string{"dangling_edges", strconv.Itoa(h.DanglingEdges)},
+			[]string{"last_verified_at", h.LastVerifiedAt},
+			[]string{"stale", strconv.FormatBool(h.Stale)},
+			[]string{"stale_reason", h.StaleReason},
+		)
+	}
+	return header, rows
+}
+
+// formatBytes renders a size in the largest binary unit that keeps it >= 1.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
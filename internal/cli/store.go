@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -17,7 +18,38 @@ func (e dbNotInitializedError) Error() string {
 	return fmt.Sprintf("database not initialized at %s; run `recon init` first", e.Path)
 }
 
+// schemaOutOfDateError is returned for a database whose schema is older
+// than the one this build of recon reads.
+type schemaOutOfDateError struct {
+	Path     string
+	Version  int
+	Expected int
+}
+
+func (e schemaOutOfDateError) Error() string {
+	return fmt.Sprintf("database schema version %d at %s is older than this recon expects (%d); run `recon init --force` or `recon migrate up`, or rerun with --yes to migrate it", e.Version, e.Path, e.Expected)
+}
+
+// openExistingDB opens the module's database for a command that reads or
+// writes the index. A database with an older schema is migrated when the
+// user passed --yes or agrees at a prompt, after a pre-migrate snapshot;
+// otherwise it is refused with a schemaOutOfDateError rather than left to
+// fail on a missing table or column.
 func openExistingDB(app *App) (*sql.DB, error) {
+	conn, err := openDBAnyVersion(app)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureCurrentSchema(app, conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// openDBAnyVersion opens the module's database whatever its schema, for
+// commands that inspect or change the schema itself.
+func openDBAnyVersion(app *App) (*sql.DB, error) {
 	path := db.DBPath(app.ModuleRoot)
 	if _, err := os.Stat(path); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -32,3 +64,42 @@ func openExistingDB(app *App) (*sql.DB, error) {
 	}
 	return conn, nil
 }
+
+func ensureCurrentSchema(app *App, conn *sql.DB) error {
+	ctx := app.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	status, err := readMigrationStatus(ctx, conn)
+	if err != nil {
+		return err
+	}
+	// A failed migration, or a file never migrated at all, is for doctor
+	// and reset to report; a newer schema only adds what this build does
+	// not read.
+	if status.Dirty || status.Version < 0 || len(status.Pending) == 0 {
+		return nil
+	}
+
+	outOfDate := schemaOutOfDateError{Path: db.DBPath(app.ModuleRoot), Version: status.Version, Expected: status.Latest}
+	if !app.Yes {
+		if app.NoPrompt || !isInteractive() {
+			return outOfDate
+		}
+		question := fmt.Sprintf("The recon database has schema version %d; this recon expects %d. Migrate it now? [Y/n]: ", status.Version, status.Latest)
+		if ok, err := askYesNo(question, true); err != nil || !ok {
+			return outOfDate
+		}
+	}
+
+	backup, err := backupBeforeMigrate(ctx, conn, app.ModuleRoot, "pre-migrate")
+	if err != nil {
+		return err
+	}
+	if err := runMigrations(conn); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Migrated the recon database from schema version %d to %d; the previous database was saved as snapshot %s\n",
+		status.Version, status.Latest, backup.Name)
+	return nil
+}
@@ -3,23 +3,98 @@ package cli
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/robertguss/recon/internal/index"
+	"github.com/robertguss/recon/internal/knowledge"
+	"github.com/robertguss/recon/internal/mirror"
 	"github.com/spf13/cobra"
 )
 
-var runSync = func(ctx context.Context, conn *sql.DB, moduleRoot string) (index.SyncResult, error) {
-	return index.NewService(conn).Sync(ctx, moduleRoot)
+var (
+	runSync = func(ctx context.Context, conn *sql.DB, moduleRoot string, opts index.SyncOptions) (index.SyncResult, error) {
+		return index.NewService(conn).SyncWithOptions(ctx, moduleRoot, opts)
+	}
+	runSyncFiles = func(ctx context.Context, conn *sql.DB, moduleRoot string, paths []string) (index.SyncResult, error) {
+		return index.NewService(conn).SyncFiles(ctx, moduleRoot, paths)
+	}
+	runRecheck = func(ctx context.Context, conn *sql.DB, moduleRoot string, changed []string) (knowledge.RecheckResult, error) {
+		return knowledge.NewService(conn).RecheckEvidence(ctx, moduleRoot, changed)
+	}
+	runReconcile = func(ctx context.Context, conn *sql.DB, moduleRoot string) (mirror.ReconcileResult, error) {
+		return mirror.NewService(conn).Reconcile(ctx, moduleRoot)
+	}
+)
+
+// syncPayload is the sync result plus the evidence re-checked because its
+// scope intersects the changed files, and, with knowledge.files enabled, the
+// knowledge files reconciled with the database.
+type syncPayload struct {
+	index.SyncResult
+	Evidence       *knowledge.RecheckResult `json:"evidence,omitempty"`
+	KnowledgeFiles *mirror.ReconcileResult  `json:"knowledge_files,omitempty"`
 }
 
 func newSyncCommand(app *App) *cobra.Command {
-	var jsonOut bool
+	var (
+		jsonOut   bool
+		jobs      int
+		filesOnly bool
+		staged    bool
+		quiet     bool
+		verbose   bool
+		wait      bool
+	)
 
 	cmd := &cobra.Command{
-		Use:   "sync",
+		Use:   "sync [--files <path>... | --staged]",
 		Short: "Index Go source code into recon",
+		Long: "Index Go source code into recon.\n\n" +
+			"With --files, only the given files are re-indexed (their symbols, imports, and package\n" +
+			"stats); missing or ineligible files are removed from the index. This skips walking and\n" +
+			"fingerprinting the module, for editors and daemons that know exactly what changed.\n" +
+			"--staged does the same for the files staged for commit in git, as the pre-commit hook\n" +
+			"from recon install git-hooks does.\n\n" +
+			"A full sync shows its progress on stderr when stderr is a terminal. --quiet hides\n" +
+			"it and prints only the evidence whose drift status changed, and nothing otherwise.\n" +
+			"--verbose also reports each phase on stderr when it is not a terminal, and prints\n" +
+			"how long each phase took; sync --json always includes the timings.\n\n" +
+			"Only one sync runs at a time per module: a second one fails with \"another sync is\n" +
+			"running\", or with --wait, waits for the first to finish. A lock left behind by a\n" +
+			"sync that crashed is taken over.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if staged && (filesOnly || len(args) > 0) {
+				msg := "--staged cannot be combined with --files or paths"
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, map[string]any{"flag": "staged", "args": args})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			if filesOnly != (len(args) > 0) {
+				msg := "--files requires at least one path"
+				if !filesOnly {
+					msg = "sync takes file paths only with --files"
+				}
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, map[string]any{"flag": "files", "args": args})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			cfg, err := loadConfig(app.ModuleRoot)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
 			conn, err := openExistingDB(app)
 			if err != nil {
 				if jsonOut {
@@ -29,19 +104,123 @@ func newSyncCommand(app *App) *cobra.Command {
 			}
 			defer conn.Close()
 
-			result, err := runSync(cmd.Context(), conn, app.ModuleRoot)
+			if jobs < 0 {
+				msg := "--jobs must be >= 0"
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, map[string]any{"flag": "jobs", "value": jobs})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			if staged {
+				if args, err = index.StagedFiles(cmd.Context(), app.ModuleRoot); err != nil {
+					if jsonOut {
+						_ = writeJSONError("invalid_input", err.Error(), map[string]any{"flag": "staged"})
+						return ExitError{Code: 2}
+					}
+					return ExitError{Code: 2, Message: err.Error()}
+				}
+				if len(args) == 0 {
+					if jsonOut {
+						return writeJSON(syncPayload{SyncResult: index.SyncResult{Files: []string{}}})
+					}
+					if !quiet {
+						fmt.Println("No staged files to re-index")
+					}
+					return nil
+				}
+				filesOnly = true
+			}
+
+			lock, err := lockSync(cmd.Context(), app.ModuleRoot, wait, quiet || jsonOut)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				var running index.SyncRunningError
+				if errors.As(err, &running) {
+					return fmt.Errorf("%w; rerun with --wait to queue behind it", err)
+				}
+				return err
+			}
+			defer lock.Unlock()
+
+			var result index.SyncResult
+			if filesOnly {
+				result, err = runSyncFiles(cmd.Context(), conn, app.ModuleRoot, args)
+			} else {
+				opts := index.SyncOptions{Jobs: jobs}
+				var spinner *syncSpinner
+				switch {
+				case quiet:
+				case stderrIsTerminal():
+					spinner = startSyncSpinner(os.Stderr)
+					opts.Progress = spinner.Update
+				case verbose:
+					opts.Progress = phaseLogger(os.Stderr)
+				}
+				result, err = runSync(cmd.Context(), conn, app.ModuleRoot, opts)
+				if spinner != nil {
+					spinner.Stop()
+				}
+			}
 			if err != nil {
+				if errors.Is(err, index.ErrInvalidPath) {
+					if jsonOut {
+						_ = writeJSONError("invalid_input", err.Error(), map[string]any{"flag": "files", "args": args})
+						return ExitError{Code: 2}
+					}
+					return ExitError{Code: 2, Message: err.Error()}
+				}
 				if jsonOut {
 					return exitJSONCommandError(err)
 				}
 				return err
 			}
 
+			changed := result.ChangedFiles
+			if filesOnly {
+				changed = result.Files
+			}
+			payload := syncPayload{SyncResult: result}
+			if recheck, err := runRecheck(cmd.Context(), conn, app.ModuleRoot, changed); err != nil {
+				// The index is already committed; stale drift status is not worth failing the sync.
+				fmt.Fprintf(os.Stderr, "warning: evidence recheck failed: %v\n", err)
+			} else if recheck.Checked > 0 {
+				payload.Evidence = &recheck
+			}
+			if cfg.Knowledge.Files {
+				if reconciled, err := runReconcile(cmd.Context(), conn, app.ModuleRoot); err != nil {
+					fmt.Fprintf(os.Stderr, "warning: knowledge file reconcile failed: %v\n", err)
+				} else {
+					payload.KnowledgeFiles = &reconciled
+				}
+			}
+
+			app.applyPathMode(&payload)
 			if jsonOut {
-				return writeJSON(result)
+				return writeJSON(payload)
+			}
+			if quiet {
+				if payload.Evidence != nil && len(payload.Evidence.Changed) > 0 {
+					printRecheck(*payload.Evidence)
+				}
+				return nil
 			}
 
-			fmt.Printf("Synced %d files, %d symbols across %d packages\n", result.IndexedFiles, result.IndexedSymbols, result.IndexedPackages)
+			if filesOnly {
+				fmt.Printf("Re-indexed %d of %d files; index has %d symbols across %d packages\n",
+					len(result.Files), result.IndexedFiles, result.IndexedSymbols, result.IndexedPackages)
+			} else {
+				fmt.Printf("Synced %d files, %d symbols across %d packages\n", result.IndexedFiles, result.IndexedSymbols, result.IndexedPackages)
+			}
+			if len(result.Ignored) > 0 {
+				fmt.Printf("Ignored %d paths by .gitignore/.reconignore rules (see --json)\n", len(result.Ignored))
+			}
+			for _, w := range result.IgnoreWarnings {
+				fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+			}
 			if result.Diff != nil {
 				fmt.Printf("Changes: +%d files, -%d files, ~%d modified\n",
 					result.Diff.FilesAdded, result.Diff.FilesRemoved, result.Diff.FilesModified)
@@ -54,10 +233,90 @@ func newSyncCommand(app *App) *cobra.Command {
 				fmt.Printf("Git commit: %s dirty=%v\n", result.Commit, result.Dirty)
 			}
 			fmt.Printf("Synced at: %s\n", result.SyncedAt.Format("2006-01-02T15:04:05Z07:00"))
+			if verbose && result.Timings != nil {
+				printSyncTimings(*result.Timings)
+			}
+			if len(result.Renames) > 0 {
+				fmt.Printf("Renamed symbols: %d\n", len(result.Renames))
+				for _, r := range result.Renames {
+					fmt.Printf("- %s -> %s (%s, %d edges rewritten)\n", r.OldRef, r.NewRef, r.Kind, r.Edges)
+				}
+			}
+			if len(result.DanglingEdges) > 0 {
+				fmt.Printf("Dangling edges: %d point at symbols no longer in the index; recreate or remove them with recon edges\n", len(result.DanglingEdges))
+				for _, e := range result.DanglingEdges {
+					line := fmt.Sprintf("- edge %d: %s #%d %s %s", e.ID, e.FromType, e.FromID, e.Relation, e.ToRef)
+					if len(e.Candidates) > 0 {
+						line += " (possibly renamed to " + strings.Join(e.Candidates, ", ") + ")"
+					}
+					fmt.Println(line)
+				}
+			}
+			if len(result.MissingEmbeds) > 0 {
+				fmt.Printf("Missing embedded files: %d //go:embed patterns match nothing; the packages will not build\n", len(result.MissingEmbeds))
+				for _, m := range result.MissingEmbeds {
+					fmt.Printf("- %s:%d %s embeds %s\n", m.FilePath, m.Line, m.Var, m.Pattern)
+				}
+			}
+			if payload.Evidence != nil {
+				printRecheck(*payload.Evidence)
+			}
+			if payload.KnowledgeFiles != nil {
+				printReconcile(*payload.KnowledgeFiles)
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().IntVar(&jobs, "jobs", 0, "Number of files to parse concurrently (0 = one per CPU)")
+	cmd.Flags().BoolVar(&filesOnly, "files", false, "Re-index only the files given as arguments")
+	cmd.Flags().BoolVar(&staged, "staged", false, "Re-index only the files staged for commit in git")
+	cmd.Flags().BoolVar(&quiet, "quiet", false, "Print only evidence whose drift status changed, without progress")
+	cmd.Flags().BoolVar(&verbose, "verbose", false, "Report each phase even when stderr is not a terminal, and print phase timings")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for a running sync to finish instead of failing")
+	cmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
 	return cmd
 }
+
+// lockSync takes the module's sync lock. With wait, a running sync is
+// waited out, and said so on stderr unless silent.
+func lockSync(ctx context.Context, moduleRoot string, wait, silent bool) (*index.SyncLock, error) {
+	lock, err := index.LockSync(ctx, moduleRoot, false)
+	var running index.SyncRunningError
+	if !wait || !errors.As(err, &running) {
+		return lock, err
+	}
+	if !silent {
+		fmt.Fprintf(os.Stderr, "Waiting for another sync (pid %d) to finish...\n", running.Holder.PID)
+	}
+	return index.LockSync(ctx, moduleRoot, true)
+}
+
+// printRecheck renders the evidence re-checked by sync or verify, one line per
+// drift status change.
+func printRecheck(r knowledge.RecheckResult) {
+	fmt.Printf("Evidence re-checked: %d (%d changed)\n", r.Checked, len(r.Changed))
+	for _, c := range r.Changed {
+		line := fmt.Sprintf("- %s #%d %s: %s -> %s (%s)", c.EntityType, c.EntityID, c.Title, c.Before, c.After, c.Details)
+		if c.Decayed {
+			line += fmt.Sprintf("; confidence now %s", c.Confidence)
+		}
+		fmt.Println(line)
+	}
+}
+
+// printReconcile renders the knowledge files sync wrote, read, or deleted,
+// and the ones it could not read. Nothing is printed when all agree.
+func printReconcile(r mirror.ReconcileResult) {
+	if len(r.Changes) == 0 && len(r.Invalid) == 0 {
+		return
+	}
+	fmt.Printf("Knowledge files: %d changed, %d invalid\n", len(r.Changes), len(r.Invalid))
+	for _, c := range r.Changes {
+		fmt.Printf("- %s %s (%s #%d %s)\n", c.Action, c.File, c.EntityType, c.EntityID, c.Title)
+	}
+	for _, f := range r.Invalid {
+		fmt.Printf("- invalid %s: %s\n", f.File, f.Error)
+	}
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robertguss/recon/internal/todos"
+	"github.com/spf13/cobra"
+)
+
+func newTodosCommand(app *App) *cobra.Command {
+	var (
+		jsonOut     bool
+		packagePath string
+		kinds       []string
+		limit       int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "todos",
+		Short: "List TODO, FIXME, and BUG comments and deprecated symbols",
+		Long: "List the TODO, FIXME, and BUG markers that start a comment line, as indexed by\n" +
+			"`recon sync`, with their location and the name in \"TODO(name):\" if any. --kind\n" +
+			"deprecated lists the symbols whose doc comment has a \"Deprecated:\" paragraph.",
+		Example: "  recon todos\n  recon todos --package internal/index/...\n  recon todos --kind fixme,bug --json\n" +
+			"  recon todos --kind deprecated",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for i, k := range kinds {
+				kinds[i] = strings.ToLower(strings.TrimSpace(k))
+				if !todos.ValidKind(kinds[i]) {
+					msg := fmt.Sprintf("invalid --kind %q (expected %s)", k, strings.Join(todos.Kinds, ", "))
+					if jsonOut {
+						_ = writeJSONError("invalid_input", msg, map[string]any{"kind": k})
+						return ExitError{Code: 2}
+					}
+					return ExitError{Code: 2, Message: msg}
+				}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			items, err := todos.NewService(conn).List(cmd.Context(), todos.Options{
+				Package: normalizeFindPath(packagePath),
+				Kinds:   kinds,
+				Limit:   limit,
+			})
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+
+			app.applyPathMode(&items)
+			if jsonOut {
+				return writeJSON(items)
+			}
+			if len(items) == 0 {
+				fmt.Println("No annotations found")
+				return nil
+			}
+			counts := map[string]int{}
+			for _, it := range items {
+				counts[it.Kind]++
+			}
+			var parts []string
+			for _, k := range todos.Kinds {
+				if counts[k] > 0 {
+					parts = append(parts, fmt.Sprintf("%d %s", counts[k], k))
+				}
+			}
+			fmt.Printf("Annotations (%s):\n", strings.Join(parts, ", "))
+			for _, it := range items {
+				fmt.Println(todoLine(it))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().StringVar(&packagePath, "package", "", "Only list annotations in this package, or below it with a trailing /...")
+	cmd.Flags().StringSliceVar(&kinds, "kind", nil, "Annotation kinds to list: todo, fixme, bug, deprecated (default todo,fixme,bug)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum annotations to list (0 = no limit)")
+	return cmd
+}
+
+// todoLine renders one annotation as "- file:line KIND(author): text".
+func todoLine(it todos.Item) string {
+	label := strings.ToUpper(it.Kind)
+	switch {
+	case it.Symbol != "":
+		label = "Deprecated " + it.Symbol
+	case it.Author != "":
+		label += "(" + it.Author + ")"
+	}
+	if it.Text == "" {
+		return fmt.Sprintf("- %s:%d %s", it.FilePath, it.Line, label)
+	}
+	return fmt.Sprintf("- %s:%d %s: %s", it.FilePath, it.Line, label, it.Text)
+}
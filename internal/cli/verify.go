@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/diagnostics"
+	"github.com/robertguss/recon/internal/knowledge"
+	"github.com/robertguss/recon/internal/sarif"
+	"github.com/spf13/cobra"
+)
+
+// verifyPayload is the evidence re-checked by `recon verify` plus the
+// schedule of the checks still ahead.
+type verifyPayload struct {
+	knowledge.RecheckResult
+	Schedule knowledge.Schedule `json:"schedule"`
+}
+
+// verifyIntervalPayload reports a `recon verify --set-interval` update.
+type verifyIntervalPayload struct {
+	EntityType     string `json:"entity_type"`
+	EntityID       int64  `json:"entity_id"`
+	VerifyInterval string `json:"verify_interval"`
+	Updated        int64  `json:"updated"`
+}
+
+func newVerifyCommand(app *App) *cobra.Command {
+	var (
+		jsonOut       bool
+		format        string
+		due           bool
+		skipToolchain bool
+		setInterval   time.Duration
+		entityRef     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Re-check decision, pattern, and constraint evidence",
+		Long: "Re-run the evidence checks of every active decision, pattern, and constraint and record\n" +
+			"drift, as sync does for the evidence its changes touch. Build, test, and command checks are\n" +
+			"skipped. With --due, re-check only evidence whose verify interval has elapsed, build, test,\n" +
+			"and command checks included unless --skip-toolchain is set. Intervals default to 24h, and\n" +
+			"168h for build, test, and command checks; --set-interval with --entity changes them for one\n" +
+			"entity's evidence.\n\n" +
+			"--format sarif prints a SARIF 2.1.0 log for GitHub code scanning after the re-check: the\n" +
+			"code linked to knowledge with drifting or broken evidence, and to anti-patterns, as\n" +
+			"`recon diagnostics` reports it.",
+		Example: "  recon verify\n  recon verify --due --skip-toolchain\n  recon verify --format sarif > drift.sarif\n" +
+			"  recon verify --set-interval 1h --entity decision:2",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			invalid := func(msg string, details map[string]any) error {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, details)
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			outFormat, err := parseFormat(format, "text", "sarif")
+			if err != nil {
+				return invalid(err.Error(), map[string]any{"format": strings.TrimSpace(format)})
+			}
+			setting := cmd.Flags().Changed("set-interval")
+			if setting != (entityRef != "") {
+				return invalid("--set-interval and --entity must be used together", map[string]any{"entity": entityRef})
+			}
+			if setting && due {
+				return invalid("--set-interval cannot be combined with --due", nil)
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+			svc := knowledge.NewService(conn)
+
+			if setting {
+				entityType, entityID, err := parseEntityRef(entityRef)
+				if err == nil && setInterval < 0 {
+					err = fmt.Errorf("--set-interval must not be negative")
+				}
+				if err != nil {
+					return invalid(err.Error(), map[string]any{"entity": entityRef})
+				}
+				n, err := svc.SetVerifyInterval(cmd.Context(), entityType, entityID, setInterval)
+				if err != nil {
+					return invalid(err.Error(), map[string]any{"entity": entityRef})
+				}
+				if n == 0 {
+					msg := fmt.Sprintf("no evidence found for %s", entityRef)
+					if jsonOut {
+						_ = writeJSONError("not_found", msg, map[string]any{"entity": entityRef})
+						return ExitError{Code: 2}
+					}
+					return ExitError{Code: 2, Message: msg}
+				}
+				if jsonOut {
+					return writeJSON(verifyIntervalPayload{
+						EntityType: entityType, EntityID: entityID, VerifyInterval: setInterval.String(), Updated: n,
+					})
+				}
+				if setInterval == 0 {
+					fmt.Printf("Restored the default verify interval for %d evidence rows of %s #%d\n", n, entityType, entityID)
+				} else {
+					fmt.Printf("Set the verify interval to %s for %d evidence rows of %s #%d\n", setInterval, n, entityType, entityID)
+				}
+				return nil
+			}
+
+			var result knowledge.RecheckResult
+			if due {
+				result, err = svc.VerifyDue(cmd.Context(), app.ModuleRoot, knowledge.DueOptions{SkipToolchain: skipToolchain})
+			} else {
+				result, err = svc.VerifyEvidence(cmd.Context(), app.ModuleRoot)
+			}
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+			sched, err := svc.VerifySchedule(cmd.Context(), time.Now())
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+
+			if jsonOut {
+				return writeJSON(verifyPayload{RecheckResult: result, Schedule: sched})
+			}
+			if outFormat == "sarif" {
+				files, err := diagnostics.NewService(conn).Collect(cmd.Context(), app.ModuleRoot, nil)
+				if err != nil {
+					return err
+				}
+				return writeJSON(diagnosticsSARIF(files))
+			}
+			printRecheck(result)
+			printSchedule(sched)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text or sarif")
+	cmd.Flags().BoolVar(&due, "due", false, "Re-check only evidence whose verify interval has elapsed")
+	cmd.Flags().BoolVar(&skipToolchain, "skip-toolchain", false, "With --due, leave build, test, and command checks for a later run")
+	cmd.Flags().DurationVar(&setInterval, "set-interval", 0, "Set the verify interval of the --entity evidence (0 restores the default)")
+	cmd.Flags().StringVar(&entityRef, "entity", "", "Entity whose evidence --set-interval updates, as type:id (e.g., decision:2)")
+	return cmd
+}
+
+// printSchedule renders how much evidence is due and when the next check is.
+func printSchedule(s knowledge.Schedule) {
+	next := s.NextDueAt
+	if next == "" {
+		next = "none scheduled"
+	}
+	fmt.Printf("Evidence due: %d | Next due: %s\n", s.Due, next)
+}
+
+// diagnosticsSARIF converts diagnostics to SARIF results, one per flagged
+// range.
+func diagnosticsSARIF(files []diagnostics.File) sarif.Log {
+	rules := []sarif.Rule{
+		sarif.NewRule(diagnostics.CodeDriftBroken, "Code linked to knowledge whose evidence check fails", sarif.LevelError),
+		sarif.NewRule(diagnostics.CodeDriftDrifting, "Code linked to knowledge whose evidence is drifting", sarif.LevelWarning),
+		sarif.NewRule(diagnostics.CodeAntiPattern, "Code recorded as contradicting a pattern", sarif.LevelWarning),
+	}
+	results := []sarif.Result{}
+	for _, f := range files {
+		for _, d := range f.Diagnostics {
+			level := sarif.LevelWarning
+			if d.Severity == diagnostics.SeverityError {
+				level = sarif.LevelError
+			}
+			results = append(results, sarif.Result{
+				RuleID:  d.Code,
+				Level:   level,
+				Message: sarif.Message{Text: d.Message},
+				// Diagnostic ranges are zero-based and end at the start of
+				// the line after the last flagged one.
+				Locations: []sarif.Location{sarif.FileLocation(f.Path, d.Range.Start.Line+1, d.Range.End.Line)},
+			})
+		}
+	}
+	return sarif.NewLog(Version, rules, results)
+}
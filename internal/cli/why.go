@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/robertguss/recon/internal/find"
+	"github.com/robertguss/recon/internal/why"
+	"github.com/spf13/cobra"
+)
+
+func newWhyCommand(app *App) *cobra.Command {
+	var (
+		jsonOut       bool
+		packageFilter string
+		fileFilter    string
+		kindFilter    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "why <path|symbol>",
+		Short: "Show the decisions, patterns, and constraints behind a file, package, or symbol",
+		Long: "Answer \"why is this code the way it is\": resolve the argument as an indexed file,\n" +
+			"then a package path, then a symbol the way find does, and list the knowledge linked\n" +
+			"to it, its file, its package, or its symbols, with reasoning and evidence status.\n" +
+			"History lists linked knowledge that was archived or superseded, and the entries\n" +
+			"it superseded in turn, so the reasoning behind earlier versions stays visible.",
+		Example: "  recon why internal/index/service.go\n  recon why internal/index\n  recon why Service.Sync --package internal/index --json",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				msg := "why requires a <path|symbol> argument"
+				if jsonOut {
+					_ = writeJSONError("missing_argument", msg, map[string]any{"command": "why"})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+
+			kind, err := normalizeFindKind(kindFilter)
+			if err != nil {
+				if jsonOut {
+					_ = writeJSONError("invalid_input", err.Error(), map[string]any{"kind": strings.TrimSpace(kindFilter)})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: err.Error()}
+			}
+
+			conn, err := openExistingDB(app)
+			if err != nil {
+				if jsonOut {
+					return exitJSONCommandError(err)
+				}
+				return err
+			}
+			defer conn.Close()
+
+			ref := normalizeFindPath(app.modulePath(args[0]))
+			opts := find.QueryOptions{
+				PackagePath: strings.TrimSpace(packageFilter),
+				FilePath:    normalizeFindPath(app.modulePath(fileFilter)),
+				Kind:        kind,
+			}
+			result, err := why.NewService(conn).Why(cmd.Context(), ref, opts)
+			switch {
+			case errors.Is(err, why.ErrFileNotIndexed):
+				msg := err.Error() + "; run `recon sync` if it is new"
+				if jsonOut {
+					_ = writeJSONError("not_found", msg, map[string]any{"path": ref})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			case err != nil:
+				var notFound find.NotFoundError
+				var ambiguous find.AmbiguousError
+				if errors.As(err, &notFound) || errors.As(err, &ambiguous) {
+					return writeFindLookupError(app, "why", ref, err, opts, jsonOut)
+				}
+				if jsonOut {
+					_ = writeJSONError("internal_error", err.Error(), nil)
+					return ExitError{Code: 2}
+				}
+				return err
+			}
+
+			app.applyPathMode(&result)
+			if jsonOut {
+				return writeJSON(result)
+			}
+			fmt.Print(why.RenderText(result))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().StringVar(&packageFilter, "package", "", "Filter by package path when a symbol is ambiguous")
+	cmd.Flags().StringVar(&fileFilter, "file", "", "Filter by file path when a symbol is ambiguous")
+	cmd.Flags().StringVar(&kindFilter, "kind", "", "Filter by symbol kind (func, method, type, var, const, enum, table, view, proto_service, proto_rpc, proto_message, proto_enum)")
+	return cmd
+}
@@ -0,0 +1,382 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/robertguss/recon/internal/index"
+	"github.com/robertguss/recon/internal/knowledge"
+	"github.com/robertguss/recon/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var runVerify = func(ctx context.Context, moduleRoot string) (knowledge.RecheckResult, error) {
+	conn, err := openExistingDB(&App{ModuleRoot: moduleRoot, NoPrompt: true})
+	if err != nil {
+		return knowledge.RecheckResult{}, err
+	}
+	defer conn.Close()
+	return knowledge.NewService(conn).VerifyEvidence(ctx, moduleRoot)
+}
+
+// workspacePayload is the combined report of a workspace batch command.
+type workspacePayload struct {
+	Repos  []workspace.Report `json:"repos"`
+	OK     int                `json:"ok"`
+	Failed int                `json:"failed"`
+}
+
+func newWorkspaceCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "workspace",
+		Aliases: []string{"repos"},
+		Short:   "Run recon across several registered repositories",
+		Long: "Keep a per-user list of Go modules and run sync, status, or verify across all of them.\n" +
+			"The registry lives at $" + workspace.EnvVar + ", or workspace.json in the user config directory.\n" +
+			"Each repository must already be initialized with `recon init`.\n\n" +
+			"A repo is named by its directory, or by --name when added; `recon find --repo <name>`\n" +
+			"and `recon recall --all-repos` query registered repos from anywhere.",
+	}
+	cmd.AddCommand(newWorkspaceAddCommand(app))
+	cmd.AddCommand(newWorkspaceRemoveCommand(app))
+	cmd.AddCommand(newWorkspaceListCommand())
+	cmd.AddCommand(newWorkspaceBatchCommand("sync", "Sync every registered repository", workspaceSync))
+	cmd.AddCommand(newWorkspaceBatchCommand("status", "Show index status for every registered repository", workspaceStatus))
+	cmd.AddCommand(newWorkspaceBatchCommand("verify", "Re-check all evidence in every registered repository", workspaceVerify))
+	return cmd
+}
+
+func newWorkspaceAddCommand(app *App) *cobra.Command {
+	var (
+		jsonOut bool
+		name    string
+	)
+	cmd := &cobra.Command{
+		Use:   "add [path...]",
+		Short: "Register module roots (default: the current module)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			roots, err := resolveWorkspaceRoots(app, args)
+			if err != nil {
+				return workspaceError(jsonOut, err)
+			}
+			if name == "" {
+				return updateWorkspace(jsonOut, roots, func(reg *workspace.Registry, root string) (bool, error) {
+					return reg.Add(root), nil
+				}, "Registered", "already registered")
+			}
+			if len(roots) != 1 {
+				return workspaceError(jsonOut, fmt.Errorf("--name names one repository; pass a single path"))
+			}
+			return updateWorkspace(jsonOut, roots, func(reg *workspace.Registry, root string) (bool, error) {
+				added := reg.Add(root)
+				if reg.Name(root) == name {
+					return added, nil
+				}
+				return true, reg.SetName(root, name)
+			}, "Registered as "+name+":", "already registered as "+name)
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().StringVar(&name, "name", "", "Name the repo for --repo (default: its directory name)")
+	return cmd
+}
+
+func newWorkspaceRemoveCommand(app *App) *cobra.Command {
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "remove [name|path...]",
+		Short: "Unregister repos by name or module root (default: the current module)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, reg, err := loadWorkspace()
+			if err != nil {
+				return workspaceError(jsonOut, err)
+			}
+			roots := []string{app.ModuleRoot}
+			if len(args) > 0 {
+				roots = roots[:0]
+				for _, arg := range args {
+					if root, err := reg.Resolve(arg); err == nil {
+						roots = append(roots, root)
+						continue
+					}
+					abs, err := filepath.Abs(arg)
+					if err != nil {
+						return workspaceError(jsonOut, fmt.Errorf("resolve %s: %w", arg, err))
+					}
+					roots = append(roots, abs)
+				}
+			}
+			return updateWorkspace(jsonOut, roots, func(reg *workspace.Registry, root string) (bool, error) {
+				return reg.Remove(root), nil
+			}, "Removed", "not registered")
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+func newWorkspaceListCommand() *cobra.Command {
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List registered repos by name and module root",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, reg, err := loadWorkspace()
+			if err != nil {
+				return workspaceError(jsonOut, err)
+			}
+			if jsonOut {
+				return writeJSON(reg)
+			}
+			if len(reg.Repos) == 0 {
+				fmt.Println("No repositories registered; run `recon workspace add` in one.")
+			}
+			width := 0
+			for _, root := range reg.Repos {
+				width = max(width, len(reg.Name(root)))
+			}
+			for _, root := range reg.Repos {
+				fmt.Printf("%-*s  %s\n", width, reg.Name(root), root)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+// workspaceOp runs one batch operation against a single repository and
+// returns its JSON result plus a one-line text summary.
+type workspaceOp func(ctx context.Context, root string) (any, string, error)
+
+func newWorkspaceBatchCommand(use, short string, op workspaceOp) *cobra.Command {
+	var (
+		jsonOut  bool
+		parallel int
+	)
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if parallel < 1 {
+				msg := "--parallel must be >= 1"
+				if jsonOut {
+					_ = writeJSONError("invalid_input", msg, map[string]any{"flag": "parallel", "value": parallel})
+					return ExitError{Code: 2}
+				}
+				return ExitError{Code: 2, Message: msg}
+			}
+			_, reg, err := loadWorkspace()
+			if err != nil {
+				return workspaceError(jsonOut, err)
+			}
+
+			reports := workspace.Run(cmd.Context(), reg.Repos, parallel, func(ctx context.Context, root string) (any, error) {
+				result, summary, err := op(ctx, root)
+				if err != nil {
+					return nil, err
+				}
+				return workspaceResult{result: result, summary: summary}, nil
+			})
+
+			// Unwrap the text summaries so JSON carries only the results.
+			payload := workspacePayload{Repos: reports}
+			summaries := make([]string, len(reports))
+			for i, r := range reports {
+				if !r.OK {
+					payload.Failed++
+					continue
+				}
+				payload.OK++
+				res := r.Result.(workspaceResult)
+				payload.Repos[i].Result = res.result
+				summaries[i] = res.summary
+			}
+
+			if jsonOut {
+				if err := writeJSON(payload); err != nil {
+					return err
+				}
+			} else {
+				if len(reports) == 0 {
+					fmt.Println("No repositories registered; run `recon workspace add` in one.")
+				}
+				for i, r := range reports {
+					if r.OK {
+						fmt.Printf("ok    %s: %s\n", r.Root, summaries[i])
+					} else {
+						fmt.Printf("FAIL  %s: %s\n", r.Root, r.Error)
+					}
+				}
+				if len(reports) > 0 {
+					fmt.Printf("%d ok, %d failed\n", payload.OK, payload.Failed)
+				}
+			}
+			if payload.Failed > 0 {
+				return ExitError{Code: 2}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	cmd.Flags().IntVar(&parallel, "parallel", 4, "Number of repositories to process at once")
+	return cmd
+}
+
+type workspaceResult struct {
+	result  any
+	summary string
+}
+
+func workspaceSync(ctx context.Context, root string) (any, string, error) {
+	cfg, err := loadConfig(root)
+	if err != nil {
+		return nil, "", err
+	}
+	conn, err := openExistingDB(&App{ModuleRoot: root, NoPrompt: true})
+	if err != nil {
+		return nil, "", err
+	}
+	defer conn.Close()
+
+	lock, err := index.LockSync(ctx, root, true)
+	if err != nil {
+		return nil, "", err
+	}
+	defer lock.Unlock()
+
+	result, err := runSync(ctx, conn, root, index.SyncOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	payload := syncPayload{SyncResult: result}
+	if recheck, err := runRecheck(ctx, conn, root, result.ChangedFiles); err == nil && recheck.Checked > 0 {
+		payload.Evidence = &recheck
+	}
+	if cfg.Knowledge.Files {
+		reconciled, err := runReconcile(ctx, conn, root)
+		if err != nil {
+			return nil, "", err
+		}
+		payload.KnowledgeFiles = &reconciled
+	}
+	return payload, fmt.Sprintf("synced %d files, %d symbols across %d packages",
+		result.IndexedFiles, result.IndexedSymbols, result.IndexedPackages), nil
+}
+
+func workspaceStatus(ctx context.Context, root string) (any, string, error) {
+	conn, err := openExistingDB(&App{ModuleRoot: root, NoPrompt: true})
+	if err != nil {
+		return nil, "", err
+	}
+	defer conn.Close()
+
+	payload, err := loadStatus(ctx, conn)
+	if err != nil {
+		return nil, "", err
+	}
+	lastSync := payload.LastSyncAt
+	if lastSync == "" {
+		lastSync = "never"
+	}
+	return payload, fmt.Sprintf("%d files, %d symbols, %d decisions (%d drifting), last sync %s",
+		payload.Counts.Files, payload.Counts.Symbols, payload.Counts.Decisions, payload.Counts.DecisionsDrifting, lastSync), nil
+}
+
+func workspaceVerify(ctx context.Context, root string) (any, string, error) {
+	result, err := runVerify(ctx, root)
+	if err != nil {
+		return nil, "", err
+	}
+	return result, fmt.Sprintf("checked %d evidence rows, %d changed", result.Checked, len(result.Changed)), nil
+}
+
+func loadWorkspace() (string, workspace.Registry, error) {
+	path, err := workspace.Path()
+	if err != nil {
+		return "", workspace.Registry{}, err
+	}
+	reg, err := workspace.Load(path)
+	if err != nil {
+		return "", workspace.Registry{}, err
+	}
+	return path, reg, nil
+}
+
+// repoApp returns a copy of app pointed at the registered repo called name,
+// for the commands that query another repository.
+func repoApp(app *App, name string) (*App, error) {
+	_, reg, err := loadWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	root, err := reg.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	repo := *app
+	repo.ModuleRoot = root
+	return &repo, nil
+}
+
+// resolveWorkspaceRoots maps each argument to the Go module containing it.
+func resolveWorkspaceRoots(app *App, args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{app.ModuleRoot}, nil
+	}
+	roots := make([]string, 0, len(args))
+	for _, arg := range args {
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", arg, err)
+		}
+		root, err := findModuleRoot(abs)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not inside a Go module: %w", arg, err)
+		}
+		roots = append(roots, root)
+	}
+	return roots, nil
+}
+
+func updateWorkspace(jsonOut bool, roots []string, apply func(*workspace.Registry, string) (bool, error), verb, noop string) error {
+	path, reg, err := loadWorkspace()
+	if err != nil {
+		return workspaceError(jsonOut, err)
+	}
+	changed := []string{}
+	for _, root := range roots {
+		ok, err := apply(&reg, root)
+		if err != nil {
+			return workspaceError(jsonOut, err)
+		}
+		if ok {
+			changed = append(changed, root)
+		} else if !jsonOut {
+			fmt.Printf("%s is %s\n", root, noop)
+		}
+	}
+	if len(changed) > 0 {
+		if err := reg.Save(path); err != nil {
+			return workspaceError(jsonOut, err)
+		}
+	}
+	if jsonOut {
+		return writeJSON(map[string]any{"changed": changed, "repos": reg.Repos, "names": reg.Names, "path": path})
+	}
+	for _, root := range changed {
+		fmt.Printf("%s %s\n", verb, root)
+	}
+	return nil
+}
+
+func workspaceError(jsonOut bool, err error) error {
+	if jsonOut {
+		_ = writeJSONError("workspace_error", err.Error(), nil)
+		return ExitError{Code: 2}
+	}
+	return ExitError{Code: 2, Message: err.Error()}
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/workspace"
+)
+
+func TestWorkspaceCommands(t *testing.T) {
+	t.Setenv(workspace.EnvVar, filepath.Join(t.TempDir(), "workspace.json"))
+	app := setupInitializedApp(t)
+	createTestDecision(t, app, "Workspace decision")
+	uninitialized := setupModuleRoot(t)
+
+	out, _, err := runCommandWithCapture(t, newWorkspaceCommand(app), []string{"add", "--json"})
+	if err != nil || !strings.Contains(out, app.ModuleRoot) {
+		t.Fatalf("add current module: %v (out=%q)", err, out)
+	}
+	out, _, err = runCommandWithCapture(t, newWorkspaceCommand(app), []string{"add", filepath.Join(uninitialized, "pkg1"), app.ModuleRoot})
+	if err != nil || !strings.Contains(out, "Registered "+uninitialized) || !strings.Contains(out, "already registered") {
+		t.Fatalf("add paths: %v (out=%q)", err, out)
+	}
+	if _, _, err := runCommandWithCapture(t, newWorkspaceCommand(app), []string{"add", t.TempDir()}); err == nil || !strings.Contains(err.Error(), "not inside a Go module") {
+		t.Fatalf("expected non-module error, got %v", err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newWorkspaceCommand(app), []string{"sync", "--parallel", "2", "--json"})
+	var payload struct {
+		Repos []struct {
+			Root   string          `json:"root"`
+			OK     bool            `json:"ok"`
+			Error  string          `json:"error"`
+			Result json.RawMessage `json:"result"`
+		} `json:"repos"`
+		OK     int `json:"ok"`
+		Failed int `json:"failed"`
+	}
+	if err == nil {
+		t.Fatalf("expected sync to fail for the uninitialized repo (out=%q)", out)
+	}
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		t.Fatalf("decode sync: %v (out=%q)", err, out)
+	}
+	if payload.OK != 1 || payload.Failed != 1 || len(payload.Repos) != 2 {
+		t.Fatalf("unexpected sync report: %+v", payload)
+	}
+	for _, r := range payload.Repos {
+		if r.Root == uninitialized && (r.OK || !strings.Contains(r.Error, "not initialized")) {
+			t.Fatalf("expected not-initialized failure, got %+v", r)
+		}
+		if r.Root == app.ModuleRoot && (!r.OK || !strings.Contains(string(r.Result), `"indexed_symbols"`)) {
+			t.Fatalf("expected sync result, got %+v", r)
+		}
+	}
+
+	out, _, err = runCommandWithCapture(t, newWorkspaceCommand(app), []string{"remove", uninitialized})
+	if err != nil || !strings.Contains(out, "Removed "+uninitialized) {
+		t.Fatalf("remove: %v (out=%q)", err, out)
+	}
+
+	out, _, err = runCommandWithCapture(t, newWorkspaceCommand(app), []string{"status"})
+	if err != nil || !strings.Contains(out, "ok    "+app.ModuleRoot) || !strings.Contains(out, "1 decisions") || !strings.Contains(out, "1 ok, 0 failed") {
+		t.Fatalf("status: %v (out=%q)", err, out)
+	}
+	out, _, err = runCommandWithCapture(t, newWorkspaceCommand(app), []string{"verify", "--json"})
+	if err != nil || !strings.Contains(out, `"checked": 1`) {
+		t.Fatalf("verify: %v (out=%q)", err, out)
+	}
+	out, _, err = runCommandWithCapture(t, newWorkspaceCommand(app), []string{"list"})
+	if err != nil || strings.TrimSpace(out) != filepath.Base(app.ModuleRoot)+"  "+app.ModuleRoot {
+		t.Fatalf("list: %v (out=%q)", err, out)
+	}
+
+	if _, _, err := runCommandWithCapture(t, newWorkspaceCommand(app), []string{"sync", "--parallel", "0"}); err == nil || !strings.Contains(err.Error(), "--parallel") {
+		t.Fatalf("expected --parallel error, got %v", err)
+	}
+}
+
+func TestReposNamesFindAndRecall(t *testing.T) {
+	defer func() { outputVersion, outputVersionPinned = legacyOutputVersion, false }()
+	t.Setenv(workspace.EnvVar, filepath.Join(t.TempDir(), "workspace.json"))
+	api := setupInitializedApp(t)
+	if _, _, err := runCommandWithCapture(t, newSyncCommand(api), nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	createTestDecision(t, api, "Retries are bounded")
+	web := setupInitializedApp(t)
+	createTestDecision(t, web, "Retries use jitter")
+
+	out, _, err := runCommandWithCapture(t, newWorkspaceCommand(api), []string{"add", "--name", "api"})
+	if err != nil || !strings.Contains(out, "Registered as api: "+api.ModuleRoot) {
+		t.Fatalf("add --name: %v (out=%q)", err, out)
+	}
+	if _, _, err := runCommandWithCapture(t, newWorkspaceCommand(web), []string{"add", "--name", "web"}); err != nil {
+		t.Fatalf("add web: %v", err)
+	}
+	if _, _, err := runCommandWithCapture(t, newWorkspaceCommand(web), []string{"add", "--name", "api"}); err == nil || !strings.Contains(err.Error(), "already used") {
+		t.Fatalf("expected taken name error, got %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newWorkspaceCommand(web), []string{"list"})
+	if err != nil || !strings.Contains(out, "api  "+api.ModuleRoot) || !strings.Contains(out, "web  "+web.ModuleRoot) {
+		t.Fatalf("list: %v (out=%q)", err, out)
+	}
+
+	// From web, find queries api's index.
+	out, _, err = runCommandWithCapture(t, newFindCommand(web), []string{"Alpha", "--repo", "api", "--json"})
+	if err != nil || !strings.Contains(out, `"name": "Alpha"`) {
+		t.Fatalf("find --repo: %v (out=%q)", err, out)
+	}
+	out, _, err = runCommandWithCapture(t, newFindCommand(web), []string{"Alpha", "--repo", "nope", "--json"})
+	if err == nil || !strings.Contains(out, "no registered repo named") {
+		t.Fatalf("expected unknown repo error, out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCommandWithCapture(t, newRecallCommand(web), []string{"Retries", "--all-repos", "--json"})
+	var payload allReposRecallPayload
+	if err != nil || json.Unmarshal([]byte(out), &payload) != nil || len(payload.Repos) != 2 {
+		t.Fatalf("recall --all-repos: %v (out=%q)", err, out)
+	}
+	for _, repo := range payload.Repos {
+		want := map[string]string{"api": "Retries are bounded", "web": "Retries use jitter"}[repo.Repo]
+		if len(repo.Items) != 1 || repo.Items[0].Title != want {
+			t.Fatalf("expected %q from %s, got %+v", want, repo.Repo, repo)
+		}
+	}
+
+	if err := setOutputVersion(latestOutputVersion, true); err != nil {
+		t.Fatalf("setOutputVersion: %v", err)
+	}
+	out, _, err = runCommandWithCapture(t, newRecallCommand(web), []string{"Retries", "--all-repos", "--format", "ndjson"})
+	if err != nil || strings.Count(out, "\n") != 2 || !strings.HasPrefix(out, `{"repo":"api",`) || !strings.Contains(out, `"drift_status":"ok"`) {
+		t.Fatalf("recall --all-repos --format ndjson: %v (out=%q)", err, out)
+	}
+
+	out, _, err = runCommandWithCapture(t, newWorkspaceCommand(web), []string{"remove", "api"})
+	if err != nil || !strings.Contains(out, "Removed "+api.ModuleRoot) {
+		t.Fatalf("remove by name: %v (out=%q)", err, out)
+	}
+	if _, _, err := runCommandWithCapture(t, newRecallCommand(web), []string{"Retries", "--all-repos", "--semantic"}); err == nil || !strings.Contains(err.Error(), "does not support --semantic") {
+		t.Fatalf("expected --semantic error, got %v", err)
+	}
+}
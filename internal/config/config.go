@@ -0,0 +1,264 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+// FileName is the project config file inside .recon/. Unlike the database it
+// is not gitignored, so a team can commit shared settings.
+const FileName = "config.json"
+
+// AutoSyncPolicy controls what orient does when the index is stale.
+type AutoSyncPolicy string
+
+const (
+	AutoSyncNever  AutoSyncPolicy = "never"
+	AutoSyncPrompt AutoSyncPolicy = "prompt"
+	AutoSyncAlways AutoSyncPolicy = "always"
+)
+
+type Config struct {
+	Freshness    Freshness    `json:"freshness"`
+	Knowledge    Knowledge    `json:"knowledge"`
+	Architecture Architecture `json:"architecture"`
+	Orient       Orient       `json:"orient"`
+	Heat         Heat         `json:"heat"`
+	Snapshots    Snapshots    `json:"snapshots"`
+	CI           CI           `json:"ci"`
+	Embeddings   Embeddings   `json:"embeddings"`
+}
+
+// Freshness holds the stale-index policy. An empty AutoSync leaves each entry
+// point on its built-in default: prompt for `recon orient`, always for
+// `recon orient --session-start` (which the installed hook runs).
+// MaxStaleness is a Go duration such as "24h": a stale index whose last sync
+// is younger than this is served with a warning instead of triggering the
+// policy.
+type Freshness struct {
+	AutoSync     AutoSyncPolicy `json:"auto_sync,omitempty"`
+	MaxStaleness string         `json:"max_staleness,omitempty"`
+
+	maxStaleness time.Duration
+}
+
+// Knowledge holds the evidence policy for new decisions and patterns.
+// DefaultConfidence replaces medium when `recon decide` or `recon pattern` is
+// run without --confidence. RequiredChecks maps a confidence level to the
+// check types that may back it, e.g. {"high": ["symbol_exists",
+// "go_test_passes"]} stops a bare file_exists check from recording a
+// high-confidence decision. Files mirrors active decisions and patterns to
+// YAML files under .recon/knowledge/ that `recon sync` reconciles with the
+// database in both directions, so knowledge can be reviewed in git.
+// AllowedCommands lists the commands, such as "go vet ./...", that
+// command_output checks may run; the checks are refused while it is empty.
+type Knowledge struct {
+	DefaultConfidence string              `json:"default_confidence,omitempty"`
+	RequiredChecks    map[string][]string `json:"required_checks,omitempty"`
+	Files             bool                `json:"files,omitempty"`
+	AllowedCommands   []string            `json:"allowed_commands,omitempty"`
+}
+
+// Orient holds defaults for `recon orient`. MinConfidence leaves decisions
+// and patterns below that confidence out of the payload; `recon recall`
+// still finds them. The --min-confidence flag overrides it.
+type Orient struct {
+	MinConfidence string `json:"min_confidence,omitempty"`
+}
+
+// Heat holds how `recon orient` and `recon find` classify package heat: a
+// package with Hot or more commits in the last WindowDays days is hot, one
+// with Warm or more is warm, the rest cold. Zero fields keep the defaults of
+// 30 days, 4 commits, and 1 commit.
+type Heat struct {
+	WindowDays int `json:"window_days,omitempty"`
+	Hot        int `json:"hot,omitempty"`
+	Warm       int `json:"warm,omitempty"`
+}
+
+// Snapshots holds the retention policy of `recon snapshot`: Keep is how many
+// snapshots are retained, newest first. Zero leaves the default of 10.
+type Snapshots struct {
+	Keep int `json:"keep,omitempty"`
+}
+
+// Policy is how `recon ci` treats the findings of one check.
+type Policy string
+
+const (
+	PolicyFail Policy = "fail"
+	PolicyWarn Policy = "warn"
+	PolicyOff  Policy = "off"
+)
+
+// CI holds the failure policy of each `recon ci` check: fail the run, warn
+// and pass, or skip the check. Empty fields keep the defaults of failing on
+// drifting or broken evidence and on import cycles and layering violations,
+// and warning on dangling edges.
+type CI struct {
+	Drift         Policy `json:"drift,omitempty"`
+	Architecture  Policy `json:"architecture,omitempty"`
+	DanglingEdges Policy `json:"dangling_edges,omitempty"`
+}
+
+// Embeddings opts into `recon embed` and `recon recall --semantic`. Provider
+// is "local", a hashing model that runs offline, or "openai", any server
+// speaking the OpenAI embeddings API. URL, Model, and APIKeyEnv (the
+// environment variable holding the key) apply to "openai" and default to
+// the OpenAI API, text-embedding-3-small, and OPENAI_API_KEY. An empty
+// Provider leaves embeddings off.
+type Embeddings struct {
+	Provider  string `json:"provider,omitempty"`
+	Model     string `json:"model,omitempty"`
+	URL       string `json:"url,omitempty"`
+	APIKeyEnv string `json:"api_key_env,omitempty"`
+}
+
+// Architecture declares the import rules `recon lint-arch` enforces. Layers
+// are ordered from the top (entry points) down: a package may import packages
+// of its own layer or lower ones, never higher. A package belongs to the
+// first layer with a matching pattern. ForbiddenImports hold regardless of
+// layers. Patterns are module-relative package paths such as "internal/db",
+// with "/..." matching everything below.
+type Architecture struct {
+	Layers           []Layer           `json:"layers,omitempty"`
+	ForbiddenImports []ForbiddenImport `json:"forbidden_imports,omitempty"`
+}
+
+type Layer struct {
+	Name     string   `json:"name"`
+	Packages []string `json:"packages"`
+}
+
+// ForbiddenImport forbids packages matching From (every package when empty)
+// from importing packages matching To.
+type ForbiddenImport struct {
+	From   string `json:"from,omitempty"`
+	To     string `json:"to"`
+	Reason string `json:"reason,omitempty"`
+}
+
+var (
+	confidenceLevels   = []string{"low", "medium", "high"}
+	embeddingProviders = []string{"local", "openai"}
+	checkTypes         = []string{"file_exists", "file_absent", "symbol_exists", "symbol_count", "grep_pattern", "grep_absent", "not_grep_pattern", "import_absent", "go_build_passes", "go_test_passes", "command_output"}
+)
+
+// Path returns the config file location for a module root.
+func Path(root string) string {
+	return filepath.Join(db.ReconDir(root), FileName)
+}
+
+// Load reads .recon/config.json. A missing file yields the zero Config.
+func Load(root string) (Config, error) {
+	var cfg Config
+	raw, err := os.ReadFile(Path(root))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return Config{}, fmt.Errorf("read %s: %w", filepath.Join(db.ReconDirName, FileName), err)
+	}
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parse %s: %w", filepath.Join(db.ReconDirName, FileName), err)
+	}
+	if err := cfg.validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid %s: %w", filepath.Join(db.ReconDirName, FileName), err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) validate() error {
+	switch c.Freshness.AutoSync {
+	case "", AutoSyncNever, AutoSyncPrompt, AutoSyncAlways:
+	default:
+		return fmt.Errorf("freshness.auto_sync must be one of: never, prompt, always")
+	}
+	if c.Freshness.MaxStaleness != "" {
+		d, err := time.ParseDuration(c.Freshness.MaxStaleness)
+		if err != nil || d < 0 {
+			return fmt.Errorf("freshness.max_staleness must be a non-negative duration such as \"24h\"")
+		}
+		c.Freshness.maxStaleness = d
+	}
+	if c.Knowledge.DefaultConfidence != "" && !slices.Contains(confidenceLevels, c.Knowledge.DefaultConfidence) {
+		return fmt.Errorf("knowledge.default_confidence must be one of: %s", strings.Join(confidenceLevels, ", "))
+	}
+	for level, types := range c.Knowledge.RequiredChecks {
+		if !slices.Contains(confidenceLevels, level) {
+			return fmt.Errorf("knowledge.required_checks keys must be one of: %s (got %q)", strings.Join(confidenceLevels, ", "), level)
+		}
+		if len(types) == 0 {
+			return fmt.Errorf("knowledge.required_checks.%s must list at least one check type", level)
+		}
+		for _, t := range types {
+			if !slices.Contains(checkTypes, t) {
+				return fmt.Errorf("knowledge.required_checks.%s: unknown check type %q (expected one of: %s)", level, t, strings.Join(checkTypes, ", "))
+			}
+		}
+	}
+	for i, command := range c.Knowledge.AllowedCommands {
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("knowledge.allowed_commands[%d] must not be empty", i)
+		}
+	}
+	if c.Orient.MinConfidence != "" && !slices.Contains(confidenceLevels, c.Orient.MinConfidence) {
+		return fmt.Errorf("orient.min_confidence must be one of: %s", strings.Join(confidenceLevels, ", "))
+	}
+	if c.Heat.WindowDays < 0 || c.Heat.Hot < 0 || c.Heat.Warm < 0 {
+		return fmt.Errorf("heat.window_days, heat.hot, and heat.warm must not be negative")
+	}
+	if c.Heat.Warm > 0 && c.Heat.Hot > 0 && c.Heat.Warm > c.Heat.Hot {
+		return fmt.Errorf("heat.warm must not exceed heat.hot")
+	}
+	if c.Snapshots.Keep < 0 {
+		return fmt.Errorf("snapshots.keep must not be negative")
+	}
+	for _, p := range []struct {
+		name   string
+		policy Policy
+	}{
+		{"ci.drift", c.CI.Drift},
+		{"ci.architecture", c.CI.Architecture},
+		{"ci.dangling_edges", c.CI.DanglingEdges},
+	} {
+		if p.policy != "" && !p.policy.Valid() {
+			return fmt.Errorf("%s must be one of: fail, warn, off", p.name)
+		}
+	}
+	if c.Embeddings.Provider != "" && !slices.Contains(embeddingProviders, c.Embeddings.Provider) {
+		return fmt.Errorf("embeddings.provider must be one of: %s", strings.Join(embeddingProviders, ", "))
+	}
+	for i, layer := range c.Architecture.Layers {
+		if strings.TrimSpace(layer.Name) == "" {
+			return fmt.Errorf("architecture.layers[%d].name is required", i)
+		}
+		if len(layer.Packages) == 0 {
+			return fmt.Errorf("architecture.layers[%d] (%s) must list at least one package pattern", i, layer.Name)
+		}
+	}
+	for i, rule := range c.Architecture.ForbiddenImports {
+		if strings.TrimSpace(rule.To) == "" {
+			return fmt.Errorf("architecture.forbidden_imports[%d].to is required", i)
+		}
+	}
+	return nil
+}
+
+// MaxStalenessDuration returns the parsed max_staleness, or 0 when unset.
+func (f Freshness) MaxStalenessDuration() time.Duration {
+	return f.maxStaleness
+}
+
+// Valid reports whether p is one of the known policies.
+func (p Policy) Valid() bool {
+	return p == PolicyFail || p == PolicyWarn || p == PolicyOff
+}
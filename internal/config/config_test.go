@@ -0,0 +1,123 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".recon"), 0o755); err != nil {
+		t.Fatalf("mkdir .recon: %v", err)
+	}
+	if err := os.WriteFile(Path(root), []byte(body), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return root
+}
+
+func TestLoadMissingFileReturnsDefaults(t *testing.T) {
+	cfg, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Freshness.AutoSync != "" || cfg.Freshness.MaxStalenessDuration() != 0 {
+		t.Fatalf("expected zero config, got %+v", cfg)
+	}
+}
+
+func TestLoadFreshnessPolicy(t *testing.T) {
+	root := writeConfig(t, `{"freshness":{"auto_sync":"never","max_staleness":"36h"}}`)
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Freshness.AutoSync != AutoSyncNever {
+		t.Fatalf("expected never, got %q", cfg.Freshness.AutoSync)
+	}
+	if cfg.Freshness.MaxStalenessDuration() != 36*time.Hour {
+		t.Fatalf("expected 36h, got %v", cfg.Freshness.MaxStalenessDuration())
+	}
+}
+
+func TestLoadKnowledgePolicy(t *testing.T) {
+	root := writeConfig(t, `{"knowledge":{"default_confidence":"low","required_checks":{"high":["symbol_exists","go_test_passes"]}}}`)
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Knowledge.DefaultConfidence != "low" {
+		t.Fatalf("expected low, got %q", cfg.Knowledge.DefaultConfidence)
+	}
+	if got := strings.Join(cfg.Knowledge.RequiredChecks["high"], ","); got != "symbol_exists,go_test_passes" {
+		t.Fatalf("unexpected required checks %q", got)
+	}
+}
+
+func TestLoadArchitecture(t *testing.T) {
+	root := writeConfig(t, `{"architecture":{"layers":[{"name":"entry","packages":["cmd/..."]},{"name":"core","packages":["internal/..."]}],"forbidden_imports":[{"from":"internal/db","to":"internal/cli","reason":"db is a leaf"}]}}`)
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Architecture.Layers) != 2 || cfg.Architecture.Layers[1].Packages[0] != "internal/..." {
+		t.Fatalf("unexpected layers %+v", cfg.Architecture.Layers)
+	}
+	if rules := cfg.Architecture.ForbiddenImports; len(rules) != 1 || rules[0].To != "internal/cli" || rules[0].Reason != "db is a leaf" {
+		t.Fatalf("unexpected forbidden imports %+v", rules)
+	}
+}
+
+func TestLoadCIPolicies(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `{"ci":{"drift":"warn","dangling_edges":"fail"}}`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.CI.Drift != PolicyWarn || cfg.CI.DanglingEdges != PolicyFail || cfg.CI.Architecture != "" {
+		t.Fatalf("unexpected ci policies %+v", cfg.CI)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name, body, want string
+	}{
+		{"malformed json", `{"freshness":`, "parse .recon/config.json"},
+		{"unknown policy", `{"freshness":{"auto_sync":"sometimes"}}`, "freshness.auto_sync must be one of"},
+		{"bad duration", `{"freshness":{"max_staleness":"a day"}}`, "freshness.max_staleness must be"},
+		{"negative duration", `{"freshness":{"max_staleness":"-1h"}}`, "freshness.max_staleness must be"},
+		{"unknown default confidence", `{"knowledge":{"default_confidence":"certain"}}`, "knowledge.default_confidence must be one of"},
+		{"unknown required level", `{"knowledge":{"required_checks":{"certain":["file_exists"]}}}`, "knowledge.required_checks keys must be one of"},
+		{"empty required checks", `{"knowledge":{"required_checks":{"high":[]}}}`, "knowledge.required_checks.high must list"},
+		{"unknown check type", `{"knowledge":{"required_checks":{"high":["vibes"]}}}`, `unknown check type "vibes"`},
+		{"empty allowed command", `{"knowledge":{"allowed_commands":["go vet ./...", " "]}}`, "knowledge.allowed_commands[1] must not be empty"},
+		{"unknown orient min confidence", `{"orient":{"min_confidence":"certain"}}`, "orient.min_confidence must be one of"},
+		{"negative heat window", `{"heat":{"window_days":-7}}`, "heat.window_days, heat.hot, and heat.warm must not be negative"},
+		{"warm above hot", `{"heat":{"hot":2,"warm":3}}`, "heat.warm must not exceed heat.hot"},
+		{"negative snapshot keep", `{"snapshots":{"keep":-1}}`, "snapshots.keep must not be negative"},
+		{"unknown ci policy", `{"ci":{"dangling_edges":"error"}}`, "ci.dangling_edges must be one of: fail, warn, off"},
+		{"unknown embeddings provider", `{"embeddings":{"provider":"magic"}}`, "embeddings.provider must be one of: local, openai"},
+		{"unnamed layer", `{"architecture":{"layers":[{"packages":["cmd/..."]}]}}`, "architecture.layers[0].name is required"},
+		{"empty layer", `{"architecture":{"layers":[{"name":"entry"}]}}`, "architecture.layers[0] (entry) must list"},
+		{"forbidden import without target", `{"architecture":{"forbidden_imports":[{"from":"cmd/..."}]}}`, "architecture.forbidden_imports[0].to is required"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tc.body))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+
+	root := t.TempDir()
+	if err := os.MkdirAll(Path(root), 0o755); err != nil {
+		t.Fatalf("mkdir config path: %v", err)
+	}
+	if _, err := Load(root); err == nil || !strings.Contains(err.Error(), "read .recon/config.json") {
+		t.Fatalf("expected read error for directory, got %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package constraint
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/knowledge"
+)
+
+var jsonMarshal = json.Marshal
+
+// DefaultCheckType is the check a constraint is verified with when none is
+// given: a grep that passes only while the forbidden pattern is absent.
+const DefaultCheckType = "grep_absent"
+
+type ProposeConstraintInput struct {
+	Title           string
+	Reasoning       string
+	Confidence      string
+	EvidenceSummary string
+	CheckType       string
+	CheckSpec       string
+	ModuleRoot      string
+	Policy          knowledge.EvidencePolicy
+	// VerifyInterval is how often `recon verify --due` re-checks the
+	// evidence; zero uses the check type default.
+	VerifyInterval time.Duration
+}
+
+type ProposeConstraintResult struct {
+	ProposalID          int64  `json:"proposal_id"`
+	ConstraintID        int64  `json:"constraint_id,omitempty"`
+	Promoted            bool   `json:"promoted"`
+	VerificationPassed  bool   `json:"verification_passed"`
+	VerificationDetails string `json:"verification_details"`
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// ProposeAndVerifyConstraint records a hard rule. Like a pattern, it is
+// promoted only when its check passes; for the default grep_absent check that
+// means the code does not already break the rule.
+func (s *Service) ProposeAndVerifyConstraint(ctx context.Context, in ProposeConstraintInput) (ProposeConstraintResult, error) {
+	if strings.TrimSpace(in.Title) == "" {
+		return ProposeConstraintResult{}, fmt.Errorf("title is required")
+	}
+	if strings.TrimSpace(in.EvidenceSummary) == "" {
+		return ProposeConstraintResult{}, fmt.Errorf("evidence summary is required")
+	}
+	if strings.TrimSpace(in.CheckType) == "" {
+		return ProposeConstraintResult{}, fmt.Errorf("check type is required")
+	}
+	if strings.TrimSpace(in.CheckSpec) == "" {
+		return ProposeConstraintResult{}, fmt.Errorf("check spec is required")
+	}
+
+	confidence, err := in.Policy.Resolve(in.Confidence, in.CheckType)
+	if err != nil {
+		return ProposeConstraintResult{}, err
+	}
+
+	outcome := knowledge.NewService(s.db).RunCheckPublic(ctx, in.CheckType, in.CheckSpec, in.ModuleRoot)
+
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	entityData := map[string]any{
+		"title":            in.Title,
+		"reasoning":        in.Reasoning,
+		"confidence":       confidence,
+		"evidence_summary": in.EvidenceSummary,
+		"check_type":       in.CheckType,
+		"check_spec":       in.CheckSpec,
+	}
+	if in.VerifyInterval > 0 {
+		entityData["verify_interval"] = int64(in.VerifyInterval / time.Second)
+	}
+	entityDataJSON, err := jsonMarshal(entityData)
+	if err != nil {
+		return ProposeConstraintResult{}, fmt.Errorf("marshal proposal data: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ProposeConstraintResult{}, fmt.Errorf("begin constraint tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO proposals (session_id, entity_type, entity_data, status, proposed_at)
+VALUES (NULL, 'constraint', ?, 'pending', ?);
+`, string(entityDataJSON), now)
+	if err != nil {
+		return ProposeConstraintResult{}, fmt.Errorf("insert proposal: %w", err)
+	}
+	proposalID, _ := res.LastInsertId()
+
+	baselineJSON, _ := jsonMarshal(outcome.Baseline)
+	lastResultJSON, _ := jsonMarshal(map[string]any{"passed": outcome.Passed, "details": outcome.Details})
+
+	if !outcome.Passed {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO evidence (entity_type, entity_id, summary, check_type, check_spec, baseline, last_verified_at, last_result, drift_status)
+VALUES ('proposal', ?, ?, ?, ?, ?, ?, ?, 'broken');
+`, proposalID, "verification failed: "+outcome.Details, in.CheckType, in.CheckSpec, string(baselineJSON), now, string(lastResultJSON)); err != nil {
+			return ProposeConstraintResult{}, fmt.Errorf("insert proposal evidence: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return ProposeConstraintResult{}, fmt.Errorf("commit pending constraint tx: %w", err)
+		}
+		return ProposeConstraintResult{ProposalID: proposalID, VerificationDetails: outcome.Details}, nil
+	}
+
+	constraintID, err := promoteConstraint(ctx, tx, proposalID, in, confidence, string(baselineJSON), string(lastResultJSON), now)
+	if err != nil {
+		return ProposeConstraintResult{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ProposeConstraintResult{}, fmt.Errorf("commit constraint tx: %w", err)
+	}
+	return ProposeConstraintResult{
+		ProposalID:          proposalID,
+		ConstraintID:        constraintID,
+		Promoted:            true,
+		VerificationPassed:  true,
+		VerificationDetails: outcome.Details,
+	}, nil
+}
+
+// promoteConstraint records the constraint a proposal describes once its
+// check passed, with its evidence and search entry, and marks the proposal
+// promoted.
+func promoteConstraint(ctx context.Context, tx *sql.Tx, proposalID int64, in ProposeConstraintInput, confidence, baseline, lastResult, now string) (int64, error) {
+	constraintRes, err := tx.ExecContext(ctx, `
+INSERT INTO constraints (title, reasoning, confidence, status, created_at, updated_at)
+VALUES (?, ?, ?, 'active', ?, ?);
+`, in.Title, in.Reasoning, confidence, now, now)
+	if err != nil {
+		return 0, fmt.Errorf("insert constraint: %w", err)
+	}
+	constraintID, _ := constraintRes.LastInsertId()
+
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO evidence (entity_type, entity_id, summary, check_type, check_spec, baseline, last_verified_at, last_result, drift_status, verify_interval)
+VALUES ('constraint', ?, ?, ?, ?, ?, ?, ?, 'ok', ?);
+`, constraintID, in.EvidenceSummary, in.CheckType, in.CheckSpec, baseline, now, lastResult, int64(in.VerifyInterval/time.Second)); err != nil {
+		return 0, fmt.Errorf("insert constraint evidence: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+UPDATE proposals SET status = 'promoted', verified_at = ?, promoted_at = ? WHERE id = ?;
+`, now, now, proposalID); err != nil {
+		return 0, fmt.Errorf("update proposal: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO search_index (title, content, entity_type, entity_id)
+VALUES (?, ?, 'constraint', ?);
+`, in.Title, in.Reasoning+"\n"+in.EvidenceSummary, constraintID); err != nil {
+		return 0, fmt.Errorf("insert search index: %w", err)
+	}
+	return constraintID, nil
+}
+
+// RetryConstraintProposal re-runs the check of a pending constraint proposal
+// against the current code and promotes the constraint when it passes.
+func (s *Service) RetryConstraintProposal(ctx context.Context, id int64, moduleRoot string) (ProposeConstraintResult, error) {
+	knowledgeSvc := knowledge.NewService(s.db)
+	p, err := knowledgeSvc.PendingProposal(ctx, id)
+	if err != nil {
+		return ProposeConstraintResult{}, err
+	}
+	if p.EntityType != "constraint" {
+		return ProposeConstraintResult{}, fmt.Errorf("proposal %d is a %s, not a constraint", id, p.EntityType)
+	}
+	in := ProposeConstraintInput{
+		Title: p.Title, Reasoning: p.Reasoning, EvidenceSummary: p.EvidenceSummary,
+		CheckType: p.CheckType, CheckSpec: p.CheckSpec, ModuleRoot: moduleRoot,
+		VerifyInterval: time.Duration(p.VerifyInterval) * time.Second,
+	}
+
+	outcome := knowledgeSvc.RunCheckPublic(ctx, in.CheckType, in.CheckSpec, moduleRoot)
+	if !outcome.Passed {
+		if err := knowledgeSvc.RecordProposalCheck(ctx, id, outcome); err != nil {
+			return ProposeConstraintResult{}, err
+		}
+		return ProposeConstraintResult{ProposalID: id, VerificationDetails: outcome.Details}, nil
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	baselineJSON, _ := jsonMarshal(outcome.Baseline)
+	lastResultJSON, _ := jsonMarshal(map[string]any{"passed": outcome.Passed, "details": outcome.Details})
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ProposeConstraintResult{}, fmt.Errorf("begin constraint tx: %w", err)
+	}
+	defer tx.Rollback()
+	constraintID, err := promoteConstraint(ctx, tx, id, in, p.Confidence, string(baselineJSON), string(lastResultJSON), now)
+	if err != nil {
+		return ProposeConstraintResult{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM evidence WHERE entity_type = 'proposal' AND entity_id = ?;`, id); err != nil {
+		return ProposeConstraintResult{}, fmt.Errorf("delete proposal evidence: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return ProposeConstraintResult{}, fmt.Errorf("commit constraint tx: %w", err)
+	}
+	return ProposeConstraintResult{
+		ProposalID:          id,
+		ConstraintID:        constraintID,
+		Promoted:            true,
+		VerificationPassed:  true,
+		VerificationDetails: outcome.Details,
+	}, nil
+}
+
+var ErrNotFound = fmt.Errorf("not found")
+
+func (s *Service) ArchiveConstraint(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE constraints SET status = 'archived', updated_at = ? WHERE id = ? AND status = 'active';`,
+		time.Now().UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return fmt.Errorf("archive constraint: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("constraint %d: %w", id, ErrNotFound)
+	}
+	return nil
+}
+
+type ConstraintListItem struct {
+	ID         int64  `json:"id"`
+	Title      string `json:"title"`
+	Reasoning  string `json:"reasoning,omitempty"`
+	Confidence string `json:"confidence"`
+	Drift      string `json:"drift_status"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// ListConstraints returns the active constraints, most recently updated
+// first, with the drift status of their latest evidence.
+func (s *Service) ListConstraints(ctx context.Context) ([]ConstraintListItem, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT c.id, c.title, c.reasoning, c.confidence,
+       COALESCE((SELECT e.drift_status FROM entity_evidence e
+                 WHERE e.entity_type = 'constraint' AND e.entity_id = c.id
+                 ORDER BY e.id DESC LIMIT 1), 'ok'),
+       c.updated_at
+FROM constraints c
+WHERE c.status = 'active'
+ORDER BY c.updated_at DESC, c.id DESC;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query constraints: %w", err)
+	}
+	defer rows.Close()
+	items := []ConstraintListItem{}
+	for rows.Next() {
+		var item ConstraintListItem
+		if err := rows.Scan(&item.ID, &item.Title, &item.Reasoning, &item.Confidence, &item.Drift, &item.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan constraint: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,172 @@
+package constraint
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/knowledge"
+)
+
+func constraintTestDB(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+	root := t.TempDir()
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/test\n"), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "cmd"), 0o755); err != nil {
+		t.Fatalf("mkdir cmd: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "cmd", "main.go"), []byte("package main\nimport \"example.com/test/internal/cli\"\nfunc main() { cli.Run() }\n"), 0o644); err != nil {
+		t.Fatalf("write cmd/main.go: %v", err)
+	}
+	return conn, root
+}
+
+func TestProposeAndVerifyConstraint(t *testing.T) {
+	conn, root := constraintTestDB(t)
+	svc := NewService(conn)
+	ctx := context.Background()
+
+	result, err := svc.ProposeAndVerifyConstraint(ctx, ProposeConstraintInput{
+		Title:           "Never import internal/db from cmd",
+		Reasoning:       "cmd only wires the CLI",
+		Confidence:      "high",
+		EvidenceSummary: "no cmd file imports internal/db",
+		CheckType:       DefaultCheckType,
+		CheckSpec:       `{"pattern":"internal/db\"","scope":"cmd/*.go"}`,
+		ModuleRoot:      root,
+	})
+	if err != nil {
+		t.Fatalf("ProposeAndVerifyConstraint: %v", err)
+	}
+	if !result.Promoted || result.ConstraintID == 0 || !strings.Contains(result.VerificationDetails, "matched 0 of 1") {
+		t.Fatalf("expected promoted constraint, got %+v", result)
+	}
+
+	var entityType string
+	if err := conn.QueryRow(`SELECT entity_type FROM search_index WHERE entity_id = ?`, result.ConstraintID).Scan(&entityType); err != nil || entityType != "constraint" {
+		t.Fatalf("search index entity = %q, %v", entityType, err)
+	}
+
+	// A rule the code already breaks stays a pending proposal.
+	pending, err := svc.ProposeAndVerifyConstraint(ctx, ProposeConstraintInput{
+		Title:           "Never import internal/cli from cmd",
+		EvidenceSummary: "no cmd file imports internal/cli",
+		CheckType:       DefaultCheckType,
+		CheckSpec:       `{"pattern":"internal/cli\"","scope":"cmd/*.go"}`,
+		ModuleRoot:      root,
+	})
+	if err != nil {
+		t.Fatalf("ProposeAndVerifyConstraint pending: %v", err)
+	}
+	if pending.Promoted || pending.VerificationPassed || pending.ProposalID == 0 {
+		t.Fatalf("expected pending proposal, got %+v", pending)
+	}
+
+	items, err := svc.ListConstraints(ctx)
+	if err != nil {
+		t.Fatalf("ListConstraints: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != result.ConstraintID || items[0].Confidence != "high" || items[0].Drift != "ok" || items[0].Reasoning != "cmd only wires the CLI" {
+		t.Fatalf("items = %+v", items)
+	}
+
+	if err := svc.ArchiveConstraint(ctx, result.ConstraintID); err != nil {
+		t.Fatalf("ArchiveConstraint: %v", err)
+	}
+	if err := svc.ArchiveConstraint(ctx, result.ConstraintID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second archive = %v, want ErrNotFound", err)
+	}
+	if items, err := svc.ListConstraints(ctx); err != nil || len(items) != 0 {
+		t.Fatalf("items after archive = %+v, %v", items, err)
+	}
+}
+
+func TestProposeAndVerifyConstraintValidation(t *testing.T) {
+	conn, root := constraintTestDB(t)
+	svc := NewService(conn)
+
+	for _, tc := range []struct {
+		in   ProposeConstraintInput
+		want string
+	}{
+		{ProposeConstraintInput{}, "title is required"},
+		{ProposeConstraintInput{Title: "t"}, "evidence summary is required"},
+		{ProposeConstraintInput{Title: "t", EvidenceSummary: "e"}, "check type is required"},
+		{ProposeConstraintInput{Title: "t", EvidenceSummary: "e", CheckType: DefaultCheckType}, "check spec is required"},
+	} {
+		if _, err := svc.ProposeAndVerifyConstraint(context.Background(), tc.in); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("input %+v: err = %v, want %q", tc.in, err, tc.want)
+		}
+	}
+
+	policy := knowledge.EvidencePolicy{RequiredChecks: map[string][]string{"high": {"go_test_passes"}}}
+	_, err := svc.ProposeAndVerifyConstraint(context.Background(), ProposeConstraintInput{
+		Title: "t", EvidenceSummary: "e", Confidence: "high", CheckType: DefaultCheckType,
+		CheckSpec: `{"pattern":"x"}`, ModuleRoot: root, Policy: policy,
+	})
+	if !errors.Is(err, knowledge.ErrEvidenceTooWeak) {
+		t.Fatalf("policy err = %v, want ErrEvidenceTooWeak", err)
+	}
+}
+
+func TestListConstraintsQueryError(t *testing.T) {
+	conn, _ := constraintTestDB(t)
+	if _, err := conn.Exec(`DROP TABLE constraints`); err != nil {
+		t.Fatalf("drop constraints: %v", err)
+	}
+	if _, err := NewService(conn).ListConstraints(context.Background()); err == nil || !strings.Contains(err.Error(), "query constraints") {
+		t.Fatalf("expected query error, got %v", err)
+	}
+}
+
+func TestRetryConstraintProposal(t *testing.T) {
+	conn, root := constraintTestDB(t)
+	svc := NewService(conn)
+	ctx := context.Background()
+
+	result, err := svc.ProposeAndVerifyConstraint(ctx, ProposeConstraintInput{
+		Title:           "Never call cli.Run from cmd",
+		Reasoning:       "cmd should stay empty",
+		EvidenceSummary: "no cmd file calls cli.Run",
+		CheckType:       DefaultCheckType,
+		CheckSpec:       `{"pattern":"cli\\.Run","scope":"cmd/*.go"}`,
+		ModuleRoot:      root,
+	})
+	if err != nil || result.Promoted {
+		t.Fatalf("propose: %+v, %v", result, err)
+	}
+
+	retried, err := svc.RetryConstraintProposal(ctx, result.ProposalID, root)
+	if err != nil || retried.Promoted {
+		t.Fatalf("retry while violated = %+v, %v", retried, err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "cmd", "main.go"), []byte("package main\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatalf("write cmd/main.go: %v", err)
+	}
+	retried, err = svc.RetryConstraintProposal(ctx, result.ProposalID, root)
+	if err != nil || !retried.Promoted || retried.ConstraintID == 0 {
+		t.Fatalf("retry after fix = %+v, %v", retried, err)
+	}
+	list, err := svc.ListConstraints(ctx)
+	if err != nil || len(list) != 1 || list[0].Title != "Never call cli.Run from cmd" {
+		t.Fatalf("ListConstraints = %+v, %v", list, err)
+	}
+}
@@ -0,0 +1,396 @@
+package coverage
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LowThreshold is the statement coverage percentage below which a package
+// is reported as a coverage gap.
+const LowThreshold = 50.0
+
+// ErrNoIndexedFiles is returned by Import when none of the profile's files
+// are in the index, which usually means the profile is from another module
+// or the index needs a sync.
+var ErrNoIndexedFiles = errors.New("no cover profile files are in the index")
+
+// ErrInvalidProfile is returned when a cover profile cannot be parsed.
+var ErrInvalidProfile = errors.New("invalid cover profile")
+
+// Coverage is statement coverage: how many of a symbol's or package's
+// statements ran at least once.
+type Coverage struct {
+	Statements int     `json:"statements"`
+	Covered    int     `json:"covered"`
+	Percent    float64 `json:"percent"`
+}
+
+// Low reports whether c is below LowThreshold.
+func (c Coverage) Low() bool {
+	return c.Statements > 0 && c.Percent < LowThreshold
+}
+
+func newCoverage(statements, covered int) Coverage {
+	c := Coverage{Statements: statements, Covered: covered}
+	if statements > 0 {
+		c.Percent = math.Round(float64(covered)*1000/float64(statements)) / 10
+	}
+	return c
+}
+
+// Block is one line of a cover profile: a source range, its statement
+// count, and how often it ran.
+type Block struct {
+	File       string
+	StartLine  int
+	EndLine    int
+	Statements int
+	Count      int
+}
+
+// ImportResult reports an Import. UnmatchedFiles lists profile files that
+// are not in the index; their blocks are skipped.
+type ImportResult struct {
+	Mode           string   `json:"mode"`
+	Files          int      `json:"files"`
+	Symbols        int      `json:"symbols"`
+	Total          Coverage `json:"total"`
+	UnmatchedFiles []string `json:"unmatched_files,omitempty"`
+}
+
+// SymbolGap is a function or method of a package and its coverage, as
+// listed for coverage gaps.
+type SymbolGap struct {
+	Name     string   `json:"name"`
+	Receiver string   `json:"receiver,omitempty"`
+	FilePath string   `json:"file_path"`
+	Coverage Coverage `json:"coverage"`
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// ParseProfile reads a profile written by `go test -coverprofile`. Blocks
+// repeated across merged profiles are collapsed, keeping the highest count.
+func ParseProfile(r io.Reader) (string, []Block, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	mode := ""
+	seen := map[string]int{}
+	var blocks []Block
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if m, ok := strings.CutPrefix(line, "mode: "); ok {
+			if mode == "" {
+				mode = m
+			}
+			continue
+		}
+		if mode == "" {
+			return "", nil, fmt.Errorf("%w: line %d: missing mode line", ErrInvalidProfile, lineNo)
+		}
+		b, err := parseBlock(line)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: line %d: %v", ErrInvalidProfile, lineNo, err)
+		}
+		key := strings.Fields(line)[0]
+		if i, ok := seen[key]; ok {
+			blocks[i].Count = max(blocks[i].Count, b.Count)
+			continue
+		}
+		seen[key] = len(blocks)
+		blocks = append(blocks, b)
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, fmt.Errorf("read cover profile: %w", err)
+	}
+	if mode == "" {
+		return "", nil, fmt.Errorf("%w: empty profile", ErrInvalidProfile)
+	}
+	return mode, blocks, nil
+}
+
+// parseBlock parses "file.go:startLine.startCol,endLine.endCol statements count".
+func parseBlock(line string) (Block, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	colon := strings.LastIndexByte(fields[0], ':')
+	if colon < 0 {
+		return Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	start, end, ok := strings.Cut(fields[0][colon+1:], ",")
+	if !ok {
+		return Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	b := Block{File: fields[0][:colon]}
+	var err error
+	if b.StartLine, err = positionLine(start); err != nil {
+		return Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	if b.EndLine, err = positionLine(end); err != nil {
+		return Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	if b.Statements, err = strconv.Atoi(fields[1]); err != nil {
+		return Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	if b.Count, err = strconv.Atoi(fields[2]); err != nil {
+		return Block{}, fmt.Errorf("malformed block %q", line)
+	}
+	return b, nil
+}
+
+func positionLine(pos string) (int, error) {
+	line, _, _ := strings.Cut(pos, ".")
+	return strconv.Atoi(line)
+}
+
+type indexedFunc struct {
+	name, receiver string
+	start, end     int
+}
+
+type indexedFile struct {
+	path, pkg string
+	funcs     []indexedFunc
+}
+
+// Import replaces the stored coverage with the coverage of profile. Profile
+// files are matched to indexed files by import path (the form `go test`
+// writes) or by module-relative path, and each block is credited to the
+// innermost function or method whose lines enclose it.
+func (s *Service) Import(ctx context.Context, profile io.Reader) (ImportResult, error) {
+	mode, blocks, err := ParseProfile(profile)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	files, err := s.indexedFiles(ctx)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	type key struct{ file, name, receiver string }
+	type tally struct {
+		pkg                 string
+		statements, covered int
+	}
+	tallies := map[key]*tally{}
+	matched := map[string]bool{}
+	unmatched := map[string]bool{}
+	result := ImportResult{Mode: mode, UnmatchedFiles: []string{}}
+	for _, b := range blocks {
+		f, ok := files[b.File]
+		if !ok {
+			f, ok = files[strings.TrimPrefix(b.File, "./")]
+		}
+		if !ok {
+			unmatched[b.File] = true
+			continue
+		}
+		matched[f.path] = true
+		fn, ok := enclosingFunc(f.funcs, b)
+		if !ok {
+			continue
+		}
+		k := key{f.path, fn.name, fn.receiver}
+		t := tallies[k]
+		if t == nil {
+			t = &tally{pkg: f.pkg}
+			tallies[k] = t
+		}
+		t.statements += b.Statements
+		if b.Count > 0 {
+			t.covered += b.Statements
+		}
+	}
+	for f := range unmatched {
+		result.UnmatchedFiles = append(result.UnmatchedFiles, f)
+	}
+	sort.Strings(result.UnmatchedFiles)
+	result.Files = len(matched)
+	if result.Files == 0 {
+		return ImportResult{}, ErrNoIndexedFiles
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("begin coverage tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM symbol_coverage;`); err != nil {
+		return ImportResult{}, fmt.Errorf("clear coverage: %w", err)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	var statements, covered int
+	for k, t := range tallies {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO symbol_coverage (package_path, file_path, name, receiver, statements, covered, imported_at)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+`, t.pkg, k.file, k.name, k.receiver, t.statements, t.covered, now); err != nil {
+			return ImportResult{}, fmt.Errorf("insert coverage: %w", err)
+		}
+		statements += t.statements
+		covered += t.covered
+	}
+	if err := tx.Commit(); err != nil {
+		return ImportResult{}, fmt.Errorf("commit coverage tx: %w", err)
+	}
+
+	result.Symbols = len(tallies)
+	result.Total = newCoverage(statements, covered)
+	return result, nil
+}
+
+// indexedFiles maps each indexed Go file, under both its import-path form
+// and its module-relative path, to its functions and methods.
+func (s *Service) indexedFiles(ctx context.Context) (map[string]*indexedFile, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT f.path, p.path, p.import_path, COALESCE(s.name, ''), COALESCE(s.receiver, ''),
+       COALESCE(s.line_start, 0), COALESCE(s.line_end, 0)
+FROM files f
+JOIN packages p ON p.id = f.package_id
+LEFT JOIN symbols s ON s.file_id = f.id AND s.kind IN ('func', 'method')
+ORDER BY f.path, s.line_start;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query indexed functions: %w", err)
+	}
+	defer rows.Close()
+
+	files := map[string]*indexedFile{}
+	for rows.Next() {
+		var (
+			filePath, pkgPath, importPath string
+			fn                            indexedFunc
+		)
+		if err := rows.Scan(&filePath, &pkgPath, &importPath, &fn.name, &fn.receiver, &fn.start, &fn.end); err != nil {
+			return nil, fmt.Errorf("scan indexed function: %w", err)
+		}
+		f := files[filePath]
+		if f == nil {
+			f = &indexedFile{path: filePath, pkg: pkgPath}
+			files[filePath] = f
+			files[importPath+"/"+path.Base(filePath)] = f
+		}
+		if fn.name != "" {
+			f.funcs = append(f.funcs, fn)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate indexed functions: %w", err)
+	}
+	return files, nil
+}
+
+// enclosingFunc returns the narrowest function whose lines contain b.
+func enclosingFunc(funcs []indexedFunc, b Block) (indexedFunc, bool) {
+	var best indexedFunc
+	found := false
+	for _, fn := range funcs {
+		if fn.start <= b.StartLine && b.EndLine <= fn.end &&
+			(!found || fn.end-fn.start < best.end-best.start) {
+			best, found = fn, true
+		}
+	}
+	return best, found
+}
+
+// Packages returns the stored coverage of each package, keyed by
+// module-relative package path.
+func (s *Service) Packages(ctx context.Context) (map[string]Coverage, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT package_path, SUM(statements), SUM(covered) FROM symbol_coverage GROUP BY package_path;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query package coverage: %w", err)
+	}
+	defer rows.Close()
+
+	out := map[string]Coverage{}
+	for rows.Next() {
+		var (
+			pkg                 string
+			statements, covered int
+		)
+		if err := rows.Scan(&pkg, &statements, &covered); err != nil {
+			return nil, fmt.Errorf("scan package coverage: %w", err)
+		}
+		out[pkg] = newCoverage(statements, covered)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate package coverage: %w", err)
+	}
+	return out, nil
+}
+
+// Symbol returns the stored coverage of a function or method. ok is false
+// when the imported profile had no statements for it.
+func (s *Service) Symbol(ctx context.Context, filePath, name, receiver string) (Coverage, bool, error) {
+	var statements, covered int
+	err := s.db.QueryRowContext(ctx, `
+SELECT statements, covered FROM symbol_coverage WHERE file_path = ? AND name = ? AND receiver = ?;
+`, filePath, name, receiver).Scan(&statements, &covered)
+	if err == sql.ErrNoRows {
+		return Coverage{}, false, nil
+	}
+	if err != nil {
+		return Coverage{}, false, fmt.Errorf("query symbol coverage: %w", err)
+	}
+	return newCoverage(statements, covered), true, nil
+}
+
+// LeastCovered returns up to limit functions and methods of a package with
+// uncovered statements, those with the most uncovered statements first.
+func (s *Service) LeastCovered(ctx context.Context, pkgPath string, limit int) ([]SymbolGap, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT name, receiver, file_path, statements, covered
+FROM symbol_coverage
+WHERE package_path = ? AND covered < statements
+ORDER BY statements - covered DESC, file_path, name
+LIMIT ?;
+`, pkgPath, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query least covered symbols: %w", err)
+	}
+	defer rows.Close()
+
+	gaps := []SymbolGap{}
+	for rows.Next() {
+		var (
+			g                   SymbolGap
+			statements, covered int
+		)
+		if err := rows.Scan(&g.Name, &g.Receiver, &g.FilePath, &statements, &covered); err != nil {
+			return nil, fmt.Errorf("scan least covered symbol: %w", err)
+		}
+		g.Coverage = newCoverage(statements, covered)
+		gaps = append(gaps, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate least covered symbols: %w", err)
+	}
+	return gaps, nil
+}
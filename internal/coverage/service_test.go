@@ -0,0 +1,130 @@
+package coverage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func coverageTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	root := t.TempDir()
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	for _, stmt := range []string{
+		`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES
+			(1,'.','main','example.com/m',1,20,'x','x'),
+			(2,'internal/store','store','example.com/m/internal/store',1,40,'x','x')`,
+		`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES
+			(1,1,'main.go','go',20,'h','x','x'),
+			(2,2,'internal/store/store.go','go',40,'h','x','x')`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES
+			(1,1,'func','main','func main()',3,10,0,''),
+			(2,2,'type','Store','type Store struct{}',3,5,1,''),
+			(3,2,'method','Get','func (s *Store) Get()',7,20,1,'*Store'),
+			(4,2,'func','Open','func Open()',22,40,1,'')`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	return conn
+}
+
+const testProfile = `mode: set
+example.com/m/main.go:3.13,5.2 2 1
+example.com/m/internal/store/store.go:7.30,9.3 3 1
+example.com/m/internal/store/store.go:9.3,12.3 2 0
+example.com/m/internal/store/store.go:22.20,30.2 4 0
+example.com/m/internal/store/store.go:31.2,40.2 6 0
+example.com/m/internal/store/store.go:31.2,40.2 6 1
+example.com/m/gen/gen.go:1.1,2.2 1 1
+`
+
+func TestImport(t *testing.T) {
+	conn := coverageTestDB(t)
+	svc := NewService(conn)
+	ctx := context.Background()
+
+	res, err := svc.Import(ctx, strings.NewReader(testProfile))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	// main 2/2, Get 3/5, Open 6/10 (the repeated block counts as run).
+	if res.Mode != "set" || res.Files != 2 || res.Symbols != 3 || res.Total != (Coverage{Statements: 17, Covered: 11, Percent: 64.7}) {
+		t.Fatalf("Import = %+v", res)
+	}
+	if strings.Join(res.UnmatchedFiles, ",") != "example.com/m/gen/gen.go" {
+		t.Fatalf("unmatched = %v", res.UnmatchedFiles)
+	}
+
+	pkgs, err := svc.Packages(ctx)
+	if err != nil {
+		t.Fatalf("Packages: %v", err)
+	}
+	if pkgs["internal/store"] != (Coverage{Statements: 15, Covered: 9, Percent: 60}) || pkgs["."].Percent != 100 {
+		t.Fatalf("Packages = %+v", pkgs)
+	}
+
+	c, ok, err := svc.Symbol(ctx, "internal/store/store.go", "Get", "*Store")
+	if err != nil || !ok || c != (Coverage{Statements: 5, Covered: 3, Percent: 60}) {
+		t.Fatalf("Symbol Get = %+v, %t, %v", c, ok, err)
+	}
+	if _, ok, err := svc.Symbol(ctx, "internal/store/store.go", "Store", ""); err != nil || ok {
+		t.Fatalf("Symbol Store = %t, %v; want no coverage", ok, err)
+	}
+
+	least, err := svc.LeastCovered(ctx, "internal/store", 5)
+	if err != nil || len(least) != 2 || least[0].Name != "Open" || least[1].Receiver != "*Store" {
+		t.Fatalf("LeastCovered = %+v, %v", least, err)
+	}
+
+	// A second import replaces the first; module-relative paths match too.
+	res, err = svc.Import(ctx, strings.NewReader("mode: count\n./main.go:3.13,5.2 2 0\n"))
+	if err != nil || res.Files != 1 || res.Total.Percent != 0 {
+		t.Fatalf("reimport = %+v, %v", res, err)
+	}
+	if pkgs, _ := svc.Packages(ctx); len(pkgs) != 1 {
+		t.Fatalf("reimport kept old coverage: %+v", pkgs)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	svc := NewService(coverageTestDB(t))
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name, profile string
+		want          error
+	}{
+		{"empty", "", ErrInvalidProfile},
+		{"no mode", "example.com/m/main.go:3.13,5.2 2 1\n", ErrInvalidProfile},
+		{"malformed block", "mode: set\nexample.com/m/main.go 2 1\n", ErrInvalidProfile},
+		{"bad count", "mode: set\nexample.com/m/main.go:3.13,5.2 2 x\n", ErrInvalidProfile},
+		{"other module", "mode: set\nexample.com/other/a.go:1.1,2.2 1 1\n", ErrNoIndexedFiles},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.Import(ctx, strings.NewReader(tc.profile)); !errors.Is(err, tc.want) {
+				t.Fatalf("Import = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestCoverageLow(t *testing.T) {
+	if !newCoverage(10, 4).Low() || newCoverage(10, 5).Low() || newCoverage(0, 0).Low() {
+		t.Fatal("Low threshold misapplied")
+	}
+}
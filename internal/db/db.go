@@ -4,9 +4,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
@@ -34,41 +37,82 @@ func EnsureReconDir(root string) (string, error) {
 	return dir, nil
 }
 
+// BusyTimeout is how long a connection waits on another process's lock,
+// such as a sync committing, before a statement fails with SQLITE_BUSY.
+const BusyTimeout = 30 * time.Second
+
+// Open opens the database at path. Every connection waits up to
+// BusyTimeout for locks, enforces foreign keys, and begins transactions
+// with an immediate write lock, so two writers queue instead of failing
+// when the second upgrades its lock. A database Open creates is put in
+// write-ahead logging mode, where readers never wait on a sync; an existing
+// database keeps the journal mode stored in it.
 func Open(path string) (*sql.DB, error) {
-	conn, err := sqlOpen("sqlite", path)
+	start := time.Now()
+	_, statErr := os.Stat(path)
+	created := errors.Is(statErr, os.ErrNotExist) && path != ":memory:"
+
+	conn, err := sqlOpen(driverName(), dsn(path))
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite db: %w", err)
 	}
 	conn.SetMaxOpenConns(1)
 
-	if _, err := conn.Exec("PRAGMA foreign_keys = ON;"); err != nil {
+	if err := conn.Ping(); err != nil {
 		_ = conn.Close()
-		return nil, fmt.Errorf("enable foreign keys: %w", err)
+		return nil, fmt.Errorf("open sqlite db: %w", err)
 	}
+	if created {
+		var mode string
+		if err := conn.QueryRow("PRAGMA journal_mode = WAL;").Scan(&mode); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("enable wal: %w", err)
+		}
+	}
+	slog.Debug("db open", "path", path, "duration", time.Since(start))
 	return conn, nil
 }
 
-func EnsureGitIgnore(root string) error {
-	target := ".recon/recon.db"
+// dsn adds the per-connection settings Open applies to path.
+func dsn(path string) string {
+	q := url.Values{}
+	q.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", BusyTimeout.Milliseconds()))
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Set("_txlock", "immediate")
+	return path + "?" + q.Encode()
+}
+
+// WALFiles are the files SQLite keeps next to a database in write-ahead
+// logging mode, as .gitignore entries.
+var WALFiles = []string{".recon/recon.db-wal", ".recon/recon.db-shm"}
+
+// EnsureGitIgnore adds .recon/recon.db, and any extra entries, to the
+// module's .gitignore unless a line already names them.
+func EnsureGitIgnore(root string, extra ...string) error {
 	path := filepath.Join(root, ".gitignore")
 
 	raw, err := os.ReadFile(path)
 	if err != nil && !errors.Is(err, os.ErrNotExist) {
 		return fmt.Errorf("read .gitignore: %w", err)
 	}
-	if strings.Contains(string(raw), target) {
-		return nil
+	present := map[string]bool{}
+	for _, line := range strings.Split(string(raw), "\n") {
+		present[strings.TrimSpace(line)] = true
 	}
 
-	var next string
-	if len(raw) == 0 {
-		next = target + "\n"
-	} else {
-		next = string(raw)
-		if !strings.HasSuffix(next, "\n") {
+	next := string(raw)
+	for _, target := range append([]string{".recon/recon.db"}, extra...) {
+		if present[target] {
+			continue
+		}
+		if next != "" && !strings.HasSuffix(next, "\n") {
 			next += "\n"
 		}
 		next += target + "\n"
+		present[target] = true
+	}
+	if next == string(raw) {
+		return nil
 	}
 
 	if err := os.WriteFile(path, []byte(next), 0o644); err != nil {
@@ -76,3 +120,36 @@ func EnsureGitIgnore(root string) error {
 	}
 	return nil
 }
+
+// EnableWAL switches the database behind conn to write-ahead logging, so
+// readers such as `recon serve` don't block on a running sync. The mode is
+// stored in the database file and outlives conn.
+func EnableWAL(conn *sql.DB) error {
+	var mode string
+	if err := conn.QueryRow("PRAGMA journal_mode = WAL;").Scan(&mode); err != nil {
+		return fmt.Errorf("enable wal: %w", err)
+	}
+	if !strings.EqualFold(mode, "wal") {
+		return fmt.Errorf("enable wal: journal mode is %s", mode)
+	}
+	return nil
+}
+
+// DisableWAL switches the database behind conn back to a rollback journal,
+// folding the write-ahead log into the database file.
+func DisableWAL(conn *sql.DB) error {
+	var mode string
+	if err := conn.QueryRow("PRAGMA journal_mode = DELETE;").Scan(&mode); err != nil {
+		return fmt.Errorf("disable wal: %w", err)
+	}
+	if strings.EqualFold(mode, "wal") {
+		return fmt.Errorf("disable wal: journal mode is %s", mode)
+	}
+	return nil
+}
+
+// EscapeLike escapes the LIKE wildcards in s, so identifiers and paths
+// containing '_' or '%' match literally in a pattern with ESCAPE '\'.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
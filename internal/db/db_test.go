@@ -1,10 +1,13 @@
 package db
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -80,6 +83,126 @@ func TestEnsureGitIgnore(t *testing.T) {
 	}
 }
 
+func TestEnsureGitIgnoreExtraEntries(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte(".recon/recon.db\n"), 0o644); err != nil {
+		t.Fatalf("seed .gitignore: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := EnsureGitIgnore(root, WALFiles...); err != nil {
+			t.Fatalf("EnsureGitIgnore(WALFiles): %v", err)
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(root, ".gitignore"))
+	if err != nil {
+		t.Fatalf("read .gitignore: %v", err)
+	}
+	if got := string(b); got != ".recon/recon.db\n.recon/recon.db-wal\n.recon/recon.db-shm\n" {
+		t.Fatalf("unexpected .gitignore content: %q", got)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if got, want := EscapeLike(`a_b%c\d`), `a\_b\%c\\d`; got != want {
+		t.Fatalf("EscapeLike() = %q, want %q", got, want)
+	}
+}
+
+func TestEnableWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal.db")
+	conn, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := EnableWAL(conn); err != nil {
+		t.Fatalf("EnableWAL: %v", err)
+	}
+	_ = conn.Close()
+
+	conn, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer conn.Close()
+	var mode string
+	if err := conn.QueryRow("PRAGMA journal_mode;").Scan(&mode); err != nil || mode != "wal" {
+		t.Fatalf("expected the wal mode to persist, got %q (%v)", mode, err)
+	}
+
+	memory, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("Open memory: %v", err)
+	}
+	defer memory.Close()
+	if err := EnableWAL(memory); err == nil || !strings.Contains(err.Error(), "journal mode is memory") {
+		t.Fatalf("expected in-memory database to refuse wal, got %v", err)
+	}
+}
+
+func TestOpenConfiguresConnections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "open.db")
+	writer, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer writer.Close()
+
+	var (
+		mode        string
+		timeout     int
+		foreignKeys int
+	)
+	if err := writer.QueryRow("PRAGMA journal_mode;").Scan(&mode); err != nil || mode != "wal" {
+		t.Fatalf("expected a new database in wal mode, got %q (%v)", mode, err)
+	}
+	if err := writer.QueryRow("PRAGMA busy_timeout;").Scan(&timeout); err != nil || timeout != int(BusyTimeout.Milliseconds()) {
+		t.Fatalf("busy_timeout = %d (%v), want %d", timeout, err, BusyTimeout.Milliseconds())
+	}
+	if err := writer.QueryRow("PRAGMA foreign_keys;").Scan(&foreignKeys); err != nil || foreignKeys != 1 {
+		t.Fatalf("foreign_keys = %d (%v), want 1", foreignKeys, err)
+	}
+	if _, err := writer.Exec("CREATE TABLE t (v INTEGER); INSERT INTO t VALUES (1);"); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	// A reader sees the last commit while a write transaction is open.
+	tx, err := writer.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("INSERT INTO t VALUES (2);"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	reader, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open reader: %v", err)
+	}
+	defer reader.Close()
+	var count int
+	if err := reader.QueryRow("SELECT COUNT(*) FROM t;").Scan(&count); err != nil || count != 1 {
+		t.Fatalf("read during write = %d (%v), want 1", count, err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	// An existing database keeps the journal mode stored in it.
+	_ = reader.Close()
+	if err := DisableWAL(writer); err != nil {
+		t.Fatalf("DisableWAL: %v", err)
+	}
+	_ = writer.Close()
+	conn, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.QueryRow("PRAGMA journal_mode;").Scan(&mode); err != nil || mode != "delete" {
+		t.Fatalf("expected the rollback journal to persist, got %q (%v)", mode, err)
+	}
+}
+
 func TestEnsureGitIgnoreReadAndWriteErrors(t *testing.T) {
 	fileRoot := filepath.Join(t.TempDir(), "rootfile")
 	if err := os.WriteFile(fileRoot, []byte("x"), 0o644); err != nil {
@@ -278,6 +401,56 @@ func TestRunMigrationsInjectedErrors(t *testing.T) {
 	}
 }
 
+func TestMigrationStatusAndRollback(t *testing.T) {
+	ctx := context.Background()
+	conn, err := Open(filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+
+	migrations, err := Migrations()
+	if err != nil || len(migrations) == 0 {
+		t.Fatalf("Migrations = %v, %v", migrations, err)
+	}
+	latest, _ := LatestMigrationVersion()
+	if last := migrations[len(migrations)-1]; last.Version != latest || last.Name == "" || migrations[0].Version != 1 {
+		t.Fatalf("unexpected migrations %v, latest %d", migrations, latest)
+	}
+
+	status, err := ReadMigrationStatus(ctx, conn)
+	if err != nil || status.Version != -1 || len(status.Applied) != 0 || len(status.Pending) != len(migrations) || status.Latest != latest {
+		t.Fatalf("fresh status = %+v, %v", status, err)
+	}
+
+	if err := RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	if err := RollbackMigrations(conn, 1); err != nil {
+		t.Fatalf("RollbackMigrations: %v", err)
+	}
+	status, err = ReadMigrationStatus(ctx, conn)
+	if err != nil || status.Version != latest-1 || status.Dirty || len(status.Pending) != 1 || status.Pending[0].Version != latest {
+		t.Fatalf("status after rollback = %+v, %v", status, err)
+	}
+	if err := RunMigrations(conn); err != nil {
+		t.Fatalf("re-apply: %v", err)
+	}
+	if status, err = ReadMigrationStatus(ctx, conn); err != nil || status.Version != latest || len(status.Pending) != 0 || len(status.Applied) != len(migrations) {
+		t.Fatalf("status after re-apply = %+v, %v", status, err)
+	}
+
+	if err := RollbackMigrations(conn, 0); err == nil || !strings.Contains(err.Error(), "steps must be positive") {
+		t.Fatalf("expected zero steps refused, got %v", err)
+	}
+	origSteps := migrateSteps
+	t.Cleanup(func() { migrateSteps = origSteps })
+	migrateSteps = func(*migrate.Migrate, int) error { return errors.New("steps fail") }
+	if err := RollbackMigrations(conn, 1); err == nil || !strings.Contains(err.Error(), "roll back migrations: steps fail") {
+		t.Fatalf("expected wrapped steps error, got %v", err)
+	}
+}
+
 func TestMigration003PatternsTable(t *testing.T) {
 	root := t.TempDir()
 	if _, err := EnsureReconDir(root); err != nil {
@@ -305,6 +478,59 @@ func TestMigration003PatternsTable(t *testing.T) {
 	}
 }
 
+func TestSyncHistory(t *testing.T) {
+	root := t.TempDir()
+	if _, err := EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := Open(DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if err := RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+
+	ctx := context.Background()
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i, run := range []SyncRun{
+		{SyncedAt: base.Add(-48 * time.Hour), Mode: "full", IndexedFiles: 3, IndexedSymbols: 10, FilesAdded: 3},
+		{SyncedAt: base.Add(time.Hour), Mode: "files", Commit: "def", IndexedFiles: 4, IndexedSymbols: 12, FilesAdded: 1, DurationMS: 85},
+		{SyncedAt: base, Mode: "full", Commit: "abc", Dirty: true, IndexedFiles: 3, IndexedSymbols: 11, FilesModified: 2},
+	} {
+		if err := AppendSyncRun(ctx, conn, run); err != nil {
+			t.Fatalf("AppendSyncRun %d: %v", i, err)
+		}
+	}
+
+	runs, err := ListSyncRuns(ctx, conn, base)
+	if err != nil {
+		t.Fatalf("ListSyncRuns: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Commit != "abc" || !runs[0].Dirty || runs[0].FilesModified != 2 || !runs[0].SyncedAt.Equal(base) ||
+		runs[1].Mode != "files" || runs[1].IndexedSymbols != 12 || runs[1].Dirty || runs[1].DurationMS != 85 {
+		t.Fatalf("unexpected sync runs: %+v", runs)
+	}
+
+	if _, err := conn.Exec("UPDATE sync_history SET synced_at = 'x' WHERE commit_hash = 'def';"); err != nil {
+		t.Fatalf("set invalid time: %v", err)
+	}
+	if _, err := ListSyncRuns(ctx, conn, time.Time{}); err == nil || !strings.Contains(err.Error(), "parse sync timestamp") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+
+	if _, err := conn.Exec("DROP TABLE sync_history;"); err != nil {
+		t.Fatalf("drop sync_history: %v", err)
+	}
+	if err := AppendSyncRun(ctx, conn, SyncRun{Mode: "full"}); err == nil || !strings.Contains(err.Error(), "record sync run") {
+		t.Fatalf("expected record error, got %v", err)
+	}
+	if _, err := ListSyncRuns(ctx, conn, base); err == nil || !strings.Contains(err.Error(), "query sync history") {
+		t.Fatalf("expected query error, got %v", err)
+	}
+}
+
 func TestRunMigrationsUpgradesLegacySymbolDepsSchema(t *testing.T) {
 	root := t.TempDir()
 	conn, err := Open(filepath.Join(root, "legacy.db"))
@@ -314,7 +540,17 @@ func TestRunMigrationsUpgradesLegacySymbolDepsSchema(t *testing.T) {
 	defer conn.Close()
 
 	if _, err := conn.Exec(`
+CREATE TABLE files (
+    id INTEGER PRIMARY KEY
+);
 CREATE TABLE symbols (
+    id INTEGER PRIMARY KEY,
+    body TEXT
+);
+CREATE TABLE decisions (
+    id INTEGER PRIMARY KEY
+);
+CREATE TABLE evidence (
     id INTEGER PRIMARY KEY
 );
 CREATE TABLE symbol_deps (
@@ -325,7 +561,7 @@ CREATE TABLE symbol_deps (
 );
 CREATE TABLE schema_migrations (version uint64, dirty bool);
 INSERT INTO schema_migrations (version, dirty) VALUES (1, 0);
-INSERT INTO symbols (id) VALUES (1);
+INSERT INTO symbols (id, body) VALUES (1, 'func Helper() {}');
 INSERT INTO symbol_deps (id, symbol_id, dep_name) VALUES (1, 1, 'Helper');
 `); err != nil {
 		t.Fatalf("seed legacy schema: %v", err)
@@ -335,6 +571,11 @@ INSERT INTO symbol_deps (id, symbol_id, dep_name) VALUES (1, 1, 'Helper');
 		t.Fatalf("RunMigrations upgrade: %v", err)
 	}
 
+	var body string
+	if err := conn.QueryRow(`SELECT b.body FROM symbols s JOIN symbol_bodies b ON b.hash = s.body_hash WHERE s.id = 1;`).Scan(&body); err != nil || body != "func Helper() {}" {
+		t.Fatalf("expected the legacy body to move to symbol_bodies, got %q, %v", body, err)
+	}
+
 	colRows, err := conn.Query(`PRAGMA table_info(symbol_deps);`)
 	if err != nil {
 		t.Fatalf("table_info symbol_deps: %v", err)
@@ -384,3 +625,103 @@ VALUES (1, 'Helper', 'internal/util', 'func');
 		t.Fatal("expected duplicate contextual dependency insert to fail")
 	}
 }
+
+func TestLatestMigrationVersionMatchesMigratedSchema(t *testing.T) {
+	latest, err := LatestMigrationVersion()
+	if err != nil {
+		t.Fatalf("LatestMigrationVersion: %v", err)
+	}
+	ddl, err := CurrentSchema(context.Background())
+	if err != nil {
+		t.Fatalf("CurrentSchema: %v", err)
+	}
+	if want := fmt.Sprintf("-- recon schema version %d\n", latest); !strings.HasPrefix(ddl, want) {
+		t.Fatalf("latest = %d, schema header %q", latest, ddl[:min(len(ddl), 40)])
+	}
+}
+
+func TestCurrentSchemaReplaysIntoEmptyDatabase(t *testing.T) {
+	ctx := context.Background()
+	ddl, err := CurrentSchema(ctx)
+	if err != nil {
+		t.Fatalf("CurrentSchema: %v", err)
+	}
+	if !strings.HasPrefix(ddl, "-- recon schema version ") {
+		t.Fatalf("expected version header, got %q", ddl[:min(len(ddl), 40)])
+	}
+	for _, want := range []string{"CREATE TABLE symbols", "CREATE VIRTUAL TABLE search_index", "CREATE UNIQUE INDEX idx_edges_unique"} {
+		if !strings.Contains(ddl, want) {
+			t.Fatalf("expected %q in schema:\n%s", want, ddl)
+		}
+	}
+	for _, unwanted := range []string{"schema_migrations", "search_index_data"} {
+		if strings.Contains(ddl, unwanted) {
+			t.Fatalf("did not expect %q in schema:\n%s", unwanted, ddl)
+		}
+	}
+
+	conn, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, ddl); err != nil {
+		t.Fatalf("replay schema: %v", err)
+	}
+	replayed, err := DumpSchema(ctx, conn)
+	if err != nil {
+		t.Fatalf("DumpSchema: %v", err)
+	}
+	if !strings.HasSuffix(ddl, "\n\n"+replayed) {
+		t.Fatalf("replayed schema differs:\n%s", replayed)
+	}
+
+	_ = conn.Close()
+	if _, err := DumpSchema(ctx, conn); err == nil || !strings.Contains(err.Error(), "query schema") {
+		t.Fatalf("expected query schema error, got %v", err)
+	}
+}
+
+func TestOpenTracesSQLAtDebug(t *testing.T) {
+	orig := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(orig) })
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	conn, err := Open(filepath.Join(t.TempDir(), "traced.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Exec("CREATE TABLE t (v INTEGER);\n\tINSERT INTO t VALUES (?);", 1); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	var v int
+	if err := conn.QueryRow("SELECT v FROM t WHERE v = ?;", 1).Scan(&v); err != nil || v != 1 {
+		t.Fatalf("query = %d (%v), want 1", v, err)
+	}
+	if _, err := conn.Exec("SELECT * FROM missing;"); err == nil {
+		t.Fatal("expected an error querying a missing table")
+	}
+	for _, want := range []string{
+		`msg=sql query="CREATE TABLE t (v INTEGER); INSERT INTO t VALUES (?);" args=1`,
+		`query="SELECT v FROM t WHERE v = ?;" args=1`,
+		`query="SELECT * FROM missing;" args=0 duration=`,
+		"no such table: missing",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("expected %q in the trace, got:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	quiet, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer quiet.Close()
+	if _, err := quiet.Exec("SELECT 1;"); err != nil || buf.Len() != 0 {
+		t.Fatalf("expected no trace below debug, got %q (%v)", buf.String(), err)
+	}
+}
@@ -1,10 +1,15 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"errors"
 	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/sqlite"
@@ -19,26 +24,129 @@ var (
 	newSQLiteWithInstance  = sqlite.WithInstance
 	newMigratorWithInstance = migrate.NewWithInstance
 	migrateUp              = func(m *migrate.Migrate) error { return m.Up() }
+	migrateSteps           = func(m *migrate.Migrate, n int) error { return m.Steps(n) }
 )
 
 func RunMigrations(conn *sql.DB) error {
+	migrator, err := newMigrator(conn)
+	if err != nil {
+		return err
+	}
+	if err := migrateUp(migrator); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("apply migrations: %w", err)
+	}
+	return nil
+}
+
+// RollbackMigrations reverts the steps most recently applied migrations,
+// running their down scripts. It is meant for developing migrations: the
+// down scripts drop the tables and columns, and the data in them, that the
+// up scripts added.
+func RollbackMigrations(conn *sql.DB, steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("roll back migrations: steps must be positive, got %d", steps)
+	}
+	migrator, err := newMigrator(conn)
+	if err != nil {
+		return err
+	}
+	if err := migrateSteps(migrator, -steps); err != nil {
+		return fmt.Errorf("roll back migrations: %w", err)
+	}
+	return nil
+}
+
+func newMigrator(conn *sql.DB) (*migrate.Migrate, error) {
 	src, err := newIOFSSource(migrationsFS, "migrations")
 	if err != nil {
-		return fmt.Errorf("open migrations fs: %w", err)
+		return nil, fmt.Errorf("open migrations fs: %w", err)
 	}
 
 	driver, err := newSQLiteWithInstance(conn, &sqlite.Config{})
 	if err != nil {
-		return fmt.Errorf("create sqlite migrate driver: %w", err)
+		return nil, fmt.Errorf("create sqlite migrate driver: %w", err)
 	}
 
 	migrator, err := newMigratorWithInstance("iofs", src, "sqlite", driver)
 	if err != nil {
-		return fmt.Errorf("create migrator: %w", err)
+		return nil, fmt.Errorf("create migrator: %w", err)
 	}
+	return migrator, nil
+}
 
-	if err := migrateUp(migrator); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return fmt.Errorf("apply migrations: %w", err)
+// Migration is a schema migration embedded in this build.
+type Migration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+}
+
+// MigrationStatus is where a database stands against the migrations of
+// this build. Version is -1 when the database was never migrated, and
+// Dirty reports a migration that failed partway. Applied and Pending split
+// the embedded migrations at Version.
+type MigrationStatus struct {
+	Version int         `json:"version"`
+	Dirty   bool        `json:"dirty"`
+	Latest  int         `json:"latest"`
+	Applied []Migration `json:"applied"`
+	Pending []Migration `json:"pending"`
+}
+
+// Migrations returns the migrations embedded in this build, oldest first.
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
 	}
-	return nil
+	var migrations []Migration
+	for _, e := range entries {
+		base, ok := strings.CutSuffix(e.Name(), ".up.sql")
+		if !ok {
+			continue
+		}
+		prefix, name, ok := strings.Cut(base, "_")
+		if !ok {
+			continue
+		}
+		v, err := strconv.Atoi(prefix)
+		if err != nil {
+			continue
+		}
+		migrations = append(migrations, Migration{Version: v, Name: name})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// ReadMigrationStatus reports the schema version of the database behind
+// conn and the embedded migrations applied to it and still pending.
+func ReadMigrationStatus(ctx context.Context, conn *sql.DB) (MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return MigrationStatus{}, err
+	}
+	status := MigrationStatus{Version: -1, Applied: []Migration{}, Pending: []Migration{}}
+	if n := len(migrations); n > 0 {
+		status.Latest = migrations[n-1].Version
+	}
+
+	var tables int
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations';`).Scan(&tables); err != nil {
+		return MigrationStatus{}, fmt.Errorf("read schema version: %w", err)
+	}
+	if tables > 0 {
+		err := conn.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations;`).Scan(&status.Version, &status.Dirty)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return MigrationStatus{}, fmt.Errorf("read schema version: %w", err)
+		}
+	}
+
+	for _, m := range migrations {
+		if m.Version <= status.Version {
+			status.Applied = append(status.Applied, m)
+		} else {
+			status.Pending = append(status.Pending, m)
+		}
+	}
+	return status, nil
 }
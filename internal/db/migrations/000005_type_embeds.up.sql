@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS type_embeds (
+    id               INTEGER PRIMARY KEY,
+    symbol_id        INTEGER REFERENCES symbols(id) ON DELETE CASCADE,
+    embedded_name    TEXT NOT NULL,
+    embedded_package TEXT NOT NULL DEFAULT '',
+    pointer          INTEGER NOT NULL DEFAULT 0,
+    UNIQUE(symbol_id, embedded_name, embedded_package)
+);
+
+CREATE INDEX IF NOT EXISTS idx_type_embeds_embedded
+    ON type_embeds(embedded_package, embedded_name);
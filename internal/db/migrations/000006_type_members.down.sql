@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS type_methods;
+DROP TABLE IF EXISTS struct_fields;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS struct_fields (
+    id        INTEGER PRIMARY KEY,
+    symbol_id INTEGER REFERENCES symbols(id) ON DELETE CASCADE,
+    position  INTEGER NOT NULL,
+    name      TEXT NOT NULL,
+    type      TEXT NOT NULL,
+    tag       TEXT NOT NULL DEFAULT '',
+    embedded  INTEGER NOT NULL DEFAULT 0,
+    exported  INTEGER NOT NULL DEFAULT 0,
+    UNIQUE(symbol_id, position)
+);
+
+-- Methods of a type: interface method specs (method_symbol_id NULL) and the
+-- declared methods of concrete types, linked after each sync.
+CREATE TABLE IF NOT EXISTS type_methods (
+    id               INTEGER PRIMARY KEY,
+    symbol_id        INTEGER REFERENCES symbols(id) ON DELETE CASCADE,
+    name             TEXT NOT NULL,
+    signature        TEXT NOT NULL DEFAULT '',
+    pointer_receiver INTEGER NOT NULL DEFAULT 0,
+    method_symbol_id INTEGER REFERENCES symbols(id) ON DELETE CASCADE,
+    UNIQUE(symbol_id, name)
+);
@@ -0,0 +1,10 @@
+-- Constants of an enum symbol: a const block whose constants share one named
+-- type. value is the evaluated constant, or the expression as written.
+CREATE TABLE IF NOT EXISTS enum_members (
+    id        INTEGER PRIMARY KEY,
+    symbol_id INTEGER REFERENCES symbols(id) ON DELETE CASCADE,
+    position  INTEGER NOT NULL,
+    name      TEXT NOT NULL,
+    value     TEXT NOT NULL DEFAULT '',
+    UNIQUE(symbol_id, name)
+);
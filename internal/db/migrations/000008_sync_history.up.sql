@@ -0,0 +1,16 @@
+-- One row per successful sync, kept so reports can look back over index
+-- history. sync_state only holds the latest run.
+CREATE TABLE IF NOT EXISTS sync_history (
+    id              INTEGER PRIMARY KEY,
+    synced_at       TEXT NOT NULL,
+    mode            TEXT NOT NULL,
+    commit_hash     TEXT NOT NULL DEFAULT '',
+    dirty           INTEGER NOT NULL DEFAULT 0,
+    indexed_files   INTEGER NOT NULL DEFAULT 0,
+    indexed_symbols INTEGER NOT NULL DEFAULT 0,
+    files_added     INTEGER NOT NULL DEFAULT 0,
+    files_modified  INTEGER NOT NULL DEFAULT 0,
+    files_removed   INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_history_synced_at ON sync_history(synced_at);
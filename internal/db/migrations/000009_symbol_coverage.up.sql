@@ -0,0 +1,16 @@
+-- Statement coverage per function and method from the last imported Go
+-- cover profile. Rows are keyed by file and symbol name rather than
+-- symbols.id because a full sync rebuilds the symbols table.
+CREATE TABLE IF NOT EXISTS symbol_coverage (
+    id           INTEGER PRIMARY KEY,
+    package_path TEXT NOT NULL,
+    file_path    TEXT NOT NULL,
+    name         TEXT NOT NULL,
+    receiver     TEXT NOT NULL DEFAULT '',
+    statements   INTEGER NOT NULL DEFAULT 0,
+    covered      INTEGER NOT NULL DEFAULT 0,
+    imported_at  TEXT NOT NULL,
+    UNIQUE(file_path, name, receiver)
+);
+
+CREATE INDEX IF NOT EXISTS idx_symbol_coverage_package ON symbol_coverage(package_path);
@@ -0,0 +1,4 @@
+-- The build constraint a file is compiled under: its //go:build expression
+-- combined with any GOOS/GOARCH file name suffix, or '' for every platform.
+-- Symbols declared in constrained files are platform variants.
+ALTER TABLE files ADD COLUMN build_constraint TEXT NOT NULL DEFAULT '';
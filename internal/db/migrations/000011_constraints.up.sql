@@ -0,0 +1,11 @@
+-- Constraints are hard rules ("never import internal/db from cmd"), kept
+-- apart from patterns because their evidence must stay unmatched.
+CREATE TABLE IF NOT EXISTS constraints (
+    id         INTEGER PRIMARY KEY,
+    title      TEXT NOT NULL,
+    reasoning  TEXT NOT NULL DEFAULT '',
+    confidence TEXT NOT NULL DEFAULT 'medium',
+    status     TEXT NOT NULL DEFAULT 'active',
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
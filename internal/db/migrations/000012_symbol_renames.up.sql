@@ -0,0 +1,13 @@
+-- Symbols renamed or moved between syncs, detected by an unchanged body under
+-- a new package-qualified name. Edges to old_ref were rewritten to new_ref.
+CREATE TABLE IF NOT EXISTS symbol_renames (
+    id          INTEGER PRIMARY KEY,
+    old_ref     TEXT NOT NULL,
+    new_ref     TEXT NOT NULL,
+    kind        TEXT NOT NULL,
+    edges       INTEGER NOT NULL DEFAULT 0,
+    commit_hash TEXT NOT NULL DEFAULT '',
+    renamed_at  TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_symbol_renames_old_ref ON symbol_renames(old_ref);
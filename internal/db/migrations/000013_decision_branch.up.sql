@@ -0,0 +1,3 @@
+-- The git branch a decision is scoped to, or NULL for decisions that hold on
+-- every branch. Orient and recall show scoped decisions only on that branch.
+ALTER TABLE decisions ADD COLUMN branch TEXT;
@@ -0,0 +1,3 @@
+-- How long each sync took, from walking the module to committing the index.
+-- Rows recorded before this migration read as 0.
+ALTER TABLE sync_history ADD COLUMN duration_ms INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+ALTER TABLE symbols ADD COLUMN body TEXT;
+
+UPDATE symbols SET body = (SELECT b.body FROM symbol_bodies b WHERE b.hash = symbols.body_hash);
+
+ALTER TABLE symbols DROP COLUMN body_hash;
+
+DROP TABLE IF EXISTS symbol_bodies;
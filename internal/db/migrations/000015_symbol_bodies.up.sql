@@ -0,0 +1,17 @@
+-- Declaration source moves out of symbols into a content-addressed table, so
+-- symbol scans stay small and identical bodies are stored once. Existing
+-- bodies are carried over under a per-symbol key until the next full sync
+-- replaces them with hashed rows.
+CREATE TABLE IF NOT EXISTS symbol_bodies (
+    hash TEXT PRIMARY KEY,
+    body TEXT NOT NULL
+);
+
+ALTER TABLE symbols ADD COLUMN body_hash TEXT;
+
+INSERT INTO symbol_bodies (hash, body)
+SELECT 'legacy:' || id, body FROM symbols WHERE COALESCE(body, '') != '';
+
+UPDATE symbols SET body_hash = 'legacy:' || id WHERE COALESCE(body, '') != '';
+
+ALTER TABLE symbols DROP COLUMN body;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS type_params;
@@ -0,0 +1,10 @@
+-- Type parameters of generic funcs and types, one row per name, with the
+-- constraint as written: [K comparable, V any] is (K, comparable), (V, any).
+CREATE TABLE IF NOT EXISTS type_params (
+    id              INTEGER PRIMARY KEY,
+    symbol_id       INTEGER REFERENCES symbols(id) ON DELETE CASCADE,
+    position        INTEGER NOT NULL,
+    name            TEXT NOT NULL,
+    constraint_expr TEXT NOT NULL,
+    UNIQUE(symbol_id, position)
+);
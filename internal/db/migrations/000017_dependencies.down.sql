@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS module_info;
+DROP TABLE IF EXISTS dependencies;
@@ -0,0 +1,18 @@
+-- Module requirements from go.mod, rewritten by every full sync. replace_path
+-- and replace_version hold the replace directive that applies, if any.
+CREATE TABLE IF NOT EXISTS dependencies (
+    id              INTEGER PRIMARY KEY,
+    module          TEXT NOT NULL UNIQUE,
+    version         TEXT NOT NULL,
+    indirect        INTEGER NOT NULL DEFAULT 0,
+    replace_path    TEXT NOT NULL DEFAULT '',
+    replace_version TEXT NOT NULL DEFAULT ''
+);
+
+-- The module's own go.mod header. Single row, like sync_state.
+CREATE TABLE IF NOT EXISTS module_info (
+    id          INTEGER PRIMARY KEY CHECK (id = 1),
+    module_path TEXT NOT NULL,
+    go_version  TEXT NOT NULL DEFAULT '',
+    toolchain   TEXT NOT NULL DEFAULT ''
+);
@@ -0,0 +1 @@
+ALTER TABLE evidence DROP COLUMN verify_interval;
@@ -0,0 +1,3 @@
+-- How often `recon verify --due` re-runs an evidence check, in seconds. 0 uses
+-- the default for the check type: a day, or a week for build and test checks.
+ALTER TABLE evidence ADD COLUMN verify_interval INTEGER NOT NULL DEFAULT 0;
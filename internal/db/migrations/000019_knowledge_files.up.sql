@@ -0,0 +1,10 @@
+-- Files under .recon/knowledge/ that mirror decisions and patterns when
+-- knowledge.files is enabled. hash is the SHA-256 of the file as recon last
+-- wrote or read it, which tells sync whether the file or the database changed.
+CREATE TABLE IF NOT EXISTS knowledge_files (
+    path        TEXT PRIMARY KEY,
+    entity_type TEXT NOT NULL,
+    entity_id   INTEGER NOT NULL,
+    hash        TEXT NOT NULL,
+    UNIQUE (entity_type, entity_id)
+);
@@ -0,0 +1 @@
+ALTER TABLE symbol_deps DROP COLUMN lines;
@@ -0,0 +1,3 @@
+-- Source lines of the calls a dependency row stands for, comma-separated and
+-- ascending ("12,40"), for `recon find --usages`. Empty until the next sync.
+ALTER TABLE symbol_deps ADD COLUMN lines TEXT NOT NULL DEFAULT '';
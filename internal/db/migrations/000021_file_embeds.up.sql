@@ -0,0 +1,12 @@
+-- One row per pattern of a //go:embed directive. paths is a JSON array of the
+-- module-relative files the pattern matched at sync time; an empty array
+-- means the embedded path is missing and the package will not build.
+CREATE TABLE IF NOT EXISTS file_embeds (
+    id       INTEGER PRIMARY KEY,
+    file_id  INTEGER NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+    var_name TEXT NOT NULL,
+    pattern  TEXT NOT NULL,
+    line     INTEGER NOT NULL,
+    paths    TEXT NOT NULL DEFAULT '[]',
+    UNIQUE(file_id, line, pattern)
+);
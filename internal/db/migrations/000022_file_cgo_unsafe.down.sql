@@ -0,0 +1,2 @@
+ALTER TABLE files DROP COLUMN unsafe;
+ALTER TABLE files DROP COLUMN cgo;
@@ -0,0 +1,5 @@
+-- 1 when a file imports "C" (cgo) or "unsafe": code to change with extra
+-- care, surfaced by `recon find --unsafe` and orient's summary. Zero until
+-- the next sync.
+ALTER TABLE files ADD COLUMN cgo INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE files ADD COLUMN unsafe INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,13 @@
+-- One row per rule of the repository's CODEOWNERS file, in file order, as of
+-- the last full sync. source is the file's path relative to the repository
+-- root and module_dir the module root's; patterns match repository paths,
+-- and the last matching rule owns a path. owners is a JSON array, empty for
+-- a rule that leaves its paths unowned.
+CREATE TABLE IF NOT EXISTS code_owners (
+    id         INTEGER PRIMARY KEY,
+    source     TEXT NOT NULL,
+    module_dir TEXT NOT NULL DEFAULT '.',
+    line       INTEGER NOT NULL,
+    pattern    TEXT NOT NULL,
+    owners     TEXT NOT NULL DEFAULT '[]'
+);
@@ -0,0 +1,15 @@
+-- TODO, FIXME, and BUG markers at the start of a comment line, and symbols
+-- whose doc comment has a "Deprecated:" paragraph (symbol_id set, line is
+-- the symbol's first line). author is the name in "TODO(name):", if any.
+CREATE TABLE IF NOT EXISTS annotations (
+    id        INTEGER PRIMARY KEY,
+    file_id   INTEGER NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+    symbol_id INTEGER REFERENCES symbols(id) ON DELETE CASCADE,
+    kind      TEXT NOT NULL,
+    line      INTEGER NOT NULL,
+    author    TEXT NOT NULL DEFAULT '',
+    text      TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_annotations_file ON annotations(file_id);
+CREATE INDEX IF NOT EXISTS idx_annotations_symbol ON annotations(symbol_id);
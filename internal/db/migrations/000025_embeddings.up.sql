@@ -0,0 +1,16 @@
+-- Vectors computed by `recon embed` for active decisions, patterns, and
+-- constraints (entity_ref is the entity's id) and for documented symbols
+-- (entity_ref is "package.Name" or "package.Receiver.Name"). Vectors from
+-- different providers are not comparable, so each provider keeps its own
+-- rows. content_hash is the SHA-256 of the embedded text; a row whose hash
+-- still matches is not recomputed. vector holds dims little-endian float32s.
+CREATE TABLE IF NOT EXISTS embeddings (
+    provider     TEXT NOT NULL,
+    entity_type  TEXT NOT NULL,
+    entity_ref   TEXT NOT NULL,
+    content_hash TEXT NOT NULL,
+    dims         INTEGER NOT NULL,
+    vector       BLOB NOT NULL,
+    updated_at   TEXT NOT NULL,
+    PRIMARY KEY (provider, entity_type, entity_ref)
+);
@@ -0,0 +1,2 @@
+DROP VIEW IF EXISTS entity_evidence;
+ALTER TABLE evidence DROP COLUMN combine;
@@ -0,0 +1,24 @@
+-- An entity can be backed by several evidence checks, one evidence row each.
+-- combine says whether the entity holds when all of its checks pass or when
+-- any of them does; every row of an entity carries the same value.
+ALTER TABLE evidence ADD COLUMN combine TEXT NOT NULL DEFAULT 'all';
+
+-- entity_evidence folds the evidence rows of each entity into one: the id,
+-- summary, and check type of its latest check, how many checks it has, and
+-- the drift status of the entity as a whole, which is the worst status of
+-- its checks when all must pass and the best when any may.
+CREATE VIEW IF NOT EXISTS entity_evidence AS
+SELECT g.entity_type, g.entity_id, g.id, g.checks, g.combine, f.summary, f.check_type, g.last_verified_at,
+       CASE g.drift_rank WHEN 0 THEN 'ok' WHEN 1 THEN 'drifting' ELSE 'broken' END AS drift_status
+FROM (
+    SELECT entity_type, entity_id, MAX(id) AS id, COUNT(*) AS checks, MAX(combine) AS combine,
+           MAX(last_verified_at) AS last_verified_at,
+           CASE MAX(combine) WHEN 'any' THEN MIN(drift_rank) ELSE MAX(drift_rank) END AS drift_rank
+    FROM (
+        SELECT id, entity_type, entity_id, combine, last_verified_at,
+               CASE COALESCE(drift_status, 'ok') WHEN 'ok' THEN 0 WHEN 'drifting' THEN 1 ELSE 2 END AS drift_rank
+        FROM evidence
+    )
+    GROUP BY entity_type, entity_id
+) g
+JOIN evidence f ON f.id = g.id;
@@ -0,0 +1,5 @@
+-- 1 when a file is generated code (a "Code generated ... DO NOT EDIT."
+-- header) or vendored under vendor/. Such files are indexed but left out of
+-- find listings and orient's summary unless asked for. Zero until the next
+-- sync.
+ALTER TABLE files ADD COLUMN generated INTEGER NOT NULL DEFAULT 0;
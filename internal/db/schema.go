@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+type rowsQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// DumpSchema renders the DDL of conn as SQL statements: tables, then indexes,
+// triggers and views, each group sorted by name. Migration bookkeeping and
+// the shadow tables SQLite maintains for virtual tables are left out, so the
+// output replays cleanly into an empty database.
+func DumpSchema(ctx context.Context, q rowsQueryer) (string, error) {
+	rows, err := q.QueryContext(ctx, `
+SELECT m.sql
+FROM sqlite_master m
+WHERE m.sql IS NOT NULL
+  AND m.name NOT LIKE 'sqlite\_%' ESCAPE '\'
+  AND m.tbl_name != 'schema_migrations'
+  AND NOT EXISTS (
+      SELECT 1 FROM sqlite_master v
+      WHERE v.type = 'table' AND v.sql LIKE 'CREATE VIRTUAL TABLE%'
+        AND m.name LIKE v.name || '\_%' ESCAPE '\'
+  )
+ORDER BY CASE m.type WHEN 'table' THEN 0 WHEN 'index' THEN 1 WHEN 'trigger' THEN 2 ELSE 3 END, m.name;
+`)
+	if err != nil {
+		return "", fmt.Errorf("query schema: %w", err)
+	}
+	defer rows.Close()
+
+	var b strings.Builder
+	for rows.Next() {
+		var stmt string
+		if err := rows.Scan(&stmt); err != nil {
+			return "", fmt.Errorf("scan schema row: %w", err)
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(strings.TrimSpace(stmt) + ";\n")
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("iterate schema rows: %w", err)
+	}
+	return b.String(), nil
+}
+
+// CurrentSchema returns the DDL this build of recon migrates a database to,
+// headed by the migration version, without touching any project database.
+func CurrentSchema(ctx context.Context) (string, error) {
+	conn, err := Open(":memory:")
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if err := RunMigrations(conn); err != nil {
+		return "", err
+	}
+
+	var version int
+	if err := conn.QueryRowContext(ctx, "SELECT version FROM schema_migrations;").Scan(&version); err != nil {
+		return "", fmt.Errorf("read schema version: %w", err)
+	}
+	ddl, err := DumpSchema(ctx, conn)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("-- recon schema version %d\n\n%s", version, ddl), nil
+}
+
+// LatestMigrationVersion returns the highest migration version embedded in
+// this build, the version RunMigrations brings a database to.
+func LatestMigrationVersion() (int, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return 0, fmt.Errorf("read migrations: %w", err)
+	}
+	latest := 0
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.Atoi(prefix); err == nil && v > latest {
+			latest = v
+		}
+	}
+	return latest, nil
+}
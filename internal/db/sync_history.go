@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SyncRun is one successful sync as recorded in sync_history. Mode is "full"
+// for recon sync and "files" for a targeted sync.
+type SyncRun struct {
+	SyncedAt       time.Time `json:"synced_at"`
+	Mode           string    `json:"mode"`
+	Commit         string    `json:"commit"`
+	Dirty          bool      `json:"dirty"`
+	IndexedFiles   int       `json:"indexed_files"`
+	IndexedSymbols int       `json:"indexed_symbols"`
+	FilesAdded     int       `json:"files_added"`
+	FilesModified  int       `json:"files_modified"`
+	FilesRemoved   int       `json:"files_removed"`
+	DurationMS     int64     `json:"duration_ms"`
+}
+
+// AppendSyncRun records a sync in sync_history.
+func AppendSyncRun(ctx context.Context, ex execer, run SyncRun) error {
+	_, err := ex.ExecContext(ctx, `
+INSERT INTO sync_history (
+    synced_at, mode, commit_hash, dirty, indexed_files, indexed_symbols,
+    files_added, files_modified, files_removed, duration_ms
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+`, run.SyncedAt.UTC().Format(time.RFC3339), run.Mode, run.Commit, boolToInt(run.Dirty), run.IndexedFiles, run.IndexedSymbols,
+		run.FilesAdded, run.FilesModified, run.FilesRemoved, run.DurationMS)
+	if err != nil {
+		return fmt.Errorf("record sync run: %w", err)
+	}
+	return nil
+}
+
+// ListSyncRuns returns the syncs at or after since, oldest first.
+func ListSyncRuns(ctx context.Context, q rowsQueryer, since time.Time) ([]SyncRun, error) {
+	rows, err := q.QueryContext(ctx, `
+SELECT synced_at, mode, commit_hash, dirty, indexed_files, indexed_symbols,
+       files_added, files_modified, files_removed, duration_ms
+FROM sync_history
+WHERE synced_at >= ?
+ORDER BY synced_at, id;
+`, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("query sync history: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []SyncRun{}
+	for rows.Next() {
+		var (
+			run       SyncRun
+			timestamp string
+			dirty     int
+		)
+		if err := rows.Scan(&timestamp, &run.Mode, &run.Commit, &dirty, &run.IndexedFiles, &run.IndexedSymbols,
+			&run.FilesAdded, &run.FilesModified, &run.FilesRemoved, &run.DurationMS); err != nil {
+			return nil, fmt.Errorf("scan sync run: %w", err)
+		}
+		if run.SyncedAt, err = time.Parse(time.RFC3339, timestamp); err != nil {
+			return nil, fmt.Errorf("parse sync timestamp: %w", err)
+		}
+		run.Dirty = dirty == 1
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate sync history: %w", err)
+	}
+	return runs, nil
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log/slog"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// tracedDriverName is the SQLite driver Open uses when debug logging is on.
+// It logs each statement run outside a prepared statement, with how long
+// it took to execute or to return its first row.
+const tracedDriverName = "sqlite-traced"
+
+func init() {
+	sql.Register(tracedDriverName, tracedDriver{&sqlite.Driver{}})
+}
+
+// driverName picks the traced driver when the default logger records debug
+// messages, so tracing costs nothing otherwise.
+func driverName() string {
+	if slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return tracedDriverName
+	}
+	return "sqlite"
+}
+
+type tracedDriver struct {
+	driver.Driver
+}
+
+func (d tracedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return tracedConn{conn}, nil
+}
+
+// tracedConn forwards the optional interfaces of the SQLite connection that
+// database/sql uses, timing queries and execs.
+type tracedConn struct {
+	driver.Conn
+}
+
+func (c tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+	traceSQL(ctx, query, len(args), start, err)
+	return rows, err
+}
+
+func (c tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	res, err := c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+	traceSQL(ctx, query, len(args), start, err)
+	return res, err
+}
+
+func (c tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+}
+
+func (c tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func (c tracedConn) Ping(ctx context.Context) error {
+	return c.Conn.(driver.Pinger).Ping(ctx)
+}
+
+func (c tracedConn) ResetSession(ctx context.Context) error {
+	return c.Conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+func (c tracedConn) IsValid() bool {
+	return c.Conn.(driver.Validator).IsValid()
+}
+
+// maxTracedQuery caps the statement text of a log record.
+const maxTracedQuery = 300
+
+func traceSQL(ctx context.Context, query string, args int, start time.Time, err error) {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxTracedQuery {
+		query = query[:maxTracedQuery] + "..."
+	}
+	attrs := []any{"query", query, "args", args, "duration", time.Since(start)}
+	if err != nil {
+		attrs = append(attrs, "err", err)
+	}
+	slog.DebugContext(ctx, "sql", attrs...)
+}
@@ -0,0 +1,86 @@
+package deps
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderText formats a Result for the terminal.
+func RenderText(r Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Package %s (%s)\n", r.Package, r.ImportPath)
+	fmt.Fprintf(&b, "Fan-in: %d  Fan-out: %d\n", r.FanIn, r.FanOut)
+
+	writeList(&b, "Imports", r.Imports)
+	writeList(&b, "Imported by", r.Importers)
+	writeList(&b, "Standard library", r.Stdlib)
+
+	fmt.Fprintf(&b, "\nExternal (%d):\n", len(r.External))
+	if len(r.External) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, e := range r.External {
+		switch {
+		case e.Module == "":
+			fmt.Fprintf(&b, "- %s (not in go.mod)\n", e.Path)
+		case e.Indirect:
+			fmt.Fprintf(&b, "- %s (%s %s, indirect)\n", e.Path, e.Module, e.Version)
+		default:
+			fmt.Fprintf(&b, "- %s (%s %s)\n", e.Path, e.Module, e.Version)
+		}
+	}
+	return b.String()
+}
+
+func writeList(b *strings.Builder, title string, items []string) {
+	fmt.Fprintf(b, "\n%s (%d):\n", title, len(items))
+	if len(items) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+}
+
+// RenderInventoryText formats an Inventory for the terminal.
+func RenderInventoryText(inv Inventory) string {
+	var b strings.Builder
+	goVersion := inv.GoVersion
+	if goVersion == "" {
+		goVersion = "unknown"
+	}
+	fmt.Fprintf(&b, "Module %s (go %s", inv.Module, goVersion)
+	if inv.Toolchain != "" {
+		fmt.Fprintf(&b, ", toolchain %s", inv.Toolchain)
+	}
+	b.WriteString(")\n")
+
+	fmt.Fprintf(&b, "\nDependencies (%d):\n", len(inv.Dependencies))
+	if len(inv.Dependencies) == 0 {
+		b.WriteString("- (none; run `recon sync` to record go.mod)\n")
+	}
+	for _, d := range inv.Dependencies {
+		fmt.Fprintf(&b, "- %s %s", d.Module, d.Version)
+		if d.Indirect {
+			b.WriteString(" (indirect)")
+		}
+		if d.Replace != "" {
+			fmt.Fprintf(&b, " => %s", d.Replace)
+			if d.ReplaceVersion != "" {
+				fmt.Fprintf(&b, " %s", d.ReplaceVersion)
+			}
+		}
+		b.WriteString("\n")
+		if len(d.Packages) > 0 {
+			fmt.Fprintf(&b, "  Imported by: %s\n", strings.Join(d.Packages, ", "))
+		}
+	}
+
+	if len(inv.Unrequired) > 0 {
+		fmt.Fprintf(&b, "\nNot in go.mod (%d):\n", len(inv.Unrequired))
+		for _, path := range inv.Unrequired {
+			fmt.Fprintf(&b, "- %s\n", path)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,289 @@
+package deps
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ErrPackageNotFound is returned when the ref matches no indexed package.
+var ErrPackageNotFound = errors.New("package not found in index")
+
+// Result is the dependency picture of one package. Imports and Importers
+// hold module-relative package paths; Stdlib and External hold import paths.
+// FanOut counts the module packages it imports and FanIn those importing it,
+// so both measure coupling within the module.
+type Result struct {
+	Package    string     `json:"package"`
+	ImportPath string     `json:"import_path"`
+	Imports    []string   `json:"imports"`
+	Importers  []string   `json:"importers"`
+	Stdlib     []string   `json:"stdlib"`
+	External   []External `json:"external"`
+	FanIn      int        `json:"fan_in"`
+	FanOut     int        `json:"fan_out"`
+}
+
+// External is an import from outside the module and the go.mod requirement
+// that provides it. Module is empty when no requirement matches.
+type External struct {
+	Path     string `json:"path"`
+	Module   string `json:"module,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Indirect bool   `json:"indirect,omitempty"`
+}
+
+// Inventory is the module's go.mod requirements as of the last full sync,
+// each with the packages that import it. Unrequired lists external imports
+// no requirement provides.
+type Inventory struct {
+	Module       string       `json:"module"`
+	GoVersion    string       `json:"go_version"`
+	Toolchain    string       `json:"toolchain,omitempty"`
+	Dependencies []Dependency `json:"dependencies"`
+	Unrequired   []string     `json:"unrequired"`
+}
+
+// Dependency is one go.mod requirement. Imports lists the import paths of the
+// module in use and Packages the module packages importing them; both are
+// empty for a requirement only needed by other dependencies.
+type Dependency struct {
+	Module         string   `json:"module"`
+	Version        string   `json:"version"`
+	Indirect       bool     `json:"indirect"`
+	Replace        string   `json:"replace,omitempty"`
+	ReplaceVersion string   `json:"replace_version,omitempty"`
+	Imports        []string `json:"imports"`
+	Packages       []string `json:"packages"`
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// Deps resolves ref, a module-relative package path or a full import path,
+// and returns its imports, importers, and external requirements.
+func (s *Service) Deps(ctx context.Context, ref string) (Result, error) {
+	ref = strings.Trim(strings.TrimSpace(ref), "/")
+	if ref == "" {
+		ref = "."
+	}
+	var (
+		id     int64
+		result Result
+	)
+	err := s.db.QueryRowContext(ctx, `
+SELECT id, path, COALESCE(import_path, '')
+FROM packages
+WHERE path = ? OR import_path = ?
+ORDER BY path = ? DESC
+LIMIT 1;
+`, ref, ref, ref).Scan(&id, &result.Package, &result.ImportPath)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Result{}, fmt.Errorf("%w: %s", ErrPackageNotFound, ref)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("query package %s: %w", ref, err)
+	}
+	modulePath := strings.TrimSuffix(strings.TrimSuffix(result.ImportPath, result.Package), "/")
+
+	result.Imports, result.Stdlib, result.External = []string{}, []string{}, []External{}
+	rows, err := s.db.QueryContext(ctx, `
+SELECT DISTINCT i.to_path, i.import_type
+FROM imports i
+JOIN files f ON f.id = i.from_file_id
+WHERE f.package_id = ?
+ORDER BY i.to_path;
+`, id)
+	if err != nil {
+		return Result{}, fmt.Errorf("query imports of %s: %w", result.Package, err)
+	}
+	defer rows.Close()
+	var external []string
+	for rows.Next() {
+		var toPath, importType string
+		if err := rows.Scan(&toPath, &importType); err != nil {
+			return Result{}, fmt.Errorf("scan import: %w", err)
+		}
+		switch {
+		case importType == "local":
+			rel := strings.TrimPrefix(strings.TrimPrefix(toPath, modulePath), "/")
+			if rel == "" {
+				rel = "."
+			}
+			if rel != result.Package {
+				result.Imports = append(result.Imports, rel)
+			}
+		case isStdlib(toPath):
+			result.Stdlib = append(result.Stdlib, toPath)
+		default:
+			external = append(external, toPath)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return Result{}, fmt.Errorf("iterate imports: %w", err)
+	}
+	sort.Strings(result.Imports)
+
+	if len(external) > 0 {
+		deps, err := s.dependencies(ctx)
+		if err != nil {
+			return Result{}, err
+		}
+		for _, path := range external {
+			ext := External{Path: path}
+			if d := matchDependency(path, deps); d != nil {
+				ext.Module, ext.Version, ext.Indirect = d.Module, d.Version, d.Indirect
+			}
+			result.External = append(result.External, ext)
+		}
+	}
+
+	if result.Importers, err = s.importers(ctx, id); err != nil {
+		return Result{}, err
+	}
+	result.FanIn = len(result.Importers)
+	result.FanOut = len(result.Imports)
+	return result, nil
+}
+
+// External returns the inventory of go.mod requirements recorded by the last
+// full sync, with the module packages importing each.
+func (s *Service) External(ctx context.Context) (Inventory, error) {
+	var inv Inventory
+	err := s.db.QueryRowContext(ctx, `SELECT module_path, go_version, toolchain FROM module_info WHERE id = 1;`).
+		Scan(&inv.Module, &inv.GoVersion, &inv.Toolchain)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Inventory{}, fmt.Errorf("query module info: %w", err)
+	}
+
+	deps, err := s.dependencies(ctx)
+	if err != nil {
+		return Inventory{}, err
+	}
+	inv.Unrequired = []string{}
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT DISTINCT i.to_path, p.path
+FROM imports i
+JOIN files f ON f.id = i.from_file_id
+JOIN packages p ON p.id = f.package_id
+WHERE i.import_type = 'external'
+ORDER BY i.to_path, p.path;
+`)
+	if err != nil {
+		return Inventory{}, fmt.Errorf("query external imports: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var toPath, pkg string
+		if err := rows.Scan(&toPath, &pkg); err != nil {
+			return Inventory{}, fmt.Errorf("scan external import: %w", err)
+		}
+		if isStdlib(toPath) {
+			continue
+		}
+		d := matchDependency(toPath, deps)
+		if d == nil {
+			if n := len(inv.Unrequired); n == 0 || inv.Unrequired[n-1] != toPath {
+				inv.Unrequired = append(inv.Unrequired, toPath)
+			}
+			continue
+		}
+		if n := len(d.Imports); n == 0 || d.Imports[n-1] != toPath {
+			d.Imports = append(d.Imports, toPath)
+		}
+		if !slices.Contains(d.Packages, pkg) {
+			d.Packages = append(d.Packages, pkg)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return Inventory{}, fmt.Errorf("iterate external imports: %w", err)
+	}
+
+	inv.Dependencies = make([]Dependency, len(deps))
+	for i, d := range deps {
+		sort.Strings(d.Packages)
+		inv.Dependencies[i] = *d
+	}
+	return inv, nil
+}
+
+// dependencies loads the recorded go.mod requirements, ordered by module.
+func (s *Service) dependencies(ctx context.Context) ([]*Dependency, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT module, version, indirect, replace_path, replace_version
+FROM dependencies
+ORDER BY module;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query dependencies: %w", err)
+	}
+	defer rows.Close()
+	var deps []*Dependency
+	for rows.Next() {
+		d := &Dependency{Imports: []string{}, Packages: []string{}}
+		if err := rows.Scan(&d.Module, &d.Version, &d.Indirect, &d.Replace, &d.ReplaceVersion); err != nil {
+			return nil, fmt.Errorf("scan dependency: %w", err)
+		}
+		deps = append(deps, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate dependencies: %w", err)
+	}
+	return deps, nil
+}
+
+func (s *Service) importers(ctx context.Context, id int64) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT DISTINCT p.path
+FROM imports i
+JOIN files f ON f.id = i.from_file_id
+JOIN packages p ON p.id = f.package_id
+WHERE i.to_package_id = ? AND p.id != ?
+ORDER BY p.path;
+`, id, id)
+	if err != nil {
+		return nil, fmt.Errorf("query importers: %w", err)
+	}
+	defer rows.Close()
+	importers := []string{}
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("scan importer: %w", err)
+		}
+		importers = append(importers, path)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate importers: %w", err)
+	}
+	return importers, nil
+}
+
+// matchDependency returns the requirement with the longest module path that
+// is path itself or a prefix of it, or nil.
+func matchDependency(path string, deps []*Dependency) *Dependency {
+	var best *Dependency
+	for _, d := range deps {
+		if (path == d.Module || strings.HasPrefix(path, d.Module+"/")) && (best == nil || len(d.Module) > len(best.Module)) {
+			best = d
+		}
+	}
+	return best
+}
+
+// isStdlib reports whether an import path belongs to the standard library,
+// whose first element, unlike a module path's, has no dot.
+func isStdlib(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,136 @@
+package deps
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/testutil"
+)
+
+func setupDepsModule(t *testing.T) *sql.DB {
+	t.Helper()
+	_, conn := testutil.Module(t, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.26\n\nrequire (\n\tgithub.com/acme/kit v1.2.0\n\tgithub.com/acme/kit/v2 v2.0.1 // indirect\n\tgithub.com/acme/unused v0.3.0 // indirect\n)\n\nreplace github.com/acme/kit => ../kit\n",
+		"main.go": "package main\nimport (\n\t\"fmt\"\n\t\"example.com/m/store\"\n\t\"example.com/m/api\"\n)\n" +
+			"func main() { fmt.Println(store.Open(), api.Serve) }\n",
+		"api/api.go": "package api\nimport \"example.com/m/store\"\nvar Serve = store.Open\n",
+		"store/store.go": "package store\nimport (\n\t\"os\"\n\t\"net/http\"\n\t\"github.com/acme/kit/log\"\n\t\"github.com/acme/kit/v2/cache\"\n\t\"golang.org/x/sync/errgroup\"\n)\n" +
+			"func Open() any { return []any{os.Args, http.StatusOK, log.New, cache.New, errgroup.Group{}} }\n",
+	})
+	return conn
+}
+
+func TestDeps(t *testing.T) {
+	conn := setupDepsModule(t)
+	ctx := context.Background()
+	svc := NewService(conn)
+
+	got, err := svc.Deps(ctx, "store")
+	if err != nil {
+		t.Fatalf("Deps(store): %v", err)
+	}
+	want := Result{
+		Package:    "store",
+		ImportPath: "example.com/m/store",
+		Imports:    []string{},
+		Importers:  []string{".", "api"},
+		Stdlib:     []string{"net/http", "os"},
+		External: []External{
+			{Path: "github.com/acme/kit/log", Module: "github.com/acme/kit", Version: "v1.2.0"},
+			{Path: "github.com/acme/kit/v2/cache", Module: "github.com/acme/kit/v2", Version: "v2.0.1", Indirect: true},
+			{Path: "golang.org/x/sync/errgroup"},
+		},
+		FanIn:  2,
+		FanOut: 0, // stdlib and external imports do not count
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Deps(store) =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// The root package resolves by its full import path too.
+	got, err = svc.Deps(ctx, "example.com/m")
+	if err != nil {
+		t.Fatalf("Deps(example.com/m): %v", err)
+	}
+	if got.Package != "." || !reflect.DeepEqual(got.Imports, []string{"api", "store"}) || got.FanIn != 0 || got.FanOut != 2 {
+		t.Fatalf("unexpected root deps: %+v", got)
+	}
+	text := RenderText(got)
+	for _, line := range []string{"Package . (example.com/m)", "Fan-in: 0  Fan-out: 2", "Imported by (0):\n- (none)", "- fmt"} {
+		if !strings.Contains(text, line) {
+			t.Fatalf("text missing %q:\n%s", line, text)
+		}
+	}
+
+	if _, err := svc.Deps(ctx, "missing"); !errors.Is(err, ErrPackageNotFound) {
+		t.Fatalf("expected ErrPackageNotFound, got %v", err)
+	}
+}
+
+func TestExternal(t *testing.T) {
+	conn := setupDepsModule(t)
+	if _, err := conn.Exec(`INSERT INTO files(package_id,path,language,lines,hash,created_at,updated_at)
+		SELECT id,'api/extra.go','go',1,'h','x','x' FROM packages WHERE path = 'api'`); err != nil {
+		t.Fatalf("seed file: %v", err)
+	}
+	if _, err := conn.Exec(`INSERT INTO imports(from_file_id,to_path,alias,import_type)
+		SELECT id,'github.com/acme/kit/log','log','external' FROM files WHERE path = 'api/extra.go'`); err != nil {
+		t.Fatalf("seed import: %v", err)
+	}
+
+	got, err := NewService(conn).External(context.Background())
+	if err != nil {
+		t.Fatalf("External: %v", err)
+	}
+	want := Inventory{
+		Module:    "example.com/m",
+		GoVersion: "1.26",
+		Dependencies: []Dependency{
+			{Module: "github.com/acme/kit", Version: "v1.2.0", Replace: "../kit",
+				Imports: []string{"github.com/acme/kit/log"}, Packages: []string{"api", "store"}},
+			{Module: "github.com/acme/kit/v2", Version: "v2.0.1", Indirect: true,
+				Imports: []string{"github.com/acme/kit/v2/cache"}, Packages: []string{"store"}},
+			{Module: "github.com/acme/unused", Version: "v0.3.0", Indirect: true, Imports: []string{}, Packages: []string{}},
+		},
+		Unrequired: []string{"golang.org/x/sync/errgroup"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("External() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	text := RenderInventoryText(got)
+	for _, line := range []string{
+		"Module example.com/m (go 1.26)",
+		"- github.com/acme/kit v1.2.0 => ../kit\n  Imported by: api, store",
+		"- github.com/acme/unused v0.3.0 (indirect)\n",
+		"Not in go.mod (1):\n- golang.org/x/sync/errgroup",
+	} {
+		if !strings.Contains(text, line) {
+			t.Fatalf("text missing %q:\n%s", line, text)
+		}
+	}
+}
+
+func TestExternalBeforeSync(t *testing.T) {
+	conn, err := db.Open(filepath.Join(t.TempDir(), "recon.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	got, err := NewService(conn).External(context.Background())
+	if err != nil || got.GoVersion != "" || len(got.Dependencies) != 0 {
+		t.Fatalf("External() before sync = %+v, %v", got, err)
+	}
+	if text := RenderInventoryText(got); !strings.Contains(text, "go unknown") || !strings.Contains(text, "run `recon sync`") {
+		t.Fatalf("unexpected text:\n%s", text)
+	}
+}
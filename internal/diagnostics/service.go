@@ -0,0 +1,273 @@
+package diagnostics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"sort"
+)
+
+// Severities follow the Language Server Protocol's DiagnosticSeverity.
+const (
+	SeverityError   = 1
+	SeverityWarning = 2
+)
+
+// Codes name why a range is flagged.
+const (
+	CodeDriftBroken   = "drift_broken"
+	CodeDriftDrifting = "drift_drifting"
+	CodeAntiPattern   = "anti_pattern"
+)
+
+// Source is the diagnostic source editors show next to each message.
+const Source = "recon"
+
+// Position and Range are zero-based, as in the Language Server Protocol. A
+// range ends at the start of the line after the last flagged line.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Diagnostic is shaped like an LSP Diagnostic so an editor client can pass it
+// through unchanged. Data carries the knowledge entity behind it.
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Code     string `json:"code"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+	Data     Data   `json:"data"`
+}
+
+type Data struct {
+	EntityType string `json:"entity_type"`
+	EntityID   int64  `json:"entity_id"`
+	Via        string `json:"via"`
+	Drift      string `json:"drift_status"`
+}
+
+// File is the diagnostics for one file, shaped like the parameters of an LSP
+// textDocument/publishDiagnostics notification plus the module-relative path.
+type File struct {
+	URI         string       `json:"uri"`
+	Path        string       `json:"path"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// finding is an edge from active knowledge that should be flagged: its
+// entity's evidence is drifting or broken, or it marks an anti-pattern.
+type finding struct {
+	entityType string
+	entityID   int64
+	title      string
+	summary    string
+	drift      string
+	relation   string
+	toType     string
+	toRef      string
+}
+
+// Collect maps drifting and broken decisions, patterns, and constraints, and
+// anti-pattern ("contradicts") links, to the files and symbol ranges their
+// edges point at. Package edges are left out: a whole package has no range to
+// mark. When paths is non-empty, only those module-relative files are
+// reported, each with an empty list if nothing is flagged so a client can
+// clear stale marks.
+func (s *Service) Collect(ctx context.Context, moduleRoot string, paths []string) ([]File, error) {
+	findings, err := s.findings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byPath := map[string][]Diagnostic{}
+	for _, f := range findings {
+		ranges, err := s.resolve(ctx, f.toType, f.toRef)
+		if err != nil {
+			return nil, err
+		}
+		for path, rs := range ranges {
+			for _, r := range rs {
+				byPath[path] = append(byPath[path], diagnosticFor(f, r))
+			}
+		}
+	}
+
+	if len(paths) > 0 {
+		wanted := map[string][]Diagnostic{}
+		for _, p := range paths {
+			wanted[p] = byPath[p]
+		}
+		byPath = wanted
+	}
+
+	files := make([]File, 0, len(byPath))
+	for path, diags := range byPath {
+		if diags == nil {
+			diags = []Diagnostic{}
+		}
+		sort.SliceStable(diags, func(i, j int) bool {
+			a, b := diags[i], diags[j]
+			if a.Range.Start.Line != b.Range.Start.Line {
+				return a.Range.Start.Line < b.Range.Start.Line
+			}
+			return a.Severity < b.Severity
+		})
+		files = append(files, File{URI: fileURI(moduleRoot, path), Path: path, Diagnostics: diags})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// Changes returns what a client that applied prev needs to reach next: the
+// files of next whose diagnostics differ, and an empty list for each file
+// flagged in prev and clean in next. Both are sorted by path, as Collect
+// returns them.
+func Changes(prev, next []File) []File {
+	before := make(map[string]File, len(prev))
+	for _, f := range prev {
+		before[f.Path] = f
+	}
+	changes := []File{}
+	for _, f := range next {
+		old, ok := before[f.Path]
+		delete(before, f.Path)
+		if !ok && len(f.Diagnostics) == 0 {
+			continue
+		}
+		if !ok || !reflect.DeepEqual(old.Diagnostics, f.Diagnostics) {
+			changes = append(changes, f)
+		}
+	}
+	for _, f := range before {
+		if len(f.Diagnostics) > 0 {
+			changes = append(changes, File{URI: f.URI, Path: f.Path, Diagnostics: []Diagnostic{}})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func (s *Service) findings(ctx context.Context) ([]finding, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT e.from_type, e.from_id, COALESCE(d.title, p.title, c.title), COALESCE(ev.summary, ''),
+       COALESCE(ev.drift_status, 'ok'), e.relation, e.to_type, e.to_ref
+FROM edges e
+LEFT JOIN decisions d ON e.from_type = 'decision' AND d.id = e.from_id
+LEFT JOIN patterns p ON e.from_type = 'pattern' AND p.id = e.from_id
+LEFT JOIN constraints c ON e.from_type = 'constraint' AND c.id = e.from_id
+LEFT JOIN entity_evidence ev ON ev.entity_type = e.from_type AND ev.entity_id = e.from_id
+WHERE COALESCE(d.status, p.status, c.status) = 'active'
+  AND e.to_type IN ('file', 'symbol')
+  AND (ev.drift_status IN ('drifting', 'broken')
+       OR (e.from_type = 'pattern' AND e.relation = 'contradicts'))
+ORDER BY e.id;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query drift diagnostics: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []finding
+	for rows.Next() {
+		var f finding
+		if err := rows.Scan(&f.entityType, &f.entityID, &f.title, &f.summary, &f.drift, &f.relation, &f.toType, &f.toRef); err != nil {
+			return nil, fmt.Errorf("scan drift diagnostic: %w", err)
+		}
+		findings = append(findings, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate drift diagnostics: %w", err)
+	}
+	return findings, nil
+}
+
+// resolve returns the ranges an edge target covers, by file path. A file
+// target marks its first line; a symbol target marks each declaration with
+// that package-qualified name. Targets missing from the index resolve to
+// nothing.
+func (s *Service) resolve(ctx context.Context, toType, toRef string) (map[string][]Range, error) {
+	query := `SELECT path, 1, 1 FROM files WHERE path = ?;`
+	if toType == "symbol" {
+		query = `
+SELECT f.path, sy.line_start, sy.line_end
+FROM symbols sy
+JOIN files f ON f.id = sy.file_id
+JOIN packages pk ON pk.id = f.package_id
+WHERE pk.path || '.' || sy.name = ?
+ORDER BY f.path, sy.line_start;`
+	}
+	rows, err := s.db.QueryContext(ctx, query, toRef)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s %s: %w", toType, toRef, err)
+	}
+	defer rows.Close()
+
+	ranges := map[string][]Range{}
+	for rows.Next() {
+		var (
+			path       string
+			start, end int
+		)
+		if err := rows.Scan(&path, &start, &end); err != nil {
+			return nil, fmt.Errorf("scan %s range: %w", toType, err)
+		}
+		ranges[path] = append(ranges[path], Range{
+			Start: Position{Line: start - 1},
+			End:   Position{Line: end},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate %s ranges: %w", toType, err)
+	}
+	return ranges, nil
+}
+
+func diagnosticFor(f finding, r Range) Diagnostic {
+	d := Diagnostic{
+		Range:  r,
+		Source: Source,
+		Data: Data{
+			EntityType: f.entityType,
+			EntityID:   f.entityID,
+			Via:        f.toType + ":" + f.toRef,
+			Drift:      f.drift,
+		},
+	}
+	switch {
+	case f.relation == "contradicts" && f.entityType == "pattern":
+		d.Severity = SeverityWarning
+		d.Code = CodeAntiPattern
+		d.Message = fmt.Sprintf("pattern #%d %q: this code is recorded as contradicting it", f.entityID, f.title)
+	case f.drift == "broken":
+		d.Severity = SeverityError
+		d.Code = CodeDriftBroken
+		d.Message = fmt.Sprintf("%s #%d %q: evidence is broken (%s)", f.entityType, f.entityID, f.title, f.summary)
+	default:
+		d.Severity = SeverityWarning
+		d.Code = CodeDriftDrifting
+		d.Message = fmt.Sprintf("%s #%d %q: evidence is drifting (%s)", f.entityType, f.entityID, f.title, f.summary)
+	}
+	return d
+}
+
+func fileURI(moduleRoot, rel string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(moduleRoot, filepath.FromSlash(rel)))}).String()
+}
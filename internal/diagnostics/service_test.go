@@ -0,0 +1,148 @@
+package diagnostics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func diagnosticsTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	root := t.TempDir()
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	for _, stmt := range []string{
+		`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES
+			(1,'internal/db','db','example.com/m/internal/db',2,20,'x','x')`,
+		`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES
+			(1,1,'internal/db/db.go','go',10,'h','x','x'),
+			(2,1,'internal/db/other.go','go',10,'h','x','x')`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES
+			(1,1,'func','Open','func Open()',3,8,1,''),
+			(2,2,'func','Close','func Close()',5,6,1,'')`,
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+			(1,'Use SQLite','r','high','active','x','x'),
+			(2,'Single writer','r','medium','active','x','x'),
+			(3,'Old store','r','high','archived','x','x'),
+			(4,'Close carefully','r','high','active','x','x')`,
+		`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES
+			(1,'Global state','d','low','active','x','x')`,
+		`INSERT INTO evidence(entity_type,entity_id,summary,drift_status) VALUES
+			('decision',1,'old','ok'),
+			('decision',1,'sqlite in go.mod','broken'),
+			('decision',2,'s','ok'),
+			('decision',3,'s','broken'),
+			('decision',4,'Close exists','drifting')`,
+		`INSERT INTO edges(from_type,from_id,to_type,to_ref,relation,source,confidence,created_at) VALUES
+			('decision',1,'package','internal/db','affects','manual','high','x'),
+			('decision',1,'file','internal/db/db.go','affects','manual','high','x'),
+			('decision',2,'file','internal/db/db.go','affects','manual','high','x'),
+			('decision',3,'file','internal/db/db.go','affects','manual','high','x'),
+			('decision',4,'symbol','internal/db.Close','affects','manual','high','x'),
+			('decision',4,'file','internal/db/gone.go','affects','manual','high','x'),
+			('pattern',1,'symbol','internal/db.Open','contradicts','manual','high','x')`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	return conn
+}
+
+func TestCollect(t *testing.T) {
+	conn := diagnosticsTestDB(t)
+	files, err := NewService(conn).Collect(context.Background(), "/repo", nil)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if len(files) != 2 || files[0].Path != "internal/db/db.go" || files[1].Path != "internal/db/other.go" {
+		t.Fatalf("files = %+v", files)
+	}
+	if files[0].URI != "file:///repo/internal/db/db.go" {
+		t.Fatalf("uri = %q", files[0].URI)
+	}
+
+	dbGo := files[0].Diagnostics
+	if len(dbGo) != 2 {
+		t.Fatalf("db.go diagnostics = %+v", dbGo)
+	}
+	if d := dbGo[0]; d.Code != CodeDriftBroken || d.Severity != SeverityError || d.Range != (Range{End: Position{Line: 1}}) ||
+		d.Data.EntityID != 1 || d.Data.Via != "file:internal/db/db.go" || !strings.Contains(d.Message, "sqlite in go.mod") || d.Source != Source {
+		t.Fatalf("db.go[0] = %+v", d)
+	}
+	if d := dbGo[1]; d.Code != CodeAntiPattern || d.Severity != SeverityWarning || d.Range != (Range{Start: Position{Line: 2}, End: Position{Line: 8}}) {
+		t.Fatalf("db.go[1] = %+v", d)
+	}
+
+	other := files[1].Diagnostics
+	if len(other) != 1 || other[0].Code != CodeDriftDrifting || other[0].Range.Start.Line != 4 || other[0].Data.Via != "symbol:internal/db.Close" {
+		t.Fatalf("other.go diagnostics = %+v", other)
+	}
+}
+
+func TestCollectPaths(t *testing.T) {
+	conn := diagnosticsTestDB(t)
+	files, err := NewService(conn).Collect(context.Background(), "/repo", []string{"internal/db/other.go", "main.go"})
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if len(files) != 2 || files[0].Path != "internal/db/other.go" || len(files[0].Diagnostics) != 1 {
+		t.Fatalf("files = %+v", files)
+	}
+	if files[1].Path != "main.go" || files[1].Diagnostics == nil || len(files[1].Diagnostics) != 0 {
+		t.Fatalf("main.go should be reported clean, got %+v", files[1])
+	}
+}
+
+func TestCollectQueryError(t *testing.T) {
+	conn := diagnosticsTestDB(t)
+	if _, err := conn.Exec(`DROP TABLE edges`); err != nil {
+		t.Fatalf("drop edges: %v", err)
+	}
+	if _, err := NewService(conn).Collect(context.Background(), "/repo", nil); err == nil || !strings.Contains(err.Error(), "query drift diagnostics") {
+		t.Fatalf("expected query error, got %v", err)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	broken := Diagnostic{Code: CodeDriftBroken, Severity: SeverityError}
+	drifting := Diagnostic{Code: CodeDriftDrifting, Severity: SeverityWarning}
+	prev := []File{
+		{Path: "a.go", Diagnostics: []Diagnostic{broken}},
+		{Path: "b.go", Diagnostics: []Diagnostic{drifting}},
+		{Path: "c.go", Diagnostics: []Diagnostic{drifting}},
+	}
+	next := []File{
+		{Path: "a.go", Diagnostics: []Diagnostic{broken}},
+		{Path: "b.go", Diagnostics: []Diagnostic{broken}},
+		{Path: "d.go", Diagnostics: []Diagnostic{drifting}},
+		{Path: "e.go", Diagnostics: []Diagnostic{}},
+	}
+	changes := Changes(prev, next)
+	var got []string
+	for _, f := range changes {
+		got = append(got, fmt.Sprintf("%s:%d", f.Path, len(f.Diagnostics)))
+	}
+	if strings.Join(got, " ") != "b.go:1 c.go:0 d.go:1" {
+		t.Fatalf("Changes() = %v", got)
+	}
+	if changes[1].Diagnostics == nil {
+		t.Fatal("a cleared file should carry an empty list, not null")
+	}
+	if len(Changes(next, next)) != 0 {
+		t.Fatal("expected no changes between equal sets")
+	}
+}
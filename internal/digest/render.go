@@ -0,0 +1,128 @@
+package digest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// RenderMarkdown renders d as a shareable markdown report.
+func RenderMarkdown(d Digest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Recon digest: %s to %s\n", d.Since.Format(dateLayout), d.Until.Format(dateLayout))
+
+	b.WriteString("\n## Sync activity\n\n")
+	if d.Syncs.Runs == 0 {
+		b.WriteString("_No syncs._\n")
+	} else {
+		for _, line := range syncLines(d.Syncs) {
+			fmt.Fprintf(&b, "- %s\n", line)
+		}
+	}
+
+	b.WriteString("\n## Knowledge\n\n")
+	if len(d.Knowledge) == 0 {
+		b.WriteString("_No new or changed decisions or patterns._\n")
+	} else {
+		b.WriteString("| Change | Entity | Title | Confidence | When |\n")
+		b.WriteString("| ------ | ------ | ----- | ---------- | ---- |\n")
+		for _, c := range d.Knowledge {
+			fmt.Fprintf(&b, "| %s | %s #%d | %s | %s | %s |\n", c.Change, c.EntityType, c.ID, markdownCell(c.Title), c.Confidence, shortDate(c.At))
+		}
+	}
+
+	b.WriteString("\n## Drift\n\n")
+	if len(d.Drift) == 0 {
+		b.WriteString("_No drift detected._\n")
+	} else {
+		for _, e := range d.Drift {
+			fmt.Fprintf(&b, "- **%s** (%s #%d) is %s since %s: %s\n", e.Title, e.EntityType, e.ID, e.Status, shortDate(e.VerifiedAt), e.Summary)
+		}
+	}
+
+	b.WriteString("\n## Hotspots\n\n")
+	if len(d.Hotspots) == 0 {
+		b.WriteString("_No Go changes in git history._\n")
+	} else {
+		b.WriteString("| Package | Commits | Files | +Lines | -Lines |\n")
+		b.WriteString("| ------- | ------- | ----- | ------ | ------ |\n")
+		for _, h := range d.Hotspots {
+			fmt.Fprintf(&b, "| `%s` | %d | %d | %d | %d |\n", h.Package, h.Commits, h.Files, h.LinesAdded, h.LinesDeleted)
+		}
+	}
+	return b.String()
+}
+
+// RenderText renders d as plain text for the terminal.
+func RenderText(d Digest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Digest %s to %s\n", d.Since.Format(dateLayout), d.Until.Format(dateLayout))
+
+	b.WriteString("\nSync activity:\n")
+	if d.Syncs.Runs == 0 {
+		b.WriteString("- (none)\n")
+	}
+	if d.Syncs.Runs > 0 {
+		for _, line := range syncLines(d.Syncs) {
+			fmt.Fprintf(&b, "- %s\n", strings.ReplaceAll(line, "`", ""))
+		}
+	}
+
+	b.WriteString("\nKnowledge:\n")
+	if len(d.Knowledge) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, c := range d.Knowledge {
+		fmt.Fprintf(&b, "- %s %s #%d %s (confidence=%s, %s)\n", c.Change, c.EntityType, c.ID, c.Title, c.Confidence, shortDate(c.At))
+	}
+
+	b.WriteString("\nDrift:\n")
+	if len(d.Drift) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, e := range d.Drift {
+		fmt.Fprintf(&b, "- %s #%d %s is %s since %s: %s\n", e.EntityType, e.ID, e.Title, e.Status, shortDate(e.VerifiedAt), e.Summary)
+	}
+
+	b.WriteString("\nHotspots:\n")
+	if len(d.Hotspots) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, h := range d.Hotspots {
+		fmt.Fprintf(&b, "- %s  %d commits  %d files  +%d -%d\n", h.Package, h.Commits, h.Files, h.LinesAdded, h.LinesDeleted)
+	}
+	return b.String()
+}
+
+func syncLines(s SyncSummary) []string {
+	lines := []string{
+		fmt.Sprintf("%d syncs (%d full, %d targeted)", s.Runs, s.Full, s.Targeted),
+		fmt.Sprintf("Files: %d added, %d modified, %d removed", s.FilesAdded, s.FilesModified, s.FilesRemoved),
+	}
+	if s.Last != nil {
+		commit := s.Last.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		if commit == "" {
+			commit = "no commit"
+		}
+		lines = append(lines,
+			fmt.Sprintf("Last sync %s at `%s`: %d files, %d symbols (%+d in window)",
+				s.Last.SyncedAt.UTC().Format("2006-01-02 15:04 MST"), commit, s.Last.IndexedFiles, s.Last.IndexedSymbols, s.SymbolsDelta))
+	}
+	return lines
+}
+
+func shortDate(ts string) string {
+	if t, err := time.Parse(time.RFC3339, ts); err == nil {
+		return t.UTC().Format(dateLayout)
+	}
+	return ts
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
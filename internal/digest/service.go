@@ -0,0 +1,323 @@
+package digest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/index"
+)
+
+// Digest summarizes what happened to the index and the knowledge base
+// between Since and Until.
+type Digest struct {
+	Since     time.Time         `json:"since"`
+	Until     time.Time         `json:"until"`
+	Syncs     SyncSummary       `json:"syncs"`
+	Knowledge []KnowledgeChange `json:"knowledge"`
+	Drift     []DriftEvent      `json:"drift"`
+	Hotspots  []Hotspot         `json:"hotspots"`
+}
+
+// SyncSummary aggregates the sync_history rows of the window. SymbolsDelta
+// compares the last run with the index size before the first one.
+type SyncSummary struct {
+	Runs          int         `json:"runs"`
+	Full          int         `json:"full"`
+	Targeted      int         `json:"targeted"`
+	FilesAdded    int         `json:"files_added"`
+	FilesModified int         `json:"files_modified"`
+	FilesRemoved  int         `json:"files_removed"`
+	SymbolsDelta  int         `json:"symbols_delta"`
+	Last          *db.SyncRun `json:"last,omitempty"`
+}
+
+// KnowledgeChange is a decision or pattern created, updated, or archived in
+// the window. Change is "created", "updated", or "archived".
+type KnowledgeChange struct {
+	EntityType string `json:"entity_type"`
+	ID         int64  `json:"id"`
+	Title      string `json:"title"`
+	Change     string `json:"change"`
+	Confidence string `json:"confidence"`
+	At         string `json:"at"`
+}
+
+// DriftEvent is evidence of an active decision or pattern that was last
+// verified in the window with a drift status other than ok.
+type DriftEvent struct {
+	EntityType string `json:"entity_type"`
+	ID         int64  `json:"id"`
+	Title      string `json:"title"`
+	Status     string `json:"status"`
+	Summary    string `json:"summary"`
+	VerifiedAt string `json:"verified_at"`
+}
+
+// Hotspot is a package directory ranked by Go line churn in git history.
+type Hotspot struct {
+	Package      string `json:"package"`
+	Commits      int    `json:"commits"`
+	Files        int    `json:"files"`
+	LinesAdded   int    `json:"lines_added"`
+	LinesDeleted int    `json:"lines_deleted"`
+}
+
+// MaxHotspots caps the hotspot list.
+const MaxHotspots = 10
+
+type BuildOptions struct {
+	ModuleRoot string
+	Since      time.Time
+	Now        time.Time
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// gitNumstat is a package-level var for testability.
+var gitNumstat = func(ctx context.Context, moduleRoot string, since time.Time) ([]byte, error) {
+	return index.GitOutput(ctx, moduleRoot, "log", "--since="+since.UTC().Format(time.RFC3339),
+		"--no-renames", "--numstat", "--format=commit %H")
+}
+
+func (s *Service) Build(ctx context.Context, opts BuildOptions) (Digest, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	d := Digest{
+		Since:     opts.Since.UTC(),
+		Until:     now.UTC(),
+		Knowledge: []KnowledgeChange{},
+		Drift:     []DriftEvent{},
+		Hotspots:  []Hotspot{},
+	}
+
+	runs, err := db.ListSyncRuns(ctx, s.db, opts.Since)
+	if err != nil {
+		return Digest{}, err
+	}
+	d.Syncs = summarizeSyncs(runs)
+	if len(runs) > 0 {
+		var before int
+		err := s.db.QueryRowContext(ctx, `
+SELECT indexed_symbols FROM sync_history WHERE synced_at < ? ORDER BY synced_at DESC, id DESC LIMIT 1;
+`, d.Since.Format(time.RFC3339)).Scan(&before)
+		if err != nil && err != sql.ErrNoRows {
+			return Digest{}, fmt.Errorf("query sync baseline: %w", err)
+		}
+		d.Syncs.SymbolsDelta = d.Syncs.Last.IndexedSymbols - before
+	}
+
+	if d.Knowledge, err = s.knowledgeChanges(ctx, d.Since); err != nil {
+		return Digest{}, err
+	}
+	if d.Drift, err = s.driftEvents(ctx, d.Since); err != nil {
+		return Digest{}, err
+	}
+	if out, err := gitNumstat(ctx, opts.ModuleRoot, d.Since); err == nil {
+		d.Hotspots = parseHotspots(string(out), MaxHotspots)
+	}
+	return d, nil
+}
+
+func summarizeSyncs(runs []db.SyncRun) SyncSummary {
+	var sum SyncSummary
+	for i := range runs {
+		run := runs[i]
+		sum.Runs++
+		if run.Mode == "files" {
+			sum.Targeted++
+		} else {
+			sum.Full++
+		}
+		sum.FilesAdded += run.FilesAdded
+		sum.FilesModified += run.FilesModified
+		sum.FilesRemoved += run.FilesRemoved
+		sum.Last = &runs[i]
+	}
+	return sum
+}
+
+func (s *Service) knowledgeChanges(ctx context.Context, since time.Time) ([]KnowledgeChange, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT 'decision', id, title, confidence, status, created_at, updated_at FROM decisions WHERE updated_at >= ?1 OR created_at >= ?1
+UNION ALL
+SELECT 'pattern', id, title, confidence, status, created_at, updated_at FROM patterns WHERE updated_at >= ?1 OR created_at >= ?1
+ORDER BY 1, 2;
+`, since.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("query knowledge changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []KnowledgeChange{}
+	for rows.Next() {
+		var (
+			c                        KnowledgeChange
+			status, created, updated string
+		)
+		if err := rows.Scan(&c.EntityType, &c.ID, &c.Title, &c.Confidence, &status, &created, &updated); err != nil {
+			return nil, fmt.Errorf("scan knowledge change: %w", err)
+		}
+		switch {
+		case status == "archived":
+			c.Change, c.At = "archived", updated
+		case created >= since.Format(time.RFC3339):
+			c.Change, c.At = "created", created
+		default:
+			c.Change, c.At = "updated", updated
+		}
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate knowledge changes: %w", err)
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].At < changes[j].At })
+	return changes, nil
+}
+
+func (s *Service) driftEvents(ctx context.Context, since time.Time) ([]DriftEvent, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT e.entity_type, e.entity_id, COALESCE(d.title, p.title, c.title, ''), e.drift_status, e.summary, e.last_verified_at
+FROM evidence e
+JOIN entity_evidence g ON g.entity_type = e.entity_type AND g.entity_id = e.entity_id
+LEFT JOIN decisions d ON e.entity_type = 'decision' AND d.id = e.entity_id
+LEFT JOIN patterns p ON e.entity_type = 'pattern' AND p.id = e.entity_id
+LEFT JOIN constraints c ON e.entity_type = 'constraint' AND c.id = e.entity_id
+WHERE COALESCE(e.drift_status, 'ok') != 'ok'
+  AND g.drift_status != 'ok'
+  AND e.last_verified_at >= ?
+  AND COALESCE(d.status, p.status, c.status) = 'active'
+ORDER BY e.last_verified_at, e.entity_type, e.entity_id;
+`, since.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("query drift events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []DriftEvent{}
+	for rows.Next() {
+		var e DriftEvent
+		if err := rows.Scan(&e.EntityType, &e.ID, &e.Title, &e.Status, &e.Summary, &e.VerifiedAt); err != nil {
+			return nil, fmt.Errorf("scan drift event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate drift events: %w", err)
+	}
+	return events, nil
+}
+
+// parseHotspots aggregates `git log --numstat --format="commit %H"` output
+// by the directory of each Go file and returns the limit packages with the
+// most changed lines.
+func parseHotspots(out string, limit int) []Hotspot {
+	type churn struct {
+		Hotspot
+		commits map[string]bool
+		files   map[string]bool
+	}
+	byPkg := map[string]*churn{}
+	var commit string
+	for _, line := range strings.Split(out, "\n") {
+		if rest, ok := strings.CutPrefix(line, "commit "); ok {
+			commit = rest
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 || !strings.HasSuffix(fields[2], ".go") {
+			continue
+		}
+		pkg := path.Dir(fields[2])
+		c := byPkg[pkg]
+		if c == nil {
+			c = &churn{Hotspot: Hotspot{Package: pkg}, commits: map[string]bool{}, files: map[string]bool{}}
+			byPkg[pkg] = c
+		}
+		// Binary files report "-" for both counts.
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		c.LinesAdded += added
+		c.LinesDeleted += deleted
+		c.commits[commit] = true
+		c.files[fields[2]] = true
+	}
+
+	hotspots := make([]Hotspot, 0, len(byPkg))
+	for _, c := range byPkg {
+		c.Commits, c.Files = len(c.commits), len(c.files)
+		hotspots = append(hotspots, c.Hotspot)
+	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		a, b := hotspots[i], hotspots[j]
+		if a.LinesAdded+a.LinesDeleted != b.LinesAdded+b.LinesDeleted {
+			return a.LinesAdded+a.LinesDeleted > b.LinesAdded+b.LinesDeleted
+		}
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Package < b.Package
+	})
+	if len(hotspots) > limit {
+		hotspots = hotspots[:limit]
+	}
+	return hotspots
+}
+
+// ParseSince resolves a --since value relative to now: a day or week count
+// such as "7d" or "2w", a Go duration such as "36h", or a date (YYYY-MM-DD,
+// UTC) or RFC 3339 timestamp.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return time.Time{}, fmt.Errorf("--since is required")
+	}
+	return ParseTimeFlag("--since", value, now)
+}
+
+// ParseTimeFlag resolves a point in time given on the named flag, accepting
+// the same forms as ParseSince. Relative values count back from now.
+func ParseTimeFlag(flag, value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+
+	var d time.Duration
+	if n := len(value); n > 1 && (value[n-1] == 'd' || value[n-1] == 'w') {
+		count, err := strconv.Atoi(value[:n-1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s %q: use 7d, 2w, 36h, or YYYY-MM-DD", flag, value)
+		}
+		unit := 24 * time.Hour
+		if value[n-1] == 'w' {
+			unit *= 7
+		}
+		d = time.Duration(count) * unit
+	} else {
+		var err error
+		if d, err = time.ParseDuration(value); err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s %q: use 7d, 2w, 36h, or YYYY-MM-DD", flag, value)
+		}
+	}
+	if d <= 0 {
+		return time.Time{}, fmt.Errorf("invalid %s %q: must be positive", flag, value)
+	}
+	return now.Add(-d), nil
+}
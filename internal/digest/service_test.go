@@ -0,0 +1,197 @@
+package digest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func digestTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	root := t.TempDir()
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	return conn
+}
+
+func TestBuild(t *testing.T) {
+	conn := digestTestDB(t)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 8, 9, 0, 0, 0, time.UTC)
+	since := now.Add(-7 * 24 * time.Hour)
+
+	for _, run := range []db.SyncRun{
+		{SyncedAt: since.Add(-time.Hour), Mode: "full", IndexedFiles: 5, IndexedSymbols: 40, FilesAdded: 5},
+		{SyncedAt: since.Add(time.Hour), Mode: "full", Commit: "0123456789ab", IndexedFiles: 6, IndexedSymbols: 44, FilesAdded: 1, FilesModified: 2},
+		{SyncedAt: since.Add(2 * time.Hour), Mode: "files", Commit: "0123456789ab", Dirty: true, IndexedFiles: 6, IndexedSymbols: 47, FilesModified: 1},
+	} {
+		if err := db.AppendSyncRun(ctx, conn, run); err != nil {
+			t.Fatalf("AppendSyncRun: %v", err)
+		}
+	}
+	for _, stmt := range []string{
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+			(1,'Old','r','high','active','2026-09-01T00:00:00Z','2026-09-01T00:00:00Z'),
+			(2,'Use Cobra','r','high','active','2026-10-03T00:00:00Z','2026-10-03T00:00:00Z'),
+			(3,'Drop viper','r','medium','archived','2026-09-01T00:00:00Z','2026-10-05T00:00:00Z')`,
+		`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES
+			(1,'Wrap errors','d','low','active','2026-09-01T00:00:00Z','2026-10-04T00:00:00Z')`,
+		`INSERT INTO evidence(entity_type,entity_id,summary,drift_status,last_verified_at) VALUES
+			('decision',2,'cobra in go.mod','drifting','2026-10-06T00:00:00Z'),
+			('decision',1,'old check','broken','2026-09-20T00:00:00Z'),
+			('decision',3,'viper removed','broken','2026-10-06T00:00:00Z'),
+			('pattern',1,'errors wrapped','ok','2026-10-06T00:00:00Z')`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	orig := gitNumstat
+	defer func() { gitNumstat = orig }()
+	gitNumstat = func(_ context.Context, root string, s time.Time) ([]byte, error) {
+		if root != "/repo" || !s.Equal(since) {
+			t.Fatalf("gitNumstat(%q, %v)", root, s)
+		}
+		return []byte("commit a\n\n3\t1\tinternal/cli/digest.go\n"), nil
+	}
+
+	d, err := NewService(conn).Build(ctx, BuildOptions{ModuleRoot: "/repo", Since: since, Now: now})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if d.Syncs.Runs != 2 || d.Syncs.Full != 1 || d.Syncs.Targeted != 1 || d.Syncs.FilesAdded != 1 || d.Syncs.FilesModified != 3 ||
+		d.Syncs.SymbolsDelta != 7 || d.Syncs.Last == nil || !d.Syncs.Last.Dirty {
+		t.Fatalf("unexpected sync summary: %+v", d.Syncs)
+	}
+	var changes []string
+	for _, c := range d.Knowledge {
+		changes = append(changes, c.Change+" "+c.EntityType+" "+c.Title)
+	}
+	if got := strings.Join(changes, ", "); got != "created decision Use Cobra, updated pattern Wrap errors, archived decision Drop viper" {
+		t.Fatalf("unexpected knowledge changes: %s", got)
+	}
+	if len(d.Drift) != 1 || d.Drift[0].Title != "Use Cobra" || d.Drift[0].Status != "drifting" {
+		t.Fatalf("unexpected drift: %+v", d.Drift)
+	}
+	if len(d.Hotspots) != 1 || d.Hotspots[0].Package != "internal/cli" {
+		t.Fatalf("unexpected hotspots: %+v", d.Hotspots)
+	}
+
+	md := RenderMarkdown(d)
+	for _, want := range []string{
+		"# Recon digest: 2026-10-01 to 2026-10-08",
+		"- 2 syncs (1 full, 1 targeted)",
+		"`0123456`: 6 files, 47 symbols (+7 in window)",
+		"| archived | decision #3 | Drop viper | medium | 2026-10-05 |",
+		"- **Use Cobra** (decision #2) is drifting since 2026-10-06: cobra in go.mod",
+		"| `internal/cli` | 1 | 1 | 3 | 1 |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if text := RenderText(d); !strings.Contains(text, "- internal/cli  1 commits  1 files  +3 -1") || strings.Contains(text, "`") {
+		t.Fatalf("unexpected text digest:\n%s", text)
+	}
+
+	// An empty window renders every section, and git failures are not fatal.
+	gitNumstat = func(context.Context, string, time.Time) ([]byte, error) { return nil, errors.New("not a git repo") }
+	d, err = NewService(conn).Build(ctx, BuildOptions{Since: now, Now: now})
+	if err != nil {
+		t.Fatalf("Build empty window: %v", err)
+	}
+	md = RenderMarkdown(d)
+	for _, want := range []string{"_No syncs._", "_No new or changed decisions or patterns._", "_No drift detected._", "_No Go changes in git history._"} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("empty markdown missing %q:\n%s", want, md)
+		}
+	}
+	if text := RenderText(d); strings.Count(text, "- (none)") != 4 {
+		t.Fatalf("unexpected empty text digest:\n%s", text)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	for _, table := range []string{"sync_history", "decisions", "evidence"} {
+		conn := digestTestDB(t)
+		if err := db.AppendSyncRun(context.Background(), conn, db.SyncRun{SyncedAt: time.Now(), Mode: "full"}); err != nil {
+			t.Fatalf("AppendSyncRun: %v", err)
+		}
+		if _, err := conn.Exec("DROP TABLE " + table); err != nil {
+			t.Fatalf("drop %s: %v", table, err)
+		}
+		if _, err := NewService(conn).Build(context.Background(), BuildOptions{Since: time.Now().Add(-time.Hour)}); err == nil {
+			t.Fatalf("expected error with %s dropped", table)
+		}
+	}
+}
+
+func TestParseHotspots(t *testing.T) {
+	out := strings.Join([]string{
+		"commit a",
+		"",
+		"10\t2\tinternal/a/x.go",
+		"1\t1\tinternal/a/y.go",
+		"5\t0\tREADME.md",
+		"-\t-\tinternal/b/data.go",
+		"commit b",
+		"",
+		"4\t4\tinternal/a/x.go",
+		"6\t0\tinternal/c/z.go",
+		"3\t3\tmain.go",
+	}, "\n")
+
+	got := parseHotspots(out, 3)
+	want := []Hotspot{
+		{Package: "internal/a", Commits: 2, Files: 2, LinesAdded: 15, LinesDeleted: 7},
+		{Package: ".", Commits: 1, Files: 1, LinesAdded: 3, LinesDeleted: 3},
+		{Package: "internal/c", Commits: 1, Files: 1, LinesAdded: 6, LinesDeleted: 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseHotspots = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("hotspot %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 10, 8, 12, 0, 0, 0, time.UTC)
+	for value, want := range map[string]time.Time{
+		"7d":                   now.Add(-7 * 24 * time.Hour),
+		"2w":                   now.Add(-14 * 24 * time.Hour),
+		" 36h ":                now.Add(-36 * time.Hour),
+		"2026-10-01":           time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		"2026-10-01T08:00:00Z": time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC),
+	} {
+		got, err := ParseSince(value, now)
+		if err != nil || !got.Equal(want) {
+			t.Fatalf("ParseSince(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "xd", "week", "0d", "-3h"} {
+		if _, err := ParseSince(value, now); err == nil {
+			t.Fatalf("ParseSince(%q) expected error", value)
+		}
+	}
+	if _, err := ParseTimeFlag("--before", "soon", now); err == nil || !strings.Contains(err.Error(), "invalid --before") {
+		t.Fatalf("ParseTimeFlag error = %v, want it to name --before", err)
+	}
+}
@@ -0,0 +1,244 @@
+package doctor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/install"
+)
+
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+)
+
+type Check struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+type Report struct {
+	Checks []Check `json:"checks"`
+	OK     bool    `json:"ok"`
+}
+
+// Options identifies the running recon build, which the binary check
+// compares with the recon found on PATH.
+type Options struct {
+	ModuleRoot string
+	Version    string
+	Commit     string
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+// NewService returns a Service over conn, which is nil when the project has
+// no database yet.
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// Run checks the database, the Claude Code hook, and the recon binary the
+// hook runs. Report.OK is false when any check warns.
+func (s *Service) Run(ctx context.Context, opts Options) Report {
+	report := Report{Checks: []Check{
+		s.checkDatabase(ctx),
+		checkHook(opts.ModuleRoot),
+		CheckBinary(ctx, opts),
+	}}
+	report.OK = true
+	for _, c := range report.Checks {
+		if c.Status != StatusOK {
+			report.OK = false
+		}
+	}
+	return report
+}
+
+func (s *Service) checkDatabase(ctx context.Context) Check {
+	c := Check{Name: "database"}
+	if s.db == nil {
+		c.Status, c.Message, c.Hint = StatusWarn, "database not initialized", "run `recon init`"
+		return c
+	}
+	expected, err := db.LatestMigrationVersion()
+	if err != nil {
+		c.Status, c.Message = StatusWarn, err.Error()
+		return c
+	}
+	var (
+		version int
+		dirty   bool
+	)
+	if err := s.db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations;`).Scan(&version, &dirty); err != nil {
+		c.Status, c.Message, c.Hint = StatusWarn, fmt.Sprintf("read schema version: %v", err), "run `recon init --force`"
+		return c
+	}
+	switch {
+	case dirty:
+		c.Status, c.Message, c.Hint = StatusWarn, fmt.Sprintf("schema version %d is dirty: a migration failed partway", version), "run `recon reset` and `recon init`"
+	case version < expected:
+		c.Status, c.Message, c.Hint = StatusWarn, fmt.Sprintf("schema version %d, this recon expects %d", version, expected), "run `recon migrate up`"
+	case version > expected:
+		c.Status, c.Message, c.Hint = StatusWarn, fmt.Sprintf("schema version %d is newer than this recon (%d)", version, expected), "upgrade recon"
+	default:
+		c.Status, c.Message = StatusOK, fmt.Sprintf("schema version %d", version)
+	}
+	return c
+}
+
+func checkHook(root string) Check {
+	c := Check{Name: "hook"}
+	script, registered, err := install.HookStatus(root)
+	switch {
+	case err != nil:
+		c.Status, c.Message = StatusWarn, err.Error()
+	case !script && !registered:
+		c.Status, c.Message = StatusOK, "Claude Code hook not installed"
+	case !script:
+		c.Status, c.Message, c.Hint = StatusWarn, "settings.json registers the SessionStart hook but its script is missing", "run `recon init --force`"
+	case !registered:
+		c.Status, c.Message, c.Hint = StatusWarn, "SessionStart hook script exists but settings.json does not register it", "run `recon init --force`"
+	default:
+		c.Status, c.Message = StatusOK, "SessionStart hook installed and registered"
+	}
+	return c
+}
+
+var (
+	lookPath   = exec.LookPath
+	executable = os.Executable
+	// binaryVersion runs `<path> version --json`.
+	binaryVersion = func(ctx context.Context, path string) (string, string, error) {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, path, "version", "--json").Output()
+		if err != nil {
+			return "", "", err
+		}
+		var v struct {
+			Version string `json:"version"`
+			Commit  string `json:"commit"`
+		}
+		if err := json.Unmarshal(out, &v); err != nil {
+			return "", "", fmt.Errorf("parse version output: %w", err)
+		}
+		return v.Version, v.Commit, nil
+	}
+)
+
+// CheckBinary verifies that the `recon` the installed hooks invoke resolves
+// on PATH and is this build, or at least the same version. A different
+// version means agents get context from a stale binary.
+func CheckBinary(ctx context.Context, opts Options) Check {
+	c := Check{Name: "binary"}
+	self, err := executable()
+	if err == nil {
+		self = resolve(self)
+	}
+
+	found, err := lookPath("recon")
+	if err != nil {
+		c.Status = StatusWarn
+		c.Message = "recon is not on PATH, so the SessionStart hook cannot run it"
+		if self != "" {
+			c.Hint = fmt.Sprintf("add %s to PATH", filepath.Dir(self))
+		}
+		return c
+	}
+	found = resolve(found)
+	method := InstallMethod(found)
+	if found == self {
+		c.Status, c.Message = StatusOK, fmt.Sprintf("recon on PATH is this binary (%s, %s)", found, method)
+		return c
+	}
+
+	version, commit, err := binaryVersion(ctx, found)
+	if err != nil {
+		c.Status = StatusWarn
+		c.Message = fmt.Sprintf("recon on PATH (%s) did not report its version: %v", found, err)
+		c.Hint = upgradeHint(method, found)
+		return c
+	}
+	if version == opts.Version && commit == opts.Commit {
+		c.Status, c.Message = StatusOK, fmt.Sprintf("recon on PATH (%s, %s) is the same version, %s", found, method, version)
+		return c
+	}
+	c.Status = StatusWarn
+	c.Message = fmt.Sprintf("the SessionStart hook runs recon %s (commit %s) from %s, not this recon %s (commit %s)",
+		version, commit, found, opts.Version, opts.Commit)
+	c.Hint = upgradeHint(method, found)
+	return c
+}
+
+// InstallMethod guesses how the binary at path was installed: "homebrew",
+// "scoop", "go install", or "manual".
+func InstallMethod(path string) string {
+	p := strings.ToLower(strings.ReplaceAll(path, `\`, "/"))
+	switch {
+	case strings.Contains(p, "/cellar/"), strings.Contains(p, "/homebrew/"), strings.Contains(p, "/linuxbrew/"):
+		return "homebrew"
+	case strings.Contains(p, "/scoop/"):
+		return "scoop"
+	}
+	for _, dir := range goBinDirs() {
+		if strings.EqualFold(filepath.Dir(path), dir) {
+			return "go install"
+		}
+	}
+	return "manual"
+}
+
+func goBinDirs() []string {
+	if gobin := os.Getenv("GOBIN"); gobin != "" {
+		return []string{filepath.Clean(gobin)}
+	}
+	var dirs []string
+	for _, p := range filepath.SplitList(os.Getenv("GOPATH")) {
+		if p != "" {
+			dirs = append(dirs, filepath.Join(p, "bin"))
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, "go", "bin"))
+	}
+	return dirs
+}
+
+func upgradeHint(method, path string) string {
+	switch method {
+	case "homebrew":
+		return "run `brew upgrade recon`"
+	case "scoop":
+		return "run `scoop update recon`"
+	case "go install":
+		return "run `go install github.com/robertguss/recon/cmd/recon@latest`"
+	}
+	return fmt.Sprintf("replace %s with the current recon, or put the current one earlier on PATH", path)
+}
+
+// resolve follows symlinks, as package managers link their binaries into a
+// shared bin directory.
+func resolve(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return path
+}
@@ -0,0 +1,126 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/install"
+)
+
+// stubBinaries makes the running binary self and the recon on PATH found
+// (empty when not on PATH), reporting version and commit when run.
+func stubBinaries(t *testing.T, self, found, version, commit string) {
+	t.Helper()
+	origLook, origExe, origVersion := lookPath, executable, binaryVersion
+	t.Cleanup(func() { lookPath, executable, binaryVersion = origLook, origExe, origVersion })
+	executable = func() (string, error) { return self, nil }
+	lookPath = func(string) (string, error) {
+		if found == "" {
+			return "", errors.New("executable file not found in $PATH")
+		}
+		return found, nil
+	}
+	binaryVersion = func(context.Context, string) (string, string, error) { return version, commit, nil }
+}
+
+func TestCheckBinary(t *testing.T) {
+	ctx := context.Background()
+	opts := Options{Version: "v1.2.0", Commit: "abc"}
+
+	stubBinaries(t, "/usr/local/bin/recon", "/usr/local/bin/recon", "", "")
+	if c := CheckBinary(ctx, opts); c.Status != StatusOK || !strings.Contains(c.Message, "is this binary") {
+		t.Fatalf("same binary: %+v", c)
+	}
+
+	stubBinaries(t, "/home/me/bin/recon", "/usr/local/bin/recon", "v1.2.0", "abc")
+	if c := CheckBinary(ctx, opts); c.Status != StatusOK || !strings.Contains(c.Message, "same version, v1.2.0") {
+		t.Fatalf("same version: %+v", c)
+	}
+
+	stubBinaries(t, "/home/me/bin/recon", "/opt/homebrew/Cellar/recon/1.0.0/bin/recon", "v1.0.0", "old")
+	c := CheckBinary(ctx, opts)
+	if c.Status != StatusWarn || !strings.Contains(c.Message, "runs recon v1.0.0 (commit old)") || c.Hint != "run `brew upgrade recon`" {
+		t.Fatalf("stale binary: %+v", c)
+	}
+
+	stubBinaries(t, "/home/me/bin/recon", "", "", "")
+	if c := CheckBinary(ctx, opts); c.Status != StatusWarn || !strings.Contains(c.Message, "not on PATH") || c.Hint != "add /home/me/bin to PATH" {
+		t.Fatalf("missing binary: %+v", c)
+	}
+
+	stubBinaries(t, "/home/me/bin/recon", "/usr/local/bin/recon", "", "")
+	binaryVersion = func(context.Context, string) (string, string, error) { return "", "", errors.New("exit status 1") }
+	if c := CheckBinary(ctx, opts); c.Status != StatusWarn || !strings.Contains(c.Message, "did not report its version") || !strings.Contains(c.Hint, "replace /usr/local/bin/recon") {
+		t.Fatalf("broken binary: %+v", c)
+	}
+}
+
+func TestInstallMethod(t *testing.T) {
+	t.Setenv("GOBIN", "/home/me/go/bin")
+	for path, want := range map[string]string{
+		"/opt/homebrew/Cellar/recon/1.0.0/bin/recon":     "homebrew",
+		"/home/linuxbrew/.linuxbrew/bin/recon":           "homebrew",
+		`C:\Users\me\scoop\apps\recon\current\recon.exe`: "scoop",
+		"/home/me/go/bin/recon":                          "go install",
+		"/usr/local/bin/recon":                           "manual",
+	} {
+		if got := InstallMethod(path); got != want {
+			t.Errorf("InstallMethod(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	stubBinaries(t, "/usr/local/bin/recon", "/usr/local/bin/recon", "", "")
+
+	report := NewService(nil).Run(ctx, Options{ModuleRoot: root})
+	if report.OK || len(report.Checks) != 3 || report.Checks[0].Message != "database not initialized" ||
+		report.Checks[1].Status != StatusOK || report.Checks[1].Message != "Claude Code hook not installed" {
+		t.Fatalf("uninitialized report: %+v", report)
+	}
+
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	if err := install.InstallHook(root); err != nil {
+		t.Fatalf("InstallHook: %v", err)
+	}
+
+	report = NewService(conn).Run(ctx, Options{ModuleRoot: root})
+	if report.OK || report.Checks[0].Status != StatusOK || !strings.Contains(report.Checks[1].Message, "does not register it") {
+		t.Fatalf("unregistered hook report: %+v", report)
+	}
+
+	if err := install.InstallSettings(root); err != nil {
+		t.Fatalf("InstallSettings: %v", err)
+	}
+	if report = NewService(conn).Run(ctx, Options{ModuleRoot: root}); !report.OK {
+		t.Fatalf("healthy report: %+v", report)
+	}
+
+	if err := os.Remove(filepath.Join(root, ".claude", "hooks", "recon-orient.sh")); err != nil {
+		t.Fatalf("remove hook: %v", err)
+	}
+	if _, err := conn.Exec(`UPDATE schema_migrations SET version = 1`); err != nil {
+		t.Fatalf("downgrade version: %v", err)
+	}
+	report = NewService(conn).Run(ctx, Options{ModuleRoot: root})
+	if !strings.Contains(report.Checks[0].Message, "schema version 1, this recon expects") || !strings.Contains(report.Checks[1].Message, "script is missing") {
+		t.Fatalf("degraded report: %+v", report)
+	}
+}
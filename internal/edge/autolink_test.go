@@ -108,7 +108,7 @@ func TestAutoLink_FindsDistinctiveSymbols(t *testing.T) {
 	var fileID int64
 	conn.QueryRowContext(context.Background(), `SELECT id FROM files WHERE path = 'internal/cli/exit_error.go'`).Scan(&fileID)
 	conn.ExecContext(context.Background(),
-		`INSERT INTO symbols (file_id, kind, name, signature, body, line_start, line_end, exported, receiver) VALUES (?, 'type', 'ExitError', '', '{}', 1, 5, 1, '')`, fileID)
+		`INSERT INTO symbols (file_id, kind, name, signature, line_start, line_end, exported, receiver) VALUES (?, 'type', 'ExitError', '', 1, 5, 1, '')`, fileID)
 
 	linker := NewAutoLinker(conn)
 	edges := linker.Detect(context.Background(), "decision", 1, "ExitError is the standard error type", "All commands return ExitError")
@@ -200,7 +200,7 @@ func TestAutoLink_SkipsShortSymbolNames(t *testing.T) {
 	var fileID int64
 	conn.QueryRowContext(context.Background(), `SELECT id FROM files WHERE path = 'internal/cli/run.go'`).Scan(&fileID)
 	conn.ExecContext(context.Background(),
-		`INSERT INTO symbols (file_id, kind, name, signature, body, line_start, line_end, exported, receiver) VALUES (?, 'func', 'Run', '', '{}', 1, 5, 1, '')`, fileID)
+		`INSERT INTO symbols (file_id, kind, name, signature, line_start, line_end, exported, receiver) VALUES (?, 'func', 'Run', '', 1, 5, 1, '')`, fileID)
 
 	linker := NewAutoLinker(conn)
 	edges := linker.Detect(context.Background(), "decision", 1, "Run function", "We use Run everywhere")
@@ -9,16 +9,18 @@ import (
 )
 
 var validFromTypes = map[string]bool{
-	"decision": true,
-	"pattern":  true,
+	"decision":   true,
+	"pattern":    true,
+	"constraint": true,
 }
 
 var validToTypes = map[string]bool{
-	"decision": true,
-	"pattern":  true,
-	"package":  true,
-	"file":     true,
-	"symbol":   true,
+	"decision":   true,
+	"pattern":    true,
+	"constraint": true,
+	"package":    true,
+	"file":       true,
+	"symbol":     true,
 }
 
 var validRelations = map[string]bool{
@@ -193,10 +195,11 @@ func (s *Service) ListAllWithTitles(ctx context.Context) ([]EdgeWithTitle, error
 	return s.queryWithTitles(ctx, `
 SELECT e.id, e.from_type, e.from_id, e.to_type, e.to_ref, e.relation,
        e.source, e.confidence, e.created_at,
-       COALESCE(d.title, p.title, '') as from_title
+       COALESCE(d.title, p.title, c.title, '') as from_title
 FROM edges e
 LEFT JOIN decisions d ON e.from_type = 'decision' AND e.from_id = d.id
 LEFT JOIN patterns p ON e.from_type = 'pattern' AND e.from_id = p.id
+LEFT JOIN constraints c ON e.from_type = 'constraint' AND e.from_id = c.id
 ORDER BY e.from_type, e.from_id, e.relation, e.to_type, e.to_ref;
 `)
 }
@@ -205,10 +208,11 @@ func (s *Service) ListFromWithTitles(ctx context.Context, fromType string, fromI
 	return s.queryWithTitles(ctx, `
 SELECT e.id, e.from_type, e.from_id, e.to_type, e.to_ref, e.relation,
        e.source, e.confidence, e.created_at,
-       COALESCE(d.title, p.title, '') as from_title
+       COALESCE(d.title, p.title, c.title, '') as from_title
 FROM edges e
 LEFT JOIN decisions d ON e.from_type = 'decision' AND e.from_id = d.id
 LEFT JOIN patterns p ON e.from_type = 'pattern' AND e.from_id = p.id
+LEFT JOIN constraints c ON e.from_type = 'constraint' AND e.from_id = c.id
 WHERE e.from_type = ? AND e.from_id = ?
 ORDER BY e.relation, e.to_type, e.to_ref;
 `, fromType, fromID)
@@ -218,10 +222,11 @@ func (s *Service) ListToWithTitles(ctx context.Context, toType, toRef string) ([
 	return s.queryWithTitles(ctx, `
 SELECT e.id, e.from_type, e.from_id, e.to_type, e.to_ref, e.relation,
        e.source, e.confidence, e.created_at,
-       COALESCE(d.title, p.title, '') as from_title
+       COALESCE(d.title, p.title, c.title, '') as from_title
 FROM edges e
 LEFT JOIN decisions d ON e.from_type = 'decision' AND e.from_id = d.id
 LEFT JOIN patterns p ON e.from_type = 'pattern' AND e.from_id = p.id
+LEFT JOIN constraints c ON e.from_type = 'constraint' AND e.from_id = c.id
 WHERE e.to_type = ? AND e.to_ref = ?
 ORDER BY e.relation, e.from_type, e.from_id;
 `, toType, toRef)
@@ -268,10 +273,10 @@ func validate(in CreateInput) error {
 		return fmt.Errorf("relation is required")
 	}
 	if !validFromTypes[in.FromType] {
-		return fmt.Errorf("invalid from_type %q; must be one of: decision, pattern", in.FromType)
+		return fmt.Errorf("invalid from_type %q; must be one of: decision, pattern, constraint", in.FromType)
 	}
 	if !validToTypes[in.ToType] {
-		return fmt.Errorf("invalid to_type %q; must be one of: decision, pattern, package, file, symbol", in.ToType)
+		return fmt.Errorf("invalid to_type %q; must be one of: decision, pattern, constraint, package, file, symbol", in.ToType)
 	}
 	if !validRelations[in.Relation] {
 		return fmt.Errorf("invalid relation %q; must be one of: affects, evidenced_by, supersedes, contradicts, related, reinforces", in.Relation)
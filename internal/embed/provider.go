@@ -0,0 +1,236 @@
+package embed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/robertguss/recon/internal/config"
+)
+
+// ErrNotConfigured is returned by NewProvider when embeddings.provider is
+// not set in .recon/config.json.
+var ErrNotConfigured = errors.New("embeddings are not configured; set embeddings.provider in .recon/config.json to local or openai")
+
+// Provider turns texts into vectors. Vectors from one provider are compared
+// only with each other, so Name must change whenever the vector space does,
+// e.g. with the model.
+type Provider interface {
+	Name() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Defaults of the openai provider.
+const (
+	DefaultOpenAIURL   = "https://api.openai.com/v1"
+	DefaultOpenAIModel = "text-embedding-3-small"
+	DefaultAPIKeyEnv   = "OPENAI_API_KEY"
+)
+
+// NewProvider returns the provider cfg selects.
+func NewProvider(cfg config.Embeddings) (Provider, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, ErrNotConfigured
+	case "local":
+		return localProvider{}, nil
+	case "openai":
+		p := openAIProvider{
+			url:    strings.TrimSuffix(cfg.URL, "/"),
+			model:  cfg.Model,
+			client: &http.Client{Timeout: 60 * time.Second},
+		}
+		if p.url == "" {
+			p.url = DefaultOpenAIURL
+		}
+		if p.model == "" {
+			p.model = DefaultOpenAIModel
+		}
+		keyEnv := cfg.APIKeyEnv
+		if keyEnv == "" {
+			keyEnv = DefaultAPIKeyEnv
+		}
+		p.key = os.Getenv(keyEnv)
+		// Local servers such as Ollama take no key; the OpenAI API does.
+		if p.key == "" && p.url == DefaultOpenAIURL {
+			return nil, fmt.Errorf("embeddings provider openai needs an API key in $%s", keyEnv)
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("unknown embeddings provider %q", cfg.Provider)
+	}
+}
+
+// localDims is the vector size of the local provider.
+const localDims = 256
+
+// localProvider hashes the words of a text, split at camelCase and
+// snake_case boundaries, and their trigrams into a fixed-size vector. It
+// needs no network or model, and matches texts that share vocabulary even
+// in other word forms ("retry", "retries", "RetryPolicy"), but it has no
+// notion of synonyms.
+type localProvider struct{}
+
+func (localProvider) Name() string { return "local" }
+
+func (localProvider) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = hashVector(text)
+	}
+	return vectors, nil
+}
+
+var termRE = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// terms returns the lowercased words of text, with identifiers split into
+// their parts.
+func terms(text string) []string {
+	var out []string
+	for _, word := range termRE.FindAllString(text, -1) {
+		start := 0
+		runes := []rune(word)
+		for i := 1; i < len(runes); i++ {
+			// Split before an upper-case letter that follows a lower-case
+			// one, or that starts a word after an acronym ("HTTPServer").
+			if unicode.IsUpper(runes[i]) && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+				out = append(out, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+		out = append(out, strings.ToLower(string(runes[start:])))
+	}
+	return out
+}
+
+func hashVector(text string) []float32 {
+	v := make([]float32, localDims)
+	add := func(feature string, weight float32) {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(feature))
+		sum := h.Sum32()
+		if sum&(1<<31) != 0 {
+			weight = -weight
+		}
+		v[sum%localDims] += weight
+	}
+	for _, term := range terms(text) {
+		if len(term) < 2 {
+			continue
+		}
+		add(term, 1)
+		padded := "<" + term + ">"
+		for i := 0; i+3 <= len(padded); i++ {
+			add(padded[i:i+3], 0.5)
+		}
+	}
+	normalize(v)
+	return v
+}
+
+func normalize(v []float32) {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+// openAIBatch is how many texts one embeddings request carries.
+const openAIBatch = 64
+
+// openAIProvider calls the embeddings endpoint of the OpenAI API or of a
+// server that speaks it, such as Ollama or vLLM.
+type openAIProvider struct {
+	url    string
+	model  string
+	key    string
+	client *http.Client
+}
+
+func (p openAIProvider) Name() string { return "openai:" + p.model }
+
+func (p openAIProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += openAIBatch {
+		batch := texts[start:min(start+openAIBatch, len(texts))]
+		got, err := p.request(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, got...)
+	}
+	return vectors, nil
+}
+
+func (p openAIProvider) request(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]any{"model": p.model, "input": texts})
+	if err != nil {
+		return nil, fmt.Errorf("encode embeddings request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build embeddings request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.key != "" {
+		req.Header.Set("Authorization", "Bearer "+p.key)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read embeddings response: %w", err)
+	}
+
+	var decoded struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &decoded); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("decode embeddings response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(raw))
+		if decoded.Error != nil && decoded.Error.Message != "" {
+			msg = decoded.Error.Message
+		}
+		return nil, fmt.Errorf("embeddings request: %s: %s", resp.Status, msg)
+	}
+	if len(decoded.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings response has %d vectors for %d inputs", len(decoded.Data), len(texts))
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range decoded.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embeddings response has out-of-range index %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
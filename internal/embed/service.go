@@ -0,0 +1,421 @@
+// Package embed computes and searches vectors for recorded knowledge and
+// documented symbols, so recall can rank by meaning instead of shared words.
+// It is opt-in: nothing is embedded until `recon embed` runs with a provider
+// configured.
+package embed
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypeSymbol is the entity type of symbol vectors. Knowledge vectors use
+// the entity types of the knowledge tables: decision, pattern, constraint.
+const TypeSymbol = "symbol"
+
+// ErrNoEmbeddings is returned by Search when the provider has no vectors
+// stored, e.g. before the first `recon embed`.
+var ErrNoEmbeddings = errors.New("no embeddings stored for this provider; run `recon embed`")
+
+// Result reports one `recon embed` run. Embedded counts the vectors
+// computed, per entity type in ByType; Unchanged those kept because their
+// text did not change; Removed those of entities that are gone.
+type Result struct {
+	Provider  string         `json:"provider"`
+	Embedded  int            `json:"embedded"`
+	ByType    map[string]int `json:"by_type"`
+	Unchanged int            `json:"unchanged"`
+	Removed   int            `json:"removed"`
+}
+
+// Match is one stored vector ranked against a query. Ref is the entity's id
+// for knowledge, and the symbol's "package.Name" or "package.Receiver.Name"
+// for symbols, which also carry their current location.
+type Match struct {
+	EntityType string  `json:"entity_type"`
+	Ref        string  `json:"ref"`
+	Score      float64 `json:"score"`
+	Symbol     *Symbol `json:"symbol,omitempty"`
+}
+
+// Symbol locates a matched symbol in the current index.
+type Symbol struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Receiver string `json:"receiver,omitempty"`
+	Package  string `json:"package"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// document is one text to embed.
+type document struct {
+	entityType string
+	ref        string
+	text       string
+}
+
+// Embed computes vectors with p for every active decision, pattern, and
+// constraint and for every Go symbol with a doc comment, read from the
+// files under moduleRoot. Texts whose hash matches the stored vector are
+// skipped unless rebuild is set, and vectors of entities that no longer
+// exist are deleted.
+func (s *Service) Embed(ctx context.Context, moduleRoot string, p Provider, rebuild bool) (Result, error) {
+	docs, err := s.documents(ctx, moduleRoot)
+	if err != nil {
+		return Result{}, err
+	}
+	stored, err := s.storedHashes(ctx, p.Name())
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{Provider: p.Name(), ByType: map[string]int{}}
+	var (
+		pending []document
+		hashes  []string
+		keep    = map[string]bool{}
+	)
+	for _, doc := range docs {
+		key := doc.entityType + ":" + doc.ref
+		keep[key] = true
+		hash := contentHash(doc.text)
+		if !rebuild && stored[key] == hash {
+			res.Unchanged++
+			continue
+		}
+		pending = append(pending, doc)
+		hashes = append(hashes, hash)
+	}
+
+	texts := make([]string, len(pending))
+	for i, doc := range pending {
+		texts[i] = doc.text
+	}
+	var vectors [][]float32
+	if len(texts) > 0 {
+		if vectors, err = p.Embed(ctx, texts); err != nil {
+			return Result{}, fmt.Errorf("embed with %s: %w", p.Name(), err)
+		}
+		if len(vectors) != len(texts) {
+			return Result{}, fmt.Errorf("embed with %s: got %d vectors for %d texts", p.Name(), len(vectors), len(texts))
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("begin embeddings tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	now := time.Now().UTC().Format(time.RFC3339)
+	for i, doc := range pending {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO embeddings(provider, entity_type, entity_ref, content_hash, dims, vector, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(provider, entity_type, entity_ref) DO UPDATE SET
+    content_hash = excluded.content_hash, dims = excluded.dims,
+    vector = excluded.vector, updated_at = excluded.updated_at;
+`, p.Name(), doc.entityType, doc.ref, hashes[i], len(vectors[i]), encodeVector(vectors[i]), now); err != nil {
+			return Result{}, fmt.Errorf("store embedding: %w", err)
+		}
+		res.Embedded++
+		res.ByType[doc.entityType]++
+	}
+	for key := range stored {
+		if keep[key] {
+			continue
+		}
+		entityType, ref, _ := strings.Cut(key, ":")
+		if _, err := tx.ExecContext(ctx, `DELETE FROM embeddings WHERE provider = ? AND entity_type = ? AND entity_ref = ?`,
+			p.Name(), entityType, ref); err != nil {
+			return Result{}, fmt.Errorf("delete embedding: %w", err)
+		}
+		res.Removed++
+	}
+	if err := tx.Commit(); err != nil {
+		return Result{}, fmt.Errorf("commit embeddings: %w", err)
+	}
+	return res, nil
+}
+
+// Search embeds query with p and returns the limit stored vectors of p most
+// similar to it by cosine similarity, best first. Symbols no longer in the
+// index are skipped. It returns ErrNoEmbeddings when p has none stored.
+func (s *Service) Search(ctx context.Context, p Provider, query string, limit int) ([]Match, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM embeddings WHERE provider = ?`, p.Name()).Scan(&count); err != nil {
+		return nil, fmt.Errorf("count embeddings: %w", err)
+	}
+	if count == 0 {
+		return nil, ErrNoEmbeddings
+	}
+	vectors, err := p.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query with %s: %w", p.Name(), err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embed query with %s: got %d vectors", p.Name(), len(vectors))
+	}
+	q := vectors[0]
+
+	rows, err := s.db.QueryContext(ctx, `SELECT entity_type, entity_ref, vector FROM embeddings WHERE provider = ?`, p.Name())
+	if err != nil {
+		return nil, fmt.Errorf("query embeddings: %w", err)
+	}
+	defer rows.Close()
+	var matches []Match
+	for rows.Next() {
+		var (
+			m    Match
+			blob []byte
+		)
+		if err := rows.Scan(&m.EntityType, &m.Ref, &blob); err != nil {
+			return nil, fmt.Errorf("scan embedding: %w", err)
+		}
+		v := decodeVector(blob)
+		if len(v) != len(q) {
+			continue
+		}
+		m.Score = cosine(q, v)
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate embeddings: %w", err)
+	}
+	rows.Close()
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if matches[i].EntityType != matches[j].EntityType {
+			return matches[i].EntityType < matches[j].EntityType
+		}
+		return matches[i].Ref < matches[j].Ref
+	})
+
+	symbols, err := s.symbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Match, 0, limit)
+	for _, m := range matches {
+		if len(out) == limit {
+			break
+		}
+		if m.EntityType == TypeSymbol {
+			sym, ok := symbols[m.Ref]
+			if !ok {
+				continue
+			}
+			m.Symbol = &sym.Symbol
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// documents returns the texts to embed: each knowledge entity's title and
+// reasoning, and each documented symbol's kind, name, signature, and doc
+// comment.
+func (s *Service) documents(ctx context.Context, moduleRoot string) ([]document, error) {
+	var docs []document
+	for _, q := range []struct {
+		entityType string
+		query      string
+	}{
+		{"decision", `SELECT id, title, reasoning FROM decisions WHERE status = 'active' ORDER BY id`},
+		{"pattern", `SELECT id, title, description FROM patterns WHERE status = 'active' ORDER BY id`},
+		{"constraint", `SELECT id, title, reasoning FROM constraints WHERE status = 'active' ORDER BY id`},
+	} {
+		rows, err := s.db.QueryContext(ctx, q.query)
+		if err != nil {
+			return nil, fmt.Errorf("query %ss: %w", q.entityType, err)
+		}
+		for rows.Next() {
+			var (
+				id           int64
+				title, about string
+			)
+			if err := rows.Scan(&id, &title, &about); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan %s: %w", q.entityType, err)
+			}
+			docs = append(docs, document{entityType: q.entityType, ref: strconv.FormatInt(id, 10), text: title + "\n" + about})
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("iterate %ss: %w", q.entityType, err)
+		}
+	}
+
+	symbols, err := s.symbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]string, 0, len(symbols))
+	for ref := range symbols {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	files := map[string][]string{}
+	for _, ref := range refs {
+		sym := symbols[ref]
+		lines, ok := files[sym.FilePath]
+		if !ok {
+			data, err := os.ReadFile(filepath.Join(moduleRoot, filepath.FromSlash(sym.FilePath)))
+			if err == nil {
+				lines = strings.Split(string(data), "\n")
+			}
+			files[sym.FilePath] = lines
+		}
+		doc := docComment(lines, sym.Line)
+		if doc == "" {
+			continue
+		}
+		text := sym.Kind + " " + label(sym.Symbol) + "\n"
+		if sig, _, _ := strings.Cut(sym.signature, "\n"); sig != "" {
+			text += sig + "\n"
+		}
+		docs = append(docs, document{entityType: TypeSymbol, ref: ref, text: text + doc})
+	}
+	return docs, nil
+}
+
+type indexedSymbol struct {
+	Symbol
+	signature string
+}
+
+// symbols returns the Go symbols of the index by ref. A symbol declared once
+// per platform keeps its first declaration.
+func (s *Service) symbols(ctx context.Context) (map[string]indexedSymbol, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT s.kind, s.name, COALESCE(s.receiver, ''), COALESCE(s.signature, ''), s.line_start, f.path, p.path
+FROM symbols s
+JOIN files f ON f.id = s.file_id
+JOIN packages p ON p.id = f.package_id
+ORDER BY f.path, s.line_start, s.id;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query symbols: %w", err)
+	}
+	defer rows.Close()
+	symbols := map[string]indexedSymbol{}
+	for rows.Next() {
+		var sym indexedSymbol
+		if err := rows.Scan(&sym.Kind, &sym.Name, &sym.Receiver, &sym.signature, &sym.Line, &sym.FilePath, &sym.Package); err != nil {
+			return nil, fmt.Errorf("scan symbol: %w", err)
+		}
+		ref := sym.Package + "." + label(sym.Symbol)
+		if _, ok := symbols[ref]; !ok {
+			symbols[ref] = sym
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate symbols: %w", err)
+	}
+	return symbols, nil
+}
+
+func label(sym Symbol) string {
+	if sym.Receiver != "" {
+		return strings.TrimPrefix(sym.Receiver, "*") + "." + sym.Name
+	}
+	return sym.Name
+}
+
+// docComment returns the // comment lines directly above line (one-based),
+// without their comment markers and without //go: directives.
+func docComment(lines []string, line int) string {
+	var doc []string
+	for i := line - 2; i >= 0 && i < len(lines); i-- {
+		text := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(text, "//") {
+			break
+		}
+		if strings.HasPrefix(text, "//go:") {
+			continue
+		}
+		doc = append(doc, strings.TrimSpace(strings.TrimPrefix(text, "//")))
+	}
+	for i, j := 0, len(doc)-1; i < j; i, j = i+1, j-1 {
+		doc[i], doc[j] = doc[j], doc[i]
+	}
+	return strings.TrimSpace(strings.Join(doc, "\n"))
+}
+
+func (s *Service) storedHashes(ctx context.Context, provider string) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT entity_type, entity_ref, content_hash FROM embeddings WHERE provider = ?`, provider)
+	if err != nil {
+		return nil, fmt.Errorf("query embeddings: %w", err)
+	}
+	defer rows.Close()
+	hashes := map[string]string{}
+	for rows.Next() {
+		var entityType, ref, hash string
+		if err := rows.Scan(&entityType, &ref, &hash); err != nil {
+			return nil, fmt.Errorf("scan embedding: %w", err)
+		}
+		hashes[entityType+":"+ref] = hash
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate embeddings: %w", err)
+	}
+	return hashes, nil
+}
+
+func contentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(x))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
+
+// cosine returns the cosine similarity of a and b, or 0 when either is zero.
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
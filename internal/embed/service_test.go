@@ -0,0 +1,198 @@
+package embed
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/config"
+	"github.com/robertguss/recon/internal/testutil"
+)
+
+func setupEmbedModule(t *testing.T) (string, *sql.DB) {
+	t.Helper()
+	root, conn := testutil.Module(t, map[string]string{
+		"go.mod": "module example.com/m\n",
+		"store/store.go": "package store\n\n// Store keeps records on disk.\ntype Store struct{}\n\n" +
+			"// Open opens the store file, creating it if needed.\n//\n//go:noinline\nfunc Open() *Store { return &Store{} }\n\n" +
+			"// Close flushes pending writes.\nfunc (s *Store) Close() error { return nil }\n\nfunc undocumented() {}\n",
+	})
+	testutil.Seed(t, conn,
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+			(1,'Retry network calls','Transient failures are common','high','active','x','x'),
+			(2,'Old idea','r','low','superseded','x','x')`,
+		`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES
+			(1,'Wrap errors','Add context with %w','medium','active','x','x')`,
+	)
+	return root, conn
+}
+
+// countingProvider records how many texts it was asked to embed.
+type countingProvider struct {
+	localProvider
+	texts int
+}
+
+func (p *countingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	p.texts += len(texts)
+	return p.localProvider.Embed(ctx, texts)
+}
+
+func TestTerms(t *testing.T) {
+	got := terms("HTTPServer retries_count parseJSON Ünïcode42")
+	want := []string{"http", "server", "retries", "count", "parse", "json", "ünïcode42"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("terms() = %v, want %v", got, want)
+	}
+	a, b := hashVector("retry the request"), hashVector("RetryPolicy retries requests")
+	if score := cosine(a, b); score <= cosine(a, hashVector("wrap errors with context")) {
+		t.Fatalf("related texts scored %.2f, no higher than unrelated", score)
+	}
+}
+
+func TestEmbedAndSearch(t *testing.T) {
+	root, conn := setupEmbedModule(t)
+	ctx := context.Background()
+	svc := NewService(conn)
+	p := &countingProvider{}
+
+	res, err := svc.Embed(ctx, root, p, false)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	wantTypes := map[string]int{"decision": 1, "pattern": 1, TypeSymbol: 3}
+	if res.Provider != "local" || res.Embedded != 5 || !reflect.DeepEqual(res.ByType, wantTypes) || p.texts != 5 {
+		t.Fatalf("first embed = %+v (texts %d)", res, p.texts)
+	}
+
+	if _, err := conn.Exec(`UPDATE decisions SET title = 'Retry network calls twice' WHERE id = 1`); err != nil {
+		t.Fatalf("update decision: %v", err)
+	}
+	if _, err := conn.Exec(`UPDATE patterns SET status = 'archived' WHERE id = 1`); err != nil {
+		t.Fatalf("archive pattern: %v", err)
+	}
+	res, err = svc.Embed(ctx, root, p, false)
+	if err != nil {
+		t.Fatalf("Embed again: %v", err)
+	}
+	if res.Embedded != 1 || res.ByType["decision"] != 1 || res.Unchanged != 3 || res.Removed != 1 || p.texts != 6 {
+		t.Fatalf("incremental embed = %+v (texts %d)", res, p.texts)
+	}
+	if res, err = svc.Embed(ctx, root, p, true); err != nil || res.Embedded != 4 || res.Unchanged != 0 {
+		t.Fatalf("rebuild = %+v, %v", res, err)
+	}
+
+	matches, err := svc.Search(ctx, p, "open the store file", 2)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(matches) != 2 || matches[0].Ref != "store.Open" || matches[0].Symbol == nil || matches[0].Symbol.FilePath != "store/store.go" || matches[0].Symbol.Line != 9 {
+		t.Fatalf("matches = %+v", matches)
+	}
+	if matches[0].Score < matches[1].Score {
+		t.Fatalf("matches not ordered by score: %+v", matches)
+	}
+	matches, err = svc.Search(ctx, p, "retry network", 1)
+	if err != nil || len(matches) != 1 || matches[0].EntityType != "decision" || matches[0].Ref != "1" {
+		t.Fatalf("knowledge match = %+v, %v", matches, err)
+	}
+
+	if _, err := conn.Exec(`DELETE FROM symbols WHERE name = 'Open'`); err != nil {
+		t.Fatalf("delete symbol: %v", err)
+	}
+	matches, err = svc.Search(ctx, p, "open the store file", 5)
+	if err != nil {
+		t.Fatalf("Search after delete: %v", err)
+	}
+	for _, m := range matches {
+		if m.Ref == "store.Open" {
+			t.Fatalf("removed symbol still matched: %+v", matches)
+		}
+	}
+
+	if _, err := svc.Search(ctx, openAIProvider{model: "m"}, "anything", 5); !errors.Is(err, ErrNoEmbeddings) {
+		t.Fatalf("Search with another provider error = %v, want ErrNoEmbeddings", err)
+	}
+}
+
+func TestDocComment(t *testing.T) {
+	lines := strings.Split("package p\n\n// Open opens.\n//\n//go:noinline\nfunc Open() {}\n/* block */\nfunc Close() {}\n", "\n")
+	if got := docComment(lines, 6); got != "Open opens." {
+		t.Fatalf("docComment(Open) = %q", got)
+	}
+	if got := docComment(lines, 8); got != "" {
+		t.Fatalf("docComment(Close) = %q, want none", got)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider(config.Embeddings{}); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("unconfigured error = %v", err)
+	}
+	t.Setenv("RECON_TEST_KEY", "")
+	if _, err := NewProvider(config.Embeddings{Provider: "openai", APIKeyEnv: "RECON_TEST_KEY"}); err == nil || !strings.Contains(err.Error(), "$RECON_TEST_KEY") {
+		t.Fatalf("missing key error = %v", err)
+	}
+	p, err := NewProvider(config.Embeddings{Provider: "openai", URL: "http://localhost:11434/v1/", Model: "nomic-embed-text"})
+	if err != nil || p.Name() != "openai:nomic-embed-text" {
+		t.Fatalf("keyless local server provider = %v, %v", p, err)
+	}
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"bad key"}}`))
+			return
+		}
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "m" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// Answer in reverse order; the provider must sort by index.
+		type datum struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		var data []datum
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			data = append(data, datum{Index: i, Embedding: []float32{float32(len(req.Input[i])), 1}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer srv.Close()
+
+	t.Setenv("RECON_TEST_KEY", "secret")
+	p, err := NewProvider(config.Embeddings{Provider: "openai", URL: srv.URL + "/v1", Model: "m", APIKeyEnv: "RECON_TEST_KEY"})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	texts := make([]string, openAIBatch+1)
+	for i := range texts {
+		texts[i] = strings.Repeat("x", i)
+	}
+	vectors, err := p.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if len(vectors) != len(texts) || vectors[3][0] != 3 || vectors[openAIBatch][0] != openAIBatch {
+		t.Fatalf("vectors out of order: %v", vectors[:4])
+	}
+
+	t.Setenv("RECON_TEST_KEY", "wrong")
+	p, _ = NewProvider(config.Embeddings{Provider: "openai", URL: srv.URL + "/v1", Model: "m", APIKeyEnv: "RECON_TEST_KEY"})
+	if _, err := p.Embed(context.Background(), []string{"a"}); err == nil || !strings.Contains(err.Error(), "401 Unauthorized: bad key") {
+		t.Fatalf("Embed error = %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package explain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderText renders the explanation as labelled sections. An empty body is
+// left out, so callers can drop it for a shorter report.
+func RenderText(e Explanation) string {
+	var b strings.Builder
+	sym := e.Symbol
+
+	label := sym.Name
+	if sym.Receiver != "" {
+		label = sym.Receiver + "." + sym.Name
+	}
+	fmt.Fprintf(&b, "%s %s (%s:%d-%d, package %s)\n", sym.Kind, label, sym.FilePath, sym.LineStart, sym.LineEnd, sym.Package)
+	if sym.Signature != "" {
+		fmt.Fprintf(&b, "Signature: %s\n", sym.Signature)
+	}
+	if sym.Platform != "" {
+		fmt.Fprintf(&b, "Platform: %s\n", sym.Platform)
+	}
+	for _, v := range sym.Variants {
+		fmt.Fprintf(&b, "Variant: %s (%s:%d-%d)\n", v.Platform, v.FilePath, v.LineStart, v.LineEnd)
+	}
+	if sym.Body != "" {
+		fmt.Fprintf(&b, "\nBody:\n%s\n", sym.Body)
+	}
+
+	b.WriteString("\nDepends on:\n")
+	if len(e.Dependencies) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, dep := range e.Dependencies {
+		fmt.Fprintf(&b, "- %s %s (%s)\n", dep.Kind, dep.Name, dep.FilePath)
+	}
+
+	b.WriteString("\nUsed by:\n")
+	if len(e.Callers) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, c := range e.Callers {
+		name := c.Name
+		if c.Receiver != "" {
+			name = c.Receiver + "." + c.Name
+		}
+		fmt.Fprintf(&b, "- %s %s (%s:%d)\n", c.Kind, name, c.FilePath, c.LineStart)
+	}
+
+	b.WriteString("\nTested by:\n")
+	if len(e.Tests) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, t := range e.Tests {
+		fmt.Fprintf(&b, "- %s (%s:%d)\n", t.Name, t.FilePath, t.Line)
+	}
+	if e.Coverage != nil {
+		fmt.Fprintf(&b, "Coverage: %d/%d statements (%.1f%%)\n", e.Coverage.Covered, e.Coverage.Statements, e.Coverage.Percent)
+	}
+
+	b.WriteString("\nKnowledge:\n")
+	if len(e.Knowledge) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, k := range e.Knowledge {
+		fmt.Fprintf(&b, "- %s #%d %s [%s] %s via %s drift=%s\n", k.EntityType, k.ID, k.Title, k.Confidence, k.Relation, k.Via, k.Drift)
+		if k.Reasoning != "" {
+			fmt.Fprintf(&b, "  Why: %s\n", k.Reasoning)
+		}
+	}
+
+	if len(e.Assets) > 0 {
+		b.WriteString("\nEmbedded assets:\n")
+		for _, a := range e.Assets {
+			if len(a.Files) == 0 {
+				fmt.Fprintf(&b, "- %s embeds %s (%s:%d): missing\n", a.Var, a.Pattern, a.FilePath, a.Line)
+				continue
+			}
+			fmt.Fprintf(&b, "- %s embeds %s (%s:%d): %d files\n", a.Var, a.Pattern, a.FilePath, a.Line, len(a.Files))
+		}
+	}
+
+	if len(e.Gaps) > 0 {
+		fmt.Fprintf(&b, "\nGaps: %s\n", strings.Join(e.Gaps, ", "))
+	}
+	return b.String()
+}
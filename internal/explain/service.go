@@ -0,0 +1,259 @@
+package explain
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/robertguss/recon/internal/coverage"
+	"github.com/robertguss/recon/internal/find"
+)
+
+// Gaps flag what an explanation cannot lean on, so an agent can say so
+// instead of guessing.
+const (
+	GapNoTests          = "no_tests"
+	GapNoCallers        = "no_callers"
+	GapNoKnowledge      = "no_knowledge"
+	GapDriftedKnowledge = "drifted_knowledge"
+	GapLowCoverage      = "low_coverage"
+	GapMissingAssets    = "missing_assets"
+)
+
+// Explanation is what the index knows about one symbol, in the order an
+// explanation walks through it: what it is, what it uses, who uses it, what
+// tests it, and the decisions and patterns recorded about it. It is
+// retrieved, not generated; turning it into prose is left to the caller.
+type Explanation struct {
+	Symbol       find.Symbol        `json:"symbol"`
+	Dependencies []find.Symbol      `json:"dependencies"`
+	Callers      []find.Caller      `json:"callers"`
+	Tests        []Test             `json:"tests"`
+	Knowledge    []Knowledge        `json:"knowledge"`
+	Coverage     *coverage.Coverage `json:"coverage,omitempty"`
+	Assets       []find.Asset       `json:"assets,omitempty"`
+	Gaps         []string           `json:"gaps"`
+}
+
+// Test is a test, benchmark, fuzz target or example in the symbol's package
+// directory whose body refers to the symbol by name.
+type Test struct {
+	Name     string `json:"name"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+}
+
+// Knowledge is an active decision or pattern linked to the symbol, its file,
+// or its package. Via is the most specific link, as "type:ref".
+type Knowledge struct {
+	EntityType string `json:"entity_type"`
+	ID         int64  `json:"id"`
+	Title      string `json:"title"`
+	Reasoning  string `json:"reasoning,omitempty"`
+	Confidence string `json:"confidence"`
+	Relation   string `json:"relation"`
+	Via        string `json:"via"`
+	Drift      string `json:"drift_status"`
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// Explain resolves symbol the way find does and gathers its explanation.
+// Lookup failures are find.NotFoundError or find.AmbiguousError.
+func (s *Service) Explain(ctx context.Context, moduleRoot, symbol string, opts find.QueryOptions) (Explanation, error) {
+	findSvc := find.NewService(s.db)
+	result, err := findSvc.Find(ctx, symbol, opts)
+	if err != nil {
+		return Explanation{}, err
+	}
+	callers, err := findSvc.Callers(ctx, result.Symbol, 1)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	e := Explanation{
+		Symbol:       result.Symbol,
+		Dependencies: result.Dependencies,
+		Callers:      callers,
+		Gaps:         []string{},
+	}
+	if e.Tests, err = findTests(moduleRoot, result.Symbol); err != nil {
+		return Explanation{}, err
+	}
+	if e.Knowledge, err = s.knowledge(ctx, result.Symbol); err != nil {
+		return Explanation{}, err
+	}
+	if e.Assets, err = findSvc.Assets(ctx, result.Symbol.Package); err != nil {
+		return Explanation{}, err
+	}
+	if c, ok, err := coverage.NewService(s.db).Symbol(ctx, result.Symbol.FilePath, result.Symbol.Name, result.Symbol.Receiver); err == nil && ok {
+		e.Coverage = &c
+	}
+
+	if len(e.Tests) == 0 {
+		e.Gaps = append(e.Gaps, GapNoTests)
+	}
+	if len(e.Callers) == 0 {
+		e.Gaps = append(e.Gaps, GapNoCallers)
+	}
+	if len(e.Knowledge) == 0 {
+		e.Gaps = append(e.Gaps, GapNoKnowledge)
+	}
+	for _, k := range e.Knowledge {
+		if k.Drift != "ok" {
+			e.Gaps = append(e.Gaps, GapDriftedKnowledge)
+			break
+		}
+	}
+	if e.Coverage != nil && e.Coverage.Low() {
+		e.Gaps = append(e.Gaps, GapLowCoverage)
+	}
+	for _, a := range e.Assets {
+		if len(a.Files) == 0 {
+			e.Gaps = append(e.Gaps, GapMissingAssets)
+			break
+		}
+	}
+	return e, nil
+}
+
+var linkRank = map[string]int{"symbol": 0, "file": 1, "package": 2}
+
+// knowledge returns the active decisions and patterns linked to sym, each
+// once by its most specific link, decisions first.
+func (s *Service) knowledge(ctx context.Context, sym find.Symbol) ([]Knowledge, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT e.from_type, e.from_id, COALESCE(d.title, p.title), COALESCE(d.reasoning, p.description, ''),
+       COALESCE(d.confidence, p.confidence), e.relation, e.to_type, e.to_ref,
+       COALESCE((SELECT ev.drift_status FROM entity_evidence ev
+                 WHERE ev.entity_type = e.from_type AND ev.entity_id = e.from_id
+                 ORDER BY ev.id DESC LIMIT 1), 'ok')
+FROM edges e
+LEFT JOIN decisions d ON e.from_type = 'decision' AND d.id = e.from_id
+LEFT JOIN patterns p ON e.from_type = 'pattern' AND p.id = e.from_id
+WHERE COALESCE(d.status, p.status) = 'active'
+  AND (
+      (e.to_type = 'symbol' AND e.to_ref = ?1)
+   OR (e.to_type = 'file' AND e.to_ref = ?2)
+   OR (e.to_type = 'package' AND e.to_ref = ?3)
+  )
+ORDER BY e.id;
+`, sym.Package+"."+sym.Name, sym.FilePath, sym.Package)
+	if err != nil {
+		return nil, fmt.Errorf("query symbol knowledge: %w", err)
+	}
+	defer rows.Close()
+
+	best := map[string]Knowledge{}
+	var order []string
+	for rows.Next() {
+		var (
+			k             Knowledge
+			toType, toRef string
+		)
+		if err := rows.Scan(&k.EntityType, &k.ID, &k.Title, &k.Reasoning, &k.Confidence, &k.Relation, &toType, &toRef, &k.Drift); err != nil {
+			return nil, fmt.Errorf("scan symbol knowledge: %w", err)
+		}
+		k.Via = toType + ":" + toRef
+		key := fmt.Sprintf("%s:%d", k.EntityType, k.ID)
+		prev, seen := best[key]
+		if !seen {
+			order = append(order, key)
+		}
+		if !seen || linkRank[toType] < linkRank[strings.SplitN(prev.Via, ":", 2)[0]] {
+			best[key] = k
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate symbol knowledge: %w", err)
+	}
+
+	knowledge := make([]Knowledge, 0, len(order))
+	for _, key := range order {
+		knowledge = append(knowledge, best[key])
+	}
+	sort.SliceStable(knowledge, func(i, j int) bool {
+		a, b := knowledge[i], knowledge[j]
+		if a.EntityType != b.EntityType {
+			return a.EntityType == "decision"
+		}
+		return a.ID < b.ID
+	})
+	return knowledge, nil
+}
+
+// findTests parses the _test.go files in the symbol's package directory,
+// which the index skips, for test functions that mention the symbol's name.
+// Files that do not parse are skipped.
+func findTests(moduleRoot string, sym find.Symbol) ([]Test, error) {
+	dir := filepath.Join(moduleRoot, filepath.FromSlash(sym.Package))
+	matches, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return nil, fmt.Errorf("list test files: %w", err)
+	}
+	sort.Strings(matches)
+
+	tests := []Test{}
+	fset := token.NewFileSet()
+	for _, path := range matches {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(moduleRoot, path)
+		if err != nil {
+			rel = path
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Body == nil || !isTestFunc(fn.Name.Name) {
+				continue
+			}
+			if mentions(fn.Body, sym.Name) {
+				tests = append(tests, Test{
+					Name:     fn.Name.Name,
+					FilePath: filepath.ToSlash(rel),
+					Line:     fset.Position(fn.Pos()).Line,
+				})
+			}
+		}
+	}
+	return tests, nil
+}
+
+func isTestFunc(name string) bool {
+	for _, prefix := range []string{"Test", "Benchmark", "Fuzz", "Example"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func mentions(body *ast.BlockStmt, name string) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,144 @@
+package explain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/find"
+	"github.com/robertguss/recon/internal/testutil"
+)
+
+func setupExplainModule(t *testing.T) (string, *sql.DB) {
+	t.Helper()
+	return testutil.Module(t, map[string]string{
+		"go.mod":               "module example.com/m\n",
+		"main.go":              "package main\nimport \"example.com/m/store\"\nfunc main() { store.Open() }\n",
+		"store/store.go":       "package store\ntype Store struct{}\nfunc Open() *Store { return newStore() }\nfunc newStore() *Store { return &Store{} }\nfunc Unused() {}\n",
+		"store/store_test.go":  "package store\nimport \"testing\"\nfunc TestOpen(t *testing.T) { _ = Open() }\nfunc TestOther(t *testing.T) {}\nfunc helper() { Open() }\n",
+		"store/ext_test.go":    "package store_test\nimport (\"testing\"; \"example.com/m/store\")\nfunc BenchmarkOpen(b *testing.B) { store.Open() }\n",
+		"store/broken_test.go": "package store\nfunc TestBroken( {\n",
+	})
+}
+
+func TestExplain(t *testing.T) {
+	root, conn := setupExplainModule(t)
+	testutil.Seed(t, conn,
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+			(1,'Open is the constructor','Callers never build Store directly','high','active','x','x'),
+			(2,'Stores are package-scoped','r','medium','active','x','x'),
+			(3,'Old idea','r','low','superseded','x','x')`,
+		`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES
+			(1,'Constructors return pointers','d','medium','active','x','x')`,
+		`INSERT INTO evidence(entity_type,entity_id,summary,drift_status) VALUES ('decision',2,'s','drifting')`,
+		`INSERT INTO edges(from_type,from_id,to_type,to_ref,relation,source,confidence,created_at) VALUES
+			('decision',1,'package','store','affects','manual','high','x'),
+			('decision',1,'symbol','store.Open','affects','manual','high','x'),
+			('decision',2,'file','store/store.go','affects','manual','high','x'),
+			('decision',3,'symbol','store.Open','affects','manual','high','x'),
+			('pattern',1,'symbol','store.Open','affects','manual','high','x')`,
+	)
+
+	e, err := NewService(conn).Explain(context.Background(), root, "Open", find.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Explain: %v", err)
+	}
+	if e.Symbol.Name != "Open" || e.Symbol.Package != "store" || !strings.Contains(e.Symbol.Body, "newStore()") {
+		t.Fatalf("symbol = %+v", e.Symbol)
+	}
+	if len(e.Dependencies) != 1 || len(e.Callers) != 1 || e.Callers[0].Name != "main" {
+		t.Fatalf("deps = %+v, callers = %+v", e.Dependencies, e.Callers)
+	}
+	var tests []string
+	for _, tc := range e.Tests {
+		tests = append(tests, tc.Name+"@"+tc.FilePath)
+	}
+	if strings.Join(tests, ",") != "BenchmarkOpen@store/ext_test.go,TestOpen@store/store_test.go" {
+		t.Fatalf("tests = %v", tests)
+	}
+	if len(e.Knowledge) != 3 {
+		t.Fatalf("knowledge = %+v", e.Knowledge)
+	}
+	if k := e.Knowledge[0]; k.ID != 1 || k.Via != "symbol:store.Open" || k.Reasoning != "Callers never build Store directly" {
+		t.Fatalf("knowledge[0] = %+v", k)
+	}
+	if k := e.Knowledge[1]; k.ID != 2 || k.Via != "file:store/store.go" || k.Drift != "drifting" {
+		t.Fatalf("knowledge[1] = %+v", k)
+	}
+	if k := e.Knowledge[2]; k.EntityType != "pattern" || k.Reasoning != "d" {
+		t.Fatalf("knowledge[2] = %+v", k)
+	}
+	if !slices.Equal(e.Gaps, []string{GapDriftedKnowledge}) {
+		t.Fatalf("gaps = %v", e.Gaps)
+	}
+
+	text := RenderText(e)
+	for _, want := range []string{
+		"func Open (store/store.go:3-3, package store)\n",
+		"Used by:\n- func main (main.go:3)\n",
+		"- TestOpen (store/store_test.go:3)\n",
+		"- decision #1 Open is the constructor [high] affects via symbol:store.Open drift=ok\n  Why: Callers never build Store directly\n",
+		"Gaps: drifted_knowledge\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("text missing %q:\n%s", want, text)
+		}
+	}
+
+	unused, err := NewService(conn).Explain(context.Background(), root, "Unused", find.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Explain Unused: %v", err)
+	}
+	if !slices.Equal(unused.Gaps, []string{GapNoTests, GapNoCallers, GapDriftedKnowledge}) {
+		t.Fatalf("Unused gaps = %v", unused.Gaps)
+	}
+}
+
+func TestExplainAssets(t *testing.T) {
+	root, conn := setupExplainModule(t)
+	if _, err := conn.Exec(`INSERT INTO file_embeds(file_id,var_name,pattern,line,paths)
+		SELECT id, 'schema', 'schema.sql', 6, '["store/schema.sql"]' FROM files WHERE path = 'store/store.go'
+		UNION ALL
+		SELECT id, 'seeds', 'seeds/*', 8, '[]' FROM files WHERE path = 'store/store.go'`); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	e, err := NewService(conn).Explain(context.Background(), root, "Open", find.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Explain: %v", err)
+	}
+	if len(e.Assets) != 2 || e.Assets[0].Var != "schema" || len(e.Assets[1].Files) != 0 {
+		t.Fatalf("assets = %+v", e.Assets)
+	}
+	if !slices.Contains(e.Gaps, GapMissingAssets) {
+		t.Fatalf("gaps = %v, want %s", e.Gaps, GapMissingAssets)
+	}
+	text := RenderText(e)
+	for _, want := range []string{
+		"Embedded assets:\n- schema embeds schema.sql (store/store.go:6): 1 files\n",
+		"- seeds embeds seeds/* (store/store.go:8): missing\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("text missing %q:\n%s", want, text)
+		}
+	}
+
+	main, err := NewService(conn).Explain(context.Background(), root, "main", find.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Explain main: %v", err)
+	}
+	if len(main.Assets) != 0 || strings.Contains(RenderText(main), "Embedded assets") {
+		t.Fatalf("main assets = %+v", main.Assets)
+	}
+}
+
+func TestExplainLookupErrors(t *testing.T) {
+	root, conn := setupExplainModule(t)
+	var notFound find.NotFoundError
+	if _, err := NewService(conn).Explain(context.Background(), root, "Missing", find.QueryOptions{}); !errors.As(err, &notFound) {
+		t.Fatalf("Explain Missing = %v, want NotFoundError", err)
+	}
+}
@@ -0,0 +1,65 @@
+package find
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// CompleteSymbols returns the symbol names starting with prefix, sorted, for
+// shell completion. A prefix with a dot completes the methods of the receiver
+// before it, as "Receiver.Name". At most limit names are returned.
+func (s *Service) CompleteSymbols(ctx context.Context, prefix string, limit int) ([]string, error) {
+	query := `SELECT DISTINCT s.name FROM symbols s WHERE substr(s.name, 1, ?) = ? ORDER BY s.name LIMIT ?`
+	receiver, name, method := strings.Cut(prefix, ".")
+	if !method {
+		name = prefix
+	}
+	args := []any{utf8.RuneCountInString(name), name}
+	if method {
+		clause, receiverArgs := receiverClause(receiver)
+		query = `SELECT DISTINCT s.name FROM symbols s WHERE substr(s.name, 1, ?) = ? AND s.kind = 'method' AND ` +
+			clause + ` ORDER BY s.name LIMIT ?`
+		args = append(args, receiverArgs...)
+	}
+	names, err := s.completions(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("complete symbols: %w", err)
+	}
+	if method {
+		for i, n := range names {
+			names[i] = receiver + "." + n
+		}
+	}
+	return names, nil
+}
+
+// CompletePackages returns the indexed package paths starting with prefix,
+// sorted, for shell completion. At most limit paths are returned.
+func (s *Service) CompletePackages(ctx context.Context, prefix string, limit int) ([]string, error) {
+	paths, err := s.completions(ctx, `SELECT path FROM packages WHERE substr(path, 1, ?) = ? ORDER BY path LIMIT ?`,
+		utf8.RuneCountInString(prefix), prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("complete packages: %w", err)
+	}
+	return paths, nil
+}
+
+func (s *Service) completions(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
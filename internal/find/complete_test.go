@@ -0,0 +1,42 @@
+package find
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestCompleteSymbols(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+	svc := NewService(conn)
+
+	for prefix, want := range map[string][]string{
+		"":        {"Ambig", "Dep", "Target"},
+		"Am":      {"Ambig"},
+		"am":      nil,
+		"T.":      {"T.Ambig"},
+		"*T.Am":   {"*T.Ambig"},
+		"Other.A": nil,
+	} {
+		got, err := svc.CompleteSymbols(context.Background(), prefix, 10)
+		if err != nil {
+			t.Fatalf("CompleteSymbols(%q): %v", prefix, err)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("CompleteSymbols(%q) = %v, want %v", prefix, got, want)
+		}
+	}
+	if got, _ := svc.CompleteSymbols(context.Background(), "", 1); len(got) != 1 {
+		t.Fatalf("expected the limit to apply, got %v", got)
+	}
+
+	got, err := svc.CompletePackages(context.Background(), ".", 10)
+	if err != nil || !slices.Equal(got, []string{"."}) {
+		t.Fatalf("CompletePackages = %v, %v", got, err)
+	}
+	conn.Close()
+	if _, err := svc.CompletePackages(context.Background(), "", 10); err == nil {
+		t.Fatal("expected error on closed DB")
+	}
+}
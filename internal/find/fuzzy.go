@@ -0,0 +1,109 @@
+package find
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+)
+
+// minFuzzyQuery is the shortest folded query, in runes, that is fuzzy
+// matched; shorter ones are within a single edit of nearly every name.
+const minFuzzyQuery = 3
+
+// fuzzyMatch is a symbol name within fuzzyThreshold edits of a query.
+type fuzzyMatch struct {
+	name     string
+	distance int
+	lenDiff  int
+}
+
+// fuzzyThreshold is the most edits a name may be from a query of n runes
+// and still match: one per four runes, and at least one.
+func fuzzyThreshold(n int) int {
+	return max(1, n/4)
+}
+
+// rankFuzzy returns the names whose case-folded form is within
+// fuzzyThreshold edits of a substring of them from the folded needle,
+// closest first: by edit distance, then by how near the name is to the
+// needle's length, then by name.
+func rankFuzzy(needle string, names []string) []fuzzyMatch {
+	query := []rune(needle)
+	if len(query) < minFuzzyQuery {
+		return nil
+	}
+	threshold := fuzzyThreshold(len(query))
+	var out []fuzzyMatch
+	for _, name := range names {
+		folded := []rune(foldIdentifier(name))
+		if d := substringDistance(query, folded); d <= threshold {
+			out = append(out, fuzzyMatch{name: name, distance: d, lenDiff: abs(len(folded) - len(query))})
+		}
+	}
+	slices.SortFunc(out, func(a, b fuzzyMatch) int {
+		return cmp.Or(cmp.Compare(a.distance, b.distance), cmp.Compare(a.lenDiff, b.lenDiff), strings.Compare(a.name, b.name))
+	})
+	return out
+}
+
+// substringDistance returns the fewest single-rune insertions, deletions,
+// and substitutions that turn needle into some substring of haystack
+// (Sellers' algorithm: a match may start and end anywhere in haystack).
+func substringDistance(needle, haystack []rune) int {
+	prev := make([]int, len(haystack)+1)
+	cur := make([]int, len(haystack)+1)
+	for i := 1; i <= len(needle); i++ {
+		cur[0] = i
+		for j := 1; j <= len(haystack); j++ {
+			cost := 1
+			if needle[i-1] == haystack[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j-1]+cost, prev[j]+1, cur[j-1]+1)
+		}
+		prev, cur = cur, prev
+	}
+	return slices.Min(prev)
+}
+
+// fuzzyResolve picks the name symbol most likely meant: the only name equal
+// to it ignoring case, or else the only name at the smallest fuzzy distance.
+// It returns "" when there is no such name or several tie.
+func (s *Service) fuzzyResolve(ctx context.Context, symbol string) (string, error) {
+	needle := foldIdentifier(strings.TrimSpace(symbol))
+	if needle == "" {
+		return "", nil
+	}
+	names, err := s.candidateNames(ctx, needle)
+	if err != nil {
+		return "", err
+	}
+
+	var folded []string
+	for _, name := range names {
+		if foldIdentifier(name) == needle {
+			folded = append(folded, name)
+		}
+	}
+	switch len(folded) {
+	case 1:
+		return folded[0], nil
+	case 0:
+	default:
+		return "", nil
+	}
+
+	ranked := rankFuzzy(needle, names)
+	if len(ranked) == 0 || (len(ranked) > 1 && ranked[1].distance == ranked[0].distance) {
+		return "", nil
+	}
+	return ranked[0].name, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
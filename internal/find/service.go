@@ -3,9 +3,22 @@ package find
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/robertguss/recon/internal/coverage"
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/history"
+	"golang.org/x/text/unicode/norm"
 )
 
 type Symbol struct {
@@ -19,6 +32,58 @@ type Symbol struct {
 	Receiver  string `json:"receiver,omitempty"`
 	FilePath  string `json:"file_path"`
 	Package   string `json:"package"`
+	// Language is the language of the declaring file when it is not Go,
+	// such as "sql" for a table touched by a migration or "sh" for a shell
+	// function. Such files belong to no package; Package is their directory.
+	Language string `json:"language,omitempty"`
+	// Members lists the constants of an enum, or of the enum declared for a
+	// type, in declaration order.
+	Members []EnumMember `json:"members,omitempty"`
+	// Platform is the build constraint of the declaring file, such as
+	// "linux" for foo_linux.go, or empty when it builds everywhere.
+	Platform string `json:"platform,omitempty"`
+	// Cgo and Unsafe are set when the declaring file imports "C" or
+	// "unsafe", code to change with extra care.
+	Cgo    bool `json:"cgo,omitempty"`
+	Unsafe bool `json:"unsafe,omitempty"`
+	// Generated is set when the declaring file is generated code or is
+	// vendored under vendor/.
+	Generated bool `json:"generated,omitempty"`
+	// Variants lists every platform-specific declaration of the symbol, this
+	// one included, when it is declared once per build constraint.
+	Variants []Variant `json:"variants,omitempty"`
+	// TypeParams lists the type parameters of a generic func or type. A
+	// method of a generic type carries its receiver's parameters, named as
+	// the receiver names them.
+	TypeParams []TypeParam `json:"type_params,omitempty"`
+
+	// bodyHash keys the declaration source in symbol_bodies; Body is filled
+	// from it by loadBodies.
+	bodyHash string
+}
+
+// Variant is one declaration of a symbol that is declared separately per
+// platform, e.g. in open_unix.go and open_windows.go.
+type Variant struct {
+	ID        int64  `json:"id"`
+	Platform  string `json:"platform"`
+	FilePath  string `json:"file_path"`
+	LineStart int    `json:"line_start"`
+	LineEnd   int    `json:"line_end"`
+	Signature string `json:"signature"`
+}
+
+// TypeParam is a type parameter and its constraint as written.
+type TypeParam struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
+}
+
+// EnumMember is a constant of an enum. Value is the evaluated constant, or
+// the expression as written when the indexer could not fold it.
+type EnumMember struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 type KnowledgeLink struct {
@@ -30,15 +95,137 @@ type KnowledgeLink struct {
 }
 
 type Result struct {
-	Symbol       Symbol          `json:"symbol"`
-	Dependencies []Symbol        `json:"dependencies"`
-	Knowledge    []KnowledgeLink `json:"knowledge,omitempty"`
+	// FuzzyQuery is the query as given when QueryOptions.Fuzzy resolved it
+	// to Symbol's differently spelled name.
+	FuzzyQuery      string           `json:"fuzzy_query,omitempty"`
+	Symbol          Symbol           `json:"symbol"`
+	Dependencies    []Symbol         `json:"dependencies"`
+	Embeds          []Embed          `json:"embeds,omitempty"`
+	PromotedMethods []PromotedMethod `json:"promoted_methods,omitempty"`
+	Knowledge       []KnowledgeLink  `json:"knowledge,omitempty"`
+	Callers         []Caller         `json:"callers,omitempty"`
+	Usages          []Usage          `json:"usages,omitempty"`
+	Assets          []Asset          `json:"assets,omitempty"`
+	Owners          []string         `json:"owners,omitempty"`
+	// Deprecated is the "Deprecated:" paragraph of the symbol's doc comment.
+	Deprecated string        `json:"deprecated,omitempty"`
+	CodeLinks  []CodeLink    `json:"code_links,omitempty"`
+	Fields     []Field       `json:"fields,omitempty"`
+	Methods    []Method      `json:"methods,omitempty"`
+	Coverage   *CoverageInfo `json:"coverage,omitempty"`
+	// LastTouch is the newest commit that changed the symbol's lines, set
+	// by `find --history`.
+	LastTouch *history.Commit `json:"last_touch,omitempty"`
+}
+
+// CodeLink is a link sync derived between a symbol and another symbol, such
+// as a proto message and the Go type generated for it. Symbol is the other
+// symbol's package-qualified ref; FilePath and Line locate it when it is
+// indexed.
+type CodeLink struct {
+	Relation string `json:"relation"`
+	Symbol   string `json:"symbol"`
+	FilePath string `json:"file_path,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// CoverageInfo is the imported test coverage of a symbol and its package.
+// Symbol is nil for symbols without statements, such as types. LowHot is set
+// when the package is hot in recent git history and below
+// coverage.LowThreshold.
+type CoverageInfo struct {
+	Symbol      *coverage.Coverage `json:"symbol,omitempty"`
+	Package     coverage.Coverage  `json:"package"`
+	PackageHeat string             `json:"package_heat,omitempty"`
+	LowHot      bool               `json:"low_coverage_hot_package"`
+}
+
+// Field is a struct field. Embedded fields are named after their type.
+type Field struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Tag      string `json:"tag,omitempty"`
+	Embedded bool   `json:"embedded,omitempty"`
+}
+
+// Method is a method in a type's method set: a declared method of a concrete
+// type, with its location, or a method spec of an interface.
+type Method struct {
+	Name            string `json:"name"`
+	Signature       string `json:"signature"`
+	PointerReceiver bool   `json:"pointer_receiver,omitempty"`
+	FilePath        string `json:"file_path,omitempty"`
+	LineStart       int    `json:"line_start,omitempty"`
+}
+
+// Caller is a symbol that depends on the queried symbol. Depth is 1 for
+// direct callers; Calls names the symbol one level closer to the query that
+// this caller depends on.
+type Caller struct {
+	Symbol
+	Depth int    `json:"depth"`
+	Calls string `json:"calls"`
+}
+
+// Usage is one call site of a symbol: the calling symbol, the line of the
+// call, and that line of source. Line is 0 when the index predates call-site
+// lines; `recon sync` records them.
+type Usage struct {
+	Caller     string `json:"caller"`
+	CallerKind string `json:"caller_kind"`
+	FilePath   string `json:"file_path"`
+	Package    string `json:"package"`
+	Line       int    `json:"line"`
+	Code       string `json:"code"`
+}
+
+// Asset is one pattern of a //go:embed directive in a symbol's package.
+// Files lists the module-relative files it matched at the last sync; it is
+// empty when the embedded path is missing.
+type Asset struct {
+	Var      string   `json:"var"`
+	Pattern  string   `json:"pattern"`
+	FilePath string   `json:"file_path"`
+	Line     int      `json:"line"`
+	Files    []string `json:"files"`
+}
+
+// Embed is a type embedded in a struct or interface.
+type Embed struct {
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	Pointer bool   `json:"pointer,omitempty"`
+}
+
+// PromotedMethod is a method reachable through embedding. Via is the chain
+// of embedded type names the method is promoted through, e.g. "Base" or
+// "Outer.Base".
+type PromotedMethod struct {
+	Symbol
+	Via string `json:"via"`
 }
 
 type QueryOptions struct {
-	PackagePath string `json:"package,omitempty"`
-	FilePath    string `json:"file,omitempty"`
-	Kind        string `json:"kind,omitempty"`
+	PackagePath  string   `json:"package,omitempty"`
+	FilePath     string   `json:"file,omitempty"`
+	Kind         string   `json:"kind,omitempty"`
+	Paths        []string `json:"paths,omitempty"`
+	ExcludePaths []string `json:"exclude_paths,omitempty"`
+	// Tags keeps only symbols from files built with exactly these build
+	// tags; files without a build constraint always match.
+	Tags []string `json:"tags,omitempty"`
+	// Unsafe keeps only symbols from files that import "C" or "unsafe".
+	Unsafe bool `json:"unsafe,omitempty"`
+	// IncludeGenerated lists symbols of generated and vendored files too.
+	// Lookups always resolve to them when nothing else matches, but prefer
+	// a hand-written declaration of the same name unless this is set.
+	IncludeGenerated bool `json:"include_generated,omitempty"`
+	// NoBody leaves Body empty on the symbol and its dependencies without
+	// reading symbol_bodies.
+	NoBody bool `json:"-"`
+	// Fuzzy resolves a symbol with no exact match to the one name that
+	// matches ignoring case, or else to the single closest fuzzy match.
+	Fuzzy bool `json:"-"`
 }
 
 type Candidate struct {
@@ -99,7 +286,7 @@ type PackageSummary struct {
 
 func (s *Service) ListPackages(ctx context.Context) ([]PackageSummary, error) {
 	rows, err := s.db.QueryContext(ctx,
-		`SELECT path, name, file_count, line_count FROM packages ORDER BY line_count DESC`)
+		`SELECT path, name, file_count, line_count FROM packages ORDER BY line_count DESC, path`)
 	if err != nil {
 		return nil, fmt.Errorf("query packages: %w", err)
 	}
@@ -128,28 +315,217 @@ func (s *Service) List(ctx context.Context, opts QueryOptions, limit int) (ListR
 		limit = 50
 	}
 
-	where, args := buildListWhere(opts)
+	where, args, err := s.listWhere(ctx, opts)
+	if err != nil {
+		return ListResult{}, err
+	}
+	return s.listSymbols(ctx, where, args, limit)
+}
+
+// streamPageSize is how many symbols Stream reads per query.
+var streamPageSize = 500
+
+// Stream calls fn with each symbol List would return, in the same order,
+// reading them a page at a time so that huge lists are never held in memory.
+// A limit <= 0 streams every matching symbol. An error from fn stops the
+// stream and is returned as is.
+func (s *Service) Stream(ctx context.Context, opts QueryOptions, limit int, fn func(Symbol) error) error {
+	opts = normalizeQueryOptions(opts)
+	if !hasActiveFilters(opts) {
+		return fmt.Errorf("list mode requires at least one filter (--package, --file, or --kind)")
+	}
+
+	where, args, err := s.listWhere(ctx, opts)
+	if err != nil {
+		return err
+	}
+	for offset := 0; limit <= 0 || offset < limit; offset += streamPageSize {
+		size := streamPageSize
+		if limit > 0 {
+			size = min(size, limit-offset)
+		}
+		page, err := s.listPage(ctx, where, args, size, offset)
+		if err != nil {
+			return err
+		}
+		for _, sym := range page {
+			if err := fn(sym); err != nil {
+				return err
+			}
+		}
+		if len(page) < size {
+			return nil
+		}
+	}
+	return nil
+}
+
+// SymbolQuery is a parsed "Receiver.Name" query. Either side may be a glob
+// pattern: "*" matches any run of characters and "?" any one character.
+type SymbolQuery struct {
+	Receiver string
+	Name     string
+}
+
+// ParseSymbolQuery splits a query on its first dot into receiver and name.
+// Queries without a dot have an empty Receiver.
+func ParseSymbolQuery(query string) SymbolQuery {
+	query = strings.TrimSpace(query)
+	if idx := strings.IndexByte(query, '.'); idx > 0 && idx < len(query)-1 {
+		return SymbolQuery{Receiver: query[:idx], Name: query[idx+1:]}
+	}
+	return SymbolQuery{Name: query}
+}
+
+// HasWildcard reports whether either side of the query is a glob pattern.
+// A leading "*" on a receiver names a pointer receiver, not a wildcard.
+func (q SymbolQuery) HasWildcard() bool {
+	return isGlob(q.Name) || q.Receiver == "*" || isGlob(strings.TrimPrefix(q.Receiver, "*"))
+}
+
+func isGlob(s string) bool {
+	return strings.ContainsAny(s, "*?")
+}
+
+// ListMatches lists every symbol matching query instead of resolving a single
+// one. "*.Close" lists Close methods across all receivers, "Service.*" lists
+// the method set of Service (value and pointer receivers), "New*Service"
+// lists the names matching that glob, and a plain name lists every symbol
+// with that name.
+func (s *Service) ListMatches(ctx context.Context, query string, opts QueryOptions, limit int) (ListResult, error) {
+	q := ParseSymbolQuery(query)
+	if q.Name == "" || (q.Receiver == "" && q.Name == "*") {
+		return ListResult{}, fmt.Errorf("wildcard queries need a name or receiver, e.g. New*, *.Close, or Service.*")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	where, args, err := s.listWhere(ctx, normalizeQueryOptions(opts))
+	if err != nil {
+		return ListResult{}, err
+	}
+	clauses := []string{where}
+	switch {
+	case q.Name == "*":
+	case isGlob(q.Name):
+		clauses = append(clauses, "s.name GLOB ?")
+		args = append(args, q.Name)
+	default:
+		clauses = append(clauses, "s.name = ?")
+		args = append(args, q.Name)
+	}
+	switch {
+	case q.Receiver == "":
+	case q.Receiver == "*":
+		clauses = append(clauses, "s.receiver != ''")
+	case isGlob(strings.TrimPrefix(q.Receiver, "*")):
+		clause, receiverArgs := receiverGlobClause(q.Receiver)
+		clauses = append(clauses, clause)
+		args = append(args, receiverArgs...)
+	default:
+		clause, receiverArgs := receiverClause(q.Receiver)
+		clauses = append(clauses, clause)
+		args = append(args, receiverArgs...)
+	}
+
+	return s.listSymbols(ctx, strings.Join(clauses, " AND "), args, limit)
+}
+
+// receiverClause matches methods declared on typeName with either a value or
+// pointer receiver, including generic receivers such as Box[T].
+func receiverClause(typeName string) (string, []any) {
+	base := strings.TrimPrefix(typeName, "*")
+	return `(s.receiver IN (?, ?) OR s.receiver LIKE ? ESCAPE '\' OR s.receiver LIKE ? ESCAPE '\')`,
+		[]any{base, "*" + base, db.EscapeLike(base) + "[%", db.EscapeLike("*"+base) + "[%"}
+}
+
+// receiverGlobClause is receiverClause for a glob pattern of the receiver's
+// type name.
+func receiverGlobClause(pattern string) (string, []any) {
+	base := strings.TrimPrefix(pattern, "*")
+	return `(s.receiver GLOB ? OR s.receiver GLOB ? OR s.receiver GLOB ? OR s.receiver GLOB ?)`,
+		[]any{base, "[*]" + base, base + "[[]*", "[*]" + base + "[[]*"}
+}
+
+// ListRegex lists every symbol whose name matches re, with the filters of
+// opts applied.
+func (s *Service) ListRegex(ctx context.Context, re *regexp.Regexp, opts QueryOptions, limit int) (ListResult, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	names, err := s.symbolNames(ctx)
+	if err != nil {
+		return ListResult{}, err
+	}
+	matched := make([]string, 0, len(names))
+	for _, name := range names {
+		if re.MatchString(name) {
+			matched = append(matched, name)
+		}
+	}
+	raw, err := json.Marshal(matched)
+	if err != nil {
+		return ListResult{}, fmt.Errorf("encode matched names: %w", err)
+	}
+
+	where, args, err := s.listWhere(ctx, normalizeQueryOptions(opts))
+	if err != nil {
+		return ListResult{}, err
+	}
+	where += " AND s.name IN (SELECT value FROM json_each(?))"
+	return s.listSymbols(ctx, where, append(args, string(raw)), limit)
+}
+
+// filePackage is the package path of the file f: its Go package, or the
+// directory of a file in another language, which belongs to no package.
+const filePackage = `COALESCE(p.path, NULLIF(RTRIM(RTRIM(f.path, REPLACE(f.path, '/', '')), '/'), ''), '.')`
 
+// variantGroup partitions symbols so that the platform variants of one
+// symbol share a group and every other symbol is a group of its own.
+const variantGroup = filePackage + `, s.kind, s.name, COALESCE(s.receiver, ''),
+       CASE WHEN COALESCE(f.build_constraint, '') = '' THEN s.id ELSE 0 END`
+
+// listSymbols lists one row per logical symbol: platform variants are
+// collapsed into their first declaration, which carries them as Variants.
+func (s *Service) listSymbols(ctx context.Context, where string, args []any, limit int) (ListResult, error) {
 	// Get total count
 	var total int
-	countQuery := "SELECT COUNT(*) FROM symbols s JOIN files f ON f.id = s.file_id LEFT JOIN packages p ON p.id = f.package_id WHERE " + where
+	countQuery := "SELECT COUNT(*) FROM (SELECT 1 FROM symbols s JOIN files f ON f.id = s.file_id LEFT JOIN packages p ON p.id = f.package_id WHERE " +
+		where + " GROUP BY " + variantGroup + ")"
 	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
 		return ListResult{}, fmt.Errorf("count list symbols: %w", err)
 	}
 
-	// Get limited results (no body)
+	symbols, err := s.listPage(ctx, where, args, limit, 0)
+	if err != nil {
+		return ListResult{}, err
+	}
+	return ListResult{Symbols: symbols, Total: total, Limit: limit}, nil
+}
+
+// listPage returns up to limit list symbols (no body) after skipping offset,
+// with their variants and enum members.
+func (s *Service) listPage(ctx context.Context, where string, args []any, limit, offset int) ([]Symbol, error) {
 	selectQuery := `
-SELECT s.id, s.kind, s.name, COALESCE(s.signature, ''), '',
-       s.line_start, s.line_end, COALESCE(s.receiver, ''), f.path, COALESCE(p.path, '.')
-FROM symbols s
-JOIN files f ON f.id = s.file_id
-LEFT JOIN packages p ON p.id = f.package_id
-WHERE ` + where + `
-ORDER BY p.path, f.path, s.kind, s.name
-LIMIT ?;`
-	rows, err := s.db.QueryContext(ctx, selectQuery, append(args, limit)...)
+SELECT id, kind, name, signature, '', line_start, line_end, receiver, file_path, package, platform, cgo, unsafe, generated, language
+FROM (
+    SELECT s.id, s.kind, s.name, COALESCE(s.signature, '') AS signature,
+           s.line_start, s.line_end, COALESCE(s.receiver, '') AS receiver, f.path AS file_path,
+           ` + filePackage + ` AS package, COALESCE(f.build_constraint, '') AS platform,
+           f.cgo, f.unsafe, f.generated, COALESCE(NULLIF(f.language, 'go'), '') AS language,
+           ROW_NUMBER() OVER (PARTITION BY ` + variantGroup + ` ORDER BY f.path, s.id) AS variant_rank
+    FROM symbols s
+    JOIN files f ON f.id = s.file_id
+    LEFT JOIN packages p ON p.id = f.package_id
+    WHERE ` + where + `
+)
+WHERE variant_rank = 1
+ORDER BY package, file_path, kind, name, receiver, line_start, id
+LIMIT ? OFFSET ?;`
+	rows, err := s.db.QueryContext(ctx, selectQuery, append(slices.Clip(args), limit, offset)...)
 	if err != nil {
-		return ListResult{}, fmt.Errorf("query list symbols: %w", err)
+		return nil, fmt.Errorf("query list symbols: %w", err)
 	}
 	defer rows.Close()
 
@@ -157,16 +533,32 @@ LIMIT ?;`
 	for rows.Next() {
 		var sym Symbol
 		if err := rows.Scan(&sym.ID, &sym.Kind, &sym.Name, &sym.Signature, &sym.Body,
-			&sym.LineStart, &sym.LineEnd, &sym.Receiver, &sym.FilePath, &sym.Package); err != nil {
-			return ListResult{}, fmt.Errorf("scan list symbol: %w", err)
+			&sym.LineStart, &sym.LineEnd, &sym.Receiver, &sym.FilePath, &sym.Package, &sym.Platform, &sym.Cgo, &sym.Unsafe, &sym.Generated, &sym.Language); err != nil {
+			return nil, fmt.Errorf("scan list symbol: %w", err)
 		}
 		symbols = append(symbols, sym)
 	}
 	if err := rows.Err(); err != nil {
-		return ListResult{}, fmt.Errorf("iterate list symbols: %w", err)
+		return nil, fmt.Errorf("iterate list symbols: %w", err)
 	}
+	rows.Close()
 
-	return ListResult{Symbols: symbols, Total: total, Limit: limit}, nil
+	// The connection pool holds one connection, so variants and members are
+	// fetched once the page's rows are closed.
+	for i, sym := range symbols {
+		if sym.Platform != "" {
+			if symbols[i].Variants, err = s.variantsOf(ctx, sym); err != nil {
+				return nil, err
+			}
+		}
+		if sym.Kind != "enum" {
+			continue
+		}
+		if symbols[i].Members, err = s.enumMembers(ctx, sym.Package, sym.Name); err != nil {
+			return nil, err
+		}
+	}
+	return symbols, nil
 }
 
 func buildListWhere(opts QueryOptions) (string, []any) {
@@ -175,10 +567,10 @@ func buildListWhere(opts QueryOptions) (string, []any) {
 	if opts.PackagePath != "" {
 		if !strings.Contains(opts.PackagePath, "/") {
 			// Short name: match exact or last path segment
-			clauses = append(clauses, "(COALESCE(p.path, '.') = ? OR COALESCE(p.path, '.') LIKE ?)")
+			clauses = append(clauses, "("+filePackage+" = ? OR "+filePackage+" LIKE ?)")
 			args = append(args, opts.PackagePath, "%/"+opts.PackagePath)
 		} else {
-			clauses = append(clauses, "COALESCE(p.path, '.') = ?")
+			clauses = append(clauses, filePackage+" = ?")
 			args = append(args, opts.PackagePath)
 		}
 	}
@@ -196,9 +588,78 @@ func buildListWhere(opts QueryOptions) (string, []any) {
 		clauses = append(clauses, "LOWER(s.kind) = ?")
 		args = append(args, opts.Kind)
 	}
+	if opts.Unsafe {
+		clauses = append(clauses, "(f.cgo = 1 OR f.unsafe = 1)")
+	}
+	if !opts.IncludeGenerated {
+		clauses = append(clauses, "f.generated = 0")
+	}
+	if len(opts.Paths) > 0 {
+		include := make([]string, 0, len(opts.Paths))
+		for _, pattern := range opts.Paths {
+			clause, patternArgs := pathPatternClause(pattern)
+			include = append(include, clause)
+			args = append(args, patternArgs...)
+		}
+		clauses = append(clauses, "("+strings.Join(include, " OR ")+")")
+	}
+	for _, pattern := range opts.ExcludePaths {
+		clause, patternArgs := pathPatternClause(pattern)
+		clauses = append(clauses, "NOT "+clause)
+		args = append(args, patternArgs...)
+	}
 	return strings.Join(clauses, " AND "), args
 }
 
+// NormalizePathPatterns cleans --path style package patterns: "./" prefixes
+// and trailing slashes are dropped, and "..." is only allowed as a trailing
+// "/..." wildcard (or on its own, meaning every package).
+func NormalizePathPatterns(patterns []string) ([]string, error) {
+	out := make([]string, 0, len(patterns))
+	for _, raw := range patterns {
+		p := filepath.ToSlash(strings.TrimSpace(raw))
+		if p == "" {
+			continue
+		}
+		p = strings.TrimPrefix(p, "./")
+		if p != "/" {
+			p = strings.TrimSuffix(p, "/")
+		}
+		if p == "" {
+			p = "."
+		}
+		base := strings.TrimSuffix(strings.TrimSuffix(p, "..."), "/")
+		if strings.Contains(base, "...") || strings.HasPrefix(p, "/") {
+			return nil, fmt.Errorf("invalid path pattern %q: use a module-relative package path, optionally ending in /...", raw)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// pathPatternClause matches a package path against a normalized pattern:
+// "..." matches everything, "dir/..." matches dir and its subpackages, and
+// anything else matches one package exactly.
+func pathPatternClause(pattern string) (string, []any) {
+	if pattern == "..." || pattern == "./..." {
+		return "1=1", nil
+	}
+	if base, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return `(` + filePackage + ` = ? OR ` + filePackage + ` LIKE ? ESCAPE '\')`, []any{base, db.EscapeLike(base) + "/%"}
+	}
+	return filePackage + " = ?", []any{pattern}
+}
+
+func matchPathPattern(pkgPath, pattern string) bool {
+	if pattern == "..." {
+		return true
+	}
+	if base, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return pkgPath == base || strings.HasPrefix(pkgPath, base+"/")
+	}
+	return pkgPath == pattern
+}
+
 func (s *Service) FindExact(ctx context.Context, symbol string) (Result, error) {
 	return s.Find(ctx, symbol, QueryOptions{})
 }
@@ -208,20 +669,19 @@ func (s *Service) Find(ctx context.Context, symbol string, opts QueryOptions) (R
 	filtersApplied := hasActiveFilters(opts)
 
 	// Support "Receiver.Name" dot syntax
-	var receiverFilter string
-	if idx := strings.IndexByte(symbol, '.'); idx > 0 && idx < len(symbol)-1 {
-		receiverFilter = symbol[:idx]
-		symbol = symbol[idx+1:]
-	}
+	rawQuery := strings.TrimSpace(symbol)
+	parsed := ParseSymbolQuery(symbol)
+	receiverFilter, symbol := parsed.Receiver, parsed.Name
 
 	rows, err := s.db.QueryContext(ctx, `
-SELECT s.id, s.kind, s.name, COALESCE(s.signature, ''), COALESCE(s.body, ''),
-       s.line_start, s.line_end, COALESCE(s.receiver, ''), f.path, COALESCE(p.path, '.')
+SELECT s.id, s.kind, s.name, COALESCE(s.signature, ''), COALESCE(s.body_hash, ''),
+       s.line_start, s.line_end, COALESCE(s.receiver, ''), f.path, `+filePackage+`,
+       COALESCE(f.build_constraint, ''), f.cgo, f.unsafe, f.generated, COALESCE(NULLIF(f.language, 'go'), '')
 FROM symbols s
 JOIN files f ON f.id = s.file_id
 LEFT JOIN packages p ON p.id = f.package_id
 WHERE s.name = ?
-ORDER BY p.path, f.path, s.kind, s.receiver;
+ORDER BY p.path, f.path, s.kind, s.receiver, s.line_start, s.id;
 `, symbol)
 	if err != nil {
 		return Result{}, fmt.Errorf("query symbol: %w", err)
@@ -236,12 +696,17 @@ ORDER BY p.path, f.path, s.kind, s.receiver;
 			&item.Kind,
 			&item.Name,
 			&item.Signature,
-			&item.Body,
+			&item.bodyHash,
 			&item.LineStart,
 			&item.LineEnd,
 			&item.Receiver,
 			&item.FilePath,
 			&item.Package,
+			&item.Platform,
+			&item.Cgo,
+			&item.Unsafe,
+			&item.Generated,
+			&item.Language,
 		); err != nil {
 			return Result{}, fmt.Errorf("scan symbol row: %w", err)
 		}
@@ -262,6 +727,26 @@ ORDER BY p.path, f.path, s.kind, s.receiver;
 		matches = filtered
 	}
 
+	if len(matches) == 0 && opts.Fuzzy {
+		name, err := s.fuzzyResolve(ctx, symbol)
+		if err != nil {
+			return Result{}, err
+		}
+		if name != "" && name != symbol {
+			query := name
+			if receiverFilter != "" {
+				query = receiverFilter + "." + name
+			}
+			opts.Fuzzy = false
+			result, err := s.Find(ctx, query, opts)
+			if err != nil {
+				return Result{}, err
+			}
+			result.FuzzyQuery = rawQuery
+			return result, nil
+		}
+	}
+
 	if len(matches) == 0 {
 		queryLabel := symbol
 		if receiverFilter != "" {
@@ -281,6 +766,14 @@ ORDER BY p.path, f.path, s.kind, s.receiver;
 		}
 	}
 
+	if !opts.IncludeGenerated {
+		matches = preferHandWritten(matches)
+	}
+	if opts.Kind == "" {
+		matches = dropEnumsOfTypes(matches)
+	}
+	matches = collapseVariants(matches)
+
 	if len(matches) > 1 {
 		candidates := make([]Candidate, 0, len(matches))
 		for _, m := range matches {
@@ -295,20 +788,430 @@ ORDER BY p.path, f.path, s.kind, s.receiver;
 	}
 
 	sym := matches[0]
+	if sym.Platform != "" {
+		if sym.Variants, err = s.variantsOf(ctx, sym); err != nil {
+			return Result{}, err
+		}
+	}
 	deps, err := s.directDeps(ctx, sym.ID)
 	if err != nil {
 		return Result{}, err
 	}
 
-	return Result{Symbol: sym, Dependencies: deps}, nil
+	result := Result{Symbol: sym, Dependencies: deps}
+	if result.Symbol.TypeParams, err = s.typeParamsOf(ctx, sym); err != nil {
+		return Result{}, err
+	}
+	if !opts.NoBody {
+		syms := []*Symbol{&result.Symbol}
+		for i := range result.Dependencies {
+			syms = append(syms, &result.Dependencies[i])
+		}
+		if err := s.loadBodies(ctx, syms); err != nil {
+			return Result{}, err
+		}
+	}
+	if sym.Kind == "type" || sym.Kind == "enum" {
+		if result.Symbol.Members, err = s.enumMembers(ctx, sym.Package, sym.Name); err != nil {
+			return Result{}, err
+		}
+	}
+	if sym.Kind == "type" {
+		if result.Embeds, err = s.embedsOf(ctx, sym.Package, sym.Name); err != nil {
+			return Result{}, err
+		}
+		if len(result.Embeds) > 0 {
+			if result.PromotedMethods, err = s.PromotedMethods(ctx, sym.Package, sym.Name); err != nil {
+				return Result{}, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// preferHandWritten drops the matches from generated and vendored files when
+// any match is hand-written, so a type is not ambiguous with its vendored
+// copy or a generated mock.
+func preferHandWritten(matches []Symbol) []Symbol {
+	kept := make([]Symbol, 0, len(matches))
+	for _, m := range matches {
+		if !m.Generated {
+			kept = append(kept, m)
+		}
+	}
+	if len(kept) == 0 {
+		return matches
+	}
+	return kept
+}
+
+// collapseVariants keeps the first of each set of platform variants (matches
+// from build-constrained files that share package, kind, receiver and name),
+// so a symbol declared once per platform resolves instead of being ambiguous.
+func collapseVariants(matches []Symbol) []Symbol {
+	seen := map[string]bool{}
+	out := make([]Symbol, 0, len(matches))
+	for _, m := range matches {
+		if m.Platform != "" {
+			key := strings.Join([]string{m.Package, m.Kind, m.Receiver, m.Name}, "\x00")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// variantsOf returns every build-constrained declaration sharing sym's
+// package, kind, receiver and name, or nil when sym is the only one.
+func (s *Service) variantsOf(ctx context.Context, sym Symbol) ([]Variant, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT s.id, f.build_constraint, f.path, s.line_start, s.line_end, COALESCE(s.signature, '')
+FROM symbols s
+JOIN files f ON f.id = s.file_id
+LEFT JOIN packages p ON p.id = f.package_id
+WHERE `+filePackage+` = ? AND s.kind = ? AND s.name = ? AND COALESCE(s.receiver, '') = ?
+  AND COALESCE(f.build_constraint, '') != ''
+ORDER BY f.path, s.id;
+`, sym.Package, sym.Kind, sym.Name, sym.Receiver)
+	if err != nil {
+		return nil, fmt.Errorf("query platform variants: %w", err)
+	}
+	defer rows.Close()
+
+	var variants []Variant
+	for rows.Next() {
+		var v Variant
+		if err := rows.Scan(&v.ID, &v.Platform, &v.FilePath, &v.LineStart, &v.LineEnd, &v.Signature); err != nil {
+			return nil, fmt.Errorf("scan platform variant: %w", err)
+		}
+		variants = append(variants, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate platform variants: %w", err)
+	}
+	if len(variants) < 2 {
+		return nil, nil
+	}
+	return variants, nil
+}
+
+// dropEnumsOfTypes removes enum matches whose type is also matched, so a
+// plain lookup of an enum type resolves to the type. The type's result still
+// carries the enum members.
+func dropEnumsOfTypes(matches []Symbol) []Symbol {
+	types := map[string]bool{}
+	for _, m := range matches {
+		if m.Kind == "type" {
+			types[m.Package+"."+m.Name] = true
+		}
+	}
+	kept := matches[:0:0]
+	for _, m := range matches {
+		if m.Kind == "enum" && types[m.Package+"."+m.Name] {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+// enumMembers returns the constants of the enums named typeName in pkgPath.
+// A type can have several const blocks; their members are listed by file
+// and block position.
+func (s *Service) enumMembers(ctx context.Context, pkgPath, typeName string) ([]EnumMember, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT m.name, m.value
+FROM enum_members m
+JOIN symbols s ON s.id = m.symbol_id
+JOIN files f ON f.id = s.file_id
+LEFT JOIN packages p ON p.id = f.package_id
+WHERE s.kind = 'enum' AND s.name = ? AND `+filePackage+` = ?
+ORDER BY f.path, m.position, m.id;
+`, typeName, pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("query enum members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []EnumMember
+	for rows.Next() {
+		var m EnumMember
+		if err := rows.Scan(&m.Name, &m.Value); err != nil {
+			return nil, fmt.Errorf("scan enum member: %w", err)
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate enum members: %w", err)
+	}
+	return members, nil
+}
+
+// typeParamsOf returns the type parameters of sym. Methods declare none of
+// their own, so a method of a generic type reports the type's parameters,
+// renamed positionally to the names its receiver uses, e.g. K for List[K].
+func (s *Service) typeParamsOf(ctx context.Context, sym Symbol) ([]TypeParam, error) {
+	query, args := `SELECT name, constraint_expr FROM type_params WHERE symbol_id = ? ORDER BY position;`, []any{sym.ID}
+	var renames []string
+	if sym.Kind == "method" {
+		base, params, ok := strings.Cut(strings.TrimPrefix(sym.Receiver, "*"), "[")
+		if !ok {
+			return nil, nil
+		}
+		for _, name := range strings.Split(strings.TrimSuffix(params, "]"), ",") {
+			renames = append(renames, strings.TrimSpace(name))
+		}
+		query = `
+SELECT tp.name, tp.constraint_expr
+FROM type_params tp
+JOIN symbols s ON s.id = tp.symbol_id
+JOIN files f ON f.id = s.file_id
+LEFT JOIN packages p ON p.id = f.package_id
+WHERE s.kind = 'type' AND s.name = ? AND ` + filePackage + ` = ?
+ORDER BY f.path, s.id, tp.position;`
+		args = []any{base, sym.Package}
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query type params: %w", err)
+	}
+	defer rows.Close()
+
+	var params []TypeParam
+	for rows.Next() {
+		var p TypeParam
+		if err := rows.Scan(&p.Name, &p.Constraint); err != nil {
+			return nil, fmt.Errorf("scan type param: %w", err)
+		}
+		params = append(params, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate type params: %w", err)
+	}
+	// A type declared once per platform lists its parameters once per
+	// declaration; keep the first.
+	if len(renames) > 0 && len(params) >= len(renames) {
+		params = params[:len(renames)]
+		for i := range params {
+			if renames[i] != "_" {
+				params[i].Name = renames[i]
+			}
+		}
+	}
+	return params, nil
+}
+
+// TypeMembers returns the indexed fields and declared method set of a type
+// symbol, fields in declaration order and methods by name. Methods promoted
+// through embedding are reported by PromotedMethods instead.
+func (s *Service) TypeMembers(ctx context.Context, symbolID int64) ([]Field, []Method, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT name, type, tag, embedded
+FROM struct_fields
+WHERE symbol_id = ?
+ORDER BY position;
+`, symbolID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query fields: %w", err)
+	}
+	defer rows.Close()
+
+	fields := []Field{}
+	for rows.Next() {
+		var f Field
+		if err := rows.Scan(&f.Name, &f.Type, &f.Tag, &f.Embedded); err != nil {
+			return nil, nil, fmt.Errorf("scan field: %w", err)
+		}
+		fields = append(fields, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate fields: %w", err)
+	}
+
+	methodRows, err := s.db.QueryContext(ctx, `
+SELECT m.name, m.signature, m.pointer_receiver, COALESCE(f.path, ''), COALESCE(ms.line_start, 0)
+FROM type_methods m
+LEFT JOIN symbols ms ON ms.id = m.method_symbol_id
+LEFT JOIN files f ON f.id = ms.file_id
+WHERE m.symbol_id = ?
+ORDER BY m.name;
+`, symbolID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query methods: %w", err)
+	}
+	defer methodRows.Close()
+
+	methods := []Method{}
+	for methodRows.Next() {
+		var m Method
+		if err := methodRows.Scan(&m.Name, &m.Signature, &m.PointerReceiver, &m.FilePath, &m.LineStart); err != nil {
+			return nil, nil, fmt.Errorf("scan method: %w", err)
+		}
+		methods = append(methods, m)
+	}
+	if err := methodRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate methods: %w", err)
+	}
+	return fields, methods, nil
+}
+
+// maxEmbedDepth bounds how far PromotedMethods follows embedding chains.
+const maxEmbedDepth = 5
+
+type embedPath struct {
+	pkg  string
+	name string
+	via  string
+}
+
+// PromotedMethods returns the methods typeName gains through embedding,
+// following Go's selector rules: a method declared on the type itself, or at
+// a shallower embedding depth, shadows deeper ones, and a name reachable
+// through more than one embed at the same depth is ambiguous and not
+// promoted. Types outside the index contribute no methods.
+func (s *Service) PromotedMethods(ctx context.Context, pkgPath, typeName string) ([]PromotedMethod, error) {
+	own, err := s.methodsOf(ctx, pkgPath, typeName)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(own))
+	for _, m := range own {
+		seen[m.Name] = true
+	}
+
+	visited := map[string]bool{pkgPath + "." + typeName: true}
+	level := []embedPath{{pkg: pkgPath, name: typeName}}
+	promoted := []PromotedMethod{}
+	for depth := 0; depth < maxEmbedDepth && len(level) > 0; depth++ {
+		var next []embedPath
+		for _, parent := range level {
+			embeds, err := s.embedsOf(ctx, parent.pkg, parent.name)
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range embeds {
+				key := e.Package + "." + e.Name
+				if visited[key] {
+					continue
+				}
+				visited[key] = true
+				via := e.Name
+				if parent.via != "" {
+					via = parent.via + "." + e.Name
+				}
+				next = append(next, embedPath{pkg: e.Package, name: e.Name, via: via})
+			}
+		}
+
+		candidates := map[string][]PromotedMethod{}
+		var order []string
+		for _, child := range next {
+			methods, err := s.methodsOf(ctx, child.pkg, child.name)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range methods {
+				if seen[m.Name] {
+					continue
+				}
+				if _, ok := candidates[m.Name]; !ok {
+					order = append(order, m.Name)
+				}
+				candidates[m.Name] = append(candidates[m.Name], PromotedMethod{Symbol: m, Via: child.via})
+			}
+		}
+		for _, name := range order {
+			seen[name] = true
+			if len(candidates[name]) == 1 {
+				promoted = append(promoted, candidates[name][0])
+			}
+		}
+		level = next
+	}
+
+	return promoted, nil
+}
+
+func (s *Service) embedsOf(ctx context.Context, pkgPath, typeName string) ([]Embed, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT e.embedded_name, e.embedded_package, e.pointer
+FROM type_embeds e
+JOIN symbols s ON s.id = e.symbol_id
+JOIN files f ON f.id = s.file_id
+LEFT JOIN packages p ON p.id = f.package_id
+WHERE s.kind = 'type' AND s.name = ? AND `+filePackage+` = ?
+ORDER BY e.id;
+`, typeName, pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("query embeds: %w", err)
+	}
+	defer rows.Close()
+
+	embeds := []Embed{}
+	for rows.Next() {
+		var e Embed
+		if err := rows.Scan(&e.Name, &e.Package, &e.Pointer); err != nil {
+			return nil, fmt.Errorf("scan embed: %w", err)
+		}
+		embeds = append(embeds, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate embeds: %w", err)
+	}
+	return embeds, nil
+}
+
+func (s *Service) methodsOf(ctx context.Context, pkgPath, typeName string) ([]Symbol, error) {
+	clause, args := receiverClause(typeName)
+	rows, err := s.db.QueryContext(ctx, `
+SELECT s.id, s.kind, s.name, COALESCE(s.signature, ''), '',
+       s.line_start, s.line_end, COALESCE(s.receiver, ''), f.path, `+filePackage+`
+FROM symbols s
+JOIN files f ON f.id = s.file_id
+LEFT JOIN packages p ON p.id = f.package_id
+WHERE s.kind = 'method' AND `+filePackage+` = ? AND `+clause+`
+ORDER BY s.name, s.receiver, s.id;
+`, append([]any{pkgPath}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("query methods: %w", err)
+	}
+	defer rows.Close()
+
+	methods := []Symbol{}
+	for rows.Next() {
+		var m Symbol
+		if err := rows.Scan(&m.ID, &m.Kind, &m.Name, &m.Signature, &m.Body,
+			&m.LineStart, &m.LineEnd, &m.Receiver, &m.FilePath, &m.Package); err != nil {
+			return nil, fmt.Errorf("scan method: %w", err)
+		}
+		methods = append(methods, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate methods: %w", err)
+	}
+	return methods, nil
 }
 
 func normalizeQueryOptions(opts QueryOptions) QueryOptions {
 	normalized := QueryOptions{
-		PackagePath: strings.TrimSpace(opts.PackagePath),
-		FilePath:    normalizeFilePath(opts.FilePath),
-		Kind:        strings.ToLower(strings.TrimSpace(opts.Kind)),
+		PackagePath:      strings.TrimSpace(opts.PackagePath),
+		FilePath:         normalizeFilePath(opts.FilePath),
+		Kind:             strings.ToLower(strings.TrimSpace(opts.Kind)),
+		Tags:             NormalizeTags(opts.Tags),
+		Unsafe:           opts.Unsafe,
+		IncludeGenerated: opts.IncludeGenerated,
+		NoBody:           opts.NoBody,
+		Fuzzy:            opts.Fuzzy,
 	}
+	// Invalid patterns are rejected by callers; drop them here rather than
+	// failing a lookup.
+	normalized.Paths, _ = NormalizePathPatterns(opts.Paths)
+	normalized.ExcludePaths, _ = NormalizePathPatterns(opts.ExcludePaths)
 	return normalized
 }
 
@@ -321,7 +1224,8 @@ func normalizeFilePath(path string) string {
 }
 
 func hasActiveFilters(opts QueryOptions) bool {
-	return opts.PackagePath != "" || opts.FilePath != "" || opts.Kind != ""
+	return opts.PackagePath != "" || opts.FilePath != "" || opts.Kind != "" ||
+		len(opts.Paths) > 0 || len(opts.ExcludePaths) > 0 || len(opts.Tags) > 0 || opts.Unsafe
 }
 
 func filterMatches(matches []Symbol, opts QueryOptions) []Symbol {
@@ -336,11 +1240,37 @@ func filterMatches(matches []Symbol, opts QueryOptions) []Symbol {
 		if opts.Kind != "" && strings.ToLower(match.Kind) != opts.Kind {
 			continue
 		}
+		if !matchPathPatterns(match.Package, opts) {
+			continue
+		}
+		if !MatchTags(match.Platform, opts.Tags) {
+			continue
+		}
+		if opts.Unsafe && !match.Cgo && !match.Unsafe {
+			continue
+		}
 		filtered = append(filtered, match)
 	}
 	return filtered
 }
 
+func matchPathPatterns(pkgPath string, opts QueryOptions) bool {
+	for _, pattern := range opts.ExcludePaths {
+		if matchPathPattern(pkgPath, pattern) {
+			return false
+		}
+	}
+	if len(opts.Paths) == 0 {
+		return true
+	}
+	for _, pattern := range opts.Paths {
+		if matchPathPattern(pkgPath, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
 func matchPackagePath(pkgPath, filter string) bool {
 	if pkgPath == filter {
 		return true
@@ -373,10 +1303,10 @@ func (s *Service) suggestions(ctx context.Context, symbol string) ([]string, err
 	rows, err := s.db.QueryContext(ctx, `
 SELECT DISTINCT name
 FROM symbols
-WHERE name LIKE ?
+WHERE name LIKE ? ESCAPE '\'
 ORDER BY name
 LIMIT 5;
-`, symbol+"%")
+`, db.EscapeLike(symbol)+"%")
 	if err != nil {
 		return nil, fmt.Errorf("query suggestions: %w", err)
 	}
@@ -394,34 +1324,134 @@ LIMIT 5;
 		return nil, fmt.Errorf("iterate suggestions: %w", err)
 	}
 
-	// Pass 2: case-insensitive substring match if prefix found nothing
+	// Pass 2: case-folded prefix, then substring, then fuzzy match if prefix
+	// found nothing. SQLite's LOWER() and LIKE only fold ASCII, so folding
+	// happens in Go to give non-ASCII identifiers usable suggestions too.
 	if len(out) == 0 {
-		rows2, err := s.db.QueryContext(ctx, `
+		out = append(out, s.foldedSuggestions(ctx, symbol, 5)...)
+	}
+
+	return out, nil
+}
+
+// foldedSuggestions scans distinct symbol names and returns up to limit names
+// whose Unicode case-folded form starts with, then contains, the folded query,
+// followed by the closest fuzzy matches. Errors are non-fatal and yield no
+// suggestions.
+func (s *Service) foldedSuggestions(ctx context.Context, symbol string, limit int) []string {
+	needle := foldIdentifier(strings.TrimSpace(symbol))
+	if needle == "" {
+		return nil
+	}
+	names, err := s.candidateNames(ctx, needle)
+	if err != nil {
+		return nil
+	}
+
+	var prefix, substring []string
+	for _, name := range names {
+		folded := foldIdentifier(name)
+		switch {
+		case strings.HasPrefix(folded, needle):
+			prefix = append(prefix, name)
+		case strings.Contains(folded, needle):
+			substring = append(substring, name)
+		}
+	}
+
+	out := append(prefix, substring...)
+	for _, m := range rankFuzzy(needle, names) {
+		if len(out) >= limit {
+			break
+		}
+		if !slices.Contains(out, m.name) {
+			out = append(out, m.name)
+		}
+	}
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// symbolNames returns the distinct symbol names in the index, sorted.
+func (s *Service) symbolNames(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
 SELECT DISTINCT name
 FROM symbols
-WHERE LOWER(name) LIKE LOWER(?)
-ORDER BY name
-LIMIT 5;
-`, "%"+symbol+"%")
-		if err != nil {
-			return out, nil // non-fatal
+ORDER BY name;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query symbol names: %w", err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan symbol name: %w", err)
 		}
-		defer rows2.Close()
-		for rows2.Next() {
-			var name string
-			if err := rows2.Scan(&name); err != nil {
-				break
-			}
-			out = append(out, name)
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate symbol names: %w", err)
+	}
+	return names, nil
+}
+
+// maxCandidateNames bounds the distinct names a missed lookup scans for
+// folded and fuzzy suggestions, so a typo costs the same on any index.
+const maxCandidateNames = 50000
+
+// candidateNames returns the distinct symbol names, sorted, that are long
+// enough to match the folded needle within fuzzyThreshold edits, at most
+// maxCandidateNames of them.
+func (s *Service) candidateNames(ctx context.Context, needle string) ([]string, error) {
+	n := utf8.RuneCountInString(needle)
+	rows, err := s.db.QueryContext(ctx, `
+SELECT DISTINCT name
+FROM symbols
+WHERE length(name) >= ?
+ORDER BY name
+LIMIT ?;
+`, n-fuzzyThreshold(n), maxCandidateNames)
+	if err != nil {
+		return nil, fmt.Errorf("query candidate names: %w", err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan candidate name: %w", err)
 		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate candidate names: %w", err)
 	}
+	return names, nil
+}
 
-	return out, nil
+// foldIdentifier composes s to NFC and maps every rune to the smallest rune
+// in its Unicode simple case-folding orbit, so identifiers like "Überblick",
+// "überblick", and "u\u0308berblick" (u and a combining diaeresis) compare
+// equal.
+func foldIdentifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		folded := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < folded {
+				folded = f
+			}
+		}
+		return folded
+	}, norm.NFC.String(s))
 }
 
 func (s *Service) directDeps(ctx context.Context, symbolID int64) ([]Symbol, error) {
 	rows, err := s.db.QueryContext(ctx, `
-SELECT DISTINCT s2.id, s2.kind, s2.name, COALESCE(s2.signature, ''), COALESCE(s2.body, ''),
+SELECT DISTINCT s2.id, s2.kind, s2.name, COALESCE(s2.signature, ''), COALESCE(s2.body_hash, ''),
        s2.line_start, s2.line_end, COALESCE(s2.receiver, ''), f2.path, COALESCE(p2.path, '.')
 FROM symbol_deps d
 JOIN symbols s2 ON s2.name = d.dep_name
@@ -430,7 +1460,7 @@ LEFT JOIN packages p2 ON p2.id = f2.package_id
 WHERE d.symbol_id = ?
   AND (d.dep_package = '' OR COALESCE(p2.path, '.') = d.dep_package)
   AND (d.dep_kind = '' OR s2.kind = d.dep_kind)
-ORDER BY p2.path, f2.path, s2.name
+ORDER BY p2.path, f2.path, s2.name, s2.receiver, s2.line_start, s2.id
 LIMIT 25;
 `, symbolID)
 	if err != nil {
@@ -446,7 +1476,7 @@ LIMIT 25;
 			&dep.Kind,
 			&dep.Name,
 			&dep.Signature,
-			&dep.Body,
+			&dep.bodyHash,
 			&dep.LineStart,
 			&dep.LineEnd,
 			&dep.Receiver,
@@ -463,6 +1493,341 @@ LIMIT 25;
 	return deps, nil
 }
 
+// MaxCallerDepth bounds how many levels Callers walks.
+const MaxCallerDepth = 10
+
+// maxCallers caps the total callers returned so a widely used symbol does not
+// flood the result.
+const maxCallers = 100
+
+// Callers walks symbol_deps in reverse from sym, breadth first, up to depth
+// levels. A dependency row matches a symbol under the same rules directDeps
+// uses, so a caller of X lists X among its dependencies. Each symbol appears
+// once, at its shallowest depth.
+func (s *Service) Callers(ctx context.Context, sym Symbol, depth int) ([]Caller, error) {
+	seen := map[int64]bool{sym.ID: true}
+	level := []Symbol{sym}
+	callers := make([]Caller, 0, 8)
+	for d := 1; d <= depth && len(level) > 0; d++ {
+		var next []Symbol
+		for _, target := range level {
+			direct, err := s.directCallers(ctx, target)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range direct {
+				if seen[c.ID] {
+					continue
+				}
+				if len(callers) == maxCallers {
+					return callers, nil
+				}
+				seen[c.ID] = true
+				callers = append(callers, Caller{Symbol: c, Depth: d, Calls: symbolLabel(target)})
+				next = append(next, c)
+			}
+		}
+		level = next
+	}
+	syms := make([]*Symbol, len(callers))
+	for i := range callers {
+		syms[i] = &callers[i].Symbol
+	}
+	if err := s.loadBodies(ctx, syms); err != nil {
+		return nil, err
+	}
+	return callers, nil
+}
+
+// loadBodies fills Body for syms from symbol_bodies in one query. Bodies are
+// kept out of the symbol queries so lookups that do not print them, such as
+// `recon find --no-body`, never read them.
+func (s *Service) loadBodies(ctx context.Context, syms []*Symbol) error {
+	byHash := map[string][]*Symbol{}
+	args := []any{}
+	for _, sym := range syms {
+		if sym.bodyHash == "" {
+			continue
+		}
+		if _, ok := byHash[sym.bodyHash]; !ok {
+			args = append(args, sym.bodyHash)
+		}
+		byHash[sym.bodyHash] = append(byHash[sym.bodyHash], sym)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	rows, err := s.db.QueryContext(ctx, `
+SELECT hash, body FROM symbol_bodies
+WHERE hash IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")+`);
+`, args...)
+	if err != nil {
+		return fmt.Errorf("query symbol bodies: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hash, body string
+		if err := rows.Scan(&hash, &body); err != nil {
+			return fmt.Errorf("scan symbol body: %w", err)
+		}
+		for _, sym := range byHash[hash] {
+			sym.Body = body
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate symbol bodies: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) directCallers(ctx context.Context, target Symbol) ([]Symbol, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT DISTINCT s.id, s.kind, s.name, COALESCE(s.signature, ''), COALESCE(s.body_hash, ''),
+       s.line_start, s.line_end, COALESCE(s.receiver, ''), f.path, `+filePackage+`
+FROM symbol_deps d
+JOIN symbols s ON s.id = d.symbol_id
+JOIN files f ON f.id = s.file_id
+LEFT JOIN packages p ON p.id = f.package_id
+WHERE d.dep_name = ?
+  AND (d.dep_package = '' OR d.dep_package = ?)
+  AND (d.dep_kind = '' OR d.dep_kind = ?)
+ORDER BY p.path, f.path, s.name, s.receiver, s.line_start, s.id;
+`, target.Name, target.Package, target.Kind)
+	if err != nil {
+		return nil, fmt.Errorf("query callers: %w", err)
+	}
+	defer rows.Close()
+
+	callers := make([]Symbol, 0, 8)
+	for rows.Next() {
+		var c Symbol
+		if err := rows.Scan(
+			&c.ID,
+			&c.Kind,
+			&c.Name,
+			&c.Signature,
+			&c.bodyHash,
+			&c.LineStart,
+			&c.LineEnd,
+			&c.Receiver,
+			&c.FilePath,
+			&c.Package,
+		); err != nil {
+			return nil, fmt.Errorf("scan caller row: %w", err)
+		}
+		callers = append(callers, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate caller rows: %w", err)
+	}
+	return callers, nil
+}
+
+// maxUsages caps the call sites Usages returns.
+const maxUsages = 200
+
+// Usages returns the call sites of target recorded in symbol_deps, ordered
+// by package, file, and line. The source of each line comes from the
+// caller's indexed body, so files are not read.
+func (s *Service) Usages(ctx context.Context, target Symbol) ([]Usage, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT s.id, s.kind, s.name, COALESCE(s.receiver, ''), f.path, `+filePackage+`,
+       s.line_start, COALESCE(b.body, ''), d.lines
+FROM symbol_deps d
+JOIN symbols s ON s.id = d.symbol_id
+JOIN files f ON f.id = s.file_id
+LEFT JOIN packages p ON p.id = f.package_id
+LEFT JOIN symbol_bodies b ON b.hash = s.body_hash
+WHERE d.dep_name = ?
+  AND (d.dep_package = '' OR d.dep_package = ?)
+  AND (d.dep_kind = '' OR d.dep_kind = ?)
+ORDER BY p.path, f.path, s.line_start, s.id;
+`, target.Name, target.Package, target.Kind)
+	if err != nil {
+		return nil, fmt.Errorf("query usages: %w", err)
+	}
+	defer rows.Close()
+
+	usages := make([]Usage, 0, 8)
+	seen := map[[2]int64]bool{}
+	for rows.Next() {
+		var (
+			id     int64
+			caller Symbol
+			body   string
+			lines  string
+		)
+		if err := rows.Scan(&id, &caller.Kind, &caller.Name, &caller.Receiver, &caller.FilePath, &caller.Package,
+			&caller.LineStart, &body, &lines); err != nil {
+			return nil, fmt.Errorf("scan usage row: %w", err)
+		}
+		bodyLines := strings.Split(body, "\n")
+		for _, field := range strings.Split(lines, ",") {
+			line, _ := strconv.Atoi(field) // 0 when no lines were recorded
+			if seen[[2]int64{id, int64(line)}] {
+				continue
+			}
+			seen[[2]int64{id, int64(line)}] = true
+			u := Usage{Caller: symbolLabel(caller), CallerKind: caller.Kind, FilePath: caller.FilePath, Package: caller.Package, Line: line}
+			if i := line - caller.LineStart; line > 0 && i >= 0 && i < len(bodyLines) {
+				u.Code = strings.TrimSpace(bodyLines[i])
+			}
+			usages = append(usages, u)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate usage rows: %w", err)
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.Line < b.Line
+	})
+	if len(usages) > maxUsages {
+		usages = usages[:maxUsages]
+	}
+	return usages, nil
+}
+
+// Assets returns the //go:embed patterns declared in pkgPath, in file and
+// line order, so callers can tell that the package ships files.
+func (s *Service) Assets(ctx context.Context, pkgPath string) ([]Asset, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT e.var_name, e.pattern, f.path, e.line, e.paths
+FROM file_embeds e
+JOIN files f ON f.id = e.file_id
+LEFT JOIN packages p ON p.id = f.package_id
+WHERE `+filePackage+` = ?
+ORDER BY f.path, e.line, e.id;
+`, pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("query assets: %w", err)
+	}
+	defer rows.Close()
+
+	var assets []Asset
+	for rows.Next() {
+		var (
+			a     Asset
+			paths string
+		)
+		if err := rows.Scan(&a.Var, &a.Pattern, &a.FilePath, &a.Line, &paths); err != nil {
+			return nil, fmt.Errorf("scan asset row: %w", err)
+		}
+		if err := json.Unmarshal([]byte(paths), &a.Files); err != nil {
+			return nil, fmt.Errorf("decode embedded paths of %s: %w", a.Pattern, err)
+		}
+		if a.Files == nil {
+			a.Files = []string{}
+		}
+		assets = append(assets, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate asset rows: %w", err)
+	}
+	return assets, nil
+}
+
+// Deprecation returns the "Deprecated:" paragraph recorded for a symbol, or
+// "" when its doc comment has none.
+func (s *Service) Deprecation(ctx context.Context, symbolID int64) (string, error) {
+	var text string
+	err := s.db.QueryRowContext(ctx, `
+SELECT text FROM annotations WHERE symbol_id = ? AND kind = 'deprecated' ORDER BY id LIMIT 1;
+`, symbolID).Scan(&text)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("query deprecation: %w", err)
+	}
+	return text, nil
+}
+
+// reverseRelations names sync-derived relations as seen from their target.
+var reverseRelations = map[string]string{
+	"generated_as":   "generated_from",
+	"implemented_by": "implements",
+}
+
+// CodeLinks returns the links sync derived from sym, such as the Go types
+// generated for a proto message, and those pointing at it, with the relation
+// reversed.
+func (s *Service) CodeLinks(ctx context.Context, sym Symbol) ([]CodeLink, error) {
+	links, err := s.queryCodeLinks(ctx, `
+SELECT e.relation, e.to_ref, COALESCE(tf.path, ''), COALESCE(t.line_start, 0)
+FROM edges e
+LEFT JOIN symbols t ON t.id = (
+    SELECT sy.id FROM symbols sy
+    JOIN files sf ON sf.id = sy.file_id
+    JOIN packages sp ON sp.id = sf.package_id
+    WHERE sp.path || '.' || sy.name = e.to_ref AND COALESCE(sy.receiver, '') = ''
+    ORDER BY sy.id LIMIT 1)
+LEFT JOIN files tf ON tf.id = t.file_id
+WHERE e.from_type = 'symbol' AND e.from_id = ? AND e.source = 'sync'
+ORDER BY e.relation, e.to_ref;
+`, sym.ID)
+	if err != nil {
+		return nil, err
+	}
+	if sym.Receiver != "" {
+		return links, nil
+	}
+	incoming, err := s.queryCodeLinks(ctx, `
+SELECT e.relation,
+       `+filePackage+` || '.' || CASE WHEN COALESCE(s.receiver, '') != '' THEN s.receiver || '.' || s.name ELSE s.name END,
+       f.path, s.line_start
+FROM edges e
+JOIN symbols s ON s.id = e.from_id
+JOIN files f ON f.id = s.file_id
+LEFT JOIN packages p ON p.id = f.package_id
+WHERE e.from_type = 'symbol' AND e.source = 'sync' AND e.to_type = 'symbol' AND e.to_ref = ?
+ORDER BY e.relation, f.path, s.line_start;
+`, sym.Package+"."+sym.Name)
+	if err != nil {
+		return nil, err
+	}
+	for i := range incoming {
+		if r, ok := reverseRelations[incoming[i].Relation]; ok {
+			incoming[i].Relation = r
+		}
+	}
+	return append(links, incoming...), nil
+}
+
+func (s *Service) queryCodeLinks(ctx context.Context, query string, args ...any) ([]CodeLink, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query code links: %w", err)
+	}
+	defer rows.Close()
+	var links []CodeLink
+	for rows.Next() {
+		var l CodeLink
+		if err := rows.Scan(&l.Relation, &l.Symbol, &l.FilePath, &l.Line); err != nil {
+			return nil, fmt.Errorf("scan code link: %w", err)
+		}
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate code links: %w", err)
+	}
+	return links, nil
+}
+
+func symbolLabel(sym Symbol) string {
+	if sym.Receiver == "" {
+		return sym.Name
+	}
+	return strings.TrimPrefix(sym.Receiver, "*") + "." + sym.Name
+}
+
 // ImportResult holds the path and optional name of an imported package.
 type ImportResult struct {
 	Path string `json:"path"`
@@ -478,7 +1843,7 @@ JOIN files f ON f.id = i.from_file_id
 JOIN packages p ON p.id = f.package_id
 LEFT JOIN packages p2 ON p2.id = i.to_package_id
 WHERE p.path = ?
-ORDER BY i.to_path;
+ORDER BY i.to_path, p2.name;
 `, pkgPath)
 	if err != nil {
 		return nil, fmt.Errorf("query imports of %s: %w", pkgPath, err)
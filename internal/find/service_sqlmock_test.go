@@ -57,8 +57,8 @@ func TestFindExactSuggestionsAndDepsErrors(t *testing.T) {
 	}
 
 	mock.ExpectQuery("SELECT s.id").WithArgs("A").WillReturnRows(
-		sqlmock.NewRows([]string{"id", "kind", "name", "signature", "body", "line_start", "line_end", "receiver", "path", "package"}).
-			AddRow(1, "func", "A", "", "", 1, 1, "", "f.go", "."),
+		sqlmock.NewRows([]string{"id", "kind", "name", "signature", "body", "line_start", "line_end", "receiver", "path", "package", "platform", "cgo", "unsafe", "generated", "language"}).
+			AddRow(1, "func", "A", "", "", 1, 1, "", "f.go", ".", "", 0, 0, 0, ""),
 	)
 	mock.ExpectQuery("SELECT DISTINCT s2.id").WithArgs(int64(1)).WillReturnError(errors.New("dep query fail"))
 	_, err = NewService(db).FindExact(context.Background(), "A")
@@ -222,9 +222,9 @@ func TestSuggestionsPass2SubstringFallback(t *testing.T) {
 	mock.ExpectQuery("SELECT DISTINCT name").WithArgs("XYZ%").WillReturnRows(
 		sqlmock.NewRows([]string{"name"}),
 	)
-	// Pass 2: substring returns results
-	mock.ExpectQuery("SELECT DISTINCT name").WithArgs("%XYZ%").WillReturnRows(
-		sqlmock.NewRows([]string{"name"}).AddRow("aXYZb"),
+	// Pass 2: folded scan of the names long enough to match returns results
+	mock.ExpectQuery("SELECT DISTINCT name").WithArgs(2, maxCandidateNames).WillReturnRows(
+		sqlmock.NewRows([]string{"name"}).AddRow("aXYZb").AddRow("unrelated"),
 	)
 
 	suggestions, err := svc.suggestions(context.Background(), "XYZ")
@@ -3,8 +3,13 @@ package find
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 	"testing"
 
@@ -28,11 +33,12 @@ func findTestDB(t *testing.T) (*sql.DB, func()) {
 	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (1,'.','main','example.com/recon',1,10,'x','x');`)
 	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (1,1,'main.go','go',10,'h','x','x');`)
 	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (2,1,'other.go','go',10,'h2','x','x');`)
-	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body,line_start,line_end,exported,receiver) VALUES (1,1,'func','Target','func()','func Target(){}',1,1,1,'');`)
-	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body,line_start,line_end,exported,receiver) VALUES (2,1,'func','Dep','func()','func Dep(){}',2,2,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbol_bodies(hash,body) VALUES ('h-target','func Target(){}'),('h-dep','func Dep(){}');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body_hash,line_start,line_end,exported,receiver) VALUES (1,1,'func','Target','func()','h-target',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body_hash,line_start,line_end,exported,receiver) VALUES (2,1,'func','Dep','func()','h-dep',2,2,1,'');`)
 	_, _ = conn.Exec(`INSERT INTO symbol_deps(symbol_id,dep_name) VALUES (1,'Dep');`)
-	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body,line_start,line_end,exported,receiver) VALUES (3,2,'func','Ambig','func()','func Ambig(){}',1,1,1,'');`)
-	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body,line_start,line_end,exported,receiver) VALUES (4,1,'method','Ambig','func()','func (t T) Ambig(){}',1,1,1,'T');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (3,2,'func','Ambig','func()',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (4,1,'method','Ambig','func()',1,1,1,'T');`)
 
 	return conn, func() { _ = conn.Close() }
 }
@@ -50,6 +56,70 @@ func TestFindExactSuccess(t *testing.T) {
 	}
 }
 
+func TestFindLoadsBodiesUnlessNoBody(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	res, err := NewService(conn).Find(ctx, "Target", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if res.Symbol.Body != "func Target(){}" || res.Dependencies[0].Body != "func Dep(){}" {
+		t.Fatalf("expected bodies loaded, got %q and %q", res.Symbol.Body, res.Dependencies[0].Body)
+	}
+
+	// With NoBody the lookup must not read symbol_bodies at all.
+	if _, err := conn.Exec(`DROP TABLE symbol_bodies;`); err != nil {
+		t.Fatalf("drop symbol_bodies: %v", err)
+	}
+	res, err = NewService(conn).Find(ctx, "Target", QueryOptions{NoBody: true})
+	if err != nil {
+		t.Fatalf("Find NoBody: %v", err)
+	}
+	if res.Symbol.Body != "" || res.Dependencies[0].Body != "" {
+		t.Fatalf("expected no bodies, got %+v", res)
+	}
+	if _, err := NewService(conn).Find(ctx, "Target", QueryOptions{}); err == nil || !strings.Contains(err.Error(), "query symbol bodies") {
+		t.Fatalf("expected body query error, got %v", err)
+	}
+}
+
+func TestFindTypeParams(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	for _, stmt := range []string{
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (10,1,'type','List','[T comparable, U any] struct{}',3,3,1,'');`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (11,1,'method','Len','func() int',4,4,1,'*List[K, _]');`,
+		`INSERT INTO type_params(symbol_id,position,name,constraint_expr) VALUES (10,0,'T','comparable'),(10,1,'U','any');`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	res, err := NewService(conn).Find(ctx, "List", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Find List: %v", err)
+	}
+	if got := fmt.Sprint(res.Symbol.TypeParams); got != "[{T comparable} {U any}]" {
+		t.Fatalf("List type params = %s", got)
+	}
+	res, err = NewService(conn).Find(ctx, "Len", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Find Len: %v", err)
+	}
+	if got := fmt.Sprint(res.Symbol.TypeParams); got != "[{K comparable} {U any}]" {
+		t.Fatalf("Len type params = %s", got)
+	}
+	res, err = NewService(conn).Find(ctx, "Target", QueryOptions{})
+	if err != nil || res.Symbol.TypeParams != nil {
+		t.Fatalf("expected no type params for Target, got %+v (%v)", res.Symbol.TypeParams, err)
+	}
+}
+
 func TestFindExactAmbiguousAndNotFound(t *testing.T) {
 	conn, cleanup := findTestDB(t)
 	defer cleanup()
@@ -172,7 +242,7 @@ func TestFindFileFilterSuffixMatch(t *testing.T) {
 	// Path with slash should do substring match
 	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (2,'pkg/sub','sub','example.com/recon/pkg/sub',1,5,'x','x');`)
 	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (3,2,'pkg/sub/service.go','go',5,'h3','x','x');`)
-	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body,line_start,line_end,exported,receiver) VALUES (5,3,'func','UniqueInSub','func()','func UniqueInSub(){}',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (5,3,'func','UniqueInSub','func()',1,1,1,'');`)
 
 	res, err = NewService(conn).Find(context.Background(), "UniqueInSub", QueryOptions{FilePath: "pkg/sub/service.go"})
 	if err != nil {
@@ -269,6 +339,49 @@ func TestListRespectsLimit(t *testing.T) {
 	}
 }
 
+func TestStreamPagesThroughList(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+	defer func(n int) { streamPageSize = n }(streamPageSize)
+	streamPageSize = 2
+
+	svc := NewService(conn)
+	list, err := svc.List(context.Background(), QueryOptions{PackagePath: "."}, 100)
+	if err != nil {
+		t.Fatalf("List error: %v", err)
+	}
+	for _, limit := range []int{0, 3} {
+		var streamed []Symbol
+		err := svc.Stream(context.Background(), QueryOptions{PackagePath: "."}, limit, func(sym Symbol) error {
+			streamed = append(streamed, sym)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Stream(limit %d) error: %v", limit, err)
+		}
+		want := list.Symbols
+		if limit > 0 {
+			want = want[:min(limit, len(want))]
+		}
+		if !reflect.DeepEqual(streamed, want) {
+			t.Fatalf("Stream(limit %d) = %+v, want %+v", limit, streamed, want)
+		}
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = svc.Stream(context.Background(), QueryOptions{PackagePath: "."}, 0, func(Symbol) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Fatalf("expected the callback error to stop the stream, got %v after %d calls", err, calls)
+	}
+	if err := svc.Stream(context.Background(), QueryOptions{}, 0, nil); err == nil {
+		t.Fatal("expected error for stream with no filters")
+	}
+}
+
 func TestFindReceiverDotSyntax(t *testing.T) {
 	conn, cleanup := findTestDB(t)
 	defer cleanup()
@@ -330,7 +443,7 @@ func TestListByFileWithSlash(t *testing.T) {
 	// Add a symbol in a subdirectory file
 	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (2,'pkg/sub','sub','example.com/recon/pkg/sub',1,5,'x','x');`)
 	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (3,2,'pkg/sub/service.go','go',5,'h3','x','x');`)
-	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body,line_start,line_end,exported,receiver) VALUES (5,3,'func','SubFunc','func()','func SubFunc(){}',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (5,3,'func','SubFunc','func()',1,1,1,'');`)
 
 	result, err := NewService(conn).List(context.Background(), QueryOptions{FilePath: "pkg/sub/service.go"}, 50)
 	if err != nil {
@@ -358,7 +471,7 @@ func TestFindWithShortPackageName(t *testing.T) {
 	// Add a symbol in a nested package
 	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (2,'internal/index','index','example.com/recon/internal/index',1,50,'x','x');`)
 	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (3,2,'internal/index/service.go','go',50,'h3','x','x');`)
-	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body,line_start,line_end,exported,receiver) VALUES (5,3,'func','NewService','func()','func NewService(){}',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (5,3,'func','NewService','func()',1,1,1,'');`)
 
 	// Short name should match
 	res, err := NewService(conn).Find(context.Background(), "NewService", QueryOptions{PackagePath: "index"})
@@ -377,7 +490,7 @@ func TestListWithShortPackageName(t *testing.T) {
 	// Add a symbol in a nested package
 	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (2,'internal/index','index','example.com/recon/internal/index',1,50,'x','x');`)
 	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (3,2,'internal/index/service.go','go',50,'h3','x','x');`)
-	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body,line_start,line_end,exported,receiver) VALUES (5,3,'func','NewService','func()','func NewService(){}',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (5,3,'func','NewService','func()',1,1,1,'');`)
 
 	// Short name should match in list mode
 	result, err := NewService(conn).List(context.Background(), QueryOptions{PackagePath: "index"}, 50)
@@ -443,7 +556,7 @@ func TestSuggestions_SubstringMatch(t *testing.T) {
 	defer cleanup()
 
 	// Add a symbol where the search term is in the MIDDLE, not a prefix
-	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body,line_start,line_end,exported,receiver) VALUES (10,1,'type','MyExitHandler','struct{}','type MyExitHandler struct{}',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (10,1,'type','MyExitHandler','struct{}',1,1,1,'');`)
 
 	svc := NewService(conn)
 	// "Exit" doesn't prefix-match MyExitHandler, should only match via substring
@@ -504,11 +617,11 @@ func TestListByFileSubstringMatch(t *testing.T) {
 
 	// Add files with "template" in various positions
 	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (10,1,'template.go','go',5,'h10','x','x');`)
-	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body,line_start,line_end,exported,receiver) VALUES (10,10,'func','TemplateFunc','func()','func TemplateFunc(){}',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (10,10,'func','TemplateFunc','func()',1,1,1,'');`)
 	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (11,1,'template_funcs.go','go',5,'h11','x','x');`)
-	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body,line_start,line_end,exported,receiver) VALUES (11,11,'func','TemplateFuncsHelper','func()','func TemplateFuncsHelper(){}',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (11,11,'func','TemplateFuncsHelper','func()',1,1,1,'');`)
 	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (12,1,'shortcode_template.go','go',5,'h12','x','x');`)
-	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body,line_start,line_end,exported,receiver) VALUES (12,12,'func','ShortcodeTemplate','func()','func ShortcodeTemplate(){}',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (12,12,'func','ShortcodeTemplate','func()',1,1,1,'');`)
 
 	// "template" should match all three files via substring
 	result, err := NewService(conn).List(context.Background(), QueryOptions{FilePath: "template"}, 50)
@@ -619,3 +732,840 @@ func TestImportsOf_NotFound(t *testing.T) {
 		t.Fatalf("expected empty slice for missing package, got %v", result)
 	}
 }
+
+func TestSuggestions_NonASCIICaseFolded(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (10,1,'type','ÜberHandler','struct{}',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (11,1,'func','newΣύνολο','func()',2,2,0,'');`)
+
+	svc := NewService(conn)
+	suggestions, err := svc.suggestions(context.Background(), "überhand")
+	if err != nil {
+		t.Fatalf("suggestions: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0] != "ÜberHandler" {
+		t.Fatalf("expected [ÜberHandler], got %v", suggestions)
+	}
+
+	suggestions, err = svc.suggestions(context.Background(), "ΣΎΝΟΛΟ")
+	if err != nil {
+		t.Fatalf("suggestions: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0] != "newΣύνολο" {
+		t.Fatalf("expected [newΣύνολο], got %v", suggestions)
+	}
+
+	// A decomposed accent (e and U+0301) matches the precomposed é, and the
+	// other way around.
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (12,1,'type','CaféStore','struct{}',3,3,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (13,1,'type','NaïveCache','struct{}',4,4,1,'');`)
+	for query, want := range map[string]string{"cafe\u0301st": "CaféStore", "NAÏVEC": "Nai\u0308veCache"} {
+		suggestions, err = svc.suggestions(context.Background(), query)
+		if err != nil {
+			t.Fatalf("suggestions(%q): %v", query, err)
+		}
+		if len(suggestions) != 1 || suggestions[0] != want {
+			t.Fatalf("suggestions(%q) = %q, want [%q]", query, suggestions, want)
+		}
+	}
+}
+
+func TestSuggestions_UnderscoreMatchesLiterally(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (10,1,'var','a_b','',1,1,0,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (11,1,'var','axb','',2,2,0,'');`)
+
+	suggestions, err := NewService(conn).suggestions(context.Background(), "a_")
+	if err != nil {
+		t.Fatalf("suggestions: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0] != "a_b" {
+		t.Fatalf("expected [a_b], got %v", suggestions)
+	}
+}
+
+func TestParseSymbolQuery(t *testing.T) {
+	cases := []struct {
+		in       string
+		want     SymbolQuery
+		wildcard bool
+	}{
+		{"Close", SymbolQuery{Name: "Close"}, false},
+		{"Service.Find", SymbolQuery{Receiver: "Service", Name: "Find"}, false},
+		{"*.Close", SymbolQuery{Receiver: "*", Name: "Close"}, true},
+		{"Service.*", SymbolQuery{Receiver: "Service", Name: "*"}, true},
+		{".Close", SymbolQuery{Name: ".Close"}, false},
+		{"New*Service", SymbolQuery{Name: "New*Service"}, true},
+		{"Get?", SymbolQuery{Name: "Get?"}, true},
+		{"*Service.Close", SymbolQuery{Receiver: "*Service", Name: "Close"}, false},
+		{"*Serv*.Close", SymbolQuery{Receiver: "*Serv*", Name: "Close"}, true},
+	}
+	for _, tc := range cases {
+		got := ParseSymbolQuery(tc.in)
+		if got != tc.want || got.HasWildcard() != tc.wildcard {
+			t.Fatalf("ParseSymbolQuery(%q) = %+v (wildcard=%v), want %+v (wildcard=%v)", tc.in, got, got.HasWildcard(), tc.want, tc.wildcard)
+		}
+	}
+}
+
+func TestListMatchesReceiverWildcards(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (10,1,'method','Close','func() error',3,3,1,'*Service');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (11,1,'method','Find','func()',4,4,1,'Service');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (12,2,'method','Close','func() error',5,5,1,'*Conn');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (13,2,'method','Len','func() int',6,6,1,'Service_x');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (14,2,'method','Get','func() T',7,7,1,'*Service[T]');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (15,2,'func','Close','func()',8,8,1,'');`)
+
+	svc := NewService(conn)
+
+	res, err := svc.ListMatches(context.Background(), "*.Close", QueryOptions{}, 0)
+	if err != nil {
+		t.Fatalf("ListMatches *.Close: %v", err)
+	}
+	if res.Total != 2 {
+		t.Fatalf("expected 2 Close methods, got %+v", res)
+	}
+	for _, sym := range res.Symbols {
+		if sym.Name != "Close" || sym.Receiver == "" {
+			t.Fatalf("unexpected symbol in *.Close: %+v", sym)
+		}
+	}
+
+	res, err = svc.ListMatches(context.Background(), "Service.*", QueryOptions{}, 0)
+	if err != nil {
+		t.Fatalf("ListMatches Service.*: %v", err)
+	}
+	if res.Total != 3 {
+		t.Fatalf("expected Service method set of 3 (value, pointer, generic), got %+v", res)
+	}
+	for _, sym := range res.Symbols {
+		if sym.Receiver == "Service_x" {
+			t.Fatalf("Service.* must not match Service_x: %+v", sym)
+		}
+	}
+
+	res, err = svc.ListMatches(context.Background(), "Service.*", QueryOptions{FilePath: "main.go"}, 0)
+	if err != nil {
+		t.Fatalf("ListMatches with filter: %v", err)
+	}
+	if res.Total != 2 {
+		t.Fatalf("expected 2 Service methods in main.go, got %+v", res)
+	}
+
+	res, err = svc.ListMatches(context.Background(), "Close", QueryOptions{}, 1)
+	if err != nil {
+		t.Fatalf("ListMatches plain name: %v", err)
+	}
+	if res.Total != 3 || len(res.Symbols) != 1 || res.Limit != 1 {
+		t.Fatalf("expected 3 Close symbols limited to 1, got %+v", res)
+	}
+
+	if _, err := svc.ListMatches(context.Background(), "*", QueryOptions{}, 0); err == nil {
+		t.Fatal("expected error for bare wildcard")
+	}
+}
+
+func TestFindTypeReportsPromotedMethods(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+
+	for _, q := range []string{
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (10,2,'type','Outer','struct{...}',1,5,1,'');`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (11,2,'type','Left','struct{...}',6,7,1,'');`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (12,2,'type','Right','struct{...}',8,9,1,'');`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (13,2,'type','Deep','struct{}',10,10,1,'');`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (20,2,'method','Own','func()',11,11,1,'*Outer');`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (21,2,'method','Own','func()',12,12,1,'Left');`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (22,2,'method','Read','func()',13,13,1,'*Left');`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (23,2,'method','Both','func()',14,14,1,'Left');`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (24,2,'method','Both','func()',15,15,1,'Right');`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (25,2,'method','Write','func()',16,16,1,'Deep');`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (26,2,'method','Read','func()',17,17,1,'Deep');`,
+		`INSERT INTO type_embeds(symbol_id,embedded_name,embedded_package,pointer) VALUES (10,'Left','.',0);`,
+		`INSERT INTO type_embeds(symbol_id,embedded_name,embedded_package,pointer) VALUES (10,'Right','.',1);`,
+		`INSERT INTO type_embeds(symbol_id,embedded_name,embedded_package,pointer) VALUES (12,'Deep','.',0);`,
+		`INSERT INTO type_embeds(symbol_id,embedded_name,embedded_package,pointer) VALUES (13,'Outer','.',0);`,
+	} {
+		if _, err := conn.Exec(q); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	res, err := NewService(conn).Find(context.Background(), "Outer", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(res.Embeds) != 2 || res.Embeds[0].Name != "Left" || !res.Embeds[1].Pointer {
+		t.Fatalf("unexpected embeds: %+v", res.Embeds)
+	}
+
+	// Own is shadowed by Outer's method, Both is ambiguous at depth 1, and
+	// Deep.Read is shadowed by the shallower Left.Read.
+	var got []string
+	for _, m := range res.PromotedMethods {
+		got = append(got, m.Name+" via "+m.Via)
+	}
+	want := []string{"Read via Left", "Write via Right.Deep"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("promoted = %v, want %v", got, want)
+	}
+}
+
+func TestListByPathPatterns(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+
+	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (2,'internal/store','store','example.com/recon/internal/store',1,5,'x','x');`)
+	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (3,'internal/store/testdata','testdata','example.com/recon/internal/store/testdata',1,5,'x','x');`)
+	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (4,'internal_tools','tools','example.com/recon/internal_tools',1,5,'x','x');`)
+	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (3,2,'internal/store/store.go','go',5,'h3','x','x');`)
+	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (4,3,'internal/store/testdata/fake.go','go',5,'h4','x','x');`)
+	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (5,4,'internal_tools/tool.go','go',5,'h5','x','x');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (5,3,'func','Ambig','func()',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (6,4,'func','Fake','func()',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (7,5,'func','Tool','func()',1,1,1,'');`)
+
+	svc := NewService(conn)
+	names := func(opts QueryOptions) []string {
+		t.Helper()
+		result, err := svc.List(context.Background(), opts, 50)
+		if err != nil {
+			t.Fatalf("List(%+v): %v", opts, err)
+		}
+		out := make([]string, 0, len(result.Symbols))
+		for _, s := range result.Symbols {
+			out = append(out, s.Package+":"+s.Name)
+		}
+		return out
+	}
+
+	got := names(QueryOptions{Paths: []string{"internal/..."}})
+	if strings.Join(got, ",") != "internal/store:Ambig,internal/store/testdata:Fake" {
+		t.Fatalf("unexpected internal/... symbols: %v", got)
+	}
+	got = names(QueryOptions{Paths: []string{"./internal/store/"}})
+	if strings.Join(got, ",") != "internal/store:Ambig" {
+		t.Fatalf("expected exact package match, got %v", got)
+	}
+	got = names(QueryOptions{Paths: []string{"internal/..."}, ExcludePaths: []string{"internal/store/testdata/..."}})
+	if strings.Join(got, ",") != "internal/store:Ambig" {
+		t.Fatalf("expected testdata excluded, got %v", got)
+	}
+	got = names(QueryOptions{ExcludePaths: []string{"internal/...", "internal_tools"}, Kind: "func"})
+	for _, name := range got {
+		if !strings.HasPrefix(name, ".:") {
+			t.Fatalf("expected only root package symbols, got %v", got)
+		}
+	}
+
+	// Exact lookup honours the same scoping, which resolves ambiguity.
+	result, err := svc.Find(context.Background(), "Ambig", QueryOptions{Paths: []string{"internal/..."}})
+	if err != nil {
+		t.Fatalf("Find with path scope: %v", err)
+	}
+	if result.Symbol.Package != "internal/store" {
+		t.Fatalf("expected internal/store Ambig, got %+v", result.Symbol)
+	}
+	_, err = svc.Find(context.Background(), "Tool", QueryOptions{ExcludePaths: []string{"internal_tools"}})
+	var notFound NotFoundError
+	if !errors.As(err, &notFound) || !notFound.Filtered {
+		t.Fatalf("expected filtered not-found for excluded symbol, got %v", err)
+	}
+}
+
+func TestNormalizePathPatterns(t *testing.T) {
+	got, err := NormalizePathPatterns([]string{" ./internal/... ", "internal/cli/", "./", "", "...", `internal\find`})
+	if err != nil {
+		t.Fatalf("NormalizePathPatterns: %v", err)
+	}
+	want := []string{"internal/...", "internal/cli", ".", "...", filepath.ToSlash(`internal\find`)}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("NormalizePathPatterns = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"internal/.../cli", "/abs/path"} {
+		if _, err := NormalizePathPatterns([]string{bad}); err == nil || !strings.Contains(err.Error(), "invalid path pattern") {
+			t.Fatalf("expected invalid pattern error for %q, got %v", bad, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		pkg, pattern string
+		want         bool
+	}{
+		{"internal/cli", "...", true},
+		{"internal/cli", "internal/...", true},
+		{"internal", "internal/...", true},
+		{"internal_tools", "internal/...", false},
+		{"internal/cli", "internal", false},
+		{".", ".", true},
+	} {
+		if got := matchPathPattern(tc.pkg, tc.pattern); got != tc.want {
+			t.Fatalf("matchPathPattern(%q, %q) = %v, want %v", tc.pkg, tc.pattern, got, tc.want)
+		}
+	}
+}
+
+func TestListOrderingTieBreakers(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+
+	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (2,'zeta','zeta','example.com/recon/zeta',1,10,'x','x');`)
+	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (3,'alpha','alpha','example.com/recon/alpha',1,10,'x','x');`)
+	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (3,3,'alpha/dup.go','go',10,'h3','x','x');`)
+	// Same package, file, kind and name, differing only by receiver: rows are
+	// inserted out of order on purpose.
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (10,3,'method','Dup','func()',8,8,1,'Z');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (11,3,'func','Dup','func()',9,9,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (12,3,'method','Dup','func()',7,7,1,'A');`)
+
+	svc := NewService(conn)
+	pkgs, err := svc.ListPackages(context.Background())
+	if err != nil {
+		t.Fatalf("ListPackages: %v", err)
+	}
+	var paths []string
+	for _, p := range pkgs {
+		paths = append(paths, p.Path)
+	}
+	if strings.Join(paths, ",") != ".,alpha,zeta" {
+		t.Fatalf("expected equal line counts ordered by path, got %v", paths)
+	}
+
+	want := "func::9,method:A:7,method:Z:8"
+	for i := 0; i < 3; i++ {
+		result, err := svc.List(context.Background(), QueryOptions{PackagePath: "alpha"}, 50)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		var got []string
+		for _, s := range result.Symbols {
+			got = append(got, fmt.Sprintf("%s:%s:%d", s.Kind, s.Receiver, s.LineStart))
+		}
+		if strings.Join(got, ",") != want {
+			t.Fatalf("List order = %v, want %s", got, want)
+		}
+	}
+
+	matches, err := svc.ListMatches(context.Background(), "Dup", QueryOptions{}, 50)
+	if err != nil {
+		t.Fatalf("ListMatches: %v", err)
+	}
+	var got []string
+	for _, s := range matches.Symbols {
+		got = append(got, fmt.Sprintf("%s:%s:%d", s.Kind, s.Receiver, s.LineStart))
+	}
+	if strings.Join(got, ",") != want {
+		t.Fatalf("ListMatches order = %v, want %s", got, want)
+	}
+}
+
+func TestCallersWalksReverseDeps(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+
+	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (2,'other','other','example.com/recon/other',1,10,'x','x');`)
+	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (3,2,'other/other.go','go',10,'h3','x','x');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (5,2,'func','Mid','func()',3,3,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (6,2,'method','Top','func()',5,5,1,'*T');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (7,2,'func','Leaf','func()',7,7,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (8,3,'func','Elsewhere','func()',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbol_deps(symbol_id,dep_name,dep_package,dep_kind) VALUES
+		(5,'Target','.','func'),
+		(6,'Target','.','func'),
+		(6,'Mid','.','func'),
+		(7,'Top','.','method'),
+		(1,'Target','.','func'),
+		(8,'Target','other','func');`)
+
+	svc := NewService(conn)
+	dep := Symbol{ID: 2, Kind: "func", Name: "Dep", Package: "."}
+
+	direct, err := svc.Callers(context.Background(), dep, 1)
+	if err != nil {
+		t.Fatalf("Callers depth 1: %v", err)
+	}
+	if len(direct) != 1 || direct[0].Name != "Target" || direct[0].Depth != 1 || direct[0].Calls != "Dep" {
+		t.Fatalf("unexpected direct callers: %+v", direct)
+	}
+
+	all, err := svc.Callers(context.Background(), dep, MaxCallerDepth)
+	if err != nil {
+		t.Fatalf("Callers transitive: %v", err)
+	}
+	var got []string
+	for _, c := range all {
+		got = append(got, fmt.Sprintf("%s@%d<-%s", c.Name, c.Depth, c.Calls))
+	}
+	want := "Target@1<-Dep,Mid@2<-Target,Top@2<-Target,Leaf@3<-T.Top"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("Callers = %v, want %s", got, want)
+	}
+
+	_ = conn.Close()
+	if _, err := svc.Callers(context.Background(), dep, 1); err == nil || !strings.Contains(err.Error(), "query callers") {
+		t.Fatalf("expected query callers error, got %v", err)
+	}
+}
+
+func TestTypeMembers(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (20,1,'type','Store','struct{}',5,8,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (21,2,'method','Save','func() error',3,3,1,'*Store');`)
+	_, _ = conn.Exec(`INSERT INTO struct_fields(symbol_id,position,name,type,tag,embedded,exported) VALUES
+		(20,1,'Name','string','json:"name"',0,1),
+		(20,0,'Mutex','sync.Mutex','',1,1);`)
+	_, _ = conn.Exec(`INSERT INTO type_methods(symbol_id,name,signature,pointer_receiver,method_symbol_id) VALUES
+		(20,'Save','func() error',1,21),
+		(20,'Close','func() error',0,NULL);`)
+
+	svc := NewService(conn)
+	fields, methods, err := svc.TypeMembers(context.Background(), 20)
+	if err != nil {
+		t.Fatalf("TypeMembers: %v", err)
+	}
+	if len(fields) != 2 || fields[0].Name != "Mutex" || !fields[0].Embedded || fields[1].Tag != `json:"name"` {
+		t.Fatalf("unexpected fields: %+v", fields)
+	}
+	if len(methods) != 2 || methods[0].Name != "Close" || methods[0].FilePath != "" ||
+		methods[1].Name != "Save" || !methods[1].PointerReceiver || methods[1].FilePath != "other.go" || methods[1].LineStart != 3 {
+		t.Fatalf("unexpected methods: %+v", methods)
+	}
+
+	fields, methods, err = svc.TypeMembers(context.Background(), 1)
+	if err != nil || len(fields) != 0 || len(methods) != 0 {
+		t.Fatalf("expected no members for a func, got %+v %+v err=%v", fields, methods, err)
+	}
+
+	_ = conn.Close()
+	if _, _, err := svc.TypeMembers(context.Background(), 20); err == nil || !strings.Contains(err.Error(), "query fields") {
+		t.Fatalf("expected query fields error, got %v", err)
+	}
+}
+
+func TestFindEnums(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (30,1,'type','Color','int',3,3,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (31,1,'enum','Color','Color',4,7,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (32,2,'enum','Color','Color',1,3,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO enum_members(symbol_id,position,name,value) VALUES
+		(31,1,'Green','1'),
+		(31,0,'Red','0'),
+		(32,0,'Blue','2');`)
+
+	svc := NewService(conn)
+	res, err := svc.Find(context.Background(), "Color", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Find(Color): %v", err)
+	}
+	var got []string
+	for _, m := range res.Symbol.Members {
+		got = append(got, m.Name+"="+m.Value)
+	}
+	if res.Symbol.Kind != "type" || strings.Join(got, ",") != "Red=0,Green=1,Blue=2" {
+		t.Fatalf("expected type with enum members, got %+v", res.Symbol)
+	}
+
+	res, err = svc.Find(context.Background(), "Color", QueryOptions{Kind: "enum", FilePath: "main.go"})
+	if err != nil || res.Symbol.ID != 31 || len(res.Symbol.Members) != 3 {
+		t.Fatalf("expected enum symbol with members, got %+v err=%v", res.Symbol, err)
+	}
+
+	list, err := svc.List(context.Background(), QueryOptions{Kind: "enum"}, 10)
+	if err != nil {
+		t.Fatalf("List(enum): %v", err)
+	}
+	if list.Total != 2 || len(list.Symbols[0].Members) != 3 {
+		t.Fatalf("unexpected enum list: %+v", list)
+	}
+
+	if kept := dropEnumsOfTypes([]Symbol{{Kind: "enum", Name: "Color", Package: "sub"}, {Kind: "type", Name: "Color", Package: "."}}); len(kept) != 2 {
+		t.Fatalf("expected enums of other packages to be kept, got %+v", kept)
+	}
+
+	_ = conn.Close()
+	if _, err := svc.enumMembers(context.Background(), ".", "Color"); err == nil || !strings.Contains(err.Error(), "query enum members") {
+		t.Fatalf("expected query enum members error, got %v", err)
+	}
+}
+
+func TestFindAndListPlatformVariants(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+	for _, stmt := range []string{
+		`INSERT INTO files(id,package_id,path,language,lines,hash,build_constraint,created_at,updated_at) VALUES
+			(3,1,'open_unix.go','go',5,'h3','unix','x','x'),
+			(4,1,'open_windows.go','go',5,'h4','windows','x','x'),
+			(5,1,'tty_linux.go','go',5,'h5','linux','x','x')`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES
+			(10,4,'func','Open','func() error',3,5,1,''),
+			(11,3,'func','Open','func() error',4,6,1,''),
+			(12,5,'func','Raw','func()',1,1,1,'')`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	svc := NewService(conn)
+
+	res, err := svc.FindExact(context.Background(), "Open")
+	if err != nil {
+		t.Fatalf("FindExact Open: %v", err)
+	}
+	if res.Symbol.FilePath != "open_unix.go" || res.Symbol.Platform != "unix" || len(res.Symbol.Variants) != 2 ||
+		res.Symbol.Variants[1].Platform != "windows" || res.Symbol.Variants[1].LineStart != 3 {
+		t.Fatalf("Open = %+v", res.Symbol)
+	}
+	// Selecting one variant by file still lists its siblings.
+	res, err = svc.Find(context.Background(), "Open", QueryOptions{FilePath: "open_windows.go"})
+	if err != nil || res.Symbol.Platform != "windows" || len(res.Symbol.Variants) != 2 {
+		t.Fatalf("Open --file = %+v, %v", res.Symbol, err)
+	}
+	res, err = svc.FindExact(context.Background(), "Raw")
+	if err != nil || res.Symbol.Platform != "linux" || res.Symbol.Variants != nil {
+		t.Fatalf("Raw = %+v, %v", res.Symbol, err)
+	}
+
+	list, err := svc.List(context.Background(), QueryOptions{PackagePath: "."}, 50)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var names []string
+	for _, sym := range list.Symbols {
+		names = append(names, sym.Name+"@"+sym.FilePath)
+	}
+	if list.Total != 6 || strings.Join(names, ",") != "Dep@main.go,Target@main.go,Ambig@main.go,Open@open_unix.go,Ambig@other.go,Raw@tty_linux.go" {
+		t.Fatalf("List = %d %v", list.Total, names)
+	}
+	if len(list.Symbols[3].Variants) != 2 {
+		t.Fatalf("listed Open variants = %+v", list.Symbols[3])
+	}
+
+	matches, err := svc.ListMatches(context.Background(), "Open", QueryOptions{}, 1)
+	if err != nil || matches.Total != 1 || len(matches.Symbols) != 1 {
+		t.Fatalf("ListMatches Open = %+v, %v", matches, err)
+	}
+}
+
+func TestFindAndListWithTags(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+	for _, stmt := range []string{
+		`INSERT INTO files(id,package_id,path,language,lines,hash,build_constraint,created_at,updated_at) VALUES
+			(3,1,'open_unix.go','go',5,'h3','unix','x','x'),
+			(4,1,'open_windows.go','go',5,'h4','windows','x','x'),
+			(5,1,'tty_linux.go','go',5,'h5','linux && !cgo','x','x')`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES
+			(10,4,'func','Open','func() error',3,5,1,''),
+			(11,3,'func','Open','func() error',4,6,1,''),
+			(12,5,'func','Raw','func()',1,1,1,'')`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	svc := NewService(conn)
+	ctx := context.Background()
+
+	res, err := svc.Find(ctx, "Open", QueryOptions{Tags: []string{"windows,amd64"}})
+	if err != nil || res.Symbol.FilePath != "open_windows.go" || len(res.Symbol.Variants) != 2 {
+		t.Fatalf("Open --tags windows = %+v, %v", res.Symbol, err)
+	}
+	if _, err := svc.Find(ctx, "Raw", QueryOptions{Tags: []string{"linux", "cgo"}}); !errors.As(err, new(NotFoundError)) {
+		t.Fatalf("Raw --tags linux,cgo should be filtered out, got %v", err)
+	}
+	if res, err := svc.Find(ctx, "Target", QueryOptions{Tags: []string{"plan9"}}); err != nil || res.Symbol.Name != "Target" {
+		t.Fatalf("unconstrained Target --tags plan9 = %+v, %v", res.Symbol, err)
+	}
+
+	list, err := svc.List(ctx, QueryOptions{Tags: []string{"linux"}}, 50)
+	if err != nil {
+		t.Fatalf("List --tags linux: %v", err)
+	}
+	var names []string
+	for _, sym := range list.Symbols {
+		names = append(names, sym.Name+"@"+sym.FilePath)
+	}
+	if list.Total != 6 || strings.Join(names, ",") != "Dep@main.go,Target@main.go,Ambig@main.go,Open@open_unix.go,Ambig@other.go,Raw@tty_linux.go" {
+		t.Fatalf("List --tags linux = %d %v", list.Total, names)
+	}
+	matches, err := svc.ListMatches(ctx, "Open", QueryOptions{Tags: []string{"windows"}}, 10)
+	if err != nil || len(matches.Symbols) != 1 || matches.Symbols[0].FilePath != "open_windows.go" {
+		t.Fatalf("ListMatches Open --tags windows = %+v, %v", matches, err)
+	}
+	re := regexp.MustCompile("^(Open|Raw)$")
+	regexMatches, err := svc.ListRegex(ctx, re, QueryOptions{Tags: []string{"js", "wasm"}}, 10)
+	if err != nil || regexMatches.Total != 0 {
+		t.Fatalf("ListRegex --tags js,wasm = %+v, %v", regexMatches, err)
+	}
+}
+
+func TestFindAndListUnsafe(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+	if _, err := conn.Exec(`UPDATE files SET unsafe = 1 WHERE path = 'other.go'`); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	svc := NewService(conn)
+	ctx := context.Background()
+
+	res, err := svc.Find(ctx, "Ambig", QueryOptions{Unsafe: true})
+	if err != nil || res.Symbol.FilePath != "other.go" || !res.Symbol.Unsafe || res.Symbol.Cgo {
+		t.Fatalf("Ambig --unsafe = %+v, %v", res.Symbol, err)
+	}
+	if _, err := svc.Find(ctx, "Target", QueryOptions{Unsafe: true}); !errors.As(err, new(NotFoundError)) {
+		t.Fatalf("Target --unsafe should be filtered out, got %v", err)
+	}
+	list, err := svc.List(ctx, QueryOptions{Unsafe: true}, 50)
+	if err != nil || list.Total != 1 || list.Symbols[0].Name != "Ambig" || !list.Symbols[0].Unsafe {
+		t.Fatalf("List --unsafe = %+v, %v", list, err)
+	}
+}
+
+func TestFindAndListGenerated(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+	for _, q := range []string{
+		`UPDATE files SET generated = 1 WHERE path = 'other.go'`,
+		`INSERT INTO files(id,package_id,path,language,lines,hash,generated,created_at,updated_at) VALUES (3,1,'zz_gen.go','go',10,'h3',1,'x','x')`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (5,3,'func','Gen','func()',1,1,1,'')`,
+	} {
+		if _, err := conn.Exec(q); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	svc := NewService(conn)
+	ctx := context.Background()
+
+	res, err := svc.Find(ctx, "Ambig", QueryOptions{NoBody: true})
+	if err != nil || res.Symbol.Receiver != "T" || res.Symbol.Generated {
+		t.Fatalf("Ambig should prefer the hand-written method, got %+v, %v", res.Symbol, err)
+	}
+	if _, err := svc.Find(ctx, "Ambig", QueryOptions{NoBody: true, IncludeGenerated: true}); !errors.As(err, new(AmbiguousError)) {
+		t.Fatalf("Ambig with IncludeGenerated should be ambiguous, got %v", err)
+	}
+	res, err = svc.Find(ctx, "Gen", QueryOptions{NoBody: true})
+	if err != nil || !res.Symbol.Generated {
+		t.Fatalf("Gen should resolve to the generated symbol, got %+v, %v", res.Symbol, err)
+	}
+
+	list, err := svc.List(ctx, QueryOptions{Kind: "func"}, 50)
+	if err != nil || list.Total != 2 {
+		t.Fatalf("List funcs = %+v, %v; want Target and Dep only", list, err)
+	}
+	list, err = svc.List(ctx, QueryOptions{Kind: "func", IncludeGenerated: true}, 50)
+	if err != nil || list.Total != 4 {
+		t.Fatalf("List funcs with IncludeGenerated = %+v, %v; want 4", list, err)
+	}
+}
+
+func TestMatchTags(t *testing.T) {
+	tests := []struct {
+		expr string
+		tags []string
+		want bool
+	}{
+		{"", []string{"windows"}, true},
+		{"linux", nil, true},
+		{"linux", []string{"linux"}, true},
+		{"linux", []string{"windows"}, false},
+		{"unix", []string{"darwin"}, true},
+		{"unix", []string{"windows"}, false},
+		{"linux && amd64", []string{"linux", "arm64"}, false},
+		{"linux && !cgo", []string{"linux"}, true},
+		{"integration", []string{"integration"}, true},
+		{"((", []string{"linux"}, true},
+	}
+	for _, tt := range tests {
+		if got := MatchTags(tt.expr, tt.tags); got != tt.want {
+			t.Errorf("MatchTags(%q, %v) = %v, want %v", tt.expr, tt.tags, got, tt.want)
+		}
+	}
+	if got := NormalizeTags([]string{"linux,amd64", " cgo linux", ""}); !reflect.DeepEqual(got, []string{"linux", "amd64", "cgo"}) {
+		t.Fatalf("NormalizeTags = %v", got)
+	}
+}
+
+func TestFindFuzzy(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (10,1,'func','ProposeAndVerifyDecision','func()',3,3,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (11,1,'method','Archive','func()',4,4,1,'Service');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (12,1,'func','ListSymbols','func()',5,5,1,''),(13,1,'func','ListSymbol','func()',6,6,1,'');`)
+	svc := NewService(conn)
+	ctx := context.Background()
+
+	if _, err := svc.Find(ctx, "propseAndVerify", QueryOptions{}); !errors.As(err, new(NotFoundError)) {
+		t.Fatalf("expected not found without Fuzzy, got %v", err)
+	}
+	for query, want := range map[string]string{
+		"propseAndVerify": "ProposeAndVerifyDecision",
+		"target":          "Target",
+		"Service.archve":  "Archive",
+	} {
+		res, err := svc.Find(ctx, query, QueryOptions{Fuzzy: true})
+		if err != nil {
+			t.Fatalf("Find(%q, Fuzzy): %v", query, err)
+		}
+		if res.Symbol.Name != want || res.FuzzyQuery != query {
+			t.Fatalf("Find(%q, Fuzzy) = %s (fuzzy_query %q), want %s", query, res.Symbol.Name, res.FuzzyQuery, want)
+		}
+	}
+	res, err := svc.Find(ctx, "Target", QueryOptions{Fuzzy: true})
+	if err != nil || res.FuzzyQuery != "" {
+		t.Fatalf("exact match with Fuzzy = %+v, %v", res, err)
+	}
+
+	// ListSymbol and ListSymbols are equally close, so neither is picked.
+	var notFound NotFoundError
+	if _, err := svc.Find(ctx, "lstSymbol", QueryOptions{Fuzzy: true}); !errors.As(err, &notFound) {
+		t.Fatalf("expected not found for a tie, got %v", err)
+	}
+	if len(notFound.Suggestions) != 2 || notFound.Suggestions[0] != "ListSymbol" || notFound.Suggestions[1] != "ListSymbols" {
+		t.Fatalf("suggestions = %v, want [ListSymbol ListSymbols]", notFound.Suggestions)
+	}
+}
+
+func TestSubstringDistance(t *testing.T) {
+	for _, tc := range []struct {
+		needle, haystack string
+		want             int
+	}{
+		{"abc", "abc", 0},
+		{"bc", "abcd", 0},
+		{"propseandverify", "proposeandverifydecision", 1},
+		{"xyz", "unrelated", 3},
+		{"abc", "", 3},
+	} {
+		if got := substringDistance([]rune(tc.needle), []rune(tc.haystack)); got != tc.want {
+			t.Fatalf("substringDistance(%q, %q) = %d, want %d", tc.needle, tc.haystack, got, tc.want)
+		}
+	}
+}
+
+func TestListMatchesGlobs(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (10,1,'func','NewService','func()',3,3,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (11,1,'func','NewOrientService','func()',4,4,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (12,1,'func','NewServer','func()',5,5,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (13,1,'method','Get','func()',6,6,1,'*UserHandler');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (14,2,'method','Get','func()',7,7,1,'Cache[K]');`)
+	svc := NewService(conn)
+	ctx := context.Background()
+
+	names := func(res ListResult) string {
+		var out []string
+		for _, sym := range res.Symbols {
+			out = append(out, sym.Receiver+"."+sym.Name)
+		}
+		sort.Strings(out)
+		return strings.Join(out, " ")
+	}
+	for query, want := range map[string]string{
+		"New*Service": ".NewOrientService .NewService",
+		"NewServ??":   ".NewServer",
+		"User*.Get":   "*UserHandler.Get",
+		"Cach?.*":     "Cache[K].Get",
+		"newservice*": "",
+	} {
+		res, err := svc.ListMatches(ctx, query, QueryOptions{}, 0)
+		if err != nil {
+			t.Fatalf("ListMatches(%q): %v", query, err)
+		}
+		if got := names(res); got != want {
+			t.Fatalf("ListMatches(%q) = %q, want %q", query, got, want)
+		}
+	}
+
+	res, err := svc.ListRegex(ctx, regexp.MustCompile(`^New.*Serv`), QueryOptions{}, 0)
+	if err != nil {
+		t.Fatalf("ListRegex: %v", err)
+	}
+	if got := names(res); got != ".NewOrientService .NewServer .NewService" {
+		t.Fatalf("ListRegex = %q", got)
+	}
+	res, err = svc.ListRegex(ctx, regexp.MustCompile(`^Get$`), QueryOptions{FilePath: "other.go"}, 0)
+	if err != nil || names(res) != "Cache[K].Get" {
+		t.Fatalf("ListRegex with filter = %q, %v", names(res), err)
+	}
+	res, err = svc.ListRegex(ctx, regexp.MustCompile(`^Nothing`), QueryOptions{}, 0)
+	if err != nil || res.Total != 0 {
+		t.Fatalf("ListRegex without matches = %+v, %v", res, err)
+	}
+}
+
+func TestUsages(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+	_, _ = conn.Exec(`INSERT INTO symbol_bodies(hash,body) VALUES ('h-caller','func (s *Svc) Run() {
+	Dep()
+	if ok {
+		Dep()
+	}
+}');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body_hash,line_start,line_end,exported,receiver) VALUES (20,2,'method','Run','func()','h-caller',10,15,1,'*Svc');`)
+	_, _ = conn.Exec(`INSERT INTO symbol_deps(symbol_id,dep_name,dep_package,dep_kind,lines) VALUES (20,'Dep','.','func','11,13');`)
+
+	svc := NewService(conn)
+	dep, err := svc.FindExact(context.Background(), "Dep")
+	if err != nil {
+		t.Fatalf("FindExact Dep: %v", err)
+	}
+	usages, err := svc.Usages(context.Background(), dep.Symbol)
+	if err != nil {
+		t.Fatalf("Usages: %v", err)
+	}
+	want := []Usage{
+		{Caller: "Target", CallerKind: "func", FilePath: "main.go", Package: ".", Line: 0},
+		{Caller: "Svc.Run", CallerKind: "method", FilePath: "other.go", Package: ".", Line: 11, Code: "Dep()"},
+		{Caller: "Svc.Run", CallerKind: "method", FilePath: "other.go", Package: ".", Line: 13, Code: "Dep()"},
+	}
+	if !reflect.DeepEqual(usages, want) {
+		t.Fatalf("Usages = %+v, want %+v", usages, want)
+	}
+
+	target, err := svc.FindExact(context.Background(), "Target")
+	if err != nil {
+		t.Fatalf("FindExact Target: %v", err)
+	}
+	if usages, err := svc.Usages(context.Background(), target.Symbol); err != nil || len(usages) != 0 {
+		t.Fatalf("Usages of an uncalled symbol = %+v, %v", usages, err)
+	}
+}
+
+func TestAssets(t *testing.T) {
+	conn, cleanup := findTestDB(t)
+	defer cleanup()
+	_, _ = conn.Exec(`INSERT INTO file_embeds(file_id,var_name,pattern,line,paths) VALUES
+(2,'static','static/*',8,'["static/a.css","static/b.js"]'),
+(1,'schema','schema.sql',4,'[]');`)
+
+	svc := NewService(conn)
+	assets, err := svc.Assets(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Assets: %v", err)
+	}
+	want := []Asset{
+		{Var: "schema", Pattern: "schema.sql", FilePath: "main.go", Line: 4, Files: []string{}},
+		{Var: "static", Pattern: "static/*", FilePath: "other.go", Line: 8, Files: []string{"static/a.css", "static/b.js"}},
+	}
+	if !reflect.DeepEqual(assets, want) {
+		t.Fatalf("Assets = %+v, want %+v", assets, want)
+	}
+
+	if assets, err := svc.Assets(context.Background(), "internal/none"); err != nil || len(assets) != 0 {
+		t.Fatalf("Assets of unknown package = %+v, %v; want none", assets, err)
+	}
+}
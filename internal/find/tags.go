@@ -0,0 +1,96 @@
+package find
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/build/constraint"
+	"strings"
+)
+
+// unixOS lists the GOOS values that satisfy the "unix" build tag.
+var unixOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+	"hurd": true, "illumos": true, "ios": true, "linux": true, "netbsd": true,
+	"openbsd": true, "solaris": true,
+}
+
+// NormalizeTags splits comma- or space-separated build tags, as accepted by
+// `go build -tags`, and drops empty and duplicate entries.
+func NormalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, raw := range tags {
+		for _, tag := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ' ' }) {
+			if !seen[tag] {
+				seen[tag] = true
+				out = append(out, tag)
+			}
+		}
+	}
+	return out
+}
+
+// MatchTags reports whether a file compiled under expr, a build constraint
+// as stored in files.build_constraint, is built when exactly tags are set.
+// Unconstrained files always match, and so do constraints that no longer
+// parse, so a filter never hides code it cannot judge.
+func MatchTags(expr string, tags []string) bool {
+	if expr == "" || len(tags) == 0 {
+		return true
+	}
+	x, err := constraint.Parse("//go:build " + expr)
+	if err != nil {
+		return true
+	}
+	set := map[string]bool{}
+	for _, tag := range tags {
+		set[tag] = true
+		if unixOS[tag] {
+			set["unix"] = true
+		}
+	}
+	return x.Eval(func(tag string) bool { return set[tag] })
+}
+
+// tagsClause restricts list queries to symbols in files built with tags. The
+// indexed constraints are evaluated here, since SQLite cannot.
+func (s *Service) tagsClause(ctx context.Context, tags []string) (string, []any, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT build_constraint FROM files WHERE COALESCE(build_constraint, '') != '';`)
+	if err != nil {
+		return "", nil, fmt.Errorf("query build constraints: %w", err)
+	}
+	defer rows.Close()
+
+	built := []string{}
+	for rows.Next() {
+		var expr string
+		if err := rows.Scan(&expr); err != nil {
+			return "", nil, fmt.Errorf("scan build constraint: %w", err)
+		}
+		if MatchTags(expr, tags) {
+			built = append(built, expr)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", nil, fmt.Errorf("iterate build constraints: %w", err)
+	}
+	raw, err := json.Marshal(built)
+	if err != nil {
+		return "", nil, fmt.Errorf("encode build constraints: %w", err)
+	}
+	return "(COALESCE(f.build_constraint, '') = '' OR f.build_constraint IN (SELECT value FROM json_each(?)))", []any{string(raw)}, nil
+}
+
+// listWhere is buildListWhere plus the tags filter, which needs the index.
+func (s *Service) listWhere(ctx context.Context, opts QueryOptions) (string, []any, error) {
+	where, args := buildListWhere(opts)
+	if len(opts.Tags) == 0 {
+		return where, args, nil
+	}
+	clause, tagArgs, err := s.tagsClause(ctx, opts.Tags)
+	if err != nil {
+		return "", nil, err
+	}
+	return where + " AND " + clause, append(args, tagArgs...), nil
+}
@@ -0,0 +1,146 @@
+package guard
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Finding is an active decision, pattern, or constraint linked to the guarded
+// file by an edge to the file itself, its package, or a symbol declared in it.
+// AntiPattern is set for patterns linked by a "contradicts" edge. Anti-patterns
+// and constraints are reported whatever their confidence.
+type Finding struct {
+	EntityType  string `json:"entity_type"`
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Confidence  string `json:"confidence"`
+	Relation    string `json:"relation"`
+	Via         string `json:"via"`
+	Drift       string `json:"drift_status"`
+	AntiPattern bool   `json:"anti_pattern"`
+}
+
+// Result is the knowledge guarding one module-relative file path.
+type Result struct {
+	Path     string    `json:"path"`
+	Package  string    `json:"package"`
+	Findings []Finding `json:"findings"`
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+var confidenceRank = map[string]int{"low": 1, "medium": 2, "high": 3}
+
+// ValidConfidence reports whether c is a confidence level.
+func ValidConfidence(c string) bool {
+	return confidenceRank[c] > 0
+}
+
+// Check returns the active decisions and patterns at or above minConfidence
+// that are linked to rel, plus any constraints and anti-patterns linked to it. An entity
+// linked several ways is reported once, by its most specific link (file,
+// then symbol, then package).
+func (s *Service) Check(ctx context.Context, rel, minConfidence string) (Result, error) {
+	pkg := path.Dir(rel)
+	result := Result{Path: rel, Package: pkg, Findings: []Finding{}}
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT e.from_type, e.from_id, COALESCE(d.title, p.title, c.title), COALESCE(d.confidence, p.confidence, c.confidence),
+       e.relation, e.to_type, e.to_ref,
+       COALESCE((SELECT ev.drift_status FROM entity_evidence ev
+                 WHERE ev.entity_type = e.from_type AND ev.entity_id = e.from_id
+                 ORDER BY ev.id DESC LIMIT 1), 'ok')
+FROM edges e
+LEFT JOIN decisions d ON e.from_type = 'decision' AND d.id = e.from_id
+LEFT JOIN patterns p ON e.from_type = 'pattern' AND p.id = e.from_id
+LEFT JOIN constraints c ON e.from_type = 'constraint' AND c.id = e.from_id
+WHERE COALESCE(d.status, p.status, c.status) = 'active'
+  AND (
+      (e.to_type = 'file' AND e.to_ref = ?1)
+   OR (e.to_type = 'package' AND e.to_ref = ?2)
+   OR (e.to_type = 'symbol' AND e.to_ref IN (
+          SELECT pk.path || '.' || sy.name
+          FROM symbols sy
+          JOIN files f ON f.id = sy.file_id
+          JOIN packages pk ON pk.id = f.package_id
+          WHERE f.path = ?1))
+  )
+ORDER BY e.id;
+`, rel, pkg)
+	if err != nil {
+		return Result{}, fmt.Errorf("query guarded knowledge: %w", err)
+	}
+	defer rows.Close()
+
+	linkRank := map[string]int{"file": 0, "symbol": 1, "package": 2}
+	best := map[string]Finding{}
+	var order []string
+	for rows.Next() {
+		var (
+			f             Finding
+			toType, toRef string
+		)
+		if err := rows.Scan(&f.EntityType, &f.ID, &f.Title, &f.Confidence, &f.Relation, &toType, &toRef, &f.Drift); err != nil {
+			return Result{}, fmt.Errorf("scan guarded knowledge: %w", err)
+		}
+		f.Via = toType + ":" + toRef
+		f.AntiPattern = f.EntityType == "pattern" && f.Relation == "contradicts"
+		if !f.AntiPattern && f.EntityType != "constraint" && confidenceRank[f.Confidence] < confidenceRank[minConfidence] {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%d", f.EntityType, f.ID)
+		prev, seen := best[key]
+		if !seen {
+			order = append(order, key)
+		}
+		if !seen || f.AntiPattern && !prev.AntiPattern ||
+			f.AntiPattern == prev.AntiPattern && linkRank[toType] < linkRank[viaType(prev.Via)] {
+			best[key] = f
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return Result{}, fmt.Errorf("iterate guarded knowledge: %w", err)
+	}
+
+	for _, key := range order {
+		result.Findings = append(result.Findings, best[key])
+	}
+	// Constraints first, then anti-patterns, then decisions before patterns,
+	// by ID.
+	sort.SliceStable(result.Findings, func(i, j int) bool {
+		a, b := result.Findings[i], result.Findings[j]
+		if ra, rb := findingRank(a), findingRank(b); ra != rb {
+			return ra < rb
+		}
+		return a.ID < b.ID
+	})
+	return result, nil
+}
+
+func findingRank(f Finding) int {
+	switch {
+	case f.EntityType == "constraint":
+		return 0
+	case f.AntiPattern:
+		return 1
+	case f.EntityType == "decision":
+		return 2
+	}
+	return 3
+}
+
+func viaType(via string) string {
+	typ, _, _ := strings.Cut(via, ":")
+	return typ
+}
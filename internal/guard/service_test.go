@@ -0,0 +1,136 @@
+package guard
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func guardTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	root := t.TempDir()
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	for _, stmt := range []string{
+		`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES
+			(1,'internal/db','db','example.com/m/internal/db',2,20,'x','x')`,
+		`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES
+			(1,1,'internal/db/db.go','go',10,'h','x','x'),
+			(2,1,'internal/db/other.go','go',10,'h','x','x')`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES
+			(1,1,'func','Open','func Open()',1,1,1,''),
+			(2,2,'func','Close','func Close()',1,1,1,'')`,
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+			(1,'Use SQLite','r','high','active','x','x'),
+			(2,'Single writer','r','medium','active','x','x'),
+			(3,'Old store','r','high','archived','x','x'),
+			(4,'Close carefully','r','high','active','x','x')`,
+		`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES
+			(1,'Global state','d','low','active','x','x'),
+			(2,'Wrap errors','d','high','active','x','x')`,
+		`INSERT INTO evidence(entity_type,entity_id,summary,drift_status) VALUES ('decision',1,'s','drifting')`,
+		`INSERT INTO edges(from_type,from_id,to_type,to_ref,relation,source,confidence,created_at) VALUES
+			('decision',1,'package','internal/db','affects','manual','high','x'),
+			('decision',1,'file','internal/db/db.go','affects','manual','high','x'),
+			('decision',2,'file','internal/db/db.go','affects','manual','high','x'),
+			('decision',3,'file','internal/db/db.go','affects','manual','high','x'),
+			('decision',4,'symbol','internal/db.Close','affects','manual','high','x'),
+			('pattern',1,'symbol','internal/db.Open','contradicts','manual','high','x'),
+			('pattern',2,'package','internal/db','reinforces','manual','high','x')`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	return conn
+}
+
+func TestCheck(t *testing.T) {
+	svc := NewService(guardTestDB(t))
+	ctx := context.Background()
+
+	format := func(r Result) string {
+		var parts []string
+		for _, f := range r.Findings {
+			parts = append(parts, fmt.Sprintf("%s#%d %s %s anti=%t drift=%s", f.EntityType, f.ID, f.Confidence, f.Via, f.AntiPattern, f.Drift))
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	res, err := svc.Check(ctx, "internal/db/db.go", "high")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	want := "pattern#1 low symbol:internal/db.Open anti=true drift=ok, " +
+		"decision#1 high file:internal/db/db.go anti=false drift=drifting, " +
+		"pattern#2 high package:internal/db anti=false drift=ok"
+	if res.Package != "internal/db" || format(res) != want {
+		t.Fatalf("Check = %s (package %s)\nwant %s", format(res), res.Package, want)
+	}
+
+	res, err = svc.Check(ctx, "internal/db/db.go", "medium")
+	if err != nil || !strings.Contains(format(res), "decision#2 medium") {
+		t.Fatalf("Check medium = %s, %v", format(res), err)
+	}
+
+	res, err = svc.Check(ctx, "internal/db/other.go", "high")
+	if err != nil || !strings.Contains(format(res), "decision#4 high symbol:internal/db.Close") || strings.Contains(format(res), "pattern#1") {
+		t.Fatalf("Check other.go = %s, %v", format(res), err)
+	}
+
+	res, err = svc.Check(ctx, "cmd/main.go", "low")
+	if err != nil || len(res.Findings) != 0 || res.Package != "cmd" {
+		t.Fatalf("Check unrelated = %+v, %v", res, err)
+	}
+}
+
+func TestCheckReportsConstraintsFirst(t *testing.T) {
+	conn := guardTestDB(t)
+	if _, err := conn.Exec(`
+INSERT INTO constraints(id,title,reasoning,confidence,status,created_at,updated_at) VALUES (1,'No globals','r','low','active','x','x');
+INSERT INTO edges(from_type,from_id,to_type,to_ref,relation,source,confidence,created_at) VALUES
+  ('constraint',1,'package','internal/db','affects','manual','high','x');`); err != nil {
+		t.Fatalf("seed constraint: %v", err)
+	}
+
+	// A constraint is reported above the minimum confidence filter and ahead
+	// of anti-patterns.
+	res, err := NewService(conn).Check(context.Background(), "internal/db/db.go", "high")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(res.Findings) != 4 || res.Findings[0].EntityType != "constraint" || res.Findings[0].Title != "No globals" || !res.Findings[1].AntiPattern {
+		t.Fatalf("findings = %+v", res.Findings)
+	}
+}
+
+func TestCheckQueryError(t *testing.T) {
+	conn := guardTestDB(t)
+	if _, err := conn.Exec(`DROP TABLE edges`); err != nil {
+		t.Fatalf("drop edges: %v", err)
+	}
+	if _, err := NewService(conn).Check(context.Background(), "a.go", "high"); err == nil || !strings.Contains(err.Error(), "query guarded knowledge") {
+		t.Fatalf("expected query error, got %v", err)
+	}
+}
+
+func TestValidConfidence(t *testing.T) {
+	for c, want := range map[string]bool{"low": true, "medium": true, "high": true, "": false, "HIGH": false} {
+		if ValidConfidence(c) != want {
+			t.Fatalf("ValidConfidence(%q) != %t", c, want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package history reads the git history of a symbol's lines with
+// `git log -L`: when they were introduced, when they last changed in more
+// than whitespace, and every commit in between.
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/robertguss/recon/internal/index"
+)
+
+// Commit is a commit that changed a symbol's lines. LinesAdded and
+// LinesDeleted count the changed lines within the symbol's range.
+type Commit struct {
+	Hash         string `json:"hash"`
+	Author       string `json:"author"`
+	Email        string `json:"email"`
+	Date         string `json:"date"`
+	Subject      string `json:"subject"`
+	LinesAdded   int    `json:"lines_added"`
+	LinesDeleted int    `json:"lines_deleted"`
+	// Cosmetic is true when the commit changed only whitespace in the
+	// symbol, as gofmt does.
+	Cosmetic bool `json:"cosmetic,omitempty"`
+}
+
+// History is the git history of a symbol's lines. Commits are newest first,
+// at most the limit Log was given; Total counts them all. Introduced and
+// LastChanged are nil when no commit touches the lines yet.
+type History struct {
+	Introduced  *Commit  `json:"introduced,omitempty"`
+	LastChanged *Commit  `json:"last_changed,omitempty"`
+	Commits     []Commit `json:"commits"`
+	Total       int      `json:"total"`
+	// Uncommitted is true when the file is not in HEAD, so it has no
+	// history yet.
+	Uncommitted bool `json:"uncommitted,omitempty"`
+}
+
+// The --format header that starts each commit in git's output: a NUL, then
+// its fields separated by unit separators. git expands the %x escapes, as
+// arguments can't hold a NUL.
+const (
+	recordSep = "\x00"
+	fieldSep  = "\x1f"
+	logFormat = "--format=%x00%H%x1f%an%x1f%ae%x1f%aI%x1f%s"
+)
+
+// gitLog runs git log in moduleRoot; a package-level var for testability.
+var gitLog = func(ctx context.Context, moduleRoot string, args ...string) ([]byte, error) {
+	return index.GitOutput(ctx, moduleRoot, append([]string{"log", "--no-color", "--no-ext-diff"}, args...)...)
+}
+
+// Log returns the history of lines start through end of file, a
+// module-relative slash path, as of HEAD. limit caps Commits; zero keeps
+// every commit.
+func Log(ctx context.Context, moduleRoot, file string, start, end, limit int) (History, error) {
+	out, err := gitLog(ctx, moduleRoot, lineRange(file, start, end), logFormat)
+	if err != nil {
+		return historyError(err)
+	}
+	commits := parseLog(string(out), true)
+	h := History{Commits: commits, Total: len(commits)}
+	if len(commits) > 0 {
+		introduced := commits[len(commits)-1]
+		h.Introduced = &introduced
+	}
+	for _, c := range commits {
+		if !c.Cosmetic {
+			last := c
+			h.LastChanged = &last
+			break
+		}
+	}
+	if limit > 0 && len(h.Commits) > limit {
+		h.Commits = h.Commits[:limit]
+	}
+	return h, nil
+}
+
+// LastTouch returns the newest commit that changed lines start through end
+// of file, whitespace-only changes included, or nil when none has. It skips
+// the diff, so it is cheap enough for every `find --history`.
+func LastTouch(ctx context.Context, moduleRoot, file string, start, end int) (*Commit, error) {
+	out, err := gitLog(ctx, moduleRoot, "-n", "1", "-s", lineRange(file, start, end), logFormat)
+	if err != nil {
+		// An uncommitted file has no last touch, and no error.
+		_, err := historyError(err)
+		return nil, err
+	}
+	commits := parseLog(string(out), false)
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	return &commits[0], nil
+}
+
+func lineRange(file string, start, end int) string {
+	return fmt.Sprintf("-L%d,%d:%s", start, max(start, end), file)
+}
+
+// historyError reports a file git has no HEAD version of as an empty,
+// uncommitted history, and any other git failure with its stderr.
+func historyError(err error) (History, error) {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		stderr := strings.TrimSpace(string(exitErr.Stderr))
+		if strings.Contains(stderr, "There is no path") {
+			return History{Commits: []Commit{}, Uncommitted: true}, nil
+		}
+		if stderr != "" {
+			return History{}, fmt.Errorf("git log: %s", stderr)
+		}
+	}
+	return History{}, fmt.Errorf("git log: %w", err)
+}
+
+// parseLog splits git log output into commits, newest first. With patches,
+// it counts each commit's changed lines and marks whitespace-only changes
+// cosmetic.
+func parseLog(out string, patches bool) []Commit {
+	commits := []Commit{}
+	for _, record := range strings.Split(out, recordSep) {
+		header, patch, _ := strings.Cut(record, "\n")
+		fields := strings.Split(header, fieldSep)
+		if len(fields) != 5 {
+			continue
+		}
+		c := Commit{Hash: fields[0], Author: fields[1], Email: fields[2], Date: fields[3], Subject: fields[4]}
+		if patches {
+			var added, deleted []string
+			inHunk := false
+			for _, line := range strings.Split(patch, "\n") {
+				switch {
+				case strings.HasPrefix(line, "@@"):
+					inHunk = true
+				case strings.HasPrefix(line, "diff --git"):
+					inHunk = false
+				case !inHunk:
+				case strings.HasPrefix(line, "+"):
+					added = append(added, squashSpace(line[1:]))
+				case strings.HasPrefix(line, "-"):
+					deleted = append(deleted, squashSpace(line[1:]))
+				}
+			}
+			c.LinesAdded, c.LinesDeleted = len(added), len(deleted)
+			c.Cosmetic = sameLines(added, deleted)
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+// squashSpace drops the whitespace of a line, so a change that only
+// reindents or rewraps it compares equal.
+func squashSpace(line string) string {
+	return strings.Join(strings.Fields(line), "")
+}
+
+// sameLines reports whether a change only moved whitespace around: added
+// and deleted hold the same text once whitespace is dropped, and there is at
+// least one changed line.
+func sameLines(added, deleted []string) bool {
+	if len(added)+len(deleted) == 0 {
+		return false
+	}
+	return strings.Join(added, "") == strings.Join(deleted, "")
+}
@@ -0,0 +1,111 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLog(t *testing.T) {
+	ctx := context.Background()
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repo}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v (%s)", args, err, string(out))
+		}
+	}
+	commit := func(author, subject, src string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, "a.go"), []byte(src), 0o644); err != nil {
+			t.Fatalf("write a.go: %v", err)
+		}
+		run("add", ".")
+		run("-c", "user.name="+author, "-c", "user.email="+author+"@example.com", "commit", "-m", subject)
+	}
+	run("init")
+	commit("alice", "add A", "package a\n\nfunc A() {}\n")
+	commit("bob", "give A a body", "package a\n\nfunc A() {\n\treturn\n}\n")
+	commit("carol", "reindent A", "package a\n\nfunc A() {\n    return\n}\n")
+	commit("dave", "add B", "package a\n\nfunc A() {\n    return\n}\n\nfunc B() {}\n")
+
+	h, err := Log(ctx, repo, "a.go", 3, 5, 0)
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if h.Total != 3 || len(h.Commits) != 3 {
+		t.Fatalf("expected the 3 commits touching A, got %+v", h)
+	}
+	if h.Introduced == nil || h.Introduced.Author != "alice" || h.Introduced.Subject != "add A" || h.Introduced.Email != "alice@example.com" {
+		t.Fatalf("unexpected introduced commit: %+v", h.Introduced)
+	}
+	if h.LastChanged == nil || h.LastChanged.Author != "bob" {
+		t.Fatalf("expected bob's change last, skipping the reindent, got %+v", h.LastChanged)
+	}
+	if newest := h.Commits[0]; newest.Author != "carol" || !newest.Cosmetic || newest.LinesAdded != 1 || newest.LinesDeleted != 1 {
+		t.Fatalf("expected the reindent newest and cosmetic, got %+v", newest)
+	}
+	if h.Commits[1].Cosmetic || h.Commits[1].LinesAdded != 3 || h.Commits[1].LinesDeleted != 1 {
+		t.Fatalf("unexpected body commit: %+v", h.Commits[1])
+	}
+
+	limited, err := Log(ctx, repo, "a.go", 3, 5, 1)
+	if err != nil || limited.Total != 3 || len(limited.Commits) != 1 || limited.Introduced.Author != "alice" {
+		t.Fatalf("Log with limit = %+v (%v)", limited, err)
+	}
+
+	last, err := LastTouch(ctx, repo, "a.go", 3, 5)
+	if err != nil || last == nil || last.Author != "carol" || len(last.Hash) != 40 {
+		t.Fatalf("LastTouch = %+v (%v), want carol's reindent", last, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "b.go"), []byte("package a\n"), 0o644); err != nil {
+		t.Fatalf("write b.go: %v", err)
+	}
+	if h, err := Log(ctx, repo, "b.go", 1, 1, 0); err != nil || !h.Uncommitted || h.Total != 0 || h.Commits == nil {
+		t.Fatalf("expected an uncommitted, empty history, got %+v (%v)", h, err)
+	}
+	if last, err := LastTouch(ctx, repo, "b.go", 1, 1); err != nil || last != nil {
+		t.Fatalf("LastTouch of an uncommitted file = %+v (%v)", last, err)
+	}
+
+	if _, err := Log(ctx, t.TempDir(), "a.go", 1, 1, 0); err == nil || !strings.Contains(err.Error(), "not a git repository") {
+		t.Fatalf("expected git's error outside a repository, got %v", err)
+	}
+}
+
+func TestLogGitFailure(t *testing.T) {
+	orig := gitLog
+	t.Cleanup(func() { gitLog = orig })
+	gitLog = func(context.Context, string, ...string) ([]byte, error) {
+		return nil, errors.New("git not found")
+	}
+	if _, err := Log(context.Background(), t.TempDir(), "a.go", 1, 2, 0); err == nil || err.Error() != "git log: git not found" {
+		t.Fatalf("expected the git error wrapped, got %v", err)
+	}
+	if _, err := LastTouch(context.Background(), t.TempDir(), "a.go", 1, 2); err == nil {
+		t.Fatal("expected LastTouch to fail with git")
+	}
+}
+
+func TestSameLines(t *testing.T) {
+	for _, tc := range []struct {
+		added, deleted []string
+		want           bool
+	}{
+		{nil, nil, false},
+		{[]string{"return"}, []string{"return"}, true},
+		{[]string{"f(a,", "b)"}, []string{"f(a,b)"}, true},
+		{[]string{""}, nil, true},
+		{[]string{"return1"}, []string{"return"}, false},
+	} {
+		if got := sameLines(tc.added, tc.deleted); got != tc.want {
+			t.Fatalf("sameLines(%q, %q) = %v, want %v", tc.added, tc.deleted, got, tc.want)
+		}
+	}
+}
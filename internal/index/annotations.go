@@ -0,0 +1,110 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// Annotation kinds recorded in the annotations table.
+const (
+	AnnotationTodo       = "todo"
+	AnnotationFixme      = "fixme"
+	AnnotationBug        = "bug"
+	AnnotationDeprecated = "deprecated"
+)
+
+// annotationPattern matches a comment line that starts with a TODO, FIXME,
+// or BUG marker, optionally naming someone as in "TODO(alice): ...".
+var annotationPattern = regexp.MustCompile(`^(TODO|FIXME|BUG)(?:\(([^)]*)\))?(?::|\s|$)\s*(.*)$`)
+
+// annotation is a TODO, FIXME, or BUG marker found in a comment.
+type annotation struct {
+	Kind   string
+	Line   int
+	Author string
+	Text   string
+}
+
+// commentAnnotations returns the markers that start a line of any comment in
+// parsed, in source order.
+func commentAnnotations(fset *token.FileSet, parsed *ast.File) []annotation {
+	var out []annotation
+	for _, group := range parsed.Comments {
+		for _, c := range group.List {
+			line := fset.Position(c.Slash).Line
+			text := c.Text
+			if rest, ok := strings.CutPrefix(text, "//"); ok {
+				text = rest
+			} else {
+				text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+			}
+			for i, l := range strings.Split(text, "\n") {
+				l = strings.TrimLeft(strings.TrimSpace(l), "* ")
+				m := annotationPattern.FindStringSubmatch(l)
+				if m == nil {
+					continue
+				}
+				out = append(out, annotation{Kind: strings.ToLower(m[1]), Line: line + i, Author: m[2], Text: strings.TrimSpace(m[3])})
+			}
+		}
+	}
+	return out
+}
+
+// deprecation returns the text of the "Deprecated:" paragraph of a doc
+// comment, following the Go convention, or "" when there is none. A bare
+// "Deprecated:" marker yields "deprecated" so the symbol is still recorded.
+func deprecation(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	for _, para := range strings.Split(doc.Text(), "\n\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(para), "Deprecated:"); ok {
+			if text := strings.Join(strings.Fields(rest), " "); text != "" {
+				return text
+			}
+			return "deprecated"
+		}
+	}
+	return ""
+}
+
+// specDoc returns the doc comment of a spec: its own, or the declaration's
+// when the declaration is not parenthesized.
+func specDoc(gen *ast.GenDecl, doc *ast.CommentGroup) *ast.CommentGroup {
+	if doc == nil && !gen.Lparen.IsValid() {
+		return gen.Doc
+	}
+	return doc
+}
+
+// writeAnnotations inserts the annotations rows for the comment markers of
+// file. Deprecated symbols are recorded as their symbols are inserted.
+func writeAnnotations(ctx context.Context, tx *sql.Tx, fileID int64, fset *token.FileSet, parsed *ast.File) error {
+	for _, a := range commentAnnotations(fset, parsed) {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO annotations (file_id, kind, line, author, text)
+VALUES (?, ?, ?, ?, ?);
+`, fileID, a.Kind, a.Line, a.Author, a.Text); err != nil {
+			return fmt.Errorf("insert %s annotation: %w", a.Kind, err)
+		}
+	}
+	return nil
+}
+
+// writeDeprecation records that symbolID, declared at line of fileID, is
+// deprecated.
+func writeDeprecation(ctx context.Context, tx *sql.Tx, fileID, symbolID int64, line int, text string) error {
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO annotations (file_id, symbol_id, kind, line, text)
+VALUES (?, ?, ?, ?, ?);
+`, fileID, symbolID, AnnotationDeprecated, line, text); err != nil {
+		return fmt.Errorf("insert deprecation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package index
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func TestCommentAnnotations(t *testing.T) {
+	src := `package p
+
+// TODO(alice): handle symlinks
+// TODO: no author
+//FIXME tighten this
+// BUG
+// todo lower case is prose, TODO mid-line too.
+// TODOS is not a marker.
+
+/*
+ * BUG(bob): off by one
+ * FIXME: second line
+ */
+func f() {} // TODO trailing
+`
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	got := commentAnnotations(fset, parsed)
+	want := []annotation{
+		{Kind: "todo", Line: 3, Author: "alice", Text: "handle symlinks"},
+		{Kind: "todo", Line: 4, Text: "no author"},
+		{Kind: "fixme", Line: 5, Text: "tighten this"},
+		{Kind: "bug", Line: 6},
+		{Kind: "bug", Line: 11, Author: "bob", Text: "off by one"},
+		{Kind: "fixme", Line: 12, Text: "second line"},
+		{Kind: "todo", Line: 14, Text: "trailing"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("commentAnnotations() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDeprecation(t *testing.T) {
+	src := `package p
+
+// Old does things.
+//
+// Deprecated: use New
+// instead.
+func Old() {}
+
+// Bare is gone.
+//
+// Deprecated:
+func Bare() {}
+
+// Current mentions Deprecated: mid-sentence.
+func Current() {}
+
+func Undocumented() {}
+`
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []string{"use New instead.", "deprecated", "", ""}
+	for i, decl := range parsed.Decls {
+		if got := deprecation(decl.(*ast.FuncDecl).Doc); got != want[i] {
+			t.Fatalf("deprecation(decl %d) = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestSyncRecordsAnnotations(t *testing.T) {
+	root := t.TempDir()
+	mustWrite := func(path, body string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", path, err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	mustWrite("go.mod", "module example.com/recon\n")
+	mustWrite("store/store.go", `package store
+
+// Store holds things.
+type Store struct{}
+
+// Get returns a thing.
+//
+// Deprecated: use Lookup.
+func (s *Store) Get() {
+	// FIXME(carol): cache this
+}
+
+// Deprecated: no longer used.
+const (
+	// Limit is kept for compatibility.
+	//
+	// Deprecated: has no effect.
+	Limit = 10
+	Other = 1
+)
+
+// Deprecated: use Store.
+var Default Store
+`)
+
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	if _, err := NewService(conn).Sync(context.Background(), root); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	rows, err := conn.Query(`
+SELECT a.kind, a.line, a.author, a.text, COALESCE(s.name, '')
+FROM annotations a
+LEFT JOIN symbols s ON s.id = a.symbol_id
+ORDER BY a.line, a.id;`)
+	if err != nil {
+		t.Fatalf("query annotations: %v", err)
+	}
+	defer rows.Close()
+	type row struct {
+		Kind   string
+		Line   int
+		Author string
+		Text   string
+		Symbol string
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.Kind, &r.Line, &r.Author, &r.Text, &r.Symbol); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		got = append(got, r)
+	}
+	want := []row{
+		{"deprecated", 9, "", "use Lookup.", "Get"},
+		{"fixme", 10, "carol", "cache this", ""},
+		{"deprecated", 18, "", "has no effect.", "Limit"},
+		{"deprecated", 23, "", "use Store.", "Default"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("annotations = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package index
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// MaxBodyBytes caps the declaration source stored per symbol. Longer bodies
+// are cut at a line boundary and end with a truncation marker, so generated
+// tables and embedded blobs do not bloat the index.
+var MaxBodyBytes = 64 << 10
+
+// capBody returns body cut to MaxBodyBytes, with a marker line noting how much
+// was left out.
+func capBody(body string) string {
+	if MaxBodyBytes <= 0 || len(body) <= MaxBodyBytes {
+		return body
+	}
+	cut := body[:MaxBodyBytes]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i]
+	}
+	return fmt.Sprintf("%s\n// ... truncated by recon: %d of %d bytes stored", cut, len(cut), len(body))
+}
+
+// storeBody writes body to symbol_bodies under its content hash and returns
+// the hash, or nil for an empty body. Identical bodies share one row.
+func storeBody(ctx context.Context, tx *sql.Tx, body string) (any, error) {
+	body = capBody(body)
+	if body == "" {
+		return nil, nil
+	}
+	sum := sha256.Sum256([]byte(body))
+	hash := hex.EncodeToString(sum[:])
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO symbol_bodies (hash, body) VALUES (?, ?)
+ON CONFLICT(hash) DO NOTHING;
+`, hash, body); err != nil {
+		return nil, fmt.Errorf("insert symbol body: %w", err)
+	}
+	return hash, nil
+}
+
+// pruneBodies deletes bodies no symbol refers to any more.
+func pruneBodies(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `
+DELETE FROM symbol_bodies
+WHERE hash NOT IN (SELECT body_hash FROM symbols WHERE body_hash IS NOT NULL);
+`); err != nil {
+		return fmt.Errorf("prune symbol bodies: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package index
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCapBody(t *testing.T) {
+	orig := MaxBodyBytes
+	defer func() { MaxBodyBytes = orig }()
+
+	MaxBodyBytes = 20
+	if got := capBody("func A() {}"); got != "func A() {}" {
+		t.Fatalf("short body changed: %q", got)
+	}
+	body := "func A() {\n\tx := 1\n\ty := 2\n}"
+	got := capBody(body)
+	if got != "func A() {\n\tx := 1\n// ... truncated by recon: 18 of 28 bytes stored" {
+		t.Fatalf("capBody = %q", got)
+	}
+
+	MaxBodyBytes = 0
+	if got := capBody(body); got != body {
+		t.Fatalf("zero cap should store everything, got %q", got)
+	}
+}
+
+func TestSyncStoresBodiesByContent(t *testing.T) {
+	root, conn, mustWrite := syncFilesFixture(t)
+	mustWrite("dup/dup.go", `package dup
+func Helper() string { return "ok" }
+`)
+	ctx := context.Background()
+	svc := NewService(conn)
+	if _, err := svc.Sync(ctx, root); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	// sub.Helper and dup.Helper have identical source and share one row.
+	var symbols, bodies int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM symbols WHERE name = 'Helper'`).Scan(&symbols); err != nil {
+		t.Fatalf("count symbols: %v", err)
+	}
+	if err := conn.QueryRow(`SELECT COUNT(DISTINCT body_hash) FROM symbols WHERE name = 'Helper'`).Scan(&bodies); err != nil {
+		t.Fatalf("count bodies: %v", err)
+	}
+	if symbols != 2 || bodies != 1 {
+		t.Fatalf("expected 2 Helper symbols sharing 1 body, got %d and %d", symbols, bodies)
+	}
+
+	var body string
+	if err := conn.QueryRow(`
+SELECT b.body FROM symbols s JOIN symbol_bodies b ON b.hash = s.body_hash WHERE s.name = 'Call'`).Scan(&body); err != nil {
+		t.Fatalf("load Call body: %v", err)
+	}
+	if !strings.Contains(body, "return sub.Helper()") {
+		t.Fatalf("unexpected Call body: %q", body)
+	}
+
+	// A targeted sync that drops the only user of a body prunes it.
+	mustWrite("main.go", "package main\nfunc Call() string { return \"\" }\n")
+	if _, err := svc.SyncFiles(ctx, root, []string{"main.go"}); err != nil {
+		t.Fatalf("SyncFiles: %v", err)
+	}
+	var orphans int
+	if err := conn.QueryRow(`
+SELECT COUNT(*) FROM symbol_bodies WHERE hash NOT IN (SELECT body_hash FROM symbols WHERE body_hash IS NOT NULL)`).Scan(&orphans); err != nil {
+		t.Fatalf("count orphans: %v", err)
+	}
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM symbol_bodies WHERE body LIKE '%sub.Helper()%'`).Scan(&bodies); err != nil {
+		t.Fatalf("count old body: %v", err)
+	}
+	if orphans != 0 || bodies != 0 {
+		t.Fatalf("expected the old Call body pruned, got %d orphans, %d stale", orphans, bodies)
+	}
+}
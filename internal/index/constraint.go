@@ -0,0 +1,104 @@
+package index
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"path"
+	"strings"
+)
+
+// knownOS and knownArch mirror the GOOS and GOARCH values go/build accepts
+// as file name suffixes.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+		"hurd": true, "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true,
+		"netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+		"windows": true, "zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+		"arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true,
+		"mips64le": true, "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+		"ppc64le": true, "riscv": true, "riscv64": true, "s390": true, "s390x": true,
+		"sparc": true, "sparc64": true, "wasm": true,
+	}
+)
+
+// buildConstraint returns the constraint a file is compiled under: its
+// //go:build line and any _GOOS, _GOARCH or _GOOS_GOARCH file name suffix,
+// joined with &&. A suffix the //go:build line already requires is left out,
+// so file_linux.go with //go:build linux gives "linux", not "linux && linux".
+// Unconstrained files return "".
+func buildConstraint(relPath string, parsed *ast.File) string {
+	var lines []constraint.Expr
+	if parsed != nil {
+		for _, group := range parsed.Comments {
+			if group.Pos() >= parsed.Package {
+				break
+			}
+			for _, c := range group.List {
+				if !constraint.IsGoBuild(c.Text) {
+					continue
+				}
+				if x, err := constraint.Parse(c.Text); err == nil {
+					lines = append(lines, x)
+				}
+			}
+		}
+	}
+	var exprs []constraint.Expr
+	for _, tag := range fileNameTags(relPath) {
+		if !requiresTag(lines, tag) {
+			exprs = append(exprs, &constraint.TagExpr{Tag: tag})
+		}
+	}
+	exprs = append(exprs, lines...)
+	if len(exprs) == 0 {
+		return ""
+	}
+	expr := exprs[0]
+	for _, x := range exprs[1:] {
+		expr = &constraint.AndExpr{X: expr, Y: x}
+	}
+	return expr.String()
+}
+
+// requiresTag reports whether one of exprs is satisfied only when tag is set:
+// tag itself, or a conjunction with tag as one of its terms.
+func requiresTag(exprs []constraint.Expr, tag string) bool {
+	for _, x := range exprs {
+		switch x := x.(type) {
+		case *constraint.TagExpr:
+			if x.Tag == tag {
+				return true
+			}
+		case *constraint.AndExpr:
+			if requiresTag([]constraint.Expr{x.X, x.Y}, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fileNameTags applies go/build's file name rule: the text before the first
+// underscore is never a constraint, and the last one or two
+// underscore-separated elements may name a GOOS, a GOARCH, or both.
+func fileNameTags(relPath string) []string {
+	name := strings.TrimSuffix(path.Base(relPath), ".go")
+	name = strings.TrimSuffix(name, "_test")
+	i := strings.IndexByte(name, '_')
+	if i < 0 {
+		return nil
+	}
+	parts := strings.Split(name[i+1:], "_")
+	n := len(parts)
+	switch {
+	case n >= 2 && knownOS[parts[n-2]] && knownArch[parts[n-1]]:
+		return parts[n-2:]
+	case knownOS[parts[n-1]] || knownArch[parts[n-1]]:
+		return parts[n-1:]
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package index
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestBuildConstraint(t *testing.T) {
+	for _, tc := range []struct {
+		path, src, want string
+	}{
+		{"pkg/file.go", "package pkg\n", ""},
+		{"pkg/linux.go", "package pkg\n", ""},
+		{"pkg/file_linux.go", "package pkg\n", "linux"},
+		{"pkg/file_linux_amd64.go", "package pkg\n", "linux && amd64"},
+		{"pkg/file_arm64.go", "package pkg\n", "arm64"},
+		{"pkg/file_unix.go", "//go:build unix\n\npackage pkg\n", "unix"},
+		{"pkg/file_other.go", "// Copyright\n\n//go:build !linux && !windows\n\npackage pkg\n", "!linux && !windows"},
+		{"pkg/file_windows.go", "//go:build amd64 || arm64\n\npackage pkg\n", "windows && (amd64 || arm64)"},
+		{"pkg/file.go", "package pkg\n\n//go:build linux\n", ""},
+		{"pkg/file_linux.go", "//go:build linux\n\npackage pkg\n", "linux"},
+		{"pkg/file_linux_amd64.go", "//go:build linux && cgo\n\npackage pkg\n", "amd64 && linux && cgo"},
+		{"pkg/file_windows.go", "//go:build windows || linux\n\npackage pkg\n", "windows && (windows || linux)"},
+	} {
+		parsed, err := parser.ParseFile(token.NewFileSet(), tc.path, tc.src, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("parse %s: %v", tc.path, err)
+		}
+		if got := buildConstraint(tc.path, parsed); got != tc.want {
+			t.Errorf("buildConstraint(%s, %q) = %q, want %q", tc.path, tc.src, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// writeDependencies replaces the dependencies and module_info rows with the
+// requirements and header of mod.
+func writeDependencies(ctx context.Context, tx *sql.Tx, mod GoMod) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM dependencies;"); err != nil {
+		return fmt.Errorf("reset dependencies: %w", err)
+	}
+	for _, req := range mod.Require {
+		r, _ := mod.ReplacementFor(req)
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO dependencies (module, version, indirect, replace_path, replace_version)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(module) DO UPDATE SET
+    version = excluded.version,
+    indirect = excluded.indirect,
+    replace_path = excluded.replace_path,
+    replace_version = excluded.replace_version;
+`, req.Path, req.Version, req.Indirect, r.New, r.NewVersion); err != nil {
+			return fmt.Errorf("insert dependency %s: %w", req.Path, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO module_info (id, module_path, go_version, toolchain)
+VALUES (1, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+    module_path = excluded.module_path,
+    go_version = excluded.go_version,
+    toolchain = excluded.toolchain;
+`, mod.Module, mod.Go, mod.Toolchain); err != nil {
+		return fmt.Errorf("upsert module info: %w", err)
+	}
+	return nil
+}
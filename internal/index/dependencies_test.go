@@ -0,0 +1,65 @@
+package index
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSyncRecordsDependencies(t *testing.T) {
+	root, conn, mustWrite := syncFilesFixture(t)
+	mustWrite("go.mod", `module example.com/recon
+
+go 1.26
+
+require (
+	github.com/acme/kit v1.2.0
+	golang.org/x/sys v0.38.0 // indirect
+)
+
+replace github.com/acme/kit => github.com/fork/kit v1.2.1
+`)
+	svc := NewService(conn)
+	if _, err := svc.Sync(context.Background(), root); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	dependencies := func() string {
+		t.Helper()
+		rows, err := conn.Query(`
+SELECT module || ' ' || version || ' ' || indirect || ' ' || replace_path || ' ' || replace_version
+FROM dependencies ORDER BY module;`)
+		if err != nil {
+			t.Fatalf("query dependencies: %v", err)
+		}
+		defer rows.Close()
+		var got []string
+		for rows.Next() {
+			var line string
+			if err := rows.Scan(&line); err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			got = append(got, line)
+		}
+		return strings.Join(got, ",")
+	}
+	if got, want := dependencies(), "github.com/acme/kit v1.2.0 0 github.com/fork/kit v1.2.1,golang.org/x/sys v0.38.0 1  "; got != want {
+		t.Fatalf("dependencies = %q, want %q", got, want)
+	}
+	var modulePath, goVersion string
+	if err := conn.QueryRow(`SELECT module_path, go_version FROM module_info`).Scan(&modulePath, &goVersion); err != nil {
+		t.Fatalf("module_info: %v", err)
+	}
+	if modulePath != "example.com/recon" || goVersion != "1.26" {
+		t.Fatalf("module_info = %q %q", modulePath, goVersion)
+	}
+
+	// Dropping a requirement drops its row on the next full sync.
+	mustWrite("go.mod", "module example.com/recon\n\ngo 1.26\n\nrequire golang.org/x/sys v0.39.0\n")
+	if _, err := svc.Sync(context.Background(), root); err != nil {
+		t.Fatalf("resync: %v", err)
+	}
+	if got, want := dependencies(), "golang.org/x/sys v0.39.0 0  "; got != want {
+		t.Fatalf("dependencies after resync = %q, want %q", got, want)
+	}
+}
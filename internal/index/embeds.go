@@ -0,0 +1,236 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MissingEmbed is a //go:embed pattern that matched no files when the
+// module was synced, so its package will not build.
+type MissingEmbed struct {
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Var      string `json:"var"`
+	Pattern  string `json:"pattern"`
+}
+
+// embedDirective is one pattern of a //go:embed directive.
+type embedDirective struct {
+	Var     string
+	Pattern string
+	Line    int
+}
+
+// embedDirectives returns the patterns of the //go:embed directives on the
+// package-level vars of parsed. Directives that do not parse are skipped;
+// the compiler reports those.
+func embedDirectives(fset *token.FileSet, parsed *ast.File) []embedDirective {
+	var out []embedDirective
+	for _, decl := range parsed.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok || len(vs.Names) == 0 {
+				continue
+			}
+			doc := vs.Doc
+			if !gen.Lparen.IsValid() {
+				doc = gen.Doc
+			}
+			if doc == nil {
+				continue
+			}
+			for _, c := range doc.List {
+				args, ok := strings.CutPrefix(c.Text, "//go:embed")
+				if !ok || (args != "" && args[0] != ' ' && args[0] != '\t') {
+					continue
+				}
+				patterns, err := splitEmbedPatterns(args)
+				if err != nil {
+					continue
+				}
+				line := fset.Position(c.Slash).Line
+				for _, p := range patterns {
+					out = append(out, embedDirective{Var: vs.Names[0].Name, Pattern: p, Line: line})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// splitEmbedPatterns splits the arguments of a //go:embed directive into
+// patterns. Patterns are separated by spaces and may be Go string literals.
+func splitEmbedPatterns(args string) ([]string, error) {
+	var patterns []string
+	for {
+		args = strings.TrimLeft(args, " \t")
+		if args == "" {
+			return patterns, nil
+		}
+		switch args[0] {
+		case '`':
+			end := strings.IndexByte(args[1:], '`')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated pattern %s", args)
+			}
+			patterns = append(patterns, args[1:end+1])
+			args = args[end+2:]
+		case '"':
+			end := 1
+			for ; end < len(args); end++ {
+				if args[end] == '\\' {
+					end++
+				} else if args[end] == '"' {
+					break
+				}
+			}
+			if end >= len(args) {
+				return nil, fmt.Errorf("unterminated pattern %s", args)
+			}
+			p, err := strconv.Unquote(args[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("parse pattern %s: %w", args[:end+1], err)
+			}
+			patterns = append(patterns, p)
+			args = args[end+1:]
+		default:
+			end := strings.IndexAny(args, " \t")
+			if end < 0 {
+				end = len(args)
+			}
+			patterns = append(patterns, args[:end])
+			args = args[end:]
+		}
+	}
+}
+
+// resolveEmbedPattern returns the files, relative to dir, that pattern
+// embeds, following the go command: a matched directory contributes its
+// files recursively, minus names starting with "." or "_" unless the
+// pattern has the "all:" prefix, and minus nested modules. A pattern that
+// is invalid or matches nothing returns no files.
+func resolveEmbedPattern(dir, pattern string) []string {
+	all := false
+	if rest, ok := strings.CutPrefix(pattern, "all:"); ok {
+		pattern, all = rest, true
+	}
+	fsys := os.DirFS(dir)
+	matches, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var files []string
+	for _, m := range matches {
+		info, err := fs.Stat(fsys, m)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			if info.Mode().IsRegular() && !seen[m] {
+				seen[m] = true
+				files = append(files, m)
+			}
+			continue
+		}
+		_ = fs.WalkDir(fsys, m, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if p != m {
+				name := d.Name()
+				if !all && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+					if d.IsDir() {
+						return fs.SkipDir
+					}
+					return nil
+				}
+				if d.IsDir() {
+					if _, err := fs.Stat(fsys, path.Join(p, "go.mod")); err == nil {
+						return fs.SkipDir
+					}
+					return nil
+				}
+			}
+			if d.Type().IsRegular() && !seen[p] {
+				seen[p] = true
+				files = append(files, p)
+			}
+			return nil
+		})
+	}
+	sort.Strings(files)
+	return files
+}
+
+// writeEmbeds inserts the file_embeds rows for the //go:embed directives of
+// file, resolving each pattern against the file's directory.
+func writeEmbeds(ctx context.Context, tx *sql.Tx, fileID int64, file SourceFile, fset *token.FileSet, parsed *ast.File) error {
+	directives := embedDirectives(fset, parsed)
+	if len(directives) == 0 {
+		return nil
+	}
+	dir := filepath.Dir(file.AbsPath)
+	relDir := path.Dir(file.RelPath)
+	for _, d := range directives {
+		paths := []string{}
+		for _, m := range resolveEmbedPattern(dir, d.Pattern) {
+			paths = append(paths, path.Join(relDir, m))
+		}
+		encoded, err := json.Marshal(paths)
+		if err != nil {
+			return fmt.Errorf("encode embedded paths: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO file_embeds (file_id, var_name, pattern, line, paths)
+VALUES (?, ?, ?, ?, ?);
+`, fileID, d.Var, d.Pattern, d.Line, string(encoded)); err != nil {
+			return fmt.Errorf("insert embed %s: %w", d.Pattern, err)
+		}
+	}
+	return nil
+}
+
+// missingEmbeds lists the indexed //go:embed patterns that matched no files.
+func missingEmbeds(ctx context.Context, tx *sql.Tx) ([]MissingEmbed, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT f.path, e.line, e.var_name, e.pattern
+FROM file_embeds e
+JOIN files f ON f.id = e.file_id
+WHERE e.paths = '[]'
+ORDER BY f.path, e.line, e.id;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query missing embeds: %w", err)
+	}
+	defer rows.Close()
+
+	var missing []MissingEmbed
+	for rows.Next() {
+		var m MissingEmbed
+		if err := rows.Scan(&m.FilePath, &m.Line, &m.Var, &m.Pattern); err != nil {
+			return nil, fmt.Errorf("scan missing embed: %w", err)
+		}
+		missing = append(missing, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate missing embeds: %w", err)
+	}
+	return missing, nil
+}
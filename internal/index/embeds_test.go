@@ -0,0 +1,151 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func TestSplitEmbedPatterns(t *testing.T) {
+	tests := []struct {
+		args    string
+		want    []string
+		wantErr bool
+	}{
+		{args: " assets/*", want: []string{"assets/*"}},
+		{args: " a.sql  b.tmpl\tall:static", want: []string{"a.sql", "b.tmpl", "all:static"}},
+		{args: ` "with space.txt" ` + "`raw name.txt`", want: []string{"with space.txt", "raw name.txt"}},
+		{args: ` "esc\"aped.txt"`, want: []string{`esc"aped.txt`}},
+		{args: ` "open`, wantErr: true},
+		{args: " `open", wantErr: true},
+		{args: "", want: nil},
+	}
+	for _, tt := range tests {
+		got, err := splitEmbedPatterns(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("splitEmbedPatterns(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("splitEmbedPatterns(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestSyncRecordsEmbeds(t *testing.T) {
+	root := t.TempDir()
+	mustWrite := func(path, body string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", path, err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	mustWrite("go.mod", "module example.com/recon\n")
+	mustWrite("web/web.go", `package web
+
+import "embed"
+
+//go:embed templates
+var templates embed.FS
+
+var (
+	//go:embed all:static "schema.sql"
+	static embed.FS
+
+	//go:embed missing/*.txt
+	missing embed.FS
+)
+
+// Not a directive: the var is local.
+func f() {
+	//go:embed schema.sql
+	var s string
+	_ = s
+}
+`)
+	mustWrite("web/templates/page.tmpl", "{{.}}")
+	mustWrite("web/templates/.hidden", "x")
+	mustWrite("web/templates/_draft.tmpl", "x")
+	mustWrite("web/templates/nested/part.tmpl", "x")
+	mustWrite("web/static/.keep", "")
+	mustWrite("web/schema.sql", "CREATE TABLE t (id INTEGER);")
+
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	result, err := NewService(conn).Sync(context.Background(), root)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	rows, err := conn.Query(`SELECT var_name, pattern, line, paths FROM file_embeds ORDER BY line, id;`)
+	if err != nil {
+		t.Fatalf("query file_embeds: %v", err)
+	}
+	defer rows.Close()
+	type row struct {
+		Var, Pattern string
+		Line         int
+		Paths        string
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.Var, &r.Pattern, &r.Line, &r.Paths); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		got = append(got, r)
+	}
+	want := []row{
+		{"templates", "templates", 5, `["web/templates/nested/part.tmpl","web/templates/page.tmpl"]`},
+		{"static", "all:static", 9, `["web/static/.keep"]`},
+		{"static", "schema.sql", 9, `["web/schema.sql"]`},
+		{"missing", "missing/*.txt", 12, `[]`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("file_embeds = %+v, want %+v", got, want)
+	}
+
+	wantMissing := []MissingEmbed{{FilePath: "web/web.go", Line: 12, Var: "missing", Pattern: "missing/*.txt"}}
+	if !reflect.DeepEqual(result.MissingEmbeds, wantMissing) {
+		t.Fatalf("MissingEmbeds = %+v, want %+v", result.MissingEmbeds, wantMissing)
+	}
+
+	// A targeted sync of the edited file replaces its rows and reports the
+	// index-wide missing patterns again.
+	mustWrite("web/missing/a.txt", "a")
+	src, err := os.ReadFile(filepath.Join(root, "web/web.go"))
+	if err != nil {
+		t.Fatalf("read web.go: %v", err)
+	}
+	mustWrite("web/web.go", string(src)+"\n// edited\n")
+	result, err = NewService(conn).SyncFiles(context.Background(), root, []string{"web/web.go"})
+	if err != nil {
+		t.Fatalf("SyncFiles() error = %v", err)
+	}
+	if len(result.MissingEmbeds) != 0 {
+		t.Fatalf("MissingEmbeds after SyncFiles = %+v, want none", result.MissingEmbeds)
+	}
+	var count int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM file_embeds;`).Scan(&count); err != nil {
+		t.Fatalf("count file_embeds: %v", err)
+	}
+	if count != 4 {
+		t.Fatalf("file_embeds rows = %d, want 4", count)
+	}
+}
@@ -0,0 +1,221 @@
+package index
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+)
+
+// enumMember is a constant of an enum group. Value is the evaluated constant
+// when the expression only involves literals, iota, and earlier constants of
+// the block; otherwise it is the expression as written.
+type enumMember struct {
+	Name  string
+	Value string
+}
+
+// enumRecords detects the enums declared by a const block: two or more
+// constants sharing one named type of the package, typically
+//
+//	const (
+//		Red Color = iota
+//		Green
+//	)
+//
+// Each enum is returned as a symbol of kind "enum" named after its type and
+// spanning the whole block. Untyped iota blocks have no name to group under
+// and are left as plain constants.
+func enumRecords(fset *token.FileSet, src []byte, d *ast.GenDecl) []symbolRecord {
+	if d.Tok != token.CONST || !d.Lparen.IsValid() {
+		return nil
+	}
+
+	var (
+		order    []string
+		members  = map[string][]enumMember{}
+		known    = map[string]constant.Value{}
+		lastType ast.Expr
+		lastVals []ast.Expr
+	)
+	for i, spec := range d.Specs {
+		s, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		// A spec without type and values repeats the previous ones.
+		if s.Type != nil || len(s.Values) > 0 {
+			lastType, lastVals = s.Type, s.Values
+		}
+		typeName := enumTypeName(lastType)
+		for j, n := range s.Names {
+			var expr ast.Expr
+			if j < len(lastVals) {
+				expr = lastVals[j]
+			}
+			value := ""
+			if v, ok := evalConst(expr, int64(i), typeName, known); ok {
+				known[n.Name] = v
+				value = constantString(v)
+			} else if expr != nil {
+				value = exprString(expr)
+			}
+			if typeName == "" || n.Name == "_" {
+				continue
+			}
+			if _, seen := members[typeName]; !seen {
+				order = append(order, typeName)
+			}
+			members[typeName] = append(members[typeName], enumMember{Name: n.Name, Value: value})
+		}
+	}
+
+	var records []symbolRecord
+	for _, typeName := range order {
+		if len(members[typeName]) < 2 {
+			continue
+		}
+		records = append(records, symbolRecord{
+			Kind:      "enum",
+			Name:      typeName,
+			Signature: typeName,
+			Body:      textForPos(fset, src, d.Pos(), d.End()),
+			LineStart: fset.Position(d.Pos()).Line,
+			LineEnd:   fset.Position(d.End()).Line,
+			Exported:  ast.IsExported(typeName),
+			Members:   members[typeName],
+		})
+	}
+	return records
+}
+
+// enumTypeName returns the name of a constant's type when it is a type
+// declared in the package, and "" for untyped, predeclared, or imported types.
+func enumTypeName(expr ast.Expr) string {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	if _, predeclared := types.Universe.Lookup(ident.Name).(*types.TypeName); predeclared {
+		return ""
+	}
+	return ident.Name
+}
+
+// evalConst evaluates a constant expression of a const block. Conversions to
+// the enum type are transparent; anything else it cannot fold reports false.
+func evalConst(expr ast.Expr, iota int64, typeName string, known map[string]constant.Value) (constant.Value, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		v := constant.MakeFromLiteral(e.Value, e.Kind, 0)
+		return v, v.Kind() != constant.Unknown
+	case *ast.Ident:
+		switch e.Name {
+		case "iota":
+			return constant.MakeInt64(iota), true
+		case "true", "false":
+			return constant.MakeBool(e.Name == "true"), true
+		}
+		v, ok := known[e.Name]
+		return v, ok
+	case *ast.ParenExpr:
+		return evalConst(e.X, iota, typeName, known)
+	case *ast.CallExpr:
+		if fn, ok := e.Fun.(*ast.Ident); ok && typeName != "" && fn.Name == typeName && len(e.Args) == 1 {
+			return evalConst(e.Args[0], iota, typeName, known)
+		}
+	case *ast.UnaryExpr:
+		x, ok := evalConst(e.X, iota, typeName, known)
+		if !ok {
+			return nil, false
+		}
+		switch {
+		case (e.Op == token.ADD || e.Op == token.SUB) && isNumeric(x),
+			e.Op == token.XOR && x.Kind() == constant.Int,
+			e.Op == token.NOT && x.Kind() == constant.Bool:
+			return constant.UnaryOp(e.Op, x, 0), true
+		}
+	case *ast.BinaryExpr:
+		x, okX := evalConst(e.X, iota, typeName, known)
+		y, okY := evalConst(e.Y, iota, typeName, known)
+		if !okX || !okY {
+			return nil, false
+		}
+		return binaryConst(e.Op, x, y)
+	}
+	return nil, false
+}
+
+func binaryConst(op token.Token, x, y constant.Value) (constant.Value, bool) {
+	switch op {
+	case token.SHL, token.SHR:
+		if x.Kind() != constant.Int || y.Kind() != constant.Int {
+			return nil, false
+		}
+		s, exact := constant.Uint64Val(y)
+		if !exact || s > 1<<10 {
+			return nil, false
+		}
+		return constant.Shift(x, op, uint(s)), true
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		ordered := op != token.EQL && op != token.NEQ
+		switch {
+		case isNumeric(x) && isNumeric(y):
+			if ordered && (x.Kind() == constant.Complex || y.Kind() == constant.Complex) {
+				return nil, false
+			}
+		case x.Kind() != y.Kind(), x.Kind() == constant.Bool && ordered:
+			return nil, false
+		}
+		return constant.MakeBool(constant.Compare(x, op, y)), true
+	case token.LAND, token.LOR:
+		if x.Kind() != constant.Bool || y.Kind() != constant.Bool {
+			return nil, false
+		}
+		return constant.BinaryOp(x, op, y), true
+	}
+
+	if x.Kind() == constant.String && y.Kind() == constant.String && op == token.ADD {
+		return constant.BinaryOp(x, op, y), true
+	}
+	if !isNumeric(x) || !isNumeric(y) {
+		return nil, false
+	}
+	switch op {
+	case token.QUO:
+		if constant.Sign(y) == 0 {
+			return nil, false
+		}
+		if x.Kind() == constant.Int && y.Kind() == constant.Int {
+			op = token.QUO_ASSIGN // integer division
+		}
+	case token.REM, token.AND, token.OR, token.XOR, token.AND_NOT:
+		if x.Kind() != constant.Int || y.Kind() != constant.Int || op == token.REM && constant.Sign(y) == 0 {
+			return nil, false
+		}
+	case token.ADD, token.SUB, token.MUL:
+	default:
+		return nil, false
+	}
+	return constant.BinaryOp(x, op, y), true
+}
+
+func isNumeric(v constant.Value) bool {
+	switch v.Kind() {
+	case constant.Int, constant.Float, constant.Complex:
+		return true
+	}
+	return false
+}
+
+func constantString(v constant.Value) string {
+	switch v.Kind() {
+	case constant.String:
+		return strconv.Quote(constant.StringVal(v))
+	case constant.Int:
+		return v.ExactString()
+	default:
+		return v.String()
+	}
+}
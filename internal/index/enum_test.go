@@ -0,0 +1,110 @@
+package index
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestEnumRecords(t *testing.T) {
+	src := `package p
+type Color int
+type Mode string
+type Flag uint
+
+const (
+	Red Color = iota
+	Green
+	_
+	Blue
+	Unknown = -1
+)
+
+const (
+	Read  Mode = "r"
+	Write Mode = "w" + "+"
+	KB         = 1 << 10
+	Big   Flag = 1 << iota
+	Small
+	Half  Flag = Flag(KB / 3)
+	Pi    Flag = Flag(len("x"))
+)
+
+const (
+	A = iota
+	B
+)
+
+const Single Color = 9
+
+const (
+	Only Color = 1
+	N        = 3 / 0
+)
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "x.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse source: %v", err)
+	}
+
+	var got []string
+	for _, d := range file.Decls {
+		for _, rec := range symbolRecordsFromDecl(fset, []byte(src), d) {
+			if rec.Kind != "enum" {
+				continue
+			}
+			var members []string
+			for _, m := range rec.Members {
+				members = append(members, m.Name+"="+m.Value)
+			}
+			got = append(got, fmt.Sprintf("%s %d-%d exported=%t [%s]", rec.Name, rec.LineStart, rec.LineEnd, rec.Exported, strings.Join(members, " ")))
+		}
+	}
+
+	want := []string{
+		"Color 6-12 exported=true [Red=0 Green=1 Blue=3]",
+		`Mode 14-22 exported=true [Read="r" Write="w+"]`,
+		`Flag 14-22 exported=true [Big=8 Small=16 Half=341 Pi=Flag(len("x"))]`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("enum records:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestEvalConstRejectsInvalidOperands(t *testing.T) {
+	for _, expr := range []string{
+		`"a" - "b"`, `"a" + 1`, `-"a"`, `!1`, `^1.5`, `1.5 % 2`, `1 % 0`, `1 << 1.5`, `"a" < 1`,
+		`true < false`, `1 && true`, `1 << 2000`, `f(1)`, `x`,
+	} {
+		e, err := parser.ParseExpr(expr)
+		if err != nil {
+			t.Fatalf("parse %q: %v", expr, err)
+		}
+		if v, ok := evalConst(e, 0, "T", nil); ok {
+			t.Fatalf("evalConst(%s) = %v, want not ok", expr, v)
+		}
+	}
+
+	for expr, want := range map[string]string{
+		`1.5 * 2`:        "3",
+		`7 / 2`:          "3",
+		`7.0 / 2`:        "3.5",
+		`"a" < "b"`:      "true",
+		`!(1 == 2)`:      "true",
+		`true && 1 <= 2`: "true",
+		`^0 &^ 1`:        "-2",
+		`T(2) | 1`:       "3",
+	} {
+		e, err := parser.ParseExpr(expr)
+		if err != nil {
+			t.Fatalf("parse %q: %v", expr, err)
+		}
+		v, ok := evalConst(e, 0, "T", nil)
+		if !ok || constantString(v) != want {
+			t.Fatalf("evalConst(%s) = %v, %t, want %s", expr, v, ok, want)
+		}
+	}
+}
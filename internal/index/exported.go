@@ -0,0 +1,141 @@
+package index
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ExportedDecl is an exported declaration of a Go file, with the kind,
+// receiver, and signature sync records for it. Fields lists the exported
+// fields of a struct type.
+type ExportedDecl struct {
+	Package     string
+	PackageName string
+	FilePath    string
+	Line        int
+	Kind        string
+	Name        string
+	Receiver    string
+	Signature   string
+	Fields      []ExportedField
+}
+
+// ExportedField is an exported field of a struct type; an embedded field is
+// named after its type.
+type ExportedField struct {
+	Name string
+	Type string
+}
+
+// ExportedDecls parses the Go files and returns their exported declarations
+// in file order. Package paths are module-relative, as sync records them.
+func ExportedDecls(modulePath string, files []SourceFile) ([]ExportedDecl, error) {
+	var out []ExportedDecl
+	for _, file := range files {
+		if file.Language != "" {
+			continue
+		}
+		fset := token.NewFileSet()
+		parsed, err := parser.ParseFile(fset, file.RelPath, file.Content, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", file.RelPath, err)
+		}
+		pkgPath, _ := packagePaths(modulePath, file.RelPath)
+		for _, decl := range parsed.Decls {
+			for _, rec := range symbolRecordsFromDecl(fset, file.Content, decl) {
+				if !rec.Exported || rec.Kind == "enum" {
+					continue
+				}
+				d := ExportedDecl{
+					Package:     pkgPath,
+					PackageName: parsed.Name.Name,
+					FilePath:    file.RelPath,
+					Line:        rec.LineStart,
+					Kind:        rec.Kind,
+					Name:        rec.Name,
+					Receiver:    rec.Receiver,
+					Signature:   rec.Signature,
+				}
+				for _, f := range rec.Fields {
+					if ast.IsExported(f.Name) {
+						d.Fields = append(d.Fields, ExportedField{Name: f.Name, Type: f.Type})
+					}
+				}
+				out = append(out, d)
+			}
+		}
+	}
+	return out, nil
+}
+
+// GoFilesAtRef returns the Go source files sync would index in moduleRoot as
+// of the git revision ref, sorted by path, read with git archive so the
+// working tree is left alone.
+func GoFilesAtRef(ctx context.Context, moduleRoot, ref string) ([]SourceFile, error) {
+	loc, err := GitOutput(ctx, moduleRoot, "rev-parse", "--show-toplevel", "--show-prefix")
+	if err != nil {
+		return nil, fmt.Errorf("locate module in git: %w", gitError(err))
+	}
+	// Archive the module's subtree from the top level; git archive run in a
+	// subdirectory would filter the subtree's paths by that directory again.
+	top, prefix, _ := strings.Cut(strings.TrimSpace(string(loc)), "\n")
+	tree := ref + ":" + strings.TrimSuffix(strings.TrimSpace(prefix), "/")
+	out, err := GitOutput(ctx, top, "archive", "--format=tar", tree)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", ref, gitError(err))
+	}
+
+	var files []SourceFile
+	tr := tar.NewReader(bytes.NewReader(out))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s archive: %w", ref, err)
+		}
+		rel := strings.TrimPrefix(hdr.Name, "./")
+		if hdr.Typeflag != tar.TypeReg || !eligibleGoPath(rel) {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s at %s: %w", rel, ref, err)
+		}
+		files = append(files, newSourceFile(filepath.Join(moduleRoot, filepath.FromSlash(rel)), rel, content))
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].RelPath < files[j].RelPath
+	})
+	return files, nil
+}
+
+// eligibleGoPath reports whether the module-relative path rel is a Go source
+// file outside the directories a sync skips. Like sync, it keeps generated
+// files and those vendored under the module root's vendor directory.
+func eligibleGoPath(rel string) bool {
+	if !strings.HasSuffix(rel, ".go") || strings.HasSuffix(rel, "_test.go") {
+		return false
+	}
+	dirs := strings.Split(rel, "/")
+	for i, dir := range dirs[:len(dirs)-1] {
+		if i == 0 && dir == vendorDir {
+			continue
+		}
+		if shouldSkipDir("", dir, dir) {
+			return false
+		}
+	}
+	return true
+}
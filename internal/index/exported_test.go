@@ -0,0 +1,97 @@
+package index
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGoFilesAtRefAndExportedDecls(t *testing.T) {
+	repo := t.TempDir()
+	root := filepath.Join(repo, "lib")
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v (%s)", args, err, string(out))
+		}
+	}
+	mustWrite := func(path, body string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", path, err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	mustWrite("go.mod", "module example.com/lib\n")
+	mustWrite("store/store.go", `package store
+
+type Store struct {
+	Path string
+	mu   int
+}
+
+func Open(path string) *Store { return nil }
+
+func (s *Store) Get(key string) string { return "" }
+
+func helper() {}
+`)
+	mustWrite("store/store_test.go", "package store\n\nfunc TestX() {}\n")
+	mustWrite("store/testdata/skip.go", "package skip\n\nfunc Skip() {}\n")
+	mustWrite("store/gen.go", "// Code generated by tool. DO NOT EDIT.\n\npackage store\n\nfunc Gen() {}\n")
+	mustWrite("vendor/example.com/dep/dep.go", "package dep\n\nfunc Dep() {}\n")
+	run("init")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Tester")
+	run("add", "-A")
+	run("commit", "-m", "base")
+	mustWrite("store/store.go", "package store\n")
+
+	ctx := context.Background()
+	files, err := GoFilesAtRef(ctx, root, "HEAD")
+	if err != nil {
+		t.Fatalf("GoFilesAtRef() error = %v", err)
+	}
+	// Like sync, generated and vendored files are kept but flagged.
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.RelPath)
+		if want := f.RelPath != "store/store.go"; f.Generated != want {
+			t.Fatalf("%s: Generated = %v, want %v", f.RelPath, f.Generated, want)
+		}
+	}
+	if got := strings.Join(paths, " "); got != "store/gen.go store/store.go vendor/example.com/dep/dep.go" {
+		t.Fatalf("GoFilesAtRef() paths = %s", got)
+	}
+	if files[1].AbsPath != filepath.Join(root, "store", "store.go") {
+		t.Fatalf("GoFilesAtRef() AbsPath = %s, want the committed store/store.go", files[1].AbsPath)
+	}
+
+	decls, err := ExportedDecls("example.com/lib", files[1:2])
+	if err != nil {
+		t.Fatalf("ExportedDecls() error = %v", err)
+	}
+	want := []ExportedDecl{
+		{Package: "store", PackageName: "store", FilePath: "store/store.go", Line: 3, Kind: "type", Name: "Store",
+			Signature: "struct {\n\tPath\tstring\n\tmu\tint\n}", Fields: []ExportedField{{Name: "Path", Type: "string"}}},
+		{Package: "store", PackageName: "store", FilePath: "store/store.go", Line: 8, Kind: "func", Name: "Open",
+			Signature: "func(path string) *Store"},
+		{Package: "store", PackageName: "store", FilePath: "store/store.go", Line: 10, Kind: "method", Name: "Get",
+			Receiver: "*Store", Signature: "func(key string) string"},
+	}
+	if !reflect.DeepEqual(decls, want) {
+		t.Fatalf("ExportedDecls() =\n%+v\nwant\n%+v", decls, want)
+	}
+
+	if _, err := GoFilesAtRef(ctx, root, "no-such-ref"); err == nil {
+		t.Fatal("GoFilesAtRef(no-such-ref) succeeded, want an error")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 )
@@ -23,67 +24,171 @@ type SourceFile struct {
 	Content []byte
 	Hash    string
 	Lines   int
+	// Language is the FileIndexer language of a file that is not Go
+	// source, such as "sql"; it is empty for Go files.
+	Language string
+	// Generated is set for generated code and for files vendored under
+	// vendor/: indexed, but not code the module's authors edit.
+	Generated bool
 }
 
+// vendorDir is the module-relative directory of vendored dependencies. Only
+// the module root's vendor directory is walked, as the go command only
+// reads that one.
+const vendorDir = "vendor"
+
+// CollectEligibleGoFiles returns the Go source files a sync indexes, sorted
+// by path.
 func CollectEligibleGoFiles(moduleRoot string) ([]SourceFile, error) {
+	files, _, _, err := collectFiles(moduleRoot, false)
+	return files, err
+}
+
+// CollectEligibleFiles returns the files a sync indexes, sorted by path: the
+// Go source files and the files a FileIndexer claims.
+func CollectEligibleFiles(moduleRoot string) ([]SourceFile, error) {
+	files, _, _, err := collectFiles(moduleRoot, true)
+	return files, err
+}
+
+// CollectSyncFiles is CollectEligibleFiles that also returns the paths the
+// rules of IgnoreFiles left out, sorted, and the invalid rules it skipped.
+func CollectSyncFiles(moduleRoot string) ([]SourceFile, []IgnoredPath, []IgnoreWarning, error) {
+	return collectFiles(moduleRoot, true)
+}
+
+func collectFiles(moduleRoot string, withIndexers bool) ([]SourceFile, []IgnoredPath, []IgnoreWarning, error) {
 	files := make([]SourceFile, 0, 128)
+	var ignored []IgnoredPath
+	ignore := newIgnoreMatcher(moduleRoot)
 
 	err := filepath.WalkDir(moduleRoot, func(path string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
 		}
 
+		rel, err := filepathRel(moduleRoot, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
 		if d.IsDir() {
-			if shouldSkipDir(moduleRoot, path, d.Name()) {
+			if shouldSkipDir(moduleRoot, path, d.Name()) && rel != vendorDir {
 				return filepath.SkipDir
 			}
-			return nil
+			if rel == "." {
+				return ignore.load("")
+			}
+			if rule, ok := ignore.match(rel, true); ok {
+				ignored = append(ignored, IgnoredPath{Path: rel + "/", Rule: rule.pattern, Source: rule.source})
+				return filepath.SkipDir
+			}
+			return ignore.load(rel)
 		}
 
 		name := d.Name()
 		if !strings.HasSuffix(name, ".go") {
+			if !withIndexers || fileIndexerFor(name) == nil {
+				return nil
+			}
+		} else if strings.HasSuffix(name, "_test.go") {
 			return nil
 		}
-		if strings.HasSuffix(name, "_test.go") {
+		if rule, ok := ignore.match(rel, false); ok {
+			ignored = append(ignored, IgnoredPath{Path: rel, Rule: rule.pattern, Source: rule.source})
 			return nil
 		}
 
-		rel, err := filepathRel(moduleRoot, path)
+		content, err := readFile(path)
 		if err != nil {
 			return err
 		}
-		rel = filepath.ToSlash(rel)
 
-		content, err := readFile(path)
+		files = append(files, newSourceFile(path, rel, content))
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("walk module files: %w", err)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].RelPath < files[j].RelPath
+	})
+	sort.Slice(ignored, func(i, j int) bool {
+		return ignored[i].Path < ignored[j].Path
+	})
+	return files, ignored, ignore.warnings, nil
+}
+
+// TestFileCounts walks moduleRoot as CollectEligibleGoFiles does and counts
+// the _test.go files in each package directory, keyed by module-relative
+// path ("." for the module root).
+func TestFileCounts(moduleRoot string) (map[string]int, error) {
+	counts := map[string]int{}
+	ignore := newIgnoreMatcher(moduleRoot)
+	err := filepath.WalkDir(moduleRoot, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepathRel(moduleRoot, path)
 		if err != nil {
 			return err
 		}
-		if isGeneratedGoFile(content) {
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if shouldSkipDir(moduleRoot, path, d.Name()) {
+				return filepath.SkipDir
+			}
+			if rel == "." {
+				return ignore.load("")
+			}
+			if _, ok := ignore.match(rel, true); ok {
+				return filepath.SkipDir
+			}
+			return ignore.load(rel)
+		}
+		if !strings.HasSuffix(d.Name(), "_test.go") {
 			return nil
 		}
-
-		sum := sha256.Sum256(content)
-		files = append(files, SourceFile{
-			AbsPath: path,
-			RelPath: rel,
-			Content: content,
-			Hash:    hex.EncodeToString(sum[:]),
-			Lines:   bytes.Count(content, []byte("\n")) + 1,
-		})
+		if _, ok := ignore.match(rel, false); ok {
+			return nil
+		}
+		dir, err := filepathRel(moduleRoot, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		counts[filepath.ToSlash(dir)]++
 		return nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf("walk module files: %w", err)
+		return nil, fmt.Errorf("walk test files: %w", err)
 	}
+	return counts, nil
+}
 
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].RelPath < files[j].RelPath
-	})
-	return files, nil
+func newSourceFile(absPath, relPath string, content []byte) SourceFile {
+	sum := sha256.Sum256(content)
+	file := SourceFile{
+		AbsPath: absPath,
+		RelPath: relPath,
+		Content: content,
+		Hash:    hex.EncodeToString(sum[:]),
+		Lines:   bytes.Count(content, []byte("\n")) + 1,
+		// Only Go files are checked for the generated header: other
+		// languages have no such convention to rely on.
+		Generated: isVendored(relPath) || strings.HasSuffix(relPath, ".go") && isGeneratedGoFile(content),
+	}
+	if !strings.HasSuffix(relPath, ".go") {
+		if ix := fileIndexerFor(relPath); ix != nil {
+			file.Language = ix.Language()
+		}
+	}
+	return file
 }
 
 func CurrentFingerprint(moduleRoot string) (string, int, error) {
-	files, err := CollectEligibleGoFiles(moduleRoot)
+	files, err := CollectEligibleFiles(moduleRoot)
 	if err != nil {
 		return "", 0, err
 	}
@@ -111,11 +216,31 @@ func shouldSkipDir(moduleRoot, path, name string) bool {
 	return false
 }
 
+// isVendored reports whether the module-relative path rel is under the
+// module's vendor directory.
+func isVendored(rel string) bool {
+	return strings.HasPrefix(rel, vendorDir+"/")
+}
+
+// generatedHeader is the comment line that marks a Go file as generated,
+// per https://go.dev/s/generatedcode.
+var generatedHeader = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// isGeneratedGoFile reports whether the Go source content has the generated
+// header on a line of its own before the package clause. Text that merely
+// mentions the header, such as a string literal a generator prints, does not
+// count.
 func isGeneratedGoFile(content []byte) bool {
-	prefix := content
-	if len(prefix) > 4096 {
-		prefix = prefix[:4096]
+	for rest := content; len(rest) > 0; {
+		var line []byte
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if generatedHeader.Match(line) {
+			return true
+		}
+		if fields := bytes.Fields(line); len(fields) > 0 && string(fields[0]) == "package" {
+			return false
+		}
 	}
-	s := string(prefix)
-	return strings.Contains(s, "Code generated") && strings.Contains(s, "DO NOT EDIT")
+	return false
 }
@@ -24,30 +24,36 @@ func TestCollectEligibleGoFilesAndFingerprint(t *testing.T) {
 	mustWrite("main.go", "package main\nfunc main(){}\n")
 	mustWrite("sub/util.go", "package sub\nfunc Util(){}\n")
 	mustWrite("x_test.go", "package main\n")
-	mustWrite("vendor/ignored.go", "package vendor\n")
+	mustWrite("vendor/example.com/dep/dep.go", "package dep\n")
+	mustWrite("sub/vendor/ignored.go", "package vendor\n")
 	mustWrite("testdata/ignored.go", "package testdata\n")
 	mustWrite(".cache/ignored.go", "package cache\n")
 	mustWrite("generated.go", "// Code generated by x. DO NOT EDIT.\npackage main\n")
+	mustWrite("prints_header.go", "package main\n\nconst header = \"// Code generated by x. DO NOT EDIT.\\n\"\n")
 
 	files, err := CollectEligibleGoFiles(root)
 	if err != nil {
 		t.Fatalf("CollectEligibleGoFiles() error = %v", err)
 	}
-	if len(files) != 2 {
-		t.Fatalf("expected 2 eligible files, got %d", len(files))
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.RelPath)
+		if want := f.RelPath == "generated.go" || strings.HasPrefix(f.RelPath, "vendor/"); f.Generated != want {
+			t.Fatalf("%s: Generated = %v, want %v", f.RelPath, f.Generated, want)
+		}
 	}
-	if files[0].RelPath != "main.go" || files[1].RelPath != "sub/util.go" {
-		t.Fatalf("unexpected rel paths: %+v", files)
+	if got := strings.Join(paths, " "); got != "generated.go main.go prints_header.go sub/util.go vendor/example.com/dep/dep.go" {
+		t.Fatalf("unexpected rel paths: %s", got)
 	}
-	if files[0].Lines == 0 || files[0].Hash == "" {
-		t.Fatalf("expected lines/hash to be populated: %+v", files[0])
+	if files[1].Lines == 0 || files[1].Hash == "" {
+		t.Fatalf("expected lines/hash to be populated: %+v", files[1])
 	}
 
 	fp, count, err := CurrentFingerprint(root)
 	if err != nil {
 		t.Fatalf("CurrentFingerprint() error = %v", err)
 	}
-	if count != 2 || fp == "" {
+	if count != 5 || fp == "" {
 		t.Fatalf("unexpected fingerprint result count=%d fp=%q", count, fp)
 	}
 
@@ -112,11 +118,21 @@ func TestShouldSkipDir(t *testing.T) {
 }
 
 func TestIsGeneratedGoFile(t *testing.T) {
-	if !isGeneratedGoFile([]byte("// Code generated by tool. DO NOT EDIT.\n")) {
-		t.Fatal("expected generated file detection")
-	}
-	prefix := strings.Repeat("x", 5000)
-	if isGeneratedGoFile([]byte(prefix)) {
-		t.Fatal("did not expect generated detection for random content")
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"header", "// Code generated by tool. DO NOT EDIT.\n\npackage gen\n", true},
+		{"header after license", "// Copyright 2026.\n\n// Code generated by tool. DO NOT EDIT.\r\npackage gen\n", true},
+		{"header in string literal", "package schema\n\nconst header = \"// Code generated by recon schema --go. DO NOT EDIT.\\n\"\n", false},
+		{"header printed by generator", "package schema\n\nfunc f() {\n\tfmt.Println(`\n// Code generated by x. DO NOT EDIT.\n`)\n}\n", false},
+		{"loose mention", "// Code generated by hand, but DO NOT EDIT it either.\npackage gen\n", false},
+		{"no header", strings.Repeat("x", 5000), false},
+	}
+	for _, tt := range tests {
+		if got := isGeneratedGoFile([]byte(tt.content)); got != tt.want {
+			t.Errorf("%s: isGeneratedGoFile() = %v, want %v", tt.name, got, tt.want)
+		}
 	}
 }
@@ -3,22 +3,141 @@ package index
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/logging"
 )
 
+// DefaultExecTimeout is how long a git subprocess may run when the context
+// carries no limit of its own.
+const DefaultExecTimeout = 30 * time.Second
+
+type execTimeoutKey struct{}
+
+// WithExecTimeout returns ctx carrying the time limit for each git
+// subprocess started under it. Zero or less disables the limit.
+func WithExecTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, execTimeoutKey{}, timeout)
+}
+
+// ExecContext returns ctx bounded by the exec timeout it carries, or by
+// DefaultExecTimeout when it carries none, for running one subprocess.
+func ExecContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout, ok := ctx.Value(execTimeoutKey{}).(time.Duration)
+	if !ok {
+		timeout = DefaultExecTimeout
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// GitOutput runs git in moduleRoot under the context's exec timeout and
+// returns its stdout.
+func GitOutput(ctx context.Context, moduleRoot string, args ...string) ([]byte, error) {
+	ctx, cancel := ExecContext(ctx)
+	defer cancel()
+	args = append([]string{"-C", moduleRoot}, args...)
+	start := time.Now()
+	out, err := exec.CommandContext(ctx, "git", args...).Output()
+	logging.Exec(ctx, "git", args, start, err)
+	return out, err
+}
+
 func CurrentGitState(ctx context.Context, moduleRoot string) (commit string, dirty bool) {
-	rev := exec.CommandContext(ctx, "git", "-C", moduleRoot, "rev-parse", "HEAD")
-	revOut, revErr := rev.Output()
+	revOut, revErr := GitOutput(ctx, moduleRoot, "rev-parse", "HEAD")
 	if revErr == nil {
 		commit = strings.TrimSpace(string(revOut))
 	}
 
-	status := exec.CommandContext(ctx, "git", "-C", moduleRoot, "status", "--porcelain")
-	statusOut, statusErr := status.Output()
+	statusOut, statusErr := GitOutput(ctx, moduleRoot, "status", "--porcelain")
 	if statusErr == nil {
 		dirty = len(bytes.TrimSpace(statusOut)) > 0
 	}
 
 	return commit, dirty
 }
+
+// CurrentBranch returns the checked-out git branch, or "" when moduleRoot is
+// not in a git repository or HEAD is detached.
+func CurrentBranch(ctx context.Context, moduleRoot string) string {
+	out, err := GitOutput(ctx, moduleRoot, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// LatestTag returns the most recent tag reachable from HEAD in moduleRoot.
+func LatestTag(ctx context.Context, moduleRoot string) (string, error) {
+	out, err := GitOutput(ctx, moduleRoot, "describe", "--tags", "--abbrev=0")
+	if err != nil {
+		return "", fmt.Errorf("find latest tag: %w", gitError(err))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// CommitsSince counts the commits reachable from HEAD but not from ref.
+func CommitsSince(ctx context.Context, moduleRoot, ref string) (int, error) {
+	out, err := GitOutput(ctx, moduleRoot, "rev-list", "--count", ref+"..HEAD")
+	if err != nil {
+		return 0, fmt.Errorf("count commits since %s: %w", ref, gitError(err))
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return 0, fmt.Errorf("count commits since %s: %w", ref, err)
+	}
+	return n, nil
+}
+
+// StagedFiles returns the module-relative paths of the files staged for
+// commit under moduleRoot that SyncFiles can index, deletions included.
+func StagedFiles(ctx context.Context, moduleRoot string) ([]string, error) {
+	out, err := GitOutput(ctx, moduleRoot, "diff", "--cached", "--name-only", "--relative", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("list staged files: %w", gitError(err))
+	}
+	var files []string
+	for _, rel := range strings.Split(string(out), "\x00") {
+		if rel != "" && (strings.HasSuffix(rel, ".go") || fileIndexerFor(rel) != nil) {
+			files = append(files, rel)
+		}
+	}
+	return files, nil
+}
+
+// GitHooksDir returns the directory git runs hooks from for the repository
+// holding moduleRoot, honouring core.hooksPath, and moduleRoot's path below
+// the top of the work tree ("" at the top, otherwise ending in a slash).
+func GitHooksDir(ctx context.Context, moduleRoot string) (dir, prefix string, err error) {
+	out, err := GitOutput(ctx, moduleRoot, "rev-parse", "--git-path", "hooks", "--show-prefix")
+	if err != nil {
+		return "", "", fmt.Errorf("locate git hooks: %w", gitError(err))
+	}
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	dir = strings.TrimSpace(lines[0])
+	if len(lines) > 1 {
+		prefix = strings.TrimSpace(lines[1])
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(moduleRoot, dir)
+	}
+	return dir, prefix, nil
+}
+
+// gitError folds git's stderr into err, so an unknown revision reads as
+// git reports it.
+func gitError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(bytes.TrimSpace(exitErr.Stderr)) > 0 {
+		return errors.New(strings.TrimPrefix(strings.TrimSpace(string(exitErr.Stderr)), "fatal: "))
+	}
+	return err
+}
@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestCurrentGitState(t *testing.T) {
@@ -47,3 +48,31 @@ func TestCurrentGitState(t *testing.T) {
 		t.Fatal("expected dirty repo")
 	}
 }
+
+func TestExecContextTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	bounded, cancel := ExecContext(ctx)
+	deadline, ok := bounded.Deadline()
+	cancel()
+	if !ok || time.Until(deadline) > DefaultExecTimeout {
+		t.Fatalf("expected the default exec timeout, got deadline=%v ok=%v", deadline, ok)
+	}
+
+	unbounded, cancel := ExecContext(WithExecTimeout(ctx, 0))
+	_, ok = unbounded.Deadline()
+	cancel()
+	if ok {
+		t.Fatal("expected no deadline with a zero exec timeout")
+	}
+
+	// A limit too short for git to start leaves the state unknown rather
+	// than blocking.
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "-C", repo, "init").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v (%s)", err, out)
+	}
+	if branch := CurrentBranch(WithExecTimeout(ctx, time.Nanosecond), repo); branch != "" {
+		t.Fatalf("expected timed-out branch lookup to return empty, got %q", branch)
+	}
+}
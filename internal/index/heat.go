@@ -0,0 +1,172 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Heat levels of a package, from its recent commits under a HeatPolicy.
+const (
+	HeatHot  = "hot"
+	HeatWarm = "warm"
+	HeatCold = "cold"
+)
+
+// HeatPolicy sets how package heat is classified: a package with Hot or more
+// commits in the last WindowDays days is hot, one with Warm or more is warm,
+// and the rest are cold. Zero fields take DefaultHeatPolicy's values.
+type HeatPolicy struct {
+	WindowDays int `json:"window_days"`
+	Hot        int `json:"hot"`
+	Warm       int `json:"warm"`
+}
+
+// DefaultHeatPolicy counts the last 30 days, with 4 commits for hot and 1
+// for warm.
+var DefaultHeatPolicy = HeatPolicy{WindowDays: 30, Hot: 4, Warm: 1}
+
+// OrDefault returns p with its zero fields set from DefaultHeatPolicy.
+func (p HeatPolicy) OrDefault() HeatPolicy {
+	if p.WindowDays <= 0 {
+		p.WindowDays = DefaultHeatPolicy.WindowDays
+	}
+	if p.Hot <= 0 {
+		p.Hot = DefaultHeatPolicy.Hot
+	}
+	if p.Warm <= 0 {
+		p.Warm = DefaultHeatPolicy.Warm
+	}
+	return p
+}
+
+// Validate reports thresholds that cannot classify anything as warm.
+func (p HeatPolicy) Validate() error {
+	p = p.OrDefault()
+	if p.Warm > p.Hot {
+		return fmt.Errorf("the warm threshold (%d commits) must not exceed the hot threshold (%d)", p.Warm, p.Hot)
+	}
+	return nil
+}
+
+// Level classifies a package by its commit count in the window.
+func (p HeatPolicy) Level(commits int) string {
+	p = p.OrDefault()
+	switch {
+	case commits >= p.Hot:
+		return HeatHot
+	case commits >= p.Warm:
+		return HeatWarm
+	default:
+		return HeatCold
+	}
+}
+
+// LogArgs are the git arguments whose output CountPackageCommits and
+// CountPackageContributors read: each commit in the window as a NUL byte and
+// its author's name, followed by the files it changed, relative to the
+// module root and limited to it.
+func (p HeatPolicy) LogArgs() []string {
+	since := fmt.Sprintf("--since=%d days ago", p.OrDefault().WindowDays)
+	return []string{"log", since, "--name-only", "--relative", "--format=%x00%an", "--", "."}
+}
+
+// PackageForFile returns the indexed package that owns file, a slash-separated
+// module-relative path: the package with the longest directory containing it,
+// so internal/a/b/x.go belongs to internal/a/b rather than internal/a, and
+// internal/ab/x.go never to internal/a. Files outside every package go to the
+// root package "." when it is indexed, and to "" otherwise.
+func PackageForFile(file string, packages map[string]bool) string {
+	for dir := path.Dir(file); ; dir = path.Dir(dir) {
+		if packages[dir] {
+			return dir
+		}
+		if dir == "." || dir == "/" {
+			return ""
+		}
+	}
+}
+
+// Contributor is an author of commits to a package and how many of them
+// they made.
+type Contributor struct {
+	Name    string `json:"name"`
+	Commits int    `json:"commits"`
+}
+
+// CountPackageCommits counts, for each package in packages, the commits in
+// log (the output of git with HeatPolicy.LogArgs) that changed at least one
+// of its files. A commit touching several files of one package counts once.
+func CountPackageCommits(log []byte, packages []string) map[string]int {
+	counts := map[string]int{}
+	forEachPackageCommit(log, packages, func(pkg, _ string) { counts[pkg]++ })
+	return counts
+}
+
+// CountPackageContributors ranks, for each package in packages, the authors
+// of the commits in log that changed its files: most commits first, then by
+// name.
+func CountPackageContributors(log []byte, packages []string) map[string][]Contributor {
+	byAuthor := map[string]map[string]int{}
+	forEachPackageCommit(log, packages, func(pkg, author string) {
+		if author == "" {
+			return
+		}
+		if byAuthor[pkg] == nil {
+			byAuthor[pkg] = map[string]int{}
+		}
+		byAuthor[pkg][author]++
+	})
+	ranked := make(map[string][]Contributor, len(byAuthor))
+	for pkg, authors := range byAuthor {
+		list := make([]Contributor, 0, len(authors))
+		for name, n := range authors {
+			list = append(list, Contributor{Name: name, Commits: n})
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Commits != list[j].Commits {
+				return list[i].Commits > list[j].Commits
+			}
+			return list[i].Name < list[j].Name
+		})
+		ranked[pkg] = list
+	}
+	return ranked
+}
+
+// forEachPackageCommit calls fn once for each commit in log and package in
+// packages it changed, with the commit's author.
+func forEachPackageCommit(log []byte, packages []string, fn func(pkg, author string)) {
+	known := make(map[string]bool, len(packages))
+	for _, p := range packages {
+		known[p] = true
+	}
+	for _, commit := range strings.Split(string(log), "\x00") {
+		author, files, _ := strings.Cut(commit, "\n")
+		author = strings.TrimSpace(author)
+		touched := map[string]bool{}
+		for _, line := range strings.Split(files, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			if pkg := PackageForFile(line, known); pkg != "" && !touched[pkg] {
+				touched[pkg] = true
+				fn(pkg, author)
+			}
+		}
+	}
+}
+
+// PackageCommits runs git in moduleRoot and counts the commits of each
+// package in packages within the policy's window, and who made them. It
+// fails when moduleRoot is not in a git repository.
+func PackageCommits(ctx context.Context, moduleRoot string, packages []string, policy HeatPolicy) (map[string]int, map[string][]Contributor, error) {
+	out, err := GitOutput(ctx, moduleRoot, policy.LogArgs()...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return CountPackageCommits(out, packages), CountPackageContributors(out, packages), nil
+}
@@ -0,0 +1,86 @@
+package index
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPackageForFile(t *testing.T) {
+	packages := map[string]bool{".": true, "internal/a": true, "internal/a/b": true, "internal/ab": true}
+	tests := map[string]string{
+		"main.go":                        ".",
+		"internal/a/x.go":                "internal/a",
+		"internal/a/b/x.go":              "internal/a/b",
+		"internal/a/b/testdata/in.txt":   "internal/a/b",
+		"internal/ab/x.go":               "internal/ab",
+		"internal/abc/x.go":              ".",
+		"docs/guide.md":                  ".",
+		"internal/a/c/deeper/unowned.go": "internal/a",
+	}
+	for file, want := range tests {
+		if got := PackageForFile(file, packages); got != want {
+			t.Errorf("PackageForFile(%q) = %q, want %q", file, got, want)
+		}
+	}
+
+	delete(packages, ".")
+	if got := PackageForFile("docs/guide.md", packages); got != "" {
+		t.Errorf("PackageForFile without a root package = %q, want empty", got)
+	}
+}
+
+func TestCountPackageCommits(t *testing.T) {
+	log := "\x00\n\ninternal/a/x.go\ninternal/a/y.go\n" +
+		"\x00\n\ninternal/a/b/x.go\nmain.go\n" +
+		"\x00\n\ninternal/ab/x.go\n" +
+		"\x00\n\nREADME.md\n"
+	got := CountPackageCommits([]byte(log), []string{".", "internal/a", "internal/a/b", "internal/ab", "internal/c"})
+	want := map[string]int{".": 2, "internal/a": 1, "internal/a/b": 1, "internal/ab": 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("CountPackageCommits() = %v, want %v", got, want)
+	}
+}
+
+func TestCountPackageContributors(t *testing.T) {
+	log := "\x00bob\n\ninternal/a/x.go\ninternal/a/y.go\n" +
+		"\x00alice\n\ninternal/a/z.go\nmain.go\n" +
+		"\x00carol\n\ninternal/a/x.go\n" +
+		"\x00carol\n\ninternal/a/y.go\n" +
+		"\x00\n\nmain.go\n"
+	got := CountPackageContributors([]byte(log), []string{".", "internal/a", "internal/c"})
+	want := map[string][]Contributor{
+		".":          {{Name: "alice", Commits: 1}},
+		"internal/a": {{Name: "carol", Commits: 2}, {Name: "alice", Commits: 1}, {Name: "bob", Commits: 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("CountPackageContributors() = %v, want %v", got, want)
+	}
+}
+
+func TestHeatPolicy(t *testing.T) {
+	var zero HeatPolicy
+	for commits, want := range map[int]string{0: HeatCold, 1: HeatWarm, 3: HeatWarm, 4: HeatHot, 12: HeatHot} {
+		if got := zero.Level(commits); got != want {
+			t.Errorf("zero policy Level(%d) = %q, want %q", commits, got, want)
+		}
+	}
+
+	custom := HeatPolicy{WindowDays: 7, Hot: 10, Warm: 3}
+	for commits, want := range map[int]string{2: HeatCold, 3: HeatWarm, 9: HeatWarm, 10: HeatHot} {
+		if got := custom.Level(commits); got != want {
+			t.Errorf("custom Level(%d) = %q, want %q", commits, got, want)
+		}
+	}
+	if got := custom.LogArgs()[1]; got != "--since=7 days ago" {
+		t.Errorf("LogArgs since = %q, want --since=7 days ago", got)
+	}
+	if got := (HeatPolicy{Hot: 8}).OrDefault(); got != (HeatPolicy{WindowDays: 30, Hot: 8, Warm: 1}) {
+		t.Errorf("OrDefault() = %+v", got)
+	}
+	if err := (HeatPolicy{Warm: 5}).Validate(); err == nil {
+		t.Error("Validate() accepted a warm threshold above the default hot one")
+	}
+	if err := custom.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
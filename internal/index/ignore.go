@@ -0,0 +1,221 @@
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFiles are the files whose gitignore-style rules keep paths out of
+// the index. Each directory's files are read in this order, so a rule in
+// .reconignore can re-include with "!" what .gitignore excludes.
+var IgnoreFiles = []string{".gitignore", ".reconignore"}
+
+// IgnoredPath is a path a sync left out because of an ignore rule: a
+// directory, ending in "/", or a file it would otherwise have indexed.
+type IgnoredPath struct {
+	Path string `json:"path"`
+	// Rule is the pattern as written, and Source the ignore file and line
+	// it is on, such as "internal/.gitignore:3".
+	Rule   string `json:"rule"`
+	Source string `json:"source"`
+}
+
+// IgnoreWarning is an ignore file line whose pattern could not be compiled.
+// As git does, sync skips the line and honors the rest of the file.
+type IgnoreWarning struct {
+	Rule   string `json:"rule"`
+	Source string `json:"source"`
+	Error  string `json:"error"`
+}
+
+func (w IgnoreWarning) String() string {
+	return fmt.Sprintf("%s: skipped invalid pattern %q: %s", w.Source, w.Rule, w.Error)
+}
+
+type ignoreRule struct {
+	pattern string
+	source  string
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// ignoreMatcher holds the ignore rules of the directories walked so far, by
+// module-relative directory ("" for the module root), and the lines it
+// skipped as invalid.
+type ignoreMatcher struct {
+	moduleRoot string
+	rules      map[string][]ignoreRule
+	warnings   []IgnoreWarning
+}
+
+func newIgnoreMatcher(moduleRoot string) *ignoreMatcher {
+	return &ignoreMatcher{moduleRoot: moduleRoot, rules: map[string][]ignoreRule{}}
+}
+
+// load reads the ignore files of the module-relative directory dir, once.
+func (m *ignoreMatcher) load(dir string) error {
+	if _, ok := m.rules[dir]; ok {
+		return nil
+	}
+	rules := []ignoreRule{}
+	for _, name := range IgnoreFiles {
+		content, err := readFile(filepath.Join(m.moduleRoot, filepath.FromSlash(dir), name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("read ignore file: %w", err)
+		}
+		source := path.Join(dir, name)
+		parsed, warnings, err := parseIgnoreRules(content, source)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, parsed...)
+		m.warnings = append(m.warnings, warnings...)
+	}
+	m.rules[dir] = rules
+	return nil
+}
+
+// match reports whether the module-relative path rel is ignored, and the
+// rule that decided it. Rules of deeper directories, and later rules within
+// a directory, take precedence, as in git.
+func (m *ignoreMatcher) match(rel string, isDir bool) (ignoreRule, bool) {
+	var (
+		decided ignoreRule
+		found   bool
+	)
+	dirs := strings.Split(rel, "/")
+	for depth := range dirs {
+		base := strings.Join(dirs[:depth], "/")
+		sub := strings.Join(dirs[depth:], "/")
+		for _, rule := range m.rules[base] {
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			if rule.re.MatchString(sub) {
+				decided, found = rule, true
+			}
+		}
+	}
+	return decided, found && !decided.negate
+}
+
+// ignored reports whether rel is ignored, loading the ignore files of its
+// ancestor directories first. A path inside an ignored directory is
+// ignored by the directory's rule.
+func (m *ignoreMatcher) ignored(rel string, isDir bool) (ignoreRule, bool, error) {
+	dirs := strings.Split(rel, "/")
+	for depth := range dirs {
+		if err := m.load(strings.Join(dirs[:depth], "/")); err != nil {
+			return ignoreRule{}, false, err
+		}
+		if depth == 0 {
+			continue
+		}
+		if rule, ok := m.match(strings.Join(dirs[:depth], "/"), true); ok {
+			return rule, true, nil
+		}
+	}
+	rule, ok := m.match(rel, isDir)
+	return rule, ok, nil
+}
+
+// parseIgnoreRules reads the rules of an ignore file. A line whose pattern
+// does not compile is skipped and reported as a warning.
+func parseIgnoreRules(content []byte, source string) ([]ignoreRule, []IgnoreWarning, error) {
+	var (
+		rules    []ignoreRule
+		warnings []IgnoreWarning
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if !strings.HasSuffix(text, `\ `) {
+			text = strings.TrimRight(text, " \t")
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		rule := ignoreRule{pattern: text, source: fmt.Sprintf("%s:%d", source, line)}
+		p := text
+		if strings.HasPrefix(p, "!") {
+			rule.negate, p = true, p[1:]
+		}
+		if strings.HasSuffix(p, "/") {
+			rule.dirOnly, p = true, strings.TrimRight(p, "/")
+		}
+		if p == "" {
+			continue
+		}
+		re, err := compileIgnorePattern(p)
+		if err != nil {
+			warnings = append(warnings, IgnoreWarning{Rule: text, Source: rule.source, Error: err.Error()})
+			continue
+		}
+		rule.re = re
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read %s: %w", source, err)
+	}
+	return rules, warnings, nil
+}
+
+// compileIgnorePattern turns a gitignore pattern, without its "!" and
+// trailing slash, into a regular expression over paths relative to the
+// ignore file's directory. A pattern with a slash is anchored there; others
+// match a name at any depth. "*" and "?" stay within a segment, "**" crosses
+// segments, "[...]" is a character class, and "\" escapes.
+func compileIgnorePattern(p string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		case p[i] == '\\' && i+1 < len(p):
+			i++
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		case p[i] == '[':
+			end := strings.IndexByte(p[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := p[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,132 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompileIgnorePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.pb.go", "api/v1/x.pb.go", true},
+		{"*.pb.go", "x.go", false},
+		{"gen", "internal/gen", true},
+		{"/gen", "internal/gen", false},
+		{"/gen", "gen", true},
+		{"internal/gen", "internal/gen", true},
+		{"internal/gen", "a/internal/gen", false},
+		{"**/mocks", "a/b/mocks", true},
+		{"**/mocks", "mocks", true},
+		{"docs/**", "docs/a/b.go", true},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"file?.go", "file1.go", true},
+		{"file?.go", "file/.go", false},
+		{"v[0-9].go", "v2.go", true},
+		{"v[!0-9].go", "v2.go", false},
+		{`\#literal`, "#literal", true},
+	}
+	for _, tt := range tests {
+		re, err := compileIgnorePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("compileIgnorePattern(%q) error = %v", tt.pattern, err)
+		}
+		if got := re.MatchString(tt.path); got != tt.want {
+			t.Errorf("pattern %q on %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseIgnoreRules(t *testing.T) {
+	rules, warnings, err := parseIgnoreRules([]byte("# comment\n\n!keep.go\nbuild/  \n/\n"), "sub/.gitignore")
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("parseIgnoreRules() error = %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %+v", rules)
+	}
+	if !rules[0].negate || rules[0].source != "sub/.gitignore:3" {
+		t.Fatalf("unexpected negated rule: %+v", rules[0])
+	}
+	if !rules[1].dirOnly || rules[1].pattern != "build/" || rules[1].source != "sub/.gitignore:4" {
+		t.Fatalf("unexpected directory rule: %+v", rules[1])
+	}
+
+	rules, warnings, err = parseIgnoreRules([]byte("*.tmp\n[z-a].go\nbuild/\n"), ".reconignore")
+	if err != nil {
+		t.Fatalf("parseIgnoreRules() with an invalid pattern error = %v", err)
+	}
+	if len(rules) != 2 || rules[0].pattern != "*.tmp" || rules[1].pattern != "build/" {
+		t.Fatalf("expected the valid rules around the invalid one, got %+v", rules)
+	}
+	if len(warnings) != 1 || warnings[0].Rule != "[z-a].go" || warnings[0].Source != ".reconignore:2" || warnings[0].Error == "" {
+		t.Fatalf("expected a warning for the invalid pattern, got %+v", warnings)
+	}
+}
+
+func TestCollectSyncFilesHonorsIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	mustWrite := func(path, body string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir for %s: %v", path, err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+
+	mustWrite("go.mod", "module example.com/m\n")
+	mustWrite(".gitignore", "gen/\n*_mock.go\n")
+	mustWrite(".reconignore", "[z-a]\n!keep_mock.go\n")
+	mustWrite("main.go", "package main\n")
+	mustWrite("gen/out.go", "package gen\n")
+	mustWrite("svc_mock.go", "package main\n")
+	mustWrite("keep_mock.go", "package main\n")
+	mustWrite("sub/.gitignore", "/local.go\n")
+	mustWrite("sub/local.go", "package sub\n")
+	mustWrite("sub/kept.go", "package sub\n")
+	mustWrite("sub/deep/local.go", "package deep\n")
+
+	files, ignored, warnings, err := CollectSyncFiles(root)
+	if err != nil {
+		t.Fatalf("CollectSyncFiles() error = %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Source != ".reconignore:1" {
+		t.Fatalf("expected the invalid .reconignore line skipped with a warning, got %+v", warnings)
+	}
+	var got []string
+	for _, f := range files {
+		if filepath.Ext(f.RelPath) == ".go" {
+			got = append(got, f.RelPath)
+		}
+	}
+	want := []string{"keep_mock.go", "main.go", "sub/deep/local.go", "sub/kept.go"}
+	if len(got) != len(want) {
+		t.Fatalf("indexed %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("indexed %v, want %v", got, want)
+		}
+	}
+
+	wantIgnored := []IgnoredPath{
+		{Path: "gen/", Rule: "gen/", Source: ".gitignore:1"},
+		{Path: "sub/local.go", Rule: "/local.go", Source: "sub/.gitignore:1"},
+		{Path: "svc_mock.go", Rule: "*_mock.go", Source: ".gitignore:2"},
+	}
+	if len(ignored) != len(wantIgnored) {
+		t.Fatalf("ignored %+v, want %+v", ignored, wantIgnored)
+	}
+	for i := range wantIgnored {
+		if ignored[i] != wantIgnored[i] {
+			t.Fatalf("ignored[%d] = %+v, want %+v", i, ignored[i], wantIgnored[i])
+		}
+	}
+}
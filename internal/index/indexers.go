@@ -0,0 +1,356 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FileIndexer indexes the files of a language other than Go. Sync records
+// every file an indexer matches under its language, with the symbols it
+// declares; such files belong to no package.
+type FileIndexer interface {
+	// Language names the files' language, as stored in files.language.
+	Language() string
+	// Match reports whether the module-relative path is one of its files.
+	Match(rel string) bool
+	// Symbols returns the symbols declared in src, in source order.
+	Symbols(src []byte) []FileSymbol
+}
+
+// FileSymbol is a symbol declared in a file of another language. Receiver
+// names the enclosing declaration, if any. Links are recorded as sync edges
+// from the symbol to Go symbols.
+type FileSymbol struct {
+	Kind      string
+	Name      string
+	Receiver  string
+	Signature string
+	Body      string
+	LineStart int
+	LineEnd   int
+	Links     []FileLink
+}
+
+// FileLink points a FileSymbol at the Go symbol Name of the package with
+// import path Package; an empty Package means the file's own directory.
+type FileLink struct {
+	Relation string
+	Package  string
+	Name     string
+}
+
+// fileIndexers are asked, in order, to claim each file that is not Go source.
+var fileIndexers = []FileIndexer{sqlIndexer{}, shellIndexer{}, protoIndexer{}}
+
+// fileIndexerFor returns the indexer of the module-relative path rel, or nil
+// when no indexer claims it.
+func fileIndexerFor(rel string) FileIndexer {
+	for _, ix := range fileIndexers {
+		if ix.Match(rel) {
+			return ix
+		}
+	}
+	return nil
+}
+
+// splitLanguages separates the Go source files from the files of other
+// languages, keeping their order.
+func splitLanguages(files []SourceFile) (goFiles, otherFiles []SourceFile) {
+	for _, f := range files {
+		if f.Language == "" {
+			goFiles = append(goFiles, f)
+		} else {
+			otherFiles = append(otherFiles, f)
+		}
+	}
+	return goFiles, otherFiles
+}
+
+// writeIndexedFile inserts the files row and symbols of file, a file claimed
+// by ix, and the edges of the symbols' links.
+func writeIndexedFile(ctx context.Context, tx *sql.Tx, file SourceFile, ix FileIndexer, modulePath string, now time.Time) error {
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO files (package_id, path, language, lines, hash, generated, created_at, updated_at)
+VALUES (NULL, ?, ?, ?, ?, ?, ?, ?);
+`, file.RelPath, ix.Language(), file.Lines, file.Hash, boolToInt(file.Generated), now.Format(time.RFC3339), now.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("insert file %s: %w", file.RelPath, err)
+	}
+	fileID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("read file id: %w", err)
+	}
+	for _, sym := range ix.Symbols(file.Content) {
+		bodyHash, err := storeBody(ctx, tx, sym.Body)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO symbols (file_id, kind, name, signature, body_hash, line_start, line_end, exported, receiver)
+VALUES (?, ?, ?, ?, ?, ?, ?, 0, ?)
+ON CONFLICT(file_id, kind, name, receiver) DO NOTHING;
+`, fileID, sym.Kind, sym.Name, sym.Signature, bodyHash, sym.LineStart, sym.LineEnd, sym.Receiver); err != nil {
+			return fmt.Errorf("insert symbol %s: %w", sym.Name, err)
+		}
+		if len(sym.Links) == 0 {
+			continue
+		}
+		var symbolID int64
+		if err := tx.QueryRowContext(ctx, `
+SELECT id FROM symbols WHERE file_id = ? AND kind = ? AND name = ? AND receiver = ?;
+`, fileID, sym.Kind, sym.Name, sym.Receiver).Scan(&symbolID); err != nil {
+			return fmt.Errorf("load symbol %s: %w", sym.Name, err)
+		}
+		for _, link := range sym.Links {
+			if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO edges (from_type, from_id, to_type, to_ref, relation, source, confidence, created_at)
+VALUES ('symbol', ?, 'symbol', ?, ?, 'sync', 'high', ?);
+`, symbolID, linkPackage(modulePath, file.RelPath, link.Package)+"."+link.Name, link.Relation, now.Format(time.RFC3339)); err != nil {
+				return fmt.Errorf("link symbol %s: %w", sym.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// linkPackage returns the package path edges use for a link's import path:
+// module-relative inside the module, the import path itself outside it, and
+// the directory of the file rel when importPath is empty.
+func linkPackage(modulePath, rel, importPath string) string {
+	switch {
+	case importPath == "":
+		return path.Dir(rel)
+	case importPath == modulePath:
+		return "."
+	case strings.HasPrefix(importPath, modulePath+"/"):
+		return strings.TrimPrefix(importPath, modulePath+"/")
+	}
+	return importPath
+}
+
+// sqlIndexer indexes .sql files, such as migrations. Each table or view the
+// file's statements create, alter, drop, or write to is a "table" or "view"
+// symbol whose signature lists those statements and whose body holds them.
+type sqlIndexer struct{}
+
+func (sqlIndexer) Language() string { return "sql" }
+
+func (sqlIndexer) Match(rel string) bool {
+	return strings.HasSuffix(strings.ToLower(rel), ".sql")
+}
+
+const sqlIdent = "[`\"\\[]?(?:\\w+[`\"\\]]?\\.[`\"\\[]?)?(\\w+)[`\"\\]]?"
+
+// sqlTargets match the statements that affect a table or view, capturing the
+// kind of object and its name.
+var sqlTargets = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"table", regexp.MustCompile(`(?is)^CREATE\s+(?:TEMP(?:ORARY)?\s+)?(?:VIRTUAL\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?` + sqlIdent)},
+	{"view", regexp.MustCompile(`(?is)^CREATE\s+(?:TEMP(?:ORARY)?\s+)?VIEW\s+(?:IF\s+NOT\s+EXISTS\s+)?` + sqlIdent)},
+	{"table", regexp.MustCompile(`(?is)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:IF\s+NOT\s+EXISTS\s+)?\S+\s+ON\s+` + sqlIdent)},
+	{"table", regexp.MustCompile(`(?is)^CREATE\s+(?:TEMP(?:ORARY)?\s+)?TRIGGER\s+.*?\bON\s+` + sqlIdent)},
+	{"table", regexp.MustCompile(`(?is)^(?:ALTER|DROP)\s+TABLE\s+(?:IF\s+EXISTS\s+)?` + sqlIdent)},
+	{"view", regexp.MustCompile(`(?is)^DROP\s+VIEW\s+(?:IF\s+EXISTS\s+)?` + sqlIdent)},
+	{"table", regexp.MustCompile(`(?is)^(?:INSERT\s+(?:OR\s+\w+\s+)?|REPLACE\s+)INTO\s+` + sqlIdent)},
+	{"table", regexp.MustCompile(`(?is)^UPDATE\s+(?:OR\s+\w+\s+)?` + sqlIdent)},
+	{"table", regexp.MustCompile(`(?is)^DELETE\s+FROM\s+` + sqlIdent)},
+}
+
+var sqlTriggerStart = regexp.MustCompile(`(?i)^CREATE\s+(?:TEMP(?:ORARY)?\s+)?TRIGGER\b`)
+
+func (sqlIndexer) Symbols(src []byte) []FileSymbol {
+	var (
+		out   []FileSymbol
+		index = map[string]int{}
+	)
+	for _, stmt := range sqlStatements(string(src)) {
+		for _, target := range sqlTargets {
+			m := target.re.FindStringSubmatch(stmt.Text)
+			if m == nil {
+				continue
+			}
+			key := target.kind + " " + m[1]
+			i, ok := index[key]
+			if !ok {
+				i = len(out)
+				index[key] = i
+				out = append(out, FileSymbol{Kind: target.kind, Name: m[1], LineStart: stmt.LineStart})
+			}
+			sym := &out[i]
+			head, _, _ := strings.Cut(stmt.Text, "\n")
+			head, _, _ = strings.Cut(head, "(")
+			if sym.Signature != "" {
+				sym.Signature += "; "
+				sym.Body += "\n\n"
+			}
+			sym.Signature += strings.TrimSpace(head)
+			sym.Body += stmt.Text + ";"
+			sym.LineEnd = stmt.LineEnd
+			break
+		}
+	}
+	return out
+}
+
+// sqlStatement is one statement of a SQL file, without its terminating
+// semicolon or the comments before it.
+type sqlStatement struct {
+	Text      string
+	LineStart int
+	LineEnd   int
+}
+
+// sqlStatements splits src into statements at the semicolons outside quotes
+// and comments. A CREATE TRIGGER statement runs to the semicolon after its
+// END.
+func sqlStatements(src string) []sqlStatement {
+	var (
+		out          []sqlStatement
+		start        = -1
+		line         = 1
+		startLine    int
+		quote        byte
+		lineComment  bool
+		blockComment bool
+	)
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if c == '\n' {
+			line++
+		}
+		switch {
+		case lineComment:
+			lineComment = c != '\n'
+			continue
+		case blockComment:
+			if c == '*' && i+1 < len(src) && src[i+1] == '/' {
+				blockComment = false
+				i++
+			}
+			continue
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			lineComment = true
+			i++
+			continue
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			blockComment = true
+			i++
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '[':
+			quote = ']'
+		}
+		if start < 0 {
+			if c == ';' || c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+				continue
+			}
+			start, startLine = i, line
+		}
+		if c != ';' {
+			continue
+		}
+		text := strings.TrimSpace(src[start:i])
+		if sqlTriggerStart.MatchString(text) && !strings.EqualFold(lastWord(text), "END") {
+			continue
+		}
+		out = append(out, sqlStatement{Text: text, LineStart: startLine, LineEnd: startLine + strings.Count(text, "\n")})
+		start = -1
+	}
+	if start >= 0 {
+		text := strings.TrimSpace(src[start:])
+		out = append(out, sqlStatement{Text: text, LineStart: startLine, LineEnd: startLine + strings.Count(text, "\n")})
+	}
+	return out
+}
+
+// lastWord returns the final run of letters in s.
+func lastWord(s string) string {
+	i := len(s)
+	for i > 0 && (s[i-1] >= 'A' && s[i-1] <= 'Z' || s[i-1] >= 'a' && s[i-1] <= 'z') {
+		i--
+	}
+	return s[i:]
+}
+
+// shellIndexer indexes shell scripts. Each function definition, in either
+// "name() {" or "function name {" form, is a "func" symbol.
+type shellIndexer struct{}
+
+func (shellIndexer) Language() string { return "sh" }
+
+func (shellIndexer) Match(rel string) bool {
+	lower := strings.ToLower(rel)
+	return strings.HasSuffix(lower, ".sh") || strings.HasSuffix(lower, ".bash")
+}
+
+var (
+	shellFunc = regexp.MustCompile(`^\s*(?:function\s+([A-Za-z_][\w:.-]*)\s*(?:\(\s*\))?|([A-Za-z_][\w:.-]*)\s*\(\s*\))\s*(?:\{|$)`)
+	// shellQuoted matches quoted strings, whose braces do not count.
+	shellQuoted = regexp.MustCompile(`'[^']*'|"(?:[^"\\]|\\.)*"`)
+)
+
+func (shellIndexer) Symbols(src []byte) []FileSymbol {
+	lines := strings.Split(string(src), "\n")
+	var out []FileSymbol
+	for i, l := range lines {
+		m := shellFunc.FindStringSubmatch(l)
+		if m == nil {
+			continue
+		}
+		name := m[1] + m[2]
+		end := shellFuncEnd(lines, i)
+		out = append(out, FileSymbol{
+			Kind:      "func",
+			Name:      name,
+			Signature: strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(m[0]), "{")),
+			Body:      strings.Join(lines[i:end+1], "\n"),
+			LineStart: i + 1,
+			LineEnd:   end + 1,
+		})
+	}
+	return out
+}
+
+// shellFuncEnd returns the index of the line closing the function whose
+// header is lines[start], by balancing the braces outside quotes and
+// comments. A function whose body never closes runs to the end of the file.
+func shellFuncEnd(lines []string, start int) int {
+	depth, opened := 0, false
+	for i := start; i < len(lines); i++ {
+		l := shellQuoted.ReplaceAllString(lines[i], "")
+		if j := strings.Index(l, " #"); j >= 0 {
+			l = l[:j]
+		}
+		if strings.HasPrefix(strings.TrimSpace(l), "#") {
+			continue
+		}
+		for _, c := range l {
+			switch c {
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+			}
+		}
+		if opened && depth <= 0 {
+			return i
+		}
+	}
+	return len(lines) - 1
+}
@@ -0,0 +1,180 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func TestSQLIndexerSymbols(t *testing.T) {
+	src := `-- Widgets; the semicolon in this comment is ignored.
+CREATE TABLE IF NOT EXISTS widgets (
+    id   INTEGER PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT 'a;b'
+);
+
+CREATE INDEX IF NOT EXISTS idx_widgets_name ON widgets(name);
+/* block; comment */
+CREATE TRIGGER widgets_touch AFTER UPDATE ON widgets BEGIN
+    UPDATE gadgets SET n = n + 1;
+END;
+
+INSERT OR IGNORE INTO "gadgets" (id) VALUES (1);
+ALTER TABLE main.widgets ADD COLUMN size INTEGER;
+CREATE VIEW big_widgets AS SELECT * FROM widgets WHERE size > 10;
+SELECT 1;
+DROP TABLE old_widgets`
+
+	got := sqlIndexer{}.Symbols([]byte(src))
+	type sym struct {
+		Kind, Name, Signature string
+		LineStart, LineEnd    int
+	}
+	var gotSyms []sym
+	for _, s := range got {
+		gotSyms = append(gotSyms, sym{s.Kind, s.Name, s.Signature, s.LineStart, s.LineEnd})
+	}
+	want := []sym{
+		{"table", "widgets", "CREATE TABLE IF NOT EXISTS widgets; CREATE INDEX IF NOT EXISTS idx_widgets_name ON widgets; CREATE TRIGGER widgets_touch AFTER UPDATE ON widgets BEGIN; ALTER TABLE main.widgets ADD COLUMN size INTEGER", 2, 14},
+		{"table", "gadgets", `INSERT OR IGNORE INTO "gadgets"`, 13, 13},
+		{"view", "big_widgets", "CREATE VIEW big_widgets AS SELECT * FROM widgets WHERE size > 10", 15, 15},
+		{"table", "old_widgets", "DROP TABLE old_widgets", 17, 17},
+	}
+	if !reflect.DeepEqual(gotSyms, want) {
+		t.Fatalf("Symbols() = %+v, want %+v", gotSyms, want)
+	}
+	wantBody := "INSERT OR IGNORE INTO \"gadgets\" (id) VALUES (1);"
+	if got[1].Body != wantBody {
+		t.Fatalf("gadgets body = %q, want %q", got[1].Body, wantBody)
+	}
+}
+
+func TestShellIndexerSymbols(t *testing.T) {
+	src := `#!/usr/bin/env bash
+set -euo pipefail
+
+log() { echo "[recon] $*" >&2; }
+
+function cleanup {
+  rm -rf "$tmp" # not a brace: }
+  echo "}"
+}
+
+build_all()
+{
+  for d in */; do
+    (cd "$d" && make)
+  done
+}
+
+echo "done() {"
+`
+	got := shellIndexer{}.Symbols([]byte(src))
+	type sym struct {
+		Name, Signature    string
+		LineStart, LineEnd int
+	}
+	var gotSyms []sym
+	for _, s := range got {
+		if s.Kind != "func" {
+			t.Fatalf("kind = %q, want func", s.Kind)
+		}
+		gotSyms = append(gotSyms, sym{s.Name, s.Signature, s.LineStart, s.LineEnd})
+	}
+	want := []sym{
+		{"log", "log()", 4, 4},
+		{"cleanup", "function cleanup", 6, 9},
+		{"build_all", "build_all()", 11, 16},
+	}
+	if !reflect.DeepEqual(gotSyms, want) {
+		t.Fatalf("Symbols() = %+v, want %+v", gotSyms, want)
+	}
+}
+
+func TestSyncIndexesOtherLanguages(t *testing.T) {
+	root := t.TempDir()
+	mustWrite := func(path, body string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", path, err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	mustWrite("go.mod", "module example.com/recon\n")
+	mustWrite("main.go", "package main\n\nfunc main() {}\n")
+	mustWrite("db/migrations/0001_init.up.sql", "CREATE TABLE widgets (id INTEGER);\n")
+	mustWrite("scripts/hook.sh", "greet() {\n  echo hi\n}\n")
+	mustWrite("notes.txt", "not indexed\n")
+
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	ctx := context.Background()
+	result, err := NewService(conn).Sync(ctx, root)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if result.IndexedFiles != 3 || result.IndexedSymbols != 3 || result.IndexedPackages != 1 {
+		t.Fatalf("Sync() = %d files, %d symbols, %d packages; want 3, 3, 1", result.IndexedFiles, result.IndexedSymbols, result.IndexedPackages)
+	}
+	fp, count, err := CurrentFingerprint(root)
+	if err != nil || count != 3 || fp != result.Fingerprint {
+		t.Fatalf("CurrentFingerprint() = %q, %d, %v; want the synced fingerprint of 3 files", fp, count, err)
+	}
+
+	symbols := func() map[string]string {
+		t.Helper()
+		rows, err := conn.Query(`
+SELECT f.path, f.language, s.kind, s.name
+FROM symbols s JOIN files f ON f.id = s.file_id
+WHERE f.package_id IS NULL ORDER BY f.path, s.name;`)
+		if err != nil {
+			t.Fatalf("query symbols: %v", err)
+		}
+		defer rows.Close()
+		got := map[string]string{}
+		for rows.Next() {
+			var path, lang, kind, name string
+			if err := rows.Scan(&path, &lang, &kind, &name); err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			got[path] = lang + " " + kind + " " + name
+		}
+		return got
+	}
+	want := map[string]string{
+		"db/migrations/0001_init.up.sql": "sql table widgets",
+		"scripts/hook.sh":                "sh func greet",
+	}
+	if got := symbols(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("indexed symbols = %v, want %v", got, want)
+	}
+
+	// A targeted sync re-indexes an edited SQL file in place.
+	mustWrite("db/migrations/0001_init.up.sql", "CREATE TABLE gadgets (id INTEGER);\n")
+	if _, err := NewService(conn).SyncFiles(ctx, root, []string{"db/migrations/0001_init.up.sql"}); err != nil {
+		t.Fatalf("SyncFiles() error = %v", err)
+	}
+	want["db/migrations/0001_init.up.sql"] = "sql table gadgets"
+	if got := symbols(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("indexed symbols after SyncFiles = %v, want %v", got, want)
+	}
+	if _, err := NewService(conn).SyncFiles(ctx, root, []string{"notes.txt"}); err == nil {
+		t.Fatal("SyncFiles(notes.txt) should reject a file no indexer claims")
+	}
+}
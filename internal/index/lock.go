@@ -0,0 +1,212 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+// SyncLockFile is the advisory lock a sync holds in .recon while it writes
+// the index, so a hook and a manual run don't interleave.
+const SyncLockFile = "sync.lock"
+
+// SyncLockGitIgnore is the .gitignore entry for the sync lock.
+var SyncLockGitIgnore = ".recon/" + SyncLockFile
+
+// syncLockMaxAge is how long a lock taken on another host is trusted. A
+// lock from this host is trusted while its process is alive.
+const syncLockMaxAge = time.Hour
+
+// syncTakeoverMaxAge is how long a takeover guard is trusted; an older one
+// was left by a process that died while taking over a stale lock.
+const syncTakeoverMaxAge = 10 * time.Second
+
+// syncLockPoll is how often a waiting sync retries the lock.
+var syncLockPoll = 200 * time.Millisecond
+
+// SyncLockHolder is the process that holds the sync lock.
+type SyncLockHolder struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	StartedAt time.Time `json:"started_at"`
+	// Nonce tells apart locks taken by the same process, so a stale lock
+	// is only removed while it is still the one found stale.
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// SyncRunningError is returned by LockSync when a live process holds the
+// sync lock.
+type SyncRunningError struct {
+	Holder SyncLockHolder
+}
+
+func (e SyncRunningError) Error() string {
+	return fmt.Sprintf("another sync is running (pid %d on %s, started %s)",
+		e.Holder.PID, e.Holder.Host, e.Holder.StartedAt.Format(time.RFC3339))
+}
+
+// SyncLock is a held sync lock.
+type SyncLock struct {
+	path  string
+	nonce string
+}
+
+// LockSync takes the sync lock of the module at moduleRoot. A lock left by
+// a process that has exited, or an unreadable one, is stale and taken
+// over, by one waiter at a time. When a live process holds it, LockSync
+// returns a SyncRunningError, or with wait, retries until the lock is free
+// or ctx is done.
+func LockSync(ctx context.Context, moduleRoot string, wait bool) (*SyncLock, error) {
+	path := filepath.Join(db.ReconDir(moduleRoot), SyncLockFile)
+	for {
+		nonce, err := createSyncLock(path)
+		if err == nil {
+			return &SyncLock{path: path, nonce: nonce}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		raw, holder, ok := readSyncLock(path)
+		if !ok || holder.stale(time.Now()) {
+			staleSyncLockFound()
+			took, err := takeOverSyncLock(path, raw)
+			if err != nil {
+				return nil, err
+			}
+			if took {
+				continue
+			}
+			// Another waiter is taking the stale lock over; retry once
+			// it is done.
+		} else if !wait {
+			return nil, SyncRunningError{Holder: holder}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(syncLockPoll):
+		}
+	}
+}
+
+// Unlock releases the lock. It is safe to call more than once, and leaves
+// alone a lock another process has taken since.
+func (l *SyncLock) Unlock() error {
+	if _, holder, ok := readSyncLock(l.path); !ok || holder.Nonce != l.nonce {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove sync lock: %w", err)
+	}
+	return nil
+}
+
+// createSyncLock writes this process's holder record beside path and links
+// it into place, so the lock appears with its contents or not at all, and
+// returns the lock's nonce. It fails with os.ErrExist when the lock is held.
+func createSyncLock(path string) (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate sync lock nonce: %w", err)
+	}
+	nonce := hex.EncodeToString(b[:])
+	host, _ := os.Hostname()
+	raw, err := json.Marshal(SyncLockHolder{PID: os.Getpid(), Host: host, StartedAt: time.Now().UTC(), Nonce: nonce})
+	if err != nil {
+		return "", fmt.Errorf("encode sync lock: %w", err)
+	}
+	tmp := path + "." + strconv.Itoa(os.Getpid()) + "." + nonce
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return "", fmt.Errorf("write sync lock: %w", err)
+	}
+	defer os.Remove(tmp)
+	if err := os.Link(tmp, path); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return "", err
+		}
+		return "", fmt.Errorf("take sync lock: %w", err)
+	}
+	return nonce, nil
+}
+
+// takeOverSyncLock removes the stale lock at path, whose contents were raw
+// when it was found stale. Waiters serialize on a guard file beside the lock
+// and each removes it only if it still holds raw, so a waiter that lost the
+// race never removes the lock the winner has just taken. It reports false
+// when another waiter holds the guard.
+func takeOverSyncLock(path string, raw []byte) (bool, error) {
+	guard := path + ".takeover"
+	f, err := os.OpenFile(guard, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if !errors.Is(err, os.ErrExist) {
+			return false, fmt.Errorf("guard stale sync lock: %w", err)
+		}
+		if info, err := os.Stat(guard); err == nil && time.Since(info.ModTime()) > syncTakeoverMaxAge {
+			_ = os.Remove(guard)
+		}
+		return false, nil
+	}
+	_ = f.Close()
+	defer os.Remove(guard)
+
+	current, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && !bytes.Equal(current, raw)) {
+		return true, nil // Released or taken afresh since it was read
+	}
+	if err != nil {
+		return false, fmt.Errorf("read stale sync lock: %w", err)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("remove stale sync lock: %w", err)
+	}
+	return true, nil
+}
+
+// readSyncLock reads the lock at path: its raw contents, and its holder
+// when they are a valid record.
+func readSyncLock(path string) ([]byte, SyncLockHolder, bool) {
+	var holder SyncLockHolder
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, SyncLockHolder{}, false
+	}
+	if json.Unmarshal(raw, &holder) != nil || holder.PID <= 0 {
+		return raw, SyncLockHolder{}, false
+	}
+	return raw, holder, true
+}
+
+// stale reports whether the holder can no longer be syncing: its process
+// on this host has exited, or its lock on another host is too old to trust.
+func (h SyncLockHolder) stale(now time.Time) bool {
+	if host, _ := os.Hostname(); h.Host != host {
+		return now.Sub(h.StartedAt) > syncLockMaxAge
+	}
+	return !processAlive(h.PID)
+}
+
+// staleSyncLockFound runs when LockSync finds a stale lock, before taking
+// it over; a package-level var so tests can race another waiter there.
+var staleSyncLockFound = func() {}
+
+// processAlive reports whether a process with pid is running. Signal 0
+// only probes; a process that exists but belongs to another user is alive.
+var processAlive = func(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return !errors.Is(proc.Signal(syscall.Signal(0)), os.ErrProcessDone)
+}
@@ -0,0 +1,154 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockSync(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".recon"), 0o755); err != nil {
+		t.Fatalf("mkdir .recon: %v", err)
+	}
+	ctx := context.Background()
+	lock, err := LockSync(ctx, root, false)
+	if err != nil {
+		t.Fatalf("LockSync: %v", err)
+	}
+
+	var running SyncRunningError
+	if _, err := LockSync(ctx, root, false); !errors.As(err, &running) || running.Holder.PID != os.Getpid() {
+		t.Fatalf("expected SyncRunningError held by this process, got %v", err)
+	}
+
+	origPoll := syncLockPoll
+	syncLockPoll = time.Millisecond
+	t.Cleanup(func() { syncLockPoll = origPoll })
+	cancelled, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := LockSync(cancelled, root, true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected waiting to stop with the context, got %v", err)
+	}
+
+	first := lock
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = first.Unlock()
+	}()
+	lock, err = LockSync(ctx, root, true)
+	if err != nil {
+		t.Fatalf("LockSync --wait: %v", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("second Unlock: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(root, ".recon")); len(entries) != 0 {
+		t.Fatalf("expected no lock files left, got %v", entries)
+	}
+}
+
+func TestLockSyncTakesOverStaleLocks(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, ".recon", SyncLockFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir .recon: %v", err)
+	}
+	host, _ := os.Hostname()
+	origAlive := processAlive
+	t.Cleanup(func() { processAlive = origAlive })
+	processAlive = func(int) bool { return false }
+
+	for name, content := range map[string]string{
+		"exited process":   `{"pid": 999999, "host": "` + host + `", "started_at": "2026-01-01T00:00:00Z"}`,
+		"old foreign host": `{"pid": 1, "host": "elsewhere", "started_at": "2020-01-01T00:00:00Z"}`,
+		"unreadable":       "{",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("%s: seed lock: %v", name, err)
+		}
+		lock, err := LockSync(context.Background(), root, false)
+		if err != nil {
+			t.Fatalf("%s: expected the stale lock to be taken over, got %v", name, err)
+		}
+		_ = lock.Unlock()
+	}
+
+	processAlive = func(int) bool { return true }
+	fresh := `{"pid": 1, "host": "elsewhere", "started_at": "` + time.Now().UTC().Format(time.RFC3339) + `"}`
+	if err := os.WriteFile(path, []byte(fresh), 0o644); err != nil {
+		t.Fatalf("seed lock: %v", err)
+	}
+	var running SyncRunningError
+	if _, err := LockSync(context.Background(), root, false); !errors.As(err, &running) || running.Holder.Host != "elsewhere" {
+		t.Fatalf("expected a recent lock from another host to be trusted, got %v", err)
+	}
+}
+
+func TestLockSyncStaleTakeoverAdmitsOneWaiter(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, ".recon", SyncLockFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir .recon: %v", err)
+	}
+	host, _ := os.Hostname()
+	origAlive, origFound := processAlive, staleSyncLockFound
+	t.Cleanup(func() { processAlive, staleSyncLockFound = origAlive, origFound })
+	processAlive = func(pid int) bool { return pid != 999999 }
+	stale := `{"pid": 999999, "host": "` + host + `", "started_at": "2026-01-01T00:00:00Z"}`
+	if err := os.WriteFile(path, []byte(stale), 0o644); err != nil {
+		t.Fatalf("seed lock: %v", err)
+	}
+
+	// Both waiters find the same stale lock; the second takes it over while
+	// the first is about to.
+	var second *SyncLock
+	staleSyncLockFound = func() {
+		staleSyncLockFound = func() {}
+		var err error
+		if second, err = LockSync(context.Background(), root, false); err != nil {
+			t.Fatalf("second waiter: %v", err)
+		}
+	}
+	var running SyncRunningError
+	if _, err := LockSync(context.Background(), root, false); !errors.As(err, &running) || running.Holder.PID != os.Getpid() {
+		t.Fatalf("expected the first waiter to find the lock retaken, got %v", err)
+	}
+	if second == nil {
+		t.Fatal("expected the second waiter to hold the lock")
+	}
+	if err := second.Unlock(); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 0 {
+		t.Fatalf("expected no lock files left, got %v", entries)
+	}
+}
+
+func TestUnlockLeavesAnotherHoldersLock(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, ".recon", SyncLockFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir .recon: %v", err)
+	}
+	lock, err := LockSync(context.Background(), root, false)
+	if err != nil {
+		t.Fatalf("LockSync: %v", err)
+	}
+	other := `{"pid": 1, "host": "elsewhere", "started_at": "2026-01-01T00:00:00Z", "nonce": "other"}`
+	if err := os.WriteFile(path, []byte(other), 0o644); err != nil {
+		t.Fatalf("replace lock: %v", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if raw, err := os.ReadFile(path); err != nil || string(raw) != other {
+		t.Fatalf("expected the other holder's lock kept, got %q (%v)", raw, err)
+	}
+}
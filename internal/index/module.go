@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -52,3 +53,126 @@ func ModulePath(moduleRoot string) (string, error) {
 	}
 	return "", errors.New("module path not found in go.mod")
 }
+
+// GoMod is the part of go.mod that describes the module's dependencies.
+type GoMod struct {
+	Module    string
+	Go        string
+	Toolchain string
+	Require   []Requirement
+	Replace   []Replacement
+}
+
+// Requirement is one require directive of go.mod.
+type Requirement struct {
+	Path     string `json:"path"`
+	Version  string `json:"version"`
+	Indirect bool   `json:"indirect,omitempty"`
+}
+
+// Replacement is one replace directive. OldVersion is empty when every
+// version of Old is replaced; NewVersion is empty for a directory.
+type Replacement struct {
+	Old        string
+	OldVersion string
+	New        string
+	NewVersion string
+}
+
+// ReplacementFor returns the replace directive that applies to req, preferring
+// one pinned to req's version over one for every version, as go does.
+func (m GoMod) ReplacementFor(req Requirement) (Replacement, bool) {
+	var (
+		found Replacement
+		ok    bool
+	)
+	for _, r := range m.Replace {
+		if r.Old != req.Path {
+			continue
+		}
+		if r.OldVersion == req.Version {
+			return r, true
+		}
+		if r.OldVersion == "" {
+			found, ok = r, true
+		}
+	}
+	return found, ok
+}
+
+// ParseGoMod reads the module path, go and toolchain versions, and require
+// and replace directives, single-line or parenthesized, of the go.mod at
+// moduleRoot. Other directives are skipped.
+func ParseGoMod(moduleRoot string) (GoMod, error) {
+	f, err := os.Open(filepath.Join(moduleRoot, "go.mod"))
+	if err != nil {
+		return GoMod{}, fmt.Errorf("open go.mod: %w", err)
+	}
+	defer f.Close()
+
+	var (
+		mod   GoMod
+		block string
+	)
+	scanner := moduleScanner(f)
+	for scanner.Scan() {
+		line, comment, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		verb := block
+		switch {
+		case block != "" && fields[0] == ")":
+			block = ""
+			continue
+		case block == "" && len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		case block == "":
+			verb, fields = fields[0], fields[1:]
+		}
+		for i := range fields {
+			fields[i] = strings.Trim(fields[i], `"`)
+		}
+
+		switch {
+		case verb == "module" && len(fields) == 1:
+			mod.Module = fields[0]
+		case verb == "go" && len(fields) == 1:
+			mod.Go = fields[0]
+		case verb == "toolchain" && len(fields) == 1:
+			mod.Toolchain = fields[0]
+		case verb == "require" && len(fields) == 2:
+			mod.Require = append(mod.Require, Requirement{
+				Path:     fields[0],
+				Version:  fields[1],
+				Indirect: strings.TrimSpace(comment) == "indirect",
+			})
+		case verb == "replace":
+			if r, ok := parseReplace(fields); ok {
+				mod.Replace = append(mod.Replace, r)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return GoMod{}, fmt.Errorf("read go.mod: %w", err)
+	}
+	return mod, nil
+}
+
+// parseReplace parses "old [version] => new [version]".
+func parseReplace(fields []string) (Replacement, bool) {
+	arrow := slices.Index(fields, "=>")
+	if arrow < 1 || arrow > 2 || len(fields)-arrow-1 < 1 || len(fields)-arrow-1 > 2 {
+		return Replacement{}, false
+	}
+	r := Replacement{Old: fields[0], New: fields[arrow+1]}
+	if arrow == 2 {
+		r.OldVersion = fields[1]
+	}
+	if len(fields) == arrow+3 {
+		r.NewVersion = fields[arrow+2]
+	}
+	return r, true
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -92,3 +93,71 @@ func TestModulePathErrors(t *testing.T) {
 type errorReader struct{}
 
 func (errorReader) Read([]byte) (int, error) { return 0, errors.New("boom") }
+
+func TestParseGoMod(t *testing.T) {
+	root := t.TempDir()
+	gomod := `module example.com/m
+
+go 1.26
+
+toolchain go1.26.1
+
+require github.com/spf13/cobra v1.10.1
+
+require (
+	// pinned for the sqlite driver
+	modernc.org/sqlite v1.40.0
+	golang.org/x/sys v0.38.0 // indirect
+)
+
+replace example.com/old => ./old
+
+replace (
+	modernc.org/sqlite => modernc.org/sqlite v1.39.0
+	modernc.org/sqlite v1.40.0 => ../sqlite
+	broken =>
+)
+
+exclude golang.org/x/sys v0.1.0
+`
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(gomod), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	mod, err := ParseGoMod(root)
+	if err != nil {
+		t.Fatalf("ParseGoMod() error = %v", err)
+	}
+	if mod.Module != "example.com/m" || mod.Go != "1.26" || mod.Toolchain != "go1.26.1" {
+		t.Fatalf("unexpected header: %+v", mod)
+	}
+	want := []Requirement{
+		{Path: "github.com/spf13/cobra", Version: "v1.10.1"},
+		{Path: "modernc.org/sqlite", Version: "v1.40.0"},
+		{Path: "golang.org/x/sys", Version: "v0.38.0", Indirect: true},
+	}
+	if !slices.Equal(mod.Require, want) {
+		t.Fatalf("Require = %+v\nwant %+v", mod.Require, want)
+	}
+	wantReplace := []Replacement{
+		{Old: "example.com/old", New: "./old"},
+		{Old: "modernc.org/sqlite", New: "modernc.org/sqlite", NewVersion: "v1.39.0"},
+		{Old: "modernc.org/sqlite", OldVersion: "v1.40.0", New: "../sqlite"},
+	}
+	if !slices.Equal(mod.Replace, wantReplace) {
+		t.Fatalf("Replace = %+v\nwant %+v", mod.Replace, wantReplace)
+	}
+
+	if r, ok := mod.ReplacementFor(want[1]); !ok || r.New != "../sqlite" {
+		t.Fatalf("version-pinned replace should win, got %+v", r)
+	}
+	if r, ok := mod.ReplacementFor(Requirement{Path: "modernc.org/sqlite", Version: "v1.0.0"}); !ok || r.NewVersion != "v1.39.0" {
+		t.Fatalf("expected the all-versions replace, got %+v", r)
+	}
+	if _, ok := mod.ReplacementFor(want[0]); ok {
+		t.Fatal("cobra is not replaced")
+	}
+
+	if _, err := ParseGoMod(t.TempDir()); err == nil || !strings.Contains(err.Error(), "open go.mod") {
+		t.Fatalf("expected open error, got %v", err)
+	}
+}
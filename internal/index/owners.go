@@ -0,0 +1,32 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/robertguss/recon/internal/owners"
+)
+
+// writeCodeOwners records the rules of the repository's CODEOWNERS file, if
+// it has one. The table was emptied with the rest of the index.
+func writeCodeOwners(ctx context.Context, tx *sql.Tx, moduleRoot string) error {
+	rules, found, err := owners.Load(moduleRoot)
+	if err != nil || !found {
+		return err
+	}
+	for _, r := range rules {
+		encoded, err := json.Marshal(r.Owners)
+		if err != nil {
+			return fmt.Errorf("encode owners: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO code_owners (source, module_dir, line, pattern, owners)
+VALUES (?, ?, ?, ?, ?);
+`, r.Source, r.ModuleDir, r.Line, r.Pattern, string(encoded)); err != nil {
+			return fmt.Errorf("insert code owner %s: %w", r.Pattern, err)
+		}
+	}
+	return nil
+}
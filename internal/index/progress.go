@@ -0,0 +1,50 @@
+package index
+
+import "time"
+
+// Phases of a full sync, in the order a Progress callback sees them.
+const (
+	// PhaseCollect walks the module for the files to index.
+	PhaseCollect = "collect"
+	// PhaseParse parses the files and writes their rows, one file at a time.
+	PhaseParse = "parse"
+	// PhaseLink resolves what needs every file written first: local
+	// imports, method sets, renames, code owners, and proto links.
+	PhaseLink = "link"
+	// PhaseCommit commits the sync transaction.
+	PhaseCommit = "commit"
+)
+
+// Progress reports how far a full sync has got. During PhaseParse, Done of
+// Total files are written and Package is the package of the latest one;
+// the other phases leave them zero, except Total, which is the number of
+// files found once PhaseCollect is over.
+type Progress struct {
+	Phase   string
+	Done    int
+	Total   int
+	Package string
+}
+
+// SyncTimings breaks down where a full sync spent its time, in
+// milliseconds. Files are parsed concurrently while earlier ones are
+// written, so ParseMS is the time spent waiting for a parsed file and
+// InsertMS the time spent writing rows and linking them.
+type SyncTimings struct {
+	CollectMS int64 `json:"collect_ms"`
+	ParseMS   int64 `json:"parse_ms"`
+	InsertMS  int64 `json:"insert_ms"`
+	CommitMS  int64 `json:"commit_ms"`
+	TotalMS   int64 `json:"total_ms"`
+}
+
+// report calls fn with p when it is set.
+func report(fn func(Progress), p Progress) {
+	if fn != nil {
+		fn(p)
+	}
+}
+
+func millisSince(t time.Time) int64 {
+	return time.Since(t).Milliseconds()
+}
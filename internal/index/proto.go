@@ -0,0 +1,274 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Relations of the edges sync derives from .proto files. They run from a
+// proto symbol to a package-qualified Go symbol and are rebuilt on every sync.
+const (
+	// RelationGeneratedAs links a proto message, enum, or service to the Go
+	// types protoc generates for it.
+	RelationGeneratedAs = "generated_as"
+	// RelationImplementedBy links a proto service to the Go types that embed
+	// its Unimplemented<Service>Server or Unsafe<Service>Server.
+	RelationImplementedBy = "implemented_by"
+)
+
+// protoIndexer indexes Protocol Buffers definitions. Services, their RPCs,
+// messages, and enums are symbols of kind proto_service, proto_rpc,
+// proto_message, and proto_enum; RPCs and nested declarations carry their
+// parent as receiver. Messages, enums, and services link to the Go types
+// generated for them in the file's go_package, or in its own directory when
+// it names none in the module.
+type protoIndexer struct{}
+
+func (protoIndexer) Language() string { return "proto" }
+
+func (protoIndexer) Match(rel string) bool {
+	return strings.HasSuffix(strings.ToLower(rel), ".proto")
+}
+
+// protoToken is a word, quoted string, or punctuation character of a
+// .proto file, with comments removed.
+type protoToken struct {
+	text string
+	line int
+}
+
+func (protoIndexer) Symbols(src []byte) []FileSymbol {
+	lines := strings.Split(string(src), "\n")
+	toks := protoTokens(string(src))
+
+	type frame struct {
+		kind  string // "message", "enum", "service", or "" for other blocks
+		name  string
+		index int // symbol index in out, or -1
+	}
+	var (
+		out       []FileSymbol
+		stack     []frame
+		goPackage string
+	)
+	parents := func() (receiver, goPrefix string) {
+		var names []string
+		for _, f := range stack {
+			if f.kind == "message" {
+				names = append(names, f.name)
+			}
+		}
+		if len(names) == 0 {
+			return "", ""
+		}
+		return strings.Join(names, "."), strings.Join(names, "_") + "_"
+	}
+	at := func(i int) string {
+		if i < len(toks) {
+			return toks[i].text
+		}
+		return ""
+	}
+
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		switch tok.text {
+		case "}":
+			if len(stack) == 0 {
+				continue
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if top.index >= 0 {
+				out[top.index].LineEnd = tok.line
+				out[top.index].Body = strings.Join(lines[out[top.index].LineStart-1:tok.line], "\n")
+			}
+			continue
+		case "{":
+			stack = append(stack, frame{index: -1})
+			continue
+		case "option":
+			if len(stack) == 0 && at(i+1) == "go_package" && at(i+2) == "=" {
+				goPackage = protoGoPackage(strings.Trim(at(i+3), `"'`))
+			}
+			continue
+		case "message", "enum", "service":
+			name := at(i + 1)
+			if at(i+2) != "{" || !isProtoIdent(name) {
+				continue
+			}
+			receiver, goPrefix := parents()
+			sym := FileSymbol{
+				Kind:      "proto_" + tok.text,
+				Name:      name,
+				Receiver:  receiver,
+				Signature: tok.text + " " + name,
+				LineStart: tok.line,
+				LineEnd:   tok.line,
+			}
+			if tok.text == "service" {
+				sym.Links = []FileLink{
+					{Relation: RelationGeneratedAs, Name: name + "Server"},
+					{Relation: RelationGeneratedAs, Name: name + "Client"},
+				}
+			} else {
+				sym.Links = []FileLink{{Relation: RelationGeneratedAs, Name: goPrefix + name}}
+			}
+			stack = append(stack, frame{kind: tok.text, name: name, index: len(out)})
+			out = append(out, sym)
+			i += 2
+			continue
+		case "rpc":
+			if len(stack) == 0 || stack[len(stack)-1].kind != "service" || !isProtoIdent(at(i+1)) {
+				continue
+			}
+			// rpc Name ( [stream] Req ) returns ( [stream] Resp ) { ... } or ;
+			j := i + 2
+			var sig strings.Builder
+			sig.WriteString("rpc " + at(i+1))
+			for ; j < len(toks) && toks[j].text != "{" && toks[j].text != ";"; j++ {
+				switch t := toks[j].text; {
+				case t == "(" && at(j-1) == "returns":
+					sig.WriteString(" (")
+				case t == "(" || t == ")":
+					sig.WriteString(t)
+				case at(j-1) == "(":
+					sig.WriteString(t)
+				default:
+					sig.WriteString(" " + t)
+				}
+			}
+			end := tok.line
+			if j < len(toks) {
+				end = toks[j].line
+			}
+			sym := FileSymbol{
+				Kind:      "proto_rpc",
+				Name:      at(i + 1),
+				Receiver:  stack[len(stack)-1].name,
+				Signature: sig.String(),
+				LineStart: tok.line,
+				LineEnd:   end,
+				Body:      strings.Join(lines[tok.line-1:end], "\n"),
+			}
+			if at(j) == "{" {
+				// An options block: the RPC ends where the block closes.
+				stack = append(stack, frame{kind: "rpc", name: sym.Name, index: len(out)})
+			}
+			out = append(out, sym)
+			i = j
+			continue
+		}
+	}
+
+	for i := range out {
+		for j := range out[i].Links {
+			out[i].Links[j].Package = goPackage
+		}
+	}
+	return out
+}
+
+// protoGoPackage returns the import path of a go_package option value such
+// as "example.com/m/gen/pb;pb", or "" when it names only a package.
+func protoGoPackage(value string) string {
+	importPath, _, _ := strings.Cut(value, ";")
+	if !strings.Contains(importPath, "/") {
+		return ""
+	}
+	return importPath
+}
+
+func isProtoIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+		case i > 0 && c >= '0' && c <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// protoTokens splits src into words (identifiers, numbers, and dotted
+// names), quoted strings, and single punctuation characters, dropping
+// comments and whitespace.
+func protoTokens(src string) []protoToken {
+	var toks []protoToken
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				end = len(src) - i - 2
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c && src[j] != '\n' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(src))
+			toks = append(toks, protoToken{text: src[i:j], line: line})
+			i = j
+		case c == '_' || c == '.' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] == '.' || src[j] >= '0' && src[j] <= '9' ||
+				src[j] >= 'A' && src[j] <= 'Z' || src[j] >= 'a' && src[j] <= 'z') {
+				j++
+			}
+			toks = append(toks, protoToken{text: src[i:j], line: line})
+			i = j
+		default:
+			toks = append(toks, protoToken{text: string(c), line: line})
+			i++
+		}
+	}
+	return toks
+}
+
+// linkProtoServices rebuilds the implemented_by edges from proto services
+// to the Go types embedding the service's Unimplemented or Unsafe server.
+// Those types may be in any file, so this runs once all files are written.
+func linkProtoServices(ctx context.Context, tx *sql.Tx, now time.Time) error {
+	if _, err := tx.ExecContext(ctx, `
+DELETE FROM edges WHERE source = 'sync' AND relation = ?;
+`, RelationImplementedBy); err != nil {
+		return fmt.Errorf("reset proto service links: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO edges (from_type, from_id, to_type, to_ref, relation, source, confidence, created_at)
+SELECT 'symbol', svc.id, 'symbol', p.path || '.' || impl.name, ?, 'sync', 'high', ?
+FROM symbols svc
+JOIN type_embeds te ON te.embedded_name IN ('Unimplemented' || svc.name || 'Server', 'Unsafe' || svc.name || 'Server')
+JOIN symbols impl ON impl.id = te.symbol_id
+JOIN files f ON f.id = impl.file_id
+JOIN packages p ON p.id = f.package_id
+WHERE svc.kind = 'proto_service';
+`, RelationImplementedBy, now.Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("link proto services: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func TestProtoIndexerSymbols(t *testing.T) {
+	src := `syntax = "proto3";
+
+package greet.v1;
+
+// Greeter greets; the braces in this comment { are ignored.
+service Greeter {
+  rpc SayHello(HelloRequest) returns (HelloReply);
+  rpc StreamHellos(stream HelloRequest) returns (stream HelloReply) {
+    option idempotency_level = NO_SIDE_EFFECTS;
+  }
+}
+
+message HelloRequest {
+  string name = 1;
+  message Options {
+    bool loud = 1;
+  }
+  enum Tone {
+    TONE_UNSPECIFIED = 0;
+  }
+}
+
+/* A reply.
+   service NotAService { } */
+message HelloReply { string message = 1; }
+
+option go_package = "example.com/recon/gen/greetpb;greetpb";
+`
+	got := protoIndexer{}.Symbols([]byte(src))
+	type sym struct {
+		Kind, Name, Receiver, Signature string
+		LineStart, LineEnd              int
+		Links                           []string
+	}
+	var gotSyms []sym
+	for _, s := range got {
+		var links []string
+		for _, l := range s.Links {
+			links = append(links, l.Relation+" "+l.Package+"."+l.Name)
+		}
+		gotSyms = append(gotSyms, sym{s.Kind, s.Name, s.Receiver, s.Signature, s.LineStart, s.LineEnd, links})
+	}
+	pb := "example.com/recon/gen/greetpb."
+	want := []sym{
+		{"proto_service", "Greeter", "", "service Greeter", 6, 11, []string{"generated_as " + pb + "GreeterServer", "generated_as " + pb + "GreeterClient"}},
+		{"proto_rpc", "SayHello", "Greeter", "rpc SayHello(HelloRequest) returns (HelloReply)", 7, 7, nil},
+		{"proto_rpc", "StreamHellos", "Greeter", "rpc StreamHellos(stream HelloRequest) returns (stream HelloReply)", 8, 10, nil},
+		{"proto_message", "HelloRequest", "", "message HelloRequest", 13, 21, []string{"generated_as " + pb + "HelloRequest"}},
+		{"proto_message", "Options", "HelloRequest", "message Options", 15, 17, []string{"generated_as " + pb + "HelloRequest_Options"}},
+		{"proto_enum", "Tone", "HelloRequest", "enum Tone", 18, 20, []string{"generated_as " + pb + "HelloRequest_Tone"}},
+		{"proto_message", "HelloReply", "", "message HelloReply", 25, 25, []string{"generated_as " + pb + "HelloReply"}},
+	}
+	if !reflect.DeepEqual(gotSyms, want) {
+		t.Fatalf("Symbols() =\n%+v\nwant\n%+v", gotSyms, want)
+	}
+	if got[1].Body != "  rpc SayHello(HelloRequest) returns (HelloReply);" {
+		t.Fatalf("SayHello body = %q", got[1].Body)
+	}
+}
+
+func TestSyncLinksProtoToGo(t *testing.T) {
+	root := t.TempDir()
+	mustWrite := func(path, body string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", path, err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	mustWrite("go.mod", "module example.com/recon\n")
+	mustWrite("api/greet.proto", `syntax = "proto3";
+option go_package = "example.com/recon/gen/greetpb";
+service Greeter {
+  rpc SayHello(HelloRequest) returns (HelloRequest);
+}
+message HelloRequest {}
+`)
+	mustWrite("gen/greetpb/greet.go", `package greetpb
+
+type HelloRequest struct{}
+
+type UnimplementedGreeterServer struct{}
+`)
+	mustWrite("server/server.go", `package server
+
+import "example.com/recon/gen/greetpb"
+
+type Server struct {
+	greetpb.UnimplementedGreeterServer
+}
+`)
+
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+
+	links := func() []string {
+		t.Helper()
+		rows, err := conn.Query(`
+SELECT s.name, e.relation, e.to_ref
+FROM edges e JOIN symbols s ON s.id = e.from_id
+WHERE e.from_type = 'symbol' AND e.source = 'sync'
+ORDER BY s.name, e.relation, e.to_ref;`)
+		if err != nil {
+			t.Fatalf("query edges: %v", err)
+		}
+		defer rows.Close()
+		var got []string
+		for rows.Next() {
+			var name, relation, ref string
+			if err := rows.Scan(&name, &relation, &ref); err != nil {
+				t.Fatalf("scan edge: %v", err)
+			}
+			got = append(got, name+" "+relation+" "+ref)
+		}
+		return got
+	}
+
+	ctx := context.Background()
+	svc := NewService(conn)
+	if _, err := svc.Sync(ctx, root); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	want := []string{
+		"Greeter generated_as gen/greetpb.GreeterClient",
+		"Greeter generated_as gen/greetpb.GreeterServer",
+		"Greeter implemented_by server.Server",
+		"HelloRequest generated_as gen/greetpb.HelloRequest",
+	}
+	if got := links(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("links after Sync() = %q, want %q", got, want)
+	}
+	if _, err := svc.Sync(ctx, root); err != nil {
+		t.Fatalf("second Sync() error = %v", err)
+	}
+	if got := links(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("links after second Sync() = %q, want %q", got, want)
+	}
+
+	mustWrite("server/server.go", "package server\n\ntype Server struct{}\n")
+	mustWrite("api/greet.proto", "syntax = \"proto3\";\nservice Greeter {}\n")
+	if _, err := svc.SyncFiles(ctx, root, []string{"server/server.go", "api/greet.proto"}); err != nil {
+		t.Fatalf("SyncFiles() error = %v", err)
+	}
+	want = []string{
+		"Greeter generated_as api.GreeterClient",
+		"Greeter generated_as api.GreeterServer",
+	}
+	if got := links(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("links after SyncFiles() = %q, want %q", got, want)
+	}
+}
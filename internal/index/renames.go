@@ -0,0 +1,256 @@
+package index
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SymbolRename is a symbol whose declaration survived a sync unchanged under
+// a new package-qualified name. Edges is the number of edges rewritten from
+// OldRef to NewRef.
+type SymbolRename struct {
+	OldRef string `json:"old_ref"`
+	NewRef string `json:"new_ref"`
+	Kind   string `json:"kind"`
+	Edges  int    `json:"edges"`
+}
+
+// DanglingEdge is an edge from active knowledge to a symbol that is not in
+// the index. Candidates lists the new symbols it matched when the match was
+// too ambiguous to rewrite the edge.
+type DanglingEdge struct {
+	ID         int64    `json:"id"`
+	FromType   string   `json:"from_type"`
+	FromID     int64    `json:"from_id"`
+	ToRef      string   `json:"to_ref"`
+	Relation   string   `json:"relation"`
+	Candidates []string `json:"candidates,omitempty"`
+}
+
+// symbolSnapshot is what rename tracking needs from the index before a sync
+// replaces its rows: every symbol ref, and the fingerprints of the symbols
+// that edges point at.
+type symbolSnapshot struct {
+	refs   map[string]bool
+	linked map[string][]symbolPrint
+}
+
+type symbolPrint struct {
+	kind string
+	hash string
+}
+
+// snapshotSymbols reads the symbol refs that edges point at and, only when
+// there are any, the rest of the index.
+func snapshotSymbols(ctx context.Context, tx *sql.Tx) (symbolSnapshot, error) {
+	snap := symbolSnapshot{refs: map[string]bool{}, linked: map[string][]symbolPrint{}}
+	rows, err := tx.QueryContext(ctx, `SELECT DISTINCT to_ref FROM edges WHERE to_type = 'symbol' AND source != 'sync';`)
+	if err != nil {
+		return snap, fmt.Errorf("query symbol edges: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ref string
+		if err := rows.Scan(&ref); err != nil {
+			return snap, fmt.Errorf("scan symbol edge: %w", err)
+		}
+		snap.linked[ref] = nil
+	}
+	if err := rows.Err(); err != nil {
+		return snap, fmt.Errorf("iterate symbol edges: %w", err)
+	}
+	if len(snap.linked) == 0 {
+		return snap, nil
+	}
+
+	err = eachSymbol(ctx, tx, func(ref, name, kind, body string) {
+		snap.refs[ref] = true
+		if prints, ok := snap.linked[ref]; ok {
+			snap.linked[ref] = append(prints, symbolPrint{kind: kind, hash: bodyHash(name, body)})
+		}
+	})
+	return snap, err
+}
+
+// trackRenames rewrites edges whose symbol disappeared in this sync to the
+// one new symbol with the same kind and declaration apart from its name, and
+// logs each rename. It then returns the symbol edges from active knowledge
+// that still do not resolve.
+func trackRenames(ctx context.Context, tx *sql.Tx, before symbolSnapshot, commit string, now time.Time) ([]SymbolRename, []DanglingEdge, error) {
+	if len(before.linked) == 0 {
+		return nil, nil, nil
+	}
+
+	current := map[string]bool{}
+	added := map[symbolPrint][]string{}
+	err := eachSymbol(ctx, tx, func(ref, name, kind, body string) {
+		current[ref] = true
+		if before.refs[ref] {
+			return
+		}
+		p := symbolPrint{kind: kind, hash: bodyHash(name, body)}
+		if refs := added[p]; len(refs) == 0 || refs[len(refs)-1] != ref {
+			added[p] = append(refs, ref)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	oldRefs := make([]string, 0, len(before.linked))
+	for ref := range before.linked {
+		if !current[ref] {
+			oldRefs = append(oldRefs, ref)
+		}
+	}
+	sort.Strings(oldRefs)
+
+	matches := map[string][]string{}
+	claims := map[string]int{}
+	kinds := map[string]string{}
+	for _, old := range oldRefs {
+		seen := map[string]bool{}
+		for _, p := range before.linked[old] {
+			for _, ref := range added[p] {
+				if !seen[ref] {
+					seen[ref] = true
+					matches[old] = append(matches[old], ref)
+					kinds[old] = p.kind
+				}
+			}
+		}
+		sort.Strings(matches[old])
+		if len(matches[old]) == 1 {
+			claims[matches[old][0]]++
+		}
+	}
+
+	var renames []SymbolRename
+	for _, old := range oldRefs {
+		if len(matches[old]) != 1 || claims[matches[old][0]] != 1 {
+			continue
+		}
+		newRef := matches[old][0]
+		// An edge that already points at newRef keeps its row; the
+		// duplicate left on the old ref is dropped.
+		res, err := tx.ExecContext(ctx, `UPDATE OR IGNORE edges SET to_ref = ? WHERE to_type = 'symbol' AND to_ref = ?;`, newRef, old)
+		if err != nil {
+			return nil, nil, fmt.Errorf("rewrite edges of %s: %w", old, err)
+		}
+		n, _ := res.RowsAffected()
+		if _, err := tx.ExecContext(ctx, `DELETE FROM edges WHERE to_type = 'symbol' AND to_ref = ?;`, old); err != nil {
+			return nil, nil, fmt.Errorf("delete duplicate edges of %s: %w", old, err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO symbol_renames (old_ref, new_ref, kind, edges, commit_hash, renamed_at)
+VALUES (?, ?, ?, ?, ?, ?);
+`, old, newRef, kinds[old], n, commit, now.Format(time.RFC3339)); err != nil {
+			return nil, nil, fmt.Errorf("record rename of %s: %w", old, err)
+		}
+		renames = append(renames, SymbolRename{OldRef: old, NewRef: newRef, Kind: kinds[old], Edges: int(n)})
+		delete(matches, old)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+SELECT e.id, e.from_type, e.from_id, e.to_ref, e.relation
+FROM edges e
+LEFT JOIN decisions d ON e.from_type = 'decision' AND d.id = e.from_id
+LEFT JOIN patterns p ON e.from_type = 'pattern' AND p.id = e.from_id
+LEFT JOIN constraints c ON e.from_type = 'constraint' AND c.id = e.from_id
+WHERE e.to_type = 'symbol' AND COALESCE(d.status, p.status, c.status) = 'active'
+ORDER BY e.id;
+`)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query dangling edges: %w", err)
+	}
+	defer rows.Close()
+	var dangling []DanglingEdge
+	for rows.Next() {
+		var e DanglingEdge
+		if err := rows.Scan(&e.ID, &e.FromType, &e.FromID, &e.ToRef, &e.Relation); err != nil {
+			return nil, nil, fmt.Errorf("scan dangling edge: %w", err)
+		}
+		if current[e.ToRef] {
+			continue
+		}
+		e.Candidates = matches[e.ToRef]
+		dangling = append(dangling, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate dangling edges: %w", err)
+	}
+	return renames, dangling, nil
+}
+
+// eachSymbol calls fn with every indexed symbol's package-qualified ref, the
+// form edges use.
+func eachSymbol(ctx context.Context, tx *sql.Tx, fn func(ref, name, kind, body string)) error {
+	rows, err := tx.QueryContext(ctx, `
+SELECT pk.path, sy.name, sy.kind, COALESCE(b.body, '')
+FROM symbols sy
+LEFT JOIN symbol_bodies b ON b.hash = sy.body_hash
+JOIN files f ON f.id = sy.file_id
+JOIN packages pk ON pk.id = f.package_id
+ORDER BY pk.path, sy.name, sy.id;
+`)
+	if err != nil {
+		return fmt.Errorf("query symbols: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var pkg, name, kind, body string
+		if err := rows.Scan(&pkg, &name, &kind, &body); err != nil {
+			return fmt.Errorf("scan symbol: %w", err)
+		}
+		fn(pkg+"."+name, name, kind, body)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate symbols: %w", err)
+	}
+	return nil
+}
+
+// bodyHash fingerprints a declaration with every use of its own name blanked,
+// so a rename, including of a recursive function, keeps the hash.
+func bodyHash(name, body string) string {
+	var b strings.Builder
+	for name != "" {
+		i := indexIdent(body, name)
+		if i < 0 {
+			break
+		}
+		b.WriteString(body[:i])
+		b.WriteByte('_')
+		body = body[i+len(name):]
+	}
+	b.WriteString(body)
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// indexIdent returns the index of the first occurrence of name in s that is
+// a whole identifier, or -1.
+func indexIdent(s, name string) int {
+	for off := 0; off < len(s); {
+		i := strings.Index(s[off:], name)
+		if i < 0 {
+			return -1
+		}
+		start, end := off+i, off+i+len(name)
+		if (start == 0 || !isIdentByte(s[start-1])) && (end == len(s) || !isIdentByte(s[end])) {
+			return start
+		}
+		off = start + 1
+	}
+	return -1
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
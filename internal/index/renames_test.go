@@ -0,0 +1,182 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func renameTestModule(t *testing.T, files map[string]string) (string, *sql.DB) {
+	t.Helper()
+	root := t.TempDir()
+	files["go.mod"] = "module example.com/recon\n"
+	writeRenameFiles(t, root, files)
+	conn, err := db.Open(filepath.Join(t.TempDir(), "recon.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	if _, err := NewService(conn).Sync(context.Background(), root); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if _, err := conn.Exec(`INSERT INTO decisions (id, title, reasoning, status, created_at, updated_at) VALUES (1, 'd', 'r', 'active', 'now', 'now'), (2, 'old', 'r', 'archived', 'now', 'now')`); err != nil {
+		t.Fatalf("insert decisions: %v", err)
+	}
+	return root, conn
+}
+
+func writeRenameFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, src := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+}
+
+func addSymbolEdge(t *testing.T, conn *sql.DB, decisionID int64, ref string) {
+	t.Helper()
+	if _, err := conn.Exec(`INSERT INTO edges (from_type, from_id, to_type, to_ref, relation, created_at) VALUES ('decision', ?, 'symbol', ?, 'affects', 'now')`, decisionID, ref); err != nil {
+		t.Fatalf("insert edge: %v", err)
+	}
+}
+
+func symbolEdgeRefs(t *testing.T, conn *sql.DB) string {
+	t.Helper()
+	rows, err := conn.Query(`SELECT to_ref FROM edges WHERE to_type = 'symbol' ORDER BY to_ref`)
+	if err != nil {
+		t.Fatalf("query edges: %v", err)
+	}
+	defer rows.Close()
+	var refs []string
+	for rows.Next() {
+		var ref string
+		if err := rows.Scan(&ref); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		refs = append(refs, ref)
+	}
+	return strings.Join(refs, ",")
+}
+
+func TestSyncFollowsRenamedSymbols(t *testing.T) {
+	root, conn := renameTestModule(t, map[string]string{
+		"a/a.go": "package a\n\n// Walk visits n nodes.\nfunc Walk(n int) int {\n\tif n == 0 {\n\t\treturn 0\n\t}\n\treturn Walk(n-1) + 1\n}\n\nfunc Gone() {}\n",
+		"b/b.go": "package b\n\ntype Config struct {\n\tName string\n}\n",
+	})
+	addSymbolEdge(t, conn, 1, "a.Walk")
+	addSymbolEdge(t, conn, 1, "b.Config")
+	addSymbolEdge(t, conn, 1, "a.Gone")
+	addSymbolEdge(t, conn, 2, "a.Gone")
+
+	// Walk is renamed (recursion included), Config moves to package c, and
+	// Gone is deleted.
+	if err := os.Remove(filepath.Join(root, "b", "b.go")); err != nil {
+		t.Fatalf("remove b.go: %v", err)
+	}
+	writeRenameFiles(t, root, map[string]string{
+		"a/a.go": "package a\n\n// Traverse visits n nodes.\nfunc Traverse(n int) int {\n\tif n == 0 {\n\t\treturn 0\n\t}\n\treturn Traverse(n-1) + 1\n}\n",
+		"c/c.go": "package c\n\ntype Config struct {\n\tName string\n}\n",
+	})
+	result, err := NewService(conn).Sync(context.Background(), root)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if len(result.Renames) != 2 || result.Renames[0] != (SymbolRename{OldRef: "a.Walk", NewRef: "a.Traverse", Kind: "func", Edges: 1}) ||
+		result.Renames[1] != (SymbolRename{OldRef: "b.Config", NewRef: "c.Config", Kind: "type", Edges: 1}) {
+		t.Fatalf("renames = %+v", result.Renames)
+	}
+	if len(result.DanglingEdges) != 1 || result.DanglingEdges[0].ToRef != "a.Gone" || result.DanglingEdges[0].FromID != 1 {
+		t.Fatalf("dangling = %+v", result.DanglingEdges)
+	}
+	if got := symbolEdgeRefs(t, conn); got != "a.Gone,a.Gone,a.Traverse,c.Config" {
+		t.Fatalf("edge refs = %s", got)
+	}
+	var logged int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM symbol_renames WHERE old_ref = 'a.Walk' AND new_ref = 'a.Traverse'`).Scan(&logged); err != nil || logged != 1 {
+		t.Fatalf("rename log: count=%d err=%v", logged, err)
+	}
+
+	// A second sync finds nothing new to rename but still reports the
+	// dangling edge.
+	result, err = NewService(conn).Sync(context.Background(), root)
+	if err != nil {
+		t.Fatalf("resync: %v", err)
+	}
+	if len(result.Renames) != 0 || len(result.DanglingEdges) != 1 {
+		t.Fatalf("resync renames=%+v dangling=%+v", result.Renames, result.DanglingEdges)
+	}
+}
+
+func TestSyncLeavesAmbiguousRenamesDangling(t *testing.T) {
+	root, conn := renameTestModule(t, map[string]string{
+		"a/a.go": "package a\n\nfunc Old() int { return 1 }\n\nfunc Other() {}\n",
+	})
+	addSymbolEdge(t, conn, 1, "a.Old")
+	addSymbolEdge(t, conn, 1, "a.Other")
+
+	// Old has two identical successors, and Other has none.
+	writeRenameFiles(t, root, map[string]string{
+		"a/a.go": "package a\n\nfunc One() int { return 1 }\n\nfunc Two() int { return 1 }\n",
+	})
+	result, err := NewService(conn).Sync(context.Background(), root)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if len(result.Renames) != 0 || len(result.DanglingEdges) != 2 {
+		t.Fatalf("renames=%+v dangling=%+v", result.Renames, result.DanglingEdges)
+	}
+	if d := result.DanglingEdges[0]; d.ToRef != "a.Old" || strings.Join(d.Candidates, ",") != "a.One,a.Two" {
+		t.Fatalf("ambiguous edge = %+v", d)
+	}
+	if d := result.DanglingEdges[1]; d.ToRef != "a.Other" || len(d.Candidates) != 0 {
+		t.Fatalf("unmatched edge = %+v", d)
+	}
+}
+
+func TestSyncFilesFollowsRenamedSymbols(t *testing.T) {
+	root, conn := renameTestModule(t, map[string]string{
+		"a/a.go": "package a\n\ntype Store struct{}\n\nfunc (s *Store) Load() error { return nil }\n",
+	})
+	addSymbolEdge(t, conn, 1, "a.Load")
+	// An edge that already follows the new name is kept once.
+	addSymbolEdge(t, conn, 1, "a.Fetch")
+
+	writeRenameFiles(t, root, map[string]string{
+		"a/a.go": "package a\n\ntype Store struct{}\n\nfunc (s *Store) Fetch() error { return nil }\n",
+	})
+	result, err := NewService(conn).SyncFiles(context.Background(), root, []string{"a/a.go"})
+	if err != nil {
+		t.Fatalf("SyncFiles: %v", err)
+	}
+	if len(result.Renames) != 1 || result.Renames[0] != (SymbolRename{OldRef: "a.Load", NewRef: "a.Fetch", Kind: "method", Edges: 0}) {
+		t.Fatalf("renames = %+v", result.Renames)
+	}
+	if len(result.DanglingEdges) != 0 || symbolEdgeRefs(t, conn) != "a.Fetch" {
+		t.Fatalf("dangling=%+v refs=%s", result.DanglingEdges, symbolEdgeRefs(t, conn))
+	}
+}
+
+func TestBodyHash(t *testing.T) {
+	if bodyHash("Walk", "func Walk() { Walk() }") != bodyHash("Run", "func Run() { Run() }") {
+		t.Fatal("renamed recursive func should keep its hash")
+	}
+	if bodyHash("Walk", "func Walk() { Walker() }") == bodyHash("Run", "func Run() { Runner() }") {
+		t.Fatal("only whole identifiers should be blanked")
+	}
+	if bodyHash("", "x") != bodyHash("", "x") {
+		t.Fatal("empty name should hash the body as is")
+	}
+}
@@ -9,8 +9,11 @@ import (
 	"go/parser"
 	"go/printer"
 	"go/token"
+	"log/slog"
 	"path"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -20,7 +23,7 @@ import (
 )
 
 var (
-	collectEligibleFiles = CollectEligibleGoFiles
+	collectEligibleFiles = CollectSyncFiles
 	importPathUnquote    = strconv.Unquote
 )
 
@@ -43,6 +46,39 @@ type SyncResult struct {
 	Dirty           bool      `json:"dirty"`
 	SyncedAt        time.Time `json:"synced_at"`
 	Diff            *SyncDiff `json:"diff,omitempty"`
+	// Files lists the module-relative paths a targeted sync was asked to
+	// re-index; it is empty for a full sync.
+	Files []string `json:"files,omitempty"`
+	// ChangedFiles lists the module-relative paths a full sync found added,
+	// modified, or removed since the previous index, sorted.
+	ChangedFiles []string `json:"changed_files,omitempty"`
+	// Renames lists symbols found under a new name, whose edges were
+	// rewritten to follow them.
+	Renames []SymbolRename `json:"renames,omitempty"`
+	// DanglingEdges lists edges from active knowledge to symbols no longer
+	// in the index.
+	DanglingEdges []DanglingEdge `json:"dangling_edges,omitempty"`
+	// MissingEmbeds lists //go:embed patterns that matched no files.
+	MissingEmbeds []MissingEmbed `json:"missing_embeds,omitempty"`
+	// Ignored lists the directories and files a full sync left out because
+	// of a .gitignore or .reconignore rule, with the rule.
+	Ignored []IgnoredPath `json:"ignored,omitempty"`
+	// IgnoreWarnings lists the ignore file lines a full sync skipped
+	// because their patterns are invalid.
+	IgnoreWarnings []IgnoreWarning `json:"ignore_warnings,omitempty"`
+	// Timings is where a full sync spent its time; a targeted sync leaves
+	// it nil.
+	Timings *SyncTimings `json:"timings,omitempty"`
+}
+
+// SyncOptions tunes how Sync walks and parses the module.
+type SyncOptions struct {
+	// Jobs is the number of files parsed concurrently. Zero or negative
+	// means one worker per available CPU.
+	Jobs int
+	// Progress, when set, is called as the sync moves through its phases
+	// and after each file it writes, from the goroutine running the sync.
+	Progress func(Progress)
 }
 
 type Service struct {
@@ -54,15 +90,31 @@ func NewService(conn *sql.DB) *Service {
 }
 
 func (s *Service) Sync(ctx context.Context, moduleRoot string) (SyncResult, error) {
+	return s.SyncWithOptions(ctx, moduleRoot, SyncOptions{})
+}
+
+func (s *Service) SyncWithOptions(ctx context.Context, moduleRoot string, opts SyncOptions) (SyncResult, error) {
+	start := time.Now()
 	modulePath, err := ModulePath(moduleRoot)
 	if err != nil {
 		return SyncResult{}, err
 	}
+	gomod, err := ParseGoMod(moduleRoot)
+	if err != nil {
+		return SyncResult{}, err
+	}
 
-	files, err := collectEligibleFiles(moduleRoot)
+	var timings SyncTimings
+	report(opts.Progress, Progress{Phase: PhaseCollect})
+	collectStart := time.Now()
+	files, ignored, ignoreWarnings, err := collectEligibleFiles(moduleRoot)
 	if err != nil {
 		return SyncResult{}, err
 	}
+	for _, w := range ignoreWarnings {
+		slog.WarnContext(ctx, "ignore rule", "source", w.Source, "rule", w.Rule, "error", w.Error)
+	}
+	timings.CollectMS = millisSince(collectStart)
 	fingerprint := ComputeFingerprint(files)
 	commit, dirty := CurrentGitState(ctx, moduleRoot)
 	now := time.Now().UTC()
@@ -92,10 +144,25 @@ func (s *Service) Sync(ctx context.Context, moduleRoot string) (SyncResult, erro
 		}
 	}
 
+	before, err := snapshotSymbols(ctx, tx)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
 	for _, q := range []string{
+		"DELETE FROM edges WHERE source = 'sync';",
 		"DELETE FROM symbol_deps;",
+		"DELETE FROM type_embeds;",
+		"DELETE FROM struct_fields;",
+		"DELETE FROM type_methods;",
+		"DELETE FROM enum_members;",
+		"DELETE FROM type_params;",
 		"DELETE FROM imports;",
 		"DELETE FROM symbols;",
+		"DELETE FROM symbol_bodies;",
+		"DELETE FROM file_embeds;",
+		"DELETE FROM annotations;",
+		"DELETE FROM code_owners;",
 		"DELETE FROM files;",
 		"DELETE FROM packages;",
 	} {
@@ -112,21 +179,30 @@ func (s *Service) Sync(ctx context.Context, moduleRoot string) (SyncResult, erro
 		LineCount int
 	}
 	packageStats := map[string]*pkgStats{}
-	for _, file := range files {
-		fset := token.NewFileSet()
-		parsed, err := parser.ParseFile(fset, file.AbsPath, file.Content, parser.ParseComments)
-		if err != nil {
-			return SyncResult{}, fmt.Errorf("parse %s: %w", file.RelPath, err)
-		}
 
-		pkgPath := filepath.ToSlash(filepath.Dir(file.RelPath))
-		if pkgPath == "." {
-			pkgPath = "."
+	parseCtx, cancelParse := context.WithCancel(ctx)
+	defer cancelParse()
+	goFiles, otherFiles := splitLanguages(files)
+	results, release := parseFiles(parseCtx, goFiles, opts.Jobs)
+	report(opts.Progress, Progress{Phase: PhaseParse, Total: len(files)})
+	insertStart := time.Now()
+	var parseWait time.Duration
+
+	for i, file := range goFiles {
+		var result parsedFile
+		waitStart := time.Now()
+		select {
+		case result = <-results[i]:
+		case <-ctx.Done():
+			return SyncResult{}, fmt.Errorf("parse %s: %w", file.RelPath, ctx.Err())
 		}
-		importPath := modulePath
-		if pkgPath != "." {
-			importPath = modulePath + "/" + pkgPath
+		parseWait += time.Since(waitStart)
+		if result.err != nil {
+			return SyncResult{}, fmt.Errorf("parse %s: %w", file.RelPath, result.err)
 		}
+		fset, parsed := result.fset, result.file
+
+		pkgPath, importPath := packagePaths(modulePath, file.RelPath)
 
 		stats := packageStats[pkgPath]
 		if stats == nil {
@@ -147,99 +223,49 @@ VALUES (?, ?, ?, 0, 0, ?, ?);
 		stats.FileCount++
 		stats.LineCount += file.Lines
 
-		res, err := tx.ExecContext(ctx, `
-INSERT INTO files (package_id, path, language, lines, hash, created_at, updated_at)
-VALUES (?, ?, 'go', ?, ?, ?, ?);
-`, stats.ID, file.RelPath, file.Lines, file.Hash, now.Format(time.RFC3339), now.Format(time.RFC3339))
-		if err != nil {
-			return SyncResult{}, fmt.Errorf("insert file %s: %w", file.RelPath, err)
-		}
-		fileID, err := res.LastInsertId()
-		if err != nil {
-			return SyncResult{}, fmt.Errorf("read file id: %w", err)
-		}
-
-		localImportAliases := map[string]string{}
-
-		for _, imp := range parsed.Imports {
-			toPath, err := importPathUnquote(imp.Path.Value)
-			if err != nil {
-				toPath = strings.Trim(imp.Path.Value, "\"")
-			}
-			alias := ""
-			if imp.Name != nil {
-				alias = imp.Name.Name
-			} else {
-				alias = path.Base(toPath)
-			}
-
-			importType := "external"
-			var toPkgID any
-			localPkgPath := ""
-			if toPath == modulePath || strings.HasPrefix(toPath, modulePath+"/") {
-				importType = "local"
-				rel := strings.TrimPrefix(toPath, modulePath)
-				rel = strings.TrimPrefix(rel, "/")
-				if rel == "" {
-					rel = "."
-				}
-				localPkgPath = rel
+		if err := writeFileRows(ctx, tx, fileRows{
+			File:        file,
+			PackageID:   stats.ID,
+			PackagePath: pkgPath,
+			ModulePath:  modulePath,
+			Fset:        fset,
+			Parsed:      parsed,
+			Now:         now,
+			LocalPackageID: func(rel string) any {
 				if localStats, ok := packageStats[rel]; ok {
-					toPkgID = localStats.ID
+					return localStats.ID
 				}
-			}
-			if alias != "" && alias != "_" && alias != "." {
-				localImportAliases[alias] = localPkgPath
-			}
-
-			if _, err := tx.ExecContext(ctx, `
-INSERT INTO imports (from_file_id, to_path, to_package_id, alias, import_type)
-VALUES (?, ?, ?, ?, ?)
-ON CONFLICT(from_file_id, to_path) DO UPDATE SET
-    to_package_id = excluded.to_package_id,
-    alias = excluded.alias,
-    import_type = excluded.import_type;
-`, fileID, toPath, toPkgID, alias, importType); err != nil {
-				return SyncResult{}, fmt.Errorf("insert import %s: %w", toPath, err)
-			}
+				return nil
+			},
+		}); err != nil {
+			return SyncResult{}, err
 		}
+		release()
+		report(opts.Progress, Progress{Phase: PhaseParse, Done: i + 1, Total: len(files), Package: pkgPath})
+	}
 
-		for _, decl := range parsed.Decls {
-			records := symbolRecordsFromDeclWithContext(fset, file.Content, decl, depContext{
-				PackagePath:  pkgPath,
-				LocalImports: localImportAliases,
-			})
-			for _, rec := range records {
-				if _, err := tx.ExecContext(ctx, `
-INSERT INTO symbols (file_id, kind, name, signature, body, line_start, line_end, exported, receiver)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-ON CONFLICT(file_id, kind, name, receiver) DO UPDATE SET
-    signature = excluded.signature,
-    body = excluded.body,
-    line_start = excluded.line_start,
-    line_end = excluded.line_end,
-    exported = excluded.exported;
-`, fileID, rec.Kind, rec.Name, rec.Signature, rec.Body, rec.LineStart, rec.LineEnd, boolToInt(rec.Exported), rec.Receiver); err != nil {
-					return SyncResult{}, fmt.Errorf("insert symbol %s: %w", rec.Name, err)
-				}
-
-				var symbolID int64
-				if err := tx.QueryRowContext(ctx, `
-SELECT id FROM symbols WHERE file_id = ? AND kind = ? AND name = ? AND receiver = ?;
-`, fileID, rec.Kind, rec.Name, rec.Receiver).Scan(&symbolID); err != nil {
-					return SyncResult{}, fmt.Errorf("resolve symbol id for %s: %w", rec.Name, err)
-				}
-
-				for _, dep := range rec.DepRefs {
-					if _, err := tx.ExecContext(ctx, `
-INSERT OR IGNORE INTO symbol_deps (symbol_id, dep_name, dep_package, dep_kind)
-VALUES (?, ?, ?, ?);
-`, symbolID, dep.Name, dep.PackagePath, dep.Kind); err != nil {
-						return SyncResult{}, fmt.Errorf("insert symbol dep %s: %w", dep.Name, err)
-					}
-				}
-			}
+	for i, file := range otherFiles {
+		if err := writeIndexedFile(ctx, tx, file, fileIndexerFor(file.RelPath), modulePath, now); err != nil {
+			return SyncResult{}, err
 		}
+		report(opts.Progress, Progress{Phase: PhaseParse, Done: len(goFiles) + i + 1, Total: len(files), Package: path.Dir(file.RelPath)})
+	}
+	report(opts.Progress, Progress{Phase: PhaseLink, Total: len(files)})
+
+	// Local imports of packages later in walk order were written before
+	// those packages existed; link them now that every package is in.
+	if _, err := tx.ExecContext(ctx, `
+UPDATE imports
+SET to_package_id = (SELECT p.id FROM packages p WHERE p.import_path = imports.to_path)
+WHERE import_type = 'local' AND to_package_id IS NULL;
+`); err != nil {
+		return SyncResult{}, fmt.Errorf("link local imports: %w", err)
+	}
+	if err := linkMethodSets(ctx, tx); err != nil {
+		return SyncResult{}, err
+	}
+	if err := writeDependencies(ctx, tx, gomod); err != nil {
+		return SyncResult{}, err
 	}
 
 	// Query actual symbol count from DB (loop counter may overcount due to ON CONFLICT)
@@ -258,6 +284,21 @@ WHERE path = ?;
 		}
 	}
 
+	renames, dangling, err := trackRenames(ctx, tx, before, commit, now)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	missing, err := missingEmbeds(ctx, tx)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	if err := writeCodeOwners(ctx, tx, moduleRoot); err != nil {
+		return SyncResult{}, err
+	}
+	if err := linkProtoServices(ctx, tx, now); err != nil {
+		return SyncResult{}, err
+	}
+
 	if err := db.UpsertSyncState(ctx, tx, db.SyncState{
 		LastSyncAt:       now,
 		LastSyncCommit:   commit,
@@ -268,28 +309,33 @@ WHERE path = ?;
 		return SyncResult{}, err
 	}
 
+	newPaths := map[string]string{}
+	for _, f := range files {
+		newPaths[f.RelPath] = f.Hash
+	}
+	var changed []string
+	added, removed, modified := 0, 0, 0
+	for p, oldHash := range prevHashes {
+		newHash, exists := newPaths[p]
+		if !exists {
+			removed++
+			changed = append(changed, p)
+		} else if oldHash != newHash {
+			modified++
+			changed = append(changed, p)
+		}
+	}
+	for p := range newPaths {
+		if _, existed := prevHashes[p]; !existed {
+			added++
+			changed = append(changed, p)
+		}
+	}
+	sort.Strings(changed)
+
 	// Compute diff if there was previous data
 	var diff *SyncDiff
 	if prevFiles > 0 || prevSymbols > 0 || prevPackages > 0 {
-		newPaths := map[string]string{}
-		for _, f := range files {
-			newPaths[f.RelPath] = f.Hash
-		}
-
-		added, removed, modified := 0, 0, 0
-		for p, oldHash := range prevHashes {
-			newHash, exists := newPaths[p]
-			if !exists {
-				removed++
-			} else if oldHash != newHash {
-				modified++
-			}
-		}
-		for p := range newPaths {
-			if _, existed := prevHashes[p]; !existed {
-				added++
-			}
-		}
 
 		diff = &SyncDiff{
 			FilesAdded:     added,
@@ -302,9 +348,28 @@ WHERE path = ?;
 		}
 	}
 
+	run := db.SyncRun{SyncedAt: now, Mode: "full", Commit: commit, Dirty: dirty,
+		IndexedFiles: len(files), IndexedSymbols: actualSymbolCount, FilesAdded: len(files),
+		DurationMS: time.Since(start).Milliseconds()}
+	if diff != nil {
+		run.FilesAdded, run.FilesModified, run.FilesRemoved = diff.FilesAdded, diff.FilesModified, diff.FilesRemoved
+	}
+	if err := db.AppendSyncRun(ctx, tx, run); err != nil {
+		return SyncResult{}, err
+	}
+
+	timings.ParseMS = parseWait.Milliseconds()
+	timings.InsertMS = millisSince(insertStart) - timings.ParseMS
+	report(opts.Progress, Progress{Phase: PhaseCommit, Total: len(files)})
+	commitStart := time.Now()
 	if err := tx.Commit(); err != nil {
 		return SyncResult{}, fmt.Errorf("commit sync tx: %w", err)
 	}
+	timings.CommitMS = millisSince(commitStart)
+	timings.TotalMS = millisSince(start)
+	slog.InfoContext(ctx, "sync", "files", len(files), "symbols", actualSymbolCount, "packages", len(packageStats),
+		"collect_ms", timings.CollectMS, "parse_ms", timings.ParseMS, "insert_ms", timings.InsertMS,
+		"commit_ms", timings.CommitMS, "total_ms", timings.TotalMS)
 
 	return SyncResult{
 		IndexedFiles:    len(files),
@@ -315,9 +380,106 @@ WHERE path = ?;
 		Dirty:           dirty,
 		SyncedAt:        now,
 		Diff:            diff,
+		ChangedFiles:    changed,
+		Renames:         renames,
+		DanglingEdges:   dangling,
+		MissingEmbeds:   missing,
+		Ignored:         ignored,
+		IgnoreWarnings:  ignoreWarnings,
+		Timings:         &timings,
 	}, nil
 }
 
+// linkMethodSets rebuilds the type_methods rows of concrete types from the
+// indexed method declarations. Methods may be declared in any file of the
+// type's package, so this runs once all files are written.
+func linkMethodSets(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM type_methods WHERE method_symbol_id IS NOT NULL;`); err != nil {
+		return fmt.Errorf("reset method sets: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO type_methods (symbol_id, name, signature, pointer_receiver, method_symbol_id)
+SELECT t.id, m.name, COALESCE(m.signature, ''), m.receiver LIKE '*%', m.id
+FROM symbols m
+JOIN files fm ON fm.id = m.file_id
+JOIN files ft ON ft.package_id = fm.package_id
+JOIN symbols t ON t.file_id = ft.id AND t.kind = 'type'
+WHERE m.kind = 'method'
+  AND t.name = CASE
+      WHEN instr(ltrim(m.receiver, '*'), '[') > 0
+      THEN substr(ltrim(m.receiver, '*'), 1, instr(ltrim(m.receiver, '*'), '[') - 1)
+      ELSE ltrim(m.receiver, '*')
+  END;
+`); err != nil {
+		return fmt.Errorf("link method sets: %w", err)
+	}
+	return nil
+}
+
+type parsedFile struct {
+	fset *token.FileSet
+	file *ast.File
+	err  error
+}
+
+// parseAheadPerJob is how many parsed files per worker may wait for the DB
+// writer, bounding the syntax trees held in memory however large the module.
+const parseAheadPerJob = 4
+
+// parseFiles parses files on a pool of workers. It returns one buffered
+// channel per input file, in input order, so the single DB writer in Sync can
+// consume results sequentially while workers parse ahead of it, and a release
+// func the writer calls once it has written each file. Workers parse at most
+// parseAheadPerJob files per worker ahead of the writer. Cancelling ctx stops
+// workers from picking up further files.
+func parseFiles(ctx context.Context, files []SourceFile, jobs int) ([]chan parsedFile, func()) {
+	if jobs <= 0 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+	if jobs > len(files) {
+		jobs = len(files)
+	}
+
+	results := make([]chan parsedFile, len(files))
+	for i := range results {
+		results[i] = make(chan parsedFile, 1)
+	}
+
+	// A file takes a slot when it is handed to a worker and gives it back
+	// when the writer releases it.
+	slots := make(chan struct{}, max(jobs, 1)*parseAheadPerJob)
+	release := func() { <-slots }
+
+	next := make(chan int)
+	go func() {
+		defer close(next)
+		for i := range files {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case next <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for w := 0; w < jobs; w++ {
+		go func() {
+			for i := range next {
+				fset := token.NewFileSet()
+				parsed, err := parser.ParseFile(fset, files[i].AbsPath, files[i].Content, parser.ParseComments)
+				results[i] <- parsedFile{fset: fset, file: parsed, err: err}
+			}
+		}()
+	}
+
+	return results, release
+}
+
 type symbolRecord struct {
 	Kind      string
 	Name      string
@@ -328,17 +490,61 @@ type symbolRecord struct {
 	Exported  bool
 	Receiver  string
 	DepRefs   []depRef
+	Embeds    []embedRef
+	Fields    []fieldRef
+	Methods   []methodRef
+	Members   []enumMember
+	// TypeParams are the type parameters of a generic func or type, in
+	// declaration order.
+	TypeParams []typeParamRef
+	// Deprecated is the "Deprecated:" paragraph of the doc comment.
+	Deprecated string
+}
+
+// typeParamRef is one type parameter and its constraint as written, e.g.
+// {K, comparable} for [K comparable, V any].
+type typeParamRef struct {
+	Name       string
+	Constraint string
+}
+
+// fieldRef is a struct field. Embedded fields are named after their type,
+// as Go names them.
+type fieldRef struct {
+	Name     string
+	Type     string
+	Tag      string
+	Embedded bool
+}
+
+// methodRef is a method spec declared in an interface type.
+type methodRef struct {
+	Name      string
+	Signature string
+}
+
+// embedRef is a type embedded in a struct or interface. PackagePath is the
+// module-relative package for local types and the import path for external
+// ones.
+type embedRef struct {
+	Name        string
+	PackagePath string
+	Pointer     bool
 }
 
+// depRef is a call made by a symbol. Lines are the ascending source lines of
+// its call sites; they are empty when no file set was available.
 type depRef struct {
 	Name        string
 	PackagePath string
 	Kind        string
+	Lines       []int
 }
 
 type depContext struct {
-	PackagePath  string
-	LocalImports map[string]string
+	PackagePath     string
+	LocalImports    map[string]string
+	ExternalImports map[string]string
 }
 
 func symbolRecordsFromDecl(fset *token.FileSet, src []byte, decl ast.Decl) []symbolRecord {
@@ -359,7 +565,10 @@ func symbolRecordsFromDeclWithContext(fset *token.FileSet, src []byte, decl ast.
 			LineEnd:   fset.Position(d.End()).Line,
 			Exported:  ast.IsExported(d.Name.Name),
 			Receiver:  receiverName(d),
-			DepRefs:   collectCallDeps(d.Body, ctx),
+			DepRefs:   collectCallDeps(fset, d.Body, ctx),
+
+			TypeParams: collectTypeParams(d.Type.TypeParams),
+			Deprecated: deprecation(d.Doc),
 		}
 		if rec.Receiver != "" {
 			rec.Kind = "method"
@@ -376,11 +585,17 @@ func symbolRecordsFromDeclWithContext(fset *token.FileSet, src []byte, decl ast.
 				records = append(records, symbolRecord{
 					Kind:      "type",
 					Name:      s.Name.Name,
-					Signature: exprString(s.Type),
+					Signature: typeSignature(s),
 					Body:      textForPos(fset, src, s.Pos(), s.End()),
 					LineStart: fset.Position(s.Pos()).Line,
 					LineEnd:   fset.Position(s.End()).Line,
 					Exported:  ast.IsExported(s.Name.Name),
+					Embeds:    collectEmbeds(s.Type, ctx),
+					Fields:    collectFields(s.Type),
+					Methods:   collectInterfaceMethods(s.Type),
+
+					TypeParams: collectTypeParams(s.TypeParams),
+					Deprecated: deprecation(specDoc(d, s.Doc)),
 				})
 			case *ast.ValueSpec:
 				for _, n := range s.Names {
@@ -392,17 +607,20 @@ func symbolRecordsFromDeclWithContext(fset *token.FileSet, src []byte, decl ast.
 						LineStart: fset.Position(s.Pos()).Line,
 						LineEnd:   fset.Position(s.End()).Line,
 						Exported:  ast.IsExported(n.Name),
+
+						Deprecated: deprecation(specDoc(d, s.Doc)),
 					})
 				}
 			}
 		}
+		records = append(records, enumRecords(fset, src, d)...)
 	}
 
 	return records
 }
 
 func collectCallNames(body *ast.BlockStmt) []string {
-	depsWithContext := collectCallDeps(body, depContext{})
+	depsWithContext := collectCallDeps(nil, body, depContext{})
 	if depsWithContext == nil {
 		return nil
 	}
@@ -422,13 +640,21 @@ func collectCallNames(body *ast.BlockStmt) []string {
 	return deps
 }
 
-func collectCallDeps(body *ast.BlockStmt, ctx depContext) []depRef {
+// collectCallDeps returns the calls in body, one depRef per callee with the
+// lines it is called on when fset is non-nil.
+func collectCallDeps(fset *token.FileSet, body *ast.BlockStmt, ctx depContext) []depRef {
 	if body == nil {
 		return nil
 	}
 	set := map[string]depRef{}
-	addDep := func(dep depRef) {
+	addDep := func(dep depRef, pos token.Pos) {
 		key := dep.Name + "\x00" + dep.PackagePath + "\x00" + dep.Kind
+		dep.Lines = set[key].Lines
+		if fset != nil {
+			if line := fset.Position(pos).Line; line > 0 && !slices.Contains(dep.Lines, line) {
+				dep.Lines = append(dep.Lines, line)
+			}
+		}
 		set[key] = dep
 	}
 
@@ -441,19 +667,19 @@ func collectCallDeps(body *ast.BlockStmt, ctx depContext) []depRef {
 		switch fn := call.Fun.(type) {
 		case *ast.Ident:
 			if fn.Name != "" {
-				addDep(depRef{Name: fn.Name, PackagePath: currentPackage, Kind: "func"})
+				addDep(depRef{Name: fn.Name, PackagePath: currentPackage, Kind: "func"}, fn.Pos())
 			}
 		case *ast.SelectorExpr:
 			if fn.Sel != nil && fn.Sel.Name != "" {
 				if ident, ok := fn.X.(*ast.Ident); ok {
 					if pkgPath, found := ctx.LocalImports[ident.Name]; found {
 						if pkgPath != "" {
-							addDep(depRef{Name: fn.Sel.Name, PackagePath: pkgPath, Kind: "func"})
+							addDep(depRef{Name: fn.Sel.Name, PackagePath: pkgPath, Kind: "func"}, fn.Sel.Pos())
 						}
 						return true
 					}
 
-					addDep(depRef{Name: fn.Sel.Name, PackagePath: currentPackage, Kind: "method"})
+					addDep(depRef{Name: fn.Sel.Name, PackagePath: currentPackage, Kind: "method"}, fn.Sel.Pos())
 				}
 			}
 		}
@@ -462,6 +688,7 @@ func collectCallDeps(body *ast.BlockStmt, ctx depContext) []depRef {
 
 	deps := make([]depRef, 0, len(set))
 	for _, dep := range set {
+		slices.Sort(dep.Lines)
 		deps = append(deps, dep)
 	}
 	sort.Slice(deps, func(i, j int) bool {
@@ -476,6 +703,181 @@ func collectCallDeps(body *ast.BlockStmt, ctx depContext) []depRef {
 	return deps
 }
 
+// collectEmbeds returns the types embedded in a struct or interface type
+// expression: anonymous struct fields and embedded interface elements.
+func collectEmbeds(expr ast.Expr, ctx depContext) []embedRef {
+	var fields *ast.FieldList
+	switch t := expr.(type) {
+	case *ast.StructType:
+		fields = t.Fields
+	case *ast.InterfaceType:
+		fields = t.Methods
+	}
+	if fields == nil {
+		return nil
+	}
+
+	var embeds []embedRef
+	for _, field := range fields.List {
+		if len(field.Names) > 0 {
+			continue
+		}
+		if ref, ok := embedRefFromExpr(field.Type, ctx); ok {
+			embeds = append(embeds, ref)
+		}
+	}
+	return embeds
+}
+
+func embedRefFromExpr(expr ast.Expr, ctx depContext) (embedRef, bool) {
+	pointer := false
+	if star, ok := expr.(*ast.StarExpr); ok {
+		pointer = true
+		expr = star.X
+	}
+	// Generic instantiations embed the base type: Base[T] -> Base.
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		expr = t.X
+	case *ast.IndexListExpr:
+		expr = t.X
+	}
+
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return embedRef{Name: t.Name, PackagePath: strings.TrimSpace(ctx.PackagePath), Pointer: pointer}, true
+	case *ast.SelectorExpr:
+		pkgIdent, ok := t.X.(*ast.Ident)
+		if !ok {
+			return embedRef{}, false
+		}
+		if local := ctx.LocalImports[pkgIdent.Name]; local != "" {
+			return embedRef{Name: t.Sel.Name, PackagePath: local, Pointer: pointer}, true
+		}
+		if external, ok := ctx.ExternalImports[pkgIdent.Name]; ok {
+			return embedRef{Name: t.Sel.Name, PackagePath: external, Pointer: pointer}, true
+		}
+		return embedRef{Name: t.Sel.Name, PackagePath: pkgIdent.Name, Pointer: pointer}, true
+	}
+	return embedRef{}, false
+}
+
+// collectFields returns the fields of a struct type expression in
+// declaration order, one per name.
+func collectFields(expr ast.Expr) []fieldRef {
+	st, ok := expr.(*ast.StructType)
+	if !ok || st.Fields == nil {
+		return nil
+	}
+
+	var fields []fieldRef
+	for _, field := range st.Fields.List {
+		typ := exprString(field.Type)
+		tag := ""
+		if field.Tag != nil {
+			if unquoted, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = unquoted
+			}
+		}
+		if len(field.Names) == 0 {
+			fields = append(fields, fieldRef{Name: embeddedFieldName(field.Type), Type: typ, Tag: tag, Embedded: true})
+			continue
+		}
+		for _, name := range field.Names {
+			fields = append(fields, fieldRef{Name: name.Name, Type: typ, Tag: tag})
+		}
+	}
+	return fields
+}
+
+// embeddedFieldName returns the implicit field name of an embedded type:
+// the unqualified type name without pointer or type arguments.
+func embeddedFieldName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		expr = t.X
+	case *ast.IndexListExpr:
+		expr = t.X
+	}
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return exprString(expr)
+}
+
+// collectInterfaceMethods returns the method specs declared directly in an
+// interface type expression. Embedded interfaces are recorded as embeds.
+func collectInterfaceMethods(expr ast.Expr) []methodRef {
+	it, ok := expr.(*ast.InterfaceType)
+	if !ok || it.Methods == nil {
+		return nil
+	}
+
+	var methods []methodRef
+	for _, field := range it.Methods.List {
+		if _, ok := field.Type.(*ast.FuncType); !ok {
+			continue
+		}
+		for _, name := range field.Names {
+			methods = append(methods, methodRef{Name: name.Name, Signature: exprString(field.Type)})
+		}
+	}
+	return methods
+}
+
+// typeSignature is the declaration of a type after its name: the type
+// parameter list of a generic type followed by the underlying type, e.g.
+// "[T comparable] struct{ items []T }", or "= T" for an alias.
+func typeSignature(s *ast.TypeSpec) string {
+	sig := exprString(s.Type)
+	if s.Assign.IsValid() {
+		sig = "= " + sig
+	}
+	if params := typeParamList(s.TypeParams); params != "" {
+		return params + " " + sig
+	}
+	return sig
+}
+
+// collectTypeParams flattens a type parameter list, so [K, V any] yields one
+// entry per name.
+func collectTypeParams(fl *ast.FieldList) []typeParamRef {
+	if fl == nil {
+		return nil
+	}
+	var params []typeParamRef
+	for _, field := range fl.List {
+		constraint := exprString(field.Type)
+		for _, name := range field.Names {
+			params = append(params, typeParamRef{Name: name.Name, Constraint: constraint})
+		}
+	}
+	return params
+}
+
+// typeParamList renders a type parameter list as declared, grouping names
+// that share a constraint: "[K comparable, V any]".
+func typeParamList(fl *ast.FieldList) string {
+	if fl == nil || len(fl.List) == 0 {
+		return ""
+	}
+	groups := make([]string, 0, len(fl.List))
+	for _, field := range fl.List {
+		names := make([]string, 0, len(field.Names))
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		groups = append(groups, strings.Join(names, ", ")+" "+exprString(field.Type))
+	}
+	return "[" + strings.Join(groups, ", ") + "]"
+}
+
 func receiverName(d *ast.FuncDecl) string {
 	if d.Recv == nil || len(d.Recv.List) == 0 {
 		return ""
@@ -492,10 +894,16 @@ func receiverName(d *ast.FuncDecl) string {
 	return exprString(recv)
 }
 
+// exprString prints expr without the doc and line comments of its fields.
+// Sync parses with comments, and printing them against a fresh FileSet,
+// which knows none of their positions, splices them into the signature.
 func exprString(expr any) string {
 	if expr == nil {
 		return ""
 	}
+	if node, ok := expr.(ast.Node); ok {
+		defer stripFieldComments(node)()
+	}
 	var b bytes.Buffer
 	if err := printer.Fprint(&b, token.NewFileSet(), expr); err != nil {
 		return ""
@@ -503,6 +911,28 @@ func exprString(expr any) string {
 	return b.String()
 }
 
+// stripFieldComments clears the comments of every field under node and
+// returns a func that puts them back, so the parsed file keeps its docs.
+func stripFieldComments(node ast.Node) func() {
+	type saved struct {
+		field        *ast.Field
+		doc, comment *ast.CommentGroup
+	}
+	var fields []saved
+	ast.Inspect(node, func(n ast.Node) bool {
+		if f, ok := n.(*ast.Field); ok && (f.Doc != nil || f.Comment != nil) {
+			fields = append(fields, saved{f, f.Doc, f.Comment})
+			f.Doc, f.Comment = nil, nil
+		}
+		return true
+	})
+	return func() {
+		for _, s := range fields {
+			s.field.Doc, s.field.Comment = s.doc, s.comment
+		}
+	}
+}
+
 func textForPos(fset *token.FileSet, src []byte, start, end token.Pos) string {
 	if !start.IsValid() || !end.IsValid() {
 		return ""
@@ -519,9 +949,227 @@ func textForPos(fset *token.FileSet, src []byte, start, end token.Pos) string {
 	return string(src[offStart:offEnd])
 }
 
+// joinLines encodes call-site lines for symbol_deps.lines: "12,40".
+func joinLines(lines []int) string {
+	parts := make([]string, len(lines))
+	for i, line := range lines {
+		parts[i] = strconv.Itoa(line)
+	}
+	return strings.Join(parts, ",")
+}
+
 func boolToInt(v bool) int {
 	if v {
 		return 1
 	}
 	return 0
 }
+
+// packagePaths returns the module-relative package path ("." for the root)
+// and full import path of the package containing relPath. A vendored
+// package keeps the import path it is vendored under.
+func packagePaths(modulePath, relPath string) (string, string) {
+	pkgPath := filepath.ToSlash(filepath.Dir(relPath))
+	if pkgPath == "." {
+		return ".", modulePath
+	}
+	if vendored, ok := strings.CutPrefix(pkgPath, vendorDir+"/"); ok {
+		return pkgPath, vendored
+	}
+	return pkgPath, modulePath + "/" + pkgPath
+}
+
+// fileRows is one parsed file ready to be written by writeFileRows.
+type fileRows struct {
+	File        SourceFile
+	PackageID   int64
+	PackagePath string
+	ModulePath  string
+	Fset        *token.FileSet
+	Parsed      *ast.File
+	Now         time.Time
+	// LocalPackageID resolves a module-relative package path to its packages
+	// row ID, or nil when that package is not indexed (yet).
+	LocalPackageID func(pkgPath string) any
+}
+
+// lowLevelImports reports whether parsed imports "C", making it a cgo file,
+// and whether it imports "unsafe".
+func lowLevelImports(parsed *ast.File) (cgo, unsafe bool) {
+	for _, imp := range parsed.Imports {
+		switch imp.Path.Value {
+		case `"C"`:
+			cgo = true
+		case `"unsafe"`:
+			unsafe = true
+		}
+	}
+	return cgo, unsafe
+}
+
+// writeFileRows inserts the files row for r.File along with its imports,
+// symbols, symbol deps, type embeds and //go:embed directives.
+func writeFileRows(ctx context.Context, tx *sql.Tx, r fileRows) error {
+	file, now, modulePath := r.File, r.Now, r.ModulePath
+	cgo, unsafe := lowLevelImports(r.Parsed)
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO files (package_id, path, language, lines, hash, build_constraint, cgo, unsafe, generated, created_at, updated_at)
+VALUES (?, ?, 'go', ?, ?, ?, ?, ?, ?, ?, ?);
+`, r.PackageID, file.RelPath, file.Lines, file.Hash, buildConstraint(file.RelPath, r.Parsed), boolToInt(cgo), boolToInt(unsafe),
+		boolToInt(file.Generated), now.Format(time.RFC3339), now.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("insert file %s: %w", file.RelPath, err)
+	}
+	fileID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("read file id: %w", err)
+	}
+
+	localImportAliases := map[string]string{}
+	externalImportAliases := map[string]string{}
+
+	for _, imp := range r.Parsed.Imports {
+		toPath, err := importPathUnquote(imp.Path.Value)
+		if err != nil {
+			toPath = strings.Trim(imp.Path.Value, "\"")
+		}
+		alias := ""
+		if imp.Name != nil {
+			alias = imp.Name.Name
+		} else {
+			alias = path.Base(toPath)
+		}
+
+		importType := "external"
+		var toPkgID any
+		localPkgPath := ""
+		if toPath == modulePath || strings.HasPrefix(toPath, modulePath+"/") {
+			importType = "local"
+			rel := strings.TrimPrefix(toPath, modulePath)
+			rel = strings.TrimPrefix(rel, "/")
+			if rel == "" {
+				rel = "."
+			}
+			localPkgPath = rel
+			toPkgID = r.LocalPackageID(rel)
+		}
+		if alias != "" && alias != "_" && alias != "." {
+			localImportAliases[alias] = localPkgPath
+			if importType == "external" {
+				externalImportAliases[alias] = toPath
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO imports (from_file_id, to_path, to_package_id, alias, import_type)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(from_file_id, to_path) DO UPDATE SET
+    to_package_id = excluded.to_package_id,
+    alias = excluded.alias,
+    import_type = excluded.import_type;
+`, fileID, toPath, toPkgID, alias, importType); err != nil {
+			return fmt.Errorf("insert import %s: %w", toPath, err)
+		}
+	}
+
+	for _, decl := range r.Parsed.Decls {
+		records := symbolRecordsFromDeclWithContext(r.Fset, file.Content, decl, depContext{
+			PackagePath:     r.PackagePath,
+			LocalImports:    localImportAliases,
+			ExternalImports: externalImportAliases,
+		})
+		for _, rec := range records {
+			bodyHash, err := storeBody(ctx, tx, rec.Body)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `
+INSERT INTO symbols (file_id, kind, name, signature, body_hash, line_start, line_end, exported, receiver)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(file_id, kind, name, receiver) DO UPDATE SET
+    signature = excluded.signature,
+    body_hash = excluded.body_hash,
+    line_start = excluded.line_start,
+    line_end = excluded.line_end,
+    exported = excluded.exported;
+`, fileID, rec.Kind, rec.Name, rec.Signature, bodyHash, rec.LineStart, rec.LineEnd, boolToInt(rec.Exported), rec.Receiver); err != nil {
+				return fmt.Errorf("insert symbol %s: %w", rec.Name, err)
+			}
+
+			var symbolID int64
+			if err := tx.QueryRowContext(ctx, `
+SELECT id FROM symbols WHERE file_id = ? AND kind = ? AND name = ? AND receiver = ?;
+`, fileID, rec.Kind, rec.Name, rec.Receiver).Scan(&symbolID); err != nil {
+				return fmt.Errorf("resolve symbol id for %s: %w", rec.Name, err)
+			}
+			if rec.Deprecated != "" {
+				if err := writeDeprecation(ctx, tx, fileID, symbolID, rec.LineStart, rec.Deprecated); err != nil {
+					return err
+				}
+			}
+
+			for _, dep := range rec.DepRefs {
+				if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO symbol_deps (symbol_id, dep_name, dep_package, dep_kind, lines)
+VALUES (?, ?, ?, ?, ?);
+`, symbolID, dep.Name, dep.PackagePath, dep.Kind, joinLines(dep.Lines)); err != nil {
+					return fmt.Errorf("insert symbol dep %s: %w", dep.Name, err)
+				}
+			}
+
+			for _, embed := range rec.Embeds {
+				if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO type_embeds (symbol_id, embedded_name, embedded_package, pointer)
+VALUES (?, ?, ?, ?);
+`, symbolID, embed.Name, embed.PackagePath, boolToInt(embed.Pointer)); err != nil {
+					return fmt.Errorf("insert type embed %s: %w", embed.Name, err)
+				}
+			}
+
+			for i, field := range rec.Fields {
+				if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO struct_fields (symbol_id, position, name, type, tag, embedded, exported)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+`, symbolID, i, field.Name, field.Type, field.Tag, boolToInt(field.Embedded), boolToInt(ast.IsExported(field.Name))); err != nil {
+					return fmt.Errorf("insert struct field %s: %w", field.Name, err)
+				}
+			}
+
+			for _, method := range rec.Methods {
+				if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO type_methods (symbol_id, name, signature)
+VALUES (?, ?, ?);
+`, symbolID, method.Name, method.Signature); err != nil {
+					return fmt.Errorf("insert interface method %s: %w", method.Name, err)
+				}
+			}
+
+			for i, param := range rec.TypeParams {
+				if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO type_params (symbol_id, position, name, constraint_expr)
+VALUES (?, ?, ?, ?);
+`, symbolID, i, param.Name, param.Constraint); err != nil {
+					return fmt.Errorf("insert type param %s: %w", param.Name, err)
+				}
+			}
+
+			// Positions continue across const blocks declaring the same enum.
+			for _, member := range rec.Members {
+				if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO enum_members (symbol_id, position, name, value)
+VALUES (?, (SELECT COUNT(*) FROM enum_members WHERE symbol_id = ?), ?, ?);
+`, symbolID, symbolID, member.Name, member.Value); err != nil {
+					return fmt.Errorf("insert enum member %s: %w", member.Name, err)
+				}
+			}
+		}
+	}
+	if err := writeEmbeds(ctx, tx, fileID, file, r.Fset, r.Parsed); err != nil {
+		return err
+	}
+	if file.Generated {
+		// Nobody acts on a TODO in code they do not edit.
+		return nil
+	}
+	return writeAnnotations(ctx, tx, fileID, r.Fset, r.Parsed)
+}
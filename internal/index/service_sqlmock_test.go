@@ -25,9 +25,20 @@ func writeModuleForSync(t *testing.T, src string) string {
 
 func expectResetTables(mock sqlmock.Sqlmock) {
 	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT DISTINCT to_ref FROM edges").WillReturnRows(sqlmock.NewRows([]string{"to_ref"}))
+	mock.ExpectExec("DELETE FROM edges WHERE source").WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("DELETE FROM symbol_deps").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM type_embeds").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM struct_fields").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM enum_members").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM type_params").WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("DELETE FROM imports").WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("DELETE FROM symbols").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM symbol_bodies").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM file_embeds").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM annotations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM code_owners").WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("DELETE FROM files").WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("DELETE FROM packages").WillReturnResult(sqlmock.NewResult(0, 0))
 }
@@ -100,6 +111,7 @@ func TestSyncSQLMockErrorBranches(t *testing.T) {
 				expectResetTables(mock)
 				mock.ExpectExec("INSERT INTO packages").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectExec("INSERT INTO files").WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("INSERT INTO symbol_bodies").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectExec("INSERT INTO symbols").WillReturnError(errors.New("symbol fail"))
 				mock.ExpectRollback()
 			},
@@ -112,6 +124,7 @@ func TestSyncSQLMockErrorBranches(t *testing.T) {
 				expectResetTables(mock)
 				mock.ExpectExec("INSERT INTO packages").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectExec("INSERT INTO files").WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("INSERT INTO symbol_bodies").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectExec("INSERT INTO symbols").WillReturnResult(sqlmock.NewResult(3, 1))
 				mock.ExpectQuery("SELECT id FROM symbols").WillReturnRows(sqlmock.NewRows([]string{"id"}))
 				mock.ExpectRollback()
@@ -125,6 +138,7 @@ func TestSyncSQLMockErrorBranches(t *testing.T) {
 				expectResetTables(mock)
 				mock.ExpectExec("INSERT INTO packages").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectExec("INSERT INTO files").WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("INSERT INTO symbol_bodies").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectExec("INSERT INTO symbols").WillReturnResult(sqlmock.NewResult(3, 1))
 				mock.ExpectQuery("SELECT id FROM symbols").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
 				mock.ExpectExec("INSERT OR IGNORE INTO symbol_deps").WillReturnError(errors.New("dep fail"))
@@ -132,6 +146,48 @@ func TestSyncSQLMockErrorBranches(t *testing.T) {
 			},
 			wantErr: "insert symbol dep",
 		},
+		{
+			name: "link local imports error",
+			src:  "package main\n",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				expectResetTables(mock)
+				mock.ExpectExec("INSERT INTO packages").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO files").WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("UPDATE imports").WillReturnError(errors.New("link fail"))
+				mock.ExpectRollback()
+			},
+			wantErr: "link local imports",
+		},
+		{
+			name: "link method sets error",
+			src:  "package main\n",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				expectResetTables(mock)
+				mock.ExpectExec("INSERT INTO packages").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO files").WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("UPDATE imports").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT OR IGNORE INTO type_methods").WillReturnError(errors.New("method set fail"))
+				mock.ExpectRollback()
+			},
+			wantErr: "link method sets",
+		},
+		{
+			name: "write dependencies error",
+			src:  "package main\n",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				expectResetTables(mock)
+				mock.ExpectExec("INSERT INTO packages").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO files").WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("UPDATE imports").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT OR IGNORE INTO type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM dependencies").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO module_info").WillReturnError(errors.New("module info fail"))
+				mock.ExpectRollback()
+			},
+			wantErr: "upsert module info",
+		},
 		{
 			name: "count symbols error",
 			src:  "package main\n",
@@ -139,6 +195,11 @@ func TestSyncSQLMockErrorBranches(t *testing.T) {
 				expectResetTables(mock)
 				mock.ExpectExec("INSERT INTO packages").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectExec("INSERT INTO files").WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("UPDATE imports").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT OR IGNORE INTO type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM dependencies").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO module_info").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectQuery("SELECT COUNT").WillReturnError(errors.New("count fail"))
 				mock.ExpectRollback()
 			},
@@ -151,6 +212,11 @@ func TestSyncSQLMockErrorBranches(t *testing.T) {
 				expectResetTables(mock)
 				mock.ExpectExec("INSERT INTO packages").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectExec("INSERT INTO files").WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("UPDATE imports").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT OR IGNORE INTO type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM dependencies").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO module_info").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectQuery("SELECT COUNT").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 				mock.ExpectExec("UPDATE packages").WillReturnError(errors.New("update pkg fail"))
 				mock.ExpectRollback()
@@ -164,13 +230,44 @@ func TestSyncSQLMockErrorBranches(t *testing.T) {
 				expectResetTables(mock)
 				mock.ExpectExec("INSERT INTO packages").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectExec("INSERT INTO files").WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("UPDATE imports").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT OR IGNORE INTO type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM dependencies").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO module_info").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectQuery("SELECT COUNT").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 				mock.ExpectExec("UPDATE packages").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("FROM file_embeds").WillReturnRows(sqlmock.NewRows([]string{"path", "line", "var_name", "pattern"}))
+				mock.ExpectExec("DELETE FROM edges WHERE source").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT OR IGNORE INTO edges").WillReturnResult(sqlmock.NewResult(0, 0))
 				mock.ExpectExec("INSERT INTO sync_state").WillReturnError(errors.New("sync state fail"))
 				mock.ExpectRollback()
 			},
 			wantErr: "upsert sync state",
 		},
+		{
+			name: "record sync run error",
+			src:  "package main\n",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				expectResetTables(mock)
+				mock.ExpectExec("INSERT INTO packages").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO files").WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("UPDATE imports").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT OR IGNORE INTO type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM dependencies").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO module_info").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT COUNT").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectExec("UPDATE packages").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("FROM file_embeds").WillReturnRows(sqlmock.NewRows([]string{"path", "line", "var_name", "pattern"}))
+				mock.ExpectExec("DELETE FROM edges WHERE source").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT OR IGNORE INTO edges").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO sync_state").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO sync_history").WillReturnError(errors.New("history fail"))
+				mock.ExpectRollback()
+			},
+			wantErr: "record sync run",
+		},
 		{
 			name: "commit error",
 			src:  "package main\n",
@@ -178,9 +275,18 @@ func TestSyncSQLMockErrorBranches(t *testing.T) {
 				expectResetTables(mock)
 				mock.ExpectExec("INSERT INTO packages").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectExec("INSERT INTO files").WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("UPDATE imports").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT OR IGNORE INTO type_methods").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM dependencies").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO module_info").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectQuery("SELECT COUNT").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 				mock.ExpectExec("UPDATE packages").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("FROM file_embeds").WillReturnRows(sqlmock.NewRows([]string{"path", "line", "var_name", "pattern"}))
+				mock.ExpectExec("DELETE FROM edges WHERE source").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT OR IGNORE INTO edges").WillReturnResult(sqlmock.NewResult(0, 0))
 				mock.ExpectExec("INSERT INTO sync_state").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO sync_history").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectCommit().WillReturnError(errors.New("commit fail"))
 			},
 			wantErr: "commit sync tx",
@@ -3,13 +3,16 @@ package index
 import (
 	"context"
 	"errors"
+	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/robertguss/recon/internal/db"
 )
@@ -251,8 +254,9 @@ func TestSyncErrors(t *testing.T) {
 		t.Fatalf("Open: %v", err)
 	}
 	defer conn2.Close()
-	if _, err := NewService(conn2).Sync(context.Background(), root2); err == nil || !strings.Contains(err.Error(), "reset index tables") {
-		t.Fatalf("expected reset table error, got %v", err)
+	// Without migrations the first query, for symbol edges, fails.
+	if _, err := NewService(conn2).Sync(context.Background(), root2); err == nil || !strings.Contains(err.Error(), "query symbol edges") {
+		t.Fatalf("expected symbol edges error, got %v", err)
 	}
 
 	conn3, err := db.Open(db.DBPath(root2))
@@ -272,7 +276,9 @@ func TestSyncErrors(t *testing.T) {
 	}
 	origCollect := collectEligibleFiles
 	defer func() { collectEligibleFiles = origCollect }()
-	collectEligibleFiles = func(string) ([]SourceFile, error) { return nil, errors.New("collect fail") }
+	collectEligibleFiles = func(string) ([]SourceFile, []IgnoredPath, []IgnoreWarning, error) {
+		return nil, nil, nil, errors.New("collect fail")
+	}
 	if _, err := NewService(conn2).Sync(context.Background(), root3); err == nil || !strings.Contains(err.Error(), "collect fail") {
 		t.Fatalf("expected collect files error, got %v", err)
 	}
@@ -363,6 +369,7 @@ func F(v thing) {
 	v.Method()
 	(func() {})()
 	time.Now().Format(time.RFC3339)
+	Local()
 }
 `
 	fset := token.NewFileSet()
@@ -380,7 +387,7 @@ func F(v thing) {
 	if fnDecl == nil {
 		t.Fatal("expected function declaration")
 	}
-	deps := collectCallDeps(fnDecl.Body, depContext{
+	deps := collectCallDeps(fset, fnDecl.Body, depContext{
 		PackagePath: ".",
 		LocalImports: map[string]string{
 			"local":  "pkg1",
@@ -390,18 +397,18 @@ func F(v thing) {
 	})
 
 	want := map[string]depRef{
-		"Local\x00.\x00func":       {Name: "Local", PackagePath: ".", Kind: "func"},
-		"External\x00pkg1\x00func": {Name: "External", PackagePath: "pkg1", Kind: "func"},
-		"External\x00pkg2\x00func": {Name: "External", PackagePath: "pkg2", Kind: "func"},
-		"Method\x00.\x00method":    {Name: "Method", PackagePath: ".", Kind: "method"},
-		"Method\x00.\x00func":      {Name: "Method", PackagePath: ".", Kind: "func"},
+		"Local\x00.\x00func":       {Name: "Local", PackagePath: ".", Kind: "func", Lines: []int{6, 13}},
+		"External\x00pkg1\x00func": {Name: "External", PackagePath: "pkg1", Kind: "func", Lines: []int{7}},
+		"External\x00pkg2\x00func": {Name: "External", PackagePath: "pkg2", Kind: "func", Lines: []int{8}},
+		"Method\x00.\x00method":    {Name: "Method", PackagePath: ".", Kind: "method", Lines: []int{10}},
+		"Method\x00.\x00func":      {Name: "Method", PackagePath: ".", Kind: "func", Lines: []int{9}},
 	}
 	if len(deps) != len(want) {
 		t.Fatalf("unexpected dep count %d: %+v", len(deps), deps)
 	}
 	for _, dep := range deps {
 		key := dep.Name + "\x00" + dep.PackagePath + "\x00" + dep.Kind
-		if got, ok := want[key]; !ok || got != dep {
+		if got, ok := want[key]; !ok || !reflect.DeepEqual(got, dep) {
 			t.Fatalf("unexpected dep %q => %+v", key, dep)
 		}
 	}
@@ -462,6 +469,9 @@ func TestSync_ReportsDiff(t *testing.T) {
 	if result2.Diff.FilesRemoved != 0 {
 		t.Fatalf("expected 0 files removed, got %d", result2.Diff.FilesRemoved)
 	}
+	if len(result2.ChangedFiles) != 1 || result2.ChangedFiles[0] != "extra.go" {
+		t.Fatalf("expected extra.go changed, got %v", result2.ChangedFiles)
+	}
 
 	// Remove the extra file and re-sync
 	os.Remove(filepath.Join(root, "extra.go"))
@@ -475,6 +485,9 @@ func TestSync_ReportsDiff(t *testing.T) {
 	if result3.Diff.FilesRemoved != 1 {
 		t.Fatalf("expected 1 file removed, got %d", result3.Diff.FilesRemoved)
 	}
+	if len(result3.ChangedFiles) != 1 || result3.ChangedFiles[0] != "extra.go" {
+		t.Fatalf("expected removed extra.go in changed files, got %v", result3.ChangedFiles)
+	}
 }
 
 func TestSyncImportUnquoteFallbackAndAliasLocalImportBranches(t *testing.T) {
@@ -517,3 +530,410 @@ func Use() { _ = alias.Use }
 		t.Fatalf("Sync with unquote fallback error: %v", err)
 	}
 }
+
+func TestSyncWithOptions_ParallelMatchesSerial(t *testing.T) {
+	root := t.TempDir()
+	mustWrite := func(path, body string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", path, err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	mustWrite("go.mod", "module example.com/recon\n")
+	mustWrite("main.go", `package main
+import "example.com/recon/sub"
+func main() { sub.Helper() }
+`)
+	for i := 0; i < 12; i++ {
+		mustWrite(fmt.Sprintf("sub/f%02d.go", i), fmt.Sprintf("package sub\nfunc F%02d() { Helper() }\n", i))
+	}
+	mustWrite("sub/helper.go", `package sub
+func Helper() string { return "ok" }
+`)
+
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+
+	snapshot := func() string {
+		t.Helper()
+		rows, err := conn.Query(`
+SELECT f.path, s.kind, s.name, COUNT(d.id)
+FROM symbols s
+JOIN files f ON f.id = s.file_id
+LEFT JOIN symbol_deps d ON d.symbol_id = s.id
+GROUP BY s.id
+ORDER BY f.path, s.name;`)
+		if err != nil {
+			t.Fatalf("snapshot query: %v", err)
+		}
+		defer rows.Close()
+		var b strings.Builder
+		for rows.Next() {
+			var path, kind, name string
+			var deps int
+			if err := rows.Scan(&path, &kind, &name, &deps); err != nil {
+				t.Fatalf("snapshot scan: %v", err)
+			}
+			fmt.Fprintf(&b, "%s %s %s %d\n", path, kind, name, deps)
+		}
+		return b.String()
+	}
+
+	svc := NewService(conn)
+	serial, err := svc.SyncWithOptions(context.Background(), root, SyncOptions{Jobs: 1})
+	if err != nil {
+		t.Fatalf("serial sync: %v", err)
+	}
+	serialSnapshot := snapshot()
+
+	parallel, err := svc.SyncWithOptions(context.Background(), root, SyncOptions{Jobs: 8})
+	if err != nil {
+		t.Fatalf("parallel sync: %v", err)
+	}
+	if serial.IndexedFiles != parallel.IndexedFiles || serial.IndexedSymbols != parallel.IndexedSymbols || serial.Fingerprint != parallel.Fingerprint {
+		t.Fatalf("parallel result %+v differs from serial %+v", parallel, serial)
+	}
+	if got := snapshot(); got != serialSnapshot {
+		t.Fatalf("parallel index differs from serial:\n%s\nvs\n%s", got, serialSnapshot)
+	}
+}
+
+func TestParseFilesPreservesOrderAndErrors(t *testing.T) {
+	files := []SourceFile{
+		{AbsPath: "a.go", RelPath: "a.go", Content: []byte("package a\n")},
+		{AbsPath: "b.go", RelPath: "b.go", Content: []byte("package b\nfunc x(")},
+		{AbsPath: "c.go", RelPath: "c.go", Content: []byte("package c\n")},
+	}
+	results, _ := parseFiles(context.Background(), files, 0)
+	if len(results) != len(files) {
+		t.Fatalf("expected %d results, got %d", len(files), len(results))
+	}
+	if r := <-results[0]; r.err != nil || r.file.Name.Name != "a" {
+		t.Fatalf("unexpected first result: %+v", r)
+	}
+	if r := <-results[1]; r.err == nil {
+		t.Fatal("expected parse error for b.go")
+	}
+	if r := <-results[2]; r.err != nil || r.file.Name.Name != "c" {
+		t.Fatalf("unexpected third result: %+v", r)
+	}
+}
+
+func TestParseFilesBoundsParseAhead(t *testing.T) {
+	files := make([]SourceFile, 50)
+	for i := range files {
+		name := fmt.Sprintf("f%d.go", i)
+		files[i] = SourceFile{AbsPath: name, RelPath: name, Content: []byte("package p\n")}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, release := parseFiles(ctx, files, 2)
+
+	window := 2 * parseAheadPerJob
+	for i := 0; i < window; i++ {
+		if r := <-results[i]; r.err != nil {
+			t.Fatalf("parse %s: %v", files[i].RelPath, r.err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	for i := window; i < len(results); i++ {
+		if len(results[i]) != 0 {
+			t.Fatalf("file %d parsed while %d earlier files were unreleased", i, window)
+		}
+	}
+	for i := range results {
+		if i >= window {
+			if r := <-results[i]; r.err != nil {
+				t.Fatalf("parse %s: %v", files[i].RelPath, r.err)
+			}
+		}
+		release()
+	}
+}
+
+func TestSyncRecordsTypeEmbeds(t *testing.T) {
+	root := t.TempDir()
+	mustWrite := func(path, body string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", path, err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	mustWrite("go.mod", "module example.com/recon\n")
+	mustWrite("main.go", `package main
+import (
+  "io"
+  "sync"
+  "example.com/recon/sub"
+)
+type Local struct{}
+type Box[T any] struct{}
+type Outer struct {
+  Local
+  *sub.Base
+  sync.Mutex
+  Box[int]
+  name string
+}
+type RC interface {
+  io.Reader
+  Close() error
+}
+`)
+	mustWrite("sub/sub.go", `package sub
+type Base struct{}
+`)
+
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	if _, err := NewService(conn).Sync(context.Background(), root); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	rows, err := conn.Query(`
+SELECT s.name, e.embedded_name, e.embedded_package, e.pointer
+FROM type_embeds e JOIN symbols s ON s.id = e.symbol_id
+ORDER BY e.id;`)
+	if err != nil {
+		t.Fatalf("query embeds: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var owner, name, pkg string
+		var pointer bool
+		if err := rows.Scan(&owner, &name, &pkg, &pointer); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%s>%s@%s/%v", owner, name, pkg, pointer))
+	}
+	want := []string{
+		"Outer>Local@./false",
+		"Outer>Base@sub/true",
+		"Outer>Mutex@sync/false",
+		"Outer>Box@./false",
+		"RC>Reader@io/false",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("embeds = %v, want %v", got, want)
+	}
+}
+
+func TestSyncRecordsStructFieldsAndMethodSets(t *testing.T) {
+	root := t.TempDir()
+	mustWrite := func(path, body string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", path, err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	mustWrite("go.mod", "module example.com/recon\n")
+	mustWrite("a_methods.go", `package main
+func (s *Store) Save(v string) error { return nil }
+func (l List[T]) Len() int { return 0 }
+`)
+	mustWrite("main.go", `package main
+import "sync"
+type Store struct {
+  sync.Mutex
+  Name, path string `+"`json:\"name\"`"+`
+  _ int
+}
+type List[T any] struct{ items []T }
+func (s Store) String() string { return s.Name }
+type Saver interface {
+  Save(v string) error
+}
+`)
+	mustWrite("sub/sub.go", `package sub
+type Store struct{}
+func (Store) Other() {}
+`)
+
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	if _, err := NewService(conn).Sync(context.Background(), root); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	query := func(q string) string {
+		t.Helper()
+		rows, err := conn.Query(q)
+		if err != nil {
+			t.Fatalf("query: %v", err)
+		}
+		defer rows.Close()
+		var got []string
+		for rows.Next() {
+			var line string
+			if err := rows.Scan(&line); err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			got = append(got, line)
+		}
+		return strings.Join(got, ",")
+	}
+
+	fields := query(`
+SELECT s.name || '.' || f.name || ':' || f.type || ':' || f.tag || ':' || f.embedded || f.exported
+FROM struct_fields f JOIN symbols s ON s.id = f.symbol_id
+ORDER BY s.name, f.position;`)
+	want := `List.items:[]T::00,Store.Mutex:sync.Mutex::11,Store.Name:string:json:"name":01,Store.path:string:json:"name":00,Store._:int::00`
+	if fields != want {
+		t.Fatalf("fields = %s\nwant %s", fields, want)
+	}
+
+	methods := query(`
+SELECT fl.path || ':' || s.name || '.' || m.name || ':' || m.pointer_receiver || ':' || (m.method_symbol_id IS NOT NULL) || ':' || m.signature
+FROM type_methods m JOIN symbols s ON s.id = m.symbol_id JOIN files fl ON fl.id = s.file_id
+ORDER BY fl.path, s.name, m.name;`)
+	want = "main.go:List.Len:0:1:func() int," +
+		"main.go:Saver.Save:0:0:func(v string) error," +
+		"main.go:Store.Save:1:1:func(v string) error," +
+		"main.go:Store.String:0:1:func() string," +
+		"sub/sub.go:Store.Other:0:1:func()"
+	if methods != want {
+		t.Fatalf("methods = %s\nwant %s", methods, want)
+	}
+}
+
+func TestSyncRecordsTypeParams(t *testing.T) {
+	root, conn, mustWrite := syncFilesFixture(t)
+	mustWrite("generic.go", `package main
+type Map[K comparable, V any] map[K]V
+type List[T interface{ ~int | ~string }] struct{ items []T }
+type Alias = List[int]
+func Keys[K comparable, V any](m Map[K, V]) []K { return nil }
+func (l *List[E]) Len() int { return len(l.items) }
+`)
+	if _, err := NewService(conn).Sync(context.Background(), root); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	signatures := map[string]string{}
+	rows, err := conn.Query(`SELECT name, signature FROM symbols WHERE name IN ('Map', 'List', 'Alias', 'Keys')`)
+	if err != nil {
+		t.Fatalf("query signatures: %v", err)
+	}
+	for rows.Next() {
+		var name, sig string
+		if err := rows.Scan(&name, &sig); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		signatures[name] = sig
+	}
+	rows.Close()
+	for name, want := range map[string]string{
+		"Map":   "[K comparable, V any] map[K]V",
+		"List":  "[T interface{ ~int | ~string }] struct{ items []T }",
+		"Alias": "= List[int]",
+		"Keys":  "func[K comparable, V any](m Map[K, V]) []K",
+	} {
+		if signatures[name] != want {
+			t.Fatalf("%s signature = %q, want %q", name, signatures[name], want)
+		}
+	}
+
+	var got []string
+	rows, err = conn.Query(`
+SELECT s.name || ':' || p.position || ':' || p.name || ':' || p.constraint_expr
+FROM type_params p JOIN symbols s ON s.id = p.symbol_id
+ORDER BY s.name, p.position;`)
+	if err != nil {
+		t.Fatalf("query type params: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		got = append(got, line)
+	}
+	want := "Keys:0:K:comparable,Keys:1:V:any,List:0:T:interface{ ~int | ~string },Map:0:K:comparable,Map:1:V:any"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("type params = %s\nwant %s", strings.Join(got, ","), want)
+	}
+}
+
+func TestLowLevelImports(t *testing.T) {
+	for src, want := range map[string][2]bool{
+		"package p\nimport \"fmt\"\n":                           {false, false},
+		"package p\nimport \"C\"\n":                             {true, false},
+		"package p\nimport (\n\t\"C\"\n\t\"unsafe\"\n)\n":       {true, true},
+		"package p\nimport u \"unsafe\"\nvar _ = u.Sizeof(0)\n": {false, true},
+	} {
+		parsed, err := parser.ParseFile(token.NewFileSet(), "p.go", src, parser.ImportsOnly)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if cgo, unsafe := lowLevelImports(parsed); cgo != want[0] || unsafe != want[1] {
+			t.Errorf("lowLevelImports(%q) = %v, %v; want %v", src, cgo, unsafe, want)
+		}
+	}
+}
+
+func TestSyncReportsProgressAndTimings(t *testing.T) {
+	root, conn, _ := syncFilesFixture(t)
+	var phases []string
+	var last Progress
+	res, err := NewService(conn).SyncWithOptions(context.Background(), root, SyncOptions{Progress: func(p Progress) {
+		if len(phases) == 0 || phases[len(phases)-1] != p.Phase {
+			phases = append(phases, p.Phase)
+		}
+		if p.Phase == PhaseParse && p.Done > 0 {
+			last = p
+		}
+	}})
+	if err != nil {
+		t.Fatalf("SyncWithOptions: %v", err)
+	}
+	if got := strings.Join(phases, " "); got != "collect parse link commit" {
+		t.Fatalf("phases = %q", got)
+	}
+	if last.Done != 2 || last.Total != 2 || last.Package != "sub" {
+		t.Fatalf("last parse progress = %+v, want 2/2 in sub", last)
+	}
+	if res.Timings == nil || res.Timings.TotalMS < res.Timings.CommitMS {
+		t.Fatalf("timings = %+v", res.Timings)
+	}
+}
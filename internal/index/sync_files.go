@@ -0,0 +1,384 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+var (
+	// ErrNotSynced is returned by SyncFiles when there is no full index to update.
+	ErrNotSynced = errors.New("targeted sync requires an existing index; run recon sync first")
+	// ErrInvalidPath wraps SyncFiles errors for paths that cannot be indexed.
+	ErrInvalidPath = errors.New("invalid path")
+)
+
+// SyncFiles re-indexes only the named files, for editor and daemon
+// integrations that already know what changed. Paths may be absolute or
+// relative to moduleRoot. Files that no longer exist, or that a full sync would
+// skip (tests, testdata trees, ignored paths), are removed from the index. Package stats are updated for every package touched, and packages
+// left without files are dropped.
+//
+// The module is not walked: the stored fingerprint is recomputed from the
+// indexed file hashes, so orient still reports the index as stale when other
+// files changed on disk.
+func (s *Service) SyncFiles(ctx context.Context, moduleRoot string, paths []string) (SyncResult, error) {
+	start := time.Now()
+	modulePath, err := ModulePath(moduleRoot)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	rels, err := targetRelPaths(moduleRoot, paths)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	state, exists, err := db.LoadSyncState(ctx, s.db)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	if !exists {
+		return SyncResult{}, ErrNotSynced
+	}
+	now := time.Now().UTC()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return SyncResult{}, fmt.Errorf("begin sync tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var prevSymbols, prevPackages int
+	_ = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM symbols").Scan(&prevSymbols)
+	_ = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM packages").Scan(&prevPackages)
+
+	before, err := snapshotSymbols(ctx, tx)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	diff := &SyncDiff{SymbolsBefore: prevSymbols, PackagesBefore: prevPackages}
+	touched := map[int64]bool{}
+	for _, rel := range rels {
+		file, present, err := loadTargetFile(moduleRoot, rel)
+		if err != nil {
+			return SyncResult{}, err
+		}
+
+		var (
+			oldID, oldPkgID int64
+			oldHash         string
+		)
+		err = tx.QueryRowContext(ctx, `SELECT id, hash, COALESCE(package_id, 0) FROM files WHERE path = ?;`, rel).Scan(&oldID, &oldHash, &oldPkgID)
+		indexed := err == nil
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return SyncResult{}, fmt.Errorf("load file %s: %w", rel, err)
+		}
+		if indexed && present && oldHash == file.Hash {
+			continue
+		}
+
+		if indexed {
+			if _, err := tx.ExecContext(ctx, `
+DELETE FROM edges WHERE source = 'sync' AND from_type = 'symbol'
+  AND from_id IN (SELECT id FROM symbols WHERE file_id = ?);
+`, oldID); err != nil {
+				return SyncResult{}, fmt.Errorf("delete links of %s: %w", rel, err)
+			}
+			// Cascades to the file's symbols, imports, and per-symbol rows.
+			if _, err := tx.ExecContext(ctx, `DELETE FROM files WHERE id = ?;`, oldID); err != nil {
+				return SyncResult{}, fmt.Errorf("delete file %s: %w", rel, err)
+			}
+			if oldPkgID != 0 {
+				touched[oldPkgID] = true
+			}
+		}
+		switch {
+		case !present && indexed:
+			diff.FilesRemoved++
+		case present && indexed:
+			diff.FilesModified++
+		case present:
+			diff.FilesAdded++
+		}
+		if !present {
+			continue
+		}
+		if file.Language != "" {
+			if err := writeIndexedFile(ctx, tx, file, fileIndexerFor(rel), modulePath, now); err != nil {
+				return SyncResult{}, err
+			}
+			continue
+		}
+
+		fset := token.NewFileSet()
+		parsed, err := parser.ParseFile(fset, file.AbsPath, file.Content, parser.ParseComments)
+		if err != nil {
+			return SyncResult{}, fmt.Errorf("parse %s: %w", rel, err)
+		}
+		pkgPath, importPath := packagePaths(modulePath, rel)
+		pkgID, err := ensurePackage(ctx, tx, pkgPath, parsed.Name.Name, importPath, now)
+		if err != nil {
+			return SyncResult{}, err
+		}
+		touched[pkgID] = true
+
+		if err := writeFileRows(ctx, tx, fileRows{
+			File:        file,
+			PackageID:   pkgID,
+			PackagePath: pkgPath,
+			ModulePath:  modulePath,
+			Fset:        fset,
+			Parsed:      parsed,
+			Now:         now,
+			LocalPackageID: func(rel string) any {
+				var id int64
+				if err := tx.QueryRowContext(ctx, `SELECT id FROM packages WHERE path = ?;`, rel).Scan(&id); err != nil {
+					return nil
+				}
+				return id
+			},
+		}); err != nil {
+			return SyncResult{}, err
+		}
+	}
+
+	if err := refreshPackageStats(ctx, tx, touched, now); err != nil {
+		return SyncResult{}, err
+	}
+	if err := linkMethodSets(ctx, tx); err != nil {
+		return SyncResult{}, err
+	}
+	if err := linkProtoServices(ctx, tx, now); err != nil {
+		return SyncResult{}, err
+	}
+	if err := pruneBodies(ctx, tx); err != nil {
+		return SyncResult{}, err
+	}
+
+	fingerprint, fileCount, err := indexedFingerprint(ctx, tx)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	var symbolCount, packageCount int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM symbols").Scan(&symbolCount); err != nil {
+		return SyncResult{}, fmt.Errorf("count symbols: %w", err)
+	}
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM packages").Scan(&packageCount); err != nil {
+		return SyncResult{}, fmt.Errorf("count packages: %w", err)
+	}
+	diff.SymbolsAfter, diff.PackagesAfter = symbolCount, packageCount
+
+	// Only the dirty flag can be refreshed: a HEAD move means files beyond the
+	// targeted ones may have changed, so the last synced commit is kept.
+	commit, dirty := CurrentGitState(ctx, moduleRoot)
+	if commit != state.LastSyncCommit {
+		commit, dirty = state.LastSyncCommit, state.LastSyncDirty
+	}
+	renames, dangling, err := trackRenames(ctx, tx, before, commit, now)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	missing, err := missingEmbeds(ctx, tx)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	if err := db.UpsertSyncState(ctx, tx, db.SyncState{
+		LastSyncAt:       now,
+		LastSyncCommit:   commit,
+		LastSyncDirty:    dirty,
+		IndexedFileCount: fileCount,
+		IndexFingerprint: fingerprint,
+	}); err != nil {
+		return SyncResult{}, err
+	}
+	if err := db.AppendSyncRun(ctx, tx, db.SyncRun{SyncedAt: now, Mode: "files", Commit: commit, Dirty: dirty,
+		IndexedFiles: fileCount, IndexedSymbols: symbolCount,
+		FilesAdded: diff.FilesAdded, FilesModified: diff.FilesModified, FilesRemoved: diff.FilesRemoved,
+		DurationMS: time.Since(start).Milliseconds()}); err != nil {
+		return SyncResult{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return SyncResult{}, fmt.Errorf("commit sync tx: %w", err)
+	}
+
+	return SyncResult{
+		IndexedFiles:    fileCount,
+		IndexedSymbols:  symbolCount,
+		IndexedPackages: packageCount,
+		Fingerprint:     fingerprint,
+		Commit:          commit,
+		Dirty:           dirty,
+		SyncedAt:        now,
+		Diff:            diff,
+		Files:           rels,
+		Renames:         renames,
+		DanglingEdges:   dangling,
+		MissingEmbeds:   missing,
+	}, nil
+}
+
+// targetRelPaths converts paths to sorted, de-duplicated module-relative
+// slash paths, rejecting anything outside the module or neither a .go file
+// nor one a FileIndexer claims.
+func targetRelPaths(moduleRoot string, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%w: no files given", ErrInvalidPath)
+	}
+	seen := map[string]bool{}
+	rels := make([]string, 0, len(paths))
+	for _, p := range paths {
+		abs := p
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(moduleRoot, abs)
+		}
+		rel, err := filepathRel(moduleRoot, abs)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", p, err)
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ".." || strings.HasPrefix(rel, "../") {
+			return nil, fmt.Errorf("%w: %s is outside the module", ErrInvalidPath, p)
+		}
+		if !strings.HasSuffix(rel, ".go") && fileIndexerFor(rel) == nil {
+			return nil, fmt.Errorf("%w: %s is not a Go file or another indexed language", ErrInvalidPath, p)
+		}
+		if !seen[rel] {
+			seen[rel] = true
+			rels = append(rels, rel)
+		}
+	}
+	sort.Strings(rels)
+	return rels, nil
+}
+
+// loadTargetFile reads rel the way a full sync would. present is false when
+// the file is missing or would not be indexed by a full sync.
+func loadTargetFile(moduleRoot, rel string) (SourceFile, bool, error) {
+	if strings.HasSuffix(rel, "_test.go") {
+		return SourceFile{}, false, nil
+	}
+	dir := moduleRoot
+	for _, part := range strings.Split(filepath.Dir(filepath.FromSlash(rel)), string(filepath.Separator)) {
+		if part == "." {
+			continue
+		}
+		dir = filepath.Join(dir, part)
+		if shouldSkipDir(moduleRoot, dir, part) && dir != filepath.Join(moduleRoot, vendorDir) {
+			return SourceFile{}, false, nil
+		}
+	}
+	if _, ok, err := newIgnoreMatcher(moduleRoot).ignored(rel, false); err != nil || ok {
+		return SourceFile{}, false, err
+	}
+
+	abs := filepath.Join(moduleRoot, filepath.FromSlash(rel))
+	content, err := readFile(abs)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return SourceFile{}, false, nil
+		}
+		return SourceFile{}, false, fmt.Errorf("read %s: %w", rel, err)
+	}
+	return newSourceFile(abs, rel, content), true, nil
+}
+
+// ensurePackage returns the packages row for pkgPath, creating it if needed.
+// A new package also resolves local imports that were recorded before it
+// existed.
+func ensurePackage(ctx context.Context, tx *sql.Tx, pkgPath, name, importPath string, now time.Time) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM packages WHERE path = ?;`, pkgPath).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("load package %s: %w", pkgPath, err)
+	}
+
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO packages (path, name, import_path, file_count, line_count, created_at, updated_at)
+VALUES (?, ?, ?, 0, 0, ?, ?);
+`, pkgPath, name, importPath, now.Format(time.RFC3339), now.Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("insert package %s: %w", pkgPath, err)
+	}
+	if id, err = res.LastInsertId(); err != nil {
+		return 0, fmt.Errorf("read package id: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE imports SET to_package_id = ?
+WHERE import_type = 'local' AND to_path = ? AND to_package_id IS NULL;
+`, id, importPath); err != nil {
+		return 0, fmt.Errorf("link imports of %s: %w", pkgPath, err)
+	}
+	return id, nil
+}
+
+// refreshPackageStats recomputes file and line counts for the given packages
+// and drops those with no files left.
+func refreshPackageStats(ctx context.Context, tx *sql.Tx, pkgIDs map[int64]bool, now time.Time) error {
+	ids := make([]int64, 0, len(pkgIDs))
+	for id := range pkgIDs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		var files, lines int
+		if err := tx.QueryRowContext(ctx, `
+SELECT COUNT(*), COALESCE(SUM(lines), 0) FROM files WHERE package_id = ?;
+`, id).Scan(&files, &lines); err != nil {
+			return fmt.Errorf("count package files: %w", err)
+		}
+		if files == 0 {
+			if _, err := tx.ExecContext(ctx, `UPDATE imports SET to_package_id = NULL WHERE to_package_id = ?;`, id); err != nil {
+				return fmt.Errorf("unlink imports of removed package: %w", err)
+			}
+			if _, err := tx.ExecContext(ctx, `DELETE FROM packages WHERE id = ?;`, id); err != nil {
+				return fmt.Errorf("delete empty package: %w", err)
+			}
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+UPDATE packages SET file_count = ?, line_count = ?, updated_at = ? WHERE id = ?;
+`, files, lines, now.Format(time.RFC3339), id); err != nil {
+			return fmt.Errorf("update package stats: %w", err)
+		}
+	}
+	return nil
+}
+
+// indexedFingerprint computes the fingerprint of the indexed files, which
+// matches CurrentFingerprint whenever the index is in step with the worktree.
+func indexedFingerprint(ctx context.Context, tx *sql.Tx) (string, int, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT path, hash FROM files ORDER BY path;`)
+	if err != nil {
+		return "", 0, fmt.Errorf("query indexed files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []SourceFile
+	for rows.Next() {
+		var f SourceFile
+		if err := rows.Scan(&f.RelPath, &f.Hash); err != nil {
+			return "", 0, fmt.Errorf("scan indexed file: %w", err)
+		}
+		files = append(files, f)
+	}
+	if err := rows.Err(); err != nil {
+		return "", 0, fmt.Errorf("iterate indexed files: %w", err)
+	}
+	return ComputeFingerprint(files), len(files), nil
+}
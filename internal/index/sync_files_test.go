@@ -0,0 +1,255 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func syncFilesFixture(t *testing.T) (string, *sql.DB, func(path, body string)) {
+	t.Helper()
+	root := t.TempDir()
+	mustWrite := func(path, body string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", path, err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	mustWrite("go.mod", "module example.com/recon\n")
+	mustWrite("main.go", `package main
+import "example.com/recon/sub"
+func Call() string { return sub.Helper() }
+`)
+	mustWrite("sub/sub.go", `package sub
+func Helper() string { return "ok" }
+`)
+
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	return root, conn, mustWrite
+}
+
+// indexSnapshot renders the index tables that a targeted sync must keep in
+// step with a full sync, without row IDs or timestamps.
+func indexSnapshot(t *testing.T, conn *sql.DB) string {
+	t.Helper()
+	var b strings.Builder
+	for _, q := range []string{
+		`SELECT path || ' ' || name || ' ' || import_path || ' ' || file_count || ' ' || line_count FROM packages ORDER BY path`,
+		`SELECT f.path || ' ' || p.path || ' ' || f.lines || ' ' || f.hash || ' ' || f.generated FROM files f JOIN packages p ON p.id = f.package_id ORDER BY f.path`,
+		`SELECT f.path || ' ' || s.kind || ' ' || s.name || ' ' || s.receiver || ' ' || s.line_start FROM symbols s JOIN files f ON f.id = s.file_id ORDER BY f.path, s.name, s.kind`,
+		`SELECT f.path || ' ' || i.to_path || ' ' || COALESCE(p.path, '-') FROM imports i JOIN files f ON f.id = i.from_file_id LEFT JOIN packages p ON p.id = i.to_package_id ORDER BY f.path, i.to_path`,
+		`SELECT s.name || ' ' || d.dep_name || ' ' || d.dep_package FROM symbol_deps d JOIN symbols s ON s.id = d.symbol_id ORDER BY s.name, d.dep_name`,
+		`SELECT s.name || ' ' || f.position || ' ' || f.name || ' ' || f.type FROM struct_fields f JOIN symbols s ON s.id = f.symbol_id ORDER BY s.name, f.position`,
+		`SELECT s.name || ' ' || m.name || ' ' || m.pointer_receiver || ' ' || COALESCE(ms.name, '-') FROM type_methods m JOIN symbols s ON s.id = m.symbol_id LEFT JOIN symbols ms ON ms.id = m.method_symbol_id ORDER BY s.name, m.name`,
+		`SELECT s.name || ' ' || e.position || ' ' || e.name || ' ' || e.value FROM enum_members e JOIN symbols s ON s.id = e.symbol_id ORDER BY s.name, e.position`,
+	} {
+		rows, err := conn.Query(q)
+		if err != nil {
+			t.Fatalf("snapshot query %q: %v", q, err)
+		}
+		for rows.Next() {
+			var line string
+			if err := rows.Scan(&line); err != nil {
+				t.Fatalf("snapshot scan: %v", err)
+			}
+			b.WriteString(line + "\n")
+		}
+		_ = rows.Close()
+		b.WriteString("--\n")
+	}
+	return b.String()
+}
+
+func TestSyncFilesMatchesFullSync(t *testing.T) {
+	root, conn, mustWrite := syncFilesFixture(t)
+	svc := NewService(conn)
+	ctx := context.Background()
+	if _, err := svc.Sync(ctx, root); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	// Modify a file, add one in a new package, and delete the old package.
+	mustWrite("main.go", `package main
+import "example.com/recon/util"
+func Call() string { return util.Trim("x") }
+func Extra() {}
+type T struct{ A int }
+`)
+	mustWrite("methods.go", "package main\nfunc (t *T) M() {}\n")
+	mustWrite("util/util.go", `package util
+func Trim(s string) string { return s }
+`)
+	if err := os.Remove(filepath.Join(root, "sub", "sub.go")); err != nil {
+		t.Fatalf("remove sub.go: %v", err)
+	}
+
+	res, err := svc.SyncFiles(ctx, root, []string{"main.go", filepath.Join(root, "util", "util.go"), "sub/sub.go", "main.go", "methods.go"})
+	if err != nil {
+		t.Fatalf("SyncFiles: %v", err)
+	}
+	if strings.Join(res.Files, ",") != "main.go,methods.go,sub/sub.go,util/util.go" {
+		t.Fatalf("unexpected targeted files: %v", res.Files)
+	}
+	if res.Diff == nil || res.Diff.FilesAdded != 2 || res.Diff.FilesModified != 1 || res.Diff.FilesRemoved != 1 {
+		t.Fatalf("unexpected diff: %+v", res.Diff)
+	}
+	if res.IndexedFiles != 3 || res.IndexedPackages != 2 {
+		t.Fatalf("unexpected totals: %+v", res)
+	}
+
+	fingerprint, _, err := CurrentFingerprint(root)
+	if err != nil {
+		t.Fatalf("CurrentFingerprint: %v", err)
+	}
+	if res.Fingerprint != fingerprint {
+		t.Fatalf("expected fingerprint to match worktree, got %s want %s", res.Fingerprint, fingerprint)
+	}
+	state, _, err := db.LoadSyncState(ctx, conn)
+	if err != nil || state.IndexFingerprint != fingerprint || state.IndexedFileCount != 3 {
+		t.Fatalf("unexpected sync state %+v err=%v", state, err)
+	}
+
+	targeted := indexSnapshot(t, conn)
+	if !strings.Contains(targeted, "T M 1 M\n") {
+		t.Fatalf("expected method set linked across files:\n%s", targeted)
+	}
+	if _, err := svc.Sync(ctx, root); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if full := indexSnapshot(t, conn); full != targeted {
+		t.Fatalf("targeted sync diverged from full sync\ntargeted:\n%s\nfull:\n%s", targeted, full)
+	}
+}
+
+func TestSyncFilesSkipsUnchangedAndIneligible(t *testing.T) {
+	root, conn, mustWrite := syncFilesFixture(t)
+	svc := NewService(conn)
+	ctx := context.Background()
+	if _, err := svc.Sync(ctx, root); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	before := indexSnapshot(t, conn)
+
+	mustWrite("sub/sub_test.go", "package sub\nfunc TestX() {}\n")
+	mustWrite("testdata/fixture.go", "package fixture\nfunc F() {}\n")
+
+	res, err := svc.SyncFiles(ctx, root, []string{"main.go", "sub/sub_test.go", "testdata/fixture.go"})
+	if err != nil {
+		t.Fatalf("SyncFiles: %v", err)
+	}
+	if res.Diff.FilesAdded+res.Diff.FilesModified+res.Diff.FilesRemoved != 0 {
+		t.Fatalf("expected no changes, got %+v", res.Diff)
+	}
+	if after := indexSnapshot(t, conn); after != before {
+		t.Fatalf("index changed for unchanged/ineligible files\nbefore:\n%s\nafter:\n%s", before, after)
+	}
+}
+
+func TestSyncFlagsGeneratedAndVendoredFiles(t *testing.T) {
+	root, conn, mustWrite := syncFilesFixture(t)
+	svc := NewService(conn)
+	ctx := context.Background()
+	mustWrite("gen.go", "// Code generated by tool. DO NOT EDIT.\npackage main\n// TODO: not ours\nfunc Gen() {}\n")
+	mustWrite("vendor/example.com/dep/dep.go", "package dep\nfunc Dep() {}\n")
+	// Neither quotes the header where the Go convention looks for it.
+	mustWrite("gosource.go", "package main\nconst header = \"// Code generated by tool. DO NOT EDIT.\\n\"\n")
+	mustWrite("schema.sql", "-- Code generated by tool. DO NOT EDIT.\nCREATE TABLE t (id INTEGER);\n")
+	if _, err := svc.Sync(ctx, root); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	full := indexSnapshot(t, conn)
+
+	var generated []string
+	rows, err := conn.Query(`SELECT path FROM files WHERE generated = 1 ORDER BY path`)
+	if err != nil {
+		t.Fatalf("query generated files: %v", err)
+	}
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		generated = append(generated, p)
+	}
+	_ = rows.Close()
+	if strings.Join(generated, " ") != "gen.go vendor/example.com/dep/dep.go" {
+		t.Fatalf("generated files = %v", generated)
+	}
+	var importPath string
+	if err := conn.QueryRow(`SELECT import_path FROM packages WHERE path = 'vendor/example.com/dep'`).Scan(&importPath); err != nil || importPath != "example.com/dep" {
+		t.Fatalf("vendored import path = %q, %v; want example.com/dep", importPath, err)
+	}
+	var todos int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM annotations`).Scan(&todos); err != nil || todos != 0 {
+		t.Fatalf("annotations = %d, %v; want none from generated code", todos, err)
+	}
+
+	mustWrite("gen.go", "// Code generated by tool. DO NOT EDIT.\npackage main\nfunc Gen() {}\nfunc Gen2() {}\n")
+	mustWrite("vendor/example.com/dep/dep.go", "package dep\nfunc Dep() {}\nfunc Dep2() {}\n")
+	if _, err := svc.SyncFiles(ctx, root, []string{"gen.go", "vendor/example.com/dep/dep.go"}); err != nil {
+		t.Fatalf("SyncFiles: %v", err)
+	}
+	targeted := indexSnapshot(t, conn)
+	if _, err := svc.Sync(ctx, root); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if after := indexSnapshot(t, conn); after != targeted || after == full {
+		t.Fatalf("targeted sync diverged from full sync\ntargeted:\n%s\nfull:\n%s", targeted, after)
+	}
+}
+
+func TestSyncFilesErrors(t *testing.T) {
+	root, conn, mustWrite := syncFilesFixture(t)
+	svc := NewService(conn)
+	ctx := context.Background()
+
+	if _, err := svc.SyncFiles(ctx, root, []string{"main.go"}); !errors.Is(err, ErrNotSynced) {
+		t.Fatalf("expected ErrNotSynced before first sync, got %v", err)
+	}
+	if _, err := svc.Sync(ctx, root); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	for _, tc := range []struct {
+		paths []string
+		want  string
+	}{
+		{nil, "no files given"},
+		{[]string{"../other/x.go"}, "outside the module"},
+		{[]string{"README.md"}, "not a Go file"},
+	} {
+		_, err := svc.SyncFiles(ctx, root, tc.paths)
+		if !errors.Is(err, ErrInvalidPath) || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("SyncFiles(%v) = %v, want ErrInvalidPath containing %q", tc.paths, err, tc.want)
+		}
+	}
+
+	before := indexSnapshot(t, conn)
+	mustWrite("main.go", "package main\nfunc Broken( {\n")
+	if _, err := svc.SyncFiles(ctx, root, []string{"main.go"}); err == nil || !strings.Contains(err.Error(), "parse main.go") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+	if after := indexSnapshot(t, conn); after != before {
+		t.Fatal("expected failed targeted sync to roll back")
+	}
+}
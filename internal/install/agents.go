@@ -0,0 +1,76 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Agents lists the coding agents `recon init --agent` can integrate with.
+var Agents = []string{"claude", "copilot", "cursor", "gemini", "windsurf"}
+
+// agentTarget describes where an agent reads project rules from. Agents with
+// a dedicated rules directory get a recon-owned file; agents that read a
+// single shared instructions file get a recon section merged into it.
+type agentTarget struct {
+	path        string
+	frontmatter string
+	shared      bool
+}
+
+var agentTargets = map[string]agentTarget{
+	"copilot": {path: ".github/copilot-instructions.md", shared: true},
+	"cursor": {
+		path:        ".cursor/rules/recon.mdc",
+		frontmatter: "---\ndescription: Recon code intelligence for this Go repository\nalwaysApply: true\n---\n\n",
+	},
+	"gemini": {path: "GEMINI.md", shared: true},
+	"windsurf": {
+		path:        ".windsurf/rules/recon.md",
+		frontmatter: "---\ntrigger: always_on\n---\n\n",
+	},
+}
+
+// IsSupportedAgent reports whether name is one of Agents.
+func IsSupportedAgent(name string) bool {
+	for _, a := range Agents {
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+// InstallAgentRules writes recon's always-on rules for a non-Claude agent and
+// returns the path written, relative to root. These agents have no
+// session-start hook, so the rules tell the agent to run orient itself.
+func InstallAgentRules(root, agent string) (string, error) {
+	target, ok := agentTargets[agent]
+	if !ok {
+		return "", fmt.Errorf("unsupported agent %q; must be one of: %s", agent, strings.Join(Agents, ", "))
+	}
+
+	rules, err := readAsset("assets/AGENT_RULES.md")
+	if err != nil {
+		return "", fmt.Errorf("read embedded agent rules: %w", err)
+	}
+
+	path := filepath.Join(root, filepath.FromSlash(target.path))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("create %s rules dir: %w", agent, err)
+	}
+
+	if target.shared {
+		if err := installSection(path, rules); err != nil {
+			return "", fmt.Errorf("install %s rules: %w", agent, err)
+		}
+		return target.path, nil
+	}
+
+	data := append([]byte(target.frontmatter), rules...)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("write %s rules: %w", agent, err)
+	}
+	return target.path, nil
+}
@@ -0,0 +1,12 @@
+#!/bin/sh
+# recon git hook
+# Installed by: recon install git-hooks. Keeps the recon index current and
+# reports knowledge whose evidence the change affects. It never fails the git
+# command; remove it with: recon install git-hooks --remove
+
+command -v recon >/dev/null 2>&1 || exit 0
+cd "$(git rev-parse --show-toplevel)/{{MODULE_DIR}}" 2>/dev/null || exit 0
+[ -f .recon/recon.db ] || exit 0
+
+recon {{COMMAND}} --no-prompt || true
+exit 0
@@ -0,0 +1,21 @@
+# Recon SessionStart hook -- injects orient payload into agent context.
+# Windows variant of recon-orient.sh. Installed by: recon init
+
+$ErrorActionPreference = 'Stop'
+
+# Only run if recon is initialized in this repo.
+if (-not (Test-Path -LiteralPath '.recon/recon.db' -PathType Leaf)) {
+  exit 0
+}
+
+# Re-check evidence whose verify interval has elapsed so orient reports
+# current drift. Build and test checks are too slow for session start.
+try { recon verify --due --skip-toolchain *> $null } catch { }
+
+Write-Output '## Recon Orient Context'
+Write-Output ''
+Write-Output 'The following is live code intelligence data for this repository.'
+Write-Output 'Use it to understand the project structure, recent activity, and existing decisions.'
+Write-Output ''
+recon orient --session-start
+exit $LASTEXITCODE
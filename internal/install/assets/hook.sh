@@ -9,9 +9,13 @@ if [ ! -f ".recon/recon.db" ]; then
   exit 0
 fi
 
+# Re-check evidence whose verify interval has elapsed so orient reports
+# current drift. Build and test checks are too slow for session start.
+recon verify --due --skip-toolchain >/dev/null 2>&1 || true
+
 echo "## Recon Orient Context"
 echo ""
 echo "The following is live code intelligence data for this repository."
 echo "Use it to understand the project structure, recent activity, and existing decisions."
 echo ""
-recon orient --json --auto-sync
+recon orient --session-start
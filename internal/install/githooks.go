@@ -0,0 +1,125 @@
+package install
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitHookCommands maps each git hook `recon install git-hooks` manages to the
+// recon command it runs: a sync of the staged files before a commit, and a
+// full sync after a merge or pull brings in other changes.
+var gitHookCommands = map[string]string{
+	"pre-commit": "sync --staged --quiet",
+	"post-merge": "sync --quiet",
+}
+
+// GitHooks lists the managed git hooks in the order they are reported.
+var GitHooks = []string{"pre-commit", "post-merge"}
+
+// gitHookMarker identifies a hook script written by recon, so it can be
+// updated or removed without touching hooks installed by other tools.
+const gitHookMarker = "# recon git hook"
+
+// ErrForeignGitHook is returned when a hook to install already exists and
+// was not written by recon.
+var ErrForeignGitHook = errors.New("git hook exists and was not installed by recon")
+
+// GitHookResult reports what happened to one git hook: installed, updated,
+// unchanged, replaced (a foreign hook was moved to Backup), removed, or
+// absent.
+type GitHookResult struct {
+	Hook   string `json:"hook"`
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Backup string `json:"backup,omitempty"`
+}
+
+// IsGitHook reports whether name is a git hook recon manages.
+func IsGitHook(name string) bool {
+	_, ok := gitHookCommands[name]
+	return ok
+}
+
+// InstallGitHooks writes the named hooks into hooksDir. moduleDir is the
+// module's path below the top of the work tree, where the hooks run recon.
+// A hook that exists and was not written by recon fails the whole install
+// with ErrForeignGitHook, before anything is written, unless force is set;
+// force moves it aside to <hook>.pre-recon.
+func InstallGitHooks(hooksDir, moduleDir string, hooks []string, force bool) ([]GitHookResult, error) {
+	tmpl, err := readAsset("assets/git-hook.sh")
+	if err != nil {
+		return nil, fmt.Errorf("read embedded git hook: %w", err)
+	}
+
+	existing := map[string][]byte{}
+	for _, hook := range hooks {
+		data, err := os.ReadFile(filepath.Join(hooksDir, hook))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("read %s hook: %w", hook, err)
+		}
+		if !bytes.Contains(data, []byte(gitHookMarker)) && !force {
+			return nil, fmt.Errorf("%w: %s", ErrForeignGitHook, filepath.Join(hooksDir, hook))
+		}
+		existing[hook] = data
+	}
+
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create hooks dir: %w", err)
+	}
+	results := make([]GitHookResult, 0, len(hooks))
+	for _, hook := range hooks {
+		path := filepath.Join(hooksDir, hook)
+		script := strings.NewReplacer("{{MODULE_DIR}}", moduleDir, "{{COMMAND}}", gitHookCommands[hook]).Replace(string(tmpl))
+		result := GitHookResult{Hook: hook, Path: path, Action: "installed"}
+		if old, ok := existing[hook]; ok {
+			switch {
+			case string(old) == script:
+				result.Action = "unchanged"
+			case bytes.Contains(old, []byte(gitHookMarker)):
+				result.Action = "updated"
+			default:
+				result.Action, result.Backup = "replaced", path+".pre-recon"
+				if err := os.Rename(path, result.Backup); err != nil {
+					return results, fmt.Errorf("back up %s hook: %w", hook, err)
+				}
+			}
+		}
+		if result.Action != "unchanged" {
+			if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+				return results, fmt.Errorf("write %s hook: %w", hook, err)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// RemoveGitHooks deletes the named hooks from hooksDir where recon wrote
+// them. Hooks written by other tools are left alone and reported absent.
+func RemoveGitHooks(hooksDir string, hooks []string) ([]GitHookResult, error) {
+	results := make([]GitHookResult, 0, len(hooks))
+	for _, hook := range hooks {
+		path := filepath.Join(hooksDir, hook)
+		result := GitHookResult{Hook: hook, Path: path, Action: "absent"}
+		data, err := os.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return results, fmt.Errorf("read %s hook: %w", hook, err)
+		case bytes.Contains(data, []byte(gitHookMarker)):
+			if err := os.Remove(path); err != nil {
+				return results, fmt.Errorf("remove %s hook: %w", hook, err)
+			}
+			result.Action = "removed"
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
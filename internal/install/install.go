@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 )
 
@@ -20,20 +21,28 @@ var readAsset = func(name string) ([]byte, error) {
 // marshalJSON is a package-level var for testability.
 var marshalJSON = json.MarshalIndent
 
-func InstallHook(root string) error {
-	data, err := readAsset("assets/hook.sh")
-	if err != nil {
-		return fmt.Errorf("read embedded hook: %w", err)
-	}
+// hookScripts maps each embedded SessionStart hook to the file it is
+// installed as. Both are written so a repository shared across platforms
+// works wherever settings.json points.
+var hookScripts = []struct{ asset, name string }{
+	{"assets/hook.sh", "recon-orient.sh"},
+	{"assets/hook.ps1", "recon-orient.ps1"},
+}
 
+func InstallHook(root string) error {
 	dir := filepath.Join(root, ".claude", "hooks")
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return fmt.Errorf("create hooks dir: %w", err)
 	}
 
-	path := filepath.Join(dir, "recon-orient.sh")
-	if err := os.WriteFile(path, data, 0o755); err != nil {
-		return fmt.Errorf("write hook: %w", err)
+	for _, script := range hookScripts {
+		data, err := readAsset(script.asset)
+		if err != nil {
+			return fmt.Errorf("read embedded hook: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, script.name), data, 0o755); err != nil {
+			return fmt.Errorf("write hook: %w", err)
+		}
 	}
 	return nil
 }
@@ -61,44 +70,86 @@ func InstallClaudeSection(root string) error {
 	if err != nil {
 		return fmt.Errorf("read embedded claude section: %w", err)
 	}
+	return installSection(filepath.Join(root, "CLAUDE.md"), section)
+}
+
+// reconSectionMarker is the heading that opens the recon section in shared
+// instruction files such as CLAUDE.md.
+const reconSectionMarker = "## Recon (Code Intelligence)"
+
+// installSection writes section into the markdown file at path, replacing an
+// existing recon section in place or appending one, so that re-running it is
+// idempotent and leaves the rest of the file untouched.
+func installSection(path string, section []byte) error {
+	_, err := writeSection(path, reconSectionMarker, section)
+	return err
+}
 
-	claudePath := filepath.Join(root, "CLAUDE.md")
-	existing, err := os.ReadFile(claudePath)
+// writeSection merges section into the markdown file at path under marker and
+// reports whether the file changed. An unchanged file is not rewritten.
+func writeSection(path, marker string, section []byte) (bool, error) {
+	existing, err := os.ReadFile(path)
 	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("read CLAUDE.md: %w", err)
+		return false, fmt.Errorf("read %s: %w", filepath.Base(path), err)
 	}
 
-	content := string(existing)
-	sectionStr := string(section)
+	content := mergeSection(string(existing), marker, string(section))
+	if content == string(existing) {
+		return false, nil
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
+// mergeSection replaces the section that starts at marker (through the next
+// "## " heading or EOF) with section, or appends section if marker is absent.
+func mergeSection(content, marker, section string) string {
 	if content == "" {
 		// No existing file — write section directly.
-		return os.WriteFile(claudePath, section, 0o644)
+		return section
 	}
 
-	// Check if Recon section already exists.
-	const marker = "## Recon (Code Intelligence)"
+	// Check if the section already exists.
 	idx := strings.Index(content, marker)
 	if idx >= 0 {
-		// Find the end of the Recon section (next ## heading or EOF).
+		// Find the end of the section (next ## heading or EOF).
 		rest := content[idx+len(marker):]
 		endIdx := strings.Index(rest, "\n## ")
 		if endIdx >= 0 {
-			// Replace just the Recon section.
-			content = content[:idx] + sectionStr + rest[endIdx+1:]
-		} else {
-			// Recon section goes to EOF — replace it.
-			content = content[:idx] + sectionStr
-		}
-	} else {
-		// Append with a leading newline.
-		if !strings.HasSuffix(content, "\n") {
-			content += "\n"
+			// Replace just this section, keeping a blank line before the next.
+			if !strings.HasSuffix(section, "\n\n") {
+				section = strings.TrimRight(section, "\n") + "\n\n"
+			}
+			return content[:idx] + section + rest[endIdx+1:]
 		}
-		content += "\n" + sectionStr
+		// Section goes to EOF — replace it.
+		return content[:idx] + section
+	}
+
+	// Append with a leading newline.
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
 	}
+	return content + "\n" + section
+}
+
+// AgentsSectionMarker is the heading of the generated section in AGENTS.md.
+const AgentsSectionMarker = "## Recon Project Map"
 
-	return os.WriteFile(claudePath, []byte(content), 0o644)
+// InstallAgentsSection writes the generated project map section into
+// AGENTS.md at root and reports whether the file changed. Content outside the
+// section is preserved, so it is safe to run from a hook.
+func InstallAgentsSection(root string, section []byte) (bool, error) {
+	if !strings.HasPrefix(string(section), AgentsSectionMarker) {
+		return false, fmt.Errorf("agents section must start with %q", AgentsSectionMarker)
+	}
+	changed, err := writeSection(filepath.Join(root, "AGENTS.md"), AgentsSectionMarker, section)
+	if err != nil {
+		return false, fmt.Errorf("write AGENTS.md: %w", err)
+	}
+	return changed, nil
 }
 
 func InstallSettings(root string) error {
@@ -122,7 +173,7 @@ func InstallSettings(root string) error {
 	// Build the hook entry.
 	hookEntry := map[string]any{
 		"type":    "command",
-		"command": ".claude/hooks/recon-orient.sh",
+		"command": hookCommand(),
 		"timeout": 10000,
 	}
 	sessionStartEntry := map[string]any{
@@ -136,9 +187,12 @@ func InstallSettings(root string) error {
 		hooks = make(map[string]any)
 	}
 
-	// Merge into existing SessionStart entries rather than replacing.
+	// Merge into existing SessionStart entries rather than replacing. An
+	// existing recon hook is pointed at this platform's script.
 	currentEntries, _ := hooks["SessionStart"].([]any)
-	if !hasReconHook(currentEntries) {
+	if existing := findReconHook(currentEntries); existing != nil {
+		existing["command"] = hookCommand()
+	} else {
 		hooks["SessionStart"] = append(currentEntries, sessionStartEntry)
 	}
 	settings["hooks"] = hooks
@@ -151,11 +205,26 @@ func InstallSettings(root string) error {
 	return os.WriteFile(settingsPath, append(data, '\n'), 0o644)
 }
 
-const reconHookCommand = ".claude/hooks/recon-orient.sh"
+const (
+	reconHookCommand        = ".claude/hooks/recon-orient.sh"
+	reconHookCommandWindows = "powershell -NoProfile -ExecutionPolicy Bypass -File .claude/hooks/recon-orient.ps1"
+)
+
+// goos is a package-level var for testability.
+var goos = runtime.GOOS
 
-// hasReconHook checks whether a SessionStart entries list already contains
-// a hook pointing to the recon orient script.
-func hasReconHook(entries []any) bool {
+// hookCommand returns the SessionStart command for the platform recon init
+// runs on. Windows cannot run the shell script, so it gets the PowerShell one.
+func hookCommand() string {
+	if goos == "windows" {
+		return reconHookCommandWindows
+	}
+	return reconHookCommand
+}
+
+// findReconHook returns the hook in a SessionStart entries list that runs
+// either recon orient script, or nil if there is none.
+func findReconHook(entries []any) map[string]any {
 	for _, entry := range entries {
 		entryMap, ok := entry.(map[string]any)
 		if !ok {
@@ -170,10 +239,39 @@ func hasReconHook(entries []any) bool {
 			if !ok {
 				continue
 			}
-			if cmd, _ := hMap["command"].(string); cmd == reconHookCommand {
-				return true
+			if cmd, _ := hMap["command"].(string); cmd == reconHookCommand || cmd == reconHookCommandWindows {
+				return hMap
 			}
 		}
 	}
-	return false
+	return nil
+}
+
+// HookStatus reports whether the SessionStart hook script for this platform
+// exists under root and whether .claude/settings.json registers a recon hook.
+func HookStatus(root string) (script, registered bool, err error) {
+	name := "recon-orient.sh"
+	if goos == "windows" {
+		name = "recon-orient.ps1"
+	}
+	if _, err := os.Stat(filepath.Join(root, ".claude", "hooks", name)); err == nil {
+		script = true
+	} else if !os.IsNotExist(err) {
+		return false, false, fmt.Errorf("stat hook: %w", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(root, ".claude", "settings.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return script, false, nil
+		}
+		return false, false, fmt.Errorf("read settings: %w", err)
+	}
+	var settings map[string]any
+	if err := json.Unmarshal(raw, &settings); err != nil {
+		return false, false, fmt.Errorf("parse settings: %w", err)
+	}
+	hooks, _ := settings["hooks"].(map[string]any)
+	entries, _ := hooks["SessionStart"].([]any)
+	return script, findReconHook(entries) != nil, nil
 }
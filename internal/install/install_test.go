@@ -2,6 +2,7 @@ package install
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -52,6 +53,19 @@ func TestInstallHook(t *testing.T) {
 	if string(got) != string(want) {
 		t.Fatalf("hook content mismatch:\ngot:  %q\nwant: %q", got, want)
 	}
+
+	ps1, err := os.ReadFile(filepath.Join(root, ".claude", "hooks", "recon-orient.ps1"))
+	if err != nil {
+		t.Fatalf("read powershell hook: %v", err)
+	}
+	if !strings.Contains(string(ps1), "recon orient --session-start") {
+		t.Fatalf("powershell hook does not run orient:\n%s", ps1)
+	}
+	for name, hook := range map[string][]byte{"bash": got, "powershell": ps1} {
+		if !strings.Contains(string(hook), "recon verify --due --skip-toolchain") {
+			t.Fatalf("%s hook does not re-check due evidence:\n%s", name, hook)
+		}
+	}
 }
 
 func TestInstallSkill(t *testing.T) {
@@ -333,6 +347,38 @@ func TestInstallSettings(t *testing.T) {
 		}
 	})
 
+	t.Run("registers the powershell hook on windows", func(t *testing.T) {
+		root := t.TempDir()
+		if err := InstallSettings(root); err != nil {
+			t.Fatalf("InstallSettings: %v", err)
+		}
+
+		orig := goos
+		goos = "windows"
+		defer func() { goos = orig }()
+		// Re-running on Windows repoints the recon hook instead of adding one.
+		if err := InstallSettings(root); err != nil {
+			t.Fatalf("InstallSettings: %v", err)
+		}
+
+		got, err := os.ReadFile(filepath.Join(root, ".claude", "settings.json"))
+		if err != nil {
+			t.Fatalf("read settings: %v", err)
+		}
+		var settings map[string]any
+		if err := json.Unmarshal(got, &settings); err != nil {
+			t.Fatalf("parse settings: %v", err)
+		}
+		sessionStart := settings["hooks"].(map[string]any)["SessionStart"].([]any)
+		if len(sessionStart) != 1 {
+			t.Fatalf("expected 1 SessionStart entry, got %d", len(sessionStart))
+		}
+		hook := sessionStart[0].(map[string]any)["hooks"].([]any)[0].(map[string]any)
+		if hook["command"] != reconHookCommandWindows {
+			t.Fatalf("command = %v, want %q", hook["command"], reconHookCommandWindows)
+		}
+	})
+
 	t.Run("error on invalid existing JSON", func(t *testing.T) {
 		root := t.TempDir()
 		if err := os.MkdirAll(filepath.Join(root, ".claude"), 0o755); err != nil {
@@ -385,6 +431,40 @@ func TestInstallSettings(t *testing.T) {
 	})
 }
 
+func TestHookStatus(t *testing.T) {
+	root := t.TempDir()
+	if script, registered, err := HookStatus(root); err != nil || script || registered {
+		t.Fatalf("empty root: script=%v registered=%v err=%v", script, registered, err)
+	}
+
+	if err := InstallHook(root); err != nil {
+		t.Fatalf("InstallHook: %v", err)
+	}
+	if err := InstallSettings(root); err != nil {
+		t.Fatalf("InstallSettings: %v", err)
+	}
+	if script, registered, err := HookStatus(root); err != nil || !script || !registered {
+		t.Fatalf("installed: script=%v registered=%v err=%v", script, registered, err)
+	}
+
+	orig := goos
+	goos = "windows"
+	defer func() { goos = orig }()
+	if err := os.Remove(filepath.Join(root, ".claude", "hooks", "recon-orient.ps1")); err != nil {
+		t.Fatalf("remove ps1: %v", err)
+	}
+	if script, registered, err := HookStatus(root); err != nil || script || !registered {
+		t.Fatalf("missing windows script: script=%v registered=%v err=%v", script, registered, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, ".claude", "settings.json"), []byte("{"), 0o644); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+	if _, _, err := HookStatus(root); err == nil || !strings.Contains(err.Error(), "parse settings") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
+
 func TestInstallHookErrors(t *testing.T) {
 	t.Run("error on read-only root", func(t *testing.T) {
 		root, cleanup := readOnlyRoot(t)
@@ -587,3 +667,159 @@ func TestInstallClaudeSectionErrors(t *testing.T) {
 		}
 	})
 }
+
+func TestInstallAgentRules(t *testing.T) {
+	root := t.TempDir()
+	rules, err := assetsFS.ReadFile("assets/AGENT_RULES.md")
+	if err != nil {
+		t.Fatalf("read embedded: %v", err)
+	}
+
+	for _, tc := range []struct {
+		agent       string
+		path        string
+		frontmatter string
+	}{
+		{"cursor", ".cursor/rules/recon.mdc", "alwaysApply: true"},
+		{"windsurf", ".windsurf/rules/recon.md", "trigger: always_on"},
+		{"copilot", ".github/copilot-instructions.md", ""},
+		{"gemini", "GEMINI.md", ""},
+	} {
+		rel, err := InstallAgentRules(root, tc.agent)
+		if err != nil {
+			t.Fatalf("InstallAgentRules(%s): %v", tc.agent, err)
+		}
+		if rel != tc.path {
+			t.Fatalf("%s: path = %q, want %q", tc.agent, rel, tc.path)
+		}
+		got, err := os.ReadFile(filepath.Join(root, rel))
+		if err != nil {
+			t.Fatalf("read %s: %v", rel, err)
+		}
+		if !strings.HasSuffix(string(got), string(rules)) {
+			t.Fatalf("%s: rules body missing:\n%s", tc.agent, got)
+		}
+		if tc.frontmatter != "" && (!strings.HasPrefix(string(got), "---\n") || !strings.Contains(string(got), tc.frontmatter)) {
+			t.Fatalf("%s: expected frontmatter %q:\n%s", tc.agent, tc.frontmatter, got)
+		}
+	}
+
+	if _, err := InstallAgentRules(root, "vim"); err == nil || !strings.Contains(err.Error(), `unsupported agent "vim"`) {
+		t.Fatalf("expected unsupported agent error, got %v", err)
+	}
+}
+
+func TestInstallAgentRulesMergesSharedFile(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, ".github", "copilot-instructions.md")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	existing := "# Team conventions\n\nUse table-driven tests.\n"
+	if err := os.WriteFile(path, []byte(existing), 0o644); err != nil {
+		t.Fatalf("write existing: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := InstallAgentRules(root, "copilot"); err != nil {
+			t.Fatalf("InstallAgentRules run %d: %v", i, err)
+		}
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.HasPrefix(string(got), existing) {
+		t.Fatalf("existing content not preserved:\n%s", got)
+	}
+	if strings.Count(string(got), "## Recon (Code Intelligence)") != 1 {
+		t.Fatalf("expected exactly one recon section:\n%s", got)
+	}
+}
+
+func TestInstallAgentsSection(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "AGENTS.md")
+	existing := "# Agents\n\nHand-written notes.\n\n## Recon Project Map\n\nold\n\n## Release\n\nSteps.\n"
+	if err := os.WriteFile(path, []byte(existing), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	section := []byte("## Recon Project Map\n\nnew map\n")
+	changed, err := InstallAgentsSection(root, section)
+	if err != nil || !changed {
+		t.Fatalf("first install: changed=%v err=%v", changed, err)
+	}
+	got, _ := os.ReadFile(path)
+	want := "# Agents\n\nHand-written notes.\n\n## Recon Project Map\n\nnew map\n\n## Release\n\nSteps.\n"
+	if string(got) != want {
+		t.Fatalf("content mismatch:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	changed, err = InstallAgentsSection(root, section)
+	if err != nil || changed {
+		t.Fatalf("second install should be a no-op: changed=%v err=%v", changed, err)
+	}
+
+	if _, err := InstallAgentsSection(root, []byte("# wrong\n")); err == nil {
+		t.Fatal("expected error for section without marker")
+	}
+}
+
+func TestInstallGitHooks(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "hooks")
+
+	results, err := InstallGitHooks(dir, "lib/", GitHooks, false)
+	if err != nil {
+		t.Fatalf("InstallGitHooks: %v", err)
+	}
+	if len(results) != 2 || results[0].Action != "installed" || results[1].Action != "installed" {
+		t.Fatalf("first install = %+v", results)
+	}
+	pre, err := os.ReadFile(filepath.Join(dir, "pre-commit"))
+	if err != nil {
+		t.Fatalf("read pre-commit: %v", err)
+	}
+	for _, want := range []string{"#!/bin/sh\n", gitHookMarker, `cd "$(git rev-parse --show-toplevel)/lib/"`, "recon sync --staged --quiet --no-prompt || true"} {
+		if !strings.Contains(string(pre), want) {
+			t.Fatalf("pre-commit missing %q:\n%s", want, pre)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dir, "post-merge")); err != nil || info.Mode().Perm() != 0o755 {
+		t.Fatalf("post-merge mode: %v, %v", info, err)
+	}
+
+	if results, err = InstallGitHooks(dir, "lib/", GitHooks, false); err != nil || results[0].Action != "unchanged" {
+		t.Fatalf("re-install = %+v, %v", results, err)
+	}
+	if results, err = InstallGitHooks(dir, "", []string{"pre-commit"}, false); err != nil || results[0].Action != "updated" {
+		t.Fatalf("move module = %+v, %v", results, err)
+	}
+
+	foreign := filepath.Join(dir, "post-merge")
+	if err := os.WriteFile(foreign, []byte("#!/bin/sh\nmake deps\n"), 0o755); err != nil {
+		t.Fatalf("write foreign hook: %v", err)
+	}
+	if _, err := InstallGitHooks(dir, "", GitHooks, false); !errors.Is(err, ErrForeignGitHook) {
+		t.Fatalf("expected ErrForeignGitHook, got %v", err)
+	}
+	results, err = InstallGitHooks(dir, "", GitHooks, true)
+	if err != nil || results[1].Action != "replaced" || results[1].Backup != foreign+".pre-recon" {
+		t.Fatalf("forced install = %+v, %v", results, err)
+	}
+	if backup, err := os.ReadFile(foreign + ".pre-recon"); err != nil || !strings.Contains(string(backup), "make deps") {
+		t.Fatalf("backup = %q, %v", backup, err)
+	}
+
+	if err := os.WriteFile(foreign, []byte("#!/bin/sh\nmake deps\n"), 0o755); err != nil {
+		t.Fatalf("write foreign hook: %v", err)
+	}
+	results, err = RemoveGitHooks(dir, GitHooks)
+	if err != nil || results[0].Action != "removed" || results[1].Action != "absent" {
+		t.Fatalf("RemoveGitHooks = %+v, %v", results, err)
+	}
+	if _, err := os.Stat(foreign); err != nil {
+		t.Fatalf("foreign hook removed: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Check is one evidence check: a check type and its JSON spec.
+type Check struct {
+	Type string `json:"type"`
+	Spec string `json:"spec"`
+}
+
+// How the checks of an entity backed by several combine: it holds when all
+// of them pass, or when any does.
+const (
+	CombineAll = "all"
+	CombineAny = "any"
+)
+
+// ParseChecks decodes a JSON array of checks such as
+// [{"type":"file_exists","spec":{"path":"go.mod"}}]. The spec of each may be
+// a JSON object or a string holding one.
+func ParseChecks(raw string) ([]Check, error) {
+	var items []struct {
+		Type string          `json:"type"`
+		Spec json.RawMessage `json:"spec"`
+	}
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return nil, fmt.Errorf("parse check spec array: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("check spec array is empty")
+	}
+	checks := make([]Check, 0, len(items))
+	for i, item := range items {
+		if strings.TrimSpace(item.Type) == "" {
+			return nil, fmt.Errorf("check spec array: check %d has no type", i+1)
+		}
+		spec := strings.TrimSpace(string(item.Spec))
+		var quoted string
+		if json.Unmarshal(item.Spec, &quoted) == nil {
+			spec = quoted
+		}
+		if spec == "" || spec == "null" {
+			spec = "{}"
+		}
+		checks = append(checks, Check{Type: item.Type, Spec: spec})
+	}
+	return checks, nil
+}
+
+// normalizeCombine validates a combine mode, defaulting to all.
+func normalizeCombine(combine string) (string, error) {
+	switch combine = strings.ToLower(strings.TrimSpace(combine)); combine {
+	case "":
+		return CombineAll, nil
+	case CombineAll, CombineAny:
+		return combine, nil
+	default:
+		return "", fmt.Errorf("combine must be all or any, got %q", combine)
+	}
+}
+
+// checkTypes returns the types of checks.
+func checkTypes(checks []Check) []string {
+	types := make([]string, len(checks))
+	for i, c := range checks {
+		types[i] = c.Type
+	}
+	return types
+}
+
+// runChecks runs every check and folds the outcomes into one under combine.
+// A check that cannot run fails with the error as its details. The
+// outcome of a single check is returned as is.
+func (s *Service) runChecks(ctx context.Context, checks []Check, combine, moduleRoot string) (runCheckOutcome, []runCheckOutcome) {
+	outcomes := make([]runCheckOutcome, len(checks))
+	for i, c := range checks {
+		outcome, err := s.runCheck(ctx, ProposeDecisionInput{CheckType: c.Type, CheckSpec: c.Spec, ModuleRoot: moduleRoot})
+		if err != nil {
+			outcome = runCheckOutcome{Passed: false, Details: err.Error(), Baseline: map[string]any{"error": err.Error()}}
+		}
+		outcomes[i] = outcome
+	}
+	return combineOutcomes(checks, combine, outcomes), outcomes
+}
+
+// combineOutcomes folds the outcomes of checks into the outcome of the
+// entity they back.
+func combineOutcomes(checks []Check, combine string, outcomes []runCheckOutcome) runCheckOutcome {
+	if len(outcomes) == 1 {
+		return outcomes[0]
+	}
+	passed := 0
+	parts := make([]string, len(outcomes))
+	for i, o := range outcomes {
+		if o.Passed {
+			passed++
+		}
+		parts[i] = checks[i].Type + ": " + o.Details
+	}
+	ok := passed == len(outcomes)
+	if combine == CombineAny {
+		ok = passed > 0
+	}
+	return runCheckOutcome{
+		Passed:  ok,
+		Details: fmt.Sprintf("%d of %d checks passed (%s required): %s", passed, len(outcomes), combine, strings.Join(parts, "; ")),
+	}
+}
+
+// RunChecks runs checks like RunCheckPublic and reports whether the entity
+// they back would hold under combine.
+func (s *Service) RunChecks(ctx context.Context, checks []Check, combine, moduleRoot string) (CheckOutcome, error) {
+	combine, err := normalizeCombine(combine)
+	if err != nil {
+		return CheckOutcome{}, err
+	}
+	outcome, _ := s.runChecks(ctx, checks, combine, moduleRoot)
+	return CheckOutcome{Passed: outcome.Passed, Details: outcome.Details, Baseline: outcome.Baseline}, nil
+}
+
+// entityDrift returns the drift status of an entity as a whole, folding the
+// statuses of its checks as the entity_evidence view does; ok when it has
+// no evidence.
+func entityDrift(ctx context.Context, q execQueryer, entityType string, id int64) (string, error) {
+	var drift string
+	err := q.QueryRowContext(ctx, `
+SELECT COALESCE((SELECT drift_status FROM entity_evidence WHERE entity_type = ? AND entity_id = ?), 'ok');
+`, entityType, id).Scan(&drift)
+	if err != nil {
+		return "", fmt.Errorf("query %s %d drift: %w", entityType, id, err)
+	}
+	return drift, nil
+}
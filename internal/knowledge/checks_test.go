@@ -0,0 +1,121 @@
+package knowledge
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseChecks(t *testing.T) {
+	checks, err := ParseChecks(`[{"type":"file_exists","spec":{"path":"go.mod"}},{"type":"symbol_exists","spec":"{\"name\":\"Hello\"}"},{"type":"go_build_passes"}]`)
+	if err != nil {
+		t.Fatalf("ParseChecks: %v", err)
+	}
+	want := []Check{
+		{Type: "file_exists", Spec: `{"path":"go.mod"}`},
+		{Type: "symbol_exists", Spec: `{"name":"Hello"}`},
+		{Type: "go_build_passes", Spec: "{}"},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("checks = %+v", checks)
+	}
+	for i := range want {
+		if checks[i] != want[i] {
+			t.Fatalf("check %d = %+v, want %+v", i, checks[i], want[i])
+		}
+	}
+
+	for _, raw := range []string{`[]`, `{"type":"file_exists"}`, `[{"spec":{}}]`} {
+		if _, err := ParseChecks(raw); err == nil {
+			t.Fatalf("ParseChecks(%s) succeeded", raw)
+		}
+	}
+}
+
+func TestProposeAndVerifyDecisionWithChecks(t *testing.T) {
+	root, conn := setupKnowledgeEnv(t)
+	defer conn.Close()
+	svc := NewService(conn)
+	ctx := context.Background()
+	checks := []Check{
+		{Type: "file_exists", Spec: `{"path":"go.mod"}`},
+		{Type: "file_exists", Spec: `{"path":"missing.go"}`},
+	}
+	in := ProposeDecisionInput{
+		Title: "Keep the module file", Reasoning: "r", EvidenceSummary: "e", Confidence: "high",
+		Checks: checks, ModuleRoot: root,
+	}
+
+	res, err := svc.ProposeAndVerifyDecision(ctx, in)
+	if err != nil || res.Promoted || !strings.Contains(res.VerificationDetails, "1 of 2 checks passed (all required)") {
+		t.Fatalf("all = %+v, %v", res, err)
+	}
+
+	in.Combine = CombineAny
+	res, err = svc.ProposeAndVerifyDecision(ctx, in)
+	if err != nil || !res.Promoted {
+		t.Fatalf("any = %+v, %v", res, err)
+	}
+	var rows int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM evidence WHERE entity_type = 'decision' AND entity_id = ? AND combine = 'any'`, res.DecisionID).Scan(&rows); err != nil || rows != 2 {
+		t.Fatalf("evidence rows = %d, %v", rows, err)
+	}
+	if drift, err := entityDrift(ctx, conn, "decision", res.DecisionID); err != nil || drift != "ok" {
+		t.Fatalf("entity drift = %q, %v", drift, err)
+	}
+	items, err := svc.ListDecisions(ctx)
+	if err != nil || len(items) != 1 || items[0].Drift != "ok" {
+		t.Fatalf("ListDecisions = %+v, %v", items, err)
+	}
+
+	// Losing the check that held leaves no check passing, so the decision
+	// breaks as a whole and decays once.
+	if err := os.Remove(filepath.Join(root, "go.mod")); err != nil {
+		t.Fatalf("remove go.mod: %v", err)
+	}
+	result, err := svc.VerifyEvidence(ctx, root)
+	if err != nil {
+		t.Fatalf("VerifyEvidence: %v", err)
+	}
+	if len(result.Changed) != 1 || !result.Changed[0].Decayed || result.Changed[0].Confidence != "medium" {
+		t.Fatalf("changed = %+v", result.Changed)
+	}
+	drifted, err := svc.DriftedEvidence(ctx)
+	if err != nil || len(drifted) != 2 {
+		t.Fatalf("DriftedEvidence = %+v, %v", drifted, err)
+	}
+}
+
+func TestRecheckIgnoresOptionalCheck(t *testing.T) {
+	root, conn := setupKnowledgeEnv(t)
+	defer conn.Close()
+	svc := NewService(conn)
+	ctx := context.Background()
+	if err := os.WriteFile(filepath.Join(root, "extra.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("write extra.go: %v", err)
+	}
+	res, err := svc.ProposeAndVerifyDecision(ctx, ProposeDecisionInput{
+		Title: "Keep files", Reasoning: "r", EvidenceSummary: "e", Confidence: "high", ModuleRoot: root,
+		Checks:  []Check{{Type: "file_exists", Spec: `{"path":"go.mod"}`}, {Type: "file_exists", Spec: `{"path":"extra.go"}`}},
+		Combine: CombineAny,
+	})
+	if err != nil || !res.Promoted {
+		t.Fatalf("propose = %+v, %v", res, err)
+	}
+
+	if err := os.Remove(filepath.Join(root, "extra.go")); err != nil {
+		t.Fatalf("remove extra.go: %v", err)
+	}
+	result, err := svc.RecheckEvidence(ctx, root, []string{"extra.go"})
+	if err != nil {
+		t.Fatalf("RecheckEvidence: %v", err)
+	}
+	if len(result.Changed) != 1 || result.Changed[0].After != "broken" || result.Changed[0].Decayed {
+		t.Fatalf("changed = %+v", result.Changed)
+	}
+	if drifted, err := svc.DriftedEvidence(ctx); err != nil || len(drifted) != 0 {
+		t.Fatalf("DriftedEvidence = %+v, %v", drifted, err)
+	}
+}
@@ -0,0 +1,151 @@
+package knowledge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/config"
+	"github.com/robertguss/recon/internal/logging"
+)
+
+const (
+	defaultCommandTimeout = 2 * time.Minute
+	// maxCommandOutput caps the stdout a command_output check keeps.
+	maxCommandOutput = 1 << 20
+)
+
+// commandOutputSpec is the spec of a command_output check: Command is run
+// without a shell, split on whitespace, and its stdout must match Pattern.
+type commandOutputSpec struct {
+	Command        string `json:"command"`
+	Pattern        string `json:"pattern"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+}
+
+// cappedBuffer keeps the first max bytes written to it and drops the rest.
+type cappedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+var runCommand = func(ctx context.Context, dir string, argv []string) ([]byte, error) {
+	var stdout cappedBuffer
+	stdout.max = maxCommandOutput
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	// A child the command leaves behind must not keep the check waiting on
+	// its output after the timeout.
+	cmd.WaitDelay = time.Second
+	start := time.Now()
+	err := cmd.Run()
+	logging.Exec(ctx, argv[0], argv[1:], start, err)
+	return stdout.Bytes(), err
+}
+
+// allowedCommand reports whether argv is one of the commands allowed under
+// knowledge.allowed_commands in the module's config. Entries match word for
+// word, so allowing "go vet ./..." allows no other go command.
+func allowedCommand(moduleRoot string, argv []string) (bool, error) {
+	cfg, err := config.Load(moduleRoot)
+	if err != nil {
+		return false, err
+	}
+	for _, allowed := range cfg.Knowledge.AllowedCommands {
+		if slices.Equal(strings.Fields(allowed), argv) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// runCommandOutput runs an allow-listed command in the module root and passes
+// when it exits zero and its stdout matches the spec's pattern, if any.
+// command_output checks are opt-in: a command is refused unless the module's
+// config allows it.
+func (s *Service) runCommandOutput(ctx context.Context, specRaw, moduleRoot string) (runCheckOutcome, error) {
+	var spec commandOutputSpec
+	if err := json.Unmarshal([]byte(specRaw), &spec); err != nil {
+		return runCheckOutcome{}, fmt.Errorf("parse command_output check spec: %w", err)
+	}
+	argv := strings.Fields(spec.Command)
+	if len(argv) == 0 {
+		return runCheckOutcome{}, fmt.Errorf("command_output requires spec.command")
+	}
+	var pattern *regexp.Regexp
+	if spec.Pattern != "" {
+		re, err := regexp.Compile(spec.Pattern)
+		if err != nil {
+			return runCheckOutcome{}, fmt.Errorf("invalid command_output check spec: compile pattern: %w", err)
+		}
+		pattern = re
+	}
+	if spec.TimeoutSeconds < 0 {
+		return runCheckOutcome{}, fmt.Errorf("invalid command_output check spec: timeout_seconds must be >= 0")
+	}
+	timeout := defaultCommandTimeout
+	if spec.TimeoutSeconds > 0 {
+		timeout = time.Duration(spec.TimeoutSeconds) * time.Second
+	}
+	command := strings.Join(argv, " ")
+	ok, err := allowedCommand(moduleRoot, argv)
+	if err != nil {
+		return runCheckOutcome{}, fmt.Errorf("run %s: %w", command, err)
+	}
+	if !ok {
+		return runCheckOutcome{}, fmt.Errorf("command %q is not allowed; add it to knowledge.allowed_commands in .recon/config.json", command)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	out, err := runCommand(runCtx, moduleRoot, argv)
+
+	baseline := map[string]any{"command": command, "passed": false}
+	if spec.Pattern != "" {
+		baseline["pattern"] = spec.Pattern
+	}
+	switch {
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		baseline["timed_out"] = true
+		return runCheckOutcome{Details: fmt.Sprintf("%s timed out after %s", command, timeout), Baseline: baseline}, nil
+	case errors.Is(err, exec.ErrNotFound):
+		return runCheckOutcome{}, fmt.Errorf("run %s: %w", command, err)
+	case err != nil:
+		details := command + " failed"
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			details = fmt.Sprintf("%s exited with status %d", command, exitErr.ExitCode())
+		}
+		if summary := summarizeGoOutput(out, 3); summary != "" {
+			details += ": " + summary
+		}
+		return runCheckOutcome{Details: details, Baseline: baseline}, nil
+	case pattern != nil && !pattern.Match(out):
+		details := fmt.Sprintf("%s output does not match %q", command, spec.Pattern)
+		if summary := summarizeGoOutput(out, 3); summary != "" {
+			details += ": " + summary
+		}
+		return runCheckOutcome{Details: details, Baseline: baseline}, nil
+	}
+	baseline["passed"] = true
+	details := command + " passed"
+	if pattern != nil {
+		details = fmt.Sprintf("%s output matches %q", command, spec.Pattern)
+	}
+	return runCheckOutcome{Passed: true, Details: details, Baseline: baseline}, nil
+}
@@ -0,0 +1,80 @@
+package knowledge
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/config"
+)
+
+func writeAllowedCommands(t *testing.T, root string, commands string) {
+	t.Helper()
+	if err := os.WriteFile(config.Path(root), []byte(`{"knowledge":{"allowed_commands":`+commands+`}}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+}
+
+func TestRunCommandOutput(t *testing.T) {
+	root, conn := setupKnowledgeEnv(t)
+	defer conn.Close()
+	svc := NewService(conn)
+	ctx := context.Background()
+
+	spec := `{"command":"go version","pattern":"^go version go"}`
+	if _, err := svc.runCheck(ctx, ProposeDecisionInput{CheckType: "command_output", CheckSpec: spec, ModuleRoot: root}); err == nil || !strings.Contains(err.Error(), "knowledge.allowed_commands") {
+		t.Fatalf("expected command refused without an allow-list, got %v", err)
+	}
+
+	writeAllowedCommands(t, root, `["go  version", "go env GOBOGUSVAR", "go bogus"]`)
+	out, err := svc.runCheck(ctx, ProposeDecisionInput{CheckType: "command_output", CheckSpec: spec, ModuleRoot: root})
+	if err != nil || !out.Passed || out.Baseline["command"] != "go version" {
+		t.Fatalf("expected go version to pass, out=%+v err=%v", out, err)
+	}
+	out, err = svc.runCommandOutput(ctx, `{"command":"go env GOBOGUSVAR","pattern":"\\S"}`, root)
+	if err != nil || out.Passed || !strings.Contains(out.Details, "does not match") {
+		t.Fatalf("expected empty output to fail the pattern, out=%+v err=%v", out, err)
+	}
+	out, err = svc.runCommandOutput(ctx, `{"command":"go bogus"}`, root)
+	if err != nil || out.Passed || !strings.Contains(out.Details, "exited with status") {
+		t.Fatalf("expected a failing command to fail the check, out=%+v err=%v", out, err)
+	}
+	if _, err := svc.runCommandOutput(ctx, `{"command":"go version -m"}`, root); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("expected extra arguments to be refused, got %v", err)
+	}
+
+	for spec, want := range map[string]string{
+		`{`:                                      "parse command_output check spec",
+		`{"command":"  "}`:                       "requires spec.command",
+		`{"command":"go version","pattern":"("}`: "compile pattern",
+		`{"command":"go version","timeout_seconds":-1}`: "timeout_seconds",
+	} {
+		if _, err := svc.runCommandOutput(ctx, spec, root); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected error containing %q, got %v", spec, want, err)
+		}
+	}
+
+	orig := runCommand
+	defer func() { runCommand = orig }()
+	runCommand = func(ctx context.Context, _ string, _ []string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	out, err = svc.runCommandOutput(ctx, `{"command":"go version","timeout_seconds":1}`, root)
+	if err != nil || out.Passed || out.Baseline["timed_out"] != true {
+		t.Fatalf("expected a timed out command to fail, out=%+v err=%v", out, err)
+	}
+}
+
+func TestCappedBuffer(t *testing.T) {
+	b := cappedBuffer{max: 4}
+	for _, s := range []string{"ab", "cdef", "gh"} {
+		if n, err := b.Write([]byte(s)); n != len(s) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v", s, n, err)
+		}
+	}
+	if b.String() != "abcd" {
+		t.Fatalf("expected output capped to abcd, got %q", b.String())
+	}
+}
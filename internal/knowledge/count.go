@@ -0,0 +1,143 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// symbolCountSpec is the spec of a symbol_count check: the number of indexed
+// symbols matching Query must stay within Min and Max, either of which may be
+// left out.
+type symbolCountSpec struct {
+	Query string `json:"query"`
+	Min   *int   `json:"min"`
+	Max   *int   `json:"max"`
+}
+
+// symbolQueryFields maps the fields of a symbol_count query to the condition
+// each adds. kind, receiver, and exported compare exactly; name and file
+// are globs; package is a package path, or one ending in /... to include the
+// packages below it.
+var symbolQueryFields = map[string]func(value string) (string, []any, error){
+	"kind": func(v string) (string, []any, error) {
+		return "LOWER(s.kind) = ?", []any{strings.ToLower(v)}, nil
+	},
+	"name": func(v string) (string, []any, error) {
+		return "s.name GLOB ?", []any{v}, nil
+	},
+	"receiver": func(v string) (string, []any, error) {
+		return "COALESCE(s.receiver, '') = ?", []any{strings.TrimPrefix(v, "*")}, nil
+	},
+	"file": func(v string) (string, []any, error) {
+		return "f.path GLOB ?", []any{strings.TrimPrefix(v, "./")}, nil
+	},
+	"exported": func(v string) (string, []any, error) {
+		switch strings.ToLower(v) {
+		case "true", "1", "yes":
+			return "s.exported = 1", nil, nil
+		case "false", "0", "no":
+			return "s.exported = 0", nil, nil
+		}
+		return "", nil, fmt.Errorf("exported must be true or false, got %q", v)
+	},
+	"package": func(v string) (string, []any, error) {
+		v = strings.TrimSuffix(strings.TrimPrefix(v, "./"), "/")
+		if v == "..." {
+			return "1=1", nil, nil
+		}
+		if base, ok := strings.CutSuffix(v, "/..."); ok {
+			return "(p.path = ? OR p.path GLOB ?)", []any{base, base + "/*"}, nil
+		}
+		return "p.path = ?", []any{v}, nil
+	},
+}
+
+var queryAnd = regexp.MustCompile(`(?i)\s+AND\b\s*`)
+
+// parseSymbolQuery turns a query such as "kind=func AND package=internal/cli"
+// into a WHERE condition over symbols s, files f, and packages p. An empty
+// query matches every symbol.
+func parseSymbolQuery(query string) (string, []any, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "1=1", nil, nil
+	}
+	var (
+		clauses []string
+		args    []any
+	)
+	for _, term := range queryAnd.Split(query, -1) {
+		field, value, ok := strings.Cut(term, "=")
+		field = strings.ToLower(strings.TrimSpace(field))
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if !ok || value == "" {
+			return "", nil, fmt.Errorf("query term %q must be field=value", strings.TrimSpace(term))
+		}
+		condition, ok := symbolQueryFields[field]
+		if !ok {
+			return "", nil, fmt.Errorf("unknown query field %q; use kind, name, receiver, file, exported, or package", field)
+		}
+		clause, clauseArgs, err := condition(value)
+		if err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, clause)
+		args = append(args, clauseArgs...)
+	}
+	return strings.Join(clauses, " AND "), args, nil
+}
+
+// runSymbolCount counts the indexed symbols the spec's query matches and
+// passes while the count is within its bounds.
+func (s *Service) runSymbolCount(ctx context.Context, specRaw string) (runCheckOutcome, error) {
+	var spec symbolCountSpec
+	if err := json.Unmarshal([]byte(specRaw), &spec); err != nil {
+		return runCheckOutcome{}, fmt.Errorf("parse symbol_count check spec: %w", err)
+	}
+	if spec.Min == nil && spec.Max == nil {
+		return runCheckOutcome{}, fmt.Errorf("symbol_count requires spec.min or spec.max")
+	}
+	where, args, err := parseSymbolQuery(spec.Query)
+	if err != nil {
+		return runCheckOutcome{}, fmt.Errorf("invalid symbol_count check spec: %w", err)
+	}
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, `
+SELECT COUNT(*)
+FROM symbols s
+JOIN files f ON f.id = s.file_id
+LEFT JOIN packages p ON p.id = f.package_id
+WHERE `+where+`;
+`, args...).Scan(&count); err != nil {
+		return runCheckOutcome{}, fmt.Errorf("count symbols: %w", err)
+	}
+
+	var bounds []string
+	passed := true
+	if spec.Min != nil {
+		bounds = append(bounds, fmt.Sprintf("min %d", *spec.Min))
+		passed = passed && count >= *spec.Min
+	}
+	if spec.Max != nil {
+		bounds = append(bounds, fmt.Sprintf("max %d", *spec.Max))
+		passed = passed && count <= *spec.Max
+	}
+	// The count is kept as "symbols" rather than "count": a falling count
+	// is no drift for a check that caps it.
+	baseline := map[string]any{"query": spec.Query, "symbols": count}
+	if spec.Min != nil {
+		baseline["min"] = *spec.Min
+	}
+	if spec.Max != nil {
+		baseline["max"] = *spec.Max
+	}
+	return runCheckOutcome{
+		Passed:   passed,
+		Details:  fmt.Sprintf("%d symbols match %q (%s)", count, spec.Query, strings.Join(bounds, ", ")),
+		Baseline: baseline,
+	}, nil
+}
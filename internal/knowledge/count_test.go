@@ -0,0 +1,65 @@
+package knowledge
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRunSymbolCount(t *testing.T) {
+	_, conn := setupKnowledgeEnv(t)
+	defer conn.Close()
+	svc := NewService(conn)
+	ctx := context.Background()
+
+	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (2,'internal/cli','cli','example.com/recon/internal/cli',1,9,'x','x'), (3,'internal/cli/sub','sub','example.com/recon/internal/cli/sub',1,3,'x','x');`)
+	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (2,2,'internal/cli/root.go','go',9,'h','x','x'), (3,3,'internal/cli/sub/sub.go','go',3,'h','x','x');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES
+		(2,2,'func','NewRoot','func()',1,1,1,''),
+		(3,2,'func','newSub','func()',2,2,0,''),
+		(4,2,'method','Run','func()',3,3,1,'App'),
+		(5,3,'func','NewSub','func()',1,1,1,'');`)
+
+	cases := []struct {
+		spec   string
+		passed bool
+		count  int
+	}{
+		{`{"query":"kind=func AND package=internal/cli","max":2}`, true, 2},
+		{`{"query":"kind=func AND package=internal/cli","max":1}`, false, 2},
+		{`{"query":"kind=func and package=internal/cli/...","max":2}`, false, 3},
+		{`{"query":"exported=true AND name=New*","min":2}`, true, 2},
+		{`{"query":"receiver=*App","min":1,"max":1}`, true, 1},
+		{`{"query":"file=internal/cli/*.go AND exported=false","max":0}`, false, 1},
+		{`{"max":10}`, true, 5},
+	}
+	for _, tc := range cases {
+		out, err := svc.runCheck(ctx, ProposeDecisionInput{CheckType: "symbol_count", CheckSpec: tc.spec})
+		if err != nil {
+			t.Fatalf("%s: runCheck: %v", tc.spec, err)
+		}
+		if out.Passed != tc.passed || out.Baseline["symbols"] != tc.count {
+			t.Fatalf("%s: expected passed=%v with %d symbols, got %+v", tc.spec, tc.passed, tc.count, out)
+		}
+	}
+
+	for spec, want := range map[string]string{
+		`{`:                                  "parse symbol_count check spec",
+		`{"query":"kind=func"}`:              "requires spec.min or spec.max",
+		`{"query":"color=red","max":1}`:      "unknown query field",
+		`{"query":"kind","max":1}`:           "must be field=value",
+		`{"query":"exported=maybe","max":1}`: "exported must be true or false",
+		`{"query":"kind=func AND ","max":1}`: "must be field=value",
+	} {
+		if _, err := svc.runSymbolCount(ctx, spec); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected error containing %q, got %v", spec, want, err)
+		}
+	}
+}
+
+func TestSymbolCountFallingIsNotDrift(t *testing.T) {
+	outcome := runCheckOutcome{Passed: true, Baseline: map[string]any{"symbols": 3}}
+	if status := driftStatus(outcome, `{"query":"kind=func","symbols":5,"max":10}`); status != "ok" {
+		t.Fatalf("expected a falling symbol count within bounds to be ok, got %q", status)
+	}
+}
@@ -0,0 +1,387 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EvidenceChange is evidence whose drift status changed when it was
+// re-checked. Confidence is the entity's confidence after any decay.
+type EvidenceChange struct {
+	EntityType string `json:"entity_type"`
+	EntityID   int64  `json:"entity_id"`
+	Title      string `json:"title"`
+	CheckType  string `json:"check_type"`
+	Before     string `json:"before"`
+	After      string `json:"after"`
+	Details    string `json:"details"`
+	Confidence string `json:"confidence"`
+	Decayed    bool   `json:"decayed"`
+}
+
+// RecheckResult reports a RecheckEvidence run. Checked counts the evidence
+// rows whose check scope intersected the changed files.
+type RecheckResult struct {
+	Checked int              `json:"checked"`
+	Changed []EvidenceChange `json:"changed"`
+}
+
+// DriftedEvidence is evidence of an active decision, pattern, or constraint
+// whose last check left it drifting or broken. Details is what that check
+// reported.
+type DriftedEvidence struct {
+	EntityType string `json:"entity_type"`
+	EntityID   int64  `json:"entity_id"`
+	Title      string `json:"title"`
+	Summary    string `json:"summary"`
+	CheckType  string `json:"check_type"`
+	Drift      string `json:"drift_status"`
+	Details    string `json:"details,omitempty"`
+}
+
+// recheckTypes are the check types cheap enough to re-run after every sync.
+// Toolchain checks (go_build_passes, go_test_passes) and command_output are
+// left to explicit verification.
+var recheckTypes = []string{"file_exists", "file_absent", "symbol_exists", "symbol_count", "grep_pattern", "grep_absent", "not_grep_pattern", "import_absent"}
+
+type evidenceRow struct {
+	ID         int64
+	EntityType string
+	EntityID   int64
+	Title      string
+	Confidence string
+	CheckType  string
+	CheckSpec  string
+	Baseline   string
+	Drift      string
+	// LastVerifiedAt and Interval schedule the next re-check.
+	LastVerifiedAt string
+	Interval       time.Duration
+}
+
+// RecheckEvidence re-runs the evidence checks of active decisions, patterns,
+// and constraints whose scope intersects changed, a list of module-relative
+// paths that were added, modified, or removed. A failing check marks the
+// evidence broken; a passing check whose match count fell below its baseline
+// marks it drifting. Entities whose evidence moves from ok to drifting or broken lose
+// one confidence level (high to medium, medium to low); an entity backed by
+// several checks moves when its checks no longer hold under its combine mode.
+func (s *Service) RecheckEvidence(ctx context.Context, moduleRoot string, changed []string) (RecheckResult, error) {
+	if len(changed) == 0 {
+		return RecheckResult{Changed: []EvidenceChange{}}, nil
+	}
+	return s.recheck(ctx, moduleRoot, recheckTypes, func(r evidenceRow) bool {
+		return checkScopeIntersects(r.CheckType, r.CheckSpec, moduleRoot, changed)
+	})
+}
+
+// VerifyEvidence re-runs the evidence checks of every active decision,
+// pattern, and constraint regardless of what changed, with the drift and
+// confidence decay rules of RecheckEvidence. Toolchain checks are skipped as
+// they are after a sync.
+func (s *Service) VerifyEvidence(ctx context.Context, moduleRoot string) (RecheckResult, error) {
+	return s.recheck(ctx, moduleRoot, recheckTypes, func(evidenceRow) bool { return true })
+}
+
+// DriftedEvidence lists the drifting and broken evidence of active entities,
+// broken first.
+func (s *Service) DriftedEvidence(ctx context.Context) ([]DriftedEvidence, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT e.entity_type, e.entity_id, COALESCE(d.title, p.title, c.title), e.summary, COALESCE(e.check_type, ''),
+       e.drift_status, COALESCE(json_extract(e.last_result, '$.details'), '')
+FROM evidence e
+JOIN entity_evidence g ON g.entity_type = e.entity_type AND g.entity_id = e.entity_id
+LEFT JOIN decisions d ON e.entity_type = 'decision' AND d.id = e.entity_id
+LEFT JOIN patterns p ON e.entity_type = 'pattern' AND p.id = e.entity_id
+LEFT JOIN constraints c ON e.entity_type = 'constraint' AND c.id = e.entity_id
+WHERE COALESCE(d.status, p.status, c.status) = 'active'
+  AND e.drift_status IN ('drifting', 'broken')
+  AND g.drift_status != 'ok'
+ORDER BY e.drift_status = 'drifting', e.entity_type, e.entity_id, e.id;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query drifted evidence: %w", err)
+	}
+	defer rows.Close()
+	out := []DriftedEvidence{}
+	for rows.Next() {
+		var d DriftedEvidence
+		if err := rows.Scan(&d.EntityType, &d.EntityID, &d.Title, &d.Summary, &d.CheckType, &d.Drift, &d.Details); err != nil {
+			return nil, fmt.Errorf("scan drifted evidence: %w", err)
+		}
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate drifted evidence: %w", err)
+	}
+	return out, nil
+}
+
+// loadEvidence returns the evidence of active entities whose check type is
+// one of types.
+func (s *Service) loadEvidence(ctx context.Context, types []string) ([]evidenceRow, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(types)), ", ")
+	args := make([]any, 0, len(types))
+	for _, t := range types {
+		args = append(args, t)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+SELECT e.id, e.entity_type, e.entity_id, COALESCE(d.title, p.title, c.title), COALESCE(d.confidence, p.confidence, c.confidence),
+       e.check_type, COALESCE(e.check_spec, ''), COALESCE(e.baseline, ''), COALESCE(e.drift_status, 'ok'),
+       COALESCE(e.last_verified_at, ''), e.verify_interval
+FROM evidence e
+LEFT JOIN decisions d ON e.entity_type = 'decision' AND d.id = e.entity_id
+LEFT JOIN patterns p ON e.entity_type = 'pattern' AND p.id = e.entity_id
+LEFT JOIN constraints c ON e.entity_type = 'constraint' AND c.id = e.entity_id
+WHERE COALESCE(d.status, p.status, c.status) = 'active'
+  AND e.check_type IN (`+placeholders+`)
+ORDER BY e.id;
+`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query evidence for recheck: %w", err)
+	}
+	defer rows.Close()
+	var out []evidenceRow
+	for rows.Next() {
+		var (
+			r        evidenceRow
+			interval int64
+		)
+		if err := rows.Scan(&r.ID, &r.EntityType, &r.EntityID, &r.Title, &r.Confidence, &r.CheckType, &r.CheckSpec, &r.Baseline, &r.Drift, &r.LastVerifiedAt, &interval); err != nil {
+			return nil, fmt.Errorf("scan evidence for recheck: %w", err)
+		}
+		r.Interval = time.Duration(interval) * time.Second
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate evidence for recheck: %w", err)
+	}
+	return out, nil
+}
+
+// recheck re-runs the evidence checks of the given types that selected keeps
+// and records their drift status.
+func (s *Service) recheck(ctx context.Context, moduleRoot string, types []string, selected func(evidenceRow) bool) (RecheckResult, error) {
+	result := RecheckResult{Changed: []EvidenceChange{}}
+	rows, err := s.loadEvidence(ctx, types)
+	if err != nil {
+		return RecheckResult{}, err
+	}
+	var candidates []evidenceRow
+	for _, r := range rows {
+		if selected(r) {
+			candidates = append(candidates, r)
+		}
+	}
+	if len(candidates) == 0 {
+		return result, nil
+	}
+
+	// Checks read the files and the symbols table, so they run before the
+	// write transaction takes the only connection.
+	type verdict struct {
+		row     evidenceRow
+		outcome runCheckOutcome
+		status  string
+	}
+	verdicts := make([]verdict, 0, len(candidates))
+	for _, r := range candidates {
+		start := time.Now()
+		outcome, err := s.runCheck(ctx, ProposeDecisionInput{CheckType: r.CheckType, CheckSpec: r.CheckSpec, ModuleRoot: moduleRoot})
+		if err != nil {
+			outcome = runCheckOutcome{Passed: false, Details: err.Error()}
+		}
+		status := driftStatus(outcome, r.Baseline)
+		slog.DebugContext(ctx, "evidence check", "entity", fmt.Sprintf("%s:%d", r.EntityType, r.EntityID),
+			"check", r.CheckType, "status", status, "duration", time.Since(start))
+		verdicts = append(verdicts, verdict{row: r, outcome: outcome, status: status})
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return RecheckResult{}, fmt.Errorf("begin recheck tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// An entity backed by several checks drifts as a whole, by the rule its
+	// combine mode sets, so its status is read before and after the updates.
+	before := map[string]string{}
+	for _, v := range verdicts {
+		key := fmt.Sprintf("%s:%d", v.row.EntityType, v.row.EntityID)
+		if _, ok := before[key]; ok {
+			continue
+		}
+		if before[key], err = entityDrift(ctx, tx, v.row.EntityType, v.row.EntityID); err != nil {
+			return RecheckResult{}, err
+		}
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	firstChange := map[string]int{}
+	for _, v := range verdicts {
+		lastResultJSON, err := marshalJSON(map[string]any{
+			"passed":  v.outcome.Passed,
+			"details": v.outcome.Details,
+		})
+		if err != nil {
+			return RecheckResult{}, fmt.Errorf("marshal check result: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+UPDATE evidence SET last_verified_at = ?, last_result = ?, drift_status = ? WHERE id = ?;
+`, now, string(lastResultJSON), v.status, v.row.ID); err != nil {
+			return RecheckResult{}, fmt.Errorf("update evidence drift: %w", err)
+		}
+		result.Checked++
+		if v.status == v.row.Drift {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%d", v.row.EntityType, v.row.EntityID)
+		if _, ok := firstChange[key]; !ok {
+			firstChange[key] = len(result.Changed)
+		}
+		result.Changed = append(result.Changed, EvidenceChange{
+			EntityType: v.row.EntityType,
+			EntityID:   v.row.EntityID,
+			Title:      v.row.Title,
+			CheckType:  v.row.CheckType,
+			Before:     v.row.Drift,
+			After:      v.status,
+			Details:    v.outcome.Details,
+			Confidence: v.row.Confidence,
+		})
+	}
+
+	for key, i := range firstChange {
+		change := &result.Changed[i]
+		if before[key] != "ok" {
+			continue
+		}
+		after, err := entityDrift(ctx, tx, change.EntityType, change.EntityID)
+		if err != nil {
+			return RecheckResult{}, err
+		}
+		if after == "ok" {
+			continue
+		}
+		if lowered, ok := decayConfidence(change.Confidence); ok {
+			table := entityTables[change.EntityType]
+			if _, err := tx.ExecContext(ctx, `UPDATE `+table+` SET confidence = ?, updated_at = ? WHERE id = ?;`, lowered, now, change.EntityID); err != nil {
+				return RecheckResult{}, fmt.Errorf("decay confidence: %w", err)
+			}
+			change.Confidence, change.Decayed = lowered, true
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return RecheckResult{}, fmt.Errorf("commit recheck tx: %w", err)
+	}
+	return result, nil
+}
+
+// checkScopeIntersects reports whether a change to any of the changed paths
+// can affect the outcome of a check. Specs that fail to parse intersect so
+// that the re-run reports them broken.
+func checkScopeIntersects(checkType, specRaw, moduleRoot string, changed []string) bool {
+	var spec struct {
+		Path  string `json:"path"`
+		Scope string `json:"scope"`
+	}
+	if err := json.Unmarshal([]byte(specRaw), &spec); err != nil {
+		return true
+	}
+
+	switch checkType {
+	case "file_exists", "file_absent":
+		target := spec.Path
+		if filepath.IsAbs(target) {
+			rel, err := filepath.Rel(moduleRoot, target)
+			if err != nil {
+				return false
+			}
+			target = rel
+		}
+		target = path.Clean(filepath.ToSlash(target))
+		for _, p := range changed {
+			if p == target {
+				return true
+			}
+		}
+	case "grep_pattern", "grep_absent", "not_grep_pattern":
+		for _, p := range changed {
+			if spec.Scope == "" {
+				if strings.HasSuffix(p, ".go") {
+					return true
+				}
+				continue
+			}
+			baseMatch, _ := filepath.Match(spec.Scope, path.Base(p))
+			relMatch, _ := filepath.Match(spec.Scope, p)
+			if baseMatch || relMatch {
+				return true
+			}
+		}
+	case "symbol_exists", "symbol_count", "import_absent":
+		for _, p := range changed {
+			if strings.HasSuffix(p, ".go") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// driftStatus classifies a re-run against the baseline recorded when the
+// evidence was verified: broken when the check fails, drifting when it
+// passes with fewer matches (grep_pattern) or symbols (symbol_exists) than
+// before, ok otherwise.
+func driftStatus(outcome runCheckOutcome, baselineJSON string) string {
+	if !outcome.Passed {
+		return "broken"
+	}
+	var baseline map[string]any
+	if err := json.Unmarshal([]byte(baselineJSON), &baseline); err != nil {
+		return "ok"
+	}
+	for _, key := range []string{"matched", "count"} {
+		before, okBefore := baseline[key].(float64)
+		after, okAfter := toFloat(outcome.Baseline[key])
+		if okBefore && okAfter && after < before {
+			return "drifting"
+		}
+	}
+	return "ok"
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// entityTables maps an evidence entity_type to the table holding the entity.
+var entityTables = map[string]string{
+	"decision":   "decisions",
+	"pattern":    "patterns",
+	"constraint": "constraints",
+}
+
+func decayConfidence(confidence string) (string, bool) {
+	switch confidence {
+	case "high":
+		return "medium", true
+	case "medium":
+		return "low", true
+	}
+	return confidence, false
+}
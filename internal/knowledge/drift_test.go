@@ -0,0 +1,265 @@
+package knowledge
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecheckEvidence(t *testing.T) {
+	root, conn := setupKnowledgeEnv(t)
+	defer conn.Close()
+	ctx := context.Background()
+	svc := NewService(conn)
+
+	for name, src := range map[string]string{
+		"main.go": "package main\n// TODO one\nfunc Hello(){}\n",
+		"util.go": "package main\n// TODO two\n",
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(src), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	propose := func(title, checkType, spec, confidence string) int64 {
+		t.Helper()
+		res, err := svc.ProposeAndVerifyDecision(ctx, ProposeDecisionInput{
+			Title: title, Reasoning: "r", EvidenceSummary: "e", CheckType: checkType, CheckSpec: spec,
+			Confidence: confidence, ModuleRoot: root,
+		})
+		if err != nil || !res.Promoted {
+			t.Fatalf("propose %s: %+v, %v", title, res, err)
+		}
+		return res.DecisionID
+	}
+	utilID := propose("Keep util", "file_exists", `{"path":"util.go"}`, "high")
+	todoID := propose("TODO markers", "grep_pattern", `{"pattern":"TODO","scope":"*.go"}`, "medium")
+	helloID := propose("Hello exists", "symbol_exists", `{"name":"Hello"}`, "low")
+	modID := propose("Has go.mod", "file_exists", `{"path":"go.mod"}`, "high")
+
+	// Nothing changed, nothing checked.
+	res, err := svc.RecheckEvidence(ctx, root, nil)
+	if err != nil || res.Checked != 0 || len(res.Changed) != 0 {
+		t.Fatalf("empty recheck = %+v, %v", res, err)
+	}
+
+	// Delete util.go, dropping one TODO, and remove Hello from the index.
+	if err := os.Remove(filepath.Join(root, "util.go")); err != nil {
+		t.Fatalf("remove util.go: %v", err)
+	}
+	if _, err := conn.Exec(`DELETE FROM symbols WHERE name = 'Hello'`); err != nil {
+		t.Fatalf("delete symbol: %v", err)
+	}
+
+	res, err = svc.RecheckEvidence(ctx, root, []string{"main.go", "util.go"})
+	if err != nil {
+		t.Fatalf("RecheckEvidence: %v", err)
+	}
+	if res.Checked != 3 {
+		t.Fatalf("checked = %d, want 3 (go.mod is out of scope)", res.Checked)
+	}
+	var got []string
+	for _, c := range res.Changed {
+		got = append(got, strings.Join([]string{c.Title, c.Before, c.After, c.Confidence}, "|"))
+	}
+	want := "Keep util|ok|broken|medium, TODO markers|ok|drifting|low, Hello exists|ok|broken|low"
+	if strings.Join(got, ", ") != want {
+		t.Fatalf("changes = %s, want %s", strings.Join(got, ", "), want)
+	}
+	if res.Changed[2].Decayed {
+		t.Fatalf("low confidence should not decay: %+v", res.Changed[2])
+	}
+
+	status := func(id int64) (drift, confidence string) {
+		t.Helper()
+		if err := conn.QueryRow(`
+SELECT e.drift_status, d.confidence FROM evidence e JOIN decisions d ON d.id = e.entity_id
+WHERE e.entity_type = 'decision' AND e.entity_id = ?`, id).Scan(&drift, &confidence); err != nil {
+			t.Fatalf("query decision %d: %v", id, err)
+		}
+		return drift, confidence
+	}
+	if drift, confidence := status(utilID); drift != "broken" || confidence != "medium" {
+		t.Fatalf("util decision = %s/%s", drift, confidence)
+	}
+	if drift, confidence := status(modID); drift != "ok" || confidence != "high" {
+		t.Fatalf("go.mod decision = %s/%s", drift, confidence)
+	}
+
+	// A second recheck keeps the status without decaying again; restoring
+	// the file recovers the evidence but not the confidence.
+	if err := os.WriteFile(filepath.Join(root, "util.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("restore util.go: %v", err)
+	}
+	res, err = svc.RecheckEvidence(ctx, root, []string{"main.go", "util.go"})
+	if err != nil {
+		t.Fatalf("second RecheckEvidence: %v", err)
+	}
+	if len(res.Changed) != 1 || res.Changed[0].Title != "Keep util" || res.Changed[0].After != "ok" || res.Changed[0].Decayed {
+		t.Fatalf("second recheck changes = %+v", res.Changed)
+	}
+	if drift, confidence := status(todoID); drift != "drifting" || confidence != "low" {
+		t.Fatalf("todo decision = %s/%s", drift, confidence)
+	}
+	if drift, _ := status(helloID); drift != "broken" {
+		t.Fatalf("hello decision = %s", drift)
+	}
+
+	// Archived decisions are not rechecked; the grep and symbol checks still are.
+	if err := svc.ArchiveDecision(ctx, utilID); err != nil {
+		t.Fatalf("ArchiveDecision: %v", err)
+	}
+	if res, err := svc.RecheckEvidence(ctx, root, []string{"util.go"}); err != nil || res.Checked != 2 {
+		t.Fatalf("recheck after archive = %+v, %v", res, err)
+	}
+
+	if _, err := conn.Exec(`DROP TABLE evidence`); err != nil {
+		t.Fatalf("drop evidence: %v", err)
+	}
+	if _, err := svc.RecheckEvidence(ctx, root, []string{"main.go"}); err == nil || !strings.Contains(err.Error(), "query evidence for recheck") {
+		t.Fatalf("expected query error, got %v", err)
+	}
+}
+
+func TestCheckScopeIntersects(t *testing.T) {
+	changed := []string{"internal/cli/root.go", "go.mod"}
+	for _, tc := range []struct {
+		checkType, spec string
+		want            bool
+	}{
+		{"file_exists", `{"path":"go.mod"}`, true},
+		{"file_exists", `{"path":"./internal/cli/root.go"}`, true},
+		{"file_exists", `{"path":"/repo/go.mod"}`, true},
+		{"file_exists", `{"path":"README.md"}`, false},
+		{"grep_pattern", `{"pattern":"x"}`, true},
+		{"grep_pattern", `{"pattern":"x","scope":"go.mod"}`, true},
+		{"grep_pattern", `{"pattern":"x","scope":"internal/cli/*.go"}`, true},
+		{"grep_pattern", `{"pattern":"x","scope":"*.md"}`, false},
+		{"symbol_exists", `{"name":"Run"}`, true},
+		{"symbol_exists", `not json`, true},
+		{"go_build_passes", `{}`, false},
+	} {
+		if got := checkScopeIntersects(tc.checkType, tc.spec, "/repo", changed); got != tc.want {
+			t.Fatalf("checkScopeIntersects(%s, %s) = %t, want %t", tc.checkType, tc.spec, got, tc.want)
+		}
+	}
+	if checkScopeIntersects("symbol_exists", `{"name":"Run"}`, "/repo", []string{"go.mod"}) {
+		t.Fatal("symbol_exists should ignore non-Go changes")
+	}
+}
+
+func TestRecheckEvidenceBreaksViolatedConstraint(t *testing.T) {
+	root, conn := setupKnowledgeEnv(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	outcome := NewService(conn).RunCheckPublic(ctx, "grep_absent", `{"pattern":"internal/db","scope":"*.go"}`, root)
+	if !outcome.Passed || outcome.Baseline["matched"] != 0 {
+		t.Fatalf("grep_absent on clean tree = %+v", outcome)
+	}
+	if _, err := conn.Exec(`
+INSERT INTO constraints(id,title,reasoning,confidence,status,created_at,updated_at) VALUES (1,'No db in main','r','high','active','x','x');
+INSERT INTO evidence(entity_type,entity_id,summary,check_type,check_spec,baseline,drift_status)
+VALUES ('constraint',1,'main does not import db','grep_absent','{"pattern":"internal/db","scope":"*.go"}','{"matched":0,"total":1}','ok');`); err != nil {
+		t.Fatalf("seed constraint: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\nimport _ \"example.com/recon/internal/db\"\n"), 0o644); err != nil {
+		t.Fatalf("write main.go: %v", err)
+	}
+	res, err := NewService(conn).RecheckEvidence(ctx, root, []string{"main.go"})
+	if err != nil {
+		t.Fatalf("RecheckEvidence: %v", err)
+	}
+	if len(res.Changed) != 1 {
+		t.Fatalf("changes = %+v", res.Changed)
+	}
+	c := res.Changed[0]
+	if c.EntityType != "constraint" || c.Title != "No db in main" || c.After != "broken" || !c.Decayed || c.Confidence != "medium" ||
+		!strings.Contains(c.Details, "forbidden pattern matched 1 of 1 files") {
+		t.Fatalf("change = %+v", c)
+	}
+	var confidence string
+	if err := conn.QueryRow(`SELECT confidence FROM constraints WHERE id = 1`).Scan(&confidence); err != nil || confidence != "medium" {
+		t.Fatalf("constraint confidence = %q, %v", confidence, err)
+	}
+}
+
+func TestRecheckEvidenceBreaksAbsenceDecisions(t *testing.T) {
+	root, conn := setupKnowledgeEnv(t)
+	defer conn.Close()
+	ctx := context.Background()
+	svc := NewService(conn)
+
+	for _, in := range []ProposeDecisionInput{
+		{Title: "No reflection in main", CheckType: "not_grep_pattern", CheckSpec: `{"pattern":"\\breflect\\b","scope":"*.go"}`},
+		{Title: "No vendor directory", CheckType: "file_absent", CheckSpec: `{"path":"vendor"}`},
+	} {
+		in.Reasoning, in.EvidenceSummary, in.Confidence, in.ModuleRoot = "r", "e", "high", root
+		res, err := svc.ProposeAndVerifyDecision(ctx, in)
+		if err != nil || !res.Promoted {
+			t.Fatalf("propose %s = %+v, %v", in.CheckType, res, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\nimport \"reflect\"\nvar _ = reflect.TypeOf\n"), 0o644); err != nil {
+		t.Fatalf("write main.go: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "vendor"), 0o755); err != nil {
+		t.Fatalf("mkdir vendor: %v", err)
+	}
+	res, err := svc.RecheckEvidence(ctx, root, []string{"main.go", "vendor"})
+	if err != nil {
+		t.Fatalf("RecheckEvidence: %v", err)
+	}
+	if len(res.Changed) != 2 {
+		t.Fatalf("changes = %+v", res.Changed)
+	}
+	for _, c := range res.Changed {
+		if c.After != "broken" || !c.Decayed {
+			t.Fatalf("change = %+v", c)
+		}
+	}
+	if c := res.Changed[0]; c.CheckType != "not_grep_pattern" || !strings.Contains(c.Details, "forbidden pattern matched 1 of 1 files") {
+		t.Fatalf("not_grep_pattern change = %+v", c)
+	}
+	if c := res.Changed[1]; c.CheckType != "file_absent" || c.Details != "file vendor exists=true" {
+		t.Fatalf("file_absent change = %+v", c)
+	}
+}
+
+func TestVerifyEvidenceChecksEverything(t *testing.T) {
+	root, conn := setupKnowledgeEnv(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	if _, err := conn.Exec(`
+INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES (1,'Keep a README','r','high','active','x','x');
+INSERT INTO evidence(entity_type,entity_id,summary,check_type,check_spec,drift_status)
+VALUES ('decision',1,'README exists','file_exists','{"path":"README.md"}','ok');`); err != nil {
+		t.Fatalf("seed decision: %v", err)
+	}
+
+	// No changed path names README.md, so a scoped recheck skips it.
+	res, err := NewService(conn).RecheckEvidence(ctx, root, []string{"main.go"})
+	if err != nil || res.Checked != 0 {
+		t.Fatalf("RecheckEvidence = %+v, %v", res, err)
+	}
+	res, err = NewService(conn).VerifyEvidence(ctx, root)
+	if err != nil {
+		t.Fatalf("VerifyEvidence: %v", err)
+	}
+	if res.Checked != 1 || len(res.Changed) != 1 || res.Changed[0].After != "broken" || !res.Changed[0].Decayed {
+		t.Fatalf("VerifyEvidence = %+v", res)
+	}
+
+	drifted, err := NewService(conn).DriftedEvidence(ctx)
+	if err != nil {
+		t.Fatalf("DriftedEvidence: %v", err)
+	}
+	if len(drifted) != 1 || drifted[0].Title != "Keep a README" || drifted[0].Drift != "broken" ||
+		drifted[0].Summary != "README exists" || drifted[0].Details == "" {
+		t.Fatalf("DriftedEvidence = %+v", drifted)
+	}
+}
@@ -0,0 +1,56 @@
+package knowledge
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrEvidenceTooWeak is returned when a proposal's check type does not meet
+// the evidence policy for its confidence.
+var ErrEvidenceTooWeak = fmt.Errorf("evidence too weak for confidence")
+
+// EvidencePolicy is the project's bar for new decisions and patterns, read
+// from the knowledge section of .recon/config.json. DefaultConfidence applies
+// when a proposal names none. RequiredChecks maps a confidence level to the
+// check types strong enough to back it; levels without an entry accept any
+// check type.
+type EvidencePolicy struct {
+	DefaultConfidence string
+	RequiredChecks    map[string][]string
+}
+
+// Resolve returns the confidence a proposal is recorded with, falling back to
+// the policy default and then to medium, and rejects a check type the policy
+// does not accept at that confidence.
+func (p EvidencePolicy) Resolve(confidence, checkType string) (string, error) {
+	return p.ResolveChecks(confidence, CombineAll, []string{checkType})
+}
+
+// ResolveChecks is Resolve for a proposal backed by several checks. When all
+// of them must pass, one accepted check type is enough; when any may, each
+// must be accepted, since any one alone could keep the proposal standing.
+func (p EvidencePolicy) ResolveChecks(confidence, combine string, checkTypes []string) (string, error) {
+	confidence = strings.TrimSpace(confidence)
+	if confidence == "" {
+		confidence = p.DefaultConfidence
+	}
+	if confidence == "" {
+		confidence = "medium"
+	}
+	allowed, ok := p.RequiredChecks[confidence]
+	if !ok {
+		return confidence, nil
+	}
+	var weak []string
+	for _, checkType := range checkTypes {
+		if !slices.Contains(allowed, checkType) {
+			weak = append(weak, checkType)
+		}
+	}
+	if len(weak) == 0 || (combine != CombineAny && len(weak) < len(checkTypes)) {
+		return confidence, nil
+	}
+	return "", fmt.Errorf("%w: %s confidence requires a %s check, got %s",
+		ErrEvidenceTooWeak, confidence, strings.Join(allowed, " or "), strings.Join(weak, ", "))
+}
@@ -0,0 +1,80 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEvidencePolicyResolve(t *testing.T) {
+	policy := EvidencePolicy{
+		DefaultConfidence: "low",
+		RequiredChecks:    map[string][]string{"high": {"symbol_exists", "go_test_passes"}},
+	}
+	for _, tc := range []struct {
+		confidence, checkType, want string
+	}{
+		{"", "file_exists", "low"},
+		{" medium ", "file_exists", "medium"},
+		{"high", "symbol_exists", "high"},
+		{"high", "go_test_passes", "high"},
+	} {
+		got, err := policy.Resolve(tc.confidence, tc.checkType)
+		if err != nil || got != tc.want {
+			t.Fatalf("Resolve(%q, %q) = %q, %v; want %q", tc.confidence, tc.checkType, got, err, tc.want)
+		}
+	}
+
+	_, err := policy.Resolve("high", "file_exists")
+	if !errors.Is(err, ErrEvidenceTooWeak) || !strings.Contains(err.Error(), "high confidence requires a symbol_exists or go_test_passes check, got file_exists") {
+		t.Fatalf("expected weak evidence error, got %v", err)
+	}
+
+	if got, err := (EvidencePolicy{}).Resolve("", "file_exists"); err != nil || got != "medium" {
+		t.Fatalf("zero policy = %q, %v; want medium", got, err)
+	}
+}
+
+func TestProposeAndVerifyDecisionEnforcesPolicy(t *testing.T) {
+	root, conn := setupKnowledgeEnv(t)
+	defer conn.Close()
+	svc := NewService(conn)
+	in := ProposeDecisionInput{
+		Title: "Keep go.mod", Reasoning: "r", EvidenceSummary: "e", Confidence: "high",
+		CheckType: "file_exists", CheckSpec: `{"path":"go.mod"}`, ModuleRoot: root,
+		Policy: EvidencePolicy{RequiredChecks: map[string][]string{"high": {"symbol_exists"}}},
+	}
+	if _, err := svc.ProposeAndVerifyDecision(context.Background(), in); !errors.Is(err, ErrEvidenceTooWeak) {
+		t.Fatalf("expected ErrEvidenceTooWeak, got %v", err)
+	}
+	var proposals int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM proposals`).Scan(&proposals); err != nil || proposals != 0 {
+		t.Fatalf("rejected proposal was recorded: %d, %v", proposals, err)
+	}
+
+	in.Confidence = ""
+	in.Policy.DefaultConfidence = "low"
+	res, err := svc.ProposeAndVerifyDecision(context.Background(), in)
+	if err != nil || !res.Promoted {
+		t.Fatalf("propose with default confidence = %+v, %v", res, err)
+	}
+	var confidence string
+	if err := conn.QueryRow(`SELECT confidence FROM decisions WHERE id = ?`, res.DecisionID).Scan(&confidence); err != nil || confidence != "low" {
+		t.Fatalf("confidence = %q, %v; want low", confidence, err)
+	}
+}
+
+func TestEvidencePolicyResolveChecks(t *testing.T) {
+	policy := EvidencePolicy{RequiredChecks: map[string][]string{"high": {"symbol_exists"}}}
+	if got, err := policy.ResolveChecks("high", CombineAll, []string{"file_exists", "symbol_exists"}); err != nil || got != "high" {
+		t.Fatalf("all with one strong check = %q, %v", got, err)
+	}
+	_, err := policy.ResolveChecks("high", CombineAny, []string{"file_exists", "symbol_exists"})
+	if !errors.Is(err, ErrEvidenceTooWeak) || !strings.HasSuffix(err.Error(), "got file_exists") {
+		t.Fatalf("any with a weak check = %v", err)
+	}
+	if _, err := policy.ResolveChecks("high", CombineAll, []string{"file_exists", "grep_pattern"}); !errors.Is(err, ErrEvidenceTooWeak) {
+		t.Fatalf("all without a strong check = %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package knowledge
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Proposal is a decision, pattern, or constraint that was recorded but not
+// promoted because its evidence check failed. Details is the outcome of the
+// last check. The JSON names of the proposed fields match the keys of
+// proposals.entity_data.
+type Proposal struct {
+	ID              int64  `json:"id"`
+	EntityType      string `json:"entity_type"`
+	Title           string `json:"title"`
+	Reasoning       string `json:"reasoning,omitempty"`
+	Description     string `json:"description,omitempty"`
+	Confidence      string `json:"confidence"`
+	EvidenceSummary string `json:"evidence_summary"`
+	CheckType       string `json:"check_type"`
+	CheckSpec       string `json:"check_spec"`
+	// VerifyInterval is the evidence re-check interval in seconds the
+	// entity gets when promoted; 0 uses the check type default.
+	VerifyInterval int64 `json:"verify_interval,omitempty"`
+	// Checks and Combine are set when the entity is backed by several
+	// checks; CheckType and CheckSpec then describe the first.
+	Checks     []Check `json:"checks,omitempty"`
+	Combine    string  `json:"combine,omitempty"`
+	Status     string  `json:"status"`
+	ProposedAt string  `json:"proposed_at"`
+	VerifiedAt string  `json:"verified_at,omitempty"`
+	Details    string  `json:"details,omitempty"`
+	// Data is the raw entity_data, for the fields only one entity type has.
+	Data json.RawMessage `json:"-"`
+}
+
+const proposalColumns = `
+SELECT p.id, p.entity_type, p.entity_data, p.status, p.proposed_at, COALESCE(p.verified_at, ''),
+       COALESCE((SELECT json_extract(e.last_result, '$.details') FROM evidence e
+                 WHERE e.entity_type = 'proposal' AND e.entity_id = p.id ORDER BY e.id DESC LIMIT 1), '')
+FROM proposals p`
+
+// ListProposals returns the pending proposals, oldest first.
+func (s *Service) ListProposals(ctx context.Context) ([]Proposal, error) {
+	rows, err := s.db.QueryContext(ctx, proposalColumns+` WHERE p.status = 'pending' ORDER BY p.id;`)
+	if err != nil {
+		return nil, fmt.Errorf("query proposals: %w", err)
+	}
+	defer rows.Close()
+	out := []Proposal{}
+	for rows.Next() {
+		p, err := scanProposal(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate proposals: %w", err)
+	}
+	return out, nil
+}
+
+// PendingProposal returns one pending proposal, or ErrNotFound when it does
+// not exist or was already promoted or discarded.
+func (s *Service) PendingProposal(ctx context.Context, id int64) (Proposal, error) {
+	p, err := scanProposal(s.db.QueryRowContext(ctx, proposalColumns+` WHERE p.id = ? AND p.status = 'pending';`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Proposal{}, ErrNotFound
+	}
+	return p, err
+}
+
+func scanProposal(row interface{ Scan(...any) error }) (Proposal, error) {
+	var (
+		p    Proposal
+		data string
+	)
+	if err := row.Scan(&p.ID, &p.EntityType, &data, &p.Status, &p.ProposedAt, &p.VerifiedAt, &p.Details); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Proposal{}, err
+		}
+		return Proposal{}, fmt.Errorf("scan proposal: %w", err)
+	}
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		return Proposal{}, fmt.Errorf("decode proposal %d: %w", p.ID, err)
+	}
+	p.Data = json.RawMessage(data)
+	return p, nil
+}
+
+// DiscardProposal marks a pending proposal discarded and drops the evidence
+// of its failed checks.
+func (s *Service) DiscardProposal(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin discard tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE proposals SET status = 'discarded' WHERE id = ? AND status = 'pending';`, id)
+	if err != nil {
+		return fmt.Errorf("discard proposal: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM evidence WHERE entity_type = 'proposal' AND entity_id = ?;`, id); err != nil {
+		return fmt.Errorf("delete proposal evidence: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit discard tx: %w", err)
+	}
+	return nil
+}
+
+// RecordProposalCheck stores the outcome of a check that failed again for a
+// pending proposal.
+func (s *Service) RecordProposalCheck(ctx context.Context, id int64, outcome CheckOutcome) error {
+	rec, err := newCheckRecord(runCheckOutcome(outcome))
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin proposal check tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+UPDATE evidence SET summary = ?, baseline = ?, last_verified_at = ?, last_result = ?
+WHERE entity_type = 'proposal' AND entity_id = ?;
+`, "verification failed: "+outcome.Details, rec.Baseline, rec.VerifiedAt, rec.LastResult, id); err != nil {
+		return fmt.Errorf("update proposal evidence: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE proposals SET verified_at = ? WHERE id = ?;`, rec.VerifiedAt, id); err != nil {
+		return fmt.Errorf("update proposal: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit proposal check tx: %w", err)
+	}
+	return nil
+}
+
+// clearProposalEvidence drops the evidence of a proposal's failed checks once
+// it is promoted.
+func clearProposalEvidence(ctx context.Context, tx *sql.Tx, id int64) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM evidence WHERE entity_type = 'proposal' AND entity_id = ?;`, id); err != nil {
+		return fmt.Errorf("delete proposal evidence: %w", err)
+	}
+	return nil
+}
+
+// RetryDecisionProposal re-runs the checks of a pending decision proposal
+// against the current code and promotes the decision when they hold.
+func (s *Service) RetryDecisionProposal(ctx context.Context, id int64, moduleRoot string) (ProposeDecisionResult, error) {
+	p, err := s.PendingProposal(ctx, id)
+	if err != nil {
+		return ProposeDecisionResult{}, err
+	}
+	if p.EntityType != "decision" {
+		return ProposeDecisionResult{}, fmt.Errorf("proposal %d is a %s, not a decision", id, p.EntityType)
+	}
+	var data struct {
+		Branch string `json:"branch"`
+	}
+	if err := json.Unmarshal(p.Data, &data); err != nil {
+		return ProposeDecisionResult{}, fmt.Errorf("decode proposal %d: %w", id, err)
+	}
+	in := ProposeDecisionInput{
+		Title: p.Title, Reasoning: p.Reasoning, EvidenceSummary: p.EvidenceSummary,
+		CheckType: p.CheckType, CheckSpec: p.CheckSpec, ModuleRoot: moduleRoot, Branch: data.Branch,
+		VerifyInterval: time.Duration(p.VerifyInterval) * time.Second,
+		Checks:         p.Checks, Combine: p.Combine,
+	}
+	if in.Combine, err = normalizeCombine(in.Combine); err != nil {
+		return ProposeDecisionResult{}, fmt.Errorf("proposal %d: %w", id, err)
+	}
+
+	outcome, outcomes := s.runChecks(ctx, in.checks(), in.Combine, moduleRoot)
+	if !outcome.Passed {
+		if err := s.RecordProposalCheck(ctx, id, CheckOutcome(outcome)); err != nil {
+			return ProposeDecisionResult{}, err
+		}
+		return ProposeDecisionResult{ProposalID: id, VerificationDetails: outcome.Details}, nil
+	}
+
+	recs, err := newCheckRecords(outcomes)
+	if err != nil {
+		return ProposeDecisionResult{}, err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ProposeDecisionResult{}, fmt.Errorf("begin decision tx: %w", err)
+	}
+	defer tx.Rollback()
+	decisionID, err := promoteDecision(ctx, tx, id, in, p.Confidence, recs)
+	if err != nil {
+		return ProposeDecisionResult{}, err
+	}
+	if err := clearProposalEvidence(ctx, tx, id); err != nil {
+		return ProposeDecisionResult{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return ProposeDecisionResult{}, fmt.Errorf("commit decision tx: %w", err)
+	}
+	return ProposeDecisionResult{
+		ProposalID:          id,
+		DecisionID:          decisionID,
+		Branch:              in.Branch,
+		Promoted:            true,
+		VerificationPassed:  true,
+		VerificationDetails: outcome.Details,
+	}, nil
+}
@@ -0,0 +1,124 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Default intervals between scheduled re-checks of evidence whose
+// verify_interval is 0. Build, test, and command checks are slow, so they run
+// weekly.
+const (
+	DefaultVerifyInterval   = 24 * time.Hour
+	ToolchainVerifyInterval = 7 * 24 * time.Hour
+)
+
+// toolchainTypes are the check types that run the go toolchain or another
+// command.
+var toolchainTypes = []string{"go_build_passes", "go_test_passes", "command_output"}
+
+// VerifyIntervalFor returns how often evidence with the given check type and
+// stored interval is due for a re-check.
+func VerifyIntervalFor(checkType string, interval time.Duration) time.Duration {
+	switch {
+	case interval > 0:
+		return interval
+	case slices.Contains(toolchainTypes, checkType):
+		return ToolchainVerifyInterval
+	}
+	return DefaultVerifyInterval
+}
+
+// DueOptions selects the evidence VerifyDue re-checks.
+type DueOptions struct {
+	// Now is the time due dates are compared against; zero means time.Now.
+	Now time.Time
+	// SkipToolchain leaves due build and test checks for a later run, for
+	// callers such as session hooks that must return quickly.
+	SkipToolchain bool
+}
+
+// Schedule summarizes when evidence is next due. Due counts evidence whose
+// interval has elapsed; NextDueAt is the earliest due date still ahead.
+type Schedule struct {
+	Due       int    `json:"due"`
+	NextDueAt string `json:"next_due_at,omitempty"`
+}
+
+// VerifyDue re-runs the checks of active evidence whose verify interval has
+// elapsed since it was last verified, including build and test checks unless
+// opts.SkipToolchain is set, with the drift and confidence decay rules of
+// RecheckEvidence.
+func (s *Service) VerifyDue(ctx context.Context, moduleRoot string, opts DueOptions) (RecheckResult, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	types := recheckTypes
+	if !opts.SkipToolchain {
+		types = append(slices.Clone(recheckTypes), toolchainTypes...)
+	}
+	return s.recheck(ctx, moduleRoot, types, func(r evidenceRow) bool {
+		return !r.nextDue().After(now)
+	})
+}
+
+// VerifySchedule reports how much active evidence is due for a re-check at
+// now and when the next check falls due.
+func (s *Service) VerifySchedule(ctx context.Context, now time.Time) (Schedule, error) {
+	rows, err := s.loadEvidence(ctx, append(slices.Clone(recheckTypes), toolchainTypes...))
+	if err != nil {
+		return Schedule{}, err
+	}
+	var (
+		sched Schedule
+		next  time.Time
+	)
+	for _, r := range rows {
+		due := r.nextDue()
+		if !due.After(now) {
+			sched.Due++
+		} else if next.IsZero() || due.Before(next) {
+			next = due
+		}
+	}
+	if !next.IsZero() {
+		sched.NextDueAt = next.UTC().Format(time.RFC3339)
+	}
+	return sched, nil
+}
+
+// SetVerifyInterval sets the re-check interval of every piece of evidence
+// backing one entity and returns how many rows changed. Zero restores the
+// check type default.
+func (s *Service) SetVerifyInterval(ctx context.Context, entityType string, entityID int64, interval time.Duration) (int64, error) {
+	if _, ok := entityTables[entityType]; !ok {
+		return 0, fmt.Errorf("unsupported entity type %q; use decision, pattern, or constraint", entityType)
+	}
+	if interval < 0 {
+		return 0, fmt.Errorf("verify interval must not be negative")
+	}
+	res, err := s.db.ExecContext(ctx, `
+UPDATE evidence SET verify_interval = ? WHERE entity_type = ? AND entity_id = ?;
+`, int64(interval/time.Second), entityType, entityID)
+	if err != nil {
+		return 0, fmt.Errorf("update verify interval: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("read updated evidence count: %w", err)
+	}
+	return n, nil
+}
+
+// nextDue is when the evidence is next due for a re-check. Evidence never
+// verified, or with an unreadable timestamp, is due immediately.
+func (r evidenceRow) nextDue() time.Time {
+	last, err := time.Parse(time.RFC3339, r.LastVerifiedAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return last.Add(VerifyIntervalFor(r.CheckType, r.Interval))
+}
@@ -0,0 +1,87 @@
+package knowledge
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestVerifyIntervalFor(t *testing.T) {
+	for _, tc := range []struct {
+		checkType string
+		interval  time.Duration
+		want      time.Duration
+	}{
+		{"file_exists", 0, DefaultVerifyInterval},
+		{"go_test_passes", 0, ToolchainVerifyInterval},
+		{"go_build_passes", time.Hour, time.Hour},
+		{"grep_pattern", 90 * time.Minute, 90 * time.Minute},
+	} {
+		if got := VerifyIntervalFor(tc.checkType, tc.interval); got != tc.want {
+			t.Errorf("VerifyIntervalFor(%s, %s) = %s, want %s", tc.checkType, tc.interval, got, tc.want)
+		}
+	}
+}
+
+func TestVerifyDue(t *testing.T) {
+	root, conn := setupKnowledgeEnv(t)
+	defer conn.Close()
+	ctx := context.Background()
+	svc := NewService(conn)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	ago := func(d time.Duration) string { return now.Add(-d).Format(time.RFC3339) }
+	if _, err := conn.Exec(`
+INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+  (1,'Keep a README','r','high','active','x','x'),
+  (2,'Has go.mod','r','high','active','x','x'),
+  (3,'Builds','r','high','active','x','x'),
+  (4,'Hourly check','r','high','active','x','x');
+INSERT INTO evidence(entity_type,entity_id,summary,check_type,check_spec,drift_status,last_verified_at,verify_interval) VALUES
+  ('decision',1,'README exists','file_exists','{"path":"README.md"}','ok',?,0),
+  ('decision',2,'go.mod exists','file_exists','{"path":"go.mod"}','ok',?,0),
+  ('decision',3,'build passes','go_build_passes','{}','ok',?,0),
+  ('decision',4,'package main','grep_pattern','{"pattern":"package main","scope":"*.go"}','ok',?,3600);`,
+		ago(25*time.Hour), ago(time.Hour), ago(48*time.Hour), ago(2*time.Hour)); err != nil {
+		t.Fatalf("seed evidence: %v", err)
+	}
+
+	// README and the hourly check are due; go.mod is next, a day after its
+	// last check, and the build check waits a week.
+	sched, err := svc.VerifySchedule(ctx, now)
+	if err != nil {
+		t.Fatalf("VerifySchedule: %v", err)
+	}
+	if want := now.Add(23 * time.Hour).Format(time.RFC3339); sched.Due != 2 || sched.NextDueAt != want {
+		t.Fatalf("schedule = %+v, want 2 due, next at %s", sched, want)
+	}
+
+	res, err := svc.VerifyDue(ctx, root, DueOptions{Now: now})
+	if err != nil {
+		t.Fatalf("VerifyDue: %v", err)
+	}
+	if res.Checked != 2 || len(res.Changed) != 1 || res.Changed[0].Title != "Keep a README" || res.Changed[0].After != "broken" {
+		t.Fatalf("VerifyDue = %+v", res)
+	}
+
+	// Shortening the build check's interval makes it due, but the hook mode
+	// leaves it alone.
+	n, err := svc.SetVerifyInterval(ctx, "decision", 3, time.Hour)
+	if err != nil || n != 1 {
+		t.Fatalf("SetVerifyInterval = %d, %v", n, err)
+	}
+	if sched, err = svc.VerifySchedule(ctx, time.Now()); err != nil || sched.Due != 1 {
+		t.Fatalf("schedule after set = %+v, %v", sched, err)
+	}
+	res, err = svc.VerifyDue(ctx, root, DueOptions{SkipToolchain: true})
+	if err != nil || res.Checked != 0 {
+		t.Fatalf("VerifyDue skipping toolchain = %+v, %v", res, err)
+	}
+
+	if _, err := svc.SetVerifyInterval(ctx, "widget", 1, time.Hour); err == nil {
+		t.Fatal("expected unsupported entity type error")
+	}
+	if _, err := svc.SetVerifyInterval(ctx, "decision", 1, -time.Hour); err == nil {
+		t.Fatal("expected negative interval error")
+	}
+}
@@ -4,15 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/robertguss/recon/internal/archlint"
 	"github.com/robertguss/recon/internal/index"
+	"github.com/robertguss/recon/internal/logging"
+	"github.com/robertguss/recon/internal/recall"
 )
 
 var marshalJSON = json.Marshal
@@ -28,14 +33,39 @@ type ProposeDecisionInput struct {
 	CheckType       string
 	CheckSpec       string
 	ModuleRoot      string
+	Policy          EvidencePolicy
+	// Branch scopes the decision to one git branch; empty records it for
+	// every branch.
+	Branch string
+	// VerifyInterval is how often `recon verify --due` re-checks the
+	// evidence; zero uses the check type default.
+	VerifyInterval time.Duration
+	// Checks backs the decision with several evidence checks in place of
+	// CheckType and CheckSpec; Combine says whether all of them must pass
+	// (the default) or any.
+	Checks  []Check
+	Combine string
+}
+
+// checks returns the evidence checks of in: Checks, or the single check
+// CheckType and CheckSpec describe.
+func (in ProposeDecisionInput) checks() []Check {
+	if len(in.Checks) > 0 {
+		return in.Checks
+	}
+	return []Check{{Type: in.CheckType, Spec: in.CheckSpec}}
 }
 
 type ProposeDecisionResult struct {
 	ProposalID          int64  `json:"proposal_id"`
 	DecisionID          int64  `json:"decision_id,omitempty"`
+	Branch              string `json:"branch,omitempty"`
 	Promoted            bool   `json:"promoted"`
 	VerificationPassed  bool   `json:"verification_passed"`
 	VerificationDetails string `json:"verification_details"`
+	// Similar lists the active decisions that already resemble this one;
+	// recon decide fills it in before recording the decision.
+	Similar []recall.SimilarDecision `json:"similar,omitempty"`
 }
 
 type Service struct {
@@ -56,16 +86,25 @@ func (s *Service) ProposeAndVerifyDecision(ctx context.Context, in ProposeDecisi
 	if strings.TrimSpace(in.EvidenceSummary) == "" {
 		return ProposeDecisionResult{}, fmt.Errorf("evidence summary is required")
 	}
-	if strings.TrimSpace(in.CheckType) == "" {
-		return ProposeDecisionResult{}, fmt.Errorf("check type is required")
+	checks := in.checks()
+	for _, c := range checks {
+		if strings.TrimSpace(c.Type) == "" {
+			return ProposeDecisionResult{}, fmt.Errorf("check type is required")
+		}
+		if strings.TrimSpace(c.Spec) == "" {
+			return ProposeDecisionResult{}, fmt.Errorf("check spec is required")
+		}
 	}
-	if strings.TrimSpace(in.CheckSpec) == "" {
-		return ProposeDecisionResult{}, fmt.Errorf("check spec is required")
+	combine, err := normalizeCombine(in.Combine)
+	if err != nil {
+		return ProposeDecisionResult{}, err
 	}
+	in.Checks, in.Combine = checks, combine
+	in.CheckType, in.CheckSpec = checks[0].Type, checks[0].Spec
 
-	confidence := strings.TrimSpace(in.Confidence)
-	if confidence == "" {
-		confidence = "medium"
+	confidence, err := in.Policy.ResolveChecks(in.Confidence, combine, checkTypes(checks))
+	if err != nil {
+		return ProposeDecisionResult{}, err
 	}
 
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -77,16 +116,22 @@ func (s *Service) ProposeAndVerifyDecision(ctx context.Context, in ProposeDecisi
 		"check_type":       in.CheckType,
 		"check_spec":       in.CheckSpec,
 	}
+	if in.Branch != "" {
+		entityData["branch"] = in.Branch
+	}
+	if in.VerifyInterval > 0 {
+		entityData["verify_interval"] = int64(in.VerifyInterval / time.Second)
+	}
+	if len(checks) > 1 {
+		entityData["checks"] = checks
+		entityData["combine"] = combine
+	}
 	entityDataJSON, err := marshalJSON(entityData)
 	if err != nil {
 		return ProposeDecisionResult{}, fmt.Errorf("marshal proposal data: %w", err)
 	}
 
-	outcome := runCheckOutcome{Passed: false, Details: "unknown"}
-	outcome, err = s.runCheck(ctx, in)
-	if err != nil {
-		outcome = runCheckOutcome{Passed: false, Details: err.Error(), Baseline: map[string]any{"error": err.Error()}}
-	}
+	outcome, outcomes := s.runChecks(ctx, checks, combine, in.ModuleRoot)
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -106,61 +151,19 @@ VALUES (NULL, 'decision', ?, 'pending', ?);
 		return ProposeDecisionResult{}, fmt.Errorf("read proposal id: %w", err)
 	}
 
-	baselineJSON, err := marshalJSON(outcome.Baseline)
+	rec, err := newCheckRecord(outcome)
 	if err != nil {
-		return ProposeDecisionResult{}, fmt.Errorf("marshal baseline: %w", err)
-	}
-	lastResultJSON, err := marshalJSON(map[string]any{
-		"passed":  outcome.Passed,
-		"details": outcome.Details,
-	})
-	if err != nil {
-		return ProposeDecisionResult{}, fmt.Errorf("marshal check result: %w", err)
+		return ProposeDecisionResult{}, err
 	}
 
-	verifiedAt := time.Now().UTC().Format(time.RFC3339)
 	if outcome.Passed {
-		decisionRes, err := tx.ExecContext(ctx, `
-INSERT INTO decisions (title, reasoning, confidence, status, created_at, updated_at)
-VALUES (?, ?, ?, 'active', ?, ?);
-`, in.Title, in.Reasoning, confidence, verifiedAt, verifiedAt)
+		recs, err := newCheckRecords(outcomes)
 		if err != nil {
-			return ProposeDecisionResult{}, fmt.Errorf("insert decision: %w", err)
+			return ProposeDecisionResult{}, err
 		}
-		decisionID, err := decisionRes.LastInsertId()
+		decisionID, err := promoteDecision(ctx, tx, proposalID, in, confidence, recs)
 		if err != nil {
-			return ProposeDecisionResult{}, fmt.Errorf("read decision id: %w", err)
-		}
-
-		if _, err := tx.ExecContext(ctx, `
-INSERT INTO evidence (
-    entity_type,
-    entity_id,
-    summary,
-    check_type,
-    check_spec,
-    baseline,
-    last_verified_at,
-    last_result,
-    drift_status
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'ok');
-`, "decision", decisionID, in.EvidenceSummary, in.CheckType, in.CheckSpec, string(baselineJSON), verifiedAt, string(lastResultJSON)); err != nil {
-			return ProposeDecisionResult{}, fmt.Errorf("insert decision evidence: %w", err)
-		}
-
-		if _, err := tx.ExecContext(ctx, `
-UPDATE proposals
-SET status = 'promoted', verified_at = ?, promoted_at = ?
-WHERE id = ?;
-`, verifiedAt, verifiedAt, proposalID); err != nil {
-			return ProposeDecisionResult{}, fmt.Errorf("update proposal status to promoted: %w", err)
-		}
-
-		if _, err := tx.ExecContext(ctx, `
-INSERT INTO search_index (title, content, entity_type, entity_id)
-VALUES (?, ?, 'decision', ?);
-`, in.Title, in.Reasoning+"\n"+in.EvidenceSummary, decisionID); err != nil {
-			return ProposeDecisionResult{}, fmt.Errorf("insert search index: %w", err)
+			return ProposeDecisionResult{}, err
 		}
 
 		if err := tx.Commit(); err != nil {
@@ -170,6 +173,7 @@ VALUES (?, ?, 'decision', ?);
 		return ProposeDecisionResult{
 			ProposalID:          proposalID,
 			DecisionID:          decisionID,
+			Branch:              in.Branch,
 			Promoted:            true,
 			VerificationPassed:  true,
 			VerificationDetails: outcome.Details,
@@ -188,7 +192,7 @@ INSERT INTO evidence (
     last_result,
     drift_status
 ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'broken');
-`, "proposal", proposalID, "verification failed: "+outcome.Details, in.CheckType, in.CheckSpec, string(baselineJSON), verifiedAt, string(lastResultJSON)); err != nil {
+`, "proposal", proposalID, "verification failed: "+outcome.Details, in.CheckType, in.CheckSpec, rec.Baseline, rec.VerifiedAt, rec.LastResult); err != nil {
 		return ProposeDecisionResult{}, fmt.Errorf("insert proposal evidence: %w", err)
 	}
 
@@ -196,7 +200,7 @@ INSERT INTO evidence (
 UPDATE proposals
 SET status = 'pending', verified_at = ?
 WHERE id = ?;
-`, verifiedAt, proposalID); err != nil {
+`, rec.VerifiedAt, proposalID); err != nil {
 		return ProposeDecisionResult{}, fmt.Errorf("update proposal status to pending: %w", err)
 	}
 
@@ -212,6 +216,114 @@ WHERE id = ?;
 	}, nil
 }
 
+// checkRecord is a check outcome serialized for the evidence table.
+type checkRecord struct {
+	Baseline   string
+	LastResult string
+	VerifiedAt string
+	Passed     bool
+}
+
+// drift is the drift status the evidence row of the check starts with: a
+// check of a decision that needs only any of its checks may fail.
+func (r checkRecord) drift() string {
+	if r.Passed {
+		return "ok"
+	}
+	return "broken"
+}
+
+func newCheckRecord(outcome runCheckOutcome) (checkRecord, error) {
+	baselineJSON, err := marshalJSON(outcome.Baseline)
+	if err != nil {
+		return checkRecord{}, fmt.Errorf("marshal baseline: %w", err)
+	}
+	lastResultJSON, err := marshalJSON(map[string]any{
+		"passed":  outcome.Passed,
+		"details": outcome.Details,
+	})
+	if err != nil {
+		return checkRecord{}, fmt.Errorf("marshal check result: %w", err)
+	}
+	return checkRecord{
+		Baseline:   string(baselineJSON),
+		LastResult: string(lastResultJSON),
+		VerifiedAt: time.Now().UTC().Format(time.RFC3339),
+		Passed:     outcome.Passed,
+	}, nil
+}
+
+func newCheckRecords(outcomes []runCheckOutcome) ([]checkRecord, error) {
+	recs := make([]checkRecord, len(outcomes))
+	for i, outcome := range outcomes {
+		rec, err := newCheckRecord(outcome)
+		if err != nil {
+			return nil, err
+		}
+		recs[i] = rec
+	}
+	return recs, nil
+}
+
+// promoteDecision records the decision a proposal describes once its checks
+// passed, with an evidence row per check (recs holds their outcomes in the
+// same order) and its search entry, and marks the proposal promoted.
+func promoteDecision(ctx context.Context, tx *sql.Tx, proposalID int64, in ProposeDecisionInput, confidence string, recs []checkRecord) (int64, error) {
+	rec := recs[0]
+	decisionRes, err := tx.ExecContext(ctx, `
+INSERT INTO decisions (title, reasoning, confidence, status, created_at, updated_at, branch)
+VALUES (?, ?, ?, 'active', ?, ?, ?);
+`, in.Title, in.Reasoning, confidence, rec.VerifiedAt, rec.VerifiedAt, sql.NullString{String: in.Branch, Valid: in.Branch != ""})
+	if err != nil {
+		return 0, fmt.Errorf("insert decision: %w", err)
+	}
+	decisionID, err := decisionRes.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("read decision id: %w", err)
+	}
+
+	combine := in.Combine
+	if combine == "" {
+		combine = CombineAll
+	}
+	for i, c := range in.checks() {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO evidence (
+    entity_type,
+    entity_id,
+    summary,
+    check_type,
+    check_spec,
+    baseline,
+    last_verified_at,
+    last_result,
+    drift_status,
+    verify_interval,
+    combine
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+`, "decision", decisionID, in.EvidenceSummary, c.Type, c.Spec, recs[i].Baseline, recs[i].VerifiedAt, recs[i].LastResult,
+			recs[i].drift(), int64(in.VerifyInterval/time.Second), combine); err != nil {
+			return 0, fmt.Errorf("insert decision evidence: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+UPDATE proposals
+SET status = 'promoted', verified_at = ?, promoted_at = ?
+WHERE id = ?;
+`, rec.VerifiedAt, rec.VerifiedAt, proposalID); err != nil {
+		return 0, fmt.Errorf("update proposal status to promoted: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO search_index (title, content, entity_type, entity_id)
+VALUES (?, ?, 'decision', ?);
+`, in.Title, in.Reasoning+"\n"+in.EvidenceSummary, decisionID); err != nil {
+		return 0, fmt.Errorf("insert search index: %w", err)
+	}
+	return decisionID, nil
+}
+
 type DecisionListItem struct {
 	ID         int64  `json:"id"`
 	Title      string `json:"title"`
@@ -219,15 +331,16 @@ type DecisionListItem struct {
 	Status     string `json:"status"`
 	Drift      string `json:"drift_status"`
 	UpdatedAt  string `json:"updated_at"`
+	Branch     string `json:"branch,omitempty"`
 }
 
 func (s *Service) ListDecisions(ctx context.Context) ([]DecisionListItem, error) {
 	rows, err := s.db.QueryContext(ctx, `
-SELECT d.id, d.title, d.confidence, d.status, COALESCE(e.drift_status, 'ok'), d.updated_at
+SELECT d.id, d.title, d.confidence, d.status, COALESCE(e.drift_status, 'ok'), d.updated_at, COALESCE(d.branch, '')
 FROM decisions d
-LEFT JOIN evidence e ON e.entity_type = 'decision' AND e.entity_id = d.id
+LEFT JOIN entity_evidence e ON e.entity_type = 'decision' AND e.entity_id = d.id
 WHERE d.status = 'active'
-ORDER BY d.updated_at DESC;
+ORDER BY d.updated_at DESC, d.id DESC;
 `)
 	if err != nil {
 		return nil, fmt.Errorf("query decisions: %w", err)
@@ -236,7 +349,7 @@ ORDER BY d.updated_at DESC;
 	items := []DecisionListItem{}
 	for rows.Next() {
 		var item DecisionListItem
-		if err := rows.Scan(&item.ID, &item.Title, &item.Confidence, &item.Status, &item.Drift, &item.UpdatedAt); err != nil {
+		if err := rows.Scan(&item.ID, &item.Title, &item.Confidence, &item.Status, &item.Drift, &item.UpdatedAt, &item.Branch); err != nil {
 			return nil, fmt.Errorf("scan decision: %w", err)
 		}
 		items = append(items, item)
@@ -256,6 +369,121 @@ func (s *Service) ArchiveDecision(ctx context.Context, id int64) error {
 	return nil
 }
 
+// PromoteDecisionToMain clears the branch scope of the active decision id,
+// typically once its branch is merged, so it shows on every branch. It
+// returns the branch the decision was scoped to, or "" if it had none.
+func (s *Service) PromoteDecisionToMain(ctx context.Context, id int64) (string, error) {
+	var branch sql.NullString
+	err := s.db.QueryRowContext(ctx, `SELECT branch FROM decisions WHERE id = ? AND status = 'active';`, id).Scan(&branch)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("decision %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("read decision branch: %w", err)
+	}
+	if !branch.Valid {
+		return "", nil
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE decisions SET branch = NULL, updated_at = ? WHERE id = ?;`, time.Now().UTC().Format(time.RFC3339), id); err != nil {
+		return "", fmt.Errorf("promote decision to main: %w", err)
+	}
+	return branch.String, nil
+}
+
+// ArchiveImpact is the knowledge that loses its backing when a decision is
+// archived: edges from or to it, and active patterns linked to it by those
+// edges. RenderedIn is left for callers that know where instruction files
+// live.
+type ArchiveImpact struct {
+	DecisionID int64           `json:"decision_id"`
+	Title      string          `json:"title"`
+	Edges      []ImpactEdge    `json:"edges"`
+	Patterns   []ImpactPattern `json:"patterns"`
+	RenderedIn []string        `json:"rendered_in"`
+}
+
+type ImpactEdge struct {
+	ID       int64  `json:"id"`
+	FromType string `json:"from_type"`
+	FromID   int64  `json:"from_id"`
+	ToType   string `json:"to_type"`
+	ToRef    string `json:"to_ref"`
+	Relation string `json:"relation"`
+}
+
+type ImpactPattern struct {
+	ID       int64  `json:"id"`
+	Title    string `json:"title"`
+	Relation string `json:"relation"`
+}
+
+// Empty reports whether archiving affects nothing beyond the decision itself.
+func (a ArchiveImpact) Empty() bool {
+	return len(a.Edges) == 0 && len(a.Patterns) == 0 && len(a.RenderedIn) == 0
+}
+
+// DecisionArchiveImpact previews what archiving the active decision id takes
+// with it, without changing anything.
+func (s *Service) DecisionArchiveImpact(ctx context.Context, id int64) (ArchiveImpact, error) {
+	impact := ArchiveImpact{DecisionID: id, Edges: []ImpactEdge{}, Patterns: []ImpactPattern{}, RenderedIn: []string{}}
+	err := s.db.QueryRowContext(ctx, `SELECT title FROM decisions WHERE id = ? AND status = 'active';`, id).Scan(&impact.Title)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ArchiveImpact{}, fmt.Errorf("decision %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return ArchiveImpact{}, fmt.Errorf("query decision: %w", err)
+	}
+
+	ref := fmt.Sprintf("%d", id)
+	rows, err := s.db.QueryContext(ctx, `
+SELECT e.id, e.from_type, e.from_id, e.to_type, e.to_ref, e.relation
+FROM edges e
+WHERE (e.from_type = 'decision' AND e.from_id = ?) OR (e.to_type = 'decision' AND e.to_ref = ?)
+ORDER BY e.id;
+`, id, ref)
+	if err != nil {
+		return ArchiveImpact{}, fmt.Errorf("query decision edges: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e ImpactEdge
+		if err := rows.Scan(&e.ID, &e.FromType, &e.FromID, &e.ToType, &e.ToRef, &e.Relation); err != nil {
+			return ArchiveImpact{}, fmt.Errorf("scan decision edge: %w", err)
+		}
+		impact.Edges = append(impact.Edges, e)
+	}
+	if err := rows.Err(); err != nil {
+		return ArchiveImpact{}, fmt.Errorf("iterate decision edges: %w", err)
+	}
+	rows.Close()
+
+	rows, err = s.db.QueryContext(ctx, `
+SELECT p.id, p.title, MIN(e.relation)
+FROM edges e
+JOIN patterns p ON p.status = 'active' AND (
+    (e.from_type = 'pattern' AND e.from_id = p.id AND e.to_type = 'decision' AND e.to_ref = ?)
+ OR (e.from_type = 'decision' AND e.from_id = ? AND e.to_type = 'pattern' AND e.to_ref = CAST(p.id AS TEXT))
+)
+GROUP BY p.id, p.title
+ORDER BY p.id;
+`, ref, id)
+	if err != nil {
+		return ArchiveImpact{}, fmt.Errorf("query linked patterns: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p ImpactPattern
+		if err := rows.Scan(&p.ID, &p.Title, &p.Relation); err != nil {
+			return ArchiveImpact{}, fmt.Errorf("scan linked pattern: %w", err)
+		}
+		impact.Patterns = append(impact.Patterns, p)
+	}
+	if err := rows.Err(); err != nil {
+		return ArchiveImpact{}, fmt.Errorf("iterate linked patterns: %w", err)
+	}
+	return impact, nil
+}
+
 type UpdateDecisionInput struct {
 	Title     string
 	Reasoning string
@@ -292,23 +520,34 @@ func (s *Service) UpdateDecision(ctx context.Context, id int64, in UpdateDecisio
 		return fmt.Errorf("decision %d: %w", id, ErrNotFound)
 	}
 
+	return ReindexDecision(ctx, s.db, id)
+}
+
+// execQueryer is satisfied by both *sql.DB and *sql.Tx.
+type execQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// ReindexDecision refreshes the search_index entry of decision id from its
+// title, reasoning, and evidence summary.
+func ReindexDecision(ctx context.Context, q execQueryer, id int64) error {
 	var title, reasoning, evidenceSummary string
-	if err := s.db.QueryRowContext(ctx,
+	if err := q.QueryRowContext(ctx,
 		`SELECT d.title, d.reasoning, COALESCE(e.summary, '')
 		 FROM decisions d
-		 LEFT JOIN evidence e ON e.entity_type = 'decision' AND e.entity_id = d.id
+		 LEFT JOIN entity_evidence e ON e.entity_type = 'decision' AND e.entity_id = d.id
 		 WHERE d.id = ?`, id,
 	).Scan(&title, &reasoning, &evidenceSummary); err != nil {
 		return fmt.Errorf("read updated decision for reindex: %w", err)
 	}
 
-	if _, err := s.db.ExecContext(ctx,
+	if _, err := q.ExecContext(ctx,
 		`UPDATE search_index SET title = ?, content = ? WHERE entity_type = 'decision' AND entity_id = ?`,
 		title, reasoning+"\n"+evidenceSummary, id,
 	); err != nil {
 		return fmt.Errorf("reindex decision: %w", err)
 	}
-
 	return nil
 }
 
@@ -341,7 +580,7 @@ UPDATE decisions SET
 WHERE status = 'active'
   AND confidence IN ('high', 'medium')
   AND id IN (
-      SELECT entity_id FROM evidence
+      SELECT entity_id FROM entity_evidence
       WHERE entity_type = 'decision' AND drift_status IN ('drifting', 'broken')
   );
 `, now)
@@ -382,10 +621,22 @@ func (s *Service) runCheck(ctx context.Context, in ProposeDecisionInput) (runChe
 	switch in.CheckType {
 	case "file_exists":
 		return s.runFileExists(in.CheckSpec, in.ModuleRoot)
+	case "file_absent":
+		return s.runFileAbsent(in.CheckSpec, in.ModuleRoot)
 	case "symbol_exists":
 		return s.runSymbolExists(ctx, in.CheckSpec)
+	case "symbol_count":
+		return s.runSymbolCount(ctx, in.CheckSpec)
 	case "grep_pattern":
 		return s.runGrepPattern(in.CheckSpec, in.ModuleRoot)
+	case "grep_absent", "not_grep_pattern":
+		return s.runGrepAbsent(in.CheckType, in.CheckSpec, in.ModuleRoot)
+	case "import_absent":
+		return s.runImportAbsent(ctx, in.CheckSpec)
+	case "go_build_passes", "go_test_passes":
+		return s.runGoToolCheck(ctx, in.CheckType, in.CheckSpec, in.ModuleRoot)
+	case "command_output":
+		return s.runCommandOutput(ctx, in.CheckSpec, in.ModuleRoot)
 	default:
 		return runCheckOutcome{}, fmt.Errorf("unsupported check type %q", in.CheckType)
 	}
@@ -419,6 +670,36 @@ func (s *Service) runFileExists(specRaw string, moduleRoot string) (runCheckOutc
 	}, nil
 }
 
+// runFileAbsent is the inverse of runFileExists: it passes only while
+// nothing exists at spec.path.
+func (s *Service) runFileAbsent(specRaw string, moduleRoot string) (runCheckOutcome, error) {
+	var spec struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal([]byte(specRaw), &spec); err != nil {
+		return runCheckOutcome{}, fmt.Errorf("parse file_absent check spec: %w", err)
+	}
+	if strings.TrimSpace(spec.Path) == "" {
+		return runCheckOutcome{}, fmt.Errorf("file_absent requires spec.path")
+	}
+
+	target := spec.Path
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(moduleRoot, target)
+	}
+	_, err := os.Stat(target)
+	exists := err == nil
+
+	return runCheckOutcome{
+		Passed:  !exists,
+		Details: fmt.Sprintf("file %s exists=%v", spec.Path, exists),
+		Baseline: map[string]any{
+			"path":   spec.Path,
+			"exists": exists,
+		},
+	}, nil
+}
+
 func (s *Service) runSymbolExists(ctx context.Context, specRaw string) (runCheckOutcome, error) {
 	var spec struct {
 		Name string `json:"name"`
@@ -446,21 +727,102 @@ func (s *Service) runSymbolExists(ctx context.Context, specRaw string) (runCheck
 	}, nil
 }
 
-func (s *Service) runGrepPattern(specRaw string, moduleRoot string) (runCheckOutcome, error) {
+// runImportAbsent passes when no indexed, non-test package matching
+// spec.scope (every package when empty) imports a package matching
+// spec.import. Both are package patterns such as "cmd/..." or "internal/db".
+func (s *Service) runImportAbsent(ctx context.Context, specRaw string) (runCheckOutcome, error) {
 	var spec struct {
-		Pattern string `json:"pattern"`
-		Scope   string `json:"scope"`
+		Import string `json:"import"`
+		Scope  string `json:"scope"`
+	}
+	if err := json.Unmarshal([]byte(specRaw), &spec); err != nil {
+		return runCheckOutcome{}, fmt.Errorf("parse import_absent check spec: %w", err)
+	}
+	if strings.TrimSpace(spec.Import) == "" {
+		return runCheckOutcome{}, fmt.Errorf("import_absent requires spec.import")
+	}
+
+	violations, err := archlint.NewService(s.db).ForbiddenImports(ctx, []archlint.Rule{{From: spec.Scope, To: spec.Import}})
+	if err != nil {
+		return runCheckOutcome{}, err
+	}
+	details := fmt.Sprintf("no package imports %s", spec.Import)
+	if len(violations) > 0 {
+		v := violations[0]
+		details = fmt.Sprintf("forbidden import found in %d package edge(s), e.g. %s -> %s (%s)", len(violations), v.From, v.To, strings.Join(v.Files, ", "))
+	}
+	return runCheckOutcome{
+		Passed:  len(violations) == 0,
+		Details: details,
+		Baseline: map[string]any{
+			"import":     spec.Import,
+			"scope":      spec.Scope,
+			"violations": len(violations),
+		},
+	}, nil
+}
+
+type grepSpec struct {
+	Pattern string `json:"pattern"`
+	Scope   string `json:"scope"`
+}
+
+func (s *Service) runGrepPattern(specRaw string, moduleRoot string) (runCheckOutcome, error) {
+	spec, matched, total, err := grepFiles("grep_pattern", specRaw, moduleRoot)
+	if err != nil {
+		return runCheckOutcome{}, err
 	}
+
+	passed := matched > 0
+	return runCheckOutcome{
+		Passed:  passed,
+		Details: fmt.Sprintf("grep pattern matched %d of %d files", matched, total),
+		Baseline: map[string]any{
+			"pattern": spec.Pattern,
+			"scope":   spec.Scope,
+			"matched": matched,
+			"total":   total,
+		},
+	}, nil
+}
+
+// runGrepAbsent is the inverse of runGrepPattern: it passes only when no file
+// in scope matches, which is how a constraint ("never import internal/db from
+// cmd") is verified. not_grep_pattern is the same check under the name a
+// decision ("we do not use reflect in internal/index") reads better with.
+func (s *Service) runGrepAbsent(checkType, specRaw string, moduleRoot string) (runCheckOutcome, error) {
+	spec, matched, total, err := grepFiles(checkType, specRaw, moduleRoot)
+	if err != nil {
+		return runCheckOutcome{}, err
+	}
+
+	return runCheckOutcome{
+		Passed:  matched == 0,
+		Details: fmt.Sprintf("forbidden pattern matched %d of %d files", matched, total),
+		Baseline: map[string]any{
+			"pattern": spec.Pattern,
+			"scope":   spec.Scope,
+			"matched": matched,
+			"total":   total,
+		},
+	}, nil
+}
+
+// grepFiles counts the files in scope whose content matches spec.pattern.
+// Without a scope every indexed Go file is searched; with one, every file
+// whose base name or module-relative path matches the glob.
+func grepFiles(checkType, specRaw, moduleRoot string) (grepSpec, int, int, error) {
+	var spec grepSpec
 	if err := json.Unmarshal([]byte(specRaw), &spec); err != nil {
-		return runCheckOutcome{}, fmt.Errorf("parse grep_pattern check spec: %w", err)
+		return grepSpec{}, 0, 0, fmt.Errorf("parse %s check spec: %w", checkType, err)
 	}
 	if strings.TrimSpace(spec.Pattern) == "" {
-		return runCheckOutcome{}, fmt.Errorf("grep_pattern requires spec.pattern")
+		return grepSpec{}, 0, 0, fmt.Errorf("%s requires spec.pattern", checkType)
 	}
 
 	re, err := regexp.Compile(spec.Pattern)
 	if err != nil {
-		return runCheckOutcome{}, fmt.Errorf("compile regex pattern: %w", err)
+		return grepSpec{}, 0, 0, fmt.Errorf("compile regex pattern: %w", err)
 	}
 
 	total := 0
@@ -504,13 +866,13 @@ func (s *Service) runGrepPattern(specRaw string, moduleRoot string) (runCheckOut
 			return nil
 		})
 		if walkErr != nil {
-			return runCheckOutcome{}, fmt.Errorf("walk files for grep_pattern: %w", walkErr)
+			return grepSpec{}, 0, 0, fmt.Errorf("walk files for %s: %w", checkType, walkErr)
 		}
 	} else {
 		// No scope: grep all indexed Go files.
 		files, collectErr := index.CollectEligibleGoFiles(moduleRoot)
 		if collectErr != nil {
-			return runCheckOutcome{}, fmt.Errorf("load files for grep_pattern: %w", collectErr)
+			return grepSpec{}, 0, 0, fmt.Errorf("load files for %s: %w", checkType, collectErr)
 		}
 		total = len(files)
 		for _, f := range files {
@@ -519,16 +881,104 @@ func (s *Service) runGrepPattern(specRaw string, moduleRoot string) (runCheckOut
 			}
 		}
 	}
+	return spec, matched, total, nil
+}
 
-	passed := matched > 0
-	return runCheckOutcome{
-		Passed:  passed,
-		Details: fmt.Sprintf("grep pattern matched %d of %d files", matched, total),
-		Baseline: map[string]any{
-			"pattern": spec.Pattern,
-			"scope":   spec.Scope,
-			"matched": matched,
-			"total":   total,
-		},
-	}, nil
+// Default time limits for toolchain checks when the spec sets none.
+const (
+	defaultGoBuildTimeout = 2 * time.Minute
+	defaultGoTestTimeout  = 5 * time.Minute
+)
+
+var runGoTool = func(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	start := time.Now()
+	out, err := cmd.CombinedOutput()
+	logging.Exec(ctx, "go", args, start, err)
+	return out, err
+}
+
+// runGoToolCheck verifies evidence with the Go toolchain: go_build_passes runs
+// `go build <scope>` (default ./...) and go_test_passes runs `go test <scope>`,
+// which must be given explicitly so a decision never silently runs the whole
+// suite. A failing or timed-out command fails the check; only a missing
+// toolchain is reported as an error.
+func (s *Service) runGoToolCheck(ctx context.Context, checkType, specRaw, moduleRoot string) (runCheckOutcome, error) {
+	var spec struct {
+		Scope          string `json:"scope"`
+		TimeoutSeconds int    `json:"timeout_seconds"`
+	}
+	if err := json.Unmarshal([]byte(specRaw), &spec); err != nil {
+		return runCheckOutcome{}, fmt.Errorf("parse %s check spec: %w", checkType, err)
+	}
+
+	packages := strings.Fields(spec.Scope)
+	args := []string{"build"}
+	timeout := defaultGoBuildTimeout
+	if checkType == "go_test_passes" {
+		if len(packages) == 0 {
+			return runCheckOutcome{}, fmt.Errorf("go_test_passes requires spec.scope")
+		}
+		args = []string{"test", "-count=1"}
+		timeout = defaultGoTestTimeout
+	}
+	if len(packages) == 0 {
+		packages = []string{"./..."}
+	}
+	for _, pkg := range packages {
+		if strings.HasPrefix(pkg, "-") {
+			return runCheckOutcome{}, fmt.Errorf("invalid %s check spec: scope %q is not a package pattern", checkType, pkg)
+		}
+	}
+	if spec.TimeoutSeconds < 0 {
+		return runCheckOutcome{}, fmt.Errorf("invalid %s check spec: timeout_seconds must be >= 0", checkType)
+	}
+	if spec.TimeoutSeconds > 0 {
+		timeout = time.Duration(spec.TimeoutSeconds) * time.Second
+	}
+	args = append(args, packages...)
+	command := "go " + strings.Join(args, " ")
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	out, err := runGoTool(runCtx, moduleRoot, args...)
+
+	baseline := map[string]any{
+		"command": command,
+		"scope":   strings.Join(packages, " "),
+	}
+	switch {
+	case err == nil:
+		baseline["passed"] = true
+		return runCheckOutcome{Passed: true, Details: command + " passed", Baseline: baseline}, nil
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		baseline["passed"] = false
+		baseline["timed_out"] = true
+		return runCheckOutcome{Details: fmt.Sprintf("%s timed out after %s", command, timeout), Baseline: baseline}, nil
+	case errors.Is(err, exec.ErrNotFound):
+		return runCheckOutcome{}, fmt.Errorf("run %s: %w", command, err)
+	default:
+		baseline["passed"] = false
+		details := command + " failed"
+		if summary := summarizeGoOutput(out, 3); summary != "" {
+			details += ": " + summary
+		}
+		return runCheckOutcome{Details: details, Baseline: baseline}, nil
+	}
+}
+
+// summarizeGoOutput keeps the last n non-empty lines of toolchain output,
+// which is where go build and go test report what went wrong.
+func summarizeGoOutput(out []byte, n int) string {
+	var lines []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, " | ")
 }
@@ -2,6 +2,9 @@ package knowledge
 
 import (
 	"context"
+	"errors"
+	"os/exec"
+	"strings"
 	"testing"
 )
 
@@ -25,3 +28,66 @@ func TestRunCheckDispatch(t *testing.T) {
 		}
 	}
 }
+
+func TestRunGoToolCheck(t *testing.T) {
+	root, conn := setupKnowledgeEnv(t)
+	defer conn.Close()
+	svc := NewService(conn)
+	ctx := context.Background()
+
+	orig := runGoTool
+	defer func() { runGoTool = orig }()
+
+	var gotArgs []string
+	runGoTool = func(_ context.Context, dir string, args ...string) ([]byte, error) {
+		if dir != root {
+			t.Fatalf("expected dir %q, got %q", root, dir)
+		}
+		gotArgs = args
+		return nil, nil
+	}
+	out, err := svc.runCheck(ctx, ProposeDecisionInput{CheckType: "go_build_passes", CheckSpec: `{}`, ModuleRoot: root})
+	if err != nil || !out.Passed || strings.Join(gotArgs, " ") != "build ./..." {
+		t.Fatalf("go_build_passes: out=%+v err=%v args=%v", out, err, gotArgs)
+	}
+
+	out, err = svc.runCheck(ctx, ProposeDecisionInput{CheckType: "go_test_passes", CheckSpec: `{"scope":"./a ./b"}`, ModuleRoot: root})
+	if err != nil || !out.Passed || strings.Join(gotArgs, " ") != "test -count=1 ./a ./b" {
+		t.Fatalf("go_test_passes: out=%+v err=%v args=%v", out, err, gotArgs)
+	}
+
+	runGoTool = func(context.Context, string, ...string) ([]byte, error) {
+		return []byte("# pkg\nx.go:3:1: undefined: Foo\n\nFAIL\n"), errors.New("exit status 1")
+	}
+	out, err = svc.runCheck(ctx, ProposeDecisionInput{CheckType: "go_build_passes", CheckSpec: `{"scope":"./x"}`, ModuleRoot: root})
+	if err != nil || out.Passed || !strings.Contains(out.Details, "undefined: Foo | FAIL") {
+		t.Fatalf("expected failing build, out=%+v err=%v", out, err)
+	}
+
+	runGoTool = func(ctx context.Context, _ string, _ ...string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	out, err = svc.runCheck(ctx, ProposeDecisionInput{CheckType: "go_test_passes", CheckSpec: `{"scope":"./x","timeout_seconds":1}`, ModuleRoot: root})
+	if err != nil || out.Passed || !strings.Contains(out.Details, "timed out after 1s") || out.Baseline["timed_out"] != true {
+		t.Fatalf("expected timeout, out=%+v err=%v", out, err)
+	}
+
+	runGoTool = func(context.Context, string, ...string) ([]byte, error) {
+		return nil, exec.ErrNotFound
+	}
+	if _, err := svc.runCheck(ctx, ProposeDecisionInput{CheckType: "go_build_passes", CheckSpec: `{}`, ModuleRoot: root}); err == nil || !errors.Is(err, exec.ErrNotFound) {
+		t.Fatalf("expected missing toolchain error, got %v", err)
+	}
+
+	for spec, want := range map[string]string{
+		`{}`:                                   "requires spec.scope",
+		`{"scope":"-exec=rm"}`:                 "not a package pattern",
+		`{"scope":"./x","timeout_seconds":-1}`: "timeout_seconds must be >= 0",
+		`nope`:                                 "parse go_test_passes check spec",
+	} {
+		if _, err := svc.runCheck(ctx, ProposeDecisionInput{CheckType: "go_test_passes", CheckSpec: spec, ModuleRoot: root}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("spec %s: expected %q, got %v", spec, want, err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -34,7 +35,7 @@ func setupKnowledgeEnv(t *testing.T) (string, *sql.DB) {
 
 	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (1,'.','main','example.com/recon',1,2,'x','x');`)
 	_, _ = conn.Exec(`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES (1,1,'main.go','go',2,'h','x','x');`)
-	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,body,line_start,line_end,exported,receiver) VALUES (1,1,'func','Hello','func()','func Hello(){}',1,1,1,'');`)
+	_, _ = conn.Exec(`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES (1,1,'func','Hello','func()',1,1,1,'');`)
 
 	return root, conn
 }
@@ -282,6 +283,40 @@ func TestUpdateConfidence(t *testing.T) {
 	}
 }
 
+func TestPromoteDecisionToMain(t *testing.T) {
+	root, conn := setupKnowledgeEnv(t)
+	defer conn.Close()
+	svc := NewService(conn)
+
+	res, err := svc.ProposeAndVerifyDecision(context.Background(), ProposeDecisionInput{
+		Title:           "Branch only",
+		Reasoning:       "reason",
+		EvidenceSummary: "go.mod exists",
+		CheckType:       "file_exists",
+		CheckSpec:       `{"path":"go.mod"}`,
+		ModuleRoot:      root,
+		Branch:          "feature-x",
+	})
+	if err != nil || res.Branch != "feature-x" {
+		t.Fatalf("seed decision: res=%+v err=%v", res, err)
+	}
+	items, err := svc.ListDecisions(context.Background())
+	if err != nil || len(items) != 1 || items[0].Branch != "feature-x" {
+		t.Fatalf("ListDecisions: items=%+v err=%v", items, err)
+	}
+
+	branch, err := svc.PromoteDecisionToMain(context.Background(), res.DecisionID)
+	if err != nil || branch != "feature-x" {
+		t.Fatalf("PromoteDecisionToMain: branch=%q err=%v", branch, err)
+	}
+	if branch, err = svc.PromoteDecisionToMain(context.Background(), res.DecisionID); err != nil || branch != "" {
+		t.Fatalf("expected unscoped decision to stay unscoped, branch=%q err=%v", branch, err)
+	}
+	if _, err := svc.PromoteDecisionToMain(context.Background(), 99999); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
 func TestConfidenceDecaysOnDrift(t *testing.T) {
 	root, conn := setupKnowledgeEnv(t)
 	defer conn.Close()
@@ -384,6 +419,58 @@ func TestListDecisionsDBError(t *testing.T) {
 	}
 }
 
+func TestDecisionArchiveImpact(t *testing.T) {
+	root, conn := setupKnowledgeEnv(t)
+	defer conn.Close()
+	svc := NewService(conn)
+
+	res, err := svc.ProposeAndVerifyDecision(context.Background(), ProposeDecisionInput{
+		Title:           "Impactful",
+		Reasoning:       "reason",
+		EvidenceSummary: "go.mod exists",
+		CheckType:       "file_exists",
+		CheckSpec:       `{"path":"go.mod"}`,
+		ModuleRoot:      root,
+	})
+	if err != nil {
+		t.Fatalf("seed decision: %v", err)
+	}
+	id := res.DecisionID
+	for _, q := range []string{
+		`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES (7,'Linked','d','medium','active','x','x'),(8,'Gone','d','medium','archived','x','x');`,
+		fmt.Sprintf(`INSERT INTO edges(from_type,from_id,to_type,to_ref,relation,created_at) VALUES
+			('decision',%d,'package','internal/cli','affects','x'),
+			('pattern',7,'decision','%d','reinforces','x'),
+			('decision',%d,'pattern','8','related','x');`, id, id, id),
+	} {
+		if _, err := conn.Exec(q); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	impact, err := svc.DecisionArchiveImpact(context.Background(), id)
+	if err != nil {
+		t.Fatalf("DecisionArchiveImpact: %v", err)
+	}
+	if impact.Title != "Impactful" || len(impact.Edges) != 3 || impact.Edges[0].ToRef != "internal/cli" || impact.Empty() {
+		t.Fatalf("unexpected edges: %+v", impact)
+	}
+	if len(impact.Patterns) != 1 || impact.Patterns[0].ID != 7 || impact.Patterns[0].Relation != "reinforces" {
+		t.Fatalf("expected only the active linked pattern, got %+v", impact.Patterns)
+	}
+
+	if err := svc.ArchiveDecision(context.Background(), id); err != nil {
+		t.Fatalf("ArchiveDecision: %v", err)
+	}
+	if _, err := svc.DecisionArchiveImpact(context.Background(), id); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for archived decision, got %v", err)
+	}
+	conn.Close()
+	if _, err := svc.DecisionArchiveImpact(context.Background(), id); err == nil || !strings.Contains(err.Error(), "query decision") {
+		t.Fatalf("expected query error on closed DB, got %v", err)
+	}
+}
+
 func TestArchiveDecisionDBError(t *testing.T) {
 	_, conn := setupKnowledgeEnv(t)
 	svc := NewService(conn)
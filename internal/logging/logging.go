@@ -0,0 +1,91 @@
+// Package logging configures the structured log recon writes for field
+// debugging: which commands ran, the SQL they issued, and the git and go
+// subprocesses they started, with timings. Packages log through slog's
+// default logger, which New replaces; until then nothing is written.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// FileName is the log file under .recon/ that --log-file appends to.
+	FileName = "recon.log"
+	// LevelEnv and FileEnv set --log-level and --log-file for every
+	// command, including those hooks run.
+	LevelEnv = "RECON_LOG_LEVEL"
+	FileEnv  = "RECON_LOG_FILE"
+)
+
+// GitIgnore is the .gitignore entry for the log file.
+var GitIgnore = ".recon/" + FileName
+
+// Until a command configures logging, nothing is written: slog's own
+// default would print info records to stderr.
+func init() {
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+}
+
+// Levels are the accepted --log-level values, quietest first.
+var Levels = []string{"off", "error", "warn", "info", "debug"}
+
+// ParseLevel returns the slog level of name, and false for "off".
+func ParseLevel(name string) (slog.Level, bool, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "off":
+		return 0, false, nil
+	case "error":
+		return slog.LevelError, true, nil
+	case "warn":
+		return slog.LevelWarn, true, nil
+	case "info":
+		return slog.LevelInfo, true, nil
+	case "debug":
+		return slog.LevelDebug, true, nil
+	}
+	return 0, false, fmt.Errorf("invalid log level %q: use one of %s", name, strings.Join(Levels, ", "))
+}
+
+// New returns a logger writing text records at level and above to w, or
+// discarding everything for "off".
+func New(level string, w io.Writer) (*slog.Logger, error) {
+	lvl, on, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	if !on {
+		return slog.New(slog.DiscardHandler), nil
+	}
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: lvl})), nil
+}
+
+// OpenFile opens FileName in reconDir for appending, creating the
+// directory if needed.
+func OpenFile(reconDir string) (*os.File, error) {
+	if err := os.MkdirAll(reconDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", reconDir, err)
+	}
+	path := filepath.Join(reconDir, FileName)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open log file: %w", err)
+	}
+	return f, nil
+}
+
+// Exec logs a finished subprocess at debug level: its command line, how
+// long it ran, and its error, if any.
+func Exec(ctx context.Context, name string, args []string, start time.Time, err error) {
+	attrs := []any{"cmd", name, "args", strings.Join(args, " "), "duration", time.Since(start)}
+	if err != nil {
+		attrs = append(attrs, "err", err)
+	}
+	slog.DebugContext(ctx, "exec", attrs...)
+}
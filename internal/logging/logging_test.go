@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]slog.Level{"error": slog.LevelError, "WARN": slog.LevelWarn, " info ": slog.LevelInfo, "debug": slog.LevelDebug} {
+		if got, on, err := ParseLevel(name); err != nil || !on || got != want {
+			t.Fatalf("ParseLevel(%q) = %v, %v, %v; want %v", name, got, on, err, want)
+		}
+	}
+	for _, name := range []string{"", "off"} {
+		if _, on, err := ParseLevel(name); err != nil || on {
+			t.Fatalf("ParseLevel(%q) = on %v, err %v; want off", name, on, err)
+		}
+	}
+	if _, _, err := ParseLevel("trace"); err == nil || !strings.Contains(err.Error(), "off, error, warn, info, debug") {
+		t.Fatalf("expected an invalid level error listing the levels, got %v", err)
+	}
+}
+
+func TestNewAndExec(t *testing.T) {
+	orig := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(orig) })
+
+	var buf bytes.Buffer
+	off, err := New("off", &buf)
+	if err != nil {
+		t.Fatalf("New off: %v", err)
+	}
+	slog.SetDefault(off)
+	Exec(context.Background(), "git", []string{"status"}, time.Now(), nil)
+	if buf.Len() != 0 {
+		t.Fatalf("expected nothing logged when off, got %q", buf.String())
+	}
+
+	info, err := New("info", &buf)
+	if err != nil {
+		t.Fatalf("New info: %v", err)
+	}
+	slog.SetDefault(info)
+	Exec(context.Background(), "git", []string{"status"}, time.Now(), nil)
+	if buf.Len() != 0 {
+		t.Fatalf("expected exec records only at debug, got %q", buf.String())
+	}
+
+	debug, err := New("debug", &buf)
+	if err != nil {
+		t.Fatalf("New debug: %v", err)
+	}
+	slog.SetDefault(debug)
+	Exec(context.Background(), "git", []string{"log", "-n", "1"}, time.Now(), errors.New("exit status 128"))
+	for _, want := range []string{"level=DEBUG", "msg=exec", "cmd=git", `args="log -n 1"`, "duration=", `err="exit status 128"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("expected %s in %q", want, buf.String())
+		}
+	}
+}
+
+func TestOpenFileAppends(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".recon")
+	for _, line := range []string{"one\n", "two\n"} {
+		f, err := OpenFile(dir)
+		if err != nil {
+			t.Fatalf("OpenFile: %v", err)
+		}
+		if _, err := f.WriteString(line); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		f.Close()
+	}
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil || string(data) != "one\ntwo\n" {
+		t.Fatalf("log file = %q (%v), want both lines", data, err)
+	}
+}
@@ -0,0 +1,218 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceEnv sets the hidden --trace flag, for commands hooks run.
+const TraceEnv = "RECON_TRACE"
+
+// Profile phases. Exec records are filed under the program they ran, so git
+// and go calls get phases of their own.
+const (
+	PhaseDBOpen = "db_open"
+	PhaseQuery  = "query"
+	PhaseRender = "render"
+	PhaseOther  = "other"
+)
+
+// maxSlowest is how many of the slowest operations a report lists.
+const maxSlowest = 5
+
+// Profile adds up the durations of the debug records recon writes for
+// opening the database, SQL statements, subprocesses, and rendering output,
+// so one run of a command can be broken down by phase. Its handler collects
+// every such record and passes records on to the log handler it wraps.
+type Profile struct {
+	start time.Time
+
+	mu      sync.Mutex
+	phases  map[string]*PhaseTiming
+	slowest []Operation
+}
+
+// NewProfile starts a profile; time not spent in a recorded phase is
+// reported as PhaseOther.
+func NewProfile(start time.Time) *Profile {
+	return &Profile{start: start, phases: map[string]*PhaseTiming{}}
+}
+
+// ProfileReport is what --trace writes: where one command spent its time.
+// Phases may overlap when a command runs work concurrently.
+type ProfileReport struct {
+	Command   string        `json:"command"`
+	Args      []string      `json:"args"`
+	StartedAt string        `json:"started_at"`
+	TotalMS   float64       `json:"total_ms"`
+	Phases    []PhaseTiming `json:"phases"`
+	Slowest   []Operation   `json:"slowest"`
+}
+
+// PhaseTiming is the time spent in one phase, over Count operations.
+type PhaseTiming struct {
+	Phase   string  `json:"phase"`
+	Count   int     `json:"count"`
+	TotalMS float64 `json:"total_ms"`
+	MaxMS   float64 `json:"max_ms"`
+}
+
+// Operation is one timed query, subprocess, or render.
+type Operation struct {
+	Phase      string  `json:"phase"`
+	Detail     string  `json:"detail"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// Handler returns a handler that records into p and forwards to next.
+func (p *Profile) Handler(next slog.Handler) slog.Handler {
+	return profileHandler{profile: p, next: next}
+}
+
+// Report summarizes the profile for command, up to now.
+func (p *Profile) Report(command string, args []string, now time.Time) ProfileReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	total := now.Sub(p.start)
+	report := ProfileReport{
+		Command:   command,
+		Args:      append([]string{}, args...),
+		StartedAt: p.start.UTC().Format(time.RFC3339Nano),
+		TotalMS:   millis(total),
+		Phases:    make([]PhaseTiming, 0, len(p.phases)+1),
+		Slowest:   append([]Operation{}, p.slowest...),
+	}
+	var recorded float64
+	for _, t := range p.phases {
+		report.Phases = append(report.Phases, *t)
+		recorded += t.TotalMS
+	}
+	sort.Slice(report.Phases, func(i, j int) bool {
+		if report.Phases[i].TotalMS != report.Phases[j].TotalMS {
+			return report.Phases[i].TotalMS > report.Phases[j].TotalMS
+		}
+		return report.Phases[i].Phase < report.Phases[j].Phase
+	})
+	other := math.Max(0, report.TotalMS-recorded)
+	report.Phases = append(report.Phases, PhaseTiming{Phase: PhaseOther, TotalMS: round(other), MaxMS: round(other)})
+	return report
+}
+
+// WriteText prints r as an aligned table for a terminal.
+func (r ProfileReport) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "trace: %s took %s\n", r.Command, formatMS(r.TotalMS))
+	for _, t := range r.Phases {
+		if t.Phase == PhaseOther {
+			fmt.Fprintf(w, "  %-8s %5s  %10s\n", t.Phase, "", formatMS(t.TotalMS))
+			continue
+		}
+		fmt.Fprintf(w, "  %-8s %5d  %10s  (max %s)\n", t.Phase, t.Count, formatMS(t.TotalMS), formatMS(t.MaxMS))
+	}
+	if len(r.Slowest) > 0 {
+		fmt.Fprintln(w, "slowest:")
+		for _, op := range r.Slowest {
+			fmt.Fprintf(w, "  %10s  %-8s %s\n", formatMS(op.DurationMS), op.Phase, op.Detail)
+		}
+	}
+}
+
+func (p *Profile) record(phase, detail string, d time.Duration) {
+	ms := millis(d)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t := p.phases[phase]
+	if t == nil {
+		t = &PhaseTiming{Phase: phase}
+		p.phases[phase] = t
+	}
+	t.Count++
+	t.TotalMS = round(t.TotalMS + ms)
+	t.MaxMS = math.Max(t.MaxMS, ms)
+
+	i := sort.Search(len(p.slowest), func(i int) bool { return p.slowest[i].DurationMS < ms })
+	if i < maxSlowest {
+		p.slowest = append(p.slowest, Operation{})
+		copy(p.slowest[i+1:], p.slowest[i:])
+		p.slowest[i] = Operation{Phase: phase, Detail: detail, DurationMS: ms}
+		p.slowest = p.slowest[:min(len(p.slowest), maxSlowest)]
+	}
+}
+
+type profileHandler struct {
+	profile *Profile
+	next    slog.Handler
+}
+
+// Enabled is true at debug level, where the timed records are written, so
+// they are collected whatever the log level.
+func (h profileHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelDebug
+}
+
+func (h profileHandler) Handle(ctx context.Context, r slog.Record) error {
+	if phase, detail, d, ok := timedRecord(r); ok {
+		h.profile.record(phase, detail, d)
+	}
+	if h.next.Enabled(ctx, r.Level) {
+		return h.next.Handle(ctx, r)
+	}
+	return nil
+}
+
+func (h profileHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return profileHandler{profile: h.profile, next: h.next.WithAttrs(attrs)}
+}
+
+func (h profileHandler) WithGroup(name string) slog.Handler {
+	return profileHandler{profile: h.profile, next: h.next.WithGroup(name)}
+}
+
+// timedRecord maps a record to its phase, a description of the operation,
+// and its duration. Records that are not timed operations report false.
+func timedRecord(r slog.Record) (phase, detail string, d time.Duration, ok bool) {
+	attrs := map[string]slog.Value{}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.Resolve()
+		return true
+	})
+	dur, found := attrs["duration"]
+	if !found || dur.Kind() != slog.KindDuration {
+		return "", "", 0, false
+	}
+	switch r.Message {
+	case "db open":
+		phase, detail = PhaseDBOpen, attrs["path"].String()
+	case "sql":
+		phase, detail = PhaseQuery, attrs["query"].String()
+	case "exec":
+		phase = attrs["cmd"].String()
+		detail = strings.TrimSpace(phase + " " + attrs["args"].String())
+	case "render":
+		phase, detail = PhaseRender, attrs["format"].String()
+	default:
+		return "", "", 0, false
+	}
+	return phase, detail, dur.Duration(), true
+}
+
+func millis(d time.Duration) float64 {
+	return round(float64(d) / float64(time.Millisecond))
+}
+
+// round keeps microsecond precision.
+func round(ms float64) float64 {
+	return math.Round(ms*1000) / 1000
+}
+
+func formatMS(ms float64) string {
+	return fmt.Sprintf("%.2fms", ms)
+}
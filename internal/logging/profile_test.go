@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfile(t *testing.T) {
+	start := time.Date(2026, 2, 18, 10, 30, 0, 0, time.UTC)
+	profile := NewProfile(start)
+	var logged bytes.Buffer
+	next := slog.NewTextHandler(&logged, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(profile.Handler(next)).With("pid", 1)
+
+	ctx := context.Background()
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		t.Fatal("expected a profiled logger to take debug records")
+	}
+	logger.Debug("db open", "path", "recon.db", "duration", 2*time.Millisecond)
+	for i := 1; i <= 6; i++ {
+		logger.Debug("sql", "query", "SELECT "+strings.Repeat("1", i), "args", 0, "duration", time.Duration(i)*time.Millisecond)
+	}
+	logger.Debug("exec", "cmd", "git", "args", "status --porcelain", "duration", 10*time.Millisecond)
+	logger.Debug("render", "format", "json", "duration", 500*time.Microsecond)
+	logger.Debug("evidence check", "check", "grep", "duration", time.Second)
+	logger.Info("sync", "files", 3)
+
+	if strings.Contains(logged.String(), "level=DEBUG") || !strings.Contains(logged.String(), "msg=sync pid=1 files=3") {
+		t.Fatalf("expected only info records passed on, got %q", logged.String())
+	}
+
+	report := profile.Report("recon orient", []string{"--json"}, start.Add(50*time.Millisecond))
+	if report.Command != "recon orient" || report.TotalMS != 50 || report.StartedAt != "2026-02-18T10:30:00Z" {
+		t.Fatalf("unexpected report header: %+v", report)
+	}
+	want := []PhaseTiming{
+		{Phase: PhaseQuery, Count: 6, TotalMS: 21, MaxMS: 6},
+		{Phase: "git", Count: 1, TotalMS: 10, MaxMS: 10},
+		{Phase: PhaseDBOpen, Count: 1, TotalMS: 2, MaxMS: 2},
+		{Phase: PhaseRender, Count: 1, TotalMS: 0.5, MaxMS: 0.5},
+		{Phase: PhaseOther, TotalMS: 16.5, MaxMS: 16.5},
+	}
+	if len(report.Phases) != len(want) {
+		t.Fatalf("phases = %+v, want %+v", report.Phases, want)
+	}
+	for i := range want {
+		if report.Phases[i] != want[i] {
+			t.Fatalf("phase %d = %+v, want %+v", i, report.Phases[i], want[i])
+		}
+	}
+	if len(report.Slowest) != maxSlowest || report.Slowest[0].Detail != "git status --porcelain" || report.Slowest[1].DurationMS != 6 || report.Slowest[4].DurationMS != 3 {
+		t.Fatalf("unexpected slowest operations: %+v", report.Slowest)
+	}
+
+	var text bytes.Buffer
+	report.WriteText(&text)
+	for _, line := range []string{
+		"trace: recon orient took 50.00ms\n",
+		"  query        6     21.00ms  (max 6.00ms)\n",
+		"  other              16.50ms\n",
+		"     10.00ms  git      git status --porcelain\n",
+	} {
+		if !strings.Contains(text.String(), line) {
+			t.Fatalf("expected %q in:\n%s", line, text.String())
+		}
+	}
+}
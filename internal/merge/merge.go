@@ -0,0 +1,553 @@
+// Package merge combines the knowledge of another recon database, or of a
+// bundle exported from one, into the local database: decisions, patterns,
+// their evidence, and the edges between them and to code.
+package merge
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/pattern"
+)
+
+// BundleVersion is the Bundle format this build writes and reads.
+const BundleVersion = 1
+
+// Bundle is the portable form of a database's active knowledge, as written
+// by `recon merge --export`. IDs are those of the exporting database; edges
+// refer to them.
+type Bundle struct {
+	Version   int        `json:"version"`
+	Decisions []Decision `json:"decisions"`
+	Patterns  []Pattern  `json:"patterns"`
+	Edges     []Edge     `json:"edges"`
+}
+
+// Decision is an active decision of a Bundle.
+type Decision struct {
+	ID         int64     `json:"id"`
+	Title      string    `json:"title"`
+	Reasoning  string    `json:"reasoning"`
+	Confidence string    `json:"confidence"`
+	Branch     string    `json:"branch,omitempty"`
+	CreatedAt  string    `json:"created_at"`
+	Evidence   *Evidence `json:"evidence,omitempty"`
+}
+
+// Pattern is an active pattern of a Bundle.
+type Pattern struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Confidence  string    `json:"confidence"`
+	CreatedAt   string    `json:"created_at"`
+	Evidence    *Evidence `json:"evidence,omitempty"`
+}
+
+// Evidence is the first evidence row recorded for a decision or pattern.
+// An entry backed by several checks carries the rest in More, and Combine
+// says whether all of them must pass or any.
+type Evidence struct {
+	Summary        string     `json:"summary"`
+	CheckType      string     `json:"check_type,omitempty"`
+	CheckSpec      string     `json:"check_spec,omitempty"`
+	Baseline       string     `json:"baseline,omitempty"`
+	LastVerifiedAt string     `json:"last_verified_at,omitempty"`
+	LastResult     string     `json:"last_result,omitempty"`
+	DriftStatus    string     `json:"drift_status,omitempty"`
+	VerifyInterval int64      `json:"verify_interval,omitempty"`
+	Combine        string     `json:"combine,omitempty"`
+	More           []Evidence `json:"more,omitempty"`
+}
+
+// Edge starts at a decision or pattern. ToRef is an entity ID when ToType is
+// decision or pattern, and a package, file, or symbol reference otherwise.
+type Edge struct {
+	FromType   string `json:"from_type"`
+	FromID     int64  `json:"from_id"`
+	ToType     string `json:"to_type"`
+	ToRef      string `json:"to_ref"`
+	Relation   string `json:"relation"`
+	Source     string `json:"source"`
+	Confidence string `json:"confidence"`
+}
+
+// Resolution is how a conflict was settled.
+type Resolution string
+
+const (
+	// Ours keeps the local text.
+	Ours Resolution = "ours"
+	// Theirs replaces the local text and confidence with the merged ones.
+	Theirs Resolution = "theirs"
+	// Unresolved keeps the local text and reports the conflict.
+	Unresolved Resolution = "unresolved"
+)
+
+// Conflict is an active decision or pattern with the same title on both
+// sides whose reasoning (or description) diverged.
+type Conflict struct {
+	EntityType string     `json:"entity_type"`
+	ID         int64      `json:"id"`
+	Title      string     `json:"title"`
+	Ours       string     `json:"ours"`
+	Theirs     string     `json:"theirs"`
+	Resolution Resolution `json:"resolution"`
+}
+
+// Added is an entry that was new to the local database. ID is its local ID.
+type Added struct {
+	EntityType string `json:"entity_type"`
+	ID         int64  `json:"id"`
+	Title      string `json:"title"`
+}
+
+// Result reports what Merge added, left alone, and found in conflict.
+type Result struct {
+	Added      []Added    `json:"added"`
+	Unchanged  int        `json:"unchanged"`
+	Conflicts  []Conflict `json:"conflicts"`
+	EdgesAdded int        `json:"edges_added"`
+	DryRun     bool       `json:"dry_run"`
+}
+
+// Options controls a merge. Resolve settles each conflict before anything
+// is written; nil leaves every conflict unresolved. DryRun reports what
+// would change without writing.
+type Options struct {
+	Resolve func(Conflict) (Resolution, error)
+	DryRun  bool
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// Load reads the knowledge to merge from path: a bundle when it ends in
+// .json, a recon database otherwise. A database is copied and migrated to
+// the current schema first, so the original is never written.
+func Load(ctx context.Context, path string) (Bundle, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return Bundle{}, fmt.Errorf("read bundle: %w", err)
+		}
+		var b Bundle
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return Bundle{}, fmt.Errorf("parse bundle: %w", err)
+		}
+		if b.Version != BundleVersion {
+			return Bundle{}, fmt.Errorf("bundle version %d is not supported (expected %d)", b.Version, BundleVersion)
+		}
+		return b, nil
+	}
+
+	tmp, err := os.MkdirTemp("", "recon-merge-")
+	if err != nil {
+		return Bundle{}, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	copyPath := filepath.Join(tmp, db.DBFileName)
+	if err := copyFile(path, copyPath); err != nil {
+		return Bundle{}, err
+	}
+	// Writes not yet checkpointed into a WAL-mode database live in its -wal file.
+	if _, err := os.Stat(path + "-wal"); err == nil {
+		if err := copyFile(path+"-wal", copyPath+"-wal"); err != nil {
+			return Bundle{}, err
+		}
+	}
+	conn, err := db.Open(copyPath)
+	if err != nil {
+		return Bundle{}, err
+	}
+	defer conn.Close()
+	if err := db.RunMigrations(conn); err != nil {
+		return Bundle{}, fmt.Errorf("%s is not a recon database: %w", path, err)
+	}
+	return NewService(conn).Export(ctx)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("copy database: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("copy database: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("copy database: %w", err)
+	}
+	return nil
+}
+
+// Export returns the active decisions and patterns with their evidence, and
+// the edges that start at them.
+func (s *Service) Export(ctx context.Context) (Bundle, error) {
+	b := Bundle{Version: BundleVersion, Decisions: []Decision{}, Patterns: []Pattern{}, Edges: []Edge{}}
+	evidence, err := s.loadEvidence(ctx)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT id, title, reasoning, confidence, COALESCE(branch, ''), created_at
+FROM decisions WHERE status = 'active' ORDER BY id;
+`)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("query decisions: %w", err)
+	}
+	for rows.Next() {
+		var d Decision
+		if err := rows.Scan(&d.ID, &d.Title, &d.Reasoning, &d.Confidence, &d.Branch, &d.CreatedAt); err != nil {
+			rows.Close()
+			return Bundle{}, fmt.Errorf("scan decision: %w", err)
+		}
+		d.Evidence = evidence["decision:"+strconv.FormatInt(d.ID, 10)]
+		b.Decisions = append(b.Decisions, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return Bundle{}, fmt.Errorf("iterate decisions: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+SELECT id, title, description, confidence, created_at
+FROM patterns WHERE status = 'active' ORDER BY id;
+`)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("query patterns: %w", err)
+	}
+	for rows.Next() {
+		var p Pattern
+		if err := rows.Scan(&p.ID, &p.Title, &p.Description, &p.Confidence, &p.CreatedAt); err != nil {
+			rows.Close()
+			return Bundle{}, fmt.Errorf("scan pattern: %w", err)
+		}
+		p.Evidence = evidence["pattern:"+strconv.FormatInt(p.ID, 10)]
+		b.Patterns = append(b.Patterns, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return Bundle{}, fmt.Errorf("iterate patterns: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+SELECT e.from_type, e.from_id, e.to_type, e.to_ref, e.relation, e.source, e.confidence
+FROM edges e
+WHERE (e.from_type = 'decision' AND e.from_id IN (SELECT id FROM decisions WHERE status = 'active'))
+   OR (e.from_type = 'pattern' AND e.from_id IN (SELECT id FROM patterns WHERE status = 'active'))
+ORDER BY e.id;
+`)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("query edges: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e Edge
+		if err := rows.Scan(&e.FromType, &e.FromID, &e.ToType, &e.ToRef, &e.Relation, &e.Source, &e.Confidence); err != nil {
+			return Bundle{}, fmt.Errorf("scan edge: %w", err)
+		}
+		b.Edges = append(b.Edges, e)
+	}
+	if err := rows.Err(); err != nil {
+		return Bundle{}, fmt.Errorf("iterate edges: %w", err)
+	}
+	return b, nil
+}
+
+func (s *Service) loadEvidence(ctx context.Context) (map[string]*Evidence, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT entity_type, entity_id, summary, COALESCE(check_type, ''), COALESCE(check_spec, ''), COALESCE(baseline, ''),
+       COALESCE(last_verified_at, ''), COALESCE(last_result, ''), COALESCE(drift_status, 'ok'), verify_interval, combine
+FROM evidence WHERE entity_type IN ('decision', 'pattern') ORDER BY id;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query evidence: %w", err)
+	}
+	defer rows.Close()
+	out := map[string]*Evidence{}
+	for rows.Next() {
+		var (
+			entityType string
+			entityID   int64
+			e          Evidence
+		)
+		if err := rows.Scan(&entityType, &entityID, &e.Summary, &e.CheckType, &e.CheckSpec, &e.Baseline,
+			&e.LastVerifiedAt, &e.LastResult, &e.DriftStatus, &e.VerifyInterval, &e.Combine); err != nil {
+			return nil, fmt.Errorf("scan evidence: %w", err)
+		}
+		k := entityType + ":" + strconv.FormatInt(entityID, 10)
+		if e.Combine == "all" {
+			e.Combine = ""
+		}
+		if first, ok := out[k]; ok {
+			first.More = append(first.More, e)
+		} else {
+			out[k] = &e
+		}
+	}
+	return out, rows.Err()
+}
+
+// local is an active decision or pattern of the local database.
+type local struct {
+	ID   int64
+	Text string
+}
+
+// step is what Merge does with one incoming entry.
+type step struct {
+	kind     string
+	theirID  int64
+	title    string
+	text     string
+	conf     string
+	branch   string
+	created  string
+	evidence *Evidence
+	localID  int64 // 0 when the entry is added
+	conflict *Conflict
+	dupOf    *step // an earlier incoming entry with the same title
+}
+
+// Merge adds the decisions and patterns of theirs that the local database
+// lacks, matching entries by kind and title (ignoring case). Matched entries
+// whose text diverged are conflicts, settled by opts.Resolve before anything
+// is written. Edges are added once both ends exist locally; edges that
+// already exist are skipped. Evidence is copied for added entries only.
+func (s *Service) Merge(ctx context.Context, theirs Bundle, opts Options) (Result, error) {
+	res := Result{Added: []Added{}, Conflicts: []Conflict{}, DryRun: opts.DryRun}
+	ours, err := s.loadLocal(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var steps []*step
+	for _, d := range theirs.Decisions {
+		steps = append(steps, &step{kind: "decision", theirID: d.ID, title: d.Title, text: d.Reasoning, conf: d.Confidence, branch: d.Branch, created: d.CreatedAt, evidence: d.Evidence})
+	}
+	for _, p := range theirs.Patterns {
+		steps = append(steps, &step{kind: "pattern", theirID: p.ID, title: p.Title, text: p.Description, conf: p.Confidence, created: p.CreatedAt, evidence: p.Evidence})
+	}
+	pending := map[string]*step{}
+	for _, st := range steps {
+		if strings.TrimSpace(st.title) == "" {
+			return Result{}, fmt.Errorf("%s %d has no title", st.kind, st.theirID)
+		}
+		if st.conf != "low" && st.conf != "medium" && st.conf != "high" {
+			st.conf = "medium"
+		}
+		mine, ok := ours[titleKey(st.kind, st.title)]
+		if !ok {
+			if first, dup := pending[titleKey(st.kind, st.title)]; dup {
+				st.dupOf = first
+			} else {
+				pending[titleKey(st.kind, st.title)] = st
+			}
+			continue
+		}
+		st.localID = mine.ID
+		if strings.TrimSpace(mine.Text) == strings.TrimSpace(st.text) {
+			res.Unchanged++
+			continue
+		}
+		c := Conflict{EntityType: st.kind, ID: mine.ID, Title: st.title, Ours: mine.Text, Theirs: st.text, Resolution: Unresolved}
+		if opts.Resolve != nil {
+			if c.Resolution, err = opts.Resolve(c); err != nil {
+				return Result{}, err
+			}
+		}
+		st.conflict = &c
+		res.Conflicts = append(res.Conflicts, c)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("begin merge: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	ids := map[string]int64{}
+	for _, st := range steps {
+		switch {
+		case st.dupOf != nil:
+			st.localID = st.dupOf.localID
+		case st.localID == 0:
+			id, err := insertEntry(ctx, tx, st, now)
+			if err != nil {
+				return Result{}, err
+			}
+			st.localID = id
+			res.Added = append(res.Added, Added{EntityType: st.kind, ID: id, Title: st.title})
+		case st.conflict != nil && st.conflict.Resolution == Theirs:
+			if err := replaceText(ctx, tx, st, now); err != nil {
+				return Result{}, err
+			}
+		}
+		ids[st.kind+":"+strconv.FormatInt(st.theirID, 10)] = st.localID
+	}
+
+	for _, e := range theirs.Edges {
+		fromID, ok := ids[e.FromType+":"+strconv.FormatInt(e.FromID, 10)]
+		if !ok {
+			continue
+		}
+		toRef := e.ToRef
+		if e.ToType == "decision" || e.ToType == "pattern" {
+			toID, ok := ids[e.ToType+":"+e.ToRef]
+			if !ok {
+				continue
+			}
+			toRef = strconv.FormatInt(toID, 10)
+		}
+		r, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO edges (from_type, from_id, to_type, to_ref, relation, source, confidence, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+`, e.FromType, fromID, e.ToType, toRef, e.Relation, defaultString(e.Source, "manual"), defaultString(e.Confidence, "medium"), now)
+		if err != nil {
+			return Result{}, fmt.Errorf("insert edge: %w", err)
+		}
+		if n, _ := r.RowsAffected(); n > 0 {
+			res.EdgesAdded++
+		}
+	}
+
+	if opts.DryRun {
+		return res, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return Result{}, fmt.Errorf("commit merge: %w", err)
+	}
+	return res, nil
+}
+
+func titleKey(kind, title string) string {
+	return kind + ":" + strings.ToLower(strings.TrimSpace(title))
+}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// loadLocal indexes the active local decisions and patterns by kind and
+// title. Of several with the same title, the oldest wins.
+func (s *Service) loadLocal(ctx context.Context) (map[string]local, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT 'decision', id, title, reasoning FROM decisions WHERE status = 'active'
+UNION ALL
+SELECT 'pattern', id, title, description FROM patterns WHERE status = 'active'
+ORDER BY 2 DESC;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query knowledge: %w", err)
+	}
+	defer rows.Close()
+	out := map[string]local{}
+	for rows.Next() {
+		var (
+			kind, title string
+			l           local
+		)
+		if err := rows.Scan(&kind, &l.ID, &title, &l.Text); err != nil {
+			return nil, fmt.Errorf("scan knowledge: %w", err)
+		}
+		out[titleKey(kind, title)] = l
+	}
+	return out, rows.Err()
+}
+
+func insertEntry(ctx context.Context, tx *sql.Tx, st *step, now string) (int64, error) {
+	created := defaultString(st.created, now)
+	var (
+		r   sql.Result
+		err error
+	)
+	if st.kind == "decision" {
+		r, err = tx.ExecContext(ctx, `
+INSERT INTO decisions (title, reasoning, confidence, status, created_at, updated_at, branch)
+VALUES (?, ?, ?, 'active', ?, ?, ?);
+`, st.title, st.text, st.conf, created, now, sql.NullString{String: st.branch, Valid: st.branch != ""})
+	} else {
+		r, err = tx.ExecContext(ctx, `
+INSERT INTO patterns (title, description, confidence, status, created_at, updated_at)
+VALUES (?, ?, ?, 'active', ?, ?);
+`, st.title, st.text, st.conf, created, now)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("insert %s: %w", st.kind, err)
+	}
+	id, err := r.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("read %s id: %w", st.kind, err)
+	}
+
+	if first := st.evidence; first != nil {
+		combine := defaultString(first.Combine, "all")
+		for _, e := range append([]Evidence{*first}, first.More...) {
+			if _, err := tx.ExecContext(ctx, `
+INSERT INTO evidence (entity_type, entity_id, summary, check_type, check_spec, baseline, last_verified_at, last_result, drift_status, verify_interval, combine)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+`, st.kind, id, e.Summary, nullString(e.CheckType), nullString(e.CheckSpec), nullString(e.Baseline),
+				nullString(e.LastVerifiedAt), nullString(e.LastResult), defaultString(e.DriftStatus, "ok"), e.VerifyInterval, combine); err != nil {
+				return 0, fmt.Errorf("insert %s evidence: %w", st.kind, err)
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO search_index (title, content, entity_type, entity_id) VALUES ('', '', ?, ?);`, st.kind, id); err != nil {
+		return 0, fmt.Errorf("insert search index: %w", err)
+	}
+	return id, pattern.ReindexEntity(ctx, tx, st.kind, id)
+}
+
+func replaceText(ctx context.Context, tx *sql.Tx, st *step, now string) error {
+	query := `UPDATE decisions SET reasoning = ?, confidence = ?, updated_at = ? WHERE id = ?;`
+	if st.kind == "pattern" {
+		query = `UPDATE patterns SET description = ?, confidence = ?, updated_at = ? WHERE id = ?;`
+	}
+	if _, err := tx.ExecContext(ctx, query, st.text, st.conf, now, st.localID); err != nil {
+		return fmt.Errorf("update %s %d: %w", st.kind, st.localID, err)
+	}
+	return pattern.ReindexEntity(ctx, tx, st.kind, st.localID)
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// WriteBundle writes b as indented JSON to path.
+func WriteBundle(path string, b Bundle) error {
+	raw, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode bundle: %w", err)
+	}
+	if err := os.WriteFile(path, append(raw, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write bundle: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package merge
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func setupDB(t *testing.T, root string) *sql.DB {
+	t.Helper()
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func mustExec(t *testing.T, conn *sql.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := conn.Exec(query, args...); err != nil {
+		t.Fatalf("exec %q: %v", query, err)
+	}
+}
+
+func addDecision(t *testing.T, conn *sql.DB, title, reasoning string) int64 {
+	t.Helper()
+	r, err := conn.Exec(`INSERT INTO decisions (title, reasoning, confidence, status, created_at, updated_at) VALUES (?, ?, 'medium', 'active', '2026-01-01T00:00:00Z', '2026-01-01T00:00:00Z');`, title, reasoning)
+	if err != nil {
+		t.Fatalf("insert decision: %v", err)
+	}
+	id, _ := r.LastInsertId()
+	return id
+}
+
+func addPattern(t *testing.T, conn *sql.DB, title, description string) int64 {
+	t.Helper()
+	r, err := conn.Exec(`INSERT INTO patterns (title, description, confidence, status, created_at, updated_at) VALUES (?, ?, 'high', 'active', '2026-01-01T00:00:00Z', '2026-01-01T00:00:00Z');`, title, description)
+	if err != nil {
+		t.Fatalf("insert pattern: %v", err)
+	}
+	id, _ := r.LastInsertId()
+	return id
+}
+
+func queryString(t *testing.T, conn *sql.DB, query string, args ...any) string {
+	t.Helper()
+	var s string
+	if err := conn.QueryRow(query, args...).Scan(&s); err != nil {
+		t.Fatalf("query %q: %v", query, err)
+	}
+	return s
+}
+
+// theirs builds a source database with a decision, a pattern, evidence, and
+// edges between them and to code, and returns its bundle.
+func theirs(t *testing.T) Bundle {
+	t.Helper()
+	conn := setupDB(t, t.TempDir())
+	addDecision(t, conn, "Filler", "Shifts IDs so they differ from the target.")
+	mustExec(t, conn, `UPDATE decisions SET status = 'archived';`)
+	d := addDecision(t, conn, "Use SQLite", "Single file, no server.")
+	p := addPattern(t, conn, "Wrap errors", "Use fmt.Errorf with %w.")
+	mustExec(t, conn, `INSERT INTO evidence (entity_type, entity_id, summary, check_type, check_spec, drift_status, verify_interval) VALUES ('decision', ?, 'go.mod requires sqlite', 'grep_pattern', '{"pattern":"sqlite"}', 'ok', 0);`, d)
+	mustExec(t, conn, `INSERT INTO edges (from_type, from_id, to_type, to_ref, relation, source, confidence, created_at) VALUES ('decision', ?, 'pattern', ?, 'related', 'manual', 'high', '2026-01-01T00:00:00Z');`, d, p)
+	mustExec(t, conn, `INSERT INTO edges (from_type, from_id, to_type, to_ref, relation, source, confidence, created_at) VALUES ('pattern', ?, 'package', 'internal/db', 'affects', 'manual', 'medium', '2026-01-01T00:00:00Z');`, p)
+
+	b, err := NewService(conn).Export(context.Background())
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	return b
+}
+
+func TestExportAndLoad(t *testing.T) {
+	root := t.TempDir()
+	conn := setupDB(t, root)
+	d := addDecision(t, conn, "Use SQLite", "Single file.")
+	addDecision(t, conn, "Old", "Archived.")
+	mustExec(t, conn, `UPDATE decisions SET status = 'archived' WHERE title = 'Old';`)
+	mustExec(t, conn, `INSERT INTO evidence (entity_type, entity_id, summary, drift_status, verify_interval) VALUES ('decision', ?, 'seen', 'ok', 0);`, d)
+	ctx := context.Background()
+
+	b, err := NewService(conn).Export(ctx)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if b.Version != BundleVersion || len(b.Decisions) != 1 || len(b.Patterns) != 0 || b.Decisions[0].Evidence == nil || b.Decisions[0].Evidence.Summary != "seen" {
+		t.Fatalf("bundle = %+v", b)
+	}
+
+	fromDB, err := Load(ctx, db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Load db: %v", err)
+	}
+	if len(fromDB.Decisions) != 1 || fromDB.Decisions[0].Title != "Use SQLite" {
+		t.Fatalf("Load db = %+v", fromDB)
+	}
+
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := WriteBundle(path, b); err != nil {
+		t.Fatalf("WriteBundle: %v", err)
+	}
+	fromFile, err := Load(ctx, path)
+	if err != nil {
+		t.Fatalf("Load bundle: %v", err)
+	}
+	if len(fromFile.Decisions) != 1 || !reflect.DeepEqual(fromFile.Decisions[0].Evidence, b.Decisions[0].Evidence) {
+		t.Fatalf("Load bundle = %+v", fromFile)
+	}
+
+	b.Version = 99
+	if err := WriteBundle(path, b); err != nil {
+		t.Fatalf("WriteBundle: %v", err)
+	}
+	if _, err := Load(ctx, path); err == nil || !strings.Contains(err.Error(), "version 99") {
+		t.Fatalf("expected version error, got %v", err)
+	}
+	if _, err := Load(ctx, filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Fatal("expected error for a missing database")
+	}
+}
+
+func TestMergeAddsEntriesEvidenceAndEdges(t *testing.T) {
+	conn := setupDB(t, t.TempDir())
+	svc := NewService(conn)
+	ctx := context.Background()
+	b := theirs(t)
+
+	res, err := svc.Merge(ctx, b, Options{})
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if len(res.Added) != 2 || res.Unchanged != 0 || len(res.Conflicts) != 0 || res.EdgesAdded != 2 {
+		t.Fatalf("result = %+v", res)
+	}
+	dID, pID := res.Added[0].ID, res.Added[1].ID
+	if res.Added[0].EntityType != "decision" || res.Added[1].EntityType != "pattern" {
+		t.Fatalf("added = %+v", res.Added)
+	}
+	if got := queryString(t, conn, `SELECT to_ref FROM edges WHERE from_type = 'decision' AND from_id = ?;`, dID); got != queryString(t, conn, `SELECT CAST(? AS TEXT);`, pID) {
+		t.Fatalf("decision edge to_ref = %q, want pattern %d", got, pID)
+	}
+	if got := queryString(t, conn, `SELECT check_type FROM evidence WHERE entity_type = 'decision' AND entity_id = ?;`, dID); got != "grep_pattern" {
+		t.Fatalf("evidence check_type = %q", got)
+	}
+	if got := queryString(t, conn, `SELECT title FROM search_index WHERE entity_type = 'pattern' AND entity_id = ?;`, pID); got != "Wrap errors" {
+		t.Fatalf("search index title = %q", got)
+	}
+
+	again, err := svc.Merge(ctx, b, Options{})
+	if err != nil {
+		t.Fatalf("second Merge: %v", err)
+	}
+	if len(again.Added) != 0 || again.Unchanged != 2 || again.EdgesAdded != 0 {
+		t.Fatalf("second merge = %+v", again)
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	ctx := context.Background()
+	b := theirs(t)
+
+	for _, tc := range []struct {
+		resolution Resolution
+		want       string
+	}{
+		{Ours, "Embedded, pure Go."},
+		{Unresolved, "Embedded, pure Go."},
+		{Theirs, "Single file, no server."},
+	} {
+		conn := setupDB(t, t.TempDir())
+		id := addDecision(t, conn, "use sqlite ", "Embedded, pure Go.")
+		var seen []Conflict
+		res, err := NewService(conn).Merge(ctx, b, Options{Resolve: func(c Conflict) (Resolution, error) {
+			seen = append(seen, c)
+			return tc.resolution, nil
+		}})
+		if err != nil {
+			t.Fatalf("Merge %s: %v", tc.resolution, err)
+		}
+		if len(seen) != 1 || seen[0].ID != id || seen[0].Ours != "Embedded, pure Go." || seen[0].Theirs != "Single file, no server." {
+			t.Fatalf("%s: resolver saw %+v", tc.resolution, seen)
+		}
+		if len(res.Conflicts) != 1 || res.Conflicts[0].Resolution != tc.resolution || len(res.Added) != 1 {
+			t.Fatalf("%s: result = %+v", tc.resolution, res)
+		}
+		if got := queryString(t, conn, `SELECT reasoning FROM decisions WHERE id = ?;`, id); got != tc.want {
+			t.Fatalf("%s: reasoning = %q, want %q", tc.resolution, got, tc.want)
+		}
+		// The edge from the matched decision lands on the local one.
+		if got := queryString(t, conn, `SELECT COUNT(*) FROM edges WHERE from_type = 'decision' AND from_id = ?;`, id); got != "1" {
+			t.Fatalf("%s: edges from matched decision = %s", tc.resolution, got)
+		}
+	}
+}
+
+func TestMergeDryRun(t *testing.T) {
+	conn := setupDB(t, t.TempDir())
+	res, err := NewService(conn).Merge(context.Background(), theirs(t), Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if !res.DryRun || len(res.Added) != 2 || res.EdgesAdded != 2 {
+		t.Fatalf("result = %+v", res)
+	}
+	for _, table := range []string{"decisions", "patterns", "edges", "evidence"} {
+		if got := queryString(t, conn, `SELECT COUNT(*) FROM `+table+`;`); got != "0" {
+			t.Fatalf("%s rows after dry run = %s", table, got)
+		}
+	}
+}
+
+func TestMergeDuplicateIncomingTitles(t *testing.T) {
+	conn := setupDB(t, t.TempDir())
+	b := Bundle{Version: BundleVersion, Decisions: []Decision{
+		{ID: 1, Title: "Same", Reasoning: "first", Confidence: "bogus"},
+		{ID: 2, Title: "same", Reasoning: "second"},
+	}, Edges: []Edge{{FromType: "decision", FromID: 2, ToType: "package", ToRef: "internal/db", Relation: "affects"}}}
+	res, err := NewService(conn).Merge(context.Background(), b, Options{})
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if len(res.Added) != 1 || res.EdgesAdded != 1 {
+		t.Fatalf("result = %+v", res)
+	}
+	if got := queryString(t, conn, `SELECT confidence FROM decisions;`); got != "medium" {
+		t.Fatalf("confidence = %q", got)
+	}
+
+	if _, err := NewService(conn).Merge(context.Background(), Bundle{Patterns: []Pattern{{ID: 1, Title: " "}}}, Options{}); err == nil {
+		t.Fatal("expected error for an untitled pattern")
+	}
+}
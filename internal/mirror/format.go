@@ -0,0 +1,179 @@
+package mirror
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// entry is a decision or pattern as its file holds it. Body is the reasoning
+// of a decision or the description of a pattern; Branch applies to
+// decisions only.
+type entry struct {
+	Kind       string
+	Title      string
+	Confidence string
+	Branch     string
+	Body       string
+}
+
+// bodyKey is the YAML key Body is written under for kind.
+func bodyKey(kind string) string {
+	if kind == kindPattern {
+		return "description"
+	}
+	return "reasoning"
+}
+
+const header = "# Mirrored from the recon index. Edit it and run `recon sync` to apply the\n" +
+	"# change; delete the file to archive the entry.\n"
+
+// render writes e in the YAML subset parse reads back. Titles and branches
+// are always quoted; a multi-line body is written as a literal block.
+func render(e entry) []byte {
+	var b strings.Builder
+	b.WriteString(header)
+	fmt.Fprintf(&b, "kind: %s\n", e.Kind)
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(e.Title))
+	fmt.Fprintf(&b, "confidence: %s\n", e.Confidence)
+	if e.Kind == kindDecision && e.Branch != "" {
+		fmt.Fprintf(&b, "branch: %s\n", strconv.Quote(e.Branch))
+	}
+	key := bodyKey(e.Kind)
+	if !blockSafe(e.Body) {
+		fmt.Fprintf(&b, "%s: %s\n", key, strconv.Quote(e.Body))
+		return []byte(b.String())
+	}
+	fmt.Fprintf(&b, "%s: |-\n", key)
+	for _, line := range strings.Split(e.Body, "\n") {
+		if line == "" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString("  " + line + "\n")
+	}
+	return []byte(b.String())
+}
+
+// blockSafe reports whether s survives a round trip through a literal block
+// scalar: it spans lines, has no surrounding whitespace, and no carriage
+// returns.
+func blockSafe(s string) bool {
+	return strings.Contains(s, "\n") && s == strings.TrimSpace(s) && !strings.Contains(s, "\r")
+}
+
+// parse reads a knowledge file: top-level `key: value` pairs whose values
+// are plain, single- or double-quoted scalars, or `|` literal blocks.
+// Comments and blank lines are skipped. This is the subset of YAML render
+// writes and people write by hand; anchors, flow collections, and folded
+// blocks are rejected.
+func parse(content []byte) (entry, error) {
+	fields := map[string]string{}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if line != strings.TrimLeft(line, " \t") {
+			return entry{}, fmt.Errorf("line %d: unexpected indentation", i+1)
+		}
+		key, raw, ok := strings.Cut(line, ":")
+		if !ok || key == "" || strings.ContainsAny(key, " \t\"'") {
+			return entry{}, fmt.Errorf("line %d: expected `key: value`", i+1)
+		}
+		if _, dup := fields[key]; dup {
+			return entry{}, fmt.Errorf("line %d: duplicate key %q", i+1, key)
+		}
+		raw = strings.TrimSpace(raw)
+
+		var value string
+		switch {
+		case raw == "|" || raw == "|-" || raw == "|+":
+			var block []string
+			for i+1 < len(lines) && (strings.TrimSpace(lines[i+1]) == "" || strings.HasPrefix(lines[i+1], " ")) {
+				i++
+				block = append(block, lines[i])
+			}
+			value = dedent(block)
+		case strings.HasPrefix(raw, `"`):
+			v, err := strconv.Unquote(raw)
+			if err != nil {
+				return entry{}, fmt.Errorf("line %d: invalid double-quoted string", i+1)
+			}
+			value = v
+		case strings.HasPrefix(raw, "'"):
+			if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+				return entry{}, fmt.Errorf("line %d: invalid single-quoted string", i+1)
+			}
+			value = strings.ReplaceAll(raw[1:len(raw)-1], "''", "'")
+		case strings.ContainsAny(raw[:min(len(raw), 1)], ">&*!%@`[{"):
+			return entry{}, fmt.Errorf("line %d: unsupported YAML value %q", i+1, raw)
+		default:
+			if before, _, found := strings.Cut(raw, " #"); found {
+				raw = strings.TrimSpace(before)
+			}
+			value = raw
+		}
+		fields[key] = strings.TrimSpace(value)
+	}
+
+	e := entry{
+		Kind:       fields["kind"],
+		Title:      fields["title"],
+		Confidence: fields["confidence"],
+		Branch:     fields["branch"],
+	}
+	switch e.Kind {
+	case kindDecision, kindPattern:
+	case "":
+		return entry{}, fmt.Errorf("kind is required (decision or pattern)")
+	default:
+		return entry{}, fmt.Errorf("kind must be decision or pattern, got %q", e.Kind)
+	}
+	allowed := []string{"kind", "title", "confidence", bodyKey(e.Kind)}
+	if e.Kind == kindDecision {
+		allowed = append(allowed, "branch")
+	}
+	for key := range fields {
+		if !slices.Contains(allowed, key) {
+			return entry{}, fmt.Errorf("unknown field %q for a %s", key, e.Kind)
+		}
+	}
+	e.Body = fields[bodyKey(e.Kind)]
+	if e.Title == "" {
+		return entry{}, fmt.Errorf("title is required")
+	}
+	if e.Confidence == "" {
+		e.Confidence = "medium"
+	}
+	if !slices.Contains([]string{"low", "medium", "high"}, e.Confidence) {
+		return entry{}, fmt.Errorf("confidence must be low, medium, or high, got %q", e.Confidence)
+	}
+	return e, nil
+}
+
+// dedent strips the indentation of the first non-blank line from every line
+// of a literal block.
+func dedent(lines []string) string {
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			indent = len(l) - len(strings.TrimLeft(l, " "))
+			break
+		}
+	}
+	prefix := strings.Repeat(" ", max(indent, 0))
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		if trimmed, ok := strings.CutPrefix(l, prefix); ok {
+			l = trimmed
+		} else {
+			l = strings.TrimLeft(l, " ")
+		}
+		out = append(out, l)
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,534 @@
+// Package mirror keeps active decisions and patterns in step with YAML files
+// under .recon/knowledge/, so knowledge changes can be committed and reviewed
+// like code while the database stays the index recon queries.
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/pattern"
+)
+
+// DirName is the directory under .recon/ that holds knowledge files.
+const DirName = "knowledge"
+
+const (
+	kindDecision = "decision"
+	kindPattern  = "pattern"
+	ext          = ".yaml"
+)
+
+// Actions reported in a FileChange.
+const (
+	// ActionExported: a database change was written to the file, or the
+	// entry got its first file.
+	ActionExported = "exported"
+	// ActionImported: an edit to the file was applied to the database.
+	ActionImported = "imported"
+	// ActionCreated: a new file was recorded as a new entry.
+	ActionCreated = "created"
+	// ActionArchived: the file was deleted, so its entry was archived.
+	ActionArchived = "archived"
+	// ActionRemoved: the entry was archived, so its file was deleted.
+	ActionRemoved = "removed"
+)
+
+// FileChange is one file that Reconcile wrote, read, or deleted. File is
+// module-relative.
+type FileChange struct {
+	File       string `json:"file"`
+	EntityType string `json:"entity_type"`
+	EntityID   int64  `json:"entity_id"`
+	Title      string `json:"title"`
+	Action     string `json:"action"`
+}
+
+// InvalidFile is a knowledge file Reconcile could not read. Its entry is
+// left alone until the file is fixed.
+type InvalidFile struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// ReconcileResult lists the files Reconcile changed and the ones it could
+// not read.
+type ReconcileResult struct {
+	Changes []FileChange  `json:"changes"`
+	Invalid []InvalidFile `json:"invalid"`
+}
+
+// Dir returns the knowledge file directory for a module root.
+func Dir(root string) string {
+	return filepath.Join(db.ReconDir(root), DirName)
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// entity is a decision or pattern as the database holds it.
+type entity struct {
+	entry
+	ID     int64
+	Active bool
+}
+
+// tracked is a knowledge_files row.
+type tracked struct {
+	Kind string
+	ID   int64
+	Hash string
+}
+
+func key(kind string, id int64) string {
+	return kind + ":" + strconv.FormatInt(id, 10)
+}
+
+// Reconcile brings the knowledge files and the database into agreement. The
+// hash recorded for each file tells which side changed since the last run:
+//
+//   - a file edited since then is applied to its entry, which wins over a
+//     database change to the same entry and reactivates an archived one;
+//   - an unchanged file is rewritten when its entry changed in the database,
+//     and deleted when its entry was archived;
+//   - a deleted file archives its entry;
+//   - a new file becomes a new entry, or adopts an untracked active entry of
+//     the same kind and title;
+//   - an active entry without a file gets one.
+//
+// Files are named after their kind and title; a file that was renamed
+// without being edited keeps its entry. Entries created from files have no
+// evidence until one is recorded with `recon decide` or `recon pattern`.
+func (s *Service) Reconcile(ctx context.Context, moduleRoot string) (ReconcileResult, error) {
+	res := ReconcileResult{Changes: []FileChange{}, Invalid: []InvalidFile{}}
+	dir := Dir(moduleRoot)
+
+	entities, err := s.loadEntities(ctx)
+	if err != nil {
+		return ReconcileResult{}, err
+	}
+	rows, err := s.loadTracked(ctx)
+	if err != nil {
+		return ReconcileResult{}, err
+	}
+	files, err := readFiles(dir)
+	if err != nil {
+		return ReconcileResult{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ReconcileResult{}, fmt.Errorf("begin reconcile: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	rel := func(name string) string { return path.Join(db.ReconDirName, DirName, name) }
+	record := func(name string, e entity, action string) {
+		res.Changes = append(res.Changes, FileChange{File: rel(name), EntityType: e.Kind, EntityID: e.ID, Title: e.Title, Action: action})
+	}
+
+	// A tracked file that is gone but whose unchanged content turns up under
+	// a new name was renamed.
+	missing := map[string]string{}
+	for name, t := range rows {
+		if _, ok := files[name]; !ok {
+			missing[t.Hash] = name
+		}
+	}
+
+	linked := map[string]bool{}
+	for _, t := range rows {
+		linked[key(t.Kind, t.ID)] = true
+	}
+	kept := map[string]bool{}
+
+	for _, name := range sortedKeys(files) {
+		content := files[name]
+		hash := hashOf(content)
+		t, isTracked := rows[name]
+		if old, ok := missing[hash]; ok && !isTracked {
+			t, isTracked = rows[old], true
+			delete(missing, hash)
+			delete(rows, old)
+			rows[name] = t
+			if _, err := tx.ExecContext(ctx, `UPDATE knowledge_files SET path = ? WHERE path = ?;`, name, old); err != nil {
+				return ReconcileResult{}, fmt.Errorf("rename knowledge file: %w", err)
+			}
+		}
+
+		e, err := parse(content)
+		if err == nil && isTracked && e.Kind != t.Kind {
+			err = fmt.Errorf("kind cannot change from %s to %s; add a new file instead", t.Kind, e.Kind)
+		}
+		if err != nil {
+			res.Invalid = append(res.Invalid, InvalidFile{File: rel(name), Error: err.Error()})
+			if isTracked {
+				kept[key(t.Kind, t.ID)] = true
+			}
+			continue
+		}
+
+		switch {
+		case isTracked && hash == t.Hash:
+			cur, ok := entities[key(t.Kind, t.ID)]
+			if !ok || !cur.Active {
+				if err := removeFile(filepath.Join(dir, name)); err != nil {
+					return ReconcileResult{}, err
+				}
+				if err := untrack(ctx, tx, name); err != nil {
+					return ReconcileResult{}, err
+				}
+				if !ok {
+					cur = entity{entry: e, ID: t.ID}
+				}
+				record(name, cur, ActionRemoved)
+				continue
+			}
+			kept[key(t.Kind, t.ID)] = true
+			if cur.entry == e {
+				continue
+			}
+			content := render(cur.entry)
+			if err := writeFile(filepath.Join(dir, name), content); err != nil {
+				return ReconcileResult{}, err
+			}
+			if err := track(ctx, tx, name, cur, hashOf(content)); err != nil {
+				return ReconcileResult{}, err
+			}
+			record(name, cur, ActionExported)
+
+		case isTracked:
+			cur, ok := entities[key(t.Kind, t.ID)]
+			action := ActionImported
+			if !ok {
+				if cur, err = insertEntity(ctx, tx, e, now); err != nil {
+					return ReconcileResult{}, err
+				}
+				action = ActionCreated
+			} else if cur.entry != e || !cur.Active {
+				cur.entry, cur.Active = e, true
+				if err := updateEntity(ctx, tx, cur, now); err != nil {
+					return ReconcileResult{}, err
+				}
+			} else {
+				action = ""
+			}
+			kept[key(cur.Kind, cur.ID)] = true
+			if err := track(ctx, tx, name, cur, hash); err != nil {
+				return ReconcileResult{}, err
+			}
+			if action != "" {
+				record(name, cur, action)
+			}
+
+		default:
+			cur, ok := adoptable(entities, linked, e)
+			action := ActionImported
+			if !ok {
+				if cur, err = insertEntity(ctx, tx, e, now); err != nil {
+					return ReconcileResult{}, err
+				}
+				action = ActionCreated
+			} else if cur.entry != e {
+				cur.entry = e
+				if err := updateEntity(ctx, tx, cur, now); err != nil {
+					return ReconcileResult{}, err
+				}
+			} else {
+				action = ""
+			}
+			linked[key(cur.Kind, cur.ID)] = true
+			kept[key(cur.Kind, cur.ID)] = true
+			if err := track(ctx, tx, name, cur, hash); err != nil {
+				return ReconcileResult{}, err
+			}
+			if action != "" {
+				record(name, cur, action)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(rows) {
+		if _, ok := files[name]; ok {
+			continue
+		}
+		t := rows[name]
+		if err := untrack(ctx, tx, name); err != nil {
+			return ReconcileResult{}, err
+		}
+		cur, ok := entities[key(t.Kind, t.ID)]
+		if !ok || !cur.Active || kept[key(t.Kind, t.ID)] {
+			continue
+		}
+		if err := archiveEntity(ctx, tx, cur, now); err != nil {
+			return ReconcileResult{}, err
+		}
+		cur.Active = false
+		entities[key(t.Kind, t.ID)] = cur
+		record(name, cur, ActionArchived)
+	}
+
+	names := map[string]bool{}
+	for name := range files {
+		names[name] = true
+	}
+	for _, k := range sortedKeys(entities) {
+		cur := entities[k]
+		if !cur.Active || kept[k] {
+			continue
+		}
+		name := fileName(cur.entry, names)
+		names[name] = true
+		content := render(cur.entry)
+		if err := writeFile(filepath.Join(dir, name), content); err != nil {
+			return ReconcileResult{}, err
+		}
+		if err := track(ctx, tx, name, cur, hashOf(content)); err != nil {
+			return ReconcileResult{}, err
+		}
+		record(name, cur, ActionExported)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ReconcileResult{}, fmt.Errorf("commit reconcile: %w", err)
+	}
+	return res, nil
+}
+
+// adoptable finds the active entry of e's kind and title that has no file,
+// as when two clones recorded the same knowledge before enabling files.
+func adoptable(entities map[string]entity, linked map[string]bool, e entry) (entity, bool) {
+	for _, k := range sortedKeys(entities) {
+		cur := entities[k]
+		if cur.Active && cur.Kind == e.Kind && cur.Title == e.Title && !linked[k] {
+			return cur, true
+		}
+	}
+	return entity{}, false
+}
+
+func (s *Service) loadEntities(ctx context.Context) (map[string]entity, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT 'decision', id, title, reasoning, confidence, COALESCE(branch, ''), status FROM decisions
+UNION ALL
+SELECT 'pattern', id, title, description, confidence, '', status FROM patterns;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query knowledge: %w", err)
+	}
+	defer rows.Close()
+	entities := map[string]entity{}
+	for rows.Next() {
+		var (
+			e      entity
+			status string
+		)
+		if err := rows.Scan(&e.Kind, &e.ID, &e.Title, &e.Body, &e.Confidence, &e.Branch, &status); err != nil {
+			return nil, fmt.Errorf("scan knowledge: %w", err)
+		}
+		e.Title, e.Body, e.Branch = strings.TrimSpace(e.Title), strings.TrimSpace(e.Body), strings.TrimSpace(e.Branch)
+		e.Active = status == "active"
+		entities[key(e.Kind, e.ID)] = e
+	}
+	return entities, rows.Err()
+}
+
+func (s *Service) loadTracked(ctx context.Context) (map[string]tracked, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT path, entity_type, entity_id, hash FROM knowledge_files;`)
+	if err != nil {
+		return nil, fmt.Errorf("query knowledge files: %w", err)
+	}
+	defer rows.Close()
+	out := map[string]tracked{}
+	for rows.Next() {
+		var (
+			name string
+			t    tracked
+		)
+		if err := rows.Scan(&name, &t.Kind, &t.ID, &t.Hash); err != nil {
+			return nil, fmt.Errorf("scan knowledge file: %w", err)
+		}
+		out[name] = t
+	}
+	return out, rows.Err()
+}
+
+func track(ctx context.Context, tx *sql.Tx, name string, e entity, hash string) error {
+	// A file that now stands for another entry replaces that entry's row.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM knowledge_files WHERE entity_type = ? AND entity_id = ? AND path <> ?;`, e.Kind, e.ID, name); err != nil {
+		return fmt.Errorf("track knowledge file: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO knowledge_files (path, entity_type, entity_id, hash) VALUES (?, ?, ?, ?)
+ON CONFLICT(path) DO UPDATE SET entity_type = excluded.entity_type, entity_id = excluded.entity_id, hash = excluded.hash;
+`, name, e.Kind, e.ID, hash); err != nil {
+		return fmt.Errorf("track knowledge file: %w", err)
+	}
+	return nil
+}
+
+func untrack(ctx context.Context, tx *sql.Tx, name string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM knowledge_files WHERE path = ?;`, name); err != nil {
+		return fmt.Errorf("untrack knowledge file: %w", err)
+	}
+	return nil
+}
+
+func insertEntity(ctx context.Context, tx *sql.Tx, e entry, now string) (entity, error) {
+	var (
+		r   sql.Result
+		err error
+	)
+	if e.Kind == kindDecision {
+		r, err = tx.ExecContext(ctx, `
+INSERT INTO decisions (title, reasoning, confidence, status, created_at, updated_at, branch)
+VALUES (?, ?, ?, 'active', ?, ?, ?);
+`, e.Title, e.Body, e.Confidence, now, now, sql.NullString{String: e.Branch, Valid: e.Branch != ""})
+	} else {
+		r, err = tx.ExecContext(ctx, `
+INSERT INTO patterns (title, description, confidence, status, created_at, updated_at)
+VALUES (?, ?, ?, 'active', ?, ?);
+`, e.Title, e.Body, e.Confidence, now, now)
+	}
+	if err != nil {
+		return entity{}, fmt.Errorf("insert %s: %w", e.Kind, err)
+	}
+	id, err := r.LastInsertId()
+	if err != nil {
+		return entity{}, fmt.Errorf("read %s id: %w", e.Kind, err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO search_index (title, content, entity_type, entity_id) VALUES ('', '', ?, ?);`, e.Kind, id); err != nil {
+		return entity{}, fmt.Errorf("insert search index: %w", err)
+	}
+	cur := entity{entry: e, ID: id, Active: true}
+	return cur, pattern.ReindexEntity(ctx, tx, cur.Kind, cur.ID)
+}
+
+func updateEntity(ctx context.Context, tx *sql.Tx, e entity, now string) error {
+	var err error
+	if e.Kind == kindDecision {
+		_, err = tx.ExecContext(ctx, `
+UPDATE decisions SET title = ?, reasoning = ?, confidence = ?, branch = ?, status = 'active', updated_at = ? WHERE id = ?;
+`, e.Title, e.Body, e.Confidence, sql.NullString{String: e.Branch, Valid: e.Branch != ""}, now, e.ID)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+UPDATE patterns SET title = ?, description = ?, confidence = ?, status = 'active', updated_at = ? WHERE id = ?;
+`, e.Title, e.Body, e.Confidence, now, e.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("update %s %d: %w", e.Kind, e.ID, err)
+	}
+	return pattern.ReindexEntity(ctx, tx, e.Kind, e.ID)
+}
+
+func archiveEntity(ctx context.Context, tx *sql.Tx, e entity, now string) error {
+	table := "decisions"
+	if e.Kind == kindPattern {
+		table = "patterns"
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE `+table+` SET status = 'archived', updated_at = ? WHERE id = ?;`, now, e.ID); err != nil {
+		return fmt.Errorf("archive %s %d: %w", e.Kind, e.ID, err)
+	}
+	return nil
+}
+
+// readFiles returns the contents of the .yaml files in dir by name. A
+// missing directory has none.
+func readFiles(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string][]byte{}, nil
+		}
+		return nil, fmt.Errorf("read knowledge files: %w", err)
+	}
+	files := map[string][]byte{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ext) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read knowledge file: %w", err)
+		}
+		files[e.Name()] = content
+	}
+	return files, nil
+}
+
+// writeFile replaces path atomically, so an interrupted sync never leaves a
+// truncated file for git to pick up.
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return fmt.Errorf("write knowledge file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write knowledge file: %w", err)
+	}
+	return nil
+}
+
+func removeFile(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove knowledge file: %w", err)
+	}
+	return nil
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// fileName names a new file after e's kind and title, numbering it when the
+// name is taken.
+func fileName(e entry, taken map[string]bool) string {
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(e.Title), "-"), "-")
+	if len(slug) > 60 {
+		slug = strings.TrimRight(slug[:60], "-")
+	}
+	base := e.Kind
+	if slug != "" {
+		base += "-" + slug
+	}
+	name := base + ext
+	for i := 2; taken[name]; i++ {
+		name = base + "-" + strconv.Itoa(i) + ext
+	}
+	return name
+}
+
+func hashOf(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,207 @@
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func setupDB(t *testing.T, root string) *sql.DB {
+	t.Helper()
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func mustExec(t *testing.T, conn *sql.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := conn.Exec(query, args...); err != nil {
+		t.Fatalf("exec %q: %v", query, err)
+	}
+}
+
+func reconcile(t *testing.T, conn *sql.DB, root string) ReconcileResult {
+	t.Helper()
+	res, err := NewService(conn).Reconcile(context.Background(), root)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	return res
+}
+
+func actions(res ReconcileResult) string {
+	var out []string
+	for _, c := range res.Changes {
+		out = append(out, c.Action+" "+filepath.Base(c.File))
+	}
+	return strings.Join(out, ", ")
+}
+
+func TestRenderParseRoundTrip(t *testing.T) {
+	for _, e := range []entry{
+		{Kind: kindDecision, Title: `Use "SQLite": one file`, Confidence: "high", Branch: "feature/x", Body: "Single file.\n\n  Indented line.\nNo server."},
+		{Kind: kindDecision, Title: "Empty", Confidence: "low"},
+		{Kind: kindPattern, Title: "Wrap errors", Confidence: "medium", Body: "Use fmt.Errorf with %w. # not a comment"},
+		{Kind: kindPattern, Title: "Tabs", Confidence: "medium", Body: "first\n\tindented with a tab"},
+	} {
+		got, err := parse(render(e))
+		if err != nil {
+			t.Fatalf("parse(render(%+v)): %v\n%s", e, err, render(e))
+		}
+		if got != e {
+			t.Fatalf("round trip = %+v, want %+v\n%s", got, e, render(e))
+		}
+	}
+}
+
+func TestParseHandWritten(t *testing.T) {
+	got, err := parse([]byte("# comment\nkind: pattern\ntitle: 'It''s plain'  \ndescription: |\n    First line\n\n    second: line\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := entry{Kind: kindPattern, Title: "It's plain", Confidence: "medium", Body: "First line\n\nsecond: line"}
+	if got != want {
+		t.Fatalf("parse = %+v, want %+v", got, want)
+	}
+
+	for _, tc := range []struct{ name, content, want string }{
+		{"no kind", "title: x\n", "kind is required"},
+		{"bad kind", "kind: rule\ntitle: x\n", "kind must be decision or pattern"},
+		{"no title", "kind: decision\n", "title is required"},
+		{"bad confidence", "kind: decision\ntitle: x\nconfidence: sure\n", "confidence must be"},
+		{"unknown field", "kind: pattern\ntitle: x\nbranch: main\n", `unknown field "branch"`},
+		{"duplicate", "kind: decision\nkind: decision\n", "duplicate key"},
+		{"indented", "kind: decision\n  title: x\n", "unexpected indentation"},
+		{"flow", "kind: decision\ntitle: [a, b]\n", "unsupported YAML value"},
+		{"bad quote", "kind: decision\ntitle: \"open\n", "invalid double-quoted"},
+	} {
+		if _, err := parse([]byte(tc.content)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: err = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	root := t.TempDir()
+	conn := setupDB(t, root)
+	dir := Dir(root)
+	mustExec(t, conn, `INSERT INTO decisions (id, title, reasoning, confidence, status, created_at, updated_at) VALUES (1, 'Use SQLite', 'Single file.', 'high', 'active', 'now', 'now');`)
+	mustExec(t, conn, `INSERT INTO search_index (title, content, entity_type, entity_id) VALUES ('Use SQLite', 'Single file.', 'decision', 1);`)
+	mustExec(t, conn, `INSERT INTO patterns (id, title, description, confidence, status, created_at, updated_at) VALUES (1, 'Wrap errors', 'Use %w.', 'medium', 'active', 'now', 'now');`)
+	mustExec(t, conn, `INSERT INTO decisions (id, title, reasoning, confidence, status, created_at, updated_at) VALUES (2, 'Old', 'Gone.', 'low', 'archived', 'now', 'now');`)
+
+	if got := actions(reconcile(t, conn, root)); got != "exported decision-use-sqlite.yaml, exported pattern-wrap-errors.yaml" {
+		t.Fatalf("first reconcile = %q", got)
+	}
+	if got := actions(reconcile(t, conn, root)); got != "" {
+		t.Fatalf("second reconcile = %q, want no changes", got)
+	}
+
+	// Edited in review: the file wins and the search index follows.
+	decisionFile := filepath.Join(dir, "decision-use-sqlite.yaml")
+	if err := os.WriteFile(decisionFile, []byte("kind: decision\ntitle: Use SQLite\nconfidence: high\nreasoning: Single file, no server.\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got := actions(reconcile(t, conn, root)); got != "imported decision-use-sqlite.yaml" {
+		t.Fatalf("after file edit = %q", got)
+	}
+	var content string
+	if err := conn.QueryRow(`SELECT content FROM search_index WHERE entity_type = 'decision' AND entity_id = 1`).Scan(&content); err != nil || !strings.Contains(content, "no server") {
+		t.Fatalf("search content = %q, err=%v", content, err)
+	}
+
+	// Changed in the database: the file is rewritten.
+	mustExec(t, conn, `UPDATE patterns SET confidence = 'high' WHERE id = 1;`)
+	if got := actions(reconcile(t, conn, root)); got != "exported pattern-wrap-errors.yaml" {
+		t.Fatalf("after database edit = %q", got)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "pattern-wrap-errors.yaml"))
+	if !strings.Contains(string(data), "confidence: high") {
+		t.Fatalf("pattern file not rewritten:\n%s", data)
+	}
+
+	// A renamed file keeps its entry; a new file becomes a new one.
+	if err := os.Rename(filepath.Join(dir, "pattern-wrap-errors.yaml"), filepath.Join(dir, "errors.yaml")); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "no-cgo.yaml"), []byte("kind: decision\ntitle: No cgo\nreasoning: |\n  Static builds.\n  Easy cross-compiles.\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got := actions(reconcile(t, conn, root)); got != "created no-cgo.yaml" {
+		t.Fatalf("after rename and new file = %q", got)
+	}
+	var reasoning, status string
+	if err := conn.QueryRow(`SELECT reasoning, status FROM decisions WHERE title = 'No cgo'`).Scan(&reasoning, &status); err != nil || reasoning != "Static builds.\nEasy cross-compiles." || status != "active" {
+		t.Fatalf("created decision = %q %q, err=%v", reasoning, status, err)
+	}
+
+	// Deleting a file archives its entry; archiving an entry deletes its file.
+	if err := os.Remove(decisionFile); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	mustExec(t, conn, `UPDATE patterns SET status = 'archived' WHERE id = 1;`)
+	if got := actions(reconcile(t, conn, root)); got != "removed errors.yaml, archived decision-use-sqlite.yaml" {
+		t.Fatalf("after deletes = %q", got)
+	}
+	if err := conn.QueryRow(`SELECT status FROM decisions WHERE id = 1`).Scan(&status); err != nil || status != "archived" {
+		t.Fatalf("decision 1 status = %q, err=%v", status, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "errors.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("errors.yaml should be removed, err=%v", err)
+	}
+
+	// A broken file is reported and leaves its entry alone.
+	if err := os.WriteFile(filepath.Join(dir, "no-cgo.yaml"), []byte("kind: decision\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	res := reconcile(t, conn, root)
+	if len(res.Invalid) != 1 || res.Invalid[0].File != ".recon/knowledge/no-cgo.yaml" || len(res.Changes) != 0 {
+		t.Fatalf("invalid file result = %+v", res)
+	}
+}
+
+func TestReconcileAdoptsSameTitle(t *testing.T) {
+	root := t.TempDir()
+	conn := setupDB(t, root)
+	mustExec(t, conn, `INSERT INTO decisions (id, title, reasoning, confidence, status, created_at, updated_at) VALUES (7, 'Use SQLite', 'Local notes.', 'medium', 'active', 'now', 'now');`)
+	mustExec(t, conn, `INSERT INTO search_index (title, content, entity_type, entity_id) VALUES ('Use SQLite', 'Local notes.', 'decision', 7);`)
+	if err := os.MkdirAll(Dir(root), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(Dir(root), "decision-use-sqlite.yaml"), []byte("kind: decision\ntitle: Use SQLite\nconfidence: high\nreasoning: Shared notes.\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	res := reconcile(t, conn, root)
+	if got := actions(res); got != "imported decision-use-sqlite.yaml" || res.Changes[0].EntityID != 7 {
+		t.Fatalf("adopt = %+v", res)
+	}
+	var n int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM decisions`).Scan(&n); err != nil || n != 1 {
+		t.Fatalf("decisions = %d, err=%v; want the existing one adopted", n, err)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	taken := map[string]bool{"decision-use-sqlite.yaml": true}
+	if got := fileName(entry{Kind: kindDecision, Title: "Use SQLite!"}, taken); got != "decision-use-sqlite-2.yaml" {
+		t.Fatalf("fileName = %q", got)
+	}
+	if got := fileName(entry{Kind: kindPattern, Title: "???"}, taken); got != "pattern.yaml" {
+		t.Fatalf("fileName = %q", got)
+	}
+}
@@ -0,0 +1,249 @@
+package orient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrFocusNotFound is returned by Build when the focus path matches no
+// indexed package.
+var ErrFocusNotFound = errors.New("focus path matches no indexed package")
+
+// maxFocusFiles caps Focus.Files; Summary.FileCount still counts them all.
+const maxFocusFiles = 50
+
+// Focus describes the package subtree a focused payload is scoped to.
+// Dependencies are packages outside the subtree that it imports; Dependents
+// are packages outside the subtree that import it.
+type Focus struct {
+	Path         string        `json:"path"`
+	Files        []string      `json:"files"`
+	Symbols      SymbolSummary `json:"symbols"`
+	Dependencies []string      `json:"dependencies"`
+	Dependents   []string      `json:"dependents"`
+}
+
+type SymbolSummary struct {
+	Total    int            `json:"total"`
+	Exported int            `json:"exported"`
+	ByKind   map[string]int `json:"by_kind"`
+}
+
+// NormalizeFocus turns a user-supplied package path such as "./internal/index/"
+// or "internal/index/..." into the module-relative form stored in the index.
+// The module root ("." or "") means no focus.
+func NormalizeFocus(focus string) string {
+	focus = filepath.ToSlash(strings.TrimSpace(focus))
+	focus = strings.TrimSuffix(focus, "...")
+	if focus == "" {
+		return ""
+	}
+	focus = path.Clean(focus)
+	if focus == "." || focus == "/" {
+		return ""
+	}
+	return strings.TrimPrefix(focus, "/")
+}
+
+// inFocus reports whether pkg is the focus package or nested below it.
+func inFocus(pkg, focus string) bool {
+	return pkg == focus || strings.HasPrefix(pkg, focus+"/")
+}
+
+// focusArgs binds the subtree predicates below: ?1 is the focus path, ?2 the
+// length of ?3 (the focus with a trailing slash), and ?4 the focus with a
+// trailing dot for package-qualified symbol refs. substr is used instead of
+// LIKE because "_" in package paths is a LIKE wildcard.
+func focusArgs(focus string, extra ...any) []any {
+	args := []any{focus, utf8.RuneCountInString(focus) + 1, focus + "/", focus + "."}
+	return append(args, extra...)
+}
+
+const focusPackagePredicate = `(p.path = ?1 OR substr(p.path, 1, ?2) = ?3)`
+
+// focusEdgePredicate matches edges that point into the focus subtree by
+// package, file or package-qualified symbol.
+const focusEdgePredicate = `(
+      (e.to_type IN ('package', 'file') AND (e.to_ref = ?1 OR substr(e.to_ref, 1, ?2) = ?3))
+   OR (e.to_type = 'symbol' AND (substr(e.to_ref, 1, ?2) = ?3 OR substr(e.to_ref, 1, ?2) = ?4))
+)`
+
+// loadFocus fills the summary, modules, decisions, patterns and Focus for
+// the focus subtree. Knowledge is included when an edge links it into the
+// subtree.
+func (s *Service) loadFocus(ctx context.Context, focus string, maxDecisions int, payload *Payload) error {
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM packages p WHERE `+focusPackagePredicate+`;`,
+		focusArgs(focus)...).Scan(&payload.Summary.PackageCount); err != nil {
+		return fmt.Errorf("count focus packages: %w", err)
+	}
+	if payload.Summary.PackageCount == 0 {
+		return fmt.Errorf("%w: %s", ErrFocusNotFound, focus)
+	}
+
+	f := &Focus{Path: focus, Files: []string{}, Dependencies: []string{}, Dependents: []string{}, Symbols: SymbolSummary{ByKind: map[string]int{}}}
+	payload.Focus = f
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT path, name, file_count, line_count
+FROM packages p
+WHERE `+focusPackagePredicate+`
+ORDER BY path ASC;
+`, focusArgs(focus)...)
+	if err != nil {
+		return fmt.Errorf("query focus modules: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m ModuleSummary
+		if err := rows.Scan(&m.Path, &m.Name, &m.FileCount, &m.LineCount); err != nil {
+			return fmt.Errorf("scan focus module row: %w", err)
+		}
+		payload.Modules = append(payload.Modules, m)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate focus module rows: %w", err)
+	}
+
+	fileRows, err := s.db.QueryContext(ctx, `
+SELECT f.path
+FROM files f
+JOIN packages p ON p.id = f.package_id
+WHERE `+focusPackagePredicate+`
+ORDER BY f.path;
+`, focusArgs(focus)...)
+	if err != nil {
+		return fmt.Errorf("query focus files: %w", err)
+	}
+	defer fileRows.Close()
+	for fileRows.Next() {
+		var file string
+		if err := fileRows.Scan(&file); err != nil {
+			return fmt.Errorf("scan focus file: %w", err)
+		}
+		payload.Summary.FileCount++
+		if len(f.Files) < maxFocusFiles {
+			f.Files = append(f.Files, file)
+		}
+	}
+	if err := fileRows.Err(); err != nil {
+		return fmt.Errorf("iterate focus files: %w", err)
+	}
+
+	symRows, err := s.db.QueryContext(ctx, `
+SELECT s.kind, COUNT(*), SUM(s.exported)
+FROM symbols s
+JOIN files f ON f.id = s.file_id
+JOIN packages p ON p.id = f.package_id
+WHERE `+focusPackagePredicate+`
+GROUP BY s.kind
+ORDER BY s.kind;
+`, focusArgs(focus)...)
+	if err != nil {
+		return fmt.Errorf("query focus symbols: %w", err)
+	}
+	defer symRows.Close()
+	for symRows.Next() {
+		var kind string
+		var total, exported int
+		if err := symRows.Scan(&kind, &total, &exported); err != nil {
+			return fmt.Errorf("scan focus symbols: %w", err)
+		}
+		f.Symbols.ByKind[kind] = total
+		f.Symbols.Total += total
+		f.Symbols.Exported += exported
+	}
+	if err := symRows.Err(); err != nil {
+		return fmt.Errorf("iterate focus symbols: %w", err)
+	}
+	payload.Summary.SymbolCount = f.Symbols.Total
+
+	scope, scopeArgs := branchScope("d.branch", "?5", s.branch)
+	if err := s.db.QueryRowContext(ctx, `
+SELECT COUNT(DISTINCT d.id)
+FROM decisions d
+JOIN edges e ON e.from_type = 'decision' AND e.from_id = d.id
+WHERE d.status = 'active'`+scope+` AND `+focusEdgePredicate+`;
+`, focusArgs(focus, scopeArgs...)...).Scan(&payload.Summary.DecisionCount); err != nil {
+		return fmt.Errorf("count focus decisions: %w", err)
+	}
+
+	scope, scopeArgs = branchScope("d.branch", "?6", s.branch)
+	decisionRows, err := s.db.QueryContext(ctx, `
+SELECT d.id, d.title, COALESCE(d.reasoning, ''), d.confidence, d.updated_at, COALESCE(ev.drift_status, 'ok'), COALESCE(d.branch, '')
+FROM decisions d
+LEFT JOIN entity_evidence ev ON ev.entity_type = 'decision' AND ev.entity_id = d.id
+WHERE d.status = 'active'`+scope+confidenceScope("d.confidence", s.minConfidence)+`
+  AND d.id IN (SELECT e.from_id FROM edges e WHERE e.from_type = 'decision' AND `+focusEdgePredicate+`)
+ORDER BY d.updated_at DESC, d.id DESC
+LIMIT ?5;
+`, focusArgs(focus, append([]any{maxDecisions}, scopeArgs...)...)...)
+	if err != nil {
+		return fmt.Errorf("query focus decisions: %w", err)
+	}
+	if err := scanDecisions(decisionRows, payload); err != nil {
+		return err
+	}
+
+	patternRows, err := s.db.QueryContext(ctx, `
+SELECT p.id, p.title, COALESCE(p.description, ''), p.confidence, p.updated_at, COALESCE(ev.drift_status, 'ok')
+FROM patterns p
+LEFT JOIN entity_evidence ev ON ev.entity_type = 'pattern' AND ev.entity_id = p.id
+WHERE p.status = 'active'`+confidenceScope("p.confidence", s.minConfidence)+`
+  AND p.id IN (SELECT e.from_id FROM edges e WHERE e.from_type = 'pattern' AND `+focusEdgePredicate+`)
+ORDER BY p.updated_at DESC, p.id DESC
+LIMIT ?5;
+`, focusArgs(focus, 5)...)
+	if err != nil {
+		return fmt.Errorf("query focus patterns: %w", err)
+	}
+	return scanPatterns(patternRows, payload)
+}
+
+// scopeArchitecture narrows the architecture to the focus subtree once module
+// flow has been classified against the whole graph, and records the
+// subtree's direct dependencies and dependents outside it.
+func scopeArchitecture(payload *Payload) {
+	f := payload.Focus
+	deps, dependents := map[string]bool{}, map[string]bool{}
+	flow := []DependencyEdge{}
+	for _, edge := range payload.Architecture.DependencyFlow {
+		fromIn := inFocus(edge.From, f.Path)
+		if fromIn {
+			flow = append(flow, edge)
+		}
+		for _, to := range edge.To {
+			toIn := inFocus(to, f.Path)
+			switch {
+			case fromIn && !toIn:
+				deps[to] = true
+			case !fromIn && toIn:
+				dependents[edge.From] = true
+			}
+		}
+	}
+	f.Dependencies = sortedKeys(deps)
+	f.Dependents = sortedKeys(dependents)
+
+	entryPoints := []string{}
+	for _, ep := range payload.Architecture.EntryPoints {
+		if inFocus(path.Dir(ep), f.Path) {
+			entryPoints = append(entryPoints, ep)
+		}
+	}
+	payload.Architecture = Architecture{EntryPoints: entryPoints, DependencyFlow: flow}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
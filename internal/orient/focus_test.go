@@ -0,0 +1,145 @@
+package orient
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/index"
+)
+
+func TestNormalizeFocus(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                      "",
+		".":                     "",
+		"./":                    "",
+		"./...":                 "",
+		"internal/store":        "internal/store",
+		"./internal/store/":     "internal/store",
+		"internal/store/...":    "internal/store",
+		" internal//store/x/..": "internal/store",
+	} {
+		if got := NormalizeFocus(in); got != want {
+			t.Errorf("NormalizeFocus(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBuildFocus(t *testing.T) {
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v (%s)", args, err, string(out))
+		}
+	}
+	files := map[string]string{
+		"go.mod":                         "module example.com/m\n",
+		"cmd/app/main.go":                "package main\nimport \"example.com/m/internal/web\"\nfunc main(){ web.Serve() }\n",
+		"internal/web/web.go":            "package web\nimport \"example.com/m/internal/store\"\nfunc Serve(){ store.Open() }\n",
+		"internal/store/store.go":        "package store\nimport \"example.com/m/internal/util\"\ntype Store struct{}\nfunc Open() *Store { util.Log(); return nil }\nfunc (s *Store) get() {}\n",
+		"internal/store/sqlx/sqlx.go":    "package sqlx\nfunc Query(){}\n",
+		"internal/store_test/helpers.go": "package store_test\nfunc Help(){}\n",
+		"internal/util/util.go":          "package util\nfunc Log(){}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	run("init")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Tester")
+	run("add", ".")
+	run("commit", "-m", "init")
+
+	conn := setupOrientDB(t, root)
+	defer conn.Close()
+	if _, err := index.NewService(conn).Sync(context.Background(), root); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	for _, stmt := range []string{
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+			(1,'Stores wrap errors','why','high','active','2026-01-01T00:00:00Z','2026-01-01T00:00:00Z'),
+			(2,'Handlers stay thin','why','medium','active','2026-01-02T00:00:00Z','2026-01-02T00:00:00Z'),
+			(3,'Open is the only constructor','why','medium','active','2026-01-03T00:00:00Z','2026-01-03T00:00:00Z')`,
+		`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES
+			(1,'Query helpers','d','medium','active','2026-01-01T00:00:00Z','2026-01-01T00:00:00Z')`,
+		`INSERT INTO edges(from_type,from_id,to_type,to_ref,relation,source,confidence,created_at) VALUES
+			('decision',1,'package','internal/store','affects','manual','high','x'),
+			('decision',2,'package','internal/web','affects','manual','high','x'),
+			('decision',3,'symbol','internal/store.Open','affects','manual','high','x'),
+			('pattern',1,'file','internal/store/sqlx/sqlx.go','affects','manual','high','x')`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	payload, err := NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root, Focus: "./internal/store/"})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	f := payload.Focus
+	if f == nil || f.Path != "internal/store" {
+		t.Fatalf("Focus = %+v", f)
+	}
+	// internal/store_test shares the prefix but is not under the subtree.
+	if strings.Join(f.Files, ",") != "internal/store/sqlx/sqlx.go,internal/store/store.go" {
+		t.Fatalf("files = %v", f.Files)
+	}
+	if f.Symbols.Total != 4 || f.Symbols.Exported != 3 || f.Symbols.ByKind["method"] != 1 {
+		t.Fatalf("symbols = %+v", f.Symbols)
+	}
+	if strings.Join(f.Dependencies, ",") != "internal/util" || strings.Join(f.Dependents, ",") != "internal/web" {
+		t.Fatalf("deps = %v, dependents = %v", f.Dependencies, f.Dependents)
+	}
+	if payload.Summary != (Summary{FileCount: 2, SymbolCount: 4, PackageCount: 2, DecisionCount: 2}) {
+		t.Fatalf("summary = %+v", payload.Summary)
+	}
+	if len(payload.Modules) != 2 || payload.Modules[0].Path != "internal/store" || payload.Modules[0].Flow != FlowUpstream {
+		t.Fatalf("modules = %+v", payload.Modules)
+	}
+	if len(payload.ActiveDecisions) != 2 || payload.ActiveDecisions[0].ID != 3 || payload.ActiveDecisions[1].ID != 1 {
+		t.Fatalf("decisions = %+v", payload.ActiveDecisions)
+	}
+	if len(payload.ActivePatterns) != 1 {
+		t.Fatalf("patterns = %+v", payload.ActivePatterns)
+	}
+	if len(payload.Architecture.EntryPoints) != 0 || len(payload.Architecture.DependencyFlow) != 1 || payload.Architecture.DependencyFlow[0].From != "internal/store" {
+		t.Fatalf("architecture = %+v", payload.Architecture)
+	}
+	for _, a := range payload.RecentActivity {
+		if !strings.HasPrefix(a.File, "internal/store/") {
+			t.Fatalf("recent activity outside focus: %+v", payload.RecentActivity)
+		}
+	}
+	if len(payload.RecentActivity) != 2 {
+		t.Fatalf("recent activity = %+v", payload.RecentActivity)
+	}
+
+	text := RenderText(payload)
+	for _, want := range []string{"Focus: internal/store\n", "Symbols: 4 (3 exported)", "Depends on: internal/util\n", "Depended on by: internal/web\n", "- internal/store/store.go\n"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("text missing %q:\n%s", want, text)
+		}
+	}
+
+	if _, err := NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root, Focus: "internal/nope"}); !errors.Is(err, ErrFocusNotFound) {
+		t.Fatalf("Build unknown focus = %v, want ErrFocusNotFound", err)
+	}
+
+	full, err := NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root, Focus: "."})
+	if err != nil || full.Focus != nil || full.Summary.PackageCount != 6 {
+		t.Fatalf("Build with root focus = %+v, %v", full.Summary, err)
+	}
+}
@@ -0,0 +1,234 @@
+package orient
+
+import (
+	"context"
+	"fmt"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/robertguss/recon/internal/index"
+)
+
+// Onboarding is the material `recon onboarding` renders for people new to
+// the module: the orient payload plus what orient leaves to the reader.
+type Onboarding struct {
+	Payload
+	// Purposes maps package paths to the first sentence of their package
+	// doc comment. Packages without one are left out.
+	Purposes map[string]string `json:"purposes"`
+	Testing  Testing           `json:"testing"`
+}
+
+// Testing is how the module's tests are laid out and run.
+type Testing struct {
+	TestFiles    int `json:"test_files"`
+	TestPackages int `json:"test_packages"`
+	// Commands run the tests: `go test ./...` first, then the test recipes
+	// of a justfile or Makefile at the module root.
+	Commands []string `json:"commands"`
+}
+
+// taskRecipe matches a justfile recipe or Makefile target header, capturing
+// its name. `name := value` assignments do not match.
+var taskRecipe = regexp.MustCompile(`^([A-Za-z0-9_-]+)(?:\s[^:=]*)?:(?:\s|$)`)
+
+// BuildOnboarding builds the orient payload for opts and adds package
+// purposes and test commands.
+func (s *Service) BuildOnboarding(ctx context.Context, opts BuildOptions) (Onboarding, error) {
+	payload, err := s.Build(ctx, opts)
+	if err != nil {
+		return Onboarding{}, err
+	}
+	o := Onboarding{Payload: payload, Purposes: map[string]string{}}
+	for _, m := range payload.Modules {
+		purpose, err := s.packagePurpose(ctx, opts.ModuleRoot, m.Path)
+		if err != nil {
+			return Onboarding{}, err
+		}
+		if purpose != "" {
+			o.Purposes[m.Path] = purpose
+		}
+	}
+
+	counts, err := index.TestFileCounts(opts.ModuleRoot)
+	if err != nil {
+		return Onboarding{}, err
+	}
+	for _, n := range counts {
+		o.Testing.TestFiles += n
+	}
+	o.Testing.TestPackages = len(counts)
+	o.Testing.Commands = append([]string{"go test ./..."}, taskTestCommands(opts.ModuleRoot)...)
+	return o, nil
+}
+
+// packagePurpose returns the synopsis of the package doc comment of pkg,
+// preferring doc.go as `go doc` does. Files that no longer parse are skipped.
+func (s *Service) packagePurpose(ctx context.Context, moduleRoot, pkg string) (string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT f.path
+FROM files f
+JOIN packages p ON p.id = f.package_id
+WHERE p.path = ?
+ORDER BY CASE WHEN f.path LIKE '%/doc.go' OR f.path = 'doc.go' THEN 0 ELSE 1 END, f.path;
+`, pkg)
+	if err != nil {
+		return "", fmt.Errorf("query package files: %w", err)
+	}
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			rows.Close()
+			return "", fmt.Errorf("scan package file: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("iterate package files: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		parsed, err := parser.ParseFile(fset, filepath.Join(moduleRoot, filepath.FromSlash(path)), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || parsed.Doc == nil {
+			continue
+		}
+		if synopsis := new(doc.Package).Synopsis(parsed.Doc.Text()); synopsis != "" {
+			return synopsis, nil
+		}
+	}
+	return "", nil
+}
+
+// taskTestCommands lists the recipes of a justfile or Makefile at the
+// module root whose names mention tests, as commands to run them.
+func taskTestCommands(moduleRoot string) []string {
+	var commands []string
+	for _, runner := range []struct{ file, tool string }{
+		{"justfile", "just"},
+		{"Makefile", "make"},
+	} {
+		content, err := os.ReadFile(filepath.Join(moduleRoot, runner.file))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			m := taskRecipe.FindStringSubmatch(line)
+			if m != nil && strings.Contains(m[1], "test") {
+				commands = append(commands, runner.tool+" "+m[1])
+			}
+		}
+	}
+	return commands
+}
+
+// RenderOnboarding renders an onboarding walkthrough as a markdown document
+// for a person joining the project: where execution starts, what the main
+// packages are for, the rules and decisions to respect, and how to run the
+// tests.
+func RenderOnboarding(o Onboarding) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Onboarding: %s\n\n", o.Project.Name)
+	b.WriteString("<!-- Generated by `recon onboarding` from the recon index. -->\n\n")
+	fmt.Fprintf(&b, "`%s` is a Go module with %d packages, %d files, and %d symbols.\n",
+		o.Project.ModulePath, o.Summary.PackageCount, o.Summary.FileCount, o.Summary.SymbolCount)
+	if o.Freshness.IsStale {
+		fmt.Fprintf(&b, "\n> The index was stale when this was generated (%s); run `recon sync` and regenerate it.\n", o.Freshness.Reason)
+	}
+
+	b.WriteString("\n## Where to start\n\n")
+	if len(o.Architecture.EntryPoints) == 0 {
+		b.WriteString("The module has no `main` package; it is a library.")
+		if len(o.Modules) > 0 {
+			b.WriteString(" Start with the key packages below.")
+		}
+		b.WriteString("\n")
+	} else {
+		b.WriteString("Execution starts in the `main` packages:\n\n")
+		for _, e := range o.Architecture.EntryPoints {
+			fmt.Fprintf(&b, "- `%s`\n", e)
+		}
+	}
+
+	if len(o.Modules) > 0 {
+		b.WriteString("\n## Key packages\n\n")
+		b.WriteString("The largest packages, with the first sentence of their package documentation.\n")
+		b.WriteString("Upstream packages are imported widely, so changes there reach the most code.\n\n")
+		b.WriteString("| Package | Purpose | Files | Flow |\n")
+		b.WriteString("| ------- | ------- | ----: | ---- |\n")
+		for _, m := range o.Modules {
+			purpose := o.Purposes[m.Path]
+			if purpose == "" {
+				purpose = "—"
+			}
+			flow := m.Flow
+			if flow == "" {
+				flow = "—"
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %d | %s |\n", m.Path, strings.ReplaceAll(purpose, "|", `\|`), m.FileCount, flow)
+		}
+	}
+
+	if len(o.Constraints) > 0 {
+		b.WriteString("\n## Rules that must hold\n\n")
+		for _, c := range o.Constraints {
+			fmt.Fprintf(&b, "- **%s** (#%d%s)", c.Title, c.ID, driftNote(c.Drift))
+			if why := firstLine(c.Reasoning); why != "" {
+				fmt.Fprintf(&b, " — %s", why)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(o.ActiveDecisions) > 0 {
+		decisions := append([]DecisionDigest(nil), o.ActiveDecisions...)
+		sort.Slice(decisions, func(i, j int) bool { return decisions[i].ID < decisions[j].ID })
+
+		b.WriteString("\n## Decisions to know\n\n")
+		for _, d := range decisions {
+			fmt.Fprintf(&b, "- %s %s confidence%s)", DecisionMarker(d.ID, d.Title), d.Confidence, driftNote(d.Drift))
+			if why := firstLine(d.Reasoning); why != "" {
+				fmt.Fprintf(&b, " — %s", why)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(o.ActivePatterns) > 0 {
+		patterns := append([]PatternDigest(nil), o.ActivePatterns...)
+		sort.Slice(patterns, func(i, j int) bool { return patterns[i].ID < patterns[j].ID })
+
+		b.WriteString("\n## Conventions\n\n")
+		for _, p := range patterns {
+			fmt.Fprintf(&b, "- **%s** (#%d%s)", p.Title, p.ID, driftNote(p.Drift))
+			if why := firstLine(p.Reasoning); why != "" {
+				fmt.Fprintf(&b, " — %s", why)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n## Running the tests\n\n")
+	fmt.Fprintf(&b, "%d test files across %d packages.\n\n", o.Testing.TestFiles, o.Testing.TestPackages)
+	for _, c := range o.Testing.Commands {
+		fmt.Fprintf(&b, "- `%s`\n", c)
+	}
+	b.WriteString("- `go vet ./...`\n")
+
+	b.WriteString("\n## Finding your way\n\n")
+	b.WriteString("- Project context: `recon orient`\n")
+	b.WriteString("- Look up a symbol: `recon find <Symbol>`\n")
+	b.WriteString("- Why code is the way it is: `recon why <path or symbol>`\n")
+	b.WriteString("- Prior decisions on a topic: `recon recall \"<topic>\"`\n")
+
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package orient
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/index"
+)
+
+func TestBuildOnboarding(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+	write("go.mod", "module example.com/shop\n")
+	write("cmd/shop/main.go", "// Command shop serves the store.\npackage main\n\nfunc main() {}\n")
+	write("store/a.go", "// Package store is not documented here.\npackage store\n")
+	write("store/doc.go", "// Package store persists orders. It uses SQLite.\npackage store\n")
+	write("store/store_test.go", "package store\n")
+	write("store/more_test.go", "package store\n")
+	write("util/util.go", "package util\n")
+	write("justfile", "set shell := [\"bash\", \"-cu\"]\n\ntest:\n    go test ./...\n\ntest-race *args:\n    go test -race ./...\n\nbuild:\n    go build ./...\n")
+
+	conn := setupOrientDB(t, root)
+	defer conn.Close()
+	if _, err := index.NewService(conn).Sync(context.Background(), root); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	o, err := NewService(conn).BuildOnboarding(context.Background(), BuildOptions{ModuleRoot: root, MaxModules: 10, MaxDecisions: 5})
+	if err != nil {
+		t.Fatalf("BuildOnboarding: %v", err)
+	}
+	if got := o.Purposes["store"]; got != "Package store persists orders." {
+		t.Fatalf("store purpose = %q, want the doc.go synopsis", got)
+	}
+	if _, ok := o.Purposes["util"]; ok {
+		t.Fatalf("undocumented package should have no purpose: %v", o.Purposes)
+	}
+	if o.Testing.TestFiles != 2 || o.Testing.TestPackages != 1 {
+		t.Fatalf("testing = %+v, want 2 files in 1 package", o.Testing)
+	}
+	if got := strings.Join(o.Testing.Commands, ","); got != "go test ./...,just test,just test-race" {
+		t.Fatalf("commands = %q", got)
+	}
+
+	doc := RenderOnboarding(o)
+	for _, needle := range []string{
+		"# Onboarding: ",
+		"`example.com/shop` is a Go module with 3 packages",
+		"Execution starts in the `main` packages:\n\n- `cmd/shop/main.go`",
+		"| `store` | Package store persists orders. | 2 |",
+		"| `util` | — | 1 |",
+		"2 test files across 1 packages.",
+		"- `just test-race`\n- `go vet ./...`",
+	} {
+		if !strings.Contains(doc, needle) {
+			t.Fatalf("onboarding missing %q:\n%s", needle, doc)
+		}
+	}
+}
+
+func TestRenderOnboardingKnowledge(t *testing.T) {
+	got := RenderOnboarding(Onboarding{
+		Payload: Payload{
+			Project:         ProjectInfo{Name: "lib", ModulePath: "example.com/lib"},
+			Freshness:       Freshness{IsStale: true, Reason: "never_synced"},
+			Constraints:     []ConstraintDigest{{ID: 3, Title: "No cgo", Reasoning: "Static builds.", Drift: "broken"}},
+			ActiveDecisions: []DecisionDigest{{ID: 2, Title: "Use SQLite", Reasoning: "Single file.", Confidence: "high", Drift: "ok"}},
+			ActivePatterns:  []PatternDigest{{ID: 1, Title: "Wrap errors", Confidence: "medium", Drift: "ok"}},
+		},
+		Testing: Testing{Commands: []string{"go test ./..."}},
+	})
+	for _, needle := range []string{
+		"(never_synced); run `recon sync`",
+		"no `main` package; it is a library.\n",
+		"## Rules that must hold\n\n- **No cgo** (#3, evidence broken) — Static builds.",
+		"## Decisions to know\n\n- **Use SQLite** (#2, high confidence) — Single file.",
+		"## Conventions\n\n- **Wrap errors** (#1)",
+	} {
+		if !strings.Contains(got, needle) {
+			t.Fatalf("onboarding missing %q:\n%s", needle, got)
+		}
+	}
+	if strings.Contains(got, "## Key packages") {
+		t.Fatalf("no key packages section expected without modules:\n%s", got)
+	}
+}
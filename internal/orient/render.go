@@ -2,7 +2,10 @@ package orient
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+
+	"github.com/robertguss/recon/internal/coverage"
 )
 
 func RenderText(payload Payload) string {
@@ -61,6 +64,32 @@ func RenderText(payload Payload) string {
 		if payload.Freshness.LastSyncAt != "" {
 			fmt.Fprintf(&b, "Last sync: %s\n", payload.Freshness.LastSyncAt)
 		}
+		if files := payload.Freshness.ChangedFiles; len(files) > 0 {
+			fmt.Fprintf(&b, "Changed since last sync: %s", strings.Join(files, ", "))
+			if payload.Freshness.ChangedFilesTruncated {
+				b.WriteString(", ...")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if f := payload.Focus; f != nil {
+		renderFocus(&b, f, payload.Summary.FileCount)
+	}
+
+	if len(payload.Constraints) > 0 {
+		b.WriteString("Constraints (must hold):\n")
+		for _, c := range payload.Constraints {
+			fmt.Fprintf(&b, "- #%d %s [%s]", c.ID, c.Title, c.Confidence)
+			if c.Drift != "ok" {
+				fmt.Fprintf(&b, " %s", strings.ToUpper(c.Drift))
+			}
+			b.WriteString("\n")
+			if c.Reasoning != "" {
+				fmt.Fprintf(&b, "  Why: %s\n", c.Reasoning)
+			}
+		}
 		b.WriteString("\n")
 	}
 
@@ -70,13 +99,36 @@ func RenderText(payload Payload) string {
 		payload.Summary.PackageCount,
 		payload.Summary.DecisionCount,
 	)
+	if sum := payload.Summary; sum.CgoFiles > 0 || sum.UnsafeFiles > 0 {
+		fmt.Fprintf(&b, "Handle with care: %d cgo files, %d files importing unsafe (recon find --unsafe --kind func)\n\n", sum.CgoFiles, sum.UnsafeFiles)
+	}
+	if n := payload.Summary.GeneratedFiles; n > 0 {
+		fmt.Fprintf(&b, "Generated or vendored: %d files, not counted above (recon orient --include-generated)\n\n", n)
+	}
 
 	b.WriteString("Modules:\n")
 	if len(payload.Modules) == 0 {
 		b.WriteString("- (none)\n")
 	} else {
 		for _, m := range payload.Modules {
-			fmt.Fprintf(&b, "- %s (%s): %d files, %d lines [%s]\n", m.Path, m.Name, m.FileCount, m.LineCount, strings.ToUpper(m.Heat))
+			fmt.Fprintf(&b, "- %s (%s): %d files, %d lines [%s]", m.Path, m.Name, m.FileCount, m.LineCount, strings.ToUpper(m.Heat))
+			if m.Flow != "" {
+				fmt.Fprintf(&b, " %s (imported by %d, imports %d)", m.Flow, m.ImportedBy, m.Imports)
+			}
+			if m.Coverage != nil {
+				fmt.Fprintf(&b, " coverage=%.1f%%", m.Coverage.Percent)
+			}
+			if len(m.Owners) > 0 {
+				fmt.Fprintf(&b, " owners=%s", strings.Join(m.Owners, ","))
+			}
+			if len(m.Contributors) > 0 {
+				names := make([]string, len(m.Contributors))
+				for i, c := range m.Contributors {
+					names[i] = fmt.Sprintf("%s(%d)", c.Name, c.Commits)
+				}
+				fmt.Fprintf(&b, " contributors=%s", strings.Join(names, ","))
+			}
+			b.WriteString("\n")
 			for _, k := range m.Knowledge {
 				conf := k.Confidence
 				if k.EdgeConfidence != "" && k.EdgeConfidence != k.Confidence {
@@ -85,15 +137,45 @@ func RenderText(payload Payload) string {
 				fmt.Fprintf(&b, "    %s #%d: %s [%s]\n", k.Type, k.ID, k.Title, conf)
 			}
 		}
+		if h := payload.Heat; h.WindowDays > 0 {
+			fmt.Fprintf(&b, "Heat counts commits in the last %d days: HOT at %d or more, WARM at %d or more.\n", h.WindowDays, h.Hot, h.Warm)
+		}
 	}
 	b.WriteString("\n")
 
-	b.WriteString("Active decisions:\n")
+	if len(payload.CoverageGaps) > 0 {
+		fmt.Fprintf(&b, "Coverage gaps (hot modules under %.0f%% statement coverage):\n", coverage.LowThreshold)
+		for _, g := range payload.CoverageGaps {
+			fmt.Fprintf(&b, "- %s: %.1f%% of %d statements, %d recent commits\n", g.Path, g.Coverage.Percent, g.Coverage.Statements, g.RecentCommits)
+			if len(g.LeastCovered) > 0 {
+				parts := make([]string, 0, len(g.LeastCovered))
+				for _, fn := range g.LeastCovered {
+					label := fn.Name
+					if fn.Receiver != "" {
+						label = fn.Receiver + "." + fn.Name
+					}
+					parts = append(parts, fmt.Sprintf("%s (%d/%d)", label, fn.Coverage.Covered, fn.Coverage.Statements))
+				}
+				fmt.Fprintf(&b, "    least covered: %s\n", strings.Join(parts, ", "))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if payload.MinConfidence != "" {
+		fmt.Fprintf(&b, "Active decisions (confidence >= %s; `recon recall` finds the rest):\n", payload.MinConfidence)
+	} else {
+		b.WriteString("Active decisions:\n")
+	}
 	if len(payload.ActiveDecisions) == 0 {
 		b.WriteString("- (none)\n")
 	} else {
 		for _, d := range payload.ActiveDecisions {
-			fmt.Fprintf(&b, "- #%d %s [%s] drift=%s updated=%s\n", d.ID, d.Title, d.Confidence, d.Drift, d.UpdatedAt)
+			fmt.Fprintf(&b, "- #%d %s [%s] drift=%s updated=%s", d.ID, d.Title, d.Confidence, d.Drift, d.UpdatedAt)
+			if d.Branch != "" {
+				fmt.Fprintf(&b, " branch=%s", d.Branch)
+			}
+			b.WriteString("\n")
 			if d.Reasoning != "" {
 				fmt.Fprintf(&b, "  Why: %s\n", d.Reasoning)
 			}
@@ -113,7 +195,11 @@ func RenderText(payload Payload) string {
 	if len(payload.RecentActivity) > 0 {
 		b.WriteString("\nRecent activity:\n")
 		for _, a := range payload.RecentActivity {
-			fmt.Fprintf(&b, "- %s (%s)\n", a.File, a.LastModified)
+			fmt.Fprintf(&b, "- %s (%s)", a.File, a.LastModified)
+			if a.Commit != "" {
+				fmt.Fprintf(&b, " %s %s: %s", a.Commit, a.Author, a.Subject)
+			}
+			b.WriteString("\n")
 		}
 	}
 
@@ -126,3 +212,149 @@ func RenderText(payload Payload) string {
 
 	return strings.TrimSpace(b.String()) + "\n"
 }
+
+func renderFocus(b *strings.Builder, f *Focus, fileCount int) {
+	fmt.Fprintf(b, "Focus: %s\n", f.Path)
+	kinds := make([]string, 0, len(f.Symbols.ByKind))
+	for kind, n := range f.Symbols.ByKind {
+		kinds = append(kinds, fmt.Sprintf("%s=%d", kind, n))
+	}
+	sort.Strings(kinds)
+	fmt.Fprintf(b, "Symbols: %d (%d exported)", f.Symbols.Total, f.Symbols.Exported)
+	if len(kinds) > 0 {
+		fmt.Fprintf(b, " %s", strings.Join(kinds, " "))
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(b, "Depends on: %s\n", joinOrNone(f.Dependencies))
+	fmt.Fprintf(b, "Depended on by: %s\n", joinOrNone(f.Dependents))
+	if len(f.Files) > 0 {
+		if fileCount > len(f.Files) {
+			fmt.Fprintf(b, "Files (first %d of %d):\n", len(f.Files), fileCount)
+		} else {
+			b.WriteString("Files:\n")
+		}
+		for _, file := range f.Files {
+			fmt.Fprintf(b, "- %s\n", file)
+		}
+	}
+	b.WriteString("\n")
+}
+
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "(none)"
+	}
+	return strings.Join(items, ", ")
+}
+
+// DecisionMarker is how RenderAgentsMD lists a decision, up to its
+// confidence. Its presence in a file means the decision was rendered there.
+func DecisionMarker(id int64, title string) string {
+	return fmt.Sprintf("**%s** (#%d,", title, id)
+}
+
+// RenderAgentsMD renders the payload as the recon section of an AGENTS.md
+// file. Volatile fields (heat, recent activity, timestamps, line counts) are
+// left out so that regenerating from an unchanged index produces identical
+// output and a hook does not rewrite the file on every run.
+func RenderAgentsMD(payload Payload) string {
+	var b strings.Builder
+
+	b.WriteString("## Recon Project Map\n\n")
+	b.WriteString("<!-- Generated by `recon agents-md` from the recon index. Edits inside this section are overwritten. -->\n\n")
+	fmt.Fprintf(&b, "Go module `%s`: %d packages, %d files, %d symbols indexed.\n",
+		payload.Project.ModulePath,
+		payload.Summary.PackageCount,
+		payload.Summary.FileCount,
+		payload.Summary.SymbolCount,
+	)
+
+	if len(payload.Architecture.EntryPoints) > 0 {
+		b.WriteString("\n### Entry points\n\n")
+		for _, e := range payload.Architecture.EntryPoints {
+			fmt.Fprintf(&b, "- `%s`\n", e)
+		}
+	}
+
+	if len(payload.Modules) > 0 {
+		deps := map[string][]string{}
+		for _, edge := range payload.Architecture.DependencyFlow {
+			deps[edge.From] = edge.To
+		}
+		modules := append([]ModuleSummary(nil), payload.Modules...)
+		sort.Slice(modules, func(i, j int) bool { return modules[i].Path < modules[j].Path })
+
+		b.WriteString("\n### Module map\n\n")
+		b.WriteString("| Package | Name | Files | Flow | Depends on |\n")
+		b.WriteString("| ------- | ---- | ----: | ---- | ---------- |\n")
+		for _, m := range modules {
+			dependsOn := "—"
+			if to := deps[m.Path]; len(to) > 0 {
+				dependsOn = "`" + strings.Join(to, "`, `") + "`"
+			}
+			flow := m.Flow
+			if flow == "" {
+				flow = "—"
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %d | %s | %s |\n", m.Path, m.Name, m.FileCount, flow, dependsOn)
+		}
+	}
+
+	if len(payload.ActiveDecisions) > 0 {
+		decisions := append([]DecisionDigest(nil), payload.ActiveDecisions...)
+		sort.Slice(decisions, func(i, j int) bool { return decisions[i].ID < decisions[j].ID })
+
+		b.WriteString("\n### Active decisions\n\n")
+		for _, d := range decisions {
+			fmt.Fprintf(&b, "- %s %s confidence%s)", DecisionMarker(d.ID, d.Title), d.Confidence, driftNote(d.Drift))
+			if why := firstLine(d.Reasoning); why != "" {
+				fmt.Fprintf(&b, " — %s", why)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(payload.ActivePatterns) > 0 {
+		patterns := append([]PatternDigest(nil), payload.ActivePatterns...)
+		sort.Slice(patterns, func(i, j int) bool { return patterns[i].ID < patterns[j].ID })
+
+		b.WriteString("\n### Active patterns\n\n")
+		for _, p := range patterns {
+			fmt.Fprintf(&b, "- **%s** (#%d, %s confidence%s)", p.Title, p.ID, p.Confidence, driftNote(p.Drift))
+			if why := firstLine(p.Reasoning); why != "" {
+				fmt.Fprintf(&b, " — %s", why)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n### Commands\n\n")
+	b.WriteString("- Build: `go build ./...`\n")
+	b.WriteString("- Test: `go test ./...`\n")
+	b.WriteString("- Vet: `go vet ./...`\n")
+	b.WriteString("- Project context: `recon orient --json --auto-sync`\n")
+	b.WriteString("- Look up a symbol: `recon find <Symbol>`\n")
+	b.WriteString("- Prior decisions: `recon recall \"<topic>\"`\n")
+	b.WriteString("- Refresh this section: `recon agents-md`\n")
+
+	return b.String()
+}
+
+func driftNote(drift string) string {
+	if drift == "" || drift == "ok" {
+		return ""
+	}
+	return ", evidence " + drift
+}
+
+// firstLine returns the first line of s, trimmed and capped for a list item.
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		s = strings.TrimSpace(s[:idx])
+	}
+	if r := []rune(s); len(r) > 160 {
+		s = string(r[:157]) + "..."
+	}
+	return s
+}
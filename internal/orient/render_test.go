@@ -124,9 +124,75 @@ func TestRenderTextDecisionWithoutReasoning(t *testing.T) {
 	}
 }
 
+func TestRenderTextConstraintsLeadKnowledge(t *testing.T) {
+	payload := Payload{
+		Project: ProjectInfo{Name: "x", ModulePath: "m", Language: "go"},
+		Constraints: []ConstraintDigest{
+			{ID: 1, Title: "Never import internal/db from cmd", Confidence: "high", Drift: "ok", Reasoning: "cmd only wires the CLI"},
+			{ID: 2, Title: "No panics in library code", Confidence: "medium", Drift: "broken"},
+		},
+		ActiveDecisions: []DecisionDigest{{ID: 1, Title: "Use Cobra", Confidence: "high", Drift: "ok", UpdatedAt: "now"}},
+	}
+	got := RenderText(payload)
+	for _, want := range []string{
+		"Constraints (must hold):\n- #1 Never import internal/db from cmd [high]\n  Why: cmd only wires the CLI\n",
+		"- #2 No panics in library code [medium] BROKEN\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output: %s", want, got)
+		}
+	}
+	if strings.Index(got, "Constraints (must hold):") > strings.Index(got, "Summary:") {
+		t.Fatalf("expected constraints before the summary: %s", got)
+	}
+}
+
 func TestRenderTextEmptySections(t *testing.T) {
 	got := RenderText(Payload{Project: ProjectInfo{Name: "x", ModulePath: "m", Language: "go"}})
 	if !strings.Contains(got, "- (none)") {
 		t.Fatalf("expected empty markers in output: %s", got)
 	}
 }
+
+func TestRenderAgentsMD(t *testing.T) {
+	payload := Payload{
+		Project:      ProjectInfo{Name: "recon", ModulePath: "example.com/recon", Language: "go"},
+		Summary:      Summary{FileCount: 4, SymbolCount: 20, PackageCount: 2},
+		Architecture: Architecture{EntryPoints: []string{"cmd/recon"}, DependencyFlow: []DependencyEdge{{From: "internal/cli", To: []string{"internal/db"}}}},
+		Modules: []ModuleSummary{
+			{Path: "internal/db", Name: "db", FileCount: 1, LineCount: 10, Heat: "hot", RecentCommits: 9, Flow: FlowUpstream, ImportedBy: 1},
+			{Path: "internal/cli", Name: "cli", FileCount: 3, LineCount: 50, Heat: "cold"},
+		},
+		ActiveDecisions: []DecisionDigest{
+			{ID: 7, Title: "Later", Confidence: "low", Drift: "drifting", UpdatedAt: "2026-02-01"},
+			{ID: 2, Title: "Use SQLite", Reasoning: "Single file.\nMore detail.", Confidence: "high", Drift: "ok", UpdatedAt: "2026-01-01"},
+		},
+		ActivePatterns: []PatternDigest{{ID: 1, Title: "Wrap errors", Confidence: "medium", Drift: "ok"}},
+		RecentActivity: []RecentFile{{File: "x.go", LastModified: "2026-03-01"}},
+	}
+
+	got := RenderAgentsMD(payload)
+	if !strings.HasPrefix(got, "## Recon Project Map\n") {
+		t.Fatalf("expected section heading first:\n%s", got)
+	}
+	for _, needle := range []string{
+		"Go module `example.com/recon`: 2 packages, 4 files, 20 symbols indexed.",
+		"- `cmd/recon`",
+		"| `internal/cli` | cli | 3 | — | `internal/db` |\n| `internal/db` | db | 1 | upstream | — |",
+		"- **Use SQLite** (#2, high confidence) — Single file.\n- **Later** (#7, low confidence, evidence drifting)",
+		"- **Wrap errors** (#1, medium confidence)",
+		"- Refresh this section: `recon agents-md`",
+	} {
+		if !strings.Contains(got, needle) {
+			t.Fatalf("agents section missing %q:\n%s", needle, got)
+		}
+	}
+	for _, volatile := range []string{"HOT", "x.go", "2026-", "More detail"} {
+		if strings.Contains(got, volatile) {
+			t.Fatalf("agents section should not contain %q:\n%s", volatile, got)
+		}
+	}
+	if strings.Contains(got, "\n## ") {
+		t.Fatalf("section must not contain another level-2 heading:\n%s", got)
+	}
+}
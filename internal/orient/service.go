@@ -4,37 +4,83 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"os/exec"
+	"log/slog"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/robertguss/recon/internal/coverage"
 	"github.com/robertguss/recon/internal/db"
 	"github.com/robertguss/recon/internal/index"
+	"github.com/robertguss/recon/internal/owners"
 )
 
 type BuildOptions struct {
 	ModuleRoot   string
 	MaxModules   int
 	MaxDecisions int
+	// MaxRecent caps the recently changed files listed. Zero or less lists 5.
+	MaxRecent int
+	// Focus scopes the payload to one package subtree, such as
+	// "internal/index". Empty means the whole module.
+	Focus string
+	// Branch is the checked-out git branch. Decisions scoped to another
+	// branch are left out; empty leaves out every scoped decision.
+	Branch string
+	// MinConfidence leaves out decisions and patterns below this level:
+	// "low", "medium", or "high". Empty keeps them all.
+	MinConfidence string
+	// IncludeGenerated counts generated and vendored files in the summary
+	// and lists packages made only of them among the modules.
+	IncludeGenerated bool
+	// Heat classifies module heat. Zero fields take index.DefaultHeatPolicy.
+	Heat index.HeatPolicy
+	// ExecTimeout limits each git call made while building the payload.
+	// Zero keeps the limit ctx carries, or index.DefaultExecTimeout, and a
+	// negative value disables it.
+	ExecTimeout time.Duration
 }
 
 type Payload struct {
-	Project         ProjectInfo      `json:"project"`
-	Architecture    Architecture     `json:"architecture"`
-	Freshness       Freshness        `json:"freshness"`
-	Summary         Summary          `json:"summary"`
-	Modules         []ModuleSummary  `json:"modules"`
-	ActiveDecisions []DecisionDigest `json:"active_decisions"`
-	ActivePatterns  []PatternDigest  `json:"active_patterns"`
-	RecentActivity  []RecentFile     `json:"recent_activity"`
-	Warnings        []string         `json:"warnings,omitempty"`
+	Project         ProjectInfo        `json:"project"`
+	Architecture    Architecture       `json:"architecture"`
+	Freshness       Freshness          `json:"freshness"`
+	Summary         Summary            `json:"summary"`
+	Modules         []ModuleSummary    `json:"modules"`
+	Constraints     []ConstraintDigest `json:"constraints"`
+	ActiveDecisions []DecisionDigest   `json:"active_decisions"`
+	ActivePatterns  []PatternDigest    `json:"active_patterns"`
+	RecentActivity  []RecentFile       `json:"recent_activity"`
+	CoverageGaps    []CoverageGap      `json:"coverage_gaps,omitempty"`
+	Focus           *Focus             `json:"focus,omitempty"`
+	Warnings        []string           `json:"warnings,omitempty"`
+	// Heat is the window and thresholds the modules' heat was computed with.
+	Heat index.HeatPolicy `json:"heat"`
+	// MinConfidence is set when decisions and patterns below it were left
+	// out of the payload.
+	MinConfidence string `json:"min_confidence,omitempty"`
 }
 
+// CoverageGap is a hot module whose imported statement coverage is below
+// coverage.LowThreshold, with the functions that leave the most statements
+// uncovered.
+type CoverageGap struct {
+	Path          string               `json:"path"`
+	RecentCommits int                  `json:"recent_commits"`
+	Coverage      coverage.Coverage    `json:"coverage"`
+	LeastCovered  []coverage.SymbolGap `json:"least_covered"`
+}
+
+// RecentFile is a recently changed file and the newest commit that
+// changed it.
 type RecentFile struct {
 	File         string `json:"file"`
 	LastModified string `json:"last_modified"`
+	Commit       string `json:"commit"`
+	Author       string `json:"author"`
+	Subject      string `json:"subject"`
 }
 
 type DependencyEdge struct {
@@ -60,13 +106,28 @@ type Freshness struct {
 	LastSyncCommit string `json:"last_sync_commit,omitempty"`
 	CurrentCommit  string `json:"current_commit,omitempty"`
 	StaleSummary   string `json:"stale_summary,omitempty"`
+	// ChangedFiles lists the module files that differ from the last synced
+	// commit, committed or not, capped at maxChangedFiles.
+	ChangedFiles          []string `json:"changed_files,omitempty"`
+	ChangedFilesTruncated bool     `json:"changed_files_truncated,omitempty"`
 }
 
+// maxChangedFiles caps Freshness.ChangedFiles so a large rebase does not
+// flood the payload.
+const maxChangedFiles = 50
+
 type Summary struct {
 	FileCount     int `json:"file_count"`
 	SymbolCount   int `json:"symbol_count"`
 	PackageCount  int `json:"package_count"`
 	DecisionCount int `json:"decision_count"`
+	// CgoFiles and UnsafeFiles count the files importing "C" and "unsafe",
+	// which `recon find --unsafe` lists symbol by symbol.
+	CgoFiles    int `json:"cgo_files"`
+	UnsafeFiles int `json:"unsafe_files"`
+	// GeneratedFiles counts the generated and vendored files left out of
+	// the counts above; it is zero with BuildOptions.IncludeGenerated.
+	GeneratedFiles int `json:"generated_files,omitempty"`
 }
 
 type ModuleKnowledge struct {
@@ -77,14 +138,39 @@ type ModuleKnowledge struct {
 	EdgeConfidence string `json:"edge_confidence"`
 }
 
+// Module flow roles, from a package's place in the module's import graph.
+const (
+	// FlowUpstream packages are imported by at least as many module packages
+	// as they import: foundations whose changes ripple to their importers.
+	FlowUpstream = "upstream"
+	// FlowDownstream packages import more module packages than import them:
+	// consumers such as commands and wiring.
+	FlowDownstream = "downstream"
+	// FlowLeaf packages neither import nor are imported by other module
+	// packages.
+	FlowLeaf = "leaf"
+)
+
+// ModuleSummary is a package in the module map. ImportedBy and Imports count
+// the other module packages that import it and that it imports; Flow
+// classifies it from those counts.
 type ModuleSummary struct {
-	Path          string            `json:"path"`
-	Name          string            `json:"name"`
-	FileCount     int               `json:"file_count"`
-	LineCount     int               `json:"line_count"`
-	Heat          string            `json:"heat"`
-	RecentCommits int               `json:"recent_commits"`
-	Knowledge     []ModuleKnowledge `json:"knowledge,omitempty"`
+	Path          string             `json:"path"`
+	Name          string             `json:"name"`
+	FileCount     int                `json:"file_count"`
+	LineCount     int                `json:"line_count"`
+	Heat          string             `json:"heat"`
+	RecentCommits int                `json:"recent_commits"`
+	Flow          string             `json:"flow"`
+	ImportedBy    int                `json:"imported_by"`
+	Imports       int                `json:"imports"`
+	Coverage      *coverage.Coverage `json:"coverage,omitempty"`
+	Owners        []string           `json:"owners,omitempty"`
+	// Contributors are the authors of the module's commits within the heat
+	// window, most commits first, so an agent knows whose conventions to
+	// follow.
+	Contributors []index.Contributor `json:"contributors,omitempty"`
+	Knowledge    []ModuleKnowledge   `json:"knowledge,omitempty"`
 }
 
 type DecisionDigest struct {
@@ -94,6 +180,8 @@ type DecisionDigest struct {
 	Confidence string `json:"confidence"`
 	UpdatedAt  string `json:"updated_at"`
 	Drift      string `json:"drift_status"`
+	// Branch is set for a decision scoped to the current git branch.
+	Branch string `json:"branch,omitempty"`
 }
 
 type PatternDigest struct {
@@ -105,8 +193,24 @@ type PatternDigest struct {
 	Drift      string `json:"drift_status"`
 }
 
+// ConstraintDigest is an active constraint: a hard rule every change must
+// keep. Constraints are listed whatever the focus, since a rule holds across
+// the whole module.
+type ConstraintDigest struct {
+	ID         int64  `json:"id"`
+	Title      string `json:"title"`
+	Reasoning  string `json:"reasoning,omitempty"`
+	Confidence string `json:"confidence"`
+	Drift      string `json:"drift_status"`
+}
+
 type Service struct {
 	db *sql.DB
+	// branch, minConfidence, and includeGenerated are the BuildOptions
+	// fields of the same name for the loaders Build runs.
+	branch           string
+	minConfidence    string
+	includeGenerated bool
 }
 
 func NewService(conn *sql.DB) *Service {
@@ -114,6 +218,11 @@ func NewService(conn *sql.DB) *Service {
 }
 
 func (s *Service) Build(ctx context.Context, opts BuildOptions) (Payload, error) {
+	start := time.Now()
+	defer func() { slog.DebugContext(ctx, "orient", "duration", time.Since(start)) }()
+	if opts.ExecTimeout != 0 {
+		ctx = index.WithExecTimeout(ctx, opts.ExecTimeout)
+	}
 	modulePath, err := index.ModulePath(opts.ModuleRoot)
 	if err != nil {
 		return Payload{}, err
@@ -126,57 +235,102 @@ func (s *Service) Build(ctx context.Context, opts BuildOptions) (Payload, error)
 			Language:   "go",
 		},
 		Modules:         []ModuleSummary{},
+		Constraints:     []ConstraintDigest{},
 		ActiveDecisions: []DecisionDigest{},
 		ActivePatterns:  []PatternDigest{},
 		RecentActivity:  []RecentFile{},
+		Heat:            opts.Heat.OrDefault(),
 	}
 
 	if opts.MaxModules <= 0 {
 		opts.MaxModules = 8
 	}
+	if opts.MaxRecent <= 0 {
+		opts.MaxRecent = 5
+	}
 	if opts.MaxDecisions <= 0 {
 		opts.MaxDecisions = 5
 	}
 
-	if err := s.loadSummary(ctx, &payload); err != nil {
-		return Payload{}, err
-	}
-	if err := s.loadModules(ctx, opts.MaxModules, &payload); err != nil {
-		return Payload{}, err
+	if _, ok := confidenceRank[opts.MinConfidence]; !ok && opts.MinConfidence != "" {
+		return Payload{}, fmt.Errorf("min confidence must be low, medium, or high")
 	}
-	if err := s.loadDecisions(ctx, opts.MaxDecisions, &payload); err != nil {
-		return Payload{}, err
+	s.branch = opts.Branch
+	s.minConfidence = opts.MinConfidence
+	s.includeGenerated = opts.IncludeGenerated
+	payload.MinConfidence = opts.MinConfidence
+	focus := NormalizeFocus(opts.Focus)
+	if focus != "" {
+		if err := s.loadFocus(ctx, focus, opts.MaxDecisions, &payload); err != nil {
+			return Payload{}, err
+		}
+	} else {
+		if err := s.loadSummary(ctx, &payload); err != nil {
+			return Payload{}, err
+		}
+		if err := s.loadModules(ctx, opts.MaxModules, &payload); err != nil {
+			return Payload{}, err
+		}
+		if err := s.loadDecisions(ctx, opts.MaxDecisions, &payload); err != nil {
+			return Payload{}, err
+		}
+		if err := s.loadPatterns(ctx, 5, &payload); err != nil {
+			return Payload{}, err
+		}
 	}
-	if err := s.loadPatterns(ctx, 5, &payload); err != nil {
+	if err := s.loadConstraints(ctx, &payload); err != nil {
 		return Payload{}, err
 	}
 	if err := s.loadArchitecture(ctx, &payload); err != nil {
 		return Payload{}, err
 	}
+	if focus != "" {
+		scopeArchitecture(&payload)
+	}
 	s.loadModuleEdges(ctx, &payload)
 	s.loadModuleHeat(ctx, opts.ModuleRoot, &payload)
-	s.loadRecentActivity(ctx, opts.ModuleRoot, &payload)
+	s.loadModuleCoverage(ctx, &payload)
+	s.loadModuleOwners(ctx, &payload)
+	s.loadRecentActivity(ctx, opts.ModuleRoot, focus, opts.MaxRecent, &payload)
 
-	state, exists, err := db.LoadSyncState(ctx, s.db)
+	freshness, warnings, err := s.Freshness(ctx, opts.ModuleRoot)
 	if err != nil {
 		return Payload{}, err
 	}
-	currentCommit, currentDirty := index.CurrentGitState(ctx, opts.ModuleRoot)
+	payload.Freshness = freshness
+	payload.Warnings = append(payload.Warnings, warnings...)
+
+	return payload, nil
+}
+
+// Freshness reports whether the index is stale against the module's git
+// state and worktree, and why. Warnings note checks that could not run.
+func (s *Service) Freshness(ctx context.Context, moduleRoot string) (Freshness, []string, error) {
+	state, exists, err := db.LoadSyncState(ctx, s.db)
+	if err != nil {
+		return Freshness{}, nil, err
+	}
+	currentCommit, currentDirty := index.CurrentGitState(ctx, moduleRoot)
+
+	var (
+		freshness Freshness
+		warnings  []string
+	)
 
 	switch {
 	case !exists:
-		payload.Freshness = Freshness{IsStale: true, Reason: "never_synced", CurrentCommit: currentCommit}
+		freshness = Freshness{IsStale: true, Reason: "never_synced", CurrentCommit: currentCommit}
 	case state.LastSyncCommit != "" && currentCommit != "" && state.LastSyncCommit != currentCommit:
-		payload.Freshness = Freshness{
+		freshness = Freshness{
 			IsStale:        true,
 			Reason:         "git_head_changed_since_last_sync",
 			LastSyncAt:     state.LastSyncAt.Format(time.RFC3339),
 			LastSyncCommit: state.LastSyncCommit,
 			CurrentCommit:  currentCommit,
-			StaleSummary:   computeStaleSummary(ctx, opts.ModuleRoot, state.LastSyncCommit, currentCommit),
+			StaleSummary:   computeStaleSummary(ctx, moduleRoot, state.LastSyncCommit, currentCommit),
 		}
 	case state.LastSyncDirty != currentDirty:
-		payload.Freshness = Freshness{
+		freshness = Freshness{
 			IsStale:        true,
 			Reason:         "git_dirty_state_changed_since_last_sync",
 			LastSyncAt:     state.LastSyncAt.Format(time.RFC3339),
@@ -184,10 +338,10 @@ func (s *Service) Build(ctx context.Context, opts BuildOptions) (Payload, error)
 			CurrentCommit:  currentCommit,
 		}
 	default:
-		fingerprint, _, err := index.CurrentFingerprint(opts.ModuleRoot)
+		fingerprint, _, err := index.CurrentFingerprint(moduleRoot)
 		if err != nil {
-			payload.Warnings = append(payload.Warnings, fmt.Sprintf("fingerprint check failed: %v", err))
-			payload.Freshness = Freshness{
+			warnings = append(warnings, fmt.Sprintf("fingerprint check failed: %v", err))
+			freshness = Freshness{
 				IsStale:        false,
 				Reason:         "",
 				LastSyncAt:     state.LastSyncAt.Format(time.RFC3339),
@@ -195,7 +349,7 @@ func (s *Service) Build(ctx context.Context, opts BuildOptions) (Payload, error)
 				CurrentCommit:  currentCommit,
 			}
 		} else if fingerprint != state.IndexFingerprint {
-			payload.Freshness = Freshness{
+			freshness = Freshness{
 				IsStale:        true,
 				Reason:         "worktree_fingerprint_changed_since_last_sync",
 				LastSyncAt:     state.LastSyncAt.Format(time.RFC3339),
@@ -203,7 +357,7 @@ func (s *Service) Build(ctx context.Context, opts BuildOptions) (Payload, error)
 				CurrentCommit:  currentCommit,
 			}
 		} else {
-			payload.Freshness = Freshness{
+			freshness = Freshness{
 				IsStale:        false,
 				Reason:         "",
 				LastSyncAt:     state.LastSyncAt.Format(time.RFC3339),
@@ -212,30 +366,56 @@ func (s *Service) Build(ctx context.Context, opts BuildOptions) (Payload, error)
 			}
 		}
 	}
+	if freshness.IsStale && freshness.LastSyncCommit != "" {
+		freshness.ChangedFiles, freshness.ChangedFilesTruncated = changedFilesSince(ctx, moduleRoot, freshness.LastSyncCommit)
+	}
+	return freshness, warnings, nil
+}
 
-	return payload, nil
+// generatedScope returns the conditions that leave generated and vendored
+// files out of the files, symbols, and packages tables, or "1=1" for each
+// when they are included. A package is left out when all its files are.
+func (s *Service) generatedScope() (files, symbols, packages string) {
+	if s.includeGenerated {
+		return "1=1", "1=1", "1=1"
+	}
+	return "generated = 0",
+		"NOT EXISTS (SELECT 1 FROM files f WHERE f.id = symbols.file_id AND f.generated = 1)",
+		"(EXISTS (SELECT 1 FROM files f WHERE f.package_id = packages.id AND f.generated = 0) OR NOT EXISTS (SELECT 1 FROM files f WHERE f.package_id = packages.id))"
 }
 
 func (s *Service) loadSummary(ctx context.Context, payload *Payload) error {
-	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM files;").Scan(&payload.Summary.FileCount); err != nil {
+	fileScope, symbolScope, packageScope := s.generatedScope()
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM files WHERE "+fileScope+";").Scan(&payload.Summary.FileCount); err != nil {
 		return fmt.Errorf("count files: %w", err)
 	}
-	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM symbols;").Scan(&payload.Summary.SymbolCount); err != nil {
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM symbols WHERE "+symbolScope+";").Scan(&payload.Summary.SymbolCount); err != nil {
 		return fmt.Errorf("count symbols: %w", err)
 	}
-	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM packages;").Scan(&payload.Summary.PackageCount); err != nil {
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM packages WHERE "+packageScope+";").Scan(&payload.Summary.PackageCount); err != nil {
 		return fmt.Errorf("count packages: %w", err)
 	}
-	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM decisions WHERE status = 'active';").Scan(&payload.Summary.DecisionCount); err != nil {
+	if !s.includeGenerated {
+		if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM files WHERE generated = 1;").Scan(&payload.Summary.GeneratedFiles); err != nil {
+			return fmt.Errorf("count generated files: %w", err)
+		}
+	}
+	scope, scopeArgs := branchScope("branch", "?", s.branch)
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM decisions WHERE status = 'active'"+scope+";", scopeArgs...).Scan(&payload.Summary.DecisionCount); err != nil {
 		return fmt.Errorf("count decisions: %w", err)
 	}
+	if err := s.db.QueryRowContext(ctx, "SELECT COALESCE(SUM(cgo), 0), COALESCE(SUM(unsafe), 0) FROM files WHERE "+fileScope+";").Scan(&payload.Summary.CgoFiles, &payload.Summary.UnsafeFiles); err != nil {
+		return fmt.Errorf("count cgo and unsafe files: %w", err)
+	}
 	return nil
 }
 
 func (s *Service) loadModules(ctx context.Context, limit int, payload *Payload) error {
+	_, _, packageScope := s.generatedScope()
 	rows, err := s.db.QueryContext(ctx, `
 SELECT path, name, file_count, line_count
 FROM packages
+WHERE `+packageScope+`
 ORDER BY line_count DESC, path ASC
 LIMIT ?;
 `, limit)
@@ -258,22 +438,27 @@ LIMIT ?;
 }
 
 func (s *Service) loadDecisions(ctx context.Context, limit int, payload *Payload) error {
+	scope, scopeArgs := branchScope("d.branch", "?", s.branch)
 	rows, err := s.db.QueryContext(ctx, `
-SELECT d.id, d.title, COALESCE(d.reasoning, ''), d.confidence, d.updated_at, COALESCE(e.drift_status, 'ok')
+SELECT d.id, d.title, COALESCE(d.reasoning, ''), d.confidence, d.updated_at, COALESCE(e.drift_status, 'ok'), COALESCE(d.branch, '')
 FROM decisions d
-LEFT JOIN evidence e ON e.entity_type = 'decision' AND e.entity_id = d.id
-WHERE d.status = 'active'
-ORDER BY d.updated_at DESC
+LEFT JOIN entity_evidence e ON e.entity_type = 'decision' AND e.entity_id = d.id
+WHERE d.status = 'active'`+scope+confidenceScope("d.confidence", s.minConfidence)+`
+ORDER BY d.updated_at DESC, d.id DESC
 LIMIT ?;
-`, limit)
+`, append(scopeArgs, limit)...)
 	if err != nil {
 		return fmt.Errorf("query decisions: %w", err)
 	}
+	return scanDecisions(rows, payload)
+}
+
+func scanDecisions(rows *sql.Rows, payload *Payload) error {
 	defer rows.Close()
 
 	for rows.Next() {
 		var d DecisionDigest
-		if err := rows.Scan(&d.ID, &d.Title, &d.Reasoning, &d.Confidence, &d.UpdatedAt, &d.Drift); err != nil {
+		if err := rows.Scan(&d.ID, &d.Title, &d.Reasoning, &d.Confidence, &d.UpdatedAt, &d.Drift, &d.Branch); err != nil {
 			return fmt.Errorf("scan decision row: %w", err)
 		}
 		payload.ActiveDecisions = append(payload.ActiveDecisions, d)
@@ -284,18 +469,50 @@ LIMIT ?;
 	return nil
 }
 
+// branchScope returns the condition, prefixed with AND, that keeps the
+// decisions visible on branch: unscoped ones, and those scoped to branch
+// itself, bound by placeholder.
+func branchScope(column, placeholder, branch string) (string, []any) {
+	if branch == "" {
+		return " AND " + column + " IS NULL", nil
+	}
+	return " AND (" + column + " IS NULL OR " + column + " = " + placeholder + ")", []any{branch}
+}
+
+var confidenceRank = map[string]int{"low": 1, "medium": 2, "high": 3}
+
+// confidenceScope returns the condition, prefixed with AND, that keeps rows
+// whose confidence column is at least min. The levels are literals, so the
+// condition adds no placeholders.
+func confidenceScope(column, min string) string {
+	if confidenceRank[min] <= confidenceRank["low"] {
+		return ""
+	}
+	levels := []string{}
+	for _, level := range []string{"low", "medium", "high"} {
+		if confidenceRank[level] >= confidenceRank[min] {
+			levels = append(levels, "'"+level+"'")
+		}
+	}
+	return " AND " + column + " IN (" + strings.Join(levels, ", ") + ")"
+}
+
 func (s *Service) loadPatterns(ctx context.Context, limit int, payload *Payload) error {
 	rows, err := s.db.QueryContext(ctx, `
 SELECT p.id, p.title, COALESCE(p.description, ''), p.confidence, p.updated_at, COALESCE(e.drift_status, 'ok')
 FROM patterns p
-LEFT JOIN evidence e ON e.entity_type = 'pattern' AND e.entity_id = p.id
-WHERE p.status = 'active'
-ORDER BY p.updated_at DESC
+LEFT JOIN entity_evidence e ON e.entity_type = 'pattern' AND e.entity_id = p.id
+WHERE p.status = 'active'`+confidenceScope("p.confidence", s.minConfidence)+`
+ORDER BY p.updated_at DESC, p.id DESC
 LIMIT ?;
 `, limit)
 	if err != nil {
 		return fmt.Errorf("query patterns: %w", err)
 	}
+	return scanPatterns(rows, payload)
+}
+
+func scanPatterns(rows *sql.Rows, payload *Payload) error {
 	defer rows.Close()
 
 	for rows.Next() {
@@ -311,19 +528,46 @@ LIMIT ?;
 	return nil
 }
 
+func (s *Service) loadConstraints(ctx context.Context, payload *Payload) error {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT c.id, c.title, COALESCE(c.reasoning, ''), c.confidence, COALESCE(e.drift_status, 'ok')
+FROM constraints c
+LEFT JOIN entity_evidence e ON e.entity_type = 'constraint' AND e.entity_id = c.id
+WHERE c.status = 'active'
+ORDER BY c.id;
+`)
+	if err != nil {
+		return fmt.Errorf("query constraints: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c ConstraintDigest
+		if err := rows.Scan(&c.ID, &c.Title, &c.Reasoning, &c.Confidence, &c.Drift); err != nil {
+			return fmt.Errorf("scan constraint row: %w", err)
+		}
+		payload.Constraints = append(payload.Constraints, c)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate constraint rows: %w", err)
+	}
+	return nil
+}
+
 func (s *Service) loadModuleEdges(ctx context.Context, payload *Payload) {
+	scope, scopeArgs := branchScope("d.branch", "?", s.branch)
 	rows, err := s.db.QueryContext(ctx, `
 SELECT e.to_ref, e.from_type, e.from_id,
        COALESCE(d.title, p.title, '') AS title,
        COALESCE(d.confidence, p.confidence, 'medium') AS confidence,
        COALESCE(e.confidence, 'medium') AS edge_confidence
 FROM edges e
-LEFT JOIN decisions d ON e.from_type = 'decision' AND e.from_id = d.id AND d.status = 'active'
-LEFT JOIN patterns p ON e.from_type = 'pattern' AND e.from_id = p.id AND p.status = 'active'
+LEFT JOIN decisions d ON e.from_type = 'decision' AND e.from_id = d.id AND d.status = 'active'`+scope+confidenceScope("d.confidence", s.minConfidence)+`
+LEFT JOIN patterns p ON e.from_type = 'pattern' AND e.from_id = p.id AND p.status = 'active'`+confidenceScope("p.confidence", s.minConfidence)+`
 WHERE e.to_type = 'package' AND e.relation = 'affects'
   AND (d.id IS NOT NULL OR p.id IS NOT NULL)
-ORDER BY e.to_ref, e.from_type, confidence DESC;
-`)
+ORDER BY e.to_ref, e.from_type, confidence DESC, e.from_id;
+`, scopeArgs...)
 	if err != nil {
 		return // Non-fatal: edges table might not exist in older DBs
 	}
@@ -412,90 +656,184 @@ ORDER BY p1.path, p2.path;
 		return edges[i].From < edges[j].From
 	})
 	payload.Architecture = Architecture{EntryPoints: entryPoints, DependencyFlow: edges}
+	classifyModuleFlow(payload)
 	return nil
 }
 
+// classifyModuleFlow sets each module's import counts and flow role from the
+// full dependency flow, so modules outside the listed ones still count.
+func classifyModuleFlow(payload *Payload) {
+	importedBy := map[string]int{}
+	imports := map[string]int{}
+	for _, edge := range payload.Architecture.DependencyFlow {
+		imports[edge.From] = len(edge.To)
+		for _, to := range edge.To {
+			importedBy[to]++
+		}
+	}
+	for i := range payload.Modules {
+		m := &payload.Modules[i]
+		m.ImportedBy, m.Imports = importedBy[m.Path], imports[m.Path]
+		switch {
+		case m.ImportedBy == 0 && m.Imports == 0:
+			m.Flow = FlowLeaf
+		case m.ImportedBy >= m.Imports:
+			m.Flow = FlowUpstream
+		default:
+			m.Flow = FlowDownstream
+		}
+	}
+}
+
+// loadModuleHeat sets the recent commit count, heat, and top contributors
+// of each module, resolving changed files against every indexed package so a file in a
+// nested package never counts toward its parent.
 func (s *Service) loadModuleHeat(ctx context.Context, moduleRoot string, payload *Payload) {
-	cmd := exec.CommandContext(ctx, "git", "-C", moduleRoot, "log", "--since=30 days ago", "--name-only", "--pretty=format:")
-	out, err := cmd.Output()
+	paths, err := s.packagePaths(ctx)
 	if err != nil {
 		return // Non-fatal: heat is optional
 	}
-
-	counts := map[string]int{}
-	for _, line := range strings.Split(string(out), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+	counts, contributors, err := index.PackageCommits(ctx, moduleRoot, paths, payload.Heat)
+	if err != nil {
+		return // Non-fatal: heat is optional
+	}
+	for i := range payload.Modules {
+		m := &payload.Modules[i]
+		m.RecentCommits = counts[m.Path]
+		m.Heat = payload.Heat.Level(m.RecentCommits)
+		m.Contributors = contributors[m.Path]
+		if len(m.Contributors) > maxContributors {
+			m.Contributors = m.Contributors[:maxContributors]
 		}
-		dir := filepath.Dir(line)
-		if dir == "." {
-			counts["."]++
-		} else {
-			for _, m := range payload.Modules {
-				if strings.HasPrefix(filepath.ToSlash(dir), m.Path) || (m.Path == "." && !strings.Contains(dir, "/")) {
-					counts[m.Path]++
-					break
-				}
-			}
+	}
+}
+
+// packagePaths lists the paths of all indexed packages.
+func (s *Service) packagePaths(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT path FROM packages;`)
+	if err != nil {
+		return nil, fmt.Errorf("query package paths: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, fmt.Errorf("scan package path: %w", err)
 		}
+		paths = append(paths, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate package paths: %w", err)
 	}
+	return paths, nil
+}
 
+// loadModuleOwners attaches the CODEOWNERS owners of each module's files.
+func (s *Service) loadModuleOwners(ctx context.Context, payload *Payload) {
+	byPackage, err := owners.NewService(s.db).Packages(ctx)
+	if err != nil {
+		return // Non-fatal: ownership is optional
+	}
 	for i := range payload.Modules {
-		c := counts[payload.Modules[i].Path]
-		payload.Modules[i].RecentCommits = c
-		switch {
-		case c >= 4:
-			payload.Modules[i].Heat = "hot"
-		case c >= 1:
-			payload.Modules[i].Heat = "warm"
-		default:
-			payload.Modules[i].Heat = "cold"
+		payload.Modules[i].Owners = byPackage[payload.Modules[i].Path]
+	}
+}
+
+// loadModuleCoverage attaches imported coverage to the modules and lists the
+// hot ones below coverage.LowThreshold as gaps, so agents know where new
+// tests pay off most.
+func (s *Service) loadModuleCoverage(ctx context.Context, payload *Payload) {
+	svc := coverage.NewService(s.db)
+	byPackage, err := svc.Packages(ctx)
+	if err != nil || len(byPackage) == 0 {
+		return // Non-fatal: coverage is optional
+	}
+	for i := range payload.Modules {
+		m := &payload.Modules[i]
+		c, ok := byPackage[m.Path]
+		if !ok {
+			continue
 		}
+		m.Coverage = &c
+		if m.Heat != "hot" || !c.Low() {
+			continue
+		}
+		least, err := svc.LeastCovered(ctx, m.Path, 3)
+		if err != nil {
+			continue
+		}
+		payload.CoverageGaps = append(payload.CoverageGaps, CoverageGap{
+			Path: m.Path, RecentCommits: m.RecentCommits, Coverage: c, LeastCovered: least,
+		})
 	}
 }
 
-func (s *Service) loadRecentActivity(ctx context.Context, moduleRoot string, payload *Payload) {
-	cmd := exec.CommandContext(ctx, "git", "-C", moduleRoot, "log", "-n", "20", "--pretty=format:%aI", "--name-only", "--diff-filter=ACMR")
-	out, err := cmd.Output()
+// maxContributors is how many of a module's top contributors orient lists.
+const maxContributors = 3
+
+// recentLogFormat starts each commit of the recent activity log with a NUL,
+// then its date, short hash, author, and subject separated by unit
+// separators; the files it changed follow on their own lines.
+const recentLogFormat = "--format=%x00%aI%x1f%h%x1f%an%x1f%s"
+
+// loadRecentActivity lists up to limit recently changed files, newest first,
+// each with the commit that last changed it, limited to the focus subtree
+// when one is set.
+func (s *Service) loadRecentActivity(ctx context.Context, moduleRoot, focus string, limit int, payload *Payload) {
+	commits := strconv.Itoa(max(20, 4*limit))
+	args := []string{"log", "-n", commits, recentLogFormat, "--name-only", "--diff-filter=ACMR"}
+	if focus != "" {
+		args = append(args, "--", focus)
+	}
+	out, err := index.GitOutput(ctx, moduleRoot, args...)
 	if err != nil {
 		return // Non-fatal
 	}
+	payload.RecentActivity = parseRecentActivity(string(out), limit)
+}
 
+// parseRecentActivity reads the output of git log with recentLogFormat into
+// at most limit files, each listed once with its newest commit.
+func parseRecentActivity(out string, limit int) []RecentFile {
 	seen := map[string]bool{}
 	activity := []RecentFile{}
-	var currentDate string
-	for _, line := range strings.Split(string(out), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		// ISO date lines start with digit and have dash at position 4
-		if len(line) > 10 && line[4] == '-' {
-			currentDate = line
+	for _, record := range strings.Split(out, "\x00") {
+		header, files, _ := strings.Cut(record, "\n")
+		fields := strings.Split(header, "\x1f")
+		if len(fields) != 4 {
 			continue
 		}
-		if !seen[line] && currentDate != "" {
+		for _, line := range strings.Split(files, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || seen[line] {
+				continue
+			}
 			seen[line] = true
-			activity = append(activity, RecentFile{File: line, LastModified: currentDate})
-			if len(activity) >= 5 {
-				break
+			activity = append(activity, RecentFile{
+				File:         line,
+				LastModified: fields[0],
+				Commit:       fields[1],
+				Author:       fields[2],
+				Subject:      fields[3],
+			})
+			if len(activity) >= limit {
+				return activity
 			}
 		}
 	}
-	payload.RecentActivity = activity
+	return activity
 }
 
 func computeStaleSummary(ctx context.Context, moduleRoot, fromCommit, toCommit string) string {
-	cmd := exec.CommandContext(ctx, "git", "-C", moduleRoot, "rev-list", "--count", fromCommit+".."+toCommit)
-	out, err := cmd.Output()
+	out, err := index.GitOutput(ctx, moduleRoot, "rev-list", "--count", fromCommit+".."+toCommit)
 	if err != nil {
 		return ""
 	}
 	commitCount := strings.TrimSpace(string(out))
 
-	cmd2 := exec.CommandContext(ctx, "git", "-C", moduleRoot, "diff", "--name-only", fromCommit+".."+toCommit)
-	out2, _ := cmd2.Output()
+	out2, _ := index.GitOutput(ctx, moduleRoot, "diff", "--name-only", fromCommit+".."+toCommit)
 	fileCount := 0
 	for _, line := range strings.Split(string(out2), "\n") {
 		if strings.TrimSpace(line) != "" {
@@ -505,3 +843,30 @@ func computeStaleSummary(ctx context.Context, moduleRoot, fromCommit, toCommit s
 
 	return fmt.Sprintf("%s commits, %d files changed since last sync", commitCount, fileCount)
 }
+
+// changedFilesSince lists the files under moduleRoot that differ between
+// fromCommit and the worktree: committed, staged, and unstaged changes plus
+// untracked files, leaving out recon's own directory. The list is sorted and
+// capped at maxChangedFiles; truncated reports whether files were left out.
+func changedFilesSince(ctx context.Context, moduleRoot, fromCommit string) (files []string, truncated bool) {
+	diff, err := index.GitOutput(ctx, moduleRoot, "diff", "--name-only", "--relative", fromCommit)
+	if err != nil {
+		return nil, false
+	}
+	untracked, _ := index.GitOutput(ctx, moduleRoot, "ls-files", "--others", "--exclude-standard")
+
+	seen := map[string]bool{}
+	for _, line := range strings.Split(string(diff)+"\n"+string(untracked), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || seen[line] || strings.HasPrefix(line, db.ReconDirName+"/") {
+			continue
+		}
+		seen[line] = true
+		files = append(files, line)
+	}
+	sort.Strings(files)
+	if len(files) > maxChangedFiles {
+		return files[:maxChangedFiles], true
+	}
+	return files, false
+}
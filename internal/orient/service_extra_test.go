@@ -24,13 +24,13 @@ func TestLoadSummaryStepwiseErrors(t *testing.T) {
 	ctx := context.Background()
 	payload := &Payload{}
 
-	_, _ = conn.Exec(`CREATE TABLE files (id INTEGER);`)
+	_, _ = conn.Exec(`CREATE TABLE files (id INTEGER, package_id INTEGER, generated INTEGER);`)
 	if err := svc.loadSummary(ctx, payload); err == nil || !strings.Contains(err.Error(), "count symbols") {
 		t.Fatalf("expected symbols error, got %v", err)
 	}
 	_, _ = conn.Exec(`DROP TABLE files;`)
-	_, _ = conn.Exec(`CREATE TABLE files (id INTEGER);`)
-	_, _ = conn.Exec(`CREATE TABLE symbols (id INTEGER);`)
+	_, _ = conn.Exec(`CREATE TABLE files (id INTEGER, package_id INTEGER, generated INTEGER);`)
+	_, _ = conn.Exec(`CREATE TABLE symbols (id INTEGER, file_id INTEGER);`)
 	if err := svc.loadSummary(ctx, payload); err == nil || !strings.Contains(err.Error(), "count packages") {
 		t.Fatalf("expected packages error, got %v", err)
 	}
@@ -38,6 +38,10 @@ func TestLoadSummaryStepwiseErrors(t *testing.T) {
 	if err := svc.loadSummary(ctx, payload); err == nil || !strings.Contains(err.Error(), "count decisions") {
 		t.Fatalf("expected decisions error, got %v", err)
 	}
+	_, _ = conn.Exec(`CREATE TABLE decisions (id INTEGER, status TEXT, branch TEXT);`)
+	if err := svc.loadSummary(ctx, payload); err == nil || !strings.Contains(err.Error(), "count cgo and unsafe files") {
+		t.Fatalf("expected cgo and unsafe error, got %v", err)
+	}
 }
 
 func TestLoadPatternsErrorBranches(t *testing.T) {
@@ -62,6 +66,7 @@ func TestLoadPatternsErrorBranches(t *testing.T) {
 	// Patterns table with columns that exist but produce wrong types for scan
 	_, _ = conn.Exec(`CREATE TABLE patterns (id INTEGER, title TEXT, description TEXT, confidence TEXT, updated_at TEXT, status TEXT);`)
 	_, _ = conn.Exec(`CREATE TABLE evidence (entity_type TEXT, entity_id INTEGER, drift_status TEXT);`)
+	_, _ = conn.Exec(`CREATE VIEW entity_evidence AS SELECT entity_type, entity_id, drift_status FROM evidence;`)
 	// Insert a row with NULL values in required scan fields to trigger scan error
 	_, _ = conn.Exec(`INSERT INTO patterns(id, status) VALUES (1, 'active');`)
 	if err := svc.loadPatterns(ctx, 5, payload); err == nil || !strings.Contains(err.Error(), "scan pattern row") {
@@ -90,7 +95,7 @@ func TestLoadArchitectureErrorBranches(t *testing.T) {
 
 	// Create files/packages tables but with wrong columns for scan
 	_, _ = conn.Exec(`CREATE TABLE packages (id INTEGER PRIMARY KEY, path TEXT, name TEXT, file_count INTEGER, line_count INTEGER);`)
-	_, _ = conn.Exec(`CREATE TABLE files (id INTEGER, path TEXT, package_id INTEGER);`)
+	_, _ = conn.Exec(`CREATE TABLE files (id INTEGER, path TEXT, package_id INTEGER, cgo INTEGER, unsafe INTEGER, generated INTEGER);`)
 	_, _ = conn.Exec(`INSERT INTO packages(id, path, name, file_count, line_count) VALUES (1, '.', 'main', 1, 10);`)
 	_, _ = conn.Exec(`INSERT INTO files(id, path, package_id) VALUES (1, 'main.go', 1);`)
 
@@ -126,12 +131,14 @@ func TestBuildLoadArchitectureError(t *testing.T) {
 	defer conn.Close()
 
 	// Create tables so summary, modules, decisions, patterns all succeed
-	_, _ = conn.Exec(`CREATE TABLE files (id INTEGER, path TEXT, package_id INTEGER);`)
-	_, _ = conn.Exec(`CREATE TABLE symbols (id INTEGER);`)
-	_, _ = conn.Exec(`CREATE TABLE decisions (id INTEGER, title TEXT, reasoning TEXT, confidence TEXT, updated_at TEXT, status TEXT);`)
+	_, _ = conn.Exec(`CREATE TABLE files (id INTEGER, path TEXT, package_id INTEGER, cgo INTEGER, unsafe INTEGER, generated INTEGER);`)
+	_, _ = conn.Exec(`CREATE TABLE symbols (id INTEGER, file_id INTEGER);`)
+	_, _ = conn.Exec(`CREATE TABLE decisions (id INTEGER, title TEXT, reasoning TEXT, confidence TEXT, updated_at TEXT, status TEXT, branch TEXT);`)
 	_, _ = conn.Exec(`CREATE TABLE packages (id INTEGER PRIMARY KEY, path TEXT, name TEXT, file_count INTEGER, line_count INTEGER);`)
 	_, _ = conn.Exec(`CREATE TABLE patterns (id INTEGER, title TEXT, description TEXT, confidence TEXT, status TEXT, updated_at TEXT, created_at TEXT);`)
+	_, _ = conn.Exec(`CREATE TABLE constraints (id INTEGER, title TEXT, reasoning TEXT, confidence TEXT, status TEXT);`)
 	_, _ = conn.Exec(`CREATE TABLE evidence (entity_type TEXT, entity_id INTEGER, drift_status TEXT);`)
+	_, _ = conn.Exec(`CREATE VIEW entity_evidence AS SELECT entity_type, entity_id, drift_status FROM evidence;`)
 	// Do NOT create imports table — loadArchitecture will fail on query dependency flow
 	_, _ = conn.Exec(`INSERT INTO packages(id, path, name, file_count, line_count) VALUES (1, '.', 'main', 1, 10);`)
 	_, _ = conn.Exec(`INSERT INTO files(id, path, package_id) VALUES (1, 'main.go', 1);`)
@@ -171,9 +178,9 @@ func TestBuildErrorBranchesForModulesDecisionsAndSyncState(t *testing.T) {
 	defer conn.Close()
 
 	// Build hits loadModules error (missing columns in packages table).
-	_, _ = conn.Exec(`CREATE TABLE files (id INTEGER);`)
-	_, _ = conn.Exec(`CREATE TABLE symbols (id INTEGER);`)
-	_, _ = conn.Exec(`CREATE TABLE decisions (id INTEGER, status TEXT);`)
+	_, _ = conn.Exec(`CREATE TABLE files (id INTEGER, package_id INTEGER, cgo INTEGER, unsafe INTEGER, generated INTEGER);`)
+	_, _ = conn.Exec(`CREATE TABLE symbols (id INTEGER, file_id INTEGER);`)
+	_, _ = conn.Exec(`CREATE TABLE decisions (id INTEGER, status TEXT, branch TEXT);`)
 	_, _ = conn.Exec(`CREATE TABLE packages (id INTEGER);`)
 	if _, err := NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root}); err == nil || !strings.Contains(err.Error(), "query modules") {
 		t.Fatalf("expected build loadModules error, got %v", err)
@@ -183,20 +190,22 @@ func TestBuildErrorBranchesForModulesDecisionsAndSyncState(t *testing.T) {
 	_, _ = conn.Exec(`DROP TABLE packages;`)
 	_, _ = conn.Exec(`CREATE TABLE packages (id INTEGER PRIMARY KEY, path TEXT, name TEXT, file_count INTEGER, line_count INTEGER);`)
 	_, _ = conn.Exec(`DROP TABLE decisions;`)
-	_, _ = conn.Exec(`CREATE TABLE decisions (id INTEGER, status TEXT);`)
+	_, _ = conn.Exec(`CREATE TABLE decisions (id INTEGER, status TEXT, branch TEXT);`)
 	if _, err := NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root}); err == nil || !strings.Contains(err.Error(), "query decisions") {
 		t.Fatalf("expected build loadDecisions error, got %v", err)
 	}
 
 	// Fix decisions query, break LoadSyncState parse.
 	_, _ = conn.Exec(`DROP TABLE decisions;`)
-	_, _ = conn.Exec(`CREATE TABLE decisions (id INTEGER, title TEXT, reasoning TEXT, confidence TEXT, updated_at TEXT, status TEXT);`)
+	_, _ = conn.Exec(`CREATE TABLE decisions (id INTEGER, title TEXT, reasoning TEXT, confidence TEXT, updated_at TEXT, status TEXT, branch TEXT);`)
 	_, _ = conn.Exec(`CREATE TABLE evidence (entity_type TEXT, entity_id INTEGER, drift_status TEXT);`)
+	_, _ = conn.Exec(`CREATE VIEW entity_evidence AS SELECT entity_type, entity_id, drift_status FROM evidence;`)
 	// Recreate files with proper columns so loadArchitecture succeeds
 	_, _ = conn.Exec(`DROP TABLE files;`)
-	_, _ = conn.Exec(`CREATE TABLE files (id INTEGER, path TEXT, package_id INTEGER);`)
+	_, _ = conn.Exec(`CREATE TABLE files (id INTEGER, path TEXT, package_id INTEGER, cgo INTEGER, unsafe INTEGER, generated INTEGER);`)
 	_, _ = conn.Exec(`CREATE TABLE imports (id INTEGER, from_file_id INTEGER, to_path TEXT, to_package_id INTEGER, alias TEXT, import_type TEXT);`)
 	_, _ = conn.Exec(`CREATE TABLE patterns (id INTEGER, title TEXT, description TEXT, confidence TEXT, status TEXT, updated_at TEXT, created_at TEXT);`)
+	_, _ = conn.Exec(`CREATE TABLE constraints (id INTEGER, title TEXT, reasoning TEXT, confidence TEXT, status TEXT);`)
 	_, _ = conn.Exec(`CREATE TABLE sync_state (id INTEGER PRIMARY KEY, last_sync_at TEXT, last_sync_commit TEXT, last_sync_dirty INTEGER, indexed_file_count INTEGER, index_fingerprint TEXT);`)
 	_, _ = conn.Exec(`INSERT INTO sync_state(id,last_sync_at,last_sync_commit,last_sync_dirty,indexed_file_count,index_fingerprint) VALUES (1,'bad-time','c',0,0,'f');`)
 	if _, err := NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root}); err == nil || !strings.Contains(err.Error(), "parse sync timestamp") {
@@ -236,9 +245,10 @@ func TestLoadModuleEdgesTruncation(t *testing.T) {
 
 	// Create necessary tables
 	_, _ = conn.Exec(`CREATE TABLE packages (id INTEGER PRIMARY KEY, path TEXT, name TEXT, file_count INTEGER, line_count INTEGER)`)
-	_, _ = conn.Exec(`CREATE TABLE decisions (id INTEGER, title TEXT, reasoning TEXT, confidence TEXT, status TEXT, created_at TEXT, updated_at TEXT)`)
+	_, _ = conn.Exec(`CREATE TABLE decisions (id INTEGER, title TEXT, reasoning TEXT, confidence TEXT, status TEXT, created_at TEXT, updated_at TEXT, branch TEXT)`)
 	_, _ = conn.Exec(`CREATE TABLE patterns (id INTEGER, title TEXT, description TEXT, confidence TEXT, status TEXT, created_at TEXT, updated_at TEXT)`)
 	_, _ = conn.Exec(`CREATE TABLE evidence (entity_type TEXT, entity_id INTEGER, drift_status TEXT)`)
+	_, _ = conn.Exec(`CREATE VIEW entity_evidence AS SELECT entity_type, entity_id, drift_status FROM evidence;`)
 	_, _ = conn.Exec(`CREATE TABLE edges (id INTEGER PRIMARY KEY, from_type TEXT, from_id INTEGER, to_type TEXT, to_ref TEXT, relation TEXT, source TEXT, confidence TEXT, created_at TEXT, UNIQUE(from_type, from_id, to_type, to_ref, relation))`)
 
 	_, _ = conn.Exec(`INSERT INTO packages(id, path, name, file_count, line_count) VALUES (1, 'internal/cli', 'cli', 5, 500)`)
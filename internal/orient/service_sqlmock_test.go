@@ -37,16 +37,16 @@ func TestLoadModulesAndDecisionsScanAndRowsErr(t *testing.T) {
 	}
 
 	mock.ExpectQuery("SELECT d.id, d.title").WithArgs(2).WillReturnRows(
-		sqlmock.NewRows([]string{"id", "title", "reasoning", "confidence", "updated_at", "drift_status"}).
-			AddRow("bad-id", "t", "r", "c", "u", "ok"),
+		sqlmock.NewRows([]string{"id", "title", "reasoning", "confidence", "updated_at", "drift_status", "branch"}).
+			AddRow("bad-id", "t", "r", "c", "u", "ok", ""),
 	)
 	if err := svc.loadDecisions(context.Background(), 2, payload); err == nil || !strings.Contains(err.Error(), "scan decision row") {
 		t.Fatalf("expected decision scan error, got %v", err)
 	}
 
 	mock.ExpectQuery("SELECT d.id, d.title").WithArgs(2).WillReturnRows(
-		sqlmock.NewRows([]string{"id", "title", "reasoning", "confidence", "updated_at", "drift_status"}).
-			AddRow(1, "t", "r", "c", "u", "ok").
+		sqlmock.NewRows([]string{"id", "title", "reasoning", "confidence", "updated_at", "drift_status", "branch"}).
+			AddRow(1, "t", "r", "c", "u", "ok", "").
 			RowError(0, errors.New("decision iter fail")),
 	)
 	if err := svc.loadDecisions(context.Background(), 2, payload); err == nil || !strings.Contains(err.Error(), "iterate decision rows") {
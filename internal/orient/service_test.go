@@ -7,10 +7,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/robertguss/recon/internal/coverage"
 	"github.com/robertguss/recon/internal/db"
 	"github.com/robertguss/recon/internal/index"
 )
@@ -49,6 +52,33 @@ func TestBuildNeverSyncedAndSummary(t *testing.T) {
 	if payload.Project.ModulePath != "example.com/recon" || payload.Project.Language != "go" {
 		t.Fatalf("unexpected project info: %+v", payload.Project)
 	}
+	if payload.Constraints == nil || len(payload.Constraints) != 0 {
+		t.Fatalf("expected empty constraints, got %+v", payload.Constraints)
+	}
+}
+
+func TestBuildLoadsActiveConstraints(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/recon\n"), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	conn := setupOrientDB(t, root)
+	defer conn.Close()
+	if _, err := conn.Exec(`
+INSERT INTO constraints(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+  (1,'No db in cmd','r','high','active','x','x'),
+  (2,'Old rule','','low','archived','x','x');
+INSERT INTO evidence(entity_type,entity_id,summary,drift_status) VALUES ('constraint',1,'s','broken');`); err != nil {
+		t.Fatalf("seed constraints: %v", err)
+	}
+
+	payload, err := NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if len(payload.Constraints) != 1 || payload.Constraints[0] != (ConstraintDigest{ID: 1, Title: "No db in cmd", Reasoning: "r", Confidence: "high", Drift: "broken"}) {
+		t.Fatalf("constraints = %+v", payload.Constraints)
+	}
 }
 
 func TestBuildFreshnessModes(t *testing.T) {
@@ -283,6 +313,34 @@ func TestBuildModuleHeat(t *testing.T) {
 		if m.Path == "." && m.Heat != "hot" {
 			t.Fatalf("expected root module to be hot, got %s", m.Heat)
 		}
+		if m.Path == "." && !reflect.DeepEqual(m.Contributors, []index.Contributor{{Name: "Tester", Commits: 6}}) {
+			t.Fatalf("expected Tester as the root module's contributor, got %+v", m.Contributors)
+		}
+	}
+	if len(payload.CoverageGaps) != 0 {
+		t.Fatalf("expected no coverage gaps before an import, got %+v", payload.CoverageGaps)
+	}
+
+	// The hot root package is uncovered, the cold pkg fully covered.
+	profile := "mode: set\nexample.com/recon/main.go:2.12,2.13 1 0\nexample.com/recon/pkg/a.go:2.9,2.10 1 1\n"
+	if _, err := coverage.NewService(conn).Import(context.Background(), strings.NewReader(profile)); err != nil {
+		t.Fatalf("import coverage: %v", err)
+	}
+	payload, err = NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root})
+	if err != nil {
+		t.Fatalf("Build with coverage: %v", err)
+	}
+	for _, m := range payload.Modules {
+		if m.Path == "pkg" && (m.Coverage == nil || m.Coverage.Percent != 100) {
+			t.Fatalf("expected pkg coverage 100%%, got %+v", m.Coverage)
+		}
+	}
+	if len(payload.CoverageGaps) != 1 || payload.CoverageGaps[0].Path != "." ||
+		len(payload.CoverageGaps[0].LeastCovered) != 1 || payload.CoverageGaps[0].LeastCovered[0].Name != "main" {
+		t.Fatalf("expected root coverage gap, got %+v", payload.CoverageGaps)
+	}
+	if got := RenderText(payload); !strings.Contains(got, "Coverage gaps") || !strings.Contains(got, "least covered: main (0/1)") {
+		t.Fatalf("expected coverage gaps in text output: %s", got)
 	}
 }
 
@@ -322,6 +380,118 @@ func TestBuildArchitectureSection(t *testing.T) {
 	}
 }
 
+func TestBuildCountsCgoAndUnsafeFiles(t *testing.T) {
+	root := t.TempDir()
+	for name, src := range map[string]string{
+		"go.mod":      "module example.com/recon\n",
+		"main.go":     "package main\nfunc main(){}\n",
+		"cgo.go":      "package main\n// #include <stdlib.h>\nimport \"C\"\nimport \"unsafe\"\nvar _ = unsafe.Sizeof(0)\n",
+		"ptr/ptr.go":  "package ptr\nimport \"unsafe\"\nvar Size = unsafe.Sizeof(0)\n",
+		"plain/pl.go": "package plain\nimport \"fmt\"\nvar _ = fmt.Sprint\n",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	conn := setupOrientDB(t, root)
+	defer conn.Close()
+	if _, err := index.NewService(conn).Sync(context.Background(), root); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	payload, err := NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if payload.Summary.CgoFiles != 1 || payload.Summary.UnsafeFiles != 2 {
+		t.Fatalf("summary = %+v, want 1 cgo and 2 unsafe files", payload.Summary)
+	}
+	if got := RenderText(payload); !strings.Contains(got, "Handle with care: 1 cgo files, 2 files importing unsafe") {
+		t.Fatalf("render missing low-level summary:\n%s", got)
+	}
+	if got := RenderText(Payload{}); strings.Contains(got, "Handle with care") {
+		t.Fatalf("render without cgo or unsafe files:\n%s", got)
+	}
+}
+
+func TestBuildLeavesOutGeneratedFiles(t *testing.T) {
+	root := t.TempDir()
+	for name, src := range map[string]string{
+		"go.mod":                        "module example.com/recon\n",
+		"main.go":                       "package main\nfunc main(){}\n",
+		"main_gen.go":                   "// Code generated by tool. DO NOT EDIT.\npackage main\nfunc Gen(){}\n",
+		"pb/api.pb.go":                  "// Code generated by protoc-gen-go. DO NOT EDIT.\npackage pb\nimport \"unsafe\"\nvar Size = unsafe.Sizeof(0)\n",
+		"vendor/example.com/dep/dep.go": "package dep\nfunc Dep(){}\n",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	conn := setupOrientDB(t, root)
+	defer conn.Close()
+	if _, err := index.NewService(conn).Sync(context.Background(), root); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	payload, err := NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	want := Summary{FileCount: 1, SymbolCount: 1, PackageCount: 1, GeneratedFiles: 3}
+	if payload.Summary != want {
+		t.Fatalf("summary = %+v, want %+v", payload.Summary, want)
+	}
+	if len(payload.Modules) != 1 || payload.Modules[0].Path != "." {
+		t.Fatalf("modules = %+v, want only the root package", payload.Modules)
+	}
+	if got := RenderText(payload); !strings.Contains(got, "Generated or vendored: 3 files") {
+		t.Fatalf("render missing generated summary:\n%s", got)
+	}
+
+	payload, err = NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root, IncludeGenerated: true})
+	if err != nil {
+		t.Fatalf("Build IncludeGenerated: %v", err)
+	}
+	want = Summary{FileCount: 4, SymbolCount: 4, PackageCount: 3, UnsafeFiles: 1}
+	if payload.Summary != want || len(payload.Modules) != 3 {
+		t.Fatalf("summary = %+v with %d modules, want %+v with 3", payload.Summary, len(payload.Modules), want)
+	}
+}
+
+func TestClassifyModuleFlow(t *testing.T) {
+	payload := Payload{
+		Architecture: Architecture{DependencyFlow: []DependencyEdge{
+			{From: "cmd/recon", To: []string{"internal/cli"}},
+			{From: "internal/cli", To: []string{"internal/db", "internal/find"}},
+			{From: "internal/find", To: []string{"internal/db"}},
+		}},
+		Modules: []ModuleSummary{
+			{Path: "internal/db"}, {Path: "internal/find"}, {Path: "internal/cli"}, {Path: "tools"},
+		},
+	}
+	classifyModuleFlow(&payload)
+
+	var got []string
+	for _, m := range payload.Modules {
+		got = append(got, fmt.Sprintf("%s=%s/%d/%d", m.Path, m.Flow, m.ImportedBy, m.Imports))
+	}
+	want := "internal/db=upstream/2/0 internal/find=upstream/1/1 internal/cli=downstream/1/2 tools=leaf/0/0"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("flow = %s\nwant %s", strings.Join(got, " "), want)
+	}
+	if text := RenderText(payload); !strings.Contains(text, "] upstream (imported by 2, imports 0)") {
+		t.Fatalf("expected flow in text output: %s", text)
+	}
+}
+
 func TestOrientShowsActivePatterns(t *testing.T) {
 	root := t.TempDir()
 	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/recon\n"), 0o644); err != nil {
@@ -358,7 +528,10 @@ func TestRenderTextAllSections(t *testing.T) {
 		Architecture: Architecture{EntryPoints: []string{"cmd/main.go"}, DependencyFlow: []DependencyEdge{{From: "cmd", To: []string{"pkg"}}}},
 		Freshness:    Freshness{IsStale: true, Reason: "stale", LastSyncAt: "2026-01-01T00:00:00Z"},
 		Summary:      Summary{FileCount: 1, SymbolCount: 2, PackageCount: 1, DecisionCount: 1},
-		Modules:      []ModuleSummary{{Path: "cmd", Name: "cmd"}, {Path: "pkg", Name: "pkg"}},
+		Modules: []ModuleSummary{
+			{Path: "cmd", Name: "cmd"},
+			{Path: "pkg", Name: "pkg", Contributors: []index.Contributor{{Name: "alice", Commits: 3}, {Name: "bob", Commits: 1}}},
+		},
 		ActiveDecisions: []DecisionDigest{
 			{ID: 1, Title: "d1", Confidence: "high", Drift: "ok", UpdatedAt: "2026-01-01T00:00:00Z"},
 		},
@@ -366,7 +539,7 @@ func TestRenderTextAllSections(t *testing.T) {
 			{ID: 1, Title: "p1", Confidence: "medium", Drift: "ok"},
 		},
 		RecentActivity: []RecentFile{
-			{File: "main.go", LastModified: "2026-01-01T00:00:00Z"},
+			{File: "main.go", LastModified: "2026-01-01T00:00:00Z", Commit: "abc1234", Author: "alice", Subject: "fix startup"},
 		},
 		Warnings: []string{"something is wrong"},
 	}
@@ -377,11 +550,12 @@ func TestRenderTextAllSections(t *testing.T) {
 		"STALE CONTEXT: stale",
 		"Last sync: 2026-01-01T00:00:00Z",
 		"- cmd (cmd)",
+		"contributors=alice(3),bob(1)",
 		"- #1 d1",
 		"Active patterns:",
 		"- #1 p1",
 		"Recent activity:",
-		"- main.go",
+		"- main.go (2026-01-01T00:00:00Z) abc1234 alice: fix startup",
 		"Warnings:",
 		"- something is wrong",
 	} {
@@ -513,6 +687,17 @@ func TestBuildRecentActivityCapsAtFive(t *testing.T) {
 	if len(payload.RecentActivity) != 5 {
 		t.Fatalf("expected exactly 5 recent activity entries, got %d", len(payload.RecentActivity))
 	}
+
+	payload, err = NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root, MaxRecent: 7})
+	if err != nil {
+		t.Fatalf("Build with MaxRecent: %v", err)
+	}
+	if len(payload.RecentActivity) != 7 {
+		t.Fatalf("expected 7 recent activity entries with MaxRecent 7, got %d", len(payload.RecentActivity))
+	}
+	if newest := payload.RecentActivity[0]; newest.File != "file6.go" || newest.Subject != "add file6.go" {
+		t.Fatalf("expected the newest commit's file first, got %+v", newest)
+	}
 }
 
 func TestBuildRecentActivity(t *testing.T) {
@@ -552,6 +737,10 @@ func TestBuildRecentActivity(t *testing.T) {
 	if payload.RecentActivity[0].File != "main.go" && payload.RecentActivity[0].File != "go.mod" {
 		t.Fatalf("unexpected recent activity file: %s", payload.RecentActivity[0].File)
 	}
+	head := strings.TrimSpace(gitOutput(t, root, "rev-parse", "--short", "HEAD"))
+	if a := payload.RecentActivity[0]; a.Commit != head || a.Author != "Tester" || a.Subject != "init" {
+		t.Fatalf("expected recent activity attributed to %s by Tester, got %+v", head, a)
+	}
 }
 
 func TestBuild_StaleFreshnessIncludesSummary(t *testing.T) {
@@ -622,6 +811,66 @@ func TestBuild_StaleFreshnessIncludesSummary(t *testing.T) {
 	}
 }
 
+func TestFreshness_ListsChangedFiles(t *testing.T) {
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v (%s)", args, err, string(out))
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write("go.mod", "module example.com/recon\n")
+	write("main.go", "package main\nfunc main(){}\n")
+	run("init")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Tester")
+	run("add", ".")
+	run("commit", "-m", "init")
+	syncCommit := strings.TrimSpace(gitOutput(t, root, "rev-parse", "HEAD"))
+
+	conn := setupOrientDB(t, root)
+	defer conn.Close()
+	if err := db.UpsertSyncState(context.Background(), conn, db.SyncState{
+		LastSyncAt:     time.Now().UTC(),
+		LastSyncCommit: syncCommit,
+	}); err != nil {
+		t.Fatalf("UpsertSyncState: %v", err)
+	}
+
+	// One committed change, one uncommitted edit, and one untracked file.
+	write("extra.go", "package main\nfunc Extra(){}\n")
+	run("add", "extra.go")
+	run("commit", "-m", "add extra")
+	write("main.go", "package main\nfunc main(){ Extra() }\n")
+	write("new.go", "package main\n")
+
+	fresh, _, err := NewService(conn).Freshness(context.Background(), root)
+	if err != nil {
+		t.Fatalf("Freshness: %v", err)
+	}
+	if !fresh.IsStale {
+		t.Fatal("expected stale freshness")
+	}
+	if got := strings.Join(fresh.ChangedFiles, ","); got != "extra.go,main.go,new.go" || fresh.ChangedFilesTruncated {
+		t.Fatalf("ChangedFiles = %q truncated=%v", got, fresh.ChangedFilesTruncated)
+	}
+
+	for i := 0; i < maxChangedFiles; i++ {
+		write(fmt.Sprintf("gen%02d.go", i), "package main\n")
+	}
+	files, truncated := changedFilesSince(context.Background(), root, syncCommit)
+	if len(files) != maxChangedFiles || !truncated {
+		t.Fatalf("expected %d files and truncation, got %d truncated=%v", maxChangedFiles, len(files), truncated)
+	}
+}
+
 func TestBuild_ModulesIncludeEdges(t *testing.T) {
 	root := t.TempDir()
 	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/recon\n"), 0o644); err != nil {
@@ -682,3 +931,92 @@ func gitOutput(t *testing.T, dir string, args ...string) string {
 	}
 	return string(out)
 }
+
+func TestBuildOrdersKnowledgeTiesByID(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/recon\n"), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	conn := setupOrientDB(t, root)
+	defer conn.Close()
+
+	// Equal timestamps (e.g. written in one batch) fall back to newest ID first.
+	for _, id := range []int{2, 3, 1} {
+		_, _ = conn.Exec(`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES (?,?,'r','high','active','x','2026-01-01T00:00:00Z');`, id, fmt.Sprintf("D%d", id))
+		_, _ = conn.Exec(`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES (?,?,'d','high','active','x','2026-01-01T00:00:00Z');`, id, fmt.Sprintf("P%d", id))
+	}
+	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (1,'zeta','zeta','example.com/recon/zeta',1,10,'x','x'),(2,'alpha','alpha','example.com/recon/alpha',1,10,'x','x');`)
+
+	payload, err := NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	var got []string
+	for _, d := range payload.ActiveDecisions {
+		got = append(got, d.Title)
+	}
+	for _, p := range payload.ActivePatterns {
+		got = append(got, p.Title)
+	}
+	for _, m := range payload.Modules {
+		got = append(got, m.Path)
+	}
+	if strings.Join(got, ",") != "D3,D2,D1,P3,P2,P1,alpha,zeta" {
+		t.Fatalf("unexpected ordering: %v", got)
+	}
+}
+
+func TestBuildMinConfidence(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/recon\n"), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	conn := setupOrientDB(t, root)
+	defer conn.Close()
+
+	for id, conf := range map[int]string{1: "low", 2: "medium", 3: "high"} {
+		_, _ = conn.Exec(`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES (?,?,'r',?,'active','x','2026-01-01T00:00:00Z');`, id, "D-"+conf, conf)
+		_, _ = conn.Exec(`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES (?,?,'d',?,'active','x','2026-01-01T00:00:00Z');`, id, "P-"+conf, conf)
+		_, _ = conn.Exec(`INSERT INTO edges(from_type,from_id,to_type,to_ref,relation,source,confidence,created_at) VALUES ('decision',?,'package','internal/cli','affects','manual','high','x')`, id)
+	}
+	_, _ = conn.Exec(`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES (1,'internal/cli','cli','example.com/recon/internal/cli',1,10,'x','x');`)
+
+	titles := func(p Payload) string {
+		var got []string
+		for _, d := range p.ActiveDecisions {
+			got = append(got, d.Title)
+		}
+		for _, p := range p.ActivePatterns {
+			got = append(got, p.Title)
+		}
+		var linked []string
+		for _, k := range p.Modules[0].Knowledge {
+			linked = append(linked, fmt.Sprintf("%s#%d", k.Type, k.ID))
+		}
+		sort.Strings(linked)
+		return strings.Join(append(got, linked...), ",")
+	}
+
+	payload, err := NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if got := titles(payload); got != "D-high,D-medium,D-low,P-high,P-medium,P-low,decision#1,decision#2,decision#3" {
+		t.Fatalf("unexpected unfiltered knowledge: %s", got)
+	}
+
+	payload, err = NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root, MinConfidence: "medium"})
+	if err != nil {
+		t.Fatalf("Build medium: %v", err)
+	}
+	if got := titles(payload); got != "D-high,D-medium,P-high,P-medium,decision#2,decision#3" {
+		t.Fatalf("unexpected filtered knowledge: %s", got)
+	}
+	if payload.MinConfidence != "medium" || !strings.Contains(RenderText(payload), "Active decisions (confidence >= medium;") {
+		t.Fatalf("expected the filter to be reported, got %q", payload.MinConfidence)
+	}
+
+	if _, err := NewService(conn).Build(context.Background(), BuildOptions{ModuleRoot: root, MinConfidence: "certain"}); err == nil {
+		t.Fatal("expected error for unknown min confidence")
+	}
+}
@@ -0,0 +1,163 @@
+package owners
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Locations are where a CODEOWNERS file is looked for, relative to the
+// repository root, in the order GitHub reads them.
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule is one line of a CODEOWNERS file. Source is the file's path relative
+// to the repository root and ModuleDir the module root's, "." when they are
+// the same directory; patterns are matched against repository paths. A rule
+// without owners leaves the paths it matches unowned.
+type Rule struct {
+	Source    string   `json:"source"`
+	ModuleDir string   `json:"module_dir"`
+	Line      int      `json:"line"`
+	Pattern   string   `json:"pattern"`
+	Owners    []string `json:"owners"`
+}
+
+// Parse reads the rules of a CODEOWNERS file. Blank lines and comments are
+// skipped; owners end at a "#" that starts a comment.
+func Parse(data []byte) []Rule {
+	var rules []Rule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		for i, f := range fields {
+			if strings.HasPrefix(f, "#") {
+				fields = fields[:i]
+				break
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		rules = append(rules, Rule{Line: line, Pattern: fields[0], Owners: append([]string{}, fields[1:]...)})
+	}
+	return rules
+}
+
+// Load finds the CODEOWNERS file of the repository containing moduleRoot and
+// parses it. The repository root is the nearest directory at or above
+// moduleRoot holding a .git entry, or moduleRoot itself outside git. found
+// is false when there is no CODEOWNERS file.
+func Load(moduleRoot string) (rules []Rule, found bool, err error) {
+	repoRoot := repositoryRoot(moduleRoot)
+	moduleDir, err := filepath.Rel(repoRoot, moduleRoot)
+	if err != nil {
+		return nil, false, fmt.Errorf("resolve module directory: %w", err)
+	}
+	for _, loc := range Locations {
+		data, err := os.ReadFile(filepath.Join(repoRoot, filepath.FromSlash(loc)))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("read %s: %w", loc, err)
+		}
+		rules = Parse(data)
+		for i := range rules {
+			rules[i].Source, rules[i].ModuleDir = loc, filepath.ToSlash(moduleDir)
+		}
+		return rules, true, nil
+	}
+	return nil, false, nil
+}
+
+func repositoryRoot(moduleRoot string) string {
+	for dir := moduleRoot; ; {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return moduleRoot
+		}
+		dir = parent
+	}
+}
+
+// Matcher resolves the owners of module paths from a list of rules.
+type Matcher struct {
+	rules    []Rule
+	patterns []*regexp.Regexp
+}
+
+// NewMatcher compiles rules. Rules whose pattern does not compile never
+// match.
+func NewMatcher(rules []Rule) *Matcher {
+	m := &Matcher{rules: rules, patterns: make([]*regexp.Regexp, len(rules))}
+	for i, r := range rules {
+		m.patterns[i], _ = compilePattern(r.Pattern)
+	}
+	return m
+}
+
+// Match returns the rule that owns rel, a module-relative file or directory
+// path: the last rule matching it, as in GitHub. ok is false when no rule
+// matches.
+func (m *Matcher) Match(rel string) (Rule, bool) {
+	for i := len(m.rules) - 1; i >= 0; i-- {
+		re := m.patterns[i]
+		if re != nil && re.MatchString(path.Join(m.rules[i].ModuleDir, rel)) {
+			return m.rules[i], true
+		}
+	}
+	return Rule{}, false
+}
+
+// compilePattern turns a CODEOWNERS pattern into a regular expression over
+// repository paths. Patterns follow gitignore rules: one with a leading or
+// inner slash is anchored at the root, others match at any depth; "*" and
+// "?" stay within a path segment and "**" crosses them. A pattern also owns
+// everything below the directory it names, except that "dir/*" owns only
+// the direct children of dir.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	p := strings.TrimPrefix(pattern, "/")
+	anchored := p != pattern
+	p = strings.TrimSuffix(p, "/")
+	if strings.Contains(p, "/") {
+		anchored = true
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	if !strings.HasSuffix(p, "/*") {
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
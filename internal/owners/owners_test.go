@@ -0,0 +1,107 @@
+package owners
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	got := Parse([]byte(`# Default owners
+*       @org/everyone
+
+/internal/db/   @alice @org/storage # storage team
+docs/*  docs@example.com
+/vendor/
+`))
+	want := []Rule{
+		{Line: 2, Pattern: "*", Owners: []string{"@org/everyone"}},
+		{Line: 4, Pattern: "/internal/db/", Owners: []string{"@alice", "@org/storage"}},
+		{Line: 5, Pattern: "docs/*", Owners: []string{"docs@example.com"}},
+		{Line: 6, Pattern: "/vendor/", Owners: []string{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Parse() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		miss    []string
+	}{
+		{"*", []string{"main.go", "internal/db/db.go"}, nil},
+		{"*.go", []string{"main.go", "internal/db/db.go"}, []string{"README.md"}},
+		{"db", []string{"db", "internal/db", "internal/db/db.go"}, []string{"internal/dbx/a.go"}},
+		{"/internal/db/", []string{"internal/db", "internal/db/sub/a.go"}, []string{"x/internal/db/a.go"}},
+		{"internal/db", []string{"internal/db/a.go"}, []string{"x/internal/db/a.go"}},
+		{"docs/*", []string{"docs/a.md"}, []string{"docs/guide/a.md"}},
+		{"**/testdata", []string{"testdata/a", "internal/x/testdata/b"}, []string{"internal/testdatax"}},
+		{"/internal/**/service.go", []string{"internal/service.go", "internal/a/b/service.go"}, []string{"service.go"}},
+		{"a?.go", []string{"ab.go"}, []string{"a/.go", "abc.go"}},
+	}
+	for _, tt := range tests {
+		re, err := compilePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("compilePattern(%q): %v", tt.pattern, err)
+		}
+		for _, p := range tt.match {
+			if !re.MatchString(p) {
+				t.Errorf("pattern %q does not match %q", tt.pattern, p)
+			}
+		}
+		for _, p := range tt.miss {
+			if re.MatchString(p) {
+				t.Errorf("pattern %q matches %q", tt.pattern, p)
+			}
+		}
+	}
+}
+
+func TestMatcherLastRuleWins(t *testing.T) {
+	m := NewMatcher([]Rule{
+		{ModuleDir: ".", Line: 1, Pattern: "*", Owners: []string{"@everyone"}},
+		{ModuleDir: ".", Line: 2, Pattern: "/internal/db/", Owners: []string{"@alice"}},
+		{ModuleDir: ".", Line: 3, Pattern: "/internal/db/generated/", Owners: []string{}},
+	})
+	for rel, wantLine := range map[string]int{"main.go": 1, "internal/db/db.go": 2, "internal/db/generated/x.go": 3} {
+		rule, ok := m.Match(rel)
+		if !ok || rule.Line != wantLine {
+			t.Errorf("Match(%q) = line %d (ok=%v), want line %d", rel, rule.Line, ok, wantLine)
+		}
+	}
+	if _, ok := NewMatcher(nil).Match("main.go"); ok {
+		t.Error("Match() with no rules reported a match")
+	}
+}
+
+func TestLoadNestedModule(t *testing.T) {
+	repo := t.TempDir()
+	module := filepath.Join(repo, "services", "api")
+	for _, dir := range []string{filepath.Join(repo, ".git"), filepath.Join(repo, ".github"), module} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+
+	if _, found, err := Load(module); err != nil || found {
+		t.Fatalf("Load() without CODEOWNERS found=%v err=%v", found, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, ".github", "CODEOWNERS"), []byte("/services/api/internal/ @api-team\n"), 0o644); err != nil {
+		t.Fatalf("write CODEOWNERS: %v", err)
+	}
+	rules, found, err := Load(module)
+	if err != nil || !found {
+		t.Fatalf("Load() found=%v err=%v", found, err)
+	}
+	if len(rules) != 1 || rules[0].Source != ".github/CODEOWNERS" || rules[0].ModuleDir != "services/api" {
+		t.Fatalf("Load() = %+v", rules)
+	}
+	rule, ok := NewMatcher(rules).Match("internal/x.go")
+	if !ok || rule.Owners[0] != "@api-team" {
+		t.Fatalf("Match(internal/x.go) = %+v, %v", rule, ok)
+	}
+}
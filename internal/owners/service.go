@@ -0,0 +1,129 @@
+package owners
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Result is the owners of one module-relative path and the rule that
+// assigned them. Rule is nil when no rule matches the path.
+type Result struct {
+	Path   string   `json:"path"`
+	Owners []string `json:"owners"`
+	Rule   *Rule    `json:"rule,omitempty"`
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// Rules returns the CODEOWNERS rules recorded by the last sync, in file
+// order.
+func (s *Service) Rules(ctx context.Context) ([]Rule, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT source, module_dir, line, pattern, owners
+FROM code_owners
+ORDER BY line, id;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query code owners: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []Rule
+	for rows.Next() {
+		var (
+			r   Rule
+			raw string
+		)
+		if err := rows.Scan(&r.Source, &r.ModuleDir, &r.Line, &r.Pattern, &raw); err != nil {
+			return nil, fmt.Errorf("scan code owner: %w", err)
+		}
+		if err := json.Unmarshal([]byte(raw), &r.Owners); err != nil {
+			return nil, fmt.Errorf("decode owners of %s: %w", r.Pattern, err)
+		}
+		rules = append(rules, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate code owners: %w", err)
+	}
+	return rules, nil
+}
+
+// Lookup returns the owners of rel, a module-relative file or directory.
+func (s *Service) Lookup(ctx context.Context, rel string) (Result, error) {
+	rules, err := s.Rules(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Path: rel, Owners: []string{}}
+	if rule, ok := NewMatcher(rules).Match(rel); ok {
+		result.Owners, result.Rule = rule.Owners, &rule
+	}
+	return result, nil
+}
+
+// Packages returns the owners of each indexed package that has any: the
+// owners of its files, those owning the most files first.
+func (s *Service) Packages(ctx context.Context) (map[string][]string, error) {
+	rules, err := s.Rules(ctx)
+	if err != nil || len(rules) == 0 {
+		return map[string][]string{}, err
+	}
+	m := NewMatcher(rules)
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT p.path, f.path
+FROM files f
+JOIN packages p ON p.id = f.package_id
+ORDER BY p.path, f.path;
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query package files: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]map[string]int{}
+	for rows.Next() {
+		var pkg, file string
+		if err := rows.Scan(&pkg, &file); err != nil {
+			return nil, fmt.Errorf("scan package file: %w", err)
+		}
+		rule, ok := m.Match(file)
+		if !ok {
+			continue
+		}
+		for _, owner := range rule.Owners {
+			if counts[pkg] == nil {
+				counts[pkg] = map[string]int{}
+			}
+			counts[pkg][owner]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate package files: %w", err)
+	}
+
+	byPackage := map[string][]string{}
+	for pkg, owned := range counts {
+		list := make([]string, 0, len(owned))
+		for owner := range owned {
+			list = append(list, owner)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if owned[list[i]] != owned[list[j]] {
+				return owned[list[i]] > owned[list[j]]
+			}
+			return list[i] < list[j]
+		})
+		byPackage[pkg] = list
+	}
+	return byPackage, nil
+}
@@ -0,0 +1,70 @@
+package owners
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func TestServiceLookupAndPackages(t *testing.T) {
+	root := t.TempDir()
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	ctx := context.Background()
+	svc := NewService(conn)
+
+	if byPackage, err := svc.Packages(ctx); err != nil || len(byPackage) != 0 {
+		t.Fatalf("Packages() without rules = %v, %v", byPackage, err)
+	}
+
+	for _, stmt := range []string{
+		`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES
+			(1,'internal/db','db','example.com/m/internal/db',3,30,'x','x'),
+			(2,'.','main','example.com/m',1,10,'x','x')`,
+		`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES
+			(1,1,'internal/db/db.go','go',10,'h','x','x'),
+			(2,1,'internal/db/tx.go','go',10,'h','x','x'),
+			(3,1,'internal/db/gen.go','go',10,'h','x','x'),
+			(4,2,'main.go','go',10,'h','x','x')`,
+		`INSERT INTO code_owners(source,module_dir,line,pattern,owners) VALUES
+			('CODEOWNERS','.',1,'/internal/db/','["@alice","@bob"]'),
+			('CODEOWNERS','.',2,'gen.go','["@bob"]'),
+			('CODEOWNERS','.',3,'main.go','[]')`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	byPackage, err := svc.Packages(ctx)
+	if err != nil {
+		t.Fatalf("Packages() error = %v", err)
+	}
+	if want := map[string][]string{"internal/db": {"@bob", "@alice"}}; !reflect.DeepEqual(byPackage, want) {
+		t.Fatalf("Packages() = %v, want %v", byPackage, want)
+	}
+
+	result, err := svc.Lookup(ctx, "internal/db/db.go")
+	if err != nil || result.Rule == nil || result.Rule.Line != 1 || !reflect.DeepEqual(result.Owners, []string{"@alice", "@bob"}) {
+		t.Fatalf("Lookup(db.go) = %+v, %v", result, err)
+	}
+	result, err = svc.Lookup(ctx, "main.go")
+	if err != nil || result.Rule == nil || len(result.Owners) != 0 {
+		t.Fatalf("Lookup(main.go) = %+v, %v", result, err)
+	}
+	result, err = svc.Lookup(ctx, "cmd/tool/main_test.go")
+	if err != nil || result.Rule != nil || result.Owners == nil {
+		t.Fatalf("Lookup(unmatched) = %+v, %v", result, err)
+	}
+}
@@ -22,6 +22,10 @@ type ProposePatternInput struct {
 	CheckType       string
 	CheckSpec       string
 	ModuleRoot      string
+	Policy          knowledge.EvidencePolicy
+	// VerifyInterval is how often `recon verify --due` re-checks the
+	// evidence; zero uses the check type default.
+	VerifyInterval time.Duration
 }
 
 type ProposePatternResult struct {
@@ -54,9 +58,9 @@ func (s *Service) ProposeAndVerifyPattern(ctx context.Context, in ProposePattern
 		return ProposePatternResult{}, fmt.Errorf("check spec is required")
 	}
 
-	confidence := strings.TrimSpace(in.Confidence)
-	if confidence == "" {
-		confidence = "medium"
+	confidence, err := in.Policy.Resolve(in.Confidence, in.CheckType)
+	if err != nil {
+		return ProposePatternResult{}, err
 	}
 
 	knowledgeSvc := knowledge.NewService(s.db)
@@ -73,6 +77,9 @@ func (s *Service) ProposeAndVerifyPattern(ctx context.Context, in ProposePattern
 		"check_type":       in.CheckType,
 		"check_spec":       in.CheckSpec,
 	}
+	if in.VerifyInterval > 0 {
+		entityData["verify_interval"] = int64(in.VerifyInterval / time.Second)
+	}
 	entityDataJSON, err := jsonMarshal(entityData)
 	if err != nil {
 		return ProposePatternResult{}, fmt.Errorf("marshal proposal data: %w", err)
@@ -97,33 +104,9 @@ VALUES (NULL, 'pattern', ?, 'pending', ?);
 	lastResultJSON, _ := jsonMarshal(map[string]any{"passed": outcome.Passed, "details": outcome.Details})
 
 	if outcome.Passed {
-		patternRes, err := tx.ExecContext(ctx, `
-INSERT INTO patterns (title, description, confidence, status, created_at, updated_at)
-VALUES (?, ?, ?, 'active', ?, ?);
-`, in.Title, in.Description, confidence, now, now)
+		patternID, err := promotePattern(ctx, tx, proposalID, in, confidence, string(baselineJSON), string(lastResultJSON), now)
 		if err != nil {
-			return ProposePatternResult{}, fmt.Errorf("insert pattern: %w", err)
-		}
-		patternID, _ := patternRes.LastInsertId()
-
-		if _, err := tx.ExecContext(ctx, `
-INSERT INTO evidence (entity_type, entity_id, summary, check_type, check_spec, baseline, last_verified_at, last_result, drift_status)
-VALUES ('pattern', ?, ?, ?, ?, ?, ?, ?, 'ok');
-`, patternID, in.EvidenceSummary, in.CheckType, in.CheckSpec, string(baselineJSON), now, string(lastResultJSON)); err != nil {
-			return ProposePatternResult{}, fmt.Errorf("insert pattern evidence: %w", err)
-		}
-
-		if _, err := tx.ExecContext(ctx, `
-UPDATE proposals SET status = 'promoted', verified_at = ?, promoted_at = ? WHERE id = ?;
-`, now, now, proposalID); err != nil {
-			return ProposePatternResult{}, fmt.Errorf("update proposal: %w", err)
-		}
-
-		if _, err := tx.ExecContext(ctx, `
-INSERT INTO search_index (title, content, entity_type, entity_id)
-VALUES (?, ?, 'pattern', ?);
-`, in.Title, in.Description+"\n"+in.Example+"\n"+in.EvidenceSummary, patternID); err != nil {
-			return ProposePatternResult{}, fmt.Errorf("insert search index: %w", err)
+			return ProposePatternResult{}, err
 		}
 
 		if err := tx.Commit(); err != nil {
@@ -149,16 +132,115 @@ VALUES ('proposal', ?, ?, ?, ?, ?, ?, ?, 'broken');
 	return ProposePatternResult{ProposalID: proposalID, Promoted: false, VerificationPassed: false, VerificationDetails: outcome.Details}, nil
 }
 
+// promotePattern records the pattern a proposal describes once its check
+// passed, with its evidence and search entry, and marks the proposal
+// promoted.
+func promotePattern(ctx context.Context, tx *sql.Tx, proposalID int64, in ProposePatternInput, confidence, baseline, lastResult, now string) (int64, error) {
+	patternRes, err := tx.ExecContext(ctx, `
+INSERT INTO patterns (title, description, confidence, status, created_at, updated_at)
+VALUES (?, ?, ?, 'active', ?, ?);
+`, in.Title, in.Description, confidence, now, now)
+	if err != nil {
+		return 0, fmt.Errorf("insert pattern: %w", err)
+	}
+	patternID, _ := patternRes.LastInsertId()
+
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO evidence (entity_type, entity_id, summary, check_type, check_spec, baseline, last_verified_at, last_result, drift_status, verify_interval)
+VALUES ('pattern', ?, ?, ?, ?, ?, ?, ?, 'ok', ?);
+`, patternID, in.EvidenceSummary, in.CheckType, in.CheckSpec, baseline, now, lastResult, int64(in.VerifyInterval/time.Second)); err != nil {
+		return 0, fmt.Errorf("insert pattern evidence: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+UPDATE proposals SET status = 'promoted', verified_at = ?, promoted_at = ? WHERE id = ?;
+`, now, now, proposalID); err != nil {
+		return 0, fmt.Errorf("update proposal: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO search_index (title, content, entity_type, entity_id)
+VALUES (?, ?, 'pattern', ?);
+`, in.Title, in.Description+"\n"+in.Example+"\n"+in.EvidenceSummary, patternID); err != nil {
+		return 0, fmt.Errorf("insert search index: %w", err)
+	}
+	return patternID, nil
+}
+
+// RetryPatternProposal re-runs the check of a pending pattern proposal
+// against the current code and promotes the pattern when it passes.
+func (s *Service) RetryPatternProposal(ctx context.Context, id int64, moduleRoot string) (ProposePatternResult, error) {
+	knowledgeSvc := knowledge.NewService(s.db)
+	p, err := knowledgeSvc.PendingProposal(ctx, id)
+	if err != nil {
+		return ProposePatternResult{}, err
+	}
+	if p.EntityType != "pattern" {
+		return ProposePatternResult{}, fmt.Errorf("proposal %d is a %s, not a pattern", id, p.EntityType)
+	}
+	var data struct {
+		Example string `json:"example"`
+	}
+	if err := json.Unmarshal(p.Data, &data); err != nil {
+		return ProposePatternResult{}, fmt.Errorf("decode proposal %d: %w", id, err)
+	}
+	in := ProposePatternInput{
+		Title: p.Title, Description: p.Description, Example: data.Example, EvidenceSummary: p.EvidenceSummary,
+		CheckType: p.CheckType, CheckSpec: p.CheckSpec, ModuleRoot: moduleRoot,
+		VerifyInterval: time.Duration(p.VerifyInterval) * time.Second,
+	}
+
+	outcome := knowledgeSvc.RunCheckPublic(ctx, in.CheckType, in.CheckSpec, moduleRoot)
+	if !outcome.Passed {
+		if err := knowledgeSvc.RecordProposalCheck(ctx, id, outcome); err != nil {
+			return ProposePatternResult{}, err
+		}
+		return ProposePatternResult{ProposalID: id, VerificationDetails: outcome.Details}, nil
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	baselineJSON, _ := jsonMarshal(outcome.Baseline)
+	lastResultJSON, _ := jsonMarshal(map[string]any{"passed": outcome.Passed, "details": outcome.Details})
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ProposePatternResult{}, fmt.Errorf("begin pattern tx: %w", err)
+	}
+	defer tx.Rollback()
+	patternID, err := promotePattern(ctx, tx, id, in, p.Confidence, string(baselineJSON), string(lastResultJSON), now)
+	if err != nil {
+		return ProposePatternResult{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM evidence WHERE entity_type = 'proposal' AND entity_id = ?;`, id); err != nil {
+		return ProposePatternResult{}, fmt.Errorf("delete proposal evidence: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return ProposePatternResult{}, fmt.Errorf("commit pattern tx: %w", err)
+	}
+	return ProposePatternResult{ProposalID: id, PatternID: patternID, Promoted: true, VerificationPassed: true, VerificationDetails: outcome.Details}, nil
+}
+
 var ErrNotFound = fmt.Errorf("not found")
 
 type UpdatePatternInput struct {
 	Title       string
 	Description string
+	Confidence  string
+	Example     string
 }
 
+// promotedProposalJoin finds the proposal a pattern was promoted from, which
+// is where its example is kept, by matching the evidence summary it recorded.
+const promotedProposalJoin = `
+		 LEFT JOIN entity_evidence e ON e.entity_type = 'pattern' AND e.entity_id = p.id
+		 LEFT JOIN proposals pr ON pr.entity_type = 'pattern'
+		     AND pr.status = 'promoted'
+		     AND e.summary IS NOT NULL
+		     AND json_extract(pr.entity_data, '$.evidence_summary') = e.summary`
+
 func (s *Service) UpdatePattern(ctx context.Context, id int64, in UpdatePatternInput) error {
-	if strings.TrimSpace(in.Title) == "" && strings.TrimSpace(in.Description) == "" {
-		return fmt.Errorf("at least one field (title, description) is required")
+	if strings.TrimSpace(in.Title) == "" && strings.TrimSpace(in.Description) == "" &&
+		strings.TrimSpace(in.Confidence) == "" && strings.TrimSpace(in.Example) == "" {
+		return fmt.Errorf("at least one field (title, description, confidence, example) is required")
 	}
 
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -174,6 +256,16 @@ func (s *Service) UpdatePattern(ctx context.Context, id int64, in UpdatePatternI
 		setClauses = append(setClauses, "description = ?")
 		args = append(args, strings.TrimSpace(in.Description))
 	}
+	if strings.TrimSpace(in.Confidence) != "" {
+		confidence := strings.ToLower(strings.TrimSpace(in.Confidence))
+		switch confidence {
+		case "low", "medium", "high":
+		default:
+			return fmt.Errorf("confidence must be low, medium, or high")
+		}
+		setClauses = append(setClauses, "confidence = ?")
+		args = append(args, confidence)
+	}
 	args = append(args, id)
 
 	query := "UPDATE patterns SET " + strings.Join(setClauses, ", ") +
@@ -187,32 +279,63 @@ func (s *Service) UpdatePattern(ctx context.Context, id int64, in UpdatePatternI
 		return fmt.Errorf("pattern %d: %w", id, ErrNotFound)
 	}
 
+	if strings.TrimSpace(in.Example) != "" {
+		res, err := s.db.ExecContext(ctx, `
+UPDATE proposals SET entity_data = json_set(entity_data, '$.example', ?)
+WHERE id = (SELECT pr.id FROM patterns p`+promotedProposalJoin+`
+		 WHERE p.id = ?);`, strings.TrimSpace(in.Example), id)
+		if err != nil {
+			return fmt.Errorf("update pattern example: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return fmt.Errorf("pattern %d has no promoted proposal to hold its example", id)
+		}
+	}
+
+	return ReindexPattern(ctx, s.db, id)
+}
+
+// execQueryer is satisfied by both *sql.DB and *sql.Tx.
+type execQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// ReindexPattern refreshes the search_index entry of pattern id from its
+// title, description, example, and evidence summary.
+func ReindexPattern(ctx context.Context, q execQueryer, id int64) error {
 	var title, description, example, evidenceSummary string
-	if err := s.db.QueryRowContext(ctx,
+	if err := q.QueryRowContext(ctx,
 		`SELECT p.title, p.description,
 		        COALESCE(json_extract(pr.entity_data, '$.example'), ''),
 		        COALESCE(e.summary, '')
-		 FROM patterns p
-		 LEFT JOIN evidence e ON e.entity_type = 'pattern' AND e.entity_id = p.id
-		 LEFT JOIN proposals pr ON pr.entity_type = 'pattern'
-		     AND pr.status = 'promoted'
-		     AND e.summary IS NOT NULL
-		     AND json_extract(pr.entity_data, '$.evidence_summary') = e.summary
+		 FROM patterns p`+promotedProposalJoin+`
 		 WHERE p.id = ?`, id,
 	).Scan(&title, &description, &example, &evidenceSummary); err != nil {
 		return fmt.Errorf("read updated pattern for reindex: %w", err)
 	}
 
-	if _, err := s.db.ExecContext(ctx,
+	if _, err := q.ExecContext(ctx,
 		`UPDATE search_index SET title = ?, content = ? WHERE entity_type = 'pattern' AND entity_id = ?`,
 		title, description+"\n"+example+"\n"+evidenceSummary, id,
 	); err != nil {
 		return fmt.Errorf("reindex pattern: %w", err)
 	}
-
 	return nil
 }
 
+// ReindexEntity refreshes the search_index entry of a decision or pattern by
+// its entity type, for code that writes both kinds of knowledge.
+func ReindexEntity(ctx context.Context, q execQueryer, entityType string, id int64) error {
+	switch entityType {
+	case "decision":
+		return knowledge.ReindexDecision(ctx, q, id)
+	case "pattern":
+		return ReindexPattern(ctx, q, id)
+	}
+	return fmt.Errorf("reindex %s %d: unknown entity type", entityType, id)
+}
+
 func (s *Service) ArchivePattern(ctx context.Context, id int64) error {
 	res, err := s.db.ExecContext(ctx,
 		`UPDATE patterns SET status = 'archived', updated_at = ? WHERE id = ? AND status = 'active';`,
@@ -240,9 +363,9 @@ func (s *Service) ListPatterns(ctx context.Context) ([]PatternListItem, error) {
 	rows, err := s.db.QueryContext(ctx, `
 SELECT p.id, p.title, p.confidence, p.status, COALESCE(e.drift_status, 'ok'), p.updated_at
 FROM patterns p
-LEFT JOIN evidence e ON e.entity_type = 'pattern' AND e.entity_id = p.id
+LEFT JOIN entity_evidence e ON e.entity_type = 'pattern' AND e.entity_id = p.id
 WHERE p.status = 'active'
-ORDER BY p.updated_at DESC;
+ORDER BY p.updated_at DESC, p.id DESC;
 `)
 	if err != nil {
 		return nil, fmt.Errorf("query patterns: %w", err)
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/knowledge"
 )
 
 func patternTestDB(t *testing.T) (*sql.DB, string, func()) {
@@ -139,6 +140,33 @@ func TestProposePatternDefaultConfidence(t *testing.T) {
 	}
 }
 
+func TestProposePatternEvidencePolicy(t *testing.T) {
+	conn, root, cleanup := patternTestDB(t)
+	defer cleanup()
+
+	in := ProposePatternInput{
+		Title:           "Policy",
+		Description:     "desc",
+		EvidenceSummary: "file exists",
+		CheckType:       "file_exists",
+		CheckSpec:       `{"path":"go.mod"}`,
+		ModuleRoot:      root,
+		Policy: knowledge.EvidencePolicy{
+			DefaultConfidence: "high",
+			RequiredChecks:    map[string][]string{"high": {"grep_pattern"}},
+		},
+	}
+	if _, err := NewService(conn).ProposeAndVerifyPattern(context.Background(), in); !errors.Is(err, knowledge.ErrEvidenceTooWeak) {
+		t.Fatalf("expected ErrEvidenceTooWeak, got %v", err)
+	}
+
+	in.Confidence = "medium"
+	result, err := NewService(conn).ProposeAndVerifyPattern(context.Background(), in)
+	if err != nil || !result.Promoted {
+		t.Fatalf("propose medium = %+v, %v", result, err)
+	}
+}
+
 func seedSymbol(t *testing.T, conn *sql.DB, name string) {
 	t.Helper()
 	now := "2024-01-01T00:00:00Z"
@@ -163,7 +191,7 @@ func seedSymbol(t *testing.T, conn *sql.DB, name string) {
 	if err := conn.QueryRow(`SELECT id FROM files WHERE path = 'pkg/file.go'`).Scan(&fileID); err != nil {
 		t.Fatalf("select file: %v", err)
 	}
-	if _, err := conn.Exec(`INSERT INTO symbols (file_id, kind, name, signature, body, line_start, line_end, exported, receiver) VALUES (?, 'func', ?, 'func()','{}', 1, 5, 1, '')`, fileID, name); err != nil {
+	if _, err := conn.Exec(`INSERT INTO symbols (file_id, kind, name, signature, line_start, line_end, exported, receiver) VALUES (?, 'func', ?, 'func()', 1, 5, 1, '')`, fileID, name); err != nil {
 		t.Fatalf("insert symbol: %v", err)
 	}
 }
@@ -410,6 +438,57 @@ func TestUpdatePatternTitle(t *testing.T) {
 	}
 }
 
+func TestUpdatePatternConfidenceAndExample(t *testing.T) {
+	conn, root, cleanup := patternTestDB(t)
+	defer cleanup()
+	svc := NewService(conn)
+
+	res, err := svc.ProposeAndVerifyPattern(context.Background(), ProposePatternInput{
+		Title:           "Wrap errors",
+		Description:     "wrap with %w",
+		Example:         "old example",
+		EvidenceSummary: "go.mod exists",
+		CheckType:       "file_exists",
+		CheckSpec:       `{"path":"go.mod"}`,
+		ModuleRoot:      root,
+	})
+	if err != nil {
+		t.Fatalf("seed pattern: %v", err)
+	}
+
+	if err := svc.UpdatePattern(context.Background(), res.PatternID, UpdatePatternInput{
+		Confidence: " High ",
+		Example:    "fmt.Errorf(\"open: %w\", err)",
+	}); err != nil {
+		t.Fatalf("UpdatePattern: %v", err)
+	}
+
+	var confidence, example, content string
+	if err := conn.QueryRow(`SELECT confidence FROM patterns WHERE id = ?`, res.PatternID).Scan(&confidence); err != nil {
+		t.Fatalf("query confidence: %v", err)
+	}
+	if err := conn.QueryRow(`SELECT json_extract(entity_data, '$.example') FROM proposals WHERE id = ?`, res.ProposalID).Scan(&example); err != nil {
+		t.Fatalf("query example: %v", err)
+	}
+	if err := conn.QueryRow(`SELECT content FROM search_index WHERE entity_type = 'pattern' AND entity_id = ?`, res.PatternID).Scan(&content); err != nil {
+		t.Fatalf("query search index: %v", err)
+	}
+	if confidence != "high" || example != `fmt.Errorf("open: %w", err)` || !strings.Contains(content, "open: %w") || strings.Contains(content, "old example") {
+		t.Fatalf("confidence=%q example=%q content=%q", confidence, example, content)
+	}
+
+	if err := svc.UpdatePattern(context.Background(), res.PatternID, UpdatePatternInput{Confidence: "certain"}); err == nil || !strings.Contains(err.Error(), "confidence must be") {
+		t.Fatalf("expected confidence validation error, got %v", err)
+	}
+
+	if _, err := conn.Exec(`INSERT INTO patterns (id, title, description, confidence, status, created_at, updated_at) VALUES (99, 'Manual', '', 'low', 'active', 'x', 'x')`); err != nil {
+		t.Fatalf("seed manual pattern: %v", err)
+	}
+	if err := svc.UpdatePattern(context.Background(), 99, UpdatePatternInput{Example: "x"}); err == nil || !strings.Contains(err.Error(), "no promoted proposal") {
+		t.Fatalf("expected missing proposal error, got %v", err)
+	}
+}
+
 func TestUpdatePattern_NotFound(t *testing.T) {
 	conn, _, cleanup := patternTestDB(t)
 	defer cleanup()
@@ -433,3 +512,79 @@ func TestUpdatePattern_EmptyInput(t *testing.T) {
 		t.Fatal("expected error for empty UpdatePatternInput")
 	}
 }
+
+func TestRetryPatternProposal(t *testing.T) {
+	conn, root, cleanup := patternTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	svc := NewService(conn)
+
+	result, err := svc.ProposeAndVerifyPattern(ctx, ProposePatternInput{
+		Title:           "Uses panic",
+		Description:     "Code panics on impossible states",
+		Example:         `panic("unreachable")`,
+		EvidenceSummary: "grep finds panic usage",
+		CheckType:       "grep_pattern",
+		CheckSpec:       `{"pattern":"panic\\(","scope":"*.go"}`,
+		ModuleRoot:      root,
+	})
+	if err != nil || result.Promoted {
+		t.Fatalf("propose: %+v, %v", result, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "must.go"), []byte("package main\nfunc must() { panic(\"unreachable\") }\n"), 0o644); err != nil {
+		t.Fatalf("write must.go: %v", err)
+	}
+	retried, err := svc.RetryPatternProposal(ctx, result.ProposalID, root)
+	if err != nil || !retried.Promoted || retried.PatternID == 0 {
+		t.Fatalf("RetryPatternProposal = %+v, %v", retried, err)
+	}
+	var description, content string
+	if err := conn.QueryRow(`SELECT description FROM patterns WHERE id = ?`, retried.PatternID).Scan(&description); err != nil || description != "Code panics on impossible states" {
+		t.Fatalf("pattern description = %q, %v", description, err)
+	}
+	if err := conn.QueryRow(`SELECT content FROM search_index WHERE entity_type = 'pattern' AND entity_id = ?`, retried.PatternID).Scan(&content); err != nil || !strings.Contains(content, `panic("unreachable")`) {
+		t.Fatalf("search entry = %q, %v", content, err)
+	}
+	if _, err := svc.RetryPatternProposal(ctx, result.ProposalID, root); !errors.Is(err, knowledge.ErrNotFound) {
+		t.Fatalf("retry of promoted proposal: %v", err)
+	}
+}
+
+func TestReindexEntity(t *testing.T) {
+	conn, root, cleanup := patternTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	res, err := NewService(conn).ProposeAndVerifyPattern(ctx, ProposePatternInput{
+		Title:           "Indexed Pattern",
+		Description:     "before",
+		EvidenceSummary: "go.mod exists",
+		CheckType:       "file_exists",
+		CheckSpec:       `{"path":"go.mod"}`,
+		ModuleRoot:      root,
+	})
+	if err != nil {
+		t.Fatalf("seed pattern: %v", err)
+	}
+	if _, err := conn.Exec(`UPDATE patterns SET description = 'after' WHERE id = ?`, res.PatternID); err != nil {
+		t.Fatalf("update description: %v", err)
+	}
+	if err := ReindexEntity(ctx, conn, "pattern", res.PatternID); err != nil {
+		t.Fatalf("ReindexEntity: %v", err)
+	}
+	var content string
+	if err := conn.QueryRow(`SELECT content FROM search_index WHERE entity_type = 'pattern' AND entity_id = ?`, res.PatternID).Scan(&content); err != nil {
+		t.Fatalf("query search_index: %v", err)
+	}
+	if !strings.HasPrefix(content, "after\n") {
+		t.Fatalf("expected the search entry refreshed, got %q", content)
+	}
+
+	if err := ReindexEntity(ctx, conn, "decision", 999); err == nil {
+		t.Fatal("expected an error for a missing decision")
+	}
+	if err := ReindexEntity(ctx, conn, "constraint", res.PatternID); err == nil || !strings.Contains(err.Error(), "unknown entity type") {
+		t.Fatalf("expected an unknown entity type error, got %v", err)
+	}
+}
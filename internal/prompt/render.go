@@ -0,0 +1,102 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	knowledgeHeading = "\n## Decisions, patterns, and constraints\n\n"
+	symbolsHeading   = "\n## Symbols\n\n"
+	filesHeading     = "\n## Files\n\n"
+)
+
+// Render formats the bundle as a markdown document to paste into a prompt.
+// Empty sections are left out.
+func Render(b Bundle) string {
+	var out strings.Builder
+	out.WriteString(renderHeader(b, b.Tokens))
+	if len(b.Knowledge) > 0 {
+		out.WriteString(knowledgeHeading)
+		for _, k := range b.Knowledge {
+			out.WriteString(renderKnowledge(k))
+		}
+	}
+	if len(b.Symbols) > 0 {
+		out.WriteString(symbolsHeading)
+		for _, sym := range b.Symbols {
+			out.WriteString(renderSymbol(sym))
+		}
+	}
+	if len(b.Files) > 0 {
+		out.WriteString(filesHeading)
+		for _, f := range b.Files {
+			out.WriteString(renderFile(f))
+		}
+	}
+	out.WriteString(renderOmitted(b.Omitted))
+	return out.String()
+}
+
+func renderHeader(b Bundle, tokens int) string {
+	return fmt.Sprintf("# Context: %s\n\nKeywords: %s\nGathered from the recon index: about %d of %d tokens.\n",
+		b.Task, strings.Join(b.Keywords, ", "), tokens, b.Budget)
+}
+
+func renderKnowledge(k Knowledge) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "- %s #%d %s [%s, drift=%s]\n", k.EntityType, k.ID, k.Title, k.Confidence, k.Drift)
+	if k.Reasoning != "" {
+		fmt.Fprintf(&out, "  Why: %s\n", strings.ReplaceAll(k.Reasoning, "\n", "\n  "))
+	}
+	if k.Evidence != "" {
+		fmt.Fprintf(&out, "  Evidence: %s\n", k.Evidence)
+	}
+	return out.String()
+}
+
+func renderSymbol(sym Symbol) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "- %s %s (%s:%d-%d, package %s)\n", sym.Kind, symbolLabel(sym), sym.FilePath, sym.LineStart, sym.LineEnd, sym.Package)
+	switch {
+	case sym.Body != "":
+		lang := sym.Language
+		if lang == "" {
+			lang = "go"
+		}
+		fmt.Fprintf(&out, "\n```%s\n%s\n```\n\n", lang, sym.Body)
+	case sym.Signature != "":
+		fmt.Fprintf(&out, "  `%s`\n", firstLine(sym.Signature))
+	}
+	return out.String()
+}
+
+func renderFile(f File) string {
+	var parts []string
+	if len(f.Symbols) > 0 {
+		parts = append(parts, strings.Join(f.Symbols, ", "))
+	}
+	if len(f.Knowledge) > 0 {
+		parts = append(parts, "linked to "+strings.Join(f.Knowledge, ", "))
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("- %s\n", f.FilePath)
+	}
+	return fmt.Sprintf("- %s: %s\n", f.FilePath, strings.Join(parts, "; "))
+}
+
+func renderOmitted(o Omitted) string {
+	if o.Knowledge+o.Symbols+o.Files == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\nLeft out to fit the budget: %d knowledge entries, %d symbols, %d files.\n", o.Knowledge, o.Symbols, o.Files)
+}
+
+// firstLine shortens a multi-line signature, such as a struct type's field
+// list, to its first line.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " ... }"
+	}
+	return s
+}
@@ -0,0 +1,384 @@
+// Package prompt bundles what the index knows about a task description into
+// one context document: the decisions and patterns recall finds for its
+// keywords, the symbols whose names match them, and the files to open,
+// trimmed to fit a token budget.
+package prompt
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/robertguss/recon/internal/find"
+	"github.com/robertguss/recon/internal/recall"
+)
+
+// DefaultBudget is the token budget used when Options.Budget is not positive.
+const DefaultBudget = 4000
+
+// Candidate limits before the budget is applied.
+const (
+	maxKnowledge     = 20
+	maxSymbols       = 40
+	maxFiles         = 20
+	recallPerKeyword = 10
+	symbolScan       = 500
+)
+
+// ErrNoKeywords is returned when a task has no words left to search for once
+// stop words are removed.
+var ErrNoKeywords = errors.New("task has no keywords to search for")
+
+type Options struct {
+	// Budget is the approximate number of tokens the rendered bundle may
+	// use; see EstimateTokens.
+	Budget int
+	// Branch is the checked-out git branch, passed on to recall.
+	Branch string
+}
+
+// Bundle is the context gathered for one task. Items are ordered by
+// relevance and hold only what fit the budget; Omitted counts the rest.
+type Bundle struct {
+	Task      string      `json:"task"`
+	Keywords  []string    `json:"keywords"`
+	Budget    int         `json:"budget"`
+	Tokens    int         `json:"estimated_tokens"`
+	Knowledge []Knowledge `json:"knowledge"`
+	Symbols   []Symbol    `json:"symbols"`
+	Files     []File      `json:"files"`
+	Omitted   Omitted     `json:"omitted"`
+}
+
+// Knowledge is an active decision, pattern, or constraint that recall found
+// for one or more keywords, listed in Matched.
+type Knowledge struct {
+	EntityType string   `json:"entity_type"`
+	ID         int64    `json:"id"`
+	Title      string   `json:"title"`
+	Reasoning  string   `json:"reasoning,omitempty"`
+	Confidence string   `json:"confidence"`
+	Evidence   string   `json:"evidence_summary,omitempty"`
+	Drift      string   `json:"drift_status"`
+	Matched    []string `json:"matched"`
+	files      []string
+}
+
+// Symbol is an indexed symbol whose name contains a keyword. Body is set
+// for symbols named exactly by a keyword when it fits the budget.
+type Symbol struct {
+	Kind      string   `json:"kind"`
+	Name      string   `json:"name"`
+	Receiver  string   `json:"receiver,omitempty"`
+	Package   string   `json:"package"`
+	FilePath  string   `json:"file_path"`
+	LineStart int      `json:"line_start"`
+	LineEnd   int      `json:"line_end"`
+	Language  string   `json:"language,omitempty"`
+	Signature string   `json:"signature,omitempty"`
+	Body      string   `json:"body,omitempty"`
+	Matched   []string `json:"matched"`
+	score     int
+	exact     bool
+}
+
+// File is a file holding matched symbols or linked to matched knowledge.
+type File struct {
+	FilePath  string   `json:"file_path"`
+	Symbols   []string `json:"symbols,omitempty"`
+	Knowledge []string `json:"knowledge,omitempty"`
+	score     int
+}
+
+// Omitted counts the candidates left out to stay within the budget.
+type Omitted struct {
+	Knowledge int `json:"knowledge"`
+	Symbols   int `json:"symbols"`
+	Files     int `json:"files"`
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// Build extracts the task's keywords, recalls knowledge and finds symbols
+// for them, and keeps the most relevant of each that fit opts.Budget.
+func (s *Service) Build(ctx context.Context, moduleRoot, task string, opts Options) (Bundle, error) {
+	if opts.Budget <= 0 {
+		opts.Budget = DefaultBudget
+	}
+	keywords := recall.Keywords(task)
+	if len(keywords) == 0 {
+		return Bundle{}, ErrNoKeywords
+	}
+
+	knowledge, err := s.recallKeywords(ctx, keywords, opts.Branch)
+	if err != nil {
+		return Bundle{}, err
+	}
+	symbols, err := s.matchSymbols(ctx, keywords)
+	if err != nil {
+		return Bundle{}, err
+	}
+	files := rankFiles(keywords, knowledge, symbols)
+
+	b := Bundle{
+		Task:      strings.TrimSpace(task),
+		Keywords:  keywords,
+		Budget:    opts.Budget,
+		Knowledge: []Knowledge{},
+		Symbols:   []Symbol{},
+		Files:     []File{},
+	}
+	fit(&b, moduleRoot, knowledge, symbols, files)
+	b.Tokens = EstimateTokens(Render(b))
+	return b, nil
+}
+
+// recallKeywords recalls each keyword separately and ranks the items by how
+// many keywords found them, then by the rank of their first hit.
+func (s *Service) recallKeywords(ctx context.Context, keywords []string, branch string) ([]Knowledge, error) {
+	svc := recall.NewService(s.db)
+	byKey := map[string]*Knowledge{}
+	var order []string
+	for _, keyword := range keywords {
+		result, err := svc.Recall(ctx, keyword, recall.RecallOptions{Limit: recallPerKeyword, Branch: branch})
+		if err != nil {
+			return nil, fmt.Errorf("recall %q: %w", keyword, err)
+		}
+		for _, item := range result.Items {
+			id := item.DecisionID
+			switch item.EntityType {
+			case "pattern":
+				id = item.PatternID
+			case "constraint":
+				id = item.ConstraintID
+			}
+			key := fmt.Sprintf("%s:%d", item.EntityType, id)
+			k, ok := byKey[key]
+			if !ok {
+				k = &Knowledge{
+					EntityType: item.EntityType,
+					ID:         id,
+					Title:      item.Title,
+					Reasoning:  item.Reasoning,
+					Confidence: item.Confidence,
+					Evidence:   item.EvidenceSummary,
+					Drift:      item.EvidenceDrift,
+				}
+				for _, edge := range item.ConnectedEdges {
+					if edge.ToType == "file" {
+						k.files = append(k.files, edge.ToRef)
+					}
+				}
+				byKey[key] = k
+				order = append(order, key)
+			}
+			if !slices.Contains(k.Matched, keyword) {
+				k.Matched = append(k.Matched, keyword)
+			}
+		}
+	}
+
+	knowledge := make([]Knowledge, 0, len(order))
+	for _, key := range order {
+		knowledge = append(knowledge, *byKey[key])
+	}
+	sort.SliceStable(knowledge, func(i, j int) bool {
+		return len(knowledge[i].Matched) > len(knowledge[j].Matched)
+	})
+	if len(knowledge) > maxKnowledge {
+		knowledge = knowledge[:maxKnowledge]
+	}
+	return knowledge, nil
+}
+
+// matchSymbols finds the symbols whose names contain a keyword, ignoring
+// case. A name equal to a keyword scores 3 and one containing it 1; a file
+// path containing a keyword adds 1.
+func (s *Service) matchSymbols(ctx context.Context, keywords []string) ([]Symbol, error) {
+	quoted := make([]string, len(keywords))
+	for i, keyword := range keywords {
+		quoted[i] = regexp.QuoteMeta(keyword)
+	}
+	re := regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+	result, err := find.NewService(s.db).ListRegex(ctx, re, find.QueryOptions{}, symbolScan)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]Symbol, 0, len(result.Symbols))
+	for _, sym := range result.Symbols {
+		m := Symbol{
+			Kind:      sym.Kind,
+			Name:      sym.Name,
+			Receiver:  sym.Receiver,
+			Package:   sym.Package,
+			FilePath:  sym.FilePath,
+			LineStart: sym.LineStart,
+			LineEnd:   sym.LineEnd,
+			Language:  sym.Language,
+			Signature: sym.Signature,
+		}
+		name, path := strings.ToLower(sym.Name), strings.ToLower(sym.FilePath)
+		for _, keyword := range keywords {
+			switch {
+			case name == keyword:
+				m.score += 3
+				m.exact = true
+			case strings.Contains(name, keyword):
+				m.score++
+			default:
+				continue
+			}
+			m.Matched = append(m.Matched, keyword)
+		}
+		for _, keyword := range keywords {
+			if strings.Contains(path, keyword) {
+				m.score++
+			}
+		}
+		symbols = append(symbols, m)
+	}
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return symbols[i].score > symbols[j].score
+	})
+	if len(symbols) > maxSymbols {
+		symbols = symbols[:maxSymbols]
+	}
+	return symbols, nil
+}
+
+// rankFiles scores each file by the symbols matched in it and the knowledge
+// linked to it, plus one for each keyword in its path.
+func rankFiles(keywords []string, knowledge []Knowledge, symbols []Symbol) []File {
+	byPath := map[string]*File{}
+	get := func(path string) *File {
+		f, ok := byPath[path]
+		if !ok {
+			f = &File{FilePath: path}
+			lower := strings.ToLower(path)
+			for _, keyword := range keywords {
+				if strings.Contains(lower, keyword) {
+					f.score++
+				}
+			}
+			byPath[path] = f
+		}
+		return f
+	}
+	for _, sym := range symbols {
+		f := get(sym.FilePath)
+		f.Symbols = append(f.Symbols, symbolLabel(sym))
+		f.score += sym.score
+	}
+	for _, k := range knowledge {
+		for _, path := range k.files {
+			f := get(path)
+			f.Knowledge = append(f.Knowledge, fmt.Sprintf("%s #%d", k.EntityType, k.ID))
+			f.score += 2 * len(k.Matched)
+		}
+	}
+
+	files := make([]File, 0, len(byPath))
+	for _, f := range byPath {
+		files = append(files, *f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].score != files[j].score {
+			return files[i].score > files[j].score
+		}
+		return files[i].FilePath < files[j].FilePath
+	})
+	if len(files) > maxFiles {
+		files = files[:maxFiles]
+	}
+	return files
+}
+
+// fit adds candidates to b while the rendered bundle stays within
+// b.Budget, taking the next most relevant knowledge entry, symbol, and file
+// in turn so that no section crowds out the others. A candidate that does
+// not fit is counted as omitted and smaller ones after it are still tried.
+// A symbol named by a keyword carries its body when the body fits in a
+// quarter of the budget and in what is left of it.
+func fit(b *Bundle, moduleRoot string, knowledge []Knowledge, symbols []Symbol, files []File) {
+	b.Omitted = Omitted{Knowledge: len(knowledge), Symbols: len(symbols), Files: len(files)}
+	used := EstimateTokens(renderHeader(*b, b.Budget)) + EstimateTokens(renderOmitted(b.Omitted))
+	// take reports whether text fits, charging heading too when text is the
+	// first entry of its section.
+	take := func(heading string, started bool, text string) bool {
+		cost := EstimateTokens(text)
+		if !started {
+			cost += EstimateTokens(heading)
+		}
+		if used+cost > b.Budget {
+			return false
+		}
+		used += cost
+		return true
+	}
+
+	for i := 0; i < max(len(knowledge), len(symbols), len(files)); i++ {
+		if i < len(knowledge) && take(knowledgeHeading, len(b.Knowledge) > 0, renderKnowledge(knowledge[i])) {
+			b.Knowledge = append(b.Knowledge, knowledge[i])
+			b.Omitted.Knowledge--
+		}
+		if i < len(symbols) {
+			sym, withBody := symbols[i], symbols[i]
+			if sym.exact {
+				withBody.Body = readBody(moduleRoot, sym)
+			}
+			switch {
+			case withBody.Body != "" && EstimateTokens(withBody.Body) <= b.Budget/4 && take(symbolsHeading, len(b.Symbols) > 0, renderSymbol(withBody)):
+				b.Symbols = append(b.Symbols, withBody)
+				b.Omitted.Symbols--
+			case take(symbolsHeading, len(b.Symbols) > 0, renderSymbol(sym)):
+				b.Symbols = append(b.Symbols, sym)
+				b.Omitted.Symbols--
+			}
+		}
+		if i < len(files) && take(filesHeading, len(b.Files) > 0, renderFile(files[i])) {
+			b.Files = append(b.Files, files[i])
+			b.Omitted.Files--
+		}
+	}
+}
+
+// readBody returns the source lines of sym, or "" when the file cannot be
+// read or has changed since the last sync.
+func readBody(moduleRoot string, sym Symbol) string {
+	data, err := os.ReadFile(filepath.Join(moduleRoot, filepath.FromSlash(sym.FilePath)))
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(string(data), "\n")
+	if sym.LineStart < 1 || sym.LineEnd < sym.LineStart || sym.LineEnd > len(lines) {
+		return ""
+	}
+	return strings.Join(lines[sym.LineStart-1:sym.LineEnd], "\n")
+}
+
+// EstimateTokens approximates the number of tokens in text as one per four
+// bytes, the usual rule of thumb for English prose and source code.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+func symbolLabel(sym Symbol) string {
+	if sym.Receiver != "" {
+		return strings.TrimPrefix(sym.Receiver, "*") + "." + sym.Name
+	}
+	return sym.Name
+}
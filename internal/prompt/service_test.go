@@ -0,0 +1,101 @@
+package prompt
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/testutil"
+)
+
+func setupPromptModule(t *testing.T) (string, *sql.DB) {
+	t.Helper()
+	root, conn := testutil.Module(t, map[string]string{
+		"go.mod":         "module example.com/m\n",
+		"main.go":        "package main\nimport \"example.com/m/sync\"\nfunc main() { sync.Sync() }\n",
+		"sync/sync.go":   "package sync\n\n// Sync copies everything.\nfunc Sync() error {\n\treturn syncOnce()\n}\n\nfunc syncOnce() error { return nil }\n",
+		"sync/retry.go":  "package sync\n\ntype RetryPolicy struct{ Attempts int }\n",
+		"store/store.go": "package store\n\nfunc Open() {}\n",
+	})
+	testutil.Seed(t, conn,
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+			(1,'Sync retries are bounded','A retry loop never runs more than three times','high','active','x','x'),
+			(2,'Stores open lazily','r','medium','active','x','x')`,
+		`INSERT INTO search_index(title,content,entity_type,entity_id) VALUES
+			('Sync retries are bounded','A retry loop never runs more than three times','decision',1),
+			('Stores open lazily','r','decision',2)`,
+		`INSERT INTO edges(from_type,from_id,to_type,to_ref,relation,source,confidence,created_at) VALUES
+			('decision',1,'file','sync/retry.go','affects','manual','high','x')`,
+	)
+	return root, conn
+}
+
+func TestBuild(t *testing.T) {
+	root, conn := setupPromptModule(t)
+	svc := NewService(conn)
+
+	b, err := svc.Build(context.Background(), root, "add retry to the sync command", Options{})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if b.Budget != DefaultBudget || b.Tokens == 0 || b.Tokens > b.Budget {
+		t.Fatalf("budget = %d, tokens = %d", b.Budget, b.Tokens)
+	}
+	if len(b.Knowledge) != 1 || b.Knowledge[0].ID != 1 || !reflect.DeepEqual(b.Knowledge[0].Matched, []string{"retry", "sync"}) {
+		t.Fatalf("knowledge = %+v", b.Knowledge)
+	}
+	if len(b.Symbols) == 0 || b.Symbols[0].Name != "Sync" || !strings.Contains(b.Symbols[0].Body, "return syncOnce()") {
+		t.Fatalf("symbols = %+v", b.Symbols)
+	}
+	for _, sym := range b.Symbols {
+		if sym.Name == "Open" {
+			t.Fatalf("unmatched symbol bundled: %+v", sym)
+		}
+		if sym.Name != "Sync" && sym.Body != "" {
+			t.Fatalf("body for inexact match %s", sym.Name)
+		}
+	}
+	if len(b.Files) == 0 || b.Files[0].FilePath != "sync/retry.go" || !reflect.DeepEqual(b.Files[0].Knowledge, []string{"decision #1"}) {
+		t.Fatalf("files = %+v", b.Files)
+	}
+
+	doc := Render(b)
+	for _, want := range []string{
+		"# Context: add retry to the sync command",
+		"Keywords: retry, sync, command",
+		"- decision #1 Sync retries are bounded [high, drift=ok]",
+		"- func Sync (sync/sync.go:4-6, package sync)\n\n```go\nfunc Sync() error {",
+		"- type RetryPolicy (sync/retry.go:3-3, package sync)",
+		"- sync/retry.go: RetryPolicy; linked to decision #1",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Fatalf("document missing %q:\n%s", want, doc)
+		}
+	}
+	if strings.Contains(doc, "Left out") {
+		t.Fatalf("nothing should be left out:\n%s", doc)
+	}
+
+	small, err := svc.Build(context.Background(), root, "add retry to the sync command", Options{Budget: 90})
+	if err != nil {
+		t.Fatalf("Build(small): %v", err)
+	}
+	if small.Tokens > 90 || small.Omitted.Symbols+small.Omitted.Files == 0 {
+		t.Fatalf("small bundle = %d tokens, omitted %+v", small.Tokens, small.Omitted)
+	}
+	for _, sym := range small.Symbols {
+		if sym.Body != "" {
+			t.Fatalf("body kept under a small budget: %+v", sym)
+		}
+	}
+	if !strings.Contains(Render(small), "Left out to fit the budget:") {
+		t.Fatalf("small document does not report omissions:\n%s", Render(small))
+	}
+
+	if _, err := svc.Build(context.Background(), root, "fix it", Options{}); !errors.Is(err, ErrNoKeywords) {
+		t.Fatalf("Build(stop words) error = %v, want ErrNoKeywords", err)
+	}
+}
@@ -0,0 +1,39 @@
+package recall
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// stopWords are dropped from free text before searching: English function
+// words and the verbs a task or decision title opens with, which match too
+// much of any code base to narrow it.
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`a an and are as at be but by can do does for from has have how if in into is it its
+		of on or so that the their then there these this to use used using via was we when where which while who why will
+		with without add adds adding fix fixes fixing make makes making implement support should must need needs
+		please new change changes update ensure allow let lets all any each every some more less only also just`) {
+		stopWords[w] = true
+	}
+}
+
+var wordRE = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+
+// Keywords returns the distinct words of text, lowercased and in order,
+// leaving out stop words, numbers, and words shorter than three letters.
+func Keywords(text string) []string {
+	var keywords []string
+	seen := map[string]bool{}
+	for _, word := range wordRE.FindAllString(text, -1) {
+		word = strings.ToLower(strings.Trim(word, "_"))
+		if len([]rune(word)) < 3 || stopWords[word] || seen[word] || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		seen[word] = true
+		keywords = append(keywords, word)
+	}
+	return keywords
+}
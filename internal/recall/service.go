@@ -4,12 +4,69 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/embed"
 )
 
 type RecallOptions struct {
 	Limit int
 	Kind  string // "decision", "pattern", or "" for all
+
+	// MinRank, from 0 to 1, drops text matches whose Item.Rank is lower.
+	// Zero keeps every match.
+	MinRank float64
+
+	// Zero times leave that side of the window open. Since bounds are
+	// inclusive and Before bounds exclusive.
+	CreatedSince  time.Time
+	CreatedBefore time.Time
+	UpdatedSince  time.Time
+	UpdatedBefore time.Time
+
+	// Branch is the checked-out git branch. Decisions scoped to another
+	// branch are left out; empty leaves out every scoped decision.
+	Branch string
+}
+
+// branchFilter returns the SQL condition, prefixed with AND, that keeps the
+// decisions in column's table visible on the options' branch, along with its
+// argument.
+func (o RecallOptions) branchFilter(column string) (string, []any) {
+	if o.Branch == "" {
+		return " AND " + column + " IS NULL", nil
+	}
+	return " AND (" + column + " IS NULL OR " + column + " = ?)", []any{o.Branch}
+}
+
+// timeFilter returns the SQL conditions, each prefixed with AND, that keep
+// rows whose created and updated columns fall inside the options' window,
+// along with their arguments. Timestamps are stored as UTC RFC 3339 text, so
+// they compare as strings.
+func (o RecallOptions) timeFilter(created, updated string) (string, []any) {
+	var (
+		clause strings.Builder
+		args   []any
+	)
+	for _, bound := range []struct {
+		column string
+		op     string
+		at     time.Time
+	}{
+		{created, ">=", o.CreatedSince},
+		{created, "<", o.CreatedBefore},
+		{updated, ">=", o.UpdatedSince},
+		{updated, "<", o.UpdatedBefore},
+	} {
+		if bound.at.IsZero() {
+			continue
+		}
+		fmt.Fprintf(&clause, " AND %s %s ?", bound.column, bound.op)
+		args = append(args, bound.at.UTC().Format(time.RFC3339))
+	}
+	return clause.String(), args
 }
 
 type ConnectedEdge struct {
@@ -21,6 +78,7 @@ type ConnectedEdge struct {
 type Item struct {
 	DecisionID      int64           `json:"decision_id,omitempty"`
 	PatternID       int64           `json:"pattern_id,omitempty"`
+	ConstraintID    int64           `json:"constraint_id,omitempty"`
 	EntityType      string          `json:"entity_type"`
 	Title           string          `json:"title"`
 	Reasoning       string          `json:"reasoning"`
@@ -29,11 +87,72 @@ type Item struct {
 	EvidenceSummary string          `json:"evidence_summary"`
 	EvidenceDrift   string          `json:"evidence_drift_status"`
 	ConnectedEdges  []ConnectedEdge `json:"connected_edges,omitempty"`
+
+	// Set by full-text search only: the bm25 relevance of the match as a
+	// fraction of the best match's, so 1 for the best, and an excerpt of
+	// the best matching column with the matched terms between ** marks.
+	Rank    float64 `json:"rank,omitempty"`
+	Snippet string  `json:"snippet,omitempty"`
+
+	// Set by semantic recall only: the similarity to the query, and for
+	// symbol items the symbol's ref and location.
+	Score    float64 `json:"score,omitempty"`
+	Symbol   string  `json:"symbol,omitempty"`
+	FilePath string  `json:"file_path,omitempty"`
+	Line     int     `json:"line,omitempty"`
 }
 
+// Modes of a semantic recall result.
+const (
+	ModeSemantic = "semantic"
+	ModeFTS      = "fts"
+)
+
 type Result struct {
 	Query string `json:"query"`
 	Items []Item `json:"items"`
+	// Mode and Fallback are set by RecallSemantic: Mode is semantic, or fts
+	// when it fell back to full-text search for the reason in Fallback.
+	Mode     string `json:"mode,omitempty"`
+	Fallback string `json:"fallback,omitempty"`
+}
+
+// bm25 weights of the search_index columns: a term in the title counts for
+// more than one in the reasoning and evidence text.
+const (
+	titleWeight   = 10.0
+	contentWeight = 1.0
+)
+
+// ftsColumns selects the bm25 relevance, negated so higher is better, and
+// the snippet of a search_index match, for rankMatches. Queries without a
+// match select zero and an empty snippet in their place.
+var ftsColumns = fmt.Sprintf(`,
+    -bm25(search_index, %.1f, %.1f) AS relevance,
+    snippet(search_index, -1, '**', '**', '…', 16) AS snippet`, titleWeight, contentWeight)
+
+// rankMatches wraps matches, a query for the active search_index matches
+// that selects the recall columns and ftsColumns, so they come best first
+// with each relevance divided by the best one's, those ranked below
+// opts.MinRank left out, up to opts.Limit. It returns the query and the
+// arguments that follow those of matches. The matches are materialized
+// because SQLite cannot run bm25 inside the aggregate that finds the best.
+func rankMatches(matches string, opts RecallOptions) (string, []any) {
+	var (
+		minRank string
+		args    []any
+	)
+	if opts.MinRank > 0 {
+		minRank = "\nWHERE relevance / (SELECT MAX(relevance) FROM matches) >= ?"
+		args = append(args, opts.MinRank)
+	}
+	return `
+WITH matches AS MATERIALIZED (` + matches + `)
+SELECT entity_type, entity_id, title, about, confidence, updated_at, summary, drift_status,
+       relevance / (SELECT MAX(relevance) FROM matches), snippet
+FROM matches` + minRank + `
+ORDER BY relevance DESC, entity_type, entity_id
+LIMIT ?;`, append(args, opts.Limit)
 }
 
 type Service struct {
@@ -49,9 +168,9 @@ func (s *Service) Recall(ctx context.Context, query string, opts RecallOptions)
 		opts.Limit = 10
 	}
 
-	items, err := s.recallFTS(ctx, query, opts.Limit)
+	items, err := s.recallFTS(ctx, query, opts)
 	if err != nil {
-		items, err = s.recallLike(ctx, query, opts.Limit)
+		items, err = s.recallLike(ctx, query, opts)
 		if err != nil {
 			return Result{}, err
 		}
@@ -60,10 +179,124 @@ func (s *Service) Recall(ctx context.Context, query string, opts RecallOptions)
 	if opts.Kind != "" {
 		items = filterByKind(items, opts.Kind)
 	}
+	if opts.MinRank > 0 && (len(items) == 0 || items[0].Rank == 0) {
+		// The LIKE fallback ranks nothing, so nothing it finds passes.
+		items = items[:0]
+	}
 	s.enrichWithEdges(ctx, items)
 	return Result{Query: query, Items: items}, nil
 }
 
+// semanticCandidates is how many vector matches RecallSemantic ranks
+// before filters, as a multiple of the limit.
+const semanticCandidates = 4
+
+// RecallSemantic ranks active knowledge and documented symbols by the cosine
+// similarity of their vectors from p to the query's. Symbols have no dates,
+// so they are left out when the options bound a time window. When p has no
+// vectors stored or cannot embed the query, it falls back to Recall and
+// reports why.
+func (s *Service) RecallSemantic(ctx context.Context, query string, p embed.Provider, opts RecallOptions) (Result, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 10
+	}
+	matches, err := embed.NewService(s.db).Search(ctx, p, query, opts.Limit*semanticCandidates)
+	if err != nil {
+		result, ftsErr := s.Recall(ctx, query, opts)
+		if ftsErr != nil {
+			return Result{}, ftsErr
+		}
+		result.Mode, result.Fallback = ModeFTS, err.Error()
+		return result, nil
+	}
+
+	windowed := !opts.CreatedSince.IsZero() || !opts.CreatedBefore.IsZero() || !opts.UpdatedSince.IsZero() || !opts.UpdatedBefore.IsZero()
+	items := make([]Item, 0, opts.Limit)
+	for _, m := range matches {
+		if len(items) == opts.Limit {
+			break
+		}
+		if opts.Kind != "" && m.EntityType != opts.Kind {
+			continue
+		}
+		if m.EntityType == embed.TypeSymbol {
+			if windowed {
+				continue
+			}
+			items = append(items, Item{
+				EntityType: m.EntityType,
+				Title:      m.Symbol.Kind + " " + m.Ref,
+				Score:      m.Score,
+				Symbol:     m.Ref,
+				FilePath:   m.Symbol.FilePath,
+				Line:       m.Symbol.Line,
+			})
+			continue
+		}
+		id, err := strconv.ParseInt(m.Ref, 10, 64)
+		if err != nil {
+			continue
+		}
+		item, ok, err := s.activeItem(ctx, m.EntityType, id, opts)
+		if err != nil {
+			return Result{}, err
+		}
+		if ok {
+			item.Score = m.Score
+			items = append(items, item)
+		}
+	}
+	s.enrichWithEdges(ctx, items)
+	return Result{Query: query, Items: items, Mode: ModeSemantic}, nil
+}
+
+// activeItem loads one decision, pattern, or constraint as a recall item if
+// it is active and passes the options' branch and time filters.
+func (s *Service) activeItem(ctx context.Context, entityType string, id int64, opts RecallOptions) (Item, bool, error) {
+	var (
+		query string
+		args  = []any{id}
+	)
+	switch entityType {
+	case "decision":
+		branch, branchArgs := opts.branchFilter("d.branch")
+		window, windowArgs := opts.timeFilter("d.created_at", "d.updated_at")
+		query = `
+SELECT 'decision', d.id, d.title, d.reasoning, d.confidence, d.updated_at,
+       COALESCE(e.summary, ''), COALESCE(e.drift_status, 'ok'), 0.0, ''
+FROM decisions d
+LEFT JOIN entity_evidence e ON e.entity_type = 'decision' AND e.entity_id = d.id
+WHERE d.id = ? AND d.status = 'active'` + branch + window
+		args = append(append(args, branchArgs...), windowArgs...)
+	case "pattern", "constraint":
+		table, about := "patterns", "description"
+		if entityType == "constraint" {
+			table, about = "constraints", "reasoning"
+		}
+		window, windowArgs := opts.timeFilter("x.created_at", "x.updated_at")
+		query = `
+SELECT '` + entityType + `', x.id, x.title, x.` + about + `, x.confidence, x.updated_at,
+       COALESCE(e.summary, ''), COALESCE(e.drift_status, 'ok'), 0.0, ''
+FROM ` + table + ` x
+LEFT JOIN entity_evidence e ON e.entity_type = '` + entityType + `' AND e.entity_id = x.id
+WHERE x.id = ? AND x.status = 'active'` + window
+		args = append(args, windowArgs...)
+	default:
+		return Item{}, false, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, query+"\nLIMIT 1;", args...)
+	if err != nil {
+		return Item{}, false, fmt.Errorf("query %s %d: %w", entityType, id, err)
+	}
+	defer rows.Close()
+	items, err := scanItems(rows)
+	if err != nil || len(items) == 0 {
+		return Item{}, false, err
+	}
+	return items[0], true, nil
+}
+
 func filterByKind(items []Item, kind string) []Item {
 	filtered := make([]Item, 0, len(items))
 	for _, item := range items {
@@ -78,16 +311,21 @@ func (s *Service) enrichWithEdges(ctx context.Context, items []Item) {
 	for i := range items {
 		entityType := items[i].EntityType
 		var entityID int64
-		if entityType == "pattern" {
+		switch entityType {
+		case embed.TypeSymbol:
+			continue
+		case "pattern":
 			entityID = items[i].PatternID
-		} else {
+		case "constraint":
+			entityID = items[i].ConstraintID
+		default:
 			entityID = items[i].DecisionID
 		}
 
 		rows, err := s.db.QueryContext(ctx, `
 SELECT to_type, to_ref, relation FROM edges
 WHERE from_type = ? AND from_id = ?
-ORDER BY relation, to_type;
+ORDER BY relation, to_type, to_ref;
 `, entityType, entityID)
 		if err != nil {
 			continue
@@ -104,32 +342,35 @@ ORDER BY relation, to_type;
 	}
 }
 
-func (s *Service) recallFTS(ctx context.Context, query string, limit int) ([]Item, error) {
-	rows, err := s.db.QueryContext(ctx, `
+func (s *Service) recallFTS(ctx context.Context, query string, opts RecallOptions) ([]Item, error) {
+	window, windowArgs := opts.timeFilter("COALESCE(d.created_at, p.created_at, c.created_at)", "COALESCE(d.updated_at, p.updated_at, c.updated_at)")
+	branch, branchArgs := opts.branchFilter("d.branch")
+	args := append(append([]any{query}, branchArgs...), windowArgs...)
+	ranked, rankArgs := rankMatches(`
 SELECT
     search_index.entity_type,
     search_index.entity_id,
     search_index.title,
-    COALESCE(d.reasoning, p.description, ''),
-    COALESCE(d.confidence, p.confidence, 'medium'),
-    COALESCE(d.updated_at, p.updated_at, ''),
-    COALESCE(e.summary, ''),
-    COALESCE(e.drift_status, 'ok')
+    COALESCE(d.reasoning, p.description, c.reasoning, '') AS about,
+    COALESCE(d.confidence, p.confidence, c.confidence, 'medium') AS confidence,
+    COALESCE(d.updated_at, p.updated_at, c.updated_at, '') AS updated_at,
+    COALESCE(e.summary, '') AS summary,
+    COALESCE(e.drift_status, 'ok') AS drift_status`+ftsColumns+`
 FROM search_index
 LEFT JOIN decisions d ON d.id = search_index.entity_id AND search_index.entity_type = 'decision'
 LEFT JOIN patterns p ON p.id = search_index.entity_id AND search_index.entity_type = 'pattern'
-LEFT JOIN evidence e ON e.entity_type = search_index.entity_type AND e.entity_id = search_index.entity_id
+LEFT JOIN constraints c ON c.id = search_index.entity_id AND search_index.entity_type = 'constraint'
+LEFT JOIN entity_evidence e ON e.entity_type = search_index.entity_type AND e.entity_id = search_index.entity_id
 WHERE search_index MATCH ?
   AND (
-    (search_index.entity_type = 'decision' AND d.status = 'active')
+    (search_index.entity_type = 'decision' AND d.status = 'active'`+branch+`)
     OR (search_index.entity_type = 'pattern' AND p.status = 'active')
-  )
-ORDER BY rank
-LIMIT ?;
-	`, query, limit)
+    OR (search_index.entity_type = 'constraint' AND c.status = 'active')
+  )`+window, opts)
+	rows, err := s.db.QueryContext(ctx, ranked, append(args, rankArgs...)...)
 	if err != nil {
 		if isMissingTableError(err, "patterns") {
-			return s.recallFTSLegacy(ctx, query, limit)
+			return s.recallFTSLegacy(ctx, query, opts)
 		}
 		return nil, fmt.Errorf("fts recall query: %w", err)
 	}
@@ -138,26 +379,26 @@ LIMIT ?;
 	return scanItems(rows)
 }
 
-func (s *Service) recallFTSLegacy(ctx context.Context, query string, limit int) ([]Item, error) {
-	rows, err := s.db.QueryContext(ctx, `
+func (s *Service) recallFTSLegacy(ctx context.Context, query string, opts RecallOptions) ([]Item, error) {
+	window, windowArgs := opts.timeFilter("d.created_at", "d.updated_at")
+	args := append([]any{query}, windowArgs...)
+	ranked, rankArgs := rankMatches(`
 SELECT
     search_index.entity_type,
     search_index.entity_id,
     search_index.title,
-    COALESCE(d.reasoning, ''),
-    COALESCE(d.confidence, 'medium'),
-    COALESCE(d.updated_at, ''),
-    COALESCE(e.summary, ''),
-    COALESCE(e.drift_status, 'ok')
+    COALESCE(d.reasoning, '') AS about,
+    COALESCE(d.confidence, 'medium') AS confidence,
+    COALESCE(d.updated_at, '') AS updated_at,
+    COALESCE(e.summary, '') AS summary,
+    COALESCE(e.drift_status, 'ok') AS drift_status`+ftsColumns+`
 FROM search_index
 LEFT JOIN decisions d ON d.id = search_index.entity_id AND search_index.entity_type = 'decision'
 LEFT JOIN evidence e ON e.entity_type = search_index.entity_type AND e.entity_id = search_index.entity_id
 WHERE search_index MATCH ?
   AND search_index.entity_type = 'decision'
-  AND d.status = 'active'
-ORDER BY rank
-LIMIT ?;
-	`, query, limit)
+  AND d.status = 'active'`+window, opts)
+	rows, err := s.db.QueryContext(ctx, ranked, append(args, rankArgs...)...)
 	if err != nil {
 		return nil, fmt.Errorf("fts recall query: %w", err)
 	}
@@ -166,26 +407,31 @@ LIMIT ?;
 	return scanItems(rows)
 }
 
-func (s *Service) recallLike(ctx context.Context, query string, limit int) ([]Item, error) {
+func (s *Service) recallLike(ctx context.Context, query string, opts RecallOptions) ([]Item, error) {
 	like := "%" + query + "%"
+	decisionWindow, decisionArgs := opts.timeFilter("d.created_at", "d.updated_at")
+	patternWindow, patternArgs := opts.timeFilter("p.created_at", "p.updated_at")
+	branch, branchArgs := opts.branchFilter("d.branch")
+	args := append(append([]any{like, like, like}, branchArgs...), decisionArgs...)
+	args = append(append(args, like, like, like), patternArgs...)
 	rows, err := s.db.QueryContext(ctx, `
-SELECT 'decision' AS entity_type, d.id, d.title, d.reasoning, d.confidence, d.updated_at,
-       COALESCE(e.summary, ''), COALESCE(e.drift_status, 'ok')
+SELECT 'decision' AS entity_type, d.id AS entity_id, d.title, d.reasoning, d.confidence, d.updated_at,
+       COALESCE(e.summary, ''), COALESCE(e.drift_status, 'ok'), 0.0, ''
 FROM decisions d
-LEFT JOIN evidence e ON e.entity_type = 'decision' AND e.entity_id = d.id
-WHERE d.status = 'active' AND (d.title LIKE ? OR d.reasoning LIKE ? OR e.summary LIKE ?)
+LEFT JOIN entity_evidence e ON e.entity_type = 'decision' AND e.entity_id = d.id
+WHERE d.status = 'active' AND (d.title LIKE ? OR d.reasoning LIKE ? OR e.summary LIKE ?)`+branch+decisionWindow+`
 UNION ALL
 SELECT 'pattern' AS entity_type, p.id, p.title, p.description, p.confidence, p.updated_at,
-       COALESCE(e2.summary, ''), COALESCE(e2.drift_status, 'ok')
+       COALESCE(e2.summary, ''), COALESCE(e2.drift_status, 'ok'), 0.0, ''
 FROM patterns p
-LEFT JOIN evidence e2 ON e2.entity_type = 'pattern' AND e2.entity_id = p.id
-WHERE p.status = 'active' AND (p.title LIKE ? OR p.description LIKE ? OR e2.summary LIKE ?)
-ORDER BY updated_at DESC
+LEFT JOIN entity_evidence e2 ON e2.entity_type = 'pattern' AND e2.entity_id = p.id
+WHERE p.status = 'active' AND (p.title LIKE ? OR p.description LIKE ? OR e2.summary LIKE ?)`+patternWindow+`
+ORDER BY updated_at DESC, entity_type, entity_id
 LIMIT ?;
-	`, like, like, like, like, like, like, limit)
+	`, append(args, opts.Limit)...)
 	if err != nil {
 		if isMissingTableError(err, "patterns") {
-			return s.recallLikeLegacy(ctx, like, limit)
+			return s.recallLikeLegacy(ctx, like, opts)
 		}
 		return nil, fmt.Errorf("fallback recall query: %w", err)
 	}
@@ -194,16 +440,18 @@ LIMIT ?;
 	return scanItems(rows)
 }
 
-func (s *Service) recallLikeLegacy(ctx context.Context, like string, limit int) ([]Item, error) {
+func (s *Service) recallLikeLegacy(ctx context.Context, like string, opts RecallOptions) ([]Item, error) {
+	window, windowArgs := opts.timeFilter("d.created_at", "d.updated_at")
+	args := append([]any{like, like, like}, windowArgs...)
 	rows, err := s.db.QueryContext(ctx, `
 SELECT 'decision' AS entity_type, d.id, d.title, d.reasoning, d.confidence, d.updated_at,
-       COALESCE(e.summary, ''), COALESCE(e.drift_status, 'ok')
+       COALESCE(e.summary, ''), COALESCE(e.drift_status, 'ok'), 0.0, ''
 FROM decisions d
 LEFT JOIN evidence e ON e.entity_type = 'decision' AND e.entity_id = d.id
-WHERE d.status = 'active' AND (d.title LIKE ? OR d.reasoning LIKE ? OR e.summary LIKE ?)
-ORDER BY updated_at DESC
+WHERE d.status = 'active' AND (d.title LIKE ? OR d.reasoning LIKE ? OR e.summary LIKE ?)`+window+`
+ORDER BY d.updated_at DESC, d.id
 LIMIT ?;
-	`, like, like, like, limit)
+	`, append(args, opts.Limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("fallback recall query: %w", err)
 	}
@@ -234,12 +482,16 @@ func scanItems(rows *sql.Rows) ([]Item, error) {
 			&item.UpdatedAt,
 			&item.EvidenceSummary,
 			&item.EvidenceDrift,
+			&item.Rank,
+			&item.Snippet,
 		); err != nil {
 			return nil, fmt.Errorf("scan recall row: %w", err)
 		}
 		switch item.EntityType {
 		case "pattern":
 			item.PatternID = entityID
+		case "constraint":
+			item.ConstraintID = entityID
 		default:
 			item.DecisionID = entityID
 		}
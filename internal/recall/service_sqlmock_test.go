@@ -17,8 +17,8 @@ func TestScanItemsRowsErrBranch(t *testing.T) {
 	defer db.Close()
 
 	mock.ExpectQuery("search_index.entity_type").WithArgs("X", 10).WillReturnRows(
-		sqlmock.NewRows([]string{"entity_type", "entity_id", "title", "content", "confidence", "updated_at", "summary", "drift_status"}).
-			AddRow("decision", 1, "t", "r", "high", "u", "s", "ok").
+		sqlmock.NewRows([]string{"entity_type", "entity_id", "title", "content", "confidence", "updated_at", "summary", "drift_status", "relevance", "snippet"}).
+			AddRow("decision", 1, "t", "r", "high", "u", "s", "ok", 1.0, "").
 			RowError(0, errors.New("iter fail")),
 	)
 	mock.ExpectQuery("SELECT 'decision'").WithArgs("%X%", "%X%", "%X%", "%X%", "%X%", "%X%", 10).WillReturnError(errors.New("fallback fail"))
@@ -41,7 +41,7 @@ func TestRecallFTSLegacyQueryError(t *testing.T) {
 
 	mock.ExpectQuery("search_index.entity_type").WithArgs("legacy", 5).WillReturnError(errors.New("legacy fail"))
 
-	_, err = NewService(db).recallFTSLegacy(context.Background(), "legacy", 5)
+	_, err = NewService(db).recallFTSLegacy(context.Background(), "legacy", RecallOptions{Limit: 5})
 	if err == nil || !strings.Contains(err.Error(), "fts recall query") {
 		t.Fatalf("expected fts recall query error, got %v", err)
 	}
@@ -60,7 +60,7 @@ func TestRecallLikeLegacyQueryError(t *testing.T) {
 
 	mock.ExpectQuery("SELECT 'decision'").WithArgs("%legacy%", "%legacy%", "%legacy%", 5).WillReturnError(errors.New("legacy fail"))
 
-	_, err = NewService(db).recallLikeLegacy(context.Background(), "%legacy%", 5)
+	_, err = NewService(db).recallLikeLegacy(context.Background(), "%legacy%", RecallOptions{Limit: 5})
 	if err == nil || !strings.Contains(err.Error(), "fallback recall query") {
 		t.Fatalf("expected fallback recall query error, got %v", err)
 	}
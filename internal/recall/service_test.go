@@ -4,11 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/embed"
 )
 
 func TestRecall_SQLMock_ErrorPaths(t *testing.T) {
@@ -49,8 +53,8 @@ func TestRecall_SQLMock_ErrorPaths(t *testing.T) {
 
 		// Return rows with wrong column types to trigger scan error
 		mock.ExpectQuery("search_index.entity_type").WithArgs("Cobra", 10).WillReturnRows(
-			sqlmock.NewRows([]string{"entity_type", "entity_id", "title", "reasoning", "confidence", "updated_at", "summary", "drift_status"}).
-				AddRow("decision", "not_an_int", "t", "r", "high", "u", "s", "ok"),
+			sqlmock.NewRows([]string{"entity_type", "entity_id", "title", "reasoning", "confidence", "updated_at", "summary", "drift_status", "relevance", "snippet"}).
+				AddRow("decision", "not_an_int", "t", "r", "high", "u", "s", "ok", 1.0, ""),
 		)
 		// LIKE fallback also fails
 		mock.ExpectQuery("SELECT 'decision'").WithArgs("%Cobra%", "%Cobra%", "%Cobra%", "%Cobra%", "%Cobra%", "%Cobra%", 10).
@@ -312,7 +316,7 @@ func TestRecallLegacyQueriesWhenPatternsTableMissing(t *testing.T) {
 	}
 
 	// LIKE path should also stay functional without patterns.
-	items, err := svc.recallLike(context.Background(), "Cobra", 10)
+	items, err := svc.recallLike(context.Background(), "Cobra", RecallOptions{Limit: 10})
 	if err != nil {
 		t.Fatalf("recallLike on legacy DB: %v", err)
 	}
@@ -320,3 +324,304 @@ func TestRecallLegacyQueriesWhenPatternsTableMissing(t *testing.T) {
 		t.Fatalf("unexpected legacy LIKE result: %+v", items)
 	}
 }
+
+func TestRecallOrdersRankTiesByEntity(t *testing.T) {
+	conn, cleanup := recallTestDB(t)
+	defer cleanup()
+
+	// Identical documents score the same rank; inserted out of order on purpose.
+	_, _ = conn.Exec(`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES (3,'Tiebreak rule','same','high','active','x','2026-01-01T00:00:00Z');`)
+	_, _ = conn.Exec(`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES (2,'Tiebreak rule','same','high','active','x','2026-01-01T00:00:00Z');`)
+	_, _ = conn.Exec(`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES (2,'Tiebreak rule','same','high','active','x','2026-01-01T00:00:00Z');`)
+	_, _ = conn.Exec(`INSERT INTO search_index(title,content,entity_type,entity_id) VALUES ('Tiebreak rule','same','decision',3);`)
+	_, _ = conn.Exec(`INSERT INTO search_index(title,content,entity_type,entity_id) VALUES ('Tiebreak rule','same','pattern',2);`)
+	_, _ = conn.Exec(`INSERT INTO search_index(title,content,entity_type,entity_id) VALUES ('Tiebreak rule','same','decision',2);`)
+
+	res, err := NewService(conn).Recall(context.Background(), "Tiebreak", RecallOptions{})
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	var got []string
+	for _, item := range res.Items {
+		id := item.DecisionID
+		if item.EntityType == "pattern" {
+			id = item.PatternID
+		}
+		got = append(got, fmt.Sprintf("%s:%d", item.EntityType, id))
+	}
+	if strings.Join(got, ",") != "decision:2,decision:3,pattern:2" {
+		t.Fatalf("unexpected tie order: %v", got)
+	}
+}
+
+func TestRecallRanksTitleMatchesFirstWithSnippets(t *testing.T) {
+	conn, cleanup := recallTestDB(t)
+	defer cleanup()
+
+	// recallTestDB seeds decision 1, "Use Cobra". Decisions 2 and 3 name
+	// cobra in their titles too, decision 4 only in its content, and 5 and 6
+	// not at all.
+	for _, d := range []struct {
+		id             int
+		title, content string
+	}{
+		{2, "Cobra hooks run once", "PersistentPreRunE opens the database"},
+		{3, "Cobra completion stays dynamic", "Completions query the index"},
+		{4, "Flag parsing", "Subcommands register their flags with cobra at init"},
+		{5, "SQLite in one file", "The index is a single database"},
+		{6, "Errors wrap with %w", "Callers match with errors.Is"},
+	} {
+		_, _ = conn.Exec(`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES (?,?,?,'high','active','x','2026-01-01T00:00:00Z');`, d.id, d.title, d.content)
+		_, _ = conn.Exec(`INSERT INTO search_index(title,content,entity_type,entity_id) VALUES (?,?,'decision',?);`, d.title, d.content, d.id)
+	}
+
+	svc := NewService(conn)
+	res, err := svc.Recall(context.Background(), "cobra", RecallOptions{})
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if len(res.Items) != 4 || res.Items[3].DecisionID != 4 {
+		t.Fatalf("expected the content match last of four, got %+v", res.Items)
+	}
+	if res.Items[0].Rank != 1 {
+		t.Fatalf("expected the best match ranked 1, got %v", res.Items[0].Rank)
+	}
+	for i, item := range res.Items[:3] {
+		if item.Rank < 0.5 {
+			t.Fatalf("title match %d ranked %v, want at least 0.5", i, item.Rank)
+		}
+	}
+	content := res.Items[3]
+	if content.Rank <= 0 || content.Rank >= 0.5 {
+		t.Fatalf("content match ranked %v, want between 0 and 0.5", content.Rank)
+	}
+	for _, item := range res.Items {
+		if item.DecisionID == 1 && item.Snippet != "Use **Cobra**" {
+			t.Fatalf("unexpected title snippet %q", item.Snippet)
+		}
+	}
+	if !strings.Contains(content.Snippet, "flags with **cobra** at init") {
+		t.Fatalf("unexpected content snippet %q", content.Snippet)
+	}
+
+	res, err = svc.Recall(context.Background(), "cobra", RecallOptions{MinRank: 0.5})
+	if err != nil {
+		t.Fatalf("Recall with MinRank: %v", err)
+	}
+	if len(res.Items) != 3 {
+		t.Fatalf("expected the three title matches at MinRank 0.5, got %+v", res.Items)
+	}
+	// The limit counts only matches that pass MinRank.
+	res, err = svc.Recall(context.Background(), "cobra", RecallOptions{MinRank: 0.5, Limit: 2})
+	if err != nil {
+		t.Fatalf("Recall with MinRank and Limit: %v", err)
+	}
+	if len(res.Items) != 2 || res.Items[1].Rank < 0.5 {
+		t.Fatalf("expected two title matches, got %+v", res.Items)
+	}
+
+	// "go.mod" is not a valid FTS query, so the LIKE fallback finds it,
+	// without a rank; MinRank then leaves nothing.
+	res, err = svc.Recall(context.Background(), "go.mod", RecallOptions{})
+	if err != nil {
+		t.Fatalf("Recall fallback: %v", err)
+	}
+	if len(res.Items) != 1 || res.Items[0].Rank != 0 || res.Items[0].Snippet != "" {
+		t.Fatalf("expected one unranked fallback item, got %+v", res.Items)
+	}
+	res, err = svc.Recall(context.Background(), "go.mod", RecallOptions{MinRank: 0.1})
+	if err != nil {
+		t.Fatalf("Recall fallback with MinRank: %v", err)
+	}
+	if len(res.Items) != 0 {
+		t.Fatalf("expected unranked fallback items dropped, got %+v", res.Items)
+	}
+}
+
+func TestRecallTimeWindow(t *testing.T) {
+	conn, cleanup := recallTestDB(t)
+	defer cleanup()
+
+	for _, stmt := range []string{
+		`UPDATE decisions SET created_at = '2026-01-01T00:00:00Z' WHERE id = 1;`,
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES (2,'Cobra flags stay local','r','high','active','2026-03-01T00:00:00Z','2026-03-02T00:00:00Z');`,
+		`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES (1,'Cobra commands','d','high','active','2026-02-01T00:00:00Z','2026-03-05T00:00:00Z');`,
+		`INSERT INTO search_index(title,content,entity_type,entity_id) VALUES ('Cobra flags stay local','r','decision',2);`,
+		`INSERT INTO search_index(title,content,entity_type,entity_id) VALUES ('Cobra commands','d','pattern',1);`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	day := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatalf("parse %s: %v", s, err)
+		}
+		return d
+	}
+	svc := NewService(conn)
+	for _, tc := range []struct {
+		name  string
+		query string
+		opts  RecallOptions
+		want  string
+	}{
+		{"created since", "Cobra", RecallOptions{CreatedSince: day("2026-02-01")}, "pattern:1,decision:2"},
+		{"created before", "Cobra", RecallOptions{CreatedBefore: day("2026-02-01")}, "decision:1"},
+		{"created range", "Cobra", RecallOptions{CreatedSince: day("2026-01-15"), CreatedBefore: day("2026-02-15")}, "pattern:1"},
+		{"updated since", "Cobra", RecallOptions{UpdatedSince: day("2026-03-03")}, "pattern:1"},
+		{"updated before", "Cobra", RecallOptions{UpdatedBefore: day("2026-03-03")}, "decision:2,decision:1"},
+		{"fallback honors window", "flags", RecallOptions{CreatedSince: day("2026-02-01")}, "decision:2"},
+		{"empty query lists window", "", RecallOptions{CreatedSince: day("2026-02-01")}, "pattern:1,decision:2"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := svc.Recall(context.Background(), tc.query, tc.opts)
+			if err != nil {
+				t.Fatalf("Recall: %v", err)
+			}
+			var got []string
+			for _, item := range res.Items {
+				id := item.DecisionID
+				if item.EntityType == "pattern" {
+					id = item.PatternID
+				}
+				got = append(got, fmt.Sprintf("%s:%d", item.EntityType, id))
+			}
+			if strings.Join(got, ",") != tc.want {
+				t.Fatalf("items = %v, want %s", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := conn.Exec(`DROP TABLE patterns;`); err != nil {
+		t.Fatalf("drop patterns table: %v", err)
+	}
+	res, err := svc.Recall(context.Background(), "Cobra", RecallOptions{CreatedSince: day("2026-02-01")})
+	if err != nil {
+		t.Fatalf("Recall on legacy DB: %v", err)
+	}
+	if len(res.Items) != 1 || res.Items[0].DecisionID != 2 {
+		t.Fatalf("legacy window = %+v", res.Items)
+	}
+}
+
+// topicProvider embeds a text as which of three topics it mentions.
+type topicProvider struct{}
+
+func (topicProvider) Name() string { return "topics" }
+
+func (topicProvider) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		text = strings.ToLower(text)
+		v := make([]float32, 3)
+		for j, topic := range []string{"command", "flag", "test"} {
+			if strings.Contains(text, topic) {
+				v[j] = 1
+			}
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+func TestRecallSemantic(t *testing.T) {
+	conn, cleanup := recallTestDB(t)
+	defer cleanup()
+	for _, stmt := range []string{
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES (2,'Old command layout','r','low','superseded','x','x');`,
+		`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES (1,'Flags stay local','Each command owns its flags','high','active','x','x');`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	ctx := context.Background()
+	svc := NewService(conn)
+
+	res, err := svc.RecallSemantic(ctx, "Cobra", topicProvider{}, RecallOptions{})
+	if err != nil {
+		t.Fatalf("RecallSemantic without embeddings: %v", err)
+	}
+	if res.Mode != ModeFTS || !strings.Contains(res.Fallback, "run `recon embed`") || len(res.Items) != 1 || res.Items[0].DecisionID != 1 {
+		t.Fatalf("fallback result = %+v", res)
+	}
+
+	if _, err := embed.NewService(conn).Embed(ctx, t.TempDir(), topicProvider{}, false); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if _, err := conn.Exec(`UPDATE decisions SET reasoning = 'Because subcommands are commands' WHERE id = 1`); err != nil {
+		t.Fatalf("update decision: %v", err)
+	}
+	res, err = svc.RecallSemantic(ctx, "which flag does each command own", topicProvider{}, RecallOptions{})
+	if err != nil {
+		t.Fatalf("RecallSemantic: %v", err)
+	}
+	if res.Mode != ModeSemantic || len(res.Items) != 2 || res.Items[0].PatternID != 1 || res.Items[1].DecisionID != 1 {
+		t.Fatalf("semantic items = %+v", res.Items)
+	}
+	if res.Items[0].Score <= res.Items[1].Score || res.Items[1].Reasoning != "Because subcommands are commands" {
+		t.Fatalf("semantic scores or reasoning = %+v", res.Items)
+	}
+
+	res, err = svc.RecallSemantic(ctx, "command", topicProvider{}, RecallOptions{Kind: "decision"})
+	if err != nil || len(res.Items) != 1 || res.Items[0].DecisionID != 1 {
+		t.Fatalf("kind-filtered semantic items = %+v, %v", res.Items, err)
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	got := Keywords("Add retry to the sync command, and retry SYNC in v2 _cache_ 42")
+	want := []string{"retry", "sync", "command", "cache"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keywords() = %v, want %v", got, want)
+	}
+	if got := Keywords("add it to the"); len(got) != 0 {
+		t.Fatalf("Keywords(stop words) = %v, want none", got)
+	}
+}
+
+func TestSimilarDecisions(t *testing.T) {
+	conn, cleanup := recallTestDB(t)
+	defer cleanup()
+	for _, stmt := range []string{
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at,branch) VALUES
+			(2,'Retry network calls with backoff','Transient failures are common','high','active','x','x',NULL),
+			(3,'Retry network calls','Old wording','low','superseded','x','x',NULL),
+			(4,'Retry network calls on feature','Scoped','low','active','x','x','feature'),
+			(5,'Cache network responses','Saves round trips','medium','active','x','x',NULL)`,
+		`INSERT INTO search_index(title,content,entity_type,entity_id) VALUES
+			('Retry network calls with backoff','Transient failures are common','decision',2),
+			('Retry network calls','Old wording','decision',3),
+			('Retry network calls on feature','Scoped','decision',4),
+			('Cache network responses','Saves round trips','decision',5)`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	svc := NewService(conn)
+	ctx := context.Background()
+	got, err := svc.SimilarDecisions(ctx, "Retries for network calls", "Networks drop requests", RecallOptions{})
+	if err != nil {
+		t.Fatalf("SimilarDecisions: %v", err)
+	}
+	if len(got) != 1 || got[0].DecisionID != 2 || got[0].Score < SimilarThreshold || got[0].Confidence != "high" {
+		t.Fatalf("similar = %+v, want only decision 2", got)
+	}
+
+	got, err = svc.SimilarDecisions(ctx, "Retry network calls", "", RecallOptions{Branch: "feature"})
+	if err != nil || len(got) != 2 || got[0].DecisionID != 4 || got[1].DecisionID != 2 || got[0].Score < got[1].Score {
+		t.Fatalf("similar on branch = %+v, %v", got, err)
+	}
+
+	if got, err := svc.SimilarDecisions(ctx, "Adopt Bazel", "Faster builds", RecallOptions{}); err != nil || len(got) != 0 {
+		t.Fatalf("unrelated = %+v, %v", got, err)
+	}
+	if got, err := svc.SimilarDecisions(ctx, "do it", "", RecallOptions{}); err != nil || got != nil {
+		t.Fatalf("no keywords = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,107 @@
+package recall
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// SimilarThreshold is the score from which SimilarDecisions reports an
+// active decision as resembling a new one.
+const SimilarThreshold = 0.5
+
+// similarCandidates is how many full-text matches SimilarDecisions scores.
+// Patterns and constraints are dropped after the limit, so it leaves room
+// for them.
+const similarCandidates = 50
+
+// SimilarDecision is an active decision that resembles a proposed one.
+type SimilarDecision struct {
+	DecisionID int64   `json:"decision_id"`
+	Title      string  `json:"title"`
+	Reasoning  string  `json:"reasoning"`
+	Confidence string  `json:"confidence"`
+	Score      float64 `json:"score"`
+}
+
+// SimilarDecisions returns the active decisions visible on opts.Branch whose
+// wording resembles title and reasoning, most similar first. Candidates come
+// from a full-text search for any of the keywords; each is scored by keyword
+// overlap, titles weighing more than reasoning, and kept if the score
+// reaches SimilarThreshold.
+func (s *Service) SimilarDecisions(ctx context.Context, title, reasoning string, opts RecallOptions) ([]SimilarDecision, error) {
+	keywords := Keywords(title + "\n" + reasoning)
+	if len(keywords) == 0 {
+		return nil, nil
+	}
+	quoted := make([]string, len(keywords))
+	for i, k := range keywords {
+		quoted[i] = `"` + k + `"`
+	}
+	opts.Kind, opts.Limit = "decision", similarCandidates
+	result, err := s.Recall(ctx, strings.Join(quoted, " OR "), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	titleStems, allStems := stems(Keywords(title)), stems(keywords)
+	var similar []SimilarDecision
+	for _, item := range result.Items {
+		score := 0.7*dice(titleStems, stems(Keywords(item.Title))) +
+			0.3*dice(allStems, stems(Keywords(item.Title+"\n"+item.Reasoning)))
+		if score < SimilarThreshold {
+			continue
+		}
+		similar = append(similar, SimilarDecision{
+			DecisionID: item.DecisionID,
+			Title:      item.Title,
+			Reasoning:  item.Reasoning,
+			Confidence: item.Confidence,
+			Score:      float64(int(score*100+0.5)) / 100,
+		})
+	}
+	sort.SliceStable(similar, func(i, j int) bool {
+		if similar[i].Score != similar[j].Score {
+			return similar[i].Score > similar[j].Score
+		}
+		return similar[i].DecisionID < similar[j].DecisionID
+	})
+	return similar, nil
+}
+
+// Overlap scores how much the wording of a and b overlaps, from 0 (no
+// keyword in common) to 1 (the same keywords), ignoring plural endings.
+func Overlap(a, b string) float64 {
+	return dice(stems(Keywords(a)), stems(Keywords(b)))
+}
+
+// stems returns the set of keywords with plural endings removed, so that
+// "retry" and "retries" count as one word.
+func stems(keywords []string) map[string]bool {
+	set := make(map[string]bool, len(keywords))
+	for _, k := range keywords {
+		switch {
+		case len(k) > 4 && strings.HasSuffix(k, "ies"):
+			k = k[:len(k)-3] + "y"
+		case len(k) > 3 && strings.HasSuffix(k, "s") && !strings.HasSuffix(k, "ss"):
+			k = k[:len(k)-1]
+		}
+		set[k] = true
+	}
+	return set
+}
+
+// dice returns the Dice coefficient of two word sets: twice the shared
+// words over the total, 0 when both are empty.
+func dice(a, b map[string]bool) float64 {
+	if len(a)+len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b))
+}
@@ -0,0 +1,128 @@
+// Package sarif builds SARIF 2.1.0 logs, the static analysis format GitHub
+// code scanning accepts, so recon findings can annotate pull requests.
+package sarif
+
+import "path"
+
+const (
+	Version = "2.1.0"
+	Schema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// SrcRoot is the base that result paths are relative to. Code scanning
+	// resolves it to the checkout, so the module root should be the
+	// repository root or the upload should set its checkout path.
+	SrcRoot = "%SRCROOT%"
+
+	toolURI = "https://github.com/robertguss/recon"
+)
+
+// Levels of a result.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+type Log struct {
+	Version string `json:"version"`
+	Schema  string `json:"$schema"`
+	Runs    []Run  `json:"runs"`
+}
+
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+	Name           string `json:"name"`
+	Version        string `json:"version,omitempty"`
+	InformationURI string `json:"informationUri"`
+	Rules          []Rule `json:"rules"`
+}
+
+// Rule describes one kind of finding; results refer to it by ID.
+type Rule struct {
+	ID                   string        `json:"id"`
+	ShortDescription     Message       `json:"shortDescription"`
+	DefaultConfiguration Configuration `json:"defaultConfiguration"`
+}
+
+type Configuration struct {
+	Level string `json:"level"`
+}
+
+type Message struct {
+	Text string `json:"text"`
+}
+
+type Result struct {
+	RuleID    string     `json:"ruleId"`
+	Level     string     `json:"level"`
+	Message   Message    `json:"message"`
+	Locations []Location `json:"locations"`
+}
+
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+type ArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+// Region lines are one-based and inclusive.
+type Region struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+// NewLog returns a log of one recon run with the given rules and results.
+func NewLog(toolVersion string, rules []Rule, results []Result) Log {
+	if results == nil {
+		results = []Result{}
+	}
+	return Log{
+		Version: Version,
+		Schema:  Schema,
+		Runs: []Run{{
+			Tool: Tool{Driver: Driver{
+				Name:           "recon",
+				Version:        toolVersion,
+				InformationURI: toolURI,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
+
+// NewRule returns a rule whose results default to level.
+func NewRule(id, description, level string) Rule {
+	return Rule{ID: id, ShortDescription: Message{Text: description}, DefaultConfiguration: Configuration{Level: level}}
+}
+
+// FileLocation points at the module-relative file rel, at lines start
+// through end when start is positive and at the whole file otherwise.
+func FileLocation(rel string, start, end int) Location {
+	loc := Location{PhysicalLocation: PhysicalLocation{
+		ArtifactLocation: ArtifactLocation{URI: path.Clean(rel), URIBaseID: SrcRoot},
+	}}
+	if start > 0 {
+		loc.PhysicalLocation.Region = &Region{StartLine: start}
+		if end > start {
+			loc.PhysicalLocation.Region.EndLine = end
+		}
+	}
+	return loc
+}
@@ -0,0 +1,50 @@
+package sarif
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewLog(t *testing.T) {
+	log := NewLog("v1.2.3", []Rule{NewRule("import_cycle", "cycle", LevelError)}, nil)
+	out, err := json.Marshal(log)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for _, want := range []string{
+		`"version":"2.1.0"`,
+		`"$schema":"https://json.schemastore.org/sarif-2.1.0.json"`,
+		`"driver":{"name":"recon","version":"v1.2.3"`,
+		`"defaultConfiguration":{"level":"error"}`,
+		`"results":[]`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("log missing %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestFileLocation(t *testing.T) {
+	tests := []struct {
+		name       string
+		rel        string
+		start, end int
+		want       string
+	}{
+		{"whole file", "main.go", 0, 0, `{"physicalLocation":{"artifactLocation":{"uri":"main.go","uriBaseId":"%SRCROOT%"}}}`},
+		{"one line", "./pkg/a.go", 4, 4, `{"physicalLocation":{"artifactLocation":{"uri":"pkg/a.go","uriBaseId":"%SRCROOT%"},"region":{"startLine":4}}}`},
+		{"line range", "pkg/a.go", 4, 9, `{"physicalLocation":{"artifactLocation":{"uri":"pkg/a.go","uriBaseId":"%SRCROOT%"},"region":{"startLine":4,"endLine":9}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := json.Marshal(FileLocation(tt.rel, tt.start, tt.end))
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(out) != tt.want {
+				t.Fatalf("FileLocation() = %s, want %s", out, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Type is a payload to render. Value is any value of the Go type; Name is
+// the name it gets in the generated source and Doc its comment.
+type Type struct {
+	Name  string
+	Doc   string
+	Value any
+}
+
+// GoSource renders types, and every named struct type they reach, as a
+// standalone Go file in package pkg. Only JSON-visible fields are kept, with
+// their json tags, so the structs decode recon's --json output as-is.
+// Reached types are named after their Go type, prefixed with the package name
+// when two packages declare the same name.
+func GoSource(pkg string, types []Type) ([]byte, error) {
+	g := &generator{names: map[reflect.Type]string{}}
+	taken := map[string]bool{}
+	for _, t := range types {
+		rt := structType(reflect.TypeOf(t.Value))
+		if rt == nil {
+			return nil, fmt.Errorf("%s: %T is not a struct", t.Name, t.Value)
+		}
+		if taken[t.Name] {
+			return nil, fmt.Errorf("duplicate type name %q", t.Name)
+		}
+		taken[t.Name] = true
+		g.names[rt] = t.Name
+		g.queue = append(g.queue, rt)
+		g.docs = append(g.docs, t.Doc)
+	}
+	g.nameReached(taken)
+
+	var body bytes.Buffer
+	for i, rt := range g.queue {
+		if i < len(g.docs) && g.docs[i] != "" {
+			for _, line := range strings.Split(g.docs[i], "\n") {
+				fmt.Fprintf(&body, "// %s\n", line)
+			}
+		}
+		fmt.Fprintf(&body, "type %s %s\n\n", g.names[rt], g.structBody(rt))
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by recon schema --go. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	if g.usesTime {
+		out.WriteString("import \"time\"\n\n")
+	}
+	out.Write(body.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated source: %w", err)
+	}
+	return src, nil
+}
+
+type generator struct {
+	names    map[reflect.Type]string
+	queue    []reflect.Type
+	docs     []string
+	usesTime bool
+}
+
+// nameReached walks the queued types breadth first, appending and naming
+// every named struct type they reference.
+func (g *generator) nameReached(taken map[string]bool) {
+	var reached []reflect.Type
+	for i := 0; i < len(g.queue); i++ {
+		for _, f := range jsonFields(g.queue[i]) {
+			visitTypes(f.Type, func(t reflect.Type) {
+				if t.Kind() != reflect.Struct || t.Name() == "" || isTime(t) {
+					return
+				}
+				if _, ok := g.names[t]; ok {
+					return
+				}
+				g.names[t] = ""
+				g.queue = append(g.queue, t)
+				reached = append(reached, t)
+			})
+		}
+	}
+
+	counts := map[string]int{}
+	for _, t := range reached {
+		counts[exported(t.Name())]++
+	}
+	for _, t := range reached {
+		name := exported(t.Name())
+		if counts[name] > 1 || taken[name] {
+			name = exported(path.Base(t.PkgPath())) + name
+		}
+		for base, n := name, 2; taken[name]; n++ {
+			name = fmt.Sprintf("%s%d", base, n)
+		}
+		taken[name] = true
+		g.names[t] = name
+	}
+}
+
+func (g *generator) structBody(t reflect.Type) string {
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	for _, f := range jsonFields(t) {
+		if f.Anonymous {
+			fmt.Fprintf(&b, "\t%s\n", g.typeExpr(f.Type))
+			continue
+		}
+		fmt.Fprintf(&b, "\t%s %s", f.Name, g.typeExpr(f.Type))
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			fmt.Fprintf(&b, " `json:%q`", tag)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func (g *generator) typeExpr(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + g.typeExpr(t.Elem())
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "[]byte"
+		}
+		return "[]" + g.typeExpr(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), g.typeExpr(t.Elem()))
+	case reflect.Map:
+		return "map[" + g.typeExpr(t.Key()) + "]" + g.typeExpr(t.Elem())
+	case reflect.Interface:
+		return "any"
+	case reflect.Struct:
+		if isTime(t) {
+			g.usesTime = true
+			return "time.Time"
+		}
+		if name := g.names[t]; name != "" {
+			return name
+		}
+		return g.structBody(t)
+	default:
+		// Named scalars such as enums are emitted as their underlying kind.
+		return t.Kind().String()
+	}
+}
+
+// jsonFields returns the struct fields encoding/json considers: exported
+// fields and embedded structs, minus those tagged "-".
+func jsonFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("json") == "-" {
+			continue
+		}
+		if !f.IsExported() && !(f.Anonymous && structType(f.Type) != nil) {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func visitTypes(t reflect.Type, fn func(reflect.Type)) {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		visitTypes(t.Elem(), fn)
+	case reflect.Map:
+		visitTypes(t.Key(), fn)
+		visitTypes(t.Elem(), fn)
+	case reflect.Struct:
+		if t.Name() != "" {
+			fn(t)
+			return
+		}
+		for _, f := range jsonFields(t) {
+			visitTypes(f.Type, fn)
+		}
+	}
+}
+
+func structType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+func isTime(t reflect.Type) bool {
+	return t.PkgPath() == "time" && t.Name() == "Time"
+}
+
+func exported(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
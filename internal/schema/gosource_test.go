@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robertguss/recon/internal/schema/testdata/other"
+)
+
+type level string
+
+type base struct {
+	ID int64 `json:"id"`
+}
+
+type Leaf struct {
+	Name  string `json:"name"`
+	Level level  `json:"level,omitempty"`
+}
+
+type payload struct {
+	base
+	At       time.Time         `json:"at"`
+	Leaf     *Leaf             `json:"leaf,omitempty"`
+	Leaves   []Leaf            `json:"leaves"`
+	ByName   map[string][]Leaf `json:"by_name"`
+	Other    other.Leaf        `json:"other"`
+	Inline   struct{ N int }   `json:"inline"`
+	Raw      []byte            `json:"raw"`
+	Extra    any               `json:"extra,omitempty"`
+	Skipped  string            `json:"-"`
+	Untagged int
+	hidden   string
+}
+
+func TestGoSourceRendersReachableTypes(t *testing.T) {
+	src, err := GoSource("api", []Type{{Name: "Payload", Doc: "Payload is a test payload.\nSecond line.", Value: payload{hidden: "x"}}})
+	if err != nil {
+		t.Fatalf("GoSource: %v", err)
+	}
+	out := string(src)
+	flat := strings.Join(strings.Fields(out), " ")
+	if !strings.HasPrefix(out, "// Code generated by recon schema --go. DO NOT EDIT.\n\npackage api\n") {
+		t.Fatalf("unexpected header:\n%s", out)
+	}
+	for _, want := range []string{
+		`import "time"`,
+		"// Payload is a test payload. // Second line. type Payload struct { Base At time.Time",
+		"Leaf *SchemaLeaf",
+		"ByName map[string][]SchemaLeaf",
+		"Other OtherLeaf",
+		"Inline struct { N int } `json:\"inline\"`",
+		"Raw []byte",
+		"Extra any",
+		"Untagged int }",
+		"type SchemaLeaf struct { Name string `json:\"name\"` Level string `json:\"level,omitempty\"` }",
+		"type OtherLeaf struct {",
+	} {
+		if !strings.Contains(flat, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"Skipped", "hidden"} {
+		if strings.Contains(out, unwanted) {
+			t.Fatalf("did not expect %q in output:\n%s", unwanted, out)
+		}
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "api.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse generated source: %v", err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("api", fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("type-check generated source: %v\n%s", err, out)
+	}
+}
+
+func TestGoSourceErrors(t *testing.T) {
+	if _, err := GoSource("api", []Type{{Name: "S", Value: "text"}}); err == nil || !strings.Contains(err.Error(), "is not a struct") {
+		t.Fatalf("expected non-struct error, got %v", err)
+	}
+	dup := []Type{{Name: "A", Value: Leaf{}}, {Name: "A", Value: base{}}}
+	if _, err := GoSource("api", dup); err == nil || !strings.Contains(err.Error(), "duplicate type name") {
+		t.Fatalf("expected duplicate name error, got %v", err)
+	}
+	if _, err := GoSource("not a package", []Type{{Name: "A", Value: Leaf{}}}); err == nil || !strings.Contains(err.Error(), "format generated source") {
+		t.Fatalf("expected format error, got %v", err)
+	}
+}
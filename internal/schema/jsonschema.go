@@ -0,0 +1,215 @@
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Draft is the JSON Schema dialect JSONSchema emits.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	jsonMarshaler = reflect.TypeFor[json.Marshaler]()
+	textMarshaler = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// JSONSchema renders a JSON Schema for the JSON encoding of types under
+// title. Each type, and every named struct type it reaches, is a definition
+// under $defs, named as GoSource names it; the root refers to the single type
+// or, for several, accepts any of them. Fields without omitempty are
+// required. Slices and maps may be null, as encoding/json writes them when
+// nil.
+func JSONSchema(title string, types []Type) ([]byte, error) {
+	g := &generator{names: map[reflect.Type]string{}}
+	taken := map[string]bool{}
+	for _, t := range types {
+		rt := structType(reflect.TypeOf(t.Value))
+		if rt == nil {
+			return nil, fmt.Errorf("%s: %T is not a struct", t.Name, t.Value)
+		}
+		if taken[t.Name] {
+			return nil, fmt.Errorf("duplicate type name %q", t.Name)
+		}
+		taken[t.Name] = true
+		g.names[rt] = t.Name
+		g.queue = append(g.queue, rt)
+		g.docs = append(g.docs, t.Doc)
+	}
+	g.nameReached(taken)
+
+	defs := map[string]any{}
+	for i, rt := range g.queue {
+		def := g.objectSchema(rt)
+		if i < len(g.docs) && g.docs[i] != "" {
+			def["description"] = g.docs[i]
+		}
+		defs[g.names[rt]] = def
+	}
+	refs := make([]any, len(types))
+	for i, t := range types {
+		refs[i] = map[string]any{"$ref": "#/$defs/" + t.Name}
+	}
+	// A struct keeps $schema and title ahead of the definitions.
+	root := struct {
+		Schema string         `json:"$schema"`
+		Title  string         `json:"title"`
+		Ref    any            `json:"$ref,omitempty"`
+		AnyOf  []any          `json:"anyOf,omitempty"`
+		Defs   map[string]any `json:"$defs"`
+	}{Schema: Draft, Title: title, Defs: referenced(defs, refs)}
+	if len(refs) == 1 {
+		root.Ref = refs[0].(map[string]any)["$ref"]
+	} else {
+		root.AnyOf = refs
+	}
+	out, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode JSON schema: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+// objectSchema describes a struct as encoding/json writes it: the fields of
+// embedded structs without a json name are promoted into it.
+func (g *generator) objectSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	g.addFields(t, properties, &required, true)
+	s := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func (g *generator) addFields(t reflect.Type, properties map[string]any, required *[]string, mandatory bool) {
+	for _, f := range jsonFields(t) {
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.Anonymous && name == "" {
+			if embedded := structType(f.Type); embedded != nil {
+				g.addFields(embedded, properties, required, mandatory && f.Type.Kind() != reflect.Pointer)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s := g.schemaFor(f.Type)
+		if hasOption(opts, "string") {
+			s = map[string]any{"type": "string"}
+		}
+		properties[name] = s
+		if mandatory && !hasOption(opts, "omitempty") && !hasOption(opts, "omitzero") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func (g *generator) schemaFor(t reflect.Type) map[string]any {
+	if isTime(t) {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	// A type that encodes itself may produce anything.
+	if t.Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(jsonMarshaler) {
+		return map[string]any{}
+	}
+	if t.Implements(textMarshaler) || reflect.PointerTo(t).Implements(textMarshaler) {
+		return map[string]any{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(g.schemaFor(t.Elem()))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": []any{"string", "null"}}
+		}
+		return map[string]any{"type": []any{"array", "null"}, "items": g.schemaFor(t.Elem())}
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": []any{"object", "null"}, "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Interface:
+		return map[string]any{}
+	case reflect.Struct:
+		if name := g.names[t]; name != "" {
+			return map[string]any{"$ref": "#/$defs/" + name}
+		}
+		return g.objectSchema(t)
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	default:
+		return map[string]any{}
+	}
+}
+
+// referenced keeps the definitions reachable from roots. Embedded structs
+// are named like any other but promoted into their parent, so nothing may
+// refer to them.
+func referenced(defs map[string]any, roots []any) map[string]any {
+	kept := map[string]any{}
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, "#/$defs/")
+				if _, seen := kept[name]; !seen {
+					kept[name] = defs[name]
+					walk(defs[name])
+				}
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(roots)
+	return kept
+}
+
+// nullable widens s to also accept null.
+func nullable(s map[string]any) map[string]any {
+	switch typ := s["type"].(type) {
+	case string:
+		s["type"] = []any{typ, "null"}
+		return s
+	case []any:
+		for _, v := range typ {
+			if v == "null" {
+				return s
+			}
+		}
+		s["type"] = append(typ, "null")
+		return s
+	}
+	if len(s) == 0 {
+		return s
+	}
+	return map[string]any{"anyOf": []any{s, map[string]any{"type": "null"}}}
+}
+
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONSchemaDescribesEncoding(t *testing.T) {
+	out, err := JSONSchema("test", []Type{{Name: "Payload", Doc: "Payload is a test payload.", Value: payload{}}})
+	if err != nil {
+		t.Fatalf("JSONSchema: %v", err)
+	}
+	var root struct {
+		Schema string                     `json:"$schema"`
+		Title  string                     `json:"title"`
+		Ref    string                     `json:"$ref"`
+		Defs   map[string]json.RawMessage `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &root); err != nil {
+		t.Fatalf("decode schema: %v\n%s", err, out)
+	}
+	if root.Schema != Draft || root.Title != "test" || root.Ref != "#/$defs/Payload" {
+		t.Fatalf("unexpected root: %+v", root)
+	}
+	if _, ok := root.Defs["Base"]; ok {
+		t.Fatalf("expected the embedded struct to be promoted, not defined:\n%s", out)
+	}
+	flat := strings.Join(strings.Fields(string(root.Defs["Payload"])), " ")
+	for _, want := range []string{
+		`"description": "Payload is a test payload."`,
+		`"id": { "type": "integer" }`,
+		`"at": { "format": "date-time", "type": "string" }`,
+		`"leaf": { "anyOf": [ { "$ref": "#/$defs/SchemaLeaf" }, { "type": "null" } ] }`,
+		`"by_name": { "additionalProperties": { "items": { "$ref": "#/$defs/SchemaLeaf" }, "type": [ "array", "null" ] }, "type": [ "object", "null" ] }`,
+		`"raw": { "type": [ "string", "null" ] }`,
+		`"extra": {}`,
+		`"required": [ "id", "at", "leaves", "by_name", "other", "inline", "raw", "Untagged" ]`,
+	} {
+		if !strings.Contains(flat, want) {
+			t.Fatalf("expected %s in Payload definition:\n%s", want, root.Defs["Payload"])
+		}
+	}
+	for _, unwanted := range []string{"Skipped", "hidden"} {
+		if strings.Contains(string(out), unwanted) {
+			t.Fatalf("did not expect %q in schema:\n%s", unwanted, out)
+		}
+	}
+
+	doc, err := json.Marshal(payload{At: time.Now(), Leaf: &Leaf{Name: "a"}, Extra: []int{1}})
+	if err != nil {
+		t.Fatalf("encode payload: %v", err)
+	}
+	if err := Validate(out, doc); err != nil {
+		t.Fatalf("expected payload to validate: %v\n%s", err, doc)
+	}
+}
+
+func TestJSONSchemaAlternatives(t *testing.T) {
+	out, err := JSONSchema("either", []Type{{Name: "A", Value: Leaf{}}, {Name: "B", Value: base{}}})
+	if err != nil {
+		t.Fatalf("JSONSchema: %v", err)
+	}
+	for _, doc := range []string{`{"name":"x"}`, `{"id":1}`} {
+		if err := Validate(out, []byte(doc)); err != nil {
+			t.Fatalf("expected %s to validate: %v", doc, err)
+		}
+	}
+	if err := Validate(out, []byte(`{"id":"1"}`)); err == nil || !strings.Contains(err.Error(), "matches none of anyOf") {
+		t.Fatalf("expected anyOf error, got %v", err)
+	}
+
+	if _, err := JSONSchema("x", []Type{{Name: "S", Value: "text"}}); err == nil || !strings.Contains(err.Error(), "is not a struct") {
+		t.Fatalf("expected non-struct error, got %v", err)
+	}
+	dup := []Type{{Name: "A", Value: Leaf{}}, {Name: "A", Value: base{}}}
+	if _, err := JSONSchema("x", dup); err == nil || !strings.Contains(err.Error(), "duplicate type name") {
+		t.Fatalf("expected duplicate name error, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema := []byte(`{
+  "$ref": "#/$defs/Root",
+  "$defs": {
+    "Root": {
+      "type": "object",
+      "required": ["id", "tags"],
+      "properties": {
+        "id": {"type": "integer"},
+        "score": {"type": "number"},
+        "tags": {"type": ["array", "null"], "items": {"type": "string"}},
+        "meta": {"type": "object", "additionalProperties": false}
+      }
+    }
+  }
+}`)
+	for doc, want := range map[string]string{
+		`{"id":1,"tags":null,"score":2}`:       "",
+		`{"id":1,"tags":["a"],"score":0.5}`:    "",
+		`{"tags":[]}`:                          `$: missing required property "id"`,
+		`{"id":1.5,"tags":[]}`:                 "$.id: expected integer, got number",
+		`{"id":1,"tags":["a",2]}`:              "$.tags[1]: expected string, got integer",
+		`{"id":1,"tags":[],"meta":{"x":true}}`: "$.meta.x: unexpected property",
+		`[]`:                                   "$: expected object, got array",
+		`{`:                                    "parse document",
+	} {
+		err := Validate(schema, []byte(doc))
+		if want == "" {
+			if err != nil {
+				t.Fatalf("%s: expected valid, got %v", doc, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected error containing %q, got %v", doc, want, err)
+		}
+	}
+	if err := Validate([]byte(`{`), []byte(`{}`)); err == nil || !strings.Contains(err.Error(), "parse schema") {
+		t.Fatalf("expected schema parse error, got %v", err)
+	}
+	if err := Validate([]byte(`{"$ref":"#/$defs/Missing"}`), []byte(`{}`)); err == nil || !strings.Contains(err.Error(), "unresolved $ref") {
+		t.Fatalf("expected unresolved ref error, got %v", err)
+	}
+}
@@ -0,0 +1,5 @@
+package other
+
+type Leaf struct {
+	Value int `json:"value"`
+}
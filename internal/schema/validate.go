@@ -0,0 +1,144 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Validate checks the JSON document doc against schema. It understands the
+// keywords JSONSchema emits: $ref into $defs, anyOf, type, properties,
+// required, items, and additionalProperties. The error names the path of the
+// first value that does not conform, such as $.decisions[0].id.
+func Validate(schema, doc []byte) error {
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return fmt.Errorf("parse schema: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("parse document: %w", err)
+	}
+	return validator{root: root}.check(root, value, "$")
+}
+
+type validator struct {
+	root map[string]any
+}
+
+func (v validator) check(s map[string]any, value any, path string) error {
+	if ref, ok := s["$ref"].(string); ok {
+		name, found := strings.CutPrefix(ref, "#/$defs/")
+		defs, _ := v.root["$defs"].(map[string]any)
+		def, ok := defs[name].(map[string]any)
+		if !found || !ok {
+			return fmt.Errorf("%s: unresolved $ref %q", path, ref)
+		}
+		if err := v.check(def, value, path); err != nil {
+			return err
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok {
+		var errs []string
+		for _, alt := range anyOf {
+			alt, _ := alt.(map[string]any)
+			err := v.check(alt, value, path)
+			if err == nil {
+				errs = nil
+				break
+			}
+			errs = append(errs, err.Error())
+		}
+		if errs != nil {
+			return fmt.Errorf("%s: matches none of anyOf (%s)", path, strings.Join(errs, "; "))
+		}
+	}
+	if typ, ok := s["type"]; ok {
+		var allowed []string
+		switch typ := typ.(type) {
+		case string:
+			allowed = []string{typ}
+		case []any:
+			for _, t := range typ {
+				name, _ := t.(string)
+				allowed = append(allowed, name)
+			}
+		}
+		actual := jsonType(value)
+		if !slices.Contains(allowed, actual) && !(actual == "integer" && slices.Contains(allowed, "number")) {
+			return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(allowed, " or "), actual)
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		properties, _ := s["properties"].(map[string]any)
+		if required, ok := s["required"].([]any); ok {
+			for _, name := range required {
+				name, _ := name.(string)
+				if _, ok := value[name]; !ok {
+					return fmt.Errorf("%s: missing required property %q", path, name)
+				}
+			}
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := path + "." + key
+			if prop, ok := properties[key].(map[string]any); ok {
+				if err := v.check(prop, value[key], child); err != nil {
+					return err
+				}
+				continue
+			}
+			switch extra := s["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					return fmt.Errorf("%s: unexpected property", child)
+				}
+			case map[string]any:
+				if err := v.check(extra, value[key], child); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		if items, ok := s["items"].(map[string]any); ok {
+			for i, item := range value {
+				if err := v.check(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// jsonType names the JSON Schema type of a value decoded with UseNumber.
+func jsonType(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,298 @@
+package snapshot
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+const (
+	// DirName is the directory under .recon/ that holds snapshots.
+	DirName = "snapshots"
+	// DefaultKeep is how many snapshots are retained when neither the
+	// config nor the caller sets a limit.
+	DefaultKeep = 10
+
+	ext        = ".db.gz"
+	nameLayout = "20060102T150405Z"
+)
+
+// GitIgnore is the .gitignore entry that keeps snapshots out of git.
+var GitIgnore = ".recon/" + DirName + "/"
+
+var ErrNotFound = fmt.Errorf("not found")
+
+// ErrInvalidLabel is returned for a label that cannot be part of a file name.
+var ErrInvalidLabel = errors.New("invalid snapshot label")
+
+var labelPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Snapshot is a gzip-compressed copy of the recon database. Name is the
+// file name without extension: the UTC creation time plus an optional label.
+type Snapshot struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	CreatedAt string `json:"created_at"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// Dir returns the snapshot directory for a module root.
+func Dir(root string) string {
+	return filepath.Join(db.ReconDir(root), DirName)
+}
+
+// Create writes a consistent copy of the database behind conn to the
+// snapshot directory. It does not prune; see Prune.
+func Create(ctx context.Context, conn *sql.DB, root, label string, now time.Time) (Snapshot, error) {
+	if label != "" && !labelPattern.MatchString(label) {
+		return Snapshot{}, fmt.Errorf("%w %q: use only letters, digits, '.', '_', and '-'", ErrInvalidLabel, label)
+	}
+	dir := Dir(root)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Snapshot{}, fmt.Errorf("create %s: %w", dir, err)
+	}
+
+	base := now.UTC().Format(nameLayout)
+	if label != "" {
+		base += "-" + label
+	}
+	name := base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(dir, name+ext)); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		name = base + "-" + strconv.Itoa(i)
+	}
+
+	// VACUUM INTO copies a consistent view even while other connections
+	// write, and leaves out free pages.
+	raw := filepath.Join(dir, name+".db.tmp")
+	defer os.Remove(raw)
+	if _, err := conn.ExecContext(ctx, "VACUUM INTO ?", raw); err != nil {
+		return Snapshot{}, fmt.Errorf("copy database: %w", err)
+	}
+
+	path := filepath.Join(dir, name+ext)
+	if err := compress(raw, path); err != nil {
+		return Snapshot{}, err
+	}
+	return stat(path)
+}
+
+// List returns the snapshots of a module root, newest first.
+func List(root string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(Dir(root))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Snapshot{}, nil
+		}
+		return nil, fmt.Errorf("read snapshots: %w", err)
+	}
+	snaps := []Snapshot{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ext) {
+			continue
+		}
+		snap, err := stat(filepath.Join(Dir(root), e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		snaps = append(snaps, snap)
+	}
+	// Names start with the creation time, so they sort chronologically.
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Name > snaps[j].Name })
+	return snaps, nil
+}
+
+// Find returns the snapshot called name, with or without its extension, or
+// the newest one for "latest".
+func Find(root, name string) (Snapshot, error) {
+	snaps, err := List(root)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	name = strings.TrimSuffix(name, ext)
+	for _, s := range snaps {
+		if s.Name == name || name == "latest" {
+			return s, nil
+		}
+	}
+	return Snapshot{}, ErrNotFound
+}
+
+// Prune deletes all but the keep newest snapshots (keep <= 0 means
+// DefaultKeep) and returns the deleted ones.
+func Prune(root string, keep int) ([]Snapshot, error) {
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+	snaps, err := List(root)
+	if err != nil || len(snaps) <= keep {
+		return nil, err
+	}
+	pruned := snaps[keep:]
+	for _, s := range pruned {
+		if err := os.Remove(s.Path); err != nil {
+			return nil, fmt.Errorf("prune snapshot %s: %w", s.Name, err)
+		}
+	}
+	return pruned, nil
+}
+
+// Restore replaces the database of a module root with snap. The snapshot is
+// decompressed beside the database, checked, and migrated to the current
+// schema before it is swapped in, so a corrupt snapshot leaves the database
+// untouched. A database in WAL mode stays in WAL mode. No connection to the
+// database may be open.
+func Restore(ctx context.Context, root string, snap Snapshot) error {
+	path := db.DBPath(root)
+	wal, err := walMode(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".restore"
+	defer os.Remove(tmp)
+	if err := decompress(snap.Path, tmp); err != nil {
+		return err
+	}
+	if err := prepare(ctx, tmp, wal); err != nil {
+		return fmt.Errorf("snapshot %s: %w", snap.Name, err)
+	}
+
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(path + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove %s: %w", filepath.Base(path+suffix), err)
+		}
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace database: %w", err)
+	}
+	return nil
+}
+
+// walMode reports whether the database at path uses write-ahead logging.
+// A missing database does not.
+func walMode(ctx context.Context, path string) (bool, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	conn, err := db.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	var mode string
+	if err := conn.QueryRowContext(ctx, "PRAGMA journal_mode;").Scan(&mode); err != nil {
+		return false, fmt.Errorf("read journal mode: %w", err)
+	}
+	return strings.EqualFold(mode, "wal"), nil
+}
+
+// prepare checks the restored copy at path and migrates it to the schema
+// this build expects.
+func prepare(ctx context.Context, path string, wal bool) error {
+	conn, err := db.Open(path)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var result string
+	if err := conn.QueryRowContext(ctx, "PRAGMA quick_check;").Scan(&result); err != nil {
+		return fmt.Errorf("check database: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("check database: %s", result)
+	}
+	if err := db.RunMigrations(conn); err != nil {
+		return err
+	}
+	if wal {
+		return db.EnableWAL(conn)
+	}
+	return nil
+}
+
+func compress(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("open database copy: %w", err)
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create snapshot: %w", err)
+	}
+	defer os.Remove(tmp)
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		out.Close()
+		return fmt.Errorf("compress snapshot: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		return fmt.Errorf("compress snapshot: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	return nil
+}
+
+func decompress(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("open snapshot: %w", err)
+	}
+	defer in.Close()
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return fmt.Errorf("read snapshot: %w", err)
+	}
+	defer gz.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Base(dst), err)
+	}
+	if _, err := io.Copy(out, gz); err != nil {
+		out.Close()
+		return fmt.Errorf("decompress snapshot: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", filepath.Base(dst), err)
+	}
+	return nil
+}
+
+func stat(path string) (Snapshot, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("stat snapshot: %w", err)
+	}
+	name := strings.TrimSuffix(filepath.Base(path), ext)
+	created := info.ModTime().UTC()
+	if len(name) >= len(nameLayout) {
+		if t, err := time.Parse(nameLayout, name[:len(nameLayout)]); err == nil {
+			created = t
+		}
+	}
+	return Snapshot{Name: name, Path: path, CreatedAt: created.Format(time.RFC3339), SizeBytes: info.Size()}, nil
+}
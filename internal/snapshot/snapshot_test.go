@@ -0,0 +1,166 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func setupDB(t *testing.T, root string) *sql.DB {
+	t.Helper()
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	if err := db.EnableWAL(conn); err != nil {
+		t.Fatalf("EnableWAL: %v", err)
+	}
+	return conn
+}
+
+func countDecisions(t *testing.T, root string) int {
+	t.Helper()
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	var n int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM decisions;").Scan(&n); err != nil {
+		t.Fatalf("count decisions: %v", err)
+	}
+	return n
+}
+
+func TestCreateListFindAndPrune(t *testing.T) {
+	root := t.TempDir()
+	conn := setupDB(t, root)
+	defer conn.Close()
+	ctx := context.Background()
+	now := time.Date(2026, 2, 18, 10, 30, 0, 0, time.UTC)
+
+	first, err := Create(ctx, conn, root, "", now)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if first.Name != "20260218T103000Z" || first.CreatedAt != "2026-02-18T10:30:00Z" || first.SizeBytes == 0 {
+		t.Fatalf("first = %+v", first)
+	}
+	dup, err := Create(ctx, conn, root, "", now)
+	if err != nil || dup.Name != "20260218T103000Z-2" {
+		t.Fatalf("duplicate = %+v, %v", dup, err)
+	}
+	labelled, err := Create(ctx, conn, root, "before-refactor", now.Add(time.Hour))
+	if err != nil || labelled.Name != "20260218T113000Z-before-refactor" {
+		t.Fatalf("labelled = %+v, %v", labelled, err)
+	}
+	if _, err := Create(ctx, conn, root, "../escape", now); !errors.Is(err, ErrInvalidLabel) {
+		t.Fatalf("invalid label err = %v", err)
+	}
+
+	snaps, err := List(root)
+	if err != nil || len(snaps) != 3 || snaps[0].Name != labelled.Name {
+		t.Fatalf("List = %+v, %v", snaps, err)
+	}
+	if got, err := Find(root, "latest"); err != nil || got.Name != labelled.Name {
+		t.Fatalf("Find latest = %+v, %v", got, err)
+	}
+	if got, err := Find(root, first.Name+".db.gz"); err != nil || got.Name != first.Name {
+		t.Fatalf("Find with extension = %+v, %v", got, err)
+	}
+	if _, err := Find(root, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Find missing err = %v", err)
+	}
+
+	pruned, err := Prune(root, 1)
+	if err != nil || len(pruned) != 2 {
+		t.Fatalf("Prune = %+v, %v", pruned, err)
+	}
+	if snaps, _ := List(root); len(snaps) != 1 || snaps[0].Name != labelled.Name {
+		t.Fatalf("after prune = %+v", snaps)
+	}
+	if pruned, err := Prune(root, 0); err != nil || pruned != nil {
+		t.Fatalf("Prune under default keep = %+v, %v", pruned, err)
+	}
+}
+
+func TestListWithoutSnapshots(t *testing.T) {
+	snaps, err := List(t.TempDir())
+	if err != nil || snaps == nil || len(snaps) != 0 {
+		t.Fatalf("List = %#v, %v", snaps, err)
+	}
+	if _, err := Find(t.TempDir(), "latest"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Find latest err = %v", err)
+	}
+}
+
+func TestRestoreUndoesChanges(t *testing.T) {
+	root := t.TempDir()
+	conn := setupDB(t, root)
+	ctx := context.Background()
+	if _, err := conn.Exec(`INSERT INTO decisions (title, reasoning, confidence, status, created_at, updated_at) VALUES ('Use SQLite', 'single file', 'high', 'active', '2026-01-01T00:00:00Z', '2026-01-01T00:00:00Z');`); err != nil {
+		t.Fatalf("insert decision: %v", err)
+	}
+	snap, err := Create(ctx, conn, root, "", time.Now())
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := conn.Exec(`DELETE FROM decisions;`); err != nil {
+		t.Fatalf("delete decisions: %v", err)
+	}
+	conn.Close()
+
+	if err := Restore(ctx, root, snap); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if n := countDecisions(t, root); n != 1 {
+		t.Fatalf("decisions after restore = %d, want 1", n)
+	}
+	if _, err := os.Stat(db.DBPath(root) + ".restore"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("restore file left behind: %v", err)
+	}
+	if wal, err := walMode(ctx, db.DBPath(root)); err != nil || !wal {
+		t.Fatalf("walMode = %v, %v; want WAL kept", wal, err)
+	}
+}
+
+func TestRestoreRejectsCorruptSnapshot(t *testing.T) {
+	root := t.TempDir()
+	conn := setupDB(t, root)
+	conn.Close()
+	before, err := os.ReadFile(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("read db: %v", err)
+	}
+
+	if err := os.MkdirAll(Dir(root), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	bad := filepath.Join(Dir(root), "20260101T000000Z.db.gz")
+	if err := os.WriteFile(bad, []byte("not gzip"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	snap, err := Find(root, "latest")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if err := Restore(context.Background(), root, snap); err == nil {
+		t.Fatal("expected error restoring a corrupt snapshot")
+	}
+	after, err := os.ReadFile(db.DBPath(root))
+	if err != nil || string(after) != string(before) {
+		t.Fatalf("database changed by a failed restore (err %v)", err)
+	}
+}
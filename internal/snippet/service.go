@@ -0,0 +1,263 @@
+package snippet
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robertguss/recon/internal/find"
+)
+
+// statFile is a package-level var for testability.
+var statFile = os.Stat
+
+// Snippet is one call site of the target symbol, with a few lines of context
+// from the calling function.
+type Snippet struct {
+	Caller      string   `json:"caller"`
+	CallerKind  string   `json:"caller_kind"`
+	FilePath    string   `json:"file_path"`
+	Package     string   `json:"package"`
+	Line        int      `json:"line"`
+	LineStart   int      `json:"line_start"`
+	Code        string   `json:"code"`
+	CallerLines int      `json:"caller_lines"`
+	FanIn       int      `json:"fan_in"`
+	ModifiedAt  string   `json:"modified_at,omitempty"`
+	Reasons     []string `json:"reasons"`
+}
+
+type Result struct {
+	Symbol       find.Symbol `json:"symbol"`
+	Snippets     []Snippet   `json:"snippets"`
+	TotalCallers int         `json:"total_callers"`
+}
+
+type Options struct {
+	Query      find.QueryOptions
+	Limit      int
+	Context    int
+	ModuleRoot string
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+type caller struct {
+	snippet  Snippet
+	modified time.Time
+}
+
+// Snippets resolves symbol like find does and returns representative call
+// sites from the index: the shortest calling function, the most recently
+// modified one, and the most central one (highest fan-in), then the rest by
+// centrality until Limit is reached. Each snippet is tagged with the reasons
+// it was picked.
+func (s *Service) Snippets(ctx context.Context, symbol string, opts Options) (Result, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 3
+	}
+	if opts.Context < 0 {
+		opts.Context = 0
+	}
+
+	found, err := find.NewService(s.db).Find(ctx, symbol, opts.Query)
+	if err != nil {
+		return Result{}, err
+	}
+	target := found.Symbol
+
+	callers, err := s.callers(ctx, target, opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Symbol:       target,
+		Snippets:     pickSnippets(callers, opts.Limit),
+		TotalCallers: len(callers),
+	}, nil
+}
+
+func (s *Service) callers(ctx context.Context, target find.Symbol, opts Options) ([]caller, error) {
+	// Method calls are recorded without the receiver's package, so they match
+	// by name alone; function calls must also match the target's package.
+	depFilter := "d.dep_kind = 'func' AND d.dep_package = ?"
+	args := []any{target.Name, target.Package}
+	if target.Kind == "method" {
+		depFilter = "d.dep_kind = 'method'"
+		args = []any{target.Name}
+	}
+	args = append(args, target.ID)
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT DISTINCT s.id, s.kind, s.name, COALESCE(s.receiver, ''), COALESCE(b.body, ''),
+       s.line_start, s.line_end, f.path, COALESCE(p.path, '.'),
+       (SELECT COUNT(DISTINCT d2.symbol_id) FROM symbol_deps d2
+        WHERE d2.dep_name = s.name
+          AND ((s.kind = 'method' AND d2.dep_kind = 'method')
+            OR (s.kind = 'func' AND d2.dep_kind = 'func' AND d2.dep_package = COALESCE(p.path, '.'))))
+FROM symbol_deps d
+JOIN symbols s ON s.id = d.symbol_id
+LEFT JOIN symbol_bodies b ON b.hash = s.body_hash
+JOIN files f ON f.id = s.file_id
+LEFT JOIN packages p ON p.id = f.package_id
+WHERE d.dep_name = ? AND `+depFilter+` AND s.id != ?
+ORDER BY p.path, f.path, s.line_start;
+`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query callers: %w", err)
+	}
+	defer rows.Close()
+
+	callRe := callPattern(target)
+	callers := []caller{}
+	for rows.Next() {
+		var (
+			id                 int64
+			kind, name, recv   string
+			body, path, pkg    string
+			lineStart, lineEnd int
+			fanIn              int
+		)
+		if err := rows.Scan(&id, &kind, &name, &recv, &body, &lineStart, &lineEnd, &path, &pkg, &fanIn); err != nil {
+			return nil, fmt.Errorf("scan caller: %w", err)
+		}
+
+		code, line, ok := extractCall(body, callRe, opts.Context)
+		if !ok {
+			continue
+		}
+		label := name
+		if recv != "" {
+			label = strings.TrimPrefix(recv, "*") + "." + name
+		}
+		c := caller{snippet: Snippet{
+			Caller:      label,
+			CallerKind:  kind,
+			FilePath:    path,
+			Package:     pkg,
+			Line:        lineStart + line,
+			LineStart:   lineStart + line - min(line, opts.Context),
+			Code:        code,
+			CallerLines: lineEnd - lineStart + 1,
+			FanIn:       fanIn,
+		}}
+		if info, err := statFile(filepath.Join(opts.ModuleRoot, path)); err == nil {
+			c.modified = info.ModTime()
+			c.snippet.ModifiedAt = c.modified.UTC().Format(time.RFC3339)
+		}
+		callers = append(callers, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate callers: %w", err)
+	}
+	return callers, nil
+}
+
+// callPattern matches a call of target: `.Name(` for methods, and `Name(` or
+// `pkg.Name(` for functions, optionally with explicit type arguments.
+func callPattern(target find.Symbol) *regexp.Regexp {
+	prefix := `(?:^|[^\w.]|\w\.)`
+	if target.Kind == "method" {
+		prefix = `\.`
+	}
+	return regexp.MustCompile(prefix + regexp.QuoteMeta(target.Name) + `\s*(?:\[[^\]]*\])?\(`)
+}
+
+// declPrefix matches the `func (recv) Name` head of a declaration so the
+// caller's own name is never mistaken for a call.
+var declPrefix = regexp.MustCompile(`^\s*func\s+(?:\([^)]*\)\s*)?\w+`)
+
+// extractCall finds the first call matching re in body and returns it with up
+// to context lines either side and its zero-based line offset within body.
+func extractCall(body string, re *regexp.Regexp, context int) (string, int, bool) {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if i == 0 {
+			line = declPrefix.ReplaceAllString(line, "")
+		}
+		if !re.MatchString(line) {
+			continue
+		}
+		from := max(i-context, 0)
+		to := min(i+context+1, len(lines))
+		return strings.Join(lines[from:to], "\n"), i, true
+	}
+	return "", 0, false
+}
+
+func pickSnippets(callers []caller, limit int) []Snippet {
+	if len(callers) == 0 {
+		return []Snippet{}
+	}
+
+	picked := map[int][]string{}
+	var order []int
+	choose := func(idx int, reason string) {
+		if _, ok := picked[idx]; !ok {
+			if len(order) >= limit {
+				return
+			}
+			order = append(order, idx)
+		}
+		picked[idx] = append(picked[idx], reason)
+	}
+
+	shortest, recent, central := 0, -1, 0
+	for i, c := range callers {
+		if c.snippet.CallerLines < callers[shortest].snippet.CallerLines {
+			shortest = i
+		}
+		if !c.modified.IsZero() && (recent < 0 || c.modified.After(callers[recent].modified)) {
+			recent = i
+		}
+		if c.snippet.FanIn > callers[central].snippet.FanIn {
+			central = i
+		}
+	}
+	choose(shortest, "shortest")
+	if recent >= 0 {
+		choose(recent, "most_recent")
+	}
+	choose(central, "most_central")
+
+	rest := make([]int, 0, len(callers))
+	for i := range callers {
+		if _, ok := picked[i]; !ok {
+			rest = append(rest, i)
+		}
+	}
+	sort.SliceStable(rest, func(a, b int) bool {
+		ca, cb := callers[rest[a]].snippet, callers[rest[b]].snippet
+		if ca.FanIn != cb.FanIn {
+			return ca.FanIn > cb.FanIn
+		}
+		return ca.CallerLines < cb.CallerLines
+	})
+	for _, idx := range rest {
+		if len(order) >= limit {
+			break
+		}
+		choose(idx, "additional")
+	}
+
+	snippets := make([]Snippet, 0, len(order))
+	for _, idx := range order {
+		sn := callers[idx].snippet
+		sn.Reasons = picked[idx]
+		snippets = append(snippets, sn)
+	}
+	return snippets
+}
@@ -0,0 +1,162 @@
+package snippet
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robertguss/recon/internal/db"
+	"github.com/robertguss/recon/internal/find"
+	"github.com/robertguss/recon/internal/index"
+)
+
+func syncedModule(t *testing.T, files map[string]string) (string, *sql.DB) {
+	t.Helper()
+	root := t.TempDir()
+	files["go.mod"] = "module example.com/recon\n"
+	for path, body := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", path, err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	if _, err := index.NewService(conn).Sync(context.Background(), root); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	return root, conn
+}
+
+func TestSnippetsPicksShortestRecentAndCentral(t *testing.T) {
+	root, conn := syncedModule(t, map[string]string{
+		"store/store.go": `package store
+
+func Open(path string) error { return nil }
+`,
+		"a.go": `package main
+
+import "example.com/recon/store"
+
+func short() { _ = store.Open("a") }
+`,
+		"b.go": `package main
+
+import "example.com/recon/store"
+
+func hub() {
+	x := 1
+	_ = x
+	if err := store.Open("b"); err != nil {
+		panic(err)
+	}
+}
+`,
+		"c.go": `package main
+
+import "example.com/recon/store"
+
+func fresh() {
+	y := 2
+	_ = y
+	_ = store.Open("c")
+}
+
+func main() { hub(); hub() }
+func other() { hub() }
+`,
+	})
+
+	old := time.Now().Add(-time.Hour)
+	for _, f := range []string{"a.go", "b.go"} {
+		if err := os.Chtimes(filepath.Join(root, f), old, old); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+
+	res, err := NewService(conn).Snippets(context.Background(), "Open", Options{Limit: 3, Context: 1, ModuleRoot: root})
+	if err != nil {
+		t.Fatalf("Snippets: %v", err)
+	}
+	if res.Symbol.Package != "store" || res.TotalCallers != 3 || len(res.Snippets) != 3 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	byCaller := map[string]Snippet{}
+	for _, sn := range res.Snippets {
+		byCaller[sn.Caller] = sn
+	}
+	if r := strings.Join(byCaller["short"].Reasons, ","); r != "shortest" {
+		t.Fatalf("short reasons = %q", r)
+	}
+	if r := strings.Join(byCaller["fresh"].Reasons, ","); r != "most_recent" {
+		t.Fatalf("fresh reasons = %q", r)
+	}
+	hub := byCaller["hub"]
+	if strings.Join(hub.Reasons, ",") != "most_central" || hub.FanIn != 2 {
+		t.Fatalf("hub snippet = %+v", hub)
+	}
+	if hub.Line != 8 || hub.LineStart != 7 || !strings.Contains(hub.Code, `store.Open("b")`) || strings.Count(hub.Code, "\n") != 2 {
+		t.Fatalf("hub code/lines = %d/%d %q", hub.Line, hub.LineStart, hub.Code)
+	}
+
+	res, err = NewService(conn).Snippets(context.Background(), "Open", Options{Limit: 1, ModuleRoot: root})
+	if err != nil || len(res.Snippets) != 1 || res.Snippets[0].Caller != "short" {
+		t.Fatalf("limit 1: %+v err=%v", res, err)
+	}
+}
+
+func TestSnippetsMethodsAndErrors(t *testing.T) {
+	root, conn := syncedModule(t, map[string]string{
+		"main.go": `package main
+
+type Conn struct{}
+
+func (c *Conn) Close() error { return nil }
+
+func shutdown(c *Conn) {
+	defer c.Close()
+}
+
+func unused() {}
+
+func main() {}
+`,
+	})
+	svc := NewService(conn)
+
+	res, err := svc.Snippets(context.Background(), "Close", Options{ModuleRoot: root})
+	if err != nil {
+		t.Fatalf("Snippets: %v", err)
+	}
+	if len(res.Snippets) != 1 || res.Snippets[0].Caller != "shutdown" || res.Snippets[0].Line != 8 {
+		t.Fatalf("unexpected method snippets: %+v", res.Snippets)
+	}
+
+	res, err = svc.Snippets(context.Background(), "unused", Options{ModuleRoot: root})
+	if err != nil || len(res.Snippets) != 0 || res.TotalCallers != 0 {
+		t.Fatalf("expected no snippets, got %+v err=%v", res, err)
+	}
+
+	if _, err := svc.Snippets(context.Background(), "Missing", Options{}); err == nil {
+		t.Fatal("expected not found error")
+	} else if _, ok := err.(find.NotFoundError); !ok {
+		t.Fatalf("expected find.NotFoundError, got %T", err)
+	}
+}
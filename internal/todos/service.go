@@ -0,0 +1,108 @@
+package todos
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+// Kinds are the annotation kinds, in the order they are reported.
+var Kinds = []string{"todo", "fixme", "bug", "deprecated"}
+
+// Item is one annotation: a TODO, FIXME, or BUG comment marker, or a symbol
+// whose doc comment marks it deprecated. Symbol is set for deprecations only.
+type Item struct {
+	Kind     string `json:"kind"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Package  string `json:"package"`
+	Author   string `json:"author,omitempty"`
+	Text     string `json:"text"`
+	Symbol   string `json:"symbol,omitempty"`
+}
+
+// Options filters List. Package is a module-relative package path, or a
+// pattern ending in "/..." for a subtree. Kinds defaults to todo, fixme, and
+// bug. Zero Limit lists everything.
+type Options struct {
+	Package string
+	Kinds   []string
+	Limit   int
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// ValidKind reports whether k is an annotation kind.
+func ValidKind(k string) bool {
+	for _, kind := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the annotations matching opts, by file and line.
+func (s *Service) List(ctx context.Context, opts Options) ([]Item, error) {
+	kinds := opts.Kinds
+	if len(kinds) == 0 {
+		kinds = []string{"todo", "fixme", "bug"}
+	}
+	where := []string{"a.kind IN (" + strings.TrimSuffix(strings.Repeat("?,", len(kinds)), ",") + ")"}
+	args := make([]any, 0, len(kinds)+2)
+	for _, k := range kinds {
+		args = append(args, k)
+	}
+	switch pkg := strings.TrimSuffix(opts.Package, "/"); {
+	case pkg == "":
+	case pkg == "..." || pkg == "./...":
+	case strings.HasSuffix(pkg, "/..."):
+		base := strings.TrimSuffix(pkg, "/...")
+		where = append(where, `(COALESCE(p.path, '.') = ? OR COALESCE(p.path, '.') LIKE ? ESCAPE '\')`)
+		args = append(args, base, db.EscapeLike(base)+"/%")
+	default:
+		where = append(where, "COALESCE(p.path, '.') = ?")
+		args = append(args, pkg)
+	}
+	query := `
+SELECT a.kind, f.path, a.line, COALESCE(p.path, '.'), a.author, a.text,
+       COALESCE(CASE WHEN sy.receiver != '' THEN LTRIM(sy.receiver, '*') || '.' || sy.name ELSE sy.name END, '')
+FROM annotations a
+JOIN files f ON f.id = a.file_id
+LEFT JOIN packages p ON p.id = f.package_id
+LEFT JOIN symbols sy ON sy.id = a.symbol_id
+WHERE ` + strings.Join(where, " AND ") + `
+ORDER BY f.path, a.line, a.id`
+	if opts.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, opts.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query+";", args...)
+	if err != nil {
+		return nil, fmt.Errorf("query annotations: %w", err)
+	}
+	defer rows.Close()
+
+	items := []Item{}
+	for rows.Next() {
+		var it Item
+		if err := rows.Scan(&it.Kind, &it.FilePath, &it.Line, &it.Package, &it.Author, &it.Text, &it.Symbol); err != nil {
+			return nil, fmt.Errorf("scan annotation: %w", err)
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate annotations: %w", err)
+	}
+	return items, nil
+}
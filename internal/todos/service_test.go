@@ -0,0 +1,110 @@
+package todos
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/robertguss/recon/internal/db"
+)
+
+func TestServiceList(t *testing.T) {
+	root := t.TempDir()
+	if _, err := db.EnsureReconDir(root); err != nil {
+		t.Fatalf("EnsureReconDir: %v", err)
+	}
+	conn, err := db.Open(db.DBPath(root))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer conn.Close()
+	if err := db.RunMigrations(conn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	for _, stmt := range []string{
+		`INSERT INTO packages(id,path,name,import_path,file_count,line_count,created_at,updated_at) VALUES
+			(1,'internal/db','db','example.com/m/internal/db',1,30,'x','x'),
+			(2,'internal/db_util','util','example.com/m/internal/db_util',1,10,'x','x'),
+			(3,'.','main','example.com/m',1,10,'x','x')`,
+		`INSERT INTO files(id,package_id,path,language,lines,hash,created_at,updated_at) VALUES
+			(1,1,'internal/db/db.go','go',30,'h','x','x'),
+			(2,2,'internal/db_util/util.go','go',10,'h','x','x'),
+			(3,3,'main.go','go',10,'h','x','x')`,
+		`INSERT INTO symbols(id,file_id,kind,name,signature,line_start,line_end,exported,receiver) VALUES
+			(1,1,'method','Get','func (s *Store) Get()',12,20,1,'*Store')`,
+		`INSERT INTO annotations(file_id,symbol_id,kind,line,author,text) VALUES
+			(1,NULL,'todo',25,'alice','handle symlinks'),
+			(1,1,'deprecated',12,'','use Lookup.'),
+			(1,NULL,'fixme',3,'',''),
+			(2,NULL,'bug',4,'','off by one'),
+			(3,NULL,'todo',1,'','wire flags')`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	ctx := context.Background()
+	svc := NewService(conn)
+
+	tests := []struct {
+		name string
+		opts Options
+		want []Item
+	}{
+		{
+			name: "default kinds",
+			want: []Item{
+				{Kind: "fixme", FilePath: "internal/db/db.go", Line: 3, Package: "internal/db"},
+				{Kind: "todo", FilePath: "internal/db/db.go", Line: 25, Package: "internal/db", Author: "alice", Text: "handle symlinks"},
+				{Kind: "bug", FilePath: "internal/db_util/util.go", Line: 4, Package: "internal/db_util", Text: "off by one"},
+				{Kind: "todo", FilePath: "main.go", Line: 1, Package: ".", Text: "wire flags"},
+			},
+		},
+		{
+			name: "exact package",
+			opts: Options{Package: "internal/db", Kinds: []string{"todo"}},
+			want: []Item{
+				{Kind: "todo", FilePath: "internal/db/db.go", Line: 25, Package: "internal/db", Author: "alice", Text: "handle symlinks"},
+			},
+		},
+		{
+			name: "subtree does not match a sibling prefix",
+			opts: Options{Package: "internal/db/...", Kinds: []string{"bug", "todo"}},
+			want: []Item{
+				{Kind: "todo", FilePath: "internal/db/db.go", Line: 25, Package: "internal/db", Author: "alice", Text: "handle symlinks"},
+			},
+		},
+		{
+			name: "deprecations name their symbol",
+			opts: Options{Kinds: []string{"deprecated"}},
+			want: []Item{
+				{Kind: "deprecated", FilePath: "internal/db/db.go", Line: 12, Package: "internal/db", Text: "use Lookup.", Symbol: "Store.Get"},
+			},
+		},
+		{
+			name: "limit",
+			opts: Options{Package: "./...", Limit: 1},
+			want: []Item{
+				{Kind: "fixme", FilePath: "internal/db/db.go", Line: 3, Package: "internal/db"},
+			},
+		},
+		{
+			name: "no matches",
+			opts: Options{Package: "cmd"},
+			want: []Item{},
+		},
+	}
+	for _, tt := range tests {
+		got, err := svc.List(ctx, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: List() error = %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: List() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if !ValidKind("fixme") || ValidKind("note") {
+		t.Fatal("ValidKind() accepted the wrong kinds")
+	}
+}
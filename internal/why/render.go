@@ -0,0 +1,60 @@
+package why
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderText formats a Result for the terminal.
+func RenderText(r Result) string {
+	var b strings.Builder
+	switch r.Kind {
+	case KindSymbol:
+		label := r.Symbol.Name
+		if r.Symbol.Receiver != "" {
+			label = r.Symbol.Receiver + "." + label
+		}
+		fmt.Fprintf(&b, "Why %s %s (%s:%d, package %s)\n", r.Symbol.Kind, label, r.File, r.Symbol.LineStart, r.Package)
+	case KindFile:
+		fmt.Fprintf(&b, "Why file %s (package %s)\n", r.File, r.Package)
+	default:
+		fmt.Fprintf(&b, "Why package %s\n", r.Package)
+	}
+
+	b.WriteString("\nKnowledge:\n")
+	if len(r.Knowledge) == 0 {
+		b.WriteString("- (none recorded)\n")
+	}
+	for _, e := range r.Knowledge {
+		writeEntry(&b, e)
+	}
+
+	if len(r.History) > 0 {
+		b.WriteString("\nHistory:\n")
+		for _, e := range r.History {
+			writeEntry(&b, e)
+		}
+	}
+	return b.String()
+}
+
+func writeEntry(b *strings.Builder, e Entry) {
+	fmt.Fprintf(b, "- %s #%d %s [%s, %s]", e.EntityType, e.ID, e.Title, e.Confidence, e.Status)
+	if e.Via != "" {
+		fmt.Fprintf(b, " %s via %s", e.Relation, e.Via)
+	}
+	b.WriteString("\n")
+	if len(e.SupersededBy) > 0 {
+		fmt.Fprintf(b, "  Superseded by: %s\n", strings.Join(e.SupersededBy, ", "))
+	}
+	if e.Reasoning != "" {
+		fmt.Fprintf(b, "  Why: %s\n", e.Reasoning)
+	}
+	for _, ev := range e.Evidence {
+		fmt.Fprintf(b, "  Evidence: %s (drift=%s", ev.Summary, ev.DriftStatus)
+		if ev.LastVerifiedAt != "" {
+			fmt.Fprintf(b, ", verified %s", ev.LastVerifiedAt)
+		}
+		b.WriteString(")\n")
+	}
+}
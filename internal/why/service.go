@@ -0,0 +1,398 @@
+package why
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/robertguss/recon/internal/find"
+)
+
+// Target kinds a ref resolves to.
+const (
+	KindFile    = "file"
+	KindPackage = "package"
+	KindSymbol  = "symbol"
+)
+
+// ErrFileNotIndexed is returned for a .go path that is not in the index.
+var ErrFileNotIndexed = errors.New("file is not in the index")
+
+// Result is the recorded knowledge behind one file, package, or symbol.
+// Knowledge holds the active entities linked to it; History holds linked
+// entities that were archived or superseded, and the entities they in turn
+// superseded.
+type Result struct {
+	Ref       string       `json:"ref"`
+	Kind      string       `json:"kind"`
+	File      string       `json:"file,omitempty"`
+	Package   string       `json:"package"`
+	Symbol    *find.Symbol `json:"symbol,omitempty"`
+	Knowledge []Entry      `json:"knowledge"`
+	History   []Entry      `json:"history"`
+}
+
+// Entry is one decision, pattern, or constraint with its reasoning and
+// evidence. Relation and Via give the most specific edge linking it to the
+// target, as "type:ref"; both are empty for entries reached only through a
+// supersedes edge. SupersededBy lists the entities, as "type:id", that
+// supersede it.
+type Entry struct {
+	EntityType   string     `json:"entity_type"`
+	ID           int64      `json:"id"`
+	Title        string     `json:"title"`
+	Reasoning    string     `json:"reasoning"`
+	Confidence   string     `json:"confidence"`
+	Status       string     `json:"status"`
+	Relation     string     `json:"relation,omitempty"`
+	Via          string     `json:"via,omitempty"`
+	SupersededBy []string   `json:"superseded_by,omitempty"`
+	Evidence     []Evidence `json:"evidence"`
+	CreatedAt    string     `json:"created_at"`
+	UpdatedAt    string     `json:"updated_at"`
+}
+
+// Evidence is the verification state of one piece of an entry's evidence.
+type Evidence struct {
+	Summary        string `json:"summary"`
+	CheckType      string `json:"check_type,omitempty"`
+	DriftStatus    string `json:"drift_status"`
+	LastVerifiedAt string `json:"last_verified_at,omitempty"`
+}
+
+type Service struct {
+	db *sql.DB
+}
+
+func NewService(conn *sql.DB) *Service {
+	return &Service{db: conn}
+}
+
+// target is the set of refs whose edges explain a Result.
+type target struct {
+	symbols  []string
+	files    []string
+	packages []string
+}
+
+// Why resolves ref as an indexed file path, then a package path, then a
+// symbol the way find does, and gathers the knowledge linked to it. Symbol
+// lookup failures are find.NotFoundError or find.AmbiguousError.
+func (s *Service) Why(ctx context.Context, ref string, opts find.QueryOptions) (Result, error) {
+	result := Result{Ref: ref, Knowledge: []Entry{}, History: []Entry{}}
+	var t target
+
+	isFile, err := s.exists(ctx, `SELECT 1 FROM files WHERE path = ?;`, ref)
+	if err != nil {
+		return Result{}, err
+	}
+	isPackage := false
+	if !isFile {
+		if strings.HasSuffix(ref, ".go") {
+			return Result{}, fmt.Errorf("%s: %w", ref, ErrFileNotIndexed)
+		}
+		if isPackage, err = s.exists(ctx, `SELECT 1 FROM packages WHERE path = ?;`, ref); err != nil {
+			return Result{}, err
+		}
+	}
+
+	switch {
+	case isFile:
+		result.Kind, result.File, result.Package = KindFile, ref, path.Dir(ref)
+		t.files = []string{ref}
+		t.packages = []string{result.Package}
+		if t.symbols, err = s.refs(ctx, `
+SELECT DISTINCT pk.path || '.' || sy.name
+FROM symbols sy
+JOIN files f ON f.id = sy.file_id
+JOIN packages pk ON pk.id = f.package_id
+WHERE f.path = ?;
+`, ref); err != nil {
+			return Result{}, err
+		}
+	case isPackage:
+		result.Kind, result.Package = KindPackage, ref
+		t.packages = []string{ref}
+		if t.files, err = s.refs(ctx, `
+SELECT f.path FROM files f JOIN packages pk ON pk.id = f.package_id WHERE pk.path = ?;
+`, ref); err != nil {
+			return Result{}, err
+		}
+		if t.symbols, err = s.refs(ctx, `
+SELECT DISTINCT pk.path || '.' || sy.name
+FROM symbols sy
+JOIN files f ON f.id = sy.file_id
+JOIN packages pk ON pk.id = f.package_id
+WHERE pk.path = ?;
+`, ref); err != nil {
+			return Result{}, err
+		}
+	default:
+		opts.NoBody = true
+		found, err := find.NewService(s.db).Find(ctx, ref, opts)
+		if err != nil {
+			return Result{}, err
+		}
+		sym := found.Symbol
+		result.Kind, result.File, result.Package, result.Symbol = KindSymbol, sym.FilePath, sym.Package, &sym
+		t.symbols = []string{sym.Package + "." + sym.Name}
+		t.files = []string{sym.FilePath}
+		t.packages = []string{sym.Package}
+	}
+
+	entries, err := s.linked(ctx, result.Kind, t)
+	if err != nil {
+		return Result{}, err
+	}
+	if entries, err = s.withHistory(ctx, entries); err != nil {
+		return Result{}, err
+	}
+	for _, e := range entries {
+		if e.Status == "active" && len(e.SupersededBy) == 0 {
+			result.Knowledge = append(result.Knowledge, e)
+		} else {
+			result.History = append(result.History, e)
+		}
+	}
+	sortEntries(result.Knowledge)
+	sortEntries(result.History)
+	return result, nil
+}
+
+// linked returns every entity, whatever its status, with an edge to one of
+// t's refs. An entity linked several ways keeps its most specific link: one
+// to the target itself, then to a symbol, a file, and a package.
+func (s *Service) linked(ctx context.Context, kind string, t target) ([]Entry, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	for _, group := range []struct {
+		toType string
+		refs   []string
+	}{{KindSymbol, t.symbols}, {KindFile, t.files}, {KindPackage, t.packages}} {
+		if len(group.refs) == 0 {
+			continue
+		}
+		conds = append(conds, "(e.to_type = ? AND e.to_ref IN ("+strings.TrimSuffix(strings.Repeat("?,", len(group.refs)), ",")+"))")
+		args = append(args, group.toType)
+		for _, ref := range group.refs {
+			args = append(args, ref)
+		}
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT e.from_type, e.from_id, e.relation, e.to_type, e.to_ref
+FROM edges e
+WHERE e.from_type IN ('decision', 'pattern', 'constraint') AND (`+strings.Join(conds, " OR ")+`)
+ORDER BY e.id;
+`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query linked knowledge: %w", err)
+	}
+	defer rows.Close()
+
+	rank := func(toType string) int {
+		if toType == kind {
+			return 0
+		}
+		return map[string]int{KindSymbol: 1, KindFile: 2, KindPackage: 3}[toType]
+	}
+	best := map[string]Entry{}
+	var order []string
+	for rows.Next() {
+		var (
+			e             Entry
+			toType, toRef string
+		)
+		if err := rows.Scan(&e.EntityType, &e.ID, &e.Relation, &toType, &toRef); err != nil {
+			return nil, fmt.Errorf("scan linked knowledge: %w", err)
+		}
+		e.Via = toType + ":" + toRef
+		key := entityKey(e.EntityType, e.ID)
+		prev, seen := best[key]
+		if !seen {
+			order = append(order, key)
+		}
+		if !seen || rank(toType) < rank(viaType(prev.Via)) {
+			best[key] = e
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate linked knowledge: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(order))
+	for _, key := range order {
+		e := best[key]
+		if err := s.load(ctx, &e); err != nil {
+			return nil, err
+		}
+		if e.Title != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// withHistory fills in SupersededBy for entries and appends the entities
+// they supersede, following supersedes edges back as far as they go.
+func (s *Service) withHistory(ctx context.Context, entries []Entry) ([]Entry, error) {
+	seen := map[string]bool{}
+	for _, e := range entries {
+		seen[entityKey(e.EntityType, e.ID)] = true
+	}
+	for i := 0; i < len(entries); i++ {
+		e := &entries[i]
+		rows, err := s.db.QueryContext(ctx, `
+SELECT from_type, from_id, to_type, to_ref
+FROM edges
+WHERE relation = 'supersedes'
+  AND ((from_type = ?1 AND from_id = ?2 AND to_type IN ('decision', 'pattern', 'constraint'))
+    OR (to_type = ?1 AND to_ref = ?3))
+ORDER BY id;
+`, e.EntityType, e.ID, strconv.FormatInt(e.ID, 10))
+		if err != nil {
+			return nil, fmt.Errorf("query supersedes edges: %w", err)
+		}
+		var older []Entry
+		for rows.Next() {
+			var (
+				fromType, toType, toRef string
+				fromID                  int64
+			)
+			if err := rows.Scan(&fromType, &fromID, &toType, &toRef); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan supersedes edge: %w", err)
+			}
+			if fromType != e.EntityType || fromID != e.ID {
+				e.SupersededBy = append(e.SupersededBy, entityKey(fromType, fromID))
+				continue
+			}
+			id, err := strconv.ParseInt(toRef, 10, 64)
+			if err != nil || seen[entityKey(toType, id)] {
+				continue
+			}
+			seen[entityKey(toType, id)] = true
+			older = append(older, Entry{EntityType: toType, ID: id})
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("iterate supersedes edges: %w", err)
+		}
+		for _, o := range older {
+			if err := s.load(ctx, &o); err != nil {
+				return nil, err
+			}
+			if o.Title != "" {
+				entries = append(entries, o)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// load fills in e's fields from its entity row and evidence. A missing
+// entity leaves Title empty.
+func (s *Service) load(ctx context.Context, e *Entry) error {
+	var query string
+	switch e.EntityType {
+	case "decision":
+		query = `SELECT title, reasoning, confidence, status, created_at, updated_at FROM decisions WHERE id = ?;`
+	case "pattern":
+		query = `SELECT title, description, confidence, status, created_at, updated_at FROM patterns WHERE id = ?;`
+	case "constraint":
+		query = `SELECT title, reasoning, confidence, status, created_at, updated_at FROM constraints WHERE id = ?;`
+	default:
+		return nil
+	}
+	err := s.db.QueryRowContext(ctx, query, e.ID).Scan(&e.Title, &e.Reasoning, &e.Confidence, &e.Status, &e.CreatedAt, &e.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load %s %d: %w", e.EntityType, e.ID, err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT summary, COALESCE(check_type, ''), COALESCE(drift_status, 'ok'), COALESCE(last_verified_at, '')
+FROM evidence
+WHERE entity_type = ? AND entity_id = ?
+ORDER BY id;
+`, e.EntityType, e.ID)
+	if err != nil {
+		return fmt.Errorf("query evidence: %w", err)
+	}
+	defer rows.Close()
+	e.Evidence = []Evidence{}
+	for rows.Next() {
+		var ev Evidence
+		if err := rows.Scan(&ev.Summary, &ev.CheckType, &ev.DriftStatus, &ev.LastVerifiedAt); err != nil {
+			return fmt.Errorf("scan evidence: %w", err)
+		}
+		e.Evidence = append(e.Evidence, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate evidence: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) exists(ctx context.Context, query, arg string) (bool, error) {
+	var one int
+	err := s.db.QueryRowContext(ctx, query, arg).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("resolve %s: %w", arg, err)
+	}
+	return true, nil
+}
+
+func (s *Service) refs(ctx context.Context, query, arg string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("query refs of %s: %w", arg, err)
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("scan ref: %w", err)
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate refs: %w", err)
+	}
+	return out, nil
+}
+
+// sortEntries orders constraints, then decisions, then patterns, by ID.
+func sortEntries(entries []Entry) {
+	rank := map[string]int{"constraint": 0, "decision": 1, "pattern": 2}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.EntityType != b.EntityType {
+			return rank[a.EntityType] < rank[b.EntityType]
+		}
+		return a.ID < b.ID
+	})
+}
+
+func entityKey(entityType string, id int64) string {
+	return fmt.Sprintf("%s:%d", entityType, id)
+}
+
+func viaType(via string) string {
+	typ, _, _ := strings.Cut(via, ":")
+	return typ
+}
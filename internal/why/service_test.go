@@ -0,0 +1,150 @@
+package why
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/robertguss/recon/internal/find"
+	"github.com/robertguss/recon/internal/testutil"
+)
+
+func setupWhyModule(t *testing.T) *sql.DB {
+	t.Helper()
+	_, conn := testutil.Module(t, map[string]string{
+		"go.mod":         "module example.com/m\n",
+		"main.go":        "package main\nimport \"example.com/m/store\"\nfunc main() { store.Open() }\n",
+		"store/store.go": "package store\ntype Store struct{}\nfunc Open() *Store { return &Store{} }\n",
+		"store/close.go": "package store\nfunc Close() {}\n",
+	})
+	testutil.Seed(t, conn,
+		`INSERT INTO decisions(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+			(1,'Open is the constructor','Callers never build Store directly','high','active','x','x'),
+			(2,'Stores are package-scoped','r2','medium','active','x','x'),
+			(3,'Open takes options','r3','low','archived','x','x'),
+			(4,'Open took a path','r4','low','archived','x','x'),
+			(5,'Close is idempotent','r5','medium','active','x','x')`,
+		`INSERT INTO patterns(id,title,description,confidence,status,created_at,updated_at) VALUES
+			(1,'Constructors return pointers','d','medium','active','x','x')`,
+		`INSERT INTO constraints(id,title,reasoning,confidence,status,created_at,updated_at) VALUES
+			(1,'store must not import main','c','high','active','x','x')`,
+		`INSERT INTO evidence(entity_type,entity_id,summary,check_type,drift_status,last_verified_at) VALUES
+			('decision',1,'Open exists','symbol_exists','ok','2026-01-01T00:00:00Z'),
+			('decision',2,'s','grep_pattern','drifting',NULL)`,
+		`INSERT INTO edges(from_type,from_id,to_type,to_ref,relation,source,confidence,created_at) VALUES
+			('decision',1,'package','store','affects','manual','high','x'),
+			('decision',1,'symbol','store.Open','affects','manual','high','x'),
+			('decision',2,'file','store/store.go','affects','manual','high','x'),
+			('decision',3,'symbol','store.Open','affects','manual','high','x'),
+			('decision',1,'decision','3','supersedes','manual','high','x'),
+			('decision',3,'decision','4','supersedes','manual','high','x'),
+			('decision',5,'symbol','store.Close','affects','manual','high','x'),
+			('pattern',1,'symbol','store.Open','affects','manual','high','x'),
+			('constraint',1,'package','store','affects','manual','high','x')`,
+	)
+	return conn
+}
+
+func entryKeys(entries []Entry) string {
+	keys := make([]string, len(entries))
+	for i, e := range entries {
+		keys[i] = entityKey(e.EntityType, e.ID) + "@" + e.Via
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestWhySymbol(t *testing.T) {
+	conn := setupWhyModule(t)
+	r, err := NewService(conn).Why(context.Background(), "Open", find.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Why: %v", err)
+	}
+	if r.Kind != KindSymbol || r.File != "store/store.go" || r.Package != "store" || r.Symbol == nil || r.Symbol.Body != "" {
+		t.Fatalf("target = %+v", r)
+	}
+	if got := entryKeys(r.Knowledge); got != "constraint:1@package:store,decision:1@symbol:store.Open,decision:2@file:store/store.go,pattern:1@symbol:store.Open" {
+		t.Fatalf("knowledge = %s", got)
+	}
+	if got := entryKeys(r.History); got != "decision:3@symbol:store.Open,decision:4@" {
+		t.Fatalf("history = %s", got)
+	}
+	d1 := r.Knowledge[1]
+	if d1.Reasoning != "Callers never build Store directly" || len(d1.Evidence) != 1 ||
+		d1.Evidence[0] != (Evidence{Summary: "Open exists", CheckType: "symbol_exists", DriftStatus: "ok", LastVerifiedAt: "2026-01-01T00:00:00Z"}) {
+		t.Fatalf("decision 1 = %+v", d1)
+	}
+	if h := r.History[0]; h.Status != "archived" || strings.Join(h.SupersededBy, ",") != "decision:1" {
+		t.Fatalf("decision 3 = %+v", h)
+	}
+	if h := r.History[1]; h.Title != "Open took a path" || strings.Join(h.SupersededBy, ",") != "decision:3" || h.Relation != "" {
+		t.Fatalf("decision 4 = %+v", h)
+	}
+
+	text := RenderText(r)
+	for _, want := range []string{
+		"Why func Open (store/store.go:3, package store)",
+		"- decision #1 Open is the constructor [high, active] affects via symbol:store.Open",
+		"  Why: Callers never build Store directly",
+		"  Evidence: Open exists (drift=ok, verified 2026-01-01T00:00:00Z)",
+		"History:\n- decision #3 Open takes options [low, archived] affects via symbol:store.Open\n  Superseded by: decision:1",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("text missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestWhyFileAndPackage(t *testing.T) {
+	conn := setupWhyModule(t)
+	svc := NewService(conn)
+
+	r, err := svc.Why(context.Background(), "store/close.go", find.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Why file: %v", err)
+	}
+	if r.Kind != KindFile || r.Symbol != nil {
+		t.Fatalf("file target = %+v", r)
+	}
+	if got := entryKeys(r.Knowledge); got != "constraint:1@package:store,decision:1@package:store,decision:5@symbol:store.Close" {
+		t.Fatalf("file knowledge = %s", got)
+	}
+	// Decision 1 links through the package; what it superseded comes along.
+	if got := entryKeys(r.History); got != "decision:3@,decision:4@" || !strings.Contains(RenderText(r), "Why file store/close.go (package store)") {
+		t.Fatalf("file history = %s", got)
+	}
+
+	r, err = svc.Why(context.Background(), "store", find.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Why package: %v", err)
+	}
+	if r.Kind != KindPackage || r.File != "" {
+		t.Fatalf("package target = %+v", r)
+	}
+	if got := entryKeys(r.Knowledge); got != "constraint:1@package:store,decision:1@package:store,decision:2@file:store/store.go,decision:5@symbol:store.Close,pattern:1@symbol:store.Open" {
+		t.Fatalf("package knowledge = %s", got)
+	}
+
+	r, err = svc.Why(context.Background(), ".", find.QueryOptions{})
+	if err != nil || r.Kind != KindPackage || len(r.Knowledge) != 0 || !strings.Contains(RenderText(r), "- (none recorded)") {
+		t.Fatalf("root package = %+v err=%v", r, err)
+	}
+}
+
+func TestWhyLookupErrors(t *testing.T) {
+	conn := setupWhyModule(t)
+	svc := NewService(conn)
+
+	if _, err := svc.Why(context.Background(), "store/missing.go", find.QueryOptions{}); !errors.Is(err, ErrFileNotIndexed) {
+		t.Fatalf("expected ErrFileNotIndexed, got %v", err)
+	}
+	if _, err := svc.Why(context.Background(), "Nope", find.QueryOptions{}); !errors.As(err, new(find.NotFoundError)) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+
+	_ = conn.Close()
+	if _, err := svc.Why(context.Background(), "Open", find.QueryOptions{}); err == nil || !strings.Contains(err.Error(), "resolve Open") {
+		t.Fatalf("expected resolve error, got %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// EnvVar overrides where the workspace registry is kept.
+const EnvVar = "RECON_WORKSPACE"
+
+var userConfigDir = os.UserConfigDir
+
+// Registry lists the module roots `recon workspace` operates on. It is kept
+// per user rather than per repository, so one invocation spans every
+// registered repo. Names maps the names given with SetName to their roots;
+// any other repo is named by its directory.
+type Registry struct {
+	Repos []string          `json:"repos"`
+	Names map[string]string `json:"names,omitempty"`
+}
+
+// Path returns the registry location: $RECON_WORKSPACE, or workspace.json in
+// the user's recon config directory.
+func Path() (string, error) {
+	if p := os.Getenv(EnvVar); p != "" {
+		return p, nil
+	}
+	dir, err := userConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve user config dir: %w", err)
+	}
+	return filepath.Join(dir, "recon", "workspace.json"), nil
+}
+
+// Load reads the registry at path. A missing file yields an empty registry.
+func Load(path string) (Registry, error) {
+	reg := Registry{Repos: []string{}}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return reg, nil
+		}
+		return Registry{}, fmt.Errorf("read workspace: %w", err)
+	}
+	if err := json.Unmarshal(raw, &reg); err != nil {
+		return Registry{}, fmt.Errorf("parse workspace %s: %w", path, err)
+	}
+	if reg.Repos == nil {
+		reg.Repos = []string{}
+	}
+	return reg, nil
+}
+
+// Save writes the registry to path, creating its directory.
+func (r Registry) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create workspace dir: %w", err)
+	}
+	raw, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal workspace: %w", err)
+	}
+	if err := os.WriteFile(path, append(raw, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write workspace: %w", err)
+	}
+	return nil
+}
+
+// Add registers root and reports whether it was new. Repos stay sorted.
+func (r *Registry) Add(root string) bool {
+	if slices.Contains(r.Repos, root) {
+		return false
+	}
+	r.Repos = append(r.Repos, root)
+	slices.Sort(r.Repos)
+	return true
+}
+
+// Remove unregisters root, and its name, and reports whether it was
+// registered.
+func (r *Registry) Remove(root string) bool {
+	i := slices.Index(r.Repos, root)
+	if i < 0 {
+		return false
+	}
+	r.Repos = slices.Delete(r.Repos, i, i+1)
+	r.unname(root)
+	return true
+}
+
+// Name returns the name of root: the one given with SetName, or the base name
+// of its directory.
+func (r Registry) Name(root string) string {
+	for name, named := range r.Names {
+		if named == root {
+			return name
+		}
+	}
+	return filepath.Base(root)
+}
+
+// SetName names the registered root, replacing any name it had. A name is
+// one path segment and belongs to one repo.
+func (r *Registry) SetName(root, name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid repo name %q: use a single path segment such as api", name)
+	}
+	if !slices.Contains(r.Repos, root) {
+		return fmt.Errorf("%s is not registered", root)
+	}
+	if other, ok := r.Names[name]; ok && other != root {
+		return fmt.Errorf("repo name %q is already used by %s", name, other)
+	}
+	r.unname(root)
+	if r.Names == nil {
+		r.Names = map[string]string{}
+	}
+	r.Names[name] = root
+	return nil
+}
+
+func (r *Registry) unname(root string) {
+	for name, named := range r.Names {
+		if named == root {
+			delete(r.Names, name)
+		}
+	}
+}
+
+// Resolve returns the root of the registered repo called name, by the name
+// given with SetName or else by directory name. A directory name shared by
+// several repos is ambiguous. An absolute registered root resolves to itself.
+func (r Registry) Resolve(name string) (string, error) {
+	if root, ok := r.Names[name]; ok {
+		return root, nil
+	}
+	if filepath.IsAbs(name) && slices.Contains(r.Repos, name) {
+		return name, nil
+	}
+	var matches []string
+	for _, root := range r.Repos {
+		if r.Name(root) == name {
+			matches = append(matches, root)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no registered repo named %q; see `recon repos list`", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("repo name %q is ambiguous (%s); name one with `recon repos add --name`", name, strings.Join(matches, ", "))
+	}
+}
+
+// Report is the outcome of one repo in a batch run. Result holds the
+// operation's payload when it succeeded.
+type Report struct {
+	Root   string `json:"root"`
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+	Result any    `json:"result,omitempty"`
+}
+
+// Run calls fn for every root, at most parallel at a time, and returns the
+// reports in the order of roots. A failing repo does not stop the others.
+func Run(ctx context.Context, roots []string, parallel int, fn func(ctx context.Context, root string) (any, error)) []Report {
+	if parallel < 1 {
+		parallel = 1
+	}
+	reports := make([]Report, len(roots))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, root := range roots {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			reports[i] = Report{Root: root}
+			if err := ctx.Err(); err != nil {
+				reports[i].Error = err.Error()
+				return
+			}
+			result, err := fn(ctx, root)
+			if err != nil {
+				reports[i].Error = err.Error()
+				return
+			}
+			reports[i].OK, reports[i].Result = true, result
+		}()
+	}
+	wg.Wait()
+	return reports
+}
@@ -0,0 +1,148 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPath(t *testing.T) {
+	t.Setenv(EnvVar, "/tmp/custom.json")
+	if p, err := Path(); err != nil || p != "/tmp/custom.json" {
+		t.Fatalf("Path with %s = %q, %v", EnvVar, p, err)
+	}
+
+	t.Setenv(EnvVar, "")
+	orig := userConfigDir
+	defer func() { userConfigDir = orig }()
+	userConfigDir = func() (string, error) { return "/home/me/.config", nil }
+	if p, err := Path(); err != nil || p != filepath.Join("/home/me/.config", "recon", "workspace.json") {
+		t.Fatalf("Path = %q, %v", p, err)
+	}
+	userConfigDir = func() (string, error) { return "", errors.New("no home") }
+	if _, err := Path(); err == nil || !strings.Contains(err.Error(), "resolve user config dir") {
+		t.Fatalf("expected config dir error, got %v", err)
+	}
+}
+
+func TestRegistryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "workspace.json")
+	reg, err := Load(path)
+	if err != nil || len(reg.Repos) != 0 {
+		t.Fatalf("Load missing = %+v, %v", reg, err)
+	}
+
+	if !reg.Add("/src/b") || !reg.Add("/src/a") || reg.Add("/src/b") {
+		t.Fatalf("unexpected Add results: %v", reg.Repos)
+	}
+	if err := reg.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	reg, err = Load(path)
+	if err != nil || strings.Join(reg.Repos, ",") != "/src/a,/src/b" {
+		t.Fatalf("Load = %+v, %v", reg, err)
+	}
+	if !reg.Remove("/src/a") || reg.Remove("/src/a") || strings.Join(reg.Repos, ",") != "/src/b" {
+		t.Fatalf("unexpected Remove results: %v", reg.Repos)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatalf("write bad registry: %v", err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "parse workspace") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
+
+func TestRegistryNames(t *testing.T) {
+	reg := Registry{}
+	reg.Add("/src/api")
+	reg.Add("/work/api")
+	reg.Add("/src/web")
+
+	if root, err := reg.Resolve("web"); err != nil || root != "/src/web" {
+		t.Fatalf("Resolve(web) = %q, %v", root, err)
+	}
+	if _, err := reg.Resolve("api"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Fatalf("expected ambiguous directory name, got %v", err)
+	}
+	if err := reg.SetName("/work/api", "work-api"); err != nil {
+		t.Fatalf("SetName: %v", err)
+	}
+	for name, want := range map[string]string{"api": "/src/api", "work-api": "/work/api", "/src/web": "/src/web"} {
+		if root, err := reg.Resolve(name); err != nil || root != want {
+			t.Fatalf("Resolve(%s) = %q, %v; want %s", name, root, err, want)
+		}
+	}
+	if reg.Name("/work/api") != "work-api" || reg.Name("/src/web") != "web" {
+		t.Fatalf("unexpected names %q, %q", reg.Name("/work/api"), reg.Name("/src/web"))
+	}
+
+	for _, tc := range []struct{ root, name, want string }{
+		{"/src/web", "work-api", "already used"},
+		{"/src/web", "a/b", "invalid repo name"},
+		{"/src/other", "other", "not registered"},
+	} {
+		if err := reg.SetName(tc.root, tc.name); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("SetName(%s, %s): expected %q, got %v", tc.root, tc.name, tc.want, err)
+		}
+	}
+	if _, err := reg.Resolve("missing"); err == nil || !strings.Contains(err.Error(), "no registered repo") {
+		t.Fatalf("expected unknown name error, got %v", err)
+	}
+
+	if err := reg.SetName("/work/api", "jobs"); err != nil || len(reg.Names) != 1 || reg.Names["jobs"] != "/work/api" {
+		t.Fatalf("expected rename to replace the old name, got %v, %v", reg.Names, err)
+	}
+	reg.Remove("/work/api")
+	if len(reg.Names) != 0 {
+		t.Fatalf("expected Remove to drop the name, got %v", reg.Names)
+	}
+}
+
+func TestRunBoundsParallelismAndKeepsOrder(t *testing.T) {
+	var running, peak atomic.Int32
+	roots := []string{"a", "b", "c", "d", "e"}
+	reports := Run(context.Background(), roots, 2, func(_ context.Context, root string) (any, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if root == "c" {
+			return nil, errors.New("boom")
+		}
+		return strings.ToUpper(root), nil
+	})
+
+	if peak.Load() > 2 {
+		t.Fatalf("ran %d at once, want at most 2", peak.Load())
+	}
+	var got []string
+	for _, r := range reports {
+		if r.OK {
+			got = append(got, r.Root+"="+r.Result.(string))
+		} else {
+			got = append(got, r.Root+"!"+r.Error)
+		}
+	}
+	if strings.Join(got, ",") != "a=A,b=B,c!boom,d=D,e=E" {
+		t.Fatalf("reports = %v", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reports = Run(ctx, []string{"a"}, 0, func(context.Context, string) (any, error) { return "ran", nil })
+	if reports[0].OK || !strings.Contains(reports[0].Error, "canceled") {
+		t.Fatalf("expected cancelled run, got %+v", reports[0])
+	}
+}